@@ -0,0 +1,260 @@
+package accesslog
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// 访问日志格式 Access log formats
+const (
+	FormatCombined  = "combined"  // Apache combined 格式 Apache combined format
+	FormatVCombined = "vcombined" // 行首带主机名的 combined 格式 Combined format prefixed with the host
+)
+
+// timeLayout Apache 日志的时间格式
+// Time layout of Apache logs
+const timeLayout = "02/Jan/2006:15:04:05 -0700"
+
+// Entry 一次托管站点请求的访问日志
+// Access log of one hosted site request
+type Entry struct {
+	Time      time.Time // 请求开始时间 Request start time
+	Host      string    // 请求主机 Request host
+	ClientIP  string    // 客户端IP Client IP
+	Method    string    // 请求方法 Request method
+	URI       string    // 包含查询参数的请求地址 Request URI including the query
+	Proto     string    // 协议版本 Protocol version
+	Status    int       // 响应状态码 Response status code
+	Bytes     int       // 响应体字节数 Response body bytes
+	Referer   string    // 来源 Referer
+	UserAgent string    // 用户代理 User agent
+}
+
+// exporter 异步写出日志行，写入不阻塞请求
+// Writes log lines asynchronously so requests never block on writing
+type exporter struct {
+	mu        sync.RWMutex // 保证关闭后不再写入队列 Keeps the queue from being written after it is closed
+	closed    bool
+	lines     chan []byte
+	done      chan struct{}
+	format    string
+	anonymize bool
+	dropped   atomic.Int64
+}
+
+var current atomic.Pointer[exporter]
+
+// Init 根据配置打开访问日志的输出，未开启时不做任何事
+// Open the access log output from configuration, doing nothing when disabled
+func Init() error {
+	if !config.AccessLogEnable {
+		return nil
+	}
+	if config.AccessLogFormat != FormatCombined && config.AccessLogFormat != FormatVCombined {
+		return errors.New("unsupported access log format: " + config.AccessLogFormat)
+	}
+	w, err := open()
+	if err != nil {
+		return err
+	}
+	e := &exporter{
+		lines:     make(chan []byte, max(config.AccessLogBufferSize, 1)),
+		done:      make(chan struct{}),
+		format:    config.AccessLogFormat,
+		anonymize: config.AccessLogAnonymizeIP,
+	}
+	go e.run(w)
+	current.Store(e)
+	return nil
+}
+
+// open 打开配置的输出
+// Open the configured output
+func open() (io.Writer, error) {
+	switch config.AccessLogOutput {
+	case "file":
+		if config.AccessLogFile == "" {
+			return nil, errors.New("log.access.file is required when writing access logs to a file")
+		}
+		// 轮转由 lumberjack 在写入时完成 Rotation is done by lumberjack while writing
+		return lineWriter{&lumberjack.Logger{
+			Filename:   config.AccessLogFile,
+			MaxSize:    config.AccessLogMaxSize,
+			MaxBackups: config.AccessLogMaxBackups,
+			MaxAge:     config.AccessLogMaxAge,
+			Compress:   config.AccessLogCompress,
+			LocalTime:  true,
+		}}, nil
+	case "stdout":
+		return lineWriter{os.Stdout}, nil
+	case "syslog":
+		w, err := dialSyslog(config.AccessLogSyslogNetwork, config.AccessLogSyslogAddress, config.AccessLogSyslogTag)
+		if err != nil {
+			return nil, fmt.Errorf("connect to syslog: %w", err)
+		}
+		return w, nil
+	default:
+		return nil, errors.New("unsupported access log output: " + config.AccessLogOutput)
+	}
+}
+
+// lineWriter 每次写入一行，syslog 每条消息为一行所以不需要
+// Write one line per call, syslog does not need it since every message is a line
+type lineWriter struct {
+	w io.Writer
+}
+
+func (l lineWriter) Write(p []byte) (int, error) {
+	return l.w.Write(append(p, '\n'))
+}
+
+func (l lineWriter) Close() error {
+	if closer, ok := l.w.(io.Closer); ok && l.w != os.Stdout {
+		return closer.Close()
+	}
+	return nil
+}
+
+func (e *exporter) run(w io.Writer) {
+	defer close(e.done)
+	if closer, ok := w.(io.Closer); ok {
+		defer closer.Close()
+	}
+	for line := range e.lines {
+		if _, err := w.Write(line); err != nil {
+			logrus.Warnf("failed to write access log: %v", err)
+		}
+		if dropped := e.dropped.Swap(0); dropped > 0 {
+			logrus.Warnf("dropped %d access log lines because writing fell behind", dropped)
+		}
+	}
+}
+
+// Close 停止导出并写完已排队的日志
+// Stop exporting and write the queued lines
+func Close() {
+	e := current.Swap(nil)
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	e.closed = true
+	close(e.lines)
+	e.mu.Unlock()
+	<-e.done
+}
+
+// Enabled 是否开启了访问日志导出
+// Whether access log export is enabled
+func Enabled() bool {
+	return current.Load() != nil
+}
+
+// Write 格式化并排队写出一条访问日志，队列已满时丢弃
+// Format an access log and queue it for writing, dropping it when the queue is full
+func Write(entry *Entry) {
+	e := current.Load()
+	if e == nil {
+		return
+	}
+	line := Format(entry, e.format, e.anonymize)
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closed {
+		return
+	}
+	select {
+	case e.lines <- line:
+	default:
+		e.dropped.Add(1)
+	}
+}
+
+// Format 将访问日志格式化为不带换行的一行，anonymize 时截断客户端 IP
+// Format the access log as one line without a newline, truncating the client IP when anonymize is set
+func Format(entry *Entry, format string, anonymize bool) []byte {
+	ip := entry.ClientIP
+	if anonymize {
+		ip = AnonymizeIP(ip)
+	}
+	var b strings.Builder
+	if format == FormatVCombined {
+		b.WriteString(field(entry.Host))
+		b.WriteByte(' ')
+	}
+	b.WriteString(field(ip))
+	b.WriteString(" - - [")
+	b.WriteString(entry.Time.Format(timeLayout))
+	b.WriteString(`] "`)
+	b.WriteString(escape(entry.Method + " " + entry.URI + " " + entry.Proto))
+	b.WriteString(`" `)
+	b.WriteString(strconv.Itoa(entry.Status))
+	b.WriteByte(' ')
+	if entry.Bytes > 0 {
+		b.WriteString(strconv.Itoa(entry.Bytes))
+	} else {
+		b.WriteByte('-')
+	}
+	b.WriteString(` "`)
+	b.WriteString(field(entry.Referer))
+	b.WriteString(`" "`)
+	b.WriteString(field(entry.UserAgent))
+	b.WriteByte('"')
+	return []byte(b.String())
+}
+
+// AnonymizeIP 截断 IP，IPv4 保留前 24 位，IPv6 保留前 48 位，无法解析时返回 -
+// Truncate the IP, keeping the first 24 bits of IPv4 and the first 48 bits of IPv6, - when it cannot be parsed
+func AnonymizeIP(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return "-"
+	}
+	addr = addr.Unmap()
+	bits := 48
+	if addr.Is4() {
+		bits = 24
+	}
+	prefix, _ := addr.Prefix(bits)
+	return prefix.Addr().String()
+}
+
+// field 转义后的字段，为空时为 -
+// An escaped field, - when empty
+func field(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return escape(s)
+}
+
+// escape 按 Apache 的方式转义引号、反斜杠、空白和控制字符，避免伪造日志行
+// Escape quotes, backslashes, whitespace and control characters like Apache does so no log lines can be forged
+func escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 0x20 || c == 0x7f:
+			fmt.Fprintf(&b, `\x%02x`, c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,44 @@
+package accesslog
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormat(t *testing.T) {
+	entry := &Entry{
+		Time:      time.Date(2025, 3, 9, 14, 5, 6, 0, time.FixedZone("", 8*3600)),
+		Host:      "docs.example.com",
+		ClientIP:  "203.0.113.45",
+		Method:    "GET",
+		URI:       "/guide/?q=1",
+		Proto:     "HTTP/1.1",
+		Status:    200,
+		Bytes:     1234,
+		Referer:   "https://example.com/",
+		UserAgent: `curl/8.0 "x"`,
+	}
+	want := `203.0.113.45 - - [09/Mar/2025:14:05:06 +0800] "GET /guide/?q=1 HTTP/1.1" 200 1234 "https://example.com/" "curl/8.0 \"x\""`
+	if got := string(Format(entry, FormatCombined, false)); got != want {
+		t.Fatalf("combined:\n got %s\nwant %s", got, want)
+	}
+	entry.Bytes, entry.Referer, entry.UserAgent = 0, "", "a\nb"
+	want = `docs.example.com 203.0.113.0 - - [09/Mar/2025:14:05:06 +0800] "GET /guide/?q=1 HTTP/1.1" 200 - "-" "a\x0ab"`
+	if got := string(Format(entry, FormatVCombined, true)); got != want {
+		t.Fatalf("vcombined:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestAnonymizeIP(t *testing.T) {
+	for ip, want := range map[string]string{
+		"192.168.1.200":             "192.168.1.0",
+		"::ffff:10.1.2.3":           "10.1.2.0",
+		"2001:db8:abcd:12:3::1":     "2001:db8:abcd::",
+		"not an ip":                 "-",
+		"2001:0db8:0000:0000::dead": "2001:db8::",
+	} {
+		if got := AnonymizeIP(ip); got != want {
+			t.Errorf("AnonymizeIP(%q) = %q, want %q", ip, got, want)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !windows && !plan9
+
+package accesslog
+
+import (
+	"io"
+	"log/syslog"
+)
+
+// dialSyslog 连接 syslog，network 为空时使用本机的 syslog 服务
+// Connect to syslog, using the local syslog daemon when network is empty
+func dialSyslog(network, address, tag string) (io.Writer, error) {
+	return syslog.Dial(network, address, syslog.LOG_INFO|syslog.LOG_LOCAL7, tag)
+}
@@ -0,0 +1,14 @@
+//go:build windows || plan9
+
+package accesslog
+
+import (
+	"errors"
+	"io"
+)
+
+// dialSyslog 当前平台不支持 syslog
+// Syslog is not supported on this platform
+func dialSyslog(network, address, tag string) (io.Writer, error) {
+	return nil, errors.New("syslog is not supported on this platform")
+}
@@ -0,0 +1,226 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/metrics"
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrMiss 键不存在或已过期
+// The key does not exist or has expired
+var ErrMiss = errors.New("cache miss")
+
+// Cache 键值缓存，多个实例共用 Redis 时各实例看到一致的结果
+// Key-value cache, instances sharing a Redis see consistent results
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// Config 缓存配置
+// Cache configuration
+type Config struct {
+	Backend string        // 缓存存储，memory 或 redis Cache store, memory or redis
+	TTL     time.Duration // 缓存条目的默认有效期 Default lifetime of cache entries
+
+	RedisAddr     string // Redis 地址 Redis address
+	RedisPassword string // Redis 密码 Redis password
+	RedisDB       int    // Redis 数据库编号 Redis database number
+	RedisPrefix   string // 键前缀，多个实例共用 Redis 时区分 Key prefix, telling instances sharing a Redis apart
+}
+
+var (
+	// Default 当前实例使用的缓存
+	// Cache used by this instance
+	Default Cache = NewMemory()
+	// TTL 缓存条目的默认有效期，写入方会主动失效，过期只是兜底
+	// Default lifetime of cache entries, writers invalidate eagerly and expiry is only a safety net
+	TTL = time.Minute
+)
+
+var lookups = metrics.NewCounterVec("spage_cache_lookups_total",
+	"Cache lookups by result.", "result")
+
+// loadConfig 从配置文件加载缓存配置
+// Load cache configuration from config file
+func loadConfig() Config {
+	return Config{
+		Backend: config.GetString("cache.backend", "memory"),
+		TTL:     time.Duration(config.GetInt("cache.ttl", 60)) * time.Second,
+
+		RedisAddr:     config.GetString("redis.addr", "localhost:6379"),
+		RedisPassword: config.GetString("redis.password", ""),
+		RedisDB:       config.GetInt("redis.db", 0),
+		RedisPrefix:   config.GetString("redis.prefix", "spage:"),
+	}
+}
+
+// Init 根据配置初始化缓存
+// Initialize the cache from configuration
+func Init() error {
+	cfg := loadConfig()
+	TTL = cfg.TTL
+	switch cfg.Backend {
+	case "memory":
+		Default = NewMemory()
+	case "redis":
+		client, err := Client(context.Background())
+		if err != nil {
+			return err
+		}
+		Default = NewRedis(client, cfg.RedisPrefix)
+	default:
+		return errors.New("unsupported cache backend: " + cfg.Backend)
+	}
+	return nil
+}
+
+var (
+	redisMu     sync.Mutex
+	redisClient *redis.Client
+)
+
+// Client 返回共享的 Redis 连接，首次调用时按配置连接，缓存和限流共用同一个连接池
+// Return the shared Redis client, connecting from configuration on first use, the cache and rate limiting share one pool
+func Client(ctx context.Context) (*redis.Client, error) {
+	redisMu.Lock()
+	defer redisMu.Unlock()
+	if redisClient != nil {
+		return redisClient, nil
+	}
+	cfg := loadConfig()
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.RedisAddr,
+		Password: cfg.RedisPassword,
+		DB:       cfg.RedisDB,
+	})
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, err
+	}
+	redisClient = client
+	return client, nil
+}
+
+// Ping 检查缓存后端是否可以访问，进程内缓存总是可以访问
+// Check that the cache backend is reachable, the in-process cache always is
+func Ping(ctx context.Context) error {
+	if _, ok := Default.(*Memory); ok {
+		return nil
+	}
+	client, err := Client(ctx)
+	if err != nil {
+		return err
+	}
+	return client.Ping(ctx).Err()
+}
+
+// GetJSON 从默认缓存读取并解码 JSON 值，不存在时返回 ErrMiss
+// Read and decode a JSON value from the default cache, returning ErrMiss when absent
+func GetJSON(ctx context.Context, key string, v any) error {
+	data, err := Default.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, ErrMiss) {
+			lookups.Inc("miss")
+		} else {
+			lookups.Inc("error")
+		}
+		return err
+	}
+	lookups.Inc("hit")
+	return json.Unmarshal(data, v)
+}
+
+// SetJSON 编码 JSON 值写入默认缓存，ttl 为 0 时使用默认有效期
+// Encode a JSON value into the default cache, using the default lifetime when ttl is 0
+func SetJSON(ctx context.Context, key string, v any, ttl time.Duration) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if ttl == 0 {
+		ttl = TTL
+	}
+	return Default.Set(ctx, key, data, ttl)
+}
+
+// Delete 从默认缓存删除键
+// Delete keys from the default cache
+func Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return Default.Delete(ctx, keys...)
+}
+
+// Memory 进程内缓存，只对单个实例生效
+// In-process cache, only effective for a single instance
+type Memory struct {
+	mu        sync.Mutex
+	entries   map[string]entry
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type entry struct {
+	value   []byte
+	expires time.Time
+}
+
+// sweepInterval 清理过期条目的间隔
+// Interval between sweeps of expired entries
+const sweepInterval = time.Minute
+
+// NewMemory 创建进程内缓存
+// Create an in-process cache
+func NewMemory() *Memory {
+	return &Memory{entries: make(map[string]entry), now: time.Now}
+}
+
+func (m *Memory) Get(_ context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[key]
+	if !ok || !m.now().Before(e.expires) {
+		return nil, ErrMiss
+	}
+	return e.value, nil
+}
+
+func (m *Memory) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	if now.Sub(m.lastSweep) > sweepInterval {
+		m.sweep(now)
+	}
+	m.entries[key] = entry{value: value, expires: now.Add(ttl)}
+	return nil
+}
+
+func (m *Memory) Delete(_ context.Context, keys ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, key := range keys {
+		delete(m.entries, key)
+	}
+	return nil
+}
+
+// sweep 丢弃已过期的条目
+// Drop expired entries
+func (m *Memory) sweep(now time.Time) {
+	for key, e := range m.entries {
+		if !now.Before(e.expires) {
+			delete(m.entries, key)
+		}
+	}
+	m.lastSweep = now
+}
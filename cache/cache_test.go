@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMemoryExpiry(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	m := NewMemory()
+	m.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_ = m.Set(ctx, "a", []byte("1"), time.Second)
+	if value, err := m.Get(ctx, "a"); err != nil || string(value) != "1" {
+		t.Fatalf("got %q, %v; want 1", value, err)
+	}
+	now = now.Add(time.Second)
+	if _, err := m.Get(ctx, "a"); !errors.Is(err, ErrMiss) {
+		t.Fatalf("expired entry: got %v, want ErrMiss", err)
+	}
+
+	_ = m.Set(ctx, "b", []byte("2"), time.Hour)
+	now = now.Add(2 * sweepInterval)
+	_ = m.Set(ctx, "c", []byte("3"), time.Second)
+	if _, ok := m.entries["a"]; ok {
+		t.Error("expired entries should be swept")
+	}
+	if _, ok := m.entries["b"]; !ok {
+		t.Error("live entries should be kept")
+	}
+}
+
+func TestMemoryDelete(t *testing.T) {
+	m := NewMemory()
+	ctx := context.Background()
+	_ = m.Set(ctx, "a", []byte("1"), time.Hour)
+	_ = m.Set(ctx, "b", []byte("2"), time.Hour)
+	_ = m.Delete(ctx, "a", "b", "missing")
+	if _, err := m.Get(ctx, "a"); !errors.Is(err, ErrMiss) {
+		t.Errorf("deleted key a: got %v, want ErrMiss", err)
+	}
+	if _, err := m.Get(ctx, "b"); !errors.Is(err, ErrMiss) {
+		t.Errorf("deleted key b: got %v, want ErrMiss", err)
+	}
+}
+
+func TestJSONHelpers(t *testing.T) {
+	Default = NewMemory()
+	ctx := context.Background()
+	type value struct {
+		ID   uint     `json:"id"`
+		Tags []string `json:"tags"`
+	}
+	if err := SetJSON(ctx, "v", value{ID: 7, Tags: []string{"x"}}, 0); err != nil {
+		t.Fatal(err)
+	}
+	var got value
+	if err := GetJSON(ctx, "v", &got); err != nil || got.ID != 7 || len(got.Tags) != 1 {
+		t.Fatalf("got %+v, %v", got, err)
+	}
+	if err := GetJSON(ctx, "missing", &got); !errors.Is(err, ErrMiss) {
+		t.Fatalf("missing key: got %v, want ErrMiss", err)
+	}
+}
@@ -0,0 +1,217 @@
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/task"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/adaptor"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+	"gorm.io/gorm"
+)
+
+// Config ACME 配置
+// ACME configuration
+type Config struct {
+	Enable    bool   // 是否启用 Enabled
+	Email     string // 证书到期提醒邮箱 Email for expiry notices
+	Directory string // ACME 目录地址，留空使用 Let's Encrypt ACME directory URL, Let's Encrypt when empty
+}
+
+func loadConfig() Config {
+	return Config{
+		Enable:    config.GetBool("acme.enable", false),
+		Email:     config.GetString("acme.email", ""),
+		Directory: config.GetString("acme.directory", ""),
+	}
+}
+
+// Manager 证书管理器，未启用 ACME 时为 nil
+// Certificate manager, nil when ACME is disabled
+var Manager *autocert.Manager
+
+// HTTPSPort HTTPS 监听端口
+// HTTPS listen port
+var HTTPSPort string
+
+// Init 按配置创建证书管理器并加载 TLS 终止配置，ACME 证书和账户密钥保存在数据库中
+// Create the certificate manager and load the TLS termination configuration, ACME certificates and account keys are kept in the database
+func Init() error {
+	cfg := loadConfig()
+	if cfg.Enable {
+		initACME(cfg)
+	}
+	if err := initTermination(); err != nil {
+		return err
+	}
+	if Serving() {
+		logrus.Infof("Serving HTTPS on port %s", HTTPSPort)
+	}
+	return nil
+}
+
+// initACME 创建证书管理器并注册证书申请任务
+// Create the certificate manager and register the certificate job
+func initACME(cfg Config) {
+	Manager = &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		Cache:      dbCache{},
+		HostPolicy: hostPolicy,
+		Email:      cfg.Email,
+	}
+	if cfg.Directory != "" {
+		Manager.Client = &acme.Client{DirectoryURL: cfg.Directory}
+	}
+	task.RegisterJob(constants.JobTypeCertificate, task.JobHandler{Run: issue})
+	task.RegisterCron(constants.CronTaskCertificates, task.CronHandler{
+		Description: "Renew ACME certificates expiring within 30 days",
+		Schedule:    func() string { return config.CronCertificates },
+		Run:         renew,
+	})
+	logrus.Info("ACME enabled")
+}
+
+// IssueJob 证书申请任务的参数
+// Parameters of a certificate job
+type IssueJob struct {
+	Domain string `json:"domain"` // 自定义域名 Custom domain
+}
+
+// issue 在后台为域名申请或续期证书，使首次 HTTPS 访问无需等待签发
+// Obtain or renew the certificate of a domain in the background, so the first HTTPS request does not wait for issuance
+func issue(ctx context.Context, job *models.Job) (any, error) {
+	payload := IssueJob{}
+	if err := json.Unmarshal([]byte(job.Payload), &payload); err != nil || payload.Domain == "" {
+		return nil, task.Permanent(errors.New("invalid certificate job payload"))
+	}
+	if err := hostPolicy(ctx, payload.Domain); err != nil {
+		return nil, task.Permanent(err)
+	}
+	// 模拟支持 ECDSA 的客户端，得到与浏览器访问相同的证书 Pose as an ECDSA capable client to get the certificate browsers are served
+	cert, err := Manager.GetCertificate(&tls.ClientHelloInfo{
+		ServerName:       payload.Domain,
+		CipherSuites:     []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256},
+		SupportedCurves:  []tls.CurveID{tls.CurveP256},
+		SignatureSchemes: []tls.SignatureScheme{tls.ECDSAWithP256AndSHA256},
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]any{"domain": payload.Domain}
+	if cert.Leaf != nil {
+		result["not_after"] = cert.Leaf.NotAfter
+	}
+	return result, nil
+}
+
+// renewBefore 证书到期前多久续期，与 autocert 的默认值相同
+// How long before expiry certificates are renewed, the autocert default
+const renewBefore = 30 * 24 * time.Hour
+
+// renew 为缓存中即将过期且域名仍已验证的证书创建证书任务，任务加载证书时由 autocert 续期，使很少被访问的域名也能及时续期
+// Enqueue certificate jobs for cached certificates about to expire whose domains are still verified, autocert renews them once the job loads them,
+// so rarely visited domains are renewed in time too
+func renew(ctx context.Context) (any, error) {
+	entries, err := store.Domain.ListCertificates()
+	if err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(renewBefore)
+	domains := []string{}
+	for _, entry := range entries {
+		domain, notAfter, ok := CachedCertificate(entry.Name, entry.Data)
+		if !ok || notAfter.After(deadline) || !store.Domain.IsVerified(domain) {
+			continue
+		}
+		if _, err := task.EnqueueJob(ctx, constants.JobTypeCertificate, IssueJob{Domain: domain}, 0, 0); err != nil {
+			return nil, err
+		}
+		domains = append(domains, domain)
+	}
+	return map[string]any{"domains": domains}, nil
+}
+
+// Enabled 是否启用了 ACME
+// Whether ACME is enabled
+func Enabled() bool {
+	return Manager != nil
+}
+
+// HTTPChallenge 应答 /.well-known/acme-challenge/ 下的 HTTP-01 验证
+// Answer HTTP-01 challenges under /.well-known/acme-challenge/
+func HTTPChallenge() app.HandlerFunc {
+	return adaptor.HertzHandler(Manager.HTTPHandler(nil))
+}
+
+// hostPolicy 只为已验证所有权的自定义域名申请证书
+// Only request certificates for custom domains with verified ownership
+func hostPolicy(ctx context.Context, host string) error {
+	if store.Domain.IsVerified(host) {
+		return nil
+	}
+	return fmt.Errorf("host %q is not a verified custom domain", host)
+}
+
+// dbCache 基于数据库的 autocert 缓存
+// Database backed autocert cache
+type dbCache struct{}
+
+func (dbCache) Get(ctx context.Context, key string) ([]byte, error) {
+	cert, err := store.Domain.GetCertificate(key)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, autocert.ErrCacheMiss
+	}
+	if err != nil {
+		return nil, err
+	}
+	return cert.Data, nil
+}
+
+func (dbCache) Put(ctx context.Context, key string, data []byte) error {
+	return store.Domain.PutCertificate(key, data)
+}
+
+func (dbCache) Delete(ctx context.Context, key string) error {
+	return store.Domain.DeleteCertificate(key)
+}
+
+// CachedCertificate ACME 缓存中的条目为域名的 ECDSA 证书时，返回域名和叶子证书的到期时间；
+// 账户密钥、HTTP-01 验证令牌以及只提供给不支持 ECDSA 的客户端的 RSA 证书返回 false
+// The domain and the expiry of the leaf certificate when an ACME cache entry is the ECDSA certificate of a domain;
+// false for the account key, HTTP-01 tokens and the RSA certificates only served to clients without ECDSA support
+func CachedCertificate(key string, data []byte) (domain string, notAfter time.Time, ok bool) {
+	// 其他条目的键带有 + 后缀 Keys of the other entries carry a + suffix
+	if strings.Contains(key, "+") {
+		return "", time.Time{}, false
+	}
+	// 条目依次为私钥和证书链 The entry holds the private key followed by the chain
+	for rest := data; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			return "", time.Time{}, false
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		leaf, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return "", time.Time{}, false
+		}
+		return key, leaf.NotAfter, true
+	}
+}
@@ -0,0 +1,50 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestCachedCertificate(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notAfter := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		NotBefore:    notAfter.AddDate(0, -3, 0),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	data := append(keyPEM, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+
+	domain, got, ok := CachedCertificate("example.com", data)
+	if !ok || domain != "example.com" || !got.Equal(notAfter) {
+		t.Errorf("CachedCertificate = %q, %v, %v", domain, got, ok)
+	}
+	for _, name := range []string{"example.com+rsa", "acme_account+key", "token+http-01"} {
+		if _, _, ok := CachedCertificate(name, data); ok {
+			t.Errorf("CachedCertificate(%q) should not be a certificate", name)
+		}
+	}
+	if _, _, ok := CachedCertificate("example.com", keyPEM); ok {
+		t.Error("an entry without a certificate should not be a certificate")
+	}
+}
@@ -0,0 +1,212 @@
+package certs
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/acme"
+)
+
+// ProxyHeader 内置 TLS 终止转发请求时携带的凭证头，后端据此信任转发的客户端地址和协议
+// Credential header carried by requests forwarded by the built-in TLS termination, the backend trusts the forwarded client address and scheme by it
+const ProxyHeader = "X-Spage-Proxy"
+
+// staticReloadInterval 检查证书文件是否更新的最短间隔
+// Shortest interval between checks whether the certificate files changed
+const staticReloadInterval = time.Minute
+
+// TerminationConfig 内置 TLS 终止配置
+// Built-in TLS termination configuration
+type TerminationConfig struct {
+	Enable       bool   // 是否启用，启用 ACME 时自动启用 Enabled, implied by ACME
+	Port         string // HTTPS 监听端口 HTTPS listen port
+	CertFile     string // PEM 证书链文件 PEM certificate chain file
+	KeyFile      string // PEM 私钥文件 PEM private key file
+	HTTP2        bool   // 是否协商 HTTP/2 Whether to negotiate HTTP/2
+	RedirectHTTP bool   // 将 HTTP 请求重定向到 HTTPS Redirect HTTP requests to HTTPS
+	HSTSMaxAge   int    // HSTS 有效期，单位秒，0 表示不发送 HSTS max-age in seconds, 0 sends none
+}
+
+func loadTerminationConfig() TerminationConfig {
+	return TerminationConfig{
+		Enable:       config.GetBool("tls.enable", false),
+		Port:         config.GetString("tls.port", config.GetString("acme.https-port", "443")),
+		CertFile:     config.GetString("tls.cert-file", ""),
+		KeyFile:      config.GetString("tls.key-file", ""),
+		HTTP2:        config.GetBool("tls.http2", true),
+		RedirectHTTP: config.GetBool("tls.redirect-http", false),
+		HSTSMaxAge:   config.GetInt("tls.hsts-max-age", 0),
+	}
+}
+
+// Termination 生效的 TLS 终止配置，未启用时 Enable 为 false
+// Effective TLS termination configuration, Enable is false when disabled
+var Termination TerminationConfig
+
+// static 配置文件中指定的证书，未配置时为 nil
+// Certificate given in the configuration file, nil when not configured
+var static *staticCert
+
+// proxyToken 转发请求携带的凭证，由 token.secret 派生，使平滑重启的新旧进程和共享配置的实例互相认可
+// Credential of forwarded requests, derived from token.secret so the old and new process of a graceful restart and instances sharing the configuration accept each other
+var proxyToken string
+
+// initTermination 加载 TLS 终止配置和静态证书，需在 ACME 初始化之后调用
+// Load the TLS termination configuration and the static certificate, called after ACME is initialized
+func initTermination() error {
+	cfg := loadTerminationConfig()
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		if cfg.CertFile == "" || cfg.KeyFile == "" {
+			return errors.New("tls.cert-file and tls.key-file must be set together")
+		}
+		static = &staticCert{certFile: cfg.CertFile, keyFile: cfg.KeyFile}
+		if err := static.load(); err != nil {
+			return err
+		}
+	}
+	cfg.Enable = cfg.Enable || Enabled()
+	if cfg.Enable && static == nil && !Enabled() {
+		return errors.New("tls.enable requires tls.cert-file and tls.key-file or acme.enable")
+	}
+	Termination = cfg
+	HTTPSPort = cfg.Port
+	mac := hmac.New(sha256.New, []byte(config.JwtSecret))
+	mac.Write([]byte(ProxyHeader))
+	proxyToken = hex.EncodeToString(mac.Sum(nil))
+	return nil
+}
+
+// Serving 是否在 HTTPS 端口提供服务
+// Whether HTTPS is served on the HTTPS port
+func Serving() bool {
+	return Termination.Enable
+}
+
+// ProxyToken 转发请求应在 ProxyHeader 中携带的凭证
+// Credential forwarded requests carry in ProxyHeader
+func ProxyToken() string {
+	return proxyToken
+}
+
+// TLSConfig HTTPS 服务使用的 TLS 配置，按 SNI 选择静态证书或 ACME 证书，启用 ACME 时同时应答 TLS-ALPN-01 验证
+// TLS configuration of the HTTPS server, choosing the static or the ACME certificate by SNI and also answering TLS-ALPN-01 challenges when ACME is enabled
+func TLSConfig() *tls.Config {
+	protos := []string{"http/1.1"}
+	if Termination.HTTP2 {
+		protos = append([]string{"h2"}, protos...)
+	}
+	if Enabled() {
+		protos = append(protos, acme.ALPNProto)
+	}
+	return &tls.Config{
+		GetCertificate: getCertificate,
+		NextProtos:     protos,
+		MinVersion:     tls.VersionTLS12,
+	}
+}
+
+// getCertificate 静态证书覆盖的主机名使用静态证书，其余交给 ACME，都不适用时仍返回静态证书作为默认证书
+// Hosts covered by the static certificate use it, the rest go to ACME, the static certificate stays the default when neither applies
+func getCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	var cert *tls.Certificate
+	if static != nil {
+		cert = static.get()
+	}
+	challenge := len(hello.SupportedProtos) == 1 && hello.SupportedProtos[0] == acme.ALPNProto
+	if cert != nil && !challenge && (hello.ServerName == "" || cert.Leaf.VerifyHostname(hello.ServerName) == nil) {
+		return cert, nil
+	}
+	if Enabled() {
+		acmeCert, err := Manager.GetCertificate(hello)
+		if err == nil || cert == nil || challenge {
+			return acmeCert, err
+		}
+	}
+	if cert == nil {
+		return nil, fmt.Errorf("no certificate for host %q", hello.ServerName)
+	}
+	return cert, nil
+}
+
+// RedirectURL HTTP 请求重定向到的 HTTPS 地址，HTTPS 端口不是 443 时保留端口
+// HTTPS address an HTTP request is redirected to, keeping the port when the HTTPS port is not 443
+func RedirectURL(host, requestURI, port string) string {
+	host = utils.Domain.Host(host)
+	if ip := net.ParseIP(host); ip != nil && ip.To4() == nil {
+		host = "[" + host + "]"
+	}
+	if port != "" && port != "443" {
+		host += ":" + port
+	}
+	return "https://" + host + requestURI
+}
+
+// staticCert 从文件加载的证书，文件更新后自动重新加载，证书续期后无需重启
+// Certificate loaded from files, reloaded when the files change so renewals need no restart
+type staticCert struct {
+	certFile, keyFile string
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time
+	checked time.Time
+}
+
+// load 读取证书和私钥文件并解析证书
+// Read the certificate and key files and parse the certificate
+func (s *staticCert) load() error {
+	info, err := os.Stat(s.certFile)
+	if err != nil {
+		return fmt.Errorf("read tls certificate: %w", err)
+	}
+	cert, err := tls.LoadX509KeyPair(s.certFile, s.keyFile)
+	if err != nil {
+		return fmt.Errorf("load tls certificate: %w", err)
+	}
+	if cert.Leaf == nil {
+		if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+			return fmt.Errorf("parse tls certificate: %w", err)
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cert, s.modTime, s.checked = &cert, info.ModTime(), time.Now()
+	return nil
+}
+
+// get 返回当前证书，距上次检查超过 staticReloadInterval 且文件已更新时先重新加载，加载失败时继续使用旧证书
+// Return the current certificate, reloading first when staticReloadInterval passed since the last check and the file changed, the old certificate stays on failure
+func (s *staticCert) get() *tls.Certificate {
+	s.mu.Lock()
+	cert, modTime := s.cert, s.modTime
+	stale := time.Since(s.checked) >= staticReloadInterval
+	if stale {
+		s.checked = time.Now()
+	}
+	s.mu.Unlock()
+	if !stale {
+		return cert
+	}
+	if info, err := os.Stat(s.certFile); err != nil || info.ModTime().Equal(modTime) {
+		return cert
+	}
+	if err := s.load(); err != nil {
+		logrus.Warnf("Keeping the previous TLS certificate: %v", err)
+		return cert
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cert
+}
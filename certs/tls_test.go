@@ -0,0 +1,20 @@
+package certs
+
+import "testing"
+
+func TestRedirectURL(t *testing.T) {
+	cases := []struct {
+		host, uri, port, want string
+	}{
+		{"example.com", "/a?b=1", "443", "https://example.com/a?b=1"},
+		{"Example.com:80", "/", "443", "https://example.com/"},
+		{"example.com:8888", "/x", "8443", "https://example.com:8443/x"},
+		{"[::1]:80", "/", "443", "https://[::1]/"},
+		{"127.0.0.1", "/", "", "https://127.0.0.1/"},
+	}
+	for _, tc := range cases {
+		if got := RedirectURL(tc.host, tc.uri, tc.port); got != tc.want {
+			t.Errorf("RedirectURL(%q, %q, %q) = %q, want %q", tc.host, tc.uri, tc.port, got, tc.want)
+		}
+	}
+}
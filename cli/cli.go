@@ -0,0 +1,119 @@
+// Package cli spage 命令行工具，使用个人访问令牌登录、创建项目和站点并上传目录作为部署
+// Package cli is the spage command line tool, logging in with a personal access token, creating projects and sites and uploading directories as deployments
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagServer string
+	flagToken  string
+)
+
+// Execute 运行命令行工具
+// Run the command line tool
+func Execute() error {
+	return rootCmd().Execute()
+}
+
+func rootCmd() *cobra.Command {
+	root := &cobra.Command{
+		Use:           "spage",
+		Short:         "Deploy static sites to a Spage server",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	root.PersistentFlags().StringVar(&flagServer, "server", "", "Spage server URL, overrides $"+envServer)
+	root.PersistentFlags().StringVar(&flagToken, "token", "", "personal access token, overrides $"+envToken)
+	root.AddCommand(loginCmd(), logoutCmd(), whoamiCmd(), projectCmd(), siteCmd(), deployCmd(), runnerCmd())
+	return root
+}
+
+func loginCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "login",
+		Short: "Save the server URL and a personal access token",
+		Long:  "Save the server URL and a personal access token. The token is read from --token, $" + envToken + " or standard input.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			server := firstNonEmpty(flagServer, os.Getenv(envServer))
+			if server == "" {
+				return errors.New("--server is required")
+			}
+			token := firstNonEmpty(flagToken, os.Getenv(envToken))
+			if token == "" {
+				fmt.Fprint(cmd.ErrOrStderr(), "Token: ")
+				line, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+				if err != nil && line == "" {
+					return err
+				}
+				token = strings.TrimSpace(line)
+			}
+			if !strings.HasPrefix(token, constants.APITokenPrefix) {
+				return fmt.Errorf("personal access tokens start with %s, create one under your user settings", constants.APITokenPrefix)
+			}
+			flagServer, flagToken = server, token
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+			u, err := c.currentUser()
+			if err != nil {
+				return fmt.Errorf("verify token: %w", err)
+			}
+			path, err := saveCredentials(&credentials{Server: c.server, Token: token})
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Logged in to %s as %s, credentials saved to %s\n", c.server, u.Name, path)
+			return nil
+		},
+	}
+}
+
+func logoutCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "logout",
+		Short: "Remove the saved credentials",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			path, err := credentialsPath()
+			if err != nil {
+				return err
+			}
+			if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), "Logged out")
+			return nil
+		},
+	}
+}
+
+func whoamiCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "whoami",
+		Short: "Show the user owning the token",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+			u, err := c.currentUser()
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s (id %d) on %s\n", u.Name, u.ID, c.server)
+			return nil
+		},
+	}
+}
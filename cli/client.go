@@ -0,0 +1,288 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// 环境变量优先于配置文件，便于在 CI 中使用
+// Environment variables take precedence over the config file, convenient in CI
+const (
+	envServer = "SPAGE_SERVER"
+	envToken  = "SPAGE_TOKEN"
+)
+
+// credentials 登录后保存的服务地址和个人访问令牌
+// Server address and personal access token saved by login
+type credentials struct {
+	Server string `json:"server"`
+	Token  string `json:"token"`
+}
+
+// credentialsPath 配置文件路径，位于用户配置目录下
+// Path of the config file, under the user config directory
+func credentialsPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "spage", "credentials.json"), nil
+}
+
+func loadCredentials() (*credentials, error) {
+	creds := &credentials{}
+	path, err := credentialsPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, creds); err != nil {
+			return nil, fmt.Errorf("read %s: %w", path, err)
+		}
+	}
+	return creds, nil
+}
+
+func saveCredentials(creds *credentials) (string, error) {
+	path, err := credentialsPath()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(creds, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	// 令牌只允许当前用户读取 Only the current user may read the token
+	return path, os.WriteFile(path, data, 0o600)
+}
+
+// client Spage API 客户端
+// Spage API client
+type client struct {
+	server string
+	token  string
+	http   *http.Client
+}
+
+// newClient 按命令行参数、环境变量、配置文件的顺序确定服务地址和令牌
+// Resolve the server and token from flags, environment variables and the config file in that order
+func newClient() (*client, error) {
+	creds, err := loadCredentials()
+	if err != nil {
+		return nil, err
+	}
+	server := firstNonEmpty(flagServer, os.Getenv(envServer), creds.Server)
+	token := firstNonEmpty(flagToken, os.Getenv(envToken), creds.Token)
+	if server == "" || token == "" {
+		return nil, errors.New("not logged in, run `spage login` or set SPAGE_SERVER and SPAGE_TOKEN")
+	}
+	return &client{
+		server: strings.TrimRight(server, "/"),
+		token:  token,
+		// 上传大站点可能需要较长时间，只限制无响应的连接 Uploading large sites may take a while, only stalled connections are cut
+		http: &http.Client{Transport: &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			ResponseHeaderTimeout: 10 * time.Minute,
+			IdleConnTimeout:       time.Minute,
+		}},
+	}, nil
+}
+
+// apiError 接口返回的错误
+// Error returned by the API
+type apiError struct {
+	Status    int
+	Code      string // 错误码 Error code
+	Message   string
+	RequestID string // 请求ID，反馈问题时提供 Request ID to quote when reporting problems
+}
+
+func (e *apiError) Error() string {
+	if e.Message == "" {
+		return http.StatusText(e.Status)
+	}
+	if e.RequestID != "" {
+		return fmt.Sprintf("%s (%d, request %s)", e.Message, e.Status, e.RequestID)
+	}
+	return fmt.Sprintf("%s (%d)", e.Message, e.Status)
+}
+
+// do 发送请求并将 JSON 响应解码到 out，非 2xx 响应返回 apiError
+// Send a request and decode the JSON response into out, non 2xx responses return an apiError
+func (c *client) do(method, path string, body io.Reader, contentType string, size int64, out any) error {
+	req, err := http.NewRequest(method, c.server+"/api/v1"+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("User-Agent", "spage-cli")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if size >= 0 {
+		req.ContentLength = size
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return newAPIError(resp.StatusCode, data)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+// download 发送 GET 请求并将响应体写入 w，非 2xx 响应返回 apiError
+// Send a GET request and write the response body to w, non 2xx responses return an apiError
+func (c *client) download(path string, w io.Writer) error {
+	req, err := http.NewRequest(http.MethodGet, c.server+"/api/v1"+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("User-Agent", "spage-cli")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		return newAPIError(resp.StatusCode, data)
+	}
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// events 订阅 SSE 事件流，每个事件调用一次 handle，直到服务端关闭连接或 handle 返回错误
+// Subscribe to an SSE stream calling handle once per event until the server closes the connection or handle fails
+func (c *client) events(path string, handle func(event string, data []byte) error) error {
+	req, err := http.NewRequest(http.MethodGet, c.server+"/api/v1"+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("User-Agent", "spage-cli")
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		return newAPIError(resp.StatusCode, data)
+	}
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64<<10), 1<<20)
+	event, data := "", []byte(nil)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			// 空行结束一个事件，注释行没有数据 A blank line ends an event, comment lines carry no data
+			if data != nil {
+				if err := handle(event, data); err != nil {
+					return err
+				}
+			}
+			event, data = "", nil
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " ")...)
+		}
+	}
+	return scanner.Err()
+}
+
+// newAPIError 从错误响应的 JSON 中读取错误码、提示和请求ID
+// Read the code, message and request ID from the JSON of an error response
+func newAPIError(status int, data []byte) *apiError {
+	apiErr := &apiError{Status: status}
+	var body struct {
+		Code      string `json:"code"`
+		Message   string `json:"message"`
+		RequestID string `json:"request_id"`
+	}
+	if json.Unmarshal(data, &body) == nil {
+		apiErr.Code, apiErr.Message, apiErr.RequestID = body.Code, body.Message, body.RequestID
+	}
+	return apiErr
+}
+
+// getJSON 发送 GET 请求
+// Send a GET request
+func (c *client) getJSON(path string, out any) error {
+	return c.do(http.MethodGet, path, nil, "", -1, out)
+}
+
+// postJSON 以 JSON 请求体发送 POST 请求
+// Send a POST request with a JSON body
+func (c *client) postJSON(path string, in, out any) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return c.do(http.MethodPost, path, bytes.NewReader(data), "application/json", int64(len(data)), out)
+}
+
+// putJSON 以 JSON 请求体发送 PUT 请求
+// Send a PUT request with a JSON body
+func (c *client) putJSON(path string, in, out any) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return c.do(http.MethodPut, path, bytes.NewReader(data), "application/json", int64(len(data)), out)
+}
+
+// user 当前令牌所属的用户
+// User owning the current token
+type user struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+func (c *client) currentUser() (*user, error) {
+	var resp struct {
+		User user `json:"user"`
+	}
+	if err := c.getJSON("/user", &resp); err != nil {
+		return nil, err
+	}
+	return &resp.User, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
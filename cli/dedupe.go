@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// blobMaxSize 单个文件按内容上传的最大字节数，更大的文件随压缩包上传，兼容请求体限制固定为 4 MiB 的旧版本服务端
+// Largest file uploaded by content, larger ones go in an archive so older servers with a fixed 4 MiB body limit keep working
+const blobMaxSize = 4 << 20
+
+// manifestFile 部署清单中的一个文件
+// A file of a deployment manifest
+type manifestFile struct {
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+}
+
+// hashFiles 计算待上传文件的 SHA-256 和大小
+// Compute the SHA-256 and size of the files to upload
+func hashFiles(root string, files []string) ([]manifestFile, error) {
+	manifest := make([]manifestFile, 0, len(files))
+	for _, rel := range files {
+		file, err := os.Open(filepath.Join(root, filepath.FromSlash(rel)))
+		if err != nil {
+			return nil, err
+		}
+		hash := sha256.New()
+		size, err := io.Copy(hash, file)
+		_ = file.Close()
+		if err != nil {
+			return nil, err
+		}
+		manifest = append(manifest, manifestFile{Path: rel, SHA256: hex.EncodeToString(hash.Sum(nil)), Size: size})
+	}
+	return manifest, nil
+}
+
+// deployManifest 只上传服务端尚未存储的文件，再提交部署清单。
+// 服务端不支持清单部署或有文件超出单个请求的大小时返回 false，由调用方改为上传压缩包
+// Upload only the files the server does not store yet, then submit the deployment manifest.
+// Returns false when the server lacks manifest deployments or a file exceeds a single request, letting the caller upload an archive instead
+func (c *client) deployManifest(base, root string, files []string, fields map[string]any, errOut io.Writer, out any) (bool, error) {
+	manifest, err := hashFiles(root, files)
+	if err != nil {
+		return false, err
+	}
+	hashes := make([]string, 0, len(manifest))
+	for _, f := range manifest {
+		hashes = append(hashes, f.SHA256)
+	}
+	var missingResp struct {
+		Missing []string `json:"missing"`
+	}
+	err = c.postJSON(base+"/blobs/missing", map[string]any{"sha256": hashes}, &missingResp)
+	var apiErr *apiError
+	if errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound {
+		fmt.Fprintln(errOut, "The server does not support manifest deployments, uploading an archive")
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	missing := make(map[string]bool, len(missingResp.Missing))
+	for _, hash := range missingResp.Missing {
+		missing[hash] = true
+	}
+	var uploads []manifestFile
+	var total int64
+	for _, f := range manifest {
+		if !missing[f.SHA256] {
+			continue
+		}
+		if f.Size > blobMaxSize {
+			fmt.Fprintf(errOut, "%s is larger than %s, uploading an archive\n", f.Path, formatBytes(blobMaxSize))
+			return false, nil
+		}
+		delete(missing, f.SHA256)
+		uploads = append(uploads, f)
+		total += f.Size
+	}
+	fmt.Fprintf(errOut, "%d of %d files changed (%s)\n", len(uploads), len(manifest), formatBytes(total))
+	if len(uploads) > 0 {
+		progress := newProgress(errOut, total)
+		for _, f := range uploads {
+			if err := c.uploadBlob(base, root, f, progress); err != nil {
+				return false, fmt.Errorf("upload %s: %w", f.Path, err)
+			}
+		}
+		progress.done()
+	}
+	fields["files"] = manifest
+	if err := c.postJSON(base+"/release/manifest", fields, out); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// uploadBlob 按内容哈希上传一个文件
+// Upload one file by its content hash
+func (c *client) uploadBlob(base, root string, f manifestFile, progress *progress) error {
+	file, err := os.Open(filepath.Join(root, filepath.FromSlash(f.Path)))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return c.do(http.MethodPut, base+"/blobs/"+f.SHA256, progress.reader(file), "application/octet-stream", f.Size, nil)
+}
@@ -0,0 +1,360 @@
+package cli
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func deployCmd() *cobra.Command {
+	var (
+		projectID uint
+		siteID    uint
+		tag       string
+		preview   string
+		ttl       int
+		publishAt string
+		expiresAt string
+		expire    string
+		dryRun    bool
+		noDedupe  bool
+		async     bool
+	)
+	cmd := &cobra.Command{
+		Use:   "deploy [DIR]",
+		Short: "Upload a directory as a new deployment of a site",
+		Long: "Upload DIR (the current directory by default) as a new version of the site, skipping paths matched by " + ignoreFile + ". " +
+			"Only files the server does not store yet are uploaded; with --no-dedupe the directory is packed into a zip and uploaded whole. " +
+			"With --async the server publishes the upload in a background job whose progress is shown live. " +
+			"With --preview the upload becomes a preview deployment; with --publish-at it goes live at the given time and with --expires-at it is rolled back or taken offline when it expires.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := "."
+			if len(args) == 1 {
+				dir = args[0]
+			}
+			if tag == "" {
+				tag = "cli-" + time.Now().Format("20060102-150405")
+			}
+			out, errOut := cmd.OutOrStdout(), cmd.ErrOrStderr()
+			files, root, err := collectFiles(dir)
+			if err != nil {
+				return err
+			}
+			if dryRun {
+				for _, f := range files {
+					fmt.Fprintln(out, f)
+				}
+				return nil
+			}
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+			var resp struct {
+				Job struct {
+					ID uint `json:"id"`
+				} `json:"job"`
+				Release struct {
+					ID        uint       `json:"id"`
+					Tag       string     `json:"tag"`
+					Status    string     `json:"status"`
+					PublishAt *time.Time `json:"publish_at"`
+					Findings  []struct {
+						Path      string `json:"path"`
+						Signature string `json:"signature"`
+					} `json:"scan_findings"`
+				} `json:"release"`
+				Preview struct {
+					Host string `json:"host"`
+				} `json:"preview"`
+			}
+			base := fmt.Sprintf("/project/%d/site/%d", projectID, siteID)
+			deployed := false
+			// 清单部署没有后台模式，--async 时上传整个压缩包 Manifest deployments have no background mode, --async uploads the whole zip
+			if !noDedupe && !async {
+				fields := map[string]any{"tag": tag}
+				if preview != "" {
+					fields["preview"] = preview
+					fields["ttl"] = ttl
+				}
+				for k, v := range scheduleFields(publishAt, expiresAt, expire) {
+					fields[k] = v
+				}
+				if deployed, err = c.deployManifest(base, root, files, fields, errOut, &resp); err != nil {
+					return fmt.Errorf("deploy: %w", err)
+				}
+			}
+			if !deployed {
+				archive, err := packFiles(root, files)
+				if err != nil {
+					return err
+				}
+				defer os.Remove(archive)
+				info, err := os.Stat(archive)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(errOut, "Packed %d files (%s)\n", len(files), formatBytes(info.Size()))
+				fields := map[string]string{"tag": tag}
+				if preview != "" {
+					fields["preview"] = preview
+					fields["ttl"] = strconv.Itoa(ttl)
+				}
+				for k, v := range scheduleFields(publishAt, expiresAt, expire) {
+					fields[k] = v
+				}
+				if async {
+					fields["async"] = "true"
+				}
+				if err := c.upload(base+"/release", fields, archive, newProgress(errOut, info.Size()), &resp); err != nil {
+					return fmt.Errorf("upload: %w", err)
+				}
+				if async {
+					if err := c.waitDeploy(base, resp.Job.ID, errOut, &resp); err != nil {
+						return err
+					}
+				}
+			}
+			if resp.Release.Status == "quarantined" {
+				fmt.Fprintf(out, "Uploaded %s as release %d, quarantined for review by an administrator\n", resp.Release.Tag, resp.Release.ID)
+				for _, finding := range resp.Release.Findings {
+					fmt.Fprintf(errOut, "  %s: %s\n", finding.Path, finding.Signature)
+				}
+				return nil
+			}
+			if resp.Release.Status == "awaiting_approval" {
+				fmt.Fprintf(out, "Uploaded %s as release %d, awaiting approval by a project maintainer\n", resp.Release.Tag, resp.Release.ID)
+				return nil
+			}
+			if resp.Release.PublishAt != nil {
+				fmt.Fprintf(out, "Scheduled %s as release %d, going live at %s\n", resp.Release.Tag, resp.Release.ID, resp.Release.PublishAt.Local().Format(time.RFC3339))
+				return nil
+			}
+			fmt.Fprintf(out, "Deployed %s as release %d\n", resp.Release.Tag, resp.Release.ID)
+			if resp.Preview.Host != "" {
+				fmt.Fprintf(out, "Preview: https://%s/\n", resp.Preview.Host)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().UintVarP(&projectID, "project", "p", 0, "project ID")
+	cmd.Flags().UintVarP(&siteID, "site", "s", 0, "site ID")
+	cmd.Flags().StringVarP(&tag, "tag", "t", "", "version tag, cli-<timestamp> by default")
+	cmd.Flags().StringVar(&preview, "preview", "", "deploy as a preview with this branch or pull request name")
+	cmd.Flags().IntVar(&ttl, "ttl", 0, "preview lifetime in seconds, the server default when 0")
+	cmd.Flags().StringVar(&publishAt, "publish-at", "", "RFC 3339 time the deployment goes live at, right away when empty")
+	cmd.Flags().StringVar(&expiresAt, "expires-at", "", "RFC 3339 time the deployment expires at")
+	cmd.Flags().StringVar(&expire, "expire-action", "", "what happens on expiry, rollback (default) or offline")
+	cmd.Flags().BoolVar(&async, "async", false, "publish in a background job on the server and show its progress")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "only list the files that would be uploaded")
+	cmd.Flags().BoolVar(&noDedupe, "no-dedupe", false, "upload the whole directory as a zip instead of only the changed files")
+	_ = cmd.MarkFlagRequired("project")
+	_ = cmd.MarkFlagRequired("site")
+	return cmd
+}
+
+// waitDeploy 跟随后台发布任务的事件流输出服务端的处理进度，任务成功后将结果解码到 out
+// Follow the event stream of a background deploy job printing the server-side progress, decoding the outcome into out once it succeeded
+func (c *client) waitDeploy(base string, jobID uint, errOut io.Writer, out any) error {
+	var finished *struct {
+		Status string          `json:"status"`
+		Error  string          `json:"error"`
+		Result json.RawMessage `json:"result"`
+	}
+	stage, step := "", int64(-1)
+	err := c.events(fmt.Sprintf("%s/jobs/%d/events", base, jobID), func(event string, data []byte) error {
+		switch event {
+		case "progress":
+			var p struct {
+				Stage    string `json:"stage"`
+				Progress int64  `json:"progress"`
+				Total    int64  `json:"total"`
+			}
+			if err := json.Unmarshal(data, &p); err != nil || p.Stage == "" {
+				return nil
+			}
+			if p.Stage != stage {
+				stage, step = p.Stage, -1
+			}
+			if p.Total <= 0 {
+				if step < 0 {
+					step = 0
+					fmt.Fprintf(errOut, "Server: %s\n", p.Stage)
+				}
+				return nil
+			}
+			// 每 10% 输出一行 One line every 10%
+			if current := p.Progress * 10 / p.Total; current > step {
+				step = current
+				fmt.Fprintf(errOut, "Server: %s %d%%\n", p.Stage, current*10)
+			}
+		case "done":
+			return json.Unmarshal(data, &finished)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("follow job %d: %w", jobID, err)
+	}
+	if finished == nil {
+		return fmt.Errorf("follow job %d: the event stream ended before the job finished", jobID)
+	}
+	if finished.Status != "succeeded" {
+		return fmt.Errorf("deploy job %d %s: %s", jobID, finished.Status, finished.Error)
+	}
+	return json.Unmarshal(finished.Result, out)
+}
+
+// scheduleFields 定时上线和到期参数，未指定的参数不发送
+// Go-live and expiry parameters, unset ones are left out
+func scheduleFields(publishAt, expiresAt, action string) map[string]string {
+	fields := map[string]string{}
+	if publishAt != "" {
+		fields["publish_at"] = publishAt
+	}
+	if expiresAt != "" {
+		fields["expires_at"] = expiresAt
+	}
+	if action != "" {
+		fields["expire_action"] = action
+	}
+	return fields
+}
+
+// collectFiles 列出目录中需要上传的普通文件，跳过被排除的路径和符号链接
+// List the regular files of a directory to upload, skipping excluded paths and symlinks
+func collectFiles(dir string) (files []string, root string, err error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, "", err
+	}
+	if !info.IsDir() {
+		return nil, "", fmt.Errorf("%s is not a directory", dir)
+	}
+	rules, err := loadIgnore(dir)
+	if err != nil {
+		return nil, "", err
+	}
+	err = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil || rel == "." {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rules.Ignored(rel, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Type().IsRegular() {
+			files = append(files, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	if len(files) == 0 {
+		return nil, "", errors.New("nothing to deploy, the directory is empty or fully ignored")
+	}
+	return files, dir, nil
+}
+
+// packFiles 将目录中的文件打包为临时 zip 文件
+// Pack the files of a directory into a temporary zip file
+func packFiles(root string, files []string) (string, error) {
+	tmp, err := os.CreateTemp("", "spage-deploy-*.zip")
+	if err != nil {
+		return "", err
+	}
+	defer tmp.Close()
+	writer := zip.NewWriter(tmp)
+	for _, rel := range files {
+		if err := addZipFile(writer, root, rel); err != nil {
+			_ = os.Remove(tmp.Name())
+			return "", err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", err
+	}
+	return tmp.Name(), tmp.Close()
+}
+
+func addZipFile(writer *zip.Writer, root, rel string) error {
+	file, err := os.Open(filepath.Join(root, filepath.FromSlash(rel)))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	header.Name = rel
+	header.Method = zip.Deflate
+	entry, err := writer.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(entry, file)
+	return err
+}
+
+// upload 以 multipart 表单流式上传文件，请求体长度预先计算，无需将文件读入内存
+// Stream a file as a multipart form, computing the body length up front so the file is never read into memory
+func (c *client) upload(path string, fields map[string]string, filePath string, progress *progress, out any) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	head := &bytes.Buffer{}
+	form := multipart.NewWriter(head)
+	for k, v := range fields {
+		if err := form.WriteField(k, v); err != nil {
+			return err
+		}
+	}
+	if _, err := form.CreateFormFile("file", "site.zip"); err != nil {
+		return err
+	}
+	headLen := head.Len()
+	if err := form.Close(); err != nil {
+		return err
+	}
+	// Close 写入的结束边界放在文件内容之后 The closing boundary written by Close goes after the file content
+	tail := append([]byte{}, head.Bytes()[headLen:]...)
+	head.Truncate(headLen)
+	body := io.MultiReader(head, progress.reader(file), bytes.NewReader(tail))
+	size := int64(head.Len()) + info.Size() + int64(len(tail))
+	err = c.do(http.MethodPost, path, body, form.FormDataContentType(), size, out)
+	progress.done()
+	return err
+}
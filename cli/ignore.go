@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ignoreFile 部署目录中列出排除规则的文件，语法与 .gitignore 相同的子集
+// File in the deployed directory listing exclusion rules, a subset of the .gitignore syntax
+const ignoreFile = ".spageignore"
+
+// ignoreRule 一条排除规则
+// A single exclusion rule
+type ignoreRule struct {
+	pattern  string // 去掉修饰后的模式 Pattern without modifiers
+	negate   bool   // 以 ! 开头，重新包含之前排除的路径 Starts with !, re-including previously excluded paths
+	dirOnly  bool   // 以 / 结尾，只匹配目录 Ends with /, only matches directories
+	anchored bool   // 包含 /，相对部署目录匹配 Contains /, matched relative to the deployed directory
+}
+
+// ignoreRules 按顺序应用的排除规则，后面的规则优先
+// Exclusion rules applied in order, later rules win
+type ignoreRules []ignoreRule
+
+// defaultIgnores 总是排除的路径
+// Paths that are always excluded
+var defaultIgnores = []string{".git/", ".spageignore", ".DS_Store"}
+
+// loadIgnore 读取目录中的 .spageignore，文件不存在时只使用默认规则
+// Read the .spageignore of a directory, only the default rules apply when it does not exist
+func loadIgnore(dir string) (ignoreRules, error) {
+	rules := parseIgnore(defaultIgnores)
+	file, err := os.Open(filepath.Join(dir, ignoreFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return rules, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return append(rules, parseIgnore(lines)...), nil
+}
+
+func parseIgnore(lines []string) (rules ignoreRules) {
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rule := ignoreRule{}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if strings.Contains(line, "/") {
+			rule.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		rule.pattern = line
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// Ignored 判断相对路径是否被排除，路径使用 / 分隔
+// Check whether a slash separated relative path is excluded
+func (r ignoreRules) Ignored(rel string, isDir bool) bool {
+	ignored := false
+	for _, rule := range r {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if rule.match(rel) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+func (r ignoreRule) match(rel string) bool {
+	if !r.anchored {
+		// 不含 / 的模式匹配任意层级的名称 Patterns without a slash match the name at any depth
+		ok, _ := path.Match(r.pattern, path.Base(rel))
+		return ok
+	}
+	return matchSegments(strings.Split(r.pattern, "/"), strings.Split(rel, "/"))
+}
+
+// matchSegments 逐段匹配，** 匹配零个或多个目录
+// Match segment by segment, ** matches zero or more directories
+func matchSegments(pattern, segments []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(segments); i++ {
+				if matchSegments(pattern[1:], segments[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segments) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+			return false
+		}
+		pattern, segments = pattern[1:], segments[1:]
+	}
+	return len(segments) == 0
+}
@@ -0,0 +1,38 @@
+package cli
+
+import "testing"
+
+func TestIgnored(t *testing.T) {
+	rules := append(parseIgnore(defaultIgnores), parseIgnore([]string{
+		"# comment",
+		"*.map",
+		"!keep.map",
+		"node_modules/",
+		"/drafts",
+		"docs/**/internal",
+	})...)
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{".git", true, true},
+		{".spageignore", false, true},
+		{"index.html", false, false},
+		{"js/app.js.map", false, true},
+		{"js/keep.map", false, false},
+		{"node_modules", true, true},
+		{"node_modules", false, false},
+		{"a/node_modules", true, true},
+		{"drafts", true, true},
+		{"blog/drafts", true, false},
+		{"docs/internal", true, true},
+		{"docs/v1/api/internal", true, true},
+		{"docs/v1/public", true, false},
+	}
+	for _, tt := range tests {
+		if got := rules.Ignored(tt.path, tt.isDir); got != tt.want {
+			t.Errorf("Ignored(%q, %v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+		}
+	}
+}
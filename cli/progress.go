@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// progress 上传进度输出，终端中原地刷新，重定向到文件或 CI 日志时每 10% 输出一行
+// Upload progress output, refreshed in place on terminals and printed every 10% when redirected to files or CI logs
+type progress struct {
+	out      io.Writer
+	total    int64
+	sent     atomic.Int64
+	tty      bool
+	started  time.Time
+	lastDraw time.Time
+	lastStep int64
+}
+
+func newProgress(out io.Writer, total int64) *progress {
+	p := &progress{out: out, total: total, started: time.Now()}
+	if file, ok := out.(*os.File); ok {
+		if info, err := file.Stat(); err == nil {
+			p.tty = info.Mode()&os.ModeCharDevice != 0
+		}
+	}
+	return p
+}
+
+// reader 包装读取器，读取时更新进度
+// Wrap a reader, updating the progress as it is read
+func (p *progress) reader(r io.Reader) io.Reader {
+	return &progressReader{r: r, p: p}
+}
+
+func (p *progress) add(n int) {
+	sent := p.sent.Add(int64(n))
+	if p.total <= 0 {
+		return
+	}
+	if p.tty {
+		if time.Since(p.lastDraw) < 100*time.Millisecond && sent < p.total {
+			return
+		}
+		p.lastDraw = time.Now()
+		fmt.Fprintf(p.out, "\rUploading %s / %s (%d%%) %s/s   ", formatBytes(sent), formatBytes(p.total), sent*100/p.total, formatBytes(p.rate(sent)))
+		return
+	}
+	if step := sent * 10 / p.total; step > p.lastStep {
+		p.lastStep = step
+		fmt.Fprintf(p.out, "Uploading %s / %s (%d%%)\n", formatBytes(sent), formatBytes(p.total), step*10)
+	}
+}
+
+// done 结束进度输出，上传完成后服务端还需校验和发布
+// Finish the progress output, the server still validates and publishes after the upload
+func (p *progress) done() {
+	if p.tty {
+		fmt.Fprintln(p.out)
+	}
+	fmt.Fprintf(p.out, "Uploaded %s in %s\n", formatBytes(p.sent.Load()), time.Since(p.started).Round(100*time.Millisecond))
+}
+
+func (p *progress) rate(sent int64) int64 {
+	elapsed := time.Since(p.started).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return int64(float64(sent) / elapsed)
+}
+
+type progressReader struct {
+	r io.Reader
+	p *progress
+}
+
+func (r *progressReader) Read(b []byte) (int, error) {
+	n, err := r.r.Read(b)
+	r.p.add(n)
+	return n, err
+}
+
+// formatBytes 以二进制单位格式化字节数
+// Format a byte count with binary units
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,249 @@
+package cli
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+type project struct {
+	ID          uint    `json:"id"`
+	Name        string  `json:"name"`
+	DisplayName *string `json:"display_name"`
+	OwnerType   string  `json:"owner_type"`
+	OwnerID     uint    `json:"owner_id"`
+	Visibility  string  `json:"visibility"`
+}
+
+type site struct {
+	ID        uint    `json:"id"`
+	Name      string  `json:"name"`
+	SubDomain *string `json:"sub_domain"`
+}
+
+func projectCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "project",
+		Short: "Manage projects",
+	}
+	cmd.AddCommand(projectListCmd(), projectCreateCmd(), projectExportCmd())
+	return cmd
+}
+
+func projectListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List your projects",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+			u, err := c.currentUser()
+			if err != nil {
+				return err
+			}
+			var resp struct {
+				Projects []project `json:"projects"`
+			}
+			if err := c.getJSON(fmt.Sprintf("/user/%d/projects", u.ID), &resp); err != nil {
+				return err
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tNAME\tOWNER\tVISIBILITY")
+			for _, p := range resp.Projects {
+				fmt.Fprintf(w, "%d\t%s\t%s/%d\t%s\n", p.ID, p.Name, p.OwnerType, p.OwnerID, p.Visibility)
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func projectCreateCmd() *cobra.Command {
+	var (
+		displayName string
+		description string
+		orgID       uint
+		visibility  string
+	)
+	cmd := &cobra.Command{
+		Use:   "create NAME",
+		Short: "Create a project owned by you or an organization",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+			req := map[string]any{"name": args[0], "description": description}
+			if displayName != "" {
+				req["display_name"] = displayName
+			}
+			if visibility != "" {
+				req["visibility"] = visibility
+			}
+			if orgID != 0 {
+				req["owner_type"], req["owner_id"] = "organization", orgID
+			} else {
+				u, err := c.currentUser()
+				if err != nil {
+					return err
+				}
+				req["owner_type"], req["owner_id"] = "user", u.ID
+			}
+			var resp struct {
+				Project project `json:"project"`
+			}
+			if err := c.postJSON("/project", req, &resp); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Created project %s (id %d)\n", resp.Project.Name, resp.Project.ID)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&displayName, "display-name", "", "display name")
+	cmd.Flags().StringVar(&description, "description", "", "description")
+	cmd.Flags().UintVar(&orgID, "org", 0, "ID of the owning organization, owned by you when omitted")
+	cmd.Flags().StringVar(&visibility, "visibility", "", "public or private")
+	return cmd
+}
+
+func projectExportCmd() *cobra.Command {
+	var (
+		output    string
+		siteID    uint
+		releaseID uint
+	)
+	cmd := &cobra.Command{
+		Use:   "export ID",
+		Short: "Export the settings and deployed content of a project as a tar.gz",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.ParseUint(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid project ID %q", args[0])
+			}
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+			query := url.Values{}
+			if siteID != 0 {
+				query.Set("site_id", strconv.FormatUint(uint64(siteID), 10))
+			}
+			if releaseID != 0 {
+				query.Set("release_id", strconv.FormatUint(uint64(releaseID), 10))
+			}
+			reqPath := fmt.Sprintf("/project/%d/export", id)
+			if len(query) > 0 {
+				reqPath += "?" + query.Encode()
+			}
+			if output == "" {
+				output = fmt.Sprintf("project-%d-%s.tar.gz", id, time.Now().Format("20060102150405"))
+			}
+			file, err := os.Create(output)
+			if err != nil {
+				return err
+			}
+			if err := c.download(reqPath, file); err != nil {
+				_ = file.Close()
+				_ = os.Remove(output)
+				return err
+			}
+			if err := file.Close(); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Exported project %d to %s\n", id, output)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", "", "file to write, project-<id>-<time>.tar.gz by default")
+	cmd.Flags().UintVar(&siteID, "site", 0, "only export this site")
+	cmd.Flags().UintVar(&releaseID, "release", 0, "export this version of --site instead of the active one")
+	return cmd
+}
+
+func siteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "site",
+		Short: "Manage sites of a project",
+	}
+	cmd.AddCommand(siteListCmd(), siteCreateCmd())
+	return cmd
+}
+
+func siteListCmd() *cobra.Command {
+	var projectID uint
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the sites of a project",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+			var resp struct {
+				Sites []site `json:"sites"`
+			}
+			if err := c.getJSON(fmt.Sprintf("/project/%d/sites", projectID), &resp); err != nil {
+				return err
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tNAME\tSUBDOMAIN")
+			for _, s := range resp.Sites {
+				subDomain := ""
+				if s.SubDomain != nil {
+					subDomain = *s.SubDomain
+				}
+				fmt.Fprintf(w, "%d\t%s\t%s\n", s.ID, s.Name, subDomain)
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().UintVarP(&projectID, "project", "p", 0, "project ID")
+	_ = cmd.MarkFlagRequired("project")
+	return cmd
+}
+
+func siteCreateCmd() *cobra.Command {
+	var (
+		projectID   uint
+		subDomain   string
+		description string
+	)
+	cmd := &cobra.Command{
+		Use:   "create NAME",
+		Short: "Create a site in a project",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+			req := map[string]any{"name": args[0], "project_id": projectID, "description": description}
+			if subDomain != "" {
+				req["sub_domain"] = subDomain
+			}
+			var resp struct {
+				Site site `json:"site"`
+			}
+			if err := c.postJSON(fmt.Sprintf("/project/%d/site", projectID), req, &resp); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Created site %s (id %d)\n", resp.Site.Name, resp.Site.ID)
+			return nil
+		},
+	}
+	cmd.Flags().UintVarP(&projectID, "project", "p", 0, "project ID")
+	cmd.Flags().StringVar(&subDomain, "subdomain", "", "subdomain under the server's base domain")
+	cmd.Flags().StringVar(&description, "description", "", "description")
+	_ = cmd.MarkFlagRequired("project")
+	return cmd
+}
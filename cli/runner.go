@@ -0,0 +1,268 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/task"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// envRunnerToken 执行器令牌的环境变量 Environment variable of the runner token
+	envRunnerToken = "SPAGE_RUNNER_TOKEN"
+	// runnerPollInterval 没有可领取的构建时再次领取的间隔
+	// Interval before claiming again when no build was claimable
+	runnerPollInterval = 5 * time.Second
+	// runnerVersion 执行器上报的版本，与构建协议一起变化
+	// Version reported by the runner, changing with the build protocol
+	runnerVersion = "1"
+)
+
+// runnerBuild 服务端交给执行器的构建
+// Build handed to the runner by the server
+type runnerBuild struct {
+	ID           uint   `json:"id"`
+	Provider     string `json:"provider"`
+	RepoURL      string `json:"repo_url"`
+	Token        string `json:"token"`
+	Branch       string `json:"branch"`
+	Commit       string `json:"commit"`
+	BuildCommand string `json:"build_command"`
+	BuildImage   string `json:"build_image"`
+	OutputDir    string `json:"output_dir"`
+}
+
+// buildRunner 远程构建执行器，按并发数领取构建，在本机拉取和构建后上传结果
+// Remote build runner claiming builds up to its concurrency, fetching and building them on this host and uploading the results
+type buildRunner struct {
+	client      *client
+	labels      []string
+	concurrency int
+	logger      *log.Logger
+
+	mu      sync.Mutex
+	running map[uint]context.CancelFunc // 正在执行的构建 Builds in progress
+}
+
+func runnerCmd() *cobra.Command {
+	var labels []string
+	var concurrency int
+	var sandbox, image string
+	var allowPrivate bool
+	cmd := &cobra.Command{
+		Use:   "runner",
+		Short: "Run the builds of git push deployments for a Spage server",
+		Long: "Run the builds of git push deployments for a Spage server with git.runners enabled. " +
+			"The runner token is created by an administrator and read from --token or $" + envRunnerToken + ". " +
+			"Builds run on this host, inside --sandbox when given.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			server := firstNonEmpty(flagServer, os.Getenv(envServer))
+			token := firstNonEmpty(flagToken, os.Getenv(envRunnerToken))
+			if server == "" || token == "" {
+				return errors.New("--server and --token are required")
+			}
+			if !strings.HasPrefix(token, constants.RunnerTokenPrefix) {
+				return fmt.Errorf("runner tokens start with %s, ask an administrator to create a runner", constants.RunnerTokenPrefix)
+			}
+			if concurrency < 1 {
+				return errors.New("--concurrency must be at least 1")
+			}
+			// 构建沿用服务端的构建实现，由执行器的参数代替服务端配置
+			// Builds reuse the server's build code, with the runner's flags in place of the server config
+			config.GitBuildEnable = true
+			config.GitBuildSandbox = sandbox
+			config.GitBuildImage = image
+			config.GitAllowPrivate = allowPrivate
+			config.GitConcurrency = concurrency
+			r := &buildRunner{
+				client: &client{
+					server: strings.TrimRight(server, "/"),
+					token:  token,
+					http: &http.Client{Transport: &http.Transport{
+						Proxy:                 http.ProxyFromEnvironment,
+						ResponseHeaderTimeout: 10 * time.Minute,
+						IdleConnTimeout:       time.Minute,
+					}},
+				},
+				labels:      labels,
+				concurrency: concurrency,
+				logger:      log.New(cmd.ErrOrStderr(), "", log.LstdFlags),
+				running:     map[uint]context.CancelFunc{},
+			}
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			return r.run(ctx)
+		},
+	}
+	cmd.Flags().StringSliceVar(&labels, "labels", nil, "labels of this runner, builds asking for labels only go to runners having all of them")
+	cmd.Flags().IntVar(&concurrency, "concurrency", 1, "number of builds run at the same time")
+	cmd.Flags().StringVar(&sandbox, "sandbox", "", "sandbox prefix of build commands, {dir} is replaced with the work directory and {image} with the build image")
+	cmd.Flags().StringVar(&image, "image", "", "default build image replacing {image} when the project names none")
+	cmd.Flags().BoolVar(&allowPrivate, "allow-private", false, "allow fetching repositories from internal addresses or over plain http")
+	return cmd
+}
+
+// run 注册执行器，之后持续心跳和领取构建，直到 ctx 结束；结束时取消正在执行的构建
+// Register the runner, then keep sending heartbeats and claiming builds until ctx is done; builds in progress are cancelled at the end
+func (r *buildRunner) run(ctx context.Context) error {
+	var registered struct {
+		Runner struct {
+			Name string `json:"name"`
+		} `json:"runner"`
+		HeartbeatInterval int `json:"heartbeat_interval"`
+		BuildTimeout      int `json:"build_timeout"`
+	}
+	err := r.client.postJSON("/runner/register", map[string]any{
+		"labels":      r.labels,
+		"concurrency": r.concurrency,
+		"version":     runnerVersion,
+	}, &registered)
+	if err != nil {
+		return fmt.Errorf("register runner: %w", err)
+	}
+	if registered.BuildTimeout > 0 {
+		config.GitBuildTimeout = registered.BuildTimeout
+	}
+	r.logger.Printf("Runner %s registered on %s with labels %v, running up to %d builds", registered.Runner.Name, r.client.server, r.labels, r.concurrency)
+
+	var workers sync.WaitGroup
+	for range r.concurrency {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			r.work(ctx)
+		}()
+	}
+	r.heartbeat(ctx, time.Duration(max(registered.HeartbeatInterval, 1))*time.Second)
+	workers.Wait()
+	return nil
+}
+
+// heartbeat 按间隔发送心跳，放弃服务端不再认为在执行的构建，直到 ctx 结束
+// Send heartbeats at the interval and abandon builds the server no longer considers running, until ctx is done
+func (r *buildRunner) heartbeat(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		var resp struct {
+			Running []uint `json:"running"`
+		}
+		if err := r.client.postJSON("/runner/heartbeat", map[string]any{}, &resp); err != nil {
+			r.logger.Printf("Heartbeat failed: %v", err)
+			continue
+		}
+		r.mu.Lock()
+		for id, cancel := range r.running {
+			if !slices.Contains(resp.Running, id) {
+				r.logger.Printf("Build %d is no longer running on the server, abandoning it", id)
+				cancel()
+			}
+		}
+		r.mu.Unlock()
+	}
+}
+
+// work 循环领取并执行构建，直到 ctx 结束
+// Claim and run builds in a loop until ctx is done
+func (r *buildRunner) work(ctx context.Context) {
+	for ctx.Err() == nil {
+		var resp struct {
+			Build *runnerBuild `json:"build"`
+		}
+		err := r.client.postJSON("/runner/builds/claim", map[string]any{}, &resp)
+		if err != nil {
+			r.logger.Printf("Claim failed: %v", err)
+		}
+		if err != nil || resp.Build == nil {
+			select {
+			case <-ctx.Done():
+			case <-time.After(runnerPollInterval):
+			}
+			continue
+		}
+		r.build(ctx, resp.Build)
+	}
+}
+
+// build 执行一个构建并上报结果，构建被放弃时不再上报
+// Run one build and report the outcome, nothing is reported once the build was abandoned
+func (r *buildRunner) build(ctx context.Context, build *runnerBuild) {
+	base := "/runner/builds/" + strconv.FormatUint(uint64(build.ID), 10)
+	buildCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	r.mu.Lock()
+	r.running[build.ID] = cancel
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		delete(r.running, build.ID)
+		r.mu.Unlock()
+	}()
+
+	r.logger.Printf("Building %s (%s) as build %d", build.RepoURL, firstNonEmpty(build.Commit, build.Branch), build.ID)
+	result, err := task.RunGitBuild(buildCtx, &task.GitBuild{
+		Provider:     build.Provider,
+		RepoURL:      build.RepoURL,
+		Token:        build.Token,
+		Branch:       build.Branch,
+		Commit:       build.Commit,
+		BuildCommand: build.BuildCommand,
+		BuildImage:   build.BuildImage,
+		OutputDir:    build.OutputDir,
+		OnLog: func(output string) {
+			if err := r.client.putJSON(base+"/log", map[string]string{"log": output}, nil); err != nil {
+				r.logger.Printf("Failed to report the output of build %d: %v", build.ID, err)
+			}
+		},
+	})
+	if buildCtx.Err() != nil && ctx.Err() == nil {
+		// 服务端已放弃构建 The server gave up on the build
+		if result != nil {
+			result.Cleanup()
+		}
+		return
+	}
+	if err != nil {
+		reason := err.Error()
+		if ctx.Err() != nil {
+			reason = "the runner shut down"
+		}
+		commit, output := "", ""
+		if result != nil {
+			commit, output = result.Commit, result.Log
+		}
+		r.logger.Printf("Build %d failed: %s", build.ID, reason)
+		if err := r.client.postJSON(base+"/fail", map[string]string{"commit": commit, "error": reason, "log": output}, nil); err != nil {
+			r.logger.Printf("Failed to report the failure of build %d: %v", build.ID, err)
+		}
+		return
+	}
+	defer result.Cleanup()
+	fields := map[string]string{"commit": result.Commit, "log": result.Log}
+	if err := r.client.upload(base+"/result", fields, result.Archive, newProgress(io.Discard, 0), nil); err != nil {
+		r.logger.Printf("Failed to upload the result of build %d: %v", build.ID, err)
+		return
+	}
+	r.logger.Printf("Build %d of %s at %s uploaded", build.ID, build.RepoURL, result.Commit)
+}
@@ -0,0 +1,466 @@
+// openapi-gen 从 router/router.go 的路由定义和 handlers 的注释生成 openapi/operations_gen.go
+// openapi-gen generates openapi/operations_gen.go from the route definitions in router/router.go and the comments of handlers
+//
+// 在 openapi 目录下通过 go generate 运行 Run through go generate in the openapi directory
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// routeMethods 生成文档的 HTTP 方法 HTTP methods included in the document
+var routeMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE"}
+
+// route 路由定义中的一条路由 A route of the route definitions
+type route struct {
+	Method  string
+	Path    string
+	Summary string
+	Handler string // 处理函数，例如 User.Login Handler, e.g. User.Login
+	Auth    bool
+	Admin   bool
+}
+
+// group 路由分组 Route group
+type group struct {
+	parent string
+	prefix string
+	auth   bool
+	admin  bool
+}
+
+// handlerDoc 处理函数的注释、请求体类型和查询参数 Comment, request body type and query parameters of a handler
+type handlerDoc struct {
+	doc     string
+	request string
+	query   []string
+	calls   []string
+}
+
+func main() {
+	root := flag.String("root", "..", "repository root")
+	out := flag.String("out", "operations_gen.go", "output file")
+	flag.Parse()
+
+	routes, err := parseRoutes(filepath.Join(*root, "router", "router.go"))
+	if err != nil {
+		logrus.Fatalf("failed to parse routes: %v", err)
+	}
+	handlers, structs, fieldDocs, err := parseHandlers(filepath.Join(*root, "handlers"))
+	if err != nil {
+		logrus.Fatalf("failed to parse handlers: %v", err)
+	}
+	for _, dir := range []string{"models", "constants"} {
+		_, _, docs, err := parseHandlers(filepath.Join(*root, dir))
+		if err != nil {
+			logrus.Fatalf("failed to parse %s: %v", dir, err)
+		}
+		for key, doc := range docs {
+			fieldDocs[key] = doc
+		}
+	}
+	source, err := render(routes, handlers, structs, fieldDocs)
+	if err != nil {
+		logrus.Fatalf("failed to render operations: %v", err)
+	}
+	if err := os.WriteFile(*out, source, 0o644); err != nil {
+		logrus.Fatalf("failed to write %s: %v", *out, err)
+	}
+}
+
+// stringLit 返回字符串字面量的值 Return the value of a string literal
+func stringLit(expr ast.Expr) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	value, err := strconv.Unquote(lit.Value)
+	return value, err == nil
+}
+
+// parseRoutes 解析分组和路由，注释取自路由所在行的行尾注释
+// Parse groups and routes, the summary is the trailing comment on the line of the route
+func parseRoutes(path string) ([]route, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	comments := map[int]string{}
+	for _, cg := range file.Comments {
+		comments[fset.Position(cg.Pos()).Line] = strings.TrimSpace(strings.TrimPrefix(cg.List[0].Text, "//"))
+	}
+	groups := map[string]*group{"H": {}}
+	var routes []route
+	ast.Inspect(file, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.AssignStmt:
+			// x := y.Group("/prefix")
+			if len(n.Lhs) != 1 || len(n.Rhs) != 1 {
+				return true
+			}
+			name, ok := n.Lhs[0].(*ast.Ident)
+			call, isCall := n.Rhs[0].(*ast.CallExpr)
+			if !ok || !isCall {
+				return true
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok || sel.Sel.Name != "Group" || len(call.Args) == 0 {
+				return true
+			}
+			parent, ok := sel.X.(*ast.Ident)
+			prefix, isLit := stringLit(call.Args[0])
+			if ok && isLit {
+				groups[name.Name] = &group{parent: parent.Name, prefix: prefix}
+			}
+		case *ast.CallExpr:
+			sel, ok := n.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			recv, ok := sel.X.(*ast.Ident)
+			if !ok || groups[recv.Name] == nil {
+				return true
+			}
+			if sel.Sel.Name == "Use" {
+				// x.Use(middle.Auth.UseAuth()) 或 x.Use(middle.Auth.IsAdmin())
+				for _, arg := range n.Args {
+					text := exprString(arg)
+					groups[recv.Name].auth = groups[recv.Name].auth || strings.Contains(text, "Auth.UseAuth")
+					groups[recv.Name].admin = groups[recv.Name].admin || strings.Contains(text, "Auth.IsAdmin")
+				}
+				return true
+			}
+			if !slices.Contains(routeMethods, sel.Sel.Name) || len(n.Args) < 2 {
+				return true
+			}
+			suffix, ok := stringLit(n.Args[0])
+			if !ok {
+				return true
+			}
+			handler := handlerName(n.Args[len(n.Args)-1])
+			if handler == "" {
+				return true
+			}
+			r := route{Method: sel.Sel.Name, Handler: handler, Summary: comments[fset.Position(n.End()).Line]}
+			r.Path, r.Auth, r.Admin = resolve(groups, recv.Name)
+			r.Path += suffix
+			if strings.HasPrefix(r.Path, "/api/") {
+				routes = append(routes, r)
+			}
+		}
+		return true
+	})
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return slices.Index(routeMethods, routes[i].Method) < slices.Index(routeMethods, routes[j].Method)
+	})
+	return routes, nil
+}
+
+// resolve 返回分组的完整前缀以及是否需要登录和管理员权限
+// Return the full prefix of a group and whether it requires sign-in and admin rights
+func resolve(groups map[string]*group, name string) (prefix string, auth, admin bool) {
+	for g := groups[name]; g != nil; g = groups[g.parent] {
+		prefix = g.prefix + prefix
+		auth, admin = auth || g.auth, admin || g.admin
+		if g.parent == "" {
+			break
+		}
+	}
+	return prefix, auth || admin, admin
+}
+
+// handlerName 将 handlers.User.Login 转换为 User.Login，不是 handlers 中的处理函数时返回空
+// Convert handlers.User.Login to User.Login, empty when the expression is not a handler in handlers
+func handlerName(expr ast.Expr) string {
+	text := exprString(expr)
+	name, ok := strings.CutPrefix(text, "handlers.")
+	if !ok {
+		return ""
+	}
+	return name
+}
+
+func exprString(expr ast.Expr) string {
+	var buf bytes.Buffer
+	_ = format.Node(&buf, token.NewFileSet(), expr)
+	return buf.String()
+}
+
+// parseHandlers 解析目录中的处理函数注释、请求体和查询参数，以及结构体字段的注释
+// Parse the comments, request bodies and query parameters of the handlers in a directory, and the comments of struct fields
+func parseHandlers(dir string) (handlers map[string]*handlerDoc, structs map[string]bool, fieldDocs map[string]string, err error) {
+	fset := token.NewFileSet()
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	pkg := filepath.Base(dir)
+	vars := map[string]string{} // var User = UserApi{}
+	methods := map[string]*handlerDoc{}
+	structs, fieldDocs = map[string]bool{}, map[string]string{}
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		for _, decl := range file.Decls {
+			switch d := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					switch s := spec.(type) {
+					case *ast.ValueSpec:
+						if len(s.Names) == 1 && len(s.Values) == 1 {
+							if lit, ok := s.Values[0].(*ast.CompositeLit); ok {
+								if ident, ok := lit.Type.(*ast.Ident); ok {
+									vars[s.Names[0].Name] = ident.Name
+								}
+							}
+						}
+					case *ast.TypeSpec:
+						st, ok := s.Type.(*ast.StructType)
+						if !ok {
+							continue
+						}
+						structs[s.Name.Name] = true
+						for _, field := range st.Fields.List {
+							doc := field.Comment.Text()
+							if doc == "" {
+								doc = field.Doc.Text()
+							}
+							for _, name := range field.Names {
+								if doc := strings.TrimSpace(doc); doc != "" {
+									fieldDocs[pkg+"."+s.Name.Name+"."+name.Name] = strings.Join(strings.Fields(doc), " ")
+								}
+							}
+						}
+					}
+				}
+			case *ast.FuncDecl:
+				key := d.Name.Name
+				if d.Recv != nil && len(d.Recv.List) == 1 {
+					recv := d.Recv.List[0].Type
+					if star, ok := recv.(*ast.StarExpr); ok {
+						recv = star.X
+					}
+					if ident, ok := recv.(*ast.Ident); ok {
+						key = ident.Name + "." + key
+					}
+				}
+				doc := inspectHandler(d)
+				// 去掉注释开头的函数名 Drop the function name leading the comment
+				doc.doc = strings.TrimPrefix(doc.doc, d.Name.Name+" ")
+				methods[key] = doc
+			}
+		}
+	}
+	handlers = map[string]*handlerDoc{}
+	for key, doc := range methods {
+		handlers[key] = doc
+	}
+	// 将 var User = UserApi{} 的方法以 User.Login 的名称登记 Register methods of var User = UserApi{} under names like User.Login
+	for name, typ := range vars {
+		for key, doc := range methods {
+			if method, ok := strings.CutPrefix(key, typ+"."); ok {
+				handlers[name+"."+method] = doc
+			}
+		}
+	}
+	// 合并调用的辅助方法中的请求体和查询参数 Merge request bodies and query parameters of called helpers
+	for _, doc := range handlers {
+		seen := map[*handlerDoc]bool{doc: true}
+		queue := slices.Clone(doc.calls)
+		for len(queue) > 0 {
+			callee := handlers[queue[0]]
+			queue = queue[1:]
+			if callee == nil || seen[callee] {
+				continue
+			}
+			seen[callee] = true
+			if doc.request == "" {
+				doc.request = callee.request
+			}
+			for _, q := range callee.query {
+				if !slices.Contains(doc.query, q) {
+					doc.query = append(doc.query, q)
+				}
+			}
+			queue = append(queue, callee.calls...)
+		}
+	}
+	for _, doc := range handlers {
+		if doc.request != "" && !structs[doc.request] {
+			doc.request = ""
+		}
+	}
+	return handlers, structs, fieldDocs, nil
+}
+
+// typeName 返回 T、*T、T{} 或 &T{} 中的类型名 Return the type name of T, *T, T{} or &T{}
+func typeName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.StarExpr:
+		return typeName(e.X)
+	case *ast.UnaryExpr:
+		return typeName(e.X)
+	case *ast.CompositeLit:
+		if e.Type != nil {
+			return typeName(e.Type)
+		}
+	}
+	return ""
+}
+
+// inspectHandler 收集注释、绑定的请求体类型（没有绑定时取第一个 *Req 类型）和读取的查询参数
+// Collect the comment, the bound request type (the first *Req type when nothing is bound) and the query parameters read
+func inspectHandler(fn *ast.FuncDecl) *handlerDoc {
+	doc := &handlerDoc{doc: strings.TrimSpace(fn.Doc.Text())}
+	addQuery := func(names ...string) {
+		for _, name := range names {
+			if !slices.Contains(doc.query, name) {
+				doc.query = append(doc.query, name)
+			}
+		}
+	}
+	if fn.Body == nil {
+		return doc
+	}
+	locals := map[string]string{}
+	var bound, fallback string
+	ast.Inspect(fn.Body, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.AssignStmt:
+			for i, lhs := range n.Lhs {
+				if ident, ok := lhs.(*ast.Ident); ok && i < len(n.Rhs) {
+					if name := typeName(n.Rhs[i]); name != "" {
+						locals[ident.Name] = name
+					}
+				}
+			}
+		case *ast.CompositeLit:
+			if name := typeName(n); fallback == "" && strings.HasSuffix(name, "Req") {
+				fallback = name
+			}
+		case *ast.ValueSpec:
+			if name := typeName(n.Type); name != "" {
+				for _, ident := range n.Names {
+					locals[ident.Name] = name
+				}
+				if fallback == "" && strings.HasSuffix(name, "Req") {
+					fallback = name
+				}
+			}
+		case *ast.CallExpr:
+			switch fun := n.Fun.(type) {
+			case *ast.SelectorExpr:
+				switch fun.Sel.Name {
+				case "BindJSON", "BindAndValidate", "Bind", "BindQuery", "BindForm":
+					if len(n.Args) > 0 && bound == "" {
+						bound = locals[argName(n.Args[0])]
+					}
+				case "Query", "DefaultQuery":
+					if len(n.Args) > 0 {
+						if name, ok := stringLit(n.Args[0]); ok {
+							addQuery(name)
+						}
+					}
+				case "GetPageLimit":
+					addQuery("page", "limit")
+				default:
+					if recv, ok := fun.X.(*ast.Ident); ok {
+						doc.calls = append(doc.calls, recv.Name+"."+fun.Sel.Name)
+					}
+				}
+			case *ast.Ident:
+				if fun.Name == "getListQuery" {
+					addQuery("page", "limit", "sort")
+				} else if (fun.Name == "bind" || fun.Name == "bindJSON") && len(n.Args) > 1 {
+					// bind(c, &req) 与 c.BindAndValidate(&req) 相同 The same as c.BindAndValidate(&req)
+					if bound == "" {
+						bound = locals[argName(n.Args[1])]
+					}
+				} else {
+					doc.calls = append(doc.calls, fun.Name)
+				}
+			}
+		}
+		return true
+	})
+	doc.request = bound
+	if doc.request == "" {
+		doc.request = fallback
+	}
+	return doc
+}
+
+// argName 绑定参数 req 或 &req 的变量名 Name of the variable in a bound argument req or &req
+func argName(arg ast.Expr) string {
+	arg = ast.Unparen(arg)
+	if unary, ok := arg.(*ast.UnaryExpr); ok {
+		arg = unary.X
+	}
+	if ident, ok := arg.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// render 生成 operations_gen.go 的源码 Render the source of operations_gen.go
+func render(routes []route, handlers map[string]*handlerDoc, structs map[string]bool, fieldDocs map[string]string) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by cmd/openapi-gen from router/router.go and handlers; DO NOT EDIT.\n\n")
+	buf.WriteString("package openapi\n\nimport (\n\t\"reflect\"\n\n\t\"github.com/LiteyukiStudio/spage/handlers\"\n)\n\n")
+	buf.WriteString("var operations = []operation{\n")
+	for _, r := range routes {
+		doc := handlers[r.Handler]
+		if doc == nil {
+			doc = &handlerDoc{}
+		}
+		// 路由没有注释时使用处理函数的注释 Fall back to the handler comment when the route has none
+		if r.Summary == "" {
+			r.Summary = strings.ReplaceAll(doc.doc, "\n", " ")
+		}
+		fmt.Fprintf(&buf, "\t{Method: %q, Path: %q, ID: %q, Summary: %q, Description: %q, Auth: %t, Admin: %t",
+			r.Method, r.Path, r.Handler, r.Summary, doc.doc, r.Auth, r.Admin)
+		if len(doc.query) > 0 {
+			fmt.Fprintf(&buf, ", Query: %#v", doc.query)
+		}
+		if doc.request != "" && structs[doc.request] {
+			fmt.Fprintf(&buf, ", Request: reflect.TypeOf(handlers.%s{})", doc.request)
+		}
+		buf.WriteString("},\n")
+	}
+	buf.WriteString("}\n\nvar fieldDocs = map[string]string{\n")
+	keys := make([]string, 0, len(fieldDocs))
+	for key := range fieldDocs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&buf, "\t%q: %q,\n", key, fieldDocs[key])
+	}
+	buf.WriteString("}\n")
+	return format.Source(buf.Bytes())
+}
@@ -1,13 +1,46 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/accesslog"
+	"github.com/LiteyukiStudio/spage/cache"
+	"github.com/LiteyukiStudio/spage/certs"
 	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/handlers"
+	"github.com/LiteyukiStudio/spage/keyring"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/ratelimit"
 	"github.com/LiteyukiStudio/spage/router"
+	"github.com/LiteyukiStudio/spage/storage"
 	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/task"
+	"github.com/LiteyukiStudio/spage/tracing"
 	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
 )
 
 func main() {
+	// 子命令：spage migrate --to N / spage backup --file F / spage restore --file F [--force] / spage seed --users N --projects M
+	// Subcommands: spage migrate --to N / spage backup --file F / spage restore --file F [--force] / spage seed --users N --projects M
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			if err := command(); err != nil {
+				logrus.Fatalf("%s failed: %v", os.Args[1], err)
+			}
+			return
+		}
+	}
+
 	logrus.Info("Starting page server...")
 
 	// 第一步初始化配置文件
@@ -16,16 +49,319 @@ func main() {
 		return
 	}
 
+	// 加载敏感字段的加密密钥
+	if err := keyring.Init(); err != nil {
+		logrus.Panicf("failed to load encryption keys: %v", err)
+		return
+	}
+
+	// 初始化链路追踪
+	if err := tracing.Init(); err != nil {
+		logrus.Panicf("failed to init tracing: %v", err)
+		return
+	}
+
+	// 初始化文件存储
+	if err := storage.Init(); err != nil {
+		logrus.Panicf("failed to init storage: %v", err)
+		return
+	}
+
+	// 初始化缓存
+	if err := cache.Init(); err != nil {
+		logrus.Panicf("failed to init cache: %v", err)
+		return
+	}
+
+	// 初始化限流
+	if err := ratelimit.Init(); err != nil {
+		logrus.Panicf("failed to init rate limiter: %v", err)
+		return
+	}
+
+	// 初始化访问日志导出
+	if err := accesslog.Init(); err != nil {
+		logrus.Panicf("failed to init access log: %v", err)
+		return
+	}
+
 	// 初始化数据相关
 	if err := store.Init(); err != nil {
 		logrus.Panicf("failed to init data store: %v", err)
 		return
 	}
 
+	// 加载管理员在运行时修改的配置项
+	if err := task.LoadSettings(); err != nil {
+		logrus.Panicf("failed to load settings: %v", err)
+		return
+	}
+
+	// 初始化 ACME 证书管理和 TLS 终止
+	if err := certs.Init(); err != nil {
+		logrus.Panicf("failed to init certificates: %v", err)
+		return
+	}
+
+	// 收到 SIGINT/SIGTERM 或平滑重启的新进程就绪时开始退出
+	// Start exiting on SIGINT/SIGTERM or once the new process of a graceful restart is ready
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if config.ServerGracefulRestart {
+		ctx = router.WatchRestart(ctx)
+	}
+	var tasks sync.WaitGroup
+	run := func(fn func(ctx context.Context)) {
+		tasks.Add(1)
+		go func() {
+			defer tasks.Done()
+			fn(ctx)
+		}()
+	}
+
+	// 清理过期的预览部署
+	run(task.CleanupPreviews)
+	run(task.CleanupUploads)
+	run(task.CleanupSessions)
+	run(task.PurgeTrash)
+	run(task.CollectGarbage)
+
+	// 汇总站点流量
+	run(task.FlushTraffic)
+	run(handlers.Bandwidth.Watch)
+
+	// 删除到期的账号
+	run(handlers.Account.Watch)
+
+	// 重新加载动态配置项
+	run(task.ReloadSettings)
+
+	// 补齐已有文件的大小
+	run(task.BackfillFileSizes)
+	run(task.BackfillFileBlobs)
+
+	// 投递 webhook 和通知，检查证书到期
+	run(task.DeliverWebhooks)
+	run(task.DeliverNotifications)
+	run(handlers.Notification.Watch)
+
+	// 重新加密读取时发现的旧密文
+	run(task.Reencrypt)
+
+	// 执行后台任务
+	handlers.RegisterJobs()
+	run(task.RunJobs)
+
+	// 检查远程构建执行器的租约
+	run(handlers.Runner.Watch)
+
+	// 按 cron 表达式运行定时任务
+	run(task.RunCron)
+
 	// TODO 创建节点检查任务 task/node_check.go
 
-	if err := router.Run(); err != nil {
+	if err := router.Run(ctx); err != nil {
 		logrus.Panicf("failed to run router: %v", err)
 		return
 	}
+	stop()
+	shutdown(&tasks)
+}
+
+// shutdown 等待后台任务退出，写完剩余的访问日志，导出剩余的 span 并关闭数据库，最多等待 server.shutdown-timeout
+// Wait for background tasks to exit, write the remaining access logs, export the remaining spans and close the database, waiting at most server.shutdown-timeout
+func shutdown(tasks *sync.WaitGroup) {
+	done := make(chan struct{})
+	go func() {
+		tasks.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Duration(config.ServerShutdownTimeout) * time.Second):
+		logrus.Warn("Background tasks did not stop in time, interrupted jobs are retried after their lease expires")
+	}
+	accesslog.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(config.ServerShutdownTimeout)*time.Second)
+	defer cancel()
+	if err := tracing.Shutdown(ctx); err != nil {
+		logrus.Warnf("failed to export remaining spans: %v", err)
+	}
+	if err := store.Close(); err != nil {
+		logrus.Warnf("failed to close database: %v", err)
+	}
+	logrus.Info("Page server stopped")
+}
+
+var commands = map[string]func() error{
+	"migrate": migrate,
+	"backup":  backup,
+	"restore": restore,
+	"seed":    seed,
+}
+
+// migrate 迁移数据库到指定版本，未指定时迁移到最新版本
+// Migrate the database to the given version, or to the latest version when not given
+func migrate() error {
+	if err := config.Init(); err != nil {
+		return err
+	}
+	if err := keyring.Init(); err != nil {
+		return err
+	}
+	if _, err := store.Connect(); err != nil {
+		return err
+	}
+	target := models.LatestVersion()
+	if to, ok := config.Cmd.GetArgsMap(os.Args[2:])["to"]; ok {
+		version, err := strconv.Atoi(to)
+		if err != nil {
+			return err
+		}
+		target = version
+	}
+	err := store.Lock.With(store.WithoutTimeout(context.Background()), store.LockBootstrap, func(db *gorm.DB) error {
+		return models.MigrateTo(db, target)
+	})
+	if err != nil {
+		return err
+	}
+	current, err := models.CurrentVersion(store.DB)
+	if err != nil {
+		return err
+	}
+	logrus.Infof("Database is at version %d (latest %d)", current, models.LatestVersion())
+	return nil
+}
+
+// backup 将数据库和站点文件导出为可移植的归档
+// Export the database and site files into a portable archive
+func backup() error {
+	if err := config.Init(); err != nil {
+		return err
+	}
+	if err := keyring.Init(); err != nil {
+		return err
+	}
+	if err := storage.Init(); err != nil {
+		return err
+	}
+	if _, err := store.Connect(); err != nil {
+		return err
+	}
+	path, ok := config.Cmd.GetArgsMap(os.Args[2:])["file"]
+	if !ok {
+		path = "spage-backup-" + time.Now().Format("20060102150405") + ".zip"
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := store.Backup.Dump(file); err != nil {
+		_ = file.Close()
+		_ = os.Remove(path)
+		return err
+	}
+	logrus.Infof("Backup written to %s", path)
+	return file.Close()
+}
+
+// restore 从归档恢复数据库和站点文件
+// Restore the database and site files from an archive
+func restore() error {
+	if err := config.Init(); err != nil {
+		return err
+	}
+	if err := keyring.Init(); err != nil {
+		return err
+	}
+	if err := storage.Init(); err != nil {
+		return err
+	}
+	if _, err := store.Connect(); err != nil {
+		return err
+	}
+	args := config.Cmd.GetArgsMap(os.Args[2:])
+	path, ok := args["file"]
+	if !ok {
+		return errors.New("--file is required")
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	stat, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	manifest, err := store.Backup.Restore(file, stat.Size(), slices.Contains(os.Args[2:], "--force") || args["force"] == "true")
+	if err != nil {
+		return err
+	}
+	logrus.Infof("Restored backup from %s (%s, schema version %d)", manifest.CreatedAt.Format(time.RFC3339), manifest.Driver, manifest.SchemaVersion)
+	return nil
+}
+
+// seed 为开发实例生成演示用户、组织、项目和部署
+// Populate a development instance with demo users, organizations, projects and deployments
+func seed() error {
+	args := config.Cmd.GetArgsMap(os.Args[2:])
+	opts := handlers.SeedOptions{Password: "spage-demo", Seed: uint64(time.Now().UnixNano())}
+	if password, ok := args["password"]; ok {
+		opts.Password = password
+	}
+	var err error
+	for _, arg := range []struct {
+		name   string
+		value  *int
+		preset int
+	}{
+		{"users", &opts.Users, 10},
+		{"orgs", &opts.Orgs, -1},
+		{"projects", &opts.Projects, 20},
+		{"releases", &opts.Releases, 2},
+		{"posts", &opts.Posts, 5},
+	} {
+		*arg.value = arg.preset
+		if value, ok := args[arg.name]; ok {
+			if *arg.value, err = strconv.Atoi(value); err != nil || *arg.value < 0 {
+				return fmt.Errorf("--%s must be a non-negative number", arg.name)
+			}
+		}
+	}
+	// 默认每 5 个用户一个组织 One organization per 5 users by default
+	if opts.Orgs < 0 {
+		opts.Orgs = (opts.Users + 4) / 5
+	}
+	if value, ok := args["seed"]; ok {
+		if opts.Seed, err = strconv.ParseUint(value, 10, 64); err != nil {
+			return errors.New("--seed must be a number")
+		}
+	}
+
+	if err := config.Init(); err != nil {
+		return err
+	}
+	if err := keyring.Init(); err != nil {
+		return err
+	}
+	if err := storage.Init(); err != nil {
+		return err
+	}
+	if err := cache.Init(); err != nil {
+		return err
+	}
+	if err := store.Init(); err != nil {
+		return err
+	}
+	if err := task.LoadSettings(); err != nil {
+		return err
+	}
+	result, err := handlers.Seed.Run(context.Background(), opts)
+	if result != nil {
+		logrus.Infof("Seeded %d users, %d organizations, %d projects and %d deployments, users sign in with password %q",
+			result.Users, result.Orgs, result.Projects, result.Releases, opts.Password)
+	}
+	return err
 }
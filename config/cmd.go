@@ -1,6 +1,9 @@
 package config
 
-import "os"
+import (
+	"os"
+	"strings"
+)
 
 type CmdUtilsType struct{}
 
@@ -12,15 +15,17 @@ func (CmdUtilsType) GetArgsSlice() []string {
 	return os.Args[1:]
 }
 
-// GetArgsMap 输入--mode=dev --port=8080时，解析为map[string]string{"mode":"dev", "port":"8080"}
-// When inputting --mode=dev --port=8080, it is parsed as map[string]string{"mode":"dev", "port":"8080"}
+// GetArgsMap 输入--mode=dev --port=8080时，解析为map[string]string{"mode":"dev", "port":"8080"}，也支持--to 3的形式
+// When inputting --mode=dev --port=8080, it is parsed as map[string]string{"mode":"dev", "port":"8080"}, the --to 3 form is also supported
 func (CmdUtilsType) GetArgsMap(args []string) map[string]string {
 	argsMap := make(map[string]string)
-	for _, arg := range args {
+	for i, arg := range args {
 		if len(arg) > 2 && arg[:2] == "--" {
 			parts := splitArg(arg)
-			if len(parts) == 2 {
+			if parts[0] != "" {
 				argsMap[parts[0][2:]] = parts[1]
+			} else if i+1 < len(args) && !strings.HasPrefix(args[i+1], "--") {
+				argsMap[arg[2:]] = args[i+1]
 			}
 		}
 	}
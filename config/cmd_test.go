@@ -27,3 +27,17 @@ func TestCmdUtilsType_GetArgsMap(t *testing.T) {
 		}
 	}
 }
+
+// TestCmdUtilsType_GetArgsMapSeparated 测试以空格分隔的参数值
+// Test argument values separated by a space
+func TestCmdUtilsType_GetArgsMapSeparated(t *testing.T) {
+	args := []string{"migrate", "--to", "2", "--mode=dev", "--verbose"}
+	result := CmdUtilsType{}.GetArgsMap(args)
+
+	if result["to"] != "2" || result["mode"] != "dev" {
+		t.Errorf("Unexpected result: %v", result)
+	}
+	if _, ok := result["verbose"]; ok {
+		t.Errorf("Flag without value should be ignored, got %v", result)
+	}
+}
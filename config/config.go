@@ -3,33 +3,85 @@ package config
 import (
 	"embed"
 	"errors"
+	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
 
 	"github.com/LiteyukiStudio/spage/constants"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 )
 
-const ()
+const (
+	// envPrefix 覆盖配置项的环境变量前缀，例如 SPAGE_DATABASE__HOST 覆盖 database.host
+	// Prefix of environment variables overriding configuration items, e.g. SPAGE_DATABASE__HOST overrides database.host
+	envPrefix = "SPAGE"
+)
+
+// envReserved 同样以 SPAGE_ 开头但不是配置项的环境变量，用于 CLI、平滑重启和构建
+// Environment variables that also start with SPAGE_ but are not configuration items, used by the CLI, graceful restarts and builds
+var envReserved = []string{"SPAGE_SERVER", "SPAGE_TOKEN", "SPAGE_READY_FD", "SPAGE_COMMIT", "SPAGE_BRANCH"}
 
 var (
 	ServerPort string
 	// 服务器端口 Server Port
 
+	ServerTrustedProxies = []string{"127.0.0.0/8", "::1/128", "10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "fc00::/7"}
+	// 受信任的反向代理地址或 CIDR 网段，只有来自这些地址的请求才会采用 X-Forwarded-For 和 X-Real-IP 中的客户端地址
+	// Addresses or CIDR ranges of trusted reverse proxies, only requests from them have the client address taken from X-Forwarded-For and X-Real-IP
+
+	ServerShutdownTimeout = 30
+	// 退出时等待进行中的请求和后台任务完成的最长时间，单位秒
+	// Longest time to wait for in-flight requests and background jobs when exiting, in seconds
+
+	ServerReusePort = false
+	// 是否以 SO_REUSEPORT 监听，允许新实例在旧实例退出前绑定同一端口
+	// Whether to listen with SO_REUSEPORT, letting a new instance bind the same port before the old one exits
+
+	ServerGracefulRestart = false
+	// 是否在收到 SIGUSR2 时启动新进程接替服务，新进程就绪后旧进程平滑退出，需要 SO_REUSEPORT
+	// Whether SIGUSR2 starts a new process that takes over serving, the old one exits gracefully once the new one is ready, requires SO_REUSEPORT
+
+	ServerMaxBodySize = 4
+	// 上传接口以外的请求体大小上限，单位 MiB
+	// Largest request body of endpoints other than uploads, in MiB
+
 	Mode = constants.ModeProd
 	// 运行模式，支持dev和prod
 	// Running Mode, support dev and prod
 
+	Language = "en"
+	// 接口消息和邮件的默认语言，请求没有 Accept-Language 且用户未设置语言时使用，可选 en 或 zh-cn
+	// Default language of API messages and emails, used when a request has no Accept-Language and the user set no language, en or zh-cn
+
 	JwtSecret string
 	// JWT密钥 JWT Secret
 
 	FrontEndURL string
 	// 前端URL Frontend URL
 
+	CorsAllowedOrigins []string
+	// 允许跨域调用接口并携带 Cookie 的来源，例如第三方面板，frontend.url 总是允许；支持 https://*.example.com 形式的通配，* 允许任意来源但浏览器不会携带 Cookie
+	// Origins allowed to call the API cross-origin with cookies, e.g. third-party dashboards, frontend.url is always allowed;
+	// wildcards like https://*.example.com are supported and * allows any origin though browsers send no cookies then
+
+	CSRFEnable = true
+	// 是否要求使用 Cookie 登录的浏览器请求在修改数据时于 X-CSRF-Token 请求头中带上 csrf_token Cookie 的值
+	// Whether cookie-authenticated browser requests changing data have to repeat the csrf_token cookie in the X-CSRF-Token header
+
 	LogLevel = "info"
 	// 日志级别 Log Level
 
+	LogFormat = "text"
+	// 日志格式，text 或 json
+	// Log format, text or json
+
 	AdminUsername = "admin"
 	// 管理员用户名 Admin Username
 
@@ -43,26 +95,95 @@ var (
 	EmailPort     string // 邮箱服务器端口 Email Server Port
 	EmailPassword string // 邮箱密码 Email Password
 	EmailSSL      bool   // 是否启用SSL Enable SSL
+	EmailFromName string // 发件人名称 Sender display name
+
+	EmailVerifyTTL = 3600 * 24 * 3
+	// 邮箱验证链接的有效期，单位秒
+	// How long email verification links stay valid, in seconds
+
+	EmailResetTTL = 3600
+	// 密码重置链接的有效期，单位秒
+	// How long password reset links stay valid, in seconds
+
+	EmailRequireVerification = false
+	// 是否要求验证邮箱后才能使用密码登录，需要启用邮件发送
+	// Whether the email has to be verified before signing in with a password, needs email sending enabled
 
 	PageLimit int = 40
 	// 每页显示的文章数量，默认为40
 	// Number of articles displayed per page, default 40
 
 	CaptchaType = constants.CaptchaTypeDisable
-	// 验证码类型，支持turnstile、recaptcha和hcaptcha
-	// Captcha Type, support turnstile, recaptcha and hcaptcha
+	// 验证码类型，支持turnstile、recaptcha、hcaptcha和内置算术题math
+	// Captcha Type, support turnstile, recaptcha, hcaptcha and the built-in math challenge
 
 	CaptchaSiteKey   string // reCAPTCHA v3的站点密钥
 	CaptchaSecretKey string // reCAPTCHA v3的密钥
 	CaptchaUrl       string // for mcaptcha
 
-	TokenExpireTime = 3600 * 24
-	// session过期时间，单位秒
-	// session expiration time, in seconds
+	TokenExpireTime = 60 * 15
+	// 访问令牌过期时间，单位秒，过期后通过刷新令牌续期
+	// Access token expiration time in seconds, renewed through the refresh token once expired
 
 	RefreshTokenExpireTime = 3600 * 144
-	// 刷新token过期时间，单位秒
-	// refresh token expiration time, in seconds
+	// 登录会话和刷新令牌的过期时间，单位秒
+	// Expiration time of sign-in sessions and their refresh tokens, in seconds
+
+	ImpersonationExpireTime = 3600
+	// 管理员代为登录会话的最长有效期，单位秒
+	// Longest lifetime of sessions where an administrator impersonates a user, in seconds
+
+	LoginBackoffAfter = 3
+	// 同一账号或 IP 连续登录失败多少次后开始指数退避，等待从 1 秒起每次失败翻倍
+	// Failed logins of one account or IP before exponential backoff starts, the wait starts at 1 second and doubles with every failure
+
+	LoginBackoffMax = 300
+	// 退避的最长等待，单位秒
+	// Longest backoff wait in seconds
+
+	LoginLockoutThreshold = 10
+	// 账号连续登录失败多少次后临时锁定，0 为不锁定
+	// Failed logins after which an account is locked temporarily, 0 never locks
+
+	LoginLockoutDuration = 900
+	// 账号锁定的时长，单位秒，管理员可提前解锁
+	// How long an account stays locked in seconds, administrators may unlock it earlier
+
+	LoginFailureWindow = 3600
+	// 最近一次失败超过该时间后重新计数，单位秒
+	// Failures are counted afresh once the latest one is older than this, in seconds
+
+	LoginCaptchaAfter = 0
+	// 启用验证码时，同一账号或 IP 连续失败多少次后登录需要验证码，0 为总是需要
+	// With a captcha configured, failed logins of one account or IP after which signing in needs the captcha, 0 always needs it
+
+	TOTPIssuer = "Spage"
+	// 两步验证应用中显示的发行方名称
+	// Issuer name shown in two-factor authenticator apps
+
+	PasswordAlgorithm = "argon2id"
+	// 新密码的哈希算法，argon2id 或 bcrypt，已有的哈希在下次登录时按当前算法和参数重新计算
+	// Hash algorithm of new passwords, argon2id or bcrypt, existing hashes are recomputed with the current algorithm and parameters on the next login
+
+	PasswordArgon2Memory = 64 * 1024
+	// argon2id 使用的内存，单位 KiB
+	// Memory used by argon2id in KiB
+
+	PasswordArgon2Time = 3
+	// argon2id 的迭代次数
+	// Iterations of argon2id
+
+	PasswordArgon2Threads = 2
+	// argon2id 的并行度
+	// Parallelism of argon2id
+
+	PasswordBcryptCost = 12
+	// bcrypt 的计算代价
+	// Cost of bcrypt
+
+	PasswordLegacySecret string
+	// 验证旧版本密码哈希使用的密钥，默认为 token.secret；所有旧哈希都已重新计算后才能更换 token.secret 而不影响登录
+	// Secret used to verify password hashes of older versions, token.secret by default; token.secret can only be rotated without breaking logins once every old hash was recomputed
 
 	// CommitHash 构件时注入的git commit hash
 	CommitHash = "develop"
@@ -73,6 +194,583 @@ var (
 
 	ReleaseSavePath = "data/releases"
 
+	TemplateSavePath = "data/templates"
+	// 组织自定义预览图模板保存路径
+	// Save path of organization preview templates
+
+	UploadSavePath = "data/uploads"
+	// 分片上传的分片保存路径
+	// Save path of the chunks of chunked uploads
+
+	BlobSavePath = "data/blobs"
+	// 按内容哈希存储的站点文件保存路径
+	// Save path of site files stored by content hash
+
+	CompressedSavePath = "data/compressed"
+	// 预压缩的站点文件保存路径
+	// Save path of precompressed site files
+
+	AvatarSavePath = "data/avatars"
+	// 上传的用户头像和组织标志保存路径
+	// Save path of uploaded user avatars and organization logos
+
+	FileDedupe = true
+	// 是否将上传的压缩包拆分为按内容哈希存储的文件和部署清单，未变化的文件在多次部署之间只存储一份
+	// Whether uploaded archives are split into files stored by content hash plus a deployment manifest, so unchanged files are stored once across deployments
+
+	OGBrand = "Spage"
+	// 预览图中展示的实例品牌
+	// Instance branding shown on preview images
+
+	OGTimeout = 5
+	// 预览图生成超时时间，单位秒
+	// Preview image generation timeout, in seconds
+
+	OGMaxImageSize int64 = 2 << 20
+	// 头像和模板图片的最大字节数
+	// Max size in bytes of avatar and template images
+
+	AvatarGravatar = true
+	// 没有上传或设置头像时是否按邮箱使用 Gravatar
+	// Whether Gravatar is used by email when no avatar was uploaded or set
+
+	AvatarGravatarURL = "https://www.gravatar.com/avatar/"
+	// Gravatar 服务地址，可换成镜像，后接邮箱的 SHA-256
+	// Gravatar service URL, may be a mirror, followed by the SHA-256 of the email
+
+	PurgeHourlyLimit = 10
+	// 每个项目每小时允许的路径清除次数
+	// Number of path purges allowed per project per hour
+
+	PurgeMaxPaths = 50
+	// 单次路径清除允许的最大路径数
+	// Max number of paths in a single purge
+
+	ServeBaseDomain string
+	// 托管站点的基础域名，子域站点通过 <sub_domain>.<base-domain> 访问
+	// Base domain of hosted sites, subdomain sites are served at <sub_domain>.<base-domain>
+
+	ServeProjectDomain string
+	// 项目主机名的基础域名，每个项目通过泛域名解析的 <项目名>.<project-domain> 访问其主站点，应与面板和接口所在的域名分开
+	// Base domain of project hosts, each project serves its primary site at <project>.<project-domain> through wildcard DNS, kept apart from the panel and API hostname
+
+	ServeHeaders []string
+	// 实例级默认响应头，格式为 "Name: value"
+	// Instance-wide default response headers, formatted as "Name: value"
+
+	ServeCacheRules []string
+	// 实例级按路径的 Cache-Control，格式为 "<路径模式> <值>"
+	// Instance-wide Cache-Control by path, formatted as "<path pattern> <value>"
+
+	ServeMIMETypes []string
+	// 按扩展名覆盖托管站点的 Content-Type，格式为 "<扩展名> <类型>"，例如 ".glb model/gltf-binary"
+	// Content-Type overrides of hosted sites by extension, formatted as "<extension> <type>", e.g. ".glb model/gltf-binary"
+
+	ServeAllowIPs []string
+	// 实例级允许访问托管站点的地址或 CIDR 网段，为空时不限制
+	// Instance-wide addresses or CIDR ranges allowed to visit hosted sites, no restriction when empty
+
+	ServeDenyIPs []string
+	// 实例级禁止访问托管站点的地址或 CIDR 网段，优先于允许规则
+	// Instance-wide addresses or CIDR ranges refused from hosted sites, taking precedence over allow rules
+
+	ServePrivate bool
+	// 是否所有托管站点都要求登录实例账号才能访问，与站点自身的访问保护同时生效
+	// Whether every hosted site requires signing in with an account of the instance, applied on top of the site's own access protection
+
+	ServeProxy = false
+	// 是否允许 _redirects 中的 200 规则代理到外部地址，内网地址始终禁止
+	// Whether 200 rules of _redirects may proxy to external URLs, internal addresses are always refused
+
+	ServeProxyTimeout = 30
+	// 代理请求超时时间，单位秒
+	// Timeout of proxied requests, in seconds
+
+	ServeArchiveCache = 64
+	// 同时保持打开的发布压缩包数量
+	// Number of release archives kept open at the same time
+
+	ServeCompress = true
+	// 是否为文本类文件生成 Brotli 和 gzip 压缩版本，并按 Accept-Encoding 提供
+	// Whether to produce Brotli and gzip versions of text-like files and serve them according to Accept-Encoding
+
+	ServeCompressMinSize = 1024
+	// 参与压缩的最小文件字节数，更小的文件压缩收益不明显
+	// Smallest file size in bytes worth compressing, smaller files gain little
+
+	ServeCompressMaxSize = 16
+	// 参与压缩的最大文件大小，单位 MiB，压缩在内存中进行
+	// Largest file size compressed in MiB, compression happens in memory
+
+	PreviewTTL = 3600 * 24 * 7
+	// 预览部署的默认有效期，单位秒
+	// Default lifetime of preview deployments, in seconds
+
+	PreviewMaxTTL = 3600 * 24 * 30
+	// 上传时允许指定的最长预览有效期，单位秒
+	// Longest preview lifetime allowed at upload time, in seconds
+
+	PreviewCleanupInterval = 600
+	// 过期预览部署的清理间隔，单位秒
+	// Cleanup interval of expired preview deployments, in seconds
+
+	LogTailMaxPerSite = 3
+	// 每个站点允许同时进行的实时日志流数量
+	// Number of concurrent live log tails allowed per site
+
+	LogTailBufferSize = 256
+	// 每个实时日志订阅者的缓冲区大小，满时丢弃最旧的日志
+	// Buffer size of each live log subscriber, the oldest entry is dropped when full
+
+	LogTailIdleTimeout = 300
+	// 实时日志流空闲超时时间，单位秒
+	// Idle timeout of a live log tail, in seconds
+
+	SiteLogEnable = true
+	// 是否保存托管站点最近的请求日志，供站点成员按状态码、路径和时间查询
+	// Whether recent request logs of hosted sites are kept for site members to query by status, path and time
+
+	SiteLogRetention = 86400
+	// 站点请求日志的保留时间，单位秒，超过后由 analytics 定时任务删除
+	// Retention of site request logs in seconds, older ones are deleted by the analytics scheduled task
+
+	SiteLogBufferSize = 10000
+	// 等待写入数据库的站点请求日志数量上限，写入跟不上时丢弃新的日志而不阻塞请求
+	// Max site request logs waiting to be written to the database, new entries are dropped instead of blocking requests when writing falls behind
+
+	AccessLogEnable = false
+	// 是否以 Apache combined 格式导出托管站点的访问日志
+	// Whether to export the access logs of hosted sites in the Apache combined format
+
+	AccessLogOutput = "file"
+	// 访问日志的输出位置，可选 file/stdout/syslog
+	// Where access logs are written, one of file/stdout/syslog
+
+	AccessLogFormat = "combined"
+	// 访问日志格式，combined 为 Apache combined 格式，vcombined 在行首加上主机名
+	// Access log format, combined is the Apache combined format and vcombined prefixes each line with the host
+
+	AccessLogFile = "logs/access.log"
+	// 输出到文件时的路径
+	// Path of the file when writing to a file
+
+	AccessLogMaxSize = 100
+	// 日志文件轮转前的最大大小，单位 MB
+	// Max size of the log file before it is rotated, in MB
+
+	AccessLogMaxBackups = 7
+	// 保留的轮转文件数量，0 为不限制
+	// Number of rotated files kept, 0 keeps all
+
+	AccessLogMaxAge = 30
+	// 轮转文件的保留天数，0 为不按时间删除
+	// Days rotated files are kept, 0 keeps them regardless of age
+
+	AccessLogCompress = true
+	// 是否使用 gzip 压缩轮转后的文件
+	// Whether rotated files are compressed with gzip
+
+	AccessLogSyslogNetwork = ""
+	// 输出到 syslog 时的网络，可选 udp/tcp，为空时使用本机的 syslog 服务
+	// Network used for syslog, udp or tcp, the local syslog daemon is used when empty
+
+	AccessLogSyslogAddress = ""
+	// 远程 syslog 服务的地址，例如 127.0.0.1:514
+	// Address of a remote syslog server, e.g. 127.0.0.1:514
+
+	AccessLogSyslogTag = "spage"
+	// syslog 消息的标签
+	// Tag of syslog messages
+
+	AccessLogAnonymizeIP = false
+	// 是否截断访问者 IP，IPv4 保留前 24 位，IPv6 保留前 48 位
+	// Whether visitor IPs are truncated, keeping the first 24 bits of IPv4 and the first 48 bits of IPv6
+
+	AccessLogBufferSize = 4096
+	// 等待写入的日志行数上限，写入跟不上时丢弃新的日志而不阻塞请求
+	// Max log lines waiting to be written, new lines are dropped instead of blocking requests when writing falls behind
+
+	WebhookTimeout = 10
+	// 单次 webhook 投递的超时时间，单位秒
+	// Timeout of a single webhook delivery, in seconds
+
+	WebhookMaxAttempts = 6
+	// webhook 投递的最大尝试次数，超过后标记为失败
+	// Max attempts of a webhook delivery before it is marked failed
+
+	WebhookAllowPrivate = false
+	// 是否允许向内网地址投递 webhook
+	// Whether webhooks may be delivered to internal addresses
+
+	NotificationQuotaWarning = 90
+	// 存储用量达到配额的百分比时通知用户或组织的通知渠道，0 为不通知
+	// Percentage of the storage quota at which the notification channels of the user or organization are notified, 0 never notifies
+
+	NotificationCertificateWarning = 14
+	// 自定义域名的证书剩余有效期少于该天数时通知，正常情况下证书在到期前 30 天自动续期
+	// Days of validity left below which the certificate of a custom domain is notified, certificates normally renew 30 days before expiry
+
+	GitBuildEnable = false
+	// 是否允许 Git 推送部署执行构建命令，关闭时直接发布仓库中的输出目录
+	// Whether git push deployments may run build commands, the output directory of the repository is published as is when disabled
+
+	GitBuildTimeout = 600
+	// 单次拉取和构建的超时时间，单位秒
+	// Timeout of a single fetch and build, in seconds
+
+	GitBuildSandbox string
+	// 构建命令的沙箱前缀，例如 firejail、bwrap 或 docker run，{dir} 替换为工作目录，{image} 替换为构建镜像，为空时直接在本机执行
+	// Sandbox prefix of build commands such as firejail, bwrap or docker run, {dir} is replaced with the work directory and {image} with the build image, builds run directly on the host when empty
+
+	GitBuildImage string
+	// 项目未指定镜像时替换沙箱前缀中 {image} 的默认构建镜像
+	// Default build image replacing {image} in the sandbox prefix when the project names none
+
+	GitConcurrency = 2
+	// 同时进行的拉取和构建数量
+	// Number of fetches and builds running at the same time
+
+	GitAllowPrivate = false
+	// 是否允许从内网地址或 http 地址拉取仓库
+	// Whether repositories may be fetched from internal addresses or over plain http
+
+	GitRunners = false
+	// 是否将推送部署的拉取和构建交给远程执行器，开启后 API 主机不再执行构建
+	// Whether fetches and builds of push deployments are handed to remote runners, the API host no longer builds once enabled
+
+	GitRunnerTimeout = 90
+	// 远程执行器心跳的超时时间，单位秒，超时后执行器视为离线，其正在执行的构建视为失败
+	// Heartbeat timeout of remote runners in seconds, after which the runner counts as offline and its running builds as failed
+
+	UploadChunkSize = 2
+	// 分片上传的分片大小，单位 MiB
+	// Chunk size of chunked uploads in MiB
+
+	UploadMaxSize = 1024
+	// 默认的最大上传大小，单位 MiB，限制部署、导入和分片上传的压缩包，管理员可为用户、组织和项目单独设置
+	// Default largest upload in MiB, bounding the archives of deployments, imports and chunked uploads, administrators may set it per user, organization and project
+
+	UploadTTL = 3600 * 24
+	// 分片上传会话的有效期，单位秒，过期后删除已上传的分片
+	// Lifetime of chunked upload sessions in seconds, uploaded chunks are deleted afterwards
+
+	UploadMaxFiles = 100000
+	// 部署压缩包中最多的文件数，0 为不限制
+	// Most files in a deployment archive, 0 is unlimited
+
+	UploadMaxFileSize = 512
+	// 部署压缩包中单个文件解压后的最大大小，单位 MiB，0 为不限制
+	// Largest uncompressed file of a deployment archive in MiB, 0 is unlimited
+
+	UploadMaxUnpackedSize = 10240
+	// 部署压缩包全部文件解压后的最大大小，单位 MiB，0 为不限制，用于拒绝压缩炸弹
+	// Largest uncompressed total of a deployment archive in MiB, 0 is unlimited, rejecting zip bombs
+
+	WebDAVEnable = false
+	// 是否开启站点草稿的 WebDAV 接口，草稿保存在本机磁盘，多实例部署时需要共享 webdav.dir
+	// Whether the WebDAV interface of site drafts is on, drafts live on the local disk so several instances need a shared webdav.dir
+
+	WebDAVDir = "./data/webdav"
+	// 站点草稿的保存目录，每个站点一个子目录
+	// Directory site drafts are kept in, one subdirectory per site
+
+	WebDAVMaxSize = 100
+	// 单个站点草稿的最大大小，单位 MiB
+	// Largest draft of a site in MiB
+
+	QuotaMaxProjects = 0
+	// 每个用户或组织默认可创建的项目数量，0 为不限制
+	// Default number of projects each user or organization may create, 0 for unlimited
+
+	QuotaMaxDeploymentSize = 0
+	// 默认的单次部署解压后的最大大小，单位 MiB，0 为不限制
+	// Default largest uncompressed size of one deployment in MiB, 0 for unlimited
+
+	QuotaMaxStorage = 0
+	// 每个用户或组织默认可占用的存储空间，单位 MiB，0 为不限制
+	// Default storage each user or organization may occupy in MiB, 0 for unlimited
+
+	JobWorkers = 2
+	// 同时执行的后台任务数量
+	// Number of background jobs run at the same time
+
+	JobTimeout = 1800
+	// 单次执行后台任务的超时时间，单位秒，超时的任务按失败重试
+	// Timeout of one background job attempt in seconds, timed out jobs are retried like failures
+
+	JobMaxAttempts = 3
+	// 后台任务的最大尝试次数
+	// Max attempts of a background job
+
+	JobRetention = 3600 * 24 * 7
+	// 已结束的后台任务记录的保留时间，单位秒
+	// How long records of finished background jobs are kept, in seconds
+
+	GCInterval = 3600 * 24
+	// 存储垃圾回收的间隔，单位秒，0 为只在管理员手动触发时运行
+	// Interval of the storage garbage collection in seconds, 0 only runs it when an administrator triggers it
+
+	GCGrace = 3600 * 24
+	// 未被引用的存储对象至少存在多久才会被回收，单位秒，避免删除正在部署中刚上传的文件
+	// How long an unreferenced storage object must exist before it is collected in seconds, so files just uploaded for a deployment in progress are kept
+
+	CronGC string
+	// 存储垃圾回收的 cron 表达式，设置后代替 gc.interval，为空时按 gc.interval 运行
+	// Cron expression of the storage garbage collection, replacing gc.interval when set, empty runs it by gc.interval
+
+	CronAnalytics = "@hourly"
+	// 汇总流量和访问分析并清理过期统计的 cron 表达式，为空时不清理过期统计
+	// Cron expression of rolling up traffic and analytics and pruning expired statistics, empty never prunes them
+
+	CronCertificates = "@daily"
+	// 续期即将过期的 ACME 证书的 cron 表达式，为空时不自动续期
+	// Cron expression of renewing ACME certificates about to expire, empty never renews them automatically
+
+	CronBackup string
+	// 定时备份数据库的 cron 表达式，为空时不定时备份
+	// Cron expression of scheduled database backups, empty takes none
+
+	CronBackupDir = "./data/backups"
+	// 定时备份的保存目录
+	// Directory scheduled backups are written to
+
+	CronBackupKeep = 7
+	// 保留的定时备份数量，更早的备份会被删除，0 为全部保留
+	// Number of scheduled backups kept, older ones are deleted, 0 keeps all of them
+
+	CronRekey = "@daily"
+	// 用当前密钥重新加密数据库中敏感字段的 cron 表达式，为空时只在读取时逐条重新加密
+	// Cron expression of re-encrypting the sensitive columns of the database with the active key, empty only re-encrypts values one by one as they are read
+
+	CronRetention = "@daily"
+	// 按保留策略删除旧版本的 cron 表达式，为空时不删除
+	// Cron expression of removing old versions by the retention policy, empty never removes them
+
+	EncryptionActiveKey string
+	// 加密新写入的敏感字段使用的密钥ID，为空时不加密，已加密的值仍可读取
+	// ID of the key encrypting sensitive columns as they are written, nothing is encrypted when empty and values already encrypted stay readable
+
+	EncryptionKeys map[string]string
+	// 密钥ID到密钥的映射，密钥为 base64 编码的 32 字节，配置了 KMS 时为 KMS 加密后的密文；轮换时添加新密钥并改为当前密钥，旧密钥保留到重新加密完成
+	// Map of key IDs to keys, each the base64 encoding of 32 bytes or its KMS ciphertext when a KMS is configured; rotating adds a new key and makes it active, keeping the old one until everything is re-encrypted
+
+	EncryptionKMSProvider string
+	// 解密 encryption.keys 的密钥管理服务，可选 vault，为空时密钥以明文配置
+	// Key management service decrypting encryption.keys, vault or empty for keys configured in plain
+
+	EncryptionKMSAddress = "http://127.0.0.1:8200"
+	// 密钥管理服务的地址
+	// Address of the key management service
+
+	EncryptionKMSToken string
+	// 访问密钥管理服务的令牌
+	// Token for the key management service
+
+	EncryptionKMSMount = "transit"
+	// Vault transit 引擎的挂载路径
+	// Mount path of the Vault transit engine
+
+	EncryptionKMSKey = "spage"
+	// Vault transit 引擎中加密密钥的名称
+	// Name of the encryption key in the Vault transit engine
+
+	TrashRetention = 3600 * 24 * 7
+	// 已删除的项目和站点在回收站中保留的时间，单位秒，到期后彻底删除及其文件，0 为一直保留直到手动清除
+	// How long deleted projects and sites stay in the trash in seconds before they and their files are purged, 0 keeps them until purged by hand
+
+	ReleaseKeepLast int
+	// 每个站点至少保留的最新版本数，项目未单独设置时使用，0 为不按数量保留
+	// Newest versions kept per site at least where the project sets none, 0 keeps none by count
+
+	ReleaseKeepDays int
+	// 版本至少保留的天数，项目未单独设置时使用，0 为不按时间保留；两者都为 0 时不删除旧版本
+	// Days versions are kept at least where the project sets none, 0 keeps none by age; old versions are never removed when both are 0
+
+	AccountDeletionGrace = 3600 * 24 * 14
+	// 用户申请删除账号后等待的时间，单位秒，期间可以取消，到期后删除账号并将个人项目移入回收站
+	// How long an account waits after the user asked for its deletion in seconds, the request can be cancelled meanwhile and
+	// once it passes the account is deleted and its personal projects go to the trash
+
+	ProjectTransferTTL = 3600 * 24 * 7
+	// 项目转移等待接收方确认的时间，单位秒，过期后需要重新发起
+	// How long a project transfer awaits the recipient's confirmation in seconds, it must be requested again once expired
+
+	TrafficRetention = 3600 * 24 * 90
+	// 站点每日流量统计的保留时间，单位秒，0 为一直保留
+	// How long the daily traffic statistics of sites are kept in seconds, 0 keeps them forever
+
+	TrafficSoftLimit = 0
+	// 每个站点每月的流量软限制，单位 MiB，超出时通知项目的 webhook，0 为不限制
+	// Soft monthly transfer limit of each site in MiB, the project's webhooks are notified once exceeded, 0 is unlimited
+
+	TrafficHardLimit = 0
+	// 每个站点每月的流量硬限制，单位 MiB，超出后按 traffic.hard-action 处理，0 为不限制
+	// Hard monthly transfer limit of each site in MiB, handled by traffic.hard-action once exceeded, 0 is unlimited
+
+	TrafficHardAction = TransferActionThrottle
+	// 超出硬限制后的处理方式，throttle 限制响应速度，suspend 暂停提供服务直到下个月
+	// What happens beyond the hard limit, throttle slows responses down and suspend stops serving until the next month
+
+	TrafficThrottleRate = 256
+	// throttle 时每个响应的速度上限，单位 KiB/s
+	// Speed limit of each response while throttled in KiB/s
+
+	AnalyticsEnable = true
+	// 是否记录站点的页面浏览量，仅统计 GET 请求返回的 HTML 页面
+	// Whether to record page views of sites, only HTML pages returned to GET requests are counted
+
+	AnalyticsRetention = 3600 * 24 * 90
+	// 站点访问分析每日汇总的保留时间，单位秒，0 为一直保留
+	// How long the daily analytics rollups of sites are kept in seconds, 0 keeps them forever
+
+	AnalyticsCountryHeader = ""
+	// 提供访问者国家或地区代码的请求头，如 CDN 设置的 CF-IPCountry，为空时不统计地区；仅应使用可信代理设置的请求头
+	// Request header carrying the visitor's country or region code such as CF-IPCountry set by a CDN, countries are not counted when empty; only use headers set by a trusted proxy
+
+	AnalyticsMaxValues = 1000
+	// 每个站点每天每个维度最多记录的不同值数量，超出的合并为 (other)，0 为不限制
+	// Max distinct values recorded per site, day and dimension, the rest are merged into (other), 0 is unlimited
+
+	AnalyticsRespectDNT = true
+	// 是否不统计发送 DNT 或 Sec-GPC 请求头的访问者
+	// Whether visitors sending the DNT or Sec-GPC header are not counted
+
+	AnalyticsExcludeBots = true
+	// 是否不统计用户代理为爬虫的请求
+	// Whether requests whose user agent is a crawler are not counted
+
+	SiteAccessSessionTTL = 3600 * 12
+	// 访问受保护站点的登录状态有效期，单位秒，修改站点的访问设置后已有的登录状态立即失效
+	// How long a visitor stays signed in to a protected site in seconds, changing the site's access settings signs everyone out at once
+
+	SiteAccessSignedURLTTL = 3600
+	// 受保护站点签名链接的默认有效期，单位秒
+	// Default lifetime of signed URLs of protected sites in seconds
+
+	SiteAccessSignedURLMaxTTL = 3600 * 24 * 7
+	// 受保护站点签名链接的最长有效期，单位秒
+	// Longest lifetime of signed URLs of protected sites in seconds
+
+	MaintenanceMode = MaintenanceOff
+	// 实例运行模式，read-only 时拒绝接口的写请求并暂停会删除文件的后台任务，maintenance 时托管站点同时返回维护页，用于存储迁移
+	// Instance mode, read-only rejects API writes and pauses background work that deletes files, maintenance also answers hosted sites with the maintenance page, used for storage migrations
+
+	MaintenanceMessage = "This site is down for maintenance and will be back shortly."
+	// 维护页展示的说明
+	// Notice shown on the maintenance page
+
+	MaintenancePageFile string
+	// 自定义维护页的 HTML 文件，为空时使用内置页面，文件中的 {{message}} 替换为维护说明
+	// HTML file of a custom maintenance page, the built-in page is used when empty, {{message}} in the file is replaced with the notice
+
+	MaintenanceRetryAfter = 600
+	// 维护期间 503 响应的 Retry-After，单位秒，0 表示不发送
+	// Retry-After of 503 responses during maintenance in seconds, 0 sends none
+
+	PolicyBlockedExtensions []string
+	// 实例默认禁止托管的文件扩展名，不区分大小写，例如 ".exe"、".php"
+	// File extensions hosted content may not use by default, case insensitive, e.g. ".exe" or ".php"
+
+	PolicyBlockedMIMETypes []string
+	// 实例默认禁止托管的 MIME 类型，按扩展名判断，支持 "application/*" 形式的通配
+	// MIME types hosted content may not have by default, judged by extension, wildcards like "application/*" are allowed
+
+	PolicyAction = PolicyActionReject
+	// 上传中出现禁止的文件时的处理方式，reject 拒绝部署，strip 去掉这些文件后照常部署
+	// What happens when an upload contains disallowed files, reject refuses the deployment and strip deploys without them
+
+	PolicyServe = false
+	// 是否在访问时同样拒绝禁止的文件，用于策略收紧前已经部署的内容
+	// Whether disallowed files are refused when requested too, for content deployed before the policy was tightened
+
+	ScanEnable = false
+	// 是否在上线前扫描新版本的内容，发现恶意文件时隔离该版本等待管理员审核
+	// Whether the content of new versions is scanned before going live, versions with malicious files are quarantined for an administrator's review
+
+	ScanBackend = ScanBackendClamAV
+	// 扫描后端，clamav 通过 clamd 套接字扫描，http 将文件提交给外部扫描服务
+	// Scan backend, clamav scans through the clamd socket and http submits files to an external scanning service
+
+	ScanClamAVAddress = "tcp://127.0.0.1:3310"
+	// clamd 的地址，tcp://host:port 或 unix:///path/to/clamd.sock
+	// Address of clamd, tcp://host:port or unix:///path/to/clamd.sock
+
+	ScanHTTPURL string
+	// 外部扫描服务的地址，每个文件以请求体 POST 到该地址
+	// URL of the external scanning service, every file is POSTed to it as the request body
+
+	ScanHTTPToken string
+	// 请求外部扫描服务时携带的 Bearer 令牌，为空时不携带
+	// Bearer token sent to the external scanning service, none when empty
+
+	ScanTimeout = 30
+	// 扫描单个文件的超时时间，单位秒
+	// Timeout of scanning one file in seconds
+
+	ScanMaxFileSize = 25
+	// 扫描的最大文件大小，单位 MiB，更大的文件跳过，0 为全部扫描
+	// Largest file scanned in MiB, larger files are skipped, 0 scans all of them
+
+	ScanFailOpen = false
+	// 扫描失败时是否照常上线，关闭时隔离该版本等待审核
+	// Whether a version goes live as usual when scanning fails, it is quarantined for review otherwise
+
+	LinkCheckEnable = false
+	// 是否在版本上线后检查站点内的失效链接、缺失资源和过大的页面，发现问题时通知项目
+	// Whether a version is checked for broken internal links, missing assets and oversized pages after going live, the project is notified of problems
+
+	LinkCheckMaxPages = 500
+	// 每次检查最多抓取的页面数
+	// Most pages crawled by one check
+
+	LinkCheckMaxPageSize = 2048
+	// 页面及其引用的站内资源的总大小上限，单位 KiB，超出时报告为过大的页面，0 为不检查
+	// Limit in KiB on the total size of a page and the site assets it references, larger pages are reported as oversized, 0 disables the check
+
+	RegistrationInviteOnly = false
+	// 是否只允许持有邀请链接的用户注册
+	// Whether only users with an invite link may sign up
+
+	InvitationTTL = 3600 * 24 * 7
+	// 邀请链接的默认有效期，单位秒
+	// Default lifetime of invite links, in seconds
+
+	SettingsReloadInterval = 30
+	// 从数据库重新加载动态配置项的间隔，单位秒，其他实例通过管理员接口修改的配置在此间隔内生效
+	// Interval of reloading dynamic settings from the database in seconds, changes made through the admin API of another instance take effect within it
+
+	MetricsEnable = true
+	// 是否提供 Prometheus 格式的 /metrics 端点
+	// Whether to serve the Prometheus /metrics endpoint
+
+	MetricsToken string
+	// 访问 /metrics 所需的 Bearer 令牌，为空时不校验
+	// Bearer token required to scrape /metrics, not checked when empty
+
+	TracingEnable = false
+	// 是否通过 OTLP 导出 OpenTelemetry 链路追踪
+	// Whether OpenTelemetry traces are exported over OTLP
+
+	TracingEndpoint = "http://localhost:4318"
+	// OTLP/HTTP 接收地址，路径为空时使用 /v1/traces
+	// OTLP/HTTP receiver URL, /v1/traces is used when the path is empty
+
+	TracingServiceName = "spage"
+	// 上报的服务名，多个部署共用一个追踪后端时用于区分
+	// Service name reported, telling deployments apart when they share a tracing backend
+
+	TracingSampleRate = 1.0
+	// 没有上游采样决定的请求和任务的采样比例，0 到 1
+	// Share of requests and jobs without an upstream sampling decision that are sampled, 0 to 1
+
+	OpenAPIEnable = true
+	// 是否提供 /api/openapi.json 和 /api/docs
+	// Whether to serve /api/openapi.json and /api/docs
+
+	OpenAPIUIAssets = "https://unpkg.com/swagger-ui-dist@5"
+	// Swagger UI 脚本和样式的地址，内网部署时可指向自建镜像
+	// Location of the Swagger UI scripts and styles, may point to a self-hosted mirror for internal deployments
+
 	//go:embed config.example.yaml
 	configExample embed.FS
 )
@@ -120,11 +818,14 @@ func Init() error {
 	viper.AddConfigPath(".")
 	viper.SetConfigName("config")
 	viper.SetConfigType("yaml")
-	// 读取配置文件
-	// Read the configuration file
+	bindEnv()
+	// 读取配置文件，只用环境变量配置时可以没有配置文件
+	// Read the configuration file, which may be missing when only environment variables are used
 	if err := viper.ReadInConfig(); err != nil {
 		var configFileNotFoundError viper.ConfigFileNotFoundError
-		if errors.As(err, &configFileNotFoundError) {
+		if errors.As(err, &configFileNotFoundError) && hasEnvConfig() {
+			logrus.Info("Config file not found, using environment variables and defaults")
+		} else if errors.As(err, &configFileNotFoundError) {
 			err := InitConfig()
 			if err != nil {
 				return err
@@ -135,9 +836,45 @@ func Init() error {
 	// 初始化配置常量
 	// Initialize configuration constants
 	ServerPort = GetString("server.port", "8888")
+	ServerTrustedProxies = GetStringSlice("server.trusted-proxies", ServerTrustedProxies)
+	ServerShutdownTimeout = GetInt("server.shutdown-timeout", ServerShutdownTimeout)
+	ServerGracefulRestart = GetBool("server.graceful-restart", ServerGracefulRestart)
+	ServerReusePort = GetBool("server.reuse-port", ServerReusePort) || ServerGracefulRestart
+	ServerMaxBodySize = GetInt("server.max-body-size", ServerMaxBodySize)
 	FrontEndURL = GetString("frontend.url", "http://localhost:5173")
+	CorsAllowedOrigins = GetStringSlice("cors.allowed-origins", CorsAllowedOrigins)
+	CSRFEnable = GetBool("csrf.enable", CSRFEnable)
 	Mode = GetString("mode", "prod")
+	Language = GetString("language", Language)
 	LogLevel = GetString("log.level", "info")
+	LogFormat = GetString("log.format", LogFormat)
+	LogTailMaxPerSite = GetInt("log.tail.max-per-site", LogTailMaxPerSite)
+	LogTailBufferSize = GetInt("log.tail.buffer-size", LogTailBufferSize)
+	LogTailIdleTimeout = GetInt("log.tail.idle-timeout", LogTailIdleTimeout)
+	SiteLogEnable = GetBool("log.site.enable", SiteLogEnable)
+	SiteLogRetention = GetInt("log.site.retention", SiteLogRetention)
+	SiteLogBufferSize = GetInt("log.site.buffer-size", SiteLogBufferSize)
+	AccessLogEnable = GetBool("log.access.enable", AccessLogEnable)
+	AccessLogOutput = GetString("log.access.output", AccessLogOutput)
+	AccessLogFormat = GetString("log.access.format", AccessLogFormat)
+	AccessLogFile = GetString("log.access.file", AccessLogFile)
+	AccessLogMaxSize = GetInt("log.access.max-size", AccessLogMaxSize)
+	AccessLogMaxBackups = GetInt("log.access.max-backups", AccessLogMaxBackups)
+	AccessLogMaxAge = GetInt("log.access.max-age", AccessLogMaxAge)
+	AccessLogCompress = GetBool("log.access.compress", AccessLogCompress)
+	AccessLogSyslogNetwork = GetString("log.access.syslog-network", AccessLogSyslogNetwork)
+	AccessLogSyslogAddress = GetString("log.access.syslog-address", AccessLogSyslogAddress)
+	AccessLogSyslogTag = GetString("log.access.syslog-tag", AccessLogSyslogTag)
+	AccessLogAnonymizeIP = GetBool("log.access.anonymize-ip", AccessLogAnonymizeIP)
+	AccessLogBufferSize = GetInt("log.access.buffer-size", AccessLogBufferSize)
+	MetricsEnable = GetBool("metrics.enable", MetricsEnable)
+	MetricsToken = GetString("metrics.token", "")
+	TracingEnable = GetBool("tracing.enable", TracingEnable)
+	TracingEndpoint = GetString("tracing.endpoint", TracingEndpoint)
+	TracingServiceName = GetString("tracing.service-name", TracingServiceName)
+	TracingSampleRate = GetFloat64("tracing.sample-rate", TracingSampleRate)
+	OpenAPIEnable = GetBool("openapi.enable", OpenAPIEnable)
+	OpenAPIUIAssets = GetString("openapi.ui-assets", OpenAPIUIAssets)
 
 	// Admin配置项
 	// Admin configuration items
@@ -160,19 +897,269 @@ func Init() error {
 	EmailPort = GetString("email.port", "465")
 	EmailPassword = GetString("email.password", "")
 	EmailSSL = GetBool("email.ssl", true)
+	EmailFromName = GetString("email.from-name", "Spage")
+	EmailVerifyTTL = GetInt("email.verify-ttl", EmailVerifyTTL)
+	EmailResetTTL = GetInt("email.reset-ttl", EmailResetTTL)
+	EmailRequireVerification = GetBool("email.require-verification", EmailRequireVerification)
 
 	// File存储配置项
 	ReleaseSavePath = GetString("file.release-path", "data/releases")
+	TemplateSavePath = GetString("file.template-path", TemplateSavePath)
+	UploadSavePath = GetString("file.upload-path", UploadSavePath)
+	BlobSavePath = GetString("file.blob-path", BlobSavePath)
+	CompressedSavePath = GetString("file.compressed-path", CompressedSavePath)
+	AvatarSavePath = GetString("file.avatar-path", AvatarSavePath)
+	FileDedupe = GetBool("file.dedupe", FileDedupe)
+
+	// 站点服务配置项
+	// Site serving configuration items
+	ServeBaseDomain = strings.ToLower(GetString("serve.base-domain", ""))
+	ServeProjectDomain = strings.TrimSuffix(strings.ToLower(GetString("serve.project-domain", "")), ".")
+	if frontend, err := url.Parse(FrontEndURL); err == nil && ServeProjectDomain != "" && strings.HasSuffix("."+frontend.Hostname(), "."+ServeProjectDomain) {
+		logrus.Warnf("serve.project-domain %s contains the panel host %s, sites and the panel should use separate domains", ServeProjectDomain, frontend.Hostname())
+	}
+	ServeHeaders = GetStringSlice("serve.headers", ServeHeaders)
+	ServeCacheRules = GetStringSlice("serve.cache-rules", ServeCacheRules)
+	ServeMIMETypes = GetStringSlice("serve.mime-types", ServeMIMETypes)
+	ServeAllowIPs = GetStringSlice("serve.allow-ips", ServeAllowIPs)
+	ServeDenyIPs = GetStringSlice("serve.deny-ips", ServeDenyIPs)
+	ServePrivate = GetBool("serve.private", ServePrivate)
+	ServeArchiveCache = GetInt("serve.archive-cache", ServeArchiveCache)
+	ServeProxy = GetBool("serve.proxy", ServeProxy)
+	ServeProxyTimeout = GetInt("serve.proxy-timeout", ServeProxyTimeout)
+	ServeCompress = GetBool("serve.compress", ServeCompress)
+	ServeCompressMinSize = GetInt("serve.compress-min-size", ServeCompressMinSize)
+	ServeCompressMaxSize = GetInt("serve.compress-max-size", ServeCompressMaxSize)
+
+	// 预览部署配置项
+	// Preview deployment configuration items
+	PreviewTTL = GetInt("preview.ttl", PreviewTTL)
+	PreviewMaxTTL = GetInt("preview.max-ttl", PreviewMaxTTL)
+	PreviewCleanupInterval = GetInt("preview.cleanup-interval", PreviewCleanupInterval)
+
+	// Webhook配置项
+	// Webhook configuration items
+	WebhookTimeout = GetInt("webhook.timeout", WebhookTimeout)
+	WebhookMaxAttempts = GetInt("webhook.max-attempts", WebhookMaxAttempts)
+	WebhookAllowPrivate = GetBool("webhook.allow-private", WebhookAllowPrivate)
+
+	// 通知配置项
+	// Notification configuration items
+	NotificationQuotaWarning = GetInt("notification.quota-warning", NotificationQuotaWarning)
+	NotificationCertificateWarning = GetInt("notification.certificate-warning", NotificationCertificateWarning)
+
+	// Git 推送部署配置项
+	// Git push deployment configuration items
+	GitBuildEnable = GetBool("git.build-enable", GitBuildEnable)
+	GitBuildTimeout = GetInt("git.build-timeout", GitBuildTimeout)
+	GitBuildSandbox = GetString("git.build-sandbox", GitBuildSandbox)
+	GitBuildImage = GetString("git.build-image", GitBuildImage)
+	GitConcurrency = GetInt("git.concurrency", GitConcurrency)
+	GitAllowPrivate = GetBool("git.allow-private", GitAllowPrivate)
+	GitRunners = GetBool("git.runners", GitRunners)
+	GitRunnerTimeout = GetInt("git.runner-timeout", GitRunnerTimeout)
+
+	// 分片上传配置项
+	// Chunked upload configuration items
+	UploadChunkSize = GetInt("upload.chunk-size", UploadChunkSize)
+	UploadMaxSize = GetInt("upload.max-size", UploadMaxSize)
+	UploadTTL = GetInt("upload.ttl", UploadTTL)
+	UploadMaxFiles = GetInt("upload.max-files", UploadMaxFiles)
+	UploadMaxFileSize = GetInt("upload.max-file-size", UploadMaxFileSize)
+	UploadMaxUnpackedSize = GetInt("upload.max-unpacked-size", UploadMaxUnpackedSize)
+
+	// WebDAV 草稿配置项
+	// WebDAV draft configuration items
+	WebDAVEnable = GetBool("webdav.enable", WebDAVEnable)
+	WebDAVDir = GetString("webdav.dir", WebDAVDir)
+	WebDAVMaxSize = GetInt("webdav.max-size", WebDAVMaxSize)
+
+	// 配额配置项
+	// Quota configuration items
+	QuotaMaxProjects = GetInt("quota.max-projects", QuotaMaxProjects)
+	QuotaMaxDeploymentSize = GetInt("quota.max-deployment-size", QuotaMaxDeploymentSize)
+	QuotaMaxStorage = GetInt("quota.max-storage", QuotaMaxStorage)
+
+	// 后台任务配置项
+	// Background job configuration items
+	JobWorkers = GetInt("job.workers", JobWorkers)
+	JobTimeout = GetInt("job.timeout", JobTimeout)
+	JobMaxAttempts = GetInt("job.max-attempts", JobMaxAttempts)
+	JobRetention = GetInt("job.retention", JobRetention)
+
+	// 存储垃圾回收配置项
+	// Storage garbage collection configuration items
+	GCInterval = GetInt("gc.interval", GCInterval)
+	GCGrace = GetInt("gc.grace", GCGrace)
+
+	// 定时任务配置项
+	// Scheduled task configuration items
+	for key, expr := range map[string]*string{
+		"cron.gc":           &CronGC,
+		"cron.analytics":    &CronAnalytics,
+		"cron.certificates": &CronCertificates,
+		"cron.backup":       &CronBackup,
+		"cron.rekey":        &CronRekey,
+		"cron.retention":    &CronRetention,
+	} {
+		value := GetString(key, *expr)
+		if err := checkCron(value); err != nil {
+			logrus.Warnf("Ignoring %s: %v", key, err)
+			continue
+		}
+		*expr = value
+	}
+	CronBackupDir = GetString("cron.backup-dir", CronBackupDir)
+	CronBackupKeep = GetInt("cron.backup-keep", CronBackupKeep)
+
+	// 敏感字段加密配置项
+	// Sensitive column encryption configuration items
+	EncryptionActiveKey = GetString("encryption.active-key", "")
+	EncryptionKeys = viper.GetStringMapString("encryption.keys")
+	EncryptionKMSProvider = GetString("encryption.kms.provider", "")
+	EncryptionKMSAddress = GetString("encryption.kms.address", EncryptionKMSAddress)
+	EncryptionKMSToken = GetString("encryption.kms.token", "")
+	EncryptionKMSMount = GetString("encryption.kms.mount", EncryptionKMSMount)
+	EncryptionKMSKey = GetString("encryption.kms.key", EncryptionKMSKey)
+
+	// 回收站配置项
+	// Trash configuration items
+	TrashRetention = GetInt("trash.retention", TrashRetention)
+
+	// 版本保留配置项
+	// Release retention configuration items
+	ReleaseKeepLast = GetInt("release.keep-last", ReleaseKeepLast)
+	ReleaseKeepDays = GetInt("release.keep-days", ReleaseKeepDays)
+
+	// 账号配置项
+	// Account configuration items
+	AccountDeletionGrace = GetInt("account.deletion-grace", AccountDeletionGrace)
+
+	// 项目配置项
+	// Project configuration items
+	ProjectTransferTTL = GetInt("project.transfer-ttl", ProjectTransferTTL)
+
+	// 流量统计配置项
+	// Traffic statistics configuration items
+	TrafficRetention = GetInt("traffic.retention", TrafficRetention)
+	TrafficSoftLimit = GetInt("traffic.soft-limit", TrafficSoftLimit)
+	TrafficHardLimit = GetInt("traffic.hard-limit", TrafficHardLimit)
+	TrafficHardAction = GetString("traffic.hard-action", TrafficHardAction)
+	if err := checkTransferAction(TrafficHardAction); err != nil {
+		logrus.Warnf("Ignoring traffic.hard-action: %v", err)
+		TrafficHardAction = TransferActionThrottle
+	}
+	TrafficThrottleRate = GetInt("traffic.throttle-rate", TrafficThrottleRate)
+
+	// 访问分析配置项
+	// Analytics configuration items
+	AnalyticsEnable = GetBool("analytics.enable", AnalyticsEnable)
+	AnalyticsRetention = GetInt("analytics.retention", AnalyticsRetention)
+	AnalyticsCountryHeader = GetString("analytics.country-header", AnalyticsCountryHeader)
+	AnalyticsMaxValues = GetInt("analytics.max-values", AnalyticsMaxValues)
+	AnalyticsRespectDNT = GetBool("analytics.respect-dnt", AnalyticsRespectDNT)
+	AnalyticsExcludeBots = GetBool("analytics.exclude-bots", AnalyticsExcludeBots)
+
+	// 站点访问保护配置项
+	// Site access protection configuration items
+	SiteAccessSessionTTL = GetInt("site-access.session-ttl", SiteAccessSessionTTL)
+	SiteAccessSignedURLTTL = GetInt("site-access.signed-url-ttl", SiteAccessSignedURLTTL)
+	SiteAccessSignedURLMaxTTL = GetInt("site-access.signed-url-max-ttl", SiteAccessSignedURLMaxTTL)
+
+	// 维护模式配置项
+	// Maintenance mode configuration items
+	MaintenanceMode = GetString("maintenance.mode", MaintenanceMode)
+	if err := checkMaintenanceMode(MaintenanceMode); err != nil {
+		logrus.Warnf("Ignoring maintenance.mode: %v", err)
+		MaintenanceMode = MaintenanceOff
+	}
+	MaintenanceMessage = GetString("maintenance.message", MaintenanceMessage)
+	MaintenancePageFile = GetString("maintenance.page-file", MaintenancePageFile)
+	MaintenanceRetryAfter = GetInt("maintenance.retry-after", MaintenanceRetryAfter)
+
+	// 内容策略配置项
+	// Content policy configuration items
+	PolicyBlockedExtensions = GetStringSlice("policy.blocked-extensions", PolicyBlockedExtensions)
+	PolicyBlockedMIMETypes = GetStringSlice("policy.blocked-mime-types", PolicyBlockedMIMETypes)
+	PolicyAction = GetString("policy.action", PolicyAction)
+	if err := checkPolicyAction(PolicyAction); err != nil {
+		logrus.Warnf("Ignoring policy.action: %v", err)
+		PolicyAction = PolicyActionReject
+	}
+	PolicyServe = GetBool("policy.serve", PolicyServe)
+
+	// 内容扫描配置项
+	// Content scan configuration items
+	ScanEnable = GetBool("scan.enable", ScanEnable)
+	ScanBackend = GetString("scan.backend", ScanBackend)
+	if err := checkScanBackend(ScanBackend); err != nil {
+		logrus.Warnf("Ignoring scan.backend: %v", err)
+		ScanBackend = ScanBackendClamAV
+	}
+	ScanClamAVAddress = GetString("scan.clamav-address", ScanClamAVAddress)
+	ScanHTTPURL = GetString("scan.http-url", ScanHTTPURL)
+	ScanHTTPToken = GetString("scan.http-token", ScanHTTPToken)
+	ScanTimeout = GetInt("scan.timeout", ScanTimeout)
+	ScanMaxFileSize = GetInt("scan.max-file-size", ScanMaxFileSize)
+	ScanFailOpen = GetBool("scan.fail-open", ScanFailOpen)
+	LinkCheckEnable = GetBool("linkcheck.enable", LinkCheckEnable)
+	LinkCheckMaxPages = GetInt("linkcheck.max-pages", LinkCheckMaxPages)
+	LinkCheckMaxPageSize = GetInt("linkcheck.max-page-size", LinkCheckMaxPageSize)
+
+	// 注册配置项
+	// Registration configuration items
+	RegistrationInviteOnly = GetBool("registration.invite-only", RegistrationInviteOnly)
+	InvitationTTL = GetInt("registration.invite-ttl", InvitationTTL)
+
+	// 路径清除配置项
+	// Path purge configuration items
+	PurgeHourlyLimit = GetInt("purge.hourly-limit", PurgeHourlyLimit)
+	PurgeMaxPaths = GetInt("purge.max-paths", PurgeMaxPaths)
+
+	// Open-graph 预览图配置项
+	// Open-graph preview configuration items
+	OGBrand = GetString("og.brand", OGBrand)
+	OGTimeout = GetInt("og.timeout", OGTimeout)
+	OGMaxImageSize = int64(GetInt("og.max-image-size", int(OGMaxImageSize)))
+
+	// 头像配置项
+	// Avatar configuration items
+	AvatarGravatar = GetBool("avatar.gravatar", AvatarGravatar)
+	AvatarGravatarURL = GetString("avatar.gravatar-url", AvatarGravatarURL)
 
 	// 分页查询限制
 	// Pagination query limit
 	PageLimit = GetInt("page-limit", PageLimit)
 
+	// 动态配置项配置
+	// Dynamic settings configuration items
+	SettingsReloadInterval = GetInt("settings.reload-interval", SettingsReloadInterval)
+
 	// Session过期时间
 	// Session expiration time
 	TokenExpireTime = GetInt("token.expire", TokenExpireTime)
 	RefreshTokenExpireTime = GetInt("token.refresh-expire", RefreshTokenExpireTime)
+	ImpersonationExpireTime = GetInt("token.impersonation-expire", ImpersonationExpireTime)
 	JwtSecret = GetString("token.secret", "none-secret")
+	TOTPIssuer = GetString("token.totp-issuer", TOTPIssuer)
+	LoginBackoffAfter = GetInt("login.backoff-after", LoginBackoffAfter)
+	LoginBackoffMax = GetInt("login.backoff-max", LoginBackoffMax)
+	LoginLockoutThreshold = GetInt("login.lockout-threshold", LoginLockoutThreshold)
+	LoginLockoutDuration = GetInt("login.lockout-duration", LoginLockoutDuration)
+	LoginFailureWindow = GetInt("login.failure-window", LoginFailureWindow)
+	LoginCaptchaAfter = GetInt("login.captcha-after", LoginCaptchaAfter)
+
+	// 密码哈希配置项
+	// Password hashing configuration items
+	PasswordAlgorithm = GetString("password.algorithm", PasswordAlgorithm)
+	PasswordArgon2Memory = GetInt("password.argon2-memory", PasswordArgon2Memory)
+	PasswordArgon2Time = GetInt("password.argon2-time", PasswordArgon2Time)
+	PasswordArgon2Threads = GetInt("password.argon2-threads", PasswordArgon2Threads)
+	PasswordBcryptCost = GetInt("password.bcrypt-cost", PasswordBcryptCost)
+	PasswordLegacySecret = GetString("password.legacy-secret", "")
+	if PasswordLegacySecret == "" {
+		PasswordLegacySecret = JwtSecret
+	}
 
 	// 从启动参数拿取一些配置项mode frontend-url
 	// Get some configuration items from the startup parameters mode frontend-url
@@ -190,6 +1177,17 @@ func Init() error {
 			}
 		}
 	}
+	// 设置日志格式，json 便于日志系统按字段检索
+	// Set log format, json lets log systems search by field
+	switch LogFormat {
+	case "json":
+		logrus.SetFormatter(&logrus.JSONFormatter{TimestampFormat: time.RFC3339Nano})
+	case "text":
+		logrus.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
+	default:
+		logrus.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
+		logrus.Error("Invalid log format, using default format: text")
+	}
 	logrus.Info("Configuration loaded successfully, mode: ", Mode)
 
 	// 设置日志级别
@@ -202,12 +1200,49 @@ func Init() error {
 	logrus.SetLevel(logLevel)
 	logrus.Info("Log level set to: ", logLevel)
 	logrus.Debugln("LogLevel is: ", LogLevel)
+	captureSettings()
 	// 其他配置项合法校验流程
 	// Other configuration item validation process
 	// ...
 	return nil
 }
 
+// bindEnv 使每个配置项都可以被 SPAGE_ 开头的环境变量覆盖，层级用 __ 分隔，- 替换为 _，例如 SPAGE_LOG__ACCESS__MAX_SIZE 覆盖 log.access.max-size
+// Let SPAGE_ prefixed environment variables override every configuration item, levels are separated by __ and - becomes _, e.g. SPAGE_LOG__ACCESS__MAX_SIZE overrides log.access.max-size
+func bindEnv() {
+	viper.SetEnvPrefix(envPrefix)
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "__", "-", "_"))
+	viper.AutomaticEnv()
+}
+
+// hasEnvConfig 是否设置了覆盖配置项的环境变量
+// Whether any environment variable overriding a configuration item is set
+func hasEnvConfig() bool {
+	for _, env := range os.Environ() {
+		name, value, _ := strings.Cut(env, "=")
+		if strings.HasPrefix(name, envPrefix+"_") && value != "" && !slices.Contains(envReserved, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseEnvValue 将环境变量的字符串值转换为默认值的类型
+// Convert the string value of an environment variable to the type of the default value
+func parseEnvValue(value string, defaultValue any) (any, error) {
+	switch defaultValue.(type) {
+	case int:
+		return strconv.Atoi(value)
+	case int64:
+		return strconv.ParseInt(value, 10, 64)
+	case bool:
+		return strconv.ParseBool(value)
+	case float64:
+		return strconv.ParseFloat(value, 64)
+	}
+	return nil, fmt.Errorf("unsupported type %T", defaultValue)
+}
+
 // Get 返回配置项的值，如果不存在则返回默认值
 // Return the value of the configuration item, or the default value if it does not exist
 func Get[T any](key string, defaultValue T) T {
@@ -219,12 +1254,38 @@ func Get[T any](key string, defaultValue T) T {
 	if v, ok := value.(T); ok {
 		return v
 	}
+	// 环境变量的值总是字符串 Values of environment variables are always strings
+	if raw, ok := value.(string); ok {
+		parsed, err := parseEnvValue(raw, defaultValue)
+		if v, ok := parsed.(T); ok && err == nil {
+			return v
+		}
+		logrus.Warnf("Invalid value %q of %s, using default: %v", raw, key, defaultValue)
+	}
 	return defaultValue
 }
 
-// GetString 返回配置项的字符串值
-// Return the string value of the configuration item
+// readSecretFile 读取配置项的 <key>-file 指向的文件，例如 database.password-file，用于挂载的 Docker 和 Kubernetes secret，末尾的换行会被去掉
+// Read the file <key>-file of a configuration item points to, e.g. database.password-file, for mounted Docker and Kubernetes secrets; trailing newlines are removed
+func readSecretFile(key string) (string, bool) {
+	path := viper.GetString(key + "-file")
+	if path == "" {
+		return "", false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		// 不能退回到配置文件中的值或默认密码 Must not fall back to the configured value or a default password
+		logrus.Fatalf("failed to read %s-file: %v", key, err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), true
+}
+
+// GetString 返回配置项的字符串值，设置了 <key>-file 时读取该文件的内容
+// Return the string value of the configuration item, reading the file of <key>-file when it is set
 func GetString(key string, defaultValue ...string) string {
+	if secret, ok := readSecretFile(key); ok {
+		return secret
+	}
 	if len(defaultValue) > 0 {
 		return Get(key, defaultValue[0])
 	}
@@ -258,11 +1319,15 @@ func GetFloat64(key string, defaultValue ...float64) float64 {
 	return viper.GetFloat64(key)
 }
 
-// GetStringSlice 返回配置项的字符串切片值
-// Return the string slice value of the configuration item
+// GetStringSlice 返回配置项的字符串切片值，YAML 列表读取为 []any 因此需要转换而不能使用 Get
+// Return the string slice value of the configuration item, YAML lists are read as []any and have to be converted instead of going through Get
 func GetStringSlice(key string, defaultValue ...[]string) []string {
-	if len(defaultValue) > 0 {
-		return Get(key, defaultValue[0])
+	if len(defaultValue) > 0 && !viper.IsSet(key) {
+		return defaultValue[0]
+	}
+	// 环境变量中的列表以逗号或空白分隔 Lists in environment variables are separated by commas or whitespace
+	if raw, ok := viper.Get(key).(string); ok {
+		return strings.FieldsFunc(raw, func(r rune) bool { return r == ',' || unicode.IsSpace(r) })
 	}
 	return viper.GetStringSlice(key)
 }
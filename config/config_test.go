@@ -0,0 +1,69 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// TestEnvOverrides 测试 SPAGE_ 开头的环境变量覆盖配置项并转换为默认值的类型
+// Test that SPAGE_ prefixed environment variables override configuration items and convert to the type of the default
+func TestEnvOverrides(t *testing.T) {
+	bindEnv()
+	t.Setenv("SPAGE_DATABASE__HOST", "db.internal")
+	t.Setenv("SPAGE_LOG__ACCESS__MAX_SIZE", "200")
+	t.Setenv("SPAGE_METRICS__ENABLE", "false")
+	t.Setenv("SPAGE_SERVE__ALLOW_IPS", "10.0.0.0/8, 192.168.0.0/16")
+	t.Setenv("SPAGE_JOB__WORKERS", "many")
+
+	if got := GetString("database.host", "postgres"); got != "db.internal" {
+		t.Errorf("Expected database.host from the environment, got %s", got)
+	}
+	if got := GetInt("log.access.max-size", 100); got != 200 {
+		t.Errorf("Expected log.access.max-size 200, got %d", got)
+	}
+	if got := GetBool("metrics.enable", true); got {
+		t.Error("Expected metrics.enable to be overridden to false")
+	}
+	if got := GetStringSlice("serve.allow-ips", nil); !slices.Equal(got, []string{"10.0.0.0/8", "192.168.0.0/16"}) {
+		t.Errorf("Unexpected serve.allow-ips %v", got)
+	}
+	if got := GetInt("job.workers", 2); got != 2 {
+		t.Errorf("Expected an invalid job.workers to fall back to the default, got %d", got)
+	}
+	if got := GetInt("job.timeout", 1800); got != 1800 {
+		t.Errorf("Expected an unset job.timeout to keep the default, got %d", got)
+	}
+}
+
+// TestHasEnvConfig 测试 CLI 等使用的 SPAGE_ 环境变量不视为配置
+// Test that SPAGE_ variables used by the CLI and others do not count as configuration
+func TestHasEnvConfig(t *testing.T) {
+	t.Setenv("SPAGE_TOKEN", "secret")
+	if hasEnvConfig() {
+		t.Skip("Environment already contains SPAGE_ configuration")
+	}
+	t.Setenv("SPAGE_MODE", "dev")
+	if !hasEnvConfig() {
+		t.Error("Expected SPAGE_MODE to count as configuration")
+	}
+}
+
+// TestSecretFile 测试 <key>-file 指向的文件优先于配置项本身，末尾的换行被去掉
+// Test that the file of <key>-file takes precedence over the item itself and trailing newlines are removed
+func TestSecretFile(t *testing.T) {
+	bindEnv()
+	path := filepath.Join(t.TempDir(), "db_password")
+	if err := os.WriteFile(path, []byte("s3cret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SPAGE_DATABASE__PASSWORD", "plain")
+	t.Setenv("SPAGE_DATABASE__PASSWORD_FILE", path)
+	if got := GetString("database.password", "spage"); got != "s3cret" {
+		t.Errorf("Expected the password from the secret file, got %q", got)
+	}
+	if got := GetString("database.user", "spage"); got != "spage" {
+		t.Errorf("Expected database.user without a secret file to keep the default, got %q", got)
+	}
+}
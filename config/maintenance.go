@@ -0,0 +1,20 @@
+package config
+
+// 实例运行模式 Instance modes
+const (
+	MaintenanceOff      = ""            // 正常运行 Running normally
+	MaintenanceReadOnly = "read-only"   // 只读，托管站点照常提供 Read-only, hosted sites keep serving
+	MaintenanceOn       = "maintenance" // 维护中，托管站点返回维护页 Under maintenance, hosted sites answer with the maintenance page
+)
+
+// ReadOnly 是否拒绝写入，维护模式同样只读
+// Whether writes are rejected, maintenance mode is read-only as well
+func ReadOnly() bool {
+	return MaintenanceMode == MaintenanceReadOnly || MaintenanceMode == MaintenanceOn
+}
+
+// UnderMaintenance 是否处于维护模式
+// Whether the instance is under maintenance
+func UnderMaintenance() bool {
+	return MaintenanceMode == MaintenanceOn
+}
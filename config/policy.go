@@ -0,0 +1,17 @@
+package config
+
+import "fmt"
+
+// 内容策略对禁止文件的处理方式 Content policy actions for disallowed files
+const (
+	PolicyActionReject = "reject" // 拒绝整个部署 Reject the whole deployment
+	PolicyActionStrip  = "strip"  // 去掉禁止的文件后照常部署 Deploy without the disallowed files
+)
+
+func checkPolicyAction(value string) error {
+	switch value {
+	case PolicyActionReject, PolicyActionStrip:
+		return nil
+	}
+	return fmt.Errorf("must be %q or %q", PolicyActionReject, PolicyActionStrip)
+}
@@ -0,0 +1,17 @@
+package config
+
+import "fmt"
+
+// 内容扫描后端 Content scan backends
+const (
+	ScanBackendClamAV = "clamav" // 通过 clamd 套接字扫描 Scan through the clamd socket
+	ScanBackendHTTP   = "http"   // 提交给外部扫描服务 Submit to an external scanning service
+)
+
+func checkScanBackend(value string) error {
+	switch value {
+	case ScanBackendClamAV, ScanBackendHTTP:
+		return nil
+	}
+	return fmt.Errorf("must be %q or %q", ScanBackendClamAV, ScanBackendHTTP)
+}
@@ -0,0 +1,318 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/LiteyukiStudio/spage/cron"
+	"github.com/sirupsen/logrus"
+)
+
+// 动态配置项的值类型 Value types of dynamic settings
+const (
+	SettingInt    = "int"
+	SettingBool   = "bool"
+	SettingString = "string"
+)
+
+// setting 可由管理员在运行时修改的配置项，修改保存在数据库中并覆盖配置文件中的值
+// A configuration item administrators may change at runtime, changes are stored in the database and override the value of the configuration file
+type setting struct {
+	key         string
+	description string
+	value       any                // 指向配置变量的指针 *int、*bool 或 *string Pointer to the configuration variable, *int, *bool or *string
+	min         int                // 整数的最小值 Minimum of integers
+	check       func(string) error // 字符串的额外校验 Extra validation of strings
+	apply       func()             // 值变化后执行 Run after the value changed
+	file        any                // 配置文件中的值或默认值 Value of the configuration file or the default
+}
+
+var (
+	settingsMu sync.Mutex
+	// settings 动态配置项，其他配置项只在启动时读取
+	// Dynamic settings, other configuration items are only read at startup
+	settings = []*setting{
+		{key: "log.level", description: "Log level", value: &LogLevel, check: checkLogLevel, apply: applyLogLevel},
+		{key: "log.site.enable", description: "Whether recent request logs of hosted sites are kept for their members", value: &SiteLogEnable},
+		{key: "log.site.retention", description: "Retention of site request logs in seconds", value: &SiteLogRetention, min: 60},
+		{key: "maintenance.mode", description: "Instance mode, empty, read-only or maintenance", value: &MaintenanceMode, check: checkMaintenanceMode},
+		{key: "maintenance.message", description: "Notice shown on the maintenance page", value: &MaintenanceMessage},
+		{key: "maintenance.retry-after", description: "Retry-After of 503 responses during maintenance in seconds, 0 sends none", value: &MaintenanceRetryAfter},
+		{key: "serve.private", description: "Whether every hosted site requires signing in with an account of the instance", value: &ServePrivate},
+		{key: "traffic.hard-action", description: "What happens beyond the hard transfer limit, throttle or suspend", value: &TrafficHardAction, check: checkTransferAction},
+		{key: "policy.action", description: "What happens to uploads with disallowed files, reject or strip", value: &PolicyAction, check: checkPolicyAction},
+		{key: "policy.serve", description: "Whether disallowed files are refused when requested too", value: &PolicyServe},
+		{key: "scan.enable", description: "Whether new versions are scanned before going live", value: &ScanEnable},
+		{key: "scan.backend", description: "Scan backend, clamav or http", value: &ScanBackend, check: checkScanBackend},
+		{key: "scan.max-file-size", description: "Largest file scanned in MiB, 0 scans all of them", value: &ScanMaxFileSize},
+		{key: "scan.fail-open", description: "Whether versions go live when scanning fails instead of being quarantined", value: &ScanFailOpen},
+		{key: "linkcheck.enable", description: "Whether versions are checked for broken links and oversized pages after going live", value: &LinkCheckEnable},
+		{key: "linkcheck.max-pages", description: "Most pages crawled by one link check", value: &LinkCheckMaxPages},
+		{key: "linkcheck.max-page-size", description: "Largest page with its assets in KiB before it is reported, 0 disables the check", value: &LinkCheckMaxPageSize},
+		{key: "registration.invite-only", description: "Whether signing up requires an invitation", value: &RegistrationInviteOnly},
+		{key: "registration.invite-ttl", description: "Default lifetime of invitations in seconds", value: &InvitationTTL, min: 1},
+		{key: "email.require-verification", description: "Whether the email has to be verified before signing in with a password", value: &EmailRequireVerification},
+		{key: "email.verify-ttl", description: "Lifetime of email verification links in seconds", value: &EmailVerifyTTL, min: 1},
+		{key: "email.reset-ttl", description: "Lifetime of password reset links in seconds", value: &EmailResetTTL, min: 1},
+		{key: "token.expire", description: "Lifetime of access tokens in seconds", value: &TokenExpireTime, min: 60},
+		{key: "token.refresh-expire", description: "Lifetime of sessions and their refresh tokens in seconds", value: &RefreshTokenExpireTime, min: 60},
+		{key: "login.backoff-after", description: "Failed logins of one account or IP before exponential backoff starts", value: &LoginBackoffAfter, min: 1},
+		{key: "login.backoff-max", description: "Longest backoff wait after failed logins in seconds", value: &LoginBackoffMax, min: 1},
+		{key: "login.lockout-threshold", description: "Failed logins after which an account is locked, 0 never locks", value: &LoginLockoutThreshold},
+		{key: "login.lockout-duration", description: "How long an account stays locked in seconds", value: &LoginLockoutDuration, min: 1},
+		{key: "login.failure-window", description: "Failed logins are counted afresh once the latest is older than this in seconds", value: &LoginFailureWindow, min: 1},
+		{key: "login.captcha-after", description: "Failed logins after which signing in needs the captcha, 0 always needs it", value: &LoginCaptchaAfter},
+		{key: "token.impersonation-expire", description: "Longest lifetime of impersonation sessions in seconds", value: &ImpersonationExpireTime, min: 60},
+		{key: "server.max-body-size", description: "Largest request body of endpoints other than uploads in MiB", value: &ServerMaxBodySize, min: 1},
+		{key: "page-limit", description: "Largest page size of lists", value: &PageLimit, min: 1},
+		{key: "quota.max-projects", description: "Default number of projects per user or organization, 0 is unlimited", value: &QuotaMaxProjects},
+		{key: "quota.max-deployment-size", description: "Default largest uncompressed deployment in MiB, 0 is unlimited", value: &QuotaMaxDeploymentSize},
+		{key: "quota.max-storage", description: "Default storage per user or organization in MiB, 0 is unlimited", value: &QuotaMaxStorage},
+		{key: "upload.chunk-size", description: "Suggested chunk size of chunked uploads in MiB", value: &UploadChunkSize, min: 1},
+		{key: "upload.max-size", description: "Default largest upload in MiB", value: &UploadMaxSize, min: 1},
+		{key: "upload.ttl", description: "Lifetime of upload sessions in seconds", value: &UploadTTL, min: 60},
+		{key: "upload.max-files", description: "Most files in a deployment archive, 0 is unlimited", value: &UploadMaxFiles},
+		{key: "upload.max-file-size", description: "Largest uncompressed file of a deployment archive in MiB, 0 is unlimited", value: &UploadMaxFileSize},
+		{key: "upload.max-unpacked-size", description: "Largest uncompressed deployment archive in MiB, 0 is unlimited", value: &UploadMaxUnpackedSize},
+		{key: "webdav.enable", description: "Whether site drafts can be edited over WebDAV", value: &WebDAVEnable},
+		{key: "webdav.max-size", description: "Largest draft of a site in MiB", value: &WebDAVMaxSize, min: 1},
+		{key: "preview.ttl", description: "Default lifetime of preview deployments in seconds", value: &PreviewTTL, min: 60},
+		{key: "preview.max-ttl", description: "Longest lifetime of preview deployments in seconds", value: &PreviewMaxTTL, min: 60},
+		{key: "purge.hourly-limit", description: "Path purges per site and hour", value: &PurgeHourlyLimit, min: 1},
+		{key: "purge.max-paths", description: "Paths per purge request", value: &PurgeMaxPaths, min: 1},
+		{key: "webhook.timeout", description: "Timeout of webhook deliveries in seconds", value: &WebhookTimeout, min: 1},
+		{key: "webhook.max-attempts", description: "Delivery attempts per webhook event", value: &WebhookMaxAttempts, min: 1},
+		{key: "notification.quota-warning", description: "Percentage of the storage quota notified as a warning, 0 never notifies", value: &NotificationQuotaWarning},
+		{key: "notification.certificate-warning", description: "Days of certificate validity left notified as a warning", value: &NotificationCertificateWarning, min: 1},
+		{key: "job.max-attempts", description: "Default attempts of background jobs", value: &JobMaxAttempts, min: 1},
+		{key: "job.retention", description: "How long finished jobs are kept in seconds", value: &JobRetention},
+		{key: "trash.retention", description: "How long deleted projects and sites stay in the trash in seconds", value: &TrashRetention},
+		{key: "release.keep-last", description: "Newest versions kept per site where the project sets none, 0 keeps none by count", value: &ReleaseKeepLast},
+		{key: "release.keep-days", description: "Days versions are kept where the project sets none, 0 keeps none by age", value: &ReleaseKeepDays},
+		{key: "account.deletion-grace", description: "How long an account waits after the user asked for its deletion in seconds", value: &AccountDeletionGrace},
+		{key: "traffic.retention", description: "How long daily traffic statistics are kept in seconds, 0 keeps them forever", value: &TrafficRetention},
+		{key: "traffic.soft-limit", description: "Soft monthly transfer limit of each site in MiB, 0 is unlimited", value: &TrafficSoftLimit},
+		{key: "traffic.hard-limit", description: "Hard monthly transfer limit of each site in MiB, 0 is unlimited", value: &TrafficHardLimit},
+		{key: "traffic.throttle-rate", description: "Speed limit of each response of throttled sites in KiB/s", value: &TrafficThrottleRate, min: 1},
+		{key: "gc.grace", description: "Age in seconds before unreferenced objects are collected", value: &GCGrace},
+		{key: "cron.gc", description: "Cron expression of the storage garbage collection, empty runs it by gc.interval", value: &CronGC, check: checkCron},
+		{key: "cron.analytics", description: "Cron expression of rolling up traffic and analytics, empty never prunes expired statistics", value: &CronAnalytics, check: checkCron},
+		{key: "cron.certificates", description: "Cron expression of renewing certificates about to expire, empty never renews them", value: &CronCertificates, check: checkCron},
+		{key: "cron.backup", description: "Cron expression of scheduled database backups, empty takes none", value: &CronBackup, check: checkCron},
+		{key: "cron.rekey", description: "Cron expression of re-encrypting sensitive columns with the active key, empty only re-encrypts them as they are read", value: &CronRekey, check: checkCron},
+		{key: "cron.retention", description: "Cron expression of removing old versions by the retention policy, empty never removes them", value: &CronRetention, check: checkCron},
+		{key: "cron.backup-keep", description: "Number of scheduled backups kept, 0 keeps all of them", value: &CronBackupKeep},
+		{key: "analytics.enable", description: "Whether page views are counted", value: &AnalyticsEnable},
+		{key: "analytics.retention", description: "How long daily analytics are kept in seconds, 0 keeps them forever", value: &AnalyticsRetention},
+		{key: "analytics.max-values", description: "Distinct values per site, day and dimension, 0 is unlimited", value: &AnalyticsMaxValues},
+		{key: "analytics.respect-dnt", description: "Whether visitors sending DNT or Sec-GPC are not counted", value: &AnalyticsRespectDNT},
+		{key: "analytics.exclude-bots", description: "Whether crawlers are not counted", value: &AnalyticsExcludeBots},
+		{key: "og.brand", description: "Instance branding shown on preview images", value: &OGBrand},
+		{key: "og.timeout", description: "Timeout of preview image generation in seconds", value: &OGTimeout, min: 1},
+		{key: "avatar.gravatar", description: "Whether Gravatar is used by email when no avatar was uploaded or set", value: &AvatarGravatar},
+		{key: "avatar.gravatar-url", description: "Gravatar service URL followed by the SHA-256 of the email", value: &AvatarGravatarURL, check: checkHTTPURL},
+	}
+)
+
+// SettingInfo 动态配置项的当前值和配置文件中的值
+// Current value of a dynamic setting and its value in the configuration file
+type SettingInfo struct {
+	Key         string
+	Type        string
+	Description string
+	Value       any
+	Default     any // 配置文件中的值，未配置时为内置默认值 Value of the configuration file, the built-in default when not configured
+	Min         int
+}
+
+func checkMaintenanceMode(value string) error {
+	switch value {
+	case MaintenanceOff, MaintenanceReadOnly, MaintenanceOn:
+		return nil
+	}
+	return fmt.Errorf("must be one of %q, %q or %q", MaintenanceOff, MaintenanceReadOnly, MaintenanceOn)
+}
+
+func checkHTTPURL(value string) error {
+	if !strings.HasPrefix(value, "http://") && !strings.HasPrefix(value, "https://") {
+		return fmt.Errorf("must start with http:// or https://")
+	}
+	return nil
+}
+
+// checkCron 校验 cron 表达式，空值表示禁用
+// Validate a cron expression, empty disables the task
+func checkCron(value string) error {
+	if value == "" {
+		return nil
+	}
+	_, err := cron.Parse(value)
+	return err
+}
+
+func checkLogLevel(value string) error {
+	_, err := logrus.ParseLevel(value)
+	return err
+}
+
+func applyLogLevel() {
+	if level, err := logrus.ParseLevel(LogLevel); err == nil {
+		logrus.SetLevel(level)
+	}
+}
+
+func (s *setting) kind() string {
+	switch s.value.(type) {
+	case *int:
+		return SettingInt
+	case *bool:
+		return SettingBool
+	default:
+		return SettingString
+	}
+}
+
+func (s *setting) current() any {
+	switch v := s.value.(type) {
+	case *int:
+		return *v
+	case *bool:
+		return *v
+	default:
+		return *s.value.(*string)
+	}
+}
+
+func (s *setting) set(value any) {
+	switch v := s.value.(type) {
+	case *int:
+		*v = value.(int)
+	case *bool:
+		*v = value.(bool)
+	case *string:
+		*v = value.(string)
+	}
+}
+
+// parse 解析并校验 JSON 值
+// Parse and validate a JSON value
+func (s *setting) parse(raw []byte) (any, error) {
+	switch s.value.(type) {
+	case *int:
+		var v int
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return nil, fmt.Errorf("%s must be an integer", s.key)
+		}
+		if v < s.min {
+			return nil, fmt.Errorf("%s must be at least %d", s.key, s.min)
+		}
+		return v, nil
+	case *bool:
+		var v bool
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return nil, fmt.Errorf("%s must be a boolean", s.key)
+		}
+		return v, nil
+	default:
+		var v string
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return nil, fmt.Errorf("%s must be a string", s.key)
+		}
+		if s.check != nil {
+			if err := s.check(v); err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", s.key, err)
+			}
+		}
+		return v, nil
+	}
+}
+
+func lookupSetting(key string) *setting {
+	for _, s := range settings {
+		if s.key == key {
+			return s
+		}
+	}
+	return nil
+}
+
+// captureSettings 记录配置文件中动态配置项的值，数据库中没有覆盖时恢复为这些值
+// Record the values of dynamic settings from the configuration file, restored when the database has no override
+func captureSettings() {
+	settingsMu.Lock()
+	defer settingsMu.Unlock()
+	for _, s := range settings {
+		s.file = s.current()
+	}
+}
+
+// Settings 返回所有动态配置项，按名称排序
+// Return all dynamic settings sorted by key
+func Settings() []SettingInfo {
+	settingsMu.Lock()
+	defer settingsMu.Unlock()
+	infos := make([]SettingInfo, 0, len(settings))
+	for _, s := range settings {
+		infos = append(infos, SettingInfo{
+			Key:         s.key,
+			Type:        s.kind(),
+			Description: s.description,
+			Value:       s.current(),
+			Default:     s.file,
+			Min:         s.min,
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Key < infos[j].Key })
+	return infos
+}
+
+// IsSetting 是否为动态配置项
+// Whether key is a dynamic setting
+func IsSetting(key string) bool {
+	return lookupSetting(key) != nil
+}
+
+// ParseSetting 校验动态配置项的 JSON 值，返回保存到数据库中的形式
+// Validate the JSON value of a dynamic setting, returning the form stored in the database
+func ParseSetting(key string, raw json.RawMessage) (string, error) {
+	s := lookupSetting(key)
+	if s == nil {
+		return "", fmt.Errorf("%s is not a dynamic setting", key)
+	}
+	value, err := s.parse(raw)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(value)
+	return string(data), err
+}
+
+// ApplySettings 以数据库中保存的值覆盖动态配置项，没有覆盖的恢复为配置文件中的值，返回值发生变化的配置项
+// Override dynamic settings with the values stored in the database, restoring the value of the configuration file for the rest, returning the settings whose value changed
+func ApplySettings(overrides map[string]string) (changed []string) {
+	settingsMu.Lock()
+	defer settingsMu.Unlock()
+	for _, s := range settings {
+		value := s.file
+		if raw, ok := overrides[s.key]; ok {
+			// 无法解析的值可能来自手动修改的数据库，保留配置文件中的值 Unparsable values may come from a hand-edited database, the configured value is kept
+			if parsed, err := s.parse([]byte(raw)); err != nil {
+				logrus.Warnf("ignoring stored setting: %v", err)
+			} else {
+				value = parsed
+			}
+		}
+		if value == s.current() {
+			continue
+		}
+		s.set(value)
+		if s.apply != nil {
+			s.apply()
+		}
+		changed = append(changed, s.key)
+	}
+	return changed
+}
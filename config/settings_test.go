@@ -0,0 +1,54 @@
+package config
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+// TestParseSetting 测试动态配置项的类型和最小值校验
+// Test the type and minimum validation of dynamic settings
+func TestParseSetting(t *testing.T) {
+	cases := []struct {
+		key   string
+		raw   string
+		valid bool
+	}{
+		{"quota.max-projects", "5", true},
+		{"quota.max-projects", `"5"`, false},
+		{"page-limit", "0", false},
+		{"registration.invite-only", "true", true},
+		{"log.level", `"debug"`, true},
+		{"log.level", `"loud"`, false},
+		{"maintenance.mode", `"read-only"`, true},
+		{"maintenance.mode", `""`, true},
+		{"maintenance.mode", `"paused"`, false},
+		{"server.port", `"80"`, false},
+	}
+	for _, c := range cases {
+		_, err := ParseSetting(c.key, json.RawMessage(c.raw))
+		if (err == nil) != c.valid {
+			t.Errorf("ParseSetting(%s, %s) error = %v, expected valid %v", c.key, c.raw, err, c.valid)
+		}
+	}
+}
+
+// TestApplySettings 测试覆盖动态配置项以及删除覆盖后恢复配置文件中的值
+// Test overriding dynamic settings and restoring the configured value once the override is removed
+func TestApplySettings(t *testing.T) {
+	captureSettings()
+	original := QuotaMaxProjects
+	defer ApplySettings(nil)
+
+	changed := ApplySettings(map[string]string{"quota.max-projects": "7", "page-limit": "bad"})
+	if QuotaMaxProjects != 7 || !slices.Equal(changed, []string{"quota.max-projects"}) {
+		t.Fatalf("Expected quota.max-projects to change to 7, got %d and changed %v", QuotaMaxProjects, changed)
+	}
+	if changed := ApplySettings(map[string]string{"quota.max-projects": "7"}); len(changed) != 0 {
+		t.Errorf("Expected no changes when applying the same overrides, got %v", changed)
+	}
+	ApplySettings(nil)
+	if QuotaMaxProjects != original {
+		t.Errorf("Expected quota.max-projects to be restored to %d, got %d", original, QuotaMaxProjects)
+	}
+}
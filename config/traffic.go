@@ -0,0 +1,17 @@
+package config
+
+import "fmt"
+
+// 站点月流量超出硬限制后的处理方式 What happens once a site exceeds its hard monthly transfer limit
+const (
+	TransferActionThrottle = "throttle" // 限制响应速度 Throttle responses
+	TransferActionSuspend  = "suspend"  // 暂停提供服务直到下个月 Stop serving until the next month
+)
+
+func checkTransferAction(value string) error {
+	switch value {
+	case TransferActionThrottle, TransferActionSuspend:
+		return nil
+	}
+	return fmt.Errorf("must be %q or %q", TransferActionThrottle, TransferActionSuspend)
+}
@@ -1,14 +1,30 @@
 package constants
 
 const (
-	RoleAdmin       = "admin"        // 管理员 Admin
-	RoleUser        = "user"         // 普通用户 User
-	FlagSystemAdmin = "system_admin" // 系统管理员标志 SystemAdmin
+	RoleAdmin       Role = "admin"        // 管理员 Admin
+	RoleUser        Role = "user"         // 普通用户 User
+	FlagSystemAdmin      = "system_admin" // 系统管理员标志 SystemAdmin
+
+	OrgRoleOwner      OrgRole = "owner"      // 所有者，可管理成员、团队和删除 Owner, manages members and teams and may delete
+	OrgRoleMaintainer OrgRole = "maintainer" // 维护者，可修改项目和站点设置 Maintainer, may change project and site settings
+	OrgRoleMember     OrgRole = "member"     // 成员，可部署站点 Member, may deploy sites
+	OrgRoleViewer     OrgRole = "viewer"     // 访客，只读 Viewer, read-only
+
+	VisibilityPublic  Visibility = "public"  // 公开 Public
+	VisibilityPrivate Visibility = "private" // 私有 Private
+
+	DeploymentStatusPending          DeploymentStatus = "pending"           // 等待处理 Pending
+	DeploymentStatusReady            DeploymentStatus = "ready"             // 可用 Ready
+	DeploymentStatusFailed           DeploymentStatus = "failed"            // 失败 Failed
+	DeploymentStatusScheduled        DeploymentStatus = "scheduled"         // 等待定时上线 Waiting for its scheduled go-live
+	DeploymentStatusQuarantined      DeploymentStatus = "quarantined"       // 内容扫描发现问题，等待管理员审核 Flagged by the content scan, waiting for an administrator's review
+	DeploymentStatusAwaitingApproval DeploymentStatus = "awaiting_approval" // 受保护项目的新部署，等待维护者批准 New deployment of a protected project, waiting for a maintainer's approval
 
 	CaptchaTypeDisable   = "disable"     // 禁用验证码 Captcha
 	CaptchaTypeTurnstile = "turnstile"   // 云flare turnstile
 	CaptchaTypeReCaptcha = "recaptcha"   // Google reCAPTCHA
 	CaptchaTypeHCaptcha  = "hcaptcha"    // HCaptcha
+	CaptchaTypeMath      = "math"        // 内置算术题 Built-in math challenge
 	CaptchaDevPasscode   = "dev-captcha" // 开发者验证码 Developer Captcha
 
 	ModeDev  = "dev"  // 开发者模式 Developer Mode
@@ -16,4 +32,286 @@ const (
 
 	OwnerTypeUser = "user"         // 个人用户 Personal user
 	OwnerTypeOrg  = "organization" // 组织用户 Organization user
+
+	AuthProviderGitHub AuthProviderType = "github" // GitHub OAuth2
+	AuthProviderGitLab AuthProviderType = "gitlab" // GitLab OIDC
+	AuthProviderOIDC   AuthProviderType = "oidc"   // 通用 OIDC 服务 Generic OIDC issuer
+	AuthProviderLDAP   AuthProviderType = "ldap"   // LDAP 或 Active Directory 目录 LDAP or Active Directory
+
+	TokenScopeRead   TokenScope = "read"   // 只读访问 Read-only access
+	TokenScopeDeploy TokenScope = "deploy" // 上传和激活站点发布 Upload and activate site releases
+	TokenScopeWrite  TokenScope = "write"  // 读写访问 Read and write access
+	TokenScopeAdmin  TokenScope = "admin"  // 访问管理员接口 Access to admin endpoints
+
+	APITokenPrefix     = "spat_" // 个人访问令牌前缀 Personal access token prefix
+	RefreshTokenPrefix = "sprt_" // 登录会话刷新令牌前缀 Sign-in session refresh token prefix
+	DeployKeyPrefix    = "spdk_" // 项目部署密钥前缀 Project deploy key prefix
+	RunnerTokenPrefix  = "sprn_" // 远程构建执行器令牌前缀 Remote build runner token prefix
+
+	ReleaseTagLatest = "latest" // 指向当前激活版本的发布记录标签 Tag of the release record pointing at the active version
+
+	DomainVerifyDNS  DomainVerifyMethod = "dns"  // DNS TXT 记录验证 DNS TXT record verification
+	DomainVerifyHTTP DomainVerifyMethod = "http" // HTTP 文件验证 HTTP file verification
+
+	SiteAccessPublic   SiteAccessMode = "public"   // 公开访问 Anyone may visit
+	SiteAccessPassword SiteAccessMode = "password" // 需要访问密码 A password is required
+	SiteAccessMembers  SiteAccessMode = "members"  // 仅限登录的项目成员 Only signed-in project members
+
+	UserTokenVerifyEmail   = "verify_email"   // 验证邮箱 Verify the email address
+	UserTokenResetPassword = "reset_password" // 重置密码 Reset the password
+)
+
+// 审计日志的操作 Audit log actions
+const (
+	AuditUserCreate          = "user.create"           // 管理员创建用户 Administrator created a user
+	AuditUserUpdate          = "user.update"           // 管理员修改用户 Administrator changed a user
+	AuditUserDisable         = "user.disable"          // 管理员批量停用用户 Administrator disabled users in bulk
+	AuditLogin               = "user.login"            // 登录成功 Successful login
+	AuditLoginFailed         = "user.login_failed"     // 密码或两步验证码错误 Wrong password or two-factor code
+	AuditLoginLockout        = "user.lockout"          // 连续登录失败过多，账号被临时锁定 Account locked temporarily after too many failed logins
+	AuditLoginUnlock         = "user.unlock"           // 管理员解除账号锁定 Administrator unlocked an account
+	AuditEmailVerify         = "user.email_verify"     // 验证邮箱 Email verified
+	AuditPasswordReset       = "user.password_reset"   // 通过邮件重置密码 Password reset by email
+	AuditSessionRevoke       = "user.session_revoke"   // 撤销一个或全部登录会话 One or all sign-in sessions revoked
+	AuditImpersonationStart  = "user.impersonate"      // 管理员开始代为登录 Administrator started impersonating a user
+	AuditImpersonationStop   = "user.impersonate_stop" // 结束代为登录会话 Impersonation session ended
+	AuditAccountDeletion     = "user.deletion"         // 申请或取消删除账号 Account deletion requested or cancelled
+	AuditAccountDelete       = "user.delete"           // 删除账号 Account deleted
+	AuditAccountExport       = "user.export"           // 导出个人数据 Personal data exported
+	AuditInvitationCreate    = "invitation.create"     // 创建邀请 Invitation created
+	AuditInvitationRevoke    = "invitation.revoke"     // 撤销邀请 Invitation revoked
+	AuditInvitationAccept    = "invitation.accept"     // 使用邀请 Invitation used
+	AuditTokenCreate         = "token.create"          // 创建个人访问令牌 Personal access token created
+	AuditTokenRevoke         = "token.revoke"          // 撤销个人访问令牌 Personal access token revoked
+	AuditDeployKeyCreate     = "deploy_key.create"     // 创建项目部署密钥 Project deploy key created
+	AuditDeployKeyRevoke     = "deploy_key.revoke"     // 撤销项目部署密钥 Project deploy key revoked
+	AuditProjectCreate       = "project.create"        // 管理员创建项目 Administrator created a project
+	AuditProjectImport       = "project.import"        // 从导出的站点或仓库导入项目 Project imported from an exported site or a repository
+	AuditProjectExport       = "project.export"        // 导出项目的设置和内容 Project settings and content exported
+	AuditProjectUpdate       = "project.update"        // 修改项目设置 Project settings changed
+	AuditProjectDelete       = "project.delete"        // 删除项目 Project deleted
+	AuditProjectReassign     = "project.reassign"      // 管理员直接更换项目的所有者 Administrator changed the owner of a project directly
+	AuditProjectRestore      = "project.restore"       // 从回收站恢复项目 Project restored from the trash
+	AuditProjectPurge        = "project.purge"         // 彻底删除回收站中的项目 Project purged from the trash
+	AuditProjectMemberUpdate = "project.member.update" // 授予或修改项目成员角色 Project member role granted or changed
+	AuditProjectMemberRemove = "project.member.remove" // 移除项目成员 Project member removed
+	AuditProjectTeamUpdate   = "project.team.update"   // 授予或修改团队的项目角色 Team project role granted or changed
+	AuditProjectTeamRemove   = "project.team.remove"   // 撤销团队的项目角色 Team project role revoked
+	AuditTransferRequest     = "transfer.request"      // 发起项目转移 Project transfer requested
+	AuditTransferCancel      = "transfer.cancel"       // 取消或拒绝项目转移 Project transfer cancelled or declined
+	AuditTransferAccept      = "transfer.accept"       // 接受项目转移 Project transfer accepted
+	AuditSiteUpdate          = "site.update"           // 修改站点设置 Site settings changed
+	AuditSiteDelete          = "site.delete"           // 删除站点 Site deleted
+	AuditSiteRestore         = "site.restore"          // 从回收站恢复站点 Site restored from the trash
+	AuditSitePurge           = "site.purge"            // 彻底删除回收站中的站点 Site purged from the trash
+	AuditSiteSignURL         = "site.sign_url"         // 签发受保护站点的临时链接 Temporary URL of a protected site issued
+	AuditSitePurgePaths      = "site.purge_paths"      // 从当前版本中清除或下架路径 Paths purged or taken down from the active version
+	AuditReleaseCreate       = "release.create"        // 部署新版本 New version deployed
+	AuditReleaseActivate     = "release.activate"      // 激活或回滚版本 Version activated or rolled back
+	AuditReleaseExpire       = "release.expire"        // 版本到期后回滚或下线站点 Version expired, rolling back or taking the site offline
+	AuditReleaseQuarantine   = "release.quarantine"    // 内容扫描隔离新版本 New version quarantined by the content scan
+	AuditReleaseReview       = "release.review"        // 管理员批准或驳回隔离的版本 Administrator approved or rejected a quarantined version
+	AuditReleaseApproval     = "release.approval"      // 维护者批准或驳回受保护项目的部署 Maintainer approved or rejected a deployment of a protected project
+	AuditReleasePin          = "release.pin"           // 固定或取消固定版本 Version pinned or unpinned
+	AuditRetentionUpdate     = "retention.update"      // 修改项目的版本保留策略 Project release retention policy changed
+	AuditDraftCreate         = "draft.create"          // 创建站点的 WebDAV 草稿 WebDAV draft of a site created
+	AuditDraftDelete         = "draft.delete"          // 丢弃站点的 WebDAV 草稿 WebDAV draft of a site discarded
+	AuditOrgCreate           = "org.create"            // 管理员创建组织 Administrator created an organization
+	AuditOrgUpdate           = "org.update"            // 修改组织资料 Organization profile changed
+	AuditOrgDelete           = "org.delete"            // 删除组织 Organization deleted
+	AuditOrgMemberUpdate     = "org.member.update"     // 添加组织成员或修改角色 Organization member added or role changed
+	AuditOrgMemberRemove     = "org.member.remove"     // 移除组织成员 Organization member removed
+	AuditTeamMemberAdd       = "team.member.add"       // 添加团队成员 Team member added
+	AuditTeamMemberRemove    = "team.member.remove"    // 移除团队成员 Team member removed
+	AuditAuthProviderUpdate  = "auth_provider.update"  // 创建、修改或删除登录提供方 Auth provider created, changed or deleted
+	AuditQuotaUpdate         = "quota.update"          // 设置或删除用户、组织的配额 User or organization quota set or removed
+	AuditContentPolicyUpdate = "content_policy.update" // 设置或删除组织的内容策略 Organization content policy set or removed
+	AuditSettingUpdate       = "setting.update"        // 修改或重置动态配置项 Dynamic setting changed or reset
+	AuditTenantUpdate        = "tenant.update"         // 创建、修改或删除租户 Tenant created, changed or deleted
+	AuditTenantAssign        = "tenant.assign"         // 将用户或组织移入或移出租户 User or organization moved into or out of a tenant
+	AuditCDNUpdate           = "cdn.update"            // 创建、修改或删除项目的 CDN 集成 Project CDN integration created, changed or deleted
+	AuditCDNPurge            = "cdn.purge"             // 手动刷新站点的 CDN 缓存 Site CDN cache purged by hand
+	AuditRunnerCreate        = "runner.create"         // 管理员创建远程构建执行器 Administrator created a remote build runner
+	AuditRunnerDelete        = "runner.delete"         // 管理员删除远程构建执行器 Administrator deleted a remote build runner
+
+	AuditTargetUser         = "user"          // 用户 User
+	AuditTargetToken        = "api_token"     // 个人访问令牌 Personal access token
+	AuditTargetDeployKey    = "deploy_key"    // 项目部署密钥 Project deploy key
+	AuditTargetProject      = "project"       // 项目 Project
+	AuditTargetSite         = "site"          // 站点 Site
+	AuditTargetOrg          = "organization"  // 组织 Organization
+	AuditTargetTeam         = "team"          // 团队 Team
+	AuditTargetAuthProvider = "auth_provider" // 登录提供方 Auth provider
+	AuditTargetInvitation   = "invitation"    // 邀请 Invitation
+	AuditTargetTenant       = "tenant"        // 租户 Tenant
+	AuditTargetSetting      = "setting"       // 动态配置项 Dynamic setting
+	AuditTargetRunner       = "runner"        // 远程构建执行器 Remote build runner
+)
+
+// Webhook 事件、载荷格式与投递状态 Webhook events, payload formats and delivery statuses
+const (
+	WebhookDeploymentSucceeded        = "deployment.succeeded"         // 部署成功，包括预览部署和重新激活 Deployment succeeded, including previews and reactivations
+	WebhookDeploymentFailed           = "deployment.failed"            // 部署失败 Deployment failed
+	WebhookDeploymentRollback         = "deployment.rolled_back"       // 回滚到之前的版本 Rolled back to a previous version
+	WebhookDeploymentExpired          = "deployment.expired"           // 版本到期，站点已回滚或下线 Version expired, the site rolled back or went offline
+	WebhookDeploymentQuarantined      = "deployment.quarantined"       // 内容扫描发现问题，版本等待管理员审核 Flagged by the content scan, the version waits for an administrator's review
+	WebhookDeploymentAwaitingApproval = "deployment.awaiting_approval" // 受保护项目的新部署等待维护者批准 A new deployment of a protected project waits for a maintainer's approval
+	WebhookDomainVerified             = "domain.verified"              // 自定义域名首次验证通过 Custom domain verified for the first time
+	WebhookTransferSoftLimit          = "transfer.soft_limit"          // 站点本月流量超出软限制 The site's transfer this month exceeded the soft limit
+	WebhookTransferHardLimit          = "transfer.hard_limit"          // 站点本月流量超出硬限制，已限速或暂停服务 The site's transfer this month exceeded the hard limit, it is throttled or suspended
+	WebhookCertificateExpiring        = "certificate.expiring"         // 自定义域名的证书即将到期且未能续期 The certificate of a custom domain expires soon and was not renewed
+	WebhookDeploymentLinkWarnings     = "deployment.link_warnings"     // 上线后的链接检查发现失效链接、缺失资源或过大的页面 The link check after going live found broken links, missing assets or oversized pages
+
+	WebhookFormatJSON   = "json"   // 完整的 JSON 载荷 Full JSON payload
+	WebhookFormatSlack  = "slack"  // Slack 传入 webhook 的消息格式 Slack incoming webhook message
+	WebhookFormatFeishu = "feishu" // 飞书自定义机器人的消息格式 Feishu custom bot message
+
+	WebhookDeliveryPending   = "pending"   // 等待投递或重试 Waiting for delivery or retry
+	WebhookDeliverySucceeded = "succeeded" // 目标返回 2xx The target answered 2xx
+	WebhookDeliveryFailed    = "failed"    // 重试次数用尽 Attempts exhausted
+
+	NotificationQuotaWarning = "quota.warning"     // 用户或组织的存储用量接近配额 Storage of a user or organization is close to its quota
+	NotificationTest         = "notification.test" // 手动发送的测试通知 Test notification sent by hand
+
+	NotificationChannelEmail    = "email"    // 邮件，目标为邮箱地址 Email, the target is an email address
+	NotificationChannelSlack    = "slack"    // Slack 传入 webhook，目标为 webhook 地址 Slack incoming webhook, the target is the webhook URL
+	NotificationChannelTelegram = "telegram" // Telegram 机器人，目标为聊天ID，密钥为机器人令牌 Telegram bot, the target is the chat ID and the secret the bot token
+	NotificationChannelFeishu   = "feishu"   // 飞书自定义机器人，目标为 webhook 地址，密钥为签名密钥 Feishu custom bot, the target is the webhook URL and the secret the signing secret
+
+	JobPending   = "pending"   // 等待执行或重试 Waiting to run or retry
+	JobRunning   = "running"   // 已被工作协程领取 Claimed by a worker
+	JobSucceeded = "succeeded" // 执行成功 Finished successfully
+	JobFailed    = "failed"    // 重试次数用尽或无法重试 Attempts exhausted or not retryable
+	JobCanceled  = "canceled"  // 执行前被取消 Canceled before running
+
+	JobTypeDeploy      = "release.deploy"    // 解压并发布上传的站点 Unpack and publish an uploaded site
+	JobTypeSchedule    = "release.schedule"  // 定时上线版本或处理到期的版本 Bring a scheduled version live or handle an expired one
+	JobTypeCertificate = "certificate.issue" // 为已验证的自定义域名申请证书 Request a certificate for a verified custom domain
+	JobTypeStorageGC   = "storage.gc"        // 删除不再被引用的存储对象 Delete storage objects nothing references anymore
+	JobTypeCDNPurge    = "cdn.purge"         // 调用项目配置的 CDN 刷新站点的缓存 Purge the site from the CDNs configured for the project
+	JobTypeLinkCheck   = "release.linkcheck" // 检查上线版本中的链接、资源和页面大小 Check the links, assets and page sizes of a version that went live
+	JobTypeAdminBulk   = "admin.bulk"        // 管理员批量停用用户、删除或转移项目 Administrator disabling users, deleting or reassigning projects in bulk
+
+	JobStageAssemble = "assemble" // 拼接分片上传，进度为分片数 Assembling a chunked upload, progress counts chunks
+	JobStageVerify   = "verify"   // 校验压缩包并计算哈希 Validating and hashing the archive
+	JobStageExtract  = "extract"  // 计算压缩包中每个文件的哈希，进度为文件数 Hashing every file of the archive, progress counts files
+	JobStageUpload   = "upload"   // 将内容写入存储，进度为字节数 Writing the content to storage, progress counts bytes
+	JobStageScan     = "scan"     // 扫描内容中的恶意文件，进度为文件数 Scanning the content for malicious files, progress counts files
+	JobStageActivate = "activate" // 切换站点到新版本 Switching the site to the new version
+	JobStageApply    = "apply"    // 逐项执行批量操作，进度为条目数 Applying a bulk operation item by item, progress counts items
 )
+
+// 定时任务 Scheduled tasks
+const (
+	CronTaskGC           = "gc"           // 存储垃圾回收 Storage garbage collection
+	CronTaskAnalytics    = "analytics"    // 汇总流量和访问分析并清理过期统计 Roll up traffic and analytics and prune expired statistics
+	CronTaskCertificates = "certificates" // 续期即将过期的 ACME 证书 Renew ACME certificates about to expire
+	CronTaskBackup       = "backup"       // 备份数据库到本地目录 Back up the database to a local directory
+	CronTaskRekey        = "rekey"        // 用当前密钥重新加密敏感字段 Re-encrypt sensitive columns with the active key
+	CronTaskRetention    = "retention"    // 按保留策略删除旧版本 Remove old versions by the retention policy
+
+	CronTriggerSchedule = "schedule" // 按表达式到期运行 Run because the expression was due
+	CronTriggerManual   = "manual"   // 管理员手动触发 Triggered by an administrator
+)
+
+// WebhookEvents 所有可订阅的 webhook 事件 All webhook events that can be subscribed to
+var WebhookEvents = []string{WebhookDeploymentSucceeded, WebhookDeploymentFailed, WebhookDeploymentRollback, WebhookDeploymentExpired, WebhookDeploymentQuarantined, WebhookDeploymentAwaitingApproval, WebhookDomainVerified, WebhookTransferSoftLimit, WebhookTransferHardLimit, WebhookCertificateExpiring, WebhookDeploymentLinkWarnings}
+
+// NotificationEvents 所有可订阅的通知事件，包括全部 webhook 事件 All notification events that can be subscribed to, including every webhook event
+var NotificationEvents = []string{WebhookDeploymentSucceeded, WebhookDeploymentFailed, WebhookDeploymentRollback, WebhookDeploymentExpired, WebhookDeploymentQuarantined, WebhookDeploymentAwaitingApproval, WebhookDomainVerified, WebhookTransferSoftLimit, WebhookTransferHardLimit, WebhookCertificateExpiring, WebhookDeploymentLinkWarnings, NotificationQuotaWarning}
+
+// NotificationDefaultEvents 未选择事件的通知渠道订阅的事件，即需要处理的失败和警告
+// Events of notification channels without chosen events, the failures and warnings that need attention
+var NotificationDefaultEvents = []string{WebhookDeploymentFailed, WebhookDeploymentExpired, WebhookDeploymentQuarantined, WebhookDeploymentAwaitingApproval, WebhookTransferHardLimit, WebhookCertificateExpiring, WebhookDeploymentLinkWarnings, NotificationQuotaWarning}
+
+// NotificationChannels 所有支持的通知渠道 All supported notification channels
+var NotificationChannels = []string{NotificationChannelEmail, NotificationChannelSlack, NotificationChannelTelegram, NotificationChannelFeishu}
+
+// JobStatuses 所有后台任务状态 All background job statuses
+var JobStatuses = []string{JobPending, JobRunning, JobSucceeded, JobFailed, JobCanceled}
+
+// WebhookFormats 所有支持的 webhook 载荷格式 All supported webhook payload formats
+var WebhookFormats = []string{WebhookFormatJSON, WebhookFormatSlack, WebhookFormatFeishu}
+
+// CDN 集成的服务商 Providers of CDN integrations
+const (
+	CDNProviderCloudflare = "cloudflare" // Cloudflare，需要区域ID和 API 令牌 Cloudflare, needs the zone ID and an API token
+	CDNProviderBunny      = "bunny"      // BunnyCDN，需要账号的 API 密钥 BunnyCDN, needs the API key of the account
+	CDNProviderWebhook    = "webhook"    // 向自定义地址发送签名的刷新请求 Signed purge request sent to a custom URL
+)
+
+// CDNProviders 所有支持的 CDN 服务商 All supported CDN providers
+var CDNProviders = []string{CDNProviderCloudflare, CDNProviderBunny, CDNProviderWebhook}
+
+// Git 推送部署的代码托管平台与运行状态 Git hosting providers and run statuses of push deployments
+const (
+	GitProviderGitHub = "github" // GitHub
+	GitProviderGitLab = "gitlab" // GitLab
+
+	GitRunQueued    = "queued"    // 已收到推送，等待构建 Push received, waiting to build
+	GitRunBuilding  = "building"  // 正在拉取代码或构建 Fetching or building
+	GitRunSucceeded = "succeeded" // 已发布为新版本 Published as a new version
+	GitRunFailed    = "failed"    // 拉取、构建或发布失败 Fetch, build or publish failed
+)
+
+// 常见静态站点生成器的构建预设 Build presets of common static site generators
+const (
+	BuildPresetHugo   = "hugo"   // Hugo
+	BuildPresetMkDocs = "mkdocs" // MkDocs
+	BuildPresetVite   = "vite"   // Vite 及其他 npm 构建 Vite and other npm builds
+)
+
+// 版本到期后的处理方式 What happens when a version expires
+const (
+	ReleaseExpireRollback = "rollback" // 回滚到之前的可用版本，没有时下线站点 Roll back to the previous servable version, taking the site offline when there is none
+	ReleaseExpireOffline  = "offline"  // 下线站点 Take the site offline
+)
+
+// ReleaseExpireActions 所有版本到期处理方式 All version expiry actions
+var ReleaseExpireActions = []string{ReleaseExpireRollback, ReleaseExpireOffline}
+
+// 站点 robots.txt 策略，上传的文件中没有 robots.txt 时生效 Robots policies of sites, applied when the upload has no robots.txt
+const (
+	RobotsPolicyNone     = ""         // 不生成 Never generated
+	RobotsPolicyAllow    = "allow"    // 允许所有爬虫 Allow all crawlers
+	RobotsPolicyDisallow = "disallow" // 禁止所有爬虫 Disallow all crawlers
+	RobotsPolicyCustom   = "custom"   // 使用站点设置的内容 Serve the content set on the site
+)
+
+// RobotsPolicies 所有站点 robots.txt 策略 All robots policies of sites
+var RobotsPolicies = []string{RobotsPolicyNone, RobotsPolicyAllow, RobotsPolicyDisallow, RobotsPolicyCustom}
+
+// GitProviders 所有支持的代码托管平台 All supported git hosting providers
+var GitProviders = []string{GitProviderGitHub, GitProviderGitLab}
+
+// Roles 所有已声明的全局角色 All declared global roles
+var Roles = []Role{RoleAdmin, RoleUser}
+
+// OrgRoles 所有已声明的组织角色 All declared organization roles
+var OrgRoles = []OrgRole{OrgRoleOwner, OrgRoleMaintainer, OrgRoleMember, OrgRoleViewer}
+
+// Visibilities 所有已声明的可见性 All declared visibilities
+var Visibilities = []Visibility{VisibilityPublic, VisibilityPrivate}
+
+// DeploymentStatuses 所有已声明的部署状态 All declared deployment statuses
+var DeploymentStatuses = []DeploymentStatus{DeploymentStatusPending, DeploymentStatusReady, DeploymentStatusFailed, DeploymentStatusScheduled, DeploymentStatusQuarantined, DeploymentStatusAwaitingApproval}
+
+// AuthProviderTypes 所有已声明的登录提供方类型 All declared auth provider types
+var AuthProviderTypes = []AuthProviderType{AuthProviderGitHub, AuthProviderGitLab, AuthProviderOIDC, AuthProviderLDAP}
+
+// TokenScopes 所有已声明的令牌权限范围 All declared token scopes
+var TokenScopes = []TokenScope{TokenScopeRead, TokenScopeDeploy, TokenScopeWrite, TokenScopeAdmin}
+
+// DomainVerifyMethods 所有已声明的域名验证方式 All declared domain verification methods
+var DomainVerifyMethods = []DomainVerifyMethod{DomainVerifyDNS, DomainVerifyHTTP}
+
+// SiteAccessModes 所有已声明的站点访问模式 All declared site access modes
+var SiteAccessModes = []SiteAccessMode{SiteAccessPublic, SiteAccessPassword, SiteAccessMembers}
+
+// ReservedNames 不能用作项目、组织和站点子域名的名称，它们与接口路径、前端页面或平台自身的主机名冲突
+// Names that cannot be used for projects, organizations and site subdomains as they clash with API paths, frontend pages or hosts of the platform itself
+var ReservedNames = []string{
+	"about", "admin", "api", "app", "assets", "auth", "cdn", "console", "dashboard", "docs", "graphql", "health",
+	"help", "login", "logout", "mail", "metrics", "new", "oauth", "openapi", "org", "orgs", "preview", "project", "projects",
+	"register", "settings", "site", "sites", "spage", "static", "status", "support", "user", "users", "www",
+}
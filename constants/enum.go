@@ -0,0 +1,331 @@
+package constants
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Role 用户的全局角色
+// User's global role
+type Role string
+
+// OrgRole 用户在组织中的角色
+// User's role in an organization
+type OrgRole string
+
+// Visibility 项目的可见性
+// Project visibility
+type Visibility string
+
+// DeploymentStatus 站点发布的部署状态
+// Deployment status of a site release
+type DeploymentStatus string
+
+// AuthProviderType 第三方登录提供方类型
+// Type of a third-party auth provider
+type AuthProviderType string
+
+// TokenScope 个人访问令牌的权限范围
+// Scope of a personal access token
+type TokenScope string
+
+// DomainVerifyMethod 自定义域名所有权的验证方式
+// Ownership verification method of a custom domain
+type DomainVerifyMethod string
+
+// SiteAccessMode 站点的访问保护方式
+// Access protection of a site
+type SiteAccessMode string
+
+// EnumError 枚举值非法时返回的字段级错误，包含允许的取值
+// Field-level error returned for an invalid enum value, including the allowed values
+type EnumError struct {
+	Field   string   // 字段名 Field name
+	Value   string   // 非法取值 Invalid value
+	Allowed []string // 允许的取值 Allowed values
+}
+
+func (e *EnumError) Error() string {
+	return fmt.Sprintf("invalid %s %q, allowed values: %s", e.Field, e.Value, strings.Join(e.Allowed, ", "))
+}
+
+// enum 所有枚举类型的约束
+// Constraint of all enum types
+type enum interface {
+	~string
+	Valid() bool
+}
+
+func newEnumError[T enum](field string, value T, all []T) *EnumError {
+	allowed := make([]string, 0, len(all))
+	for _, v := range all {
+		allowed = append(allowed, string(v))
+	}
+	return &EnumError{Field: field, Value: string(value), Allowed: allowed}
+}
+
+// enumValue 写入数据库前校验枚举值，拒绝未知取值
+// Validate the enum value before writing to the database, rejecting unknown values
+func enumValue[T enum](field string, value T, all []T) (driver.Value, error) {
+	if !value.Valid() {
+		return nil, newEnumError(field, value, all)
+	}
+	return string(value), nil
+}
+
+// scanEnum 从数据库读取枚举值并校验
+// Read the enum value from the database and validate it
+func scanEnum[T enum](field string, src any, dst *T, all []T) error {
+	var s string
+	switch v := src.(type) {
+	case nil:
+		*dst = ""
+		return nil
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return fmt.Errorf("cannot scan %T into %s", src, field)
+	}
+	value := T(s)
+	if !value.Valid() {
+		return newEnumError(field, value, all)
+	}
+	*dst = value
+	return nil
+}
+
+// unmarshalEnum 从请求体解析枚举值并校验，用于在绑定阶段拒绝非法取值
+// Parse the enum value from the request body and validate it, rejecting invalid values at bind time
+func unmarshalEnum[T enum](field string, data []byte, dst *T, all []T) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("invalid %s: %w", field, err)
+	}
+	value := T(s)
+	if !value.Valid() {
+		return newEnumError(field, value, all)
+	}
+	*dst = value
+	return nil
+}
+
+// Valid 判断角色是否为已声明的取值
+// Check whether the role is a declared value
+func (r Role) Valid() bool {
+	switch r {
+	case RoleAdmin, RoleUser:
+		return true
+	}
+	return false
+}
+
+func (r Role) Value() (driver.Value, error) { return enumValue("role", r, Roles) }
+
+func (r *Role) Scan(src any) error { return scanEnum("role", src, r, Roles) }
+
+func (r Role) MarshalJSON() ([]byte, error) { return json.Marshal(string(r)) }
+
+func (r *Role) UnmarshalJSON(data []byte) error { return unmarshalEnum("role", data, r, Roles) }
+
+// Valid 判断组织角色是否为已声明的取值
+// Check whether the organization role is a declared value
+func (r OrgRole) Valid() bool {
+	return r.Rank() > 0
+}
+
+// Rank 组织角色的权限等级，数值越大权限越高，未知角色为 0
+// Permission rank of the organization role, higher means more privileges, 0 for unknown roles
+func (r OrgRole) Rank() int {
+	switch r {
+	case OrgRoleOwner:
+		return 4
+	case OrgRoleMaintainer:
+		return 3
+	case OrgRoleMember:
+		return 2
+	case OrgRoleViewer:
+		return 1
+	}
+	return 0
+}
+
+// HighestOrgRole 返回权限最高的角色，全部无效时为空
+// Return the role with the most privileges, empty when none is valid
+func HighestOrgRole(roles ...OrgRole) (highest OrgRole) {
+	for _, r := range roles {
+		if r.Rank() > highest.Rank() {
+			highest = r
+		}
+	}
+	return highest
+}
+
+// AtLeast 判断该角色是否具有不低于 required 的权限
+// Check whether the role has at least the privileges of required
+func (r OrgRole) AtLeast(required OrgRole) bool {
+	return r.Valid() && r.Rank() >= required.Rank()
+}
+
+func (r OrgRole) Value() (driver.Value, error) { return enumValue("role", r, OrgRoles) }
+
+func (r *OrgRole) Scan(src any) error { return scanEnum("role", src, r, OrgRoles) }
+
+func (r OrgRole) MarshalJSON() ([]byte, error) { return json.Marshal(string(r)) }
+
+func (r *OrgRole) UnmarshalJSON(data []byte) error { return unmarshalEnum("role", data, r, OrgRoles) }
+
+// Valid 判断可见性是否为已声明的取值
+// Check whether the visibility is a declared value
+func (v Visibility) Valid() bool {
+	switch v {
+	case VisibilityPublic, VisibilityPrivate:
+		return true
+	}
+	return false
+}
+
+func (v Visibility) Value() (driver.Value, error) { return enumValue("visibility", v, Visibilities) }
+
+func (v *Visibility) Scan(src any) error { return scanEnum("visibility", src, v, Visibilities) }
+
+func (v Visibility) MarshalJSON() ([]byte, error) { return json.Marshal(string(v)) }
+
+func (v *Visibility) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum("visibility", data, v, Visibilities)
+}
+
+// Valid 判断部署状态是否为已声明的取值
+// Check whether the deployment status is a declared value
+func (s DeploymentStatus) Valid() bool {
+	switch s {
+	case DeploymentStatusPending, DeploymentStatusReady, DeploymentStatusFailed, DeploymentStatusScheduled, DeploymentStatusQuarantined, DeploymentStatusAwaitingApproval:
+		return true
+	}
+	return false
+}
+
+// Servable 该状态的发布是否可以对外提供服务
+// Whether a release in this status can be served
+func (s DeploymentStatus) Servable() bool {
+	switch s {
+	case DeploymentStatusReady:
+		return true
+	case DeploymentStatusPending, DeploymentStatusFailed, DeploymentStatusScheduled, DeploymentStatusQuarantined, DeploymentStatusAwaitingApproval:
+		return false
+	}
+	return false
+}
+
+func (s DeploymentStatus) Value() (driver.Value, error) {
+	return enumValue("status", s, DeploymentStatuses)
+}
+
+func (s *DeploymentStatus) Scan(src any) error {
+	return scanEnum("status", src, s, DeploymentStatuses)
+}
+
+func (s DeploymentStatus) MarshalJSON() ([]byte, error) { return json.Marshal(string(s)) }
+
+func (s *DeploymentStatus) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum("status", data, s, DeploymentStatuses)
+}
+
+// Valid 判断登录提供方类型是否为已声明的取值
+// Check whether the auth provider type is a declared value
+func (t AuthProviderType) Valid() bool {
+	switch t {
+	case AuthProviderGitHub, AuthProviderGitLab, AuthProviderOIDC, AuthProviderLDAP:
+		return true
+	}
+	return false
+}
+
+func (t AuthProviderType) Value() (driver.Value, error) {
+	return enumValue("type", t, AuthProviderTypes)
+}
+
+func (t *AuthProviderType) Scan(src any) error {
+	return scanEnum("type", src, t, AuthProviderTypes)
+}
+
+func (t AuthProviderType) MarshalJSON() ([]byte, error) { return json.Marshal(string(t)) }
+
+func (t *AuthProviderType) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum("type", data, t, AuthProviderTypes)
+}
+
+// Valid 判断令牌权限范围是否为已声明的取值
+// Check whether the token scope is a declared value
+func (s TokenScope) Valid() bool {
+	switch s {
+	case TokenScopeRead, TokenScopeDeploy, TokenScopeWrite, TokenScopeAdmin:
+		return true
+	}
+	return false
+}
+
+func (s TokenScope) Value() (driver.Value, error) {
+	return enumValue("scope", s, TokenScopes)
+}
+
+func (s *TokenScope) Scan(src any) error {
+	return scanEnum("scope", src, s, TokenScopes)
+}
+
+func (s TokenScope) MarshalJSON() ([]byte, error) { return json.Marshal(string(s)) }
+
+func (s *TokenScope) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum("scope", data, s, TokenScopes)
+}
+
+// Valid 判断域名验证方式是否为已声明的取值
+// Check whether the domain verification method is a declared value
+func (m DomainVerifyMethod) Valid() bool {
+	switch m {
+	case DomainVerifyDNS, DomainVerifyHTTP:
+		return true
+	}
+	return false
+}
+
+func (m DomainVerifyMethod) Value() (driver.Value, error) {
+	return enumValue("method", m, DomainVerifyMethods)
+}
+
+func (m *DomainVerifyMethod) Scan(src any) error {
+	return scanEnum("method", src, m, DomainVerifyMethods)
+}
+
+func (m DomainVerifyMethod) MarshalJSON() ([]byte, error) { return json.Marshal(string(m)) }
+
+func (m *DomainVerifyMethod) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum("method", data, m, DomainVerifyMethods)
+}
+
+// Valid 判断站点访问模式是否为已声明的取值
+// Check whether the site access mode is a declared value
+func (m SiteAccessMode) Valid() bool {
+	switch m {
+	case SiteAccessPublic, SiteAccessPassword, SiteAccessMembers:
+		return true
+	}
+	return false
+}
+
+func (m SiteAccessMode) Value() (driver.Value, error) {
+	return enumValue("access_mode", m, SiteAccessModes)
+}
+
+func (m *SiteAccessMode) Scan(src any) error {
+	return scanEnum("access_mode", src, m, SiteAccessModes)
+}
+
+func (m SiteAccessMode) MarshalJSON() ([]byte, error) { return json.Marshal(string(m)) }
+
+func (m *SiteAccessMode) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum("access_mode", data, m, SiteAccessModes)
+}
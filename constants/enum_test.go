@@ -0,0 +1,122 @@
+package constants
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// TestEnumValuesExhaustive 遍历所有已声明的枚举值，确保 Valid 和各 switch 分支都覆盖了它们
+// Iterate all declared enum values to make sure Valid and every switch handle them
+func TestEnumValuesExhaustive(t *testing.T) {
+	for _, r := range Roles {
+		if !r.Valid() {
+			t.Errorf("Role %q is declared but not valid", r)
+		}
+	}
+	for _, r := range OrgRoles {
+		if !r.Valid() || r.Rank() == 0 {
+			t.Errorf("OrgRole %q is declared but has no rank", r)
+		}
+	}
+	for _, v := range Visibilities {
+		if !v.Valid() {
+			t.Errorf("Visibility %q is declared but not valid", v)
+		}
+	}
+	for _, p := range AuthProviderTypes {
+		if !p.Valid() {
+			t.Errorf("AuthProviderType %q is declared but not valid", p)
+		}
+	}
+	for _, s := range TokenScopes {
+		if !s.Valid() {
+			t.Errorf("TokenScope %q is declared but not valid", s)
+		}
+	}
+	for _, m := range DomainVerifyMethods {
+		if !m.Valid() {
+			t.Errorf("DomainVerifyMethod %q is declared but not valid", m)
+		}
+	}
+	for _, m := range SiteAccessModes {
+		if !m.Valid() {
+			t.Errorf("SiteAccessMode %q is declared but not valid", m)
+		}
+	}
+	// 每个部署状态都必须明确是否可服务
+	// Every deployment status must explicitly state whether it is servable
+	servable := map[DeploymentStatus]bool{
+		DeploymentStatusPending:          false,
+		DeploymentStatusReady:            true,
+		DeploymentStatusFailed:           false,
+		DeploymentStatusScheduled:        false,
+		DeploymentStatusQuarantined:      false,
+		DeploymentStatusAwaitingApproval: false,
+	}
+	for _, s := range DeploymentStatuses {
+		if !s.Valid() {
+			t.Errorf("DeploymentStatus %q is declared but not valid", s)
+		}
+		expected, ok := servable[s]
+		if !ok {
+			t.Errorf("DeploymentStatus %q has no servable expectation", s)
+			continue
+		}
+		if s.Servable() != expected {
+			t.Errorf("Expected Servable()=%v for %q", expected, s)
+		}
+	}
+}
+
+// TestEnumRejectsUnknown 测试未知取值在绑定、写库和读库时都会被拒绝
+// Test that unknown values are rejected when binding, writing and scanning
+func TestEnumRejectsUnknown(t *testing.T) {
+	var v Visibility
+	err := json.Unmarshal([]byte(`"secret"`), &v)
+	var enumErr *EnumError
+	if !errors.As(err, &enumErr) {
+		t.Fatalf("Expected EnumError, got %v", err)
+	}
+	if enumErr.Field != "visibility" || len(enumErr.Allowed) != len(Visibilities) {
+		t.Errorf("Unexpected error content: %v", enumErr)
+	}
+
+	if _, err := Role("root").Value(); err == nil {
+		t.Errorf("Expected Value() to reject unknown role")
+	}
+
+	var s DeploymentStatus
+	if err := s.Scan("unknown"); err == nil {
+		t.Errorf("Expected Scan() to reject unknown status")
+	}
+	if err := s.Scan([]byte("ready")); err != nil || s != DeploymentStatusReady {
+		t.Errorf("Expected Scan() to accept ready, got %q, %v", s, err)
+	}
+}
+
+// TestOrgRoleAtLeast 测试组织角色权限比较
+// Test organization role comparison
+func TestOrgRoleAtLeast(t *testing.T) {
+	if !OrgRoleOwner.AtLeast(OrgRoleMember) {
+		t.Errorf("Owner should have member privileges")
+	}
+	if OrgRoleMember.AtLeast(OrgRoleOwner) {
+		t.Errorf("Member should not have owner privileges")
+	}
+	if OrgRole("").AtLeast(OrgRoleMember) {
+		t.Errorf("Empty role should not have member privileges")
+	}
+	if !OrgRoleMaintainer.AtLeast(OrgRoleMember) || OrgRoleMaintainer.AtLeast(OrgRoleOwner) {
+		t.Errorf("Maintainer should rank between member and owner")
+	}
+	if OrgRoleViewer.AtLeast(OrgRoleMember) || !OrgRoleViewer.AtLeast(OrgRoleViewer) {
+		t.Errorf("Viewer should only have viewer privileges")
+	}
+	if r := HighestOrgRole(OrgRoleViewer, OrgRole("unknown"), OrgRoleMaintainer, OrgRoleMember); r != OrgRoleMaintainer {
+		t.Errorf("Expected maintainer as the highest role, got %q", r)
+	}
+	if r := HighestOrgRole(); r != "" {
+		t.Errorf("Expected no role, got %q", r)
+	}
+}
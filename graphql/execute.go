@@ -0,0 +1,493 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Request GraphQL 请求
+// A GraphQL request
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// Result GraphQL 响应，请求无法执行时没有 data
+// A GraphQL response, without data when the request cannot be executed
+type Result struct {
+	data   *object
+	Errors []*Error
+}
+
+// MarshalJSON 按规范输出 data 和 errors
+// Output data and errors as the spec describes
+func (r *Result) MarshalJSON() ([]byte, error) {
+	out := struct {
+		Data   *object  `json:"data,omitempty"`
+		Errors []*Error `json:"errors,omitempty"`
+	}{r.data, r.Errors}
+	return json.Marshal(out)
+}
+
+// Error 执行错误，Path 为出错字段在结果中的路径
+// An execution error, Path is where the failing field sits in the result
+type Error struct {
+	Message string `json:"message"`
+	Path    []any  `json:"path,omitempty"`
+}
+
+func (e *Error) Error() string { return e.Message }
+
+// object 按查询顺序输出字段的结果对象
+// A result object whose fields are output in query order
+type object struct {
+	keys   []string
+	values map[string]any
+}
+
+func (o *object) set(key string, value any) {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+func (o *object) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		name, _ := json.Marshal(key)
+		b.Write(name)
+		b.WriteByte(':')
+		value, err := json.Marshal(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// errTooManyFields 解析的字段超过 Schema.MaxFields
+// More fields resolved than Schema.MaxFields
+var errTooManyFields = errors.New("the query resolves too many fields")
+
+type executor struct {
+	ctx     context.Context
+	schema  *Schema
+	doc     *Document
+	vars    map[string]any
+	errors  []*Error
+	pending []pendingValue
+	fields  int
+	aborted bool
+}
+
+// pendingValue 等待求值的 Thunk 及其结果的处理
+// A Thunk waiting for evaluation and what to do with its result
+type pendingValue struct {
+	thunk Thunk
+	done  func(value any, err error)
+}
+
+// Execute 执行请求中的查询，同一层返回 Thunk 的字段在该层的其余字段解析后依次求值
+// Execute the query of the request, fields of a level returning a Thunk are evaluated in turn once the rest of that level is resolved
+func (s *Schema) Execute(ctx context.Context, req Request) *Result {
+	doc, err := Parse(req.Query)
+	if err != nil {
+		return &Result{Errors: []*Error{{Message: err.Error()}}}
+	}
+	var operation *Operation
+	for _, op := range doc.Operations {
+		if req.OperationName == "" && len(doc.Operations) == 1 || op.Name == req.OperationName {
+			operation = op
+			break
+		}
+	}
+	if operation == nil {
+		message := "operationName is required when the document has several operations"
+		if req.OperationName != "" {
+			message = fmt.Sprintf("unknown operation %q", req.OperationName)
+		}
+		return &Result{Errors: []*Error{{Message: message}}}
+	}
+	if operation.Kind != "query" {
+		return &Result{Errors: []*Error{{Message: "only queries are supported"}}}
+	}
+	e := &executor{ctx: ctx, schema: s, doc: doc, vars: map[string]any{}}
+	for _, def := range operation.Variables {
+		value, ok := req.Variables[def.Name]
+		if !ok || value == nil {
+			value = def.Default
+		}
+		if value == nil && strings.HasSuffix(def.Type, "!") {
+			return &Result{Errors: []*Error{{Message: fmt.Sprintf("variable $%s of type %s is required", def.Name, def.Type)}}}
+		}
+		e.vars[def.Name] = value
+	}
+	if e.skipped(operation.Directives) {
+		return &Result{data: &object{values: map[string]any{}}}
+	}
+	data := e.executeObject(s.Query, nil, operation.Selections, nil, 1)
+	for len(e.pending) > 0 && !e.aborted {
+		if err := ctx.Err(); err != nil {
+			e.fail(nil, err)
+			break
+		}
+		batch := e.pending
+		e.pending = nil
+		for _, p := range batch {
+			value, err := p.thunk()
+			p.done(value, err)
+		}
+	}
+	return &Result{data: data, Errors: e.errors}
+}
+
+func (e *executor) fail(path []any, err error) {
+	if e.aborted {
+		return
+	}
+	if errors.Is(err, errTooManyFields) {
+		e.aborted = true
+	}
+	e.errors = append(e.errors, &Error{Message: err.Error(), Path: path})
+}
+
+// collectedField 结果中的一个键及选择它的所有字段
+// A key of the result and every field selecting it
+type collectedField struct {
+	key   string
+	nodes []*FieldNode
+}
+
+// collect 展开片段并按结果键合并字段，跳过被指令排除和类型不符的片段
+// Expand fragments and merge fields by result key, skipping selections excluded by directives and fragments of other types
+func (e *executor) collect(t *Object, selections []Selection, visited map[string]bool, fields []*collectedField) ([]*collectedField, error) {
+	for _, selection := range selections {
+		if e.skipped(selection.directives()) {
+			continue
+		}
+		switch s := selection.(type) {
+		case *FieldNode:
+			key := s.ResponseKey()
+			i := slices.IndexFunc(fields, func(f *collectedField) bool { return f.key == key })
+			if i < 0 {
+				fields = append(fields, &collectedField{key: key})
+				i = len(fields) - 1
+			}
+			if len(fields[i].nodes) > 0 && fields[i].nodes[0].Name != s.Name {
+				return nil, fmt.Errorf("fields %q and %q conflict on key %q", fields[i].nodes[0].Name, s.Name, key)
+			}
+			fields[i].nodes = append(fields[i].nodes, s)
+		case *InlineFragment:
+			if s.On != "" && s.On != t.Name {
+				continue
+			}
+			var err error
+			if fields, err = e.collect(t, s.Selections, visited, fields); err != nil {
+				return nil, err
+			}
+		case *FragmentSpread:
+			if visited[s.Name] {
+				continue
+			}
+			fragment, ok := e.doc.Fragments[s.Name]
+			if !ok {
+				return nil, fmt.Errorf("unknown fragment %q", s.Name)
+			}
+			visited[s.Name] = true
+			if fragment.On != t.Name || e.skipped(fragment.Directives) {
+				continue
+			}
+			var err error
+			if fields, err = e.collect(t, fragment.Selections, visited, fields); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return fields, nil
+}
+
+// skipped 按 @skip 和 @include 判断是否排除选择
+// Whether @skip or @include excludes the selection
+func (e *executor) skipped(directives []*Directive) bool {
+	for _, directive := range directives {
+		if directive.Name != "skip" && directive.Name != "include" {
+			continue
+		}
+		value, _ := e.substitute(directive.Arguments["if"])
+		if b, _ := value.(bool); b == (directive.Name == "skip") {
+			return true
+		}
+	}
+	return false
+}
+
+// substitute 替换值中的变量
+// Substitute the variables in a value
+func (e *executor) substitute(value any) (any, error) {
+	switch v := value.(type) {
+	case Variable:
+		value, ok := e.vars[string(v)]
+		if !ok {
+			return nil, fmt.Errorf("variable $%s is not defined", v)
+		}
+		return value, nil
+	case []any:
+		list := make([]any, 0, len(v))
+		for _, item := range v {
+			item, err := e.substitute(item)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+		}
+		return list, nil
+	case map[string]any:
+		fields := make(map[string]any, len(v))
+		for name, item := range v {
+			item, err := e.substitute(item)
+			if err != nil {
+				return nil, err
+			}
+			fields[name] = item
+		}
+		return fields, nil
+	}
+	return value, nil
+}
+
+// args 转换字段的参数，未提供的参数使用默认值
+// Coerce the arguments of a field, using defaults for those not given
+func (e *executor) args(field *Field, node *FieldNode) (map[string]any, error) {
+	for name := range node.Arguments {
+		if _, ok := field.Args[name]; !ok {
+			return nil, fmt.Errorf("unknown argument %q on field %q", name, node.Name)
+		}
+	}
+	args := make(map[string]any, len(field.Args))
+	for name, arg := range field.Args {
+		value, err := e.substitute(node.Arguments[name])
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			value = arg.Default
+		}
+		if value, err = coerce(arg.Type, value); err != nil {
+			return nil, fmt.Errorf("argument %q: %v", name, err)
+		}
+		if value != nil {
+			args[name] = value
+		}
+	}
+	return args, nil
+}
+
+// executeObject 解析对象的选择集
+// Resolve the selection set of an object
+func (e *executor) executeObject(t *Object, source any, selections []Selection, path []any, depth int) *object {
+	out := &object{values: map[string]any{}}
+	fields, err := e.collect(t, selections, map[string]bool{}, nil)
+	if err != nil {
+		e.fail(path, err)
+		return out
+	}
+	for _, collected := range fields {
+		key, node := collected.key, collected.nodes[0]
+		fieldPath := append(slices.Clip(path), key)
+		if node.Name == "__typename" {
+			out.set(key, t.Name)
+			continue
+		}
+		field, ok := t.Fields[node.Name]
+		if !ok {
+			out.set(key, nil)
+			e.fail(fieldPath, fmt.Errorf("cannot query field %q on type %q", node.Name, t.Name))
+			continue
+		}
+		if e.aborted {
+			out.set(key, nil)
+			continue
+		}
+		if e.fields++; e.schema.MaxFields > 0 && e.fields > e.schema.MaxFields {
+			out.set(key, nil)
+			e.fail(fieldPath, errTooManyFields)
+			continue
+		}
+		var children []Selection
+		for _, n := range collected.nodes {
+			children = append(children, n.Selections...)
+		}
+		if len(children) > 0 && e.schema.MaxDepth > 0 && depth >= e.schema.MaxDepth {
+			out.set(key, nil)
+			e.fail(fieldPath, fmt.Errorf("the query is nested deeper than %d levels", e.schema.MaxDepth))
+			continue
+		}
+		args, err := e.args(field, node)
+		if err != nil {
+			out.set(key, nil)
+			e.fail(fieldPath, err)
+			continue
+		}
+		var value any
+		if field.Resolve != nil {
+			value, err = field.Resolve(ResolveParams{Context: e.ctx, Source: source, Args: args})
+		} else {
+			value = defaultResolve(source, node.Name)
+		}
+		out.set(key, nil)
+		e.complete(field.Type, children, value, err, fieldPath, depth, func(v any) { out.values[key] = v })
+	}
+	return out
+}
+
+// complete 完成字段的值，Thunk 放入等待队列，结果通过 set 写回
+// Complete the value of a field, Thunks go to the queue and results are written back through set
+func (e *executor) complete(t Type, selections []Selection, value any, err error, path []any, depth int, set func(any)) {
+	if err != nil {
+		e.fail(path, err)
+		return
+	}
+	var thunk Thunk
+	switch v := value.(type) {
+	case Thunk:
+		thunk = v
+	case func() (any, error):
+		thunk = v
+	}
+	if thunk != nil {
+		e.pending = append(e.pending, pendingValue{thunk: thunk, done: func(value any, err error) {
+			e.complete(t, selections, value, err, path, depth, set)
+		}})
+		return
+	}
+	result, err := e.completeValue(t, selections, value, path, depth)
+	if err != nil {
+		e.fail(path, err)
+		return
+	}
+	set(result)
+}
+
+func (e *executor) completeValue(t Type, selections []Selection, value any, path []any, depth int) (any, error) {
+	if isNil(value) {
+		return nil, nil
+	}
+	switch t := t.(type) {
+	case *NonNull:
+		return e.completeValue(t.Of, selections, value, path, depth)
+	case *Scalar:
+		if len(selections) > 0 {
+			return nil, fmt.Errorf("field of type %s cannot have a selection set", t.Name)
+		}
+		v := reflect.ValueOf(value)
+		for v.Kind() == reflect.Pointer {
+			v = v.Elem()
+		}
+		return v.Interface(), nil
+	case *List:
+		v := reflect.ValueOf(value)
+		for v.Kind() == reflect.Pointer {
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			return nil, fmt.Errorf("expected a list for %s", t)
+		}
+		items := make([]any, v.Len())
+		for i := range items {
+			e.complete(t.Of, selections, v.Index(i).Interface(), nil, append(slices.Clip(path), i), depth, func(item any) { items[i] = item })
+		}
+		return items, nil
+	case *Object:
+		if len(selections) == 0 {
+			return nil, fmt.Errorf("field of type %s must have a selection set", t.Name)
+		}
+		return e.executeObject(t, value, selections, path, depth+1), nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", t)
+}
+
+func isNil(value any) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface, reflect.Func:
+		return v.IsNil()
+	}
+	return false
+}
+
+// jsonFields 结构体类型的 json 标签名到字段索引，嵌入的字段按 Go 的提升规则取最浅的一个
+// json tag names of a struct type mapped to field indexes, embedded fields follow Go's promotion by taking the shallowest
+var jsonFields sync.Map
+
+func fieldIndexes(t reflect.Type) map[string][]int {
+	if cached, ok := jsonFields.Load(t); ok {
+		return cached.(map[string][]int)
+	}
+	indexes := map[string][]int{}
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || f.Anonymous {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		if current, ok := indexes[name]; !ok || len(f.Index) < len(current) {
+			indexes[name] = f.Index
+		}
+	}
+	jsonFields.Store(t, indexes)
+	return indexes
+}
+
+// defaultResolve 从结构体的 json 标签或映射的键读取字段
+// Read a field from a struct by json tag or from a map by key
+func defaultResolve(source any, name string) any {
+	v := reflect.ValueOf(source)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil
+		}
+		item := v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+		if !item.IsValid() {
+			return nil
+		}
+		return item.Interface()
+	case reflect.Struct:
+		index, ok := fieldIndexes(v.Type())[name]
+		if !ok {
+			return nil
+		}
+		field, err := v.FieldByIndexErr(index)
+		if err != nil {
+			return nil
+		}
+		return field.Interface()
+	}
+	return nil
+}
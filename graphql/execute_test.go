@@ -0,0 +1,211 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+)
+
+type testTeam struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type testMember struct {
+	Name   string  `json:"name"`
+	Leader *string `json:"leader"`
+}
+
+// testSchema 团队及其成员，成员和团队负责人通过加载器批量读取，fetches 记录每次批量读取的键
+// Teams and their members, members and team leads are read in batches through loaders, fetches records the keys of every batch
+func testSchema(fetches *[][]int) *Schema {
+	teams := []testTeam{{1, "core"}, {2, "docs"}, {3, "infra"}}
+	members := map[int][]testMember{1: {{Name: "ann"}, {Name: "bob"}}, 2: {{Name: "cid"}}}
+	newLoaders := func() (*Loader[int, []testMember], *Loader[int, *testTeam]) {
+		memberLoader := NewLoader(func(keys []int) (map[int][]testMember, error) {
+			*fetches = append(*fetches, slices.Clone(keys))
+			result := map[int][]testMember{}
+			for _, key := range keys {
+				result[key] = members[key]
+			}
+			return result, nil
+		})
+		teamLoader := NewLoader(func(keys []int) (map[int]*testTeam, error) {
+			*fetches = append(*fetches, slices.Clone(keys))
+			result := map[int]*testTeam{}
+			for _, key := range keys {
+				if key <= len(teams) {
+					result[key] = &teams[key-1]
+				}
+			}
+			return result, nil
+		})
+		return memberLoader, teamLoader
+	}
+	var memberLoader *Loader[int, []testMember]
+	var teamLoader *Loader[int, *testTeam]
+	team := &Object{Name: "Team", Fields: Fields{
+		"id":   {Type: NonNullOf(Int)},
+		"name": {Type: String},
+		"members": {Type: ListOf(&Object{Name: "Member", Fields: Fields{
+			"name":   {Type: String},
+			"leader": {Type: String},
+		}}), Resolve: func(p ResolveParams) (any, error) {
+			load := memberLoader.Load(p.Source.(testTeam).ID)
+			return Thunk(func() (any, error) { return load() }), nil
+		}},
+		"fail": {Type: String, Resolve: func(p ResolveParams) (any, error) {
+			return nil, errors.New("boom")
+		}},
+	}}
+	// next 先读取成员再读取下一个团队，两次读取都会合并 Members are read before the next team, both reads are merged
+	team.Fields["next"] = &Field{Type: team, Resolve: func(p ResolveParams) (any, error) {
+		id := p.Source.(testTeam).ID
+		loadMembers := memberLoader.Load(id)
+		return func() (any, error) {
+			if _, err := loadMembers(); err != nil {
+				return nil, err
+			}
+			loadTeam := teamLoader.Load(id + 1)
+			return Thunk(func() (any, error) {
+				next, err := loadTeam()
+				if next == nil || err != nil {
+					return nil, err
+				}
+				return *next, nil
+			}), nil
+		}, nil
+	}}
+	return &Schema{MaxDepth: 4, MaxFields: 100, Query: &Object{Name: "Query", Fields: Fields{
+		"teams": {Type: ListOf(team), Resolve: func(p ResolveParams) (any, error) {
+			memberLoader, teamLoader = newLoaders()
+			return teams, nil
+		}},
+		"team": {Type: team, Args: Args{"id": {Type: NonNullOf(Int)}}, Resolve: func(p ResolveParams) (any, error) {
+			memberLoader, teamLoader = newLoaders()
+			id := p.Args["id"].(int)
+			if id < 1 || id > len(teams) {
+				return nil, nil
+			}
+			return teams[id-1], nil
+		}},
+	}}}
+}
+
+func execute(t *testing.T, schema *Schema, req Request) string {
+	t.Helper()
+	body, err := json.Marshal(schema.Execute(context.Background(), req))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+// TestExecuteBatches 同一层的加载合并为一次读取，结果按查询顺序输出
+// Loads of a level are merged into one read and results follow the query order
+func TestExecuteBatches(t *testing.T) {
+	var fetches [][]int
+	got := execute(t, testSchema(&fetches), Request{Query: `{ teams { name id members { name } } }`})
+	want := `{"data":{"teams":[{"name":"core","id":1,"members":[{"name":"ann"},{"name":"bob"}]},{"name":"docs","id":2,"members":[{"name":"cid"}]},{"name":"infra","id":3,"members":null}]}}`
+	if got != want {
+		t.Errorf("got %s\nwant %s", got, want)
+	}
+	if len(fetches) != 1 || !slices.Equal(fetches[0], []int{1, 2, 3}) {
+		t.Errorf("expected one batch of every team, got %v", fetches)
+	}
+}
+
+// TestExecuteChainedThunks 返回 Thunk 的 Thunk 在下一轮求值，各轮的加载仍然合并
+// Thunks returned by Thunks are evaluated in the next round, the loads of every round are still merged
+func TestExecuteChainedThunks(t *testing.T) {
+	var fetches [][]int
+	got := execute(t, testSchema(&fetches), Request{Query: `{ teams { next { name } } }`})
+	want := `{"data":{"teams":[{"next":{"name":"docs"}},{"next":{"name":"infra"}},{"next":null}]}}`
+	if got != want {
+		t.Errorf("got %s\nwant %s", got, want)
+	}
+	if len(fetches) != 2 || !slices.Equal(fetches[0], []int{1, 2, 3}) || !slices.Equal(fetches[1], []int{2, 3, 4}) {
+		t.Errorf("expected two batches, got %v", fetches)
+	}
+}
+
+// TestExecuteFeatures 支持别名、变量、片段、指令和 __typename，字段错误带有路径
+// Aliases, variables, fragments, directives and __typename are supported, field errors carry their path
+func TestExecuteFeatures(t *testing.T) {
+	var fetches [][]int
+	got := execute(t, testSchema(&fetches), Request{
+		Query: `query Q($id: Int!, $details: Boolean = false) {
+			first: team(id: $id) { __typename ...names fail members @include(if: $details) { name } }
+			missing: team(id: 9) { id }
+		}
+		fragment names on Team { name }`,
+		Variables: map[string]any{"id": 2.0},
+	})
+	want := `{"data":{"first":{"__typename":"Team","name":"docs","fail":null},"missing":null},"errors":[{"message":"boom","path":["first","fail"]}]}`
+	if got != want {
+		t.Errorf("got %s\nwant %s", got, want)
+	}
+}
+
+// TestExecuteErrors 请求级错误没有 data，超出限制的字段返回错误
+// Request errors come without data, fields beyond the limits fail
+func TestExecuteErrors(t *testing.T) {
+	var fetches [][]int
+	schema := testSchema(&fetches)
+	cases := []struct {
+		req  Request
+		want string
+	}{
+		{Request{Query: `mutation { teams { id } }`}, `{"errors":[{"message":"only queries are supported"}]}`},
+		{Request{Query: `query A { teams { id } } query B { teams { id } }`}, `{"errors":[{"message":"operationName is required when the document has several operations"}]}`},
+		{Request{Query: `query ($id: Int!) { team(id: $id) { id } }`}, `{"errors":[{"message":"variable $id of type Int! is required"}]}`},
+		{Request{Query: `{ team(id: "x") { id } }`}, `{"data":{"team":null},"errors":[{"message":"argument \"id\": expected Int, got x","path":["team"]}]}`},
+		{Request{Query: `{ team(id: 1) { nope } }`}, `{"data":{"team":{"nope":null}},"errors":[{"message":"cannot query field \"nope\" on type \"Team\"","path":["team","nope"]}]}`},
+		{Request{Query: `{ team(id: 1) { next { next { next { id } } } } }`}, `{"data":{"team":{"next":{"next":{"next":null}}}},"errors":[{"message":"the query is nested deeper than 4 levels","path":["team","next","next","next"]}]}`},
+		{Request{Query: `{ team(id: 1) }`}, `{"data":{"team":null},"errors":[{"message":"field of type Team must have a selection set","path":["team"]}]}`},
+	}
+	for _, tc := range cases {
+		if got := execute(t, schema, tc.req); got != tc.want {
+			t.Errorf("%s:\ngot  %s\nwant %s", tc.req.Query, got, tc.want)
+		}
+	}
+	schema.MaxFields = 3
+	got := execute(t, schema, Request{Query: `{ teams { id name } }`})
+	if want := `{"data":{"teams":[{"id":1,"name":"core"},{"id":null,"name":null},{"id":null,"name":null}]},"errors":[{"message":"the query resolves too many fields","path":["teams",1,"id"]}]}`; got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
+
+// TestSDL 模式定义列出可访问的类型、字段和参数
+// The schema definition lists the reachable types, fields and arguments
+func TestSDL(t *testing.T) {
+	var fetches [][]int
+	schema := testSchema(&fetches)
+	want := `schema {
+  query: Query
+}
+
+type Member {
+  leader: String
+  name: String
+}
+
+type Query {
+  team(id: Int!): Team
+  teams: [Team]
+}
+
+type Team {
+  fail: String
+  id: Int!
+  members: [Member]
+  name: String
+  next: Team
+}
+`
+	if got := schema.SDL(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,55 @@
+package graphql
+
+// Loader 按请求合并同一层字段的加载：Load 只登记键，第一次读取结果时为所有已登记的键调用一次 fetch，结果在请求内缓存；不能在多个 goroutine 间共享
+// Merge the loads of the fields of a level within a request: Load only registers the key, the first read calls fetch once for every registered key and results are cached for the request; not safe to share between goroutines
+type Loader[K comparable, V any] struct {
+	fetch   func(keys []K) (map[K]V, error)
+	pending []K
+	queued  map[K]bool
+	values  map[K]V
+	errs    map[K]error
+}
+
+// NewLoader 创建加载器，fetch 返回的结果中缺少的键以零值表示
+// Create a loader, keys missing from the result of fetch get the zero value
+func NewLoader[K comparable, V any](fetch func(keys []K) (map[K]V, error)) *Loader[K, V] {
+	return &Loader[K, V]{
+		fetch:  fetch,
+		queued: map[K]bool{},
+		values: map[K]V{},
+		errs:   map[K]error{},
+	}
+}
+
+// Load 登记键并返回读取其结果的函数，应在解析函数返回的 Thunk 中调用
+// Register the key and return a function reading its result, meant to be called from the Thunk a resolver returns
+func (l *Loader[K, V]) Load(key K) func() (V, error) {
+	if !l.queued[key] {
+		l.queued[key] = true
+		l.pending = append(l.pending, key)
+	}
+	return func() (V, error) {
+		if _, ok := l.values[key]; !ok && l.errs[key] == nil {
+			l.dispatch()
+		}
+		return l.values[key], l.errs[key]
+	}
+}
+
+// dispatch 为所有已登记但尚未加载的键调用 fetch
+// Call fetch for every registered key not loaded yet
+func (l *Loader[K, V]) dispatch() {
+	keys := l.pending
+	l.pending = nil
+	if len(keys) == 0 {
+		return
+	}
+	values, err := l.fetch(keys)
+	for _, key := range keys {
+		if err != nil {
+			l.errs[key] = err
+			continue
+		}
+		l.values[key] = values[key]
+	}
+}
@@ -0,0 +1,101 @@
+package graphql
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestParseDocument 解析操作、变量、别名、参数、片段和指令
+// Operations, variables, aliases, arguments, fragments and directives are parsed
+func TestParseDocument(t *testing.T) {
+	doc, err := Parse(`
+		# 项目及其站点 Projects and their sites
+		query Dashboard($id: ID!, $limit: Int = 5) {
+			p: project(id: $id) {
+				...fields
+				sites(limit: $limit, names: ["a", "b"]) @include(if: true) { id }
+				... on Project { name }
+			}
+		}
+		fragment fields on Project { id, display_name }
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Operations) != 1 {
+		t.Fatalf("got %d operations", len(doc.Operations))
+	}
+	op := doc.Operations[0]
+	if op.Kind != "query" || op.Name != "Dashboard" {
+		t.Errorf("got operation %s %s", op.Kind, op.Name)
+	}
+	if len(op.Variables) != 2 || op.Variables[0].Type != "ID!" || op.Variables[1].Default != int64(5) {
+		t.Errorf("unexpected variables %+v %+v", op.Variables[0], op.Variables[1])
+	}
+	project := op.Selections[0].(*FieldNode)
+	if project.ResponseKey() != "p" || project.Name != "project" || project.Arguments["id"] != Variable("id") {
+		t.Errorf("unexpected field %+v", project)
+	}
+	if spread, ok := project.Selections[0].(*FragmentSpread); !ok || spread.Name != "fields" {
+		t.Errorf("expected a spread of fields, got %#v", project.Selections[0])
+	}
+	sites := project.Selections[1].(*FieldNode)
+	if want := []any{"a", "b"}; !reflect.DeepEqual(sites.Arguments["names"], want) {
+		t.Errorf("got names %v", sites.Arguments["names"])
+	}
+	if len(sites.Directives) != 1 || sites.Directives[0].Name != "include" || sites.Directives[0].Arguments["if"] != true {
+		t.Errorf("unexpected directives %+v", sites.Directives)
+	}
+	if inline, ok := project.Selections[2].(*InlineFragment); !ok || inline.On != "Project" {
+		t.Errorf("expected an inline fragment on Project, got %#v", project.Selections[2])
+	}
+	if fragment := doc.Fragments["fields"]; fragment == nil || fragment.On != "Project" || len(fragment.Selections) != 2 {
+		t.Errorf("unexpected fragment %+v", fragment)
+	}
+}
+
+// TestParseValues 解析各种字面值和字符串转义
+// Literal values and string escapes are parsed
+func TestParseValues(t *testing.T) {
+	doc, err := Parse(`{ f(a: -12, b: 1.5e2, c: "x\né\"", d: null, e: ASC, f: {k: [1]}, g: """
+		block
+		  text
+	""") }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := doc.Operations[0].Selections[0].(*FieldNode).Arguments
+	want := map[string]any{
+		"a": int64(-12),
+		"b": 150.0,
+		"c": "x\né\"",
+		"d": nil,
+		"e": Enum("ASC"),
+		"f": map[string]any{"k": []any{int64(1)}},
+		"g": "block\n  text",
+	}
+	if !reflect.DeepEqual(args, want) {
+		t.Errorf("got %#v, want %#v", args, want)
+	}
+}
+
+// TestParseErrors 无效的文档返回错误
+// Invalid documents fail to parse
+func TestParseErrors(t *testing.T) {
+	for _, source := range []string{
+		``,
+		`{`,
+		`{ }`,
+		`{ a(b: $) }`,
+		`{ a(b: "x) }`,
+		`{ a(b: 1, b: 2) }`,
+		`fragment on on X { a }`,
+		`fragment f on X { a } fragment f on X { b } { a }`,
+		`foo { a }`,
+		`query ($v: Int = $w) { a }`,
+	} {
+		if _, err := Parse(source); err == nil {
+			t.Errorf("%q: expected an error", source)
+		}
+	}
+}
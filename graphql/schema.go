@@ -0,0 +1,291 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Type 字段或参数的类型：*Scalar、*Object、*List 或 *NonNull
+// Type of a field or argument: *Scalar, *Object, *List or *NonNull
+type Type interface {
+	String() string
+}
+
+// Scalar 标量类型，Coerce 将参数值转换为解析函数使用的值
+// A scalar type, Coerce turns argument values into the values resolvers use
+type Scalar struct {
+	Name        string
+	Description string
+	Coerce      func(value any) (any, error)
+}
+
+func (s *Scalar) String() string { return s.Name }
+
+// Object 对象类型
+// An object type
+type Object struct {
+	Name        string
+	Description string
+	Fields      Fields
+}
+
+func (o *Object) String() string { return o.Name }
+
+// List 列表类型
+// A list type
+type List struct {
+	Of Type
+}
+
+func (l *List) String() string { return "[" + l.Of.String() + "]" }
+
+// NonNull 非空类型
+// A non-null type
+type NonNull struct {
+	Of Type
+}
+
+func (n *NonNull) String() string { return n.Of.String() + "!" }
+
+// ListOf 返回 t 的列表类型
+// The list type of t
+func ListOf(t Type) *List { return &List{Of: t} }
+
+// NonNullOf 返回 t 的非空类型
+// The non-null type of t
+func NonNullOf(t Type) *NonNull { return &NonNull{Of: t} }
+
+// Fields 对象的字段，按名称索引
+// Fields of an object, keyed by name
+type Fields map[string]*Field
+
+// Field 对象的字段，Resolve 为空时从父值中按 json 标签或映射键读取同名字段
+// A field of an object, read from the parent value by json tag or map key when Resolve is nil
+type Field struct {
+	Type        Type
+	Description string
+	Args        Args
+	Resolve     ResolveFunc
+}
+
+// Args 字段的参数，按名称索引
+// Arguments of a field, keyed by name
+type Args map[string]*Arg
+
+// Arg 字段的参数，Default 为未提供时使用的值
+// An argument of a field, Default is used when it is not given
+type Arg struct {
+	Type        Type
+	Description string
+	Default     any
+}
+
+// ResolveParams 解析函数的参数
+// Parameters of a resolver
+type ResolveParams struct {
+	Context context.Context
+	Source  any            // 父对象的值 Value of the parent object
+	Args    map[string]any // 转换后的参数 Coerced arguments
+}
+
+// ResolveFunc 解析字段的值，可以返回 Thunk 延迟到同一层的字段都解析后再求值，以便批量加载
+// Resolve the value of a field, a Thunk may be returned to evaluate later once the fields of the same level are resolved, allowing batched loads
+type ResolveFunc func(p ResolveParams) (any, error)
+
+// Thunk 延迟求值的字段值，可以返回另一个 Thunk
+// A field value evaluated later, which may return another Thunk
+type Thunk func() (any, error)
+
+// Schema 查询入口和执行限制
+// Query root and execution limits
+type Schema struct {
+	Query     *Object
+	MaxDepth  int // 选择集最大嵌套层数，0 为不限制 Max nesting of selection sets, 0 is unlimited
+	MaxFields int // 一次请求最多解析的字段数，0 为不限制 Max fields resolved per request, 0 is unlimited
+}
+
+var (
+	// Int 32 位有符号整数 A signed 32-bit integer
+	Int = &Scalar{Name: "Int", Coerce: coerceInt}
+	// Float 双精度浮点数 A double-precision float
+	Float = &Scalar{Name: "Float", Coerce: coerceFloat}
+	// String UTF-8 字符串 A UTF-8 string
+	String = &Scalar{Name: "String", Coerce: coerceString}
+	// Boolean 布尔值 A boolean
+	Boolean = &Scalar{Name: "Boolean", Coerce: coerceBoolean}
+	// ID 唯一标识，接受字符串或整数 A unique identifier, accepting a string or an integer
+	ID = &Scalar{Name: "ID", Coerce: coerceID}
+)
+
+func coerceInt(value any) (any, error) {
+	var n float64
+	switch v := value.(type) {
+	case int64:
+		n = float64(v)
+	case float64:
+		n = v
+	case int:
+		n = float64(v)
+	default:
+		return nil, fmt.Errorf("expected Int, got %v", value)
+	}
+	if n != math.Trunc(n) || n < math.MinInt32 || n > math.MaxInt32 {
+		return nil, fmt.Errorf("expected Int, got %v", value)
+	}
+	return int(n), nil
+}
+
+func coerceFloat(value any) (any, error) {
+	switch v := value.(type) {
+	case int64:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	case int:
+		return float64(v), nil
+	}
+	return nil, fmt.Errorf("expected Float, got %v", value)
+}
+
+func coerceString(value any) (any, error) {
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	return nil, fmt.Errorf("expected String, got %v", value)
+}
+
+func coerceBoolean(value any) (any, error) {
+	if b, ok := value.(bool); ok {
+		return b, nil
+	}
+	return nil, fmt.Errorf("expected Boolean, got %v", value)
+}
+
+func coerceID(value any) (any, error) {
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	n, err := coerceInt(value)
+	if err != nil {
+		return nil, fmt.Errorf("expected ID, got %v", value)
+	}
+	return strconv.Itoa(n.(int)), nil
+}
+
+// coerce 按类型转换参数值，value 中的变量已被替换
+// Coerce an argument value by its type, variables in value are already substituted
+func coerce(t Type, value any) (any, error) {
+	switch t := t.(type) {
+	case *NonNull:
+		if value == nil {
+			return nil, fmt.Errorf("expected %s, got null", t)
+		}
+		return coerce(t.Of, value)
+	case *List:
+		if value == nil {
+			return nil, nil
+		}
+		items, ok := value.([]any)
+		if !ok {
+			// 单个值视为只有一项的列表 A single value is taken as a list of one
+			items = []any{value}
+		}
+		list := make([]any, 0, len(items))
+		for _, item := range items {
+			v, err := coerce(t.Of, item)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		return list, nil
+	case *Scalar:
+		if value == nil {
+			return nil, nil
+		}
+		if e, ok := value.(Enum); ok {
+			value = string(e)
+		}
+		return t.Coerce(value)
+	}
+	return nil, fmt.Errorf("%s cannot be used as an argument", t)
+}
+
+// SDL 以 GraphQL 模式定义语言描述可从查询入口访问的类型
+// Describe the types reachable from the query root in the GraphQL schema definition language
+func (s *Schema) SDL() string {
+	objects := map[string]*Object{}
+	var walk func(t Type)
+	walk = func(t Type) {
+		switch t := t.(type) {
+		case *NonNull:
+			walk(t.Of)
+		case *List:
+			walk(t.Of)
+		case *Object:
+			if _, ok := objects[t.Name]; ok {
+				return
+			}
+			objects[t.Name] = t
+			for _, field := range t.Fields {
+				walk(field.Type)
+			}
+		}
+	}
+	walk(s.Query)
+	names := make([]string, 0, len(objects))
+	for name := range objects {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	var b strings.Builder
+	b.WriteString("schema {\n  query: " + s.Query.Name + "\n}\n")
+	for _, name := range names {
+		object := objects[name]
+		b.WriteString("\n")
+		writeDescription(&b, "", object.Description)
+		b.WriteString("type " + name + " {\n")
+		fieldNames := make([]string, 0, len(object.Fields))
+		for fieldName := range object.Fields {
+			fieldNames = append(fieldNames, fieldName)
+		}
+		slices.Sort(fieldNames)
+		for _, fieldName := range fieldNames {
+			field := object.Fields[fieldName]
+			writeDescription(&b, "  ", field.Description)
+			b.WriteString("  " + fieldName)
+			if len(field.Args) > 0 {
+				argNames := make([]string, 0, len(field.Args))
+				for argName := range field.Args {
+					argNames = append(argNames, argName)
+				}
+				slices.Sort(argNames)
+				args := make([]string, 0, len(argNames))
+				for _, argName := range argNames {
+					arg := field.Args[argName]
+					text := argName + ": " + arg.Type.String()
+					if s, ok := arg.Default.(string); ok {
+						text += " = " + strconv.Quote(s)
+					} else if arg.Default != nil {
+						text += " = " + fmt.Sprint(arg.Default)
+					}
+					args = append(args, text)
+				}
+				b.WriteString("(" + strings.Join(args, ", ") + ")")
+			}
+			b.WriteString(": " + field.Type.String() + "\n")
+		}
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
+
+func writeDescription(b *strings.Builder, indent, description string) {
+	if description != "" {
+		b.WriteString(indent + strconv.Quote(description) + "\n")
+	}
+}
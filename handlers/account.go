@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/middle"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/serve"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// accountExportVersion 个人数据导出格式版本
+	// Personal data export format version
+	accountExportVersion = 1
+	// accountDeletionBatch 每轮删除的账号数量上限
+	// Max number of accounts deleted per round
+	accountDeletionBatch = 20
+)
+
+type AccountApi struct{}
+
+// Account 用户自助删除账号和导出个人数据
+// Self-service account deletion and personal data export
+var Account = AccountApi{}
+
+// deletionStatus 账号的删除时间以及删除前需要处理的项目和组织
+// Deletion time of the account and the projects and organizations to take care of before it
+func (AccountApi) deletionStatus(user *models.User) (*AccountDeletionDTO, error) {
+	projects, err := store.User.OwnedProjects(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	orgs, err := store.User.SoleOwnerOrgs(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	status := &AccountDeletionDTO{
+		DeleteAt:      user.DeleteAt,
+		OwnedProjects: make([]ProjectDTO, 0, len(projects)),
+		SoleOwnerOrgs: make([]OrganizationDTO, 0, len(orgs)),
+	}
+	for _, project := range projects {
+		status.OwnedProjects = append(status.OwnedProjects, Project.toDTO(&project, true))
+	}
+	for _, org := range orgs {
+		status.SoleOwnerOrgs = append(status.SoleOwnerOrgs, Org.ToDTO(&org))
+	}
+	return status, nil
+}
+
+// DeletionStatus 获取账号的删除状态，以及删除前应转移的个人项目和需要其他所有者的组织
+// Get the deletion status of the account with the personal projects to transfer and the organizations needing another owner first
+func (AccountApi) DeletionStatus(ctx context.Context, c *app.RequestContext) {
+	user := middle.Auth.GetUser(ctx, c)
+	if user == nil {
+		return
+	}
+	status, err := Account.deletionStatus(user)
+	if err != nil {
+		resps.InternalServerError(c, "Failed to get deletion status")
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"deletion": status,
+	})
+}
+
+// RequestDeletion 申请删除账号，等待 account.deletion-grace 后删除，期间可以取消；
+// 用户是组织的唯一所有者时拒绝，有个人项目时需要确认项目随账号移入回收站，两种情况都返回 409 和需要处理的项目与组织
+// Ask for the account's deletion, done after account.deletion-grace and cancellable meanwhile;
+// refused while the user is the only owner of an organization and personal projects need confirming they go to the trash with the account,
+// both answered with 409 and the projects and organizations to take care of
+func (AccountApi) RequestDeletion(ctx context.Context, c *app.RequestContext) {
+	user := middle.Auth.GetUser(ctx, c)
+	if user == nil {
+		return
+	}
+	req := DeleteAccountReq{}
+	if !bindJSON(c, &req) {
+		return
+	}
+	if req.Confirm != user.Name {
+		resps.BadRequest(c, "confirm must be the username")
+		return
+	}
+	if user.Password != nil {
+		if verified, _ := utils.Password.VerifyPassword(req.Password, *user.Password); !verified {
+			resps.Forbidden(c, "Incorrect password")
+			return
+		}
+	}
+	status, err := Account.deletionStatus(user)
+	if err != nil {
+		resps.InternalServerError(c, "Failed to get deletion status")
+		return
+	}
+	switch {
+	case len(status.SoleOwnerOrgs) > 0:
+		resps.Custom(c, http.StatusConflict, "Add another owner to or delete the organizations you solely own first", map[string]any{"deletion": status})
+		return
+	case len(status.OwnedProjects) > 0 && !req.DeleteProjects:
+		resps.Custom(c, http.StatusConflict, "Transfer your projects or set delete_projects to move them to the trash with the account", map[string]any{"deletion": status})
+		return
+	}
+	deleteAt := time.Now().Add(time.Duration(config.AccountDeletionGrace) * time.Second)
+	if err := store.User.ScheduleDeletion(user.ID, &deleteAt); err != nil {
+		resps.DBError(c, err, "Failed to schedule deletion")
+		return
+	}
+	status.DeleteAt = &deleteAt
+	Audit.record(ctx, c, user, constants.AuditAccountDeletion, constants.AuditTargetUser, user.ID, map[string]any{
+		"delete_at": deleteAt,
+		"projects":  len(status.OwnedProjects),
+	})
+	resps.Ok(c, resps.OK, map[string]any{
+		"deletion": status,
+	})
+}
+
+// CancelDeletion 在删除时间之前取消删除账号
+// Cancel the account's deletion before its time comes
+func (AccountApi) CancelDeletion(ctx context.Context, c *app.RequestContext) {
+	user := middle.Auth.GetUser(ctx, c)
+	if user == nil {
+		return
+	}
+	if user.DeleteAt == nil {
+		resps.NotFound(c, "Account deletion was not requested")
+		return
+	}
+	if err := store.User.ScheduleDeletion(user.ID, nil); err != nil {
+		resps.DBError(c, err, "Failed to cancel deletion")
+		return
+	}
+	Audit.record(ctx, c, user, constants.AuditAccountDeletion, constants.AuditTargetUser, user.ID, map[string]any{"cancelled": true})
+	resps.Ok(c, resps.OK)
+}
+
+// Export 以 JSON 文件下载个人数据，包括个人资料、关联身份、组织和项目的成员关系、个人项目、会话、令牌、通知渠道和相关的审计日志
+// Download the personal data as a JSON file, with the profile, linked identities, organization and project memberships,
+// personal projects, sessions, tokens, notification channels and the related audit entries
+func (AccountApi) Export(ctx context.Context, c *app.RequestContext) {
+	user := middle.Auth.GetUser(ctx, c)
+	if user == nil {
+		return
+	}
+	export, err := Account.collect(user, middle.Auth.SessionID(c))
+	if err != nil {
+		utils.Log.Ctx(ctx).Errorf("failed to collect personal data of user %d: %v", user.ID, err)
+		resps.InternalServerError(c, "Failed to export personal data")
+		return
+	}
+	body, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		resps.InternalServerError(c, "Failed to export personal data")
+		return
+	}
+	Audit.record(ctx, c, user, constants.AuditAccountExport, constants.AuditTargetUser, user.ID, nil)
+	c.Response.Header.Set("Content-Disposition", "attachment; filename=spage-"+user.Name+"-"+export.ExportedAt.Format("20060102150405")+".json")
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
+
+// collect 读取用户的个人数据
+// Read the personal data of a user
+func (AccountApi) collect(user *models.User, session uint) (*AccountExport, error) {
+	export := &AccountExport{Version: accountExportVersion, ExportedAt: time.Now().UTC(), User: User.ToDTO(user, true)}
+	identities, err := store.OIDC.ListIdentities(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, identity := range identities {
+		export.Identities = append(export.Identities, IdentityDTO{
+			ID:        identity.ID,
+			Provider:  OIDC.toDTO(&identity.Provider),
+			Email:     identity.Email,
+			CreatedAt: identity.CreatedAt,
+		})
+	}
+	if export.Organizations, err = store.User.OrgMemberships(user.ID); err != nil {
+		return nil, err
+	}
+	if export.Memberships, err = store.User.ProjectMemberships(user.ID); err != nil {
+		return nil, err
+	}
+	projects, err := store.User.OwnedProjects(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.Project.LoadLabels(projects...); err != nil {
+		return nil, err
+	}
+	for _, project := range projects {
+		export.Projects = append(export.Projects, Project.toDTO(&project, true))
+	}
+	sessions, err := store.JWT.ListSessions(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range sessions {
+		export.Sessions = append(export.Sessions, Session.toDTO(&s, session))
+	}
+	tokens, err := store.APIToken.ListByUser(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, token := range tokens {
+		export.Tokens = append(export.Tokens, APIToken.toDTO(&token))
+	}
+	channels, err := store.Notification.ListByOwner(constants.OwnerTypeUser, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, channel := range channels {
+		export.Notifications = append(export.Notifications, Notification.toDTO(&channel))
+	}
+	logs, err := store.Audit.ListByUser(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range logs {
+		export.AuditLogs = append(export.AuditLogs, Audit.toDTO(&entry))
+	}
+	return export, nil
+}
+
+// Watch 按预览清理间隔删除删除时间已到的账号，直到 ctx 结束
+// Delete the accounts whose deletion time has come at the preview cleanup interval until ctx is done
+func (AccountApi) Watch(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(config.PreviewCleanupInterval) * time.Second)
+	defer ticker.Stop()
+	for {
+		if removed, err := Account.purgeDue(ctx, time.Now()); err != nil {
+			logrus.Warnf("failed to delete accounts: %v", err)
+		} else if removed > 0 {
+			logrus.Infof("Deleted %d account(s)", removed)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// purgeDue 删除删除时间已到的账号；申请后成为组织唯一所有者的账号取消删除，以免组织失去所有者
+// Delete the accounts whose deletion time has come; accounts that became the only owner of an organization since asking
+// have their deletion cancelled so the organization keeps an owner
+func (AccountApi) purgeDue(ctx context.Context, now time.Time) (removed int, err error) {
+	// 只读期间不修改数据 No data is changed while read-only
+	if config.ReadOnly() {
+		return 0, nil
+	}
+	users, err := store.User.ListDueDeletions(now, accountDeletionBatch)
+	if err != nil {
+		return 0, err
+	}
+	for _, user := range users {
+		orgs, err := store.User.SoleOwnerOrgs(user.ID)
+		if err != nil {
+			return removed, err
+		}
+		if len(orgs) > 0 {
+			if err := store.User.ScheduleDeletion(user.ID, nil); err != nil {
+				return removed, err
+			}
+			ids := make([]uint, 0, len(orgs))
+			for _, org := range orgs {
+				ids = append(ids, org.ID)
+			}
+			Audit.record(ctx, nil, nil, constants.AuditAccountDeletion, constants.AuditTargetUser, user.ID, map[string]any{"cancelled": true, "sole_owner_orgs": ids})
+			continue
+		}
+		projectIDs, err := store.User.Purge(ctx, &user)
+		if err != nil {
+			return removed, err
+		}
+		Avatar.remove(ctx, constants.OwnerTypeUser, user.ID, user.AvatarHash)
+		for _, id := range projectIDs {
+			serve.SiteCache.ForgetProject(ctx, id)
+		}
+		Audit.record(ctx, nil, nil, constants.AuditAccountDelete, constants.AuditTargetUser, user.ID, map[string]any{"trashed_projects": projectIDs})
+		removed++
+	}
+	return removed, nil
+}
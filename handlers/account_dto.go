@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/LiteyukiStudio/spage/store"
+)
+
+// AccountDeletionDTO 账号删除的状态，以及删除前需要处理的项目和组织
+// Status of the account deletion and the projects and organizations to take care of before it
+type AccountDeletionDTO struct {
+	DeleteAt      *time.Time        `json:"delete_at"`       // 账号的删除时间，未申请时为空 Time the account is deleted at, empty when not requested
+	OwnedProjects []ProjectDTO      `json:"owned_projects"`  // 个人项目，删除账号时移入回收站，可先转移给他人 Personal projects, moved to the trash with the account unless transferred first
+	SoleOwnerOrgs []OrganizationDTO `json:"sole_owner_orgs"` // 用户是唯一所有者的组织，需要先添加其他所有者或删除 Organizations the user is the only owner of, which need another owner or deleting first
+}
+
+// DeleteAccountReq 申请删除账号的请求参数
+// Request parameters to ask for the account's deletion
+type DeleteAccountReq struct {
+	Confirm        string `json:"confirm"`         // 用户名，用于确认 Username, for confirmation
+	Password       string `json:"password"`        // 账号设置了密码时必填 Required when the account has a password
+	DeleteProjects bool   `json:"delete_projects"` // 确认个人项目随账号移入回收站 Confirms the personal projects go to the trash with the account
+}
+
+// AccountExport 个人数据导出
+// Personal data export
+type AccountExport struct {
+	Version       int                      `json:"version"`       // 导出格式版本 Export format version
+	ExportedAt    time.Time                `json:"exported_at"`   // 导出时间 Export time
+	User          UserDTO                  `json:"user"`          // 个人资料 Profile
+	Identities    []IdentityDTO            `json:"identities"`    // 关联的第三方身份 Linked third-party identities
+	Organizations []store.Membership       `json:"organizations"` // 所属组织及角色 Organizations and roles
+	Memberships   []store.Membership       `json:"memberships"`   // 直接授予角色的项目 Projects granting a role directly
+	Projects      []ProjectDTO             `json:"projects"`      // 个人项目 Personal projects
+	Sessions      []SessionDTO             `json:"sessions"`      // 登录会话 Sign-in sessions
+	Tokens        []APITokenDTO            `json:"tokens"`        // 个人访问令牌，不包含明文 Personal access tokens, without the plain tokens
+	Notifications []NotificationChannelDTO `json:"notifications"` // 通知渠道，不包含密钥 Notification channels, without their secrets
+	AuditLogs     []AuditLogDTO            `json:"audit_logs"`    // 用户执行的或以用户为目标的审计日志 Audit entries performed by or targeting the user
+}
@@ -2,9 +2,21 @@ package handlers
 
 import (
 	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/middle"
+	"github.com/LiteyukiStudio/spage/models"
 	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/utils"
 	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/http1/resp"
 )
 
 type AdminApi struct{}
@@ -21,3 +33,192 @@ func (AdminApi) CreateUser(ctx context.Context, c *app.RequestContext) {
 		return
 	}
 }
+
+// ListUsers 分页查询用户，q 按用户名、显示名称和邮箱筛选，role 按全局角色筛选
+// Query users with pagination, q filters by name, display name and email and role by global role
+func (AdminApi) ListUsers(ctx context.Context, c *app.RequestContext) {
+	list, ok := getListQuery(c, "-id", "id", "created_at", "name")
+	if !ok {
+		return
+	}
+	filter := store.UserFilter{Keyword: c.Query("q"), Role: constants.Role(c.Query("role"))}
+	if filter.Role != "" && !filter.Role.Valid() {
+		resps.BadRequest(c, "invalid role")
+		return
+	}
+	users, total, err := store.User.List(ctx, filter, list)
+	if err != nil {
+		resps.DBError(c, err, "Failed to get users")
+		return
+	}
+	utils.Ctx.SetPageHeaders(c, list.Page, list.Limit, total)
+	dtos := make([]UserDTO, 0, len(users))
+	for _, user := range users {
+		dtos = append(dtos, User.ToDTO(&user, true))
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"users": dtos,
+		"total": total,
+	})
+}
+
+// Impersonate 代为登录用户以排查用户反馈的问题，创建有效期短、权限受限的会话并记入审计日志，令牌只在响应中返回，不替换管理员自己的 cookie；
+// 用户可在会话列表中看到并撤销该会话，以该会话的访问令牌登出即结束代为登录
+// Impersonate a user to debug the problems they report, creating a short-lived session with limited scopes that is recorded in the audit log,
+// the tokens are only returned in the response and do not replace the administrator's own cookies;
+// the user sees the session in their session list and can revoke it, logging out with its access token ends the impersonation
+func (AdminApi) Impersonate(ctx context.Context, c *app.RequestContext) {
+	admin := middle.Auth.GetUser(ctx, c)
+	if admin == nil {
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		resps.BadRequest(c, resps.ParameterError)
+		return
+	}
+	req := ImpersonateReq{}
+	if !bindJSON(c, &req) {
+		return
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" || len(req.Reason) > 255 {
+		resps.BadRequest(c, "reason is required and at most 255 bytes")
+		return
+	}
+	if len(req.Scopes) == 0 {
+		req.Scopes = []constants.TokenScope{constants.TokenScopeRead}
+	}
+	if slices.Contains(req.Scopes, constants.TokenScopeAdmin) {
+		resps.BadRequest(c, "impersonation sessions cannot have the admin scope")
+		return
+	}
+	if req.Duration == 0 {
+		req.Duration = config.ImpersonationExpireTime
+	}
+	if req.Duration < 0 || req.Duration > config.ImpersonationExpireTime {
+		resps.BadRequestf(c, "duration must be between 1 and %d seconds", config.ImpersonationExpireTime)
+		return
+	}
+	target, err := store.User.GetByID(uint(id))
+	if err != nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	if target.ID == admin.ID {
+		resps.BadRequest(c, "cannot impersonate yourself")
+		return
+	}
+	if target.Role == constants.RoleAdmin {
+		resps.Forbidden(c, "cannot impersonate another administrator")
+		return
+	}
+	refreshToken, refreshHash, err := utils.Token.NewRefreshToken()
+	if err != nil {
+		resps.InternalServerError(c, "Failed to create refresh token")
+		return
+	}
+	now := time.Now()
+	lifetime := time.Duration(req.Duration) * time.Second
+	session := &models.Token{
+		UserID:         target.ID,
+		RefreshHash:    refreshHash,
+		ExpiresAt:      now.Add(lifetime),
+		LastUsedAt:     now,
+		Method:         "impersonation",
+		IP:             c.ClientIP(),
+		UserAgent:      string(c.UserAgent()),
+		ImpersonatorID: admin.ID,
+		Scopes:         req.Scopes,
+		Reason:         req.Reason,
+	}
+	if err := store.JWT.CreateSession(session); err != nil {
+		resps.DBError(c, err, "Failed to create session")
+		return
+	}
+	token, err := utils.Token.CreateImpersonationToken(target.ID, session.ID, admin.ID, req.Scopes, min(lifetime, time.Duration(config.TokenExpireTime)*time.Second))
+	if err != nil {
+		resps.InternalServerError(c, "Failed to create token")
+		return
+	}
+	Audit.record(ctx, c, admin, constants.AuditImpersonationStart, constants.AuditTargetUser, target.ID, map[string]any{
+		"session_id": session.ID,
+		"reason":     req.Reason,
+		"scopes":     req.Scopes,
+		"expires_at": session.ExpiresAt,
+	})
+	resps.Ok(c, resps.OK, map[string]any{
+		"impersonation": ImpersonationDTO{
+			SessionID:    session.ID,
+			Token:        token,
+			RefreshToken: refreshToken,
+			Scopes:       req.Scopes,
+			ExpiresAt:    session.ExpiresAt,
+		},
+		"user": User.ToDTO(target, true),
+	})
+}
+
+// Unlock 解除账号因连续登录失败而被临时锁定的状态并清零失败次数
+// Lift the temporary lock of an account after too many failed logins and clear its failures
+func (AdminApi) Unlock(ctx context.Context, c *app.RequestContext) {
+	admin := middle.Auth.GetUser(ctx, c)
+	if admin == nil {
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		resps.BadRequest(c, resps.ParameterError)
+		return
+	}
+	target, err := store.User.GetByID(uint(id))
+	if err != nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	if err := store.LoginFailure.Reset(models.LoginFailureUser, strconv.FormatUint(uint64(target.ID), 10)); err != nil {
+		resps.DBError(c, err, "Failed to unlock user")
+		return
+	}
+	Audit.record(ctx, c, admin, constants.AuditLoginUnlock, constants.AuditTargetUser, target.ID, nil)
+	resps.Ok(c, resps.OK)
+}
+
+// Backup 以流的形式下载实例备份
+// Download an instance backup as a stream
+func (AdminApi) Backup(ctx context.Context, c *app.RequestContext) {
+	c.SetStatusCode(200)
+	c.Response.Header.SetContentType("application/zip")
+	c.Response.Header.Set("Content-Disposition", "attachment; filename=spage-backup-"+time.Now().Format("20060102150405")+".zip")
+	c.Response.HijackWriter(resp.NewChunkedBodyWriter(&c.Response, c.GetWriter()))
+	// 响应头已发送，失败时只能记录日志并中断传输
+	// Headers are already sent, a failure can only be logged and the transfer aborted
+	if err := store.Backup.Dump(c); err != nil {
+		utils.Log.Ctx(ctx).Error("Failed to dump backup: ", err)
+		return
+	}
+	_ = c.Flush()
+}
+
+// Checkpoint 将 SQLite 的 WAL 写回数据库文件，返回 WAL 和已写回的页数；数据库不是 SQLite 时返回参数错误
+// Write the SQLite WAL back into the database file, responding with the pages in the WAL and written back; a bad request when the database is not SQLite
+func (AdminApi) Checkpoint(ctx context.Context, c *app.RequestContext) {
+	req := CheckpointReq{}
+	if len(c.Request.Body()) > 0 {
+		if !bindJSON(c, &req) {
+			return
+		}
+	}
+	result, err := store.Checkpoint(ctx, req.Mode)
+	if errors.Is(err, store.ErrCheckpointUnsupported) {
+		resps.BadRequest(c, err.Error())
+		return
+	}
+	if err != nil {
+		resps.DBError(c, err, "checkpoint error")
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"checkpoint": result,
+	})
+}
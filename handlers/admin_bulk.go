@@ -0,0 +1,274 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/middle"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/serve"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/task"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+	"gorm.io/gorm"
+)
+
+// 批量操作 Bulk operations
+const (
+	bulkDisableUsers     = "users.disable"     // 停用用户 Disable users
+	bulkDeleteProjects   = "projects.delete"   // 删除长期未部署的项目 Delete projects not deployed for a long time
+	bulkReassignProjects = "projects.reassign" // 更换项目的所有者 Reassign the owner of projects
+)
+
+// 批量操作中每项的结果 Outcome of each item of a bulk operation
+const (
+	bulkMatched = "matched" // 试运行时将被处理 Would be processed, on a dry run
+	bulkDone    = "done"    // 已处理 Processed
+	bulkSkipped = "skipped" // 不满足条件而跳过 Skipped as it does not qualify
+	bulkFailed  = "failed"  // 处理失败 Failed to process
+)
+
+// bulkPayload 批量操作任务的参数，筛选条件在创建任务时确定，重试时匹配相同的对象
+// Parameters of a bulk operation job, the filters are fixed when the job is created so retries match the same objects
+type bulkPayload struct {
+	Action      string     `json:"action"`                  // 批量操作 Bulk operation
+	DryRun      bool       `json:"dry_run"`                 // 只列出将被处理的对象 Only list the objects that would be processed
+	UserIDs     []uint     `json:"user_ids,omitempty"`      // 停用的用户ID IDs of the users to disable
+	Before      *time.Time `json:"before,omitempty"`        // 删除在此之前创建且此后没有部署的项目 Delete projects created before and not deployed since
+	OwnerType   string     `json:"owner_type,omitempty"`    // 筛选或原所有者类型 Owner type filtered by or the current one
+	OwnerID     uint       `json:"owner_id,omitempty"`      // 筛选或原所有者ID Owner ID filtered by or the current one
+	ProjectIDs  []uint     `json:"project_ids,omitempty"`   // 只转移这些项目 Only reassign these projects
+	ToOwnerType string     `json:"to_owner_type,omitempty"` // 新所有者类型 New owner type
+	ToOwnerID   uint       `json:"to_owner_id,omitempty"`   // 新所有者ID New owner ID
+}
+
+// bulkItem 批量操作中一项的结果
+// Outcome of one item of a bulk operation
+type bulkItem struct {
+	ID     uint   `json:"id"`               // 用户或项目ID User or project ID
+	Name   string `json:"name,omitempty"`   // 用户或项目名称 User or project name
+	Status string `json:"status"`           // matched、done、skipped 或 failed Matched, done, skipped or failed
+	Reason string `json:"reason,omitempty"` // 跳过或失败的原因 Why it was skipped or failed
+}
+
+// bulkResult 批量操作任务的结果
+// Result of a bulk operation job
+type bulkResult struct {
+	Action  string     `json:"action"`  // 批量操作 Bulk operation
+	DryRun  bool       `json:"dry_run"` // 是否为试运行 Whether it was a dry run
+	Matched int        `json:"matched"` // 满足条件的数量 Number of qualifying items
+	Done    int        `json:"done"`    // 已处理的数量 Number processed
+	Skipped int        `json:"skipped"` // 跳过的数量 Number skipped
+	Failed  int        `json:"failed"`  // 失败的数量 Number failed
+	Items   []bulkItem `json:"items"`   // 每项的结果 Outcome of each item
+}
+
+// add 记录一项的结果 Record the outcome of one item
+func (r *bulkResult) add(item bulkItem) {
+	switch item.Status {
+	case bulkMatched:
+		r.Matched++
+	case bulkDone:
+		r.Matched++
+		r.Done++
+	case bulkSkipped:
+		r.Skipped++
+	case bulkFailed:
+		r.Matched++
+		r.Failed++
+	}
+	r.Items = append(r.Items, item)
+}
+
+// outcome 试运行时为 matched，否则执行 apply 并按其错误记为 done 或 failed
+// Matched on a dry run, otherwise run apply and record done or failed by its error
+func (r *bulkResult) outcome(item bulkItem, apply func() error) bulkItem {
+	item.Status = bulkMatched
+	if r.DryRun {
+		return item
+	}
+	item.Status = bulkDone
+	if err := apply(); err != nil {
+		item.Status, item.Reason = bulkFailed, err.Error()
+	}
+	return item
+}
+
+// enqueueBulk 创建批量操作任务并返回 202，失败时已写入响应
+// Create a bulk operation job and respond with 202, the response is already written on failure
+func (AdminApi) enqueueBulk(ctx context.Context, c *app.RequestContext, payload bulkPayload) {
+	job, err := task.EnqueueJob(ctx, constants.JobTypeAdminBulk, payload, 0, middle.Auth.GetUser(ctx, c).ID)
+	if err != nil {
+		resps.InternalServerError(c, "create job error")
+		return
+	}
+	resps.Custom(c, 202, resps.OK, map[string]any{
+		"job": Job.toDTO(job, true),
+	})
+}
+
+// BulkDisableUsers 在后台任务中批量停用用户并撤销其登录会话，系统管理员和自己会被跳过
+// Disable users in bulk in a background job and revoke their sign-in sessions, the system administrator and yourself are skipped
+func (AdminApi) BulkDisableUsers(ctx context.Context, c *app.RequestContext) {
+	req := BulkDisableUsersReq{}
+	if !bindJSON(c, &req) {
+		return
+	}
+	Admin.enqueueBulk(ctx, c, bulkPayload{Action: bulkDisableUsers, DryRun: req.DryRun, UserIDs: req.UserIDs})
+}
+
+// BulkDeleteProjects 在后台任务中将创建超过 inactive_days 天且期间没有部署的项目移入回收站，可按所有者筛选
+// Move the projects older than inactive_days days without any deployment in that time to the trash in a background job, optionally filtered by owner
+func (AdminApi) BulkDeleteProjects(ctx context.Context, c *app.RequestContext) {
+	req := BulkDeleteProjectsReq{}
+	if !bindJSON(c, &req) {
+		return
+	}
+	if req.OwnerID != 0 && req.OwnerType == "" {
+		resps.BadRequest(c, "owner_id requires owner_type")
+		return
+	}
+	before := time.Now().AddDate(0, 0, -req.InactiveDays)
+	Admin.enqueueBulk(ctx, c, bulkPayload{Action: bulkDeleteProjects, DryRun: req.DryRun, Before: &before, OwnerType: req.OwnerType, OwnerID: req.OwnerID})
+}
+
+// BulkReassignProjects 在后台任务中将一个所有者的项目直接交给另一个用户或组织，不需要接收方确认
+// Hand the projects of one owner directly to another user or organization in a background job, without the recipient confirming
+func (AdminApi) BulkReassignProjects(ctx context.Context, c *app.RequestContext) {
+	req := BulkReassignProjectsReq{}
+	if !bindJSON(c, &req) {
+		return
+	}
+	if req.FromOwnerType == req.ToOwnerType && req.FromOwnerID == req.ToOwnerID {
+		resps.BadRequest(c, "The project already belongs to this owner")
+		return
+	}
+	if _, err := store.Tenant.OfOwner(req.ToOwnerType, req.ToOwnerID); err != nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	Admin.enqueueBulk(ctx, c, bulkPayload{
+		Action:      bulkReassignProjects,
+		DryRun:      req.DryRun,
+		OwnerType:   req.FromOwnerType,
+		OwnerID:     req.FromOwnerID,
+		ProjectIDs:  req.ProjectIDs,
+		ToOwnerType: req.ToOwnerType,
+		ToOwnerID:   req.ToOwnerID,
+	})
+}
+
+// runBulkJob 执行批量操作，逐项报告进度并记入审计日志；已处理过的对象不再满足条件，重试不会重复处理
+// Run a bulk operation, reporting progress and recording audit entries item by item; processed objects no longer qualify so retries do not process them twice
+func (AdminApi) runBulkJob(ctx context.Context, job *models.Job) (any, error) {
+	payload := bulkPayload{}
+	if err := json.Unmarshal([]byte(job.Payload), &payload); err != nil {
+		return nil, task.Permanent(err)
+	}
+	actor, err := store.User.GetByID(job.UserID)
+	if err != nil {
+		return nil, task.Permanent(errors.New("administrator not found"))
+	}
+	result := &bulkResult{Action: payload.Action, DryRun: payload.DryRun, Items: []bulkItem{}}
+	switch payload.Action {
+	case bulkDisableUsers:
+		Admin.bulkDisableUsers(ctx, job, actor, payload, result)
+	case bulkDeleteProjects:
+		if payload.Before == nil {
+			return nil, task.Permanent(errors.New("missing cutoff time"))
+		}
+		projects, err := store.Project.ListStale(ctx, store.StaleProjectFilter{Before: *payload.Before, OwnerType: payload.OwnerType, OwnerID: payload.OwnerID})
+		if err != nil {
+			return nil, err
+		}
+		Admin.bulkDeleteProjects(ctx, job, actor, projects, result)
+	case bulkReassignProjects:
+		projects, err := store.Project.ListOwned(ctx, payload.OwnerType, payload.OwnerID, payload.ProjectIDs)
+		if err != nil {
+			return nil, err
+		}
+		Admin.bulkReassignProjects(ctx, job, actor, payload, projects, result)
+	default:
+		return nil, task.Permanent(fmt.Errorf("unknown bulk action %q", payload.Action))
+	}
+	task.ReportProgress(ctx, constants.JobStageApply, int64(len(result.Items)), int64(len(result.Items)))
+	utils.Log.Ctx(ctx).Infof("bulk %s by %s: %d matched, %d done, %d skipped, %d failed", payload.Action, actor.Name, result.Matched, result.Done, result.Skipped, result.Failed)
+	return result, nil
+}
+
+// bulkDisableUsers 停用用户并撤销其登录会话 Disable users and revoke their sign-in sessions
+func (AdminApi) bulkDisableUsers(ctx context.Context, job *models.Job, actor *models.User, payload bulkPayload, result *bulkResult) {
+	for i, id := range payload.UserIDs {
+		task.ReportProgress(ctx, constants.JobStageApply, int64(i), int64(len(payload.UserIDs)))
+		item := bulkItem{ID: id, Status: bulkSkipped}
+		user, err := store.User.GetByID(id)
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			item.Reason = "user not found"
+		case err != nil:
+			item.Status, item.Reason = bulkFailed, err.Error()
+		case user.Flag == constants.FlagSystemAdmin || user.ID == actor.ID:
+			item.Name, item.Reason = user.Name, "cannot disable the system administrator or yourself"
+		case user.DisabledAt != nil:
+			item.Name, item.Reason = user.Name, "already disabled"
+		default:
+			item.Name = user.Name
+			item = result.outcome(item, func() error {
+				if err := store.User.SetDisabled(user.ID, true); err != nil {
+					return err
+				}
+				if err := store.JWT.RevokeTokenByUserID(user.ID); err != nil {
+					return err
+				}
+				Audit.record(ctx, nil, actor, constants.AuditUserDisable, constants.AuditTargetUser, user.ID, map[string]any{"name": user.Name, "job_id": job.ID})
+				return nil
+			})
+		}
+		result.add(item)
+	}
+}
+
+// bulkDeleteProjects 将项目及其站点移入回收站 Move projects and their sites to the trash
+func (AdminApi) bulkDeleteProjects(ctx context.Context, job *models.Job, actor *models.User, projects []models.Project, result *bulkResult) {
+	for i := range projects {
+		project := &projects[i]
+		task.ReportProgress(ctx, constants.JobStageApply, int64(i), int64(len(projects)))
+		result.add(result.outcome(bulkItem{ID: project.ID, Name: project.Name}, func() error {
+			if err := store.Project.Delete(project); err != nil {
+				return err
+			}
+			serve.SiteCache.ForgetProject(ctx, project.ID)
+			Audit.record(ctx, nil, actor, constants.AuditProjectDelete, constants.AuditTargetProject, project.ID, map[string]any{"name": project.Name, "owner_type": project.OwnerType, "owner_id": project.OwnerID, "job_id": job.ID})
+			return nil
+		}))
+	}
+}
+
+// bulkReassignProjects 将项目交给新的所有者 Hand projects to the new owner
+func (AdminApi) bulkReassignProjects(ctx context.Context, job *models.Job, actor *models.User, payload bulkPayload, projects []models.Project, result *bulkResult) {
+	for i := range projects {
+		project := &projects[i]
+		task.ReportProgress(ctx, constants.JobStageApply, int64(i), int64(len(projects)))
+		result.add(result.outcome(bulkItem{ID: project.ID, Name: project.Name}, func() error {
+			if err := store.Project.Reassign(project, payload.ToOwnerType, payload.ToOwnerID); err != nil {
+				return err
+			}
+			serve.SiteCache.ForgetProject(ctx, project.ID)
+			Audit.record(ctx, nil, actor, constants.AuditProjectReassign, constants.AuditTargetProject, project.ID, map[string]any{
+				"name":            project.Name,
+				"from_owner_type": payload.OwnerType,
+				"from_owner_id":   payload.OwnerID,
+				"owner_type":      payload.ToOwnerType,
+				"owner_id":        payload.ToOwnerID,
+				"job_id":          job.ID,
+			})
+			return nil
+		}))
+	}
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/LiteyukiStudio/spage/constants"
+)
+
+// ImpersonateReq 管理员代为登录的请求参数
+// Request parameters for an administrator to impersonate a user
+type ImpersonateReq struct {
+	Reason   string                 `json:"reason"`   // 代为登录的原因，记入审计日志 Reason for the impersonation, recorded in the audit log
+	Scopes   []constants.TokenScope `json:"scopes"`   // 会话的权限范围，默认只读，不能包含 admin Scopes of the session, read-only by default and never admin
+	Duration int                    `json:"duration"` // 会话有效期，单位秒，默认且最长为配置的上限 Session lifetime in seconds, the configured maximum by default and at most
+}
+
+// ImpersonationDTO 代为登录的会话，令牌只在创建时返回
+// Impersonation session, the tokens are only returned on creation
+type ImpersonationDTO struct {
+	SessionID    uint                   `json:"session_id"`    // 会话ID Session ID
+	Token        string                 `json:"token"`         // 访问令牌 Access token
+	RefreshToken string                 `json:"refresh_token"` // 刷新令牌 Refresh token
+	Scopes       []constants.TokenScope `json:"scopes"`        // 权限范围 Scopes
+	ExpiresAt    time.Time              `json:"expires_at"`    // 会话过期时间 Session expiry time
+}
+
+// AdminUserReq 声明式创建或更新用户的请求参数，用户名取自路径，为 null 或省略的字段保持不变
+// Request parameters to declaratively create or update a user, the name comes from the path and null or omitted fields are left unchanged
+type AdminUserReq struct {
+	DisplayName *string        `json:"display_name" validate:"max=128"` // 显示名称 Display name
+	Email       *string        `json:"email" validate:"email"`          // 邮箱，为空字符串时清除 Email, cleared by an empty string
+	Description *string        `json:"description" validate:"max=1024"` // 描述 Description
+	Role        constants.Role `json:"role"`                            // 全局角色，创建时默认为 user Global role, user by default on creation
+	Language    string         `json:"language"`                        // 语言 Language
+	Password    *string        `json:"password"`                        // 密码，只写，为空时不修改 Password, write-only and unchanged when empty
+	Disabled    *bool          `json:"disabled"`                        // 停用或重新启用账号 Disable or enable the account again
+}
+
+// AdminOrgReq 声明式创建或更新组织的请求参数，组织名称取自路径，为 null 或省略的字段保持不变
+// Request parameters to declaratively create or update an organization, the name comes from the path and null or omitted fields are left unchanged
+type AdminOrgReq struct {
+	DisplayName *string  `json:"display_name" validate:"max=128"` // 显示名称，创建时默认为组织名称 Display name, the organization name by default on creation
+	Email       *string  `json:"email" validate:"email"`          // 邮箱地址 Email address
+	Description *string  `json:"description" validate:"max=1024"` // 描述信息 Description
+	Owners      []string `json:"owners"`                          // 应为所有者的用户名，创建时至少一个，不会移除其他所有者 Names of users that should be owners, at least one on creation; other owners are never removed
+}
+
+// AdminProjectReq 声明式创建或更新项目的请求参数，项目名称取自路径，为 null 或省略的字段保持不变
+// Request parameters to declaratively create or update a project, the name comes from the path and null or omitted fields are left unchanged
+type AdminProjectReq struct {
+	OwnerType   string                `json:"owner_type" validate:"required,oneof=user organization"` // 所有者类型 Owner type
+	Owner       string                `json:"owner" validate:"required"`                              // 所有者的用户名或组织名称，已有项目的所有者须通过项目转移修改 Name of the owning user or organization, owners of existing projects change through a project transfer
+	DisplayName *string               `json:"display_name" validate:"max=128"`                        // 显示名称 Display name
+	Description *string               `json:"description" validate:"max=1024"`                        // 描述 Description
+	Visibility  *constants.Visibility `json:"visibility"`                                             // 可见性 Visibility
+	Labels      *map[string]string    `json:"labels"`                                                 // 项目标签，设置时替换全部标签 Project labels, replacing all of them when set
+}
+
+// CheckpointReq 执行 SQLite WAL 检查点的请求参数
+// Request parameters to run a SQLite WAL checkpoint
+type CheckpointReq struct {
+	Mode string `json:"mode" validate:"oneof=passive full restart truncate"` // 检查点模式，默认 passive，truncate 会清空 WAL 文件 Checkpoint mode, passive by default, truncate empties the WAL file
+}
+
+// BulkDisableUsersReq 批量停用用户的请求参数
+// Request parameters to disable users in bulk
+type BulkDisableUsersReq struct {
+	UserIDs []uint `json:"user_ids" validate:"required,max=1000"` // 停用的用户ID，系统管理员和自己会被跳过 IDs of the users to disable, the system administrator and yourself are skipped
+	DryRun  bool   `json:"dry_run"`                               // 只列出将被停用的用户 Only list the users that would be disabled
+}
+
+// BulkDeleteProjectsReq 批量删除长期未部署的项目的请求参数，项目移入回收站
+// Request parameters to delete projects not deployed for a long time in bulk, the projects go to the trash
+type BulkDeleteProjectsReq struct {
+	InactiveDays int    `json:"inactive_days" validate:"required,min=1"`       // 创建已超过且期间没有部署的天数 Days the projects have existed without any deployment
+	OwnerType    string `json:"owner_type" validate:"oneof=user organization"` // 只删除此类所有者的项目 Only delete projects of this owner type
+	OwnerID      uint   `json:"owner_id"`                                      // 只删除此所有者的项目，需要同时指定 owner_type Only delete projects of this owner, requires owner_type
+	DryRun       bool   `json:"dry_run"`                                       // 只列出将被删除的项目 Only list the projects that would be deleted
+}
+
+// BulkReassignProjectsReq 批量更换项目所有者的请求参数
+// Request parameters to reassign the owner of projects in bulk
+type BulkReassignProjectsReq struct {
+	FromOwnerType string `json:"from_owner_type" validate:"required,oneof=user organization"` // 原所有者类型 Current owner type
+	FromOwnerID   uint   `json:"from_owner_id" validate:"required"`                           // 原所有者ID Current owner ID
+	ToOwnerType   string `json:"to_owner_type" validate:"required,oneof=user organization"`   // 新所有者类型 New owner type
+	ToOwnerID     uint   `json:"to_owner_id" validate:"required"`                             // 新所有者ID New owner ID
+	ProjectIDs    []uint `json:"project_ids" validate:"max=1000"`                             // 只转移原所有者的这些项目，为空时转移全部 Only reassign these projects of the current owner, all of them when empty
+	DryRun        bool   `json:"dry_run"`                                                     // 只列出将被转移的项目 Only list the projects that would be reassigned
+}
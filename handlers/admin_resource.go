@@ -0,0 +1,477 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/i18n"
+	"github.com/LiteyukiStudio/spage/middle"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/serve"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+	"gorm.io/gorm"
+)
+
+// 以名称寻址的用户、组织和项目接口供 Terraform 等基础设施即代码工具声明式地管理实例：
+// PUT 不存在时创建、存在时更新，重复提交相同的内容结果不变；响应带有 ETag，
+// 携带 If-Match 时只修改未被他人改动的版本，携带 If-None-Match: * 时只创建不覆盖，条件不满足返回 412
+// Users, organizations and projects addressed by name let infrastructure-as-code tools such as Terraform manage an instance declaratively:
+// PUT creates what is missing and updates what exists, submitting the same content again changes nothing; responses carry an ETag,
+// If-Match only changes the version nobody else has changed, If-None-Match: * only creates and never overwrites, and a failed condition returns 412
+
+// resourceETag 资源表示的强 ETag，由 JSON 内容的哈希生成，内容不变时不变
+// Strong ETag of a resource representation, derived from the hash of its JSON so it stays the same while the content does
+func resourceETag(dto any) string {
+	data, _ := json.Marshal(dto)
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// etagMatches 条件请求头中的 ETag 列表是否包含 etag，* 匹配任意存在的资源；etag 为空表示资源不存在
+// Whether the ETag list of a conditional header contains etag, * matching any existing resource; an empty etag means the resource does not exist
+func etagMatches(header, etag string) bool {
+	if etag == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPreconditions 按 If-Match 和 If-None-Match 检查资源的当前版本，不满足时写入 412 响应
+// Check the current version of the resource against If-Match and If-None-Match, writing a 412 response when they fail
+func checkPreconditions(c *app.RequestContext, etag string) bool {
+	ifMatch := strings.TrimSpace(string(c.GetHeader("If-Match")))
+	ifNoneMatch := strings.TrimSpace(string(c.GetHeader("If-None-Match")))
+	if (ifMatch != "" && !etagMatches(ifMatch, etag)) || (ifNoneMatch != "" && etagMatches(ifNoneMatch, etag)) {
+		resps.PreconditionFailed(c, "The resource does not match the precondition")
+		return false
+	}
+	return true
+}
+
+// respondResource 返回资源及其 ETag，创建时为 201；GET 请求的 If-None-Match 匹配时返回 304
+// Respond with the resource and its ETag, 201 on creation; GET requests whose If-None-Match matches get a 304
+func respondResource(c *app.RequestContext, created bool, key string, dto any) {
+	etag := resourceETag(dto)
+	c.Response.Header.Set("ETag", etag)
+	if string(c.Method()) == http.MethodGet && etagMatches(string(c.GetHeader("If-None-Match")), etag) {
+		c.SetStatusCode(http.StatusNotModified)
+		return
+	}
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	resps.Custom(c, status, resps.OK, map[string]any{key: dto})
+}
+
+// resourceName 路径中的名称，名称不区分大小写
+// Name from the path, names are case-insensitive
+func resourceName(c *app.RequestContext) string {
+	return strings.ToLower(strings.TrimSpace(c.Param("name")))
+}
+
+// checkNewName 新资源的名称须为 slug 且不是保留名称，失败时写入 400 响应
+// Names of new resources must be slugs and not reserved, writing a 400 response otherwise
+func checkNewName(c *app.RequestContext, name string) bool {
+	if !utils.Validator.Slug(name) || slices.Contains(constants.ReservedNames, name) {
+		resps.BadRequest(c, "name must be a slug that is not reserved")
+		return false
+	}
+	return true
+}
+
+// lookupError 按名称查找资源的错误，不存在不算错误；其他错误写入响应
+// Error of looking a resource up by name, a missing record is no error; other errors are written to the response
+func lookupError(c *app.RequestContext, err error) bool {
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		resps.DBError(c, err, "Failed to get resource")
+		return true
+	}
+	return false
+}
+
+// GetUserByName 按名称获取用户，响应带有 ETag
+// Get a user by name, the response carries an ETag
+func (AdminApi) GetUserByName(ctx context.Context, c *app.RequestContext) {
+	user, err := store.User.GetByName(resourceName(c))
+	if err != nil {
+		resps.DBError(c, err, "Failed to get resource")
+		return
+	}
+	respondResource(c, false, "user", User.ToDTO(user, true))
+}
+
+// PutUser 按名称创建或更新用户，密码只在提交时设置；不能修改系统管理员和自己的角色
+// Create or update a user by name, the password is only set when submitted; the role of the system administrator and of oneself cannot change
+func (AdminApi) PutUser(ctx context.Context, c *app.RequestContext) {
+	admin := middle.Auth.GetUser(ctx, c)
+	if admin == nil {
+		return
+	}
+	name := resourceName(c)
+	req := &AdminUserReq{}
+	if !bindJSON(c, req) {
+		return
+	}
+	if req.Role != "" && !req.Role.Valid() {
+		resps.BadRequest(c, "invalid role")
+		return
+	}
+	if req.Language != "" {
+		if req.Language = i18n.Normalize(req.Language); req.Language == "" {
+			resps.BadRequest(c, "unsupported language, use en or zh-cn")
+			return
+		}
+	}
+	user, err := store.User.GetByName(name)
+	if lookupError(c, err) {
+		return
+	}
+	created := user == nil
+	etag := ""
+	if !created {
+		etag = resourceETag(User.ToDTO(user, true))
+	}
+	if !checkPreconditions(c, etag) {
+		return
+	}
+	if created {
+		if !checkNewName(c, name) {
+			return
+		}
+		user = &models.User{Name: name, Role: constants.RoleUser}
+	}
+	if req.Password != nil && *req.Password != "" {
+		if !utils.Password.CheckPasswordComplexity(*req.Password, config.GetInt("password_complexity", 3)) {
+			resps.BadRequest(c, "Password complexity is too low")
+			return
+		}
+		hashed, err := utils.Password.HashPassword(*req.Password)
+		if err != nil {
+			resps.InternalServerError(c, "Failed to hash password")
+			return
+		}
+		user.Password = &hashed
+	}
+	if req.Role != "" && req.Role != user.Role {
+		if user.Flag == constants.FlagSystemAdmin || user.ID == admin.ID {
+			resps.Forbidden(c, "cannot change the role of the system administrator or yourself")
+			return
+		}
+		user.Role = req.Role
+	}
+	// 修改邮箱后需要重新验证 A changed email has to be verified again
+	emailChanged := req.Email != nil && !sameEmail(user.Email, req.Email)
+	if emailChanged {
+		user.Email, user.EmailVerifiedAt = req.Email, nil
+		if *req.Email == "" {
+			user.Email = nil
+		}
+	}
+	if req.DisplayName != nil {
+		user.DisplayName = req.DisplayName
+	}
+	if req.Description != nil {
+		user.Description = *req.Description
+	}
+	if req.Language != "" {
+		user.Language = req.Language
+	}
+	disabled := req.Disabled != nil && *req.Disabled && user.DisabledAt == nil
+	if req.Disabled != nil && *req.Disabled != (user.DisabledAt != nil) {
+		if user.Flag == constants.FlagSystemAdmin || user.ID == admin.ID {
+			resps.Forbidden(c, "cannot disable the system administrator or yourself")
+			return
+		}
+		user.DisabledAt = nil
+		if *req.Disabled {
+			now := time.Now()
+			user.DisabledAt = &now
+		}
+	}
+	if created {
+		err = store.User.Create(user)
+	} else {
+		err = store.User.Update(user)
+	}
+	if err != nil {
+		resps.DBError(c, err, "Failed to save user")
+		return
+	}
+	if emailChanged && user.Email != nil {
+		_ = store.UserToken.Void(user.ID, constants.UserTokenVerifyEmail)
+		_ = User.sendVerification(ctx, user)
+	}
+	if disabled {
+		if err := store.JWT.RevokeTokenByUserID(user.ID); err != nil {
+			utils.Log.Ctx(ctx).Warnf("failed to revoke the sessions of disabled user %d: %v", user.ID, err)
+		}
+	}
+	// 重新读取以返回数据库中的默认值 Read again to return the defaults of the database
+	if user, err = store.User.GetByID(user.ID); err != nil {
+		resps.DBError(c, err, "Failed to save user")
+		return
+	}
+	action := constants.AuditUserUpdate
+	if created {
+		action = constants.AuditUserCreate
+	}
+	Audit.record(ctx, c, admin, action, constants.AuditTargetUser, user.ID, map[string]any{"name": user.Name, "role": user.Role, "password_changed": req.Password != nil && *req.Password != "", "disabled": user.DisabledAt != nil})
+	respondResource(c, created, "user", User.ToDTO(user, true))
+}
+
+// GetOrgByName 按名称获取组织，响应带有 ETag
+// Get an organization by name, the response carries an ETag
+func (AdminApi) GetOrgByName(ctx context.Context, c *app.RequestContext) {
+	org, err := store.Org.GetOrgByName(resourceName(c))
+	if err != nil {
+		resps.DBError(c, err, "Failed to get resource")
+		return
+	}
+	respondResource(c, false, "organization", Org.ToDTO(org))
+}
+
+// PutOrg 按名称创建或更新组织，owners 中的用户成为所有者，创建时第一个用户为创建者
+// Create or update an organization by name, users of owners become owners and the first one is the creator on creation
+func (AdminApi) PutOrg(ctx context.Context, c *app.RequestContext) {
+	admin := middle.Auth.GetUser(ctx, c)
+	if admin == nil {
+		return
+	}
+	name := resourceName(c)
+	req := &AdminOrgReq{}
+	if !bindJSON(c, req) {
+		return
+	}
+	owners := make([]*models.User, 0, len(req.Owners))
+	for _, ownerName := range req.Owners {
+		owner, err := store.User.GetByName(ownerName)
+		if err != nil {
+			resps.BadRequestf(c, "user %s does not exist", ownerName)
+			return
+		}
+		owners = append(owners, owner)
+	}
+	org, err := store.Org.GetOrgByName(name)
+	if lookupError(c, err) {
+		return
+	}
+	created := err != nil
+	etag := ""
+	if !created {
+		etag = resourceETag(Org.ToDTO(org))
+	}
+	if !checkPreconditions(c, etag) {
+		return
+	}
+	if created {
+		if !checkNewName(c, name) {
+			return
+		}
+		if len(owners) == 0 {
+			resps.BadRequest(c, "owners are required to create an organization")
+			return
+		}
+		org = &models.Organization{Name: name, DisplayName: &name, TenantID: owners[0].TenantID}
+	}
+	for _, owner := range owners {
+		if !sameTenant(owner.TenantID, org.TenantID) {
+			resps.BadRequestf(c, "user %s belongs to another tenant", owner.Name)
+			return
+		}
+	}
+	if req.DisplayName != nil {
+		org.DisplayName = req.DisplayName
+	}
+	if req.Email != nil {
+		org.Email = req.Email
+	}
+	if req.Description != nil {
+		org.Description = *req.Description
+	}
+	if created {
+		err = store.Org.CreateOrg(org, owners[0])
+	} else {
+		err = store.Org.UpdateOrg(org)
+	}
+	if err != nil {
+		resps.DBError(c, err, "Failed to save organization")
+		return
+	}
+	for _, owner := range owners {
+		if store.Org.GetUserAuth(org, owner.ID) == constants.OrgRoleOwner {
+			continue
+		}
+		if err := store.Org.SetMember(org.ID, owner.ID, constants.OrgRoleOwner); err != nil {
+			resps.DBError(c, err, "Failed to save organization")
+			return
+		}
+	}
+	if org, err = store.Org.GetOrgById(org.ID); err != nil {
+		resps.DBError(c, err, "Failed to save organization")
+		return
+	}
+	action := constants.AuditOrgUpdate
+	if created {
+		action = constants.AuditOrgCreate
+	}
+	Audit.record(ctx, c, admin, action, constants.AuditTargetOrg, org.ID, map[string]any{"name": org.Name, "display_name": org.DisplayName, "owners": req.Owners})
+	respondResource(c, created, "organization", Org.ToDTO(org))
+}
+
+// GetProjectByName 按名称获取项目，响应带有 ETag
+// Get a project by name, the response carries an ETag
+func (AdminApi) GetProjectByName(ctx context.Context, c *app.RequestContext) {
+	project, err := store.Project.GetByName(resourceName(c))
+	if err != nil {
+		resps.DBError(c, err, "Failed to get resource")
+		return
+	}
+	dto, err := Admin.projectDTO(project)
+	if err != nil {
+		resps.DBError(c, err, "Failed to get labels")
+		return
+	}
+	respondResource(c, false, "project", dto)
+}
+
+// PutProject 按名称创建或更新项目，创建时检查所有者的配额；已有项目的所有者不同时返回 409，须通过项目转移修改
+// Create or update a project by name, checking the owner's quota on creation; a different owner of an existing project returns 409 as it changes through a project transfer
+func (AdminApi) PutProject(ctx context.Context, c *app.RequestContext) {
+	admin := middle.Auth.GetUser(ctx, c)
+	if admin == nil {
+		return
+	}
+	name := resourceName(c)
+	req := &AdminProjectReq{}
+	if !bindJSON(c, req) {
+		return
+	}
+	var ownerID uint
+	if req.OwnerType == constants.OwnerTypeOrg {
+		org, err := store.Org.GetOrgByName(req.Owner)
+		if err != nil {
+			resps.BadRequestf(c, "organization %s does not exist", req.Owner)
+			return
+		}
+		ownerID = org.ID
+	} else {
+		user, err := store.User.GetByName(req.Owner)
+		if err != nil {
+			resps.BadRequestf(c, "user %s does not exist", req.Owner)
+			return
+		}
+		ownerID = user.ID
+	}
+	var labels []models.ProjectLabel
+	if req.Labels != nil {
+		var err error
+		if labels, err = Project.parseLabels(*req.Labels); err != nil {
+			resps.BadRequest(c, err.Error())
+			return
+		}
+	}
+	project, err := store.Project.GetByName(name)
+	if lookupError(c, err) {
+		return
+	}
+	created := err != nil
+	etag := ""
+	if !created {
+		dto, err := Admin.projectDTO(project)
+		if err != nil {
+			resps.DBError(c, err, "Failed to get labels")
+			return
+		}
+		etag = resourceETag(dto)
+	}
+	if !checkPreconditions(c, etag) {
+		return
+	}
+	if created {
+		if !checkNewName(c, name) {
+			return
+		}
+		if err := Quota.checkProjects(req.OwnerType, ownerID); errors.Is(err, errQuotaExceeded) {
+			resps.Forbidden(c, err.Error())
+			return
+		} else if err != nil {
+			resps.InternalServerError(c, err.Error())
+			return
+		}
+		project = &models.Project{Name: name, OwnerType: req.OwnerType, OwnerID: ownerID, Labels: labels}
+	} else if project.OwnerType != req.OwnerType || project.OwnerID != ownerID {
+		resps.Fail(c, http.StatusConflict, resps.CodeConflict, "the owner of an existing project changes through a project transfer", nil)
+		return
+	}
+	if req.DisplayName != nil {
+		project.DisplayName = req.DisplayName
+	}
+	if req.Description != nil {
+		project.Description = *req.Description
+	}
+	if req.Visibility != nil {
+		project.Visibility = *req.Visibility
+	}
+	if created {
+		err = store.Project.Create(project)
+	} else {
+		err = store.Project.Update(project)
+	}
+	if err != nil {
+		resps.DBError(c, err, "Failed to save project")
+		return
+	}
+	if !created && req.Labels != nil {
+		if err := store.Project.SetLabels(project, labels); err != nil {
+			resps.DBError(c, err, "Failed to update labels")
+			return
+		}
+	}
+	if !created {
+		serve.SiteCache.ForgetProject(ctx, project.ID)
+	}
+	if project, err = store.Project.GetByID(project.ID); err != nil {
+		resps.DBError(c, err, "Failed to save project")
+		return
+	}
+	dto, err := Admin.projectDTO(project)
+	if err != nil {
+		resps.DBError(c, err, "Failed to get labels")
+		return
+	}
+	action := constants.AuditProjectUpdate
+	if created {
+		action = constants.AuditProjectCreate
+	}
+	Audit.record(ctx, c, admin, action, constants.AuditTargetProject, project.ID, map[string]any{"name": project.Name, "owner_type": project.OwnerType, "owner_id": project.OwnerID})
+	respondResource(c, created, "project", dto)
+}
+
+// projectDTO 带有标签的完整项目信息
+// Full project information including the labels
+func (AdminApi) projectDTO(project *models.Project) (ProjectDTO, error) {
+	projects := []models.Project{*project}
+	if err := store.Project.LoadLabels(projects...); err != nil {
+		return ProjectDTO{}, err
+	}
+	return Project.toDTO(&projects[0], true), nil
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+const (
+	// defaultAnalyticsLimit 每个维度默认返回的值数量
+	// Values returned per dimension by default
+	defaultAnalyticsLimit = 10
+	// maxAnalyticsLimit 每个维度最多返回的值数量
+	// Max values returned per dimension
+	maxAnalyticsLimit = 100
+)
+
+type AnalyticsApi struct{}
+
+// Analytics 站点访问分析，数据来自每日汇总
+// Site analytics, read from the daily rollups
+var Analytics = AnalyticsApi{}
+
+// Site 获取站点最近 days 天的访问分析，默认 30 天，每个维度返回浏览量最多的 limit 个值，并附带本月的流量及其限制
+// Get the analytics of the site over the last days days, 30 by default, with the limit values of each dimension with the most views and the transfer of this month with its limits
+func (AnalyticsApi) Site(ctx context.Context, c *app.RequestContext) {
+	site := getSite(c)
+	if site == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	days, limit := defaultStatsDays, defaultAnalyticsLimit
+	for _, param := range []struct {
+		name  string
+		value *int
+		max   int
+	}{{"days", &days, maxStatsDays}, {"limit", &limit, maxAnalyticsLimit}} {
+		raw := c.Query(param.name)
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > param.max {
+			resps.BadRequest(c, param.name+" must be between 1 and "+strconv.Itoa(param.max))
+			return
+		}
+		*param.value = n
+	}
+	// 先写入本实例尚未写入的浏览量 Write the views this instance has not written yet first
+	if err := store.Analytics.Flush(ctx); err != nil {
+		utils.Log.Ctx(ctx).Warnf("failed to write site analytics: %v", err)
+	}
+	since := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 1-days)
+	dto := SiteAnalyticsDTO{Days: days}
+	daily, err := store.Analytics.Daily(ctx, site.ID, since)
+	if err != nil {
+		resps.DBError(c, err, "Failed to get analytics")
+		return
+	}
+	dto.Daily = make([]DailyCountDTO, 0, len(daily))
+	for _, day := range daily {
+		dto.Views += day.Views
+		dto.Daily = append(dto.Daily, DailyCountDTO{Day: day.Day.UTC().Format(time.DateOnly), Count: day.Views})
+	}
+	for _, dimension := range []struct {
+		name   string
+		values *[]AnalyticsValueDTO
+	}{
+		{models.AnalyticsPath, &dto.Paths},
+		{models.AnalyticsReferrer, &dto.Referrers},
+		{models.AnalyticsCountry, &dto.Countries},
+		{models.AnalyticsStatus, &dto.Statuses},
+	} {
+		values, err := store.Analytics.Top(ctx, site.ID, dimension.name, since, limit)
+		if err != nil {
+			resps.DBError(c, err, "Failed to get analytics")
+			return
+		}
+		*dimension.values = make([]AnalyticsValueDTO, 0, len(values))
+		for _, value := range values {
+			*dimension.values = append(*dimension.values, AnalyticsValueDTO{Value: value.Value, Views: value.Views})
+		}
+	}
+	now := time.Now()
+	sum, err := store.Traffic.Month(ctx, site.ID, now)
+	if err != nil {
+		resps.DBError(c, err, "Failed to get analytics")
+		return
+	}
+	dto.Transfer = Bandwidth.toDTO(site, sum, now)
+	resps.Ok(c, resps.OK, map[string]any{"analytics": dto})
+}
@@ -0,0 +1,21 @@
+package handlers
+
+// SiteAnalyticsDTO 站点访问分析数据传输对象
+// Site analytics Data Transfer Object (DTO)
+type SiteAnalyticsDTO struct {
+	Days      int                 `json:"days"`      // 统计的天数 Days covered
+	Views     int64               `json:"views"`     // 总浏览量 Total page views
+	Daily     []DailyCountDTO     `json:"daily"`     // 每天的浏览量 Page views per day
+	Paths     []AnalyticsValueDTO `json:"paths"`     // 浏览量最多的路径 Paths with the most views
+	Referrers []AnalyticsValueDTO `json:"referrers"` // 浏览量最多的来源主机 Referring hosts with the most views
+	Countries []AnalyticsValueDTO `json:"countries"` // 浏览量最多的国家或地区 Countries with the most views
+	Statuses  []AnalyticsValueDTO `json:"statuses"`  // 各状态码的浏览量 Page views by status code
+	Transfer  SiteTransferDTO     `json:"transfer"`  // 本月的流量及其限制 Transfer this month and its limits
+}
+
+// AnalyticsValueDTO 维度值的浏览量
+// Page views of a dimension value
+type AnalyticsValueDTO struct {
+	Value string `json:"value"` // 维度的值，超出记录上限的值合并为 (other) Value of the dimension, values beyond the limit are merged into (other)
+	Views int64  `json:"views"` // 浏览量 Page views
+}
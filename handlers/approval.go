@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/i18n"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/serve"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+type ApprovalApi struct{}
+
+// Approval 受保护项目的部署批准，新部署在维护者批准后才上线
+// Approval of deployments to protected projects, new deployments only go live once a maintainer approves them
+var Approval = ApprovalApi{}
+
+// request 记录版本等待批准并通知 webhook 和通知渠道
+// Record that a version awaits approval and tell the webhooks and notification channels
+func (ApprovalApi) request(ctx context.Context, site *models.Site, release *models.SiteRelease) {
+	utils.Log.Ctx(ctx).Infof("release %d of site %d is awaiting approval", release.ID, site.ID)
+	Webhook.emit(ctx, &site.Project, constants.WebhookDeploymentAwaitingApproval,
+		i18n.Msg("Release %s of site %s is waiting for a maintainer's approval", release.Tag, site.Name),
+		map[string]any{"site_id": site.ID, "site": site.Name, "release_id": release.ID, "tag": release.Tag})
+}
+
+// List 分页获取项目中等待批准的部署
+// List the deployments of the project awaiting approval with pagination
+func (ApprovalApi) List(ctx context.Context, c *app.RequestContext) {
+	project := getProject(c)
+	if project == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	page, limit := utils.Ctx.GetPageLimit(c)
+	releases, total, err := store.Site.ListAwaitingApproval(ctx, project.ID, page, limit)
+	if err != nil {
+		resps.DBError(c, err, "get releases error")
+		return
+	}
+	releaseDTOs := make([]ReleaseDTO, 0, len(releases))
+	for i := range releases {
+		releaseDTOs = append(releaseDTOs, Release.ToDTO(&releases[i]))
+	}
+	utils.Ctx.SetPageHeaders(c, page, limit, total)
+	resps.Ok(c, resps.OK, map[string]any{
+		"releases": releaseDTOs,
+		"total":    total,
+	})
+}
+
+// getAwaiting 获取路径中属于当前项目且等待批准的版本及其站点，失败时已写入响应
+// Get the version of the path that belongs to the current project and awaits approval, with its site; the response is already written on failure
+func (ApprovalApi) getAwaiting(c *app.RequestContext) (*models.SiteRelease, *models.Site) {
+	project := getProject(c)
+	id, err := strconv.Atoi(c.Param("release_id"))
+	if project == nil || err != nil {
+		resps.BadRequest(c, resps.ParameterError)
+		return nil, nil
+	}
+	release, err := store.Site.GetReleaseById(uint(id))
+	if err != nil || release.Tag == constants.ReleaseTagLatest {
+		resps.NotFound(c, resps.TargetNotFound)
+		return nil, nil
+	}
+	site, err := store.Site.GetByID(release.SiteID)
+	if err != nil || site.ProjectID != project.ID {
+		resps.NotFound(c, resps.TargetNotFound)
+		return nil, nil
+	}
+	if release.Status != constants.DeploymentStatusAwaitingApproval {
+		resps.BadRequestf(c, "release is %s, not awaiting approval", release.Status)
+		return nil, nil
+	}
+	release.Site = *site
+	return release, site
+}
+
+// Approve 维护者批准部署：定时上线时间未到时恢复定时，部署时要求上线或定时上线时间已过时立即激活，其余恢复为普通版本
+// A maintainer approves a deployment: it is scheduled again while its go-live time is ahead, activated right away when the deployment asked for it or the go-live time has passed, and becomes a plain version otherwise
+func (ApprovalApi) Approve(ctx context.Context, c *app.RequestContext) {
+	release, site := Approval.getAwaiting(c)
+	if release == nil {
+		return
+	}
+	activate := release.ApprovalActivate
+	release.Status = constants.DeploymentStatusReady
+	if release.PublishAt != nil {
+		if release.PublishAt.After(time.Now()) {
+			release.Status = constants.DeploymentStatusScheduled
+		} else {
+			activate = true
+		}
+	}
+	release.ApprovalActivate = false
+	if !Approval.decide(c, release) {
+		return
+	}
+	details := map[string]any{"decision": "approve", "release_id": release.ID, "tag": release.Tag, "status": release.Status}
+	releaseDTO := Release.ToDTO(release)
+	if activate {
+		previousID, err := store.Site.ActivateRelease(ctx, release)
+		if err != nil {
+			resps.DBError(c, err, errActivate.Error())
+			return
+		}
+		serve.SiteCache.Forget(ctx, site.ID)
+		utils.Log.Ctx(ctx).Infof("site %d switched from release %d to approved release %d", site.ID, previousID, release.ID)
+		details["previous_release_id"] = previousID
+		Release.notifyDeployed(ctx, site, release, previousID)
+		releaseDTO.Active = true
+	}
+	Audit.record(ctx, c, nil, constants.AuditReleaseApproval, constants.AuditTargetSite, site.ID, details)
+	resps.Ok(c, resps.OK, map[string]any{
+		"release": releaseDTO,
+	})
+}
+
+// Reject 维护者驳回部署，版本标记为失败且不再提供服务，项目的 webhook 收到部署失败通知
+// A maintainer rejects a deployment, which is marked failed and never served, the project's webhooks are told the deployment failed
+func (ApprovalApi) Reject(ctx context.Context, c *app.RequestContext) {
+	req := ApprovalRejectReq{}
+	if len(c.Request.Body()) > 0 {
+		if !bindJSON(c, &req) {
+			return
+		}
+	}
+	release, site := Approval.getAwaiting(c)
+	if release == nil {
+		return
+	}
+	release.Status = constants.DeploymentStatusFailed
+	release.ApprovalActivate = false
+	if !Approval.decide(c, release) {
+		return
+	}
+	reason := "rejected by a maintainer"
+	if req.Reason != "" {
+		reason += ": " + req.Reason
+	}
+	Audit.record(ctx, c, nil, constants.AuditReleaseApproval, constants.AuditTargetSite, site.ID, map[string]any{
+		"decision":   "reject",
+		"release_id": release.ID,
+		"tag":        release.Tag,
+		"reason":     req.Reason,
+	})
+	Webhook.emit(ctx, &site.Project, constants.WebhookDeploymentFailed,
+		i18n.Msg("Deployment of %s to site %s failed: %s", release.Tag, site.Name, reason),
+		map[string]any{"site_id": site.ID, "site": site.Name, "release_id": release.ID, "tag": release.Tag, "reason": reason})
+	resps.Ok(c, resps.OK, map[string]any{
+		"release": Release.ToDTO(release),
+	})
+}
+
+// decide 保存维护者的决定，其他维护者已先处理时返回冲突；失败时已写入响应
+// Save the maintainer's decision, answering with a conflict when another maintainer decided first; the response is already written on failure
+func (ApprovalApi) decide(c *app.RequestContext, release *models.SiteRelease) bool {
+	saved, err := store.Site.SetApproval(release)
+	if err != nil {
+		resps.DBError(c, err, "update release error")
+		return false
+	}
+	if !saved {
+		resps.Fail(c, http.StatusConflict, resps.CodeConflict, "release is no longer awaiting approval", nil)
+		return false
+	}
+	return true
+}
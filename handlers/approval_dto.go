@@ -0,0 +1,7 @@
+package handlers
+
+// ApprovalRejectReq 驳回等待批准的部署的请求参数
+// Request parameters to reject a deployment awaiting approval
+type ApprovalRejectReq struct {
+	Reason string `json:"reason" validate:"max=255"` // 驳回原因，随 webhook 通知项目 Reason of the rejection, sent to the project's webhooks
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"strconv"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/middle"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+type AuditApi struct{}
+
+var Audit = AuditApi{}
+
+// record 记录审计日志，actor 为空时使用当前登录用户；写入失败只记录警告，不影响请求本身
+// Record an audit entry, using the current user when actor is nil; a failed write only logs a warning and does not fail the request
+func (AuditApi) record(ctx context.Context, c *app.RequestContext, actor *models.User, action, targetType string, targetID uint, details map[string]any) {
+	entry := &models.AuditLog{
+		Action:     action,
+		TargetType: targetType,
+	}
+	// 后台任务触发的操作没有请求 Operations triggered by background jobs have no request
+	if c != nil {
+		if actor == nil {
+			actor = middle.Auth.GetUser(ctx, c)
+		}
+		entry.IP = c.ClientIP()
+	}
+	if actor != nil {
+		entry.ActorID, entry.ActorName = actor.ID, actor.Name
+	}
+	// 代为登录期间的操作记录管理员 Operations during an impersonation record the administrator
+	if c != nil && middle.Auth.ImpersonatorID(c) != 0 {
+		details = maps.Clone(details)
+		if details == nil {
+			details = map[string]any{}
+		}
+		details["impersonator_id"] = middle.Auth.ImpersonatorID(c)
+	}
+	// 部署密钥执行的操作记录密钥 Operations performed with a deploy key record the key
+	if c != nil && middle.Auth.DeployKeyID(c) != 0 {
+		details = maps.Clone(details)
+		if details == nil {
+			details = map[string]any{}
+		}
+		details["deploy_key_id"] = middle.Auth.DeployKeyID(c)
+	}
+	if targetID != 0 {
+		entry.TargetID = strconv.Itoa(int(targetID))
+	}
+	if len(details) > 0 {
+		data, err := json.Marshal(details)
+		if err != nil {
+			utils.Log.Ctx(ctx).Warnf("failed to encode audit details of %s: %v", action, err)
+		}
+		entry.Details = string(data)
+	}
+	if err := store.Audit.Append(ctx, entry); err != nil {
+		utils.Log.Ctx(ctx).Warnf("failed to record audit log %s: %v", action, err)
+	}
+}
+
+func (AuditApi) toDTO(entry *models.AuditLog) AuditLogDTO {
+	auditDTO := AuditLogDTO{
+		ID:         entry.ID,
+		CreatedAt:  entry.CreatedAt,
+		ActorID:    entry.ActorID,
+		ActorName:  entry.ActorName,
+		Action:     entry.Action,
+		TargetType: entry.TargetType,
+		TargetID:   entry.TargetID,
+		IP:         entry.IP,
+		Hash:       entry.Hash,
+	}
+	if entry.Details != "" {
+		auditDTO.Details = json.RawMessage(entry.Details)
+	}
+	return auditDTO
+}
+
+// List 分页查询审计日志
+// Query audit logs with pagination
+func (AuditApi) List(ctx context.Context, c *app.RequestContext) {
+	req := AuditListReq{}
+	if !bind(c, &req) {
+		return
+	}
+	filter := store.AuditFilter{
+		ActorID:    req.ActorID,
+		Action:     req.Action,
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+	}
+	for _, bound := range []struct {
+		value string
+		t     *time.Time
+	}{{req.Since, &filter.Since}, {req.Until, &filter.Until}} {
+		if bound.value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, bound.value)
+		if err != nil {
+			resps.BadRequest(c, "since and until must be RFC 3339 times")
+			return
+		}
+		*bound.t = parsed
+	}
+	list, ok := getListQuery(c, "-id", "id", "created_at", "action")
+	if !ok {
+		return
+	}
+	logs, total, err := store.Audit.List(ctx, filter, list)
+	if err != nil {
+		resps.DBError(c, err, "get audit logs error")
+		return
+	}
+	utils.Ctx.SetPageHeaders(c, list.Page, list.Limit, total)
+	resps.Ok(c, resps.OK, map[string]any{
+		"logs": func() (logDTOs []AuditLogDTO) {
+			for _, entry := range logs {
+				logDTOs = append(logDTOs, Audit.toDTO(&entry))
+			}
+			return
+		}(),
+		"total": total,
+	})
+}
+
+// Verify 校验审计日志的哈希链
+// Verify the hash chain of the audit log
+func (AuditApi) Verify(ctx context.Context, c *app.RequestContext) {
+	result, err := store.Audit.Verify()
+	if err != nil {
+		resps.DBError(c, err, "verify audit logs error")
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"verification": result,
+	})
+}
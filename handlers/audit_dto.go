@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditLogDTO 审计日志数据传输对象
+// Audit Log Data Transfer Object (DTO)
+type AuditLogDTO struct {
+	ID         uint            `json:"id"`          // 日志ID Log ID
+	CreatedAt  time.Time       `json:"created_at"`  // 发生时间 Event time
+	ActorID    uint            `json:"actor_id"`    // 操作者ID Actor ID
+	ActorName  string          `json:"actor_name"`  // 操作者名称 Actor name
+	Action     string          `json:"action"`      // 操作 Action
+	TargetType string          `json:"target_type"` // 目标类型 Target type
+	TargetID   string          `json:"target_id"`   // 目标ID Target ID
+	IP         string          `json:"ip"`          // 客户端IP Client IP
+	Details    json.RawMessage `json:"details"`     // 详细信息 Details
+	Hash       string          `json:"hash"`        // 记录哈希 Entry hash
+}
+
+// AuditListReq 审计日志查询参数
+// Audit log query parameters
+type AuditListReq struct {
+	ActorID    uint   `form:"actor_id"`    // 操作者ID Actor ID
+	Action     string `form:"action"`      // 操作 Action
+	TargetType string `form:"target_type"` // 目标类型 Target type
+	TargetID   string `form:"target_id"`   // 目标ID Target ID
+	Since      string `form:"since"`       // 起始时间（RFC 3339） Start time (RFC 3339)
+	Until      string `form:"until"`       // 结束时间（RFC 3339） End time (RFC 3339)
+}
@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/middle"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/storage"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+type AvatarApi struct{}
+
+var Avatar = AvatarApi{}
+
+// avatarURL 头像地址：上传的头像优先，其次是设置的地址，最后按邮箱使用 Gravatar，都没有时为空；
+// 上传的头像地址包含内容哈希，更换后地址随之变化，可以长期缓存
+// Avatar URL: the uploaded avatar comes first, then the configured URL and finally Gravatar by email, nil when there is none;
+// URLs of uploaded avatars contain the content hash and change with the avatar, so they can be cached for long
+func avatarURL(ownerType string, ownerID uint, hash string, url, email *string) *string {
+	switch {
+	case hash != "":
+		uploaded := strings.TrimSuffix(config.FrontEndURL, "/") + "/api/v1/avatars/" + ownerType + "/" + strconv.FormatUint(uint64(ownerID), 10) + "/" + hash + ".png"
+		return &uploaded
+	case url != nil && *url != "":
+		return url
+	case config.AvatarGravatar && email != nil && *email != "":
+		gravatar := utils.Avatar.Gravatar(config.AvatarGravatarURL, *email, utils.Avatar.Size(0))
+		return &gravatar
+	}
+	return nil
+}
+
+// userAvatar 用户的头像地址
+// Avatar URL of a user
+func userAvatar(user *models.User) *string {
+	return avatarURL(constants.OwnerTypeUser, user.ID, user.AvatarHash, user.AvatarURL, user.Email)
+}
+
+// orgAvatar 组织的标志地址
+// Logo URL of an organization
+func orgAvatar(org *models.Organization) *string {
+	return avatarURL(constants.OwnerTypeOrg, org.ID, org.AvatarHash, org.AvatarURL, org.Email)
+}
+
+// avatarChanged 提交的头像地址是否不同于当前展示的地址，原样提交读取到的地址时保留上传的头像
+// Whether the submitted avatar URL differs from the one currently shown, submitting the URL as read keeps the uploaded avatar
+func avatarChanged(submitted, current *string) bool {
+	if submitted == nil || *submitted == "" {
+		return current != nil
+	}
+	return current == nil || *submitted != *current
+}
+
+// UploadUser 上传当前用户的头像
+// Upload the avatar of the current user
+func (AvatarApi) UploadUser(ctx context.Context, c *app.RequestContext) {
+	user := middle.Auth.GetUser(ctx, c)
+	if user == nil {
+		return
+	}
+	hash, ok := Avatar.upload(ctx, c, constants.OwnerTypeUser, user.ID)
+	if !ok {
+		return
+	}
+	if err := store.User.SetAvatar(user.ID, hash, nil); err != nil {
+		resps.DBError(c, err, "Failed to update user")
+		return
+	}
+	if user.AvatarHash != hash {
+		Avatar.remove(ctx, constants.OwnerTypeUser, user.ID, user.AvatarHash)
+	}
+	user.AvatarHash, user.AvatarURL = hash, nil
+	resps.Ok(c, resps.OK, map[string]any{"avatar_url": userAvatar(user)})
+}
+
+// DeleteUser 删除当前用户上传或设置的头像，改用 Gravatar
+// Delete the avatar the current user uploaded or set, falling back to Gravatar
+func (AvatarApi) DeleteUser(ctx context.Context, c *app.RequestContext) {
+	user := middle.Auth.GetUser(ctx, c)
+	if user == nil {
+		return
+	}
+	if err := store.User.SetAvatar(user.ID, "", nil); err != nil {
+		resps.DBError(c, err, "Failed to update user")
+		return
+	}
+	Avatar.remove(ctx, constants.OwnerTypeUser, user.ID, user.AvatarHash)
+	user.AvatarHash, user.AvatarURL = "", nil
+	resps.Ok(c, resps.OK, map[string]any{"avatar_url": userAvatar(user)})
+}
+
+// UploadOrg 上传组织的标志，显示在控制台中
+// Upload the logo of the organization shown in the dashboard
+func (AvatarApi) UploadOrg(ctx context.Context, c *app.RequestContext) {
+	org := getOrg(c)
+	hash, ok := Avatar.upload(ctx, c, constants.OwnerTypeOrg, org.ID)
+	if !ok {
+		return
+	}
+	if err := store.Org.SetAvatar(org.ID, hash, nil); err != nil {
+		resps.DBError(c, err, err.Error())
+		return
+	}
+	if org.AvatarHash != hash {
+		Avatar.remove(ctx, constants.OwnerTypeOrg, org.ID, org.AvatarHash)
+	}
+	org.AvatarHash, org.AvatarURL = hash, nil
+	Audit.record(ctx, c, nil, constants.AuditOrgUpdate, constants.AuditTargetOrg, org.ID, map[string]any{"avatar": hash})
+	resps.Ok(c, resps.OK, map[string]any{"avatar_url": orgAvatar(org)})
+}
+
+// DeleteOrg 删除组织上传或设置的标志
+// Delete the logo the organization uploaded or set
+func (AvatarApi) DeleteOrg(ctx context.Context, c *app.RequestContext) {
+	org := getOrg(c)
+	if err := store.Org.SetAvatar(org.ID, "", nil); err != nil {
+		resps.DBError(c, err, err.Error())
+		return
+	}
+	Avatar.remove(ctx, constants.OwnerTypeOrg, org.ID, org.AvatarHash)
+	org.AvatarHash, org.AvatarURL = "", nil
+	Audit.record(ctx, c, nil, constants.AuditOrgUpdate, constants.AuditTargetOrg, org.ID, map[string]any{"avatar": nil})
+	resps.Ok(c, resps.OK, map[string]any{"avatar_url": orgAvatar(org)})
+}
+
+// Get 获取上传的头像，size 参数选择不小于它的最小标准尺寸；地址包含内容哈希，响应可被长期缓存
+// Get an uploaded avatar, the size parameter picks the smallest standard size not below it; the URL contains the content hash so responses are cached for long
+func (AvatarApi) Get(ctx context.Context, c *app.RequestContext) {
+	ownerType := c.Param("owner_type")
+	ownerID, err := strconv.ParseUint(c.Param("owner_id"), 10, 64)
+	hash, isPNG := strings.CutSuffix(c.Param("file"), ".png")
+	if err != nil || !isPNG || (ownerType != constants.OwnerTypeUser && ownerType != constants.OwnerTypeOrg) || !isAvatarHash(hash) {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	requested, _ := strconv.Atoi(c.Query("size"))
+	size := utils.Avatar.Size(requested)
+	object, err := storage.Default.Get(ctx, storage.AvatarKey(ownerType, uint(ownerID), hash, size))
+	if err != nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	c.Response.Header.Set("Cache-Control", "public, max-age=31536000, immutable")
+	c.Response.Header.Set("ETag", `"`+hash+"-"+strconv.Itoa(size)+`"`)
+	c.Response.Header.SetContentType("image/png")
+	c.SetBodyStream(object, int(object.Info().Size))
+}
+
+// upload 读取请求中的图片，处理后将各尺寸写入存储，返回原图的内容哈希
+// Read the image of the request and write every processed size into the storage, returning the content hash of the original
+func (AvatarApi) upload(ctx context.Context, c *app.RequestContext, ownerType string, ownerID uint) (string, bool) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		resps.BadRequest(c, resps.MissingParameter)
+		return "", false
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		resps.BadRequest(c, resps.ParameterError)
+		return "", false
+	}
+	hash, images, err := utils.Avatar.Process(file, config.OGMaxImageSize)
+	_ = file.Close()
+	if err != nil {
+		resps.BadRequest(c, resps.RespMessageWithError("invalid avatar image", err))
+		return "", false
+	}
+	for size, data := range images {
+		if err := storage.Default.Put(ctx, storage.AvatarKey(ownerType, ownerID, hash, size), bytes.NewReader(data), int64(len(data))); err != nil {
+			utils.Log.Ctx(ctx).Errorf("failed to save avatar of %s %d: %v", ownerType, ownerID, err)
+			resps.InternalServerError(c, "save avatar file error")
+			return "", false
+		}
+	}
+	return hash, true
+}
+
+// remove 删除上传的头像的所有尺寸，hash 为空时不做任何事
+// Delete every size of an uploaded avatar, doing nothing when hash is empty
+func (AvatarApi) remove(ctx context.Context, ownerType string, ownerID uint, hash string) {
+	if hash == "" {
+		return
+	}
+	for _, size := range utils.AvatarSizes {
+		if err := storage.Default.Delete(ctx, storage.AvatarKey(ownerType, ownerID, hash, size)); err != nil {
+			utils.Log.Ctx(ctx).Warnf("failed to delete avatar of %s %d: %v", ownerType, ownerID, err)
+		}
+	}
+}
+
+// isAvatarHash 是否为 utils.Avatar.Process 返回的十六进制哈希
+// Whether the text is a hex hash as returned by utils.Avatar.Process
+func isAvatarHash(hash string) bool {
+	if len(hash) != 16 {
+		return false
+	}
+	for _, r := range hash {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
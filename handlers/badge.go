@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+type BadgeApi struct{}
+
+var Badge = BadgeApi{}
+
+// deployStatusBadges 部署状态对应的徽章文本和颜色 Badge message and color of each deployment status
+var deployStatusBadges = map[constants.DeploymentStatus][2]string{
+	constants.DeploymentStatusReady:            {"ready", utils.BadgeGreen},
+	constants.DeploymentStatusPending:          {"deploying", utils.BadgeYellow},
+	constants.DeploymentStatusFailed:           {"failed", utils.BadgeRed},
+	constants.DeploymentStatusScheduled:        {"scheduled", utils.BadgeGrey},
+	constants.DeploymentStatusQuarantined:      {"under review", utils.BadgeYellow},
+	constants.DeploymentStatusAwaitingApproval: {"awaiting approval", utils.BadgeYellow},
+}
+
+// DeployStatus 获取公开项目最近一次部署状态的 SVG 徽章，可通过 site 参数指定站点，用于嵌入 README
+// Get an SVG badge of the latest deployment status of a public project, the site parameter picks a site, meant to be embedded in READMEs
+func (BadgeApi) DeployStatus(ctx context.Context, c *app.RequestContext) {
+	project, err := store.Project.GetByName(c.Param("project"))
+	// 私有项目与不存在的项目无法区分 Private projects are indistinguishable from missing ones
+	if err != nil || project.Visibility != constants.VisibilityPublic {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	message, color := "none", utils.BadgeGrey
+	if release, err := store.Project.LatestRelease(project.ID, c.Query("site")); err == nil {
+		if badge, ok := deployStatusBadges[release.Status]; ok {
+			message, color = badge[0], badge[1]
+		}
+	}
+	// 缓存时间较短，部署后很快更新 Cached briefly so the badge follows new deployments
+	c.Response.Header.Set("Cache-Control", "public, max-age=60")
+	c.Data(200, "image/svg+xml; charset=utf-8", utils.Badge.Render("deploy", message, color))
+}
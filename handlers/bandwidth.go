@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/i18n"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/serve"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/sirupsen/logrus"
+)
+
+// transferCheckInterval 检查站点每月流量的间隔，与流量统计的写入间隔相同
+// Interval between checks of the monthly transfer of sites, the same as the traffic write interval
+const bandwidthCheckInterval = time.Minute
+
+type BandwidthApi struct{}
+
+// Bandwidth 站点每月流量的限制，超出软限制时通知，超出硬限制时限速或暂停服务
+// Monthly transfer limits of sites, notifying beyond the soft limit and throttling or suspending beyond the hard limit
+var Bandwidth = BandwidthApi{}
+
+// limits 站点生效的软限制和硬限制字节数，0 为不限制
+// Effective soft and hard limits of the site in bytes, 0 is unlimited
+func (BandwidthApi) limits(site *models.Site) (soft, hard int64) {
+	soft, hard = int64(config.TrafficSoftLimit)<<20, int64(config.TrafficHardLimit)<<20
+	if site.TransferSoftLimit != nil {
+		soft = *site.TransferSoftLimit
+	}
+	if site.TransferHardLimit != nil {
+		hard = *site.TransferHardLimit
+	}
+	return
+}
+
+// toDTO 站点本月的流量及其限制
+// Transfer of the site this month and its limits
+func (BandwidthApi) toDTO(site *models.Site, sum store.SiteTrafficSum, now time.Time) SiteTransferDTO {
+	soft, hard := Bandwidth.limits(site)
+	return SiteTransferDTO{
+		Month:     now.UTC().Format("2006-01"),
+		Requests:  sum.Requests,
+		Bytes:     sum.Bytes,
+		SoftLimit: soft,
+		HardLimit: hard,
+		Custom:    site.TransferSoftLimit != nil || site.TransferHardLimit != nil,
+		Action:    serve.Bandwidth.Action(site.ID),
+	}
+}
+
+// Watch 定期检查本月有流量的站点，更新超出硬限制的站点并通知新超出限制的站点，ctx 结束时返回
+// Periodically check the sites with traffic this month, updating the sites beyond their hard limit and notifying those newly beyond a limit, returning once ctx is done
+func (BandwidthApi) Watch(ctx context.Context) {
+	ticker := time.NewTicker(bandwidthCheckInterval)
+	defer ticker.Stop()
+	for {
+		if err := Bandwidth.check(ctx, time.Now()); err != nil {
+			logrus.Warnf("failed to check site transfer: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// check 按本月的流量更新超出硬限制的站点，每个站点每月每个级别只通知一次
+// Update the sites beyond their hard limit from this month's traffic, each site is notified once a month per level
+func (BandwidthApi) check(ctx context.Context, now time.Time) error {
+	usage, err := store.Traffic.MonthlyBytes(ctx, now)
+	if err != nil {
+		return err
+	}
+	ids := make([]uint, 0, len(usage))
+	for id := range usage {
+		ids = append(ids, id)
+	}
+	sites, err := store.Site.GetByIDs(ids)
+	if err != nil {
+		return err
+	}
+	actions := make(map[uint]string)
+	for i := range sites {
+		site := &sites[i]
+		bytes := usage[site.ID]
+		soft, hard := Bandwidth.limits(site)
+		if hard > 0 && bytes >= hard {
+			actions[site.ID] = config.TrafficHardAction
+			Bandwidth.alert(ctx, site, models.TransferLimitHard, bytes, hard, now)
+		}
+		if soft > 0 && bytes >= soft {
+			Bandwidth.alert(ctx, site, models.TransferLimitSoft, bytes, soft, now)
+		}
+	}
+	serve.Bandwidth.Set(actions)
+	return nil
+}
+
+// alert 记录站点本月超出限制并通知项目的 webhook，本月已通知过该级别时什么也不做
+// Record that the site exceeded a limit this month and notify the project's webhooks, doing nothing when the level was already notified this month
+func (BandwidthApi) alert(ctx context.Context, site *models.Site, level string, bytes, limit int64, now time.Time) {
+	recorded, err := store.Traffic.RecordAlert(site.ID, level, bytes, now)
+	if err != nil {
+		utils.Log.Ctx(ctx).Warnf("failed to record transfer alert of site %d: %v", site.ID, err)
+		return
+	}
+	if !recorded {
+		return
+	}
+	event := constants.WebhookTransferSoftLimit
+	message := i18n.Msg("Site %s served %s this month, beyond its soft limit of %s", site.Name, mebibytes(bytes), mebibytes(limit))
+	data := map[string]any{"site_id": site.ID, "site": site.Name, "month": now.UTC().Format("2006-01"), "bytes": bytes, "limit": limit}
+	if level == models.TransferLimitHard {
+		event = constants.WebhookTransferHardLimit
+		message = i18n.Msg("Site %s served %s this month, beyond its hard limit of %s, it is %s until the next month", site.Name, mebibytes(bytes), mebibytes(limit), transferActionText(config.TrafficHardAction))
+		data["action"] = config.TrafficHardAction
+	}
+	utils.Log.Ctx(ctx).Infof("site %d exceeded its %s transfer limit with %d bytes", site.ID, level, bytes)
+	Webhook.emit(ctx, &site.Project, event, message, data)
+}
+
+// mebibytes 以 MiB 表示的字节数 Bytes expressed in MiB
+func mebibytes(bytes int64) string {
+	return fmt.Sprintf("%.1f MiB", float64(bytes)/(1<<20))
+}
+
+// transferActionText 硬限制处理方式的描述 Description of a hard limit action
+func transferActionText(action string) i18n.Message {
+	if action == config.TransferActionSuspend {
+		return i18n.Msg("suspended")
+	}
+	return i18n.Msg("throttled")
+}
+
+// AdminSiteLimit 管理员设置站点每月流量的软限制和硬限制，为空时恢复为实例配置，0 为不限制
+// Admin sets the soft and hard monthly transfer limits of a site, empty restores the instance configuration and 0 means unlimited
+func (BandwidthApi) AdminSiteLimit(ctx context.Context, c *app.RequestContext) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		resps.BadRequest(c, resps.ParameterError)
+		return
+	}
+	site, err := store.Site.GetByID(uint(id))
+	if err != nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	req := SiteTransferLimitReq{}
+	if !bindJSON(c, &req) {
+		return
+	}
+	if req.SoftLimit != nil && *req.SoftLimit < 0 || req.HardLimit != nil && *req.HardLimit < 0 {
+		resps.BadRequest(c, "limits must not be negative, use 0 for unlimited")
+		return
+	}
+	if err := store.Site.SetTransferLimits(site, req.SoftLimit, req.HardLimit); err != nil {
+		resps.DBError(c, err, "save transfer limits error")
+		return
+	}
+	now := time.Now()
+	// 立即按新的限制检查，解除限制无需等待下一次检查 Check against the new limits right away so lifted limits take effect without waiting
+	if err := Bandwidth.check(ctx, now); err != nil {
+		utils.Log.Ctx(ctx).Warnf("failed to check site transfer: %v", err)
+	}
+	Audit.record(ctx, c, nil, constants.AuditQuotaUpdate, constants.AuditTargetSite, site.ID, map[string]any{
+		"operation":  "site transfer",
+		"soft_limit": req.SoftLimit,
+		"hard_limit": req.HardLimit,
+	})
+	sum, err := store.Traffic.Month(ctx, site.ID, now)
+	if err != nil {
+		resps.DBError(c, err, "Failed to get transfer")
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{"transfer": Bandwidth.toDTO(site, sum, now)})
+}
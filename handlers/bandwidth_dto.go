@@ -0,0 +1,20 @@
+package handlers
+
+// SiteTransferDTO 站点本月的流量及其限制
+// Transfer of a site this month and its limits
+type SiteTransferDTO struct {
+	Month     string `json:"month"`            // UTC 月份，格式为 2006-01 UTC month, formatted as 2006-01
+	Requests  int64  `json:"requests"`         // 本月请求数 Requests this month
+	Bytes     int64  `json:"bytes"`            // 本月响应字节数 Response bytes this month
+	SoftLimit int64  `json:"soft_limit"`       // 生效的软限制字节数，0 为不限制 Effective soft limit in bytes, 0 is unlimited
+	HardLimit int64  `json:"hard_limit"`       // 生效的硬限制字节数，0 为不限制 Effective hard limit in bytes, 0 is unlimited
+	Custom    bool   `json:"custom"`           // 是否由管理员为站点单独设置 Whether an administrator set the limits for the site
+	Action    string `json:"action,omitempty"` // 超出硬限制后的处理方式，未超出时为空 Handling beyond the hard limit, empty while within it
+}
+
+// SiteTransferLimitReq 管理员设置站点每月流量限制的请求参数，为空时恢复为实例配置，0 为不限制
+// Request parameters for an administrator setting the monthly transfer limits of a site, empty restores the instance configuration and 0 means unlimited
+type SiteTransferLimitReq struct {
+	SoftLimit *int64 `json:"soft_limit"` // 软限制字节数，超出时通知 Soft limit in bytes, notifying once exceeded
+	HardLimit *int64 `json:"hard_limit"` // 硬限制字节数，超出后限速或暂停服务 Hard limit in bytes, throttling or suspending once exceeded
+}
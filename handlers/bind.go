@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// bind 绑定请求参数并按 validate 标签校验，失败时已写入响应，校验失败时 details.fields 列出每个字段的问题
+// Bind the request parameters and validate them by their validate tags; the response is already written on failure,
+// with details.fields listing the problem of every field when validation fails
+func bind(c *app.RequestContext, req any) bool {
+	if err := c.BindAndValidate(req); err != nil {
+		resps.BadRequest(c, resps.RespMessageWithError(resps.ParameterError, err))
+		return false
+	}
+	return validate(c, req)
+}
+
+// bindJSON 与 bind 相同，但无论 Content-Type 都按 JSON 解析请求体
+// The same as bind but the body is parsed as JSON regardless of the Content-Type
+func bindJSON(c *app.RequestContext, req any) bool {
+	if err := c.BindJSON(req); err != nil {
+		resps.BadRequest(c, resps.RespMessageWithError(resps.ParameterError, err))
+		return false
+	}
+	return validate(c, req)
+}
+
+// normalizer 在校验前规范化自身字段的请求参数，例如由显示名称生成名称
+// Request parameters normalizing their own fields before validation, e.g. deriving the name from the display name
+type normalizer interface {
+	normalize()
+}
+
+// validate 按 validate 标签校验已绑定的请求参数，失败时已写入响应
+// Validate bound request parameters by their validate tags, the response is already written on failure
+func validate(c *app.RequestContext, req any) bool {
+	if n, ok := req.(normalizer); ok {
+		n.normalize()
+	}
+	if errs := utils.Validator.Struct(req); len(errs) > 0 {
+		resps.Invalid(c, errs)
+		return false
+	}
+	return true
+}
+
+// normalizeName 去掉名称首尾空白并转为小写，名称为空时由显示名称生成 slug
+// Trim and lowercase the name, deriving a slug from the display name when it is empty
+func normalizeName(name string, displayName *string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" && displayName != nil {
+		name = utils.Validator.NormalizeSlug(*displayName)
+	}
+	return name
+}
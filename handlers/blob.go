@@ -0,0 +1,329 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/middle"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/storage"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/task"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+type BlobApi struct{}
+
+// Blob 按内容哈希存储的站点文件，客户端可先查询缺失的文件，只上传这些文件后再提交部署清单
+// Site files stored by content hash, clients look up the missing files, upload only those and then submit the deployment manifest
+var Blob = BlobApi{}
+
+var errInvalidManifest = errors.New("invalid manifest")
+
+// releaseManifest 客户端提交的部署清单，引用的文件需事先上传
+// Deployment manifest submitted by a client, the referenced files must be uploaded beforehand
+type releaseManifest struct {
+	manifest  *utils.SiteManifest
+	changelog string // 部署时提供的变更说明 Changelog given with the deployment
+}
+
+// store 校验清单并按内容策略检查，确认引用的文件均已上传且大小一致后保存清单
+// Validate the manifest and check it against the content policy, then store it once every referenced file is uploaded with a matching size
+func (m releaseManifest) store(ctx context.Context, site *models.Site) (*models.File, error) {
+	if err := m.manifest.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidManifest, err)
+	}
+	if err := ContentPolicy.checkManifest(ctx, site, m.manifest); err != nil {
+		return nil, err
+	}
+	sizes, err := store.Blob.Sizes(ctx, m.manifest.Hashes())
+	if err != nil {
+		return nil, errFileRecord
+	}
+	missing := 0
+	for _, f := range m.manifest.Files {
+		size, ok := sizes[f.SHA256]
+		if !ok {
+			missing++
+			continue
+		}
+		if size != f.Size {
+			return nil, fmt.Errorf("%w: size of %s is %d, the stored content has %d bytes", errInvalidManifest, f.Path, f.Size, size)
+		}
+	}
+	if missing > 0 {
+		return nil, fmt.Errorf("%w: %d files are not uploaded yet", errInvalidManifest, missing)
+	}
+	if err := Quota.checkManifest(ctx, &site.Project, m.manifest); err != nil {
+		return nil, err
+	}
+	return saveManifest(ctx, site, m.manifest)
+}
+
+// storeFiles 将压缩包拆分为按内容哈希存储的文件，只写入尚未存储的内容，再保存部署清单。
+// 不能作为站点路径的条目被忽略，这些条目原本也无法访问
+// Split the archive into files stored by content hash, writing only contents not stored yet, then store the manifest.
+// Entries that are not valid site paths are skipped, they could never be served anyway
+func (a releaseArchive) storeFiles(ctx context.Context, site *models.Site) (*models.File, error) {
+	file, err := a.open()
+	if err != nil {
+		return nil, errArchiveStore
+	}
+	defer file.Close()
+	reader, err := zip.NewReader(file, a.size)
+	if err != nil {
+		return nil, errInvalidArchive
+	}
+	manifest := &utils.SiteManifest{}
+	positions := make(map[string]int)
+	entries := make(map[string]*zip.File)
+	for i, entry := range reader.File {
+		task.ReportProgress(ctx, constants.JobStageExtract, int64(i), int64(len(reader.File)))
+		name := strings.TrimPrefix(entry.Name, "./")
+		if entry.FileInfo().IsDir() || !utils.ValidManifestPath(name) {
+			continue
+		}
+		hash, size, err := hashZipEntry(entry)
+		if err != nil {
+			return nil, errInvalidArchive
+		}
+		entries[hash] = entry
+		// 重复的路径以后出现的条目为准，与直接读取压缩包一致 Later entries win for duplicate paths, matching reads of the archive
+		if i, ok := positions[name]; ok {
+			manifest.Files[i] = utils.ManifestFile{Path: name, SHA256: hash, Size: size}
+			continue
+		}
+		positions[name] = len(manifest.Files)
+		manifest.Files = append(manifest.Files, utils.ManifestFile{Path: name, SHA256: hash, Size: size})
+	}
+	if err := Quota.checkManifest(ctx, &site.Project, manifest); err != nil {
+		return nil, err
+	}
+	sizes, err := store.Blob.Sizes(ctx, manifest.Hashes())
+	if err != nil {
+		return nil, errFileRecord
+	}
+	// 相同内容只写入一次 Identical contents are written once
+	var missing, uploaded int64
+	pending := make(map[string]bool)
+	for _, f := range manifest.Files {
+		if _, ok := sizes[f.SHA256]; !ok && !pending[f.SHA256] {
+			pending[f.SHA256] = true
+			missing += f.Size
+		}
+	}
+	var blobs []models.Blob
+	for _, f := range manifest.Files {
+		if _, ok := sizes[f.SHA256]; ok {
+			continue
+		}
+		task.ReportProgress(ctx, constants.JobStageUpload, uploaded, missing)
+		content, err := entries[f.SHA256].Open()
+		if err != nil {
+			return nil, errInvalidArchive
+		}
+		err = storage.Default.Put(ctx, storage.BlobKey(f.SHA256), content, f.Size)
+		_ = content.Close()
+		if err != nil {
+			utils.Log.Ctx(ctx).Errorf("failed to store blob %s: %v", f.SHA256, err)
+			return nil, errArchiveStore
+		}
+		sizes[f.SHA256] = f.Size
+		uploaded += f.Size
+		blobs = append(blobs, models.Blob{Hash: f.SHA256, Size: f.Size})
+	}
+	if err := store.Blob.Create(ctx, blobs); err != nil {
+		return nil, errFileRecord
+	}
+	return saveManifest(ctx, site, manifest)
+}
+
+// hashZipEntry 计算压缩包条目解压后的哈希和字节数
+// Compute the hash and size of an archive entry after decompression
+func hashZipEntry(entry *zip.File) (string, int64, error) {
+	content, err := entry.Open()
+	if err != nil {
+		return "", 0, err
+	}
+	defer content.Close()
+	hash := sha256.New()
+	size, err := io.Copy(hash, content)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), size, nil
+}
+
+// saveManifest 保存部署清单，清单同样按内容寻址，文件集合相同的部署复用同一份清单
+// Store a deployment manifest, manifests are content addressed too so deployments with the same files share one
+func saveManifest(ctx context.Context, site *models.Site, manifest *utils.SiteManifest) (*models.File, error) {
+	data, err := manifest.Encode()
+	if err != nil {
+		return nil, errArchiveStore
+	}
+	sum := sha256.Sum256(data)
+	fileHash := hex.EncodeToString(sum[:])
+	manifestKey := storage.ReleasePrefix + site.Name + "/sha256/" + fileHash + ".json"
+	file, err := store.File.GetByPath(ctx, manifestKey)
+	if err != nil {
+		if err := storage.Default.Put(ctx, manifestKey, bytes.NewReader(data), int64(len(data))); err != nil {
+			utils.Log.Ctx(ctx).Errorf("failed to store manifest %s: %v", manifestKey, err)
+			return nil, errArchiveStore
+		}
+		file = &models.File{
+			Path:     manifestKey,
+			Hash:     fileHash,
+			Size:     int64(len(data)),
+			Manifest: true,
+		}
+		if err := store.File.Create(ctx, file); err != nil {
+			return nil, errFileRecord
+		}
+	}
+	hashes := manifest.Hashes()
+	if err := store.Blob.Link(ctx, file.ID, hashes); err != nil {
+		return nil, errFileRecord
+	}
+	// 引用记录写入之前内容可能刚被垃圾回收删除，之后的回收不会再删除它们
+	// Contents may have been garbage collected just before the references were recorded, later collections leave them alone
+	sizes, err := store.Blob.Sizes(ctx, hashes)
+	if err != nil {
+		return nil, errFileRecord
+	}
+	if len(sizes) < len(hashes) {
+		return nil, fmt.Errorf("%w: %d files were removed meanwhile, upload them again", errInvalidManifest, len(hashes)-len(sizes))
+	}
+	return file, nil
+}
+
+// Missing 返回尚未存储的内容哈希，客户端只需上传这些文件
+// Return the content hashes not stored yet, the only files the client needs to upload
+func (BlobApi) Missing(ctx context.Context, c *app.RequestContext) {
+	req := MissingBlobsReq{}
+	if !bind(c, &req) {
+		return
+	}
+	for _, hash := range req.Hashes {
+		if !utils.ValidSHA256(hash) {
+			resps.BadRequest(c, "invalid sha256 "+hash)
+			return
+		}
+	}
+	sizes, err := store.Blob.Sizes(ctx, req.Hashes)
+	if err != nil {
+		resps.DBError(c, err, "look up files error")
+		return
+	}
+	missing := make([]string, 0)
+	seen := make(map[string]bool, len(req.Hashes))
+	for _, hash := range req.Hashes {
+		if _, ok := sizes[hash]; !ok && !seen[hash] {
+			missing = append(missing, hash)
+		}
+		seen[hash] = true
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"missing": missing,
+	})
+}
+
+// Put 上传一个文件的内容，请求体为文件原始内容，服务端校验其 SHA-256 与路径中的哈希一致
+// Upload the content of one file as the raw request body, the server checks that its SHA-256 matches the hash in the path
+func (BlobApi) Put(ctx context.Context, c *app.RequestContext) {
+	hash := c.Param("hash")
+	if !utils.ValidSHA256(hash) {
+		resps.BadRequest(c, "invalid sha256 "+hash)
+		return
+	}
+	body, digest, size, err := Blob.spool(c)
+	if errors.Is(err, middle.ErrBodyTooLarge) {
+		return // 由请求体限制中间件返回 413 The body limit middleware answers with 413
+	}
+	if err != nil {
+		utils.Log.Ctx(ctx).Errorf("failed to read blob %s: %v", hash, err)
+		resps.InternalServerError(c, "save file error")
+		return
+	}
+	defer func() {
+		_ = body.Close()
+		_ = os.Remove(body.Name())
+	}()
+	if digest != hash {
+		resps.BadRequest(c, "content does not match sha256 "+hash)
+		return
+	}
+	sizes, err := store.Blob.Sizes(ctx, []string{hash})
+	if err != nil {
+		resps.DBError(c, err, "look up files error")
+		return
+	}
+	if _, ok := sizes[hash]; !ok {
+		if err := storage.Default.Put(ctx, storage.BlobKey(hash), body, size); err != nil {
+			utils.Log.Ctx(ctx).Errorf("failed to store blob %s: %v", hash, err)
+			resps.InternalServerError(c, "save file error")
+			return
+		}
+		if err := store.Blob.Create(ctx, []models.Blob{{Hash: hash, Size: size}}); err != nil {
+			resps.DBError(c, err, "save file error")
+			return
+		}
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"sha256": hash,
+		"size":   size,
+	})
+}
+
+// spool 将请求体写入临时文件并计算 SHA-256，大文件不必整个读入内存；返回的文件已回到开头
+// Write the request body into a temporary file while computing its SHA-256, so large files never sit in memory whole; the returned file is rewound
+func (BlobApi) spool(c *app.RequestContext) (*os.File, string, int64, error) {
+	tmp, err := os.CreateTemp("", "spage-blob-*")
+	if err != nil {
+		return nil, "", 0, err
+	}
+	hasher := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hasher), middle.BodyLimit.Reader(c))
+	if err == nil {
+		_, err = tmp.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return nil, "", 0, err
+	}
+	return tmp, hex.EncodeToString(hasher.Sum(nil)), size, nil
+}
+
+// Deploy 使用部署清单创建新版本，清单引用的文件需事先上传，版本和预览参数与普通上传相同
+// Create a version from a deployment manifest whose files are uploaded beforehand, the version and preview parameters match plain uploads
+func (BlobApi) Deploy(ctx context.Context, c *app.RequestContext) {
+	site := getSite(c)
+	if site == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	req := ManifestDeployReq{}
+	if !bind(c, &req) {
+		return
+	}
+	previewName, ok := Release.checkDeploy(c, site, req.Tag, req.Preview, req.TTL)
+	if !ok {
+		return
+	}
+	schedule, ok := Release.checkSchedule(c, previewName, req.PublishAt, req.ExpiresAt, req.ExpireAction)
+	if !ok {
+		return
+	}
+	Release.deploy(ctx, c, site, releaseManifest{manifest: &utils.SiteManifest{Files: req.Files}, changelog: req.Changelog}, req.Tag, previewName, req.TTL, schedule)
+}
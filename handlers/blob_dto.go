@@ -0,0 +1,24 @@
+package handlers
+
+import "github.com/LiteyukiStudio/spage/utils"
+
+// MissingBlobsReq 查询缺失文件的请求参数
+// Request parameters to look up missing files
+type MissingBlobsReq struct {
+	Hashes []string `json:"sha256" binding:"required"` // 文件内容的 SHA-256 列表 SHA-256 list of file contents
+}
+
+// ManifestDeployReq 使用部署清单创建版本的请求参数
+// Request parameters to create a version from a deployment manifest
+type ManifestDeployReq struct {
+	Tag     string               `json:"tag" binding:"required"`   // 版本标签 Version tag
+	Preview string               `json:"preview"`                  // 分支或合并请求标识，填写时作为预览部署 Branch or pull request identifier, makes it a preview deployment
+	TTL     int                  `json:"ttl"`                      // 预览有效期，单位秒，0 为默认值 Preview lifetime in seconds, 0 for the default
+	Files   []utils.ManifestFile `json:"files" binding:"required"` // 站点的全部文件 Every file of the site
+
+	PublishAt    string `json:"publish_at"`    // RFC 3339 定时上线时间 RFC 3339 go-live time
+	ExpiresAt    string `json:"expires_at"`    // RFC 3339 到期时间 RFC 3339 expiry time
+	ExpireAction string `json:"expire_action"` // 到期处理方式 Expiry action
+
+	Changelog string `json:"changelog" validate:"max=10000"` // 版本的变更说明，显示在部署订阅源中 Changelog of the version, shown in the deployment feeds
+}
@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/middle"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/serve"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/task"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+type CDNApi struct{}
+
+// CDN 项目的 CDN 集成，站点切换版本或清除路径后在后台任务中刷新 CDN 缓存，项目维护者也可手动刷新
+// CDN integrations of projects, the CDN cache is purged in a background job after a site switches versions or purges paths, and project maintainers may purge by hand
+var CDN = CDNApi{}
+
+// cdnPurgePayload CDN 刷新任务的参数
+// Parameters of a CDN purge job
+type cdnPurgePayload struct {
+	ReleaseID uint     `json:"release_id,omitempty"` // 切换到的版本ID Release ID switched to
+	Paths     []string `json:"paths,omitempty"`      // 要刷新的路径，为空时刷新全部内容 Paths to purge, everything when empty
+}
+
+func (CDNApi) toDTO(integration *models.CDNIntegration) CDNIntegrationDTO {
+	return CDNIntegrationDTO{
+		ID:        integration.ID,
+		Provider:  integration.Provider,
+		ZoneID:    integration.ZoneID,
+		URL:       integration.URL,
+		Active:    integration.Active,
+		CreatedAt: integration.CreatedAt,
+	}
+}
+
+// getIntegration 获取路径中指定的项目 CDN 集成，不存在时返回 404
+// Get the project CDN integration given in the path, answering 404 when it does not exist
+func (CDNApi) getIntegration(c *app.RequestContext) *models.CDNIntegration {
+	project := getProject(c)
+	if project == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return nil
+	}
+	id, err := strconv.Atoi(c.Param("cdn_id"))
+	if err != nil {
+		resps.BadRequest(c, resps.ParameterError)
+		return nil
+	}
+	integration, err := store.CDN.GetByProject(project.ID, uint(id))
+	if err != nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return nil
+	}
+	return integration
+}
+
+// apply 校验请求并写入 CDN 集成，失败时已写入响应
+// Validate the request and apply it to the CDN integration; the response is already written on failure
+func (CDNApi) apply(c *app.RequestContext, integration *models.CDNIntegration, req *CDNIntegrationReq) bool {
+	integration.Provider = req.Provider
+	integration.ZoneID = strings.TrimSpace(req.ZoneID)
+	integration.URL = req.URL
+	if req.Token != "" {
+		integration.Token = req.Token
+	}
+	if req.Active != nil {
+		integration.Active = *req.Active
+	}
+	switch {
+	case integration.Provider == constants.CDNProviderCloudflare && integration.ZoneID == "":
+		resps.BadRequest(c, "zone_id is required for cloudflare")
+		return false
+	case integration.Provider == constants.CDNProviderWebhook && integration.URL == "":
+		resps.BadRequest(c, "url is required for webhook")
+		return false
+	case integration.Token == "":
+		resps.BadRequest(c, "token is required")
+		return false
+	}
+	return true
+}
+
+// List 获取项目的 CDN 集成
+// List the CDN integrations of the project
+func (CDNApi) List(ctx context.Context, c *app.RequestContext) {
+	project := getProject(c)
+	if project == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	integrations, err := store.CDN.ListByProject(project.ID)
+	if err != nil {
+		resps.DBError(c, err, "get CDN integrations error")
+		return
+	}
+	dtos := make([]CDNIntegrationDTO, 0, len(integrations))
+	for i := range integrations {
+		dtos = append(dtos, CDN.toDTO(&integrations[i]))
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"integrations": dtos,
+	})
+}
+
+// Create 为项目创建 CDN 集成
+// Create a CDN integration for the project
+func (CDNApi) Create(ctx context.Context, c *app.RequestContext) {
+	project := getProject(c)
+	if project == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	req := CDNIntegrationReq{}
+	if !bindJSON(c, &req) {
+		return
+	}
+	integration := &models.CDNIntegration{ProjectID: project.ID, Active: true}
+	if !CDN.apply(c, integration, &req) {
+		return
+	}
+	if err := store.CDN.Create(integration); err != nil {
+		resps.DBError(c, err, "create CDN integration error")
+		return
+	}
+	Audit.record(ctx, c, nil, constants.AuditCDNUpdate, constants.AuditTargetProject, project.ID, map[string]any{"operation": "create", "integration_id": integration.ID, "provider": integration.Provider})
+	resps.Ok(c, resps.OK, map[string]any{
+		"integration": CDN.toDTO(integration),
+	})
+}
+
+// Update 更新 CDN 集成
+// Update a CDN integration
+func (CDNApi) Update(ctx context.Context, c *app.RequestContext) {
+	integration := CDN.getIntegration(c)
+	if integration == nil {
+		return
+	}
+	req := CDNIntegrationReq{}
+	if !bindJSON(c, &req) {
+		return
+	}
+	if !CDN.apply(c, integration, &req) {
+		return
+	}
+	if err := store.CDN.Update(integration); err != nil {
+		resps.DBError(c, err, "update CDN integration error")
+		return
+	}
+	Audit.record(ctx, c, nil, constants.AuditCDNUpdate, constants.AuditTargetProject, integration.ProjectID, map[string]any{"operation": "update", "integration_id": integration.ID, "provider": integration.Provider, "active": integration.Active})
+	resps.Ok(c, resps.OK, map[string]any{
+		"integration": CDN.toDTO(integration),
+	})
+}
+
+// Delete 删除 CDN 集成
+// Delete a CDN integration
+func (CDNApi) Delete(ctx context.Context, c *app.RequestContext) {
+	integration := CDN.getIntegration(c)
+	if integration == nil {
+		return
+	}
+	if err := store.CDN.Delete(integration); err != nil {
+		resps.DBError(c, err, "delete CDN integration error")
+		return
+	}
+	Audit.record(ctx, c, nil, constants.AuditCDNUpdate, constants.AuditTargetProject, integration.ProjectID, map[string]any{"operation": "delete", "integration_id": integration.ID, "provider": integration.Provider})
+	resps.Ok(c, resps.OK)
+}
+
+// Purge 手动刷新站点在项目所有已启用 CDN 集成中的缓存，返回执行刷新的后台任务
+// Purge the site from every enabled CDN integration of the project by hand, returning the background job doing it
+func (CDNApi) Purge(ctx context.Context, c *app.RequestContext) {
+	site := getSite(c)
+	if site == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	req := CDNPurgeReq{}
+	if len(c.Request.Body()) > 0 {
+		if !bindJSON(c, &req) {
+			return
+		}
+	}
+	if len(req.Paths) > config.PurgeMaxPaths {
+		resps.BadRequestf(c, "at most %d paths are allowed", config.PurgeMaxPaths)
+		return
+	}
+	for _, path := range req.Paths {
+		if !utils.ValidSitePathPattern(path) {
+			resps.BadRequest(c, "invalid path, wildcards are only allowed as a trailing /*: "+path)
+			return
+		}
+	}
+	count, err := store.CDN.CountActive(site.ProjectID)
+	if err != nil {
+		resps.DBError(c, err, "get CDN integrations error")
+		return
+	}
+	if count == 0 {
+		resps.BadRequest(c, "the project has no enabled CDN integration")
+		return
+	}
+	user := middle.Auth.GetUser(ctx, c)
+	job, err := task.EnqueueJob(ctx, constants.JobTypeCDNPurge, cdnPurgePayload{Paths: req.Paths}, site.ID, user.ID)
+	if err != nil {
+		resps.DBError(c, err, "create job error")
+		return
+	}
+	Audit.record(ctx, c, nil, constants.AuditCDNPurge, constants.AuditTargetSite, site.ID, map[string]any{"paths": req.Paths, "job_id": job.ID})
+	resps.Ok(c, resps.OK, map[string]any{
+		"job": Job.toDTO(job, false),
+	})
+}
+
+// purge 站点切换版本或清除路径后，项目有已启用的 CDN 集成时创建刷新任务，失败只记录日志
+// Create a purge job after the site switched versions or purged paths when the project has enabled CDN integrations, failures are only logged
+func (CDNApi) purge(ctx context.Context, site *models.Site, releaseID uint, paths []string) {
+	count, err := store.CDN.CountActive(site.ProjectID)
+	if err != nil {
+		utils.Log.Ctx(ctx).Warnf("failed to count CDN integrations of project %d: %v", site.ProjectID, err)
+		return
+	}
+	if count == 0 {
+		return
+	}
+	if _, err := task.EnqueueJob(ctx, constants.JobTypeCDNPurge, cdnPurgePayload{ReleaseID: releaseID, Paths: paths}, site.ID, 0); err != nil {
+		utils.Log.Ctx(ctx).Warnf("failed to enqueue the CDN purge of site %d: %v", site.ID, err)
+	}
+}
+
+// runPurgeJob 刷新站点在项目所有已启用 CDN 集成中的缓存，任一集成失败时整个任务重试
+// Purge the site from every enabled CDN integration of the project, the whole job retries when any integration fails
+func (CDNApi) runPurgeJob(ctx context.Context, job *models.Job) (any, error) {
+	payload := cdnPurgePayload{}
+	if err := json.Unmarshal([]byte(job.Payload), &payload); err != nil {
+		return nil, task.Permanent(err)
+	}
+	site, err := store.Site.GetByID(job.SiteID)
+	if err != nil {
+		return nil, task.Permanent(errors.New("site not found"))
+	}
+	integrations, err := store.CDN.ListActive(site.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	hosts := serve.SiteHosts(site)
+	for i := range hosts {
+		hosts[i] = strings.ToLower(hosts[i])
+	}
+	purge := task.CDNPurge{SiteID: site.ID, Site: site.Name, ReleaseID: payload.ReleaseID, Hosts: hosts, Paths: payload.Paths}
+	var errs []error
+	purged := make([]uint, 0, len(integrations))
+	for i := range integrations {
+		integration := &integrations[i]
+		if err := task.PurgeCDN(ctx, integration, purge); err != nil {
+			utils.Log.Ctx(ctx).Warnf("CDN integration %d failed to purge site %d: %v", integration.ID, site.ID, err)
+			errs = append(errs, err)
+			continue
+		}
+		purged = append(purged, integration.ID)
+	}
+	// 只有全部失败都无法重试时才不再重试 Retrying stops only when none of the failures can be retried
+	for _, err := range errs {
+		if !errors.Is(err, task.ErrJobPermanent) {
+			return nil, errors.New(errors.Join(errs...).Error())
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return map[string]any{"hosts": hosts, "paths": payload.Paths, "integrations": purged}, nil
+}
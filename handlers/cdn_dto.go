@@ -0,0 +1,30 @@
+package handlers
+
+import "time"
+
+// CDNIntegrationDTO 项目的 CDN 集成，令牌不会返回
+// CDN integration of a project, the token is never returned
+type CDNIntegrationDTO struct {
+	ID        uint      `json:"id"`                // 集成ID Integration ID
+	Provider  string    `json:"provider"`          // 服务商 Provider
+	ZoneID    string    `json:"zone_id,omitempty"` // Cloudflare 的区域ID Zone ID of Cloudflare
+	URL       string    `json:"url,omitempty"`     // webhook 的地址 URL of the webhook
+	Active    bool      `json:"active"`            // 是否启用 Whether enabled
+	CreatedAt time.Time `json:"created_at"`        // 创建时间 Created time
+}
+
+// CDNIntegrationReq 创建或更新 CDN 集成的请求参数，更新时未提供令牌则保留原令牌
+// Request parameters to create or update a CDN integration, the token is kept when not given on update
+type CDNIntegrationReq struct {
+	Provider string `json:"provider" validate:"required,oneof=cloudflare bunny webhook"` // 服务商，cloudflare、bunny 或 webhook Provider, cloudflare, bunny or webhook
+	ZoneID   string `json:"zone_id" validate:"max=64"`                                   // Cloudflare 的区域ID，cloudflare 必填 Zone ID of Cloudflare, required by cloudflare
+	URL      string `json:"url" validate:"max=2048,url"`                                 // 接收刷新请求的地址，webhook 必填 URL receiving purge requests, required by webhook
+	Token    string `json:"token" validate:"max=512"`                                    // API 令牌、API 密钥或签名密钥，创建时必填 API token, API key or signing secret, required on creation
+	Active   *bool  `json:"active"`                                                      // 是否启用，默认启用 Whether enabled, enabled by default
+}
+
+// CDNPurgeReq 手动刷新站点 CDN 缓存的请求参数，路径为空时刷新站点的全部内容
+// Request parameters to purge the CDN cache of a site by hand, the whole site when no paths are given
+type CDNPurgeReq struct {
+	Paths []string `json:"paths"` // 要刷新的路径，/dir/* 形式刷新整个目录 Paths to purge, the form /dir/* purges a whole directory
+}
@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/serve"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+	"gorm.io/gorm"
+)
+
+type ContentPolicyApi struct{}
+
+// ContentPolicy 托管内容策略，按扩展名和 MIME 类型拒绝或去掉上传中禁止的文件，默认值来自配置文件，管理员可为单个组织覆盖
+// Hosted content policy rejecting or stripping disallowed files of uploads by extension and MIME type, defaults come from the configuration and administrators may override them per organization
+var ContentPolicy = ContentPolicyApi{}
+
+var errBlockedContent = errors.New("upload contains files not allowed by the content policy")
+
+// filesListed 错误信息中最多列出的文件数 Most files listed in an error message
+const filesListed = 5
+
+// forProject 项目生效的内容策略，读取失败时使用默认策略
+// Effective content policy of a project, the defaults when it cannot be read
+func (ContentPolicyApi) forProject(ctx context.Context, project *models.Project) utils.ContentPolicy {
+	override, err := store.ContentPolicy.Override(project)
+	if err != nil {
+		utils.Log.Ctx(ctx).Warnf("failed to get the content policy of project %d: %v", project.ID, err)
+	}
+	return utils.EffectiveContentPolicy(override)
+}
+
+// blockedError 列出禁止的文件及命中的规则
+// List the disallowed files with their matched rules
+func (ContentPolicyApi) blockedError(blocked map[string]string) error {
+	return filesError(errBlockedContent, blocked)
+}
+
+// filesError 在错误后按路径顺序列出文件及其原因，超出 filesListed 的只给出数量
+// List the files with their reasons after the error in path order, only counting those beyond filesListed
+func filesError(err error, files map[string]string) error {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	listed := make([]string, 0, filesListed)
+	for _, name := range names[:min(len(names), filesListed)] {
+		listed = append(listed, fmt.Sprintf("%s (%s)", name, files[name]))
+	}
+	if len(names) > filesListed {
+		listed = append(listed, fmt.Sprintf("and %d more", len(names)-filesListed))
+	}
+	return fmt.Errorf("%w: %s", err, strings.Join(listed, ", "))
+}
+
+// checkArchive 按项目的内容策略检查压缩包，reject 时返回错误，strip 时返回去掉禁止文件后的压缩包，没有禁止的文件时为空；调用方需执行返回的清理函数
+// Check the archive against the project's content policy, failing under reject and returning the archive without the disallowed files under strip, nil when nothing is disallowed; callers must run the returned cleanup
+func (ContentPolicyApi) checkArchive(ctx context.Context, site *models.Site, a releaseArchive) (*releaseArchive, func(), error) {
+	noop := func() {}
+	policy := ContentPolicy.forProject(ctx, &site.Project)
+	if policy.Empty() {
+		return nil, noop, nil
+	}
+	names, err := a.names()
+	if err != nil {
+		return nil, noop, err
+	}
+	blocked := policy.Blocked(names)
+	if len(blocked) == 0 {
+		return nil, noop, nil
+	}
+	if policy.Action != config.PolicyActionStrip || len(blocked) == len(names) {
+		return nil, noop, ContentPolicy.blockedError(blocked)
+	}
+	src, err := a.open()
+	if err != nil {
+		return nil, noop, errArchiveStore
+	}
+	defer src.Close()
+	tmp, err := os.CreateTemp("", "spage-policy-*.zip")
+	if err != nil {
+		return nil, noop, errArchiveStore
+	}
+	cleanup := func() { _ = os.Remove(tmp.Name()) }
+	_, err = policy.StripZip(src, a.size, tmp)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		cleanup()
+		return nil, noop, errArchiveStore
+	}
+	stripped, err := fileArchive(tmp.Name())
+	if err != nil {
+		cleanup()
+		return nil, noop, errArchiveStore
+	}
+	utils.Log.Ctx(ctx).Infof("stripped %d files not allowed by the content policy from the upload to site %d", len(blocked), site.ID)
+	return &stripped, cleanup, nil
+}
+
+// checkManifest 按项目的内容策略检查部署清单，reject 时返回错误，strip 时从清单中去掉禁止的文件
+// Check the manifest against the project's content policy, failing under reject and dropping the disallowed files from it under strip
+func (ContentPolicyApi) checkManifest(ctx context.Context, site *models.Site, manifest *utils.SiteManifest) error {
+	policy := ContentPolicy.forProject(ctx, &site.Project)
+	if policy.Empty() {
+		return nil
+	}
+	names := make([]string, 0, len(manifest.Files))
+	for _, f := range manifest.Files {
+		names = append(names, f.Path)
+	}
+	blocked := policy.Blocked(names)
+	if len(blocked) == 0 {
+		return nil
+	}
+	if policy.Action != config.PolicyActionStrip || len(blocked) == len(names) {
+		return ContentPolicy.blockedError(blocked)
+	}
+	files := manifest.Files[:0]
+	for _, f := range manifest.Files {
+		if _, ok := blocked[f.Path]; !ok {
+			files = append(files, f)
+		}
+	}
+	manifest.Files = files
+	utils.Log.Ctx(ctx).Infof("stripped %d files not allowed by the content policy from the manifest of site %d", len(blocked), site.ID)
+	return nil
+}
+
+// toDTO 组织生效的内容策略
+// Effective content policy of an organization
+func (ContentPolicyApi) toDTO(orgID uint) (ContentPolicyDTO, error) {
+	policy, err := store.ContentPolicy.Get(orgID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ContentPolicyDTO{OrganizationID: orgID, ContentPolicy: utils.EffectiveContentPolicy(nil)}, nil
+	}
+	if err != nil {
+		return ContentPolicyDTO{}, err
+	}
+	return ContentPolicyDTO{OrganizationID: orgID, ContentPolicy: utils.EffectiveContentPolicy(policy), Custom: true}, nil
+}
+
+// Org 组织成员查看组织生效的内容策略
+// Organization members view the effective content policy of the organization
+func (ContentPolicyApi) Org(ctx context.Context, c *app.RequestContext) {
+	dto, err := ContentPolicy.toDTO(getOrg(c).ID)
+	if err != nil {
+		resps.InternalServerError(c, "get content policy error")
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"policy": dto,
+	})
+}
+
+// adminOrg 获取路径中的组织，失败时已写入响应
+// Get the organization of the path; the response is already written on failure
+func (ContentPolicyApi) adminOrg(c *app.RequestContext) (uint, bool) {
+	id, err := strconv.Atoi(c.Param("org_id"))
+	if err != nil || id <= 0 {
+		resps.BadRequest(c, resps.ParameterError)
+		return 0, false
+	}
+	if _, err := store.Org.GetOrgById(uint(id)); err != nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// forget 失效组织所有站点的缓存，使访问时的策略立即生效
+// Invalidate the cache of every site of the organization so the policy applies to requests at once
+func (ContentPolicyApi) forget(ctx context.Context, orgID uint) {
+	ids, err := store.ContentPolicy.ProjectIDs(orgID)
+	if err != nil {
+		utils.Log.Ctx(ctx).Warnf("failed to list projects of organization %d for cache invalidation: %v", orgID, err)
+		return
+	}
+	for _, id := range ids {
+		serve.SiteCache.ForgetProject(ctx, id)
+	}
+}
+
+// AdminGet 管理员获取组织生效的内容策略
+// Admin gets the effective content policy of an organization
+func (ContentPolicyApi) AdminGet(ctx context.Context, c *app.RequestContext) {
+	orgID, ok := ContentPolicy.adminOrg(c)
+	if !ok {
+		return
+	}
+	dto, err := ContentPolicy.toDTO(orgID)
+	if err != nil {
+		resps.InternalServerError(c, "get content policy error")
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"policy": dto,
+	})
+}
+
+// AdminUpdate 管理员为组织设置内容策略，覆盖默认值，已部署的内容只在开启访问时拒绝后受影响
+// Admin sets the content policy of an organization, overriding the defaults; deployed content is only affected once requests are refused too
+func (ContentPolicyApi) AdminUpdate(ctx context.Context, c *app.RequestContext) {
+	orgID, ok := ContentPolicy.adminOrg(c)
+	if !ok {
+		return
+	}
+	req := ContentPolicyReq{}
+	if !bindJSON(c, &req) {
+		return
+	}
+	policy := &models.ContentPolicy{
+		OrganizationID:    orgID,
+		BlockedExtensions: req.BlockedExtensions,
+		BlockedMIMETypes:  req.BlockedMIMETypes,
+		Action:            req.Action,
+		Serve:             req.Serve,
+	}
+	if err := utils.EffectiveContentPolicy(policy).Validate(); err != nil {
+		resps.BadRequest(c, err.Error())
+		return
+	}
+	if err := store.ContentPolicy.Save(policy); err != nil {
+		resps.DBError(c, err, "save content policy error")
+		return
+	}
+	ContentPolicy.forget(ctx, orgID)
+	Audit.record(ctx, c, nil, constants.AuditContentPolicyUpdate, constants.AuditTargetOrg, orgID, map[string]any{
+		"operation":          "update",
+		"blocked_extensions": req.BlockedExtensions,
+		"blocked_mime_types": req.BlockedMIMETypes,
+		"action":             req.Action,
+		"serve":              req.Serve,
+	})
+	dto, err := ContentPolicy.toDTO(orgID)
+	if err != nil {
+		resps.InternalServerError(c, "get content policy error")
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"policy": dto,
+	})
+}
+
+// AdminDelete 管理员删除组织的内容策略，恢复为默认策略
+// Admin removes the content policy of an organization, restoring the defaults
+func (ContentPolicyApi) AdminDelete(ctx context.Context, c *app.RequestContext) {
+	orgID, ok := ContentPolicy.adminOrg(c)
+	if !ok {
+		return
+	}
+	if err := store.ContentPolicy.Delete(orgID); err != nil {
+		resps.DBError(c, err, "delete content policy error")
+		return
+	}
+	ContentPolicy.forget(ctx, orgID)
+	Audit.record(ctx, c, nil, constants.AuditContentPolicyUpdate, constants.AuditTargetOrg, orgID, map[string]any{"operation": "delete"})
+	resps.Ok(c, resps.OK)
+}
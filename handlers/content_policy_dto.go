@@ -0,0 +1,20 @@
+package handlers
+
+import "github.com/LiteyukiStudio/spage/utils"
+
+// ContentPolicyDTO 组织生效的内容策略
+// Effective content policy of an organization
+type ContentPolicyDTO struct {
+	OrganizationID uint `json:"organization_id"` // 组织ID Organization ID
+	utils.ContentPolicy
+	Custom bool `json:"custom"` // 是否为管理员单独设置的策略 Whether an administrator set this policy individually
+}
+
+// ContentPolicyReq 管理员设置内容策略的请求参数，字段为空时使用默认策略，空列表表示不禁止
+// Request parameters for an administrator setting a content policy, empty fields use the defaults and an empty list disallows nothing
+type ContentPolicyReq struct {
+	BlockedExtensions []string `json:"blocked_extensions"` // 禁止的扩展名，例如 .exe Disallowed extensions, e.g. .exe
+	BlockedMIMETypes  []string `json:"blocked_mime_types"` // 禁止的 MIME 类型，支持 type/* 通配 Disallowed MIME types, type/* wildcards are allowed
+	Action            string   `json:"action"`             // reject 拒绝部署，strip 去掉禁止的文件 Reject refuses the deployment, strip drops the disallowed files
+	Serve             *bool    `json:"serve"`              // 访问时是否同样拒绝 Whether requests are refused too
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/task"
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+type CronApi struct{}
+
+// Cron 管理员查看和手动触发定时任务，表达式通过 cron.* 配置项修改
+// Administrators view and trigger scheduled tasks, expressions are changed through the cron.* settings
+var Cron = CronApi{}
+
+func (CronApi) toDTO(info *task.CronInfo) CronTaskDTO {
+	state := info.State
+	dto := CronTaskDTO{
+		Name:           info.Name,
+		Description:    info.Description,
+		Schedule:       info.Schedule,
+		Enabled:        info.Schedule != "",
+		Running:        info.Running,
+		NextRunAt:      state.NextRunAt,
+		LastRunAt:      state.LastRunAt,
+		LastFinishedAt: state.LastFinishedAt,
+		LastTrigger:    state.LastTrigger,
+		LastStatus:     state.LastStatus,
+		LastError:      state.LastError,
+	}
+	if info.Running {
+		dto.RunningSince = state.RunningSince
+	}
+	if json.Valid([]byte(state.LastResult)) {
+		dto.LastResult = json.RawMessage(state.LastResult)
+	}
+	return dto
+}
+
+// List 获取所有定时任务的表达式、上次和下次运行时间
+// List every scheduled task with its expression and last and next runs
+func (CronApi) List(ctx context.Context, c *app.RequestContext) {
+	infos, err := task.ListCron()
+	if err != nil {
+		resps.DBError(c, err, "get scheduled tasks error")
+		return
+	}
+	dtos := make([]CronTaskDTO, 0, len(infos))
+	for i := range infos {
+		dtos = append(dtos, Cron.toDTO(&infos[i]))
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"tasks": dtos,
+	})
+}
+
+// Run 立即在后台运行一次定时任务，不影响下次运行时间；任务正在运行时返回冲突
+// Run a scheduled task once in the background right away without moving its next run; a conflict while it is running
+func (CronApi) Run(ctx context.Context, c *app.RequestContext) {
+	name := c.Param("name")
+	err := task.TriggerCron(ctx, name)
+	switch {
+	case errors.Is(err, task.ErrCronUnknown):
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	case errors.Is(err, task.ErrCronRunning):
+		resps.Fail(c, http.StatusConflict, resps.CodeConflict, err.Error(), nil)
+		return
+	case err != nil:
+		resps.DBError(c, err, "run scheduled task error")
+		return
+	}
+	info, err := task.GetCron(name)
+	if err != nil {
+		resps.DBError(c, err, "get scheduled task error")
+		return
+	}
+	resps.Custom(c, http.StatusAccepted, resps.OK, map[string]any{
+		"task": Cron.toDTO(info),
+	})
+}
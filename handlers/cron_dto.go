@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// CronTaskDTO 定时任务的表达式和运行状态
+// Expression and run state of a scheduled task
+type CronTaskDTO struct {
+	Name           string          `json:"name"`                  // 任务名称 Task name
+	Description    string          `json:"description"`           // 任务说明 Task description
+	Schedule       string          `json:"schedule"`              // cron 表达式，为空时禁用 Cron expression, empty when disabled
+	Enabled        bool            `json:"enabled"`               // 是否按表达式运行 Whether it runs by its expression
+	Running        bool            `json:"running"`               // 是否正在运行 Whether it is running
+	RunningSince   *time.Time      `json:"running_since"`         // 正在运行时的开始时间 Start of the run in progress
+	NextRunAt      *time.Time      `json:"next_run_at"`           // 下次运行时间 Next run time
+	LastRunAt      *time.Time      `json:"last_run_at"`           // 最近一次开始运行的时间 Start of the latest run
+	LastFinishedAt *time.Time      `json:"last_finished_at"`      // 最近一次结束的时间 End of the latest run
+	LastTrigger    string          `json:"last_trigger"`          // 最近一次的触发方式 How the latest run was triggered
+	LastStatus     string          `json:"last_status"`           // 最近一次的结果 Outcome of the latest run
+	LastError      string          `json:"last_error,omitempty"`  // 最近一次的错误 Error of the latest run
+	LastResult     json.RawMessage `json:"last_result,omitempty"` // 最近一次的结果详情 Details of the latest run
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/middle"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+type DeployKeyApi struct{}
+
+var DeployKey = DeployKeyApi{}
+
+func (DeployKeyApi) toDTO(key *models.DeployKey) DeployKeyDTO {
+	return DeployKeyDTO{
+		ID:          key.ID,
+		Name:        key.Name,
+		Prefix:      key.Prefix,
+		CreatedByID: key.CreatedByID,
+		ExpiresAt:   key.ExpiresAt,
+		LastUsedAt:  key.LastUsedAt,
+		CreatedAt:   key.CreatedAt,
+	}
+}
+
+// List 获取项目的部署密钥
+// List the deploy keys of the project
+func (DeployKeyApi) List(ctx context.Context, c *app.RequestContext) {
+	project := getProject(c)
+	keys, err := store.DeployKey.ListByProject(project.ID)
+	if err != nil {
+		resps.DBError(c, err, "Failed to get deploy keys")
+		return
+	}
+	keyDTOs := make([]DeployKeyDTO, 0, len(keys))
+	for _, key := range keys {
+		keyDTOs = append(keyDTOs, DeployKey.toDTO(&key))
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"deploy_keys": keyDTOs,
+	})
+}
+
+// Create 创建项目部署密钥，以当前用户的身份执行，明文只在此时返回；适合交给 CI，避免共享个人访问令牌
+// Create a project deploy key acting as the current user, the plain key is only returned here; meant for CI instead of sharing personal access tokens
+func (DeployKeyApi) Create(ctx context.Context, c *app.RequestContext) {
+	user := middle.Auth.GetUser(ctx, c)
+	if user == nil {
+		return
+	}
+	req := CreateDeployKeyReq{}
+	if !bindJSON(c, &req) {
+		return
+	}
+	if req.ExpiresAt != nil && req.ExpiresAt.Before(time.Now()) {
+		resps.BadRequest(c, "expires_at must be in the future")
+		return
+	}
+	plain, hash, err := utils.Token.NewDeployKey()
+	if err != nil {
+		resps.InternalServerError(c, "Failed to create deploy key")
+		return
+	}
+	project := getProject(c)
+	key := &models.DeployKey{
+		ProjectID:   project.ID,
+		CreatedByID: user.ID,
+		Name:        req.Name,
+		Prefix:      plain[:tokenPrefixLength],
+		Hash:        hash,
+		ExpiresAt:   req.ExpiresAt,
+	}
+	if err := store.DeployKey.Create(key); err != nil {
+		resps.DBError(c, err, "Failed to create deploy key")
+		return
+	}
+	Audit.record(ctx, c, user, constants.AuditDeployKeyCreate, constants.AuditTargetDeployKey, key.ID, map[string]any{
+		"project_id": project.ID,
+		"name":       key.Name,
+		"prefix":     key.Prefix,
+		"expires_at": key.ExpiresAt,
+	})
+	resps.Ok(c, resps.OK, map[string]any{
+		"deploy_key": DeployKey.toDTO(key),
+		"plaintext":  plain,
+	})
+}
+
+// Delete 撤销项目部署密钥
+// Revoke a project deploy key
+func (DeployKeyApi) Delete(ctx context.Context, c *app.RequestContext) {
+	project := getProject(c)
+	id, err := strconv.Atoi(c.Param("key_id"))
+	if err != nil {
+		resps.BadRequest(c, resps.ParameterError)
+		return
+	}
+	key, err := store.DeployKey.GetByProject(project.ID, uint(id))
+	if err != nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	if err := store.DeployKey.Delete(key); err != nil {
+		resps.DBError(c, err, "Failed to delete deploy key")
+		return
+	}
+	Audit.record(ctx, c, nil, constants.AuditDeployKeyRevoke, constants.AuditTargetDeployKey, key.ID, map[string]any{"project_id": project.ID, "name": key.Name, "prefix": key.Prefix})
+	resps.Ok(c, resps.OK)
+}
@@ -0,0 +1,22 @@
+package handlers
+
+import "time"
+
+// DeployKeyDTO 项目部署密钥信息，不包含密钥明文
+// Project deploy key information, without the plain key
+type DeployKeyDTO struct {
+	ID          uint       `json:"id"`            // 密钥ID Key ID
+	Name        string     `json:"name"`          // 密钥名称 Key name
+	Prefix      string     `json:"prefix"`        // 密钥开头部分 Leading part of the key
+	CreatedByID uint       `json:"created_by_id"` // 创建者ID，密钥以创建者的身份执行 Creator ID, the key acts as its creator
+	ExpiresAt   *time.Time `json:"expires_at"`    // 过期时间 Expiry time
+	LastUsedAt  *time.Time `json:"last_used_at"`  // 最后使用时间 Last used time
+	CreatedAt   time.Time  `json:"created_at"`    // 创建时间 Created time
+}
+
+// CreateDeployKeyReq 创建项目部署密钥的请求参数
+// Request parameters to create a project deploy key
+type CreateDeployKeyReq struct {
+	Name      string     `json:"name" validate:"required,max=64"` // 密钥名称 Key name
+	ExpiresAt *time.Time `json:"expires_at"`                      // 过期时间，为空则永不过期 Expiry time, never expires when empty
+}
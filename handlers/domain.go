@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/certs"
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/i18n"
+	"github.com/LiteyukiStudio/spage/middle"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/serve"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/task"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+	"gorm.io/gorm"
+)
+
+type DomainApi struct{}
+
+var Domain = DomainApi{}
+
+// 绑定自定义域名失败的原因 Reasons binding a custom domain fails
+var (
+	errDomainBaseDomain = errors.New("Domains under the base domain are served as subdomains")
+	errDomainBound      = errors.New("Domain is already bound")
+	errDomainToken      = errors.New("Failed to create token")
+	errDomainSave       = errors.New("Failed to bind domain")
+)
+
+func (DomainApi) toDTO(domain *models.CustomDomain) CustomDomainDTO {
+	domainDTO := CustomDomainDTO{
+		ID:            domain.ID,
+		Domain:        domain.Domain,
+		Method:        domain.Method,
+		Verified:      domain.VerifiedAt != nil,
+		VerifiedAt:    domain.VerifiedAt,
+		LastCheckedAt: domain.LastCheckedAt,
+		LastError:     domain.LastError,
+	}
+	switch domain.Method {
+	case constants.DomainVerifyDNS:
+		domainDTO.RecordName = utils.Domain.RecordName(domain.Domain)
+		domainDTO.RecordValue = utils.Domain.RecordValue(domain.Token)
+	case constants.DomainVerifyHTTP:
+		domainDTO.ChallengeURL = utils.Domain.ChallengeURL(domain.Domain, domain.Token)
+	}
+	return domainDTO
+}
+
+// List 获取站点的自定义域名
+// List the custom domains of the site
+func (DomainApi) List(ctx context.Context, c *app.RequestContext) {
+	site := getSite(c)
+	if site == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	domains, err := store.Domain.ListBySite(site.ID)
+	if err != nil {
+		resps.DBError(c, err, "Failed to get domains")
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"domains": func() (domainDTOs []CustomDomainDTO) {
+			for _, domain := range domains {
+				domainDTOs = append(domainDTOs, Domain.toDTO(&domain))
+			}
+			return
+		}(),
+	})
+}
+
+// Create 为站点绑定自定义域名，返回所有权验证方式，验证通过后才会提供服务
+// Bind a custom domain to the site and return the verification instructions, it is served only after verification
+func (DomainApi) Create(ctx context.Context, c *app.RequestContext) {
+	site := getSite(c)
+	if site == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	req := CreateCustomDomainReq{}
+	if !bindJSON(c, &req) {
+		return
+	}
+	domain, err := Domain.bind(site, req.Domain, req.Method)
+	if errors.Is(err, errDomainToken) || errors.Is(err, errDomainSave) {
+		resps.InternalServerError(c, err.Error())
+		return
+	} else if err != nil {
+		resps.BadRequest(c, err.Error())
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"domain": Domain.toDTO(domain),
+	})
+}
+
+// bind 为站点绑定待验证的自定义域名，method 为空时使用 DNS 验证
+// Bind an unverified custom domain to the site, verifying by DNS when method is empty
+func (DomainApi) bind(site *models.Site, raw string, method constants.DomainVerifyMethod) (*models.CustomDomain, error) {
+	name, err := utils.Domain.Normalize(raw)
+	if err != nil {
+		return nil, err
+	}
+	if serve.UnderBaseDomain(name) {
+		return nil, errDomainBaseDomain
+	}
+	if _, err := store.Domain.GetByDomain(name); err == nil {
+		return nil, errDomainBound
+	}
+	if method == "" {
+		method = constants.DomainVerifyDNS
+	}
+	token, err := utils.Domain.NewToken()
+	if err != nil {
+		return nil, errDomainToken
+	}
+	domain := &models.CustomDomain{
+		SiteID: site.ID,
+		Domain: name,
+		Method: method,
+		Token:  token,
+	}
+	if err := store.Domain.Create(domain); err != nil {
+		return nil, errDomainSave
+	}
+	return domain, nil
+}
+
+// Verify 检查域名所有权，通过后开始提供服务，启用 ACME 时在后台任务中申请证书
+// Check the domain ownership, once verified it is served and with ACME enabled a background job obtains the certificate
+func (DomainApi) Verify(ctx context.Context, c *app.RequestContext) {
+	domain := Domain.getDomain(c)
+	if domain == nil {
+		return
+	}
+	now := time.Now()
+	domain.LastCheckedAt = &now
+	wasVerified := domain.VerifiedAt != nil
+	if err := utils.Domain.Verify(ctx, domain); err != nil {
+		domain.LastError = err.Error()
+	} else {
+		domain.LastError = ""
+		if domain.VerifiedAt == nil {
+			domain.VerifiedAt = &now
+		}
+	}
+	if err := store.Domain.SaveCheck(domain); err != nil {
+		utils.Log.Ctx(ctx).Errorf("failed to save verification of %s: %v", domain.Domain, err)
+		resps.InternalServerError(c, "Failed to save verification")
+		return
+	}
+	serve.SiteCache.Forget(ctx, domain.SiteID, domain.Domain)
+	if !wasVerified && domain.VerifiedAt != nil {
+		Webhook.emit(ctx, getProject(c), constants.WebhookDomainVerified,
+			i18n.Msg("Domain %s verified", domain.Domain),
+			map[string]any{"site_id": domain.SiteID, "domain": domain.Domain, "method": domain.Method})
+		if certs.Enabled() {
+			// 申请失败时首次 HTTPS 访问仍会再次尝试 The first HTTPS request still tries again if this fails
+			if _, err := task.EnqueueJob(ctx, constants.JobTypeCertificate, certs.IssueJob{Domain: domain.Domain}, domain.SiteID, middle.Auth.GetUser(ctx, c).ID); err != nil {
+				utils.Log.Ctx(ctx).Warnf("failed to create certificate job for %s: %v", domain.Domain, err)
+			}
+		}
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"domain": Domain.toDTO(domain),
+	})
+}
+
+// Delete 解除自定义域名并删除其证书
+// Unbind a custom domain and delete its certificate
+func (DomainApi) Delete(ctx context.Context, c *app.RequestContext) {
+	domain := Domain.getDomain(c)
+	if domain == nil {
+		return
+	}
+	if err := store.Domain.Delete(domain); err != nil {
+		resps.DBError(c, err, "Failed to unbind domain")
+		return
+	}
+	serve.SiteCache.Forget(ctx, domain.SiteID, domain.Domain)
+	resps.Ok(c, resps.OK)
+}
+
+// Challenge 应答 HTTP 所有权验证
+// Answer the HTTP ownership verification
+func (DomainApi) Challenge(ctx context.Context, c *app.RequestContext) {
+	domain, err := store.Domain.GetByDomain(utils.Domain.Host(string(c.Host())))
+	if err != nil || domain.Method != constants.DomainVerifyHTTP || domain.Token != c.Param("token") {
+		c.String(404, "Not Found")
+		return
+	}
+	c.String(200, domain.Token)
+}
+
+func (DomainApi) getDomain(c *app.RequestContext) *models.CustomDomain {
+	site := getSite(c)
+	if site == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return nil
+	}
+	id, err := strconv.Atoi(c.Param("domain_id"))
+	if err != nil {
+		resps.BadRequest(c, resps.ParameterError)
+		return nil
+	}
+	domain, err := store.Domain.GetBySite(site.ID, uint(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		resps.NotFound(c, resps.TargetNotFound)
+		return nil
+	}
+	if err != nil {
+		resps.InternalServerError(c, "Failed to get domain")
+		return nil
+	}
+	return domain
+}
@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"path"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/serve"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/http1/resp"
+	"gorm.io/gorm"
+)
+
+type ExportApi struct{}
+
+var Export = ExportApi{}
+
+const (
+	exportVersion      = 1                   // 导出格式版本 Export format version
+	exportManifestFile = "spage-export.json" // 导出包中的项目设置 Project settings in the export
+)
+
+// exportSite 待导出的站点及其版本 A site to export together with its version
+type exportSite struct {
+	site    models.Site
+	release *models.SiteRelease
+}
+
+// Project 以 tar.gz 流的形式导出项目的设置、自定义域名、重定向规则和各站点当前或指定版本的内容，用于备份或在实例间迁移
+// Stream a tar.gz of the project's settings, custom domains, redirect rules and the content of the active or chosen version of each site, for backups or migrating between instances
+func (ExportApi) Project(ctx context.Context, c *app.RequestContext) {
+	project := getProject(c)
+	if project == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	req := ExportProjectReq{}
+	if !bind(c, &req) {
+		return
+	}
+	if req.ReleaseID != 0 && req.SiteID == 0 {
+		resps.BadRequest(c, "release_id requires site_id")
+		return
+	}
+	manifest, sites, err := Export.collect(project, &req)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	} else if err != nil {
+		utils.Log.Ctx(ctx).Errorf("failed to collect export of project %d: %v", project.ID, err)
+		resps.InternalServerError(c, "Failed to export project")
+		return
+	}
+	Audit.record(ctx, c, nil, constants.AuditProjectExport, constants.AuditTargetProject, project.ID, map[string]any{
+		"name":       project.Name,
+		"site_id":    req.SiteID,
+		"release_id": req.ReleaseID,
+	})
+	c.SetStatusCode(200)
+	c.Response.Header.SetContentType("application/gzip")
+	c.Response.Header.Set("Content-Disposition", "attachment; filename="+project.Name+"-"+manifest.ExportedAt.Format("20060102150405")+".tar.gz")
+	c.Response.HijackWriter(resp.NewChunkedBodyWriter(&c.Response, c.GetWriter()))
+	// 响应头已发送，失败时只能记录日志并中断传输
+	// Headers are already sent, a failure can only be logged and the transfer aborted
+	if err := Export.write(c, manifest, sites); err != nil {
+		utils.Log.Ctx(ctx).Errorf("failed to write export of project %d: %v", project.ID, err)
+		return
+	}
+	_ = c.Flush()
+}
+
+// collect 读取项目设置和待导出的站点，指定的站点或版本不存在时返回 gorm.ErrRecordNotFound
+// Read the project settings and the sites to export, gorm.ErrRecordNotFound when the given site or version does not exist
+func (ExportApi) collect(project *models.Project, req *ExportProjectReq) (*ProjectExport, []exportSite, error) {
+	projects := []models.Project{*project}
+	if err := store.Project.LoadLabels(projects...); err != nil {
+		return nil, nil, err
+	}
+	manifest := &ProjectExport{
+		Version:     exportVersion,
+		ExportedAt:  time.Now().UTC(),
+		Name:        project.Name,
+		DisplayName: project.DisplayName,
+		Description: project.Description,
+		Visibility:  project.Visibility,
+		Labels:      Project.toDTO(&projects[0], false).Labels,
+		Sites:       []SiteExport{},
+	}
+	all, err := store.Project.ListAllSites(project.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	var sites []exportSite
+	for _, site := range all {
+		// 回收站中的站点不导出 Sites in the trash are not exported
+		if site.DeletedAt.Valid || (req.SiteID != 0 && site.ID != req.SiteID) {
+			continue
+		}
+		release, err := Export.release(&site, req.ReleaseID)
+		if err != nil {
+			return nil, nil, err
+		}
+		siteExport, err := Export.site(&site, release)
+		if err != nil {
+			return nil, nil, err
+		}
+		manifest.Sites = append(manifest.Sites, siteExport)
+		sites = append(sites, exportSite{site: site, release: release})
+	}
+	if req.SiteID != 0 && len(sites) == 0 {
+		return nil, nil, gorm.ErrRecordNotFound
+	}
+	return manifest, sites, nil
+}
+
+// release 站点待导出的版本，id 为 0 时为当前版本，站点没有版本时返回空
+// Version of the site to export, the active one when id is 0 and nil when the site has none
+func (ExportApi) release(site *models.Site, id uint) (*models.SiteRelease, error) {
+	if id == 0 {
+		if id = Release.activeID(site); id == 0 {
+			return nil, nil
+		}
+	}
+	return store.Site.GetSiteRelease(site.ID, id)
+}
+
+func (ExportApi) site(site *models.Site, release *models.SiteRelease) (SiteExport, error) {
+	siteExport := SiteExport{
+		Name:          site.Name,
+		Description:   site.Description,
+		SubDomain:     site.SubDomain,
+		Domains:       site.Domains,
+		OGPreview:     site.OGPreview,
+		Headers:       site.Headers,
+		CacheRules:    site.CacheRules,
+		AccessMode:    site.AccessMode,
+		AllowIPs:      site.AllowIPs,
+		DenyIPs:       site.DenyIPs,
+		CustomDomains: []DomainExport{},
+	}
+	domains, err := store.Domain.ListBySite(site.ID)
+	if err != nil {
+		return siteExport, err
+	}
+	for _, domain := range domains {
+		siteExport.CustomDomains = append(siteExport.CustomDomains, DomainExport{
+			Domain:   domain.Domain,
+			Method:   domain.Method,
+			Verified: domain.VerifiedAt != nil,
+		})
+	}
+	if integration, err := store.Git.GetBySite(site.ID); err == nil {
+		siteExport.Git = &GitExport{
+			Provider:     integration.Provider,
+			RepoURL:      integration.RepoURL,
+			Branch:       integration.Branch,
+			BuildCommand: integration.BuildCommand,
+			BuildPreset:  integration.BuildPreset,
+			BuildImage:   integration.BuildImage,
+			RunnerLabels: integration.RunnerLabels,
+			OutputDir:    integration.OutputDir,
+		}
+	}
+	if release != nil {
+		redirects, err := serve.ReleaseRedirects(&release.File)
+		if err != nil {
+			return siteExport, err
+		}
+		siteExport.Release = &ReleaseExport{
+			ID:        release.ID,
+			Tag:       release.Tag,
+			CreatedAt: release.CreatedAt,
+			Path:      Export.dir(site),
+			Redirects: redirects,
+		}
+	}
+	return siteExport, nil
+}
+
+// dir 站点内容在导出包中的目录 Directory of the site content in the export
+func (ExportApi) dir(site *models.Site) string {
+	return path.Join("sites", path.Clean("/" + site.Name)[1:])
+}
+
+// write 依次写入 spage-export.json 和各站点版本的文件
+// Write spage-export.json followed by the files of each site version
+func (ExportApi) write(w io.Writer, manifest *ProjectExport, sites []exportSite) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := Export.writeFile(tw, exportManifestFile, int64(len(data)), manifest.ExportedAt, bytes.NewReader(data)); err != nil {
+		return err
+	}
+	for _, s := range sites {
+		if s.release == nil {
+			continue
+		}
+		dir := Export.dir(&s.site)
+		err := serve.WalkRelease(&s.release.File, func(name string, size int64, body io.Reader) error {
+			return Export.writeFile(tw, path.Join(dir, path.Clean("/" + name)[1:]), size, s.release.CreatedAt, body)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func (ExportApi) writeFile(tw *tar.Writer, name string, size int64, modTime time.Time, body io.Reader) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Name:     name,
+		Mode:     0o644,
+		Size:     size,
+		ModTime:  modTime,
+		Typeflag: tar.TypeReg,
+	}); err != nil {
+		return err
+	}
+	_, err := io.Copy(tw, body)
+	return err
+}
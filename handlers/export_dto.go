@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/serve"
+)
+
+// ExportProjectReq 导出项目的请求参数，默认导出全部站点的当前版本
+// Request parameters to export a project, the active version of every site by default
+type ExportProjectReq struct {
+	SiteID    uint `query:"site_id"`    // 只导出该站点 Only export this site
+	ReleaseID uint `query:"release_id"` // 导出的版本，需同时指定站点 Exported version, requires site_id
+}
+
+// ProjectExport 导出包中 spage-export.json 的内容，不包含密码、令牌和密钥
+// Content of spage-export.json in an export, passwords, tokens and secrets are left out
+type ProjectExport struct {
+	Version     int                  `json:"version"`      // 导出格式版本 Export format version
+	ExportedAt  time.Time            `json:"exported_at"`  // 导出时间 Export time
+	Name        string               `json:"name"`         // 项目名称 Project Name
+	DisplayName *string              `json:"display_name"` // 项目显示名称 Project Display Name
+	Description string               `json:"description"`  // 项目描述 Project Description
+	Visibility  constants.Visibility `json:"visibility"`   // 项目可见性 Project Visibility
+	Labels      map[string]string    `json:"labels"`       // 项目标签 Project labels
+	Sites       []SiteExport         `json:"sites"`        // 导出的站点 Exported sites
+}
+
+// SiteExport 导出的站点设置，内容位于导出包的 sites/<name>/ 目录
+// Exported site settings, the content sits in sites/<name>/ of the export
+type SiteExport struct {
+	Name          string                   `json:"name"`           // 站点名称 Site name
+	Description   string                   `json:"description"`    // 站点描述 Site description
+	SubDomain     string                   `json:"sub_domain"`     // 子域名 SubDomain
+	Domains       []string                 `json:"domains"`        // 域名 Domains
+	OGPreview     bool                     `json:"og_preview"`     // 是否生成预览图 Whether to generate preview images
+	Headers       []string                 `json:"headers"`        // 自定义响应头 Custom response headers
+	CacheRules    []string                 `json:"cache_rules"`    // 按路径的 Cache-Control Cache-Control by path
+	AccessMode    constants.SiteAccessMode `json:"access_mode"`    // 访问保护方式，密码不导出 Access protection, the password is not exported
+	AllowIPs      []string                 `json:"allow_ips"`      // 允许访问的地址 Allowed addresses
+	DenyIPs       []string                 `json:"deny_ips"`       // 禁止访问的地址 Refused addresses
+	CustomDomains []DomainExport           `json:"custom_domains"` // 自定义域名 Custom domains
+	Git           *GitExport               `json:"git,omitempty"`  // 绑定的仓库 Bound repository
+	Release       *ReleaseExport           `json:"release"`        // 导出的版本，站点没有版本时为空 Exported version, empty when the site has none
+}
+
+// DomainExport 导出的自定义域名，迁移后需要重新验证
+// Exported custom domain, verified again after migrating
+type DomainExport struct {
+	Domain   string                       `json:"domain"`   // 域名 Domain
+	Method   constants.DomainVerifyMethod `json:"method"`   // 验证方式 Verification method
+	Verified bool                         `json:"verified"` // 导出时是否已验证 Whether it was verified at export time
+}
+
+// GitExport 导出的仓库绑定，访问令牌和推送密钥不导出
+// Exported repository binding, the access token and push secret are not exported
+type GitExport struct {
+	Provider     string   `json:"provider"`                // 代码托管平台 Git hosting provider
+	RepoURL      string   `json:"repo_url"`                // 仓库地址 Repository URL
+	Branch       string   `json:"branch"`                  // 部署的分支 Deployed branch
+	BuildCommand string   `json:"build_command"`           // 构建命令 Build command
+	BuildPreset  string   `json:"build_preset,omitempty"`  // 构建预设 Build preset
+	BuildImage   string   `json:"build_image,omitempty"`   // 构建镜像 Build image
+	RunnerLabels []string `json:"runner_labels,omitempty"` // 远程执行器必须具有的标签 Labels a remote runner must have
+	OutputDir    string   `json:"output_dir"`              // 输出目录 Output directory
+}
+
+// ReleaseExport 导出的版本及其重定向规则
+// Exported version and its redirect rules
+type ReleaseExport struct {
+	ID        uint                 `json:"id"`         // 版本ID Version ID
+	Tag       string               `json:"tag"`        // 版本标签 Version tag
+	CreatedAt time.Time            `json:"created_at"` // 部署时间 Deployed time
+	Path      string               `json:"path"`       // 内容在导出包中的目录 Directory of the content in the export
+	Redirects []serve.RedirectRule `json:"redirects"`  // 根目录 _redirects 中的规则 Rules of _redirects at the root
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/serve"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+type FeedApi struct{}
+
+// Feed 公开项目和组织的部署订阅源，以 Atom 或 RSS 发布新上线的版本及上传时提供的变更说明，订阅者无需轮询接口
+// Deployment feeds of public projects and organizations, announcing versions that went live with the changelog given at upload time as Atom or RSS so subscribers need not poll the API
+var Feed = FeedApi{}
+
+// feedEntries 订阅源中的最多条目数 Most entries of a feed
+const feedEntries = 50
+
+// tagURI 生成 RFC 4151 的 tag URI，作为订阅源和条目的唯一标识
+// Build an RFC 4151 tag URI identifying a feed or an entry
+func (FeedApi) tagURI(date string, specific string) string {
+	host := "localhost"
+	if u, err := url.Parse(config.FrontEndURL); err == nil && u.Hostname() != "" {
+		host = u.Hostname()
+	}
+	return "tag:" + host + "," + date + ":" + specific
+}
+
+// ownerName 项目所有者的名称，找不到时为空
+// Name of the project's owner, empty when it cannot be found
+func (FeedApi) ownerName(project *models.Project) string {
+	if project.OwnerType == constants.OwnerTypeOrg {
+		if org, err := store.Org.GetOrgById(project.OwnerID); err == nil {
+			return org.Name
+		}
+		return ""
+	}
+	if user, err := store.User.GetByID(project.OwnerID); err == nil {
+		return user.Name
+	}
+	return ""
+}
+
+// entries 将版本转换为订阅源条目，跳过启用了访问保护的站点的版本
+// Turn versions into feed entries, skipping those of sites with access protection
+func (FeedApi) entries(releases []models.SiteRelease) []utils.FeedEntry {
+	entries := make([]utils.FeedEntry, 0, len(releases))
+	for _, release := range releases {
+		site := &release.Site
+		if serve.Protected(site) {
+			continue
+		}
+		entry := utils.FeedEntry{
+			ID:        Feed.tagURI(release.CreatedAt.UTC().Format("2006-01-02"), "release/"+strconv.FormatUint(uint64(release.ID), 10)),
+			Title:     site.Project.Name + "/" + site.Name + " " + release.Tag,
+			Content:   release.Changelog,
+			Published: release.CreatedAt,
+		}
+		if host := serve.CanonicalHost(site); host != "" {
+			entry.Link = "https://" + host + "/"
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// write 按路径的扩展名输出 Atom 或 RSS，私有实例不提供订阅源
+// Write the feed as Atom or RSS by the extension of the path, private instances offer no feeds
+func (FeedApi) write(c *app.RequestContext, feed *utils.Feed) {
+	feed.Self = strings.TrimSuffix(config.FrontEndURL, "/") + string(c.Request.RequestURI())
+	if feed.Link == "" {
+		feed.Link = config.FrontEndURL
+	}
+	render, contentType := feed.Atom, "application/atom+xml; charset=utf-8"
+	if strings.HasSuffix(c.FullPath(), ".rss") {
+		render, contentType = feed.RSS, "application/rss+xml; charset=utf-8"
+	}
+	data, err := render()
+	if err != nil {
+		resps.InternalServerError(c, "render feed error")
+		return
+	}
+	// 缓存时间较短，部署后很快更新 Cached briefly so the feed follows new deployments
+	c.Response.Header.Set("Cache-Control", "public, max-age=300")
+	c.Data(200, contentType, data)
+}
+
+// Project 获取公开项目的部署订阅源
+// Get the deployment feed of a public project
+func (FeedApi) Project(ctx context.Context, c *app.RequestContext) {
+	project, err := store.Project.GetByName(c.Param("name"))
+	// 私有项目与不存在的项目无法区分 Private projects are indistinguishable from missing ones
+	if err != nil || project.Visibility != constants.VisibilityPublic || serve.Private() {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	releases, err := store.Site.FeedReleases(ctx, []uint{project.ID}, feedEntries)
+	if err != nil {
+		resps.DBError(c, err, "get releases error")
+		return
+	}
+	feed := &utils.Feed{
+		ID:          Feed.tagURI(project.CreatedAt.UTC().Format("2006-01-02"), "project/"+strconv.FormatUint(uint64(project.ID), 10)),
+		Title:       project.Name + " deployments",
+		Description: project.Description,
+		Author:      Feed.ownerName(project),
+		Entries:     Feed.entries(releases),
+	}
+	if host := serve.ProjectHost(project); host != "" {
+		feed.Link = "https://" + host + "/"
+	}
+	Feed.write(c, feed)
+}
+
+// Org 获取组织全部公开项目的部署订阅源
+// Get the deployment feed of every public project of an organization
+func (FeedApi) Org(ctx context.Context, c *app.RequestContext) {
+	org, err := store.Org.GetOrgByName(c.Param("name"))
+	if err != nil || serve.Private() {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	projects, err := store.Project.ListOwned(ctx, constants.OwnerTypeOrg, org.ID, nil)
+	if err != nil {
+		resps.DBError(c, err, "get projects error")
+		return
+	}
+	var ids []uint
+	for _, project := range projects {
+		if project.Visibility == constants.VisibilityPublic {
+			ids = append(ids, project.ID)
+		}
+	}
+	releases, err := store.Site.FeedReleases(ctx, ids, feedEntries)
+	if err != nil {
+		resps.DBError(c, err, "get releases error")
+		return
+	}
+	Feed.write(c, &utils.Feed{
+		ID:          Feed.tagURI(org.CreatedAt.UTC().Format("2006-01-02"), "organization/"+strconv.FormatUint(uint64(org.ID), 10)),
+		Title:       org.Name + " deployments",
+		Description: org.Description,
+		Author:      org.Name,
+		Entries:     Feed.entries(releases),
+	})
+}
@@ -0,0 +1,488 @@
+package handlers
+
+import (
+	"cmp"
+	"context"
+	"crypto/hmac"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/i18n"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/task"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/http1/resp"
+)
+
+type GitApi struct{}
+
+var Git = GitApi{}
+
+var (
+	// gitCommitPattern 完整的 SHA-1 或 SHA-256 提交哈希 Full SHA-1 or SHA-256 commit hash
+	gitCommitPattern = regexp.MustCompile(`^[0-9a-f]{40}([0-9a-f]{24})?$`)
+	// gitBranchPattern 允许部署的分支名 Branch names allowed for deployment
+	gitBranchPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._/-]*$`)
+	// buildImagePattern 允许的构建镜像引用，不能以 - 开头以免被当作沙箱参数 Allowed build image references, never starting with - so they cannot pass as sandbox options
+	buildImagePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._/:@-]{0,254}$`)
+)
+
+// gitHookPath 托管平台推送事件的接收地址
+// Path receiving push events from providers
+func gitHookPath(id uint) string {
+	return "/api/v1/hooks/git/" + strconv.FormatUint(uint64(id), 10)
+}
+
+// shortCommit 提交哈希的前 12 位，用作版本标签
+// First 12 characters of a commit hash, used as the version tag
+func shortCommit(commit string) string {
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+	return commit
+}
+
+func (GitApi) toDTO(c *app.RequestContext, integration *models.GitIntegration) GitIntegrationDTO {
+	return GitIntegrationDTO{
+		ID:           integration.ID,
+		Provider:     integration.Provider,
+		RepoURL:      integration.RepoURL,
+		Branch:       integration.Branch,
+		BuildCommand: integration.BuildCommand,
+		BuildPreset:  integration.BuildPreset,
+		BuildImage:   integration.BuildImage,
+		RunnerLabels: integration.RunnerLabels,
+		OutputDir:    integration.OutputDir,
+		HasToken:     integration.Token != "",
+		HookURL:      string(c.URI().Scheme()) + "://" + string(c.Host()) + gitHookPath(integration.ID),
+		LastCommit:   integration.LastCommit,
+		LastStatus:   integration.LastStatus,
+		LastError:    integration.LastError,
+		LastLog:      integration.LastLog,
+		LastRunAt:    integration.LastRunAt,
+		CreatedAt:    integration.CreatedAt,
+	}
+}
+
+// apply 校验请求并写入仓库绑定
+// Validate the request and apply it to the binding
+func (GitApi) apply(c *app.RequestContext, integration *models.GitIntegration, req *GitIntegrationReq) bool {
+	if !slices.Contains(constants.GitProviders, req.Provider) {
+		resps.BadRequest(c, "unknown provider "+req.Provider)
+		return false
+	}
+	if err := task.CheckGitRepo(req.RepoURL); err != nil {
+		resps.BadRequest(c, err.Error())
+		return false
+	}
+	if req.Branch == "" {
+		req.Branch = "main"
+	}
+	if !gitBranchPattern.MatchString(req.Branch) || strings.Contains(req.Branch, "..") {
+		resps.BadRequest(c, "invalid branch name")
+		return false
+	}
+	// 预设只填充未指定的命令、输出目录和镜像 Presets only fill in the command, output directory and image left empty
+	if req.BuildPreset != "" {
+		preset, ok := task.BuildPresets[req.BuildPreset]
+		if !ok {
+			resps.BadRequest(c, "unknown build preset "+req.BuildPreset)
+			return false
+		}
+		req.BuildCommand = cmp.Or(req.BuildCommand, preset.Command)
+		req.OutputDir = cmp.Or(req.OutputDir, preset.OutputDir)
+		req.BuildImage = cmp.Or(req.BuildImage, preset.Image)
+	}
+	if req.BuildImage != "" && !buildImagePattern.MatchString(req.BuildImage) {
+		resps.BadRequest(c, "invalid build image")
+		return false
+	}
+	// 交给远程执行器时由执行器决定能否构建 Runners decide whether they can build when builds are handed to them
+	if req.BuildCommand != "" && !config.GitBuildEnable && !config.GitRunners {
+		resps.BadRequest(c, task.ErrGitBuildDisabled.Error())
+		return false
+	}
+	for _, label := range req.RunnerLabels {
+		if !runnerLabelPattern.MatchString(label) {
+			resps.BadRequest(c, "invalid runner label "+label)
+			return false
+		}
+	}
+	if strings.Contains("/"+req.OutputDir+"/", "/../") {
+		resps.BadRequest(c, task.ErrGitOutputDir.Error())
+		return false
+	}
+	integration.Provider = req.Provider
+	integration.RepoURL = req.RepoURL
+	integration.Branch = req.Branch
+	integration.BuildCommand = req.BuildCommand
+	integration.BuildPreset = req.BuildPreset
+	integration.BuildImage = req.BuildImage
+	integration.RunnerLabels = req.RunnerLabels
+	integration.OutputDir = strings.Trim(req.OutputDir, "/")
+	if req.Secret != "" {
+		integration.Secret = req.Secret
+	}
+	if req.Token != nil {
+		integration.Token = *req.Token
+	}
+	return true
+}
+
+// Get 获取站点绑定的仓库及最近一次运行
+// Get the repository bound to the site and its latest run
+func (GitApi) Get(ctx context.Context, c *app.RequestContext) {
+	site := getSite(c)
+	if site == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	integration, err := store.Git.GetBySite(site.ID)
+	if err != nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"git": Git.toDTO(c, integration),
+	})
+}
+
+// Save 为站点绑定或更新仓库，首次绑定时未提供密钥则生成一个并只在此时返回
+// Bind or update the site's repository, generating a secret on first binding when none is given and returning it only then
+func (GitApi) Save(ctx context.Context, c *app.RequestContext) {
+	site := getSite(c)
+	if site == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	req := GitIntegrationReq{}
+	if !bind(c, &req) {
+		return
+	}
+	integration, err := store.Git.GetBySite(site.ID)
+	created := err != nil
+	if created {
+		integration = &models.GitIntegration{SiteID: site.ID}
+	}
+	if !Git.apply(c, integration, &req) {
+		return
+	}
+	if integration.Secret == "" {
+		secret, err := utils.Webhook.NewSecret()
+		if err != nil {
+			resps.InternalServerError(c, "create push secret error")
+			return
+		}
+		integration.Secret = secret
+	}
+	if created {
+		err = store.Git.Create(integration)
+	} else {
+		err = store.Git.Update(integration)
+	}
+	if err != nil {
+		resps.InternalServerError(c, "save git integration error")
+		return
+	}
+	gitDTO := Git.toDTO(c, integration)
+	if created || req.Secret != "" {
+		gitDTO.Secret = integration.Secret
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"git": gitDTO,
+	})
+}
+
+// Delete 解除站点绑定的仓库，已发布的版本保留
+// Unbind the site's repository, published versions are kept
+func (GitApi) Delete(ctx context.Context, c *app.RequestContext) {
+	site := getSite(c)
+	if site == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	integration, err := store.Git.GetBySite(site.ID)
+	if err != nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	if err := store.Git.Delete(integration); err != nil {
+		resps.DBError(c, err, "delete git integration error")
+		return
+	}
+	resps.Ok(c, resps.OK)
+}
+
+// Logs 以 SSE 实时推送站点最近一次构建的输出，先发送已有的输出，构建结束时发送 done 事件；输出从仓库绑定记录读取，构建可以在其他实例上进行
+// Stream the output of the site's latest build as SSE, sending the output so far first and a done event once the build ends; the output is read from the binding record so the build may run on another instance
+func (GitApi) Logs(ctx context.Context, c *app.RequestContext) {
+	site := getSite(c)
+	if site == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	integration, err := store.Git.GetBySite(site.ID)
+	if err != nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	c.SetStatusCode(200)
+	c.Response.Header.SetContentType("text/event-stream")
+	c.Response.Header.Set("Cache-Control", "no-cache")
+	c.Response.Header.Set("X-Accel-Buffering", "no")
+	c.Response.HijackWriter(resp.NewChunkedBodyWriter(&c.Response, c.GetWriter()))
+	_, _ = c.WriteString(": connected\n\n")
+	if err := c.Flush(); err != nil {
+		return
+	}
+
+	poll := time.NewTicker(jobEventInterval)
+	defer poll.Stop()
+	heartbeat := time.NewTicker(tailHeartbeatInterval)
+	defer heartbeat.Stop()
+	sent := ""
+	for {
+		// 保存的输出只保留末尾，开头被截掉时重新发送全部 The kept output is only the tail, everything is sent again once its start is cut off
+		if integration.LastLog != sent {
+			event, text := "log", strings.TrimPrefix(integration.LastLog, sent)
+			if !strings.HasPrefix(integration.LastLog, sent) {
+				event, text = "reset", integration.LastLog
+			}
+			sent = integration.LastLog
+			data, _ := json.Marshal(GitLogDTO{Text: text})
+			_, _ = c.WriteString("event: " + event + "\ndata: " + string(data) + "\n\n")
+			if err := c.Flush(); err != nil {
+				return
+			}
+		}
+		if integration.LastStatus != constants.GitRunQueued && integration.LastStatus != constants.GitRunBuilding {
+			data, _ := json.Marshal(GitRunDTO{Commit: integration.LastCommit, Status: integration.LastStatus, Error: integration.LastError})
+			_, _ = c.WriteString("event: done\ndata: " + string(data) + "\n\n")
+			_ = c.Flush()
+			return
+		}
+		select {
+		case <-poll.C:
+		case <-heartbeat.C:
+			// 心跳写入失败说明客户端已断开 A failed heartbeat means the client is gone
+			_, _ = c.WriteString(": ping\n\n")
+			if err := c.Flush(); err != nil {
+				return
+			}
+			continue
+		case <-ctx.Done():
+			return
+		}
+		next, err := store.Git.GetBySite(site.ID)
+		if err != nil {
+			return
+		}
+		integration = next
+	}
+}
+
+// Deploy 手动部署分支的最新提交
+// Deploy the head of the branch manually
+func (GitApi) Deploy(ctx context.Context, c *app.RequestContext) {
+	site := getSite(c)
+	if site == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	integration, err := store.Git.GetBySite(site.ID)
+	if err != nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	integration.Site = *site
+	Git.deploy(ctx, integration, "", false)
+	resps.Custom(c, http.StatusAccepted, "deployment queued")
+}
+
+// Receive 接收 GitHub 或 GitLab 的推送事件，校验签名后在后台部署推送的提交
+// Receive push events from GitHub or GitLab, deploying the pushed commit in the background once the signature checks out
+func (GitApi) Receive(ctx context.Context, c *app.RequestContext) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	integration, err := store.Git.GetByID(uint(id))
+	// 站点删除后绑定失效 The binding is void once the site is deleted
+	if err != nil || integration.Site.ID == 0 {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	body := c.Request.Body()
+	switch integration.Provider {
+	case constants.GitProviderGitHub:
+		signature := string(c.GetHeader("X-Hub-Signature-256"))
+		if !hmac.Equal([]byte(signature), []byte(utils.Webhook.Sign(integration.Secret, body))) {
+			resps.Unauthorized(c, "invalid signature")
+			return
+		}
+		switch string(c.GetHeader("X-GitHub-Event")) {
+		case "push":
+		case "ping":
+			resps.Ok(c, "pong")
+			return
+		default:
+			resps.Ok(c, "event ignored")
+			return
+		}
+	case constants.GitProviderGitLab:
+		if subtle.ConstantTimeCompare(c.GetHeader("X-Gitlab-Token"), []byte(integration.Secret)) != 1 {
+			resps.Unauthorized(c, "invalid token")
+			return
+		}
+		if string(c.GetHeader("X-Gitlab-Event")) != "Push Hook" {
+			resps.Ok(c, "event ignored")
+			return
+		}
+	default:
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	push := gitPush{}
+	if err := json.Unmarshal(body, &push); err != nil {
+		resps.BadRequest(c, resps.ParameterError)
+		return
+	}
+	if push.Ref != "refs/heads/"+integration.Branch {
+		resps.Ok(c, "branch ignored")
+		return
+	}
+	// 分支删除时提交为全零 The commit is all zeros when the branch is deleted
+	if push.Deleted || strings.Trim(push.After, "0") == "" {
+		resps.Ok(c, "branch deletion ignored")
+		return
+	}
+	if !gitCommitPattern.MatchString(push.After) {
+		resps.BadRequest(c, "invalid commit")
+		return
+	}
+	Git.deploy(ctx, integration, push.After, false)
+	resps.Custom(c, http.StatusAccepted, "deployment queued", map[string]any{"commit": push.After})
+}
+
+// deploy 记录推送并在后台拉取、构建和发布，commit 为空时部署分支的最新提交；mapCNAME 为真时发布后绑定 CNAME 文件中的域名；
+// 开启 git.runners 时构建排队等待远程执行器领取
+// Record the push and fetch, build and publish it in the background, deploying the head of the branch when commit is empty; with mapCNAME the domain in the CNAME file is bound after publishing;
+// with git.runners the build is queued for a remote runner to take
+func (GitApi) deploy(ctx context.Context, integration *models.GitIntegration, commit string, mapCNAME bool) {
+	if err := store.Git.SaveRun(integration.ID, commit, constants.GitRunQueued, "", ""); err != nil {
+		utils.Log.Ctx(ctx).Warnf("failed to record git push of site %d: %v", integration.SiteID, err)
+	}
+	if config.GitRunners {
+		build := &models.RunnerBuild{SiteID: integration.SiteID, IntegrationID: integration.ID, Commit: commit, MapCNAME: mapCNAME, Labels: integration.RunnerLabels}
+		if err := store.Runner.QueueBuild(build, time.Now()); err != nil {
+			utils.Log.Ctx(ctx).Warnf("failed to queue the git build of site %d: %v", integration.SiteID, err)
+			Git.saveRun(ctx, integration, commit, constants.GitRunFailed, err.Error(), "")
+		}
+		return
+	}
+	// 构建在请求结束后继续，保留请求ID便于关联日志 The build outlives the request, keeping the request ID to correlate logs
+	runCtx := utils.Log.WithRequestID(context.Background(), utils.Log.RequestID(ctx))
+	go Git.run(runCtx, *integration, commit, mapCNAME)
+}
+
+func (GitApi) run(ctx context.Context, integration models.GitIntegration, commit string, mapCNAME bool) {
+	site := &integration.Site
+	log := utils.Log.Ctx(ctx)
+	if err := store.Git.SaveRun(integration.ID, commit, constants.GitRunBuilding, "", ""); err != nil {
+		log.Warnf("failed to record git build of site %d: %v", site.ID, err)
+	}
+	result, err := task.RunGitBuild(ctx, &task.GitBuild{
+		Provider:     integration.Provider,
+		RepoURL:      integration.RepoURL,
+		Token:        integration.Token,
+		Branch:       integration.Branch,
+		Commit:       commit,
+		BuildCommand: integration.BuildCommand,
+		BuildImage:   integration.BuildImage,
+		OutputDir:    integration.OutputDir,
+		OnLog: func(output string) {
+			if err := store.Git.SaveLog(integration.ID, output); err != nil {
+				log.Warnf("failed to record build output of site %d: %v", site.ID, err)
+			}
+		},
+	})
+	if err != nil {
+		built, output := commit, ""
+		if result != nil {
+			built, output = result.Commit, result.Log
+		}
+		Git.fail(ctx, &integration, built, err.Error(), output)
+		return
+	}
+	defer result.Cleanup()
+	Git.publish(ctx, &integration, commit, result, mapCNAME)
+}
+
+// fail 记录构建失败并通知项目 webhook，integration 需要带有站点和项目
+// Record a failed build and notify the project webhooks, integration must carry its site and project
+func (GitApi) fail(ctx context.Context, integration *models.GitIntegration, commit, reason, output string) {
+	site := &integration.Site
+	utils.Log.Ctx(ctx).Warnf("git build of site %d at %s failed: %s", site.ID, commit, reason)
+	Git.saveRun(ctx, integration, commit, constants.GitRunFailed, reason, output)
+	Webhook.emit(ctx, &site.Project, constants.WebhookDeploymentFailed,
+		i18n.Msg("Build of %s for site %s failed: %s", shortCommit(commit), site.Name, reason),
+		map[string]any{"site_id": site.ID, "site": site.Name, "commit": commit, "reason": reason})
+}
+
+// publish 将构建结果发布为站点的新版本，requested 为推送时请求构建的提交；integration 需要带有站点和项目
+// Publish the build result as a new version of the site, requested is the commit asked for by the push; integration must carry its site and project
+func (GitApi) publish(ctx context.Context, integration *models.GitIntegration, requested string, result *task.GitResult, mapCNAME bool) {
+	site := &integration.Site
+	log := utils.Log.Ctx(ctx)
+	// 构建期间收到更新的推送时放弃发布，避免旧提交覆盖新提交
+	// Skip publishing when a newer push arrived during the build, so an older commit never overrides a newer one
+	if current, err := store.Git.GetBySite(site.ID); err != nil || current.ID != integration.ID || current.LastCommit != requested {
+		log.Infof("git build of site %d at %s superseded, not publishing", site.ID, result.Commit)
+		return
+	}
+	archive, err := fileArchive(result.Archive)
+	if err == nil {
+		var release *models.SiteRelease
+		var previousID uint
+		if release, previousID, err = Release.publish(ctx, site, archive, shortCommit(result.Commit), true); err == nil {
+			Git.saveRun(ctx, integration, result.Commit, constants.GitRunSucceeded, "", result.Log)
+			Audit.record(ctx, nil, nil, constants.AuditReleaseCreate, constants.AuditTargetSite, site.ID, map[string]any{
+				"release_id":          release.ID,
+				"tag":                 release.Tag,
+				"file_id":             release.FileID,
+				"previous_release_id": previousID,
+				"commit":              result.Commit,
+				"source":              integration.Provider,
+			})
+			if release.Status.Servable() {
+				Release.notifyDeployed(ctx, site, release, previousID)
+			}
+			if mapCNAME {
+				if _, err := Import.bindCNAME(site, result.Archive); err != nil {
+					log.Warnf("failed to bind the CNAME domain of site %d: %v", site.ID, err)
+				}
+			}
+			return
+		}
+	}
+	log.Warnf("failed to publish git build of site %d at %s: %v", site.ID, result.Commit, err)
+	Git.saveRun(ctx, integration, result.Commit, constants.GitRunFailed, err.Error(), result.Log)
+}
+
+func (GitApi) saveRun(ctx context.Context, integration *models.GitIntegration, commit, status, runErr, output string) {
+	if err := store.Git.SaveRun(integration.ID, commit, status, runErr, output); err != nil {
+		utils.Log.Ctx(ctx).Warnf("failed to record git run of site %d: %v", integration.SiteID, err)
+	}
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import "time"
+
+// GitIntegrationDTO 站点仓库绑定数据传输对象，推送密钥只在创建时返回，访问令牌从不返回
+// Site repository binding Data Transfer Object (DTO), the push secret is only returned on creation and the access token never
+type GitIntegrationDTO struct {
+	ID           uint       `json:"id"`               // 绑定ID Binding ID
+	Provider     string     `json:"provider"`         // 代码托管平台 Git hosting provider
+	RepoURL      string     `json:"repo_url"`         // 仓库地址 Repository URL
+	Branch       string     `json:"branch"`           // 部署的分支 Deployed branch
+	BuildCommand string     `json:"build_command"`    // 构建命令 Build command
+	BuildPreset  string     `json:"build_preset"`     // 构建预设 Build preset
+	BuildImage   string     `json:"build_image"`      // 构建镜像 Build image
+	RunnerLabels []string   `json:"runner_labels"`    // 远程执行器必须具有的标签 Labels a remote runner must have
+	OutputDir    string     `json:"output_dir"`       // 输出目录 Output directory
+	HasToken     bool       `json:"has_token"`        // 是否设置了访问令牌 Whether an access token is set
+	Secret       string     `json:"secret,omitempty"` // 推送事件的签名密钥或令牌 Signing secret or token of push events
+	HookURL      string     `json:"hook_url"`         // 在托管平台填写的 webhook 地址 Webhook URL to configure on the provider
+	LastCommit   string     `json:"last_commit"`      // 最近一次推送的提交 Commit of the latest push
+	LastStatus   string     `json:"last_status"`      // 最近一次运行的状态 Status of the latest run
+	LastError    string     `json:"last_error"`       // 最近一次运行的错误 Error of the latest run
+	LastLog      string     `json:"last_log"`         // 最近一次构建的输出 Output of the latest build
+	LastRunAt    *time.Time `json:"last_run_at"`      // 最近一次运行的时间 Time of the latest run
+	CreatedAt    time.Time  `json:"created_at"`       // 创建时间 Created time
+}
+
+// GitIntegrationReq 绑定或更新仓库的请求参数，更新时未提供的密钥和令牌保持不变，令牌传空字符串时清除
+// Request parameters to bind or update a repository, the secret and token are kept when not given on update and an empty token clears it
+type GitIntegrationReq struct {
+	Provider     string   `json:"provider" binding:"required"` // 代码托管平台 github 或 gitlab Git hosting provider, github or gitlab
+	RepoURL      string   `json:"repo_url" binding:"required"` // 仓库 https 地址 Repository https URL
+	Branch       string   `json:"branch"`                      // 部署的分支，默认 main Deployed branch, main by default
+	Secret       string   `json:"secret"`                      // 推送密钥，创建时为空则自动生成 Push secret, generated when empty on creation
+	Token        *string  `json:"token"`                       // 拉取私有仓库的访问令牌 Access token for private repositories
+	BuildCommand string   `json:"build_command"`               // 构建命令，需要实例开启 git.build-enable Build command, requires git.build-enable
+	BuildPreset  string   `json:"build_preset"`                // 构建预设 hugo、mkdocs 或 vite，填充未指定的命令、输出目录和镜像 Build preset hugo, mkdocs or vite, filling in the command, output directory and image left empty
+	BuildImage   string   `json:"build_image"`                 // 构建镜像，替换沙箱前缀中的 {image} Build image, replacing {image} in the sandbox prefix
+	RunnerLabels []string `json:"runner_labels"`               // 开启 git.runners 时执行器必须具有的标签 Labels a runner must have when git.runners is enabled
+	OutputDir    string   `json:"output_dir"`                  // 发布的输出目录，默认仓库根目录 Published output directory, the repository root by default
+}
+
+// GitLogDTO 构建输出事件 Build output event
+type GitLogDTO struct {
+	Text string `json:"text"` // 新增的输出，reset 事件中为全部输出 Output added, the whole output in reset events
+}
+
+// GitRunDTO 构建结束事件 Build finished event
+type GitRunDTO struct {
+	Commit string `json:"commit"` // 构建的提交 Built commit
+	Status string `json:"status"` // 运行状态 Run status
+	Error  string `json:"error"`  // 失败原因 Failure reason
+}
+
+// gitPush GitHub 和 GitLab 推送事件中共同使用的字段
+// Fields shared by GitHub and GitLab push events
+type gitPush struct {
+	Ref     string `json:"ref"`     // 推送的引用 Pushed ref
+	After   string `json:"after"`   // 推送后的提交 Commit after the push
+	Deleted bool   `json:"deleted"` // 分支是否被删除，仅 GitHub Whether the branch was deleted, GitHub only
+}
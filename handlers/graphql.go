@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/graphql"
+	"github.com/LiteyukiStudio/spage/middle"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+const (
+	// graphqlMaxDepth GraphQL 查询最多嵌套的层数
+	// Max nesting of a GraphQL query
+	graphqlMaxDepth = 8
+	// graphqlMaxFields 一次 GraphQL 查询最多解析的字段数
+	// Max fields resolved by one GraphQL query
+	graphqlMaxFields = 5000
+	// defaultGraphQLLimit 列表字段默认返回的数量
+	// Items returned by list fields by default
+	defaultGraphQLLimit = 20
+	// maxGraphQLLimit 列表字段最多返回的数量
+	// Max items returned by list fields
+	maxGraphQLLimit = 100
+)
+
+type GraphQLApi struct{}
+
+// GraphQL 与 REST 接口并行的只读 GraphQL 接口，一次请求获取嵌套的资源，权限与对应的 REST 接口相同
+// Read-only GraphQL API alongside REST fetching nested resources in one request, with the same permissions as the matching REST endpoints
+var GraphQL = GraphQLApi{}
+
+// graphqlSchema GraphQL 接口的模式
+// Schema of the GraphQL API
+var graphqlSchema = newGraphQLSchema()
+
+// Query 执行 GraphQL 查询，结果按 GraphQL 规范返回 data 和 errors，不使用 REST 的响应结构
+// Execute a GraphQL query, answered with data and errors as the GraphQL spec describes instead of the REST response structure
+func (GraphQLApi) Query(ctx context.Context, c *app.RequestContext) {
+	req := GraphQLReq{}
+	if err := c.BindAndValidate(&req); err != nil || strings.TrimSpace(req.Query) == "" {
+		resps.BadRequest(c, resps.ParameterError)
+		return
+	}
+	user := middle.Auth.GetUser(ctx, c)
+	if user == nil {
+		return
+	}
+	scope := newGraphQLScope(ctx, user)
+	result := graphqlSchema.Execute(context.WithValue(ctx, graphqlScopeKey{}, scope), graphql.Request{
+		Query:         req.Query,
+		OperationName: req.OperationName,
+		Variables:     req.Variables,
+	})
+	c.JSON(http.StatusOK, result)
+}
+
+// Schema 以 GraphQL 模式定义语言获取接口的类型
+// Get the types of the API in the GraphQL schema definition language
+func (GraphQLApi) Schema(ctx context.Context, c *app.RequestContext) {
+	c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(graphqlSchema.SDL()))
+}
+
+type graphqlScopeKey struct{}
+
+// graphqlListKey 列表加载器的键，同一次批量读取中数量相同的键合并查询
+// Key of the list loaders, keys with the same limit are queried together within a batch
+type graphqlListKey struct {
+	ID    uint
+	Limit int
+}
+
+// graphqlScope 一次 GraphQL 请求的当前用户和加载器，加载器合并同一层字段的数据库查询
+// Current user and loaders of one GraphQL request, the loaders merge the database queries of the fields of a level
+type graphqlScope struct {
+	ctx      context.Context
+	user     *models.User
+	now      time.Time
+	projects map[uint]*models.Project // 已解析的项目，供按项目批量计算角色 Projects resolved so far, used to compute roles in batches
+	flushed  bool                     // 本次请求是否已写入待写入的浏览量 Whether the pending views were written for this request
+
+	orgRoles     *graphql.Loader[uint, constants.OrgRole]
+	orgProjects  *graphql.Loader[graphqlListKey, []models.Project]
+	projectRoles *graphql.Loader[uint, constants.OrgRole]
+	projectSites *graphql.Loader[graphqlListKey, []models.Site]
+	deployments  *graphql.Loader[graphqlListKey, []models.SiteRelease]
+	activeIDs    *graphql.Loader[uint, uint]
+	releases     *graphql.Loader[uint, models.SiteRelease]
+	daily        *graphql.Loader[graphqlListKey, []store.DailyViews]
+	transfer     *graphql.Loader[uint, store.SiteTrafficSum]
+}
+
+func newGraphQLScope(ctx context.Context, user *models.User) *graphqlScope {
+	s := &graphqlScope{ctx: ctx, user: user, now: time.Now(), projects: map[uint]*models.Project{}}
+	s.orgRoles = graphql.NewLoader(func(ids []uint) (map[uint]constants.OrgRole, error) {
+		roles, err := store.Org.UserRoles(ids, user.ID)
+		return roles, s.internal(err, "organization roles")
+	})
+	s.orgProjects = graphql.NewLoader(func(keys []graphqlListKey) (map[graphqlListKey][]models.Project, error) {
+		projects, err := graphqlBatch(keys, func(ids []uint, limit int) (map[uint][]models.Project, error) {
+			return store.Project.ListByOwners(ctx, constants.OwnerTypeOrg, ids, limit)
+		})
+		return projects, s.internal(err, "projects")
+	})
+	s.projectRoles = graphql.NewLoader(func(ids []uint) (map[uint]constants.OrgRole, error) {
+		projects := make([]*models.Project, 0, len(ids))
+		for _, id := range ids {
+			projects = append(projects, s.projects[id])
+		}
+		roles, err := store.Project.UserRoles(projects, user.ID)
+		return roles, s.internal(err, "project roles")
+	})
+	s.projectSites = graphql.NewLoader(func(keys []graphqlListKey) (map[graphqlListKey][]models.Site, error) {
+		sites, err := graphqlBatch(keys, store.Project.ListSitesByProjects)
+		return sites, s.internal(err, "sites")
+	})
+	s.deployments = graphql.NewLoader(func(keys []graphqlListKey) (map[graphqlListKey][]models.SiteRelease, error) {
+		releases, err := graphqlBatch(keys, store.Site.ListVersionsBySites)
+		return releases, s.internal(err, "deployments")
+	})
+	s.activeIDs = graphql.NewLoader(func(ids []uint) (map[uint]uint, error) {
+		active, err := store.Site.ActiveReleaseIDs(ids)
+		return active, s.internal(err, "deployments")
+	})
+	s.releases = graphql.NewLoader(func(ids []uint) (map[uint]models.SiteRelease, error) {
+		releases, err := store.Site.GetReleasesByIDs(ids)
+		return releases, s.internal(err, "deployments")
+	})
+	// 按天数合并，Limit 为统计的天数 Merged by days, Limit holds the days covered
+	s.daily = graphql.NewLoader(func(keys []graphqlListKey) (map[graphqlListKey][]store.DailyViews, error) {
+		s.flushAnalytics()
+		daily, err := graphqlBatch(keys, func(ids []uint, days int) (map[uint][]store.DailyViews, error) {
+			return store.Analytics.DailyBySites(ctx, ids, s.since(days))
+		})
+		return daily, s.internal(err, "analytics")
+	})
+	s.transfer = graphql.NewLoader(func(ids []uint) (map[uint]store.SiteTrafficSum, error) {
+		sums, err := store.Traffic.Months(ctx, ids, s.now)
+		return sums, s.internal(err, "analytics")
+	})
+	return s
+}
+
+// graphqlScopeOf 获取解析函数所在请求的作用域
+// Scope of the request a resolver runs in
+func graphqlScopeOf(ctx context.Context) *graphqlScope {
+	return ctx.Value(graphqlScopeKey{}).(*graphqlScope)
+}
+
+// graphqlBatch 将列表键按数量分组查询，fetch 返回按ID索引的结果
+// Query list keys grouped by limit, fetch returns results keyed by ID
+func graphqlBatch[V any](keys []graphqlListKey, fetch func(ids []uint, limit int) (map[uint]V, error)) (map[graphqlListKey]V, error) {
+	groups := map[int][]uint{}
+	for _, key := range keys {
+		groups[key.Limit] = append(groups[key.Limit], key.ID)
+	}
+	result := make(map[graphqlListKey]V, len(keys))
+	for limit, ids := range groups {
+		values, err := fetch(ids, limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			result[graphqlListKey{id, limit}] = values[id]
+		}
+	}
+	return result, nil
+}
+
+// internal 记录数据库错误，只向客户端返回概括的错误
+// Log a database error and only give the client a general one
+func (s *graphqlScope) internal(err error, what string) error {
+	if err == nil {
+		return nil
+	}
+	utils.Log.Ctx(s.ctx).Errorf("graphql: failed to load %s: %v", what, err)
+	return fmt.Errorf("failed to load %s", what)
+}
+
+// flushAnalytics 读取访问分析前写入本实例尚未写入的浏览量，每次请求只写入一次
+// Write the views this instance has not written yet before reading analytics, once per request
+func (s *graphqlScope) flushAnalytics() {
+	if s.flushed {
+		return
+	}
+	s.flushed = true
+	if err := store.Analytics.Flush(s.ctx); err != nil {
+		utils.Log.Ctx(s.ctx).Warnf("failed to write site analytics: %v", err)
+	}
+}
+
+// since 最近 days 天的起始日期
+// Start day of the last days days
+func (s *graphqlScope) since(days int) time.Time {
+	return s.now.UTC().Truncate(24*time.Hour).AddDate(0, 0, 1-days)
+}
+
+// project 登记项目并转换为解析使用的值
+// Register a project and turn it into the value resolvers use
+func (s *graphqlScope) project(project *models.Project) graphqlProject {
+	s.projects[project.ID] = project
+	return graphqlProject{ProjectDTO: Project.toDTO(project, true), project: project}
+}
+
+// graphqlID 读取ID参数
+// Read an ID argument
+func graphqlID(args map[string]any, name string) (uint, error) {
+	id, _ := args[name].(int)
+	if id < 1 {
+		return 0, fmt.Errorf("%s must be a positive id", name)
+	}
+	return uint(id), nil
+}
+
+// graphqlInt 读取整数参数并检查范围
+// Read an integer argument and check its range
+func graphqlInt(args map[string]any, name string, max int) (int, error) {
+	n, _ := args[name].(int)
+	if n < 1 || n > max {
+		return 0, fmt.Errorf("%s must be between 1 and %d", name, max)
+	}
+	return n, nil
+}
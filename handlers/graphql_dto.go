@@ -0,0 +1,9 @@
+package handlers
+
+// GraphQLReq GraphQL 查询请求
+// GraphQL query request
+type GraphQLReq struct {
+	Query         string         `json:"query"`         // 查询文档 Query document
+	OperationName string         `json:"operationName"` // 文档包含多个操作时要执行的操作 Operation to run when the document has several
+	Variables     map[string]any `json:"variables"`     // 查询变量 Query variables
+}
@@ -0,0 +1,495 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/graphql"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/store"
+)
+
+// graphqlProject 项目字段的值，保留模型以便解析嵌套字段
+// Value of project fields, keeping the model for the nested fields
+type graphqlProject struct {
+	ProjectDTO
+	project *models.Project
+}
+
+// graphqlSite 站点字段的值，模型的 Project 已加载
+// Value of site fields, with the Project of the model loaded
+type graphqlSite struct {
+	SiteDTO
+	site *models.Site
+}
+
+// graphqlAnalytics 站点访问分析字段的值
+// Value of site analytics fields
+type graphqlAnalytics struct {
+	Days int `json:"days"`
+	site *models.Site
+}
+
+var (
+	errGraphQLNotFound  = errors.New(resps.TargetNotFound)
+	errGraphQLForbidden = errors.New(resps.PermissionDenied)
+)
+
+var (
+	// graphqlTime RFC 3339 格式的时间 Time in RFC 3339
+	graphqlTime = &graphql.Scalar{Name: "Time", Description: "Time in RFC 3339", Coerce: func(value any) (any, error) {
+		s, _ := value.(string)
+		return time.Parse(time.RFC3339, s)
+	}}
+	// graphqlBigInt 超出 Int 范围的 64 位整数，例如字节数 64-bit integers beyond Int, such as byte counts
+	graphqlBigInt = &graphql.Scalar{Name: "BigInt", Description: "64-bit integer", Coerce: func(value any) (any, error) {
+		if n, ok := value.(float64); ok && n == float64(int64(n)) {
+			return int64(n), nil
+		}
+		if n, ok := value.(int64); ok {
+			return n, nil
+		}
+		return nil, errors.New("expected BigInt")
+	}}
+	// graphqlMap 字符串到字符串的映射，例如项目标签 A map of strings to strings, such as project labels
+	graphqlMap = &graphql.Scalar{Name: "Map", Description: "Object of string values", Coerce: func(value any) (any, error) {
+		return nil, errors.New("a Map cannot be used as an argument")
+	}}
+)
+
+// graphqlLimitArg 列表字段的数量参数
+// Limit argument of list fields
+func graphqlLimitArg(def int) graphql.Args {
+	return graphql.Args{"limit": {Type: graphql.Int, Default: def, Description: "Max items returned"}}
+}
+
+// newGraphQLSchema 创建 GraphQL 模式，类型之间相互引用，字段在类型创建后补充
+// Create the GraphQL schema, the types reference each other so fields are added once the types exist
+func newGraphQLSchema() *graphql.Schema {
+	user := &graphql.Object{Name: "User", Description: "A user", Fields: graphql.Fields{
+		"id":             {Type: graphql.NonNullOf(graphql.Int)},
+		"name":           {Type: graphql.String},
+		"display_name":   {Type: graphql.String},
+		"email":          {Type: graphql.String},
+		"description":    {Type: graphql.String},
+		"avatar_url":     {Type: graphql.String},
+		"role":           {Type: graphql.String},
+		"language":       {Type: graphql.String},
+		"email_verified": {Type: graphql.Boolean},
+	}}
+	organization := &graphql.Object{Name: "Organization", Description: "An organization the current user is a member of", Fields: graphql.Fields{
+		"id":            {Type: graphql.NonNullOf(graphql.Int)},
+		"name":          {Type: graphql.String},
+		"display_name":  {Type: graphql.String},
+		"email":         {Type: graphql.String},
+		"description":   {Type: graphql.String},
+		"avatar_url":    {Type: graphql.String},
+		"project_limit": {Type: graphql.Int},
+	}}
+	project := &graphql.Object{Name: "Project", Description: "A project the current user has a role in", Fields: graphql.Fields{
+		"id":                {Type: graphql.NonNullOf(graphql.Int)},
+		"name":              {Type: graphql.String},
+		"display_name":      {Type: graphql.String},
+		"description":       {Type: graphql.String},
+		"owner_type":        {Type: graphql.String},
+		"owner_id":          {Type: graphql.Int},
+		"site_limit":        {Type: graphql.Int},
+		"visibility":        {Type: graphql.String},
+		"primary_site_id":   {Type: graphql.Int},
+		"host":              {Type: graphql.String},
+		"max_upload_size":   {Type: graphqlBigInt},
+		"directory_listing": {Type: graphql.Boolean},
+		"labels":            {Type: graphqlMap},
+	}}
+	site := &graphql.Object{Name: "Site", Description: "A site of a project", Fields: graphql.Fields{
+		"id":                  {Type: graphql.NonNullOf(graphql.Int)},
+		"name":                {Type: graphql.String},
+		"description":         {Type: graphql.String},
+		"sub_domain":          {Type: graphql.String},
+		"domains":             {Type: graphql.ListOf(graphql.String)},
+		"og_preview":          {Type: graphql.Boolean},
+		"headers":             {Type: graphql.ListOf(graphql.String)},
+		"cache_rules":         {Type: graphql.ListOf(graphql.String)},
+		"access_mode":         {Type: graphql.String},
+		"has_access_password": {Type: graphql.Boolean},
+		"allow_ips":           {Type: graphql.ListOf(graphql.String)},
+		"deny_ips":            {Type: graphql.ListOf(graphql.String)},
+		"sitemap":             {Type: graphql.Boolean},
+		"robots_policy":       {Type: graphql.String},
+		"robots_txt":          {Type: graphql.String},
+	}}
+	deployment := &graphql.Object{Name: "Deployment", Description: "A deployed version of a site", Fields: graphql.Fields{
+		"id":            {Type: graphql.NonNullOf(graphql.Int)},
+		"tag":           {Type: graphql.String},
+		"status":        {Type: graphql.String},
+		"active":        {Type: graphql.Boolean, Description: "Whether it is the active version"},
+		"publish_at":    {Type: graphqlTime},
+		"expires_at":    {Type: graphqlTime},
+		"expire_action": {Type: graphql.String},
+		"created_at":    {Type: graphqlTime},
+		"file": {Type: &graphql.Object{Name: "File", Fields: graphql.Fields{
+			"id":       {Type: graphql.NonNullOf(graphql.Int)},
+			"hash":     {Type: graphql.String},
+			"size":     {Type: graphqlBigInt},
+			"manifest": {Type: graphql.Boolean},
+		}}},
+		"scan_findings": {Type: graphql.ListOf(&graphql.Object{Name: "ScanFinding", Fields: graphql.Fields{
+			"path":      {Type: graphql.String},
+			"signature": {Type: graphql.String},
+		}})},
+	}}
+	analyticsValue := &graphql.Object{Name: "AnalyticsValue", Fields: graphql.Fields{
+		"value": {Type: graphql.String},
+		"views": {Type: graphqlBigInt},
+	}}
+	analytics := &graphql.Object{Name: "Analytics", Description: "Site analytics read from the daily rollups", Fields: graphql.Fields{
+		"days": {Type: graphql.Int},
+		"daily": {Type: graphql.ListOf(&graphql.Object{Name: "DailyCount", Fields: graphql.Fields{
+			"day":   {Type: graphql.String},
+			"count": {Type: graphqlBigInt},
+		}}), Resolve: resolveAnalyticsDaily},
+		"views": {Type: graphqlBigInt, Resolve: resolveAnalyticsViews},
+		"transfer": {Type: &graphql.Object{Name: "Transfer", Description: "Transfer of this month and its limits", Fields: graphql.Fields{
+			"month":      {Type: graphql.String},
+			"requests":   {Type: graphqlBigInt},
+			"bytes":      {Type: graphqlBigInt},
+			"soft_limit": {Type: graphqlBigInt},
+			"hard_limit": {Type: graphqlBigInt},
+			"custom":     {Type: graphql.Boolean},
+			"action":     {Type: graphql.String},
+		}}, Resolve: resolveAnalyticsTransfer},
+	}}
+	for name, dimension := range map[string]string{
+		"paths":     models.AnalyticsPath,
+		"referrers": models.AnalyticsReferrer,
+		"countries": models.AnalyticsCountry,
+		"statuses":  models.AnalyticsStatus,
+	} {
+		analytics.Fields[name] = &graphql.Field{
+			Type:        graphql.ListOf(analyticsValue),
+			Description: "Values of the dimension with the most views",
+			Args:        graphqlLimitArg(defaultAnalyticsLimit),
+			Resolve:     resolveAnalyticsTop(dimension),
+		}
+	}
+
+	user.Fields["organizations"] = &graphql.Field{Type: graphql.ListOf(organization), Args: graphqlLimitArg(defaultGraphQLLimit), Resolve: resolveUserOrganizations}
+	user.Fields["projects"] = &graphql.Field{Type: graphql.ListOf(project), Description: "Personal projects", Args: graphqlLimitArg(defaultGraphQLLimit), Resolve: resolveUserProjects}
+	organization.Fields["role"] = &graphql.Field{Type: graphql.String, Description: "Role of the current user", Resolve: resolveOrganizationRole}
+	organization.Fields["projects"] = &graphql.Field{Type: graphql.ListOf(project), Args: graphqlLimitArg(defaultGraphQLLimit), Resolve: resolveOrganizationProjects}
+	project.Fields["role"] = &graphql.Field{Type: graphql.String, Description: "Role of the current user", Resolve: resolveProjectRole}
+	project.Fields["sites"] = &graphql.Field{Type: graphql.ListOf(site), Args: graphqlLimitArg(defaultGraphQLLimit), Resolve: resolveProjectSites}
+	site.Fields["project"] = &graphql.Field{Type: project, Resolve: resolveSiteProject}
+	site.Fields["deployments"] = &graphql.Field{Type: graphql.ListOf(deployment), Description: "Deployed versions, newest first", Args: graphqlLimitArg(defaultGraphQLLimit), Resolve: resolveSiteDeployments}
+	site.Fields["active_deployment"] = &graphql.Field{Type: deployment, Resolve: resolveSiteActiveDeployment}
+	site.Fields["analytics"] = &graphql.Field{Type: analytics, Args: graphql.Args{
+		"days": {Type: graphql.Int, Default: defaultStatsDays, Description: "Days covered"},
+	}, Resolve: resolveSiteAnalytics}
+
+	idArg := graphql.Args{"id": {Type: graphql.NonNullOf(graphql.Int)}}
+	return &graphql.Schema{MaxDepth: graphqlMaxDepth, MaxFields: graphqlMaxFields, Query: &graphql.Object{Name: "Query", Fields: graphql.Fields{
+		"me":           {Type: user, Description: "The current user", Resolve: resolveMe},
+		"organization": {Type: organization, Args: idArg, Resolve: resolveOrganization},
+		"project":      {Type: project, Args: idArg, Resolve: resolveProject},
+		"site":         {Type: site, Args: idArg, Resolve: resolveSite},
+	}}}
+}
+
+func resolveMe(p graphql.ResolveParams) (any, error) {
+	return User.ToDTO(graphqlScopeOf(p.Context).user, true), nil
+}
+
+// resolveOrganization 只有组织成员可以查看组织，与 REST 一样对其他用户视为不存在
+// Only members see an organization, hidden from other users as in REST
+func resolveOrganization(p graphql.ResolveParams) (any, error) {
+	scope := graphqlScopeOf(p.Context)
+	id, err := graphqlID(p.Args, "id")
+	if err != nil {
+		return nil, err
+	}
+	org, err := store.Org.GetOrgById(id)
+	if err != nil || !store.Org.GetUserAuth(org, scope.user.ID).Valid() {
+		return nil, errGraphQLNotFound
+	}
+	return Org.ToDTO(org), nil
+}
+
+// resolveProject 只有在项目中有角色的用户可以查看项目
+// Only users with a role in the project see it
+func resolveProject(p graphql.ResolveParams) (any, error) {
+	scope := graphqlScopeOf(p.Context)
+	id, err := graphqlID(p.Args, "id")
+	if err != nil {
+		return nil, err
+	}
+	project, err := store.Project.GetByID(id)
+	if err != nil || !store.Project.UserRole(project, scope.user.ID).Valid() {
+		return nil, errGraphQLNotFound
+	}
+	return scope.project(project), nil
+}
+
+// resolveSite 只有在站点所属项目中有角色的用户可以查看站点
+// Only users with a role in the site's project see it
+func resolveSite(p graphql.ResolveParams) (any, error) {
+	scope := graphqlScopeOf(p.Context)
+	id, err := graphqlID(p.Args, "id")
+	if err != nil {
+		return nil, err
+	}
+	site, err := store.Site.GetByID(id)
+	if err != nil || !store.Project.UserRole(&site.Project, scope.user.ID).Valid() {
+		return nil, errGraphQLNotFound
+	}
+	return graphqlSite{SiteDTO: Site.ToDTO(site, true), site: site}, nil
+}
+
+// resolveUserOrganizations 与 REST 一样只能获取自己的组织
+// Only one's own organizations, as in REST
+func resolveUserOrganizations(p graphql.ResolveParams) (any, error) {
+	scope := graphqlScopeOf(p.Context)
+	if p.Source.(UserDTO).ID != scope.user.ID {
+		return nil, errGraphQLForbidden
+	}
+	limit, err := graphqlInt(p.Args, "limit", maxGraphQLLimit)
+	if err != nil {
+		return nil, err
+	}
+	orgs, _, err := store.Org.ListByUserID(p.Context, strconv.Itoa(int(scope.user.ID)), 1, limit)
+	if err != nil {
+		return nil, scope.internal(err, "organizations")
+	}
+	orgDTOs := make([]OrganizationDTO, 0, len(orgs))
+	for i := range orgs {
+		orgDTOs = append(orgDTOs, Org.ToDTO(&orgs[i]))
+	}
+	return orgDTOs, nil
+}
+
+// resolveUserProjects 与 REST 一样只能获取自己的个人项目
+// Only one's own personal projects, as in REST
+func resolveUserProjects(p graphql.ResolveParams) (any, error) {
+	scope := graphqlScopeOf(p.Context)
+	if p.Source.(UserDTO).ID != scope.user.ID {
+		return nil, errGraphQLForbidden
+	}
+	limit, err := graphqlInt(p.Args, "limit", maxGraphQLLimit)
+	if err != nil {
+		return nil, err
+	}
+	grouped, err := store.Project.ListByOwners(p.Context, constants.OwnerTypeUser, []uint{scope.user.ID}, limit)
+	if err != nil {
+		return nil, scope.internal(err, "projects")
+	}
+	projects := grouped[scope.user.ID]
+	values := make([]graphqlProject, 0, len(projects))
+	for i := range projects {
+		values = append(values, scope.project(&projects[i]))
+	}
+	return values, nil
+}
+
+func resolveOrganizationRole(p graphql.ResolveParams) (any, error) {
+	load := graphqlScopeOf(p.Context).orgRoles.Load(p.Source.(OrganizationDTO).ID)
+	return graphql.Thunk(func() (any, error) {
+		role, err := load()
+		if err != nil || !role.Valid() {
+			return nil, err
+		}
+		return string(role), nil
+	}), nil
+}
+
+// resolveOrganizationProjects 组织的项目只对成员可见
+// The projects of an organization are visible to its members only
+func resolveOrganizationProjects(p graphql.ResolveParams) (any, error) {
+	scope := graphqlScopeOf(p.Context)
+	limit, err := graphqlInt(p.Args, "limit", maxGraphQLLimit)
+	if err != nil {
+		return nil, err
+	}
+	id := p.Source.(OrganizationDTO).ID
+	loadRole := scope.orgRoles.Load(id)
+	loadProjects := scope.orgProjects.Load(graphqlListKey{id, limit})
+	return graphql.Thunk(func() (any, error) {
+		if role, err := loadRole(); err != nil {
+			return nil, err
+		} else if !role.Valid() {
+			return nil, errGraphQLNotFound
+		}
+		projects, err := loadProjects()
+		if err != nil {
+			return nil, err
+		}
+		values := make([]graphqlProject, 0, len(projects))
+		for i := range projects {
+			values = append(values, scope.project(&projects[i]))
+		}
+		return values, nil
+	}), nil
+}
+
+func resolveProjectRole(p graphql.ResolveParams) (any, error) {
+	load := graphqlScopeOf(p.Context).projectRoles.Load(p.Source.(graphqlProject).ID)
+	return graphql.Thunk(func() (any, error) {
+		role, err := load()
+		if err != nil || !role.Valid() {
+			return nil, err
+		}
+		return string(role), nil
+	}), nil
+}
+
+func resolveProjectSites(p graphql.ResolveParams) (any, error) {
+	scope := graphqlScopeOf(p.Context)
+	limit, err := graphqlInt(p.Args, "limit", maxGraphQLLimit)
+	if err != nil {
+		return nil, err
+	}
+	project := p.Source.(graphqlProject).project
+	load := scope.projectSites.Load(graphqlListKey{project.ID, limit})
+	return graphql.Thunk(func() (any, error) {
+		sites, err := load()
+		if err != nil {
+			return nil, err
+		}
+		values := make([]graphqlSite, 0, len(sites))
+		for i := range sites {
+			site := sites[i]
+			site.Project = *project
+			values = append(values, graphqlSite{SiteDTO: Site.ToDTO(&site, true), site: &site})
+		}
+		return values, nil
+	}), nil
+}
+
+func resolveSiteProject(p graphql.ResolveParams) (any, error) {
+	return graphqlScopeOf(p.Context).project(&p.Source.(graphqlSite).site.Project), nil
+}
+
+func resolveSiteDeployments(p graphql.ResolveParams) (any, error) {
+	scope := graphqlScopeOf(p.Context)
+	limit, err := graphqlInt(p.Args, "limit", maxGraphQLLimit)
+	if err != nil {
+		return nil, err
+	}
+	id := p.Source.(graphqlSite).ID
+	loadReleases := scope.deployments.Load(graphqlListKey{id, limit})
+	loadActive := scope.activeIDs.Load(id)
+	return graphql.Thunk(func() (any, error) {
+		releases, err := loadReleases()
+		if err != nil {
+			return nil, err
+		}
+		activeID, err := loadActive()
+		if err != nil {
+			return nil, err
+		}
+		releaseDTOs := make([]ReleaseDTO, 0, len(releases))
+		for i := range releases {
+			releaseDTO := Release.ToDTO(&releases[i])
+			releaseDTO.Active = releases[i].ID == activeID
+			releaseDTOs = append(releaseDTOs, releaseDTO)
+		}
+		return releaseDTOs, nil
+	}), nil
+}
+
+// resolveSiteActiveDeployment 先批量读取激活的版本ID，再批量读取版本
+// Read the active version IDs in a batch, then the versions in another
+func resolveSiteActiveDeployment(p graphql.ResolveParams) (any, error) {
+	scope := graphqlScopeOf(p.Context)
+	loadActive := scope.activeIDs.Load(p.Source.(graphqlSite).ID)
+	return graphql.Thunk(func() (any, error) {
+		activeID, err := loadActive()
+		if err != nil || activeID == 0 {
+			return nil, err
+		}
+		loadRelease := scope.releases.Load(activeID)
+		return graphql.Thunk(func() (any, error) {
+			release, err := loadRelease()
+			if err != nil || release.ID == 0 {
+				return nil, err
+			}
+			releaseDTO := Release.ToDTO(&release)
+			releaseDTO.Active = true
+			return releaseDTO, nil
+		}), nil
+	}), nil
+}
+
+func resolveSiteAnalytics(p graphql.ResolveParams) (any, error) {
+	days, err := graphqlInt(p.Args, "days", maxStatsDays)
+	if err != nil {
+		return nil, err
+	}
+	return graphqlAnalytics{Days: days, site: p.Source.(graphqlSite).site}, nil
+}
+
+func resolveAnalyticsDaily(p graphql.ResolveParams) (any, error) {
+	source := p.Source.(graphqlAnalytics)
+	load := graphqlScopeOf(p.Context).daily.Load(graphqlListKey{source.site.ID, source.Days})
+	return graphql.Thunk(func() (any, error) {
+		daily, err := load()
+		if err != nil {
+			return nil, err
+		}
+		counts := make([]DailyCountDTO, 0, len(daily))
+		for _, day := range daily {
+			counts = append(counts, DailyCountDTO{Day: day.Day.UTC().Format(time.DateOnly), Count: day.Views})
+		}
+		return counts, nil
+	}), nil
+}
+
+func resolveAnalyticsViews(p graphql.ResolveParams) (any, error) {
+	source := p.Source.(graphqlAnalytics)
+	load := graphqlScopeOf(p.Context).daily.Load(graphqlListKey{source.site.ID, source.Days})
+	return graphql.Thunk(func() (any, error) {
+		daily, err := load()
+		var views int64
+		for _, day := range daily {
+			views += day.Views
+		}
+		return views, err
+	}), nil
+}
+
+// resolveAnalyticsTop 维度的取值按站点逐个查询，每个站点的数量不同
+// The values of a dimension are queried site by site, since each has its own limit
+func resolveAnalyticsTop(dimension string) graphql.ResolveFunc {
+	return func(p graphql.ResolveParams) (any, error) {
+		scope := graphqlScopeOf(p.Context)
+		limit, err := graphqlInt(p.Args, "limit", maxAnalyticsLimit)
+		if err != nil {
+			return nil, err
+		}
+		source := p.Source.(graphqlAnalytics)
+		scope.flushAnalytics()
+		values, err := store.Analytics.Top(p.Context, source.site.ID, dimension, scope.since(source.Days), limit)
+		if err != nil {
+			return nil, scope.internal(err, "analytics")
+		}
+		valueDTOs := make([]AnalyticsValueDTO, 0, len(values))
+		for _, value := range values {
+			valueDTOs = append(valueDTOs, AnalyticsValueDTO{Value: value.Value, Views: value.Views})
+		}
+		return valueDTOs, nil
+	}
+}
+
+func resolveAnalyticsTransfer(p graphql.ResolveParams) (any, error) {
+	scope := graphqlScopeOf(p.Context)
+	site := p.Source.(graphqlAnalytics).site
+	load := scope.transfer.Load(site.ID)
+	return graphql.Thunk(func() (any, error) {
+		sum, err := load()
+		if err != nil {
+			return nil, err
+		}
+		return Bandwidth.toDTO(site, sum, scope.now), nil
+	}), nil
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/cache"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/storage"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// 探针状态 Probe statuses
+const (
+	healthOK   = "ok"
+	healthFail = "fail"
+)
+
+// healthCheckTimeout 每项依赖检查的超时时间，需短于探针的超时
+// Timeout of each dependency check, shorter than the timeout of probes
+const healthCheckTimeout = 3 * time.Second
+
+type HealthApi struct{}
+
+// Health 存活和就绪探针，供 Kubernetes 和负载均衡判断是否转发流量
+// Liveness and readiness probes, used by Kubernetes and load balancers to decide whether to route traffic
+var Health = HealthApi{}
+
+// Live 存活探针，进程能够处理请求即返回 200，不检查依赖
+// Liveness probe, returning 200 as long as the process handles requests without checking dependencies
+func (HealthApi) Live(ctx context.Context, c *app.RequestContext) {
+	c.Response.Header.Set("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, HealthDTO{Status: healthOK})
+}
+
+// Ready 就绪探针，检查数据库连通、迁移已执行到最新版本、存储和缓存可以访问，任一失败时返回 503
+// Readiness probe checking that the database is reachable and fully migrated and the storage and cache are reachable, returning 503 when any fails
+func (HealthApi) Ready(ctx context.Context, c *app.RequestContext) {
+	checks := map[string]func(ctx context.Context, check *HealthCheckDTO) error{
+		"database": func(ctx context.Context, check *HealthCheckDTO) error {
+			_, version, err := store.Stats.Ping(ctx)
+			if err != nil {
+				return err
+			}
+			check.Version, check.Latest = version, models.LatestVersion()
+			if version < check.Latest {
+				return fmt.Errorf("database is at migration %d, %d is required", version, check.Latest)
+			}
+			return nil
+		},
+		"storage": func(ctx context.Context, _ *HealthCheckDTO) error {
+			return storage.Ping(ctx)
+		},
+		"cache": func(ctx context.Context, _ *HealthCheckDTO) error {
+			return cache.Ping(ctx)
+		},
+	}
+	dto := HealthDTO{Status: healthOK, Checks: make(map[string]HealthCheckDTO, len(checks))}
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for name, run := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+			defer cancel()
+			check := HealthCheckDTO{Status: healthOK}
+			start := time.Now()
+			err := run(ctx, &check)
+			check.LatencyMs = float64(time.Since(start).Microseconds()) / 1000
+			if errors.Is(err, context.DeadlineExceeded) {
+				err = fmt.Errorf("timed out after %s", healthCheckTimeout)
+			}
+			if err != nil {
+				check.Status, check.Error = healthFail, err.Error()
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			dto.Checks[name] = check
+			if err != nil {
+				dto.Status = healthFail
+			}
+		}()
+	}
+	wg.Wait()
+	// 探针不应被缓存 Probes must not be cached
+	c.Response.Header.Set("Cache-Control", "no-store")
+	if dto.Status != healthOK {
+		c.JSON(http.StatusServiceUnavailable, dto)
+		return
+	}
+	c.JSON(http.StatusOK, dto)
+}
@@ -0,0 +1,18 @@
+package handlers
+
+// HealthDTO 探针的检查结果
+// Result of a probe
+type HealthDTO struct {
+	Status string                    `json:"status"`           // ok 或 fail ok or fail
+	Checks map[string]HealthCheckDTO `json:"checks,omitempty"` // 各项依赖的检查结果 Results of the dependency checks
+}
+
+// HealthCheckDTO 一项依赖的检查结果
+// Result of one dependency check
+type HealthCheckDTO struct {
+	Status    string  `json:"status"`            // ok 或 fail ok or fail
+	LatencyMs float64 `json:"latency_ms"`        // 检查耗时（毫秒） Check latency in milliseconds
+	Error     string  `json:"error,omitempty"`   // 失败原因 Failure reason
+	Version   int     `json:"version,omitempty"` // 当前迁移版本 Current migration version
+	Latest    int     `json:"latest,omitempty"`  // 最新迁移版本 Latest migration version
+}
@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/serve"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+type ImportApi struct{}
+
+var Import = ImportApi{}
+
+// importReleaseTag 导入的压缩包发布的版本标签 Version tag of an imported archive
+const importReleaseTag = "imported"
+
+// importDefaultBranch 导入仓库时默认部署的分支，GitHub Pages 的常用发布分支
+// Branch deployed by default when importing a repository, the usual GitHub Pages publishing branch
+const importDefaultBranch = "gh-pages"
+
+// Create 从 GitHub Pages、Netlify 导出的压缩包或仓库创建项目和站点并完成首次部署，根目录的 CNAME 文件绑定为待验证的自定义域名
+// Create a project and a site from an archive exported by GitHub Pages or Netlify or from a repository and deploy it for the first time, the CNAME file at the root is bound as an unverified custom domain
+func (ImportApi) Create(ctx context.Context, c *app.RequestContext) {
+	req := ImportProjectReq{}
+	if !bind(c, &req) {
+		return
+	}
+	if (req.File == nil) == (req.RepoURL == "") {
+		resps.BadRequest(c, "exactly one of file and repo_url is required")
+		return
+	}
+	if req.SiteName == "" {
+		req.SiteName = req.Name
+	}
+	if req.File != nil {
+		Import.fromArchive(ctx, c, &req)
+	} else {
+		Import.fromRepo(ctx, c, &req)
+	}
+}
+
+// fromArchive 转换上传的压缩包并同步发布，发布失败时删除已创建的项目
+// Convert the uploaded archive and publish it synchronously, deleting the created project when publishing fails
+func (ImportApi) fromArchive(ctx context.Context, c *app.RequestContext, req *ImportProjectReq) {
+	archivePath, err := Import.normalize(req.File, Quota.UploadLimit(ctx, c))
+	if errors.Is(err, utils.ErrImportTooLarge) {
+		resps.Custom(c, http.StatusRequestEntityTooLarge, err.Error())
+		return
+	} else if errors.Is(err, utils.ErrImportFormat) {
+		resps.BadRequest(c, err.Error())
+		return
+	} else if err != nil {
+		utils.Log.Ctx(ctx).Errorf("failed to convert imported archive: %v", err)
+		resps.InternalServerError(c, errArchiveStore.Error())
+		return
+	}
+	defer os.Remove(archivePath)
+
+	project, site := Import.createSite(ctx, c, req, nil)
+	if site == nil {
+		return
+	}
+	source, err := fileArchive(archivePath)
+	var release *models.SiteRelease
+	var previousID uint
+	if err == nil {
+		release, previousID, err = Release.publish(ctx, site, source, importReleaseTag, true)
+	}
+	if err != nil {
+		Import.rollback(ctx, project)
+		switch {
+		case unsafeArchive(c, err):
+		case errors.Is(err, errInvalidArchive), errors.Is(err, errBlockedContent):
+			resps.BadRequest(c, err.Error())
+		case errors.Is(err, errQuotaExceeded):
+			resps.Custom(c, http.StatusRequestEntityTooLarge, err.Error())
+		default:
+			resps.InternalServerError(c, err.Error())
+		}
+		return
+	}
+	data, err := Release.finishDeploy(ctx, c, nil, site, release, previousID, "", 0)
+	if err != nil {
+		resps.InternalServerError(c, err.Error())
+		return
+	}
+	// 域名已被绑定等情况不影响导入，原因随响应返回 A domain that cannot be bound does not fail the import, the reason is returned
+	if domain, err := Import.bindCNAME(site, archivePath); err != nil {
+		data["domain_error"] = err.Error()
+	} else if domain != nil {
+		data["domain"] = Domain.toDTO(domain)
+	}
+	Import.audit(ctx, c, project, site, "archive")
+	data["project"] = Project.toDTO(project, true)
+	data["site"] = Site.ToDTO(site, true)
+	resps.Ok(c, resps.OK, data)
+}
+
+// fromRepo 绑定仓库并在后台完成首次部署，与推送部署一样构建和发布，发布成功后绑定 CNAME 中的域名
+// Bind the repository and run the first deployment in the background, built and published like push deployments; the CNAME domain is bound once it is published
+func (ImportApi) fromRepo(ctx context.Context, c *app.RequestContext, req *ImportProjectReq) {
+	gitReq := GitIntegrationReq{
+		Provider:     req.Provider,
+		RepoURL:      req.RepoURL,
+		Branch:       req.Branch,
+		BuildCommand: req.BuildCommand,
+		BuildPreset:  req.BuildPreset,
+		BuildImage:   req.BuildImage,
+		OutputDir:    req.OutputDir,
+	}
+	if gitReq.Provider == "" {
+		gitReq.Provider = Import.provider(req.RepoURL)
+	}
+	if gitReq.Branch == "" {
+		gitReq.Branch = importDefaultBranch
+	}
+	if req.Token != "" {
+		gitReq.Token = &req.Token
+	}
+	integration := &models.GitIntegration{}
+	if !Git.apply(c, integration, &gitReq) {
+		return
+	}
+	secret, err := utils.Webhook.NewSecret()
+	if err != nil {
+		resps.InternalServerError(c, "create push secret error")
+		return
+	}
+	integration.Secret = secret
+
+	// 仓库绑定与项目和站点一同创建 The repository binding is created together with the project and site
+	project, site := Import.createSite(ctx, c, req, func(tx *store.Handle, site *models.Site) error {
+		integration.SiteID = site.ID
+		if err := tx.Git.Create(integration); err != nil {
+			utils.Log.Ctx(ctx).Errorf("failed to save git integration of imported site: %v", err)
+			return errors.New("save git integration error")
+		}
+		return nil
+	})
+	if site == nil {
+		return
+	}
+	integration.Site = *site
+	Git.deploy(ctx, integration, "", true)
+	Import.audit(ctx, c, project, site, integration.Provider)
+	gitDTO := Git.toDTO(c, integration)
+	gitDTO.Secret = integration.Secret
+	resps.Custom(c, http.StatusAccepted, "deployment queued", map[string]any{
+		"project": Project.toDTO(project, true),
+		"site":    Site.ToDTO(site, true),
+		"git":     gitDTO,
+	})
+}
+
+// createSite 在一个事务中创建项目及其站点并执行 then，任一步失败时全部回滚，失败时已写入响应并返回空站点
+// Create the project and its site and run then within one transaction, rolling all of it back when a step fails; the response is already written and the site is nil on failure
+func (ImportApi) createSite(ctx context.Context, c *app.RequestContext, req *ImportProjectReq, then func(tx *store.Handle, site *models.Site) error) (*models.Project, *models.Site) {
+	if req.SubDomain != "" {
+		if _, err := store.Site.GetBySubDomain(req.SubDomain); err == nil {
+			resps.BadRequest(c, "sub_domain is already used")
+			return nil, nil
+		}
+	}
+	project := Project.prepare(ctx, c, CreateProjectReq{
+		Name:        req.Name,
+		DisplayName: req.DisplayName,
+		Description: req.Description,
+		OwnerType:   req.OwnerType,
+		OwnerID:     req.OwnerID,
+		Visibility:  req.Visibility,
+	})
+	if project == nil {
+		return nil, nil
+	}
+	site := &models.Site{
+		Name:        req.SiteName,
+		Description: req.Description,
+		SubDomain:   req.SubDomain,
+	}
+	err := store.Default.WithTx(ctx, func(tx *store.Handle) error {
+		if err := tx.Project.Create(project); err != nil {
+			return err
+		}
+		site.ProjectID = project.ID
+		if err := tx.Site.Create(site); err != nil {
+			return err
+		}
+		if then != nil {
+			return then(tx, site)
+		}
+		return nil
+	})
+	if err != nil {
+		resps.InternalServerError(c, err.Error())
+		return nil, nil
+	}
+	site.Project = *project
+	serve.SiteCache.ForgetSite(ctx, site)
+	return project, site
+}
+
+// rollback 导入失败时彻底删除已创建的项目
+// Purge the created project when the import fails
+func (ImportApi) rollback(ctx context.Context, project *models.Project) {
+	if err := store.Trash.PurgeProject(ctx, project); err != nil {
+		utils.Log.Ctx(ctx).Errorf("failed to remove project %d after a failed import: %v", project.ID, err)
+	}
+}
+
+func (ImportApi) audit(ctx context.Context, c *app.RequestContext, project *models.Project, site *models.Site, source string) {
+	Audit.record(ctx, c, nil, constants.AuditProjectImport, constants.AuditTargetProject, project.ID, map[string]any{
+		"name":    project.Name,
+		"site_id": site.ID,
+		"source":  source,
+	})
+}
+
+// normalize 将上传的压缩包转换为站点 zip 写入临时文件，返回文件路径；limit 为解压后的字节上限，0 表示不限制
+// Convert the uploaded archive into a site zip in a temporary file, returning its path; limit bounds the unpacked bytes, 0 is unlimited
+func (ImportApi) normalize(fileHeader *multipart.FileHeader, limit int64) (string, error) {
+	src, err := fileHeader.Open()
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+	tmp, err := os.CreateTemp("", "spage-import-*.zip")
+	if err != nil {
+		return "", err
+	}
+	err = utils.Import.Normalize(src, fileHeader.Size, tmp, limit)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", err
+	}
+	return tmp.Name(), nil
+}
+
+// bindCNAME 将站点 zip 根目录 CNAME 文件中的域名绑定到站点，没有 CNAME 时返回空
+// Bind the domain in the CNAME file at the root of the site zip to the site, nil without a CNAME
+func (ImportApi) bindCNAME(site *models.Site, archivePath string) (*models.CustomDomain, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	name := utils.Import.CNAME(file, info.Size())
+	if name == "" {
+		return nil, nil
+	}
+	return Domain.bind(site, name, "")
+}
+
+// provider 按仓库地址的主机名判断代码托管平台，无法判断时视为 GitHub
+// Infer the git hosting provider from the host of the repository URL, GitHub when it cannot be told
+func (ImportApi) provider(repoURL string) string {
+	if u, err := url.Parse(repoURL); err == nil && strings.Contains(strings.ToLower(u.Hostname()), "gitlab") {
+		return constants.GitProviderGitLab
+	}
+	return constants.GitProviderGitHub
+}
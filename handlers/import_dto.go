@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"mime/multipart"
+
+	"github.com/LiteyukiStudio/spage/constants"
+)
+
+// ImportProjectReq 导入项目的请求参数，file 和 repo_url 二选一
+// Request parameters to import a project, exactly one of file and repo_url is given
+type ImportProjectReq struct {
+	Name        string                `json:"name" form:"name" validate:"required,max=63,slug,reserved"`                        // 项目名称，为空时由显示名称生成 Project Name, derived from the display name when empty
+	DisplayName *string               `json:"display_name" form:"display_name" validate:"max=128"`                              // 项目显示名称 Project Display Name
+	Description string                `json:"description" form:"description" validate:"max=1024"`                               // 项目描述 Project Description
+	OwnerType   string                `json:"owner_type" form:"owner_type" binding:"required" vd:"in($,'user','organization')"` // 项目拥有者类型 Project Owner Type
+	OwnerID     uint                  `json:"owner_id" form:"owner_id"`                                                         // 项目拥有者ID，拥有者为组织时必填 Project Owner ID, required for organizations
+	Visibility  *constants.Visibility `json:"visibility" form:"visibility"`                                                     // 项目可见性 Project Visibility
+	SiteName    string                `json:"site_name" form:"site_name"`                                                       // 站点名称，默认与项目名称相同 Site name, the project name by default
+	SubDomain   string                `json:"sub_domain" form:"sub_domain" validate:"max=63,slug,reserved"`                     // 站点子域名 Site subdomain
+
+	File *multipart.FileHeader `json:"file" form:"file"` // GitHub Pages 构建产物或 Netlify 导出的 zip、tar、tar.gz GitHub Pages artifact or Netlify export as zip, tar or tar.gz
+
+	RepoURL      string `json:"repo_url" form:"repo_url" validate:"url"` // 仓库 https 地址，导入后绑定该仓库 Repository https URL, bound to the site after importing
+	Provider     string `json:"provider" form:"provider"`                // 代码托管平台，默认按仓库地址判断 Git hosting provider, inferred from the repository URL by default
+	Branch       string `json:"branch" form:"branch"`                    // 部署的分支，默认 gh-pages Deployed branch, gh-pages by default
+	Token        string `json:"token" form:"token"`                      // 拉取私有仓库的访问令牌 Access token for private repositories
+	BuildCommand string `json:"build_command" form:"build_command"`      // 构建命令，需要实例开启 git.build-enable Build command, requires git.build-enable
+	BuildPreset  string `json:"build_preset" form:"build_preset"`        // 构建预设 hugo、mkdocs 或 vite Build preset hugo, mkdocs or vite
+	BuildImage   string `json:"build_image" form:"build_image"`          // 构建镜像 Build image
+	OutputDir    string `json:"output_dir" form:"output_dir"`            // 发布的输出目录 Published output directory
+}
+
+func (req *ImportProjectReq) normalize() {
+	req.Name = normalizeName(req.Name, req.DisplayName)
+}
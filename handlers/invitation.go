@@ -0,0 +1,316 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/mailer"
+	"github.com/LiteyukiStudio/spage/middle"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+type InvitationApi struct{}
+
+var Invitation = InvitationApi{}
+
+// 邀请的限制 Limits of invitations
+const (
+	maxInvitationUses = 1000
+	maxInvitationTTL  = 3600 * 24 * 90
+)
+
+func (InvitationApi) toDTO(invitation *models.Invitation) InvitationDTO {
+	dto := InvitationDTO{
+		ID:        invitation.ID,
+		Role:      invitation.Role,
+		Email:     invitation.Email,
+		Prefix:    invitation.Prefix,
+		MaxUses:   invitation.MaxUses,
+		Uses:      invitation.Uses,
+		ExpiresAt: invitation.ExpiresAt,
+		CreatedBy: invitation.CreatedBy.Name,
+		CreatedAt: invitation.CreatedAt,
+	}
+	if invitation.Organization != nil {
+		org := Org.ToDTO(invitation.Organization)
+		dto.Organization = &org
+	} else {
+		dto.Role = ""
+	}
+	return dto
+}
+
+// create 校验请求并创建邀请，设置了邮箱时发送邀请邮件
+// Validate the request and create an invitation, sending an invite email when an address is set
+func (InvitationApi) create(ctx context.Context, c *app.RequestContext, req *CreateInvitationReq, org *models.Organization) {
+	user := middle.Auth.GetUser(ctx, c)
+	if req.MaxUses == 0 {
+		req.MaxUses = 1
+	}
+	if req.MaxUses < 0 || req.MaxUses > maxInvitationUses {
+		resps.BadRequestf(c, "max_uses must be between 1 and %d", maxInvitationUses)
+		return
+	}
+	if req.ExpiresIn == 0 {
+		req.ExpiresIn = config.InvitationTTL
+	}
+	if req.ExpiresIn < 0 || req.ExpiresIn > maxInvitationTTL {
+		resps.BadRequestf(c, "expires_in must be between 1 and %d seconds", maxInvitationTTL)
+		return
+	}
+	req.Email = strings.TrimSpace(req.Email)
+	if req.Email != "" {
+		if _, err := mail.ParseAddress(req.Email); err != nil {
+			resps.BadRequest(c, "invalid email address")
+			return
+		}
+	}
+	invitation := &models.Invitation{
+		Email:       req.Email,
+		MaxUses:     req.MaxUses,
+		ExpiresAt:   time.Now().Add(time.Duration(req.ExpiresIn) * time.Second),
+		Role:        constants.OrgRoleMember,
+		CreatedByID: user.ID,
+		CreatedBy:   *user,
+	}
+	if org == nil && req.Role != "" {
+		resps.BadRequest(c, "role requires an organization")
+		return
+	}
+	if req.Role != "" {
+		if !req.Role.Valid() {
+			resps.BadRequest(c, "invalid role")
+			return
+		}
+		invitation.Role = req.Role
+	}
+	if org != nil {
+		invitation.OrganizationID, invitation.Organization = &org.ID, org
+	}
+	plain, hash, err := utils.Token.NewOneTimeToken()
+	if err != nil {
+		resps.InternalServerError(c, "Failed to create invitation")
+		return
+	}
+	invitation.Prefix, invitation.Hash = plain[:tokenPrefixLength], hash
+	if err := store.Invitation.Create(invitation); err != nil {
+		resps.DBError(c, err, "Failed to create invitation")
+		return
+	}
+	var orgID uint
+	if org != nil {
+		orgID = org.ID
+	}
+	Audit.record(ctx, c, user, constants.AuditInvitationCreate, constants.AuditTargetInvitation, invitation.ID, map[string]any{
+		"organization_id": orgID,
+		"role":            invitation.Role,
+		"email":           invitation.Email,
+		"max_uses":        invitation.MaxUses,
+		"expires_at":      invitation.ExpiresAt,
+	})
+	link := strings.TrimSuffix(config.FrontEndURL, "/") + "/register?invite=" + url.QueryEscape(plain)
+	if invitation.Email != "" {
+		var orgName string
+		if org != nil {
+			orgName = org.Name
+			if org.DisplayName != nil && *org.DisplayName != "" {
+				orgName = *org.DisplayName
+			}
+		}
+		mailer.SendAsync(invitation.Email, mailer.TemplateInvitation, user.Language, map[string]any{
+			"Inviter":      displayName(user),
+			"Organization": orgName,
+			"Link":         link,
+			"Days":         (req.ExpiresIn + 3600*24 - 1) / (3600 * 24),
+		})
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"invitation": Invitation.toDTO(invitation),
+		"plaintext":  plain,
+		"link":       link,
+	})
+}
+
+// list 返回邀请列表，org 为空时返回实例的所有邀请
+// Respond with invitations, all invitations of the instance when org is nil
+func (InvitationApi) list(c *app.RequestContext, org *models.Organization) {
+	var orgID *uint
+	if org != nil {
+		orgID = &org.ID
+	}
+	invitations, err := store.Invitation.List(orgID)
+	if err != nil {
+		resps.DBError(c, err, "Failed to get invitations")
+		return
+	}
+	dtos := make([]InvitationDTO, 0, len(invitations))
+	for _, invitation := range invitations {
+		dtos = append(dtos, Invitation.toDTO(&invitation))
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"invitations": dtos,
+	})
+}
+
+// revoke 撤销邀请，org 不为空时邀请必须属于该组织
+// Revoke an invitation, which must belong to org when it is set
+func (InvitationApi) revoke(ctx context.Context, c *app.RequestContext, org *models.Organization) {
+	id, err := strconv.Atoi(c.Param("invitation_id"))
+	if err != nil {
+		resps.BadRequest(c, resps.ParameterError)
+		return
+	}
+	invitation, err := store.Invitation.GetByID(uint(id))
+	if err != nil || (org != nil && (invitation.OrganizationID == nil || *invitation.OrganizationID != org.ID)) {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	if err := store.Invitation.Revoke(invitation); err != nil {
+		resps.DBError(c, err, "Failed to revoke invitation")
+		return
+	}
+	Audit.record(ctx, c, nil, constants.AuditInvitationRevoke, constants.AuditTargetInvitation, invitation.ID, map[string]any{"prefix": invitation.Prefix})
+	resps.Ok(c, resps.OK, map[string]any{})
+}
+
+// OrgList 获取组织的邀请
+// List the invitations of an organization
+func (InvitationApi) OrgList(ctx context.Context, c *app.RequestContext) {
+	Invitation.list(c, getOrg(c))
+}
+
+// OrgCreate 创建加入组织的邀请
+// Create an invitation to join the organization
+func (InvitationApi) OrgCreate(ctx context.Context, c *app.RequestContext) {
+	req := &CreateInvitationReq{}
+	if !bindJSON(c, req) {
+		return
+	}
+	Invitation.create(ctx, c, req, getOrg(c))
+}
+
+// OrgRevoke 撤销组织的邀请
+// Revoke an invitation of the organization
+func (InvitationApi) OrgRevoke(ctx context.Context, c *app.RequestContext) {
+	Invitation.revoke(ctx, c, getOrg(c))
+}
+
+// AdminList 获取实例的所有邀请
+// List all invitations of the instance
+func (InvitationApi) AdminList(ctx context.Context, c *app.RequestContext) {
+	Invitation.list(c, nil)
+}
+
+// AdminCreate 创建注册邀请，可指定加入的组织
+// Create an invitation to sign up, optionally joining an organization
+func (InvitationApi) AdminCreate(ctx context.Context, c *app.RequestContext) {
+	req := &CreateInvitationReq{}
+	if !bindJSON(c, req) {
+		return
+	}
+	var org *models.Organization
+	if req.OrganizationID != nil {
+		found, err := store.Org.GetOrgById(*req.OrganizationID)
+		if err != nil {
+			resps.NotFound(c, resps.TargetNotFound)
+			return
+		}
+		org = found
+	}
+	Invitation.create(ctx, c, req, org)
+}
+
+// AdminRevoke 撤销任意邀请
+// Revoke any invitation
+func (InvitationApi) AdminRevoke(ctx context.Context, c *app.RequestContext) {
+	Invitation.revoke(ctx, c, nil)
+}
+
+// Get 根据链接中的令牌查看邀请，供注册页面展示
+// Look up an invitation by the token of its link, shown by the sign-up page
+func (InvitationApi) Get(ctx context.Context, c *app.RequestContext) {
+	invitation, err := store.Invitation.GetByToken(c.Param("token"))
+	if err != nil {
+		resps.NotFound(c, "Invalid or expired invitation")
+		return
+	}
+	data := map[string]any{
+		"email":      invitation.Email,
+		"inviter":    displayName(&invitation.CreatedBy),
+		"expires_at": invitation.ExpiresAt,
+	}
+	if invitation.Organization != nil {
+		data["organization"] = Org.ToDTO(invitation.Organization)
+		data["role"] = invitation.Role
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"invitation": data,
+	})
+}
+
+// forUser 获取令牌对应的邀请并检查邮箱限制
+// Get the invitation of a token and check its email restriction
+func (InvitationApi) forUser(token string, email *string) (*models.Invitation, error) {
+	invitation, err := store.Invitation.GetByToken(token)
+	if err != nil {
+		return nil, errors.New("Invalid or expired invitation")
+	}
+	if invitation.Email != "" && (email == nil || !strings.EqualFold(strings.TrimSpace(*email), invitation.Email)) {
+		return nil, errors.New("This invitation is for another email address")
+	}
+	return invitation, nil
+}
+
+// Accept 已登录用户使用邀请加入组织
+// A signed-in user uses an invitation to join its organization
+func (InvitationApi) Accept(ctx context.Context, c *app.RequestContext) {
+	req := &InvitationTokenReq{}
+	if !bindJSON(c, req) {
+		return
+	}
+	user := middle.Auth.GetUser(ctx, c)
+	invitation, err := Invitation.forUser(req.Token, user.Email)
+	if err != nil {
+		resps.BadRequest(c, err.Error())
+		return
+	}
+	if invitation.Organization == nil {
+		resps.BadRequest(c, "This invitation is only for signing up")
+		return
+	}
+	if !sameTenant(user.TenantID, invitation.Organization.TenantID) {
+		resps.BadRequest(c, "user belongs to another tenant")
+		return
+	}
+	// 不降低已有的角色 An existing higher role is kept
+	if store.Org.GetUserAuth(invitation.Organization, user.ID).AtLeast(invitation.Role) {
+		resps.BadRequest(c, "You are already a member of this organization")
+		return
+	}
+	if err := store.Invitation.Accept(invitation, user.ID); err != nil {
+		if errors.Is(err, store.ErrInvitationInvalid) {
+			resps.BadRequest(c, "Invalid or expired invitation")
+			return
+		}
+		resps.InternalServerError(c, "Failed to accept invitation")
+		return
+	}
+	Audit.record(ctx, c, user, constants.AuditInvitationAccept, constants.AuditTargetInvitation, invitation.ID, map[string]any{
+		"organization_id": *invitation.OrganizationID,
+		"role":            invitation.Role,
+	})
+	resps.Ok(c, resps.OK, map[string]any{
+		"organization": Org.ToDTO(invitation.Organization),
+	})
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/LiteyukiStudio/spage/constants"
+)
+
+// InvitationDTO 邀请信息，不包含令牌明文
+// Invitation information, without the plain token
+type InvitationDTO struct {
+	ID           uint              `json:"id"`                     // 邀请ID Invitation ID
+	Organization *OrganizationDTO  `json:"organization,omitempty"` // 加入的组织 Organization to join
+	Role         constants.OrgRole `json:"role,omitempty"`         // 加入组织后的角色 Role after joining
+	Email        string            `json:"email"`                  // 限定的邮箱 Restricted email
+	Prefix       string            `json:"prefix"`                 // 令牌开头部分 Leading part of the token
+	MaxUses      int               `json:"max_uses"`               // 可使用次数 Allowed uses
+	Uses         int               `json:"uses"`                   // 已使用次数 Uses so far
+	ExpiresAt    time.Time         `json:"expires_at"`             // 过期时间 Expiry time
+	CreatedBy    string            `json:"created_by"`             // 创建者用户名 Creator username
+	CreatedAt    time.Time         `json:"created_at"`             // 创建时间 Created time
+}
+
+// CreateInvitationReq 创建邀请的请求参数
+// Request parameters to create an invitation
+type CreateInvitationReq struct {
+	OrganizationID *uint             `json:"organization_id"` // 加入的组织，仅管理员接口使用 Organization to join, only used by the admin endpoint
+	Role           constants.OrgRole `json:"role"`            // 组织角色，默认 member Organization role, member by default
+	Email          string            `json:"email"`           // 限定的邮箱，设置且启用邮件时发送邀请邮件 Restricted email, an invite email is sent when set and email is enabled
+	MaxUses        int               `json:"max_uses"`        // 可使用次数，默认为 1 Allowed uses, 1 by default
+	ExpiresIn      int               `json:"expires_in"`      // 有效期，单位秒，默认为 registration.invite-ttl Lifetime in seconds, registration.invite-ttl by default
+}
+
+// InvitationTokenReq 使用邀请的请求参数
+// Request parameters to use an invitation
+type InvitationTokenReq struct {
+	Token string `json:"token" binding:"required"` // 邀请令牌 Invitation token
+}
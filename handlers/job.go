@@ -0,0 +1,324 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/middle"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/task"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/http1/resp"
+)
+
+type JobApi struct{}
+
+// jobEventInterval 任务事件流读取任务记录的间隔
+// Interval at which the job event stream reads the job record
+const jobEventInterval = 500 * time.Millisecond
+
+// Job 后台任务，管理员可查看、重试和取消全部任务，站点成员可查看站点的任务
+// Background jobs, administrators may list, retry and cancel all jobs and site members may view the jobs of their site
+var Job = JobApi{}
+
+// RegisterJobs 注册由 handlers 执行的任务类型，应在任务队列启动之前调用
+// Register the job types run by handlers, meant to be called before the job queue starts
+func RegisterJobs() {
+	task.RegisterJob(constants.JobTypeDeploy, task.JobHandler{
+		Run:     Release.runDeployJob,
+		Discard: Release.discardDeployJob,
+	})
+	task.RegisterJob(constants.JobTypeSchedule, task.JobHandler{
+		Run: Release.runScheduleJob,
+	})
+	task.RegisterJob(constants.JobTypeCDNPurge, task.JobHandler{
+		Run: CDN.runPurgeJob,
+	})
+	task.RegisterJob(constants.JobTypeLinkCheck, task.JobHandler{
+		Run: LinkCheck.runJob,
+	})
+	task.RegisterJob(constants.JobTypeAdminBulk, task.JobHandler{
+		Run: Admin.runBulkJob,
+	})
+}
+
+func (JobApi) toDTO(job *models.Job, withPayload bool) JobDTO {
+	dto := JobDTO{
+		ID:          job.ID,
+		Type:        job.Type,
+		Status:      job.Status,
+		Attempts:    job.Attempts,
+		MaxAttempts: job.MaxAttempts,
+		StartedAt:   job.StartedAt,
+		FinishedAt:  job.FinishedAt,
+		Error:       job.Error,
+		SiteID:      job.SiteID,
+		UserID:      job.UserID,
+		CreatedAt:   job.CreatedAt,
+	}
+	if job.Status == constants.JobRunning {
+		dto.Stage = job.Stage
+		dto.Progress = job.Progress
+		dto.ProgressTotal = job.ProgressTotal
+	}
+	if job.Status == constants.JobPending {
+		dto.RunAt = &job.RunAt
+	}
+	if withPayload && json.Valid([]byte(job.Payload)) {
+		dto.Payload = json.RawMessage(job.Payload)
+	}
+	if json.Valid([]byte(job.Result)) {
+		dto.Result = json.RawMessage(job.Result)
+	}
+	return dto
+}
+
+func (JobApi) listToDTO(jobs []models.Job, withPayload bool) []JobDTO {
+	dtos := make([]JobDTO, 0, len(jobs))
+	for _, job := range jobs {
+		dtos = append(dtos, Job.toDTO(&job, withPayload))
+	}
+	return dtos
+}
+
+// List 管理员分页获取后台任务，可按状态和类型过滤
+// Admin lists background jobs with pagination, optionally filtered by status and type
+func (JobApi) List(ctx context.Context, c *app.RequestContext) {
+	status := c.Query("status")
+	if status != "" && !slices.Contains(constants.JobStatuses, status) {
+		resps.BadRequest(c, "unknown job status "+status)
+		return
+	}
+	page, limit := utils.Ctx.GetPageLimit(c)
+	jobs, total, err := store.Job.List(ctx, status, c.Query("type"), 0, page, limit)
+	if err != nil {
+		resps.DBError(c, err, "get jobs error")
+		return
+	}
+	utils.Ctx.SetPageHeaders(c, page, limit, total)
+	resps.Ok(c, resps.OK, map[string]any{
+		"jobs":  Job.listToDTO(jobs, false),
+		"total": total,
+	})
+}
+
+// getJob 获取路径中的任务，失败时已写入响应
+// Get the job of the path; the response is already written on failure
+func (JobApi) getJob(c *app.RequestContext) *models.Job {
+	id, err := strconv.Atoi(c.Param("job_id"))
+	if err != nil {
+		resps.BadRequest(c, resps.ParameterError)
+		return nil
+	}
+	job, err := store.Job.GetByID(uint(id))
+	if err != nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return nil
+	}
+	return job
+}
+
+// Get 管理员获取后台任务及其参数
+// Admin gets a background job with its parameters
+func (JobApi) Get(ctx context.Context, c *app.RequestContext) {
+	job := Job.getJob(c)
+	if job == nil {
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"job": Job.toDTO(job, true),
+	})
+}
+
+// Retry 管理员重新执行失败或已取消的任务，尝试次数从零开始
+// Admin runs a failed or canceled job again, starting over with zero attempts
+func (JobApi) Retry(ctx context.Context, c *app.RequestContext) {
+	job := Job.getJob(c)
+	if job == nil {
+		return
+	}
+	retried, err := store.Job.Retry(job, time.Now())
+	if err != nil {
+		resps.DBError(c, err, "retry job error")
+		return
+	}
+	if !retried {
+		resps.BadRequest(c, "only failed or canceled jobs can be retried")
+		return
+	}
+	task.WakeJobs()
+	if job, err = store.Job.GetByID(job.ID); err != nil {
+		resps.InternalServerError(c, "get job error")
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"job": Job.toDTO(job, true),
+	})
+}
+
+// Cancel 管理员取消尚未开始执行的任务，运行中的任务不能取消
+// Admin cancels a job that has not started running, running jobs cannot be canceled
+func (JobApi) Cancel(ctx context.Context, c *app.RequestContext) {
+	job := Job.getJob(c)
+	if job == nil {
+		return
+	}
+	canceled, err := store.Job.Cancel(job, time.Now())
+	if err != nil {
+		resps.DBError(c, err, "cancel job error")
+		return
+	}
+	if !canceled {
+		resps.BadRequest(c, "only pending jobs can be canceled")
+		return
+	}
+	task.DiscardJob(ctx, job)
+	if job, err = store.Job.GetByID(job.ID); err != nil {
+		resps.InternalServerError(c, "get job error")
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"job": Job.toDTO(job, true),
+	})
+}
+
+// CollectGarbage 管理员立即运行存储垃圾回收，返回任务，回收的字节数见任务结果
+// Admin runs a storage garbage collection right away, responding with the job whose result reports the reclaimed bytes
+func (JobApi) CollectGarbage(ctx context.Context, c *app.RequestContext) {
+	req := GCReq{}
+	if len(c.Request.Body()) > 0 {
+		if !bindJSON(c, &req) {
+			return
+		}
+	}
+	job, err := task.EnqueueJob(ctx, constants.JobTypeStorageGC, task.GCJob{DryRun: req.DryRun}, 0, middle.Auth.GetUser(ctx, c).ID)
+	if err != nil {
+		resps.InternalServerError(c, "create job error")
+		return
+	}
+	resps.Custom(c, 202, resps.OK, map[string]any{
+		"job": Job.toDTO(job, true),
+	})
+}
+
+// SiteList 分页获取站点的后台任务
+// List the background jobs of the site with pagination
+func (JobApi) SiteList(ctx context.Context, c *app.RequestContext) {
+	site := getSite(c)
+	if site == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	page, limit := utils.Ctx.GetPageLimit(c)
+	jobs, total, err := store.Job.List(ctx, "", "", site.ID, page, limit)
+	if err != nil {
+		resps.DBError(c, err, "get jobs error")
+		return
+	}
+	utils.Ctx.SetPageHeaders(c, page, limit, total)
+	resps.Ok(c, resps.OK, map[string]any{
+		"jobs":  Job.listToDTO(jobs, false),
+		"total": total,
+	})
+}
+
+// SiteGet 获取站点的后台任务，用于轮询异步部署的结果
+// Get a background job of the site, used to poll the outcome of an async deployment
+func (JobApi) SiteGet(ctx context.Context, c *app.RequestContext) {
+	job := Job.getSiteJob(c)
+	if job == nil {
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"job": Job.toDTO(job, false),
+	})
+}
+
+// SiteEvents 以 SSE 推送站点后台任务的状态和进度，任务结束时发送 done 事件并关闭连接，用于实时显示异步部署的进度
+// Stream the status and progress of a site's background job via SSE, sending a done event and closing once the job finished; used to show the progress of async deployments live
+func (JobApi) SiteEvents(ctx context.Context, c *app.RequestContext) {
+	job := Job.getSiteJob(c)
+	if job == nil {
+		return
+	}
+	c.SetStatusCode(200)
+	c.Response.Header.SetContentType("text/event-stream")
+	c.Response.Header.Set("Cache-Control", "no-cache")
+	c.Response.Header.Set("X-Accel-Buffering", "no")
+	c.Response.HijackWriter(resp.NewChunkedBodyWriter(&c.Response, c.GetWriter()))
+	_, _ = c.WriteString(": connected\n\n")
+	if err := c.Flush(); err != nil {
+		return
+	}
+
+	poll := time.NewTicker(jobEventInterval)
+	defer poll.Stop()
+	heartbeat := time.NewTicker(tailHeartbeatInterval)
+	defer heartbeat.Stop()
+	var last []byte
+	for {
+		dto := Job.toDTO(job, false)
+		if slices.Contains([]string{constants.JobSucceeded, constants.JobFailed, constants.JobCanceled}, job.Status) {
+			data, _ := json.Marshal(dto)
+			_, _ = c.WriteString("event: done\ndata: " + string(data) + "\n\n")
+			_ = c.Flush()
+			return
+		}
+		// 状态或进度变化时才推送 Only pushed when the status or progress changed
+		data, _ := json.Marshal(JobProgressDTO{Status: dto.Status, Attempts: dto.Attempts, Stage: dto.Stage, Progress: dto.Progress, Total: dto.ProgressTotal, RunAt: dto.RunAt})
+		if !bytes.Equal(data, last) {
+			last = data
+			_, _ = c.WriteString("event: progress\ndata: " + string(data) + "\n\n")
+			if err := c.Flush(); err != nil {
+				return
+			}
+		}
+		select {
+		case <-poll.C:
+		case <-heartbeat.C:
+			// 心跳写入失败说明客户端已断开 A failed heartbeat means the client is gone
+			_, _ = c.WriteString(": ping\n\n")
+			if err := c.Flush(); err != nil {
+				return
+			}
+			continue
+		case <-ctx.Done():
+			return
+		}
+		// 任务可能在其他实例上执行，进度从任务记录读取 The job may run on another instance, so progress is read from its record
+		next, err := store.Job.GetBySite(job.SiteID, job.ID)
+		if err != nil {
+			return
+		}
+		job = next
+	}
+}
+
+// getSiteJob 获取路径参数指定的站点任务，失败时已写入响应
+// Get the site job named by the path parameter, the response is already written on failure
+func (JobApi) getSiteJob(c *app.RequestContext) *models.Job {
+	site := getSite(c)
+	if site == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return nil
+	}
+	id, err := strconv.Atoi(c.Param("job_id"))
+	if err != nil {
+		resps.BadRequest(c, resps.ParameterError)
+		return nil
+	}
+	job, err := store.Job.GetBySite(site.ID, uint(id))
+	if err != nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return nil
+	}
+	return job
+}
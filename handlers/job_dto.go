@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// GCReq 手动触发存储垃圾回收的请求参数
+// Request parameters to trigger a storage garbage collection by hand
+type GCReq struct {
+	DryRun bool `json:"dry_run"` // 只统计可回收的对象和字节数，不删除 Only count the collectable objects and bytes without deleting them
+}
+
+// JobDTO 后台任务数据传输对象，参数只对管理员返回
+// Background job Data Transfer Object (DTO), the parameters are only returned to administrators
+type JobDTO struct {
+	ID          uint            `json:"id"`                    // 任务ID Job ID
+	Type        string          `json:"type"`                  // 任务类型 Job type
+	Status      string          `json:"status"`                // 任务状态 Job status
+	Attempts    int             `json:"attempts"`              // 已尝试次数 Attempts made
+	MaxAttempts int             `json:"max_attempts"`          // 最大尝试次数 Max attempts
+	RunAt       *time.Time      `json:"run_at,omitempty"`      // 下次执行时间，等待执行时返回 Next run time, returned while pending
+	StartedAt   *time.Time      `json:"started_at,omitempty"`  // 最近一次开始执行的时间 Start of the latest attempt
+	FinishedAt  *time.Time      `json:"finished_at,omitempty"` // 结束时间 Finished time
+	Payload     json.RawMessage `json:"payload,omitempty"`     // 任务参数 Job parameters
+	Result      json.RawMessage `json:"result,omitempty"`      // 执行结果 Outcome
+	Error       string          `json:"error"`                 // 最近一次的错误 Error of the latest attempt
+	SiteID      uint            `json:"site_id,omitempty"`     // 关联的站点ID Related site ID
+	UserID      uint            `json:"user_id,omitempty"`     // 创建任务的用户ID ID of the user creating the job
+	CreatedAt   time.Time       `json:"created_at"`            // 创建时间 Created time
+
+	Stage         string `json:"stage,omitempty"`          // 执行中的阶段 Stage of the running attempt
+	Progress      int64  `json:"progress,omitempty"`       // 当前阶段已完成的量 Amount of the current stage done
+	ProgressTotal int64  `json:"progress_total,omitempty"` // 当前阶段的总量，未知时为 0 Total amount of the current stage, 0 when unknown
+}
+
+// JobProgressDTO 任务事件流中的进度事件
+// Progress event of the job event stream
+type JobProgressDTO struct {
+	Status   string     `json:"status"`           // 任务状态 Job status
+	Attempts int        `json:"attempts"`         // 已尝试次数 Attempts made
+	Stage    string     `json:"stage,omitempty"`  // 执行中的阶段 Stage of the running attempt
+	Progress int64      `json:"progress"`         // 当前阶段已完成的量 Amount of the current stage done
+	Total    int64      `json:"total"`            // 当前阶段的总量，未知时为 0 Total amount of the current stage, 0 when unknown
+	RunAt    *time.Time `json:"run_at,omitempty"` // 等待执行时的下次执行时间 Next run time while pending
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/middle"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+	"gorm.io/gorm"
+)
+
+type LDAPApi struct{}
+
+var LDAP = LDAPApi{}
+
+// errLDAPNoMatch 没有目录接受该登录名和密码
+// No directory accepted the login name and password
+var errLDAPNoMatch = errors.New("no directory accepted the credentials")
+
+// validate 校验 LDAP 提供方配置
+// Validate the configuration of an LDAP provider
+func (LDAPApi) validate(provider *models.OIDCConfig) error {
+	if provider.DisplayName == "" {
+		return errors.New("display_name is required")
+	}
+	if err := utils.LDAP.ValidateURL(provider.BaseURL); err != nil {
+		return err
+	}
+	if provider.LDAP.UserBaseDN == "" {
+		return errors.New("ldap.user_base_dn is required")
+	}
+	if provider.ClientID != "" && provider.ClientSecret == "" {
+		return errors.New("client_secret is required when client_id is set as the lookup account")
+	}
+	for _, mapping := range provider.LDAP.GroupMappings {
+		if mapping.Group == "" || mapping.Organization == "" {
+			return errors.New("group mappings require group and organization")
+		}
+		if !mapping.Role.Valid() {
+			return errors.New("invalid role in group mapping of " + mapping.Group)
+		}
+	}
+	return nil
+}
+
+// authenticate 依次尝试启用的目录验证登录名和密码，首次登录的目录用户会创建本地账号
+// Try the enabled directories in turn to verify the login name and password, a local account is created on a directory user's first sign-in
+func (LDAPApi) authenticate(ctx context.Context, username, password string) (*models.User, error) {
+	providers, err := store.OIDC.ListProviders(true)
+	if err != nil {
+		return nil, err
+	}
+	for _, provider := range providers {
+		if provider.Type != constants.AuthProviderLDAP {
+			continue
+		}
+		identity, err := utils.LDAP.Authenticate(ctx, &provider, username, password)
+		if errors.Is(err, utils.ErrLDAPInvalidCredentials) {
+			continue
+		}
+		if err != nil {
+			utils.Log.Ctx(ctx).Warnf("failed to authenticate against directory %d: %v", provider.ID, err)
+			continue
+		}
+		if !utils.OIDC.MatchGroups(provider.AllowedGroups, identity.Groups) {
+			return nil, errors.New("Your groups are not allowed to sign in")
+		}
+		linked, err := store.OIDC.GetIdentity(provider.ID, identity.Subject)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Failed to get identity")
+		}
+		var user *models.User
+		if linked != nil {
+			user = &linked.User
+		} else if user, err = OIDC.createUser(ctx, &provider, identity); err != nil {
+			return nil, err
+		}
+		if err := LDAP.sync(ctx, user, &provider, identity); err != nil {
+			return nil, err
+		}
+		return user, nil
+	}
+	return nil, errLDAPNoMatch
+}
+
+// sync 按目录中的组提升管理员并加入映射的组织，已有的更高角色保持不变
+// Promote admins and join the mapped organizations by the directory groups, existing higher roles are kept
+func (LDAPApi) sync(ctx context.Context, user *models.User, provider *models.OIDCConfig, identity *utils.ExternalIdentity) error {
+	if err := OIDC.promote(user, provider, identity); err != nil {
+		return errors.New("Failed to update user")
+	}
+	for _, mapping := range provider.LDAP.GroupMappings {
+		if !utils.OIDC.MatchGroups([]string{mapping.Group}, identity.Groups) {
+			continue
+		}
+		org, err := store.Org.GetOrgByName(mapping.Organization)
+		if err != nil {
+			utils.Log.Ctx(ctx).Warnf("organization %q of directory %d group mapping not found", mapping.Organization, provider.ID)
+			continue
+		}
+		if store.Org.GetUserAuth(org, user.ID).AtLeast(mapping.Role) {
+			continue
+		}
+		if err := store.Org.SetMember(org.ID, user.ID, mapping.Role); err != nil {
+			utils.Log.Ctx(ctx).Errorf("failed to add user %d to organization %d: %v", user.ID, org.ID, err)
+		}
+	}
+	return nil
+}
+
+// Link 使用目录登录名和密码将目录账号关联到当前用户
+// Link a directory account to the current user with its login name and password
+func (LDAPApi) Link(ctx context.Context, c *app.RequestContext) {
+	id, err := strconv.Atoi(c.Param("provider_id"))
+	if err != nil {
+		resps.BadRequest(c, resps.ParameterError)
+		return
+	}
+	req := &LDAPLinkReq{}
+	if !bindJSON(c, req) {
+		return
+	}
+	provider, err := store.OIDC.GetProviderByID(uint(id))
+	if err != nil || !provider.Enabled || provider.Type != constants.AuthProviderLDAP {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	user := middle.Auth.GetUser(ctx, c)
+	identity, err := utils.LDAP.Authenticate(ctx, provider, req.Username, req.Password)
+	if err != nil {
+		if !errors.Is(err, utils.ErrLDAPInvalidCredentials) {
+			utils.Log.Ctx(ctx).Warnf("failed to authenticate against directory %d: %v", provider.ID, err)
+		}
+		resps.Unauthorized(c, "Failed to verify directory login")
+		return
+	}
+	linked, err := store.OIDC.GetIdentity(provider.ID, identity.Subject)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		resps.InternalServerError(c, "Failed to get identity")
+		return
+	}
+	if linked != nil {
+		if linked.UserID != user.ID {
+			resps.Forbidden(c, "This identity is already linked to another user")
+			return
+		}
+	} else if err := store.OIDC.CreateIdentity(OIDC.newIdentity(provider, identity, user.ID)); err != nil {
+		resps.DBError(c, err, "Failed to link identity")
+		return
+	}
+	if err := LDAP.sync(ctx, user, provider, identity); err != nil {
+		resps.InternalServerError(c, err.Error())
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{})
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/i18n"
+	"github.com/LiteyukiStudio/spage/middle"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/serve"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/task"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+	"gorm.io/gorm"
+)
+
+type LinkCheckApi struct{}
+
+// LinkCheck 上线后的链接检查，在后台任务中抓取版本并将报告保存在版本上，发现问题时通知项目
+// Link checks after going live, a background job crawls the version and saves the report on it, notifying the project of problems
+var LinkCheck = LinkCheckApi{}
+
+// linkCheckPayload 链接检查任务的参数
+// Parameters of a link check job
+type linkCheckPayload struct {
+	ReleaseID uint `json:"release_id"` // 检查的版本ID Version ID checked
+}
+
+// enqueue 开启 linkcheck.enable 时为上线的版本创建检查任务，失败只记录日志
+// Enqueue a check of a version that went live when linkcheck.enable is on, failures are only logged
+func (LinkCheckApi) enqueue(ctx context.Context, site *models.Site, release *models.SiteRelease) {
+	if !config.LinkCheckEnable {
+		return
+	}
+	if _, err := task.EnqueueJob(ctx, constants.JobTypeLinkCheck, linkCheckPayload{ReleaseID: release.ID}, site.ID, 0); err != nil {
+		utils.Log.Ctx(ctx).Warnf("failed to enqueue the link check of release %d: %v", release.ID, err)
+	}
+}
+
+// runJob 检查版本中的链接、资源和页面大小并保存报告，发现问题时通过 webhook 和通知渠道发送警告；版本已删除时什么也不做
+// Check the links, assets and page sizes of a version and save the report, warning through webhooks and notification channels when problems are found; does nothing once the version was deleted
+func (LinkCheckApi) runJob(ctx context.Context, job *models.Job) (any, error) {
+	payload := linkCheckPayload{}
+	if err := json.Unmarshal([]byte(job.Payload), &payload); err != nil {
+		return nil, task.Permanent(err)
+	}
+	site, err := store.Site.GetByID(job.SiteID)
+	if err != nil {
+		return nil, task.Permanent(errors.New("site not found"))
+	}
+	release, err := store.Site.GetSiteRelease(site.ID, payload.ReleaseID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return map[string]any{"skipped": "release deleted"}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	report, err := serve.CheckLinks(ctx, &release.File, serve.SiteHosts(site), config.LinkCheckMaxPages, int64(config.LinkCheckMaxPageSize)<<10)
+	if err != nil {
+		return nil, err
+	}
+	data, _ := json.Marshal(report)
+	if err := store.Site.SetLinkReport(release.ID, string(data)); err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int)
+	for _, issue := range report.Issues {
+		counts[issue.Kind]++
+	}
+	if len(report.Issues) > 0 {
+		utils.Log.Ctx(ctx).Infof("link check of release %d of site %d found %d problems", release.ID, site.ID, len(report.Issues))
+		Webhook.emit(ctx, &site.Project, constants.WebhookDeploymentLinkWarnings,
+			i18n.Msg("Link check of %s on site %s found %d broken links, %d missing assets and %d oversized pages", release.Tag, site.Name,
+				counts[serve.LinkIssueBroken], counts[serve.LinkIssueMissing], counts[serve.LinkIssueOversized]),
+			map[string]any{"site_id": site.ID, "site": site.Name, "release_id": release.ID, "tag": release.Tag, "issues": report.Issues})
+	}
+	return map[string]any{"release_id": release.ID, "pages": report.Pages, "links": report.Links, "issues": counts}, nil
+}
+
+// getRelease 获取路径中属于当前站点的版本，失败时已写入响应
+// Get the version of the path that belongs to the current site; the response is already written on failure
+func (LinkCheckApi) getRelease(c *app.RequestContext) (*models.Site, *models.SiteRelease) {
+	site := getSite(c)
+	if site == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return nil, nil
+	}
+	id, err := strconv.Atoi(c.Param("release_id"))
+	if err != nil {
+		resps.BadRequest(c, resps.ParameterError)
+		return nil, nil
+	}
+	release, err := store.Site.GetSiteRelease(site.ID, uint(id))
+	if err != nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return nil, nil
+	}
+	return site, release
+}
+
+// Get 获取版本最近一次链接检查的报告，未检查过时报告为空
+// Get the report of the latest link check of a version, the report is null when it was never checked
+func (LinkCheckApi) Get(ctx context.Context, c *app.RequestContext) {
+	_, release := LinkCheck.getRelease(c)
+	if release == nil {
+		return
+	}
+	var report *serve.LinkReport
+	if release.LinkReport != "" {
+		report = &serve.LinkReport{}
+		if err := json.Unmarshal([]byte(release.LinkReport), report); err != nil {
+			report = nil
+		}
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"release_id": release.ID,
+		"report":     report,
+	})
+}
+
+// Run 立即在后台任务中重新检查版本，不受 linkcheck.enable 影响
+// Check the version again in a background job right away, regardless of linkcheck.enable
+func (LinkCheckApi) Run(ctx context.Context, c *app.RequestContext) {
+	site, release := LinkCheck.getRelease(c)
+	if release == nil {
+		return
+	}
+	user := middle.Auth.GetUser(ctx, c)
+	job, err := task.EnqueueJob(ctx, constants.JobTypeLinkCheck, linkCheckPayload{ReleaseID: release.ID}, site.ID, user.ID)
+	if err != nil {
+		resps.DBError(c, err, "create job error")
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"job": Job.toDTO(job, false),
+	})
+}
@@ -0,0 +1,18 @@
+package handlers
+
+import (
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// getListQuery 获取列表接口的分页和排序参数，sort 不合法时返回 400
+// Get the pagination and sorting parameters of a list endpoint, responding with 400 for an invalid sort
+func getListQuery(c *app.RequestContext, defaultSort string, sortable ...string) (utils.ListQuery, bool) {
+	list, err := utils.Ctx.GetListQuery(c, defaultSort, sortable...)
+	if err != nil {
+		resps.BadRequest(c, err.Error())
+		return list, false
+	}
+	return list, true
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/middle"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// loginSubjects 统计登录失败的对象，客户端 IP 以及已知时的账号
+// Subjects failed logins are counted for, the client IP and the account when it is known
+func loginSubjects(c *app.RequestContext, user *models.User) [][2]string {
+	subjects := [][2]string{{models.LoginFailureIP, c.ClientIP()}}
+	if user != nil {
+		subjects = append(subjects, [2]string{models.LoginFailureUser, strconv.FormatUint(uint64(user.ID), 10)})
+	}
+	return subjects
+}
+
+// loginFailures 窗口内的连续失败次数，最近一次失败超过 login.failure-window 时为 0
+// Consecutive failures within the window, 0 once the latest failure is older than login.failure-window
+func loginFailures(failure models.LoginFailure, now time.Time) int {
+	if failure.LastFailedAt.Before(now.Add(-time.Duration(config.LoginFailureWindow) * time.Second)) {
+		return 0
+	}
+	return failure.Failures
+}
+
+// loginBackoff 距离允许下一次登录还需等待的时间，连续失败达到 login.backoff-after 后从 1 秒起每次失败翻倍，不超过 login.backoff-max
+// Time left before the next login is allowed, doubling from 1 second with every failure from login.backoff-after on and capped at login.backoff-max
+func loginBackoff(failure models.LoginFailure, now time.Time) time.Duration {
+	excess := loginFailures(failure, now) - config.LoginBackoffAfter
+	if excess < 0 {
+		return 0
+	}
+	delay := time.Duration(config.LoginBackoffMax) * time.Second
+	if excess < 30 {
+		delay = min(time.Second<<excess, delay)
+	}
+	return time.Until(failure.LastFailedAt.Add(delay))
+}
+
+// setRetryAfter 以向上取整的秒数设置 Retry-After 响应头
+// Set the Retry-After header in seconds rounded up
+func setRetryAfter(c *app.RequestContext, wait time.Duration) {
+	c.Response.Header.Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+}
+
+// loginBlocked 账号被锁定或账号、客户端 IP 处于退避中时返回错误响应并返回 true，user 为空时只检查 IP
+// Respond with an error and return true when the account is locked or the account or client IP is backing off, only the IP is checked when user is nil
+func (UserApi) loginBlocked(c *app.RequestContext, user *models.User) bool {
+	now := time.Now()
+	for _, subject := range loginSubjects(c, user) {
+		failure, err := store.LoginFailure.Get(subject[0], subject[1])
+		if err != nil {
+			resps.DBError(c, err, "Failed to check login attempts")
+			return true
+		}
+		if failure.LockedUntil != nil && failure.LockedUntil.After(now) {
+			setRetryAfter(c, failure.LockedUntil.Sub(now))
+			resps.Fail(c, http.StatusForbidden, resps.CodeAccountLocked, "Account is temporarily locked after too many failed logins", nil)
+			return true
+		}
+		if wait := loginBackoff(failure, now); wait > 0 {
+			setRetryAfter(c, wait)
+			resps.TooManyRequests(c, "Too many failed logins, retry later")
+			return true
+		}
+	}
+	return false
+}
+
+// captchaRequired 登录是否需要验证码，配置了验证码且 login.captcha-after 为 0 或账号、客户端 IP 的连续失败达到该值时需要
+// Whether signing in needs the captcha, which is when one is configured and login.captcha-after is 0 or reached by the failures of the account or client IP
+func (UserApi) captchaRequired(c *app.RequestContext, user *models.User) bool {
+	if !middle.Captcha.Enabled() {
+		return false
+	}
+	if config.LoginCaptchaAfter <= 0 {
+		return true
+	}
+	now := time.Now()
+	for _, subject := range loginSubjects(c, user) {
+		if failure, err := store.LoginFailure.Get(subject[0], subject[1]); err != nil || loginFailures(failure, now) >= config.LoginCaptchaAfter {
+			return true
+		}
+	}
+	return false
+}
+
+// loginFailed 记录一次失败的登录，账号连续失败达到 login.lockout-threshold 时临时锁定，均记入审计日志；返回下一次登录是否需要验证码
+// Record a failed login, locking the account temporarily once its failures reach login.lockout-threshold, both written to the audit log;
+// returns whether the next login needs the captcha
+func (UserApi) loginFailed(ctx context.Context, c *app.RequestContext, user *models.User) bool {
+	now := time.Now()
+	since := now.Add(-time.Duration(config.LoginFailureWindow) * time.Second)
+	failures := map[string]int{}
+	for _, subject := range loginSubjects(c, user) {
+		failure, err := store.LoginFailure.Fail(subject[0], subject[1], now, since)
+		if err != nil {
+			utils.Log.Ctx(ctx).Warnf("failed to record a failed login of %s %s: %v", subject[0], subject[1], err)
+			continue
+		}
+		failures[subject[0]] = failure.Failures
+	}
+	if user == nil {
+		return User.captchaRequired(c, nil)
+	}
+	Audit.record(ctx, c, user, constants.AuditLoginFailed, constants.AuditTargetUser, user.ID, map[string]any{"failures": failures[models.LoginFailureUser]})
+	if config.LoginLockoutThreshold > 0 && failures[models.LoginFailureUser] >= config.LoginLockoutThreshold {
+		until := now.Add(time.Duration(config.LoginLockoutDuration) * time.Second)
+		if err := store.LoginFailure.Lock(models.LoginFailureUser, strconv.FormatUint(uint64(user.ID), 10), until); err != nil {
+			utils.Log.Ctx(ctx).Warnf("failed to lock user %d: %v", user.ID, err)
+		} else {
+			Audit.record(ctx, c, user, constants.AuditLoginLockout, constants.AuditTargetUser, user.ID, map[string]any{"failures": failures[models.LoginFailureUser], "locked_until": until})
+		}
+	}
+	return User.captchaRequired(c, user)
+}
+
+// loginFailedResp 返回登录失败的响应，details 中的 captcha_required 告知客户端下一次登录是否需要验证码
+// Respond to a failed login, captcha_required in details tells the client whether the next login needs the captcha
+func loginFailedResp(c *app.RequestContext, status int, code, message string, captchaRequired bool) {
+	resps.Fail(c, status, code, message, map[string]any{"captcha_required": captchaRequired})
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/metrics"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+type MetricsApi struct{}
+
+var Metrics = MetricsApi{}
+
+// Serve 以 Prometheus 文本格式输出监控指标，配置了 metrics.token 时需要携带 Bearer 令牌
+// Write the metrics in the Prometheus text format, requiring a bearer token when metrics.token is set
+func (MetricsApi) Serve(ctx context.Context, c *app.RequestContext) {
+	if config.MetricsToken != "" {
+		token, ok := strings.CutPrefix(string(c.GetHeader("Authorization")), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(config.MetricsToken)) != 1 {
+			c.Header("WWW-Authenticate", `Bearer realm="metrics"`)
+			c.String(http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+			return
+		}
+	}
+	c.SetStatusCode(http.StatusOK)
+	c.Response.Header.SetContentType(metrics.ContentType)
+	if err := metrics.Write(c); err != nil {
+		utils.Log.Ctx(ctx).Error("Failed to write metrics: ", err)
+	}
+}
@@ -0,0 +1,404 @@
+package handlers
+
+import (
+	"context"
+	"net/mail"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/certs"
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/i18n"
+	"github.com/LiteyukiStudio/spage/mailer"
+	"github.com/LiteyukiStudio/spage/middle"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/task"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/sirupsen/logrus"
+)
+
+// certificateCheckInterval 检查证书到期时间的间隔
+// Interval between checks of certificate expiry
+const certificateCheckInterval = time.Hour
+
+type NotificationApi struct{}
+
+// Notification 用户和组织的通知渠道，订阅的事件通过邮件、Slack、Telegram 或飞书通知，投递与 webhook 相同地重试
+// Notification channels of users and organizations, subscribed events are sent by email, Slack, Telegram or Feishu and retried like webhooks
+var Notification = NotificationApi{}
+
+func (NotificationApi) toDTO(channel *models.NotificationChannel) NotificationChannelDTO {
+	events := channel.Events
+	if events == nil {
+		events = []string{}
+	}
+	return NotificationChannelDTO{
+		ID:        channel.ID,
+		Name:      channel.Name,
+		Kind:      channel.Kind,
+		Target:    channel.Target,
+		HasSecret: channel.Secret != "",
+		Events:    events,
+		Active:    channel.Active,
+		CreatedAt: channel.CreatedAt,
+	}
+}
+
+func (NotificationApi) deliveryToDTO(delivery *models.NotificationDelivery) NotificationDeliveryDTO {
+	deliveryDTO := NotificationDeliveryDTO{
+		ID:             delivery.ID,
+		Event:          delivery.Event,
+		Subject:        delivery.Subject,
+		Text:           delivery.Text,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		ResponseStatus: delivery.ResponseStatus,
+		ResponseBody:   delivery.ResponseBody,
+		Error:          delivery.Error,
+		CreatedAt:      delivery.CreatedAt,
+		DeliveredAt:    delivery.DeliveredAt,
+	}
+	if delivery.Status == constants.WebhookDeliveryPending {
+		deliveryDTO.NextAttemptAt = &delivery.NextAttemptAt
+	}
+	return deliveryDTO
+}
+
+// owner 请求所属的通知渠道所有者，组织路由为路径中的组织，其余为当前用户
+// Owner of the notification channels of the request, the organization in the path for organization routes and the current user otherwise
+func (NotificationApi) owner(ctx context.Context, c *app.RequestContext) (string, uint, bool) {
+	if org := getOrg(c); org != nil {
+		return constants.OwnerTypeOrg, org.ID, true
+	}
+	user := middle.Auth.GetUser(ctx, c)
+	if user == nil {
+		return "", 0, false
+	}
+	return constants.OwnerTypeUser, user.ID, true
+}
+
+// getChannel 获取路径中指定的通知渠道，不存在时返回 404
+// Get the notification channel given in the path, answering 404 when it does not exist
+func (NotificationApi) getChannel(ctx context.Context, c *app.RequestContext) *models.NotificationChannel {
+	ownerType, ownerID, ok := Notification.owner(ctx, c)
+	if !ok {
+		return nil
+	}
+	channelID, err := strconv.Atoi(c.Param("channel_id"))
+	if err != nil {
+		resps.BadRequest(c, resps.ParameterError)
+		return nil
+	}
+	channel, err := store.Notification.GetByOwner(ownerType, ownerID, uint(channelID))
+	if err != nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return nil
+	}
+	return channel
+}
+
+// apply 按渠道类型校验请求并写入通知渠道
+// Validate the request for the channel kind and apply it to the channel
+func (NotificationApi) apply(c *app.RequestContext, channel *models.NotificationChannel, req *NotificationChannelReq) bool {
+	if channel.Kind == "" {
+		if !slices.Contains(constants.NotificationChannels, req.Kind) {
+			resps.BadRequest(c, "unknown kind "+req.Kind)
+			return false
+		}
+		channel.Kind = req.Kind
+	} else if req.Kind != channel.Kind {
+		resps.BadRequest(c, "kind cannot be changed")
+		return false
+	}
+	switch channel.Kind {
+	case constants.NotificationChannelEmail:
+		if !mailer.Enabled() {
+			resps.BadRequest(c, "email sending is disabled on this instance")
+			return false
+		}
+		if _, err := mail.ParseAddress(req.Target); err != nil {
+			resps.BadRequest(c, "target must be an email address")
+			return false
+		}
+	case constants.NotificationChannelSlack, constants.NotificationChannelFeishu:
+		target, err := url.Parse(req.Target)
+		if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+			resps.BadRequest(c, "target must be an absolute http or https URL")
+			return false
+		}
+	case constants.NotificationChannelTelegram:
+		if req.Secret == "" && channel.Secret == "" {
+			resps.BadRequest(c, "secret must be the bot token")
+			return false
+		}
+	}
+	for _, event := range req.Events {
+		if !slices.Contains(constants.NotificationEvents, event) {
+			resps.BadRequest(c, "unknown event "+event)
+			return false
+		}
+	}
+	channel.Name = req.Name
+	channel.Target = req.Target
+	channel.Events = req.Events
+	if req.Secret != "" {
+		channel.Secret = req.Secret
+	}
+	if req.Active != nil {
+		channel.Active = *req.Active
+	}
+	return true
+}
+
+// List 获取当前用户或组织的通知渠道
+// List the notification channels of the current user or the organization
+func (NotificationApi) List(ctx context.Context, c *app.RequestContext) {
+	ownerType, ownerID, ok := Notification.owner(ctx, c)
+	if !ok {
+		return
+	}
+	channels, err := store.Notification.ListByOwner(ownerType, ownerID)
+	if err != nil {
+		resps.DBError(c, err, "get notification channels error")
+		return
+	}
+	channelDTOs := make([]NotificationChannelDTO, 0, len(channels))
+	for _, channel := range channels {
+		channelDTOs = append(channelDTOs, Notification.toDTO(&channel))
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"channels": channelDTOs,
+	})
+}
+
+// Create 为当前用户或组织创建通知渠道
+// Create a notification channel for the current user or the organization
+func (NotificationApi) Create(ctx context.Context, c *app.RequestContext) {
+	ownerType, ownerID, ok := Notification.owner(ctx, c)
+	if !ok {
+		return
+	}
+	req := NotificationChannelReq{}
+	if !bind(c, &req) {
+		return
+	}
+	channel := models.NotificationChannel{OwnerType: ownerType, OwnerID: ownerID, Active: true}
+	if !Notification.apply(c, &channel, &req) {
+		return
+	}
+	if err := store.Notification.Create(&channel); err != nil {
+		resps.DBError(c, err, "create notification channel error")
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"channel": Notification.toDTO(&channel),
+	})
+}
+
+// Update 更新通知渠道
+// Update a notification channel
+func (NotificationApi) Update(ctx context.Context, c *app.RequestContext) {
+	channel := Notification.getChannel(ctx, c)
+	if channel == nil {
+		return
+	}
+	req := NotificationChannelReq{}
+	if !bind(c, &req) {
+		return
+	}
+	if !Notification.apply(c, channel, &req) {
+		return
+	}
+	if err := store.Notification.Update(channel); err != nil {
+		resps.DBError(c, err, "update notification channel error")
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"channel": Notification.toDTO(channel),
+	})
+}
+
+// Delete 删除通知渠道及其投递记录
+// Delete a notification channel and its deliveries
+func (NotificationApi) Delete(ctx context.Context, c *app.RequestContext) {
+	channel := Notification.getChannel(ctx, c)
+	if channel == nil {
+		return
+	}
+	if err := store.Notification.Delete(channel); err != nil {
+		resps.DBError(c, err, "delete notification channel error")
+		return
+	}
+	resps.Ok(c, resps.OK)
+}
+
+// Deliveries 分页获取通知渠道的投递记录
+// List the deliveries of a notification channel with pagination
+func (NotificationApi) Deliveries(ctx context.Context, c *app.RequestContext) {
+	channel := Notification.getChannel(ctx, c)
+	if channel == nil {
+		return
+	}
+	page, limit := utils.Ctx.GetPageLimit(c)
+	deliveries, total, err := store.Notification.ListDeliveries(ctx, channel.ID, page, limit)
+	if err != nil {
+		resps.DBError(c, err, "get deliveries error")
+		return
+	}
+	utils.Ctx.SetPageHeaders(c, page, limit, total)
+	deliveryDTOs := make([]NotificationDeliveryDTO, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		deliveryDTOs = append(deliveryDTOs, Notification.deliveryToDTO(&delivery))
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"deliveries": deliveryDTOs,
+		"total":      total,
+	})
+}
+
+// Test 通过通知渠道发送一条测试通知，结果见投递记录
+// Send a test notification through the channel, the outcome shows in its deliveries
+func (NotificationApi) Test(ctx context.Context, c *app.RequestContext) {
+	channel := Notification.getChannel(ctx, c)
+	if channel == nil {
+		return
+	}
+	language := Notification.language(channel.OwnerType, channel.OwnerID)
+	delivery := models.NotificationDelivery{
+		ChannelID:     channel.ID,
+		Event:         constants.NotificationTest,
+		Subject:       i18n.T(language, "Spage test notification"),
+		Text:          i18n.Sprintf(language, "This is a test of the notification channel %s.", channel.Name),
+		Status:        constants.WebhookDeliveryPending,
+		NextAttemptAt: time.Now(),
+	}
+	if err := store.Notification.CreateDeliveries([]models.NotificationDelivery{delivery}); err != nil {
+		resps.DBError(c, err, "create delivery error")
+		return
+	}
+	task.WakeNotifications()
+	resps.Ok(c, resps.OK)
+}
+
+// notify 为用户或组织订阅了事件的通知渠道创建投递，失败只记录日志，不影响触发事件的操作
+// Create deliveries for the channels of a user or organization subscribed to the event, failures are only logged and never fail the triggering operation
+func (NotificationApi) notify(ctx context.Context, ownerType string, ownerID uint, event string, subject, text i18n.Message) {
+	channels, err := store.Notification.ListSubscribed(ownerType, ownerID, event)
+	if err != nil {
+		utils.Log.Ctx(ctx).Warnf("failed to list notification channels of %s %d: %v", ownerType, ownerID, err)
+		return
+	}
+	if len(channels) == 0 {
+		return
+	}
+	now := time.Now()
+	language := Notification.language(ownerType, ownerID)
+	deliveries := make([]models.NotificationDelivery, 0, len(channels))
+	for _, channel := range channels {
+		deliveries = append(deliveries, models.NotificationDelivery{
+			ChannelID:     channel.ID,
+			Event:         event,
+			Subject:       subject.In(language),
+			Text:          text.In(language),
+			Status:        constants.WebhookDeliveryPending,
+			NextAttemptAt: now,
+		})
+	}
+	if err := store.Notification.CreateDeliveries(deliveries); err != nil {
+		utils.Log.Ctx(ctx).Warnf("failed to queue %s notifications of %s %d: %v", event, ownerType, ownerID, err)
+		return
+	}
+	task.WakeNotifications()
+}
+
+// notifyProject 通知项目所有者项目上发生的事件
+// Notify the owner of a project about an event of the project
+func (NotificationApi) notifyProject(ctx context.Context, project *models.Project, event string, text i18n.Message) {
+	Notification.notify(ctx, project.OwnerType, project.OwnerID, event, i18n.Msg("[%s] %s", project.Name, i18n.Msg(event)), text)
+}
+
+// language 用户或组织接收通知使用的语言 Language the user or organization receives notifications in
+func (NotificationApi) language(ownerType string, ownerID uint) string {
+	if language := i18n.Normalize(store.User.OwnerLanguage(ownerType, ownerID)); language != "" {
+		return language
+	}
+	return i18n.Default()
+}
+
+// Watch 每小时检查自定义域名证书的到期时间，直到 ctx 结束
+// Check the expiry of custom domain certificates every hour until ctx is done
+func (NotificationApi) Watch(ctx context.Context) {
+	ticker := time.NewTicker(certificateCheckInterval)
+	defer ticker.Stop()
+	for {
+		if err := Notification.checkCertificates(ctx, time.Now()); err != nil {
+			logrus.Warnf("failed to check certificates: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkCertificates 证书剩余有效期少于 notification.certificate-warning 天时通知项目，续期前每张证书只通知一次
+// Notify the project when a certificate has fewer than notification.certificate-warning days left, once per certificate until it renews
+func (NotificationApi) checkCertificates(ctx context.Context, now time.Time) error {
+	if !certs.Enabled() || config.ReadOnly() {
+		return nil
+	}
+	entries, err := store.Domain.ListCertificates()
+	if err != nil {
+		return err
+	}
+	warnAfter := now.AddDate(0, 0, config.NotificationCertificateWarning)
+	for _, entry := range entries {
+		name, notAfter, ok := certs.CachedCertificate(entry.Name, entry.Data)
+		if !ok {
+			continue
+		}
+		// 已解绑或未验证的域名不再续期，无需通知 Unbound or unverified domains are not renewed anymore and need no notice
+		domain, err := store.Domain.GetByDomain(name)
+		if err != nil || domain.VerifiedAt == nil {
+			continue
+		}
+		site, err := store.Site.GetByID(domain.SiteID)
+		if err != nil {
+			continue
+		}
+		key := "certificate:" + name
+		if notAfter.After(warnAfter) {
+			if err := store.Notification.ClearAlert(site.Project.OwnerType, site.Project.OwnerID, key); err != nil {
+				return err
+			}
+			continue
+		}
+		recorded, err := store.Notification.RecordAlert(site.Project.OwnerType, site.Project.OwnerID, key, now)
+		if err != nil {
+			return err
+		}
+		if !recorded {
+			continue
+		}
+		message := i18n.Msg("The certificate of %s (site %s) expires on %s and was not renewed yet, check that the domain still points to this instance",
+			name, site.Name, notAfter.UTC().Format(time.DateOnly))
+		if !notAfter.After(now) {
+			message = i18n.Msg("The certificate of %s (site %s) expired on %s, check that the domain still points to this instance",
+				name, site.Name, notAfter.UTC().Format(time.DateOnly))
+		}
+		Webhook.emit(ctx, &site.Project, constants.WebhookCertificateExpiring, message, map[string]any{
+			"site_id":   site.ID,
+			"site":      site.Name,
+			"domain":    name,
+			"not_after": notAfter,
+		})
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import "time"
+
+// NotificationChannelDTO 通知渠道数据传输对象，密钥不返回
+// Notification Channel Data Transfer Object (DTO), the secret is never returned
+type NotificationChannelDTO struct {
+	ID        uint      `json:"id"`         // 渠道ID Channel ID
+	Name      string    `json:"name"`       // 名称 Name
+	Kind      string    `json:"kind"`       // 渠道类型 Channel kind
+	Target    string    `json:"target"`     // 邮箱地址、webhook 地址或聊天ID Email address, webhook URL or chat ID
+	HasSecret bool      `json:"has_secret"` // 是否设置了密钥 Whether a secret is set
+	Events    []string  `json:"events"`     // 订阅的事件，为空时订阅失败和警告 Subscribed events, failures and warnings when empty
+	Active    bool      `json:"active"`     // 是否启用 Whether enabled
+	CreatedAt time.Time `json:"created_at"` // 创建时间 Created time
+}
+
+// NotificationChannelReq 创建或更新通知渠道的请求参数，更新时未提供密钥则保留原密钥，渠道类型不能修改
+// Request parameters to create or update a notification channel, the secret is kept when not given on update and the kind cannot change
+type NotificationChannelReq struct {
+	Name   string   `json:"name" binding:"required" validate:"required,max=64"` // 名称 Name
+	Kind   string   `json:"kind" binding:"required"`                            // 渠道类型：email、slack、telegram 或 feishu Channel kind: email, slack, telegram or feishu
+	Target string   `json:"target" binding:"required"`                          // 邮箱地址、webhook 地址或 Telegram 聊天ID Email address, webhook URL or Telegram chat ID
+	Secret string   `json:"secret"`                                             // 飞书签名密钥或 Telegram 机器人令牌 Feishu signing secret or Telegram bot token
+	Events []string `json:"events"`                                             // 订阅的事件 Subscribed events
+	Active *bool    `json:"active"`                                             // 是否启用，默认启用 Whether enabled, enabled by default
+}
+
+// NotificationDeliveryDTO 通知投递记录数据传输对象
+// Notification Delivery Data Transfer Object (DTO)
+type NotificationDeliveryDTO struct {
+	ID             uint       `json:"id"`                     // 投递ID Delivery ID
+	Event          string     `json:"event"`                  // 事件 Event
+	Subject        string     `json:"subject"`                // 标题 Subject
+	Text           string     `json:"text"`                   // 正文 Text
+	Status         string     `json:"status"`                 // 投递状态 Delivery status
+	Attempts       int        `json:"attempts"`               // 已尝试次数 Attempts made
+	NextAttemptAt  *time.Time `json:"next_attempt_at"`        // 下次尝试时间，已结束时为空 Next attempt time, empty once finished
+	ResponseStatus int        `json:"response_status"`        // 最近一次的响应状态码 Status code of the latest response
+	ResponseBody   string     `json:"response_body"`          // 最近一次的响应体 Body of the latest response
+	Error          string     `json:"error"`                  // 最近一次的错误 Error of the latest attempt
+	CreatedAt      time.Time  `json:"created_at"`             // 创建时间 Created time
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"` // 投递成功时间 Time of successful delivery
+}
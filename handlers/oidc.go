@@ -0,0 +1,530 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/middle"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol"
+	"gorm.io/gorm"
+)
+
+type OIDCApi struct{}
+
+var OIDC = OIDCApi{}
+
+const (
+	oidcStateCookie = "oidc_state"     // 登录状态 cookie 名称 Login state cookie name
+	oidcStateTTL    = 10 * time.Minute // 登录流程的有效期 Validity of a login flow
+)
+
+func (OIDCApi) toDTO(provider *models.OIDCConfig) AuthProviderDTO {
+	return AuthProviderDTO{
+		ID:          provider.ID,
+		Type:        provider.Type,
+		DisplayName: provider.DisplayName,
+		Icon:        provider.Icon,
+	}
+}
+
+func (OIDCApi) toAdminDTO(provider *models.OIDCConfig) AuthProviderAdminDTO {
+	dto := AuthProviderAdminDTO{
+		AuthProviderDTO:  OIDC.toDTO(provider),
+		ClientID:         provider.ClientID,
+		OidcDiscoveryURL: provider.OidcDiscoveryURL,
+		BaseURL:          provider.BaseURL,
+		GroupsClaim:      provider.GroupsClaim,
+		AdminGroups:      provider.AdminGroups,
+		AllowedGroups:    provider.AllowedGroups,
+		Enabled:          provider.Enabled,
+		CallbackURL:      OIDC.callbackURL(provider),
+	}
+	if provider.Type == constants.AuthProviderLDAP {
+		dto.LDAP, dto.CallbackURL = &provider.LDAP, ""
+	}
+	return dto
+}
+
+// callbackURL 提供方回调地址，前端与后端同源部署或由前端代理 /api
+// Provider callback URL, the frontend is served from the same origin or proxies /api
+func (OIDCApi) callbackURL(provider *models.OIDCConfig) string {
+	return strings.TrimSuffix(config.FrontEndURL, "/") + "/api/v1/user/oidc/" + strconv.Itoa(int(provider.ID)) + "/callback"
+}
+
+// getProvider 从路径参数获取启用的登录提供方，types 为空时不限类型
+// Get the enabled auth provider from the path parameter, of any type when types is empty
+func getProvider(c *app.RequestContext, types ...constants.AuthProviderType) *models.OIDCConfig {
+	id, err := strconv.Atoi(c.Param("provider_id"))
+	if err != nil {
+		return nil
+	}
+	provider, err := store.OIDC.GetProviderByID(uint(id))
+	if err != nil || !provider.Enabled {
+		return nil
+	}
+	if len(types) > 0 && !slices.Contains(types, provider.Type) {
+		return nil
+	}
+	return provider
+}
+
+// redirectTypes 通过跳转登录的提供方类型 Provider types signing in through redirects
+var redirectTypes = []constants.AuthProviderType{constants.AuthProviderGitHub, constants.AuthProviderGitLab, constants.AuthProviderOIDC}
+
+// Providers 获取可用于登录的提供方
+// Get the providers available for signing in
+func (OIDCApi) Providers(ctx context.Context, c *app.RequestContext) {
+	providers, err := store.OIDC.ListProviders(true)
+	if err != nil {
+		resps.DBError(c, err, "Failed to get providers")
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"providers": func() (providerDTOs []AuthProviderDTO) {
+			for _, provider := range providers {
+				providerDTOs = append(providerDTOs, OIDC.toDTO(&provider))
+			}
+			return
+		}(),
+	})
+}
+
+// Login 跳转到提供方登录
+// Redirect to the provider to sign in
+func (OIDCApi) Login(ctx context.Context, c *app.RequestContext) {
+	provider := getProvider(c, redirectTypes...)
+	if provider == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	OIDC.redirectToProvider(ctx, c, provider, 0)
+}
+
+// Link 为当前用户关联提供方身份
+// Link a provider identity to the current user
+func (OIDCApi) Link(ctx context.Context, c *app.RequestContext) {
+	provider := getProvider(c, redirectTypes...)
+	if provider == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	user := middle.Auth.GetUser(ctx, c)
+	if user == nil {
+		return
+	}
+	OIDC.redirectToProvider(ctx, c, provider, user.ID)
+}
+
+func (OIDCApi) redirectToProvider(ctx context.Context, c *app.RequestContext, provider *models.OIDCConfig, linkUserID uint) {
+	state, err := utils.OIDC.NewState(provider.ID, linkUserID, oidcStateTTL)
+	if err != nil {
+		resps.InternalServerError(c, "Failed to create login state")
+		return
+	}
+	authURL, err := utils.OIDC.AuthCodeURL(ctx, provider, OIDC.callbackURL(provider), state)
+	if err != nil {
+		utils.Log.Ctx(ctx).Errorf("failed to prepare login with provider %d: %v", provider.ID, err)
+		resps.ServiceUnavailable(c, "Provider is unavailable")
+		return
+	}
+	signed, err := utils.OIDC.SignState(state)
+	if err != nil {
+		resps.InternalServerError(c, "Failed to create login state")
+		return
+	}
+	c.SetCookie(oidcStateCookie, signed, int(oidcStateTTL.Seconds()), "/api/v1/user/oidc", "", protocol.CookieSameSiteLaxMode, true, true)
+	c.Redirect(http.StatusFound, []byte(authURL))
+}
+
+// Callback 处理提供方回调：已关联的身份直接登录，关联流程绑定到当前用户，否则创建新用户
+// Handle the provider callback: linked identities sign in, link flows bind to the current user, otherwise a new user is created
+func (OIDCApi) Callback(ctx context.Context, c *app.RequestContext) {
+	provider := getProvider(c, redirectTypes...)
+	if provider == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	signed := string(c.Cookie(oidcStateCookie))
+	c.SetCookie(oidcStateCookie, "", -1, "/api/v1/user/oidc", "", protocol.CookieSameSiteLaxMode, true, true)
+	state, err := utils.OIDC.ParseState(signed)
+	if err != nil || state.ProviderID != provider.ID || c.Query("state") != state.State {
+		resps.BadRequest(c, "Invalid or expired login state")
+		return
+	}
+	if providerErr := c.Query("error"); providerErr != "" {
+		resps.BadRequest(c, "Provider returned an error: "+providerErr)
+		return
+	}
+	identity, err := utils.OIDC.Exchange(ctx, provider, OIDC.callbackURL(provider), c.Query("code"), state)
+	if err != nil {
+		utils.Log.Ctx(ctx).Warnf("failed to verify login with provider %d: %v", provider.ID, err)
+		resps.Unauthorized(c, "Failed to verify provider login")
+		return
+	}
+	if !utils.OIDC.MatchGroups(provider.AllowedGroups, identity.Groups) {
+		resps.Forbidden(c, "Your groups are not allowed to sign in")
+		return
+	}
+
+	linked, err := store.OIDC.GetIdentity(provider.ID, identity.Subject)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		resps.InternalServerError(c, "Failed to get identity")
+		return
+	}
+	var user *models.User
+	switch {
+	case state.LinkUserID != 0:
+		if linked != nil && linked.UserID != state.LinkUserID {
+			resps.Forbidden(c, "This identity is already linked to another user")
+			return
+		}
+		user, err = store.User.GetByID(state.LinkUserID)
+		if err != nil {
+			resps.NotFound(c, resps.TargetNotFound)
+			return
+		}
+		if linked == nil {
+			if err := store.OIDC.CreateIdentity(OIDC.newIdentity(provider, identity, user.ID)); err != nil {
+				resps.DBError(c, err, "Failed to link identity")
+				return
+			}
+		}
+	case linked != nil:
+		user = &linked.User
+	default:
+		user, err = OIDC.createUser(ctx, provider, identity)
+		if err != nil {
+			resps.Forbidden(c, err.Error())
+			return
+		}
+	}
+
+	if err := OIDC.promote(user, provider, identity); err != nil {
+		resps.InternalServerError(c, "Failed to update user")
+		return
+	}
+	if User.accountDisabled(c, user) {
+		return
+	}
+	// 启用两步验证的用户带着临时凭证回到前端完成第二因素
+	// Users with two-factor enabled return to the frontend with a challenge to complete the second factor
+	challenge, err := TwoFactor.challenge(user)
+	if err != nil {
+		resps.InternalServerError(c, "Failed to get two-factor status")
+		return
+	}
+	if challenge != "" {
+		c.Redirect(http.StatusFound, []byte(strings.TrimSuffix(config.FrontEndURL, "/")+"/login?two_factor_challenge="+url.QueryEscape(challenge)))
+		return
+	}
+	if _, _, err := User.startSession(ctx, c, user, "oidc"); err != nil {
+		resps.InternalServerError(c, err.Error())
+		return
+	}
+	c.Redirect(http.StatusFound, []byte(config.FrontEndURL))
+}
+
+// promote 属于管理员组的用户提升为管理员，不会自动降级
+// Promote users in admin groups to admins, never demoting automatically
+func (OIDCApi) promote(user *models.User, provider *models.OIDCConfig, identity *utils.ExternalIdentity) error {
+	if user.Role == constants.RoleAdmin || len(provider.AdminGroups) == 0 || !utils.OIDC.MatchGroups(provider.AdminGroups, identity.Groups) {
+		return nil
+	}
+	user.Role = constants.RoleAdmin
+	return store.User.Update(user)
+}
+
+func (OIDCApi) newIdentity(provider *models.OIDCConfig, identity *utils.ExternalIdentity, userID uint) *models.UserIdentity {
+	linked := &models.UserIdentity{
+		UserID:     userID,
+		ProviderID: provider.ID,
+		Subject:    identity.Subject,
+	}
+	if identity.Email != "" {
+		linked.Email = &identity.Email
+	}
+	return linked
+}
+
+// createUser 为首次登录的身份创建本地用户，邮箱已被占用时要求先登录再关联，避免通过提供方接管已有账号
+// Create a local user for a first-time identity; when the email is taken the user must sign in and link instead,
+// so a provider cannot take over an existing account
+func (OIDCApi) createUser(ctx context.Context, provider *models.OIDCConfig, identity *utils.ExternalIdentity) (*models.User, error) {
+	user := &models.User{Role: constants.RoleUser}
+	if identity.Email != "" && identity.EmailVerified {
+		if _, err := store.User.GetByEmail(identity.Email); err == nil {
+			return nil, errors.New("An account with this email already exists, sign in and link this provider from your account settings")
+		}
+		user.Email = &identity.Email
+		// 提供方已验证的邮箱无需再次验证 An address verified by the provider needs no further verification
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+	base := identity.Username
+	if base == "" {
+		base, _, _ = strings.Cut(identity.Email, "@")
+	}
+	base = sanitizeUsername(base)
+	if base == "" {
+		base = string(provider.Type) + "-" + identity.Subject
+	}
+	user.Name = base
+	for i := 2; store.User.IsNameExist(user.Name); i++ {
+		user.Name = fmt.Sprintf("%s-%d", base, i)
+	}
+	if identity.Name != "" {
+		user.DisplayName = &identity.Name
+	}
+	if identity.AvatarURL != "" {
+		user.AvatarURL = &identity.AvatarURL
+	}
+	if err := store.OIDC.CreateUserWithIdentity(user, OIDC.newIdentity(provider, identity, 0)); err != nil {
+		utils.Log.Ctx(ctx).Errorf("failed to create user from provider %d: %v", provider.ID, err)
+		return nil, errors.New("Failed to create user")
+	}
+	return user, nil
+}
+
+// sanitizeUsername 只保留小写字母、数字和 - _ .
+// Keep only lowercase letters, digits and - _ .
+func sanitizeUsername(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		}
+		return -1
+	}, name)
+}
+
+// Unlink 解除当前用户与提供方的关联，必须保留至少一种登录方式
+// Unlink the provider from the current user, at least one sign-in method must remain
+func (OIDCApi) Unlink(ctx context.Context, c *app.RequestContext) {
+	id, err := strconv.Atoi(c.Param("provider_id"))
+	if err != nil {
+		resps.BadRequest(c, resps.ParameterError)
+		return
+	}
+	user := middle.Auth.GetUser(ctx, c)
+	if user == nil {
+		return
+	}
+	identities, err := store.OIDC.ListIdentities(user.ID)
+	if err != nil {
+		resps.DBError(c, err, "Failed to get identities")
+		return
+	}
+	if user.Password == nil && len(identities) <= 1 {
+		resps.BadRequest(c, "Set a password or link another provider before unlinking the last one")
+		return
+	}
+	affected, err := store.OIDC.DeleteIdentity(user.ID, uint(id))
+	if err != nil {
+		resps.DBError(c, err, "Failed to unlink identity")
+		return
+	}
+	if affected == 0 {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	resps.Ok(c, resps.OK)
+}
+
+// Identities 获取当前用户关联的身份
+// Get the identities linked to the current user
+func (OIDCApi) Identities(ctx context.Context, c *app.RequestContext) {
+	user := middle.Auth.GetUser(ctx, c)
+	if user == nil {
+		return
+	}
+	identities, err := store.OIDC.ListIdentities(user.ID)
+	if err != nil {
+		resps.DBError(c, err, "Failed to get identities")
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"identities": func() (identityDTOs []IdentityDTO) {
+			for _, identity := range identities {
+				identityDTOs = append(identityDTOs, IdentityDTO{
+					ID:        identity.ID,
+					Provider:  OIDC.toDTO(&identity.Provider),
+					Email:     identity.Email,
+					CreatedAt: identity.CreatedAt,
+				})
+			}
+			return
+		}(),
+	})
+}
+
+// AdminList 管理员获取所有登录提供方
+// Admin lists all auth providers
+func (OIDCApi) AdminList(ctx context.Context, c *app.RequestContext) {
+	providers, err := store.OIDC.ListProviders(false)
+	if err != nil {
+		resps.DBError(c, err, "Failed to get providers")
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"providers": func() (providerDTOs []AuthProviderAdminDTO) {
+			for _, provider := range providers {
+				providerDTOs = append(providerDTOs, OIDC.toAdminDTO(&provider))
+			}
+			return
+		}(),
+	})
+}
+
+// AdminCreate 管理员创建登录提供方
+// Admin creates an auth provider
+func (OIDCApi) AdminCreate(ctx context.Context, c *app.RequestContext) {
+	req := AuthProviderReq{}
+	if !bindJSON(c, &req) {
+		return
+	}
+	provider := &models.OIDCConfig{
+		Type:          constants.AuthProviderOIDC,
+		AdminGroups:   []string{},
+		AllowedGroups: []string{"*"},
+		Enabled:       true,
+	}
+	OIDC.applyReq(provider, &req)
+	if err := OIDC.validate(provider); err != nil {
+		resps.BadRequest(c, err.Error())
+		return
+	}
+	if err := store.OIDC.CreateProvider(provider); err != nil {
+		resps.DBError(c, err, "Failed to create provider")
+		return
+	}
+	Audit.record(ctx, c, nil, constants.AuditAuthProviderUpdate, constants.AuditTargetAuthProvider, provider.ID, map[string]any{"operation": "create", "display_name": provider.DisplayName})
+	resps.Ok(c, resps.OK, map[string]any{
+		"provider": OIDC.toAdminDTO(provider),
+	})
+}
+
+// AdminUpdate 管理员更新登录提供方
+// Admin updates an auth provider
+func (OIDCApi) AdminUpdate(ctx context.Context, c *app.RequestContext) {
+	provider := OIDC.adminProvider(c)
+	if provider == nil {
+		return
+	}
+	req := AuthProviderReq{}
+	if !bindJSON(c, &req) {
+		return
+	}
+	OIDC.applyReq(provider, &req)
+	if err := OIDC.validate(provider); err != nil {
+		resps.BadRequest(c, err.Error())
+		return
+	}
+	if err := store.OIDC.UpdateProvider(provider); err != nil {
+		resps.DBError(c, err, "Failed to update provider")
+		return
+	}
+	Audit.record(ctx, c, nil, constants.AuditAuthProviderUpdate, constants.AuditTargetAuthProvider, provider.ID, map[string]any{"operation": "update", "display_name": provider.DisplayName})
+	resps.Ok(c, resps.OK, map[string]any{
+		"provider": OIDC.toAdminDTO(provider),
+	})
+}
+
+// AdminDelete 管理员删除登录提供方，关联的身份一并删除
+// Admin deletes an auth provider together with its linked identities
+func (OIDCApi) AdminDelete(ctx context.Context, c *app.RequestContext) {
+	provider := OIDC.adminProvider(c)
+	if provider == nil {
+		return
+	}
+	if err := store.OIDC.DeleteProvider(provider); err != nil {
+		resps.DBError(c, err, "Failed to delete provider")
+		return
+	}
+	Audit.record(ctx, c, nil, constants.AuditAuthProviderUpdate, constants.AuditTargetAuthProvider, provider.ID, map[string]any{"operation": "delete", "display_name": provider.DisplayName})
+	resps.Ok(c, resps.OK)
+}
+
+func (OIDCApi) adminProvider(c *app.RequestContext) *models.OIDCConfig {
+	id, err := strconv.Atoi(c.Param("provider_id"))
+	if err != nil {
+		resps.BadRequest(c, resps.ParameterError)
+		return nil
+	}
+	provider, err := store.OIDC.GetProviderByID(uint(id))
+	if err != nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return nil
+	}
+	return provider
+}
+
+func (OIDCApi) applyReq(provider *models.OIDCConfig, req *AuthProviderReq) {
+	if req.Type != nil {
+		provider.Type = *req.Type
+	}
+	if req.DisplayName != nil {
+		provider.DisplayName = *req.DisplayName
+	}
+	if req.Icon != nil {
+		provider.Icon = req.Icon
+	}
+	if req.ClientID != nil {
+		provider.ClientID = *req.ClientID
+	}
+	if req.ClientSecret != nil {
+		provider.ClientSecret = *req.ClientSecret
+	}
+	if req.OidcDiscoveryURL != nil {
+		provider.OidcDiscoveryURL = *req.OidcDiscoveryURL
+	}
+	if req.BaseURL != nil {
+		provider.BaseURL = *req.BaseURL
+	}
+	if req.GroupsClaim != nil {
+		provider.GroupsClaim = req.GroupsClaim
+	}
+	if req.AdminGroups != nil {
+		provider.AdminGroups = req.AdminGroups
+	}
+	if req.AllowedGroups != nil {
+		provider.AllowedGroups = req.AllowedGroups
+	}
+	if req.Enabled != nil {
+		provider.Enabled = *req.Enabled
+	}
+	if req.LDAP != nil {
+		provider.LDAP = *req.LDAP
+	}
+}
+
+func (OIDCApi) validate(provider *models.OIDCConfig) error {
+	if !provider.Type.Valid() {
+		return errors.New("invalid provider type")
+	}
+	if provider.Type == constants.AuthProviderLDAP {
+		return LDAP.validate(provider)
+	}
+	if provider.DisplayName == "" || provider.ClientID == "" || provider.ClientSecret == "" {
+		return errors.New("display_name, client_id and client_secret are required")
+	}
+	if provider.Type == constants.AuthProviderOIDC && provider.OidcDiscoveryURL == "" {
+		return errors.New("oidc_discovery_url is required for oidc providers")
+	}
+	return nil
+}
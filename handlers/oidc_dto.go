@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/models"
+)
+
+// AuthProviderDTO 登录页展示的提供方信息
+// Provider information shown on the login page
+type AuthProviderDTO struct {
+	ID          uint                       `json:"id"`           // 提供方ID Provider ID
+	Type        constants.AuthProviderType `json:"type"`         // 提供方类型 Provider type
+	DisplayName string                     `json:"display_name"` // 显示名称 Display name
+	Icon        *string                    `json:"icon"`         // 图标 Icon URL
+}
+
+// AuthProviderAdminDTO 管理员查看的提供方配置，不包含客户端密钥
+// Provider configuration seen by admins, without the client secret
+type AuthProviderAdminDTO struct {
+	AuthProviderDTO
+	ClientID         string               `json:"client_id"`          // 客户端ID Client ID
+	OidcDiscoveryURL string               `json:"oidc_discovery_url"` // OpenID自动发现URL OpenID discovery URL
+	BaseURL          string               `json:"base_url"`           // 自托管服务地址 Self-hosted base URL
+	GroupsClaim      *string              `json:"groups_claim"`       // 组声明 Groups claim
+	AdminGroups      []string             `json:"admin_groups"`       // 平台管理员组 Admin groups
+	AllowedGroups    []string             `json:"allowed_groups"`     // 允许登录的组 Allowed groups
+	Enabled          bool                 `json:"enabled"`            // 是否启用 Enabled
+	CallbackURL      string               `json:"callback_url"`       // 需要在提供方登记的回调地址 Callback URL to register at the provider
+	LDAP             *models.LDAPSettings `json:"ldap,omitempty"`     // LDAP 目录设置 LDAP directory settings
+}
+
+// AuthProviderReq 创建或更新登录提供方的请求参数，更新时为空的字段保持不变
+// Request parameters to create or update an auth provider, nil fields are kept on update
+type AuthProviderReq struct {
+	Type             *constants.AuthProviderType `json:"type"`               // 提供方类型 Provider type
+	DisplayName      *string                     `json:"display_name"`       // 显示名称 Display name
+	Icon             *string                     `json:"icon"`               // 图标 Icon URL
+	ClientID         *string                     `json:"client_id"`          // 客户端ID Client ID
+	ClientSecret     *string                     `json:"client_secret"`      // 客户端密钥 Client secret
+	OidcDiscoveryURL *string                     `json:"oidc_discovery_url"` // OpenID自动发现URL OpenID discovery URL
+	BaseURL          *string                     `json:"base_url"`           // 自托管服务地址 Self-hosted base URL
+	GroupsClaim      *string                     `json:"groups_claim"`       // 组声明 Groups claim
+	AdminGroups      []string                    `json:"admin_groups"`       // 平台管理员组 Admin groups
+	AllowedGroups    []string                    `json:"allowed_groups"`     // 允许登录的组 Allowed groups
+	Enabled          *bool                       `json:"enabled"`            // 是否启用 Enabled
+	LDAP             *models.LDAPSettings        `json:"ldap"`               // LDAP 目录设置，整体替换 LDAP directory settings, replaced as a whole
+}
+
+// LDAPLinkReq 关联目录账号的请求参数
+// Request parameters to link a directory account
+type LDAPLinkReq struct {
+	Username string `json:"username" binding:"required"` // 目录登录名 Directory login name
+	Password string `json:"password" binding:"required"` // 目录密码 Directory password
+}
+
+// IdentityDTO 用户关联的第三方身份
+// Third-party identity linked to the user
+type IdentityDTO struct {
+	ID        uint            `json:"id"`         // 身份ID Identity ID
+	Provider  AuthProviderDTO `json:"provider"`   // 提供方 Provider
+	Email     *string         `json:"email"`      // 提供方邮箱 Email at the provider
+	CreatedAt time.Time       `json:"created_at"` // 关联时间 Linked time
+}
@@ -2,13 +2,19 @@ package handlers
 
 import (
 	"context"
+	"errors"
+	"path/filepath"
 	"strconv"
+	"strings"
 
+	"github.com/LiteyukiStudio/spage/config"
 	"github.com/LiteyukiStudio/spage/constants"
 	"github.com/LiteyukiStudio/spage/middle"
 	"github.com/LiteyukiStudio/spage/models"
 	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/storage"
 	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/utils"
 	"github.com/cloudwego/hertz/pkg/app"
 )
 
@@ -18,8 +24,9 @@ var Org = OrgApi{}
 
 // OrganizationDTO 组织信息数据传输对象
 // Organization Information Data Transfer Object (DTO)
-func getOrg(ctx context.Context) *models.Organization {
-	org, ok := ctx.Value("userOrg").(*models.Organization)
+func getOrg(c *app.RequestContext) *models.Organization {
+	value, _ := c.Get("userOrg")
+	org, ok := value.(*models.Organization)
 	if !ok {
 		return nil
 	}
@@ -30,6 +37,9 @@ func getOrg(ctx context.Context) *models.Organization {
 // Organization permission check
 func (OrgApi) UserOrgAuth(ctx context.Context, c *app.RequestContext) {
 	user := middle.Auth.GetUser(ctx, c)
+	if user == nil {
+		return
+	}
 	// 当 id 为空的 POST 请求默认为 create
 	orgIdStr := c.Param("id")
 	if orgIdStr == "" && string(c.Method()) == "POST" {
@@ -48,22 +58,49 @@ func (OrgApi) UserOrgAuth(ctx context.Context, c *app.RequestContext) {
 		c.Abort()
 		return
 	}
-	// 判断权限 (GET 请求需要用户权限，其他请求需要管理员权限)
-	// Determine permissions (GET requests require user permissions, other requests require admin permissions)
-	var authType string
-	if string(c.Method()) == "GET" {
-		authType = "member"
-	} else {
-		authType = "owner"
-	}
-	if authType == store.Org.GetUserAuth(org, user.ID) {
-		context.WithValue(ctx, "userOrg", org)
+	// 判断权限，非成员视为组织不存在 Determine permissions, the organization is hidden from non-members
+	role := store.Org.GetUserAuth(org, user.ID)
+	if !role.Valid() {
+		resps.NotFound(c, resps.TargetNotFound)
+		c.Abort()
 		return
-	} else {
-		resps.BadRequest(c, resps.ParameterError)
+	}
+	if !role.AtLeast(Org.requiredRole(c)) {
+		resps.Forbidden(c, resps.PermissionDenied)
 		c.Abort()
 		return
 	}
+	c.Set("userOrg", org)
+	c.Set("orgRole", role)
+}
+
+// requiredRole 请求所需的最低组织角色：查看只需 viewer，修改组织资料和管理通知渠道需要 maintainer，删除组织和管理成员、团队、邀请需要 owner
+// Minimum organization role of the request: viewers read, maintainers edit the profile and manage notification channels, owners delete it and manage members, teams and invitations
+func (OrgApi) requiredRole(c *app.RequestContext) constants.OrgRole {
+	path := c.FullPath()
+	switch {
+	case strings.Contains(path, "/invitations"):
+		return constants.OrgRoleOwner
+	// 通知渠道的地址可能包含机器人的凭据 Channel targets may hold bot credentials
+	case strings.Contains(path, "/notifications"):
+		return constants.OrgRoleMaintainer
+	case string(c.Method()) == "GET" || string(c.Method()) == "HEAD":
+		return constants.OrgRoleViewer
+	case (string(c.Method()) == "PUT" || string(c.Method()) == "DELETE") && strings.HasSuffix(path, "/avatar"),
+		string(c.Method()) == "PUT" && (strings.HasSuffix(path, "/org/:id") || strings.HasSuffix(path, "/preview-template")):
+		return constants.OrgRoleMaintainer
+	}
+	return constants.OrgRoleOwner
+}
+
+// membersToDTO 带角色的成员列表
+// Member list with roles
+func (OrgApi) membersToDTO(members []store.Member) []MemberDTO {
+	memberDTOs := make([]MemberDTO, 0, len(members))
+	for _, member := range members {
+		memberDTOs = append(memberDTOs, MemberDTO{User: User.ToDTO(&member.User, false), Role: member.MemberRole})
+	}
+	return memberDTOs
 }
 
 // OrganizationDTO 组织信息数据传输对象
@@ -75,7 +112,7 @@ func (OrgApi) ToDTO(org *models.Organization) OrganizationDTO {
 		DisplayName:  org.DisplayName,
 		Email:        org.Email,
 		Description:  org.Description,
-		AvatarURL:    org.AvatarURL,
+		AvatarURL:    orgAvatar(org),
 		ProjectLimit: org.ProjectLimit,
 	}
 }
@@ -86,8 +123,7 @@ func (OrgApi) CreateOrganization(ctx context.Context, c *app.RequestContext) {
 	// 绑定参数
 	// Bind parameters
 	req := &CreateOrgReq{}
-	if err := c.BindAndValidate(&req); err != nil {
-		resps.BadRequest(c, resps.ParameterError)
+	if !bind(c, req) {
 		return
 	}
 	// 检验组织名称是否存在
@@ -105,11 +141,9 @@ func (OrgApi) CreateOrganization(ctx context.Context, c *app.RequestContext) {
 		Email:       req.Email,
 		Description: req.Description,
 		AvatarURL:   req.AvatarURL,
-		Members:     []*models.User{user},
-		Owners:      []models.User{*user},
 	}
-	if err := store.Org.CreateOrg(&org); err != nil {
-		resps.InternalServerError(c, err.Error())
+	if err := store.Org.CreateOrg(&org, user); err != nil {
+		resps.DBError(c, err, err.Error())
 		return
 	}
 	resps.Ok(c, resps.OK, map[string]any{
@@ -121,20 +155,31 @@ func (OrgApi) CreateOrganization(ctx context.Context, c *app.RequestContext) {
 // Update Organization Information
 func (OrgApi) UpdateOrganization(ctx context.Context, c *app.RequestContext) {
 	req := &UpdateOrgReq{}
-	if err := c.BindAndValidate(&req); err != nil {
-		resps.BadRequest(c, resps.ParameterError)
+	if !bind(c, req) {
 		return
 	}
-	org := getOrg(ctx)
+	org := getOrg(c)
 	// 更新 Update
 	org.DisplayName = req.DisplayName
 	org.Email = req.Email
-	org.Description = *req.Description
-	org.AvatarURL = req.AvatarURL
+	if req.Description != nil {
+		org.Description = *req.Description
+	}
+	// 原样提交读取到的地址时保留上传的标志 Submitting the URL as read keeps the uploaded logo
+	replacedAvatar, avatarReplaced := org.AvatarHash, avatarChanged(req.AvatarURL, orgAvatar(org))
 	if err := store.Org.UpdateOrg(org); err != nil {
-		resps.InternalServerError(c, err.Error())
+		resps.DBError(c, err, err.Error())
 		return
 	}
+	if avatarReplaced {
+		if err := store.Org.SetAvatar(org.ID, "", req.AvatarURL); err != nil {
+			resps.DBError(c, err, err.Error())
+			return
+		}
+		Avatar.remove(ctx, constants.OwnerTypeOrg, org.ID, replacedAvatar)
+		org.AvatarURL, org.AvatarHash = req.AvatarURL, ""
+	}
+	Audit.record(ctx, c, nil, constants.AuditOrgUpdate, constants.AuditTargetOrg, org.ID, map[string]any{"display_name": org.DisplayName, "email": org.Email})
 	resps.Ok(c, resps.OK, map[string]any{
 		"organization": Org.ToDTO(org),
 	})
@@ -143,18 +188,22 @@ func (OrgApi) UpdateOrganization(ctx context.Context, c *app.RequestContext) {
 // GetOrganizationProject 获取组织项目
 // Get Organization Projects
 func (OrgApi) GetOrganizationProject(ctx context.Context, c *app.RequestContext) {
-	req := &GetOrgProjectReq{}
-	if err := c.BindAndValidate(&req); err != nil {
-		resps.BadRequest(c, resps.ParameterError)
+	list, ok := getListQuery(c, "-id", projectSortFields...)
+	if !ok {
 		return
 	}
-	org := getOrg(ctx)
+	labels, ok := labelSelectors(c)
+	if !ok {
+		return
+	}
+	org := getOrg(c)
 	// 查询 Query
-	projects, total, err := store.Project.ListByOwner(constants.OwnerTypeOrg, strconv.Itoa(int(org.ID)), req.Page, req.Limit)
+	projects, total, err := store.Project.ListByOwner(ctx, constants.OwnerTypeOrg, strconv.Itoa(int(org.ID)), c.Query("q"), labels, list)
 	if err != nil {
-		resps.InternalServerError(c, "Failed to get projects")
+		resps.DBError(c, err, "Failed to get projects")
 		return
 	}
+	utils.Ctx.SetPageHeaders(c, list.Page, list.Limit, total)
 	resps.Ok(c, resps.OK, map[string]any{
 		"projects": func() (projectDTOs []ProjectDTO) {
 			for _, project := range projects {
@@ -169,50 +218,51 @@ func (OrgApi) GetOrganizationProject(ctx context.Context, c *app.RequestContext)
 // DeleteOrganization 删除组织
 // Delete Organization
 func (OrgApi) DeleteOrganization(ctx context.Context, c *app.RequestContext) {
-	org := getOrg(ctx)
+	org := getOrg(c)
 	// 删除组织
 	if err := store.Org.DeleteOrg(org); err != nil {
-		resps.InternalServerError(c, err.Error())
+		resps.DBError(c, err, err.Error())
 		return
 	}
+	Audit.record(ctx, c, nil, constants.AuditOrgDelete, constants.AuditTargetOrg, org.ID, map[string]any{"name": org.Name})
 	resps.Ok(c, resps.OK)
 }
 
 // GetOrganization 获取组织信息
 // Get Organization Information
 func (OrgApi) GetOrganization(ctx context.Context, c *app.RequestContext) {
-	org := getOrg(ctx)
+	org := getOrg(c)
+	orgDTO := Org.ToDTO(org)
+	value, _ := c.Get("orgRole")
+	orgDTO.Role, _ = value.(constants.OrgRole)
 	resps.Ok(c, resps.OK, map[string]any{
-		"organization": Org.ToDTO(org),
+		"organization": orgDTO,
 	})
 }
 
-// GetOrganizationUsers 获取组织用户
-// Get Organization Users
+// GetOrganizationUsers 获取组织成员及其角色
+// Get Organization members and their roles
 func (OrgApi) GetOrganizationUsers(ctx context.Context, c *app.RequestContext) {
-	org := getOrg(ctx)
+	org := getOrg(c)
+	members, err := store.Org.ListMembers(org.ID)
+	if err != nil {
+		resps.DBError(c, err, err.Error())
+		return
+	}
 	resps.Ok(c, resps.OK, map[string]any{
-		"members": func() (users []UserDTO) {
-			for _, user := range org.Members {
-				users = append(users, User.ToDTO(user, false))
-			}
-			return
-		},
-		"owners": func() (users []UserDTO) {
-			for _, user := range org.Owners {
-				users = append(users, User.ToDTO(&user, false))
-			}
-			return
-		},
+		"members": Org.membersToDTO(members),
 	})
 }
 
-// AddOrganizationUser 添加组织用户
-// Add Organization User
+// AddOrganizationUser 添加组织成员或修改其角色
+// Add an organization member or change their role
 func (OrgApi) AddOrganizationUser(ctx context.Context, c *app.RequestContext) {
 	req := &OrgUserReq{}
-	if err := c.BindAndValidate(&req); err != nil {
-		resps.BadRequest(c, resps.ParameterError)
+	if !bind(c, req) {
+		return
+	}
+	if !req.Role.Valid() {
+		resps.BadRequest(c, resps.RespMessageWithError(resps.ParameterError, errors.New("role is required")))
 		return
 	}
 	// 查询 Query
@@ -221,62 +271,89 @@ func (OrgApi) AddOrganizationUser(ctx context.Context, c *app.RequestContext) {
 		resps.NotFound(c, resps.TargetNotFound)
 		return
 	}
-	// 新增 Add
-	org := getOrg(ctx)
-	if req.Role == "member" {
-		org.Members = append(org.Members, user)
-		if err = store.Org.UpdateOrg(org); err != nil {
-			resps.InternalServerError(c, err.Error())
-			return
-		}
-	} else if req.Role == "owner" {
-		org.Owners = append(org.Owners, *user)
-		if err = store.Org.UpdateOrg(org); err != nil {
-			resps.InternalServerError(c, err.Error())
-			return
-		}
+	org := getOrg(c)
+	if !sameTenant(user.TenantID, org.TenantID) {
+		resps.BadRequest(c, "user belongs to another tenant")
+		return
 	}
+	if req.Role != constants.OrgRoleOwner && Org.isLastOwner(org, user.ID) {
+		resps.BadRequest(c, "organization must keep at least one owner")
+		return
+	}
+	if err := store.Org.SetMember(org.ID, user.ID, req.Role); err != nil {
+		resps.DBError(c, err, err.Error())
+		return
+	}
+	Audit.record(ctx, c, nil, constants.AuditOrgMemberUpdate, constants.AuditTargetOrg, org.ID, map[string]any{"user_id": user.ID, "role": req.Role})
 	resps.Ok(c, resps.OK)
 }
 
-// DeleteOrganizationUser 删除组织用户
-// Delete Organization User
+// DeleteOrganizationUser 移除组织成员
+// Remove an organization member
 func (OrgApi) DeleteOrganizationUser(ctx context.Context, c *app.RequestContext) {
 	req := &OrgUserReq{}
-	if err := c.BindAndValidate(&req); err != nil {
+	if !bind(c, req) {
+		return
+	}
+	org := getOrg(c)
+	if Org.isLastOwner(org, req.UserID) {
+		resps.BadRequest(c, "organization must keep at least one owner")
+		return
+	}
+	if err := store.Org.RemoveMember(org.ID, req.UserID); err != nil {
+		resps.DBError(c, err, err.Error())
+		return
+	}
+	Audit.record(ctx, c, nil, constants.AuditOrgMemberRemove, constants.AuditTargetOrg, org.ID, map[string]any{"user_id": req.UserID})
+	resps.Ok(c, resps.OK)
+}
+
+// isLastOwner 判断用户是否为组织唯一的所有者
+// Check whether the user is the only owner of the organization
+func (OrgApi) isLastOwner(org *models.Organization, userID uint) bool {
+	if store.Org.GetUserAuth(org, userID) != constants.OrgRoleOwner {
+		return false
+	}
+	count, err := store.Org.CountOwners(org.ID)
+	return err != nil || count <= 1
+}
+
+// UploadPreviewTemplate 上传组织的预览图模板背景
+// Upload the organization's preview image template background
+func (OrgApi) UploadPreviewTemplate(ctx context.Context, c *app.RequestContext) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		resps.BadRequest(c, resps.MissingParameter)
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
 		resps.BadRequest(c, resps.ParameterError)
 		return
 	}
-	// 查询 Query
-	user, err := store.User.GetByID(req.UserID)
-	if err != nil || user == nil {
-		resps.NotFound(c, resps.TargetNotFound)
+	err = utils.Preview.ValidateImage(file, config.OGMaxImageSize)
+	_ = file.Close()
+	if err != nil {
+		resps.BadRequest(c, resps.RespMessageWithError("invalid template image", err))
 		return
 	}
-	// 删除 Delete
-	org := getOrg(ctx)
-	if req.Role == "member" {
-		for i, member := range org.Members {
-			if member.ID == user.ID {
-				org.Members = append(org.Members[:i], org.Members[i+1:]...)
-				break
-			}
-		}
-		if err = store.Org.UpdateOrg(org); err != nil {
-			resps.InternalServerError(c, err.Error())
-			return
-		}
-	} else if req.Role == "owner" {
-		for i, owner := range org.Owners {
-			if owner.ID == user.ID {
-				org.Owners = append(org.Owners[:i], org.Owners[i+1:]...)
-				break
-			}
-			if err = store.Org.UpdateOrg(org); err != nil {
-				resps.InternalServerError(c, err.Error())
-				return
-			}
-		}
+	org := getOrg(c)
+	templatePath := storage.TemplatePrefix + strconv.Itoa(int(org.ID)) + filepath.Ext(fileHeader.Filename)
+	file, err = fileHeader.Open()
+	if err != nil {
+		resps.BadRequest(c, resps.ParameterError)
+		return
+	}
+	err = storage.Default.Put(ctx, templatePath, file, fileHeader.Size)
+	_ = file.Close()
+	if err != nil {
+		resps.InternalServerError(c, "save template file error")
+		return
+	}
+	org.PreviewTemplate = &templatePath
+	if err := store.Org.UpdateOrg(org); err != nil {
+		resps.DBError(c, err, err.Error())
+		return
 	}
 	resps.Ok(c, resps.OK)
 }
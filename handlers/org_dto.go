@@ -1,47 +1,73 @@
 package handlers
 
+import "github.com/LiteyukiStudio/spage/constants"
+
 // OrganizationDTO 组织信息数据传输对象
 // Organization Information Data Transfer Object (DTO)
 type OrganizationDTO struct {
-	ID           uint      `json:"id"`            // 组织ID Organization ID
-	Name         string    `json:"name"`          // 组织名称 Organization Name
-	DisplayName  *string   `json:"display_name"`  // 显示名称 Display Name
-	Email        *string   `json:"email"`         // 邮箱地址 Email Address
-	Description  string    `json:"description"`   // 描述信息 Description
-	AvatarURL    *string   `json:"avatar_url"`    // 头像URL Avatar URL
-	ProjectLimit int       `json:"project_limit"` // 项目数量限制 Project Limit
-	Members      []UserDTO `json:"members"`       // 组织成员 Members
-	Owners       []UserDTO `json:"owners"`        // 组织所有者 Owners
+	ID           uint              `json:"id"`             // 组织ID Organization ID
+	Name         string            `json:"name"`           // 组织名称 Organization Name
+	DisplayName  *string           `json:"display_name"`   // 显示名称 Display Name
+	Email        *string           `json:"email"`          // 邮箱地址 Email Address
+	Description  string            `json:"description"`    // 描述信息 Description
+	AvatarURL    *string           `json:"avatar_url"`     // 头像URL Avatar URL
+	ProjectLimit int               `json:"project_limit"`  // 项目数量限制 Project Limit
+	Role         constants.OrgRole `json:"role,omitempty"` // 当前用户在组织中的角色 Current user's role in the organization
+}
+
+// MemberDTO 带有组织或项目角色的成员
+// Member with an organization or project role
+type MemberDTO struct {
+	User UserDTO           `json:"user"` // 用户 User
+	Role constants.OrgRole `json:"role"` // 角色 Role
 }
 
 type CreateOrgReq struct {
-	Name        string  `json:"name" binding:"required"`         // 组织名称 Organization Name
-	DisplayName string  `json:"display_name" binding:"required"` // 显示名称 Display Name
-	Email       *string `json:"email"`                           // 邮箱地址 Email Address
-	Description string  `json:"description" binding:"required"`  // 描述信息 Description
-	AvatarURL   *string `json:"avatar_url"`                      // 头像URL Avatar URL
+	Name        string  `json:"name" validate:"required,max=63,slug,reserved"`               // 组织名称，为空时由显示名称生成 Organization Name, derived from the display name when empty
+	DisplayName string  `json:"display_name" binding:"required" validate:"required,max=128"` // 显示名称 Display Name
+	Email       *string `json:"email" validate:"email"`                                      // 邮箱地址 Email Address
+	Description string  `json:"description" binding:"required" validate:"max=1024"`          // 描述信息 Description
+	AvatarURL   *string `json:"avatar_url" validate:"url"`                                   // 头像URL Avatar URL
+}
+
+func (req *CreateOrgReq) normalize() {
+	req.Name = normalizeName(req.Name, &req.DisplayName)
 }
 
 // UpdateOrgReq 用于更新组织信息的请求体
 // Request body for updating organization information
 type UpdateOrgReq struct {
-	DisplayName *string `json:"display_name"` // 显示名称 Display Name
-	Email       *string `json:"email"`        // 邮箱地址 Email Address
-	Description *string `json:"description"`  // 描述信息 Description
-	AvatarURL   *string `json:"avatar_url"`   // 头像URL Avatar URL
+	DisplayName *string `json:"display_name" validate:"max=128"` // 显示名称 Display Name
+	Email       *string `json:"email" validate:"email"`          // 邮箱地址 Email Address
+	Description *string `json:"description" validate:"max=1024"` // 描述信息 Description
+	AvatarURL   *string `json:"avatar_url" validate:"url"`       // 头像URL Avatar URL
 }
 
-// GetOrgProjectReq 用于获取组织项目的请求体
-// Request body for getting organization projects
-type GetOrgProjectReq struct {
-	Page    int    `json:"page" binding:"required"`  // 页码 Page number
-	Limit   int    `json:"limit" binding:"required"` // 每页项目数量 Number of projects per page
-	OrderBy string // 排序字段 Sorting field
+// OrgUserReq 用于添加或删除组织用户的请求体，删除时不需要角色
+// Request body for adding or removing organization users, the role is not needed when removing
+type OrgUserReq struct {
+	UserID uint              `json:"user_id" binding:"required"` // 用户ID User ID
+	Role   constants.OrgRole `json:"role"`                       // 角色 Role
 }
 
-// OrgUserReq 用于添加或删除组织用户的请求体
-// Request body for adding or removing organization users
-type OrgUserReq struct {
-	UserID uint   `json:"user_id" binding:"required"` // 用户ID User ID
-	Role   string `json:"role" binding:"required"`    // 角色 Role
+// TeamDTO 团队信息数据传输对象
+// Team Information Data Transfer Object (DTO)
+type TeamDTO struct {
+	ID             uint   `json:"id"`              // 团队ID Team ID
+	OrganizationID uint   `json:"organization_id"` // 所属组织ID Organization ID
+	Name           string `json:"name"`            // 团队名称 Team name
+	Description    string `json:"description"`     // 团队描述 Team description
+}
+
+// TeamReq 创建或更新团队的请求体
+// Request body for creating or updating a team
+type TeamReq struct {
+	Name        string `json:"name" binding:"required" validate:"required,max=64"` // 团队名称 Team name
+	Description string `json:"description" validate:"max=1024"`                    // 团队描述 Team description
+}
+
+// TeamUserReq 添加或移除团队成员的请求体
+// Request body for adding or removing team members
+type TeamUserReq struct {
+	UserID uint `json:"user_id" binding:"required"` // 用户ID User ID
 }
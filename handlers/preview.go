@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/i18n"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/serve"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+type PreviewApi struct{}
+
+var Preview = PreviewApi{}
+
+func (PreviewApi) toDTO(site *models.Site, preview *models.SitePreview) PreviewDTO {
+	return PreviewDTO{
+		ID:        preview.ID,
+		Name:      preview.Name,
+		Host:      utils.Domain.PreviewHost(preview.Name, site.SubDomain, serve.BaseDomain(site)),
+		ReleaseID: preview.ReleaseID,
+		Tag:       preview.Release.Tag,
+		ExpiresAt: preview.ExpiresAt,
+		CreatedAt: preview.CreatedAt,
+		UpdatedAt: preview.UpdatedAt,
+	}
+}
+
+// save 创建或更新同名预览部署，同名预览之前的版本随之删除
+// Create or update the preview deployment of the same name, deleting the version it previously served
+func (PreviewApi) save(ctx context.Context, site *models.Site, release *models.SiteRelease, name string, ttl int) (*models.SitePreview, error) {
+	if ttl == 0 {
+		ttl = config.PreviewTTL
+	}
+	preview := models.SitePreview{
+		SiteID:    site.ID,
+		Name:      name,
+		ReleaseID: release.ID,
+		Release:   *release,
+		ExpiresAt: time.Now().Add(time.Duration(ttl) * time.Second),
+	}
+	replacedID, err := store.Preview.Save(&preview)
+	if err != nil {
+		return nil, errors.New("save preview error")
+	}
+	serve.SiteCache.ForgetPreview(ctx, site, name)
+	previewURL := "https://" + utils.Domain.PreviewHost(name, site.SubDomain, serve.BaseDomain(site)) + "/"
+	Webhook.emit(ctx, &site.Project, constants.WebhookDeploymentSucceeded,
+		i18n.Msg("Site %s deployed %s to preview %s: %s", site.Name, release.Tag, name, previewURL),
+		map[string]any{"site_id": site.ID, "site": site.Name, "release_id": release.ID, "tag": release.Tag, "preview": name, "url": previewURL})
+	if replacedID != 0 && replacedID != release.ID {
+		Preview.deleteVersion(ctx, site.ID, replacedID)
+	}
+	return &preview, nil
+}
+
+// List 获取站点的预览部署
+// List the preview deployments of the site
+func (PreviewApi) List(ctx context.Context, c *app.RequestContext) {
+	site := getSite(c)
+	if site == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	previews, err := store.Preview.ListBySite(site.ID)
+	if err != nil {
+		resps.DBError(c, err, "get previews error")
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"previews": func() (previewDTOs []PreviewDTO) {
+			for _, preview := range previews {
+				previewDTOs = append(previewDTOs, Preview.toDTO(site, &preview))
+			}
+			return
+		}(),
+	})
+}
+
+// Delete 提前删除预览部署及其版本
+// Delete a preview deployment and its version ahead of expiry
+func (PreviewApi) Delete(ctx context.Context, c *app.RequestContext) {
+	site := getSite(c)
+	if site == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	previewID, err := strconv.Atoi(c.Param("preview_id"))
+	if err != nil {
+		resps.BadRequest(c, resps.ParameterError)
+		return
+	}
+	preview, err := store.Preview.GetBySite(site.ID, uint(previewID))
+	if err != nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	if err := store.Preview.Delete(preview); err != nil {
+		resps.DBError(c, err, "delete preview error")
+		return
+	}
+	serve.SiteCache.ForgetPreview(ctx, site, preview.Name)
+	Preview.deleteVersion(ctx, site.ID, preview.ReleaseID)
+	resps.Ok(c, resps.OK)
+}
+
+// deleteVersion 删除预览不再使用的版本，已被激活为站点当前版本的保留
+// Delete a version no longer used by a preview, keeping it when it was promoted to the site's active version
+func (PreviewApi) deleteVersion(ctx context.Context, siteID, releaseID uint) {
+	release, err := store.Site.GetSiteRelease(siteID, releaseID)
+	if err != nil {
+		return
+	}
+	if err := store.Site.DeleteVersion(ctx, release); err != nil && !errors.Is(err, store.ErrReleaseActive) {
+		utils.Log.Ctx(ctx).Warnf("failed to delete preview release %d: %v", releaseID, err)
+	}
+}
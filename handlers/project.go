@@ -2,12 +2,18 @@ package handlers
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/LiteyukiStudio/spage/constants"
 	"github.com/LiteyukiStudio/spage/middle"
 	"github.com/LiteyukiStudio/spage/models"
 	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/serve"
 	"github.com/LiteyukiStudio/spage/store"
 	"github.com/cloudwego/hertz/pkg/app"
 )
@@ -17,6 +23,19 @@ type ProjectApi struct {
 
 var Project = ProjectApi{}
 
+// projectSortFields 项目列表可排序的字段 Sortable fields of project lists
+var projectSortFields = []string{"id", "created_at", "updated_at", "name"}
+
+// 项目标签的限制 Limits of project labels
+const (
+	maxProjectLabels     = 32
+	maxProjectLabelValue = 255
+)
+
+// projectLabelKey 标签键由小写字母、数字和 . _ / - 组成，以字母或数字开头
+// Label keys consist of lowercase letters, digits and . _ / -, starting with a letter or digit
+var projectLabelKey = regexp.MustCompile(`^[a-z0-9][a-z0-9._/-]{0,62}$`)
+
 // ProjectDTO 项目信息数据传输对象
 // Project Information Data Transfer Object (DTO)
 func (ProjectApi) toDTO(project *models.Project, full bool) ProjectDTO {
@@ -26,34 +45,123 @@ func (ProjectApi) toDTO(project *models.Project, full bool) ProjectDTO {
 		ID:          project.ID,
 		Name:        project.Name,
 		OwnerType:   project.OwnerType,
+		Visibility:  project.Visibility,
 	}
 	if full {
 		projectDto.OwnerID = project.OwnerID
-		projectDto.Owners = func([]models.User) (owners []UserDTO) {
-			for _, owner := range project.Owners {
-				owners = append(owners, User.ToDTO(&owner, false))
-			}
-			return
-		}(project.Owners)
 		projectDto.SiteLimit = project.SiteLimit
+		projectDto.PrimarySiteID = project.PrimarySiteID
+		projectDto.Host = serve.ProjectHost(project)
+		projectDto.MaxUploadSize = project.MaxUploadSize
+		projectDto.DirectoryListing = project.DirectoryListing
+		projectDto.ImmutableAssets = project.ImmutableAssets
+		projectDto.Protected = project.Protected
+	}
+	if len(project.Labels) > 0 {
+		projectDto.Labels = make(map[string]string, len(project.Labels))
+		for _, label := range project.Labels {
+			projectDto.Labels[label.Key] = label.Value
+		}
 	}
 	return projectDto
 }
 
+// parseLabels 校验请求中的项目标签，按键排序返回
+// Validate the project labels of a request, returned sorted by key
+func (ProjectApi) parseLabels(labels map[string]string) ([]models.ProjectLabel, error) {
+	if len(labels) > maxProjectLabels {
+		return nil, fmt.Errorf("at most %d labels are allowed", maxProjectLabels)
+	}
+	parsed := make([]models.ProjectLabel, 0, len(labels))
+	for key, value := range labels {
+		if !projectLabelKey.MatchString(key) {
+			return nil, fmt.Errorf("invalid label key %q", key)
+		}
+		if len(value) > maxProjectLabelValue {
+			return nil, fmt.Errorf("value of label %q is longer than %d bytes", key, maxProjectLabelValue)
+		}
+		parsed = append(parsed, models.ProjectLabel{Key: key, Value: value})
+	}
+	slices.SortFunc(parsed, func(a, b models.ProjectLabel) int { return strings.Compare(a.Key, b.Key) })
+	return parsed, nil
+}
+
+// labelSelectors 解析列表接口的 label 参数，可重复，格式为 key=value 或只有 key
+// Parse the label parameters of list endpoints, repeatable and formatted as key=value or just key
+func labelSelectors(c *app.RequestContext) ([]store.LabelSelector, bool) {
+	var selectors []store.LabelSelector
+	for _, raw := range c.QueryArgs().PeekAll("label") {
+		key, value, hasValue := strings.Cut(string(raw), "=")
+		if !projectLabelKey.MatchString(key) {
+			resps.BadRequestf(c, "invalid label selector %q", raw)
+			return nil, false
+		}
+		selector := store.LabelSelector{Key: key}
+		if hasValue {
+			selector.Value = &value
+		}
+		selectors = append(selectors, selector)
+	}
+	return selectors, true
+}
+
 // GetProject 获取项目信息
 // Get project information
-func getProject(ctx context.Context) *models.Project {
-	project, ok := ctx.Value("userProject").(*models.Project)
+func getProject(c *app.RequestContext) *models.Project {
+	value, _ := c.Get("userProject")
+	project, ok := value.(*models.Project)
 	if !ok {
 		return nil
 	}
 	return project
 }
 
+// getProjectRole 获取当前用户在项目中的角色
+// Get the current user's role in the project
+func getProjectRole(c *app.RequestContext) constants.OrgRole {
+	value, _ := c.Get("projectRole")
+	role, _ := value.(constants.OrgRole)
+	return role
+}
+
+// requiredRole 请求所需的最低项目角色：viewer 只读，member 可部署，maintainer 可修改设置和管理部署密钥，owner 可删除、转移项目并管理成员和团队
+// Minimum project role of the request: viewers read, members deploy, maintainers change settings and manage deploy keys, owners delete and transfer the project and manage members and teams
+func (ProjectApi) requiredRole(c *app.RequestContext) constants.OrgRole {
+	path := c.FullPath()
+	switch {
+	// webhook 的密钥和投递记录只对所有者可见 Webhook secrets and deliveries are only visible to owners
+	case strings.Contains(path, "/:id/webhooks"):
+		return constants.OrgRoleOwner
+	// CDN 集成保存服务商的 API 令牌 CDN integrations hold the provider's API token
+	case strings.Contains(path, "/:id/cdn"):
+		return constants.OrgRoleOwner
+	// 只有所有者可以发起、查看和取消转移 Only owners request, view and cancel transfers
+	case strings.HasSuffix(path, "/:id/transfer"):
+		return constants.OrgRoleOwner
+	// 保留策略决定哪些版本会被删除，所有人都可以查看 The retention policy decides which versions are removed, everyone may view it
+	case strings.HasSuffix(path, "/:id/retention") && string(c.Method()) != "GET":
+		return constants.OrgRoleOwner
+	// 导出包含站点的全部设置 Exports carry every setting of the sites
+	case strings.HasSuffix(path, "/:id/export"), strings.Contains(path, "/:id/deploy-keys"):
+		return constants.OrgRoleMaintainer
+	case string(c.Method()) == "GET" || string(c.Method()) == "HEAD" || string(c.Method()) == "PROPFIND":
+		return constants.OrgRoleViewer
+	case middle.DeployRequest(c):
+		return constants.OrgRoleMember
+	case string(c.Method()) == "DELETE" && strings.HasSuffix(path, "/project/:id"),
+		strings.HasSuffix(path, "/:id/members"), strings.HasSuffix(path, "/:id/teams"):
+		return constants.OrgRoleOwner
+	}
+	return constants.OrgRoleMaintainer
+}
+
 // UserProjectAuth 用户项目权限认证
 // User project authorization
 func (ProjectApi) UserProjectAuth(ctx context.Context, c *app.RequestContext) {
 	user := middle.Auth.GetUser(ctx, c)
+	if user == nil {
+		return
+	}
 	projectIdStr := c.Param("id")
 	// 当id为空默认为创建
 	if projectIdStr == "" && string(c.Method()) == "POST" {
@@ -72,50 +180,55 @@ func (ProjectApi) UserProjectAuth(ctx context.Context, c *app.RequestContext) {
 		c.Abort()
 		return
 	}
-	// 项目权限判断 Project authorization check
-	if store.Project.UserIsOwner(project, user.ID) {
-		context.WithValue(ctx, "userProject", project)
+	// 项目角色判断，无任何角色时视为不存在 Project role check, the project is hidden from users without any role
+	role := store.Project.UserRole(project, user.ID)
+	if !role.Valid() {
+		resps.NotFound(c, resps.TargetNotFound)
+		c.Abort()
 		return
 	}
-	// 组织权限判断 Organization authorization check
-	if project.OwnerType == constants.OwnerTypeOrg {
-		// 组织查询
-		org, err := store.Org.GetOrgById(project.OwnerID)
-		if err != nil || org == nil {
-			resps.InternalServerError(c, resps.ParameterError)
-			c.Abort()
-			return
-		}
-		// 请求类型判断
-		var authType string
-		if string(c.Method()) == "GET" {
-			authType = "member"
-		} else {
-			authType = "owner"
-		}
-		if authType == store.Org.GetUserAuth(org, user.ID) {
-			context.WithValue(ctx, "userOrg", org)
-			context.WithValue(ctx, "userProject", project)
-			return
-		} else {
-			resps.BadRequest(c, resps.ParameterError)
-			c.Abort()
-			return
-		}
-	} else {
-		resps.BadRequest(c, resps.ParameterError)
+	if !role.AtLeast(Project.requiredRole(c)) {
+		resps.Forbidden(c, resps.PermissionDenied)
 		c.Abort()
+		return
 	}
+	c.Set("userProject", project)
+	c.Set("projectRole", role)
 }
 
 // Create 创建项目
 // Create project
 func (ProjectApi) Create(ctx context.Context, c *app.RequestContext) {
 	req := CreateProjectReq{}
-	if err := c.BindAndValidate(&req); err != nil {
-		resps.BadRequest(c, resps.ParameterError)
+	if !bind(c, &req) {
 		return
 	}
+	project := Project.create(ctx, c, req)
+	if project == nil {
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"project": Project.toDTO(project, true),
+	})
+}
+
+// create 校验所有者权限、配额和标签后创建项目，失败时写入错误响应并返回 nil
+// Create a project after checking owner permissions, quota and labels, writing the error response and returning nil on failure
+func (ProjectApi) create(ctx context.Context, c *app.RequestContext, req CreateProjectReq) *models.Project {
+	project := Project.prepare(ctx, c, req)
+	if project == nil {
+		return nil
+	}
+	if err := store.Project.Create(project); err != nil {
+		resps.DBError(c, err, resps.ParameterError)
+		return nil
+	}
+	return project
+}
+
+// prepare 校验所有者权限、配额和标签并构造待创建的项目，失败时写入错误响应并返回 nil
+// Check owner permissions, quota and labels and build the project to create, writing the error response and returning nil on failure
+func (ProjectApi) prepare(ctx context.Context, c *app.RequestContext, req CreateProjectReq) *models.Project {
 	user := middle.Auth.GetUser(ctx, c)
 	// 校验权限 Check permissions
 	if req.OwnerType == constants.OwnerTypeOrg {
@@ -124,10 +237,11 @@ func (ProjectApi) Create(ctx context.Context, c *app.RequestContext) {
 		org, err := store.Org.GetOrgById(req.OwnerID)
 		if err != nil || org == nil {
 			resps.InternalServerError(c, resps.ParameterError)
-			return
+			return nil
 		}
-		if store.Org.GetUserAuth(org, user.ID) != "member" {
+		if !store.Org.GetUserAuth(org, user.ID).AtLeast(constants.OrgRoleMaintainer) {
 			resps.Forbidden(c, resps.PermissionDenied)
+			return nil
 		}
 	} else if req.OwnerType == constants.OwnerTypeUser {
 		// 如果为用户，仅允许为自己添加
@@ -135,7 +249,19 @@ func (ProjectApi) Create(ctx context.Context, c *app.RequestContext) {
 		req.OwnerID = user.ID
 	} else {
 		resps.BadRequest(c, resps.ParameterError)
-		return
+		return nil
+	}
+	if err := Quota.checkProjects(req.OwnerType, req.OwnerID); errors.Is(err, errQuotaExceeded) {
+		resps.Forbidden(c, err.Error())
+		return nil
+	} else if err != nil {
+		resps.InternalServerError(c, err.Error())
+		return nil
+	}
+	labels, err := Project.parseLabels(req.Labels)
+	if err != nil {
+		resps.BadRequest(c, err.Error())
+		return nil
 	}
 	project := &models.Project{
 		Description: req.Description,
@@ -143,170 +269,297 @@ func (ProjectApi) Create(ctx context.Context, c *app.RequestContext) {
 		Name:        req.Name,
 		OwnerID:     req.OwnerID,
 		OwnerType:   req.OwnerType,
-		Owners:      []models.User{*user},
+		Labels:      labels,
 	}
-	if err := store.Project.Create(project); err != nil {
-		resps.InternalServerError(c, resps.ParameterError)
-		return
+	if req.Visibility != nil {
+		project.Visibility = *req.Visibility
 	}
-	resps.Ok(c, resps.OK, map[string]any{
-		"project": Project.toDTO(project, true),
-	})
+	return project
 }
 
 // Update 更新项目
 // Update project
 func (ProjectApi) Update(ctx context.Context, c *app.RequestContext) {
 	req := UpdateProjectReq{}
-	if err := c.BindAndValidate(&req); err != nil {
-		resps.BadRequest(c, resps.ParameterError)
+	if !bind(c, &req) {
 		return
 	}
-	project := getProject(ctx)
+	project := getProject(c)
 	if project == nil {
 		resps.NotFound(c, resps.TargetNotFound)
 		return
 	}
+	var labels []models.ProjectLabel
+	if req.Labels != nil {
+		var err error
+		if labels, err = Project.parseLabels(*req.Labels); err != nil {
+			resps.BadRequest(c, err.Error())
+			return
+		}
+	}
+	// 主站点必须属于该项目 The primary site must belong to the project
+	var primarySiteID *uint
+	if req.PrimarySiteID != nil && *req.PrimarySiteID != 0 {
+		site, err := store.Site.GetByID(*req.PrimarySiteID)
+		if err != nil || site.ProjectID != project.ID {
+			resps.BadRequest(c, "primary_site_id must be a site of the project")
+			return
+		}
+		primarySiteID = &site.ID
+	}
 	// 更新数据 Update data
-	project.Description = *req.Description
+	if req.Description != nil {
+		project.Description = *req.Description
+	}
 	project.DisplayName = req.DisplayName
 	project.Name = *req.Name
+	if req.Visibility != nil {
+		project.Visibility = *req.Visibility
+	}
 	if err := store.Project.Update(project); err != nil {
-		resps.InternalServerError(c, resps.ParameterError)
+		resps.DBError(c, err, resps.ParameterError)
 		return
 	}
+	if req.PrimarySiteID != nil {
+		if err := store.Project.SetPrimarySite(project, primarySiteID); err != nil {
+			resps.DBError(c, err, "Failed to set primary site")
+			return
+		}
+	}
+	if req.DirectoryListing != nil {
+		if err := store.Project.SetDirectoryListing(project, *req.DirectoryListing); err != nil {
+			resps.DBError(c, err, "Failed to set directory listing")
+			return
+		}
+	}
+	if req.ImmutableAssets != nil {
+		if err := store.Project.SetImmutableAssets(project, *req.ImmutableAssets); err != nil {
+			resps.DBError(c, err, "Failed to set immutable assets")
+			return
+		}
+	}
+	if req.Protected != nil {
+		if err := store.Project.SetProtected(project, *req.Protected); err != nil {
+			resps.DBError(c, err, "Failed to set protection")
+			return
+		}
+	}
+	if req.Labels != nil {
+		if err := store.Project.SetLabels(project, labels); err != nil {
+			resps.DBError(c, err, "Failed to update labels")
+			return
+		}
+	} else if err := store.Project.LoadLabels(*project); err != nil {
+		resps.DBError(c, err, "Failed to get labels")
+		return
+	}
+	serve.SiteCache.ForgetProject(ctx, project.ID)
+	Audit.record(ctx, c, nil, constants.AuditProjectUpdate, constants.AuditTargetProject, project.ID, map[string]any{"name": project.Name, "display_name": project.DisplayName, "directory_listing": req.DirectoryListing, "immutable_assets": req.ImmutableAssets, "protected": req.Protected})
 	resps.Ok(c, resps.OK, map[string]any{
 		"project": Project.toDTO(project, true),
 	})
 }
 
-// Delete 删除项目
-// Delete project
+// Delete 删除项目，项目及其站点移入回收站，保留期内可以恢复
+// Delete project, moving it and its sites to the trash where they can be restored within the retention window
 func (ProjectApi) Delete(ctx context.Context, c *app.RequestContext) {
-	project := getProject(ctx)
+	project := getProject(c)
 	if project == nil {
 		resps.NotFound(c, resps.TargetNotFound)
 		return
 	}
 	if err := store.Project.Delete(project); err != nil {
-		resps.InternalServerError(c, resps.ParameterError)
+		resps.DBError(c, err, resps.ParameterError)
 		return
 	}
+	serve.SiteCache.ForgetProject(ctx, project.ID)
+	Audit.record(ctx, c, nil, constants.AuditProjectDelete, constants.AuditTargetProject, project.ID, map[string]any{"name": project.Name, "owner_type": project.OwnerType, "owner_id": project.OwnerID})
 	resps.Ok(c, resps.OK)
 }
 
 // Info 获取项目信息
 // Get project information
 func (ProjectApi) Info(ctx context.Context, c *app.RequestContext) {
-	project := getProject(ctx)
+	project := getProject(c)
 	if project == nil {
 		resps.NotFound(c, resps.TargetNotFound)
 		return
 	}
+	if err := store.Project.LoadLabels(*project); err != nil {
+		resps.DBError(c, err, "Failed to get labels")
+		return
+	}
+	projectDTO := Project.toDTO(project, true)
+	projectDTO.Role = getProjectRole(c)
 	resps.Ok(c, resps.OK, map[string]any{
-		"project": Project.toDTO(project, true),
+		"project": projectDTO,
 	})
 }
 
-// GetOwners 获取项目所有者列表
-// Get project owner list
-func (ProjectApi) GetOwners(ctx context.Context, c *app.RequestContext) {
-	project := getProject(ctx)
+// Members 获取直接授予角色的项目成员
+// List the project members granted a role directly
+func (ProjectApi) Members(ctx context.Context, c *app.RequestContext) {
+	project := getProject(c)
 	if project == nil {
 		resps.NotFound(c, resps.TargetNotFound)
 		return
 	}
+	members, err := store.Project.ListMembers(project.ID)
+	if err != nil {
+		resps.DBError(c, err, "get members error")
+		return
+	}
 	resps.Ok(c, resps.OK, map[string]any{
-		"owners": func([]models.User) (owners []UserDTO) {
-			for _, owner := range project.Owners {
-				owners = append(owners, User.ToDTO(&owner, false))
-			}
-			return
-		}(project.Owners),
+		"members": Org.membersToDTO(members),
 	})
 }
 
-// AddOwner 添加项目所有者
-// Add project owner
-func (ProjectApi) AddOwner(ctx context.Context, c *app.RequestContext) {
-	project := getProject(ctx)
+// SetMember 添加项目成员或修改其角色
+// Add a project member or change their role
+func (ProjectApi) SetMember(ctx context.Context, c *app.RequestContext) {
+	project := getProject(c)
 	if project == nil {
 		resps.NotFound(c, resps.TargetNotFound)
 		return
 	}
 	req := ProjectUserReq{}
-	if err := c.BindAndValidate(&req); err != nil {
-		resps.BadRequest(c, resps.ParameterError)
+	if !bind(c, &req) {
 		return
 	}
-	// 查询用户	查询用户
+	if !req.Role.Valid() {
+		resps.BadRequest(c, resps.RespMessageWithError(resps.ParameterError, errors.New("role is required")))
+		return
+	}
+	// 查询用户 Query user
 	user, err := store.User.GetByID(req.UserID)
 	if err != nil || user == nil {
 		resps.NotFound(c, resps.TargetNotFound)
 		return
 	}
-	// 判断用户是否已存在权限列表 Check if the user already exists in the permission list
-	for _, owner := range project.Owners {
-		if owner.ID == user.ID {
-			resps.BadRequest(c, "User already exists in the permission list")
-			return
-		}
+	if !sameTenant(user.TenantID, project.TenantID) {
+		resps.BadRequest(c, "user belongs to another tenant")
+		return
 	}
-	// 添加用户	Add user
-	if err := store.Project.AddOwner(project, user); err != nil {
-		resps.InternalServerError(c, resps.ParameterError)
+	if err := store.Project.SetMember(project.ID, user.ID, req.Role); err != nil {
+		resps.DBError(c, err, resps.ParameterError)
 		return
 	}
-	resps.Ok(c, resps.OK, map[string]any{
-		"project": Project.toDTO(project, true),
-	})
+	Audit.record(ctx, c, nil, constants.AuditProjectMemberUpdate, constants.AuditTargetProject, project.ID, map[string]any{"user_id": user.ID, "role": req.Role})
+	resps.Ok(c, resps.OK)
 }
 
-// DeleteOwner 删除项目所有者
-// Delete project owner
-func (ProjectApi) DeleteOwner(ctx context.Context, c *app.RequestContext) {
-	project := getProject(ctx)
+// RemoveMember 移除项目成员
+// Remove a project member
+func (ProjectApi) RemoveMember(ctx context.Context, c *app.RequestContext) {
+	project := getProject(c)
 	if project == nil {
 		resps.NotFound(c, resps.TargetNotFound)
 		return
 	}
 	req := ProjectUserReq{}
-	if err := c.BindAndValidate(&req); err != nil {
-		resps.BadRequest(c, resps.ParameterError)
+	if !bind(c, &req) {
 		return
 	}
-	// 查询用户 Query user
-	user, err := store.User.GetByID(req.UserID)
-	if err != nil || user == nil {
+	if err := store.Project.RemoveMember(project.ID, req.UserID); err != nil {
+		resps.DBError(c, err, resps.ParameterError)
+		return
+	}
+	Audit.record(ctx, c, nil, constants.AuditProjectMemberRemove, constants.AuditTargetProject, project.ID, map[string]any{"user_id": req.UserID})
+	resps.Ok(c, resps.OK)
+}
+
+// Teams 获取被授予项目角色的团队
+// List the teams granted a role on the project
+func (ProjectApi) Teams(ctx context.Context, c *app.RequestContext) {
+	project := getProject(c)
+	if project == nil {
 		resps.NotFound(c, resps.TargetNotFound)
 		return
 	}
-	// 删除用户 Delete user
-	if err := store.Project.DeleteOwner(project, user); err != nil {
-		resps.InternalServerError(c, resps.ParameterError)
+	grants, err := store.Project.ListTeams(project.ID)
+	if err != nil {
+		resps.DBError(c, err, "get teams error")
 		return
 	}
 	resps.Ok(c, resps.OK, map[string]any{
-		"project": Project.toDTO(project, true),
+		"teams": func() (teamDTOs []ProjectTeamDTO) {
+			for _, grant := range grants {
+				teamDTOs = append(teamDTOs, ProjectTeamDTO{Team: Team.toDTO(&grant.Team), Role: grant.Role})
+			}
+			return
+		}(),
 	})
 }
 
+// SetTeam 为组织内的团队授予项目角色
+// Grant a team of the organization a role on the project
+func (ProjectApi) SetTeam(ctx context.Context, c *app.RequestContext) {
+	project := getProject(c)
+	if project == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	req := ProjectTeamReq{}
+	if !bind(c, &req) {
+		return
+	}
+	if !req.Role.Valid() {
+		resps.BadRequest(c, resps.RespMessageWithError(resps.ParameterError, errors.New("role is required")))
+		return
+	}
+	// 团队必须属于项目所属的组织 The team must belong to the organization owning the project
+	if project.OwnerType != constants.OwnerTypeOrg {
+		resps.BadRequest(c, "Only organization projects can be granted to teams")
+		return
+	}
+	team, err := store.Team.GetByOrg(project.OwnerID, req.TeamID)
+	if err != nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	if err := store.Project.SetTeam(project.ID, team.ID, req.Role); err != nil {
+		resps.DBError(c, err, resps.ParameterError)
+		return
+	}
+	Audit.record(ctx, c, nil, constants.AuditProjectTeamUpdate, constants.AuditTargetProject, project.ID, map[string]any{"team_id": team.ID, "role": req.Role})
+	resps.Ok(c, resps.OK)
+}
+
+// RemoveTeam 撤销团队的项目角色
+// Revoke a team's role on the project
+func (ProjectApi) RemoveTeam(ctx context.Context, c *app.RequestContext) {
+	project := getProject(c)
+	if project == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	req := ProjectTeamReq{}
+	if !bind(c, &req) {
+		return
+	}
+	if err := store.Project.RemoveTeam(project.ID, req.TeamID); err != nil {
+		resps.DBError(c, err, resps.ParameterError)
+		return
+	}
+	Audit.record(ctx, c, nil, constants.AuditProjectTeamRemove, constants.AuditTargetProject, project.ID, map[string]any{"team_id": req.TeamID})
+	resps.Ok(c, resps.OK)
+}
+
 // GetSites 获取站点列表
 // Get site list
 func (ProjectApi) GetSites(ctx context.Context, c *app.RequestContext) {
 	req := GetSiteListReq{}
-	if err := c.BindAndValidate(&req); err != nil {
-		resps.BadRequest(c, resps.ParameterError)
+	if !bind(c, &req) {
 		return
 	}
-	project := getProject(ctx)
+	project := getProject(c)
 	if project == nil {
 		resps.NotFound(c, resps.TargetNotFound)
 		return
 	}
 	sites, total, err := store.Project.GetSiteList(project, req.Page, req.Limit)
 	if err != nil {
-		resps.InternalServerError(c, "Failed to get sites")
+		resps.DBError(c, err, "Failed to get sites")
 		return
 	}
 	resps.Ok(c, resps.OK, map[string]any{
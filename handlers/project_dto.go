@@ -1,41 +1,90 @@
 package handlers
 
+import "github.com/LiteyukiStudio/spage/constants"
+
 // ProjectDTO 项目信息数据传输对象
 // Project Information Data Transfer Object (DTO)
 type ProjectDTO struct {
-	ID          uint      `json:"id"`           // 项目ID Project ID
-	Name        string    `json:"name"`         // 项目名称 Project Name
-	DisplayName *string   `json:"display_name"` // 项目显示名称 Project Display Name
-	Description string    `json:"description"`  // 项目描述 Project Description
-	OwnerType   string    `json:"owner_type"`   // 项目拥有者类型 Project Owner Type
-	OwnerID     uint      `json:"owner_id"`     // 项目拥有者ID Project Owner ID
-	Owners      []UserDTO `json:"owners"`       // 项目拥有者列表 Project Owner List
-	SiteLimit   int       `json:"site_limit"`   // 项目站点数量限制 Project Site Limit
+	ID          uint                 `json:"id"`             // 项目ID Project ID
+	Name        string               `json:"name"`           // 项目名称 Project Name
+	DisplayName *string              `json:"display_name"`   // 项目显示名称 Project Display Name
+	Description string               `json:"description"`    // 项目描述 Project Description
+	OwnerType   string               `json:"owner_type"`     // 项目拥有者类型 Project Owner Type
+	OwnerID     uint                 `json:"owner_id"`       // 项目拥有者ID Project Owner ID
+	SiteLimit   int                  `json:"site_limit"`     // 项目站点数量限制 Project Site Limit
+	Visibility  constants.Visibility `json:"visibility"`     // 项目可见性 Project Visibility
+	Role        constants.OrgRole    `json:"role,omitempty"` // 当前用户在项目中的角色 Current user's role in the project
+
+	PrimarySiteID *uint  `json:"primary_site_id,omitempty"` // 通过项目主机名访问的站点，为空时使用最早创建的站点 Site served at the project host, the oldest site when empty
+	Host          string `json:"host,omitempty"`            // 项目主机名，未配置 serve.project-domain 时为空 Project host, empty without serve.project-domain
+	MaxUploadSize *int64 `json:"max_upload_size,omitempty"` // 管理员为项目设置的单次上传最大字节数 Largest single upload in bytes set by an administrator
+
+	DirectoryListing bool `json:"directory_listing"` // 没有 index.html 的目录是否显示目录索引 Whether directories without index.html show a directory index
+	ImmutableAssets  bool `json:"immutable_assets"`  // 文件名带内容哈希的资源是否长期缓存 Whether assets with a content hash in their file name are cached for long
+
+	Protected bool `json:"protected"` // 新部署是否需要维护者批准后才能上线 Whether new deployments only go live once a maintainer approves them
+
+	Labels map[string]string `json:"labels,omitempty"` // 项目标签 Project labels
 }
 
 // CreateProjectReq 创建项目请求参数
 // Create Project Request Parameters
 type CreateProjectReq struct {
-	Name        string  `json:"name" binding:"required"`                                         // 项目名称 Project Name
-	DisplayName *string `json:"display_name"`                                                    // 项目显示名称 Project Display Name
-	Description string  `json:"description"`                                                     // 项目描述 Project Description
-	OwnerType   string  `json:"owner_type" binding:"required"  vd:"in($,'user','organization')"` // 项目拥有者类型 Project Owner Type
-	OwnerID     uint    `json:"owner_id" binding:"required"`                                     // 项目拥有者ID Project Owner ID
+	Name        string                `json:"name" validate:"required,max=63,slug,reserved"`                   // 项目名称，可用作子域名，为空时由显示名称生成 Project Name, usable as a subdomain and derived from the display name when empty
+	DisplayName *string               `json:"display_name" validate:"max=128"`                                 // 项目显示名称 Project Display Name
+	Description string                `json:"description" validate:"max=1024"`                                 // 项目描述 Project Description
+	OwnerType   string                `json:"owner_type" binding:"required"  vd:"in($,'user','organization')"` // 项目拥有者类型 Project Owner Type
+	OwnerID     uint                  `json:"owner_id" binding:"required"`                                     // 项目拥有者ID Project Owner ID
+	Visibility  *constants.Visibility `json:"visibility"`                                                      // 项目可见性 Project Visibility
+	Labels      map[string]string     `json:"labels"`                                                          // 项目标签 Project labels
+}
 
+func (req *CreateProjectReq) normalize() {
+	req.Name = normalizeName(req.Name, req.DisplayName)
 }
 
 // UpdateProjectReq 更新项目请求参数
 // Update Project Request Parameters
 type UpdateProjectReq struct {
-	Name        *string `json:"name"`         // 项目名称 Project Name
-	DisplayName *string `json:"display_name"` // 项目显示名称 Project Display Name
-	Description *string `json:"description"`  // 项目描述 Project Description
+	Name        *string               `json:"name" validate:"required,max=63,slug,reserved"` // 项目名称 Project Name
+	DisplayName *string               `json:"display_name" validate:"max=128"`               // 项目显示名称 Project Display Name
+	Description *string               `json:"description" validate:"max=1024"`               // 项目描述 Project Description
+	Visibility  *constants.Visibility `json:"visibility"`                                    // 项目可见性 Project Visibility
+	Labels      *map[string]string    `json:"labels"`                                        // 项目标签，设置时替换全部标签 Project labels, replacing all of them when set
+
+	PrimarySiteID *uint `json:"primary_site_id"` // 通过项目主机名访问的站点，为 0 时恢复为最早创建的站点 Site served at the project host, 0 falls back to the oldest site
+
+	DirectoryListing *bool `json:"directory_listing"` // 没有 index.html 的目录是否显示自动生成的目录索引 Whether directories without index.html show a generated directory index
+	ImmutableAssets  *bool `json:"immutable_assets"`  // 文件名带内容哈希的资源是否以 immutable 缓存一年 Whether assets with a content hash in their file name are cached immutable for a year
+
+	Protected *bool `json:"protected"` // 新部署是否需要维护者批准后才能上线 Whether new deployments only go live once a maintainer approves them
+}
+
+func (req *UpdateProjectReq) normalize() {
+	if req.Name != nil {
+		*req.Name = normalizeName(*req.Name, nil)
+	}
 }
 
-// ProjectUserReq 项目用户请求参数
-// Project User Request Parameters
+// ProjectUserReq 项目用户请求参数，移除成员时不需要角色
+// Project User Request Parameters, the role is not needed when removing a member
 type ProjectUserReq struct {
-	UserID uint `json:"user_id" binding:"required"` // 用户ID User ID
+	UserID uint              `json:"user_id" binding:"required"` // 用户ID User ID
+	Role   constants.OrgRole `json:"role"`                       // 项目角色 Project role
+}
+
+// ProjectTeamReq 项目团队请求参数，撤销授权时不需要角色
+// Project Team Request Parameters, the role is not needed when revoking a grant
+type ProjectTeamReq struct {
+	TeamID uint              `json:"team_id" binding:"required"` // 团队ID Team ID
+	Role   constants.OrgRole `json:"role"`                       // 授予团队成员的项目角色 Project role granted to the team's members
+}
+
+// ProjectTeamDTO 被授予项目角色的团队
+// Team granted a role on the project
+type ProjectTeamDTO struct {
+	Team TeamDTO           `json:"team"` // 团队 Team
+	Role constants.OrgRole `json:"role"` // 项目角色 Project role
 }
 
 // GetProjectListReq 获取项目列表请求参数
@@ -0,0 +1,420 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/i18n"
+	"github.com/LiteyukiStudio/spage/middle"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+	"gorm.io/gorm"
+)
+
+type QuotaApi struct{}
+
+// Quota 用户和组织的配额，限制项目数量、单次部署大小和存储用量，默认值来自配置文件，管理员可为单个用户或组织覆盖
+// Quotas of users and organizations limiting the project count, deployment size and storage, defaults come from the configuration and administrators may override them per user or organization
+var Quota = QuotaApi{}
+
+// quotaStorageAlert 存储用量警告的键
+// Key of the storage usage warning
+const quotaStorageAlert = "quota.storage"
+
+var (
+	errQuotaExceeded = errors.New("quota exceeded")
+	errQuotaCheck    = errors.New("check quota error")
+)
+
+// limits 所有者生效的配额，所属租户的默认配额覆盖配置文件中的默认值，管理员单独设置的字段再覆盖前两者
+// Effective quota of an owner, the defaults of its tenant override the configured ones and fields set by an administrator override both
+func (QuotaApi) limits(ownerType string, ownerID uint) (QuotaDTO, error) {
+	dto := QuotaDTO{
+		OwnerType:         ownerType,
+		OwnerID:           ownerID,
+		MaxProjects:       int64(config.QuotaMaxProjects),
+		MaxStorage:        int64(config.QuotaMaxStorage) << 20,
+		MaxDeploymentSize: int64(config.QuotaMaxDeploymentSize) << 20,
+		MaxUploadSize:     int64(config.UploadMaxSize) << 20,
+	}
+	// 租户的默认配额覆盖配置文件中的默认值 Default quotas of the tenant override the configured defaults
+	tenant, err := store.Tenant.OfOwner(ownerType, ownerID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return dto, err
+	}
+	if tenant != nil {
+		applyLimits(&dto, tenant.MaxProjects, tenant.MaxStorage, tenant.MaxDeploymentSize, tenant.MaxUploadSize)
+	}
+	quota, err := store.Quota.Get(ownerType, ownerID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return dto, nil
+	}
+	if err != nil {
+		return dto, err
+	}
+	dto.Custom = true
+	applyLimits(&dto, quota.MaxProjects, quota.MaxStorage, quota.MaxDeploymentSize, quota.MaxUploadSize)
+	return dto, nil
+}
+
+// applyLimits 用设置了的字段覆盖配额
+// Override the quota with the fields that are set
+func applyLimits(dto *QuotaDTO, maxProjects, maxStorage, maxDeploymentSize, maxUploadSize *int64) {
+	if maxProjects != nil {
+		dto.MaxProjects = *maxProjects
+	}
+	if maxStorage != nil {
+		dto.MaxStorage = *maxStorage
+	}
+	if maxDeploymentSize != nil {
+		dto.MaxDeploymentSize = *maxDeploymentSize
+	}
+	if maxUploadSize != nil {
+		dto.MaxUploadSize = *maxUploadSize
+	}
+}
+
+// UploadLimit 上传接口的请求体限制，请求属于项目时为项目生效的限制，否则使用当前组织或用户的配额
+// Body limit of upload endpoints, the effective limit of the project for requests within one, otherwise the quota of the current organization or user
+func (QuotaApi) UploadLimit(ctx context.Context, c *app.RequestContext) int64 {
+	if project := getProject(c); project != nil {
+		return Quota.projectUploadLimit(ctx, project)
+	}
+	ownerType, ownerID := constants.OwnerTypeUser, uint(0)
+	if org := getOrg(c); org != nil {
+		ownerType, ownerID = constants.OwnerTypeOrg, org.ID
+	} else if user := middle.Auth.GetUser(ctx, c); user != nil {
+		ownerID = user.ID
+	}
+	return Quota.uploadLimit(ctx, ownerType, ownerID)
+}
+
+// projectUploadLimit 项目生效的单次上传限制，管理员为项目设置的值优先于所有者的配额
+// Effective single upload limit of a project, the value an administrator set for the project comes before the owner's quota
+func (QuotaApi) projectUploadLimit(ctx context.Context, project *models.Project) int64 {
+	if project.MaxUploadSize != nil {
+		return *project.MaxUploadSize
+	}
+	return Quota.uploadLimit(ctx, project.OwnerType, project.OwnerID)
+}
+
+// uploadLimit 所有者的单次上传限制，读取配额失败时使用默认值
+// Single upload limit of an owner, the default when the quota cannot be read
+func (QuotaApi) uploadLimit(ctx context.Context, ownerType string, ownerID uint) int64 {
+	limits, err := Quota.limits(ownerType, ownerID)
+	if err != nil {
+		utils.Log.Ctx(ctx).Warnf("failed to get the upload limit of %s %d: %v", ownerType, ownerID, err)
+	}
+	return limits.MaxUploadSize
+}
+
+// usage 所有者的配额及当前用量
+// Quota of an owner with the current usage
+func (QuotaApi) usage(ctx context.Context, ownerType string, ownerID uint) (QuotaDTO, error) {
+	dto, err := Quota.limits(ownerType, ownerID)
+	if err != nil {
+		return dto, err
+	}
+	if dto.Projects, err = store.Quota.CountProjects(ownerType, ownerID); err != nil {
+		return dto, err
+	}
+	dto.StorageUsed, err = store.Quota.StorageUsed(ctx, ownerType, ownerID)
+	return dto, err
+}
+
+// checkProjects 校验所有者是否还能创建项目
+// Check whether the owner may create another project
+func (QuotaApi) checkProjects(ownerType string, ownerID uint) error {
+	limits, err := Quota.limits(ownerType, ownerID)
+	if err != nil {
+		return errQuotaCheck
+	}
+	if limits.MaxProjects <= 0 {
+		return nil
+	}
+	count, err := store.Quota.CountProjects(ownerType, ownerID)
+	if err != nil {
+		return errQuotaCheck
+	}
+	if count >= limits.MaxProjects {
+		return fmt.Errorf("%w: at most %d projects may be created", errQuotaExceeded, limits.MaxProjects)
+	}
+	return nil
+}
+
+// checkDeployment 校验部署是否超出项目所有者的配额，size 为部署解压后的字节数，added 计算部署新增占用的存储字节数，仅在限制存储时调用
+// Check a deployment against the quota of the project owner, size is its uncompressed size and added computes the storage it adds, only called when storage is limited
+func (QuotaApi) checkDeployment(ctx context.Context, project *models.Project, size int64, added func() (int64, error)) error {
+	limits, err := Quota.limits(project.OwnerType, project.OwnerID)
+	if err != nil {
+		return errQuotaCheck
+	}
+	if limits.MaxDeploymentSize > 0 && size > limits.MaxDeploymentSize {
+		return fmt.Errorf("%w: the deployment has %d bytes, at most %d are allowed", errQuotaExceeded, size, limits.MaxDeploymentSize)
+	}
+	if limits.MaxStorage <= 0 {
+		return nil
+	}
+	used, err := store.Quota.StorageUsed(ctx, project.OwnerType, project.OwnerID)
+	if err != nil {
+		return errQuotaCheck
+	}
+	extra, err := added()
+	if err != nil {
+		return errQuotaCheck
+	}
+	// 只引用已有内容的部署总是允许，即使已超出配额 Deployments only referencing existing contents are always allowed, even beyond the quota
+	if extra > 0 && used+extra > limits.MaxStorage {
+		return fmt.Errorf("%w: the deployment adds %d bytes of storage, %d of %d are used", errQuotaExceeded, extra, used, limits.MaxStorage)
+	}
+	return nil
+}
+
+// checkManifest 按部署清单校验配额，所有者的其他版本已引用的内容不重复计算
+// Check the quota for a deployment manifest, contents already referenced by other versions of the owner are not counted again
+func (QuotaApi) checkManifest(ctx context.Context, project *models.Project, manifest *utils.SiteManifest) error {
+	var size int64
+	sizes := make(map[string]int64, len(manifest.Files))
+	for _, f := range manifest.Files {
+		size += f.Size
+		sizes[f.SHA256] = f.Size
+	}
+	return Quota.checkDeployment(ctx, project, size, func() (int64, error) {
+		owned, err := store.Quota.OwnedHashes(ctx, project.OwnerType, project.OwnerID, manifest.Hashes())
+		if err != nil {
+			return 0, err
+		}
+		var added int64
+		for hash, s := range sizes {
+			if !owned[hash] {
+				added += s
+			}
+		}
+		return added, nil
+	})
+}
+
+// checkStorage 校验所有者的存储空间是否已用完，用于在上传大文件之前尽早拒绝
+// Check whether the owner's storage is used up, rejecting large uploads before they start
+func (QuotaApi) checkStorage(ctx context.Context, project *models.Project) error {
+	limits, err := Quota.limits(project.OwnerType, project.OwnerID)
+	if err != nil {
+		return errQuotaCheck
+	}
+	if limits.MaxStorage <= 0 {
+		return nil
+	}
+	used, err := store.Quota.StorageUsed(ctx, project.OwnerType, project.OwnerID)
+	if err != nil {
+		return errQuotaCheck
+	}
+	if used >= limits.MaxStorage {
+		return fmt.Errorf("%w: %d of %d bytes of storage are used", errQuotaExceeded, used, limits.MaxStorage)
+	}
+	return nil
+}
+
+// warn 部署后存储用量达到配额的 notification.quota-warning 百分比时通知所有者，用量回落前只通知一次
+// Notify the owner once a deployment brings storage to notification.quota-warning percent of the quota, only once until usage drops again
+func (QuotaApi) warn(ctx context.Context, project *models.Project) {
+	if config.NotificationQuotaWarning <= 0 {
+		return
+	}
+	limits, err := Quota.limits(project.OwnerType, project.OwnerID)
+	if err != nil || limits.MaxStorage <= 0 {
+		return
+	}
+	used, err := store.Quota.StorageUsed(ctx, project.OwnerType, project.OwnerID)
+	if err != nil {
+		utils.Log.Ctx(ctx).Warnf("failed to get storage usage of %s %d: %v", project.OwnerType, project.OwnerID, err)
+		return
+	}
+	if used*100 < limits.MaxStorage*int64(config.NotificationQuotaWarning) {
+		if err := store.Notification.ClearAlert(project.OwnerType, project.OwnerID, quotaStorageAlert); err != nil {
+			utils.Log.Ctx(ctx).Warnf("failed to clear quota warning of %s %d: %v", project.OwnerType, project.OwnerID, err)
+		}
+		return
+	}
+	recorded, err := store.Notification.RecordAlert(project.OwnerType, project.OwnerID, quotaStorageAlert, time.Now())
+	if err != nil || !recorded {
+		return
+	}
+	Notification.notify(ctx, project.OwnerType, project.OwnerID, constants.NotificationQuotaWarning, i18n.Msg("Storage quota almost used up"),
+		i18n.Msg("%s of %s of storage are used (%d%%), deployments adding content fail once the quota is reached", mebibytes(used), mebibytes(limits.MaxStorage), used*100/limits.MaxStorage))
+}
+
+// User 获取当前用户的配额和用量
+// Get the quota and usage of the current user
+func (QuotaApi) User(ctx context.Context, c *app.RequestContext) {
+	user := middle.Auth.GetUser(ctx, c)
+	dto, err := Quota.usage(ctx, constants.OwnerTypeUser, user.ID)
+	if err != nil {
+		resps.InternalServerError(c, "get quota error")
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"quota": dto,
+	})
+}
+
+// Org 获取组织的配额和用量
+// Get the quota and usage of an organization
+func (QuotaApi) Org(ctx context.Context, c *app.RequestContext) {
+	org := getOrg(c)
+	dto, err := Quota.usage(ctx, constants.OwnerTypeOrg, org.ID)
+	if err != nil {
+		resps.InternalServerError(c, "get quota error")
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"quota": dto,
+	})
+}
+
+// adminOwner 解析路径中的所有者并确认其存在，失败时已写入响应
+// Parse the owner of the path and make sure it exists; the response is already written on failure
+func (QuotaApi) adminOwner(c *app.RequestContext) (string, uint, bool) {
+	ownerType := c.Param("owner_type")
+	id, err := strconv.Atoi(c.Param("owner_id"))
+	if err != nil || id <= 0 {
+		resps.BadRequest(c, resps.ParameterError)
+		return "", 0, false
+	}
+	ownerID := uint(id)
+	switch ownerType {
+	case constants.OwnerTypeUser:
+		_, err = store.User.GetByID(ownerID)
+	case constants.OwnerTypeOrg:
+		_, err = store.Org.GetOrgById(ownerID)
+	default:
+		resps.BadRequest(c, "owner type must be user or organization")
+		return "", 0, false
+	}
+	if err != nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return "", 0, false
+	}
+	return ownerType, ownerID, true
+}
+
+// AdminGet 管理员获取用户或组织的配额和用量
+// Admin gets the quota and usage of a user or organization
+func (QuotaApi) AdminGet(ctx context.Context, c *app.RequestContext) {
+	ownerType, ownerID, ok := Quota.adminOwner(c)
+	if !ok {
+		return
+	}
+	dto, err := Quota.usage(ctx, ownerType, ownerID)
+	if err != nil {
+		resps.InternalServerError(c, "get quota error")
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"quota": dto,
+	})
+}
+
+// AdminUpdate 管理员为用户或组织设置配额，覆盖默认值，已超出新配额的内容不受影响
+// Admin sets the quota of a user or organization, overriding the defaults; content already beyond the new quota is left alone
+func (QuotaApi) AdminUpdate(ctx context.Context, c *app.RequestContext) {
+	ownerType, ownerID, ok := Quota.adminOwner(c)
+	if !ok {
+		return
+	}
+	req := QuotaReq{}
+	if !bindJSON(c, &req) {
+		return
+	}
+	for _, limit := range []*int64{req.MaxProjects, req.MaxStorage, req.MaxDeploymentSize, req.MaxUploadSize} {
+		if limit != nil && *limit < 0 {
+			resps.BadRequest(c, "quota limits must not be negative, use 0 for unlimited")
+			return
+		}
+	}
+	quota := &models.Quota{
+		OwnerType:         ownerType,
+		OwnerID:           ownerID,
+		MaxProjects:       req.MaxProjects,
+		MaxStorage:        req.MaxStorage,
+		MaxDeploymentSize: req.MaxDeploymentSize,
+		MaxUploadSize:     req.MaxUploadSize,
+	}
+	if err := store.Quota.Save(quota); err != nil {
+		resps.DBError(c, err, "save quota error")
+		return
+	}
+	Audit.record(ctx, c, nil, constants.AuditQuotaUpdate, ownerType, ownerID, map[string]any{
+		"operation":           "update",
+		"max_projects":        req.MaxProjects,
+		"max_storage":         req.MaxStorage,
+		"max_deployment_size": req.MaxDeploymentSize,
+		"max_upload_size":     req.MaxUploadSize,
+	})
+	dto, err := Quota.usage(ctx, ownerType, ownerID)
+	if err != nil {
+		resps.InternalServerError(c, "get quota error")
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"quota": dto,
+	})
+}
+
+// AdminDelete 管理员删除用户或组织的配额，恢复为默认配额
+// Admin removes the quota of a user or organization, restoring the defaults
+func (QuotaApi) AdminDelete(ctx context.Context, c *app.RequestContext) {
+	ownerType, ownerID, ok := Quota.adminOwner(c)
+	if !ok {
+		return
+	}
+	if err := store.Quota.Delete(ownerType, ownerID); err != nil {
+		resps.DBError(c, err, "delete quota error")
+		return
+	}
+	Audit.record(ctx, c, nil, constants.AuditQuotaUpdate, ownerType, ownerID, map[string]any{"operation": "delete"})
+	resps.Ok(c, resps.OK)
+}
+
+// AdminProjectUpload 管理员设置项目的单次上传最大字节数，覆盖所有者的配额，为空时恢复为所有者的配额
+// Admin sets the largest single upload of a project, overriding the owner's quota, empty restores the owner's quota
+func (QuotaApi) AdminProjectUpload(ctx context.Context, c *app.RequestContext) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		resps.BadRequest(c, resps.ParameterError)
+		return
+	}
+	project, err := store.Project.GetByID(uint(id))
+	if err != nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	req := ProjectUploadLimitReq{}
+	if !bindJSON(c, &req) {
+		return
+	}
+	if req.MaxUploadSize != nil && *req.MaxUploadSize < 0 {
+		resps.BadRequest(c, "max_upload_size must not be negative, use 0 for unlimited")
+		return
+	}
+	if err := store.Project.SetMaxUploadSize(project, req.MaxUploadSize); err != nil {
+		resps.DBError(c, err, "save quota error")
+		return
+	}
+	Audit.record(ctx, c, nil, constants.AuditQuotaUpdate, constants.AuditTargetProject, project.ID, map[string]any{
+		"operation":       "project upload",
+		"max_upload_size": req.MaxUploadSize,
+	})
+	resps.Ok(c, resps.OK, map[string]any{
+		"project_id":      project.ID,
+		"max_upload_size": Quota.projectUploadLimit(ctx, project),
+		"custom":          project.MaxUploadSize != nil,
+	})
+}
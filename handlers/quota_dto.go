@@ -0,0 +1,30 @@
+package handlers
+
+// QuotaDTO 配额及当前用量，限制为 0 表示不限制
+// Quota with the current usage, a limit of 0 means unlimited
+type QuotaDTO struct {
+	OwnerType         string `json:"owner_type"`          // 所有者类型 Owner type
+	OwnerID           uint   `json:"owner_id"`            // 所有者ID Owner ID
+	Projects          int64  `json:"projects"`            // 已创建的项目数量 Number of projects created
+	MaxProjects       int64  `json:"max_projects"`        // 可创建的项目数量 Number of projects that may be created
+	StorageUsed       int64  `json:"storage_used"`        // 已占用的存储字节数 Storage occupied in bytes
+	MaxStorage        int64  `json:"max_storage"`         // 可占用的存储字节数 Storage that may be occupied in bytes
+	MaxDeploymentSize int64  `json:"max_deployment_size"` // 单次部署解压后的最大字节数 Largest uncompressed size of one deployment in bytes
+	MaxUploadSize     int64  `json:"max_upload_size"`     // 单次上传的最大字节数 Largest single upload in bytes
+	Custom            bool   `json:"custom"`              // 是否为管理员单独设置的配额 Whether an administrator set this quota individually
+}
+
+// QuotaReq 管理员设置配额的请求参数，字段为空时使用默认配额，0 为不限制
+// Request parameters for an administrator setting a quota, empty fields use the defaults and 0 means unlimited
+type QuotaReq struct {
+	MaxProjects       *int64 `json:"max_projects"`        // 可创建的项目数量 Number of projects that may be created
+	MaxStorage        *int64 `json:"max_storage"`         // 可占用的存储字节数 Storage that may be occupied in bytes
+	MaxDeploymentSize *int64 `json:"max_deployment_size"` // 单次部署解压后的最大字节数 Largest uncompressed size of one deployment in bytes
+	MaxUploadSize     *int64 `json:"max_upload_size"`     // 单次上传的最大字节数 Largest single upload in bytes
+}
+
+// ProjectUploadLimitReq 管理员设置项目上传大小的请求参数，为空时恢复为所有者的配额，0 为不限制
+// Request parameters for an administrator setting the upload size of a project, empty restores the owner's quota and 0 means unlimited
+type ProjectUploadLimitReq struct {
+	MaxUploadSize *int64 `json:"max_upload_size"` // 单次上传的最大字节数 Largest single upload in bytes
+}
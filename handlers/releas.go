@@ -1,15 +1,34 @@
 package handlers
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/i18n"
+	"github.com/LiteyukiStudio/spage/middle"
 	"github.com/LiteyukiStudio/spage/models"
 	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/serve"
+	"github.com/LiteyukiStudio/spage/storage"
 	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/task"
 	"github.com/LiteyukiStudio/spage/utils"
 	"github.com/cloudwego/hertz/pkg/app"
-	"os"
-	"time"
 )
 
 type ReleaseApi struct {
@@ -19,128 +38,865 @@ var Release = ReleaseApi{}
 
 func (ReleaseApi) ToDTO(release *models.SiteRelease) ReleaseDTO {
 	return ReleaseDTO{
-		ID:   release.ID,
-		Site: Site.ToDTO(&release.Site, false),
-		Tag:  release.Tag,
-		File: release.File,
+		ID:     release.ID,
+		Site:   Site.ToDTO(&release.Site, false),
+		Tag:    release.Tag,
+		File:   release.File,
+		Status: release.Status,
+		Pinned: release.Pinned,
+
+		Changelog: release.Changelog,
+
+		PublishAt:    release.PublishAt,
+		ExpiresAt:    release.ExpiresAt,
+		ExpireAction: release.ExpireAction,
+
+		ScanFindings:  Scan.findings(release),
+		VerifiedFiles: len(release.Checksums),
+
+		CreatedAt: release.CreatedAt,
+	}
+}
+
+func (ReleaseApi) toPurgeDTO(purge *models.SitePathPurge) PurgeDTO {
+	return PurgeDTO{
+		ID:            purge.ID,
+		UserID:        purge.UserID,
+		Paths:         purge.Paths,
+		Removed:       purge.Removed,
+		FromReleaseID: purge.FromReleaseID,
+		ToReleaseID:   purge.ToReleaseID,
+		Takedown:      purge.Takedown,
+		CreatedAt:     purge.CreatedAt,
 	}
 }
 
+// releaseSortFields 版本列表可排序的字段 Sortable fields of release lists
+var releaseSortFields = []string{"id", "created_at", "tag", "status"}
+
+// ReleaseList 分页获取站点的 release 记录
+// List the release records of the site with pagination
 func (ReleaseApi) ReleaseList(ctx context.Context, c *app.RequestContext) {
-	site := getSite(ctx)
+	site := getSite(c)
 	if site == nil {
 		resps.NotFound(c, resps.TargetNotFound)
 		return
 	}
-	releaseList, err := store.Site.GetReleaseList(site.ID)
+	list, ok := getListQuery(c, "-id", releaseSortFields...)
+	if !ok {
+		return
+	}
+	releaseList, total, err := store.Site.ListReleases(ctx, site.ID, list)
 	if err != nil {
-		resps.InternalServerError(c, resps.ParameterError)
+		resps.DBError(c, err, resps.ParameterError)
 		return
 	}
+	utils.Ctx.SetPageHeaders(c, list.Page, list.Limit, total)
+	activeID := Release.activeID(site)
 	resps.Ok(c, resps.OK, map[string]any{
-		"releases": func(releases []*models.SiteRelease) []ReleaseDTO {
+		"releases": func(releases []models.SiteRelease) []ReleaseDTO {
 			var releasesDTO []ReleaseDTO
 			for _, release := range releases {
-				releasesDTO = append(releasesDTO, Release.ToDTO(release))
+				releaseDTO := Release.ToDTO(&release)
+				releaseDTO.Active = release.ID == activeID
+				releasesDTO = append(releasesDTO, releaseDTO)
 			}
 			return releasesDTO
 		}(releaseList),
+		"total": total,
+	})
+}
+
+// Deployments 分页获取站点的部署版本，每次上传都会保留为不可变的版本
+// List the deployed versions of the site with pagination, every upload is kept as an immutable version
+func (ReleaseApi) Deployments(ctx context.Context, c *app.RequestContext) {
+	site := getSite(c)
+	if site == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	list, ok := getListQuery(c, "-id", releaseSortFields...)
+	if !ok {
+		return
+	}
+	status := constants.DeploymentStatus(c.Query("status"))
+	if status != "" && !status.Valid() {
+		resps.BadRequest(c, "invalid status")
+		return
+	}
+	releases, total, err := store.Site.ListVersions(ctx, site.ID, status, list)
+	if err != nil {
+		resps.DBError(c, err, "get deployments error")
+		return
+	}
+	utils.Ctx.SetPageHeaders(c, list.Page, list.Limit, total)
+	activeID := Release.activeID(site)
+	resps.Ok(c, resps.OK, map[string]any{
+		"deployments": func() (releaseDTOs []ReleaseDTO) {
+			for _, release := range releases {
+				releaseDTO := Release.ToDTO(&release)
+				releaseDTO.Active = release.ID == activeID
+				releaseDTOs = append(releaseDTOs, releaseDTO)
+			}
+			return
+		}(),
+		"total":             total,
+		"active_release_id": activeID,
+	})
+}
+
+// Diff 比较站点的两个部署版本，列出新增、删除和变化的文件及其大小和哈希，便于回滚前确认部署改动了什么
+// Compare two deployments of the site, listing added, removed and changed files with their sizes and hashes so owners can see what a deploy changed before rolling back
+func (ReleaseApi) Diff(ctx context.Context, c *app.RequestContext) {
+	req := DiffDeploymentsReq{}
+	if !bind(c, &req) {
+		return
+	}
+	site := getSite(c)
+	if site == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	if req.To == 0 {
+		if req.To = Release.activeID(site); req.To == 0 {
+			resps.NotFound(c, "site has no active release")
+			return
+		}
+	}
+	from, err := store.Site.GetSiteRelease(site.ID, req.From)
+	if err != nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	to, err := store.Site.GetSiteRelease(site.ID, req.To)
+	if err != nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	fromFiles, err := serve.ReleaseFiles(&from.File)
+	if err != nil {
+		utils.Log.Ctx(ctx).Errorf("failed to read files of release %d: %v", from.ID, err)
+		resps.InternalServerError(c, "read release files error")
+		return
+	}
+	toFiles, err := serve.ReleaseFiles(&to.File)
+	if err != nil {
+		utils.Log.Ctx(ctx).Errorf("failed to read files of release %d: %v", to.ID, err)
+		resps.InternalServerError(c, "read release files error")
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"diff": DeploymentDiffDTO{
+			FromReleaseID: from.ID,
+			ToReleaseID:   to.ID,
+			ReleaseDiff:   serve.DiffReleases(fromFiles, toFiles),
+		},
+	})
+}
+
+// Checksums 获取部署上传时提供并在解压后校验通过的文件 SHA-256
+// Get the file SHA-256 given with the upload of a deployment and verified after extraction
+func (ReleaseApi) Checksums(ctx context.Context, c *app.RequestContext) {
+	site := getSite(c)
+	if site == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	id, err := strconv.Atoi(c.Param("release_id"))
+	if err != nil {
+		resps.BadRequest(c, resps.ParameterError)
+		return
+	}
+	release, err := store.Site.GetSiteRelease(site.ID, uint(id))
+	if err != nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	checksums := release.Checksums
+	if checksums == nil {
+		checksums = map[string]string{}
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"release_id": release.ID,
+		"checksums":  checksums,
 	})
 }
 
 func (ReleaseApi) Create(ctx context.Context, c *app.RequestContext) {
 	req := CreateReleaseReq{}
-	if err := c.BindAndValidate(&req); err != nil {
-		resps.BadRequest(c, resps.ParameterError)
+	if !bind(c, &req) {
 		return
 	}
-	site := getSite(ctx)
+	site := getSite(c)
 	if site == nil {
 		resps.NotFound(c, resps.TargetNotFound)
 		return
 	}
-	// 检查 zip 文件
-	valid, err := utils.IsValidZipFile(req.File)
-	if !valid || err != nil {
-		resps.BadRequest(c, "file is not a zip or zip file is invalid")
+	if req.Tag == "" || req.File == nil {
+		resps.BadRequest(c, resps.ParameterError)
+		return
+	}
+	previewName, ok := Release.checkDeploy(c, site, req.Tag, req.Preview, req.TTL)
+	if !ok {
 		return
 	}
-	// 拼接并生成保存路径
-	releaseSaveDir := config.ReleaseSavePath + "/" + site.Name + "/" + req.Tag
-	if err := os.MkdirAll(releaseSaveDir, os.ModePerm); err != nil {
-		resps.InternalServerError(c, "create release directory error")
+	schedule, ok := Release.checkSchedule(c, previewName, req.PublishAt, req.ExpiresAt, req.ExpireAction)
+	if !ok {
 		return
 	}
-	releaseName := time.Now().Format("20060102150405") + ".zip"
-	releaseSavePath := releaseSaveDir + "/" + releaseName
-	// 保存文件
-	if err := c.SaveUploadedFile(req.File, releaseSavePath); err != nil {
-		resps.InternalServerError(c, "create release file error")
+	checksums, err := utils.ParseChecksums(req.Checksums)
+	if err != nil {
+		resps.BadRequest(c, err.Error())
 		return
 	}
-	// 计算文件hash
-	fileHash, err := utils.FileHash(releaseSavePath)
+	if !req.Async {
+		archive := uploadArchive(req.File)
+		archive.checksums = checksums
+		archive.changelog = req.Changelog
+		Release.deploy(ctx, c, site, archive, req.Tag, previewName, req.TTL, schedule)
+		return
+	}
+	key, err := Release.stageArchive(ctx, req.File)
+	if err != nil {
+		utils.Log.Ctx(ctx).Errorf("failed to stage release file of site %d: %v", site.ID, err)
+		resps.InternalServerError(c, errArchiveStore.Error())
+		return
+	}
+	payload := deployJobPayload{Tag: req.Tag, Preview: previewName, TTL: req.TTL, Archive: key, Checksums: checksums, Changelog: req.Changelog, releaseSchedule: schedule}
+	if !Release.enqueueDeploy(ctx, c, site, payload) {
+		_ = storage.Default.Delete(ctx, key)
+	}
+}
+
+// checkDeploy 校验版本标签和预览参数，返回规范化的预览名称，校验失败时已写入响应
+// Validate the version tag and preview parameters, returning the normalized preview name; the response is already written on failure
+func (ReleaseApi) checkDeploy(c *app.RequestContext, site *models.Site, tag, preview string, ttl int) (string, bool) {
+	if tag == constants.ReleaseTagLatest {
+		resps.BadRequest(c, "tag latest is reserved")
+		return "", false
+	}
+	// 带 preview 的上传作为预览部署，不切换站点当前版本
+	// Uploads with a preview name become preview deployments and leave the active version untouched
+	if preview == "" {
+		return "", true
+	}
+	previewName, err := utils.Domain.PreviewName(preview)
+	if err != nil {
+		resps.BadRequest(c, err.Error())
+		return "", false
+	}
+	if site.SubDomain == "" || serve.BaseDomain(site) == "" {
+		resps.BadRequest(c, "preview deployments require a site subdomain and serve.base-domain")
+		return "", false
+	}
+	if len(previewName)+len(utils.PreviewSeparator)+len(site.SubDomain) > 63 {
+		resps.BadRequest(c, "preview name is too long for this site")
+		return "", false
+	}
+	if ttl < 0 || ttl > config.PreviewMaxTTL {
+		resps.BadRequestf(c, "ttl must be between 0 and %d seconds", config.PreviewMaxTTL)
+		return "", false
+	}
+	return previewName, true
+}
+
+// deploy 发布内容并写入响应，普通上传、分片上传和清单部署共用；返回是否已创建版本
+// Publish the content and write the response, shared by plain uploads, chunked uploads and manifest deployments; reports whether a version was created
+func (ReleaseApi) deploy(ctx context.Context, c *app.RequestContext, site *models.Site, source releaseSource, tag, previewName string, ttl int, schedule releaseSchedule) bool {
+	release, previousID, err := Release.publish(ctx, site, source, tag, previewName == "" && schedule.PublishAt == nil)
+	if unsafeArchive(c, err) {
+		return false
+	}
+	if errors.Is(err, errInvalidArchive) || errors.Is(err, errInvalidManifest) || errors.Is(err, errBlockedContent) || errors.Is(err, errChecksumMismatch) {
+		resps.BadRequest(c, err.Error())
+		return false
+	}
+	if errors.Is(err, errQuotaExceeded) {
+		resps.Custom(c, 413, err.Error())
+		return false
+	}
+	if err != nil {
+		resps.InternalServerError(c, err.Error())
+		return false
+	}
+	if err := Release.schedule(ctx, site, release, schedule, middle.Auth.GetUser(ctx, c).ID); err != nil {
+		utils.Log.Ctx(ctx).Errorf("failed to schedule release %d: %v", release.ID, err)
+		resps.InternalServerError(c, errSchedule.Error())
+		return true
+	}
+	data, err := Release.finishDeploy(ctx, c, nil, site, release, previousID, previewName, ttl)
+	if err != nil {
+		resps.InternalServerError(c, err.Error())
+		return true
+	}
+	resps.Ok(c, resps.OK, data)
+	return true
+}
+
+// finishDeploy 记录审计日志并完成预览部署或通知站点已切换版本，返回响应数据；c 为空时由后台任务以 actor 的身份调用
+// Record the audit entry and finish the preview deployment or announce the new active version, returning the response data; c is nil when a background job calls it on behalf of actor
+func (ReleaseApi) finishDeploy(ctx context.Context, c *app.RequestContext, actor *models.User, site *models.Site, release *models.SiteRelease, previousID uint, previewName string, ttl int) (map[string]any, error) {
+	details := map[string]any{"release_id": release.ID, "tag": release.Tag, "file_id": release.FileID}
+	if len(release.Checksums) > 0 {
+		details["verified_files"] = len(release.Checksums)
+	}
+	if release.ExpiresAt != nil {
+		details["expires_at"] = release.ExpiresAt
+		details["expire_action"] = release.ExpireAction
+	}
+	// 隔离的版本在审核通过后才上线 Quarantined versions only go live once approved
+	quarantined := release.Status == constants.DeploymentStatusQuarantined
+	if quarantined {
+		details["quarantined"] = true
+	}
+	// 等待批准的版本在批准后才上线 Versions awaiting approval only go live once approved
+	awaiting := release.Status == constants.DeploymentStatusAwaitingApproval
+	if awaiting {
+		details["awaiting_approval"] = true
+	}
+	// 定时上线的版本到时由后台任务切换并通知 Scheduled versions are switched to and announced by a background job later
+	if release.Status == constants.DeploymentStatusScheduled || awaiting || (quarantined && previewName == "") {
+		if release.PublishAt != nil {
+			details["publish_at"] = release.PublishAt
+		}
+		Audit.record(ctx, c, actor, constants.AuditReleaseCreate, constants.AuditTargetSite, site.ID, details)
+		return map[string]any{
+			"release": Release.ToDTO(release),
+		}, nil
+	}
+	if previewName != "" {
+		details["preview"] = previewName
+		Audit.record(ctx, c, actor, constants.AuditReleaseCreate, constants.AuditTargetSite, site.ID, details)
+		preview, err := Preview.save(ctx, site, release, previewName, ttl)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{
+			"release": Release.ToDTO(release),
+			"preview": Preview.toDTO(site, preview),
+		}, nil
+	}
+	details["previous_release_id"] = previousID
+	Audit.record(ctx, c, actor, constants.AuditReleaseCreate, constants.AuditTargetSite, site.ID, details)
+	Release.notifyDeployed(ctx, site, release, previousID)
+	releaseDTO := Release.ToDTO(release)
+	releaseDTO.Active = true
+	return map[string]any{
+		"release": releaseDTO,
+	}, nil
+}
+
+// deployJobPayload 后台发布任务的参数，内容来自暂存的压缩包或分片上传会话
+// Parameters of a background deploy job, the content comes from a staged archive or a chunked upload session
+type deployJobPayload struct {
+	Tag      string `json:"tag"`                 // 版本标签 Version tag
+	Preview  string `json:"preview,omitempty"`   // 预览部署名称 Preview deployment name
+	TTL      int    `json:"ttl,omitempty"`       // 预览有效期 Preview lifetime
+	Archive  string `json:"archive,omitempty"`   // 暂存压缩包的存储键 Storage key of the staged archive
+	UploadID string `json:"upload_id,omitempty"` // 分片上传会话ID Chunked upload session ID
+
+	Checksums map[string]string `json:"checksums,omitempty"` // 解压后校验的文件 SHA-256 SHA-256 of the files verified after extraction
+	Changelog string            `json:"changelog,omitempty"` // 版本的变更说明 Changelog of the version
+
+	releaseSchedule
+}
+
+// stageArchive 将上传的压缩包暂存到对象存储，供后台发布任务读取，返回存储键
+// Stage an uploaded archive in storage for a background deploy job to read, returning the storage key
+func (ReleaseApi) stageArchive(ctx context.Context, fileHeader *multipart.FileHeader) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	key := storage.StagedPrefix + hex.EncodeToString(buf) + ".zip"
+	file, err := fileHeader.Open()
 	if err != nil {
-		resps.InternalServerError(c, "calculate file hash error")
+		return "", err
+	}
+	defer file.Close()
+	return key, storage.Default.Put(ctx, key, file, fileHeader.Size)
+}
+
+// enqueueDeploy 创建后台发布任务并以 202 返回任务，返回是否已创建
+// Create a background deploy job and respond 202 with it, reporting whether it was created
+func (ReleaseApi) enqueueDeploy(ctx context.Context, c *app.RequestContext, site *models.Site, payload deployJobPayload) bool {
+	job, err := task.EnqueueJob(ctx, constants.JobTypeDeploy, payload, site.ID, middle.Auth.GetUser(ctx, c).ID)
+	if err != nil {
+		utils.Log.Ctx(ctx).Errorf("failed to create deploy job of site %d: %v", site.ID, err)
+		resps.InternalServerError(c, "create job error")
+		return false
+	}
+	resps.Custom(c, 202, resps.OK, map[string]any{
+		"job": Job.toDTO(job, false),
+	})
+	return true
+}
+
+// runDeployJob 在后台解压并发布暂存的压缩包或分片上传，与同步上传一样校验内容和配额，内容无效时不再重试
+// Unpack and publish a staged archive or chunked upload in the background, checked like a synchronous upload; invalid content is not retried
+func (ReleaseApi) runDeployJob(ctx context.Context, job *models.Job) (any, error) {
+	payload := deployJobPayload{}
+	if err := json.Unmarshal([]byte(job.Payload), &payload); err != nil {
+		return nil, task.Permanent(err)
+	}
+	site, err := store.Site.GetByID(job.SiteID)
+	if err != nil {
+		return nil, task.Permanent(errors.New("site not found"))
+	}
+	path, err := Release.deploySource(ctx, job.SiteID, &payload)
+	if path != "" {
+		defer os.Remove(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	archive, err := fileArchive(path)
+	if err != nil {
+		return nil, err
+	}
+	archive.checksums = payload.Checksums
+	archive.changelog = payload.Changelog
+	release, previousID, err := Release.publish(ctx, site, archive, payload.Tag, payload.Preview == "" && payload.PublishAt == nil)
+	if errors.Is(err, errInvalidArchive) || errors.Is(err, errInvalidManifest) || errors.Is(err, errBlockedContent) || errors.Is(err, errQuotaExceeded) || errors.Is(err, errChecksumMismatch) || errors.As(err, new(*utils.ArchiveError)) {
+		return nil, task.Permanent(err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// 版本已创建，定时设置保存失败时重试会重复创建版本 The version exists now, retrying after a failed schedule would create it twice
+	if err := Release.schedule(ctx, site, release, payload.releaseSchedule, job.UserID); err != nil {
+		return nil, task.Permanent(fmt.Errorf("%w: %v", errSchedule, err))
+	}
+	Release.discardDeployJob(ctx, job)
+	if payload.UploadID != "" {
+		if upload, err := store.Upload.Get(job.SiteID, payload.UploadID); err == nil {
+			if err := store.Upload.Delete(ctx, upload); err != nil {
+				utils.Log.Ctx(ctx).Warnf("failed to delete upload %s: %v", upload.ID, err)
+			}
+		}
+	}
+	actor, err := store.User.GetByID(job.UserID)
+	if err != nil {
+		actor = nil
+	}
+	return Release.finishDeploy(ctx, nil, actor, site, release, previousID, payload.Preview, payload.TTL)
+}
+
+// deploySource 将任务的内容写入临时文件并返回其路径，分片上传在此拼接和校验
+// Write the content of the job to a temporary file and return its path, chunked uploads are assembled and verified here
+func (ReleaseApi) deploySource(ctx context.Context, siteID uint, payload *deployJobPayload) (string, error) {
+	if payload.UploadID != "" {
+		upload, err := store.Upload.Get(siteID, payload.UploadID)
+		if err != nil {
+			return "", task.Permanent(errors.New("upload session not found"))
+		}
+		path, err := Upload.assemble(ctx, upload)
+		if err != nil && path != "" {
+			// 大小或哈希不一致时重试没有意义 Retrying cannot fix a size or hash mismatch
+			err = task.Permanent(err)
+		}
+		return path, err
+	}
+	object, err := storage.Default.Get(ctx, payload.Archive)
+	if errors.Is(err, storage.ErrNotFound) {
+		return "", task.Permanent(errors.New("staged archive not found"))
+	}
+	if err != nil {
+		return "", err
+	}
+	defer object.Close()
+	tmp, err := os.CreateTemp("", "spage-deploy-*.zip")
+	if err != nil {
+		return "", err
+	}
+	defer tmp.Close()
+	if _, err := io.Copy(tmp, object); err != nil {
+		return tmp.Name(), err
+	}
+	return tmp.Name(), tmp.Close()
+}
+
+// discardDeployJob 删除发布任务暂存的压缩包，分片上传会话保留以便重传分片，过期后自动清理
+// Delete the staged archive of a deploy job, chunked upload sessions are kept so chunks can be re-uploaded and are cleaned up once expired
+func (ReleaseApi) discardDeployJob(ctx context.Context, job *models.Job) {
+	payload := deployJobPayload{}
+	if err := json.Unmarshal([]byte(job.Payload), &payload); err != nil || payload.Archive == "" {
 		return
 	}
+	if err := storage.Default.Delete(ctx, payload.Archive); err != nil {
+		utils.Log.Ctx(ctx).Warnf("failed to delete staged archive %s: %v", payload.Archive, err)
+	}
+}
+
+var (
+	errInvalidArchive = errors.New("file is not a zip or zip file is invalid")
+	errArchiveHash    = errors.New("calculate file hash error")
+	errArchiveStore   = errors.New("create release file error")
+	errFileRecord     = errors.New("create file record error")
+	errReleaseRecord  = errors.New("create release record error")
+	errActivate       = errors.New("update latest release error")
+	errSchedule       = errors.New("schedule release error")
+
+	errChecksumMismatch = errors.New("uploaded files do not match the checksums")
+)
+
+// releaseSource 待发布的内容，保存后得到版本引用的文件记录
+// Content waiting to be published, storing it yields the file record referenced by the version
+type releaseSource interface {
+	store(ctx context.Context, site *models.Site) (*models.File, error)
+}
+
+// releaseArchive 待发布的压缩包，可以来自上传的文件或磁盘上的构建产物
+// Archive waiting to be published, either an upload or a build output on disk
+type releaseArchive struct {
+	open func() (multipart.File, error)
+	size int64
+
+	checksums map[string]string // 上传时提供的文件 SHA-256，解压后逐个校验，为空时不校验 SHA-256 of the files given with the upload, each verified after extraction, nothing is verified when empty
+	changelog string            // 上传时提供的变更说明 Changelog given with the upload
+}
+
+// uploadArchive 使用上传的文件作为压缩包
+// Use an uploaded file as the archive
+func uploadArchive(fileHeader *multipart.FileHeader) releaseArchive {
+	return releaseArchive{open: fileHeader.Open, size: fileHeader.Size}
+}
+
+// fileArchive 使用磁盘上的文件作为压缩包
+// Use a file on disk as the archive
+func fileArchive(path string) (releaseArchive, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return releaseArchive{}, err
+	}
+	return releaseArchive{
+		open: func() (multipart.File, error) { return os.Open(path) },
+		size: info.Size(),
+	}, nil
+}
+
+// archiveLimits 配置的部署压缩包文件数量和解压大小限制
+// Configured limits on the file count and uncompressed sizes of deployment archives
+func archiveLimits() utils.ArchiveLimits {
+	return utils.ArchiveLimits{
+		MaxFiles:     config.UploadMaxFiles,
+		MaxFileSize:  int64(config.UploadMaxFileSize) << 20,
+		MaxTotalSize: int64(config.UploadMaxUnpackedSize) << 20,
+	}
+}
+
+// unsafeArchive 压缩包未通过安全检查时写入带原因的 400 响应并返回真
+// Write a 400 response with the reason and report true when the archive failed the safety checks
+func unsafeArchive(c *app.RequestContext, err error) bool {
+	var archiveErr *utils.ArchiveError
+	if !errors.As(err, &archiveErr) {
+		return false
+	}
+	resps.Fail(c, http.StatusBadRequest, resps.CodeUnsafeArchive, archiveErr.Message(), archiveErr)
+	return true
+}
+
+// inspect 校验压缩包的格式、条目路径和大小限制并计算其哈希值
+// Validate the format, entry paths and size limits of the archive and compute its hash
+func (a releaseArchive) inspect() (string, error) {
+	file, err := a.open()
+	if err != nil {
+		return "", errArchiveStore
+	}
+	defer file.Close()
+	if valid, err := utils.IsValidZip(file, a.size); !valid || err != nil {
+		return "", errInvalidArchive
+	}
+	reader, err := zip.NewReader(file, a.size)
+	if err != nil {
+		return "", errInvalidArchive
+	}
+	if err := utils.CheckZip(reader, archiveLimits()); err != nil {
+		return "", err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", errArchiveHash
+	}
+	hash, err := utils.ReaderHash(file)
+	if err != nil {
+		return "", errArchiveHash
+	}
+	return hash, nil
+}
+
+// verify 解压每个文件并与上传时提供的 SHA-256 比对，压缩包必须恰好包含清单中的文件；损坏的条目同样无法通过
+// Extract every file and compare it with the SHA-256 given with the upload, the archive must hold exactly the listed files; corrupted entries fail too
+func (a releaseArchive) verify(ctx context.Context) error {
+	if len(a.checksums) == 0 {
+		return nil
+	}
+	file, err := a.open()
+	if err != nil {
+		return errArchiveStore
+	}
+	defer file.Close()
+	reader, err := zip.NewReader(file, a.size)
+	if err != nil {
+		return errInvalidArchive
+	}
+	failed := make(map[string]string)
+	actual := make(map[string]string, len(reader.File))
+	for i, entry := range reader.File {
+		task.ReportProgress(ctx, constants.JobStageVerify, int64(i), int64(len(reader.File)))
+		if entry.FileInfo().IsDir() {
+			continue
+		}
+		name := strings.TrimPrefix(entry.Name, "./")
+		// 重复的路径以后出现的条目为准，与直接读取压缩包一致 Later entries win for duplicate paths, matching reads of the archive
+		hash, _, err := hashZipEntry(entry)
+		if err != nil {
+			failed[name] = "corrupted"
+			continue
+		}
+		delete(failed, name)
+		actual[name] = hash
+	}
+	for name, hash := range actual {
+		expected, ok := a.checksums[name]
+		switch {
+		case !ok:
+			failed[name] = "not listed"
+		case expected != hash:
+			failed[name] = "sha256 mismatch"
+		}
+	}
+	for name := range a.checksums {
+		if _, ok := actual[name]; !ok && failed[name] == "" {
+			failed[name] = "missing"
+		}
+	}
+	if len(failed) > 0 {
+		return filesError(errChecksumMismatch, failed)
+	}
+	return nil
+}
+
+// names 压缩包中所有文件的路径，不包括目录
+// Paths of every file of the archive, directories excluded
+func (a releaseArchive) names() ([]string, error) {
+	file, err := a.open()
+	if err != nil {
+		return nil, errArchiveStore
+	}
+	defer file.Close()
+	reader, err := zip.NewReader(file, a.size)
+	if err != nil {
+		return nil, errInvalidArchive
+	}
+	names := make([]string, 0, len(reader.File))
+	for _, entry := range reader.File {
+		if !entry.FileInfo().IsDir() {
+			names = append(names, strings.TrimPrefix(entry.Name, "./"))
+		}
+	}
+	return names, nil
+}
+
+// contentSize 压缩包解压后的总字节数
+// Total uncompressed size of the archive in bytes
+func (a releaseArchive) contentSize() (int64, error) {
+	file, err := a.open()
+	if err != nil {
+		return 0, errArchiveStore
+	}
+	defer file.Close()
+	reader, err := zip.NewReader(file, a.size)
+	if err != nil {
+		return 0, errInvalidArchive
+	}
+	var size int64
+	for _, entry := range reader.File {
+		size += int64(entry.UncompressedSize64)
+	}
+	return size, nil
+}
+
+// store 校验压缩包并按内容策略检查后保存，开启 file.dedupe 时拆分为按内容哈希存储的文件和部署清单
+// Validate the archive, check it against the content policy and store it, splitting it into files stored by content hash plus a manifest when file.dedupe is on
+func (a releaseArchive) store(ctx context.Context, site *models.Site) (*models.File, error) {
+	task.ReportProgress(ctx, constants.JobStageVerify, 0, 0)
+	fileHash, err := a.inspect()
+	if err != nil {
+		return nil, err
+	}
+	if err := a.verify(ctx); err != nil {
+		return nil, err
+	}
+	// 去掉禁止的文件后的压缩包替换原压缩包 The archive without the disallowed files replaces the original
+	stripped, cleanup, err := ContentPolicy.checkArchive(ctx, site, a)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	if stripped != nil {
+		stripped.checksums = a.checksums
+		a = *stripped
+		if fileHash, err = a.inspect(); err != nil {
+			return nil, err
+		}
+		// 只记录最终发布的文件 Only files published in the end are recorded
+		names, err := a.names()
+		if err != nil {
+			return nil, err
+		}
+		kept := make(map[string]bool, len(names))
+		for _, name := range names {
+			kept[name] = true
+		}
+		for name := range a.checksums {
+			if !kept[name] {
+				delete(a.checksums, name)
+			}
+		}
+	}
+	if config.FileDedupe {
+		return a.storeFiles(ctx, site)
+	}
+	// 版本文件按内容寻址且不可修改，重复上传相同的压缩包时复用已有对象
+	// Version archives are content addressed and immutable, re-uploading an identical archive reuses the stored object
+	releaseKey := storage.ReleasePrefix + site.Name + "/sha256/" + fileHash + ".zip"
+	if file, err := store.File.GetByPath(ctx, releaseKey); err == nil {
+		return file, nil
+	}
+	size, err := a.contentSize()
+	if err != nil {
+		return nil, err
+	}
+	if err := Quota.checkDeployment(ctx, &site.Project, size, func() (int64, error) { return a.size, nil }); err != nil {
+		return nil, err
+	}
+	// 保存文件
+	upload, err := a.open()
+	if err != nil {
+		return nil, errArchiveStore
+	}
+	err = storage.Default.Put(ctx, releaseKey, task.ProgressReader(ctx, constants.JobStageUpload, upload, a.size), a.size)
+	_ = upload.Close()
+	if err != nil {
+		utils.Log.Ctx(ctx).Errorf("failed to store release file %s: %v", releaseKey, err)
+		return nil, errArchiveStore
+	}
 	// 创建文件记录
-	file := models.File{
-		Path: releaseSavePath,
+	file := &models.File{
+		Path: releaseKey,
 		Hash: fileHash,
+		Size: a.size,
 	}
-	if err := store.File.Create(&file); err != nil {
-		resps.InternalServerError(c, "create file record error")
-		return
+	if err := store.File.Create(ctx, file); err != nil {
+		return nil, errFileRecord
 	}
+	return file, nil
+}
+
+// publish 保存待发布的内容并创建站点版本，activate 为真时切换站点到新版本；失败时通知 webhook。
+// 上传、清单部署和 Git 推送部署共用此流程
+// Store the content and create a site version, switching the site to it when activate is set; failures notify webhooks.
+// Shared by uploads, manifest deployments and git push deployments
+func (ReleaseApi) publish(ctx context.Context, site *models.Site, source releaseSource, tag string, activate bool) (release *models.SiteRelease, previousID uint, err error) {
+	// 部署失败时通知 webhook Notify webhooks when the deployment fails
+	defer func() {
+		if err != nil {
+			Webhook.emit(ctx, &site.Project, constants.WebhookDeploymentFailed,
+				i18n.Msg("Deployment of %s to site %s failed: %s", tag, site.Name, err),
+				map[string]any{"site_id": site.ID, "site": site.Name, "tag": tag, "reason": err.Error()})
+		}
+	}()
+	file, err := source.store(ctx, site)
+	if err != nil {
+		return nil, 0, err
+	}
+	releaseKeyDir := storage.ReleasePrefix + site.Name + "/" + tag
 	// 创建发布记录
-	release := models.SiteRelease{
+	release = &models.SiteRelease{
 		SiteID: site.ID,
-		Tag:    req.Tag,
+		Tag:    tag,
 		FileID: file.ID,
+		File:   *file,
+		Status: constants.DeploymentStatusReady,
 	}
-	if err := store.Site.CreateRelease(&release); err != nil {
-		resps.InternalServerError(c, "create release record error")
-		return
+	// 记录校验通过的文件哈希 Record the verified file hashes
+	// 记录上传时提供的变更说明 Record the changelog given with the upload
+	switch source := source.(type) {
+	case releaseArchive:
+		if len(source.checksums) > 0 {
+			release.Checksums = source.checksums
+		}
+		release.Changelog = source.changelog
+	case releaseManifest:
+		release.Changelog = source.changelog
 	}
-	// 创建 latest 发布记录
-	latestRelease := models.SiteRelease{
-		SiteID: site.ID,
-		Tag:    "latest",
-		FileID: file.ID,
+	// 发现恶意文件的版本隔离等待审核，审核通过后按 activate 处理 Versions with malicious files are quarantined for review and handled by activate once approved
+	findings := Scan.check(ctx, site, file)
+	if len(findings) > 0 {
+		release.Status = constants.DeploymentStatusQuarantined
+		release.ScanFindings = Scan.encode(findings)
+		release.ScanActivate = activate
+		activate = false
+	} else if activate && site.Project.Protected {
+		// 受保护项目的新版本在维护者批准后才上线 New versions of protected projects only go live once a maintainer approves them
+		release.Status = constants.DeploymentStatusAwaitingApproval
+		release.ApprovalActivate = true
+		activate = false
 	}
-	if err := store.Site.CreateRelease(&latestRelease); err != nil {
-		resps.InternalServerError(c, "create release record error")
-		return
+	// 生成 open-graph 预览图，失败不影响发布
+	// Generate the open-graph preview image, failures do not block publishing
+	if site.OGPreview && activate {
+		if err := Release.generatePreview(ctx, site, release, releaseKeyDir); err != nil {
+			utils.Log.Ctx(ctx).Warnf("failed to generate preview for site %d: %v", site.ID, err)
+		}
 	}
-	// TODO 创建发布任务
-	resps.Ok(c, resps.OK, map[string]any{
-		"release": Release.ToDTO(&release),
-	})
+	if err := store.Site.CreateRelease(ctx, release); err != nil {
+		return nil, 0, errReleaseRecord
+	}
+	serve.Precompress(file)
+	if len(findings) > 0 {
+		Scan.quarantine(ctx, site, release, findings)
+	}
+	if release.Status == constants.DeploymentStatusAwaitingApproval {
+		Approval.request(ctx, site, release)
+	}
+	if !activate {
+		return release, 0, nil
+	}
+	// 切换 latest 指针到新版本 Point latest at the new version
+	task.ReportProgress(ctx, constants.JobStageActivate, 0, 0)
+	previousID, err = store.Site.ActivateRelease(ctx, release)
+	if err != nil {
+		return nil, 0, errActivate
+	}
+	serve.SiteCache.Forget(ctx, site.ID)
+	return release, previousID, nil
 }
 
 func (ReleaseApi) Delete(ctx context.Context, c *app.RequestContext) {
 	req := ReleaseIdReq{}
-	if err := c.BindAndValidate(&req); err != nil {
-		resps.BadRequest(c, resps.ParameterError)
+	if !bind(c, &req) {
+		return
+	}
+	site := getSite(c)
+	if site == nil {
+		resps.NotFound(c, resps.TargetNotFound)
 		return
 	}
 	// 获取 release
-	release, err := store.Site.GetReleaseById(req.ID)
+	release, err := store.Site.GetSiteRelease(site.ID, req.ID)
 	if err != nil {
 		resps.NotFound(c, resps.TargetNotFound)
 		return
 	}
-	// 删除文件
-	err = os.RemoveAll(release.File.Path)
-	if err != nil {
-		resps.InternalServerError(c, "delete file error")
+	err = store.Site.DeleteVersion(ctx, release)
+	if errors.Is(err, store.ErrReleaseActive) {
+		resps.BadRequest(c, "cannot delete the active release, activate another release first")
 		return
 	}
-	// 删除 release 记录
-	err = store.Site.DeleteRelease(release)
 	if err != nil {
-		resps.InternalServerError(c, "delete release record error")
+		utils.Log.Ctx(ctx).Errorf("failed to delete release %d: %v", release.ID, err)
+		resps.InternalServerError(c, "delete release error")
 		return
 	}
 	resps.Ok(c, resps.OK)
@@ -148,34 +904,484 @@ func (ReleaseApi) Delete(ctx context.Context, c *app.RequestContext) {
 
 func (ReleaseApi) Activation(ctx context.Context, c *app.RequestContext) {
 	req := ReleaseIdReq{}
-	if err := c.BindAndValidate(&req); err != nil {
-		resps.BadRequest(c, resps.ParameterError)
+	if !bind(c, &req) {
+		return
+	}
+	site := getSite(c)
+	if site == nil {
+		resps.NotFound(c, resps.TargetNotFound)
 		return
 	}
 	// 获取 release
-	release, err := store.Site.GetReleaseById(req.ID)
+	release, err := store.Site.GetSiteRelease(site.ID, req.ID)
 	if err != nil {
 		resps.NotFound(c, resps.TargetNotFound)
 		return
 	}
-	site := getSite(ctx)
-	if site == nil || site.ID != release.SiteID {
+	Release.activate(ctx, c, release, "activation")
+}
+
+// Rollback 回滚到指定版本，未指定时回滚到当前版本之前的版本
+// Roll back to the given version, or to the version before the active one when none is given
+func (ReleaseApi) Rollback(ctx context.Context, c *app.RequestContext) {
+	req := RollbackReq{}
+	if !bind(c, &req) {
+		return
+	}
+	site := getSite(c)
+	if site == nil {
 		resps.NotFound(c, resps.TargetNotFound)
 		return
 	}
-	// 获取 latest release
+	var release *models.SiteRelease
+	var err error
+	if req.ReleaseID != 0 {
+		release, err = store.Site.GetSiteRelease(site.ID, req.ReleaseID)
+		if err != nil {
+			resps.NotFound(c, resps.TargetNotFound)
+			return
+		}
+	} else {
+		activeID := Release.activeID(site)
+		if activeID == 0 {
+			resps.NotFound(c, "site has no active release")
+			return
+		}
+		release, err = store.Site.GetPreviousRelease(site.ID, activeID)
+		if err != nil {
+			resps.NotFound(c, "no earlier release to roll back to")
+			return
+		}
+	}
+	Release.activate(ctx, c, release, "rollback")
+}
+
+// notifyDeployed 通知 webhook 站点已切换到新的版本，检查所有者的存储用量，开启链接检查时检查新版本
+// Notify webhooks that the site now serves a new version, check the owner's storage usage and check the new version's links when link checks are on
+func (ReleaseApi) notifyDeployed(ctx context.Context, site *models.Site, release *models.SiteRelease, previousID uint) {
+	data := map[string]any{"site_id": site.ID, "site": site.Name, "release_id": release.ID, "tag": release.Tag, "previous_release_id": previousID}
+	if host := serve.CanonicalHost(site); host != "" {
+		data["url"] = "https://" + host + "/"
+	}
+	Webhook.emit(ctx, &site.Project, constants.WebhookDeploymentSucceeded,
+		i18n.Msg("Site %s deployed %s (release %d)", site.Name, release.Tag, release.ID), data)
+	CDN.purge(ctx, site, release.ID, nil)
+	Quota.warn(ctx, &site.Project)
+	LinkCheck.enqueue(ctx, site, release)
+}
+
+// activate 将站点切换到指定版本，reason 区分手动激活和回滚
+// Switch the site to the given version, reason tells manual activations from rollbacks
+func (ReleaseApi) activate(ctx context.Context, c *app.RequestContext, release *models.SiteRelease, reason string) {
+	// 手动激活定时上线的版本即提前上线，之后到时的任务不再生效 Activating a scheduled version by hand brings it live early, the job due later then does nothing
+	if release.Status == constants.DeploymentStatusScheduled {
+		release.Status = constants.DeploymentStatusReady
+		if err := store.Site.SetSchedule(release); err != nil {
+			resps.DBError(c, err, errSchedule.Error())
+			return
+		}
+	}
+	if !release.Status.Servable() {
+		resps.BadRequestf(c, "release is %s", release.Status)
+		return
+	}
+	previousID, err := store.Site.ActivateRelease(ctx, release)
+	if err != nil {
+		resps.DBError(c, err, "update latest release error")
+		return
+	}
+	serve.SiteCache.Forget(ctx, release.SiteID)
+	utils.Log.Ctx(ctx).Infof("site %d switched from release %d to release %d", release.SiteID, previousID, release.ID)
+	if site := getSite(c); site != nil {
+		if reason == "rollback" {
+			Webhook.emit(ctx, &site.Project, constants.WebhookDeploymentRollback,
+				i18n.Msg("Site %s rolled back to %s (release %d)", site.Name, release.Tag, release.ID),
+				map[string]any{"site_id": site.ID, "site": site.Name, "release_id": release.ID, "tag": release.Tag, "previous_release_id": previousID})
+			CDN.purge(ctx, site, release.ID, nil)
+		} else {
+			Release.notifyDeployed(ctx, site, release, previousID)
+		}
+	}
+	Audit.record(ctx, c, nil, constants.AuditReleaseActivate, constants.AuditTargetSite, release.SiteID, map[string]any{
+		"reason":              reason,
+		"release_id":          release.ID,
+		"tag":                 release.Tag,
+		"previous_release_id": previousID,
+	})
+	// TODO 创建发布任务
+	releaseDTO := Release.ToDTO(release)
+	releaseDTO.Active = true
+	resps.Ok(c, resps.OK, map[string]any{
+		"release":             releaseDTO,
+		"previous_release_id": previousID,
+	})
+}
+
+// activeID 站点当前激活的版本ID，没有时为 0
+// ID of the site's active version, 0 when there is none
+func (ReleaseApi) activeID(site *models.Site) uint {
 	latestRelease, err := store.Site.GetLatestRelease(site)
+	if err != nil || latestRelease.ActiveReleaseID == nil {
+		return 0
+	}
+	return *latestRelease.ActiveReleaseID
+}
+
+// PreviewImage 公开获取站点当前发布的 open-graph 预览图，仅限公开项目，私有实例不提供
+// Publicly serve the open-graph preview image of the site's active release, public projects only and never on private instances
+func (ReleaseApi) PreviewImage(ctx context.Context, c *app.RequestContext) {
+	siteID, err := strconv.Atoi(c.Param("site_id"))
 	if err != nil {
-		resps.InternalServerError(c, "get latest release error")
+		resps.BadRequest(c, resps.ParameterError)
 		return
 	}
-	// 修改 latest release
-	latestRelease.FileID = release.FileID
-	err = store.Site.UpdateRelease(latestRelease)
+	site, err := store.Site.GetByID(uint(siteID))
+	if err != nil || !site.OGPreview || site.Project.Visibility != constants.VisibilityPublic || serve.Protected(site) || serve.Private() {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	latestRelease, err := store.Site.GetLatestRelease(site)
+	if err != nil || latestRelease.PreviewPath == "" {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	object, err := storage.Default.Get(ctx, latestRelease.PreviewPath)
 	if err != nil {
-		resps.InternalServerError(c, "update latest release error")
+		resps.NotFound(c, resps.TargetNotFound)
 		return
 	}
-	// TODO 创建发布任务
-	resps.Ok(c, resps.OK)
+	c.Response.Header.Set("Cache-Control", "public, max-age=3600")
+	c.Response.Header.Set("ETag", `"`+latestRelease.PreviewHash+`"`)
+	c.Response.Header.SetContentType("image/png")
+	c.SetBodyStream(object, int(object.Info().Size))
+}
+
+// generatePreview 渲染预览图并写入发布目录，输入与当前发布一致时直接复用
+// Render the preview image into the release directory, reusing the active one when inputs are unchanged
+func (ReleaseApi) generatePreview(ctx context.Context, site *models.Site, release *models.SiteRelease, keyDir string) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(config.OGTimeout)*time.Second)
+	defer cancel()
+
+	card := &utils.PreviewCard{
+		Title:       site.Name,
+		Subtitle:    site.Project.Name,
+		Description: site.Description,
+		Brand:       config.OGBrand,
+	}
+	if site.Project.DisplayName != nil && *site.Project.DisplayName != "" {
+		card.Subtitle = *site.Project.DisplayName
+	}
+	// 所有者头像与组织模板 Owner avatar and organization template
+	// 上传的头像直接从存储读取 Uploaded avatars are read from the storage directly
+	var avatarURL, avatarKey, templatePath, templateVersion string
+	switch site.Project.OwnerType {
+	case constants.OwnerTypeUser:
+		if owner, err := store.User.GetByID(site.Project.OwnerID); err == nil {
+			if owner.AvatarHash != "" {
+				avatarKey = storage.AvatarKey(constants.OwnerTypeUser, owner.ID, owner.AvatarHash, utils.Avatar.Size(0))
+			} else if url := userAvatar(owner); url != nil {
+				avatarURL = *url
+			}
+		}
+	case constants.OwnerTypeOrg:
+		if org, err := store.Org.GetOrgById(site.Project.OwnerID); err == nil {
+			if org.AvatarHash != "" {
+				avatarKey = storage.AvatarKey(constants.OwnerTypeOrg, org.ID, org.AvatarHash, utils.Avatar.Size(0))
+			} else if url := orgAvatar(org); url != nil {
+				avatarURL = *url
+			}
+			if org.PreviewTemplate != nil {
+				templatePath = *org.PreviewTemplate
+				if template, err := storage.Default.Get(ctx, templatePath); err == nil {
+					templateVersion = template.Info().ModTime.String()
+					_ = template.Close()
+				}
+			}
+		}
+	}
+
+	hash := utils.Preview.Hash(card.Title, card.Subtitle, card.Description, card.Brand, avatarURL, avatarKey, templatePath, templateVersion)
+	if latestRelease, err := store.Site.GetLatestRelease(site); err == nil && latestRelease.PreviewHash == hash && latestRelease.PreviewPath != "" {
+		if preview, err := storage.Default.Get(ctx, latestRelease.PreviewPath); err == nil {
+			_ = preview.Close()
+			release.PreviewPath = latestRelease.PreviewPath
+			release.PreviewHash = hash
+			return nil
+		}
+	}
+
+	if avatarKey != "" {
+		if object, err := storage.Default.Get(ctx, avatarKey); err == nil {
+			if avatar, err := utils.Preview.LoadImage(object, config.OGMaxImageSize); err == nil {
+				card.Avatar = avatar
+			}
+			_ = object.Close()
+		}
+	} else if avatarURL != "" {
+		if avatar, err := utils.Preview.FetchImage(ctx, avatarURL, config.OGMaxImageSize); err == nil {
+			card.Avatar = avatar
+		}
+	}
+	if templatePath != "" {
+		if template, err := storage.Default.Get(ctx, templatePath); err == nil {
+			if background, err := utils.Preview.LoadImage(template, config.OGMaxImageSize); err == nil {
+				card.Background = background
+			}
+			_ = template.Close()
+		}
+	}
+	data, err := utils.Preview.Render(ctx, card)
+	if err != nil {
+		return err
+	}
+	previewKey := keyDir + "/og-" + hash[:12] + ".png"
+	if err := storage.Default.Put(ctx, previewKey, bytes.NewReader(data), int64(len(data))); err != nil {
+		return err
+	}
+	release.PreviewPath = previewKey
+	release.PreviewHash = hash
+	return nil
+}
+
+// PurgePaths 从当前发布中移除指定路径，生成新的派生发布并切换，保留历史发布以便回滚
+// Remove paths from the active release by creating a derived release and switching to it, keeping history for rollback
+func (ReleaseApi) PurgePaths(ctx context.Context, c *app.RequestContext) {
+	req := PurgePathsReq{}
+	if !bind(c, &req) {
+		return
+	}
+	site := getSite(c)
+	project := getProject(c)
+	if site == nil || project == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	user := middle.Auth.GetUser(ctx, c)
+	if user == nil {
+		return
+	}
+	if len(req.Paths) == 0 || len(req.Paths) > config.PurgeMaxPaths {
+		resps.BadRequestf(c, "between 1 and %d paths are required", config.PurgeMaxPaths)
+		return
+	}
+	for _, path := range req.Paths {
+		if !utils.ValidSitePathPattern(path) {
+			resps.BadRequest(c, "invalid path, wildcards are only allowed as a trailing /*: "+path)
+			return
+		}
+		// 前缀清除影响范围大，需要确认站点名称
+		// Prefix purges have a wide impact and require the site name as confirmation
+		if strings.HasSuffix(path, "/*") && req.Confirm != site.Name {
+			resps.BadRequest(c, "prefix purges require confirm to equal the site name")
+			return
+		}
+	}
+	latestRelease, err := store.Site.GetLatestRelease(site)
+	if err != nil {
+		resps.NotFound(c, "site has no active release")
+		return
+	}
+	// 每个项目每小时的操作次数限制，统计和占用在同一把锁内完成，并发的请求不会超出
+	// Per-project hourly action cap, counted and taken under one lock so concurrent requests cannot exceed it
+	purge := models.SitePathPurge{
+		SiteID:    site.ID,
+		ProjectID: project.ID,
+		UserID:    user.ID,
+		Paths:     req.Paths,
+		Takedown:  req.Takedown,
+	}
+	if err := store.Site.CreatePurge(ctx, &purge, time.Now().Add(-time.Hour), config.PurgeHourlyLimit); errors.Is(err, store.ErrPurgeLimit) {
+		resps.Custom(c, 429, "purge limit reached, try again later")
+		return
+	} else if err != nil {
+		resps.DBError(c, err, "create purge record error")
+		return
+	}
+	// 未能完成的清除不计入次数，也不留下屏蔽路径 A purge that cannot complete does not count and leaves no blocked paths
+	abort := func() {
+		if err := store.Site.DeletePurge(&purge); err != nil {
+			utils.Log.Ctx(ctx).Errorf("failed to delete purge %d: %v", purge.ID, err)
+		}
+		serve.SiteCache.Forget(ctx, site.ID)
+	}
+
+	// 生成去除路径后的派生发布文件 Create the derived release file without the paths
+	releaseTag := "purge-" + time.Now().Format("20060102150405")
+	drop := func(name string) bool {
+		for _, path := range req.Paths {
+			if utils.MatchSitePath(path, name) {
+				return true
+			}
+		}
+		return false
+	}
+	var file *models.File
+	var removed int
+	if latestRelease.File.Manifest {
+		file, removed, err = Release.purgeManifest(ctx, site, &latestRelease.File, drop)
+	} else {
+		file, removed, err = Release.purgeZip(ctx, site, &latestRelease.File, releaseTag, drop)
+	}
+	if err != nil {
+		abort()
+		resps.InternalServerError(c, err.Error())
+		return
+	}
+	if removed == 0 {
+		abort()
+		resps.NotFound(c, "no matching paths in the active release")
+		return
+	}
+	release := models.SiteRelease{
+		SiteID:      site.ID,
+		Tag:         releaseTag,
+		FileID:      file.ID,
+		File:        *file,
+		Status:      constants.DeploymentStatusReady,
+		PreviewPath: latestRelease.PreviewPath,
+		PreviewHash: latestRelease.PreviewHash,
+	}
+	if err := store.Site.CreateRelease(ctx, &release); err != nil {
+		abort()
+		resps.DBError(c, err, "create release record error")
+		return
+	}
+	// 切换 latest 指针 Flip the latest pointer
+	fromReleaseID, err := store.Site.ActivateRelease(ctx, &release)
+	if err != nil {
+		abort()
+		resps.DBError(c, err, "update latest release error")
+		return
+	}
+	if fromReleaseID == 0 {
+		fromReleaseID = latestRelease.ID
+	}
+
+	purge.Removed = removed
+	purge.FromReleaseID = fromReleaseID
+	purge.ToReleaseID = release.ID
+	if err := store.Site.FinishPurge(&purge); err != nil {
+		resps.DBError(c, err, "update purge record error")
+		return
+	}
+	serve.SiteCache.Forget(ctx, site.ID)
+	utils.Log.Ctx(ctx).Warnf("user %d purged %d file(s) from site %d (takedown=%v): %v", user.ID, removed, site.ID, req.Takedown, req.Paths)
+	Audit.record(ctx, c, user, constants.AuditSitePurgePaths, constants.AuditTargetSite, site.ID, map[string]any{
+		"paths":               req.Paths,
+		"takedown":            req.Takedown,
+		"removed":             removed,
+		"release_id":          release.ID,
+		"previous_release_id": fromReleaseID,
+	})
+	Release.notifyDeployed(ctx, site, &release, fromReleaseID)
+	resps.Ok(c, resps.OK, map[string]any{
+		"release": Release.ToDTO(&release),
+		"purge":   Release.toPurgeDTO(&purge),
+	})
+}
+
+// PurgeList 获取站点的路径清除记录
+// Get path purge records of the site
+func (ReleaseApi) PurgeList(ctx context.Context, c *app.RequestContext) {
+	site := getSite(c)
+	if site == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	page, limit := utils.Ctx.GetPageLimit(c)
+	purges, total, err := store.Site.ListPurges(ctx, site.ID, page, limit)
+	if err != nil {
+		resps.DBError(c, err, "get purges error")
+		return
+	}
+	utils.Ctx.SetPageHeaders(c, page, limit, total)
+	resps.Ok(c, resps.OK, map[string]any{
+		"purges": func() (purgeDTOs []PurgeDTO) {
+			for _, purge := range purges {
+				purgeDTOs = append(purgeDTOs, Release.toPurgeDTO(&purge))
+			}
+			return
+		}(),
+		"total": total,
+	})
+}
+
+// purgeZip 复制当前发布的压缩包并去除匹配的条目，没有匹配时不保存
+// Copy the active release archive without the matching entries, nothing is stored when none match
+func (ReleaseApi) purgeZip(ctx context.Context, site *models.Site, sourceFile *models.File, releaseTag string, drop func(name string) bool) (*models.File, int, error) {
+	releaseKey := storage.ReleasePrefix + site.Name + "/" + releaseTag + "/" + releaseTag + ".zip"
+	source, err := storage.Default.Get(ctx, sourceFile.Path)
+	if err != nil {
+		return nil, 0, errors.New("open release file error")
+	}
+	defer source.Close()
+	// 先写入临时文件以便计算哈希并获得长度 Write to a temporary file first to hash it and know its length
+	derived, err := os.CreateTemp("", "spage-purge-*.zip")
+	if err != nil {
+		return nil, 0, errors.New("rewrite release file error")
+	}
+	defer func() {
+		_ = derived.Close()
+		_ = os.Remove(derived.Name())
+	}()
+	removed, err := utils.RewriteZipWithout(source, source.Info().Size, derived, drop)
+	if err != nil {
+		return nil, 0, errors.New("rewrite release file error")
+	}
+	if removed == 0 {
+		return nil, 0, nil
+	}
+	if _, err := derived.Seek(0, io.SeekStart); err != nil {
+		return nil, 0, errArchiveHash
+	}
+	fileHash, err := utils.ReaderHash(derived)
+	if err != nil {
+		return nil, 0, errArchiveHash
+	}
+	stat, err := derived.Stat()
+	if err == nil {
+		_, err = derived.Seek(0, io.SeekStart)
+	}
+	if err == nil {
+		err = storage.Default.Put(ctx, releaseKey, derived, stat.Size())
+	}
+	if err != nil {
+		utils.Log.Ctx(ctx).Errorf("failed to store release file %s: %v", releaseKey, err)
+		return nil, 0, errArchiveStore
+	}
+	file := &models.File{
+		Path: releaseKey,
+		Hash: fileHash,
+		Size: stat.Size(),
+	}
+	if err := store.File.Create(ctx, file); err != nil {
+		return nil, 0, errFileRecord
+	}
+	return file, removed, nil
+}
+
+// purgeManifest 从当前发布的部署清单中去除匹配的文件并保存新清单，文件内容无需复制
+// Drop the matching files from the active release manifest and store the new manifest, file contents need no copying
+func (ReleaseApi) purgeManifest(ctx context.Context, site *models.Site, sourceFile *models.File, drop func(name string) bool) (*models.File, int, error) {
+	source, err := storage.Default.Get(ctx, sourceFile.Path)
+	if err != nil {
+		return nil, 0, errors.New("open release file error")
+	}
+	manifest, err := utils.DecodeManifest(source)
+	_ = source.Close()
+	if err != nil {
+		return nil, 0, errors.New("read release manifest error")
+	}
+	derived := &utils.SiteManifest{}
+	for _, f := range manifest.Files {
+		if !drop(f.Path) {
+			derived.Files = append(derived.Files, f)
+		}
+	}
+	removed := len(manifest.Files) - len(derived.Files)
+	if removed == 0 {
+		return nil, 0, nil
+	}
+	file, err := saveManifest(ctx, site, derived)
+	return file, removed, err
 }
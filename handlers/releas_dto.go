@@ -1,22 +1,109 @@
 package handlers
 
 import (
-	"github.com/LiteyukiStudio/spage/models"
 	"mime/multipart"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/scan"
+	"github.com/LiteyukiStudio/spage/serve"
 )
 
 type ReleaseDTO struct {
-	ID   uint        `json:"id"`
-	Site SiteDTO     `json:"site"`
-	Tag  string      `json:"tag"`
-	File models.File `json:"file"`
+	ID     uint                       `json:"id"`
+	Site   SiteDTO                    `json:"site"`
+	Tag    string                     `json:"tag"`
+	File   models.File                `json:"file"`
+	Status constants.DeploymentStatus `json:"status"`
+	Active bool                       `json:"active"` // 是否为当前激活的版本 Whether it is the active version
+	Pinned bool                       `json:"pinned"` // 是否固定，固定的版本不会被保留策略删除 Whether pinned, pinned versions are never removed by the retention policy
+
+	PublishAt    *time.Time `json:"publish_at,omitempty"`    // 定时上线时间 Scheduled go-live time
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`    // 到期时间 Expiry time
+	ExpireAction string     `json:"expire_action,omitempty"` // 到期处理方式 Expiry action
+
+	ScanFindings []scan.Finding `json:"scan_findings,omitempty"` // 内容扫描发现的恶意文件 Malicious files found by the content scan
+
+	VerifiedFiles int `json:"verified_files,omitempty"` // 上传时提供哈希并校验通过的文件数 Number of files verified against the hashes given with the upload
+
+	Changelog string `json:"changelog,omitempty"` // 上传时提供的变更说明 Changelog given with the upload
+
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type CreateReleaseReq struct {
-	Tag  string                `json:"tag" binding:"required"`
-	File *multipart.FileHeader `json:"file" binding:"required"`
+	Tag  string                `json:"tag" form:"tag" binding:"required"`
+	File *multipart.FileHeader `json:"file" form:"file" binding:"required"`
+
+	Preview string `json:"preview" form:"preview"` // 分支或合并请求标识，填写时作为预览部署 Branch or pull request identifier, makes it a preview deployment
+	TTL     int    `json:"ttl" form:"ttl"`         // 预览有效期，单位秒，0 为默认值 Preview lifetime in seconds, 0 for the default
+	Async   bool   `json:"async" form:"async"`     // 在后台任务中解压发布，立即返回任务 Unpack and publish in a background job, returning the job right away
+
+	PublishAt    string `json:"publish_at" form:"publish_at"`       // RFC 3339 定时上线时间，到时才切换站点 RFC 3339 go-live time, the site switches only then
+	ExpiresAt    string `json:"expires_at" form:"expires_at"`       // RFC 3339 到期时间 RFC 3339 expiry time
+	ExpireAction string `json:"expire_action" form:"expire_action"` // 到期处理方式 rollback 或 offline，默认 rollback Expiry action, rollback or offline, rollback by default
+
+	Changelog string `json:"changelog" form:"changelog" validate:"max=10000"` // 版本的变更说明，显示在部署订阅源中 Changelog of the version, shown in the deployment feeds
+
+	Checksums string `json:"checksums" form:"checksums"` // 文件 SHA-256 清单，路径到哈希的 JSON 对象或 sha256sum 的输出，解压后逐个校验 SHA-256 list of the files, a JSON object mapping paths to hashes or sha256sum output, each verified after extraction
 }
 
 type ReleaseIdReq struct {
 	ID uint `json:"id" binding:"required"`
 }
+
+// RollbackReq 回滚请求参数，未指定版本时回滚到当前版本之前的版本
+// Rollback request parameters, rolling back to the version before the active one when none is given
+type RollbackReq struct {
+	ReleaseID uint `json:"release_id"` // 目标版本ID Target version ID
+}
+
+// DiffDeploymentsReq 比较部署版本请求参数
+// Compare deployments request parameters
+type DiffDeploymentsReq struct {
+	From uint `query:"from" binding:"required"` // 基准版本ID Base version ID
+	To   uint `query:"to"`                      // 比较的版本ID，默认为当前版本 Version compared, the active one by default
+}
+
+// DeploymentDiffDTO 两个部署版本之间的文件差异
+// File differences between two deployments
+type DeploymentDiffDTO struct {
+	FromReleaseID uint `json:"from_release_id"`
+	ToReleaseID   uint `json:"to_release_id"`
+	serve.ReleaseDiff
+}
+
+// PurgePathsReq 清除站点路径请求参数
+// Purge site paths request parameters
+type PurgePathsReq struct {
+	Paths    []string `query:"path"`     // 要清除的路径，可重复，前缀形式为 /dir/* Paths to purge, repeatable, prefix form is /dir/*
+	Confirm  string   `query:"confirm"`  // 批量前缀清除时需填写站点名称确认 Site name confirmation required for prefix purges
+	Takedown bool     `query:"takedown"` // 是否下架，下架后历史发布也无法访问 Whether it is a takedown, blocking historic releases too
+}
+
+// PurgeDTO 路径清除记录
+// Path purge record
+type PurgeDTO struct {
+	ID            uint      `json:"id"`
+	UserID        uint      `json:"user_id"`
+	Paths         []string  `json:"paths"`
+	Removed       int       `json:"removed"`
+	FromReleaseID uint      `json:"from_release_id"`
+	ToReleaseID   uint      `json:"to_release_id"`
+	Takedown      bool      `json:"takedown"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// PreviewDTO 预览部署
+// Preview deployment
+type PreviewDTO struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Host      string    `json:"host"` // 预览访问地址 Host serving the preview
+	ReleaseID uint      `json:"release_id"`
+	Tag       string    `json:"tag"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
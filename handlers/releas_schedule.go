@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/i18n"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/serve"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/task"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+	"gorm.io/gorm"
+)
+
+// releaseSchedule 部署的定时上线和到期设置，均为空时立即上线且不会到期
+// Scheduled go-live and expiry settings of a deployment, going live right away and never expiring when empty
+type releaseSchedule struct {
+	PublishAt    *time.Time `json:"publish_at,omitempty"`    // 定时上线时间 Scheduled go-live time
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`    // 到期时间 Expiry time
+	ExpireAction string     `json:"expire_action,omitempty"` // 到期处理方式 Expiry action
+}
+
+// 定时任务的动作 Actions of schedule jobs
+const (
+	scheduleActionPublish = "publish" // 上线定时版本 Bring a scheduled version live
+	scheduleActionExpire  = "expire"  // 处理到期的版本 Handle an expired version
+)
+
+// scheduleJobPayload 定时任务的参数，At 与版本当前的设置不一致时说明设置已变更，任务不再生效
+// Parameters of a schedule job, an At differing from the version's current settings means they changed and the job does nothing
+type scheduleJobPayload struct {
+	ReleaseID uint      `json:"release_id"` // 版本ID Version ID
+	Action    string    `json:"action"`     // publish 或 expire Publish or expire
+	At        time.Time `json:"at"`         // 计划执行时间 Planned time
+}
+
+// checkSchedule 解析并校验 RFC 3339 格式的定时上线和到期参数，预览部署不能定时；校验失败时已写入响应
+// Parse and validate the RFC 3339 go-live and expiry parameters, previews cannot be scheduled; the response is already written on failure
+func (ReleaseApi) checkSchedule(c *app.RequestContext, previewName, publishAt, expiresAt, action string) (releaseSchedule, bool) {
+	schedule := releaseSchedule{}
+	if publishAt == "" && expiresAt == "" {
+		if action != "" {
+			resps.BadRequest(c, "expire_action requires expires_at")
+			return schedule, false
+		}
+		return schedule, true
+	}
+	if previewName != "" {
+		resps.BadRequest(c, "preview deployments cannot be scheduled, use ttl instead")
+		return schedule, false
+	}
+	now := time.Now()
+	if publishAt != "" {
+		at, err := time.Parse(time.RFC3339, publishAt)
+		if err != nil || !at.After(now) {
+			resps.BadRequest(c, "publish_at must be a future RFC 3339 time")
+			return schedule, false
+		}
+		schedule.PublishAt = &at
+	}
+	if expiresAt == "" {
+		if action != "" {
+			resps.BadRequest(c, "expire_action requires expires_at")
+			return schedule, false
+		}
+		return schedule, true
+	}
+	at, err := time.Parse(time.RFC3339, expiresAt)
+	if err != nil || !at.After(now) {
+		resps.BadRequest(c, "expires_at must be a future RFC 3339 time")
+		return schedule, false
+	}
+	if schedule.PublishAt != nil && !at.After(*schedule.PublishAt) {
+		resps.BadRequest(c, "expires_at must be after publish_at")
+		return schedule, false
+	}
+	if action == "" {
+		action = constants.ReleaseExpireRollback
+	}
+	if !slices.Contains(constants.ReleaseExpireActions, action) {
+		resps.BadRequestf(c, "expire_action must be one of %v", constants.ReleaseExpireActions)
+		return schedule, false
+	}
+	schedule.ExpiresAt = &at
+	schedule.ExpireAction = action
+	return schedule, true
+}
+
+// schedule 保存新版本的定时设置并创建到时执行的任务，定时上线的版本在上线前保持 scheduled 状态，隔离的版本保持隔离直到审核，
+// 受保护项目的版本先等待批准
+// Save the schedule of a new version and create the jobs due at its times, a version scheduled to go live stays scheduled until then and a quarantined one stays quarantined until reviewed,
+// versions of protected projects wait for approval first
+func (ReleaseApi) schedule(ctx context.Context, site *models.Site, release *models.SiteRelease, schedule releaseSchedule, userID uint) error {
+	if schedule.PublishAt == nil && schedule.ExpiresAt == nil {
+		return nil
+	}
+	awaiting := false
+	if schedule.PublishAt != nil && release.Status != constants.DeploymentStatusQuarantined {
+		release.Status = constants.DeploymentStatusScheduled
+		if site.Project.Protected {
+			release.Status = constants.DeploymentStatusAwaitingApproval
+			awaiting = true
+		}
+	}
+	release.PublishAt = schedule.PublishAt
+	release.ExpiresAt = schedule.ExpiresAt
+	release.ExpireAction = schedule.ExpireAction
+	if err := store.Site.SetSchedule(release); err != nil {
+		return err
+	}
+	if schedule.PublishAt != nil {
+		payload := scheduleJobPayload{ReleaseID: release.ID, Action: scheduleActionPublish, At: *schedule.PublishAt}
+		if _, err := task.EnqueueJobAt(ctx, constants.JobTypeSchedule, payload, release.SiteID, userID, *schedule.PublishAt); err != nil {
+			return err
+		}
+	}
+	if schedule.ExpiresAt != nil {
+		payload := scheduleJobPayload{ReleaseID: release.ID, Action: scheduleActionExpire, At: *schedule.ExpiresAt}
+		if _, err := task.EnqueueJobAt(ctx, constants.JobTypeSchedule, payload, release.SiteID, userID, *schedule.ExpiresAt); err != nil {
+			return err
+		}
+	}
+	if awaiting {
+		Approval.request(ctx, site, release)
+	}
+	utils.Log.Ctx(ctx).Infof("release %d of site %d scheduled, publish at %v, expires at %v", release.ID, release.SiteID, schedule.PublishAt, schedule.ExpiresAt)
+	return nil
+}
+
+// runScheduleJob 到时上线定时版本或处理到期的版本；版本已删除、已提前上线或不再激活时什么也不做
+// Bring a scheduled version live or handle an expired one when due; does nothing once the version was deleted, brought live early or is no longer active
+func (ReleaseApi) runScheduleJob(ctx context.Context, job *models.Job) (any, error) {
+	payload := scheduleJobPayload{}
+	if err := json.Unmarshal([]byte(job.Payload), &payload); err != nil {
+		return nil, task.Permanent(err)
+	}
+	site, err := store.Site.GetByID(job.SiteID)
+	if err != nil {
+		return nil, task.Permanent(errors.New("site not found"))
+	}
+	release, err := store.Site.GetSiteRelease(site.ID, payload.ReleaseID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return map[string]any{"skipped": "release deleted"}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	actor, err := store.User.GetByID(job.UserID)
+	if err != nil {
+		actor = nil
+	}
+	switch payload.Action {
+	case scheduleActionPublish:
+		return Release.publishScheduled(ctx, actor, site, release, payload.At)
+	case scheduleActionExpire:
+		return Release.expire(ctx, actor, site, release, payload.At)
+	}
+	return nil, task.Permanent(fmt.Errorf("unknown schedule action %q", payload.Action))
+}
+
+// publishScheduled 将站点切换到到时的定时版本
+// Switch the site to a scheduled version that is due
+func (ReleaseApi) publishScheduled(ctx context.Context, actor *models.User, site *models.Site, release *models.SiteRelease, at time.Time) (any, error) {
+	if release.Status != constants.DeploymentStatusScheduled || release.PublishAt == nil || !release.PublishAt.Equal(at) {
+		return map[string]any{"skipped": "release is no longer scheduled"}, nil
+	}
+	release.Status = constants.DeploymentStatusReady
+	if err := store.Site.SetSchedule(release); err != nil {
+		return nil, err
+	}
+	previousID, err := store.Site.ActivateRelease(ctx, release)
+	if err != nil {
+		return nil, errActivate
+	}
+	serve.SiteCache.Forget(ctx, site.ID)
+	utils.Log.Ctx(ctx).Infof("site %d switched from release %d to scheduled release %d", site.ID, previousID, release.ID)
+	Audit.record(ctx, nil, actor, constants.AuditReleaseActivate, constants.AuditTargetSite, site.ID, map[string]any{
+		"reason":              "schedule",
+		"release_id":          release.ID,
+		"tag":                 release.Tag,
+		"previous_release_id": previousID,
+	})
+	Release.notifyDeployed(ctx, site, release, previousID)
+	return map[string]any{"release_id": release.ID, "previous_release_id": previousID}, nil
+}
+
+// expire 处理到期时仍激活的版本：回滚到之前的可用版本，没有可用版本或设置为 offline 时下线站点
+// Handle a version still active at its expiry: roll back to the previous servable version, taking the site offline when there is none or the action is offline
+func (ReleaseApi) expire(ctx context.Context, actor *models.User, site *models.Site, release *models.SiteRelease, at time.Time) (any, error) {
+	if release.ExpiresAt == nil || !release.ExpiresAt.Equal(at) {
+		return map[string]any{"skipped": "expiry changed"}, nil
+	}
+	if Release.activeID(site) != release.ID {
+		return map[string]any{"skipped": "release is no longer active"}, nil
+	}
+	var target *models.SiteRelease
+	if release.ExpireAction == constants.ReleaseExpireRollback {
+		previous, err := store.Site.GetPreviousRelease(site.ID, release.ID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if err == nil {
+			target = previous
+		}
+	}
+	result := map[string]any{"release_id": release.ID, "tag": release.Tag, "action": release.ExpireAction}
+	message := i18n.Msg("Release %s of site %s expired, the site is offline", release.Tag, site.Name)
+	if target != nil {
+		if _, err := store.Site.ActivateRelease(ctx, target); err != nil {
+			return nil, errActivate
+		}
+		result["to_release_id"] = target.ID
+		message = i18n.Msg("Release %s of site %s expired, rolled back to %s (release %d)", release.Tag, site.Name, target.Tag, target.ID)
+	} else if _, err := store.Site.DeactivateRelease(ctx, site.ID); err != nil {
+		return nil, errActivate
+	}
+	serve.SiteCache.Forget(ctx, site.ID)
+	utils.Log.Ctx(ctx).Infof("release %d of site %d expired", release.ID, site.ID)
+	Audit.record(ctx, nil, actor, constants.AuditReleaseExpire, constants.AuditTargetSite, site.ID, result)
+	data := map[string]any{"site_id": site.ID, "site": site.Name}
+	for key, value := range result {
+		data[key] = value
+	}
+	Webhook.emit(ctx, &site.Project, constants.WebhookDeploymentExpired, message, data)
+	var toReleaseID uint
+	if target != nil {
+		toReleaseID = target.ID
+	}
+	CDN.purge(ctx, site, toReleaseID, nil)
+	return result, nil
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+type RetentionApi struct{}
+
+// Retention 版本保留策略，定时任务删除既不在最新的若干个版本中也不在最近天数内的版本，激活和固定的版本总是保留
+// Release retention policies, the scheduled task removes versions that are neither among the newest ones kept nor within the days kept, the active and pinned versions are always kept
+var Retention = RetentionApi{}
+
+func (RetentionApi) toDTO(project *models.Project) RetentionDTO {
+	keepLast, keepDays := store.EffectiveRetention(project.KeepReleases, project.KeepReleaseDays)
+	return RetentionDTO{
+		ProjectID:       project.ID,
+		KeepReleases:    project.KeepReleases,
+		KeepReleaseDays: project.KeepReleaseDays,
+		EffectiveKeep:   keepLast,
+		EffectiveDays:   keepDays,
+	}
+}
+
+// Get 获取项目的版本保留策略
+// Get the release retention policy of a project
+func (RetentionApi) Get(ctx context.Context, c *app.RequestContext) {
+	project := getProject(c)
+	if project == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"retention": Retention.toDTO(project),
+	})
+}
+
+// Update 所有者修改项目的版本保留策略，在下一次 retention 定时任务时生效
+// Owners change the release retention policy of a project, applied by the next run of the retention scheduled task
+func (RetentionApi) Update(ctx context.Context, c *app.RequestContext) {
+	project := getProject(c)
+	if project == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	req := RetentionReq{}
+	if !bindJSON(c, &req) {
+		return
+	}
+	if err := store.Project.SetRetention(project, req.KeepReleases, req.KeepReleaseDays); err != nil {
+		resps.DBError(c, err, "update retention error")
+		return
+	}
+	Audit.record(ctx, c, nil, constants.AuditRetentionUpdate, constants.AuditTargetProject, project.ID, map[string]any{
+		"keep_releases":     req.KeepReleases,
+		"keep_release_days": req.KeepReleaseDays,
+	})
+	resps.Ok(c, resps.OK, map[string]any{
+		"retention": Retention.toDTO(project),
+	})
+}
+
+// setPinned 固定或取消固定路径中的版本，固定的版本不会被保留策略删除
+// Pin or unpin the version of the path, pinned versions are never removed by the retention policy
+func (RetentionApi) setPinned(ctx context.Context, c *app.RequestContext, pinned bool) {
+	site, release := LinkCheck.getRelease(c)
+	if release == nil {
+		return
+	}
+	if err := store.Site.SetPinned(release, pinned); err != nil {
+		resps.DBError(c, err, "pin release error")
+		return
+	}
+	Audit.record(ctx, c, nil, constants.AuditReleasePin, constants.AuditTargetSite, site.ID, map[string]any{
+		"release_id": release.ID,
+		"tag":        release.Tag,
+		"pinned":     pinned,
+	})
+	release.Site = *site
+	releaseDTO := Release.ToDTO(release)
+	releaseDTO.Active = release.ID == Release.activeID(site)
+	resps.Ok(c, resps.OK, map[string]any{
+		"release": releaseDTO,
+	})
+}
+
+// Pin 固定版本
+// Pin a version
+func (RetentionApi) Pin(ctx context.Context, c *app.RequestContext) {
+	Retention.setPinned(ctx, c, true)
+}
+
+// Unpin 取消固定版本
+// Unpin a version
+func (RetentionApi) Unpin(ctx context.Context, c *app.RequestContext) {
+	Retention.setPinned(ctx, c, false)
+}
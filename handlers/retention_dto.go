@@ -0,0 +1,18 @@
+package handlers
+
+// RetentionDTO 项目的版本保留策略，为空的条件使用实例配置
+// Release retention policy of a project, empty conditions fall back to the instance configuration
+type RetentionDTO struct {
+	ProjectID       uint `json:"project_id"`        // 项目ID Project ID
+	KeepReleases    *int `json:"keep_releases"`     // 项目设置的保留版本数 Versions kept as set on the project
+	KeepReleaseDays *int `json:"keep_release_days"` // 项目设置的保留天数 Days kept as set on the project
+	EffectiveKeep   int  `json:"effective_keep"`    // 生效的保留版本数，0 不按数量保留 Versions kept in effect, 0 keeps none by count
+	EffectiveDays   int  `json:"effective_days"`    // 生效的保留天数，0 不按时间保留 Days kept in effect, 0 keeps none by age
+}
+
+// RetentionReq 修改项目版本保留策略的请求参数，为空时使用实例配置，两个条件都为 0 时不清理
+// Request parameters to change the release retention policy of a project, empty values fall back to the instance configuration and nothing is removed while both conditions are 0
+type RetentionReq struct {
+	KeepReleases    *int `json:"keep_releases" validate:"min=0,max=10000"`    // 保留最新的版本数 Newest versions kept
+	KeepReleaseDays *int `json:"keep_release_days" validate:"min=0,max=3650"` // 保留最近天数内的版本 Versions of the last days kept
+}
@@ -0,0 +1,399 @@
+package handlers
+
+import (
+	"cmp"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/middle"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/task"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/sirupsen/logrus"
+)
+
+type RunnerApi struct{}
+
+// Runner 远程构建执行器，管理员创建执行器并将令牌交给 spage runner，执行器注册后通过心跳保持在线，领取构建、上报输出并上传结果
+// Remote build runners, administrators create a runner and hand its token to spage runner, which registers, stays online through heartbeats, claims builds, reports output and uploads results
+var Runner = RunnerApi{}
+
+const (
+	// runnerWatchInterval 检查租约过期构建的间隔
+	// Interval between checks for builds whose lease expired
+	runnerWatchInterval = 15 * time.Second
+	// runnerExpireBatch 每轮处理的租约过期构建数量上限
+	// Max number of builds with an expired lease handled per round
+	runnerExpireBatch = 100
+)
+
+// runnerLabelPattern 执行器标签 Runner labels
+var runnerLabelPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,31}$`)
+
+// runnerLease 构建租约和执行器心跳的超时时间
+// Timeout of build leases and runner heartbeats
+func runnerLease() time.Duration {
+	return time.Duration(max(config.GitRunnerTimeout, 1)) * time.Second
+}
+
+func (RunnerApi) toDTO(runner *models.Runner) RunnerDTO {
+	labels := runner.Labels
+	if labels == nil {
+		labels = []string{}
+	}
+	return RunnerDTO{
+		ID:          runner.ID,
+		Name:        runner.Name,
+		Prefix:      runner.Prefix,
+		Labels:      labels,
+		Concurrency: runner.Concurrency,
+		Version:     runner.Version,
+		Online:      runner.LastSeenAt != nil && time.Since(*runner.LastSeenAt) < runnerLease(),
+		LastSeenAt:  runner.LastSeenAt,
+		CreatedAt:   runner.CreatedAt,
+	}
+}
+
+// AdminList 获取所有执行器及其是否在线
+// List all runners and whether they are online
+func (RunnerApi) AdminList(ctx context.Context, c *app.RequestContext) {
+	runners, err := store.Runner.List()
+	if err != nil {
+		resps.DBError(c, err, "get runners error")
+		return
+	}
+	dtos := make([]RunnerDTO, 0, len(runners))
+	for i := range runners {
+		dtos = append(dtos, Runner.toDTO(&runners[i]))
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"runners": dtos,
+	})
+}
+
+// AdminCreate 创建执行器，令牌明文只在此时返回
+// Create a runner, the plain token is only returned here
+func (RunnerApi) AdminCreate(ctx context.Context, c *app.RequestContext) {
+	req := CreateRunnerReq{}
+	if !bindJSON(c, &req) {
+		return
+	}
+	plain, hash, err := utils.Token.NewRunnerToken()
+	if err != nil {
+		resps.InternalServerError(c, "create runner error")
+		return
+	}
+	runner := &models.Runner{Name: req.Name, Prefix: plain[:tokenPrefixLength], Hash: hash, Concurrency: 1}
+	if err := store.Runner.Create(runner); err != nil {
+		resps.DBError(c, err, "create runner error")
+		return
+	}
+	Audit.record(ctx, c, nil, constants.AuditRunnerCreate, constants.AuditTargetRunner, runner.ID, map[string]any{"name": runner.Name, "prefix": runner.Prefix})
+	resps.Ok(c, resps.OK, map[string]any{
+		"runner":    Runner.toDTO(runner),
+		"plaintext": plain,
+	})
+}
+
+// AdminDelete 删除执行器，令牌立即失效，其正在执行的构建在租约过期后视为失败
+// Delete a runner, its token stops working at once and its running builds fail once their lease expires
+func (RunnerApi) AdminDelete(ctx context.Context, c *app.RequestContext) {
+	id, err := strconv.Atoi(c.Param("runner_id"))
+	if err != nil {
+		resps.BadRequest(c, resps.ParameterError)
+		return
+	}
+	runner, err := store.Runner.GetByID(uint(id))
+	if err != nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	if err := store.Runner.Delete(runner); err != nil {
+		resps.DBError(c, err, "delete runner error")
+		return
+	}
+	Audit.record(ctx, c, nil, constants.AuditRunnerDelete, constants.AuditTargetRunner, runner.ID, map[string]any{"name": runner.Name, "prefix": runner.Prefix})
+	resps.Ok(c, resps.OK)
+}
+
+// Register 执行器启动时上报标签、并发数和版本，返回心跳间隔和构建超时时间
+// A runner reports its labels, concurrency and version when it starts, receiving the heartbeat interval and the build timeout
+func (RunnerApi) Register(ctx context.Context, c *app.RequestContext) {
+	runner := middle.Auth.GetRunner(c)
+	req := RegisterRunnerReq{}
+	if !bindJSON(c, &req) {
+		return
+	}
+	for _, label := range req.Labels {
+		if !runnerLabelPattern.MatchString(label) {
+			resps.BadRequest(c, "invalid runner label "+label)
+			return
+		}
+	}
+	runner.Labels = req.Labels
+	runner.Concurrency = req.Concurrency
+	runner.Version = req.Version
+	if err := store.Runner.Register(runner, time.Now()); err != nil {
+		resps.DBError(c, err, "register runner error")
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"runner":             Runner.toDTO(runner),
+		"heartbeat_interval": max(config.GitRunnerTimeout/3, 1),
+		"build_timeout":      config.GitBuildTimeout,
+	})
+}
+
+// Heartbeat 记录执行器在线并延长其正在执行的构建的租约，返回仍在执行的构建ID，不在其中的构建执行器应当放弃
+// Record that the runner is online and extend the lease of its running builds, returning the IDs of the builds still running; the runner should abandon any other build
+func (RunnerApi) Heartbeat(ctx context.Context, c *app.RequestContext) {
+	runner := middle.Auth.GetRunner(c)
+	now := time.Now()
+	running, err := store.Runner.Heartbeat(runner, now, now.Add(runnerLease()))
+	if err != nil {
+		resps.DBError(c, err, "record heartbeat error")
+		return
+	}
+	if running == nil {
+		running = []uint{}
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"running": running,
+	})
+}
+
+// Claim 领取一个标签匹配的排队构建，没有可领取的构建时 build 为空
+// Claim a queued build whose labels match, build is null when nothing is claimable
+func (RunnerApi) Claim(ctx context.Context, c *app.RequestContext) {
+	runner := middle.Auth.GetRunner(c)
+	now := time.Now()
+	build, err := store.Runner.ClaimBuild(runner, now, now.Add(runnerLease()))
+	if err != nil {
+		resps.DBError(c, err, "claim build error")
+		return
+	}
+	if build == nil {
+		resps.Ok(c, resps.OK, map[string]any{
+			"build": nil,
+		})
+		return
+	}
+	Git.saveRun(ctx, &build.Integration, build.Commit, constants.GitRunBuilding, "", "")
+	integration := &build.Integration
+	resps.Ok(c, resps.OK, map[string]any{
+		"build": RunnerBuildDTO{
+			ID:           build.ID,
+			Provider:     integration.Provider,
+			RepoURL:      integration.RepoURL,
+			Token:        integration.Token,
+			Branch:       integration.Branch,
+			Commit:       build.Commit,
+			BuildCommand: integration.BuildCommand,
+			BuildImage:   integration.BuildImage,
+			OutputDir:    integration.OutputDir,
+		},
+	})
+}
+
+// getBuild 获取路径中指定的、当前执行器正在执行的构建，不存在或已结束时返回 404
+// Get the build given in the path that the current runner is running, answering 404 when it does not exist or already finished
+func (RunnerApi) getBuild(c *app.RequestContext) *models.RunnerBuild {
+	id, err := strconv.Atoi(c.Param("build_id"))
+	if err != nil {
+		resps.BadRequest(c, resps.ParameterError)
+		return nil
+	}
+	build, err := store.Runner.GetBuild(middle.Auth.GetRunner(c).ID, uint(id))
+	if err != nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return nil
+	}
+	return build
+}
+
+// Log 保存构建的输出，站点的实时构建日志随之更新
+// Save the output of a build, updating the live build log of the site
+func (RunnerApi) Log(ctx context.Context, c *app.RequestContext) {
+	build := Runner.getBuild(c)
+	if build == nil {
+		return
+	}
+	req := RunnerLogReq{}
+	if !bindJSON(c, &req) {
+		return
+	}
+	if err := store.Git.SaveLog(build.IntegrationID, tail(req.Log, task.GitLogLimit)); err != nil {
+		resps.DBError(c, err, "save build output error")
+		return
+	}
+	resps.Ok(c, resps.OK)
+}
+
+// Fail 结束失败的构建
+// Finish a failed build
+func (RunnerApi) Fail(ctx context.Context, c *app.RequestContext) {
+	build := Runner.getBuild(c)
+	if build == nil {
+		return
+	}
+	req := RunnerFailReq{}
+	if !bindJSON(c, &req) {
+		return
+	}
+	if !Runner.finish(c, build, constants.GitRunFailed, req.Error) {
+		return
+	}
+	Runner.fail(ctx, build, cmp.Or(req.Commit, build.Commit), req.Error, tail(req.Log, task.GitLogLimit))
+	resps.Ok(c, resps.OK)
+}
+
+// UploadLimit 构建结果的请求体限制，与站点所属项目的单次上传限制相同
+// Body limit of build results, the same as the single upload limit of the site's project
+func (RunnerApi) UploadLimit(ctx context.Context, c *app.RequestContext) int64 {
+	id, err := strconv.Atoi(c.Param("build_id"))
+	if err != nil {
+		return int64(config.UploadMaxSize) << 20
+	}
+	build, err := store.Runner.GetBuild(middle.Auth.GetRunner(c).ID, uint(id))
+	if err != nil {
+		return int64(config.UploadMaxSize) << 20
+	}
+	return Quota.projectUploadLimit(ctx, &build.Integration.Site.Project)
+}
+
+// Result 上传构建成功后输出目录的 zip 压缩包并发布为站点的新版本，构建期间收到更新的推送时不发布
+// Upload the zip of the output directory of a successful build and publish it as a new version of the site, nothing is published when a newer push arrived during the build
+func (RunnerApi) Result(ctx context.Context, c *app.RequestContext) {
+	build := Runner.getBuild(c)
+	if build == nil {
+		return
+	}
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		resps.BadRequest(c, "file is required")
+		return
+	}
+	commit := string(c.FormValue("commit"))
+	if !gitCommitPattern.MatchString(commit) {
+		resps.BadRequest(c, "invalid commit")
+		return
+	}
+	// 绑定 CNAME 域名需要磁盘上的压缩包 Binding the CNAME domain needs the archive on disk
+	archive, err := saveRunnerResult(fileHeader.Open)
+	if err != nil {
+		resps.InternalServerError(c, "save build result error")
+		return
+	}
+	defer os.Remove(archive)
+	if !Runner.finish(c, build, constants.GitRunSucceeded, "") {
+		return
+	}
+	result := &task.GitResult{Archive: archive, Commit: commit, Log: tail(string(c.FormValue("log")), task.GitLogLimit)}
+	Git.publish(ctx, &build.Integration, build.Commit, result, build.MapCNAME)
+	resps.Ok(c, resps.OK)
+}
+
+// finish 结束构建，构建已被结束时写入 409 响应并返回假
+// Finish the build, writing a 409 response and reporting false when it was already finished
+func (RunnerApi) finish(c *app.RequestContext, build *models.RunnerBuild, status, reason string) bool {
+	ok, err := store.Runner.FinishBuild(build, status, reason, time.Now())
+	if err != nil {
+		resps.DBError(c, err, "finish build error")
+		return false
+	}
+	if !ok {
+		resps.Fail(c, http.StatusConflict, resps.CodeConflict, "the build is no longer running", nil)
+		return false
+	}
+	return true
+}
+
+// fail 构建仍是仓库绑定最近一次运行时记录失败，output 为空时保留已上报的输出
+// Record the failure when the build is still the latest run of the binding, keeping the output reported so far when output is empty
+func (RunnerApi) fail(ctx context.Context, build *models.RunnerBuild, commit, reason, output string) {
+	current, err := store.Git.GetBySite(build.SiteID)
+	if err != nil || current.ID != build.IntegrationID || current.LastCommit != build.Commit || current.LastStatus != constants.GitRunBuilding {
+		return
+	}
+	Git.fail(ctx, &build.Integration, commit, reason, cmp.Or(output, current.LastLog))
+}
+
+// Watch 定期将租约过期的构建标记为失败，即执行器已停止心跳，ctx 结束时返回
+// Periodically fail builds whose lease expired because their runner stopped sending heartbeats, returning once ctx is done
+func (RunnerApi) Watch(ctx context.Context) {
+	ticker := time.NewTicker(runnerWatchInterval)
+	defer ticker.Stop()
+	for {
+		if err := Runner.expire(ctx, time.Now()); err != nil {
+			logrus.Warnf("failed to check runner builds: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (RunnerApi) expire(ctx context.Context, now time.Time) error {
+	builds, err := store.Runner.ListExpired(now, runnerExpireBatch)
+	if err != nil {
+		return err
+	}
+	for i := range builds {
+		build := &builds[i]
+		reason := "the runner stopped responding"
+		ok, err := store.Runner.FinishBuild(build, constants.GitRunFailed, reason, now)
+		if err != nil {
+			return err
+		}
+		if ok {
+			Runner.fail(ctx, build, build.Commit, reason, "")
+		}
+	}
+	return nil
+}
+
+// saveRunnerResult 将上传的构建结果写入临时文件，返回文件路径
+// Write an uploaded build result to a temporary file, returning its path
+func saveRunnerResult(open func() (multipart.File, error)) (string, error) {
+	src, err := open()
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+	dst, err := os.CreateTemp("", "spage-runner-*.zip")
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		_ = dst.Close()
+		_ = os.Remove(dst.Name())
+		return "", err
+	}
+	if err := dst.Close(); err != nil {
+		_ = os.Remove(dst.Name())
+		return "", err
+	}
+	return dst.Name(), nil
+}
+
+// tail 只保留字符串的最后 limit 字节
+// Keep only the last limit bytes of a string
+func tail(s string, limit int) string {
+	if len(s) > limit {
+		return s[len(s)-limit:]
+	}
+	return s
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import "time"
+
+// RunnerDTO 远程构建执行器信息，不包含令牌明文
+// Remote build runner information, without the plain token
+type RunnerDTO struct {
+	ID          uint       `json:"id"`           // 执行器ID Runner ID
+	Name        string     `json:"name"`         // 执行器名称 Runner name
+	Prefix      string     `json:"prefix"`       // 令牌开头部分 Leading part of the token
+	Labels      []string   `json:"labels"`       // 执行器的标签 Labels of the runner
+	Concurrency int        `json:"concurrency"`  // 同时执行的构建数量 Number of builds run at the same time
+	Version     string     `json:"version"`      // 执行器的版本 Version of the runner
+	Online      bool       `json:"online"`       // 心跳是否未超时 Whether the heartbeat has not timed out
+	LastSeenAt  *time.Time `json:"last_seen_at"` // 最后一次注册、心跳或领取构建的时间 Time of the latest registration, heartbeat or claim
+	CreatedAt   time.Time  `json:"created_at"`   // 创建时间 Created time
+}
+
+// CreateRunnerReq 创建远程构建执行器的请求参数
+// Request parameters to create a remote build runner
+type CreateRunnerReq struct {
+	Name string `json:"name" validate:"required,max=64"` // 执行器名称 Runner name
+}
+
+// RegisterRunnerReq 执行器启动时上报的信息
+// Information reported by a runner when it starts
+type RegisterRunnerReq struct {
+	Labels      []string `json:"labels"`                              // 执行器的标签 Labels of the runner
+	Concurrency int      `json:"concurrency" validate:"min=1,max=64"` // 同时执行的构建数量 Number of builds run at the same time
+	Version     string   `json:"version" validate:"max=64"`           // 执行器的版本 Version of the runner
+}
+
+// RunnerBuildDTO 交给执行器的构建，包含拉取私有仓库的访问令牌
+// Build handed to a runner, carrying the access token for private repositories
+type RunnerBuildDTO struct {
+	ID           uint   `json:"id"`            // 构建ID Build ID
+	Provider     string `json:"provider"`      // 代码托管平台 Git hosting provider
+	RepoURL      string `json:"repo_url"`      // 仓库地址 Repository URL
+	Token        string `json:"token"`         // 访问令牌 Access token
+	Branch       string `json:"branch"`        // 分支 Branch
+	Commit       string `json:"commit"`        // 提交，为空时构建分支的最新提交 Commit, the head of the branch when empty
+	BuildCommand string `json:"build_command"` // 构建命令 Build command
+	BuildImage   string `json:"build_image"`   // 构建镜像 Build image
+	OutputDir    string `json:"output_dir"`    // 输出目录 Output directory
+}
+
+// RunnerLogReq 执行器上报的构建输出
+// Build output reported by a runner
+type RunnerLogReq struct {
+	Log string `json:"log"` // 截断后的输出 Truncated output
+}
+
+// RunnerFailReq 执行器上报的构建失败
+// Build failure reported by a runner
+type RunnerFailReq struct {
+	Commit string `json:"commit"`                    // 实际构建的提交，拉取前失败时为空 Commit actually built, empty when failing before the fetch
+	Error  string `json:"error" validate:"required"` // 失败原因 Failure reason
+	Log    string `json:"log"`                       // 截断后的输出 Truncated output
+}
@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/i18n"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/scan"
+	"github.com/LiteyukiStudio/spage/serve"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/task"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+type ScanApi struct{}
+
+// Scan 新版本的内容扫描和管理员对隔离版本的审核
+// Content scans of new versions and the administrators' review of quarantined ones
+var Scan = ScanApi{}
+
+// check 扫描新版本的全部文件，返回发现的恶意文件；未开启扫描时返回空，扫描失败且未开启 scan.fail-open 时失败原因也作为发现返回
+// Scan every file of a new version, returning the malicious files found; nothing when scanning is disabled, and the failure as a finding too when scanning fails without scan.fail-open
+func (ScanApi) check(ctx context.Context, site *models.Site, file *models.File) []scan.Finding {
+	scanner, err := scan.New()
+	if scanner == nil && err == nil {
+		return nil
+	}
+	task.ReportProgress(ctx, constants.JobStageScan, 0, 0)
+	var findings []scan.Finding
+	if err == nil {
+		findings, err = scan.Walk(ctx, scanner, func(visit func(name string, size int64, body io.Reader) error) error {
+			return serve.WalkRelease(file, visit)
+		}, int64(config.ScanMaxFileSize)<<20)
+	}
+	if err != nil {
+		utils.Log.Ctx(ctx).Errorf("failed to scan file %d of site %d: %v", file.ID, site.ID, err)
+		if !config.ScanFailOpen {
+			findings = append(findings, scan.Finding{Signature: "scan failed: " + err.Error()})
+		}
+	}
+	return findings
+}
+
+// encode 将扫描发现编码为保存在版本上的 JSON
+// Encode the findings as the JSON stored on the version
+func (ScanApi) encode(findings []scan.Finding) string {
+	data, _ := json.Marshal(findings)
+	return string(data)
+}
+
+// findings 解码版本上保存的扫描发现
+// Decode the findings stored on a version
+func (ScanApi) findings(release *models.SiteRelease) []scan.Finding {
+	if release.ScanFindings == "" {
+		return nil
+	}
+	var findings []scan.Finding
+	if err := json.Unmarshal([]byte(release.ScanFindings), &findings); err != nil {
+		return nil
+	}
+	return findings
+}
+
+// quarantine 记录版本已被隔离并通知 webhook
+// Record that a version was quarantined and notify webhooks
+func (ScanApi) quarantine(ctx context.Context, site *models.Site, release *models.SiteRelease, findings []scan.Finding) {
+	utils.Log.Ctx(ctx).Warnf("release %d of site %d quarantined, %d findings", release.ID, site.ID, len(findings))
+	Audit.record(ctx, nil, nil, constants.AuditReleaseQuarantine, constants.AuditTargetSite, site.ID, map[string]any{
+		"release_id": release.ID,
+		"tag":        release.Tag,
+		"findings":   findings,
+	})
+	Webhook.emit(ctx, &site.Project, constants.WebhookDeploymentQuarantined,
+		i18n.Msg("Release %s of site %s was quarantined for review, %d suspicious files found", release.Tag, site.Name, len(findings)),
+		map[string]any{"site_id": site.ID, "site": site.Name, "release_id": release.ID, "tag": release.Tag, "findings": findings})
+}
+
+// List 管理员分页获取所有等待审核的隔离版本
+// Admin lists all quarantined versions waiting for review with pagination
+func (ScanApi) List(ctx context.Context, c *app.RequestContext) {
+	page, limit := utils.Ctx.GetPageLimit(c)
+	releases, total, err := store.Site.ListQuarantined(ctx, page, limit)
+	if err != nil {
+		resps.DBError(c, err, "get releases error")
+		return
+	}
+	releaseDTOs := make([]ReleaseDTO, 0, len(releases))
+	for i := range releases {
+		releaseDTO := Release.ToDTO(&releases[i])
+		releaseDTO.Site.Project = Project.toDTO(&releases[i].Site.Project, false)
+		releaseDTOs = append(releaseDTOs, releaseDTO)
+	}
+	utils.Ctx.SetPageHeaders(c, page, limit, total)
+	resps.Ok(c, resps.OK, map[string]any{
+		"releases": releaseDTOs,
+		"total":    total,
+	})
+}
+
+// getQuarantined 获取路径中的隔离版本及其站点，失败时已写入响应
+// Get the quarantined version of the path and its site; the response is already written on failure
+func (ScanApi) getQuarantined(c *app.RequestContext) (*models.SiteRelease, *models.Site) {
+	id, err := strconv.Atoi(c.Param("release_id"))
+	if err != nil {
+		resps.BadRequest(c, resps.ParameterError)
+		return nil, nil
+	}
+	release, err := store.Site.GetReleaseById(uint(id))
+	if err != nil || release.Tag == constants.ReleaseTagLatest {
+		resps.NotFound(c, resps.TargetNotFound)
+		return nil, nil
+	}
+	if release.Status != constants.DeploymentStatusQuarantined {
+		resps.BadRequestf(c, "release is %s, not quarantined", release.Status)
+		return nil, nil
+	}
+	site, err := store.Site.GetByID(release.SiteID)
+	if err != nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return nil, nil
+	}
+	release.Site = *site
+	return release, site
+}
+
+// Approve 管理员放行隔离的版本：定时上线时间未到时恢复定时，部署时要求上线或定时上线时间已过时立即激活，其余恢复为普通版本；
+// 受保护项目中本应上线或定时的版本转为等待维护者批准
+// Admin releases a quarantined version: it is scheduled again while its go-live time is ahead, activated right away when the deployment asked for it or the go-live time has passed, and becomes a plain version otherwise;
+// in protected projects a version that would go live or be scheduled waits for a maintainer's approval instead
+func (ScanApi) Approve(ctx context.Context, c *app.RequestContext) {
+	release, site := Scan.getQuarantined(c)
+	if release == nil {
+		return
+	}
+	activate := release.ScanActivate
+	release.Status = constants.DeploymentStatusReady
+	if release.PublishAt != nil {
+		if release.PublishAt.After(time.Now()) {
+			release.Status = constants.DeploymentStatusScheduled
+		} else {
+			activate = true
+		}
+	}
+	release.ScanActivate = false
+	awaiting := site.Project.Protected && (activate || release.Status == constants.DeploymentStatusScheduled)
+	if awaiting {
+		release.Status = constants.DeploymentStatusAwaitingApproval
+		release.ApprovalActivate = activate
+		activate = false
+	}
+	if err := store.Site.SetScan(release); err != nil {
+		resps.DBError(c, err, "update release error")
+		return
+	}
+	if awaiting {
+		Approval.request(ctx, site, release)
+	}
+	details := map[string]any{"decision": "approve", "release_id": release.ID, "tag": release.Tag, "status": release.Status}
+	releaseDTO := Release.ToDTO(release)
+	if activate {
+		previousID, err := store.Site.ActivateRelease(ctx, release)
+		if err != nil {
+			resps.DBError(c, err, errActivate.Error())
+			return
+		}
+		serve.SiteCache.Forget(ctx, site.ID)
+		utils.Log.Ctx(ctx).Infof("site %d switched from release %d to approved release %d", site.ID, previousID, release.ID)
+		details["previous_release_id"] = previousID
+		Release.notifyDeployed(ctx, site, release, previousID)
+		releaseDTO.Active = true
+	}
+	Audit.record(ctx, c, nil, constants.AuditReleaseReview, constants.AuditTargetSite, site.ID, details)
+	resps.Ok(c, resps.OK, map[string]any{
+		"release": releaseDTO,
+	})
+}
+
+// Reject 管理员拒绝隔离的版本，版本标记为失败且不再提供服务，项目的 webhook 收到部署失败通知
+// Admin rejects a quarantined version, which is marked failed and never served, the project's webhooks are told the deployment failed
+func (ScanApi) Reject(ctx context.Context, c *app.RequestContext) {
+	req := QuarantineRejectReq{}
+	if len(c.Request.Body()) > 0 {
+		if !bindJSON(c, &req) {
+			return
+		}
+	}
+	release, site := Scan.getQuarantined(c)
+	if release == nil {
+		return
+	}
+	release.Status = constants.DeploymentStatusFailed
+	release.ScanActivate = false
+	if err := store.Site.SetScan(release); err != nil {
+		resps.DBError(c, err, "update release error")
+		return
+	}
+	reason := "rejected after content review"
+	if req.Reason != "" {
+		reason += ": " + req.Reason
+	}
+	Audit.record(ctx, c, nil, constants.AuditReleaseReview, constants.AuditTargetSite, site.ID, map[string]any{
+		"decision":   "reject",
+		"release_id": release.ID,
+		"tag":        release.Tag,
+		"reason":     req.Reason,
+	})
+	Webhook.emit(ctx, &site.Project, constants.WebhookDeploymentFailed,
+		i18n.Msg("Deployment of %s to site %s failed: %s", release.Tag, site.Name, reason),
+		map[string]any{"site_id": site.ID, "site": site.Name, "release_id": release.ID, "tag": release.Tag, "reason": reason})
+	resps.Ok(c, resps.OK, map[string]any{
+		"release": Release.ToDTO(release),
+	})
+}
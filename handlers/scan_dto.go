@@ -0,0 +1,7 @@
+package handlers
+
+// QuarantineRejectReq 拒绝隔离版本的请求参数
+// Request parameters to reject a quarantined version
+type QuarantineRejectReq struct {
+	Reason string `json:"reason"` // 拒绝原因，随 webhook 通知项目 Reason of the rejection, sent to the project's webhooks
+}
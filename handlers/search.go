@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/LiteyukiStudio/spage/middle"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// maxSearchQuery 检索词的最大长度
+// Max length of a search query
+const maxSearchQuery = 200
+
+// searchTypes 可检索的结果类型
+// Result types that can be searched
+var searchTypes = []string{"projects", "sites", "users", "orgs"}
+
+type SearchApi struct{}
+
+// Search 全文检索，普通用户只能检索到自己能访问的项目、站点和组织，管理员检索整个实例
+// Full-text search, users only find the projects, sites and organizations they can access while administrators search the whole instance
+var Search = SearchApi{}
+
+// User 在当前用户可访问的范围内检索
+// Search within what the current user can access
+func (SearchApi) User(ctx context.Context, c *app.RequestContext) {
+	user := middle.Auth.GetUser(ctx, c)
+	if user == nil {
+		return
+	}
+	Search.search(ctx, c, user.ID)
+}
+
+// Admin 管理员检索整个实例
+// Admin searches the whole instance
+func (SearchApi) Admin(ctx context.Context, c *app.RequestContext) {
+	Search.search(ctx, c, 0)
+}
+
+// search 按 q 检索，type 为空时返回全部类型，分页参数对每种类型分别生效；userID 为 0 时不按成员关系过滤
+// Search by q, returning every type when type is empty with the pagination applied to each type; a userID of 0 skips the membership filter
+func (SearchApi) search(ctx context.Context, c *app.RequestContext, userID uint) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" || utf8.RuneCountInString(query) > maxSearchQuery {
+		resps.BadRequest(c, resps.ParameterError)
+		return
+	}
+	kinds := searchTypes
+	if kind := c.Query("type"); kind != "" {
+		if !slices.Contains(searchTypes, kind) {
+			resps.BadRequest(c, "unknown search type "+kind)
+			return
+		}
+		kinds = []string{kind}
+	}
+	terms := store.SearchTerms(query)
+	page, limit := utils.Ctx.GetPageLimit(c)
+	result := make(map[string]any, len(kinds)+1)
+	totals := make(map[string]int64, len(kinds))
+	for _, kind := range kinds {
+		var (
+			items any
+			total int64
+			err   error
+		)
+		switch kind {
+		case "projects":
+			projects, n, e := store.Search.Projects(ctx, terms, userID, page, limit)
+			dtos := make([]ProjectDTO, 0, len(projects))
+			for _, project := range projects {
+				dtos = append(dtos, Project.toDTO(&project, true))
+			}
+			items, total, err = dtos, n, e
+		case "sites":
+			sites, n, e := store.Search.Sites(ctx, terms, userID, page, limit)
+			dtos := make([]SiteDTO, 0, len(sites))
+			for _, site := range sites {
+				dtos = append(dtos, Site.ToDTO(&site, true))
+			}
+			items, total, err = dtos, n, e
+		case "users":
+			users, n, e := store.Search.Users(ctx, terms, page, limit)
+			dtos := make([]UserDTO, 0, len(users))
+			for _, user := range users {
+				dtos = append(dtos, User.ToDTO(&user, false))
+			}
+			items, total, err = dtos, n, e
+		case "orgs":
+			orgs, n, e := store.Search.Orgs(ctx, terms, userID, page, limit)
+			dtos := make([]OrganizationDTO, 0, len(orgs))
+			for _, org := range orgs {
+				dtos = append(dtos, Org.ToDTO(&org))
+			}
+			items, total, err = dtos, n, e
+		}
+		if err != nil {
+			resps.InternalServerError(c, "search error")
+			return
+		}
+		result[kind] = items
+		totals[kind] = total
+	}
+	result["total"] = totals
+	resps.Ok(c, resps.OK, result)
+}
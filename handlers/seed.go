@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/sirupsen/logrus"
+)
+
+type SeedApi struct{}
+
+// Seed 为开发和压测实例生成演示数据
+// Generate demo data for development and load testing instances
+var Seed = SeedApi{}
+
+// SeedOptions 生成演示数据的数量
+// How much demo data to generate
+type SeedOptions struct {
+	Users    int    // 用户数 Users
+	Orgs     int    // 组织数 Organizations
+	Projects int    // 项目数，每个项目一个站点 Projects, each with one site
+	Releases int    // 每个站点的部署次数 Deployments per site
+	Posts    int    // 每个站点的文章数 Posts per site
+	Password string // 所有演示用户的密码 Password of every demo user
+	Seed     uint64 // 随机数种子，相同种子生成相同的名称 Random seed, the same seed generates the same names
+}
+
+// SeedResult 生成的演示数据数量
+// Demo data generated
+type SeedResult struct {
+	Users    int
+	Orgs     int
+	Projects int
+	Releases int
+}
+
+var (
+	seedFirstNames = []string{"alice", "bob", "carol", "dave", "erin", "frank", "grace", "heidi", "ivan", "judy", "mallory", "niaj", "olivia", "peggy", "rupert", "sybil", "trent", "victor", "walter", "yuki"}
+	seedAdjectives = []string{"amber", "brisk", "calm", "daring", "eager", "fuzzy", "gentle", "hidden", "lucky", "mellow", "nimble", "quiet", "rapid", "silent", "tidy", "vivid"}
+	seedNouns      = []string{"atlas", "beacon", "canvas", "dune", "ember", "forest", "garden", "harbor", "island", "journal", "lantern", "meadow", "notes", "orbit", "pixel", "river"}
+	seedTopics     = []string{"Getting started", "Release notes", "Design principles", "A week of debugging", "Caching static assets", "Writing good docs", "Our roadmap", "Migrating from v1", "Performance tips", "Lessons learned"}
+	seedMemberRole = []constants.OrgRole{constants.OrgRoleMaintainer, constants.OrgRoleMember, constants.OrgRoleMember, constants.OrgRoleViewer}
+)
+
+// Run 生成用户、组织、项目及其站点，并以生成的静态内容为每个站点部署若干版本；名称带随机后缀，可以重复运行
+// Generate users, organizations, projects with their sites, and deploy several versions of generated static content to every site; names carry a random suffix so it can run again
+func (SeedApi) Run(ctx context.Context, opts SeedOptions) (*SeedResult, error) {
+	r := rand.New(rand.NewPCG(opts.Seed, opts.Seed^0x5eed))
+	result := &SeedResult{}
+	password, err := utils.Password.HashPassword(opts.Password)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	users := make([]*models.User, 0, opts.Users)
+	for range opts.Users {
+		first := seedFirstNames[r.IntN(len(seedFirstNames))]
+		name := first + "-" + seedSuffix(r)
+		email := name + "@example.com"
+		displayName := seedTitle(first)
+		user := &models.User{Name: name, DisplayName: &displayName, Email: &email, Password: &password, Role: constants.RoleUser, EmailVerifiedAt: &now}
+		if err := store.User.Create(user); err != nil {
+			return result, fmt.Errorf("create user %s: %w", name, err)
+		}
+		users = append(users, user)
+		result.Users++
+	}
+	if len(users) == 0 {
+		return result, nil
+	}
+
+	orgs := make([]*models.Organization, 0, opts.Orgs)
+	for range opts.Orgs {
+		noun := seedNouns[r.IntN(len(seedNouns))]
+		org := &models.Organization{Name: noun + "-" + seedSuffix(r), Description: "Demo organization building " + noun + " sites."}
+		displayName := seedTitle(noun) + " Team"
+		org.DisplayName = &displayName
+		creator := users[r.IntN(len(users))]
+		if err := store.Org.CreateOrg(org, creator); err != nil {
+			return result, fmt.Errorf("create organization %s: %w", org.Name, err)
+		}
+		// 创建者之外再加入几名成员 A few members join besides the creator
+		for range r.IntN(4) + 1 {
+			member := users[r.IntN(len(users))]
+			if member.ID == creator.ID {
+				continue
+			}
+			if err := store.Org.SetMember(org.ID, member.ID, seedMemberRole[r.IntN(len(seedMemberRole))]); err != nil {
+				return result, fmt.Errorf("add member to organization %s: %w", org.Name, err)
+			}
+		}
+		orgs = append(orgs, org)
+		result.Orgs++
+	}
+
+	for i := range opts.Projects {
+		project := &models.Project{
+			Name:      seedAdjectives[r.IntN(len(seedAdjectives))] + "-" + seedNouns[r.IntN(len(seedNouns))] + "-" + seedSuffix(r),
+			OwnerType: constants.OwnerTypeUser,
+			OwnerID:   users[r.IntN(len(users))].ID,
+		}
+		// 约三分之一的项目属于组织 About a third of the projects belong to organizations
+		if len(orgs) > 0 && r.IntN(3) == 0 {
+			project.OwnerType, project.OwnerID = constants.OwnerTypeOrg, orgs[r.IntN(len(orgs))].ID
+		}
+		displayName := seedTitle(project.Name)
+		project.DisplayName = &displayName
+		project.Description = "Demo project " + displayName + "."
+		site := &models.Site{Name: project.Name, Description: project.Description, SubDomain: project.Name}
+		err := store.Default.WithTx(ctx, func(tx *store.Handle) error {
+			if err := tx.Project.Create(project); err != nil {
+				return err
+			}
+			site.ProjectID = project.ID
+			return tx.Site.Create(site)
+		})
+		if err != nil {
+			return result, fmt.Errorf("create project %s: %w", project.Name, err)
+		}
+		site.Project = *project
+		result.Projects++
+		for version := 1; version <= opts.Releases; version++ {
+			if err := Seed.deploy(ctx, r, site, version, opts.Posts); err != nil {
+				return result, fmt.Errorf("deploy v%d of project %s: %w", version, project.Name, err)
+			}
+			result.Releases++
+		}
+		if (i+1)%10 == 0 {
+			logrus.Infof("Seeded %d of %d projects", i+1, opts.Projects)
+		}
+	}
+	return result, nil
+}
+
+// deploy 生成一个版本的静态内容并发布，每个版本多一篇文章
+// Generate one version of static content and publish it, every version adding a post
+func (SeedApi) deploy(ctx context.Context, r *rand.Rand, site *models.Site, version, posts int) error {
+	content := utils.DemoSite{
+		Title:       seedTitle(site.Project.Name),
+		Description: site.Project.Description,
+		Author:      site.Project.Name,
+		Version:     fmt.Sprintf("v%d", version),
+	}
+	for range posts + version - 1 {
+		content.Posts = append(content.Posts, seedTopics[r.IntN(len(seedTopics))])
+	}
+	tmp, err := os.CreateTemp("", "spage-seed-*.zip")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	err = utils.Demo.Write(tmp, content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	source, err := fileArchive(tmp.Name())
+	if err != nil {
+		return err
+	}
+	_, _, err = Release.publish(ctx, site, source, content.Version, true)
+	return err
+}
+
+// seedSuffix 名称的随机后缀，避免重复运行时冲突
+// Random name suffix so repeated runs do not collide
+func seedSuffix(r *rand.Rand) string {
+	const alphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
+	suffix := make([]byte, 4)
+	for i := range suffix {
+		suffix[i] = alphabet[r.IntN(len(alphabet))]
+	}
+	return string(suffix)
+}
+
+// seedTitle 将 amber-atlas-x1y2 这样的名称转换为 Amber Atlas X1y2
+// Turn a name such as amber-atlas-x1y2 into Amber Atlas X1y2
+func seedTitle(name string) string {
+	title := []byte(name)
+	upper := true
+	for i, c := range title {
+		switch {
+		case c == '-':
+			title[i] = ' '
+			upper = true
+		case upper && c >= 'a' && c <= 'z':
+			title[i] = c - 'a' + 'A'
+			upper = false
+		default:
+			upper = false
+		}
+	}
+	return string(title)
+}
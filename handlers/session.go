@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/middle"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol"
+)
+
+type SessionApi struct{}
+
+// Session 用户的登录会话，可查看并撤销被盗用的会话
+// Sign-in sessions of users, which can be listed and revoked when stolen
+var Session = SessionApi{}
+
+func (SessionApi) toDTO(session *models.Token, current uint) SessionDTO {
+	return SessionDTO{
+		ID:           session.ID,
+		Method:       session.Method,
+		IP:           session.IP,
+		UserAgent:    session.UserAgent,
+		Current:      session.ID == current,
+		Impersonated: session.ImpersonatorID != 0,
+		CreatedAt:    session.CreatedAt,
+		LastUsedAt:   session.LastUsedAt,
+		ExpiresAt:    session.ExpiresAt,
+	}
+}
+
+// List 获取当前用户未过期的登录会话
+// List the unexpired sign-in sessions of the current user
+func (SessionApi) List(ctx context.Context, c *app.RequestContext) {
+	user := middle.Auth.GetUser(ctx, c)
+	if user == nil {
+		return
+	}
+	sessions, err := store.JWT.ListSessions(user.ID)
+	if err != nil {
+		resps.DBError(c, err, "Failed to get sessions")
+		return
+	}
+	current := middle.Auth.SessionID(c)
+	dtos := make([]SessionDTO, 0, len(sessions))
+	for _, session := range sessions {
+		dtos = append(dtos, Session.toDTO(&session, current))
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"sessions": dtos,
+	})
+}
+
+// Revoke 撤销当前用户的一个登录会话，其访问令牌和刷新令牌立即失效
+// Revoke one sign-in session of the current user, its access and refresh tokens stop working at once
+func (SessionApi) Revoke(ctx context.Context, c *app.RequestContext) {
+	user := middle.Auth.GetUser(ctx, c)
+	if user == nil {
+		return
+	}
+	id, err := strconv.Atoi(c.Param("session_id"))
+	if err != nil || id <= 0 {
+		resps.BadRequest(c, resps.ParameterError)
+		return
+	}
+	revoked, err := store.JWT.RevokeSession(user.ID, uint(id))
+	if err != nil {
+		resps.DBError(c, err, "Failed to revoke session")
+		return
+	}
+	if !revoked {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	Audit.record(ctx, c, user, constants.AuditSessionRevoke, constants.AuditTargetUser, user.ID, map[string]any{"session_id": id})
+	resps.Ok(c, resps.OK)
+}
+
+// RevokeAll 在所有设备上登出，撤销当前用户的全部登录会话，包括当前会话
+// Log out everywhere, revoking every sign-in session of the current user including the current one
+func (SessionApi) RevokeAll(ctx context.Context, c *app.RequestContext) {
+	user := middle.Auth.GetUser(ctx, c)
+	if user == nil {
+		return
+	}
+	if err := store.JWT.RevokeTokenByUserID(user.ID); err != nil {
+		resps.DBError(c, err, "Failed to revoke sessions")
+		return
+	}
+	Audit.record(ctx, c, user, constants.AuditSessionRevoke, constants.AuditTargetUser, user.ID, map[string]any{"all": true})
+	c.SetCookie("token", "", -1, "/", "", protocol.CookieSameSiteLaxMode, true, true)
+	c.SetCookie("refresh_token", "", -1, "/", "", protocol.CookieSameSiteLaxMode, true, true)
+	middle.CSRF.Clear(c)
+	resps.Ok(c, resps.OK)
+}
@@ -0,0 +1,17 @@
+package handlers
+
+import "time"
+
+// SessionDTO 登录会话信息，不包含令牌
+// Sign-in session information, without tokens
+type SessionDTO struct {
+	ID           uint      `json:"id"`           // 会话ID Session ID
+	Method       string    `json:"method"`       // 登录方式 Login method
+	IP           string    `json:"ip"`           // 最近使用的客户端IP Client IP of the last use
+	UserAgent    string    `json:"user_agent"`   // 最近使用的用户代理 User agent of the last use
+	Current      bool      `json:"current"`      // 是否为当前请求的会话 Whether it is the session of the current request
+	Impersonated bool      `json:"impersonated"` // 是否为管理员代为登录的会话 Whether an administrator is impersonating the user in it
+	CreatedAt    time.Time `json:"created_at"`   // 登录时间 Sign-in time
+	LastUsedAt   time.Time `json:"last_used_at"` // 最近使用时间 Last used time
+	ExpiresAt    time.Time `json:"expires_at"`   // 过期时间 Expiry time
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/middle"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/task"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+type SettingApi struct{}
+
+// Setting 运行时可修改的配置项，修改保存在数据库中，本实例立即生效，其他实例在 settings.reload-interval 内生效
+// Settings changeable at runtime, changes are stored in the database and take effect at once on this instance and within settings.reload-interval on the others
+var Setting = SettingApi{}
+
+// list 所有动态配置项及其是否被覆盖
+// All dynamic settings and whether they are overridden
+func (SettingApi) list() ([]SettingDTO, error) {
+	overrides, err := store.Setting.Overrides()
+	if err != nil {
+		return nil, err
+	}
+	infos := config.Settings()
+	dtos := make([]SettingDTO, 0, len(infos))
+	for _, info := range infos {
+		_, overridden := overrides[info.Key]
+		dtos = append(dtos, SettingDTO{
+			Key:         info.Key,
+			Type:        info.Type,
+			Description: info.Description,
+			Value:       info.Value,
+			Default:     info.Default,
+			Min:         info.Min,
+			Overridden:  overridden,
+		})
+	}
+	return dtos, nil
+}
+
+// reply 重新加载配置项并返回列表
+// Reload the settings and reply with the list
+func (SettingApi) reply(ctx context.Context, c *app.RequestContext) {
+	if err := task.LoadSettings(); err != nil {
+		utils.Log.Ctx(ctx).Warnf("failed to reload settings: %v", err)
+	}
+	dtos, err := Setting.list()
+	if err != nil {
+		resps.InternalServerError(c, "get settings error")
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"settings": dtos,
+	})
+}
+
+// List 获取所有动态配置项
+// List all dynamic settings
+func (SettingApi) List(ctx context.Context, c *app.RequestContext) {
+	dtos, err := Setting.list()
+	if err != nil {
+		resps.InternalServerError(c, "get settings error")
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"settings": dtos,
+	})
+}
+
+// Update 修改动态配置项，无需重启即可生效
+// Change a dynamic setting, taking effect without a restart
+func (SettingApi) Update(ctx context.Context, c *app.RequestContext) {
+	key := c.Param("key")
+	if !config.IsSetting(key) {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	req := SettingReq{}
+	if err := c.BindJSON(&req); err != nil || len(req.Value) == 0 {
+		resps.BadRequest(c, resps.ParameterError)
+		return
+	}
+	value, err := config.ParseSetting(key, req.Value)
+	if err != nil {
+		resps.BadRequest(c, err.Error())
+		return
+	}
+	if err := store.Setting.Save(key, value, middle.Auth.GetUser(ctx, c).ID); err != nil {
+		resps.DBError(c, err, "save setting error")
+		return
+	}
+	Audit.record(ctx, c, nil, constants.AuditSettingUpdate, constants.AuditTargetSetting, 0, map[string]any{
+		"key":   key,
+		"value": json.RawMessage(value),
+	})
+	Setting.reply(ctx, c)
+}
+
+// Delete 删除管理员对动态配置项的覆盖，恢复为配置文件中的值
+// Remove the override of a dynamic setting, restoring the value of the configuration file
+func (SettingApi) Delete(ctx context.Context, c *app.RequestContext) {
+	key := c.Param("key")
+	if !config.IsSetting(key) {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	if err := store.Setting.Delete(key); err != nil {
+		resps.DBError(c, err, "delete setting error")
+		return
+	}
+	Audit.record(ctx, c, nil, constants.AuditSettingUpdate, constants.AuditTargetSetting, 0, map[string]any{
+		"key":       key,
+		"operation": "reset",
+	})
+	Setting.reply(ctx, c)
+}
@@ -0,0 +1,21 @@
+package handlers
+
+import "encoding/json"
+
+// SettingDTO 动态配置项
+// Dynamic setting
+type SettingDTO struct {
+	Key         string `json:"key"`           // 配置项名称 Setting key
+	Type        string `json:"type"`          // 值类型 int、bool 或 string Value type, int, bool or string
+	Description string `json:"description"`   // 说明 Description
+	Value       any    `json:"value"`         // 当前值 Current value
+	Default     any    `json:"default"`       // 配置文件中的值 Value of the configuration file
+	Min         int    `json:"min,omitempty"` // 整数的最小值 Minimum of integers
+	Overridden  bool   `json:"overridden"`    // 是否已被管理员覆盖 Whether an administrator overrode it
+}
+
+// SettingReq 修改动态配置项的请求参数
+// Request parameters to change a dynamic setting
+type SettingReq struct {
+	Value json.RawMessage `json:"value" binding:"required"` // 新值，类型须与配置项一致 New value, must match the type of the setting
+}
@@ -2,14 +2,34 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/middle"
 	"github.com/LiteyukiStudio/spage/models"
 	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/serve"
 	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/utils"
 	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/http1/resp"
 )
 
+// tailHeartbeatInterval 实时日志流心跳间隔，用于检测客户端断开
+// Heartbeat interval of live log tails, used to detect client disconnects
+const tailHeartbeatInterval = 15 * time.Second
+
+// maxSiteHeaders 站点设置中允许的自定义响应头数量
+// Number of custom response headers allowed in site settings
+const maxSiteHeaders = 50
+
 type SiteApi struct {
 }
 
@@ -27,12 +47,26 @@ func (SiteApi) ToDTO(site *models.Site, full bool) SiteDTO {
 		siteDTO.Project = Project.toDTO(&site.Project, full)
 		siteDTO.SubDomain = &site.SubDomain
 		siteDTO.Domains = site.Domains
+		siteDTO.OGPreview = site.OGPreview
+		siteDTO.Headers = site.Headers
+		siteDTO.CacheRules = site.CacheRules
+		siteDTO.AccessMode = constants.SiteAccessPublic
+		if serve.Protected(site) {
+			siteDTO.AccessMode = site.AccessMode
+		}
+		siteDTO.HasAccessPassword = site.AccessPassword != ""
+		siteDTO.AllowIPs = site.AllowIPs
+		siteDTO.DenyIPs = site.DenyIPs
+		siteDTO.Sitemap = site.Sitemap
+		siteDTO.RobotsPolicy = site.RobotsPolicy
+		siteDTO.RobotsTxt = site.RobotsTxt
 	}
 	return siteDTO
 }
 
-func getSite(ctx context.Context) *models.Site {
-	site, ok := ctx.Value("userSite").(*models.Site)
+func getSite(c *app.RequestContext) *models.Site {
+	value, _ := c.Get("userSite")
+	site, ok := value.(*models.Site)
 	if !ok {
 		return nil
 	}
@@ -49,84 +83,293 @@ func (SiteApi) SiteAuth(ctx context.Context, c *app.RequestContext) {
 	siteID, err := strconv.Atoi(siteIDStr)
 	if err != nil {
 		resps.BadRequest(c, resps.ParameterError)
+		c.Abort()
 		return
 	}
 	site, err := store.Site.GetByID(uint(siteID))
-	if err != nil {
+	// 站点必须属于当前项目 The site must belong to the current project
+	project := getProject(c)
+	if err != nil || project == nil || site.ProjectID != project.ID {
 		resps.NotFound(c, "Site not found")
+		c.Abort()
 		return
 	}
-	context.WithValue(ctx, "userSite", site)
+	c.Set("userSite", site)
 }
 
 // Create 创建站点
 // Create Site
 func (SiteApi) Create(ctx context.Context, c *app.RequestContext) {
 	req := CreateSiteReq{}
-	if err := c.BindAndValidate(&req); err != nil {
-		resps.BadRequest(c, resps.ParameterError)
+	if !bind(c, &req) {
+		return
+	}
+	if domain := Site.claimedDomain(req.Domains, 0); domain != "" {
+		resps.BadRequestf(c, "Domain %s is bound to another site", domain)
+		return
+	}
+	headers, err := Site.validHeaders(req.Headers)
+	if err != nil {
+		resps.BadRequest(c, err.Error())
+		return
+	}
+	cacheRules, err := Site.validCacheRules(req.CacheRules)
+	if err != nil {
+		resps.BadRequest(c, err.Error())
 		return
 	}
 	site := models.Site{
 		Name:        req.Name,
 		Description: req.Description,
 		Domains:     req.Domains,
-		ProjectID:   req.ProjectID,
-		SubDomain:   *req.SubDomain,
+		// 站点只能创建在路由中已校验角色的项目下 Sites are only created in the route's project, whose role was checked
+		ProjectID:  getProject(c).ID,
+		SubDomain:  *req.SubDomain,
+		OGPreview:  req.OGPreview,
+		Headers:    headers,
+		CacheRules: cacheRules,
 	}
 	if err := store.Site.Create(&site); err != nil {
-		resps.InternalServerError(c, err.Error())
+		resps.DBError(c, err, err.Error())
 		return
 	}
+	serve.SiteCache.ForgetSite(ctx, &site)
 	// TODO 创建站点信息
 	resps.Ok(c, resps.OK, map[string]any{
 		"site": Site.ToDTO(&site, true),
 	})
 }
 
+// claimedDomain 返回已被其他站点绑定为自定义域名的域名，防止通过站点设置抢占已验证的域名
+// Return a domain already bound as a custom domain by another site, so site settings cannot take over verified domains
+func (SiteApi) claimedDomain(domains []string, siteID uint) string {
+	for _, domain := range domains {
+		if store.Domain.IsClaimedByOther(domain, siteID) {
+			return domain
+		}
+	}
+	return ""
+}
+
+// validHeaders 校验站点自定义响应头，规范为 "Name: value" 格式
+// Validate custom site headers, normalized to the "Name: value" format
+func (SiteApi) validHeaders(lines []string) ([]string, error) {
+	if len(lines) > maxSiteHeaders {
+		return nil, fmt.Errorf("at most %d headers are allowed", maxSiteHeaders)
+	}
+	headers := make([]string, 0, len(lines))
+	for _, line := range lines {
+		name, value, err := serve.ParseHeaderLine(line)
+		if err != nil {
+			return nil, err
+		}
+		headers = append(headers, name+": "+value)
+	}
+	return headers, nil
+}
+
+// validCacheRules 校验站点按路径的 Cache-Control 规则，规范为 "<路径模式> <值>" 格式
+// Validate the Cache-Control rules of a site, normalized to the "<pattern> <value>" format
+func (SiteApi) validCacheRules(lines []string) ([]string, error) {
+	if len(lines) > maxSiteHeaders {
+		return nil, fmt.Errorf("at most %d cache rules are allowed", maxSiteHeaders)
+	}
+	rules := make([]string, 0, len(lines))
+	for _, line := range lines {
+		rule, err := serve.ParseCacheRule(line)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule.String())
+	}
+	return rules, nil
+}
+
+// maxRobotsTxtSize 自定义 robots.txt 的最大字节数 Largest custom robots.txt in bytes
+const maxRobotsTxtSize = 64 << 10
+
+// validRobots 校验站点的 robots.txt 策略和自定义内容，未传入的参数保留当前设置，非 custom 策略清空自定义内容
+// Validate the robots policy and custom content of a site, parameters not given keep the current settings and policies other than custom clear the custom content
+func (SiteApi) validRobots(site *models.Site, policy, content *string) (string, string, error) {
+	robotsPolicy, robotsTxt := site.RobotsPolicy, site.RobotsTxt
+	if policy != nil {
+		robotsPolicy = *policy
+	}
+	if content != nil {
+		robotsTxt = *content
+	}
+	if !slices.Contains(constants.RobotsPolicies, robotsPolicy) {
+		return "", "", fmt.Errorf("robots_policy must be one of %q", constants.RobotsPolicies)
+	}
+	if robotsPolicy != constants.RobotsPolicyCustom {
+		return robotsPolicy, "", nil
+	}
+	if strings.TrimSpace(robotsTxt) == "" {
+		return "", "", errors.New("robots_txt is required by the custom robots policy")
+	}
+	if len(robotsTxt) > maxRobotsTxtSize {
+		return "", "", fmt.Errorf("robots_txt must be at most %d bytes", maxRobotsTxtSize)
+	}
+	return robotsPolicy, robotsTxt, nil
+}
+
+// validIPRules 校验站点的 IP 规则，规范为地址或 CIDR 网段；rules 为空时保留当前规则
+// Validate the IP rules of a site, normalized to addresses or CIDR ranges; the current rules are kept when rules is nil
+func (SiteApi) validIPRules(rules *[]string, current []string) ([]string, error) {
+	if rules == nil {
+		return current, nil
+	}
+	if len(*rules) > maxSiteHeaders {
+		return nil, fmt.Errorf("at most %d IP rules are allowed", maxSiteHeaders)
+	}
+	normalized := make([]string, 0, len(*rules))
+	for _, rule := range *rules {
+		prefix, err := serve.ParseIPRule(rule)
+		if err != nil {
+			return nil, err
+		}
+		normalized = append(normalized, prefix.String())
+	}
+	return normalized, nil
+}
+
 func (SiteApi) Update(ctx context.Context, c *app.RequestContext) {
 	req := UpdateSiteReq{}
-	if err := c.BindAndValidate(&req); err != nil {
-		resps.BadRequest(c, resps.ParameterError)
+	if !bind(c, &req) {
 		return
 	}
-	site := getSite(ctx)
+	site := getSite(c)
 	if site == nil {
 		resps.NotFound(c, resps.TargetNotFound)
 		return
 	}
-	site.Description = *req.Description
+	if domain := Site.claimedDomain(req.Domains, site.ID); domain != "" {
+		resps.BadRequestf(c, "Domain %s is bound to another site", domain)
+		return
+	}
+	if req.Description != nil {
+		site.Description = *req.Description
+	}
 	site.Domains = req.Domains
 	site.Name = *req.Name
 	site.SubDomain = *req.SubDomain
 	if err := store.Site.Update(site); err != nil {
-		resps.InternalServerError(c, resps.ParameterError)
+		resps.DBError(c, err, resps.ParameterError)
 		return
 	}
+	if req.OGPreview != nil {
+		if err := store.Site.SetOGPreview(site, *req.OGPreview); err != nil {
+			resps.DBError(c, err, resps.ParameterError)
+			return
+		}
+	}
+	if req.Headers != nil {
+		headers, err := Site.validHeaders(*req.Headers)
+		if err != nil {
+			resps.BadRequest(c, err.Error())
+			return
+		}
+		if err := store.Site.SetHeaders(site, headers); err != nil {
+			resps.DBError(c, err, resps.ParameterError)
+			return
+		}
+	}
+	if req.CacheRules != nil {
+		cacheRules, err := Site.validCacheRules(*req.CacheRules)
+		if err != nil {
+			resps.BadRequest(c, err.Error())
+			return
+		}
+		if err := store.Site.SetCacheRules(site, cacheRules); err != nil {
+			resps.DBError(c, err, resps.ParameterError)
+			return
+		}
+	}
+	if req.AccessMode != nil || req.AccessPassword != nil {
+		mode, hash, err := Site.validAccess(site, req.AccessMode, req.AccessPassword)
+		if err != nil {
+			resps.BadRequest(c, err.Error())
+			return
+		}
+		if err := store.Site.SetAccess(site, mode, hash); err != nil {
+			resps.DBError(c, err, resps.ParameterError)
+			return
+		}
+	}
+	if req.AllowIPs != nil || req.DenyIPs != nil {
+		allow, err := Site.validIPRules(req.AllowIPs, site.AllowIPs)
+		if err != nil {
+			resps.BadRequest(c, err.Error())
+			return
+		}
+		deny, err := Site.validIPRules(req.DenyIPs, site.DenyIPs)
+		if err != nil {
+			resps.BadRequest(c, err.Error())
+			return
+		}
+		if err := store.Site.SetIPRules(site, allow, deny); err != nil {
+			resps.DBError(c, err, resps.ParameterError)
+			return
+		}
+	}
+	if req.Sitemap != nil || req.RobotsPolicy != nil || req.RobotsTxt != nil {
+		policy, content, err := Site.validRobots(site, req.RobotsPolicy, req.RobotsTxt)
+		if err != nil {
+			resps.BadRequest(c, err.Error())
+			return
+		}
+		sitemap := site.Sitemap
+		if req.Sitemap != nil {
+			sitemap = *req.Sitemap
+		}
+		if err := store.Site.SetCrawling(site, sitemap, policy, content); err != nil {
+			resps.DBError(c, err, resps.ParameterError)
+			return
+		}
+	}
+	serve.SiteCache.ForgetSite(ctx, site)
+	Audit.record(ctx, c, nil, constants.AuditSiteUpdate, constants.AuditTargetSite, site.ID, map[string]any{
+		"name":             site.Name,
+		"sub_domain":       site.SubDomain,
+		"domains":          site.Domains,
+		"og_preview":       req.OGPreview,
+		"headers":          req.Headers,
+		"cache_rules":      req.CacheRules,
+		"access_mode":      req.AccessMode,
+		"password_changed": req.AccessPassword != nil,
+		"allow_ips":        req.AllowIPs,
+		"deny_ips":         req.DenyIPs,
+		"sitemap":          req.Sitemap,
+		"robots_policy":    req.RobotsPolicy,
+		"robots_changed":   req.RobotsTxt != nil,
+	})
 	// TODO 更新站点信息
 	resps.Ok(c, resps.OK, map[string]any{
 		"site": Site.ToDTO(site, true),
 	})
 }
 
+// Delete 删除站点，站点移入项目回收站，保留期内可以恢复
+// Delete a site, moving it to the project's trash where it can be restored within the retention window
 func (SiteApi) Delete(ctx context.Context, c *app.RequestContext) {
-	site := getSite(ctx)
+	site := getSite(c)
 	if site == nil {
 		resps.NotFound(c, resps.TargetNotFound)
 		return
 	}
 	if err := store.Site.Delete(site); err != nil {
-		resps.InternalServerError(c, resps.ParameterError)
+		resps.DBError(c, err, resps.ParameterError)
 		return
 	}
-	// TODO 删除站点
+	serve.SiteCache.ForgetSite(ctx, site)
+	Audit.record(ctx, c, nil, constants.AuditSiteDelete, constants.AuditTargetSite, site.ID, map[string]any{"name": site.Name, "project_id": site.ProjectID})
 	resps.Ok(c, resps.OK, map[string]any{
 		"site": Site.ToDTO(site, true),
 	})
 }
 
 func (SiteApi) Info(ctx context.Context, c *app.RequestContext) {
-	site := getSite(ctx)
+	site := getSite(c)
 	if site == nil {
 		resps.NotFound(c, resps.TargetNotFound)
 		return
@@ -135,3 +378,198 @@ func (SiteApi) Info(ctx context.Context, c *app.RequestContext) {
 		"site": Site.ToDTO(site, true),
 	})
 }
+
+// TailLogs 以 SSE 实时推送站点访问日志，支持按路径前缀和状态码类别过滤
+// Stream the site's access logs in real time via SSE, optionally filtered by path prefix and status class
+func (SiteApi) TailLogs(ctx context.Context, c *app.RequestContext) {
+	req := TailLogsReq{}
+	if !bind(c, &req) {
+		return
+	}
+	site := getSite(c)
+	if site == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	statusClass, ok := parseStatusClass(req.Status)
+	if !ok {
+		resps.BadRequest(c, "status must be a status class such as 4xx")
+		return
+	}
+	sub, err := middle.AccessLog.Subscribe(site, middle.AccessLogFilter{
+		PathPrefix:  req.PathPrefix,
+		StatusClass: statusClass,
+	})
+	if errors.Is(err, middle.ErrTooManyTails) {
+		resps.Custom(c, 429, err.Error())
+		return
+	}
+	defer middle.AccessLog.Unsubscribe(sub)
+
+	c.SetStatusCode(200)
+	c.Response.Header.SetContentType("text/event-stream")
+	c.Response.Header.Set("Cache-Control", "no-cache")
+	c.Response.Header.Set("X-Accel-Buffering", "no")
+	c.Response.HijackWriter(resp.NewChunkedBodyWriter(&c.Response, c.GetWriter()))
+	_, _ = c.WriteString(": connected\n\n")
+	if err := c.Flush(); err != nil {
+		return
+	}
+
+	idleTimeout := time.Duration(config.LogTailIdleTimeout) * time.Second
+	idle := time.NewTimer(idleTimeout)
+	defer idle.Stop()
+	heartbeat := time.NewTicker(tailHeartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case entry := <-sub.C():
+			data, err := json.Marshal(entry)
+			if err != nil {
+				continue
+			}
+			_, _ = c.WriteString("event: log\ndata: " + string(data) + "\n\n")
+			if err := c.Flush(); err != nil {
+				return
+			}
+			idle.Reset(idleTimeout)
+		case <-heartbeat.C:
+			// 心跳写入失败说明客户端已断开 A failed heartbeat means the client is gone
+			_, _ = c.WriteString(": ping\n\n")
+			if err := c.Flush(); err != nil {
+				return
+			}
+		case <-idle.C:
+			_, _ = c.WriteString("event: idle\ndata: {}\n\n")
+			_ = c.Flush()
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Logs 分页查询站点最近的请求日志，可按状态码或状态码类别、路径或路径前缀和时间范围过滤，默认最新的在前
+// Query the recent request logs of the site with pagination, optionally filtered by status code or class, path or path prefix and time range, newest first by default
+func (SiteApi) Logs(ctx context.Context, c *app.RequestContext) {
+	req := SiteLogsReq{}
+	if !bind(c, &req) {
+		return
+	}
+	site := getSite(c)
+	if site == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	filter := store.AccessLogFilter{Path: req.Path, PathPrefix: req.PathPrefix}
+	if code, err := strconv.Atoi(req.Status); err == nil && code >= 100 && code <= 599 {
+		filter.Status = code
+	} else if class, ok := parseStatusClass(req.Status); ok {
+		filter.StatusClass = class
+	} else {
+		resps.BadRequest(c, "status must be a status code such as 404 or a status class such as 4xx")
+		return
+	}
+	for _, bound := range []struct {
+		value string
+		t     *time.Time
+	}{{req.Since, &filter.Since}, {req.Until, &filter.Until}} {
+		if bound.value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, bound.value)
+		if err != nil {
+			resps.BadRequest(c, "since and until must be RFC 3339 times")
+			return
+		}
+		*bound.t = parsed
+	}
+	list, ok := getListQuery(c, "-created_at", "created_at", "status", "bytes", "latency_ms")
+	if !ok {
+		return
+	}
+	logs, total, err := store.AccessLog.List(ctx, site.ID, filter, list)
+	if err != nil {
+		resps.DBError(c, err, "get request logs error")
+		return
+	}
+	utils.Ctx.SetPageHeaders(c, list.Page, list.Limit, total)
+	resps.Ok(c, resps.OK, map[string]any{
+		"logs":  logs,
+		"total": total,
+	})
+}
+
+// DebugResolve 以解释模式运行生产环境的服务判定流程，返回匹配结果而不输出站点内容
+// Run the production serving pipeline in explain mode, returning the decision without serving any bytes
+func (SiteApi) DebugResolve(ctx context.Context, c *app.RequestContext) {
+	req := DebugResolveReq{}
+	if !bind(c, &req) {
+		return
+	}
+	site := getSite(c)
+	if site == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	if req.Host == "" {
+		req.Host = serve.CanonicalHost(site)
+	}
+	if req.Path == "" {
+		req.Path = "/"
+	}
+	// 调用方已通过项目权限校验 The caller already passed the project permission check
+	decision, err := serve.Resolver.Resolve(ctx, serve.Request{Host: req.Host, Path: req.Path, ClientIP: req.IP, Trusted: true})
+	// 只允许解释本站点的主机名，避免泄露其它站点的配置
+	// Only hosts of this site may be explained, to avoid leaking other sites' configuration
+	if errors.Is(err, serve.ErrNoSite) || (err == nil && decision.SiteID != site.ID) {
+		resps.BadRequest(c, "host does not belong to this site")
+		return
+	}
+	if err != nil {
+		resps.InternalServerError(c, resps.RespMessageWithError("resolve error", err))
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"decision": decision,
+	})
+}
+
+// parseStatusClass 解析状态码类别，接受 "4xx" 或 "4"，空字符串表示不过滤
+// Parse a status class, accepting "4xx" or "4", empty means no filter
+func parseStatusClass(status string) (int, bool) {
+	if status == "" {
+		return 0, true
+	}
+	if len(status) != 1 && (len(status) != 3 || status[1:] != "xx") {
+		return 0, false
+	}
+	class := int(status[0] - '0')
+	if class < 1 || class > 5 {
+		return 0, false
+	}
+	return class, true
+}
+
+// OGMeta 获取站点当前发布的 open-graph 元信息，供页面注入
+// Get the open-graph meta values of the site's active release for injection into pages
+func (SiteApi) OGMeta(ctx context.Context, c *app.RequestContext) {
+	site := getSite(c)
+	if site == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	meta := map[string]any{
+		"og:title":       site.Name,
+		"og:description": site.Description,
+		"og:site_name":   config.OGBrand,
+	}
+	if latestRelease, err := store.Site.GetLatestRelease(site); err == nil && site.OGPreview && latestRelease.PreviewPath != "" {
+		meta["og:image"] = string(c.URI().Scheme()) + "://" + string(c.Host()) + "/api/v1/site/" + strconv.Itoa(int(site.ID)) + "/og.png"
+		meta["og:image:width"] = utils.PreviewWidth
+		meta["og:image:height"] = utils.PreviewHeight
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"meta": meta,
+	})
+}
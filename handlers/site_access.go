@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/middle"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/serve"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// 站点访问密码的长度限制 Length limits of site access passwords
+const (
+	minAccessPassword = 4
+	maxAccessPassword = 128
+)
+
+// validAccess 合并站点当前的访问设置与请求中的修改，返回新的访问模式和密码哈希；离开密码模式时清除密码
+// Merge the site's current access settings with the requested changes, returning the new mode and password hash; leaving the password mode clears the password
+func (SiteApi) validAccess(site *models.Site, mode *constants.SiteAccessMode, password *string) (constants.SiteAccessMode, string, error) {
+	newMode, hash := site.AccessMode, site.AccessPassword
+	if newMode == "" {
+		newMode = constants.SiteAccessPublic
+	}
+	if mode != nil {
+		newMode = *mode
+	}
+	if password != nil {
+		if n := utf8.RuneCountInString(*password); n < minAccessPassword || n > maxAccessPassword {
+			return "", "", errors.New("the access password must be " + strconv.Itoa(minAccessPassword) + " to " + strconv.Itoa(maxAccessPassword) + " characters")
+		}
+		hashed, err := utils.Password.HashPassword(*password)
+		if err != nil {
+			return "", "", err
+		}
+		hash = hashed
+	}
+	if newMode != constants.SiteAccessPassword {
+		return newMode, "", nil
+	}
+	if hash == "" {
+		return "", "", errors.New("an access password is required for the password mode")
+	}
+	return newMode, hash, nil
+}
+
+// Access 为仅限成员访问的站点或私有实例的站点签发跳转凭据并返回站点，未登录时先跳转到面板登录页，return 为要访问的站点地址；
+// 私有实例下任何已登录的用户都可以访问非成员站点
+// Issue a ticket for a members-only site or a site of a private instance and go back to it, signing in on the panel first when needed; return is the site address to visit;
+// any signed-in user may visit sites other than members-only ones on a private instance
+func (SiteApi) Access(ctx context.Context, c *app.RequestContext) {
+	siteID, err := strconv.Atoi(c.Param("site_id"))
+	if err != nil {
+		resps.BadRequest(c, resps.ParameterError)
+		return
+	}
+	site, err := store.Site.GetByID(uint(siteID))
+	members := err == nil && site.AccessMode == constants.SiteAccessMembers
+	if err != nil || (!members && !serve.Private()) {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	target, err := url.Parse(c.Query("return"))
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || !serve.HostMatches(site, target.Hostname()) {
+		resps.BadRequest(c, "invalid return address")
+		return
+	}
+	userID := middle.Auth.SessionUser(c)
+	if userID == 0 {
+		self := strings.TrimSuffix(config.FrontEndURL, "/") + string(c.Request.RequestURI())
+		c.Redirect(http.StatusFound, []byte(strings.TrimSuffix(config.FrontEndURL, "/")+"/login?redirect="+url.QueryEscape(self)))
+		return
+	}
+	issue := serve.Protection.InstanceTicket
+	if members {
+		if !store.Project.UserRole(&site.Project, userID).Valid() {
+			resps.Forbidden(c, "only project members may visit this site")
+			return
+		}
+		issue = serve.Protection.Ticket
+	}
+	ticket, err := issue(site, userID)
+	if err != nil {
+		resps.InternalServerError(c, "failed to issue access ticket")
+		return
+	}
+	back := target.Scheme + "://" + target.Host + serve.AccessPathPrefix + "access?ticket=" + url.QueryEscape(ticket) + "&return=" + url.QueryEscape(target.RequestURI())
+	c.Redirect(http.StatusFound, []byte(back))
+}
+
+// SignURL 为受保护站点的一个文件签发临时链接，无需登录即可访问直到过期，用于对外分享单个文件；修改站点的访问设置后所有链接失效
+// Issue a temporary URL for one file of a protected site, reachable without signing in until it expires, to share a single file outside; changing the site's access settings voids every URL
+func (SiteApi) SignURL(ctx context.Context, c *app.RequestContext) {
+	req := SignURLReq{}
+	if !bindJSON(c, &req) {
+		return
+	}
+	site := getSite(c)
+	if site == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	if !serve.Protected(site) {
+		resps.BadRequest(c, "the site is not access protected")
+		return
+	}
+	ttl := config.SiteAccessSignedURLTTL
+	if req.ExpiresIn > 0 {
+		ttl = req.ExpiresIn
+	}
+	ttl = min(ttl, config.SiteAccessSignedURLMaxTTL)
+	user := middle.Auth.GetUser(ctx, c)
+	signed, err := serve.Protection.SignURL(site, user.ID, req.Path, time.Duration(ttl)*time.Second)
+	if err != nil {
+		resps.BadRequest(c, err.Error())
+		return
+	}
+	expiresAt := time.Now().Add(time.Duration(ttl) * time.Second)
+	Audit.record(ctx, c, nil, constants.AuditSiteSignURL, constants.AuditTargetSite, site.ID, map[string]any{"path": req.Path, "expires_at": expiresAt})
+	resps.Ok(c, resps.OK, map[string]any{
+		"url":        signed,
+		"expires_at": expiresAt,
+	})
+}
@@ -1,5 +1,11 @@
 package handlers
 
+import (
+	"time"
+
+	"github.com/LiteyukiStudio/spage/constants"
+)
+
 // SiteDTO 网站详情
 // Site Detail
 type SiteDTO struct {
@@ -10,21 +16,103 @@ type SiteDTO struct {
 	Project     ProjectDTO `json:"project"`     // 项目详情 ProjectDetail
 	SubDomain   *string    `json:"sub_domain"`  // 子域名 SubDomain
 	Domains     []string   `json:"domains"`     // 域名 Domains
+	OGPreview   bool       `json:"og_preview"`  // 是否生成预览图 Whether to generate preview images
+	Headers     []string   `json:"headers"`     // 自定义响应头 Custom response headers
+	CacheRules  []string   `json:"cache_rules"` // 按路径的 Cache-Control Cache-Control by path
+
+	AccessMode        constants.SiteAccessMode `json:"access_mode"`         // 访问保护方式 Access protection
+	HasAccessPassword bool                     `json:"has_access_password"` // 是否设置了访问密码 Whether an access password is set
+
+	AllowIPs []string `json:"allow_ips"` // 允许访问的地址或 CIDR 网段 Addresses or CIDR ranges allowed to visit
+	DenyIPs  []string `json:"deny_ips"`  // 禁止访问的地址或 CIDR 网段 Addresses or CIDR ranges refused
+
+	Sitemap      bool   `json:"sitemap"`       // 是否生成 sitemap.xml Whether sitemap.xml is generated
+	RobotsPolicy string `json:"robots_policy"` // robots.txt 策略 Robots policy
+	RobotsTxt    string `json:"robots_txt"`    // 自定义 robots.txt 内容 Custom robots.txt content
 }
 
 // CreateSiteReq 创建网站请求参数
 // Create Site Request Parameters
 type CreateSiteReq struct {
-	Name        string   `json:"name" binding:"required"`       // 网站名称 WebSiteName
-	Description string   `json:"description"`                   // 网站描述 WebSiteDescription
-	ProjectID   uint     `json:"project_id" binding:"required"` // 项目ID ProjectID
-	SubDomain   *string  `json:"sub_domain"`                    // 子域名 SubDomain
-	Domains     []string `json:"domains"`                       // 域名 Domains
+	Name        string   `json:"name" binding:"required" validate:"required,max=63,slug,reserved"` // 网站名称 WebSiteName
+	Description string   `json:"description" validate:"max=1024"`                                  // 网站描述 WebSiteDescription
+	SubDomain   *string  `json:"sub_domain" validate:"required,max=63,slug,reserved"`              // 子域名 SubDomain
+	Domains     []string `json:"domains"`                                                          // 域名 Domains
+	OGPreview   bool     `json:"og_preview"`                                                       // 是否生成预览图 Whether to generate preview images
+	Headers     []string `json:"headers"`                                                          // 自定义响应头，格式为 "Name: value" Custom response headers, formatted as "Name: value"
+	CacheRules  []string `json:"cache_rules"`                                                      // 按路径的 Cache-Control，格式为 "<路径模式> <值>" Cache-Control by path, formatted as "<pattern> <value>"
 }
 
 type UpdateSiteReq struct {
-	Name        *string  `json:"name"`        // 网站名称 WebSiteName
-	Description *string  `json:"description"` // 网站描述 WebSiteDescription
-	SubDomain   *string  `json:"sub_domain"`  // 子域名 SubDomain
-	Domains     []string `json:"domains"`     // 域名 Domains
+	Name        *string   `json:"name" validate:"required,max=63,slug,reserved"`       // 网站名称 WebSiteName
+	Description *string   `json:"description" validate:"max=1024"`                     // 网站描述 WebSiteDescription
+	SubDomain   *string   `json:"sub_domain" validate:"required,max=63,slug,reserved"` // 子域名 SubDomain
+	Domains     []string  `json:"domains"`                                             // 域名 Domains
+	OGPreview   *bool     `json:"og_preview"`                                          // 是否生成预览图 Whether to generate preview images
+	Headers     *[]string `json:"headers"`                                             // 自定义响应头，格式为 "Name: value" Custom response headers, formatted as "Name: value"
+	CacheRules  *[]string `json:"cache_rules"`                                         // 按路径的 Cache-Control，格式为 "<路径模式> <值>" Cache-Control by path, formatted as "<pattern> <value>"
+
+	AccessMode     *constants.SiteAccessMode `json:"access_mode"`     // 访问保护方式 Access protection
+	AccessPassword *string                   `json:"access_password"` // 访问密码，只写，切换到密码模式时必填 Access password, write-only and required when switching to the password mode
+
+	AllowIPs *[]string `json:"allow_ips"` // 允许访问的地址或 CIDR 网段，为空时不限制 Addresses or CIDR ranges allowed to visit, no restriction when empty
+	DenyIPs  *[]string `json:"deny_ips"`  // 禁止访问的地址或 CIDR 网段，优先于允许规则 Addresses or CIDR ranges refused, taking precedence over allow rules
+
+	Sitemap      *bool   `json:"sitemap"`       // 上传的文件中没有 sitemap.xml 时是否按部署的页面生成 Whether sitemap.xml is generated from the deployed pages when the upload has none
+	RobotsPolicy *string `json:"robots_policy"` // 上传的文件中没有 robots.txt 时使用的策略：allow、disallow、custom 或空 Policy used when the upload has no robots.txt: allow, disallow, custom or empty
+	RobotsTxt    *string `json:"robots_txt"`    // 自定义 robots.txt 内容，custom 策略必填 Custom robots.txt content, required by the custom policy
+}
+
+// DebugResolveReq 服务判定调试请求参数
+// Serving decision debug request parameters
+type DebugResolveReq struct {
+	Path string `query:"path"` // 请求路径 Request path
+	Host string `query:"host"` // 请求主机名，默认为站点规范主机名 Request host, defaults to the site's canonical host
+	IP   string `query:"ip"`   // 客户端地址，为空时不检查 IP 规则 Client address, IP rules are not checked when empty
+}
+
+// TailLogsReq 实时日志请求参数
+// Live log tail request parameters
+type TailLogsReq struct {
+	PathPrefix string `query:"path_prefix"` // 路径前缀 Path prefix
+	Status     string `query:"status"`      // 状态码类别，例如 4xx Status class, e.g. 4xx
+}
+
+// SiteLogsReq 查询站点请求日志的参数
+// Parameters to query the request logs of a site
+type SiteLogsReq struct {
+	Status     string `query:"status"`      // 状态码如 404 或类别如 4xx Status code such as 404 or class such as 4xx
+	Path       string `query:"path"`        // 完整路径 Full path
+	PathPrefix string `query:"path_prefix"` // 路径前缀 Path prefix
+	Since      string `query:"since"`       // 起始时间（RFC 3339） Start time (RFC 3339)
+	Until      string `query:"until"`       // 结束时间（RFC 3339） End time (RFC 3339)
+}
+
+// CustomDomainDTO 自定义域名及其验证方式
+// Custom domain and its verification instructions
+type CustomDomainDTO struct {
+	ID            uint                         `json:"id"`                      // 域名ID Domain ID
+	Domain        string                       `json:"domain"`                  // 域名 Domain
+	Method        constants.DomainVerifyMethod `json:"method"`                  // 验证方式 Verification method
+	Verified      bool                         `json:"verified"`                // 是否已验证 Whether it is verified
+	VerifiedAt    *time.Time                   `json:"verified_at"`             // 验证通过时间 Verified time
+	LastCheckedAt *time.Time                   `json:"last_checked_at"`         // 最后一次验证时间 Last verification attempt
+	LastError     string                       `json:"last_error"`              // 最后一次验证失败的原因 Reason of the last failed verification
+	RecordName    string                       `json:"record_name,omitempty"`   // DNS 验证需要创建的 TXT 记录名 TXT record name for DNS verification
+	RecordValue   string                       `json:"record_value,omitempty"`  // DNS 验证需要创建的 TXT 记录值 TXT record value for DNS verification
+	ChallengeURL  string                       `json:"challenge_url,omitempty"` // HTTP 验证时请求的地址 URL requested by HTTP verification
+}
+
+// CreateCustomDomainReq 绑定自定义域名的请求参数
+// Request parameters to bind a custom domain
+type CreateCustomDomainReq struct {
+	Domain string                       `json:"domain" binding:"required" validate:"required,domain"` // 域名 Domain
+	Method constants.DomainVerifyMethod `json:"method"`                                               // 验证方式，默认为 dns Verification method, dns by default
+}
+
+// SignURLReq 签发受保护站点临时链接的请求参数
+// Request parameters to issue a temporary URL of a protected site
+type SignURLReq struct {
+	Path      string `json:"path" validate:"required,max=1024"` // 链接指向的文件路径 File path the URL points to
+	ExpiresIn int    `json:"expires_in" validate:"min=0"`       // 有效期，单位秒，默认 site-access.signed-url-ttl，最长 site-access.signed-url-max-ttl Lifetime in seconds, site-access.signed-url-ttl by default and at most site-access.signed-url-max-ttl
 }
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+const (
+	// defaultStatsDays 默认统计的天数
+	// Days covered by default
+	defaultStatsDays = 30
+	// maxStatsDays 最多统计的天数
+	// Max days covered
+	maxStatsDays = 365
+	// topSitesLimit 返回的流量最高的站点数量
+	// Number of top sites by traffic returned
+	topSitesLimit = 10
+)
+
+type StatsApi struct{}
+
+// Stats 管理员查看的实例统计，数据在服务端汇总
+// Instance statistics for administrators, aggregated on the server
+var Stats = StatsApi{}
+
+// Get 获取最近 days 天的实例统计，默认 30 天；数据库无法连接时只返回数据库的健康状况
+// Get the instance statistics of the last days days, 30 by default; only the database health is returned when the database is unreachable
+func (StatsApi) Get(ctx context.Context, c *app.RequestContext) {
+	days := defaultStatsDays
+	if value := c.Query("days"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > maxStatsDays {
+			resps.BadRequest(c, resps.ParameterError)
+			return
+		}
+		days = n
+	}
+	stats := StatsDTO{Days: days, Database: Stats.database(ctx)}
+	if !stats.Database.Healthy {
+		resps.Custom(c, 503, "database unavailable", map[string]any{"stats": stats})
+		return
+	}
+	if err := Stats.collect(ctx, &stats); err != nil {
+		utils.Log.Ctx(ctx).Errorf("failed to collect instance statistics: %v", err)
+		resps.InternalServerError(c, "get stats error")
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{"stats": stats})
+}
+
+func (StatsApi) database(ctx context.Context) DatabaseStatsDTO {
+	db, err := store.Stats.Database(ctx)
+	dto := DatabaseStatsDTO{
+		Healthy:        err == nil,
+		Driver:         db.Driver,
+		PingMs:         float64(db.Ping.Microseconds()) / 1000,
+		SchemaVersion:  db.SchemaVersion,
+		LatestVersion:  models.LatestVersion(),
+		SizeBytes:      db.SizeBytes,
+		InUse:          db.InUse,
+		Idle:           db.Idle,
+		MaxOpen:        db.MaxOpen,
+		WaitCount:      db.WaitCount,
+		WaitDurationMs: float64(db.WaitDuration.Microseconds()) / 1000,
+	}
+	if err != nil {
+		dto.Error = err.Error()
+	}
+	return dto
+}
+
+// collect 汇总数量、部署、存储、流量和任务统计
+// Aggregate the counts, deployments, storage, traffic and job statistics
+func (StatsApi) collect(ctx context.Context, stats *StatsDTO) (err error) {
+	now := time.Now()
+	since := now.UTC().Truncate(24*time.Hour).AddDate(0, 0, 1-stats.Days)
+	counts := []struct {
+		model any
+		dto   *CountDTO
+		trash bool
+	}{
+		{&models.User{}, &stats.Users, false},
+		{&models.Organization{}, &stats.Orgs, false},
+		{&models.Project{}, &stats.Projects, true},
+		{&models.Site{}, &stats.Sites.CountDTO, true},
+	}
+	for _, count := range counts {
+		if count.dto.Total, count.dto.Recent, err = store.Stats.Count(ctx, count.model, since); err != nil {
+			return err
+		}
+		if count.trash {
+			if count.dto.Trashed, err = store.Stats.CountTrashed(ctx, count.model); err != nil {
+				return err
+			}
+		}
+	}
+	if stats.Sites.Active, err = store.Site.CountActive(); err != nil {
+		return err
+	}
+	releases, err := store.Stats.DailyReleases(ctx, since)
+	if err != nil {
+		return err
+	}
+	stats.Deployments = make([]DailyCountDTO, 0, len(releases))
+	for _, day := range releases {
+		stats.Deployments = append(stats.Deployments, DailyCountDTO{Day: day.Day, Count: day.Count})
+	}
+	if stats.Storage.Files, stats.Storage.FileBytes, err = store.File.TotalSize(); err != nil {
+		return err
+	}
+	if stats.Storage.Blobs, stats.Storage.BlobBytes, err = store.Blob.TotalSize(); err != nil {
+		return err
+	}
+	stats.Storage.TotalBytes = stats.Storage.FileBytes + stats.Storage.BlobBytes
+	// 先写入本实例尚未写入的流量 Write the traffic this instance has not written yet first
+	if err := store.Traffic.Flush(ctx); err != nil {
+		utils.Log.Ctx(ctx).Warnf("failed to write site traffic: %v", err)
+	}
+	top, err := store.Traffic.Top(ctx, since, topSitesLimit)
+	if err != nil {
+		return err
+	}
+	stats.TopSites = make([]SiteTrafficDTO, 0, len(top))
+	for _, site := range top {
+		stats.TopSites = append(stats.TopSites, SiteTrafficDTO{SiteID: site.SiteID, Name: site.Name, Requests: site.Requests, Bytes: site.Bytes})
+	}
+	stats.Jobs, err = store.Stats.JobCounts(ctx)
+	return err
+}
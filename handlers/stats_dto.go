@@ -0,0 +1,74 @@
+package handlers
+
+// StatsDTO 实例统计数据传输对象
+// Instance statistics Data Transfer Object (DTO)
+type StatsDTO struct {
+	Days        int              `json:"days"`        // 统计的天数 Days covered
+	Users       CountDTO         `json:"users"`       // 用户数 Users
+	Orgs        CountDTO         `json:"orgs"`        // 组织数 Organizations
+	Projects    CountDTO         `json:"projects"`    // 项目数 Projects
+	Sites       SiteCountDTO     `json:"sites"`       // 站点数 Sites
+	Deployments []DailyCountDTO  `json:"deployments"` // 每天的部署次数 Deployments per day
+	Storage     StorageStatsDTO  `json:"storage"`     // 存储用量 Storage usage
+	TopSites    []SiteTrafficDTO `json:"top_sites"`   // 请求数最多的站点 Sites with the most requests
+	Jobs        map[string]int64 `json:"jobs"`        // 各状态的后台任务数 Background jobs by status
+	Database    DatabaseStatsDTO `json:"database"`    // 数据库健康状况 Database health
+}
+
+// CountDTO 总数与统计期内新增的数量
+// Total count and the count added within the period
+type CountDTO struct {
+	Total   int64 `json:"total"`             // 总数 Total
+	Recent  int64 `json:"recent"`            // 统计期内新增 Added within the period
+	Trashed int64 `json:"trashed,omitempty"` // 回收站中的数量 In the trash
+}
+
+// SiteCountDTO 站点数量
+// Site counts
+type SiteCountDTO struct {
+	CountDTO
+	Active int64 `json:"active"` // 有激活版本的站点 Sites with an active version
+}
+
+// DailyCountDTO 一天内的数量
+// Count within a day
+type DailyCountDTO struct {
+	Day   string `json:"day"`   // UTC 日期 UTC date
+	Count int64  `json:"count"` // 数量 Count
+}
+
+// StorageStatsDTO 存储用量
+// Storage usage
+type StorageStatsDTO struct {
+	Files      int64 `json:"files"`       // 版本压缩包和部署清单数 Release archives and manifests
+	FileBytes  int64 `json:"file_bytes"`  // 版本压缩包和部署清单的字节数 Bytes of release archives and manifests
+	Blobs      int64 `json:"blobs"`       // 按内容存储的文件数 Files stored by content
+	BlobBytes  int64 `json:"blob_bytes"`  // 按内容存储的文件字节数 Bytes of files stored by content
+	TotalBytes int64 `json:"total_bytes"` // 总字节数 Total bytes
+}
+
+// SiteTrafficDTO 站点在统计期内的流量
+// Traffic of a site within the period
+type SiteTrafficDTO struct {
+	SiteID   uint   `json:"site_id"`  // 站点ID Site ID
+	Name     string `json:"name"`     // 站点名称，已彻底删除时为空 Site name, empty once purged
+	Requests int64  `json:"requests"` // 请求数 Requests
+	Bytes    int64  `json:"bytes"`    // 响应字节数 Response bytes
+}
+
+// DatabaseStatsDTO 数据库健康状况
+// Database health
+type DatabaseStatsDTO struct {
+	Healthy        bool    `json:"healthy"`          // 是否可以连接 Whether it is reachable
+	Error          string  `json:"error,omitempty"`  // 检查失败的原因 Why the check failed
+	Driver         string  `json:"driver"`           // 数据库驱动 Database driver
+	PingMs         float64 `json:"ping_ms"`          // 往返耗时（毫秒） Round trip in milliseconds
+	SchemaVersion  int     `json:"schema_version"`   // 当前迁移版本 Current migration version
+	LatestVersion  int     `json:"latest_version"`   // 程序支持的最新迁移版本 Latest migration version of the binary
+	SizeBytes      int64   `json:"size_bytes"`       // 数据库大小 Database size
+	InUse          int     `json:"in_use"`           // 使用中的连接 Connections in use
+	Idle           int     `json:"idle"`             // 空闲连接 Idle connections
+	MaxOpen        int     `json:"max_open"`         // 最大连接数，0 为不限制 Max open connections, 0 for unlimited
+	WaitCount      int64   `json:"wait_count"`       // 因连接池耗尽而等待的次数 Waits for an exhausted pool
+	WaitDurationMs float64 `json:"wait_duration_ms"` // 等待连接的总时长（毫秒） Total wait in milliseconds
+}
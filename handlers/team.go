@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+type TeamApi struct{}
+
+var Team = TeamApi{}
+
+func (TeamApi) toDTO(team *models.Team) TeamDTO {
+	return TeamDTO{
+		ID:             team.ID,
+		OrganizationID: team.OrganizationID,
+		Name:           team.Name,
+		Description:    team.Description,
+	}
+}
+
+// getTeam 获取路径中指定的组织团队，不存在时返回 404
+// Get the organization team given in the path, answering 404 when it does not exist
+func (TeamApi) getTeam(c *app.RequestContext) *models.Team {
+	org := getOrg(c)
+	teamID, err := strconv.Atoi(c.Param("team_id"))
+	if err != nil {
+		resps.BadRequest(c, resps.ParameterError)
+		return nil
+	}
+	team, err := store.Team.GetByOrg(org.ID, uint(teamID))
+	if err != nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return nil
+	}
+	return team
+}
+
+// List 获取组织的团队
+// List the teams of the organization
+func (TeamApi) List(ctx context.Context, c *app.RequestContext) {
+	org := getOrg(c)
+	teams, err := store.Team.ListByOrg(org.ID)
+	if err != nil {
+		resps.DBError(c, err, "get teams error")
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"teams": func() (teamDTOs []TeamDTO) {
+			for _, team := range teams {
+				teamDTOs = append(teamDTOs, Team.toDTO(&team))
+			}
+			return
+		}(),
+	})
+}
+
+// Create 创建团队
+// Create a team
+func (TeamApi) Create(ctx context.Context, c *app.RequestContext) {
+	req := TeamReq{}
+	if !bind(c, &req) {
+		return
+	}
+	org := getOrg(c)
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || store.Team.NameIsExist(org.ID, req.Name, 0) {
+		resps.BadRequest(c, "team name is empty or already exists")
+		return
+	}
+	team := models.Team{OrganizationID: org.ID, Name: req.Name, Description: req.Description}
+	if err := store.Team.Create(&team); err != nil {
+		resps.DBError(c, err, "create team error")
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"team": Team.toDTO(&team),
+	})
+}
+
+// Update 更新团队
+// Update a team
+func (TeamApi) Update(ctx context.Context, c *app.RequestContext) {
+	req := TeamReq{}
+	if !bind(c, &req) {
+		return
+	}
+	team := Team.getTeam(c)
+	if team == nil {
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || store.Team.NameIsExist(team.OrganizationID, req.Name, team.ID) {
+		resps.BadRequest(c, "team name is empty or already exists")
+		return
+	}
+	team.Name, team.Description = req.Name, req.Description
+	if err := store.Team.Update(team); err != nil {
+		resps.DBError(c, err, "update team error")
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"team": Team.toDTO(team),
+	})
+}
+
+// Delete 删除团队，团队的项目授权一并撤销
+// Delete a team, revoking its project grants
+func (TeamApi) Delete(ctx context.Context, c *app.RequestContext) {
+	team := Team.getTeam(c)
+	if team == nil {
+		return
+	}
+	if err := store.Team.Delete(team); err != nil {
+		resps.DBError(c, err, "delete team error")
+		return
+	}
+	resps.Ok(c, resps.OK)
+}
+
+// Members 获取团队成员
+// List the members of a team
+func (TeamApi) Members(ctx context.Context, c *app.RequestContext) {
+	team := Team.getTeam(c)
+	if team == nil {
+		return
+	}
+	users, err := store.Team.ListMembers(team.ID)
+	if err != nil {
+		resps.DBError(c, err, "get team members error")
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"members": func() (userDTOs []UserDTO) {
+			for _, user := range users {
+				userDTOs = append(userDTOs, User.ToDTO(&user, false))
+			}
+			return
+		}(),
+	})
+}
+
+// AddMember 添加团队成员，用户必须已是组织成员
+// Add a team member, the user must already be a member of the organization
+func (TeamApi) AddMember(ctx context.Context, c *app.RequestContext) {
+	req := TeamUserReq{}
+	if !bind(c, &req) {
+		return
+	}
+	team := Team.getTeam(c)
+	if team == nil {
+		return
+	}
+	if !store.Org.GetUserAuth(getOrg(c), req.UserID).Valid() {
+		resps.BadRequest(c, "user is not a member of the organization")
+		return
+	}
+	if err := store.Team.AddMember(team.ID, req.UserID); err != nil {
+		resps.DBError(c, err, "add team member error")
+		return
+	}
+	Audit.record(ctx, c, nil, constants.AuditTeamMemberAdd, constants.AuditTargetTeam, team.ID, map[string]any{"user_id": req.UserID, "organization_id": team.OrganizationID})
+	resps.Ok(c, resps.OK)
+}
+
+// RemoveMember 移除团队成员
+// Remove a team member
+func (TeamApi) RemoveMember(ctx context.Context, c *app.RequestContext) {
+	req := TeamUserReq{}
+	if !bind(c, &req) {
+		return
+	}
+	team := Team.getTeam(c)
+	if team == nil {
+		return
+	}
+	if err := store.Team.RemoveMember(team.ID, req.UserID); err != nil {
+		resps.DBError(c, err, "remove team member error")
+		return
+	}
+	Audit.record(ctx, c, nil, constants.AuditTeamMemberRemove, constants.AuditTargetTeam, team.ID, map[string]any{"user_id": req.UserID, "organization_id": team.OrganizationID})
+	resps.Ok(c, resps.OK)
+}
@@ -0,0 +1,304 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/middle"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/serve"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+type TenantApi struct{}
+
+// Tenant 多租户模式，管理员创建租户并将用户和组织分配到租户，租户有自己的基础域名、默认配额和品牌
+// Multi-tenant mode, administrators create tenants and assign users and organizations to them, each tenant has its own base domain, default quotas and branding
+var Tenant = TenantApi{}
+
+var primaryColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// sameTenant 两个租户ID是否相同，都为空时同属实例本身
+// Whether the two tenant IDs are the same, both empty means both belong to the instance itself
+func sameTenant(a, b *uint) bool {
+	return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+}
+
+func (TenantApi) toDTO(tenant *models.Tenant) TenantDTO {
+	return TenantDTO{
+		ID:                tenant.ID,
+		Name:              tenant.Name,
+		DisplayName:       tenant.DisplayName,
+		BaseDomain:        tenant.BaseDomain,
+		LogoURL:           tenant.LogoURL,
+		PrimaryColor:      tenant.PrimaryColor,
+		MaxProjects:       tenant.MaxProjects,
+		MaxStorage:        tenant.MaxStorage,
+		MaxDeploymentSize: tenant.MaxDeploymentSize,
+		MaxUploadSize:     tenant.MaxUploadSize,
+	}
+}
+
+// toAdminDTO 租户及其成员数量
+// Tenant with the number of its members
+func (TenantApi) toAdminDTO(tenant *models.Tenant) (TenantDTO, error) {
+	dto := Tenant.toDTO(tenant)
+	users, orgs, err := store.Tenant.CountMembers(tenant.ID)
+	if err != nil {
+		return dto, err
+	}
+	dto.Users, dto.Organizations = &users, &orgs
+	return dto, nil
+}
+
+// applyReq 校验请求并写入租户，失败时已写入响应
+// Validate the request and apply it to the tenant; the response is already written on failure
+func (TenantApi) applyReq(c *app.RequestContext, tenant *models.Tenant, req *TenantReq) bool {
+	for _, limit := range []*int64{req.MaxProjects, req.MaxStorage, req.MaxDeploymentSize, req.MaxUploadSize} {
+		if limit != nil && *limit < 0 {
+			resps.BadRequest(c, "quota limits must not be negative, use 0 for unlimited")
+			return false
+		}
+	}
+	if req.PrimaryColor != "" && !primaryColorPattern.MatchString(req.PrimaryColor) {
+		resps.BadRequest(c, "primary color must be a hex color such as #3b82f6")
+		return false
+	}
+	var baseDomain *string
+	if req.BaseDomain != nil && *req.BaseDomain != "" {
+		name, err := utils.Domain.Normalize(*req.BaseDomain)
+		if err != nil {
+			resps.BadRequest(c, "invalid domain name")
+			return false
+		}
+		if name == config.ServeBaseDomain || name == config.ServeProjectDomain {
+			resps.BadRequest(c, "the base domain of a tenant must differ from the base domains of the instance")
+			return false
+		}
+		baseDomain = &name
+	}
+	tenant.Name = req.Name
+	tenant.DisplayName = req.DisplayName
+	tenant.BaseDomain = baseDomain
+	tenant.LogoURL = req.LogoURL
+	tenant.PrimaryColor = req.PrimaryColor
+	tenant.MaxProjects = req.MaxProjects
+	tenant.MaxStorage = req.MaxStorage
+	tenant.MaxDeploymentSize = req.MaxDeploymentSize
+	tenant.MaxUploadSize = req.MaxUploadSize
+	return true
+}
+
+// adminTenant 获取路径中的租户，失败时已写入响应
+// Get the tenant of the path; the response is already written on failure
+func (TenantApi) adminTenant(c *app.RequestContext) *models.Tenant {
+	id, err := strconv.Atoi(c.Param("tenant_id"))
+	if err != nil || id <= 0 {
+		resps.BadRequest(c, resps.ParameterError)
+		return nil
+	}
+	tenant, err := store.Tenant.GetByID(uint(id))
+	if err != nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return nil
+	}
+	return tenant
+}
+
+// forget 失效租户所有站点的缓存，使新的基础域名立即生效
+// Invalidate the cache of every site of the tenant so a new base domain applies at once
+func (TenantApi) forget(ctx context.Context, tenantID uint) {
+	serve.SiteCache.ForgetTenants(ctx)
+	ids, err := store.Tenant.ProjectIDs(tenantID)
+	if err != nil {
+		utils.Log.Ctx(ctx).Warnf("failed to list projects of tenant %d for cache invalidation: %v", tenantID, err)
+		return
+	}
+	for _, id := range ids {
+		serve.SiteCache.ForgetProject(ctx, id)
+	}
+}
+
+// Current 当前用户所属的租户，用于展示租户的品牌，不属于任何租户时为空
+// Tenant of the current user for showing its branding, null when the user belongs to none
+func (TenantApi) Current(ctx context.Context, c *app.RequestContext) {
+	user := middle.Auth.GetUser(ctx, c)
+	var dto *TenantDTO
+	if user.TenantID != nil {
+		tenant, err := store.Tenant.GetByID(*user.TenantID)
+		if err != nil {
+			resps.DBError(c, err, "get tenant error")
+			return
+		}
+		current := Tenant.toDTO(tenant)
+		// 默认配额只对管理员可见 Default quotas are only shown to administrators
+		current.MaxProjects, current.MaxStorage, current.MaxDeploymentSize, current.MaxUploadSize = nil, nil, nil, nil
+		dto = &current
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"tenant": dto,
+	})
+}
+
+// AdminList 管理员获取所有租户
+// Admin lists all tenants
+func (TenantApi) AdminList(ctx context.Context, c *app.RequestContext) {
+	tenants, err := store.Tenant.List()
+	if err != nil {
+		resps.InternalServerError(c, "list tenants error")
+		return
+	}
+	dtos := make([]TenantDTO, 0, len(tenants))
+	for i := range tenants {
+		dto, err := Tenant.toAdminDTO(&tenants[i])
+		if err != nil {
+			resps.InternalServerError(c, "list tenants error")
+			return
+		}
+		dtos = append(dtos, dto)
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"tenants": dtos,
+	})
+}
+
+// AdminCreate 管理员创建租户
+// Admin creates a tenant
+func (TenantApi) AdminCreate(ctx context.Context, c *app.RequestContext) {
+	req := TenantReq{}
+	if !bindJSON(c, &req) {
+		return
+	}
+	tenant := &models.Tenant{}
+	if !Tenant.applyReq(c, tenant, &req) {
+		return
+	}
+	if err := store.Tenant.Create(tenant); err != nil {
+		resps.DBError(c, err, "create tenant error")
+		return
+	}
+	serve.SiteCache.ForgetTenants(ctx)
+	Audit.record(ctx, c, nil, constants.AuditTenantUpdate, constants.AuditTargetTenant, tenant.ID, map[string]any{"operation": "create", "name": tenant.Name, "base_domain": tenant.BaseDomain})
+	dto, err := Tenant.toAdminDTO(tenant)
+	if err != nil {
+		resps.InternalServerError(c, "get tenant error")
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"tenant": dto,
+	})
+}
+
+// AdminUpdate 管理员修改租户，修改基础域名后租户的站点只在新的基础域名下访问
+// Admin changes a tenant, after a base domain change the tenant's sites are only served under the new base domain
+func (TenantApi) AdminUpdate(ctx context.Context, c *app.RequestContext) {
+	tenant := Tenant.adminTenant(c)
+	if tenant == nil {
+		return
+	}
+	req := TenantReq{}
+	if !bindJSON(c, &req) {
+		return
+	}
+	if !Tenant.applyReq(c, tenant, &req) {
+		return
+	}
+	if err := store.Tenant.Update(tenant); err != nil {
+		resps.DBError(c, err, "update tenant error")
+		return
+	}
+	Tenant.forget(ctx, tenant.ID)
+	Audit.record(ctx, c, nil, constants.AuditTenantUpdate, constants.AuditTargetTenant, tenant.ID, map[string]any{"operation": "update", "name": tenant.Name, "base_domain": tenant.BaseDomain})
+	dto, err := Tenant.toAdminDTO(tenant)
+	if err != nil {
+		resps.InternalServerError(c, "get tenant error")
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"tenant": dto,
+	})
+}
+
+// AdminDelete 管理员删除租户，租户中仍有用户或组织时拒绝
+// Admin deletes a tenant, refused while users or organizations remain in it
+func (TenantApi) AdminDelete(ctx context.Context, c *app.RequestContext) {
+	tenant := Tenant.adminTenant(c)
+	if tenant == nil {
+		return
+	}
+	users, orgs, err := store.Tenant.CountMembers(tenant.ID)
+	if err != nil {
+		resps.InternalServerError(c, "delete tenant error")
+		return
+	}
+	if users > 0 || orgs > 0 {
+		resps.Fail(c, http.StatusConflict, resps.CodeConflict, "move the users and organizations out of the tenant first", nil)
+		return
+	}
+	if err := store.Tenant.Delete(tenant); err != nil {
+		resps.DBError(c, err, "delete tenant error")
+		return
+	}
+	serve.SiteCache.ForgetTenants(ctx)
+	Audit.record(ctx, c, nil, constants.AuditTenantUpdate, constants.AuditTargetTenant, tenant.ID, map[string]any{"operation": "delete", "name": tenant.Name})
+	resps.Ok(c, resps.OK)
+}
+
+// AdminAssign 管理员将用户或组织连同其拥有的项目移入租户，已有的组织成员和项目成员不受影响
+// Admin moves a user or organization together with the projects it owns into a tenant; existing organization and project members are left alone
+func (TenantApi) AdminAssign(ctx context.Context, c *app.RequestContext) {
+	tenant := Tenant.adminTenant(c)
+	if tenant == nil {
+		return
+	}
+	Tenant.assign(ctx, c, tenant, true)
+}
+
+// AdminUnassign 管理员将用户或组织连同其拥有的项目移回实例本身
+// Admin moves a user or organization together with the projects it owns back to the instance itself
+func (TenantApi) AdminUnassign(ctx context.Context, c *app.RequestContext) {
+	tenant := Tenant.adminTenant(c)
+	if tenant == nil {
+		return
+	}
+	Tenant.assign(ctx, c, tenant, false)
+}
+
+// assign 将路径中的用户或组织移入或移出租户
+// Move the user or organization of the path into or out of the tenant
+func (TenantApi) assign(ctx context.Context, c *app.RequestContext, tenant *models.Tenant, join bool) {
+	ownerType, ownerID, ok := Quota.adminOwner(c)
+	if !ok {
+		return
+	}
+	tenantID := &tenant.ID
+	if !join {
+		current, err := store.Tenant.OfOwner(ownerType, ownerID)
+		if err != nil {
+			resps.DBError(c, err, "assign tenant error")
+			return
+		}
+		if current == nil || current.ID != tenant.ID {
+			resps.BadRequest(c, "the user or organization does not belong to this tenant")
+			return
+		}
+		tenantID = nil
+	}
+	projectIDs, err := store.Tenant.Assign(ownerType, ownerID, tenantID)
+	if err != nil {
+		resps.DBError(c, err, "assign tenant error")
+		return
+	}
+	for _, id := range projectIDs {
+		serve.SiteCache.ForgetProject(ctx, id)
+	}
+	Audit.record(ctx, c, nil, constants.AuditTenantAssign, ownerType, ownerID, map[string]any{"tenant_id": tenantID, "projects": len(projectIDs)})
+	resps.Ok(c, resps.OK)
+}
@@ -0,0 +1,32 @@
+package handlers
+
+// TenantDTO 租户及其品牌和默认配额，配额字段为空时使用配置文件中的默认值
+// Tenant with its branding and default quotas, empty quota fields use the configured defaults
+type TenantDTO struct {
+	ID                uint    `json:"id"`                            // 租户ID Tenant ID
+	Name              string  `json:"name"`                          // 租户名称 Tenant name
+	DisplayName       string  `json:"display_name"`                  // 显示名称 Display name
+	BaseDomain        *string `json:"base_domain"`                   // 站点子域的基础域名 Base domain of the site subdomains
+	LogoURL           *string `json:"logo_url"`                      // 标志 Logo
+	PrimaryColor      string  `json:"primary_color"`                 // 主题色 Theme color
+	MaxProjects       *int64  `json:"max_projects,omitempty"`        // 默认可创建的项目数量 Default number of projects that may be created
+	MaxStorage        *int64  `json:"max_storage,omitempty"`         // 默认可占用的存储字节数 Default storage that may be occupied in bytes
+	MaxDeploymentSize *int64  `json:"max_deployment_size,omitempty"` // 默认单次部署解压后的最大字节数 Default largest uncompressed size of one deployment in bytes
+	MaxUploadSize     *int64  `json:"max_upload_size,omitempty"`     // 默认单次上传的最大字节数 Default largest single upload in bytes
+	Users             *int64  `json:"users,omitempty"`               // 租户中的用户数量，仅管理员可见 Number of users in the tenant, only for administrators
+	Organizations     *int64  `json:"organizations,omitempty"`       // 租户中的组织数量，仅管理员可见 Number of organizations in the tenant, only for administrators
+}
+
+// TenantReq 管理员创建或修改租户的请求参数，配额字段为空时使用配置文件中的默认值，0 为不限制
+// Request parameters for an administrator creating or changing a tenant, empty quota fields use the configured defaults and 0 means unlimited
+type TenantReq struct {
+	Name              string  `json:"name" validate:"required,max=63,slug,reserved"` // 租户名称 Tenant name
+	DisplayName       string  `json:"display_name" validate:"required,max=128"`      // 显示名称 Display name
+	BaseDomain        *string `json:"base_domain" validate:"max=253,domain"`         // 站点子域的基础域名，为空时使用 serve.base-domain Base domain of the site subdomains, serve.base-domain when empty
+	LogoURL           *string `json:"logo_url" validate:"max=2048,url"`              // 标志 Logo
+	PrimaryColor      string  `json:"primary_color"`                                 // 主题色，例如 #3b82f6 Theme color such as #3b82f6
+	MaxProjects       *int64  `json:"max_projects"`                                  // 默认可创建的项目数量 Default number of projects that may be created
+	MaxStorage        *int64  `json:"max_storage"`                                   // 默认可占用的存储字节数 Default storage that may be occupied in bytes
+	MaxDeploymentSize *int64  `json:"max_deployment_size"`                           // 默认单次部署解压后的最大字节数 Default largest uncompressed size of one deployment in bytes
+	MaxUploadSize     *int64  `json:"max_upload_size"`                               // 默认单次上传的最大字节数 Default largest single upload in bytes
+}
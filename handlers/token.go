@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/middle"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+type APITokenApi struct{}
+
+var APIToken = APITokenApi{}
+
+// tokenPrefixLength 展示给用户的令牌开头长度 Length of the token prefix shown to users
+const tokenPrefixLength = 12
+
+func (APITokenApi) toDTO(token *models.APIToken) APITokenDTO {
+	return APITokenDTO{
+		ID:         token.ID,
+		Name:       token.Name,
+		Prefix:     token.Prefix,
+		Scopes:     token.Scopes,
+		ExpiresAt:  token.ExpiresAt,
+		LastUsedAt: token.LastUsedAt,
+		CreatedAt:  token.CreatedAt,
+	}
+}
+
+// List 获取当前用户的个人访问令牌
+// List the personal access tokens of the current user
+func (APITokenApi) List(ctx context.Context, c *app.RequestContext) {
+	user := middle.Auth.GetUser(ctx, c)
+	if user == nil {
+		return
+	}
+	tokens, err := store.APIToken.ListByUser(user.ID)
+	if err != nil {
+		resps.DBError(c, err, "Failed to get tokens")
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"tokens": func() (tokenDTOs []APITokenDTO) {
+			for _, token := range tokens {
+				tokenDTOs = append(tokenDTOs, APIToken.toDTO(&token))
+			}
+			return
+		}(),
+	})
+}
+
+// Create 创建个人访问令牌，明文只在此时返回
+// Create a personal access token, the plain token is only returned here
+func (APITokenApi) Create(ctx context.Context, c *app.RequestContext) {
+	user := middle.Auth.GetUser(ctx, c)
+	if user == nil {
+		return
+	}
+	req := CreateAPITokenReq{}
+	if !bindJSON(c, &req) {
+		return
+	}
+	if req.Name == "" || len(req.Scopes) == 0 {
+		resps.BadRequest(c, "name and scopes are required")
+		return
+	}
+	if req.ExpiresAt != nil && req.ExpiresAt.Before(time.Now()) {
+		resps.BadRequest(c, "expires_at must be in the future")
+		return
+	}
+	plain, hash, err := utils.Token.NewAPIToken()
+	if err != nil {
+		resps.InternalServerError(c, "Failed to create token")
+		return
+	}
+	token := &models.APIToken{
+		UserID:    user.ID,
+		Name:      req.Name,
+		Prefix:    plain[:tokenPrefixLength],
+		Hash:      hash,
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+	}
+	if err := store.APIToken.Create(token); err != nil {
+		resps.DBError(c, err, "Failed to create token")
+		return
+	}
+	Audit.record(ctx, c, user, constants.AuditTokenCreate, constants.AuditTargetToken, token.ID, map[string]any{
+		"name":       token.Name,
+		"prefix":     token.Prefix,
+		"scopes":     token.Scopes,
+		"expires_at": token.ExpiresAt,
+	})
+	resps.Ok(c, resps.OK, map[string]any{
+		"token":     APIToken.toDTO(token),
+		"plaintext": plain,
+	})
+}
+
+// Update 更新个人访问令牌的名称或权限范围
+// Update the name or scopes of a personal access token
+func (APITokenApi) Update(ctx context.Context, c *app.RequestContext) {
+	token := APIToken.getToken(ctx, c)
+	if token == nil {
+		return
+	}
+	req := UpdateAPITokenReq{}
+	if !bindJSON(c, &req) {
+		return
+	}
+	if req.Name != nil {
+		if *req.Name == "" {
+			resps.BadRequest(c, "name must not be empty")
+			return
+		}
+		token.Name = *req.Name
+	}
+	if req.Scopes != nil {
+		if len(req.Scopes) == 0 {
+			resps.BadRequest(c, "scopes must not be empty")
+			return
+		}
+		token.Scopes = req.Scopes
+	}
+	if err := store.APIToken.Update(token); err != nil {
+		resps.DBError(c, err, "Failed to update token")
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"token": APIToken.toDTO(token),
+	})
+}
+
+// Delete 撤销个人访问令牌
+// Revoke a personal access token
+func (APITokenApi) Delete(ctx context.Context, c *app.RequestContext) {
+	token := APIToken.getToken(ctx, c)
+	if token == nil {
+		return
+	}
+	if err := store.APIToken.Delete(token); err != nil {
+		resps.DBError(c, err, "Failed to delete token")
+		return
+	}
+	Audit.record(ctx, c, nil, constants.AuditTokenRevoke, constants.AuditTargetToken, token.ID, map[string]any{"name": token.Name, "prefix": token.Prefix})
+	resps.Ok(c, resps.OK)
+}
+
+func (APITokenApi) getToken(ctx context.Context, c *app.RequestContext) *models.APIToken {
+	user := middle.Auth.GetUser(ctx, c)
+	if user == nil {
+		return nil
+	}
+	id, err := strconv.Atoi(c.Param("token_id"))
+	if err != nil {
+		resps.BadRequest(c, resps.ParameterError)
+		return nil
+	}
+	token, err := store.APIToken.GetByUser(user.ID, uint(id))
+	if err != nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return nil
+	}
+	return token
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/LiteyukiStudio/spage/constants"
+)
+
+// APITokenDTO 个人访问令牌信息，不包含令牌明文
+// Personal access token information, without the plain token
+type APITokenDTO struct {
+	ID         uint                   `json:"id"`           // 令牌ID Token ID
+	Name       string                 `json:"name"`         // 令牌名称 Token name
+	Prefix     string                 `json:"prefix"`       // 令牌开头部分 Leading part of the token
+	Scopes     []constants.TokenScope `json:"scopes"`       // 权限范围 Scopes
+	ExpiresAt  *time.Time             `json:"expires_at"`   // 过期时间 Expiry time
+	LastUsedAt *time.Time             `json:"last_used_at"` // 最后使用时间 Last used time
+	CreatedAt  time.Time              `json:"created_at"`   // 创建时间 Created time
+}
+
+// CreateAPITokenReq 创建个人访问令牌的请求参数
+// Request parameters to create a personal access token
+type CreateAPITokenReq struct {
+	Name      string                 `json:"name"`       // 令牌名称 Token name
+	Scopes    []constants.TokenScope `json:"scopes"`     // 权限范围 Scopes
+	ExpiresAt *time.Time             `json:"expires_at"` // 过期时间，为空则永不过期 Expiry time, never expires when empty
+}
+
+// UpdateAPITokenReq 更新个人访问令牌的请求参数，为空的字段保持不变
+// Request parameters to update a personal access token, nil fields are kept
+type UpdateAPITokenReq struct {
+	Name   *string                `json:"name"`   // 令牌名称 Token name
+	Scopes []constants.TokenScope `json:"scopes"` // 权限范围 Scopes
+}
@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/middle"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/serve"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+type TransferApi struct{}
+
+var Transfer = TransferApi{}
+
+func (TransferApi) toDTO(transfer *models.ProjectTransfer) TransferDTO {
+	return TransferDTO{
+		ID:          transfer.ID,
+		Project:     Project.toDTO(&transfer.Project, false),
+		OwnerType:   transfer.OwnerType,
+		OwnerID:     transfer.OwnerID,
+		RequestedBy: transfer.RequestedBy.Name,
+		ExpiresAt:   transfer.ExpiresAt,
+		CreatedAt:   transfer.CreatedAt,
+	}
+}
+
+// recipient 获取当前用户可以处理的转移：转给用户本人，或转给其担任所有者或维护者的组织，与在组织下创建项目的权限相同
+// Get a transfer the current user may act on: one to the user, or to an organization where the user is an owner or maintainer, the same as creating projects in it
+func (TransferApi) recipient(ctx context.Context, c *app.RequestContext) (*models.ProjectTransfer, bool) {
+	user := middle.Auth.GetUser(ctx, c)
+	id, err := strconv.Atoi(c.Param("transfer_id"))
+	if err != nil {
+		resps.BadRequest(c, resps.ParameterError)
+		return nil, false
+	}
+	transfer, err := store.Transfer.GetByID(uint(id))
+	if err != nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return nil, false
+	}
+	allowed := false
+	switch transfer.OwnerType {
+	case constants.OwnerTypeUser:
+		allowed = transfer.OwnerID == user.ID
+	case constants.OwnerTypeOrg:
+		if org, err := store.Org.GetOrgById(transfer.OwnerID); err == nil {
+			allowed = store.Org.GetUserAuth(org, user.ID).AtLeast(constants.OrgRoleMaintainer)
+		}
+	}
+	// 与接收方无关的用户看不到转移 Users unrelated to the recipient cannot see the transfer
+	if !allowed {
+		resps.NotFound(c, resps.TargetNotFound)
+		return nil, false
+	}
+	return transfer, true
+}
+
+// Get 获取项目等待确认的转移
+// Get the project's transfer awaiting confirmation
+func (TransferApi) Get(ctx context.Context, c *app.RequestContext) {
+	project := getProject(c)
+	if project == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	transfer, err := store.Transfer.GetByProject(project.ID)
+	if err != nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"transfer": Transfer.toDTO(transfer),
+	})
+}
+
+// Request 发起项目转移，接收方在 transfer-ttl 内确认后项目连同站点、域名和设置一起转移，重复发起会替换之前的转移
+// Request a project transfer, the project moves with its sites, domains and settings once the recipient confirms within transfer-ttl, requesting again replaces the previous transfer
+func (TransferApi) Request(ctx context.Context, c *app.RequestContext) {
+	project := getProject(c)
+	if project == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	req := TransferProjectReq{}
+	if !bind(c, &req) {
+		return
+	}
+	if req.OwnerType == project.OwnerType && req.OwnerID == project.OwnerID {
+		resps.BadRequest(c, "The project already belongs to this owner")
+		return
+	}
+	tenant, err := store.Tenant.OfOwner(req.OwnerType, req.OwnerID)
+	if err != nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	// 项目不能转移到其他租户 Projects cannot move to another tenant
+	var tenantID *uint
+	if tenant != nil {
+		tenantID = &tenant.ID
+	}
+	if !sameTenant(tenantID, project.TenantID) {
+		resps.BadRequest(c, "the new owner belongs to another tenant")
+		return
+	}
+	transfer := &models.ProjectTransfer{
+		ProjectID:     project.ID,
+		OwnerType:     req.OwnerType,
+		OwnerID:       req.OwnerID,
+		RequestedByID: middle.Auth.GetUser(ctx, c).ID,
+		ExpiresAt:     time.Now().Add(time.Duration(config.ProjectTransferTTL) * time.Second),
+	}
+	if err := store.Transfer.Request(transfer); err != nil {
+		resps.DBError(c, err, "Failed to request transfer")
+		return
+	}
+	if transfer, err = store.Transfer.GetByProject(project.ID); err != nil {
+		resps.InternalServerError(c, "Failed to request transfer")
+		return
+	}
+	Audit.record(ctx, c, nil, constants.AuditTransferRequest, constants.AuditTargetProject, project.ID, map[string]any{
+		"owner_type": req.OwnerType,
+		"owner_id":   req.OwnerID,
+	})
+	resps.Ok(c, resps.OK, map[string]any{
+		"transfer": Transfer.toDTO(transfer),
+	})
+}
+
+// Cancel 取消项目等待确认的转移
+// Cancel the project's transfer awaiting confirmation
+func (TransferApi) Cancel(ctx context.Context, c *app.RequestContext) {
+	project := getProject(c)
+	if project == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	transfer, err := store.Transfer.GetByProject(project.ID)
+	if err != nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	if err := store.Transfer.Cancel(transfer); err != nil {
+		resps.DBError(c, err, "Failed to cancel transfer")
+		return
+	}
+	Audit.record(ctx, c, nil, constants.AuditTransferCancel, constants.AuditTargetProject, project.ID, map[string]any{
+		"owner_type": transfer.OwnerType,
+		"owner_id":   transfer.OwnerID,
+	})
+	resps.Ok(c, resps.OK)
+}
+
+// Incoming 获取当前用户可以接受的项目转移
+// List the project transfers the current user may accept
+func (TransferApi) Incoming(ctx context.Context, c *app.RequestContext) {
+	user := middle.Auth.GetUser(ctx, c)
+	transfers, err := store.Transfer.ListIncoming(user.ID)
+	if err != nil {
+		resps.DBError(c, err, "Failed to list transfers")
+		return
+	}
+	transferDTOs := make([]TransferDTO, 0, len(transfers))
+	for i := range transfers {
+		transferDTOs = append(transferDTOs, Transfer.toDTO(&transfers[i]))
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"transfers": transferDTOs,
+	})
+}
+
+// Accept 接受项目转移，接收方的项目配额必须有余量；原组织的团队授权会被移除，直接授予的项目成员保留
+// Accept a project transfer, the recipient must have room in its project quota; team grants of the former organization are removed while directly granted project members stay
+func (TransferApi) Accept(ctx context.Context, c *app.RequestContext) {
+	transfer, ok := Transfer.recipient(ctx, c)
+	if !ok {
+		return
+	}
+	if err := Quota.checkProjects(transfer.OwnerType, transfer.OwnerID); errors.Is(err, errQuotaExceeded) {
+		resps.Forbidden(c, err.Error())
+		return
+	} else if err != nil {
+		resps.InternalServerError(c, err.Error())
+		return
+	}
+	if err := store.Transfer.Accept(transfer); err != nil {
+		if errors.Is(err, store.ErrTransferInvalid) {
+			resps.NotFound(c, resps.TargetNotFound)
+			return
+		}
+		resps.InternalServerError(c, "Failed to accept transfer")
+		return
+	}
+	project := &transfer.Project
+	from := map[string]any{"owner_type": project.OwnerType, "owner_id": project.OwnerID}
+	project.OwnerType, project.OwnerID = transfer.OwnerType, transfer.OwnerID
+	serve.SiteCache.ForgetProject(ctx, project.ID)
+	Audit.record(ctx, c, nil, constants.AuditTransferAccept, constants.AuditTargetProject, project.ID, map[string]any{
+		"from":         from,
+		"owner_type":   transfer.OwnerType,
+		"owner_id":     transfer.OwnerID,
+		"requested_by": transfer.RequestedByID,
+	})
+	resps.Ok(c, resps.OK, map[string]any{
+		"project": Project.toDTO(project, true),
+	})
+}
+
+// Decline 拒绝项目转移
+// Decline a project transfer
+func (TransferApi) Decline(ctx context.Context, c *app.RequestContext) {
+	transfer, ok := Transfer.recipient(ctx, c)
+	if !ok {
+		return
+	}
+	if err := store.Transfer.Cancel(transfer); err != nil {
+		resps.DBError(c, err, "Failed to decline transfer")
+		return
+	}
+	Audit.record(ctx, c, nil, constants.AuditTransferCancel, constants.AuditTargetProject, transfer.ProjectID, map[string]any{
+		"owner_type": transfer.OwnerType,
+		"owner_id":   transfer.OwnerID,
+		"declined":   true,
+	})
+	resps.Ok(c, resps.OK)
+}
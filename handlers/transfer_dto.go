@@ -0,0 +1,22 @@
+package handlers
+
+import "time"
+
+// TransferDTO 等待确认的项目转移
+// Project transfer awaiting confirmation
+type TransferDTO struct {
+	ID          uint       `json:"id"`           // 转移ID Transfer ID
+	Project     ProjectDTO `json:"project"`      // 转移的项目 Project being transferred
+	OwnerType   string     `json:"owner_type"`   // 接收方类型 Recipient type
+	OwnerID     uint       `json:"owner_id"`     // 接收方ID Recipient ID
+	RequestedBy string     `json:"requested_by"` // 发起者用户名 Requester username
+	ExpiresAt   time.Time  `json:"expires_at"`   // 过期时间 Expiry time
+	CreatedAt   time.Time  `json:"created_at"`   // 发起时间 Request time
+}
+
+// TransferProjectReq 发起项目转移的请求参数
+// Request parameters to transfer a project
+type TransferProjectReq struct {
+	OwnerType string `json:"owner_type" binding:"required" vd:"in($,'user','organization')"` // 接收方类型 Recipient type
+	OwnerID   uint   `json:"owner_id" binding:"required"`                                    // 接收方ID Recipient ID
+}
@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/middle"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/serve"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+	"gorm.io/gorm"
+)
+
+type TrashApi struct{}
+
+// Trash 回收站，删除的项目和站点在保留期内可以恢复，也可以提前彻底删除
+// The trash, deleted projects and sites can be restored within the retention window or purged early
+var Trash = TrashApi{}
+
+// purgeAt 回收站中的条目将被彻底删除的时间，一直保留时为空
+// Time an item in the trash will be purged, nil when kept indefinitely
+func (TrashApi) purgeAt(deletedAt gorm.DeletedAt) *time.Time {
+	if config.TrashRetention <= 0 {
+		return nil
+	}
+	purgeAt := deletedAt.Time.Add(time.Duration(config.TrashRetention) * time.Second)
+	return &purgeAt
+}
+
+func (TrashApi) projectsToDTO(projects []models.Project) []TrashProjectDTO {
+	dtos := make([]TrashProjectDTO, 0, len(projects))
+	for _, project := range projects {
+		dtos = append(dtos, TrashProjectDTO{
+			ProjectDTO: Project.toDTO(&project, false),
+			DeletedAt:  project.DeletedAt.Time,
+			PurgeAt:    Trash.purgeAt(project.DeletedAt),
+		})
+	}
+	return dtos
+}
+
+// listProjects 分页返回所有者回收站中的项目
+// Respond with the projects in the trash of an owner, paginated
+func (TrashApi) listProjects(ctx context.Context, c *app.RequestContext, ownerType string, ownerID uint) {
+	page, limit := utils.Ctx.GetPageLimit(c)
+	projects, total, err := store.Trash.ListProjects(ctx, ownerType, ownerID, page, limit)
+	if err != nil {
+		resps.DBError(c, err, "get trash error")
+		return
+	}
+	utils.Ctx.SetPageHeaders(c, page, limit, total)
+	resps.Ok(c, resps.OK, map[string]any{
+		"projects": Trash.projectsToDTO(projects),
+		"total":    total,
+	})
+}
+
+// User 获取当前用户回收站中的项目
+// List the projects in the trash of the current user
+func (TrashApi) User(ctx context.Context, c *app.RequestContext) {
+	Trash.listProjects(ctx, c, constants.OwnerTypeUser, middle.Auth.GetUser(ctx, c).ID)
+}
+
+// Org 获取组织回收站中的项目
+// List the projects in the trash of an organization
+func (TrashApi) Org(ctx context.Context, c *app.RequestContext) {
+	Trash.listProjects(ctx, c, constants.OwnerTypeOrg, getOrg(c).ID)
+}
+
+// deletedProject 获取路径中回收站里的项目，与删除项目一样需要所有者角色，失败时已写入响应
+// Get the project in the trash of the path, requiring the owner role like deleting it; the response is already written on failure
+func (TrashApi) deletedProject(ctx context.Context, c *app.RequestContext) (*models.Project, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		resps.BadRequest(c, resps.ParameterError)
+		return nil, false
+	}
+	project, err := store.Trash.GetProject(uint(id))
+	if err != nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return nil, false
+	}
+	role := store.Project.UserRole(project, middle.Auth.GetUser(ctx, c).ID)
+	if !role.Valid() {
+		resps.NotFound(c, resps.TargetNotFound)
+		return nil, false
+	}
+	if !role.AtLeast(constants.OrgRoleOwner) {
+		resps.Forbidden(c, resps.PermissionDenied)
+		return nil, false
+	}
+	return project, true
+}
+
+// RestoreProject 从回收站恢复项目及随其删除的站点，恢复后的项目同样计入配额
+// Restore a project and the sites deleted along with it from the trash, the restored project counts against the quota again
+func (TrashApi) RestoreProject(ctx context.Context, c *app.RequestContext) {
+	project, ok := Trash.deletedProject(ctx, c)
+	if !ok {
+		return
+	}
+	if err := Quota.checkProjects(project.OwnerType, project.OwnerID); errors.Is(err, errQuotaExceeded) {
+		resps.Forbidden(c, err.Error())
+		return
+	} else if err != nil {
+		resps.InternalServerError(c, err.Error())
+		return
+	}
+	if err := store.Trash.RestoreProject(project); err != nil {
+		resps.DBError(c, err, "restore project error")
+		return
+	}
+	project.DeletedAt = gorm.DeletedAt{}
+	serve.SiteCache.ForgetProject(ctx, project.ID)
+	Audit.record(ctx, c, nil, constants.AuditProjectRestore, constants.AuditTargetProject, project.ID, map[string]any{"name": project.Name})
+	resps.Ok(c, resps.OK, map[string]any{
+		"project": Project.toDTO(project, true),
+	})
+}
+
+// PurgeProject 彻底删除回收站中的项目，其站点、版本和文件无法再恢复
+// Purge a project from the trash, its sites, versions and files can no longer be restored
+func (TrashApi) PurgeProject(ctx context.Context, c *app.RequestContext) {
+	project, ok := Trash.deletedProject(ctx, c)
+	if !ok {
+		return
+	}
+	if err := store.Trash.PurgeProject(ctx, project); err != nil {
+		utils.Log.Ctx(ctx).Errorf("failed to purge project %d: %v", project.ID, err)
+		resps.InternalServerError(c, "purge project error")
+		return
+	}
+	Audit.record(ctx, c, nil, constants.AuditProjectPurge, constants.AuditTargetProject, project.ID, map[string]any{"name": project.Name})
+	resps.Ok(c, resps.OK)
+}
+
+// Sites 获取项目回收站中单独删除的站点，随项目删除的站点随项目一起恢复
+// List the sites deleted on their own in the trash of a project, sites deleted along with the project are restored with it
+func (TrashApi) Sites(ctx context.Context, c *app.RequestContext) {
+	project := getProject(c)
+	if project == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	page, limit := utils.Ctx.GetPageLimit(c)
+	sites, total, err := store.Trash.ListSites(ctx, project.ID, page, limit)
+	if err != nil {
+		resps.DBError(c, err, "get trash error")
+		return
+	}
+	utils.Ctx.SetPageHeaders(c, page, limit, total)
+	dtos := make([]TrashSiteDTO, 0, len(sites))
+	for _, site := range sites {
+		dtos = append(dtos, TrashSiteDTO{
+			SiteDTO:   Site.ToDTO(&site, false),
+			DeletedAt: site.DeletedAt.Time,
+			PurgeAt:   Trash.purgeAt(site.DeletedAt),
+		})
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"sites": dtos,
+		"total": total,
+	})
+}
+
+// deletedSite 获取路径中项目回收站里的站点，失败时已写入响应
+// Get the site in the trash of the project of the path; the response is already written on failure
+func (TrashApi) deletedSite(c *app.RequestContext) (*models.Site, bool) {
+	project := getProject(c)
+	if project == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return nil, false
+	}
+	id, err := strconv.Atoi(c.Param("site_id"))
+	if err != nil {
+		resps.BadRequest(c, resps.ParameterError)
+		return nil, false
+	}
+	site, err := store.Trash.GetSite(project.ID, uint(id))
+	if err != nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return nil, false
+	}
+	return site, true
+}
+
+// RestoreSite 从回收站恢复站点，恢复后立即按原有的激活版本提供服务
+// Restore a site from the trash, it is served from its previously active version right away
+func (TrashApi) RestoreSite(ctx context.Context, c *app.RequestContext) {
+	site, ok := Trash.deletedSite(c)
+	if !ok {
+		return
+	}
+	if err := store.Trash.RestoreSite(site); err != nil {
+		resps.DBError(c, err, "restore site error")
+		return
+	}
+	site.DeletedAt = gorm.DeletedAt{}
+	serve.SiteCache.ForgetSite(ctx, site)
+	Audit.record(ctx, c, nil, constants.AuditSiteRestore, constants.AuditTargetSite, site.ID, map[string]any{"name": site.Name, "project_id": site.ProjectID})
+	resps.Ok(c, resps.OK, map[string]any{
+		"site": Site.ToDTO(site, true),
+	})
+}
+
+// PurgeSite 彻底删除回收站中的站点，其版本和文件无法再恢复
+// Purge a site from the trash, its versions and files can no longer be restored
+func (TrashApi) PurgeSite(ctx context.Context, c *app.RequestContext) {
+	site, ok := Trash.deletedSite(c)
+	if !ok {
+		return
+	}
+	if err := store.Trash.PurgeSite(ctx, site); err != nil {
+		utils.Log.Ctx(ctx).Errorf("failed to purge site %d: %v", site.ID, err)
+		resps.InternalServerError(c, "purge site error")
+		return
+	}
+	Audit.record(ctx, c, nil, constants.AuditSitePurge, constants.AuditTargetSite, site.ID, map[string]any{"name": site.Name, "project_id": site.ProjectID})
+	resps.Ok(c, resps.OK)
+}
@@ -0,0 +1,19 @@
+package handlers
+
+import "time"
+
+// TrashProjectDTO 回收站中的项目
+// Project in the trash
+type TrashProjectDTO struct {
+	ProjectDTO
+	DeletedAt time.Time  `json:"deleted_at"`         // 删除时间 Deletion time
+	PurgeAt   *time.Time `json:"purge_at,omitempty"` // 将被彻底删除的时间，一直保留时为空 Time it will be purged, empty when kept indefinitely
+}
+
+// TrashSiteDTO 回收站中的站点
+// Site in the trash
+type TrashSiteDTO struct {
+	SiteDTO
+	DeletedAt time.Time  `json:"deleted_at"`         // 删除时间 Deletion time
+	PurgeAt   *time.Time `json:"purge_at,omitempty"` // 将被彻底删除的时间，一直保留时为空 Time it will be purged, empty when kept indefinitely
+}
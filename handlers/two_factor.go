@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/middle"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+	"gorm.io/gorm"
+)
+
+type TwoFactorApi struct{}
+
+var TwoFactor = TwoFactorApi{}
+
+// twoFactorChallengeTTL 密码验证通过后完成两步验证的时限 Time allowed to complete the second factor after the password is verified
+const twoFactorChallengeTTL = 5 * time.Minute
+
+// challenge 用户启用两步验证时签发临时凭证，未启用时返回空字符串
+// Issue a challenge when the user enabled two-factor authentication, an empty string otherwise
+func (TwoFactorApi) challenge(user *models.User) (string, error) {
+	enabled, err := store.TwoFactor.IsEnabled(user.ID)
+	if err != nil || !enabled {
+		return "", err
+	}
+	return utils.TOTP.SignChallenge(user.ID, twoFactorChallengeTTL)
+}
+
+// verify 校验 TOTP 验证码或恢复码，验证码和恢复码都只能使用一次
+// Verify a TOTP code or a recovery code, both can only be used once
+func (TwoFactorApi) verify(userID uint, code string) (bool, error) {
+	totp, err := store.TwoFactor.GetTOTP(userID)
+	if err != nil {
+		return false, err
+	}
+	if step, ok := utils.TOTP.Verify(totp.Secret, code, time.Now()); ok {
+		return store.TwoFactor.UseTOTPStep(totp, step)
+	}
+	return store.TwoFactor.UseRecoveryCode(userID, utils.TOTP.HashRecoveryCode(code))
+}
+
+// Login 使用密码登录返回的临时凭证和第二因素完成登录
+// Complete the login with the challenge returned by the password login and the second factor
+func (TwoFactorApi) Login(ctx context.Context, c *app.RequestContext) {
+	req := TwoFactorLoginReq{}
+	if !bindJSON(c, &req) {
+		return
+	}
+	challenge, err := utils.TOTP.ParseChallenge(req.Challenge)
+	if err != nil {
+		resps.Unauthorized(c, "Invalid or expired challenge")
+		return
+	}
+	user, err := store.User.GetByID(challenge.UserID)
+	if err != nil {
+		resps.Unauthorized(c, "Invalid or expired challenge")
+		return
+	}
+	if User.loginBlocked(c, user) {
+		return
+	}
+	ok, err := TwoFactor.verify(user.ID, req.Code)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		resps.InternalServerError(c, "Failed to verify code")
+		return
+	}
+	if !ok {
+		User.loginFailed(ctx, c, user)
+		resps.Forbidden(c, "Incorrect code")
+		return
+	}
+	if User.accountDisabled(c, user) {
+		return
+	}
+	token, refreshToken, err := User.startSession(ctx, c, user, "two_factor")
+	if err != nil {
+		resps.InternalServerError(c, err.Error())
+		return
+	}
+	resps.Ok(c, "Login successful", map[string]any{
+		"token":         token,
+		"refresh_token": refreshToken,
+	})
+}
+
+// Status 获取当前用户的两步验证状态
+// Get the two-factor status of the current user
+func (TwoFactorApi) Status(ctx context.Context, c *app.RequestContext) {
+	user := middle.Auth.GetUser(ctx, c)
+	if user == nil {
+		return
+	}
+	enabled, err := store.TwoFactor.IsEnabled(user.ID)
+	if err != nil {
+		resps.DBError(c, err, "Failed to get two-factor status")
+		return
+	}
+	remaining, err := store.TwoFactor.CountRecoveryCodes(user.ID)
+	if err != nil {
+		resps.DBError(c, err, "Failed to get two-factor status")
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"enabled":                  enabled,
+		"recovery_codes_remaining": remaining,
+	})
+}
+
+// Enroll 开始绑定 TOTP，生成新的密钥和 otpauth 地址，确认验证码后才会启用
+// Start TOTP enrollment, generating a new secret and otpauth URI, it is enabled only after a code is confirmed
+func (TwoFactorApi) Enroll(ctx context.Context, c *app.RequestContext) {
+	user := middle.Auth.GetUser(ctx, c)
+	if user == nil {
+		return
+	}
+	totp, err := store.TwoFactor.GetTOTP(user.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		resps.InternalServerError(c, "Failed to get two-factor status")
+		return
+	}
+	if totp == nil {
+		totp = &models.UserTOTP{UserID: user.ID}
+	} else if totp.Enabled {
+		resps.BadRequest(c, "Two-factor authentication is already enabled")
+		return
+	}
+	secret, err := utils.TOTP.NewSecret()
+	if err != nil {
+		resps.InternalServerError(c, "Failed to generate secret")
+		return
+	}
+	totp.Secret = secret
+	totp.LastUsedStep = 0
+	if err := store.TwoFactor.SaveTOTP(totp); err != nil {
+		resps.DBError(c, err, "Failed to save secret")
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"secret":           secret,
+		"provisioning_uri": utils.TOTP.ProvisioningURI(secret, user.Name),
+	})
+}
+
+// Confirm 确认验证码并启用 TOTP，返回只显示一次的恢复码
+// Confirm a code and enable TOTP, returning recovery codes that are shown only once
+func (TwoFactorApi) Confirm(ctx context.Context, c *app.RequestContext) {
+	user := middle.Auth.GetUser(ctx, c)
+	if user == nil {
+		return
+	}
+	req := TwoFactorCodeReq{}
+	if !bindJSON(c, &req) {
+		return
+	}
+	totp, err := store.TwoFactor.GetTOTP(user.ID)
+	if err != nil {
+		resps.BadRequest(c, "Start the enrollment first")
+		return
+	}
+	if totp.Enabled {
+		resps.BadRequest(c, "Two-factor authentication is already enabled")
+		return
+	}
+	step, ok := utils.TOTP.Verify(totp.Secret, req.Code, time.Now())
+	if !ok {
+		resps.Forbidden(c, "Incorrect code")
+		return
+	}
+	codes, hashes, err := utils.TOTP.NewRecoveryCodes()
+	if err != nil {
+		resps.InternalServerError(c, "Failed to generate recovery codes")
+		return
+	}
+	if err := store.TwoFactor.EnableTOTP(totp, step, hashes); err != nil {
+		resps.DBError(c, err, "Failed to enable two-factor authentication")
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"recovery_codes": codes,
+	})
+}
+
+// Disable 使用验证码或恢复码关闭两步验证
+// Disable two-factor authentication with a code or a recovery code
+func (TwoFactorApi) Disable(ctx context.Context, c *app.RequestContext) {
+	user := TwoFactor.confirmedUser(ctx, c)
+	if user == nil {
+		return
+	}
+	if err := store.TwoFactor.DisableTOTP(user.ID); err != nil {
+		resps.DBError(c, err, "Failed to disable two-factor authentication")
+		return
+	}
+	resps.Ok(c, resps.OK)
+}
+
+// RegenerateRecoveryCodes 使用验证码重新生成恢复码
+// Regenerate recovery codes, confirmed by a code
+func (TwoFactorApi) RegenerateRecoveryCodes(ctx context.Context, c *app.RequestContext) {
+	user := TwoFactor.confirmedUser(ctx, c)
+	if user == nil {
+		return
+	}
+	codes, hashes, err := utils.TOTP.NewRecoveryCodes()
+	if err != nil {
+		resps.InternalServerError(c, "Failed to generate recovery codes")
+		return
+	}
+	if err := store.TwoFactor.ReplaceRecoveryCodes(user.ID, hashes); err != nil {
+		resps.DBError(c, err, "Failed to save recovery codes")
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"recovery_codes": codes,
+	})
+}
+
+// confirmedUser 获取当前用户，并要求已启用两步验证且请求带有正确的验证码
+// Get the current user, requiring two-factor authentication to be enabled and a correct code in the request
+func (TwoFactorApi) confirmedUser(ctx context.Context, c *app.RequestContext) *models.User {
+	user := middle.Auth.GetUser(ctx, c)
+	if user == nil {
+		return nil
+	}
+	req := TwoFactorCodeReq{}
+	if !bindJSON(c, &req) {
+		return nil
+	}
+	enabled, err := store.TwoFactor.IsEnabled(user.ID)
+	if err != nil {
+		resps.DBError(c, err, "Failed to get two-factor status")
+		return nil
+	}
+	if !enabled {
+		resps.BadRequest(c, "Two-factor authentication is not enabled")
+		return nil
+	}
+	ok, err := TwoFactor.verify(user.ID, req.Code)
+	if err != nil {
+		resps.InternalServerError(c, "Failed to verify code")
+		return nil
+	}
+	if !ok {
+		resps.Forbidden(c, "Incorrect code")
+		return nil
+	}
+	return user
+}
@@ -0,0 +1,322 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/i18n"
+	"github.com/LiteyukiStudio/spage/middle"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/storage"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/task"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+type UploadApi struct{}
+
+// Upload 分片上传，大型站点的压缩包分片上传，中断后只需重传缺失的分片，全部上传后在服务端拼接、校验并发布
+// Chunked uploads, large site archives are uploaded in chunks so an interrupted upload only resends the missing ones, and are assembled, verified and published on the server
+var Upload = UploadApi{}
+
+func (UploadApi) toDTO(upload *models.Upload, chunks []models.UploadChunk) UploadDTO {
+	received := make([]UploadChunkDTO, 0, len(chunks))
+	for _, chunk := range chunks {
+		received = append(received, UploadChunkDTO{Index: chunk.Index, Size: chunk.Size, SHA256: chunk.SHA256})
+	}
+	return UploadDTO{
+		ID:        upload.ID,
+		Tag:       upload.Tag,
+		Preview:   upload.Preview,
+		Size:      upload.Size,
+		SHA256:    upload.SHA256,
+		ChunkSize: upload.ChunkSize,
+		Chunks:    upload.Chunks(),
+		Received:  received,
+		ExpiresAt: upload.ExpiresAt,
+		CreatedAt: upload.CreatedAt,
+	}
+}
+
+// getUpload 获取路径中的上传会话，过期的会话视为不存在，失败时已写入响应
+// Get the upload session of the path, expired sessions count as missing; the response is already written on failure
+func (UploadApi) getUpload(c *app.RequestContext) (*models.Site, *models.Upload, bool) {
+	site := getSite(c)
+	if site == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return nil, nil, false
+	}
+	upload, err := store.Upload.Get(site.ID, c.Param("upload_id"))
+	if err != nil || time.Now().After(upload.ExpiresAt) {
+		resps.NotFound(c, resps.TargetNotFound)
+		return nil, nil, false
+	}
+	return site, upload, true
+}
+
+// Create 创建分片上传会话，返回分片大小和数量
+// Create a chunked upload session, returning the chunk size and count
+func (UploadApi) Create(ctx context.Context, c *app.RequestContext) {
+	site := getSite(c)
+	if site == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	req := CreateUploadReq{}
+	if !bind(c, &req) {
+		return
+	}
+	req.SHA256 = strings.ToLower(req.SHA256)
+	if !utils.ValidSHA256(req.SHA256) {
+		resps.BadRequest(c, "sha256 must be a hex SHA-256 digest")
+		return
+	}
+	if req.Size <= 0 {
+		resps.BadRequest(c, "size must be at least 1 byte")
+		return
+	}
+	if limit := Quota.UploadLimit(ctx, c); limit > 0 && req.Size > limit {
+		resps.Custom(c, http.StatusRequestEntityTooLarge, i18n.Sprintf(i18n.Language(c), "the upload has %d bytes, at most %d are allowed", req.Size, limit), map[string]any{
+			"limit": limit,
+			"size":  req.Size,
+		})
+		return
+	}
+	previewName, ok := Release.checkDeploy(c, site, req.Tag, req.Preview, req.TTL)
+	if !ok {
+		return
+	}
+	schedule, ok := Release.checkSchedule(c, previewName, req.PublishAt, req.ExpiresAt, req.ExpireAction)
+	if !ok {
+		return
+	}
+	checksums, err := utils.NormalizeChecksums(req.Checksums)
+	if err != nil {
+		resps.BadRequest(c, err.Error())
+		return
+	}
+	// 存储空间已用完时不必等到上传完成再拒绝 Reject right away when the storage is used up instead of after the upload
+	if err := Quota.checkStorage(ctx, &site.Project); errors.Is(err, errQuotaExceeded) {
+		resps.Custom(c, 413, err.Error())
+		return
+	} else if err != nil {
+		resps.InternalServerError(c, err.Error())
+		return
+	}
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		resps.InternalServerError(c, "create upload error")
+		return
+	}
+	upload := models.Upload{
+		ID:        hex.EncodeToString(buf),
+		SiteID:    site.ID,
+		UserID:    middle.Auth.GetUser(ctx, c).ID,
+		Tag:       req.Tag,
+		Preview:   previewName,
+		TTL:       req.TTL,
+		Size:      req.Size,
+		SHA256:    req.SHA256,
+		ChunkSize: int64(config.UploadChunkSize) << 20,
+		ExpiresAt: time.Now().Add(time.Duration(config.UploadTTL) * time.Second),
+
+		PublishAt:        schedule.PublishAt,
+		ReleaseExpiresAt: schedule.ExpiresAt,
+		ExpireAction:     schedule.ExpireAction,
+
+		Checksums: checksums,
+		Changelog: req.Changelog,
+	}
+	if err := store.Upload.Create(&upload); err != nil {
+		resps.DBError(c, err, "create upload error")
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"upload": Upload.toDTO(&upload, nil),
+	})
+}
+
+// Get 获取上传会话及已上传的分片，用于续传
+// Get an upload session with its uploaded chunks, used to resume
+func (UploadApi) Get(ctx context.Context, c *app.RequestContext) {
+	_, upload, ok := Upload.getUpload(c)
+	if !ok {
+		return
+	}
+	chunks, err := store.Upload.ListChunks(upload.ID)
+	if err != nil {
+		resps.DBError(c, err, "get upload chunks error")
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"upload": Upload.toDTO(upload, chunks),
+	})
+}
+
+// PutChunk 上传一个分片，请求体为分片原始内容，可通过 X-Chunk-Sha256 请求头校验；重传会覆盖同一分片
+// Upload one chunk as the raw request body, optionally verified by the X-Chunk-Sha256 header; re-uploading overwrites the chunk
+func (UploadApi) PutChunk(ctx context.Context, c *app.RequestContext) {
+	_, upload, ok := Upload.getUpload(c)
+	if !ok {
+		return
+	}
+	index, err := strconv.Atoi(c.Param("index"))
+	if err != nil || index < 0 || index >= upload.Chunks() {
+		resps.BadRequestf(c, "index must be between 0 and %d", upload.Chunks()-1)
+		return
+	}
+	// 分片长度已知，超出时不再继续读取 The chunk length is known, reading stops beyond it
+	want := upload.ChunkLength(index)
+	middle.BodyLimit.Set(c, want)
+	body := c.Request.Body()
+	if int64(len(body)) != want {
+		resps.BadRequestf(c, "chunk %d must be %d bytes, got %d", index, want, len(body))
+		return
+	}
+	sum := sha256.Sum256(body)
+	digest := hex.EncodeToString(sum[:])
+	if expected := string(c.GetHeader("X-Chunk-Sha256")); expected != "" && !strings.EqualFold(expected, digest) {
+		resps.BadRequestf(c, "chunk %d checksum mismatch", index)
+		return
+	}
+	key := store.Upload.ChunkKey(upload.ID, index)
+	if err := storage.Default.Put(ctx, key, bytes.NewReader(body), int64(len(body))); err != nil {
+		utils.Log.Ctx(ctx).Errorf("failed to store upload chunk %s: %v", key, err)
+		resps.InternalServerError(c, "save chunk error")
+		return
+	}
+	chunk := models.UploadChunk{UploadID: upload.ID, Index: index, Size: int64(len(body)), SHA256: digest}
+	if err := store.Upload.SaveChunk(&chunk); err != nil {
+		resps.DBError(c, err, "save chunk error")
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"chunk": UploadChunkDTO{Index: chunk.Index, Size: chunk.Size, SHA256: chunk.SHA256},
+	})
+}
+
+// Complete 拼接全部分片并校验大小和 SHA-256，通过后按普通上传发布，async 时在后台任务中进行；校验失败时保留会话以便重传分片
+// Assemble all chunks and verify the size and SHA-256, then publish like a plain upload, in a background job with async; the session is kept on mismatch so chunks can be re-uploaded
+func (UploadApi) Complete(ctx context.Context, c *app.RequestContext) {
+	req := CompleteUploadReq{}
+	if !bind(c, &req) {
+		return
+	}
+	site, upload, ok := Upload.getUpload(c)
+	if !ok {
+		return
+	}
+	chunks, err := store.Upload.ListChunks(upload.ID)
+	if err != nil {
+		resps.DBError(c, err, "get upload chunks error")
+		return
+	}
+	if len(chunks) != upload.Chunks() {
+		received := make(map[int]bool, len(chunks))
+		for _, chunk := range chunks {
+			received[chunk.Index] = true
+		}
+		var missing []int
+		for index := 0; index < upload.Chunks(); index++ {
+			if !received[index] {
+				missing = append(missing, index)
+			}
+		}
+		resps.BadRequestf(c, "missing chunks: %v", missing)
+		return
+	}
+	if req.Async {
+		// 会话保留到任务成功后删除 The session is kept until the job succeeds
+		Release.enqueueDeploy(ctx, c, site, deployJobPayload{Tag: upload.Tag, Preview: upload.Preview, TTL: upload.TTL, UploadID: upload.ID, Checksums: upload.Checksums, Changelog: upload.Changelog, releaseSchedule: Upload.schedule(upload)})
+		return
+	}
+	path, err := Upload.assemble(ctx, upload)
+	if path != "" {
+		defer os.Remove(path)
+	}
+	if err != nil {
+		resps.BadRequest(c, err.Error())
+		return
+	}
+	archive, err := fileArchive(path)
+	if err != nil {
+		resps.InternalServerError(c, "assemble upload error")
+		return
+	}
+	archive.checksums = upload.Checksums
+	archive.changelog = upload.Changelog
+	if !Release.deploy(ctx, c, site, archive, upload.Tag, upload.Preview, upload.TTL, Upload.schedule(upload)) {
+		return
+	}
+	if err := store.Upload.Delete(ctx, upload); err != nil {
+		utils.Log.Ctx(ctx).Warnf("failed to delete upload %s: %v", upload.ID, err)
+	}
+}
+
+// schedule 创建会话时指定的定时上线和到期设置
+// Scheduled go-live and expiry settings given when the session was created
+func (UploadApi) schedule(upload *models.Upload) releaseSchedule {
+	return releaseSchedule{PublishAt: upload.PublishAt, ExpiresAt: upload.ReleaseExpiresAt, ExpireAction: upload.ExpireAction}
+}
+
+// assemble 按序拼接分片到临时文件并校验大小和 SHA-256，返回临时文件路径
+// Concatenate the chunks in order into a temporary file and verify the size and SHA-256, returning the file path
+func (UploadApi) assemble(ctx context.Context, upload *models.Upload) (string, error) {
+	tmp, err := os.CreateTemp("", "spage-upload-*.zip")
+	if err != nil {
+		return "", errors.New("assemble upload error")
+	}
+	defer tmp.Close()
+	hash := sha256.New()
+	writer := io.MultiWriter(tmp, hash)
+	var size int64
+	for index := 0; index < upload.Chunks(); index++ {
+		task.ReportProgress(ctx, constants.JobStageAssemble, int64(index), int64(upload.Chunks()))
+		object, err := storage.Default.Get(ctx, store.Upload.ChunkKey(upload.ID, index))
+		if err != nil {
+			return tmp.Name(), fmt.Errorf("chunk %d is missing, upload it again", index)
+		}
+		n, err := io.Copy(writer, object)
+		_ = object.Close()
+		if err != nil {
+			return tmp.Name(), errors.New("assemble upload error")
+		}
+		size += n
+	}
+	if size != upload.Size {
+		return tmp.Name(), fmt.Errorf("assembled size %d does not match %d", size, upload.Size)
+	}
+	if digest := hex.EncodeToString(hash.Sum(nil)); digest != upload.SHA256 {
+		return tmp.Name(), fmt.Errorf("assembled sha256 %s does not match %s", digest, upload.SHA256)
+	}
+	return tmp.Name(), tmp.Close()
+}
+
+// Delete 放弃上传会话并删除已上传的分片
+// Abort an upload session and delete the uploaded chunks
+func (UploadApi) Delete(ctx context.Context, c *app.RequestContext) {
+	_, upload, ok := Upload.getUpload(c)
+	if !ok {
+		return
+	}
+	if err := store.Upload.Delete(ctx, upload); err != nil {
+		resps.DBError(c, err, "delete upload error")
+		return
+	}
+	resps.Ok(c, resps.OK)
+}
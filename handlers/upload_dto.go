@@ -0,0 +1,49 @@
+package handlers
+
+import "time"
+
+// CreateUploadReq 创建分片上传会话的请求参数，版本和预览参数与普通上传相同
+// Request parameters to create a chunked upload session, the version and preview parameters match plain uploads
+type CreateUploadReq struct {
+	Size    int64  `json:"size" binding:"required"`   // 压缩包总字节数 Total size of the archive in bytes
+	SHA256  string `json:"sha256" binding:"required"` // 压缩包的 SHA-256 十六进制值 Hex SHA-256 of the archive
+	Tag     string `json:"tag" binding:"required"`    // 版本标签 Version tag
+	Preview string `json:"preview"`                   // 分支或合并请求标识，填写时作为预览部署 Branch or pull request identifier, makes it a preview deployment
+	TTL     int    `json:"ttl"`                       // 预览有效期，单位秒，0 为默认值 Preview lifetime in seconds, 0 for the default
+
+	PublishAt    string `json:"publish_at"`    // RFC 3339 定时上线时间 RFC 3339 go-live time
+	ExpiresAt    string `json:"expires_at"`    // RFC 3339 到期时间 RFC 3339 expiry time
+	ExpireAction string `json:"expire_action"` // 到期处理方式 Expiry action
+
+	Checksums map[string]string `json:"checksums"`                      // 路径到文件 SHA-256 的清单，拼接解压后逐个校验 Map of paths to file SHA-256, each verified once assembled and extracted
+	Changelog string            `json:"changelog" validate:"max=10000"` // 版本的变更说明，显示在部署订阅源中 Changelog of the version, shown in the deployment feeds
+}
+
+// CompleteUploadReq 完成分片上传的请求参数
+// Request parameters to complete a chunked upload
+type CompleteUploadReq struct {
+	Async bool `json:"async" query:"async"` // 在后台任务中拼接、校验并发布，立即返回任务 Assemble, verify and publish in a background job, returning the job right away
+}
+
+// UploadDTO 分片上传会话数据传输对象，客户端据此续传缺失的分片
+// Chunked upload session Data Transfer Object (DTO), clients resume the missing chunks from it
+type UploadDTO struct {
+	ID        string           `json:"id"`         // 会话ID Session ID
+	Tag       string           `json:"tag"`        // 版本标签 Version tag
+	Preview   string           `json:"preview"`    // 预览部署名称 Preview deployment name
+	Size      int64            `json:"size"`       // 压缩包总字节数 Total size of the archive in bytes
+	SHA256    string           `json:"sha256"`     // 压缩包的 SHA-256 SHA-256 of the archive
+	ChunkSize int64            `json:"chunk_size"` // 分片字节数 Chunk size in bytes
+	Chunks    int              `json:"chunks"`     // 分片数量 Number of chunks
+	Received  []UploadChunkDTO `json:"received"`   // 已上传的分片 Uploaded chunks
+	ExpiresAt time.Time        `json:"expires_at"` // 过期时间 Expiry time
+	CreatedAt time.Time        `json:"created_at"` // 创建时间 Created time
+}
+
+// UploadChunkDTO 已上传的分片
+// An uploaded chunk
+type UploadChunkDTO struct {
+	Index  int    `json:"index"`  // 分片序号 Chunk index
+	Size   int64  `json:"size"`   // 字节数 Size in bytes
+	SHA256 string `json:"sha256"` // 服务端计算的 SHA-256 SHA-256 computed by the server
+}
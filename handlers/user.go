@@ -2,11 +2,16 @@ package handlers
 
 import (
 	"context"
+	"errors"
+	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/LiteyukiStudio/spage/config"
 	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/i18n"
+	"github.com/LiteyukiStudio/spage/mailer"
 	"github.com/LiteyukiStudio/spage/middle"
 	"github.com/LiteyukiStudio/spage/models"
 	"github.com/LiteyukiStudio/spage/resps"
@@ -29,20 +34,22 @@ func (UserApi) ToDTO(user *models.User, self bool) UserDTO {
 		DisplayName: user.DisplayName,
 		Email:       user.Email,
 		Description: user.Description,
-		Avatar:      user.AvatarURL,
+		Avatar:      userAvatar(user),
 	}
 	if self {
 		userDTO.Role = user.Role
 		userDTO.Language = user.Language
+		userDTO.EmailVerified = emailVerified(user)
+		userDTO.DeleteAt = user.DeleteAt
+		userDTO.DisabledAt = user.DisabledAt
 	}
 	return userDTO
 }
 
-// Login 用户登录
-// User login
+// Login 用户登录，按账号和客户端 IP 统计连续失败，失败过多时退避、要求验证码或临时锁定账号
+// User login, counting consecutive failures by account and client IP to back off, require the captcha or lock the account temporarily after too many
 func (UserApi) Login(ctx context.Context, c *app.RequestContext) {
 	loginReq := &LoginReq{}
-	// TODO: 这里需要验证验证码
 	err := c.BindJSON(loginReq)
 	if err != nil {
 		resps.BadRequest(c, "Parameter error")
@@ -55,58 +62,235 @@ func (UserApi) Login(ctx context.Context, c *app.RequestContext) {
 	user, err := store.User.GetByName(loginReq.Username)
 	if err != nil {
 		user, err = store.User.GetByEmail(loginReq.Username)
-		if err != nil {
-			resps.BadRequest(c, "User does not exist")
+	}
+	var known *models.User
+	if err == nil {
+		known = user
+	}
+	if User.loginBlocked(c, known) {
+		return
+	}
+	if User.captchaRequired(c, known) {
+		ok, captchaErr := middle.Captcha.Verify(ctx, loginReq.CaptchaToken)
+		if captchaErr != nil {
+			resps.InternalServerError(c, "Captcha verification failed")
+			return
+		}
+		if !ok {
+			loginFailedResp(c, http.StatusForbidden, resps.CodeCaptchaFailed, "Captcha verification failed", true)
 			return
 		}
 	}
+	verified, rehash := false, false
+	if err == nil && user.Password != nil {
+		verified, rehash = utils.Password.VerifyPassword(loginReq.Password, *user.Password)
+	}
+	if !verified {
+		// 本地验证失败时尝试目录登录 Try directory sign-in when local verification fails
+		ldapUser, ldapErr := LDAP.authenticate(ctx, loginReq.Username, loginReq.Password)
+		if ldapErr == nil {
+			User.finishLogin(ctx, c, ldapUser, "ldap")
+			return
+		}
+		switch {
+		case !errors.Is(ldapErr, errLDAPNoMatch):
+			resps.Forbidden(c, ldapErr.Error())
+		case err != nil:
+			loginFailedResp(c, http.StatusBadRequest, resps.CodeInvalidCredentials, "User does not exist", User.loginFailed(ctx, c, nil))
+		case user.Password == nil:
+			resps.Forbidden(c, "Password not set, please use another login method")
+		default:
+			loginFailedResp(c, http.StatusForbidden, resps.CodeInvalidCredentials, "Incorrect password", User.loginFailed(ctx, c, user))
+		}
+		return
+	}
+	if rehash {
+		User.rehashPassword(ctx, user, loginReq.Password)
+	}
+	if config.EmailRequireVerification && mailer.Enabled() && user.Email != nil && *user.Email != "" && !emailVerified(user) {
+		resps.Forbidden(c, "Email is not verified, please check your inbox")
+		return
+	}
+	User.finishLogin(ctx, c, user, "password")
+}
 
-	if user.Password == nil {
-		resps.Forbidden(c, "Password not set, please use another login method")
+// rehashPassword 以当前的算法和参数重新计算已验证的密码哈希，失败只记录警告，下次登录时再试
+// Recompute a verified password hash with the current algorithm and parameters, a failure only logs a warning and is retried on the next login
+func (UserApi) rehashPassword(ctx context.Context, user *models.User, password string) {
+	hashed, err := utils.Password.HashPassword(password)
+	if err == nil {
+		err = store.User.SetPassword(user.ID, hashed)
+	}
+	if err != nil {
+		utils.Log.Ctx(ctx).Warnf("failed to rehash the password of user %d: %v", user.ID, err)
 		return
-	} else {
-		if utils.Password.VerifyPassword(loginReq.Password, *user.Password, config.JwtSecret) {
-			token, err := utils.Token.CreateToken(user.ID, time.Duration(config.TokenExpireTime)*time.Second, false, middle.PersistentHandler)
-			if err != nil {
-				resps.InternalServerError(c, "Failed to create token")
-				return
-			}
-			refreshToken, err := utils.Token.CreateToken(user.ID, time.Duration(config.RefreshTokenExpireTime)*time.Second, true, middle.PersistentHandler)
-			if err != nil {
-				resps.InternalServerError(c, "Failed to create refresh token")
-				return
-			}
-			c.SetCookie("token", token, config.TokenExpireTime, "/", "", protocol.CookieSameSiteLaxMode, true, true)
-			c.SetCookie("refresh_token", refreshToken, config.RefreshTokenExpireTime, "/", "", protocol.CookieSameSiteLaxMode, true, true)
-			resps.Ok(c, "Login successful", map[string]any{
-				"token":         token,
-				"refresh_token": refreshToken,
-			})
-			return
-		} else {
-			resps.Forbidden(c, "Incorrect password")
+	}
+	user.Password = &hashed
+}
+
+// finishLogin 完成已验证用户的登录，启用两步验证时先返回临时凭证，由 /user/login/2fa 完成登录
+// Complete the login of a verified user, with two-factor enabled a challenge is returned first and the login is completed by /user/login/2fa
+func (UserApi) finishLogin(ctx context.Context, c *app.RequestContext, user *models.User, method string) {
+	if User.accountDisabled(c, user) {
+		return
+	}
+	challenge, err := TwoFactor.challenge(user)
+	if err != nil {
+		resps.InternalServerError(c, "Failed to get two-factor status")
+		return
+	}
+	if challenge != "" {
+		resps.Ok(c, "Two-factor authentication required", map[string]any{
+			"two_factor_required": true,
+			"challenge":           challenge,
+		})
+		return
+	}
+	token, refreshToken, err := User.startSession(ctx, c, user, method)
+	if err != nil {
+		resps.InternalServerError(c, err.Error())
+		return
+	}
+	resps.Ok(c, "Login successful", map[string]any{
+		"token":         token,
+		"refresh_token": refreshToken,
+	})
+}
+
+// accountDisabled 账号已被管理员停用时返回错误响应并返回 true
+// Respond with an error and return true when an administrator disabled the account
+func (UserApi) accountDisabled(c *app.RequestContext, user *models.User) bool {
+	if user.DisabledAt == nil {
+		return false
+	}
+	resps.Forbidden(c, "Account is disabled")
+	return true
+}
+
+// startSession 创建登录会话，签发访问令牌和刷新令牌并写入 cookie，method 为登录方式，记入审计日志
+// Create a sign-in session, issuing the access and refresh tokens and setting them as cookies, method is the login method recorded in the audit log
+func (UserApi) startSession(ctx context.Context, c *app.RequestContext, user *models.User, method string) (token, refreshToken string, err error) {
+	refreshToken, refreshHash, err := utils.Token.NewRefreshToken()
+	if err != nil {
+		return "", "", errors.New("Failed to create refresh token")
+	}
+	now := time.Now()
+	session := &models.Token{
+		UserID:      user.ID,
+		RefreshHash: refreshHash,
+		ExpiresAt:   now.Add(time.Duration(config.RefreshTokenExpireTime) * time.Second),
+		LastUsedAt:  now,
+		Method:      method,
+		IP:          c.ClientIP(),
+		UserAgent:   string(c.UserAgent()),
+	}
+	if err = store.JWT.CreateSession(session); err != nil {
+		return "", "", errors.New("Failed to create session")
+	}
+	token, err = utils.Token.CreateToken(user.ID, session.ID, time.Duration(config.TokenExpireTime)*time.Second)
+	if err != nil {
+		return "", "", errors.New("Failed to create token")
+	}
+	c.SetCookie("token", token, config.TokenExpireTime, "/", "", protocol.CookieSameSiteLaxMode, true, true)
+	c.SetCookie("refresh_token", refreshToken, config.RefreshTokenExpireTime, "/", "", protocol.CookieSameSiteLaxMode, true, true)
+	middle.CSRF.Issue(c)
+	if err := store.LoginFailure.Reset(models.LoginFailureUser, strconv.FormatUint(uint64(user.ID), 10)); err != nil {
+		utils.Log.Ctx(ctx).Warnf("failed to reset the failed logins of user %d: %v", user.ID, err)
+	}
+	Audit.record(ctx, c, user, constants.AuditLogin, constants.AuditTargetUser, user.ID, map[string]any{"method": method, "session_id": session.ID})
+	return token, refreshToken, nil
+}
+
+// Refresh 使用刷新令牌获取新的访问令牌，刷新令牌取自请求体或 cookie
+// Get a new access token with a refresh token taken from the request body or the cookie
+func (UserApi) Refresh(ctx context.Context, c *app.RequestContext) {
+	req := RefreshTokenReq{}
+	if len(c.Request.Body()) > 0 {
+		if !bindJSON(c, &req) {
 			return
 		}
 	}
+	if req.RefreshToken == "" {
+		req.RefreshToken = string(c.Cookie("refresh_token"))
+	}
+	if req.RefreshToken == "" {
+		resps.Unauthorized(c, "Refresh token not found")
+		return
+	}
+	session, token, err := middle.Auth.Refresh(ctx, c, req.RefreshToken)
+	if err != nil {
+		resps.Unauthorized(c, "Refresh token expired or invalid")
+		return
+	}
+	// 代为登录的令牌不写入 cookie，以免替换管理员自己的会话 Impersonation tokens are not set as cookies so they do not replace the administrator's own session
+	if session.ImpersonatorID == 0 {
+		c.SetCookie("token", token, config.TokenExpireTime, "/", "", protocol.CookieSameSiteLaxMode, true, true)
+		middle.CSRF.Ensure(c)
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"token": token,
+	})
 }
 
-// Logout 用户登出
-// User logout
+// Logout 用户登出，撤销当前的登录会话
+// User logout, revoking the current sign-in session
 func (UserApi) Logout(ctx context.Context, c *app.RequestContext) {
+	var sessionID, userID, impersonator uint
+	// 不使用 cookie 的客户端以访问令牌登出，携带访问令牌时优先，以免代为登录的管理员撤销 cookie 中自己的会话
+	// Clients without cookies log out with their access token, which comes first so an impersonating administrator does not revoke their own session in the cookies
+	if token := strings.TrimPrefix(string(c.GetHeader("Authorization")), "Bearer "); token != "" {
+		if claims, err := utils.Token.ParseToken(token, middle.RevokeChecker); err == nil {
+			sessionID, userID, impersonator = claims.TokenID, claims.UserID, claims.Impersonator
+		}
+	}
+	if refreshToken := string(c.Cookie("refresh_token")); sessionID == 0 && refreshToken != "" {
+		if !middle.CSRF.Valid(c) {
+			resps.Forbidden(c, "CSRF token missing or invalid")
+			return
+		}
+		if session, err := store.JWT.GetByRefreshHash(utils.Token.HashAPIToken(refreshToken)); err == nil {
+			sessionID, userID, impersonator = session.ID, session.UserID, session.ImpersonatorID
+		}
+	}
+	if sessionID != 0 {
+		if err := store.JWT.RevokeTokenByID(sessionID); err != nil {
+			utils.Log.Ctx(ctx).Warnf("failed to revoke session %d: %v", sessionID, err)
+		}
+	}
+	// 结束代为登录，操作者为管理员，cookie 中是管理员自己的会话因此保留
+	// Ending an impersonation, the administrator is the actor and the cookies hold their own session so they are kept
+	if impersonator != 0 {
+		if admin, err := store.User.GetByID(impersonator); err == nil {
+			Audit.record(ctx, c, admin, constants.AuditImpersonationStop, constants.AuditTargetUser, userID, map[string]any{"session_id": sessionID})
+		}
+		resps.Ok(c, "Logout successful")
+		return
+	}
 	// 删除cookie
 	c.SetCookie("token", "", -1, "/", "", protocol.CookieSameSiteLaxMode, true, true)
 	c.SetCookie("refresh_token", "", -1, "/", "", protocol.CookieSameSiteLaxMode, true, true)
+	middle.CSRF.Clear(c)
 	resps.Ok(c, "Logout successful")
 }
 
-// GetCaptcha 获取验证码
-// Get captcha
+// GetCaptcha 获取验证码配置，使用内置算术题时每次请求返回一道新题
+// Get captcha configuration, handing out a new challenge per request with the built-in math captcha
 func (UserApi) GetCaptcha(ctx context.Context, c *app.RequestContext) {
-	resps.Ok(c, "ok", map[string]any{
+	data := map[string]any{
 		"provider": config.CaptchaType,
 		"site_key": config.CaptchaSiteKey,
 		"url":      config.CaptchaUrl,
-	})
+	}
+	if config.CaptchaType == constants.CaptchaTypeMath {
+		challenge, err := utils.Captcha.NewMath()
+		if err != nil {
+			resps.InternalServerError(c, "create captcha error")
+			return
+		}
+		data["math"] = challenge
+		c.Response.Header.Set("Cache-Control", "no-store")
+	}
+	resps.Ok(c, "ok", data)
 }
 
 // GetUserOrgs 获取用户的组织
@@ -120,11 +304,12 @@ func (UserApi) GetOrgs(ctx context.Context, c *app.RequestContext) {
 	}
 	page, limit := utils.Ctx.GetPageLimit(c)
 
-	orgs, err := store.Org.ListByUserID(userID, page, limit)
+	orgs, total, err := store.Org.ListByUserID(ctx, userID, page, limit)
 	if err != nil {
-		resps.InternalServerError(c, "Failed to get organizations")
+		resps.DBError(c, err, "Failed to get organizations")
 		return
 	}
+	utils.Ctx.SetPageHeaders(c, page, limit, total)
 
 	resps.Ok(c, resps.OK, map[string]any{
 		"organizations": func() (orgDTOs []OrganizationDTO) {
@@ -133,6 +318,7 @@ func (UserApi) GetOrgs(ctx context.Context, c *app.RequestContext) {
 			}
 			return
 		}(),
+		"total": total,
 	})
 }
 
@@ -143,13 +329,23 @@ func (UserApi) GetProjects(ctx context.Context, c *app.RequestContext) {
 	crtUser := middle.Auth.GetUser(ctx, c)
 	if userID != strconv.Itoa(int(crtUser.ID)) {
 		resps.Forbidden(c, resps.PermissionDenied)
+		return
+	}
+	list, ok := getListQuery(c, "-id", projectSortFields...)
+	if !ok {
+		return
+	}
+	labels, ok := labelSelectors(c)
+	if !ok {
+		return
 	}
-	page, limit := utils.Ctx.GetPageLimit(c)
 
-	projects, total, err := store.Project.ListByOwner(constants.OwnerTypeUser, userID, page, limit)
+	projects, total, err := store.Project.ListByOwner(ctx, constants.OwnerTypeUser, userID, c.Query("q"), labels, list)
 	if err != nil {
-		resps.InternalServerError(c, "Failed to get projects")
+		resps.DBError(c, err, "Failed to get projects")
+		return
 	}
+	utils.Ctx.SetPageHeaders(c, list.Page, list.Limit, total)
 	resps.Ok(c, resps.OK, map[string]any{
 		"projects": func() (projectDTOs []ProjectDTO) {
 			for _, project := range projects {
@@ -187,12 +383,9 @@ func (UserApi) GetUser(ctx context.Context, c *app.RequestContext) {
 func (UserApi) Register(ctx context.Context, c *app.RequestContext) {
 	// 接收参数
 	request := &RegisterReq{}
-	err := c.BindJSON(request)
-	if err != nil {
-		resps.BadRequest(c, "Parameter error")
+	if !bindJSON(c, request) {
 		return
 	}
-	// TODO 校验邮箱验证码
 	// 校验密码复杂度
 	passwordLevel := config.GetInt("password_complexity", 3)
 	if !utils.Password.CheckPasswordComplexity(request.Password, passwordLevel) {
@@ -204,21 +397,54 @@ func (UserApi) Register(ctx context.Context, c *app.RequestContext) {
 		resps.BadRequest(c, "Username already exists")
 		return
 	}
+	// 校验邀请 Check the invitation
+	var invitation *models.Invitation
+	if request.InviteToken != "" {
+		var err error
+		invitation, err = Invitation.forUser(request.InviteToken, &request.Email)
+		if err != nil {
+			resps.BadRequest(c, err.Error())
+			return
+		}
+	} else if config.RegistrationInviteOnly {
+		resps.Forbidden(c, "Registration requires an invitation")
+		return
+	}
 	// 创建用户
-	hashPassword, err := utils.Password.HashPassword(request.Password, config.JwtSecret)
+	hashPassword, err := utils.Password.HashPassword(request.Password)
 	if err != nil {
 		resps.InternalServerError(c, "Failed to hash password")
 		return
 	}
-	err = store.User.Create(&models.User{
+	// 新用户的语言取注册时请求协商的语言 New users get the language negotiated for the sign-up request
+	user := &models.User{
 		Name:     request.Username,
 		Email:    &request.Email,
 		Password: &hashPassword,
-	})
+		Language: i18n.Language(c),
+	}
+	if invitation != nil {
+		err = store.Invitation.Register(invitation, user)
+	} else {
+		err = store.User.Create(user)
+	}
+	if errors.Is(err, store.ErrInvitationInvalid) {
+		resps.BadRequest(c, "Invalid or expired invitation")
+		return
+	}
 	if err != nil {
 		resps.InternalServerError(c, "Failed to create user")
 		return
 	}
+	if invitation != nil {
+		details := map[string]any{}
+		if invitation.OrganizationID != nil {
+			details["organization_id"], details["role"] = *invitation.OrganizationID, invitation.Role
+		}
+		Audit.record(ctx, c, user, constants.AuditInvitationAccept, constants.AuditTargetInvitation, invitation.ID, details)
+	}
+	// 发送验证邮件失败不影响注册，用户可以稍后重新发送 A failed verification email does not fail the registration, it can be sent again later
+	_ = User.sendVerification(ctx, user)
 	resps.Ok(c, "Register successful", map[string]any{
 		"user": UserDTO{
 			Name:  request.Username,
@@ -231,23 +457,53 @@ func (UserApi) Register(ctx context.Context, c *app.RequestContext) {
 // Update user information
 func (UserApi) UpdateUser(ctx context.Context, c *app.RequestContext) {
 	userDTO := &UserDTO{}
-	if err := c.BindJSON(userDTO); err != nil {
-		resps.BadRequest(c, resps.ParameterError)
+	if !bindJSON(c, userDTO) {
 		return
 	}
 
 	crtUser := middle.Auth.GetUser(ctx, c)
+	// 修改邮箱后需要重新验证 A changed email has to be verified again
+	emailChanged := !sameEmail(crtUser.Email, userDTO.Email)
+	if emailChanged {
+		crtUser.EmailVerifiedAt = nil
+	}
 	crtUser.Name = userDTO.Name
 	crtUser.DisplayName = userDTO.DisplayName
 	crtUser.Email = userDTO.Email
 	crtUser.Description = userDTO.Description
-	crtUser.AvatarURL = userDTO.Avatar
-	crtUser.Language = userDTO.Language
+	// 原样提交读取到的地址时保留上传的头像 Submitting the URL as read keeps the uploaded avatar
+	var replacedAvatar string
+	if avatarChanged(userDTO.Avatar, userAvatar(crtUser)) {
+		replacedAvatar = crtUser.AvatarHash
+		crtUser.AvatarURL, crtUser.AvatarHash = userDTO.Avatar, ""
+	}
+	if userDTO.Language != "" {
+		language := i18n.Normalize(userDTO.Language)
+		if language == "" {
+			resps.BadRequest(c, "unsupported language, use en or zh-cn")
+			return
+		}
+		crtUser.Language = language
+	}
 
 	if err := store.User.Update(crtUser); err != nil {
-		resps.InternalServerError(c, "Failed to update user")
+		resps.DBError(c, err, "Failed to update user")
 		return
 	}
+	Avatar.remove(ctx, constants.OwnerTypeUser, crtUser.ID, replacedAvatar)
+	if emailChanged {
+		_ = store.UserToken.Void(crtUser.ID, constants.UserTokenVerifyEmail)
+		_ = User.sendVerification(ctx, crtUser)
+	}
 
 	resps.Ok(c, resps.OK, map[string]any{})
 }
+
+// sameEmail 比较两个可空的邮箱，忽略大小写
+// Compare two optional email addresses, ignoring case
+func sameEmail(a, b *string) bool {
+	if a == nil || b == nil {
+		return (a == nil || *a == "") && (b == nil || *b == "")
+	}
+	return strings.EqualFold(*a, *b)
+}
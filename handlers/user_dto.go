@@ -1,33 +1,82 @@
 package handlers
 
+import (
+	"time"
+
+	"github.com/LiteyukiStudio/spage/constants"
+)
+
 // RegisterReq 注册请求结构体
 // Registration request structure
 type RegisterReq struct {
-	Username string `json:"username" binding:"required"` // 用户名 Username
-	Password string `json:"password" binding:"required"` // 密码 Password
-	Email    string `json:"email" binding:"required"`    // 邮箱 Email
-
+	Username     string `json:"username" binding:"required" validate:"required,max=64,reserved"` // 用户名 Username
+	Password     string `json:"password" binding:"required"`                                     // 密码 Password
+	Email        string `json:"email" binding:"required" validate:"required,email"`              // 邮箱 Email
+	InviteToken  string `json:"invite_token"`                                                    // 邀请令牌，只允许邀请注册时必填 Invitation token, required when sign-up is by invitation only
+	CaptchaToken string `json:"captcha_token"`                                                   // 验证码 Token，配置了验证码时必填 Captcha token, required when a captcha is configured
 }
 
 // LoginReq 登录请求结构体
 // Login request structure
 type LoginReq struct {
-	Username     string `json:"username" binding:"required"`      // 用户名 Username
-	Password     string `json:"password" binding:"required"`      // 密码 Password
-	CaptchaToken string `json:"captcha_token" binding:"required"` // 验证码 Token
+	Username     string `json:"username" binding:"required"` // 用户名 Username
+	Password     string `json:"password" binding:"required"` // 密码 Password
+	CaptchaToken string `json:"captcha_token"`               // 验证码 Token，连续失败过多或 login.captcha-after 为 0 时必填 Captcha token, required after too many failures or when login.captcha-after is 0
+}
+
+// RefreshTokenReq 刷新访问令牌的请求参数，为空时使用 cookie 中的刷新令牌
+// Request parameters to refresh the access token, the refresh token cookie is used when empty
+type RefreshTokenReq struct {
+	RefreshToken string `json:"refresh_token"` // 刷新令牌 Refresh token
 }
 
 // OrganizationDTO 组织信息数据传输对象
 // Organization Information Data Transfer Object (DTO)
 type UserDTO struct {
-	ID            uint              `json:"id"`            // 用户ID User ID
-	Name          string            `json:"name"`          // 用户名 Username
-	DisplayName   *string           `json:"display_name"`  // 显示名称 DisplayName
-	Email         *string           `json:"email"`         // 邮箱 Email
-	Description   string            `json:"description"`   // 描述 Description
-	Avatar        *string           `json:"avatar_url"`    // 头像 Avatar URL
-	Role          string            `json:"role"`          // 角色 Role
-	Organizations []OrganizationDTO `json:"organizations"` // 组织 Organizations
-	Language      string            `json:"language"`      // 语言 Language
+	ID            uint              `json:"id"`                              // 用户ID User ID
+	Name          string            `json:"name" validate:"required,max=64"` // 用户名 Username
+	DisplayName   *string           `json:"display_name" validate:"max=128"` // 显示名称 DisplayName
+	Email         *string           `json:"email" validate:"email"`          // 邮箱 Email
+	Description   string            `json:"description"`                     // 描述 Description
+	Avatar        *string           `json:"avatar_url" validate:"url"`       // 头像 Avatar URL
+	Role          constants.Role    `json:"role"`                            // 角色 Role
+	Organizations []OrganizationDTO `json:"organizations"`                   // 组织 Organizations
+	Language      string            `json:"language"`                        // 语言 Language
+	EmailVerified bool              `json:"email_verified"`                  // 邮箱是否已验证 Whether the email is verified
+	DeleteAt      *time.Time        `json:"delete_at,omitempty"`             // 已申请删除时账号的删除时间 Time the account is deleted at once its deletion was requested
+	DisabledAt    *time.Time        `json:"disabled_at,omitempty"`           // 管理员停用账号的时间 Time an administrator disabled the account
 	//Password      string            `json:"password"` // 密码 Password
 }
+
+// TwoFactorLoginReq 完成两步验证登录的请求
+// Request to complete a two-factor login
+type TwoFactorLoginReq struct {
+	Challenge string `json:"challenge" binding:"required"` // 密码登录返回的临时凭证 Challenge returned by the password login
+	Code      string `json:"code" binding:"required"`      // TOTP 验证码或恢复码 TOTP code or recovery code
+}
+
+// TwoFactorCodeReq 需要验证码确认的两步验证操作
+// Two-factor operation confirmed by a code
+type TwoFactorCodeReq struct {
+	Code string `json:"code" binding:"required"` // TOTP 验证码或恢复码 TOTP code or recovery code
+}
+
+// VerifyEmailReq 验证邮箱的请求
+// Request to verify an email address
+type VerifyEmailReq struct {
+	Token string `json:"token" binding:"required"` // 邮件中的令牌 Token from the email
+}
+
+// ForgotPasswordReq 申请重置密码的请求
+// Request to ask for a password reset
+type ForgotPasswordReq struct {
+	Email        string `json:"email" binding:"required"` // 账号邮箱 Account email
+	CaptchaToken string `json:"captcha_token"`            // 验证码 Token，配置了验证码时必填 Captcha token, required when a captcha is configured
+}
+
+// ResetPasswordReq 重置密码的请求
+// Request to reset the password
+type ResetPasswordReq struct {
+	Token    string `json:"token" binding:"required"`    // 邮件中的令牌 Token from the email
+	Password string `json:"password" binding:"required"` // 新密码 New password
+}
@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/mailer"
+	"github.com/LiteyukiStudio/spage/middle"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// forgotPasswordMessage 无论邮箱是否存在都返回同样的消息，避免泄露注册情况
+// The same message is returned whether the address exists or not, so registrations are not leaked
+const forgotPasswordMessage = "If the address belongs to an account, a reset link has been sent"
+
+// emailLink 面板中处理邮件链接的页面地址
+// Address of the panel page handling an email link
+func emailLink(page, token string) string {
+	return strings.TrimSuffix(config.FrontEndURL, "/") + "/" + page + "?token=" + url.QueryEscape(token)
+}
+
+// displayName 邮件中称呼用户的名称
+// Name used to address the user in emails
+func displayName(user *models.User) string {
+	if user.DisplayName != nil && *user.DisplayName != "" {
+		return *user.DisplayName
+	}
+	return user.Name
+}
+
+// emailVerified 用户的当前邮箱是否已验证
+// Whether the user's current email is verified
+func emailVerified(user *models.User) bool {
+	return user.Email != nil && *user.Email != "" && user.EmailVerifiedAt != nil
+}
+
+// sendVerification 向用户的当前邮箱发送验证链接，未启用邮件发送或没有邮箱时不做任何事
+// Send a verification link to the user's current email, doing nothing while email sending is disabled or without an address
+func (UserApi) sendVerification(ctx context.Context, user *models.User) error {
+	if !mailer.Enabled() || user.Email == nil || *user.Email == "" {
+		return nil
+	}
+	ttl := time.Duration(config.EmailVerifyTTL) * time.Second
+	token, err := store.UserToken.Issue(user.ID, constants.UserTokenVerifyEmail, *user.Email, ttl)
+	if err != nil {
+		utils.Log.Ctx(ctx).Errorf("failed to issue verification token for user %d: %v", user.ID, err)
+		return errors.New("Failed to issue verification token")
+	}
+	mailer.SendAsync(*user.Email, mailer.TemplateVerifyEmail, user.Language, map[string]any{
+		"Name":  displayName(user),
+		"Link":  emailLink("verify-email", token),
+		"Hours": int(ttl.Hours()),
+	})
+	return nil
+}
+
+// consumeToken 使用一次性令牌并返回其用户，令牌发出后邮箱已修改时视为无效
+// Use a one-time token and return its user, the token is invalid once the address changed after it was sent
+func (UserApi) consumeToken(plain, purpose string) (*models.User, error) {
+	if plain == "" {
+		return nil, store.ErrUserTokenInvalid
+	}
+	token, err := store.UserToken.Consume(plain, purpose)
+	if err != nil {
+		return nil, err
+	}
+	user, err := store.User.GetByID(token.UserID)
+	if err != nil || user.Email == nil || !strings.EqualFold(*user.Email, token.Email) {
+		return nil, store.ErrUserTokenInvalid
+	}
+	return user, nil
+}
+
+// VerifyEmail 通过邮件中的令牌验证邮箱
+// Verify the email address with the token from the email
+func (UserApi) VerifyEmail(ctx context.Context, c *app.RequestContext) {
+	req := &VerifyEmailReq{}
+	if !bindJSON(c, req) {
+		return
+	}
+	user, err := User.consumeToken(req.Token, constants.UserTokenVerifyEmail)
+	if err != nil {
+		resps.BadRequest(c, "Invalid or expired verification link")
+		return
+	}
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+	if err := store.User.Update(user); err != nil {
+		resps.DBError(c, err, "Failed to update user")
+		return
+	}
+	Audit.record(ctx, c, user, constants.AuditEmailVerify, constants.AuditTargetUser, user.ID, map[string]any{"email": *user.Email})
+	resps.Ok(c, "Email verified", map[string]any{
+		"user": User.ToDTO(user, true),
+	})
+}
+
+// ResendVerification 重新发送当前用户的邮箱验证链接
+// Send the verification link of the current user again
+func (UserApi) ResendVerification(ctx context.Context, c *app.RequestContext) {
+	crtUser := middle.Auth.GetUser(ctx, c)
+	if !mailer.Enabled() {
+		resps.BadRequest(c, "Email sending is not enabled")
+		return
+	}
+	if crtUser.Email == nil || *crtUser.Email == "" {
+		resps.BadRequest(c, "No email address is set")
+		return
+	}
+	if emailVerified(crtUser) {
+		resps.BadRequest(c, "Email is already verified")
+		return
+	}
+	if err := User.sendVerification(ctx, crtUser); err != nil {
+		resps.InternalServerError(c, err.Error())
+		return
+	}
+	resps.Ok(c, "Verification email sent", map[string]any{})
+}
+
+// ForgotPassword 向账号邮箱发送密码重置链接，响应不透露邮箱是否存在
+// Send a password reset link to the account's email, the response does not tell whether the address exists
+func (UserApi) ForgotPassword(ctx context.Context, c *app.RequestContext) {
+	req := &ForgotPasswordReq{}
+	if err := c.BindJSON(req); err != nil || strings.TrimSpace(req.Email) == "" {
+		resps.BadRequest(c, resps.ParameterError)
+		return
+	}
+	if !mailer.Enabled() {
+		resps.BadRequest(c, "Email sending is not enabled, please contact an administrator")
+		return
+	}
+	user, err := store.User.GetByEmail(strings.TrimSpace(req.Email))
+	if err != nil || user.Email == nil {
+		resps.Ok(c, forgotPasswordMessage, map[string]any{})
+		return
+	}
+	ttl := time.Duration(config.EmailResetTTL) * time.Second
+	token, err := store.UserToken.Issue(user.ID, constants.UserTokenResetPassword, *user.Email, ttl)
+	if err != nil {
+		utils.Log.Ctx(ctx).Errorf("failed to issue reset token for user %d: %v", user.ID, err)
+		resps.InternalServerError(c, "Failed to issue reset token")
+		return
+	}
+	mailer.SendAsync(*user.Email, mailer.TemplateResetPassword, user.Language, map[string]any{
+		"Name":    displayName(user),
+		"Link":    emailLink("reset-password", token),
+		"Minutes": int(ttl.Minutes()),
+	})
+	resps.Ok(c, forgotPasswordMessage, map[string]any{})
+}
+
+// ResetPassword 通过邮件中的令牌设置新密码，成功后撤销该用户的所有会话
+// Set a new password with the token from the email, all sessions of the user are revoked afterwards
+func (UserApi) ResetPassword(ctx context.Context, c *app.RequestContext) {
+	req := &ResetPasswordReq{}
+	if !bindJSON(c, req) {
+		return
+	}
+	// 先校验密码复杂度，避免弱密码消耗掉令牌 Check the complexity first so a weak password does not use up the token
+	if !utils.Password.CheckPasswordComplexity(req.Password, config.GetInt("password_complexity", 3)) {
+		resps.BadRequest(c, "Password complexity is too low")
+		return
+	}
+	user, err := User.consumeToken(req.Token, constants.UserTokenResetPassword)
+	if err != nil {
+		resps.BadRequest(c, "Invalid or expired reset link")
+		return
+	}
+	hashPassword, err := utils.Password.HashPassword(req.Password)
+	if err != nil {
+		resps.InternalServerError(c, "Failed to hash password")
+		return
+	}
+	user.Password = &hashPassword
+	// 能收到重置邮件即证明拥有该邮箱 Receiving the reset email proves ownership of the address
+	if user.EmailVerifiedAt == nil {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+	// 新密码和会话的撤销在同一事务中生效，撤销失败时旧会话不会在新密码下继续有效
+	// The new password and the revoked sessions take effect in one transaction, so old sessions never outlive the new password
+	err = store.Default.WithTx(ctx, func(tx *store.Handle) error {
+		if err := tx.User.Update(user); err != nil {
+			return err
+		}
+		return tx.JWT.RevokeTokenByUserID(user.ID)
+	})
+	if err != nil {
+		resps.DBError(c, err, "Failed to update user")
+		return
+	}
+	Audit.record(ctx, c, user, constants.AuditPasswordReset, constants.AuditTargetUser, user.ID, nil)
+	resps.Ok(c, "Password reset successful", map[string]any{})
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/LiteyukiStudio/spage/utils"
+)
+
+// TestRequestValidateTags 所有请求参数的 validate 标签都能解析，有误的标签在测试中失败而不是在请求时 panic
+// Every validate tag of the request parameters parses, so a bad tag fails the tests instead of panicking on a live request
+func TestRequestValidateTags(t *testing.T) {
+	requests := []any{
+		AdminOrgReq{},
+		AdminProjectReq{},
+		AdminUserReq{},
+		ApprovalRejectReq{},
+		AuditListReq{},
+		AuthProviderReq{},
+		BulkDeleteProjectsReq{},
+		BulkDisableUsersReq{},
+		BulkReassignProjectsReq{},
+		CDNIntegrationReq{},
+		CDNPurgeReq{},
+		CheckpointReq{},
+		CompleteUploadReq{},
+		ContentPolicyReq{},
+		CreateAPITokenReq{},
+		CreateCustomDomainReq{},
+		CreateDeployKeyReq{},
+		CreateDraftReq{},
+		CreateInvitationReq{},
+		CreateOrgReq{},
+		CreateProjectReq{},
+		CreateReleaseReq{},
+		CreateRunnerReq{},
+		CreateSiteReq{},
+		CreateUploadReq{},
+		DebugResolveReq{},
+		DeleteAccountReq{},
+		DiffDeploymentsReq{},
+		ExportProjectReq{},
+		ForgotPasswordReq{},
+		GCReq{},
+		GetSiteListReq{},
+		GitIntegrationReq{},
+		GraphQLReq{},
+		ImpersonateReq{},
+		ImportProjectReq{},
+		InvitationTokenReq{},
+		LDAPLinkReq{},
+		LoginReq{},
+		ManifestDeployReq{},
+		MissingBlobsReq{},
+		NotificationChannelReq{},
+		OrgUserReq{},
+		ProjectTeamReq{},
+		ProjectUploadLimitReq{},
+		ProjectUserReq{},
+		PublishDraftReq{},
+		PurgePathsReq{},
+		QuarantineRejectReq{},
+		QuotaReq{},
+		RefreshTokenReq{},
+		RegisterReq{},
+		RegisterRunnerReq{},
+		ReleaseIdReq{},
+		ResetPasswordReq{},
+		RetentionReq{},
+		RollbackReq{},
+		RunnerFailReq{},
+		RunnerLogReq{},
+		SettingReq{},
+		SignURLReq{},
+		SiteLogsReq{},
+		SiteTransferLimitReq{},
+		TailLogsReq{},
+		TeamReq{},
+		TeamUserReq{},
+		TenantReq{},
+		TransferProjectReq{},
+		TwoFactorCodeReq{},
+		TwoFactorLoginReq{},
+		UpdateAPITokenReq{},
+		UpdateOrgReq{},
+		UpdateProjectReq{},
+		UpdateSiteReq{},
+		UserDTO{},
+		VerifyEmailReq{},
+		WebhookReq{},
+	}
+	checked := map[string]bool{}
+	for _, req := range requests {
+		checked[reflect.TypeOf(req).Name()] = true
+		if err := utils.Validator.Check(req); err != nil {
+			t.Error(err)
+			continue
+		}
+		// 零值同样走一遍校验，确认不会 panic Validate the zero value too to make sure it does not panic
+		utils.Validator.Struct(req)
+	}
+
+	// 新增的请求参数或带 validate 标签的类型需要加入上面的列表 New request parameters or types with validate tags need to join the list above
+	pkgs, err := parser.ParseDir(token.NewFileSet(), ".", func(info fs.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			ast.Inspect(file, func(node ast.Node) bool {
+				spec, ok := node.(*ast.TypeSpec)
+				if !ok {
+					return true
+				}
+				st, ok := spec.Type.(*ast.StructType)
+				if !ok {
+					return true
+				}
+				tagged := false
+				for _, field := range st.Fields.List {
+					tagged = tagged || field.Tag != nil && strings.Contains(field.Tag.Value, "validate:")
+				}
+				if (tagged || strings.HasSuffix(spec.Name.Name, "Req")) && !checked[spec.Name.Name] {
+					t.Errorf("%s is missing from the requests checked by TestRequestValidateTags", spec.Name.Name)
+				}
+				return true
+			})
+		}
+	}
+}
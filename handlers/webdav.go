@@ -0,0 +1,341 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/i18n"
+	"github.com/LiteyukiStudio/spage/middle"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/serve"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/adaptor"
+	"golang.org/x/net/webdav"
+)
+
+type WebDAVApi struct{}
+
+// WebDAV 站点草稿，成员在文件管理器中通过 WebDAV 挂载并编辑草稿，再将其发布为新的部署版本，适合维护小型站点
+// Site drafts, members mount and edit a draft over WebDAV in their file manager and then publish it as a new deployment version, meant for small sites
+var WebDAV = WebDAVApi{}
+
+// davLocks 每个站点草稿的 WebDAV 锁，只在本实例内有效
+// WebDAV locks of each site draft, only valid within this instance
+var davLocks sync.Map
+
+// dir 站点草稿的目录
+// Directory of the site's draft
+func (WebDAVApi) dir(site *models.Site) string {
+	return filepath.Join(config.WebDAVDir, strconv.FormatUint(uint64(site.ID), 10))
+}
+
+// prefix 站点草稿的 WebDAV 路径前缀
+// WebDAV path prefix of the site's draft
+func (WebDAVApi) prefix(site *models.Site) string {
+	return fmt.Sprintf("/api/v1/project/%d/site/%d/dav", site.ProjectID, site.ID)
+}
+
+// junk 文件管理器自动创建的元数据文件，统计和发布时跳过
+// Metadata files created by file managers on their own, skipped when counting and publishing
+func (WebDAVApi) junk(name string) bool {
+	base := path.Base(name)
+	switch base {
+	case ".DS_Store", ".Trashes", ".fseventsd", ".Spotlight-V100", "Thumbs.db", "desktop.ini":
+		return true
+	}
+	return strings.HasPrefix(base, "._")
+}
+
+// usage 草稿的文件数、总字节数和最近修改时间
+// File count, total size in bytes and last modified time of a draft
+func (WebDAVApi) usage(dir string) (files int, size int64, updated time.Time, err error) {
+	err = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if info.ModTime().After(updated) {
+			updated = info.ModTime()
+		}
+		if d.Type().IsRegular() {
+			size += info.Size()
+			if !WebDAV.junk(d.Name()) {
+				files++
+			}
+		}
+		return nil
+	})
+	return
+}
+
+// getDraft 获取路径中站点的草稿目录，未开启 WebDAV 或草稿不存在时已写入 404 响应
+// Get the draft directory of the path's site, a 404 response is already written when WebDAV is off or there is no draft
+func (WebDAVApi) getDraft(c *app.RequestContext) (*models.Site, string, bool) {
+	site := getSite(c)
+	if !config.WebDAVEnable || site == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return nil, "", false
+	}
+	dir := WebDAV.dir(site)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		resps.NotFound(c, "draft not found")
+		return nil, "", false
+	}
+	return site, dir, true
+}
+
+// toDTO 统计草稿目录并转换为数据传输对象
+// Measure the draft directory and turn it into a DTO
+func (WebDAVApi) toDTO(site *models.Site, dir string) (DraftDTO, error) {
+	files, size, updated, err := WebDAV.usage(dir)
+	if err != nil {
+		return DraftDTO{}, err
+	}
+	return DraftDTO{
+		URL:       strings.TrimSuffix(config.FrontEndURL, "/") + WebDAV.prefix(site) + "/",
+		Files:     files,
+		Size:      size,
+		MaxSize:   int64(config.WebDAVMaxSize) << 20,
+		UpdatedAt: updated,
+	}, nil
+}
+
+// Get 获取站点草稿的 WebDAV 地址和用量
+// Get the WebDAV URL and usage of the site's draft
+func (WebDAVApi) Get(ctx context.Context, c *app.RequestContext) {
+	site, dir, ok := WebDAV.getDraft(c)
+	if !ok {
+		return
+	}
+	draft, err := WebDAV.toDTO(site, dir)
+	if err != nil {
+		resps.InternalServerError(c, "get draft error")
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{"draft": draft})
+}
+
+// Create 创建站点草稿，默认复制当前版本的文件，已有草稿时返回 409
+// Create a draft of the site, copying the files of the active version by default; 409 when there is a draft already
+func (WebDAVApi) Create(ctx context.Context, c *app.RequestContext) {
+	site := getSite(c)
+	if !config.WebDAVEnable || site == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	req := CreateDraftReq{}
+	if !bind(c, &req) {
+		return
+	}
+	dir := WebDAV.dir(site)
+	if _, err := os.Stat(dir); err == nil {
+		resps.Custom(c, http.StatusConflict, "draft already exists")
+		return
+	}
+	if err := os.MkdirAll(config.WebDAVDir, 0o755); err != nil {
+		resps.InternalServerError(c, "create draft error")
+		return
+	}
+	// 先写入临时目录，完成后再改名，半成品不会被挂载 Written to a temporary directory and renamed once done, so half-copied drafts are never mounted
+	tmp, err := os.MkdirTemp(config.WebDAVDir, ".draft-*")
+	if err != nil {
+		resps.InternalServerError(c, "create draft error")
+		return
+	}
+	defer os.RemoveAll(tmp)
+	if !req.Empty {
+		if err := WebDAV.seed(site, tmp); errors.Is(err, errDraftTooLarge) {
+			resps.Custom(c, http.StatusRequestEntityTooLarge, i18n.Sprintf(i18n.Language(c), "the draft may hold at most %d MiB", config.WebDAVMaxSize))
+			return
+		} else if err != nil {
+			utils.Log.Ctx(ctx).Errorf("failed to copy the active version of site %d into its draft: %v", site.ID, err)
+			resps.InternalServerError(c, "create draft error")
+			return
+		}
+	}
+	if err := os.Rename(tmp, dir); err != nil {
+		resps.Custom(c, http.StatusConflict, "draft already exists")
+		return
+	}
+	Audit.record(ctx, c, nil, constants.AuditDraftCreate, constants.AuditTargetSite, site.ID, map[string]any{"empty": req.Empty})
+	draft, err := WebDAV.toDTO(site, dir)
+	if err != nil {
+		resps.InternalServerError(c, "get draft error")
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{"draft": draft})
+}
+
+// errDraftTooLarge 当前版本超出草稿的最大大小 The active version exceeds the largest draft
+var errDraftTooLarge = errors.New("draft too large")
+
+// seed 将站点当前版本的文件复制到目录，站点没有版本时不复制
+// Copy the files of the site's active version into the directory, nothing is copied when the site has no version
+func (WebDAVApi) seed(site *models.Site, dir string) error {
+	id := Release.activeID(site)
+	if id == 0 {
+		return nil
+	}
+	release, err := store.Site.GetSiteRelease(site.ID, id)
+	if err != nil {
+		return err
+	}
+	remaining := int64(config.WebDAVMaxSize) << 20
+	return serve.WalkRelease(&release.File, func(name string, size int64, body io.Reader) error {
+		if remaining -= size; remaining < 0 {
+			return errDraftTooLarge
+		}
+		target := filepath.Join(dir, filepath.FromSlash(path.Clean("/" + name)[1:]))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		file, err := os.Create(target)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(file, body)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		return err
+	})
+}
+
+// Delete 丢弃站点草稿
+// Discard the site's draft
+func (WebDAVApi) Delete(ctx context.Context, c *app.RequestContext) {
+	site, dir, ok := WebDAV.getDraft(c)
+	if !ok {
+		return
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		resps.InternalServerError(c, "delete draft error")
+		return
+	}
+	davLocks.Delete(site.ID)
+	Audit.record(ctx, c, nil, constants.AuditDraftDelete, constants.AuditTargetSite, site.ID, nil)
+	resps.Ok(c, resps.OK)
+}
+
+// Publish 将草稿打包并按普通上传发布为新版本，跳过文件管理器的元数据文件
+// Pack the draft and publish it as a new version like a plain upload, skipping the metadata files of file managers
+func (WebDAVApi) Publish(ctx context.Context, c *app.RequestContext) {
+	req := PublishDraftReq{}
+	if !bind(c, &req) {
+		return
+	}
+	site, dir, ok := WebDAV.getDraft(c)
+	if !ok {
+		return
+	}
+	if _, ok := Release.checkDeploy(c, site, req.Tag, "", 0); !ok {
+		return
+	}
+	tmp, err := os.CreateTemp("", "spage-draft-*.zip")
+	if err != nil {
+		resps.InternalServerError(c, "publish draft error")
+		return
+	}
+	_ = tmp.Close()
+	defer os.Remove(tmp.Name())
+	count, err := utils.ZipDir(dir, tmp.Name(), func(rel string, _ fs.DirEntry) bool {
+		return WebDAV.junk(rel)
+	})
+	if err != nil {
+		resps.InternalServerError(c, "publish draft error")
+		return
+	}
+	if count == 0 {
+		resps.BadRequest(c, "the draft is empty")
+		return
+	}
+	archive, err := fileArchive(tmp.Name())
+	if err != nil {
+		resps.InternalServerError(c, "publish draft error")
+		return
+	}
+	archive.changelog = req.Changelog
+	if !Release.deploy(ctx, c, site, archive, req.Tag, "", 0, releaseSchedule{}) {
+		return
+	}
+	if req.Discard {
+		if err := os.RemoveAll(dir); err != nil {
+			utils.Log.Ctx(ctx).Warnf("failed to discard the draft of site %d: %v", site.ID, err)
+		}
+		davLocks.Delete(site.ID)
+	}
+}
+
+// room 写入前检查草稿的剩余空间，PUT 按 Content-Length 检查，COPY 按源文件或目录的大小检查，返回剩余的字节数和是否允许写入
+// Check the room left in a draft before writing, by Content-Length for PUT and by the size of the source file or directory for COPY,
+// returning the bytes left and whether the write is allowed
+func (WebDAVApi) room(dir, name, method string, contentLength int64) (int64, bool, error) {
+	_, size, _, err := WebDAV.usage(dir)
+	if err != nil {
+		return 0, false, err
+	}
+	remaining := int64(config.WebDAVMaxSize)<<20 - size
+	switch method {
+	case http.MethodPut:
+		// 分块传输时长度未知，由请求体限制在读取时拦截 The length is unknown when chunked, the body limit stops it while reading
+		return remaining, remaining > 0 && contentLength <= remaining, nil
+	case "COPY":
+		_, copied, _, err := WebDAV.usage(filepath.Join(dir, filepath.FromSlash(path.Clean("/"+name))))
+		// 源不存在时交给 WebDAV 返回 404 A missing source is left to WebDAV to answer 404
+		if errors.Is(err, fs.ErrNotExist) {
+			return remaining, true, nil
+		}
+		if err != nil {
+			return 0, false, err
+		}
+		return remaining, copied <= remaining, nil
+	}
+	return remaining, true, nil
+}
+
+// Serve 站点草稿的 WebDAV 接口，写入的文件受 webdav.max-size 限制
+// WebDAV interface of the site's draft, written files are bound by webdav.max-size
+func (WebDAVApi) Serve(ctx context.Context, c *app.RequestContext) {
+	site, dir, ok := WebDAV.getDraft(c)
+	if !ok {
+		return
+	}
+	if method := string(c.Method()); method == http.MethodPut || method == "COPY" {
+		name := strings.TrimPrefix(string(c.Request.URI().Path()), WebDAV.prefix(site))
+		remaining, ok, err := WebDAV.room(dir, name, method, int64(c.Request.Header.ContentLength()))
+		if err != nil {
+			resps.InternalServerError(c, "get draft error")
+			return
+		}
+		if !ok {
+			resps.Custom(c, http.StatusRequestEntityTooLarge, i18n.Sprintf(i18n.Language(c), "the draft may hold at most %d MiB", config.WebDAVMaxSize))
+			return
+		}
+		middle.BodyLimit.Set(c, remaining)
+	}
+	locks, _ := davLocks.LoadOrStore(site.ID, webdav.NewMemLS())
+	adaptor.HertzHandler(&webdav.Handler{
+		Prefix:     WebDAV.prefix(site),
+		FileSystem: webdav.Dir(dir),
+		LockSystem: locks.(webdav.LockSystem),
+	})(ctx, c)
+}
@@ -0,0 +1,27 @@
+package handlers
+
+import "time"
+
+// CreateDraftReq 创建站点草稿的请求参数
+// Request parameters to create a site draft
+type CreateDraftReq struct {
+	Empty bool `json:"empty"` // 创建空草稿，默认复制当前版本的文件 Start from an empty draft instead of copying the files of the active version
+}
+
+// PublishDraftReq 将草稿发布为新版本的请求参数
+// Request parameters to publish a draft as a new version
+type PublishDraftReq struct {
+	Tag       string `json:"tag" validate:"required"`        // 版本标签 Version tag
+	Changelog string `json:"changelog" validate:"max=10000"` // 版本的变更说明，显示在部署订阅源中 Changelog of the version, shown in the deployment feeds
+	Discard   bool   `json:"discard"`                        // 发布后丢弃草稿，默认保留以便继续编辑 Discard the draft once published, it is kept for further edits by default
+}
+
+// DraftDTO 站点草稿数据传输对象
+// Site draft Data Transfer Object (DTO)
+type DraftDTO struct {
+	URL       string    `json:"url"`        // WebDAV 地址，用户名任意，密码为个人访问令牌或部署密钥 WebDAV URL, any user name with a personal access token or deploy key as the password
+	Files     int       `json:"files"`      // 文件数 Number of files
+	Size      int64     `json:"size"`       // 总字节数 Total size in bytes
+	MaxSize   int64     `json:"max_size"`   // 最大字节数 Largest size in bytes
+	UpdatedAt time.Time `json:"updated_at"` // 最近修改时间 Last modified time
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/test/mock"
+)
+
+// TestWebDAVCopyLimit 草稿接近 webdav.max-size 时，复制超出剩余空间的文件或目录返回 413，较小的仍可复制
+// Near webdav.max-size, copying a file or directory larger than the room left answers 413 while smaller ones still copy
+func TestWebDAVCopyLimit(t *testing.T) {
+	defer func(enable bool, dir string, size int) {
+		config.WebDAVEnable, config.WebDAVDir, config.WebDAVMaxSize = enable, dir, size
+	}(config.WebDAVEnable, config.WebDAVDir, config.WebDAVMaxSize)
+	config.WebDAVEnable, config.WebDAVDir, config.WebDAVMaxSize = true, t.TempDir(), 1
+
+	site := &models.Site{ProjectID: 3}
+	site.ID = 5
+	dir := WebDAV.dir(site)
+	for name, size := range map[string]int{"big/data.bin": 600 << 10, "small.txt": 10} {
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(target, []byte(strings.Repeat("x", size)), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	request := func(method, name, destination string, body string) int {
+		c := app.NewContext(0)
+		c.SetConn(mock.NewConn(""))
+		c.Request.SetMethod(method)
+		c.Request.SetRequestURI("http://panel.test" + WebDAV.prefix(site) + name)
+		if destination != "" {
+			c.Request.Header.Set("Destination", "http://panel.test"+WebDAV.prefix(site)+destination)
+		}
+		c.Request.SetBodyString(body)
+		c.Request.Header.SetContentLength(len(body))
+		c.Set("userSite", site)
+		WebDAV.Serve(context.Background(), c)
+		return c.Response.StatusCode()
+	}
+
+	if status := request("COPY", "/big/", "/copy/", ""); status != 413 {
+		t.Errorf("copying the directory: status %d, want 413", status)
+	}
+	if status := request("COPY", "/big/data.bin", "/copy.bin", ""); status != 413 {
+		t.Errorf("copying the file: status %d, want 413", status)
+	}
+	for _, name := range []string{"copy", "copy.bin"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("expected %s not to be written", name)
+		}
+	}
+	if status := request("COPY", "/small.txt", "/small-copy.txt", ""); status != 201 {
+		t.Errorf("copying the small file: status %d, want 201", status)
+	}
+	if status := request("PUT", "/large.txt", "", strings.Repeat("y", 500<<10)); status != 413 {
+		t.Errorf("putting past the limit: status %d, want 413", status)
+	}
+	if status := request("PUT", "/note.txt", "", "hello"); status != 201 {
+		t.Errorf("putting a small file: status %d, want 201", status)
+	}
+}
@@ -0,0 +1,350 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/i18n"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/task"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+type WebhookApi struct{}
+
+var Webhook = WebhookApi{}
+
+func (WebhookApi) toDTO(hook *models.Webhook) WebhookDTO {
+	events := hook.Events
+	if events == nil {
+		events = []string{}
+	}
+	return WebhookDTO{
+		ID:        hook.ID,
+		URL:       hook.URL,
+		Events:    events,
+		Format:    hook.Format,
+		Active:    hook.Active,
+		CreatedAt: hook.CreatedAt,
+	}
+}
+
+func (WebhookApi) deliveryToDTO(delivery *models.WebhookDelivery, full bool) WebhookDeliveryDTO {
+	deliveryDTO := WebhookDeliveryDTO{
+		ID:             delivery.ID,
+		Event:          delivery.Event,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		ResponseStatus: delivery.ResponseStatus,
+		ResponseBody:   delivery.ResponseBody,
+		Error:          delivery.Error,
+		CreatedAt:      delivery.CreatedAt,
+		DeliveredAt:    delivery.DeliveredAt,
+	}
+	if delivery.Status == constants.WebhookDeliveryPending {
+		deliveryDTO.NextAttemptAt = &delivery.NextAttemptAt
+	}
+	if full {
+		deliveryDTO.Payload = json.RawMessage(delivery.Payload)
+	}
+	return deliveryDTO
+}
+
+// getWebhook 获取路径中指定的项目 webhook，不存在时返回 404
+// Get the project webhook given in the path, answering 404 when it does not exist
+func (WebhookApi) getWebhook(c *app.RequestContext) *models.Webhook {
+	project := getProject(c)
+	if project == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return nil
+	}
+	hookID, err := strconv.Atoi(c.Param("webhook_id"))
+	if err != nil {
+		resps.BadRequest(c, resps.ParameterError)
+		return nil
+	}
+	hook, err := store.Webhook.GetByProject(project.ID, uint(hookID))
+	if err != nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return nil
+	}
+	return hook
+}
+
+// apply 校验请求并写入 webhook
+// Validate the request and apply it to the webhook
+func (WebhookApi) apply(c *app.RequestContext, hook *models.Webhook, req *WebhookReq) bool {
+	for _, event := range req.Events {
+		if !slices.Contains(constants.WebhookEvents, event) {
+			resps.BadRequest(c, "unknown event "+event)
+			return false
+		}
+	}
+	if req.Format == "" {
+		req.Format = constants.WebhookFormatJSON
+	}
+	if !slices.Contains(constants.WebhookFormats, req.Format) {
+		resps.BadRequest(c, "unknown format "+req.Format)
+		return false
+	}
+	hook.URL = req.URL
+	hook.Events = req.Events
+	hook.Format = req.Format
+	if req.Secret != "" {
+		hook.Secret = req.Secret
+	}
+	if req.Active != nil {
+		hook.Active = *req.Active
+	}
+	return true
+}
+
+// List 获取项目的 webhook
+// List the webhooks of the project
+func (WebhookApi) List(ctx context.Context, c *app.RequestContext) {
+	project := getProject(c)
+	if project == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	hooks, err := store.Webhook.ListByProject(project.ID)
+	if err != nil {
+		resps.DBError(c, err, "get webhooks error")
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"webhooks": func() (hookDTOs []WebhookDTO) {
+			for _, hook := range hooks {
+				hookDTOs = append(hookDTOs, Webhook.toDTO(&hook))
+			}
+			return
+		}(),
+	})
+}
+
+// Create 为项目创建 webhook，未提供密钥时生成一个并只在此时返回
+// Create a webhook for the project, generating a secret when none is given and returning it only now
+func (WebhookApi) Create(ctx context.Context, c *app.RequestContext) {
+	project := getProject(c)
+	if project == nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return
+	}
+	req := WebhookReq{}
+	if !bind(c, &req) {
+		return
+	}
+	hook := models.Webhook{ProjectID: project.ID, Active: true}
+	if !Webhook.apply(c, &hook, &req) {
+		return
+	}
+	if hook.Secret == "" {
+		secret, err := utils.Webhook.NewSecret()
+		if err != nil {
+			resps.InternalServerError(c, "create webhook secret error")
+			return
+		}
+		hook.Secret = secret
+	}
+	if err := store.Webhook.Create(&hook); err != nil {
+		resps.DBError(c, err, "create webhook error")
+		return
+	}
+	hookDTO := Webhook.toDTO(&hook)
+	hookDTO.Secret = hook.Secret
+	resps.Ok(c, resps.OK, map[string]any{
+		"webhook": hookDTO,
+	})
+}
+
+// Update 更新 webhook
+// Update a webhook
+func (WebhookApi) Update(ctx context.Context, c *app.RequestContext) {
+	hook := Webhook.getWebhook(c)
+	if hook == nil {
+		return
+	}
+	req := WebhookReq{}
+	if !bind(c, &req) {
+		return
+	}
+	if !Webhook.apply(c, hook, &req) {
+		return
+	}
+	if err := store.Webhook.Update(hook); err != nil {
+		resps.DBError(c, err, "update webhook error")
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"webhook": Webhook.toDTO(hook),
+	})
+}
+
+// Delete 删除 webhook 及其投递记录
+// Delete a webhook and its deliveries
+func (WebhookApi) Delete(ctx context.Context, c *app.RequestContext) {
+	hook := Webhook.getWebhook(c)
+	if hook == nil {
+		return
+	}
+	if err := store.Webhook.Delete(hook); err != nil {
+		resps.DBError(c, err, "delete webhook error")
+		return
+	}
+	resps.Ok(c, resps.OK)
+}
+
+// Deliveries 分页获取 webhook 的投递记录
+// List the deliveries of a webhook with pagination
+func (WebhookApi) Deliveries(ctx context.Context, c *app.RequestContext) {
+	hook := Webhook.getWebhook(c)
+	if hook == nil {
+		return
+	}
+	page, limit := utils.Ctx.GetPageLimit(c)
+	deliveries, total, err := store.Webhook.ListDeliveries(ctx, hook.ID, page, limit)
+	if err != nil {
+		resps.DBError(c, err, "get deliveries error")
+		return
+	}
+	utils.Ctx.SetPageHeaders(c, page, limit, total)
+	resps.Ok(c, resps.OK, map[string]any{
+		"deliveries": func() (deliveryDTOs []WebhookDeliveryDTO) {
+			for _, delivery := range deliveries {
+				deliveryDTOs = append(deliveryDTOs, Webhook.deliveryToDTO(&delivery, false))
+			}
+			return
+		}(),
+		"total": total,
+	})
+}
+
+// Delivery 获取投递记录及其请求体
+// Get a delivery with its request body
+func (WebhookApi) Delivery(ctx context.Context, c *app.RequestContext) {
+	delivery := Webhook.getDelivery(c)
+	if delivery == nil {
+		return
+	}
+	resps.Ok(c, resps.OK, map[string]any{
+		"delivery": Webhook.deliveryToDTO(delivery, true),
+	})
+}
+
+// Redeliver 以相同的请求体重新投递，创建一条新的投递记录
+// Send the same body again as a new delivery
+func (WebhookApi) Redeliver(ctx context.Context, c *app.RequestContext) {
+	delivery := Webhook.getDelivery(c)
+	if delivery == nil {
+		return
+	}
+	redelivery := models.WebhookDelivery{
+		WebhookID:     delivery.WebhookID,
+		Event:         delivery.Event,
+		Payload:       delivery.Payload,
+		Status:        constants.WebhookDeliveryPending,
+		NextAttemptAt: time.Now(),
+	}
+	if err := store.Webhook.CreateDeliveries([]models.WebhookDelivery{redelivery}); err != nil {
+		resps.DBError(c, err, "create delivery error")
+		return
+	}
+	task.WakeWebhooks()
+	resps.Ok(c, resps.OK)
+}
+
+func (WebhookApi) getDelivery(c *app.RequestContext) *models.WebhookDelivery {
+	hook := Webhook.getWebhook(c)
+	if hook == nil {
+		return nil
+	}
+	deliveryID, err := strconv.Atoi(c.Param("delivery_id"))
+	if err != nil {
+		resps.BadRequest(c, resps.ParameterError)
+		return nil
+	}
+	delivery, err := store.Webhook.GetDelivery(hook.ID, uint(deliveryID))
+	if err != nil {
+		resps.NotFound(c, resps.TargetNotFound)
+		return nil
+	}
+	return delivery
+}
+
+// webhookPayload 完整格式的 webhook 载荷
+// Webhook payload of the full format
+type webhookPayload struct {
+	Event     string         `json:"event"`
+	CreatedAt time.Time      `json:"created_at"`
+	Project   webhookProject `json:"project"`
+	Text      string         `json:"text"` // 便于阅读的摘要 Human readable summary
+	Data      map[string]any `json:"data"`
+}
+
+type webhookProject struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+// emit 为项目订阅了事件的 webhook 和所有者的通知渠道创建投递，失败只记录日志，不影响触发事件的请求
+// Create deliveries for the project's webhooks and the owner's notification channels subscribed to the event,
+// failures are only logged and never fail the triggering request
+func (WebhookApi) emit(ctx context.Context, project *models.Project, event string, text i18n.Message, data map[string]any) {
+	if project == nil || project.ID == 0 {
+		return
+	}
+	Notification.notifyProject(ctx, project, event, text)
+	hooks, err := store.Webhook.ListSubscribed(project.ID, event)
+	if err != nil {
+		utils.Log.Ctx(ctx).Warnf("failed to list webhooks of project %d: %v", project.ID, err)
+		return
+	}
+	if len(hooks) == 0 {
+		return
+	}
+	now := time.Now()
+	// 聊天消息使用所有者的语言，JSON 载荷供程序处理，始终为英文 Chat messages use the owner's language, JSON payloads are meant for programs and stay in English
+	chat := text.In(Notification.language(project.OwnerType, project.OwnerID))
+	deliveries := make([]models.WebhookDelivery, 0, len(hooks))
+	for _, hook := range hooks {
+		var body any
+		switch hook.Format {
+		case constants.WebhookFormatSlack:
+			body = map[string]any{"text": chat}
+		case constants.WebhookFormatFeishu:
+			body = map[string]any{"msg_type": "text", "content": map[string]any{"text": chat}}
+		default:
+			body = webhookPayload{
+				Event:     event,
+				CreatedAt: now,
+				Project:   webhookProject{ID: project.ID, Name: project.Name},
+				Text:      text.String(),
+				Data:      data,
+			}
+		}
+		payload, err := json.Marshal(body)
+		if err != nil {
+			utils.Log.Ctx(ctx).Warnf("failed to encode webhook payload: %v", err)
+			return
+		}
+		deliveries = append(deliveries, models.WebhookDelivery{
+			WebhookID:     hook.ID,
+			Event:         event,
+			Payload:       string(payload),
+			Status:        constants.WebhookDeliveryPending,
+			NextAttemptAt: now,
+		})
+	}
+	if err := store.Webhook.CreateDeliveries(deliveries); err != nil {
+		utils.Log.Ctx(ctx).Warnf("failed to queue %s webhooks of project %d: %v", event, project.ID, err)
+		return
+	}
+	task.WakeWebhooks()
+}
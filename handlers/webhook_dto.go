@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookDTO webhook 数据传输对象，密钥只在创建时返回
+// Webhook Data Transfer Object (DTO), the secret is only returned on creation
+type WebhookDTO struct {
+	ID        uint      `json:"id"`               // Webhook ID
+	URL       string    `json:"url"`              // 投递地址 Delivery URL
+	Events    []string  `json:"events"`           // 订阅的事件，为空时订阅全部 Subscribed events, all events when empty
+	Format    string    `json:"format"`           // 载荷格式 Payload format
+	Active    bool      `json:"active"`           // 是否启用 Whether enabled
+	Secret    string    `json:"secret,omitempty"` // 签名密钥 Signing secret
+	CreatedAt time.Time `json:"created_at"`       // 创建时间 Created time
+}
+
+// WebhookReq 创建或更新 webhook 的请求参数，更新时未提供密钥则保留原密钥
+// Request parameters to create or update a webhook, the secret is kept when not given on update
+type WebhookReq struct {
+	URL    string   `json:"url" binding:"required" validate:"required,url"` // 投递地址 Delivery URL
+	Secret string   `json:"secret"`                                         // 签名密钥，创建时为空则自动生成 Signing secret, generated when empty on creation
+	Events []string `json:"events"`                                         // 订阅的事件 Subscribed events
+	Format string   `json:"format"`                                         // 载荷格式，默认 json Payload format, json by default
+	Active *bool    `json:"active"`                                         // 是否启用，默认启用 Whether enabled, enabled by default
+}
+
+// WebhookDeliveryDTO webhook 投递记录数据传输对象
+// Webhook Delivery Data Transfer Object (DTO)
+type WebhookDeliveryDTO struct {
+	ID             uint            `json:"id"`                     // 投递ID Delivery ID
+	Event          string          `json:"event"`                  // 事件 Event
+	Status         string          `json:"status"`                 // 投递状态 Delivery status
+	Attempts       int             `json:"attempts"`               // 已尝试次数 Attempts made
+	NextAttemptAt  *time.Time      `json:"next_attempt_at"`        // 下次尝试时间，已结束时为空 Next attempt time, empty once finished
+	ResponseStatus int             `json:"response_status"`        // 最近一次的响应状态码 Status code of the latest response
+	ResponseBody   string          `json:"response_body"`          // 最近一次的响应体 Body of the latest response
+	Error          string          `json:"error"`                  // 最近一次的错误 Error of the latest attempt
+	Payload        json.RawMessage `json:"payload,omitempty"`      // 请求体 Request body
+	CreatedAt      time.Time       `json:"created_at"`             // 创建时间 Created time
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"` // 投递成功时间 Time of successful delivery
+}
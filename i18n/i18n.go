@@ -0,0 +1,187 @@
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// 支持的语言 Supported languages
+const (
+	English = "en"
+	Chinese = "zh-cn"
+)
+
+// userLanguageKey 请求上下文中已认证用户的语言
+// Language of the authenticated user in the request context
+const userLanguageKey = "language"
+
+// bundles 按语言索引的翻译，键为英文原文，英文没有翻译表
+// Translations indexed by language and keyed by the English text, English needs no bundle
+var bundles = map[string]map[string]string{
+	Chinese: zhCN,
+}
+
+// prefixes 以空格或冒号结尾的原文，后面拼接的内容原样保留，按长度降序
+// Texts ending in a space or colon whose appended content is kept as is, longest first
+var prefixes = map[string][]string{}
+
+func init() {
+	for lang, bundle := range bundles {
+		for key := range bundle {
+			if strings.HasSuffix(key, " ") {
+				prefixes[lang] = append(prefixes[lang], key)
+			}
+		}
+		sort.Slice(prefixes[lang], func(i, j int) bool { return len(prefixes[lang][i]) > len(prefixes[lang][j]) })
+	}
+}
+
+// Supported 语言是否受支持
+// Whether the language is supported
+func Supported(lang string) bool {
+	return lang == English || bundles[lang] != nil
+}
+
+// Normalize 将语言标签转换为支持的语言，如 zh-CN、zh_Hans 和 zh 都转换为 zh-cn，不支持时返回空串
+// Turn a language tag into a supported language, zh-CN, zh_Hans and zh all become zh-cn, empty when unsupported
+func Normalize(tag string) string {
+	tag = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+	if Supported(tag) {
+		return tag
+	}
+	// 只有一种中文翻译，繁体也使用它 There is a single Chinese translation, traditional Chinese uses it too
+	switch base, _, _ := strings.Cut(tag, "-"); base {
+	case "en":
+		return English
+	case "zh":
+		return Chinese
+	}
+	return ""
+}
+
+// Negotiate 按权重从 Accept-Language 中选出支持的语言，没有时返回空串
+// Pick the supported language with the highest weight from an Accept-Language header, empty when there is none
+func Negotiate(header string) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		// 相同权重时靠前的优先 Earlier tags win ties
+		if lang := Normalize(tag); lang != "" && q > bestQ {
+			best, bestQ = lang, q
+		}
+	}
+	return best
+}
+
+// Default 配置的默认语言，配置无效时为英文
+// The configured default language, English when the configuration is invalid
+func Default() string {
+	if lang := Normalize(config.Language); lang != "" {
+		return lang
+	}
+	return English
+}
+
+// SetUserLanguage 记录已认证用户的语言，请求没有 Accept-Language 时使用
+// Record the language of the authenticated user, used when the request has no Accept-Language
+func SetUserLanguage(c *app.RequestContext, lang string) {
+	if lang = Normalize(lang); lang != "" {
+		c.Set(userLanguageKey, lang)
+	}
+}
+
+// Language 请求使用的语言：Accept-Language 优先，其次是用户设置的语言，最后是默认语言
+// Language of the request: Accept-Language first, then the language set by the user, then the default
+func Language(c *app.RequestContext) string {
+	if lang := Negotiate(string(c.GetHeader("Accept-Language"))); lang != "" {
+		return lang
+	}
+	if lang := c.GetString(userLanguageKey); lang != "" {
+		return lang
+	}
+	return Default()
+}
+
+// T 将英文原文翻译为 lang，没有翻译时返回原文；"原文: 详情" 只翻译冒号前的部分，以空格结尾的原文翻译后拼接其余内容
+// Translate English text into lang, returning it unchanged without a translation; only the part before the colon of "text: detail" is translated,
+// and texts registered with a trailing space are translated with the rest appended
+func T(lang, text string) string {
+	bundle := bundles[lang]
+	if bundle == nil {
+		return text
+	}
+	if translated, ok := bundle[text]; ok {
+		return translated
+	}
+	if head, detail, ok := strings.Cut(text, ": "); ok {
+		if translated, ok := bundle[head]; ok {
+			return translated + separator(lang) + detail
+		}
+	}
+	for _, prefix := range prefixes[lang] {
+		if rest, ok := strings.CutPrefix(text, prefix); ok {
+			return bundle[prefix] + rest
+		}
+	}
+	return text
+}
+
+// Sprintf 翻译格式字符串后格式化，译文可以用 %[n]s 调整参数顺序
+// Translate the format and then format it, translations may reorder the arguments with %[n]s
+func Sprintf(lang, format string, args ...any) string {
+	return fmt.Sprintf(T(lang, format), args...)
+}
+
+// separator 原文和详情之间的分隔符 Separator between the text and its detail
+func separator(lang string) string {
+	if lang == Chinese {
+		return "："
+	}
+	return ": "
+}
+
+// Message 延迟翻译的消息，用于接收方语言在生成消息时还未知的场景，如通知
+// Message translated later, for cases where the language of the recipient is unknown when the message is built, such as notifications
+type Message struct {
+	format string
+	args   []any
+}
+
+// Msg 创建延迟翻译的消息 Create a message translated later
+func Msg(format string, args ...any) Message {
+	return Message{format: format, args: args}
+}
+
+// In 以 lang 表示消息，Message 类型的参数同样翻译
+// The message in lang, arguments of type Message are translated as well
+func (m Message) In(lang string) string {
+	if len(m.args) == 0 {
+		return T(lang, m.format)
+	}
+	args := make([]any, len(m.args))
+	for i, arg := range m.args {
+		if nested, ok := arg.(Message); ok {
+			arg = nested.In(lang)
+		}
+		args[i] = arg
+	}
+	return Sprintf(lang, m.format, args...)
+}
+
+// String 英文消息 The message in English
+func (m Message) String() string {
+	return m.In(English)
+}
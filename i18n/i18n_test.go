@@ -0,0 +1,87 @@
+package i18n
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	cases := map[string]string{
+		"":                             "",
+		"zh-CN,zh;q=0.9,en;q=0.8":      Chinese,
+		"en-US,en;q=0.9,zh-CN;q=0.8":   English,
+		"fr-FR,fr;q=0.9,zh-TW;q=0.5":   Chinese,
+		"fr, de;q=0.5":                 "",
+		"en;q=0.2, zh_Hans;q=0.7":      Chinese,
+		"zh;q=0, en;q=0.1":             English,
+		"*":                            "",
+		"en;q=abc, zh":                 Chinese,
+		"EN-gb":                        English,
+		"  zh-cn ;q=1.0 , en ;q=1.0  ": Chinese,
+	}
+	for header, want := range cases {
+		if got := Negotiate(header); got != want {
+			t.Errorf("Negotiate(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestT(t *testing.T) {
+	cases := []struct{ lang, text, want string }{
+		{Chinese, "Parameter error", "参数错误"},
+		{English, "Parameter error", "Parameter error"},
+		{"fr", "Parameter error", "Parameter error"},
+		{Chinese, "save file error: disk full", "保存文件失败：disk full"},
+		{Chinese, "unknown event push", "未知的事件 push"},
+		{Chinese, "no translation here", "no translation here"},
+		{Chinese, "no translation: here", "no translation: here"},
+	}
+	for _, c := range cases {
+		if got := T(c.lang, c.text); got != c.want {
+			t.Errorf("T(%q, %q) = %q, want %q", c.lang, c.text, got, c.want)
+		}
+	}
+}
+
+func TestMessage(t *testing.T) {
+	m := Msg("[%s] %s", "blog", Msg("deployment.failed"))
+	if got := m.String(); got != "[blog] deployment.failed" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := m.In(Chinese); got != "[blog] 部署失败" {
+		t.Errorf("In(zh-cn) = %q", got)
+	}
+	reordered := Msg("Build of %s for site %s failed: %s", "abc1234", "blog", "exit 1")
+	if got := reordered.In(Chinese); got != "站点 blog 构建 abc1234 失败：exit 1" {
+		t.Errorf("In(zh-cn) = %q", got)
+	}
+}
+
+// verbs 格式字符串中的格式化动词 Formatting verbs of a format string
+var verbs = regexp.MustCompile(`%(\[\d+\])?[a-z]`)
+
+// 译文必须与原文使用相同数量的参数，否则格式化结果会出现 %!
+// Translations must use as many arguments as the original, otherwise formatting shows %!
+func TestBundleVerbs(t *testing.T) {
+	for lang, bundle := range bundles {
+		for key, translated := range bundle {
+			found := verbs.FindAllString(key, -1)
+			if got := len(verbs.FindAllString(translated, -1)); got != len(found) {
+				t.Errorf("%s %q has %d verbs, want %d", lang, key, got, len(found))
+				continue
+			}
+			args := make([]any, len(found))
+			for i, verb := range found {
+				args[i] = "x"
+				if strings.HasSuffix(verb, "d") {
+					args[i] = 1
+				}
+			}
+			if out := fmt.Sprintf(translated, args...); strings.Contains(out, "%!") {
+				t.Errorf("%s %q formats as %q", lang, key, out)
+			}
+		}
+	}
+}
@@ -0,0 +1,490 @@
+package i18n
+
+// zhCN 简体中文翻译，以空格结尾的键是前缀，后面拼接的内容原样保留；格式字符串的译文可以用 %[n]s 调整参数顺序
+// Simplified Chinese translations, keys ending in a space are prefixes whose appended content is kept as is;
+// translations of format strings may reorder the arguments with %[n]s
+var zhCN = map[string]string{
+	// 通用 Common
+	"Parameter error":       "参数错误",
+	"Missing parameter":     "缺少参数",
+	"Target not found":      "目标不存在",
+	"Target already exists": "目标已存在",
+	"Validation failed":     "参数校验失败",
+	"The instance is read-only for maintenance, retry later": "实例正在维护，处于只读状态，请稍后重试",
+	"PermissionDenied":                                     "权限不足",
+	"Permission denied":                                    "权限不足",
+	"Too many requests, retry later":                       "请求过于频繁，请稍后重试",
+	"database unavailable":                                 "数据库不可用",
+	"Request body too large, at most %d bytes are allowed": "请求体过大，最多允许 %d 字节",
+
+	// 字段校验 Field validation
+	"is required":                    "不能为空",
+	"must be at least %d characters": "至少 %d 个字符",
+	"must be at most %d characters":  "最多 %d 个字符",
+	"must have at least %d items":    "至少 %d 项",
+	"must have at most %d items":     "最多 %d 项",
+	"must be at least %d":            "不能小于 %d",
+	"must be at most %d":             "不能大于 %d",
+	"may only contain lowercase letters, digits and single hyphens, and must start and end with a letter or digit": "只能包含小写字母、数字和单个连字符，且必须以字母或数字开头和结尾",
+	"is reserved":                           "是保留名称",
+	"must be a valid domain name":           "必须是合法的域名",
+	"must be a valid email address":         "必须是合法的邮箱地址",
+	"must be an absolute http or https URL": "必须是完整的 http 或 https 地址",
+	"must be one of %s":                     "必须是 %s 之一",
+
+	// 认证与会话 Authentication and sessions
+	"Login successful":                                                      "登录成功",
+	"Logout successful":                                                     "退出登录成功",
+	"Register successful":                                                   "注册成功",
+	"Username or password cannot be empty":                                  "用户名或密码不能为空",
+	"Username already exists":                                               "用户名已存在",
+	"User does not exist":                                                   "用户不存在",
+	"Incorrect password":                                                    "密码错误",
+	"Password complexity is too low":                                        "密码复杂度过低",
+	"Password not set, please use another login method":                     "未设置密码，请使用其他登录方式",
+	"Password reset successful":                                             "密码重置成功",
+	"Captcha verification failed":                                           "人机验证失败",
+	"Account is temporarily locked after too many failed logins":            "登录失败次数过多，账号已被临时锁定",
+	"Account is disabled":                                                   "账号已被停用",
+	"Too many failed logins, retry later":                                   "登录失败次数过多，请稍后重试",
+	"Failed to check login attempts":                                        "检查登录失败记录失败",
+	"Failed to unlock user":                                                 "解除用户锁定失败",
+	"Invalid token":                                                         "令牌无效",
+	"invalid token":                                                         "令牌无效",
+	"Refresh token not found":                                               "刷新令牌不存在",
+	"Refresh token expired or invalid":                                      "刷新令牌已过期或无效",
+	"Token scope does not allow this request":                               "令牌的权限范围不允许该请求",
+	"Deploy key does not allow this request":                                "部署密钥不允许该请求",
+	"Impersonation session does not allow this request":                     "代为登录的会话不允许该请求",
+	"CSRF token missing or invalid":                                         "CSRF 令牌缺失或无效",
+	"Failed to create token":                                                "创建令牌失败",
+	"Failed to create refresh token":                                        "创建刷新令牌失败",
+	"Failed to create session":                                              "创建会话失败",
+	"Failed to create login state":                                          "创建登录状态失败",
+	"Invalid or expired login state":                                        "登录状态无效或已过期",
+	"Failed to get sessions":                                                "获取会话失败",
+	"Failed to revoke session":                                              "注销会话失败",
+	"Failed to revoke sessions":                                             "注销会话失败",
+	"Failed to get tokens":                                                  "获取令牌失败",
+	"Failed to update token":                                                "更新令牌失败",
+	"Failed to delete token":                                                "删除令牌失败",
+	"Failed to get deploy keys":                                             "获取部署密钥失败",
+	"Failed to create deploy key":                                           "创建部署密钥失败",
+	"Failed to delete deploy key":                                           "删除部署密钥失败",
+	"name and scopes are required":                                          "名称和权限范围不能为空",
+	"scopes must not be empty":                                              "权限范围不能为空",
+	"impersonation sessions cannot have the admin scope":                    "代为登录的会话不能拥有管理员权限",
+	"cannot impersonate yourself":                                           "不能代为登录自己的账号",
+	"cannot impersonate another administrator":                              "不能代为登录其他管理员的账号",
+	"duration must be between 1 and %d seconds":                             "时长必须在 1 到 %d 秒之间",
+	"failed to issue access ticket":                                         "签发访问凭证失败",
+	"Failed to hash password":                                               "密码加密失败",
+	"Failed to create user":                                                 "创建用户失败",
+	"Failed to update user":                                                 "更新用户失败",
+	"Failed to get users":                                                   "获取用户失败",
+	"unsupported language, use en or zh-cn":                                 "不支持的语言，请使用 en 或 zh-cn",
+	"Registration requires an invitation":                                   "注册需要邀请",
+	"This invitation is only for signing up":                                "该邀请只能用于注册",
+	"This invitation is for another email address":                          "该邀请属于其他邮箱地址",
+	"Invalid or expired invitation":                                         "邀请无效或已过期",
+	"invalid or expired invitation":                                         "邀请无效或已过期",
+	"Failed to create invitation":                                           "创建邀请失败",
+	"Failed to get invitations":                                             "获取邀请失败",
+	"Failed to revoke invitation":                                           "撤销邀请失败",
+	"Failed to accept invitation":                                           "接受邀请失败",
+	"You are already a member of this organization":                         "你已经是该组织的成员",
+	"max_uses must be between 1 and %d":                                     "max_uses 必须在 1 到 %d 之间",
+	"expires_in must be between 1 and %d seconds":                           "expires_in 必须在 1 到 %d 秒之间",
+	"invalid email address":                                                 "邮箱地址无效",
+	"Email sending is not enabled":                                          "未启用邮件发送",
+	"Email sending is not enabled, please contact an administrator":         "未启用邮件发送，请联系管理员",
+	"email sending is disabled on this instance":                            "该实例未启用邮件发送",
+	"Email is already verified":                                             "邮箱已验证",
+	"Email is not verified, please check your inbox":                        "邮箱未验证，请查收验证邮件",
+	"Email verified":                                                        "邮箱验证成功",
+	"Verification email sent":                                               "验证邮件已发送",
+	"No email address is set":                                               "未设置邮箱地址",
+	"Failed to save verification":                                           "保存验证信息失败",
+	"Failed to issue verification token":                                    "签发验证令牌失败",
+	"Failed to issue reset token":                                           "签发重置令牌失败",
+	"Invalid or expired verification link":                                  "验证链接无效或已过期",
+	"Invalid or expired reset link":                                         "重置链接无效或已过期",
+	"invalid or expired token":                                              "令牌无效或已过期",
+	"Failed to export personal data":                                        "导出个人数据失败",
+	"Account deletion was not requested":                                    "未申请删除账号",
+	"Failed to schedule deletion":                                           "安排删除账号失败",
+	"Failed to cancel deletion":                                             "取消删除账号失败",
+	"Failed to get deletion status":                                         "获取删除状态失败",
+	"confirm must be the username":                                          "confirm 必须为用户名",
+	"Add another owner to or delete the organizations you solely own first": "请先为你唯一拥有的组织添加其他所有者或删除这些组织",
+	"Transfer your projects or set delete_projects to move them to the trash with the account": "请先转让你的项目，或设置 delete_projects 将项目随账号移入回收站",
+
+	// 两步验证 Two-factor authentication
+	"Two-factor authentication required":           "需要两步验证",
+	"Two-factor authentication is already enabled": "两步验证已启用",
+	"Two-factor authentication is not enabled":     "两步验证未启用",
+	"Failed to get two-factor status":              "获取两步验证状态失败",
+	"Failed to enable two-factor authentication":   "启用两步验证失败",
+	"Failed to disable two-factor authentication":  "关闭两步验证失败",
+	"Failed to generate secret":                    "生成密钥失败",
+	"Failed to save secret":                        "保存密钥失败",
+	"Failed to generate recovery codes":            "生成恢复码失败",
+	"Failed to save recovery codes":                "保存恢复码失败",
+	"Failed to verify code":                        "校验验证码失败",
+	"Incorrect code":                               "验证码错误",
+	"Invalid or expired challenge":                 "验证请求无效或已过期",
+	"Start the enrollment first":                   "请先开始设置两步验证",
+	"not a two-factor challenge":                   "不是两步验证请求",
+
+	// 第三方登录 Identity providers
+	"Provider is unavailable":                         "登录提供方不可用",
+	"Provider returned an error: ":                    "登录提供方返回错误：",
+	"Failed to get providers":                         "获取登录提供方失败",
+	"Failed to create provider":                       "创建登录提供方失败",
+	"Failed to update provider":                       "更新登录提供方失败",
+	"Failed to delete provider":                       "删除登录提供方失败",
+	"Failed to verify provider login":                 "校验提供方登录失败",
+	"Failed to verify directory login":                "校验目录登录失败",
+	"Failed to get identity":                          "获取身份失败",
+	"Failed to get identities":                        "获取身份失败",
+	"Failed to link identity":                         "关联身份失败",
+	"Failed to unlink identity":                       "解除关联身份失败",
+	"This identity is already linked to another user": "该身份已关联到其他用户",
+	"Set a password or link another provider before unlinking the last one":                                "解除最后一个关联前请先设置密码或关联其他登录方式",
+	"Your groups are not allowed to sign in":                                                               "你所在的用户组不允许登录",
+	"An account with this email already exists, sign in and link this provider from your account settings": "该邮箱已有账号，请登录后在账号设置中关联该登录方式",
+	"invalid directory credentials":                                                                        "目录账号或密码错误",
+	"no directory accepted the credentials":                                                                "没有目录接受该账号和密码",
+	"unknown provider ":                                                                                    "未知的提供方 ",
+
+	// 组织、团队与转让 Organizations, teams and transfers
+	"organization name already exists":                                              "组织名已存在",
+	"organization must keep at least one owner":                                     "组织至少需要保留一名所有者",
+	"user is not a member of the organization":                                      "用户不是该组织的成员",
+	"The resource does not match the precondition":                                  "资源不满足请求的前提条件",
+	"name must be a slug that is not reserved":                                      "名称只能包含小写字母、数字和连字符，且不能是保留名称",
+	"Failed to get resource":                                                        "获取资源失败",
+	"Failed to save user":                                                           "保存用户失败",
+	"Failed to save organization":                                                   "保存组织失败",
+	"Failed to save project":                                                        "保存项目失败",
+	"user %s does not exist":                                                        "用户 %s 不存在",
+	"organization %s does not exist":                                                "组织 %s 不存在",
+	"owners are required to create an organization":                                 "创建组织时必须指定所有者",
+	"the owner of an existing project changes through a project transfer":           "已有项目的所有者只能通过项目转移修改",
+	"cannot change the role of the system administrator or yourself":                "不能修改系统管理员或自己的角色",
+	"cannot disable the system administrator or yourself":                           "不能停用系统管理员或自己",
+	"owner_id requires owner_type":                                                  "owner_id 需要同时设置 owner_type",
+	"user belongs to another tenant":                                                "用户属于其他租户",
+	"the new owner belongs to another tenant":                                       "新的所有者属于其他租户",
+	"user %s belongs to another tenant":                                             "用户 %s 属于其他租户",
+	"primary color must be a hex color such as #3b82f6":                             "主题色必须是十六进制颜色，例如 #3b82f6",
+	"invalid domain name":                                                           "域名无效",
+	"the base domain of a tenant must differ from the base domains of the instance": "租户的基础域名不能与实例的基础域名相同",
+	"get tenant error":                                                              "获取租户失败",
+	"list tenants error":                                                            "获取租户列表失败",
+	"create tenant error":                                                           "创建租户失败",
+	"update tenant error":                                                           "更新租户失败",
+	"delete tenant error":                                                           "删除租户失败",
+	"assign tenant error":                                                           "分配租户失败",
+	"move the users and organizations out of the tenant first":                      "请先将用户和组织移出该租户",
+	"the user or organization does not belong to this tenant":                       "该用户或组织不属于此租户",
+	"invalid role":                                                                  "角色无效",
+	"role is required":                                                              "角色不能为空",
+	"role requires an organization":                                                 "角色需要指定组织",
+	"Failed to get organizations":                                                   "获取组织失败",
+	"get members error":                                                             "获取成员失败",
+	"team name is empty or already exists":                                          "团队名为空或已存在",
+	"create team error":                                                             "创建团队失败",
+	"update team error":                                                             "更新团队失败",
+	"delete team error":                                                             "删除团队失败",
+	"get teams error":                                                               "获取团队失败",
+	"get team members error":                                                        "获取团队成员失败",
+	"add team member error":                                                         "添加团队成员失败",
+	"remove team member error":                                                      "移除团队成员失败",
+	"Only organization projects can be granted to teams":                            "只有组织的项目可以授权给团队",
+	"owner type must be user or organization":                                       "所有者类型必须为用户或组织",
+	"The project already belongs to this owner":                                     "项目已属于该所有者",
+	"Failed to request transfer":                                                    "申请转让失败",
+	"Failed to get transfer":                                                        "获取转让失败",
+	"Failed to list transfers":                                                      "获取转让列表失败",
+	"Failed to accept transfer":                                                     "接受转让失败",
+	"Failed to decline transfer":                                                    "拒绝转让失败",
+	"Failed to cancel transfer":                                                     "取消转让失败",
+	"invalid or expired transfer":                                                   "转让无效或已过期",
+
+	// 项目与站点 Projects and sites
+	"Failed to get projects":                               "获取项目失败",
+	"Failed to export project":                             "导出项目失败",
+	"Failed to get labels":                                 "获取标签失败",
+	"Failed to update labels":                              "更新标签失败",
+	"invalid label selector %q":                            "标签选择器 %q 无效",
+	"name must not be empty":                               "名称不能为空",
+	"Site not found":                                       "站点不存在",
+	"site not found":                                       "站点不存在",
+	"Failed to get sites":                                  "获取站点失败",
+	"Failed to set primary site":                           "设置主站点失败",
+	"primary_site_id must be a site of the project":        "primary_site_id 必须是该项目的站点",
+	"sub_domain is already used":                           "子域名已被使用",
+	"Failed to set directory listing":                      "设置目录列表失败",
+	"Failed to set immutable assets":                       "设置不可变资源缓存失败",
+	"Failed to set protection":                             "设置部署保护失败",
+	"host does not belong to this site":                    "该主机名不属于此站点",
+	"only project members may visit this site":             "只有项目成员可以访问该站点",
+	"an access password is required for the password mode": "密码模式需要设置访问密码",
+	"robots_txt is required by the custom robots policy":   "自定义 robots 策略需要填写 robots_txt",
+	"invalid template image":                               "模板图片无效",
+	"image is too large":                                   "图片过大",
+	"image dimensions are too large":                       "图片尺寸过大",
+	"save template file error":                             "保存模板文件失败",
+	"invalid avatar image":                                 "头像图片无效",
+	"save avatar file error":                               "保存头像文件失败",
+	"display_name is required":                             "display_name 不能为空",
+
+	// 域名 Domains
+	"Domain %s is bound to another site":                     "域名 %s 已绑定到其他站点",
+	"Domain is already bound":                                "域名已被绑定",
+	"Domains under the base domain are served as subdomains": "基础域名下的域名以子域名提供服务",
+	"Failed to bind domain":                                  "绑定域名失败",
+	"Failed to unbind domain":                                "解绑域名失败",
+	"Failed to get domain":                                   "获取域名失败",
+	"Failed to get domains":                                  "获取域名失败",
+	"TXT record not found or does not match":                 "TXT 记录不存在或不匹配",
+	"resolve error":                                          "域名解析失败",
+
+	// 部署与版本 Deployments and releases
+	"deployment queued":                                                "部署已加入队列",
+	"site has no active release":                                       "站点没有生效的版本",
+	"no earlier release to roll back to":                               "没有可以回滚到的更早版本",
+	"cannot delete the active release, activate another release first": "不能删除生效中的版本，请先激活其他版本",
+	"release is active":                                                "版本正在生效",
+	"release is %s":                                                    "版本状态为 %s",
+	"release is %s, not quarantined":                                   "版本状态为 %s，未被隔离",
+	"release is %s, not awaiting approval":                             "版本状态为 %s，未在等待批准",
+	"release is no longer awaiting approval":                           "版本已不在等待批准",
+	"tag latest is reserved":                                           "标签 latest 为保留标签",
+	"create release record error":                                      "创建版本记录失败",
+	"update release error":                                             "更新版本失败",
+	"update latest release error":                                      "更新最新版本失败",
+	"delete release error":                                             "删除版本失败",
+	"get releases error":                                               "获取版本失败",
+	"get deployments error":                                            "获取部署失败",
+	"get projects error":                                               "获取项目失败",
+	"create captcha error":                                             "生成验证码失败",
+	"render feed error":                                                "生成订阅源失败",
+	"draft not found":                                                  "草稿不存在",
+	"draft already exists":                                             "草稿已存在",
+	"get draft error":                                                  "获取草稿失败",
+	"create draft error":                                               "创建草稿失败",
+	"delete draft error":                                               "丢弃草稿失败",
+	"publish draft error":                                              "发布草稿失败",
+	"the draft is empty":                                               "草稿为空",
+	"the draft may hold at most %d MiB":                                "草稿最多只能容纳 %d MiB",
+	"read release files error":                                         "读取版本文件失败",
+	"schedule release error":                                           "安排版本上线失败",
+	"publish_at must be a future RFC 3339 time":                        "publish_at 必须是将来的 RFC 3339 时间",
+	"expires_at must be a future RFC 3339 time":                        "expires_at 必须是将来的 RFC 3339 时间",
+	"expires_at must be after publish_at":                              "expires_at 必须晚于 publish_at",
+	"expires_at must be in the future":                                 "expires_at 必须是将来的时间",
+	"expire_action requires expires_at":                                "expire_action 需要同时设置 expires_at",
+	"expire_action must be one of %v":                                  "expire_action 必须是 %v 之一",
+	"preview deployments cannot be scheduled, use ttl instead":         "预览部署不能定时上线，请使用 ttl",
+	"preview deployments require a site subdomain and serve.base-domain":     "预览部署需要站点子域名和 serve.base-domain",
+	"preview name is too long for this site":                                 "对该站点来说预览名称过长",
+	"ttl must be between 0 and %d seconds":                                   "ttl 必须在 0 到 %d 秒之间",
+	"get previews error":                                                     "获取预览失败",
+	"save preview error":                                                     "保存预览失败",
+	"delete preview error":                                                   "删除预览失败",
+	"file is not a zip or zip file is invalid":                               "文件不是 zip 或 zip 文件无效",
+	"archive must be a zip, tar or tar.gz file containing at least one file": "压缩包必须是至少包含一个文件的 zip、tar 或 tar.gz 文件",
+	"archive content exceeds the size limit":                                 "压缩包内容超出大小限制",
+	"exactly one of file and repo_url is required":                           "file 和 repo_url 必须且只能提供一个",
+	"initial deployment failed":                                              "首次部署失败",
+	"upload contains files not allowed by the content policy":                "上传内容包含内容策略不允许的文件",
+	"uploaded files do not match the checksums":                              "上传的文件与校验和不一致",
+	"archive entry names must not contain control characters":                "压缩包条目名不能包含控制字符",
+	"archive entries must not use absolute paths":                            "压缩包条目不能使用绝对路径",
+	"archive entries must not contain .. segments":                           "压缩包条目不能包含 .. 路径段",
+	"archive symlinks must not point outside the site root":                  "压缩包中的符号链接不能指向站点根目录之外",
+	"the archive has too many files":                                         "压缩包中的文件过多",
+	"an archive file is too large once uncompressed":                         "压缩包中有文件解压后过大",
+	"the archive is too large once uncompressed":                             "压缩包解压后过大",
+	"the archive is not safe to deploy":                                      "压缩包无法安全部署",
+	"quota exceeded":                                                         "超出配额",
+	"invalid manifest":                                                       "清单无效",
+	"save file error":                                                        "保存文件失败",
+	"look up files error":                                                    "查找文件失败",
+	"invalid sha256 ":                                                        "sha256 无效 ",
+	"content does not match sha256 ":                                         "内容与 sha256 不匹配 ",
+	"sha256 must be a hex SHA-256 digest":                                    "sha256 必须是十六进制的 SHA-256 摘要",
+
+	// 分片上传 Chunked uploads
+	"create upload error":                             "创建上传失败",
+	"delete upload error":                             "删除上传失败",
+	"upload session not found":                        "上传会话不存在",
+	"save chunk error":                                "保存分片失败",
+	"get upload chunks error":                         "获取上传分片失败",
+	"assemble upload error":                           "合并上传失败",
+	"size must be at least 1 byte":                    "大小至少为 1 字节",
+	"index must be between 0 and %d":                  "index 必须在 0 到 %d 之间",
+	"chunk %d must be %d bytes, got %d":               "分片 %d 必须为 %d 字节，实际为 %d 字节",
+	"chunk %d checksum mismatch":                      "分片 %d 校验和不匹配",
+	"missing chunks: %v":                              "缺少分片：%v",
+	"the upload has %d bytes, at most %d are allowed": "上传内容有 %d 字节，最多允许 %d 字节",
+
+	// 缓存清除 Purges
+	"between 1 and %d paths are required":                         "需要 1 到 %d 个路径",
+	"invalid path, wildcards are only allowed as a trailing /*: ": "路径无效，通配符只能作为结尾的 /*：",
+	"no matching paths in the active release":                     "生效版本中没有匹配的路径",
+	"prefix purges require confirm to equal the site name":        "按前缀清除需要 confirm 等于站点名称",
+	"purge limit reached, try again later":                        "已达到清除次数上限，请稍后重试",
+	"create purge record error":                                   "创建清除记录失败",
+	"get purges error":                                            "获取清除记录失败",
+	"count purges error":                                          "统计清除记录失败",
+
+	// Git 推送部署 Git push deployments
+	"invalid branch name":                          "分支名无效",
+	"unknown build preset ":                        "未知的构建预设 ",
+	"invalid build image":                          "构建镜像无效",
+	"the build sandbox needs a build image":        "构建沙箱需要指定构建镜像",
+	"invalid commit":                               "提交无效",
+	"invalid signature":                            "签名无效",
+	"branch ignored":                               "已忽略该分支",
+	"branch deletion ignored":                      "已忽略分支删除",
+	"event ignored":                                "已忽略该事件",
+	"save git integration error":                   "保存 Git 集成失败",
+	"delete git integration error":                 "删除 Git 集成失败",
+	"create push secret error":                     "创建推送密钥失败",
+	"repository URL must be a public https URL":    "仓库地址必须是公开的 https 地址",
+	"build commands are disabled on this instance": "该实例已禁用构建命令",
+	"output directory is empty":                    "输出目录为空",
+	"output directory must be an existing directory inside the repository": "输出目录必须是仓库中已存在的目录",
+	"invalid runner label ":          "执行器标签无效 ",
+	"get runners error":              "获取执行器失败",
+	"create runner error":            "创建执行器失败",
+	"delete runner error":            "删除执行器失败",
+	"register runner error":          "注册执行器失败",
+	"record heartbeat error":         "记录心跳失败",
+	"claim build error":              "领取构建失败",
+	"save build output error":        "保存构建输出失败",
+	"save build result error":        "保存构建结果失败",
+	"finish build error":             "结束构建失败",
+	"the build is no longer running": "构建已不在执行中",
+	"file is required":               "缺少文件",
+	"the runner stopped responding":  "执行器已停止响应",
+
+	// 配额、流量与内容策略 Quotas, transfer and content policies
+	"get quota error":    "获取配额失败",
+	"save quota error":   "保存配额失败",
+	"delete quota error": "删除配额失败",
+	"check quota error":  "检查配额失败",
+	"quota limits must not be negative, use 0 for unlimited":    "配额不能为负数，不限制请使用 0",
+	"limits must not be negative, use 0 for unlimited":          "限制不能为负数，不限制请使用 0",
+	"max_upload_size must not be negative, use 0 for unlimited": "max_upload_size 不能为负数，不限制请使用 0",
+	"save transfer limits error":                                "保存流量限制失败",
+	"get content policy error":                                  "获取内容策略失败",
+	"save content policy error":                                 "保存内容策略失败",
+	"delete content policy error":                               "删除内容策略失败",
+
+	// Webhook 与通知 Webhooks and notifications
+	"create webhook error":                         "创建 webhook 失败",
+	"update webhook error":                         "更新 webhook 失败",
+	"delete webhook error":                         "删除 webhook 失败",
+	"zone_id is required for cloudflare":           "cloudflare 需要填写 zone_id",
+	"url is required for webhook":                  "webhook 需要填写 url",
+	"token is required":                            "令牌不能为空",
+	"get CDN integrations error":                   "获取 CDN 集成失败",
+	"create CDN integration error":                 "创建 CDN 集成失败",
+	"update CDN integration error":                 "更新 CDN 集成失败",
+	"delete CDN integration error":                 "删除 CDN 集成失败",
+	"update retention error":                       "修改版本保留策略失败",
+	"pin release error":                            "固定版本失败",
+	"at most %d paths are allowed":                 "最多允许 %d 个路径",
+	"the project has no enabled CDN integration":   "项目没有已启用的 CDN 集成",
+	"get webhooks error":                           "获取 webhook 失败",
+	"create webhook secret error":                  "创建 webhook 密钥失败",
+	"get deliveries error":                         "获取投递记录失败",
+	"create delivery error":                        "创建投递失败",
+	"target must be an absolute http or https URL": "target 必须是完整的 http 或 https 地址",
+	"target must be an email address":              "target 必须是邮箱地址",
+	"secret must be the bot token":                 "secret 必须为机器人令牌",
+	"webhook target address is not allowed":        "不允许投递到该 webhook 地址",
+	"create notification channel error":            "创建通知渠道失败",
+	"update notification channel error":            "更新通知渠道失败",
+	"delete notification channel error":            "删除通知渠道失败",
+	"get notification channels error":              "获取通知渠道失败",
+	"kind cannot be changed":                       "渠道类型不能修改",
+	"unknown kind ":                                "未知的渠道类型 ",
+	"unknown event ":                               "未知的事件 ",
+	"unknown format ":                              "未知的格式 ",
+
+	// 任务、回收站与管理 Jobs, trash and administration
+	"create job error":                            "创建任务失败",
+	"get job error":                               "获取任务失败",
+	"get jobs error":                              "获取任务失败",
+	"cancel job error":                            "取消任务失败",
+	"retry job error":                             "重试任务失败",
+	"only pending jobs can be canceled":           "只能取消等待中的任务",
+	"only failed or canceled jobs can be retried": "只能重试失败或已取消的任务",
+	"unknown job status ":                         "未知的任务状态 ",
+	"get scheduled tasks error":                   "获取定时任务失败",
+	"get scheduled task error":                    "获取定时任务失败",
+	"run scheduled task error":                    "运行定时任务失败",
+	"the scheduled task is already running":       "定时任务正在运行",
+	"get trash error":                             "获取回收站失败",
+	"restore project error":                       "恢复项目失败",
+	"restore site error":                          "恢复站点失败",
+	"purge project error":                         "彻底删除项目失败",
+	"purge site error":                            "彻底删除站点失败",
+	"get settings error":                          "获取配置失败",
+	"save setting error":                          "保存配置失败",
+	"delete setting error":                        "删除配置失败",
+	"get audit logs error":                        "获取审计日志失败",
+	"verify audit logs error":                     "校验审计日志失败",
+	"get stats error":                             "获取统计失败",
+	"Failed to get analytics":                     "获取访问统计失败",
+	"search error":                                "搜索失败",
+	"unknown search type ":                        "未知的搜索类型 ",
+	"invalid status":                              "状态无效",
+	"status must be a status class such as 4xx":   "status 必须是 4xx 这样的状态码类别",
+	"status must be a status code such as 404 or a status class such as 4xx": "status 必须是 404 这样的状态码或 4xx 这样的状态码类别",
+	"since and until must be RFC 3339 times":                                 "since 和 until 必须是 RFC 3339 时间",
+	"release_id requires site_id":                                            "release_id 需要同时提供 site_id",
+	"reason is required and at most 255 bytes":                               "reason 不能为空且最多 255 字节",
+	"invalid return address":                                                 "返回地址无效",
+
+	// 通知事件标题 Titles of notification events
+	"deployment.succeeded":         "部署成功",
+	"deployment.failed":            "部署失败",
+	"deployment.rolled_back":       "已回滚",
+	"deployment.expired":           "版本已到期",
+	"deployment.quarantined":       "版本已隔离",
+	"deployment.awaiting_approval": "部署等待批准",
+	"domain.verified":              "域名已验证",
+	"transfer.soft_limit":          "流量超出软限制",
+	"transfer.hard_limit":          "流量超出硬限制",
+	"certificate.expiring":         "证书即将到期",
+	"deployment.link_warnings":     "链接检查发现问题",
+
+	// 通知内容 Notification content
+	"Spage test notification":                        "Spage 测试通知",
+	"This is a test of the notification channel %s.": "这是通知渠道 %s 的测试消息。",
+	"Storage quota almost used up":                   "存储配额即将用完",
+	"%s of %s of storage are used (%d%%), deployments adding content fail once the quota is reached": "已使用 %[2]s 存储中的 %[1]s（%[3]d%%），达到配额后增加内容的部署将会失败",
+	"Site %s deployed %s (release %d)":                                                            "站点 %s 已部署 %s（版本 %d）",
+	"Site %s deployed %s to preview %s: %s":                                                       "站点 %s 已将 %s 部署到预览 %s：%s",
+	"Site %s rolled back to %s (release %d)":                                                      "站点 %s 已回滚到 %s（版本 %d）",
+	"Deployment of %s to site %s failed: %s":                                                      "%[1]s 部署到站点 %[2]s 失败：%[3]s",
+	"Build of %s for site %s failed: %s":                                                          "站点 %[2]s 构建 %[1]s 失败：%[3]s",
+	"Release %s of site %s was quarantined for review, %d suspicious files found":                 "站点 %[2]s 的版本 %[1]s 发现 %[3]d 个可疑文件，已隔离等待审核",
+	"Release %s of site %s is waiting for a maintainer's approval":                                "站点 %[2]s 的版本 %[1]s 正在等待维护者批准",
+	"Link check of %s on site %s found %d broken links, %d missing assets and %d oversized pages": "站点 %[2]s 的版本 %[1]s 链接检查发现 %[3]d 个失效链接、%[4]d 个缺失资源和 %[5]d 个过大的页面",
+	"Release %s of site %s expired, the site is offline":                                          "站点 %[2]s 的版本 %[1]s 已到期，站点已下线",
+	"Release %s of site %s expired, rolled back to %s (release %d)":                               "站点 %[2]s 的版本 %[1]s 已到期，已回滚到 %[3]s（版本 %[4]d）",
+	"Domain %s verified": "域名 %s 已验证",
+	"Site %s served %s this month, beyond its soft limit of %s":                                "站点 %s 本月流量 %s，超出软限制 %s",
+	"Site %s served %s this month, beyond its hard limit of %s, it is %s until the next month": "站点 %s 本月流量 %s，超出硬限制 %s，下月之前将被%s",
+	"suspended": "暂停服务",
+	"throttled": "限速",
+	"The certificate of %s (site %s) expires on %s and was not renewed yet, check that the domain still points to this instance": "%[1]s（站点 %[2]s）的证书将于 %[3]s 到期且尚未续期，请检查域名是否仍指向本实例",
+	"The certificate of %s (site %s) expired on %s, check that the domain still points to this instance":                         "%[1]s（站点 %[2]s）的证书已于 %[3]s 到期，请检查域名是否仍指向本实例",
+}
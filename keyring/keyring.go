@@ -0,0 +1,164 @@
+package keyring
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/LiteyukiStudio/spage/config"
+)
+
+// prefix 密文的前缀，完整格式为 enc:<密钥ID>:<base64(随机数|密文)>
+// Prefix of ciphertexts, the full format is enc:<key ID>:<base64(nonce|ciphertext)>
+const prefix = "enc:"
+
+// keySize AES-256 的密钥字节数 Key size of AES-256 in bytes
+const keySize = 32
+
+var (
+	// ErrUnknownKey 值由未配置的密钥加密 The value was encrypted with a key that is not configured
+	ErrUnknownKey = errors.New("encrypted with an unknown key")
+	// ErrMalformed 值以密文前缀开头但无法解密 The value starts with the ciphertext prefix but cannot be decrypted
+	ErrMalformed = errors.New("malformed ciphertext")
+)
+
+// ring 已加载的密钥，active 为空时不加密
+// Loaded keys, nothing is encrypted while active is empty
+type ring struct {
+	active string
+	keys   map[string]cipher.AEAD
+}
+
+var current atomic.Pointer[ring]
+
+// Init 加载 encryption.keys，配置了 KMS 时先通过 KMS 解密每个密钥；未配置密钥时不加密也不解密
+// Load encryption.keys, decrypting each key through the KMS first when one is configured; nothing is encrypted or decrypted without keys
+func Init() error {
+	if len(config.EncryptionKeys) == 0 && config.EncryptionActiveKey == "" {
+		current.Store(nil)
+		return nil
+	}
+	var kms KMS
+	if config.EncryptionKMSProvider != "" {
+		var err error
+		if kms, err = newKMS(config.EncryptionKMSProvider); err != nil {
+			return err
+		}
+	}
+	keys := make(map[string][]byte, len(config.EncryptionKeys))
+	for id, value := range config.EncryptionKeys {
+		var key []byte
+		var err error
+		if kms != nil {
+			key, err = kms.Decrypt(context.Background(), value)
+		} else {
+			key, err = base64.StdEncoding.DecodeString(value)
+		}
+		if err != nil {
+			return fmt.Errorf("load encryption key %q: %w", id, err)
+		}
+		keys[id] = key
+	}
+	r, err := newRing(config.EncryptionActiveKey, keys)
+	if err != nil {
+		return err
+	}
+	current.Store(r)
+	return nil
+}
+
+func newRing(active string, keys map[string][]byte) (*ring, error) {
+	r := &ring{active: active, keys: make(map[string]cipher.AEAD, len(keys))}
+	for id, key := range keys {
+		if id == "" || strings.ContainsFunc(id, func(c rune) bool {
+			return !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-')
+		}) {
+			return nil, fmt.Errorf("invalid encryption key id %q, only letters, digits and - are allowed", id)
+		}
+		if len(key) != keySize {
+			return nil, fmt.Errorf("encryption key %q must be %d bytes, got %d", id, keySize, len(key))
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		if r.keys[id], err = cipher.NewGCM(block); err != nil {
+			return nil, err
+		}
+	}
+	if active != "" && r.keys[active] == nil {
+		return nil, fmt.Errorf("active encryption key %q is not configured", active)
+	}
+	return r, nil
+}
+
+// Enabled 是否加密新写入的值
+// Whether values are encrypted as they are written
+func Enabled() bool {
+	r := current.Load()
+	return r != nil && r.active != ""
+}
+
+// ActivePrefix 当前密钥加密的值的前缀，未开启加密时为空
+// Prefix of values encrypted with the active key, empty while encryption is off
+func ActivePrefix() string {
+	if r := current.Load(); r != nil && r.active != "" {
+		return prefix + r.active + ":"
+	}
+	return ""
+}
+
+// Encrypt 用当前密钥加密 plaintext，aad 为绑定的上下文，例如表名和列名，解密时必须相同；未开启加密时原样返回
+// Encrypt plaintext with the active key, aad is the bound context such as the table and column and must match when decrypting; returned unchanged while encryption is off
+func Encrypt(plaintext, aad string) (string, error) {
+	r := current.Load()
+	if r == nil || r.active == "" {
+		return plaintext, nil
+	}
+	aead := r.keys[r.active]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(aad))
+	return prefix + r.active + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt 解密 Encrypt 的结果，不带密文前缀的值视为尚未加密的旧值原样返回；
+// stale 表示值未加密或由其他密钥加密，开启加密时应以当前密钥重新加密
+// Decrypt what Encrypt returned, values without the ciphertext prefix are older plain values returned unchanged;
+// stale reports a plain value or one encrypted with another key, to be encrypted again with the active key while encryption is on
+func Decrypt(value, aad string) (plaintext string, stale bool, err error) {
+	r := current.Load()
+	enabled := r != nil && r.active != ""
+	rest, ok := strings.CutPrefix(value, prefix)
+	if !ok {
+		return value, enabled, nil
+	}
+	id, data, ok := strings.Cut(rest, ":")
+	if !ok {
+		return "", false, ErrMalformed
+	}
+	var aead cipher.AEAD
+	if r != nil {
+		aead = r.keys[id]
+	}
+	if aead == nil {
+		return "", false, fmt.Errorf("%w %q", ErrUnknownKey, id)
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(data)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", false, ErrMalformed
+	}
+	opened, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(aad))
+	if err != nil {
+		return "", false, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	return string(opened), enabled && id != r.active, nil
+}
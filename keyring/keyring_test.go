@@ -0,0 +1,110 @@
+package keyring
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// useRing 在测试期间使用给定的密钥 Use the given keys for the duration of the test
+func useRing(t *testing.T, active string, keys map[string][]byte) {
+	t.Helper()
+	r, err := newRing(active, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := current.Swap(r)
+	t.Cleanup(func() { current.Store(previous) })
+}
+
+// TestEncryptRotate 加密的值绑定上下文，轮换后旧密钥的值和明文旧值仍可读取并标记为需要重新加密
+// Encrypted values are bound to their context, after a rotation values of the old key and plain older values stay readable and are marked stale
+func TestEncryptRotate(t *testing.T) {
+	k1, k2 := bytes.Repeat([]byte{1}, keySize), bytes.Repeat([]byte{2}, keySize)
+	useRing(t, "k1", map[string][]byte{"k1": k1})
+	sealed, err := Encrypt("hook secret", "webhooks.secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(sealed, "enc:k1:") || strings.Contains(sealed, "hook secret") {
+		t.Fatalf("ciphertext %q", sealed)
+	}
+	if plain, stale, err := Decrypt(sealed, "webhooks.secret"); err != nil || plain != "hook secret" || stale {
+		t.Errorf("decrypt %q %v %v", plain, stale, err)
+	}
+	if _, _, err := Decrypt(sealed, "cdn_integrations.token"); !errors.Is(err, ErrMalformed) {
+		t.Errorf("decrypt in another context: %v, want ErrMalformed", err)
+	}
+
+	useRing(t, "k2", map[string][]byte{"k1": k1, "k2": k2})
+	if plain, stale, err := Decrypt(sealed, "webhooks.secret"); err != nil || plain != "hook secret" || !stale {
+		t.Errorf("decrypt with rotated keys %q %v %v, want stale", plain, stale, err)
+	}
+	if plain, stale, err := Decrypt("plain", "webhooks.secret"); err != nil || plain != "plain" || !stale {
+		t.Errorf("decrypt plain value %q %v %v, want stale", plain, stale, err)
+	}
+	if ActivePrefix() != "enc:k2:" {
+		t.Errorf("active prefix %q", ActivePrefix())
+	}
+
+	useRing(t, "", map[string][]byte{"k2": k2})
+	if _, _, err := Decrypt(sealed, "webhooks.secret"); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("decrypt with removed key: %v, want ErrUnknownKey", err)
+	}
+	if plain, err := Encrypt("plain", "webhooks.secret"); err != nil || plain != "plain" {
+		t.Errorf("encrypt without active key %q %v", plain, err)
+	}
+	if _, stale, _ := Decrypt("plain", "webhooks.secret"); stale {
+		t.Error("plain value is stale without active key")
+	}
+}
+
+// TestNewRing 拒绝长度错误的密钥、非法的密钥ID和未配置的当前密钥
+// Keys of the wrong length, invalid key IDs and an unconfigured active key are rejected
+func TestNewRing(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, keySize)
+	for name, keys := range map[string]map[string][]byte{
+		"short key":      {"k1": key[:16]},
+		"colon in id":    {"k:1": key},
+		"missing active": {"k2": key},
+	} {
+		if _, err := newRing("k1", keys); err == nil {
+			t.Errorf("%s accepted", name)
+		}
+	}
+}
+
+// TestVaultDecrypt 通过 transit 引擎解密配置中的密钥
+// Configured keys are decrypted through the transit engine
+func TestVaultDecrypt(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, keySize)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Ciphertext string `json:"ciphertext"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if r.URL.Path != "/v1/transit/decrypt/spage" || r.Header.Get("X-Vault-Token") != "root" || body.Ciphertext != "vault:v1:abc" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"plaintext":"` + base64.StdEncoding.EncodeToString(key) + `"}}`))
+	}))
+	defer server.Close()
+
+	vault := Vault{Address: server.URL, Token: "root", Mount: "transit", Key: "spage"}
+	got, err := vault.Decrypt(context.Background(), "vault:v1:abc")
+	if err != nil || !bytes.Equal(got, key) {
+		t.Errorf("decrypt %x %v", got, err)
+	}
+	vault.Token = "wrong"
+	if _, err := vault.Decrypt(context.Background(), "vault:v1:abc"); err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("decrypt with wrong token: %v", err)
+	}
+}
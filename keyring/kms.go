@@ -0,0 +1,86 @@
+package keyring
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+)
+
+// kmsTimeout 单次请求密钥管理服务的超时时间 Timeout of one request to the key management service
+const kmsTimeout = 10 * time.Second
+
+// KMS 密钥管理服务，只在启动时解密配置中的密钥，明文密钥不离开内存
+// Key management service, only used at startup to decrypt the configured keys, the plain keys never leave memory
+type KMS interface {
+	// Decrypt 解密 KMS 加密的密钥 Decrypt a key encrypted by the KMS
+	Decrypt(ctx context.Context, ciphertext string) ([]byte, error)
+}
+
+func newKMS(provider string) (KMS, error) {
+	switch provider {
+	case "vault":
+		return Vault{
+			Address: strings.TrimRight(config.EncryptionKMSAddress, "/"),
+			Token:   config.EncryptionKMSToken,
+			Mount:   config.EncryptionKMSMount,
+			Key:     config.EncryptionKMSKey,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported kms provider: %s", provider)
+	}
+}
+
+// Vault HashiCorp Vault 的 transit 引擎，密钥由 vault write transit/encrypt/<key> plaintext=<base64 密钥> 得到的 vault:v1:... 密文配置
+// The transit engine of HashiCorp Vault, keys are configured as the vault:v1:... ciphertext of vault write transit/encrypt/<key> plaintext=<base64 key>
+type Vault struct {
+	Address string // 服务地址 Server address
+	Token   string // 访问令牌 Access token
+	Mount   string // transit 引擎的挂载路径 Mount path of the transit engine
+	Key     string // 密钥名称 Key name
+}
+
+func (v Vault) Decrypt(ctx context.Context, ciphertext string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, kmsTimeout)
+	defer cancel()
+	body, err := json.Marshal(map[string]string{"ciphertext": ciphertext})
+	if err != nil {
+		return nil, err
+	}
+	endpoint := v.Address + "/v1/" + strings.Trim(v.Mount, "/") + "/decrypt/" + url.PathEscape(v.Key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", v.Token)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var result struct {
+		Data struct {
+			Plaintext string `json:"plaintext"`
+		} `json:"data"`
+		Errors []string `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil && resp.StatusCode == http.StatusOK {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault returned %d: %s", resp.StatusCode, strings.Join(result.Errors, "; "))
+	}
+	if result.Data.Plaintext == "" {
+		return nil, errors.New("vault returned no plaintext")
+	}
+	return base64.StdEncoding.DecodeString(result.Data.Plaintext)
+}
@@ -0,0 +1,155 @@
+package mailer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/i18n"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// 邮件模板名称 Email template names
+const (
+	TemplateVerifyEmail   = "verify_email"   // 验证邮箱，数据为 Name、Link、Hours Verify the address, data is Name, Link and Hours
+	TemplateResetPassword = "reset_password" // 重置密码，数据为 Name、Link、Minutes Reset the password, data is Name, Link and Minutes
+	TemplateInvitation    = "invitation"     // 注册邀请，数据为 Inviter、Organization、Link、Days Invitation to sign up, data is Inviter, Organization, Link and Days
+)
+
+// mailTemplate 一种邮件在某个语言下的主题和正文
+// Subject and body of one kind of email in one language
+type mailTemplate struct {
+	subject string
+	body    *template.Template
+}
+
+// templates 按模板名称和语言索引，每个模板都要有 en 和 zh-cn，语言不受支持时使用默认语言
+// Indexed by template name and language, every template needs en and zh-cn, the default language is used for unsupported languages
+var templates = map[string]map[string]mailTemplate{
+	TemplateVerifyEmail: {
+		"en": {
+			subject: "Verify your email address",
+			body: parse(`<p>Hi {{.Name}},</p>
+<p>Please confirm this email address for your Spage account by opening the link below. The link expires in {{.Hours}} hours.</p>
+<p><a href="{{.Link}}">{{.Link}}</a></p>
+<p>If you did not sign up, you can ignore this email.</p>`),
+		},
+		"zh-cn": {
+			subject: "验证你的邮箱地址",
+			body: parse(`<p>{{.Name}}，你好：</p>
+<p>请打开下面的链接确认这是你的 Spage 账号邮箱，链接在 {{.Hours}} 小时后失效。</p>
+<p><a href="{{.Link}}">{{.Link}}</a></p>
+<p>如果你没有注册过账号，请忽略这封邮件。</p>`),
+		},
+	},
+	TemplateResetPassword: {
+		"en": {
+			subject: "Reset your password",
+			body: parse(`<p>Hi {{.Name}},</p>
+<p>Someone asked to reset the password of your Spage account. Open the link below to choose a new one. The link expires in {{.Minutes}} minutes and can only be used once.</p>
+<p><a href="{{.Link}}">{{.Link}}</a></p>
+<p>If it was not you, you can ignore this email and your password stays unchanged.</p>`),
+		},
+		"zh-cn": {
+			subject: "重置你的密码",
+			body: parse(`<p>{{.Name}}，你好：</p>
+<p>有人申请重置你的 Spage 账号密码，请打开下面的链接设置新密码。链接在 {{.Minutes}} 分钟后失效，且只能使用一次。</p>
+<p><a href="{{.Link}}">{{.Link}}</a></p>
+<p>如果不是你本人操作，请忽略这封邮件，你的密码不会改变。</p>`),
+		},
+	},
+	TemplateInvitation: {
+		"en": {
+			subject: "You are invited to Spage",
+			body: parse(`<p>Hi,</p>
+<p>{{.Inviter}} invited you to Spage{{if .Organization}} to join the organization {{.Organization}}{{end}}. Open the link below to create your account. The link expires in {{.Days}} days.</p>
+<p><a href="{{.Link}}">{{.Link}}</a></p>`),
+		},
+		"zh-cn": {
+			subject: "邀请你加入 Spage",
+			body: parse(`<p>你好：</p>
+<p>{{.Inviter}} 邀请你{{if .Organization}}加入组织 {{.Organization}}{{else}}注册 Spage{{end}}，请打开下面的链接创建账号，链接在 {{.Days}} 天后失效。</p>
+<p><a href="{{.Link}}">{{.Link}}</a></p>`),
+		},
+	},
+}
+
+func parse(body string) *template.Template {
+	return template.Must(template.New("").Parse(`<!DOCTYPE html><html><body style="font-family:system-ui,sans-serif;line-height:1.6">` + body + `</body></html>`))
+}
+
+// Enabled 是否启用了邮件发送
+// Whether email sending is enabled
+func Enabled() bool {
+	return config.EmailEnable
+}
+
+// Render 按语言渲染邮件的主题和正文
+// Render the subject and body of an email in the given language
+func Render(name, language string, data map[string]any) (subject, body string, err error) {
+	variants, ok := templates[name]
+	if !ok {
+		return "", "", fmt.Errorf("unknown email template %q", name)
+	}
+	tmpl, ok := variants[i18n.Normalize(language)]
+	if !ok {
+		tmpl = variants[i18n.Default()]
+	}
+	var buf bytes.Buffer
+	if err := tmpl.body.Execute(&buf, data); err != nil {
+		return "", "", err
+	}
+	return tmpl.subject, buf.String(), nil
+}
+
+// Send 渲染并发送邮件，未启用邮件发送时不做任何事
+// Render and send an email, doing nothing while email sending is disabled
+func Send(to, name, language string, data map[string]any) error {
+	if !Enabled() {
+		return nil
+	}
+	subject, body, err := Render(name, language, data)
+	if err != nil {
+		return err
+	}
+	return utils.SendEmail(utils.EmailConfigFromConfig(), to, subject, body, true)
+}
+
+// SendAsync 在后台发送邮件，失败时只记录日志，请求无需等待 SMTP 服务器
+// Send an email in the background, only logging failures so requests do not wait for the SMTP server
+func SendAsync(to, name, language string, data map[string]any) {
+	go func() {
+		if err := Send(to, name, language, data); err != nil {
+			logrus.Warnf("Failed to send %s email: %v", name, err)
+		}
+	}()
+}
+
+// notificationBodies 按语言索引的通知邮件正文，数据为 Text、Channel，Text 是事件的摘要
+// Bodies of notification emails indexed by language, data is Text and Channel, Text being the summary of the event
+var notificationBodies = map[string]*template.Template{
+	"en": parse(`<p>{{.Text}}</p>
+<p style="color:#666;font-size:.9em">You receive this email because the notification channel {{.Channel}} of Spage subscribes to this event.</p>`),
+	"zh-cn": parse(`<p>{{.Text}}</p>
+<p style="color:#666;font-size:.9em">你收到这封邮件是因为 Spage 的通知渠道 {{.Channel}} 订阅了该事件。</p>`),
+}
+
+// SendNotification 以 language 发送通知邮件，主题和摘要已经是该语言，未启用邮件发送时返回错误，以便投递记录为失败
+// Send a notification email in language, the subject and summary already being in it, failing while email sending is disabled so the delivery is recorded as failed
+func SendNotification(to, language, channel, subject, text string) error {
+	if !Enabled() {
+		return errors.New("email sending is disabled")
+	}
+	body, ok := notificationBodies[i18n.Normalize(language)]
+	if !ok {
+		body = notificationBodies[i18n.Default()]
+	}
+	var buf bytes.Buffer
+	if err := body.Execute(&buf, map[string]any{"Text": text, "Channel": channel}); err != nil {
+		return err
+	}
+	return utils.SendEmail(utils.EmailConfigFromConfig(), to, subject, buf.String(), true)
+}
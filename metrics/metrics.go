@@ -0,0 +1,292 @@
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType Prometheus 文本格式的内容类型
+// Content type of the Prometheus text format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultBuckets 延迟直方图的默认分桶，单位秒
+// Default buckets of latency histograms, in seconds
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// labelSeparator 拼接标签值作为序列键，标签值中不会出现
+// Joins label values into a series key, it never appears in label values
+const labelSeparator = "\xff"
+
+// collector 可写出为文本格式的指标
+// A metric that can be written in the text format
+type collector interface {
+	name() string
+	write(w *bufio.Writer)
+}
+
+var registry = struct {
+	mu         sync.RWMutex
+	collectors map[string]collector
+}{collectors: make(map[string]collector)}
+
+// register 注册指标，同名指标重复注册时 panic
+// Register a metric, panicking when the name is taken
+func register(c collector) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if _, ok := registry.collectors[c.name()]; ok {
+		panic("metrics: duplicate metric " + c.name())
+	}
+	registry.collectors[c.name()] = c
+}
+
+// Write 以 Prometheus 文本格式按名称顺序写出所有指标
+// Write all metrics in the Prometheus text format, ordered by name
+func Write(w io.Writer) error {
+	registry.mu.RLock()
+	collectors := make([]collector, 0, len(registry.collectors))
+	for _, c := range registry.collectors {
+		collectors = append(collectors, c)
+	}
+	registry.mu.RUnlock()
+	sort.Slice(collectors, func(i, j int) bool { return collectors[i].name() < collectors[j].name() })
+	buf := bufio.NewWriter(w)
+	for _, c := range collectors {
+		c.write(buf)
+	}
+	return buf.Flush()
+}
+
+// desc 指标的名称、说明和标签名
+// Name, help and label names of a metric
+type desc struct {
+	metricName string
+	help       string
+	labels     []string
+}
+
+func (d *desc) name() string {
+	return d.metricName
+}
+
+func (d *desc) header(w *bufio.Writer, kind string) {
+	w.WriteString("# HELP " + d.metricName + " " + strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(d.help) + "\n")
+	w.WriteString("# TYPE " + d.metricName + " " + kind + "\n")
+}
+
+// key 将标签值拼接为序列键，数量与标签名不符时 panic
+// Join label values into a series key, panicking when the count does not match the label names
+func (d *desc) key(values []string) string {
+	if len(values) != len(d.labels) {
+		panic("metrics: " + d.metricName + " expects " + strconv.Itoa(len(d.labels)) + " label values")
+	}
+	return strings.Join(values, labelSeparator)
+}
+
+// sample 写出一行样本，extra 为额外的标签对，例如直方图的 le
+// Write a sample line, extra holds additional label pairs such as the le of histograms
+func (d *desc) sample(w *bufio.Writer, suffix string, values []string, value float64, extra ...string) {
+	w.WriteString(d.metricName + suffix)
+	if len(values)+len(extra) > 0 {
+		w.WriteByte('{')
+		for i, label := range d.labels {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			writeLabel(w, label, values[i])
+		}
+		for i := 0; i+1 < len(extra); i += 2 {
+			if len(values) > 0 || i > 0 {
+				w.WriteByte(',')
+			}
+			writeLabel(w, extra[i], extra[i+1])
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(value))
+	w.WriteByte('\n')
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func writeLabel(w *bufio.Writer, name, value string) {
+	w.WriteString(name + `="` + labelEscaper.Replace(value) + `"`)
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// CounterVec 按标签区分的计数器
+// Counter partitioned by labels
+type CounterVec struct {
+	desc
+	mu     sync.Mutex
+	series map[string]*counterSeries
+}
+
+type counterSeries struct {
+	values []string
+	value  float64
+}
+
+// NewCounterVec 创建并注册计数器
+// Create and register a counter
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{desc: desc{metricName: name, help: help, labels: labels}, series: make(map[string]*counterSeries)}
+	register(c)
+	return c
+}
+
+// Inc 计数加一
+// Increase the counter by one
+func (c *CounterVec) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// Add 计数增加 delta，delta 不能为负
+// Increase the counter by delta, which must not be negative
+func (c *CounterVec) Add(delta float64, values ...string) {
+	if delta < 0 {
+		return
+	}
+	key := c.key(values)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.series[key]
+	if !ok {
+		s = &counterSeries{values: append([]string(nil), values...)}
+		c.series[key] = s
+	}
+	s.value += delta
+}
+
+func (c *CounterVec) write(w *bufio.Writer) {
+	c.header(w, "counter")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range sortedKeys(c.series) {
+		s := c.series[key]
+		c.sample(w, "", s.values, s.value)
+	}
+}
+
+// HistogramVec 按标签区分的直方图
+// Histogram partitioned by labels
+type HistogramVec struct {
+	desc
+	buckets []float64
+	mu      sync.Mutex
+	series  map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	values []string
+	counts []uint64 // 每个分桶的非累计次数 Non-cumulative count of each bucket
+	count  uint64
+	sum    float64
+}
+
+// NewHistogramVec 创建并注册直方图，buckets 为空时使用 DefaultBuckets
+// Create and register a histogram, using DefaultBuckets when buckets is empty
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	h := &HistogramVec{desc: desc{metricName: name, help: help, labels: labels}, buckets: buckets, series: make(map[string]*histogramSeries)}
+	register(h)
+	return h
+}
+
+// Observe 记录一次观测值
+// Record an observation
+func (h *HistogramVec) Observe(value float64, values ...string) {
+	key := h.key(values)
+	index := sort.SearchFloat64s(h.buckets, value)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogramSeries{values: append([]string(nil), values...), counts: make([]uint64, len(h.buckets))}
+		h.series[key] = s
+	}
+	if index < len(h.buckets) {
+		s.counts[index]++
+	}
+	s.count++
+	s.sum += value
+}
+
+func (h *HistogramVec) write(w *bufio.Writer) {
+	h.header(w, "histogram")
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, key := range sortedKeys(h.series) {
+		s := h.series[key]
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += s.counts[i]
+			h.sample(w, "_bucket", s.values, float64(cumulative), "le", formatFloat(bound))
+		}
+		h.sample(w, "_bucket", s.values, float64(s.count), "le", "+Inf")
+		h.sample(w, "_sum", s.values, s.sum)
+		h.sample(w, "_count", s.values, float64(s.count))
+	}
+}
+
+// GaugeFunc 抓取时由回调计算的指标，适合连接池状态和数据库统计等已有数据
+// Metric computed by a callback at scrape time, suited for existing data like pool state and database statistics
+type GaugeFunc struct {
+	desc
+	kind    string
+	collect func(emit func(value float64, values ...string))
+}
+
+// NewGaugeFunc 创建并注册仪表盘指标，collect 对每个序列调用一次 emit
+// Create and register a gauge, collect calls emit once per series
+func NewGaugeFunc(name, help string, collect func(emit func(value float64, values ...string)), labels ...string) *GaugeFunc {
+	g := &GaugeFunc{desc: desc{metricName: name, help: help, labels: labels}, kind: "gauge", collect: collect}
+	register(g)
+	return g
+}
+
+// NewCounterFunc 创建并注册由回调读取的计数器，用于外部维护的累计值
+// Create and register a counter read by a callback, for cumulative values maintained elsewhere
+func NewCounterFunc(name, help string, collect func(emit func(value float64, values ...string)), labels ...string) *GaugeFunc {
+	g := &GaugeFunc{desc: desc{metricName: name, help: help, labels: labels}, kind: "counter", collect: collect}
+	register(g)
+	return g
+}
+
+func (g *GaugeFunc) write(w *bufio.Writer) {
+	g.header(w, g.kind)
+	g.collect(func(value float64, values ...string) {
+		g.key(values)
+		g.sample(w, "", values, value)
+	})
+}
+
+func sortedKeys[T any](series map[string]T) []string {
+	keys := make([]string, 0, len(series))
+	for key := range series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteCounterAndHistogram(t *testing.T) {
+	requests := NewCounterVec("test_requests_total", "Requests.", "route", "status")
+	requests.Inc("/a", "200")
+	requests.Add(2, "/a", "200")
+	requests.Inc(`/b"\`, "500")
+	latency := NewHistogramVec("test_latency_seconds", "Latency.", []float64{0.1, 1}, "route")
+	latency.Observe(0.05, "/a")
+	latency.Observe(0.1, "/a")
+	latency.Observe(3, "/a")
+
+	var buf bytes.Buffer
+	if err := Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"# TYPE test_requests_total counter\n",
+		`test_requests_total{route="/a",status="200"} 3` + "\n",
+		`test_requests_total{route="/b\"\\",status="500"} 1` + "\n",
+		"# TYPE test_latency_seconds histogram\n",
+		`test_latency_seconds_bucket{route="/a",le="0.1"} 2` + "\n",
+		`test_latency_seconds_bucket{route="/a",le="1"} 2` + "\n",
+		`test_latency_seconds_bucket{route="/a",le="+Inf"} 3` + "\n",
+		`test_latency_seconds_sum{route="/a"} 3.15` + "\n",
+		`test_latency_seconds_count{route="/a"} 3` + "\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output is missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Index(out, "test_latency_seconds") > strings.Index(out, "test_requests_total") {
+		t.Error("metrics should be written in name order")
+	}
+}
+
+func TestGaugeFunc(t *testing.T) {
+	NewGaugeFunc("test_pool_connections", "Connections.", func(emit func(float64, ...string)) {
+		emit(4, "idle")
+		emit(1, "in_use")
+	}, "state")
+	NewGaugeFunc("test_up", "Up.", func(emit func(float64, ...string)) {
+		emit(1)
+	})
+
+	var buf bytes.Buffer
+	if err := Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"# TYPE test_pool_connections gauge\n",
+		`test_pool_connections{state="idle"} 4` + "\n",
+		`test_pool_connections{state="in_use"} 1` + "\n",
+		"test_up 1\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output is missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestDuplicateRegistration(t *testing.T) {
+	NewCounterVec("test_duplicate_total", "Duplicate.")
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a metric twice should panic")
+		}
+	}()
+	NewCounterVec("test_duplicate_total", "Duplicate.")
+}
@@ -0,0 +1,174 @@
+package middle
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/accesslog"
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/serve"
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// ErrTooManyTails 站点的实时日志流数量已达上限
+// The number of live log tails of the site has reached the limit
+var ErrTooManyTails = errors.New("too many live log tails for this site")
+
+// AccessLogFilter 实时日志过滤条件
+// Live log filter
+type AccessLogFilter struct {
+	PathPrefix  string // 路径前缀，为空不过滤 Path prefix, empty means no filter
+	StatusClass int    // 状态码类别，例如 4 表示 4xx，0 不过滤 Status class, e.g. 4 means 4xx, 0 means no filter
+}
+
+// Match 判断日志是否满足过滤条件
+// Check whether the entry matches the filter
+func (f AccessLogFilter) Match(entry *models.AccessLog) bool {
+	if f.PathPrefix != "" && !strings.HasPrefix(entry.Path, f.PathPrefix) {
+		return false
+	}
+	if f.StatusClass != 0 && entry.Status/100 != f.StatusClass {
+		return false
+	}
+	return true
+}
+
+// AccessLogSubscription 一个实时日志订阅
+// A live log subscription
+type AccessLogSubscription struct {
+	site   *models.Site
+	filter AccessLogFilter
+	ch     chan models.AccessLog
+	mu     sync.Mutex // 保证丢弃最旧日志与写入的原子性 Make drop-oldest and send atomic
+}
+
+// C 返回接收日志的通道
+// Return the channel receiving entries
+func (s *AccessLogSubscription) C() <-chan models.AccessLog {
+	return s.ch
+}
+
+// send 非阻塞投递，缓冲区满时丢弃最旧的日志
+// Non-blocking delivery, drop the oldest entry when the buffer is full
+func (s *AccessLogSubscription) send(entry models.AccessLog) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for {
+		select {
+		case s.ch <- entry:
+			return
+		default:
+			select {
+			case <-s.ch:
+			default:
+			}
+		}
+	}
+}
+
+type accessLogHub struct {
+	mu     sync.RWMutex
+	subs   map[uint]map[*AccessLogSubscription]struct{}
+	active atomic.Int32
+}
+
+// AccessLog 访问日志的进程内分发器，无订阅者时不产生额外开销
+// In-process fanout of access logs, adding no cost when nobody is subscribed
+var AccessLog = &accessLogHub{
+	subs: make(map[uint]map[*AccessLogSubscription]struct{}),
+}
+
+// Active 是否存在任意订阅者
+// Whether there is any subscriber
+func (h *accessLogHub) Active() bool {
+	return h.active.Load() > 0
+}
+
+// Subscribe 订阅站点的访问日志
+// Subscribe to the access logs of a site
+func (h *accessLogHub) Subscribe(site *models.Site, filter AccessLogFilter) (*AccessLogSubscription, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	siteSubs := h.subs[site.ID]
+	if len(siteSubs) >= config.LogTailMaxPerSite {
+		return nil, ErrTooManyTails
+	}
+	if siteSubs == nil {
+		siteSubs = make(map[*AccessLogSubscription]struct{})
+		h.subs[site.ID] = siteSubs
+	}
+	size := config.LogTailBufferSize
+	if size <= 0 {
+		size = 1
+	}
+	sub := &AccessLogSubscription{
+		site:   site,
+		filter: filter,
+		ch:     make(chan models.AccessLog, size),
+	}
+	siteSubs[sub] = struct{}{}
+	h.active.Add(1)
+	return sub, nil
+}
+
+// Unsubscribe 取消订阅
+// Cancel a subscription
+func (h *accessLogHub) Unsubscribe(sub *AccessLogSubscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	siteSubs := h.subs[sub.site.ID]
+	if _, ok := siteSubs[sub]; !ok {
+		return
+	}
+	delete(siteSubs, sub)
+	if len(siteSubs) == 0 {
+		delete(h.subs, sub.site.ID)
+	}
+	h.active.Add(-1)
+}
+
+// Publish 将日志分发给匹配主机名的站点订阅者，entry.SiteID 会被填充
+// Deliver the entry to subscribers whose site matches the host, entry.SiteID is filled in
+func (h *accessLogHub) Publish(entry models.AccessLog) {
+	if !h.Active() {
+		return
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for siteID, siteSubs := range h.subs {
+		for sub := range siteSubs {
+			if !serve.HostMatches(sub.site, entry.Host) {
+				break
+			}
+			entry.SiteID = siteID
+			if sub.filter.Match(&entry) {
+				sub.send(entry)
+			}
+		}
+	}
+}
+
+// newExportEntry 由站点请求的上下文构造导出的访问日志
+// Build the exported access log from the context of a site request
+func newExportEntry(c *app.RequestContext, start time.Time) *accesslog.Entry {
+	bytes := c.Response.Header.ContentLength()
+	if bytes < 0 {
+		bytes = len(c.Response.Body())
+	}
+	return &accesslog.Entry{
+		Time:      start,
+		Host:      string(c.Host()),
+		ClientIP:  c.ClientIP(),
+		Method:    string(c.Request.Header.Method()),
+		URI:       string(c.Request.RequestURI()),
+		Proto:     c.Request.Header.GetProtocol(),
+		Status:    c.Response.StatusCode(),
+		Bytes:     bytes,
+		Referer:   string(c.Request.Header.Peek("Referer")),
+		UserAgent: string(c.Request.Header.UserAgent()),
+	}
+}
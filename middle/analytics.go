@@ -0,0 +1,106 @@
+package middle
+
+import (
+	"bytes"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/serve"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// analyticsValueLength 记录的值的最大长度，与数据库字段一致
+// Max length of recorded values, matching the database column
+const analyticsValueLength = 255
+
+// botAgents 用户代理中包含这些小写片段的请求视为爬虫
+// Requests whose user agent contains one of these lowercase fragments are treated as crawlers
+var botAgents = []string{"bot", "crawl", "spider", "slurp", "facebookexternalhit", "headless", "lighthouse", "preview"}
+
+// recordPageView 按访问分析配置记录一次页面浏览，只统计 GET 请求返回的 HTML 页面，不记录访问者 IP
+// Record a page view according to the analytics settings, only HTML pages returned to GET requests are counted and visitor IPs are never stored
+func recordPageView(c *app.RequestContext, siteID uint, now time.Time) {
+	if !config.AnalyticsEnable || string(c.Method()) != "GET" || bytes.HasPrefix(c.Path(), []byte(serve.AccessPathPrefix)) {
+		return
+	}
+	if !bytes.HasPrefix(c.Response.Header.ContentType(), []byte("text/html")) {
+		return
+	}
+	if config.AnalyticsRespectDNT && (string(c.GetHeader("DNT")) == "1" || string(c.GetHeader("Sec-GPC")) == "1") {
+		return
+	}
+	if config.AnalyticsExcludeBots && isBot(string(c.Request.Header.UserAgent())) {
+		return
+	}
+	view := store.PageView{
+		SiteID:   siteID,
+		Path:     truncate(string(c.Path()), analyticsValueLength),
+		Referrer: referrerHost(string(c.Request.Header.Peek("Referer")), string(c.Host())),
+		Status:   strconv.Itoa(c.Response.StatusCode()),
+	}
+	if config.AnalyticsCountryHeader != "" {
+		view.Country = countryCode(string(c.GetHeader(config.AnalyticsCountryHeader)))
+	}
+	store.Analytics.Record(view, now)
+}
+
+// isBot 用户代理为空或属于爬虫
+// Whether the user agent is empty or belongs to a crawler
+func isBot(userAgent string) bool {
+	if userAgent == "" {
+		return true
+	}
+	userAgent = strings.ToLower(userAgent)
+	for _, fragment := range botAgents {
+		if strings.Contains(userAgent, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// referrerHost 返回来源页面的主机名，站内跳转和无效的来源返回空
+// Return the host of the referring page, empty for links within the site and invalid referrers
+func referrerHost(referer, host string) string {
+	u, err := url.Parse(referer)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return ""
+	}
+	name := strings.ToLower(u.Hostname())
+	if own, _, _ := strings.Cut(strings.ToLower(host), ":"); name == own {
+		return ""
+	}
+	return truncate(name, analyticsValueLength)
+}
+
+// countryCode 规范化国家或地区代码，无效时返回空
+// Normalize a country or region code, empty when invalid
+func countryCode(value string) string {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	if len(value) < 2 || len(value) > 3 {
+		return ""
+	}
+	for _, r := range value {
+		if (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return ""
+		}
+	}
+	return value
+}
+
+// truncate 将字符串截断到 n 字节以内，不截断多字节字符
+// Truncate the string to at most n bytes without splitting multi-byte characters
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -2,12 +2,15 @@ package middle
 
 import (
 	"context"
-	"fmt"
+	"encoding/base64"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/LiteyukiStudio/spage/config"
 	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/i18n"
 	"github.com/LiteyukiStudio/spage/models"
 	"github.com/LiteyukiStudio/spage/resps"
 	"github.com/LiteyukiStudio/spage/store"
@@ -20,22 +23,29 @@ type authType struct{}
 
 var Auth = authType{}
 
-// PersistentHandler 持久化处理函数，使用依赖注入到 utils 中防止循环引用
-// Persistent Handler Function, using dependency injection to prevent circular references
-func PersistentHandler(userID uint) (*models.Token, error) {
-	token, err := store.JWT.CreateToken(userID)
-	if err != nil {
-		return nil, err
-	}
-	return token, nil
-}
-
 // RevokeChecker 令牌撤销检查器，使用依赖注入到 utils 中防止循环引用
 // Token Revocation Checker, using dependency injection to prevent circular references
 func RevokeChecker(tokenID uint) bool {
 	return store.JWT.IsTokenRevoked(tokenID)
 }
 
+// Refresh 使用刷新令牌为会话签发新的访问令牌，并记录会话的最近使用
+// Issue a new access token for the session of a refresh token and record the session's last use
+func (authType) Refresh(ctx context.Context, c *app.RequestContext, refreshToken string) (*models.Token, string, error) {
+	session, err := store.JWT.GetByRefreshHash(utils.Token.HashAPIToken(refreshToken))
+	if err != nil {
+		return nil, "", err
+	}
+	token, err := utils.Token.CreateImpersonationToken(session.UserID, session.ID, session.ImpersonatorID, session.Scopes, time.Duration(config.TokenExpireTime)*time.Second)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := store.JWT.Touch(session, c.ClientIP(), string(c.UserAgent())); err != nil {
+		utils.Log.Ctx(ctx).Warnf("failed to record session %d usage: %v", session.ID, err)
+	}
+	return session, token, nil
+}
+
 // UseAuth 中间件函数
 // Middleware function for authentication
 func (authType) UseAuth() app.HandlerFunc {
@@ -44,6 +54,23 @@ func (authType) UseAuth() app.HandlerFunc {
 		// 1. Check authentication method
 		authHeader := string(c.GetHeader("Authorization"))
 
+		// WebDAV 客户端只支持 Basic 认证，密码为个人访问令牌或部署密钥，未通过认证时提示客户端输入
+		// WebDAV clients only speak Basic authentication with a personal access token or deploy key as the password, and are prompted for it when unauthenticated
+		if DAVRequest(c) {
+			defer func() {
+				if c.Response.StatusCode() == 401 {
+					c.Response.Header.Set("WWW-Authenticate", `Basic realm="spage", charset="UTF-8"`)
+				}
+			}()
+			if encoded, ok := strings.CutPrefix(authHeader, "Basic "); ok {
+				if raw, err := base64.StdEncoding.DecodeString(encoded); err == nil {
+					if _, password, ok := strings.Cut(string(raw), ":"); ok {
+						authHeader = password
+					}
+				}
+			}
+		}
+
 		// 认证方式1：使用 Authorization Header
 		// Authentication method 1: Use Authorization Header
 		if authHeader != "" {
@@ -54,6 +81,17 @@ func (authType) UseAuth() app.HandlerFunc {
 				token = strings.TrimPrefix(token, "Bearer ")
 			}
 
+			// 个人访问令牌 Personal access token
+			if strings.HasPrefix(token, constants.APITokenPrefix) {
+				Auth.useAPIToken(ctx, c, token)
+				return
+			}
+			// 项目部署密钥 Project deploy key
+			if strings.HasPrefix(token, constants.DeployKeyPrefix) {
+				Auth.useDeployKey(ctx, c, token)
+				return
+			}
+
 			// 验证令牌
 			// Verify token
 			claims, err := utils.Token.ParseToken(token, RevokeChecker)
@@ -65,108 +103,254 @@ func (authType) UseAuth() app.HandlerFunc {
 
 			// 将用户信息存储到上下文中
 			// Store user information in the context
-			c.Set("user", claims.UserID)
-			c.Next(ctx)
+			Auth.useSession(ctx, c, claims.UserID, claims.TokenID, claims.Impersonator, claims.Scopes)
 			return
 		}
 
-		// 认证方式2：使用 Cookie（启用无感刷新）
-		// Authentication method 2: Use Cookie (Enable silent refresh)
-		token := string(c.Cookie("token"))
-		if token == "" {
-			// 尝试通过 refresh_token 刷新
-			// Try to refresh by refresh_token
-			refreshToken := string(c.Cookie("refresh_token"))
-			if refreshToken == "" {
-				resps.BadRequest(c, "Refresh token not found 1")
-				c.Abort()
+		// 认证方式2：使用 Cookie，访问令牌缺失或失效时通过刷新令牌无感刷新
+		// Authentication method 2: Use Cookie, silently refreshing through the refresh token when the access token is missing or invalid
+		if token := string(c.Cookie("token")); token != "" {
+			if claims, err := utils.Token.ParseToken(token, RevokeChecker); err == nil {
+				if Auth.checkCSRF(c) {
+					Auth.useSession(ctx, c, claims.UserID, claims.TokenID, claims.Impersonator, claims.Scopes)
+				}
 				return
 			}
+		}
+		refreshToken := string(c.Cookie("refresh_token"))
+		if refreshToken == "" {
+			resps.Unauthorized(c, "Refresh token not found")
+			c.Abort()
+			return
+		}
+		session, newToken, err := Auth.Refresh(ctx, c, refreshToken)
+		if err != nil {
+			resps.Unauthorized(c, "Refresh token expired or invalid")
+			c.Abort()
+			return
+		}
 
-			// 验证刷新令牌
-			// Verify refresh token
-			refreshClaims, err := utils.Token.ParseToken(refreshToken, RevokeChecker)
-			if err != nil {
-				resps.Unauthorized(c, "Refresh token expired or invalid 2")
-				c.Abort()
-				return
-			}
+		// 设置新的访问令牌
+		// Set new access token
+		c.SetCookie("token", newToken, config.TokenExpireTime, "/", "", protocol.CookieSameSiteLaxMode, true, true)
 
-			// 生成新的访问令牌
-			// Generate new access token
-			newToken, err := utils.Token.CreateToken(refreshClaims.UserID, time.Duration(config.TokenExpireTime)*time.Second, false, PersistentHandler)
-			if err != nil {
-				resps.InternalServerError(c, "Create access token failed 3")
-				c.Abort()
-				return
-			}
+		// 保存用户信息并继续请求
+		// Save user information and continue request
+		if Auth.checkCSRF(c) {
+			Auth.useSession(ctx, c, session.UserID, session.ID, session.ImpersonatorID, session.Scopes)
+		}
+	}
+}
 
-			// 设置新的访问令牌
-			// Set new access token
-			c.SetCookie("token", newToken, config.TokenExpireTime, "/", "", protocol.CookieSameSiteLaxMode, true, true)
+// checkCSRF 校验使用 Cookie 认证的请求的 CSRF 令牌，失败时已写入响应；会话还没有 CSRF 令牌时签发一个，客户端可带上它重试
+// Check the CSRF token of a cookie-authenticated request, the response is already written on failure;
+// a CSRF token is issued when the session has none yet, which clients may retry with
+func (authType) checkCSRF(c *app.RequestContext) bool {
+	CSRF.Ensure(c)
+	if !CSRF.Valid(c) {
+		resps.Forbidden(c, "CSRF token missing or invalid")
+		c.Abort()
+		return false
+	}
+	return true
+}
 
-			// 保存用户信息并继续请求
-			// Save user information and continue request
-			c.Set("user", refreshClaims.UserID)
-			c.Next(ctx)
+// useSession 使用登录会话认证，管理员代为登录的会话按会话的权限范围限制可访问的接口
+// Authenticate with a sign-in session, restricting the endpoints of impersonation sessions by the session's scopes
+func (authType) useSession(ctx context.Context, c *app.RequestContext, userID, sessionID, impersonator uint, scopes []constants.TokenScope) {
+	if impersonator != 0 {
+		if !impersonationAllowed(c, scopes) {
+			resps.Forbidden(c, "Impersonation session does not allow this request")
+			c.Abort()
 			return
 		}
+		c.Set("impersonator", impersonator)
+	}
+	c.Set("user", userID)
+	c.Set("session", sessionID)
+	c.Next(ctx)
+}
 
-		// Cookie 中存在 token，验证其有效性
-		// Cookie contains token, verify its validity
-		claims, err := utils.Token.ParseToken(token, RevokeChecker)
-		if err != nil {
-			// token 无效，尝试刷新
-			// Token is invalid, try to refresh
-			refreshToken := string(c.Cookie("refresh_token"))
-			if refreshToken == "" {
-				resps.Unauthorized(c, "Refresh token not found 4")
-				c.Abort()
-				return
-			}
+// useAPIToken 使用个人访问令牌认证，并按权限范围限制可访问的接口
+// Authenticate with a personal access token and restrict the endpoints by scope
+func (authType) useAPIToken(ctx context.Context, c *app.RequestContext, plain string) {
+	token, err := store.APIToken.GetByHash(utils.Token.HashAPIToken(plain))
+	if err != nil || (token.ExpiresAt != nil && token.ExpiresAt.Before(time.Now())) {
+		resps.Unauthorized(c, "Invalid token")
+		c.Abort()
+		return
+	}
+	if required, ok := requiredScope(c); !ok || !hasScope(token.Scopes, required) {
+		resps.Forbidden(c, "Token scope does not allow this request")
+		c.Abort()
+		return
+	}
+	if err := store.APIToken.Touch(token); err != nil {
+		utils.Log.Ctx(ctx).Warnf("failed to record token %d usage: %v", token.ID, err)
+	}
+	c.Set("user", token.UserID)
+	c.Next(ctx)
+}
 
-			// 验证刷新令牌
-			// Verify refresh token
-			refreshClaims, err := utils.Token.ParseToken(refreshToken, RevokeChecker)
-			if err != nil {
-				fmt.Println(err)
-				resps.Unauthorized(c, "Refresh token expired or invalid 5")
-				c.Abort()
-				return
-			}
+// useDeployKey 使用项目部署密钥认证，以创建者的身份执行，只允许访问所属项目的部署接口
+// Authenticate with a project deploy key, acting as its creator and only reaching the deployment endpoints of its project
+func (authType) useDeployKey(ctx context.Context, c *app.RequestContext, plain string) {
+	key, err := store.DeployKey.GetByHash(utils.Token.HashAPIToken(plain))
+	if err != nil || (key.ExpiresAt != nil && key.ExpiresAt.Before(time.Now())) {
+		resps.Unauthorized(c, "Invalid token")
+		c.Abort()
+		return
+	}
+	if !deployKeyAllowed(c, key.ProjectID) {
+		resps.Forbidden(c, "Deploy key does not allow this request")
+		c.Abort()
+		return
+	}
+	if err := store.DeployKey.Touch(key); err != nil {
+		utils.Log.Ctx(ctx).Warnf("failed to record deploy key %d usage: %v", key.ID, err)
+	}
+	c.Set("user", key.CreatedByID)
+	c.Set("deployKey", key.ID)
+	c.Next(ctx)
+}
 
-			// 生成新的访问令牌
-			// Generate new access token
-			newToken, err := utils.Token.CreateToken(refreshClaims.UserID, time.Duration(config.TokenExpireTime)*time.Second, false, PersistentHandler)
-			if err != nil {
-				resps.InternalServerError(c, "Create access token failed 6")
-				c.Abort()
-				return
-			}
+// deployKeyAllowed 部署密钥只能访问所属项目站点的部署接口，以及读取站点、发布、上传进度和后台任务以便等待部署完成
+// Deploy keys only reach the deployment endpoints of the sites of their project,
+// plus reading the site, its releases, upload progress and background jobs so deployments can be waited for
+func deployKeyAllowed(c *app.RequestContext, projectID uint) bool {
+	const sitePath = "/api/v1/project/:id/site/:site_id"
+	path := c.FullPath()
+	if c.Param("id") != strconv.FormatUint(uint64(projectID), 10) || !strings.HasPrefix(path, sitePath) {
+		return false
+	}
+	if DeployRequest(c) {
+		return true
+	}
+	switch string(c.Method()) {
+	case "GET", "HEAD":
+		rest := strings.TrimPrefix(path, sitePath)
+		return rest == "" || rest == "/releases" || rest == "/deployments" || strings.HasPrefix(rest, "/uploads/") || strings.HasPrefix(rest, "/jobs")
+	}
+	return false
+}
+
+// requiredScope 请求所需的令牌权限范围，令牌和会话管理、删除账号和导出个人数据只能通过登录会话访问
+// Token scope required by the request, token and session management, account deletion and the personal data export are only reachable from a signed-in session
+func requiredScope(c *app.RequestContext) (constants.TokenScope, bool) {
+	path := c.FullPath()
+	switch {
+	case strings.HasPrefix(path, "/api/v1/user/tokens"), strings.HasPrefix(path, "/api/v1/user/sessions"),
+		strings.HasPrefix(path, "/api/v1/user/deletion"), strings.HasPrefix(path, "/api/v1/user/export"):
+		return "", false
+	case strings.HasPrefix(path, "/api/v1/admin"):
+		return constants.TokenScopeAdmin, true
+	// GraphQL 接口只读，查询通过 POST 提交 The GraphQL API is read-only, queries are sent by POST
+	case path == "/api/v1/graphql":
+		return constants.TokenScopeRead, true
+	}
+	switch string(c.Method()) {
+	case "GET", "HEAD", "OPTIONS", "PROPFIND":
+		return constants.TokenScopeRead, true
+	}
+	if DeployRequest(c) {
+		return constants.TokenScopeDeploy, true
+	}
+	return constants.TokenScopeWrite, true
+}
 
-			// 设置新的访问令牌
-			// Set new access token
-			c.SetCookie("token", newToken, config.TokenExpireTime, "/", "", protocol.CookieSameSiteLaxMode, true, true)
+// impersonationAllowed 代为登录的会话不能访问管理员接口、令牌和会话管理以及账号的登录方式和资料，其余接口按会话的权限范围限制
+// Impersonation sessions cannot reach admin endpoints, token and session management or the account's sign-in methods and profile,
+// other endpoints are restricted by the session's scopes
+func impersonationAllowed(c *app.RequestContext, scopes []constants.TokenScope) bool {
+	path := c.FullPath()
+	for _, prefix := range []string{"/api/v1/user/2fa", "/api/v1/user/oidc", "/api/v1/user/ldap", "/api/v1/user/email"} {
+		if strings.HasPrefix(path, prefix) {
+			return false
+		}
+	}
+	if path == "/api/v1/user" && string(c.Method()) != "GET" {
+		return false
+	}
+	required, ok := requiredScope(c)
+	return ok && required != constants.TokenScopeAdmin && hasScope(scopes, required)
+}
 
-			// 保存用户信息并继续请求
-			// Save user information and continue request
-			c.Set("user", refreshClaims.UserID)
-			c.Next(ctx)
-			return
+// DeployRequest 判断请求是否为上传、激活或回滚发布，或属于分片上传、清单部署和草稿，令牌的 deploy 权限和项目的 member 角色只允许这些写操作
+// Check whether the request uploads, activates or rolls back a release or belongs to a chunked upload, manifest deployment or draft,
+// the only writes allowed by the deploy scope and the member project role
+func DeployRequest(c *app.RequestContext) bool {
+	path := c.FullPath()
+	if strings.Contains(path, "/site/:site_id/uploads") || strings.Contains(path, "/site/:site_id/blobs") ||
+		strings.Contains(path, "/site/:site_id/draft") || DAVRequest(c) {
+		return true
+	}
+	return string(c.Method()) == "POST" && (strings.HasSuffix(path, "/release") || strings.HasSuffix(path, "/release/manifest") ||
+		strings.HasSuffix(path, "/release/activation") || strings.HasSuffix(path, "/rollback"))
+}
+
+// DAVRequest 判断请求是否访问站点草稿的 WebDAV 接口
+// Check whether the request reaches the WebDAV interface of a site draft
+func DAVRequest(c *app.RequestContext) bool {
+	return strings.Contains(c.FullPath(), "/site/:site_id/dav/")
+}
+
+// hasScope write 包含 deploy，deploy 包含 read；admin 仅用于管理员接口
+// write implies deploy and deploy implies read; admin only covers admin endpoints
+func hasScope(scopes []constants.TokenScope, required constants.TokenScope) bool {
+	implied := map[constants.TokenScope][]constants.TokenScope{
+		constants.TokenScopeRead:   {constants.TokenScopeRead, constants.TokenScopeDeploy, constants.TokenScopeWrite},
+		constants.TokenScopeDeploy: {constants.TokenScopeDeploy, constants.TokenScopeWrite},
+		constants.TokenScopeWrite:  {constants.TokenScopeWrite},
+		constants.TokenScopeAdmin:  {constants.TokenScopeAdmin},
+	}
+	for _, scope := range scopes {
+		if slices.Contains(implied[required], scope) {
+			return true
 		}
+	}
+	return false
+}
 
-		// token 有效，继续请求
-		// Token is valid, continue request
-		ctx = context.WithValue(ctx, "user", claims.UserID)
+// UseRunner 中间件函数，使用远程构建执行器的令牌认证，只用于执行器接口
+// Middleware function authenticating with the token of a remote build runner, only used by the runner endpoints
+func (authType) UseRunner() app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		token := strings.TrimPrefix(string(c.GetHeader("Authorization")), "Bearer ")
+		if !strings.HasPrefix(token, constants.RunnerTokenPrefix) {
+			resps.Unauthorized(c, "Invalid token")
+			c.Abort()
+			return
+		}
+		runner, err := store.Runner.GetByHash(utils.Token.HashAPIToken(token))
+		if err != nil {
+			resps.Unauthorized(c, "Invalid token")
+			c.Abort()
+			return
+		}
+		c.Set("runner", runner)
 		c.Next(ctx)
 	}
 }
 
+// GetRunner 使用执行器令牌认证的请求的执行器，其他请求为空
+// Runner of a request authenticated with a runner token, nil for other requests
+func (authType) GetRunner(c *app.RequestContext) *models.Runner {
+	if value, ok := c.Get("runner"); ok {
+		if runner, ok := value.(*models.Runner); ok {
+			return runner
+		}
+	}
+	return nil
+}
+
 // IsAdmin 是一个中间件，用于检查用户是否为管理员
 // IsAdmin is a middleware that checks if the user is an admin
 func (authType) IsAdmin() app.HandlerFunc {
 	return func(ctx context.Context, c *app.RequestContext) {
 		user := Auth.GetUser(ctx, c)
+		if user == nil {
+			return
+		}
 		if user.Role != constants.RoleAdmin {
 			resps.Forbidden(c, "Permission denied")
 			c.Abort()
@@ -176,10 +360,44 @@ func (authType) IsAdmin() app.HandlerFunc {
 	}
 }
 
+// SessionUser 从面板的登录 Cookie 中读取用户ID，不要求登录也不终止请求，未登录时为 0
+// Read the user ID from the panel's login cookies without requiring a login or aborting the request, 0 when signed out
+func (authType) SessionUser(c *app.RequestContext) uint {
+	if token := string(c.Cookie("token")); token != "" {
+		if claims, err := utils.Token.ParseToken(token, RevokeChecker); err == nil {
+			return claims.UserID
+		}
+	}
+	if refreshToken := string(c.Cookie("refresh_token")); refreshToken != "" {
+		if session, err := store.JWT.GetByRefreshHash(utils.Token.HashAPIToken(refreshToken)); err == nil {
+			return session.UserID
+		}
+	}
+	return 0
+}
+
+// ImpersonatorID 代为登录的管理员ID，请求不属于代为登录的会话时为 0
+// ID of the impersonating administrator, 0 when the request does not belong to an impersonation session
+func (authType) ImpersonatorID(c *app.RequestContext) uint {
+	return c.GetUint("impersonator")
+}
+
+// SessionID 已认证请求的登录会话ID，使用个人访问令牌时为 0
+// Sign-in session ID of an authenticated request, 0 with a personal access token
+func (authType) SessionID(c *app.RequestContext) uint {
+	return c.GetUint("session")
+}
+
+// DeployKeyID 使用项目部署密钥认证的请求的密钥ID，其他请求为 0
+// ID of the deploy key of a request authenticated with a project deploy key, 0 for other requests
+func (authType) DeployKeyID(c *app.RequestContext) uint {
+	return c.GetUint("deployKey")
+}
+
 // GetUser 从已认证的上下文中获取用户信息,如果用户不存在则终止请求并返回
 // GetUser retrieves user information from the authenticated context, if the user does not exist it terminates the request and returns
 func (authType) GetUser(ctx context.Context, c *app.RequestContext) *models.User {
-	userID := ctx.Value("user").(uint)
+	userID := c.GetUint("user")
 	if userID == 0 {
 		resps.Unauthorized(c, resps.TargetNotFound)
 		c.Abort()
@@ -197,5 +415,12 @@ func (authType) GetUser(ctx context.Context, c *app.RequestContext) *models.User
 		c.Abort()
 		return nil
 	}
+	i18n.SetUserLanguage(c, user.Language)
+	// 停用的账号已签发的令牌不再有效 Tokens already issued to a disabled account no longer work
+	if user.DisabledAt != nil {
+		resps.Forbidden(c, "Account is disabled")
+		c.Abort()
+		return nil
+	}
 	return user
 }
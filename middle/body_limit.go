@@ -0,0 +1,127 @@
+package middle
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/i18n"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+type bodyLimitType struct{}
+
+// BodyLimit 请求体大小限制，读取请求体时按字节计数，超出限制立即停止读取并返回 413
+// Request body size limits, the body is counted while it is read and reading stops with a 413 as soon as the limit is exceeded
+var BodyLimit = bodyLimitType{}
+
+// bodyLimitKey 保存当前请求体限制的上下文键
+// Context key holding the body limit of the request
+const bodyLimitKey = "bodyLimit"
+
+// ErrBodyTooLarge 请求体超出限制 The request body exceeds the limit
+var ErrBodyTooLarge = errors.New("request body too large")
+
+// limitedBody 计数读取的字节，超出请求当前的限制时返回 ErrBodyTooLarge
+// Count the bytes read, failing with ErrBodyTooLarge once the current limit of the request is exceeded
+type limitedBody struct {
+	c        *app.RequestContext
+	reader   io.Reader
+	read     int64
+	exceeded bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.exceeded {
+		return 0, ErrBodyTooLarge
+	}
+	n, err := b.reader.Read(p)
+	b.read += int64(n)
+	if limit := BodyLimit.limit(b.c); limit > 0 && b.read > limit {
+		b.exceeded = true
+		return n, ErrBodyTooLarge
+	}
+	return n, err
+}
+
+// Use 中间件函数，为所有请求的请求体计数，未单独设置限制的接口使用 server.max-body-size
+// Middleware function counting the body of every request, endpoints without a limit of their own use server.max-body-size
+func (bodyLimitType) Use() app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		if !c.Request.IsBodyStream() {
+			c.Next(ctx)
+			return
+		}
+		original := c.Request.BodyStream()
+		body := &limitedBody{c: c, reader: original}
+		c.Request.ConstructBodyStream(c.Request.BodyBuffer(), body)
+		c.Next(ctx)
+		if body.exceeded {
+			// 剩余的请求体不再读取，响应后关闭连接 The rest of the body is never read, the connection is closed after responding
+			_ = c.Request.CloseBodyStream()
+			c.Response.ResetBody()
+			BodyLimit.reject(c, BodyLimit.limit(c), -1)
+			return
+		}
+		// 换回原始请求体，由框架读完未读取的部分以复用连接 Restore the original body so the framework drains the unread part and reuses the connection
+		if c.Request.BodyStream() == io.Reader(body) {
+			c.Request.ConstructBodyStream(c.Request.BodyBuffer(), original)
+		}
+	}
+}
+
+// Limit 中间件函数，以 limit 返回的字节数作为接口的请求体限制，0 为不限制；Content-Length 已超出时直接拒绝，不读取请求体
+// Middleware function using the bytes returned by limit as the body limit of the endpoint, 0 is unlimited; requests whose Content-Length already exceeds it are rejected without reading the body
+func (bodyLimitType) Limit(limit func(ctx context.Context, c *app.RequestContext) int64) app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		maxSize := limit(ctx, c)
+		BodyLimit.Set(c, maxSize)
+		if size := int64(c.Request.Header.ContentLength()); maxSize > 0 && size > maxSize {
+			c.Abort()
+			BodyLimit.reject(c, maxSize, size)
+			return
+		}
+		c.Next(ctx)
+	}
+}
+
+// Set 在读取请求体之前修改请求的请求体限制，0 为不限制
+// Change the body limit of the request before its body is read, 0 is unlimited
+func (bodyLimitType) Set(c *app.RequestContext, limit int64) {
+	c.Set(bodyLimitKey, limit)
+}
+
+// Reader 以流的方式读取请求体，读取时受请求体限制约束
+// Read the request body as a stream, bound by the body limit while reading
+func (bodyLimitType) Reader(c *app.RequestContext) io.Reader {
+	if c.Request.IsBodyStream() {
+		return c.Request.BodyStream()
+	}
+	return bytes.NewReader(c.Request.Body())
+}
+
+// limit 请求当前的请求体限制
+// Current body limit of the request
+func (bodyLimitType) limit(c *app.RequestContext) int64 {
+	if value, ok := c.Get(bodyLimitKey); ok {
+		if limit, ok := value.(int64); ok {
+			return limit
+		}
+	}
+	return int64(config.ServerMaxBodySize) << 20
+}
+
+// reject 以 413 拒绝请求，size 为已知的请求体大小，未知时为 -1
+// Reject the request with 413, size is the known body size, -1 when unknown
+func (bodyLimitType) reject(c *app.RequestContext, limit, size int64) {
+	c.Response.Header.SetConnectionClose(true)
+	data := map[string]any{"limit": limit}
+	if size >= 0 {
+		data["size"] = size
+	}
+	resps.Custom(c, http.StatusRequestEntityTooLarge, i18n.Sprintf(i18n.Language(c), "Request body too large, at most %d bytes are allowed", limit), data)
+}
@@ -9,13 +9,15 @@ import (
 	"github.com/LiteyukiStudio/spage/utils"
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/go-resty/resty/v2"
-	"github.com/sirupsen/logrus"
 )
 
 type captchaType struct{}
 
 var Captcha = captchaType{}
 
+// captchaClient 请求验证码服务的客户端 Client requesting the captcha service
+var captchaClient = resty.New()
+
 type CaptchaReq struct {
 	CaptchaToken string `json:"captcha_token"` // Captcha Token
 }
@@ -23,12 +25,6 @@ type CaptchaReq struct {
 // UseCaptcha 中间件函数，用于验证验证码
 // Middleware function for captcha verification
 func (captchaType) UseCaptcha() app.HandlerFunc {
-	captchaConfig := &utils.CaptchaConfig{
-		Type:        config.CaptchaType,
-		SiteSecrete: config.CaptchaSiteKey,
-		SecretKey:   config.CaptchaSecretKey,
-	}
-	restyClient := resty.New()
 	return func(ctx context.Context, c *app.RequestContext) {
 		var req CaptchaReq
 		if err := c.BindAndValidate(&req); err != nil {
@@ -36,29 +32,47 @@ func (captchaType) UseCaptcha() app.HandlerFunc {
 			c.Abort()
 			return
 		}
-		if config.Mode == constants.ModeDev && req.CaptchaToken == constants.CaptchaDevPasscode {
-			// 开发模式密钥
-			// Dev mode passkey
-			c.Next(ctx)
-			return
-		}
-
-		ok, err := utils.Captcha.VerifyCaptcha(restyClient, captchaConfig, req.CaptchaToken)
+		ok, err := Captcha.Verify(ctx, req.CaptchaToken)
 		if err != nil {
-			logrus.Error("Captcha verification error:", err)
 			resps.InternalServerError(c, "Captcha verification failed")
 			c.Abort()
 			return
 		}
 		if !ok {
-			logrus.Warn("Captcha verification failed for token:", req.CaptchaToken)
 			resps.Forbidden(c, "Captcha verification failed")
 			c.Abort()
 			return
 		}
 		c.Next(ctx) // 如果验证码验证成功，则继续下一个处理程序
 		// If captcha verification is successful, continue to the next handler
+	}
+}
+
+// Enabled 是否配置了验证码
+// Whether a captcha is configured
+func (captchaType) Enabled() bool {
+	return config.CaptchaType != "" && config.CaptchaType != constants.CaptchaTypeDisable
+}
 
-		return
+// Verify 校验验证码令牌，未配置验证码时总是通过，开发模式下接受开发口令
+// Verify a captcha token, always passing without a configured captcha and accepting the dev passcode in dev mode
+func (captchaType) Verify(ctx context.Context, token string) (bool, error) {
+	if config.Mode == constants.ModeDev && token == constants.CaptchaDevPasscode {
+		// 开发模式密钥
+		// Dev mode passkey
+		return true, nil
+	}
+	ok, err := utils.Captcha.VerifyCaptcha(captchaClient, &utils.CaptchaConfig{
+		Type:        config.CaptchaType,
+		SiteSecrete: config.CaptchaSiteKey,
+		SecretKey:   config.CaptchaSecretKey,
+	}, token)
+	if err != nil {
+		utils.Log.Ctx(ctx).Error("Captcha verification error:", err)
+		return false, err
+	}
+	if !ok {
+		utils.Log.Ctx(ctx).Warn("Captcha verification failed for token:", token)
 	}
+	return ok, nil
 }
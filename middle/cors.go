@@ -1,8 +1,12 @@
 package middle
 
 import (
+	"context"
+	"slices"
+	"strings"
+
 	"github.com/LiteyukiStudio/spage/config"
-	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/utils"
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/hertz-contrib/cors"
 	"github.com/sirupsen/logrus"
@@ -12,24 +16,76 @@ type corsType struct{}
 
 var Cors = corsType{}
 
-// UseCors 跨域中间件
-// Cross-domain middleware
+// corsHeaders 跨域请求可以携带的请求头，携带 Cookie 时浏览器不接受通配符
+// Request headers cross-origin requests may carry, browsers do not accept wildcards for requests with cookies
+var corsHeaders = []string{"Authorization", "Content-Type", "Accept-Language", "Range", "If-Match", "If-None-Match", "X-Chunk-Sha256", CSRFHeader, utils.RequestIDHeader}
+
+// UseCors 跨域中间件，frontend.url 和 cors.allowed-origins 中的来源可以携带 Cookie，
+// cors.allowed-origins 包含 * 时其他来源也可调用接口但不能携带 Cookie，其余来源的跨域请求返回 403
+// Cross-domain middleware, origins of frontend.url and cors.allowed-origins may send cookies,
+// other origins may call the API without cookies when cors.allowed-origins contains *, and cross-origin requests of the rest get a 403
 func (corsType) UseCors() app.HandlerFunc {
-	var allowedOrigins []string
-	if config.Mode == constants.ModeDev {
-		allowedOrigins = []string{config.FrontEndURL}
-		logrus.Infof("Allowed origins: %v", allowedOrigins)
-	} else {
-		allowedOrigins = []string{"*"}
-		logrus.Infof("Allowed origins: %v", allowedOrigins)
+	origins := Cors.origins()
+	logrus.Infof("Allowed origins: %v", origins)
+	base := cors.Config{
+		AllowMethods:  []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
+		AllowHeaders:  corsHeaders,
+		ExposeHeaders: []string{"Content-Length", "Access-Control-Allow-Origin", "Access-Control-Allow-Headers", utils.RequestIDHeader, "Link", "ETag", utils.TotalCountHeader, CSRFHeader},
+		MaxAge:        3600,
+	}
+	trustedConfig := base
+	trustedConfig.AllowCredentials = true
+	trustedConfig.AllowOriginFunc = func(origin string) bool {
+		return Cors.Allowed(origins, origin)
+	}
+	trusted := cors.New(trustedConfig)
+	if !slices.Contains(config.CorsAllowedOrigins, "*") {
+		return trusted
+	}
+	publicConfig := base
+	publicConfig.AllowAllOrigins = true
+	public := cors.New(publicConfig)
+	return func(ctx context.Context, c *app.RequestContext) {
+		if origin := string(c.GetHeader("Origin")); origin == "" || Cors.Allowed(origins, origin) {
+			trusted(ctx, c)
+		} else {
+			public(ctx, c)
+		}
+	}
+}
+
+// origins 可以携带 Cookie 的来源，即 frontend.url 和 cors.allowed-origins 中除 * 以外的来源
+// Origins that may send cookies, which are frontend.url and those of cors.allowed-origins other than *
+func (corsType) origins() []string {
+	origins := []string{strings.TrimSuffix(config.FrontEndURL, "/")}
+	for _, origin := range config.CorsAllowedOrigins {
+		origin = strings.TrimSuffix(strings.TrimSpace(origin), "/")
+		if origin == "*" || origin == "" {
+			continue
+		}
+		if !strings.HasPrefix(origin, "http://") && !strings.HasPrefix(origin, "https://") {
+			logrus.Warnf("cors.allowed-origins %q is ignored, origins start with http:// or https://", origin)
+			continue
+		}
+		origins = append(origins, origin)
+	}
+	return origins
+}
+
+// Allowed 来源是否与列表中的一项相同，或与 https://*.example.com 形式的通配匹配，通配符至少匹配一个字符
+// Whether the origin equals an entry of the list or matches a wildcard like https://*.example.com, the wildcard matching at least one character
+func (corsType) Allowed(origins []string, origin string) bool {
+	for _, allowed := range origins {
+		prefix, suffix, wildcard := strings.Cut(allowed, "*")
+		if !wildcard {
+			if strings.EqualFold(allowed, origin) {
+				return true
+			}
+			continue
+		}
+		if len(origin) > len(prefix)+len(suffix) && strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) {
+			return true
+		}
 	}
-	return cors.New(
-		cors.Config{
-			AllowOrigins:     allowedOrigins,
-			AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
-			AllowHeaders:     []string{"*"},
-			ExposeHeaders:    []string{"Content-Length", "Access-Control-Allow-Origin", "Access-Control-Allow-Headers"},
-			AllowCredentials: true,
-			MaxAge:           3600,
-		})
+	return false
 }
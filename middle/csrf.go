@@ -0,0 +1,62 @@
+package middle
+
+import (
+	"crypto/subtle"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol"
+)
+
+type csrfType struct{}
+
+// CSRF 使用 Cookie 登录的浏览器请求的 CSRF 防护，采用双重提交 Cookie：登录时下发前端可读取的 csrf_token Cookie，
+// 修改数据的请求需在 X-CSRF-Token 请求头中带上相同的值；使用访问令牌或个人访问令牌的请求不受影响
+// CSRF protection of cookie-authenticated browser requests with a double-submit cookie: signing in sets a csrf_token cookie the frontend can read,
+// and requests changing data have to repeat its value in the X-CSRF-Token header; requests with access tokens or personal access tokens are not affected
+var CSRF = csrfType{}
+
+const (
+	CSRFCookie = "csrf_token"   // 保存 CSRF 令牌的 Cookie Cookie holding the CSRF token
+	CSRFHeader = "X-CSRF-Token" // 携带 CSRF 令牌的请求头，签发时也在响应头中返回 Header carrying the CSRF token, also returned as a response header when issued
+)
+
+// Issue 签发新的 CSRF 令牌，写入 Cookie 和响应头，登录时调用
+// Issue a new CSRF token into the cookie and the response header, called when signing in
+func (csrfType) Issue(c *app.RequestContext) {
+	token, _, err := utils.Token.NewOneTimeToken()
+	if err != nil {
+		return
+	}
+	c.SetCookie(CSRFCookie, token, config.RefreshTokenExpireTime, "/", "", protocol.CookieSameSiteLaxMode, true, false)
+	c.Header(CSRFHeader, token)
+}
+
+// Ensure Cookie 中没有 CSRF 令牌时签发一个，用于升级前登录的会话
+// Issue a CSRF token when the cookie has none, for sessions signed in before the upgrade
+func (csrfType) Ensure(c *app.RequestContext) {
+	if len(c.Cookie(CSRFCookie)) == 0 {
+		CSRF.Issue(c)
+	}
+}
+
+// Clear 删除 CSRF 令牌 Cookie，登出时调用
+// Delete the CSRF token cookie, called when signing out
+func (csrfType) Clear(c *app.RequestContext) {
+	c.SetCookie(CSRFCookie, "", -1, "/", "", protocol.CookieSameSiteLaxMode, true, false)
+}
+
+// Valid 请求是否通过 CSRF 校验，GET、HEAD 和 OPTIONS 不修改数据总是通过，未开启 csrf.enable 时总是通过
+// Whether the request passes the CSRF check, GET, HEAD and OPTIONS change nothing and always pass, as does everything without csrf.enable
+func (csrfType) Valid(c *app.RequestContext) bool {
+	if !config.CSRFEnable {
+		return true
+	}
+	switch string(c.Method()) {
+	case "GET", "HEAD", "OPTIONS":
+		return true
+	}
+	cookie, header := c.Cookie(CSRFCookie), c.GetHeader(CSRFHeader)
+	return len(cookie) > 0 && subtle.ConstantTimeCompare(cookie, header) == 1
+}
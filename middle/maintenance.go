@@ -0,0 +1,124 @@
+package middle
+
+import (
+	"context"
+	"html"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/resps"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/sirupsen/logrus"
+)
+
+type maintenanceType struct{}
+
+var Maintenance = maintenanceType{}
+
+// ReadOnlyMessage 只读期间拒绝写请求的提示
+// Message rejecting write requests while read-only
+const ReadOnlyMessage = "The instance is read-only for maintenance, retry later"
+
+// writablePaths 只读期间仍可写入的接口，管理员需要登录并关闭只读模式
+// Endpoints still accepting writes while read-only, administrators have to sign in and leave read-only mode
+var writablePaths = []string{
+	"/api/v1/admin/",
+	"/api/v1/user/login",
+	"/api/v1/user/logout",
+	"/api/v1/user/token/refresh",
+}
+
+// maintenancePage 内置维护页，{{message}} 替换为维护说明
+// Built-in maintenance page, {{message}} is replaced with the notice
+const maintenancePage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Under maintenance</title>
+<style>body{font-family:system-ui,sans-serif;display:flex;align-items:center;justify-content:center;min-height:90vh;margin:0;color:#333}main{max-width:32rem;padding:1rem;text-align:center}</style>
+</head>
+<body><main><h1>Under maintenance</h1><p>{{message}}</p></main></body>
+</html>
+`
+
+var (
+	customPageOnce sync.Once
+	customPage     string
+)
+
+// UseReadOnly 中间件函数，只读和维护模式下以 503 拒绝接口的写请求
+// Middleware function rejecting API write requests with 503 in read-only and maintenance mode
+func (maintenanceType) UseReadOnly() app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		if !config.ReadOnly() || !writeRequest(string(c.Method())) || writable(string(c.Path())) {
+			c.Next(ctx)
+			return
+		}
+		c.Abort()
+		Maintenance.retryAfter(c)
+		resps.Custom(c, http.StatusServiceUnavailable, ReadOnlyMessage, map[string]any{"mode": config.MaintenanceMode})
+	}
+}
+
+// Page 向托管站点的访问者返回 503 维护页
+// Answer visitors of hosted sites with the 503 maintenance page
+func (maintenanceType) Page(c *app.RequestContext) {
+	customPageOnce.Do(loadCustomPage)
+	page := maintenancePage
+	if customPage != "" {
+		page = customPage
+	}
+	Maintenance.retryAfter(c)
+	c.Response.Header.Set("Cache-Control", "no-store")
+	c.Data(http.StatusServiceUnavailable, "text/html; charset=utf-8", []byte(strings.ReplaceAll(page, "{{message}}", html.EscapeString(config.MaintenanceMessage))))
+}
+
+func (maintenanceType) retryAfter(c *app.RequestContext) {
+	if config.MaintenanceRetryAfter > 0 {
+		c.Response.Header.Set("Retry-After", strconv.Itoa(config.MaintenanceRetryAfter))
+	}
+}
+
+// loadCustomPage 读取 maintenance.page-file，读取失败时使用内置页面
+// Read maintenance.page-file, falling back to the built-in page on failure
+func loadCustomPage() {
+	if config.MaintenancePageFile == "" {
+		return
+	}
+	data, err := os.ReadFile(config.MaintenancePageFile)
+	if err != nil {
+		logrus.Warnf("Using the built-in maintenance page: %v", err)
+		return
+	}
+	customPage = string(data)
+}
+
+// writeRequest 请求方法是否会修改数据
+// Whether the request method modifies data
+func writeRequest(method string) bool {
+	switch method {
+	// PROPFIND 只列出 WebDAV 草稿中的文件 PROPFIND only lists the files of a WebDAV draft
+	case http.MethodGet, http.MethodHead, http.MethodOptions, "PROPFIND":
+		return false
+	}
+	return true
+}
+
+// writable 接口在只读期间是否仍可写入
+// Whether the endpoint still accepts writes while read-only
+func writable(path string) bool {
+	if !strings.HasPrefix(path, "/api/") {
+		return true
+	}
+	for _, prefix := range writablePaths {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
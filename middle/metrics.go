@@ -0,0 +1,40 @@
+package middle
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/metrics"
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// servedSiteKey 请求由站点服务中间件应答时设置，站点请求不经过路由
+// Set when the request is answered by the site serving middleware, site requests never reach the routes
+const servedSiteKey = "servedSite"
+
+var (
+	httpRequests = metrics.NewCounterVec("spage_http_requests_total",
+		"HTTP requests by method, route and status code.", "method", "route", "status")
+	httpDuration = metrics.NewHistogramVec("spage_http_request_duration_seconds",
+		"HTTP request latency by method and route.", nil, "method", "route")
+)
+
+// observeRequest 记录请求次数和耗时，路由使用注册时的模板以限制标签数量
+// Record the request count and latency, routes use their registered template to bound label cardinality
+func observeRequest(c *app.RequestContext, method string, latency time.Duration) {
+	route := requestRoute(c)
+	httpRequests.Inc(method, route, strconv.Itoa(c.Response.StatusCode()))
+	httpDuration.Observe(latency.Seconds(), method, route)
+}
+
+// requestRoute 请求匹配的路由模板，站点请求为 site，未匹配时为 unmatched
+// Route template matched by the request, site for site requests and unmatched when none matched
+func requestRoute(c *app.RequestContext) string {
+	switch {
+	case c.GetBool(servedSiteKey):
+		return "site"
+	case c.FullPath() == "":
+		return "unmatched"
+	}
+	return c.FullPath()
+}
@@ -0,0 +1,25 @@
+package middle
+
+import (
+	"context"
+
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+type requestIDType struct{}
+
+var RequestID = requestIDType{}
+
+// UseRequestID 中间件函数，为每个请求分配请求ID并写入上下文和响应头，合法的上游 X-Request-ID 会被沿用
+// Middleware function assigning every request an ID in the context and response headers, a valid upstream X-Request-ID is kept
+func (requestIDType) UseRequestID() app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		id := string(c.GetHeader(utils.RequestIDHeader))
+		if !utils.Log.ValidRequestID(id) {
+			id = utils.Log.NewRequestID()
+		}
+		c.Response.Header.Set(utils.RequestIDHeader, id)
+		c.Next(utils.Log.WithRequestID(ctx, id))
+	}
+}
@@ -0,0 +1,269 @@
+package middle
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/accesslog"
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/ratelimit"
+	"github.com/LiteyukiStudio/spage/serve"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol"
+)
+
+type serveType struct{}
+
+var Serve = serveType{}
+
+// UseServe 中间件函数，主机名属于托管站点时直接提供站点内容，否则交给后续路由
+// Middleware function serving hosted sites when the host belongs to one, otherwise passing to the next routes
+func (serveType) UseServe() app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		// 域名验证请求由路由应答，即使域名已指向站点 Domain verification requests are answered by routes, even when the domain already serves a site
+		if bytes.HasPrefix(c.Path(), []byte(utils.DomainChallengePath)) || bytes.HasPrefix(c.Path(), []byte("/.well-known/acme-challenge/")) {
+			c.Next(ctx)
+			return
+		}
+		start := time.Now()
+		decision, err := serve.Resolver.Resolve(ctx, serve.Request{
+			Host:           string(c.Host()),
+			Path:           string(c.Path()),
+			Query:          string(c.QueryArgs().QueryString()),
+			ClientIP:       c.ClientIP(),
+			AccessCookie:   string(c.Cookie(serve.AccessCookie)),
+			InstanceCookie: string(c.Cookie(serve.InstanceCookie)),
+			Authorization:  string(c.GetHeader("Authorization")),
+			Signature:      c.Query(serve.SignedURLParam),
+		})
+		if errors.Is(err, serve.ErrNoSite) {
+			// 项目域名只用于托管站点，未匹配的主机名不进入面板和接口 The project domain only hosts sites, unmatched hosts never reach the panel and API
+			if serve.Dedicated(string(c.Host())) {
+				c.Abort()
+				c.String(404, "Not Found")
+				return
+			}
+			c.Next(ctx)
+			return
+		}
+		c.Abort()
+		c.Set(servedSiteKey, true)
+		if err != nil {
+			utils.Log.Ctx(ctx).Error("Resolve site request failed: ", err)
+			c.String(500, "Internal Server Error")
+			return
+		}
+		// 维护期间所有托管站点返回维护页 Every hosted site answers with the maintenance page during maintenance
+		if config.UnderMaintenance() {
+			Maintenance.Page(c)
+			return
+		}
+		defer func() {
+			now := time.Now()
+			store.Traffic.Add(decision.SiteID, int64(c.Response.Header.ContentLength()), now)
+			recordPageView(c, decision.SiteID, now)
+			if config.SiteLogEnable {
+				entry := newAccessLog(c, start, now.Sub(start))
+				entry.SiteID = decision.SiteID
+				if config.AccessLogAnonymizeIP {
+					entry.ClientIP = accesslog.AnonymizeIP(entry.ClientIP)
+				}
+				store.AccessLog.Add(entry)
+			}
+			if accesslog.Enabled() {
+				accesslog.Write(newExportEntry(c, start))
+			}
+		}()
+		if RateLimit.Limited(ctx, c, ratelimit.GroupServe) {
+			return
+		}
+		if bytes.HasPrefix(c.Path(), []byte(serve.AccessPathPrefix)) {
+			Serve.access(ctx, c, decision)
+			return
+		}
+		for _, header := range decision.Headers {
+			c.Response.Header.Set(header.Name, header.Value)
+		}
+		if decision.Challenge != "" {
+			Serve.challenge(c, decision)
+			return
+		}
+		if decision.Proxy != "" {
+			Serve.proxy(ctx, c, decision.Proxy)
+			return
+		}
+		if !decision.Access.Allowed || decision.Entry == nil {
+			c.String(decision.Status, http.StatusText(decision.Status))
+			return
+		}
+		// 客户端缓存仍然有效时不发送内容 Send no content while the client's cache is still valid
+		if method := string(c.Method()); method == "GET" || method == "HEAD" {
+			etag, ok := decision.NotModified(string(c.GetHeader("If-None-Match")), string(c.GetHeader("If-Modified-Since")))
+			if ok {
+				if etag != "" {
+					c.Response.Header.Set("ETag", etag)
+				}
+				c.Status(304)
+				return
+			}
+		}
+		if Serve.serveRange(ctx, c, decision) {
+			return
+		}
+		body, size, encoding, err := decision.OpenEncoded(string(c.GetHeader("Accept-Encoding")))
+		if err != nil {
+			utils.Log.Ctx(ctx).Error("Open site entry failed: ", err)
+			c.String(500, "Internal Server Error")
+			return
+		}
+		if encoding != "" {
+			c.Response.Header.Set("Content-Encoding", encoding)
+			if etag := c.Response.Header.Get("ETag"); etag != "" {
+				c.Response.Header.Set("ETag", serve.EncodedETag(etag, encoding))
+			}
+		}
+		if decision.Throttled {
+			body = serve.Throttle(body)
+		}
+		c.Status(decision.Status)
+		c.SetBodyStream(body, int(size))
+	}
+}
+
+// challenge 要求访问者登录受保护的站点：浏览器访问密码站点时显示登录页，访问成员站点或私有实例时跳转到面板登录，其他客户端使用 Basic 认证，
+// 成员站点和私有实例以个人访问令牌作为密码
+// Ask the visitor to sign in to the protected site: browsers get a login page on password sites and go through the panel login on member sites or private instances,
+// other clients use Basic auth with a personal access token as the password on member sites and private instances
+func (serveType) challenge(c *app.RequestContext, decision *serve.Decision) {
+	browser := bytes.Contains(c.GetHeader("Accept"), []byte("text/html"))
+	panel := decision.Challenge == string(constants.SiteAccessMembers) || decision.Challenge == serve.ChallengeInstance
+	realm := decision.SiteName()
+	if decision.Challenge == serve.ChallengeInstance {
+		realm = config.OGBrand
+	}
+	switch {
+	case panel && browser:
+		target := string(c.URI().Scheme()) + "://" + string(c.Host()) + string(c.Request.RequestURI())
+		c.Redirect(http.StatusFound, []byte(strings.TrimSuffix(config.FrontEndURL, "/")+"/api/v1/site/"+strconv.Itoa(int(decision.SiteID))+"/access?return="+url.QueryEscape(target)))
+	case browser:
+		c.Data(decision.Status, "text/html; charset=utf-8", serve.LoginPage(decision.SiteName(), string(c.Request.RequestURI()), false))
+	default:
+		c.Response.Header.Set("WWW-Authenticate", `Basic realm="`+strings.ReplaceAll(realm, `"`, "")+`", charset="UTF-8"`)
+		c.String(decision.Status, http.StatusText(decision.Status))
+	}
+}
+
+// access 处理站点域名下 serve.AccessPathPrefix 的登录、凭据兑换和退出请求
+// Handle the sign-in, ticket redemption and sign-out requests under serve.AccessPathPrefix of the site's host
+func (serveType) access(ctx context.Context, c *app.RequestContext, decision *serve.Decision) {
+	c.Response.Header.Set("Cache-Control", "no-store")
+	var (
+		cookie  = serve.AccessCookie
+		session string
+		err     error
+		target  string
+	)
+	switch strings.TrimPrefix(string(c.Path()), serve.AccessPathPrefix) {
+	case "login":
+		if string(c.Method()) != "POST" {
+			c.String(http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+			return
+		}
+		if RateLimit.Limited(ctx, c, ratelimit.GroupAuth) {
+			return
+		}
+		target = serve.SafeReturn(string(c.PostForm("return")))
+		session, err = serve.Protection.SignIn(ctx, string(c.Host()), string(c.PostForm("password")))
+		if errors.Is(err, serve.ErrAccessDenied) {
+			c.Data(http.StatusUnauthorized, "text/html; charset=utf-8", serve.LoginPage(decision.SiteName(), target, true))
+			return
+		}
+	case "access":
+		target = serve.SafeReturn(c.Query("return"))
+		cookie, session, err = serve.Protection.Redeem(ctx, string(c.Host()), c.Query("ticket"))
+		if errors.Is(err, serve.ErrAccessDenied) {
+			c.String(http.StatusForbidden, http.StatusText(http.StatusForbidden))
+			return
+		}
+	case "logout":
+		c.SetCookie(serve.AccessCookie, "", -1, "/", "", protocol.CookieSameSiteLaxMode, string(c.URI().Scheme()) == "https", true)
+		c.SetCookie(serve.InstanceCookie, "", -1, "/", "", protocol.CookieSameSiteLaxMode, string(c.URI().Scheme()) == "https", true)
+		c.Redirect(http.StatusSeeOther, []byte("/"))
+		return
+	default:
+		c.String(http.StatusNotFound, http.StatusText(http.StatusNotFound))
+		return
+	}
+	if err != nil {
+		utils.Log.Ctx(ctx).Error("Sign in to protected site failed: ", err)
+		c.String(500, "Internal Server Error")
+		return
+	}
+	c.SetCookie(cookie, session, config.SiteAccessSessionTTL, "/", "", protocol.CookieSameSiteLaxMode, string(c.URI().Scheme()) == "https", true)
+	c.Redirect(http.StatusSeeOther, []byte(target))
+}
+
+// proxy 转发请求到 _redirects 的代理目标并回传响应
+// Forward the request to the proxy target of _redirects and pass the response back
+func (serveType) proxy(ctx context.Context, c *app.RequestContext, target string) {
+	resp, err := serve.Proxy(ctx, target, string(c.Method()), func(name string) string {
+		return string(c.Request.Header.Peek(name))
+	}, bytes.NewReader(c.Request.Body()))
+	if err != nil {
+		utils.Log.Ctx(ctx).Warnf("Proxy to %s failed: %v", target, err)
+		c.String(502, http.StatusText(502))
+		return
+	}
+	for name, values := range resp.Header {
+		for i, value := range values {
+			if i == 0 {
+				c.Response.Header.Set(name, value)
+			} else {
+				c.Response.Header.Add(name, value)
+			}
+		}
+	}
+	c.Status(resp.StatusCode)
+	c.SetBodyStream(resp.Body, int(resp.ContentLength))
+}
+
+// serveRange 按 Range 请求头返回文件的一部分，返回 true 表示已写入响应；范围请求不使用压缩版本，无法使用的 Range 请求头被忽略
+// Answer with part of the file according to the Range header, true when the response is written; ranges never use compressed versions and unusable Range headers are ignored
+func (serveType) serveRange(ctx context.Context, c *app.RequestContext, decision *serve.Decision) bool {
+	header := string(c.GetHeader("Range"))
+	if method := string(c.Method()); header == "" || method != "GET" && method != "HEAD" || !decision.RangeApplies(string(c.GetHeader("If-Range"))) {
+		return false
+	}
+	size := decision.Size()
+	start, length, err := serve.ParseRange(header, size)
+	if errors.Is(err, serve.ErrRangeNotSatisfiable) {
+		c.Response.Header.Set("Content-Range", "bytes */"+strconv.FormatInt(size, 10))
+		c.String(http.StatusRequestedRangeNotSatisfiable, http.StatusText(http.StatusRequestedRangeNotSatisfiable))
+		return true
+	}
+	if err != nil {
+		return false
+	}
+	body, err := decision.OpenRange(start, length)
+	if err != nil {
+		utils.Log.Ctx(ctx).Error("Open site entry range failed: ", err)
+		c.String(500, "Internal Server Error")
+		return true
+	}
+	if decision.Throttled {
+		body = serve.Throttle(body)
+	}
+	c.Response.Header.Set("Content-Range", "bytes "+strconv.FormatInt(start, 10)+"-"+strconv.FormatInt(start+length-1, 10)+"/"+strconv.FormatInt(size, 10))
+	c.Status(http.StatusPartialContent)
+	c.SetBodyStream(body, int(length))
+	return true
+}
@@ -0,0 +1,60 @@
+package middle
+
+import (
+	"context"
+	"crypto/subtle"
+	"strconv"
+	"strings"
+
+	"github.com/LiteyukiStudio/spage/certs"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+type tlsType struct{}
+
+var TLS = tlsType{}
+
+// plainHTTPPaths 开启 HTTP 重定向后仍通过 HTTP 应答的路径前缀，包括所有权和 ACME 验证以及探针
+// Path prefixes still answered over HTTP when redirecting to HTTPS, covering ownership and ACME challenges and probes
+var plainHTTPPaths = []string{"/.well-known/", "/healthz", "/readyz", "/metrics"}
+
+// UseTLS 中间件函数，信任内置 TLS 终止转发的客户端地址和协议，按配置发送 HSTS 并将 HTTP 请求重定向到 HTTPS
+// Middleware function trusting the client address and scheme forwarded by the built-in TLS termination, sending HSTS and redirecting HTTP requests to HTTPS as configured
+func (tlsType) UseTLS() app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		if !certs.Serving() {
+			c.Next(ctx)
+			return
+		}
+		token := c.Request.Header.Peek(certs.ProxyHeader)
+		c.Request.Header.Del(certs.ProxyHeader)
+		if subtle.ConstantTimeCompare(token, []byte(certs.ProxyToken())) == 1 {
+			c.Request.URI().SetScheme("https")
+			ip := string(c.Request.Header.Peek("X-Forwarded-For"))
+			c.SetClientIPFunc(func(*app.RequestContext) string { return ip })
+			if certs.Termination.HSTSMaxAge > 0 {
+				c.Response.Header.Set("Strict-Transport-Security", "max-age="+strconv.Itoa(certs.Termination.HSTSMaxAge))
+			}
+			c.Next(ctx)
+			return
+		}
+		if certs.Termination.RedirectHTTP && !plainHTTP(string(c.Request.URI().Path())) {
+			c.Redirect(consts.StatusPermanentRedirect, []byte(certs.RedirectURL(string(c.Host()), string(c.Request.RequestURI()), certs.HTTPSPort)))
+			c.Abort()
+			return
+		}
+		c.Next(ctx)
+	}
+}
+
+// plainHTTP 路径是否应继续通过 HTTP 应答
+// Whether the path keeps being answered over HTTP
+func plainHTTP(path string) bool {
+	for _, prefix := range plainHTTPPaths {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
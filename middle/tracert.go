@@ -5,6 +5,8 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/utils"
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/sirupsen/logrus"
 )
@@ -25,16 +27,53 @@ func (traceType) UseTrace() app.HandlerFunc {
 
 		latency := time.Since(start)
 		statusCode := c.Response.StatusCode()
+		observeRequest(c, method, latency)
 
-		// 只记录必要信息，使用简洁格式
+		// 消息保持简洁，字段便于结构化日志检索
+		// Keep the message short, fields make structured logs searchable
 		message := method + " " + path + " " + strconv.Itoa(statusCode) + " " + latency.String()
+		entry := utils.Log.Ctx(ctx).WithFields(logrus.Fields{
+			"method":     method,
+			"path":       path,
+			"status":     statusCode,
+			"latency_ms": latency.Milliseconds(),
+			"host":       string(c.Host()),
+			"client_ip":  c.ClientIP(),
+		})
 
 		if statusCode >= 500 {
-			logrus.Error(message)
+			entry.Error(message)
 		} else if statusCode >= 400 {
-			logrus.Warn(message)
+			entry.Warn(message)
 		} else {
-			logrus.Info(message)
+			entry.Info(message)
 		}
+
+		// 仅在有人订阅实时日志时构造访问日志
+		// Only build the access log when someone is tailing
+		if AccessLog.Active() {
+			AccessLog.Publish(newAccessLog(c, start, latency))
+		}
+	}
+}
+
+// newAccessLog 由请求上下文构造访问日志
+// Build an access log from the request context
+func newAccessLog(c *app.RequestContext, start time.Time, latency time.Duration) models.AccessLog {
+	bytes := c.Response.Header.ContentLength()
+	if bytes < 0 {
+		bytes = len(c.Response.Body())
+	}
+	return models.AccessLog{
+		CreatedAt: start,
+		Host:      string(c.Host()),
+		Method:    string(c.Request.Header.Method()),
+		Path:      string(c.Request.URI().Path()),
+		Status:    c.Response.StatusCode(),
+		Bytes:     bytes,
+		LatencyMs: latency.Milliseconds(),
+		ClientIP:  c.ClientIP(),
+		Referer:   string(c.Request.Header.Peek("Referer")),
+		UserAgent: string(c.Request.Header.UserAgent()),
 	}
 }
@@ -0,0 +1,70 @@
+package middle
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/LiteyukiStudio/spage/tracing"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type tracingType struct{}
+
+var Tracing = tracingType{}
+
+// UseTracing 中间件函数，为每个请求创建服务端 span，沿用上游 traceparent 请求头中的链路
+// Middleware function creating a server span for every request, continuing the trace of an upstream traceparent header
+func (tracingType) UseTracing() app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		method := string(c.Request.Header.Method())
+		ctx = tracing.Propagator.Extract(ctx, headerCarrier{&c.Request.Header})
+		ctx, span := tracing.Start(ctx, method, trace.SpanKindServer,
+			semconv.HTTPRequestMethodKey.String(method),
+			semconv.URLPath(string(c.Request.URI().Path())),
+			semconv.ServerAddress(string(c.Host())),
+			semconv.ClientAddress(c.ClientIP()),
+		)
+		defer span.End()
+		if id := utils.Log.RequestID(ctx); id != "" {
+			span.SetAttributes(attribute.String("spage.request_id", id))
+		}
+
+		c.Next(ctx)
+
+		route := requestRoute(c)
+		status := c.Response.StatusCode()
+		span.SetName(method + " " + route)
+		span.SetAttributes(semconv.HTTPRoute(route), semconv.HTTPResponseStatusCode(status))
+		if status >= 500 {
+			span.SetStatus(codes.Error, fmt.Sprintf("status %d", status))
+		}
+	}
+}
+
+// headerCarrier 让传播器读取 Hertz 请求头
+// Lets the propagator read Hertz request headers
+type headerCarrier struct {
+	header *protocol.RequestHeader
+}
+
+func (h headerCarrier) Get(key string) string {
+	return string(h.header.Peek(key))
+}
+
+func (h headerCarrier) Set(key, value string) {
+	h.header.Set(key, value)
+}
+
+func (h headerCarrier) Keys() []string {
+	var keys []string
+	h.header.VisitAll(func(key, _ []byte) {
+		keys = append(keys, string(key))
+	})
+	return keys
+}
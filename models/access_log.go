@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// AccessLog 站点访问日志，实时日志流与持久化存储共用该结构
+// Site access log, shared by the live tail stream and persistent storage
+type AccessLog struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`                                               // 日志ID Log ID
+	CreatedAt time.Time `gorm:"index;index:idx_access_logs_site_time,priority:2" json:"created_at"` // 请求时间 Request time
+	SiteID    uint      `gorm:"not null;index:idx_access_logs_site_time,priority:1" json:"site_id"` // 站点ID Site ID
+	Host      string    `gorm:"size:255" json:"host"`                                               // 请求主机 Request host
+	Method    string    `gorm:"size:16" json:"method"`                                              // 请求方法 Request method
+	Path      string    `gorm:"size:2048" json:"path"`                                              // 请求路径 Request path
+	Status    int       `gorm:"index" json:"status"`                                                // 响应状态码 Response status code
+	Bytes     int       `json:"bytes"`                                                              // 响应字节数 Response bytes
+	LatencyMs int64     `json:"latency_ms"`                                                         // 请求耗时（毫秒） Latency in milliseconds
+	ClientIP  string    `gorm:"size:64" json:"client_ip"`                                           // 客户端IP Client IP
+	Referer   string    `gorm:"size:2048" json:"referer"`                                           // 来源 Referer
+	UserAgent string    `gorm:"size:512" json:"user_agent"`                                         // 用户代理 User agent
+}
+
+// TableName 自定义表名 Custom table name
+func (AccessLog) TableName() string {
+	return "access_logs"
+}
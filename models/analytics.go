@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// 访问分析的维度 Dimensions of the analytics
+const (
+	AnalyticsPath     = "path"     // 页面路径 Page path
+	AnalyticsReferrer = "referrer" // 来源主机 Referring host
+	AnalyticsCountry  = "country"  // 国家或地区代码 Country or region code
+	AnalyticsStatus   = "status"   // 响应状态码 Response status code
+)
+
+// AnalyticsOther 超出每天记录上限的值合并到该值
+// Values beyond the daily limit are merged into this value
+const AnalyticsOther = "(other)"
+
+// SiteAnalytics 站点每天按维度汇总的页面浏览量，不记录访问者的 IP 等个人信息
+// Page views of a site per day rolled up by dimension, no personal data such as visitor IPs is stored
+type SiteAnalytics struct {
+	SiteID    uint      `gorm:"primaryKey;autoIncrement:false"`          // 站点ID Site ID
+	Day       time.Time `gorm:"primaryKey;index:idx_site_analytics_day"` // UTC 日期的零点 Midnight of the UTC day
+	Dimension string    `gorm:"primaryKey;size:16"`                      // 维度 Dimension
+	Value     string    `gorm:"primaryKey;size:255"`                     // 维度的值 Value of the dimension
+	Views     int64     `gorm:"not null;default:0"`                      // 浏览量 Page views
+}
+
+// TableName 重写表名
+// Rewrite table name
+func (SiteAnalytics) TableName() string {
+	return "site_analytics"
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"gorm.io/gorm"
+)
+
+// APIToken 个人访问令牌，只保存令牌的哈希值，明文仅在创建时返回一次
+// Personal access token, only the hash is stored and the plain token is returned once on creation
+type APIToken struct {
+	gorm.Model
+	UserID     uint                   `gorm:"not null;index"`                                // 所属用户ID Owner user ID
+	User       User                   `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"` // 所属用户 Owner user
+	Name       string                 `gorm:"not null"`                                      // 令牌名称 Token name
+	Prefix     string                 `gorm:"not null"`                                      // 令牌开头部分，用于识别 Leading part of the token, for identification
+	Hash       string                 `gorm:"not null;uniqueIndex"`                          // 令牌的 SHA-256 哈希 SHA-256 hash of the token
+	Scopes     []constants.TokenScope `gorm:"serializer:json;type:json;default:'[]'"`        // 权限范围 Scopes
+	ExpiresAt  *time.Time             // 过期时间，空为永不过期 Expiry time, never expires when empty
+	LastUsedAt *time.Time             // 最后使用时间 Last used time
+}
+
+// TableName 重写表名
+// Rewrite table name
+func (APIToken) TableName() string {
+	return "api_tokens"
+}
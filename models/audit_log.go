@@ -0,0 +1,48 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// AuditLog 审计日志，每条记录的哈希包含上一条记录的哈希，修改或删除中间的记录会使校验失败
+// Audit log, each entry's hash covers the previous entry's hash so editing or removing entries breaks verification
+type AuditLog struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`                 // 日志ID Log ID
+	CreatedAt  time.Time `gorm:"not null;index" json:"created_at"`     // 发生时间 Event time
+	ActorID    uint      `gorm:"index" json:"actor_id"`                // 操作者ID，0 表示匿名 Actor ID, 0 for anonymous
+	ActorName  string    `gorm:"size:255" json:"actor_name"`           // 操作者名称 Actor name
+	Action     string    `gorm:"size:64;not null;index" json:"action"` // 操作，例如 release.create Action, e.g. release.create
+	TargetType string    `gorm:"size:64;index" json:"target_type"`     // 目标类型 Target type
+	TargetID   string    `gorm:"size:64" json:"target_id"`             // 目标ID Target ID
+	IP         string    `gorm:"size:64" json:"ip"`                    // 客户端IP Client IP
+	Details    string    `gorm:"type:text" json:"details"`             // JSON 格式的详细信息 Details in JSON
+	PrevHash   string    `gorm:"size:64;uniqueIndex" json:"prev_hash"` // 上一条记录的哈希，唯一以防止链分叉 Hash of the previous entry, unique so the chain cannot fork
+	Hash       string    `gorm:"size:64;not null" json:"hash"`         // 本条记录的哈希 Hash of this entry
+}
+
+// TableName 自定义表名 Custom table name
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
+
+// ComputeHash 计算记录的哈希，时间统一为 UTC 微秒精度以在各数据库间保持一致
+// Compute the entry's hash, the time is normalized to UTC microseconds so it is stable across databases
+func (l *AuditLog) ComputeHash() string {
+	fields, _ := json.Marshal([]string{
+		l.PrevHash,
+		l.CreatedAt.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano),
+		strconv.FormatUint(uint64(l.ActorID), 10),
+		l.ActorName,
+		l.Action,
+		l.TargetType,
+		l.TargetID,
+		l.IP,
+		l.Details,
+	})
+	sum := sha256.Sum256(fields)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Blob 按内容哈希存储的站点文件，部署清单通过哈希引用，同样的内容只存储一份
+// A site file stored by content hash, referenced from deployment manifests by hash so identical contents are stored once
+type Blob struct {
+	Hash      string    `gorm:"primaryKey;size:64"` // 内容的 SHA-256 SHA-256 of the content
+	Size      int64     `gorm:"not null"`           // 字节数 Size in bytes
+	CreatedAt time.Time // 首次上传时间 First upload time
+}
+
+// TableName 重写表名
+// Rewrite table name
+func (Blob) TableName() string {
+	return "blobs"
+}
+
+// FileBlob 部署清单引用的文件内容，用于统计存储用量和查找不再被引用的内容
+// File contents referenced by a deployment manifest, used to account storage usage and to find contents no longer referenced
+type FileBlob struct {
+	FileID uint   `gorm:"primaryKey;autoIncrement:false"` // 清单文件ID Manifest file ID
+	Hash   string `gorm:"primaryKey;size:64;index"`       // 内容的 SHA-256 SHA-256 of the content
+}
+
+// TableName 重写表名
+// Rewrite table name
+func (FileBlob) TableName() string {
+	return "file_blobs"
+}
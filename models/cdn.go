@@ -0,0 +1,22 @@
+package models
+
+import "gorm.io/gorm"
+
+// CDNIntegration 项目的 CDN 集成，站点切换版本或清除路径后调用服务商的接口刷新缓存
+// CDN integration of a project, the provider's API is called to purge the cache after a site switches versions or purges paths
+type CDNIntegration struct {
+	gorm.Model
+	ProjectID uint    `gorm:"not null;index"`                                                    // 项目ID Project ID
+	Project   Project `gorm:"foreignKey:ProjectID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"` // 项目 Project
+	Provider  string  `gorm:"not null"`                                                          // 服务商，cloudflare、bunny 或 webhook Provider, cloudflare, bunny or webhook
+	ZoneID    string  // Cloudflare 的区域ID Zone ID of Cloudflare
+	URL       string  // webhook 的地址 URL of the webhook
+	Token     string  `gorm:"not null;serializer:encrypted"` // Cloudflare 的 API 令牌、BunnyCDN 的 API 密钥或 webhook 的签名密钥，加密保存 API token of Cloudflare, API key of BunnyCDN or signing secret of the webhook, stored encrypted
+	Active    bool    `gorm:"not null;default:true"`         // 是否启用 Whether enabled
+}
+
+// TableName 重写表名
+// Rewrite table name
+func (CDNIntegration) TableName() string {
+	return "cdn_integrations"
+}
@@ -0,0 +1,20 @@
+package models
+
+import "gorm.io/gorm"
+
+// ContentPolicy 管理员为单个组织设置的内容策略，字段为空时使用配置文件中的默认值，空列表表示不禁止
+// Content policy set by an administrator for one organization, empty fields fall back to the configured defaults and an empty list disallows nothing
+type ContentPolicy struct {
+	gorm.Model
+	OrganizationID    uint     `gorm:"not null;uniqueIndex"` // 组织ID Organization ID
+	BlockedExtensions []string `gorm:"serializer:json"`      // 禁止的扩展名 Disallowed extensions
+	BlockedMIMETypes  []string `gorm:"serializer:json"`      // 禁止的 MIME 类型 Disallowed MIME types
+	Action            string   `gorm:"size:16"`              // reject 或 strip Reject or strip
+	Serve             *bool    // 访问时是否同样拒绝 Whether requests are refused too
+}
+
+// TableName 重写表名
+// Rewrite table name
+func (ContentPolicy) TableName() string {
+	return "content_policies"
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// CronTask 按 cron 表达式运行的定时任务的运行状态，多个实例通过条件更新领取同一次运行
+// Run state of a task scheduled by a cron expression, instances claim a run through a conditional update
+type CronTask struct {
+	Name           string     `gorm:"primaryKey;size:64"` // 任务名称 Task name
+	Schedule       string     `gorm:"size:128"`           // 计算下次运行时间所用的表达式 Expression the next run was computed from
+	NextRunAt      *time.Time // 下次运行时间，禁用时为空 Next run time, nil when disabled
+	RunningSince   *time.Time // 正在运行时的开始时间 Start of the run in progress
+	LastRunAt      *time.Time // 最近一次开始运行的时间 Start of the latest run
+	LastFinishedAt *time.Time // 最近一次结束的时间 End of the latest run
+	LastTrigger    string     `gorm:"size:16"`   // 最近一次运行的触发方式 How the latest run was triggered
+	LastStatus     string     `gorm:"size:16"`   // 最近一次运行的结果 Outcome of the latest run
+	LastError      string     `gorm:"type:text"` // 最近一次的错误 Error of the latest run
+	LastResult     string     `gorm:"type:text"` // JSON 格式的最近一次结果 Result of the latest run as JSON
+	UpdatedAt      time.Time  // 更新时间 Updated time
+}
+
+// TableName 重写表名
+// Rewrite table name
+func (CronTask) TableName() string {
+	return "cron_tasks"
+}
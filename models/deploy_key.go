@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DeployKey 项目部署密钥，只能上传和激活所属项目的站点发布，只保存密钥的哈希值，明文仅在创建时返回一次；
+// 以创建者的身份执行，创建者失去项目的部署权限后密钥随之失效
+// Project deploy key, which can only upload and activate releases of the sites of its project, only the hash is stored and the plain key is returned once on creation;
+// it acts as its creator and stops working once the creator can no longer deploy to the project
+type DeployKey struct {
+	gorm.Model
+	ProjectID   uint       `gorm:"not null;index"`                                   // 所属项目ID Project ID
+	Project     Project    `gorm:"foreignKey:ProjectID;constraint:OnDelete:CASCADE"` // 所属项目 Project
+	CreatedByID uint       `gorm:"not null"`                                         // 创建者ID Creator ID
+	Name        string     `gorm:"not null"`                                         // 密钥名称 Key name
+	Prefix      string     `gorm:"not null"`                                         // 密钥开头部分，用于识别 Leading part of the key, for identification
+	Hash        string     `gorm:"not null;uniqueIndex"`                             // 密钥的 SHA-256 哈希 SHA-256 hash of the key
+	ExpiresAt   *time.Time // 过期时间，空为永不过期 Expiry time, never expires when empty
+	LastUsedAt  *time.Time // 最后使用时间 Last used time
+}
+
+// TableName 重写表名
+// Rewrite table name
+func (DeployKey) TableName() string {
+	return "deploy_keys"
+}
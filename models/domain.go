@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"gorm.io/gorm"
+)
+
+// CustomDomain 绑定到站点的自定义域名，验证所有权后才会提供服务和签发证书
+// Custom domain bound to a site, served and issued a certificate only after ownership is verified
+type CustomDomain struct {
+	gorm.Model
+	SiteID        uint                         `gorm:"not null;index"`                                                 // 站点ID Site ID
+	Site          Site                         `gorm:"foreignKey:SiteID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"` // 站点 Site
+	Domain        string                       `gorm:"not null;uniqueIndex"`                                           // 小写域名 Lowercase domain
+	Method        constants.DomainVerifyMethod `gorm:"not null;default:dns"`                                           // 验证方式 Verification method
+	Token         string                       `gorm:"not null"`                                                       // 验证令牌 Verification token
+	VerifiedAt    *time.Time                   // 验证通过时间，空为未验证 Verified time, unverified when empty
+	LastCheckedAt *time.Time                   // 最后一次验证时间 Last verification attempt
+	LastError     string                       // 最后一次验证失败的原因 Reason of the last failed verification
+}
+
+// TableName 重写表名
+// Rewrite table name
+func (CustomDomain) TableName() string {
+	return "custom_domains"
+}
+
+// ACMECertificate ACME 客户端缓存的账户密钥和证书，多个实例共用同一份
+// Account keys and certificates cached by the ACME client, shared by all instances
+type ACMECertificate struct {
+	Name      string    `gorm:"primaryKey"` // 缓存键，通常为域名 Cache key, usually the domain
+	Data      []byte    `gorm:"not null"`   // PEM 编码的证书和私钥 PEM encoded certificate and private key
+	UpdatedAt time.Time // 更新时间 Updated time
+}
+
+// TableName 重写表名
+// Rewrite table name
+func (ACMECertificate) TableName() string {
+	return "acme_certificates"
+}
@@ -1,6 +1,11 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"gorm.io/gorm"
+)
 
 // 用户模型
 type User struct {
@@ -10,12 +15,18 @@ type User struct {
 	Email         *string         `gorm:"unique"`                          // 用户的电子邮件地址，只有用户的电子邮件地址是唯一的（用于 oidc 身份验证） User's email address, only the user's email address is unique (used for oidc authentication)
 	Description   string          `gorm:"default:'No description.'"`       // 用户描述 User description
 	AvatarURL     *string         `gorm:"column:avatar_url"`               // 留空以使用 Gravatar Leave blank to use Gravatar
-	Role          string          `gorm:"not null;default:member"`         // 用户的全局角色 User's global role
+	AvatarHash    string          `gorm:"column:avatar_hash"`              // 上传的头像的内容哈希，优先于 AvatarURL Content hash of the uploaded avatar, preferred over AvatarURL
+	Role          constants.Role  `gorm:"not null;default:user"`           // 用户的全局角色 User's global role
 	Organizations []*Organization `gorm:"many2many:organization_members;"` // 隶属于许多组织 Many organizations the user belongs to
 	ProjectLimit  int             `gorm:"default:-1"`                      // 用户的项目限制，0 表示无限制 User's project limit, 0 means no limit
 	Language      string          `gorm:"default:'zh-cn'"`                 // 用户的语言，默认为英语 User's language, default to English
 	Flag          string          `gorm:"default:'0'"`                     // system_admin 的另一面旗帜 The other side of system_admin flag
 	Password      *string         `gorm:"column:password"`                 // 用户的密码（经过哈希处理），仅用于本地身份验证 User's password (hashed), only used for local authentication
+
+	TenantID        *uint      `gorm:"index"` // 所属租户，空为实例本身 Tenant the user belongs to, the instance itself when empty
+	EmailVerifiedAt *time.Time // 邮箱验证通过的时间，空为未验证，修改邮箱后清空 Time the email was verified, unverified when empty and cleared when the email changes
+	DeleteAt        *time.Time `gorm:"index"` // 用户申请删除账号后的删除时间，空为未申请 Time the account is deleted at after the user asked for it, empty when not requested
+	DisabledAt      *time.Time // 管理员停用账号的时间，停用的账号无法登录或调用 API，空为正常 Time an administrator disabled the account, disabled accounts can neither sign in nor call the API, active when empty
 }
 
 // 用户
@@ -33,9 +44,12 @@ type Organization struct {
 	Email        *string `gorm:"column:email"`                    // 组织的电子邮件地址 Organization's email address
 	Description  string  `gorm:"default:'No description.'"`       // 组织描述 Organization description
 	AvatarURL    *string `gorm:"column:avatar_url"`               // 留空以使用 Gravatar Leave blank to use Gravatar
-	Members      []*User `gorm:"many2many:organization_members;"` // 组织的成员包含创建者 (including the creator)
-	Owners       []User  `gorm:"many2many:organization_owners;"`  // 组织的所有者（无反向关系）包含创建者 (including the creator)
+	AvatarHash   string  `gorm:"column:avatar_hash"`              // 上传的标志的内容哈希，优先于 AvatarURL Content hash of the uploaded logo, preferred over AvatarURL
+	Members      []*User `gorm:"many2many:organization_members;"` // 组织的成员包含创建者，角色见 OrgMember Members including the creator, see OrgMember for roles
 	ProjectLimit int     `gorm:"default:0"`                       // 组织的项目限制，0：遵循策略，-1：无限制 Organization's project limit, 0: follow the policy, -1: unlimited
+
+	PreviewTemplate *string `gorm:"column:preview_template"` // 自定义预览图模板对象键 Custom preview image template object key
+	TenantID        *uint   `gorm:"index"`                   // 所属租户，空为实例本身 Tenant the organization belongs to, the instance itself when empty
 }
 
 // 组织
@@ -48,13 +62,28 @@ func (Organization) TableName() string {
 // Project Model
 type Project struct {
 	gorm.Model
-	Name        string  `gorm:"not null;unique"`           // 项目的唯一名称 Project's unique name
-	DisplayName *string `gorm:"column:display_name"`       // 项目的显示名称 Project's display name
-	Description string  `gorm:"default:'No description.'"` // 项目描述 Project description
-	OwnerID     uint    `gorm:"not null"`                  // 所有者 ID（用户 ID 或组织 ID） Owner ID (user ID or organization ID)
-	OwnerType   string  `gorm:"not null"`                  // 所有者类型，可以是用户或组织 Owner type, can be user or organization
-	Owners      []User  `gorm:"many2many:project_owners;"` // 项目的所有者，无反向关系 Project's owners, no reverse relation
-	SiteLimit   int     `gorm:"default:0"`                 // 项目的站点限制，0：遵循策略，-1：无限制 Project's site limit, 0: follow the policy, -1: unlimited
+	Name        string               `gorm:"not null;unique"`           // 项目的唯一名称 Project's unique name
+	DisplayName *string              `gorm:"column:display_name"`       // 项目的显示名称 Project's display name
+	Description string               `gorm:"default:'No description.'"` // 项目描述 Project description
+	OwnerID     uint                 `gorm:"not null"`                  // 所有者 ID（用户 ID 或组织 ID） Owner ID (user ID or organization ID)
+	OwnerType   string               `gorm:"not null"`                  // 所有者类型，可以是用户或组织 Owner type, can be user or organization
+	SiteLimit   int                  `gorm:"default:0"`                 // 项目的站点限制，0：遵循策略，-1：无限制 Project's site limit, 0: follow the policy, -1: unlimited
+	Visibility  constants.Visibility `gorm:"not null;default:public"`   // 项目的可见性 Project visibility
+
+	PrimarySiteID *uint  `gorm:"column:primary_site_id"` // 通过项目主机名访问的站点，为空时使用最早创建的站点 Site served at the project host, the oldest site when empty
+	MaxUploadSize *int64 // 管理员为项目设置的单次上传最大字节数，为空时使用所有者的配额 Largest single upload in bytes set by an administrator, the owner's quota applies when empty
+
+	DirectoryListing bool `gorm:"default:false"` // 没有 index.html 的目录是否显示自动生成的目录索引 Whether directories without index.html show a generated directory index
+	ImmutableAssets  bool `gorm:"default:true"`  // 文件名带内容哈希的资源是否作为不可变内容长期缓存 Whether assets with a content hash in their file name are cached for long as immutable
+
+	Protected bool `gorm:"default:false"` // 新部署是否需要维护者批准后才能上线 Whether new deployments only go live once a maintainer approves them
+
+	TenantID *uint `gorm:"index"` // 所有者所属的租户，创建时从所有者复制 Tenant of the owner, copied from the owner on creation
+
+	KeepReleases    *int // 每个站点至少保留的最新版本数，为空时使用 release.keep-last，0 为不按数量保留 Newest versions kept per site at least, release.keep-last applies when empty and 0 keeps none by count
+	KeepReleaseDays *int // 版本至少保留的天数，为空时使用 release.keep-days，0 为不按时间保留 Days versions are kept at least, release.keep-days applies when empty and 0 keeps none by age
+
+	Labels []ProjectLabel `gorm:"foreignKey:ProjectID"` // 项目标签，仅在需要时加载 Project labels, only loaded when needed
 }
 
 // 项目
@@ -62,3 +91,16 @@ type Project struct {
 func (Project) TableName() string {
 	return "projects"
 }
+
+// ProjectLabel 项目的键值标签，例如框架、仓库地址和环境，可用于筛选项目列表
+// Key/value label of a project such as its framework, repository URL or environment, usable to filter project lists
+type ProjectLabel struct {
+	ProjectID uint   `gorm:"primaryKey;autoIncrement:false"`                  // 项目ID Project ID
+	Key       string `gorm:"primaryKey;size:63;index:idx_project_labels_key"` // 标签键 Label key
+	Value     string `gorm:"size:255;not null;default:''"`                    // 标签值，可以为空 Label value, may be empty
+}
+
+// 项目标签表名 Project label table name
+func (ProjectLabel) TableName() string {
+	return "project_labels"
+}
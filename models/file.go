@@ -4,12 +4,15 @@ import "gorm.io/gorm"
 
 type File struct {
 	gorm.Model
-	ID   uint   `gorm:"primaryKey" json:"id"` // 文件ID File ID
-	Path string `gorm:"not null" json:"path"` // 文件路径，相较于根目录的相对路径 File path, relative to the root directory
-	Hash string `gorm:"not null" json:"hash"` // 文件哈希值 File hash
+	ID   uint   `gorm:"primaryKey" json:"id"`           // 文件ID File ID
+	Path string `gorm:"not null" json:"path"`           // 存储对象键，例如 releases/<site>/<tag>/<time>.zip Storage object key, e.g. releases/<site>/<tag>/<time>.zip
+	Hash string `gorm:"not null" json:"hash"`           // 文件哈希值 File hash
+	Size int64  `gorm:"not null;default:0" json:"size"` // 字节数，0 表示尚未统计 Size in bytes, 0 means not yet measured
+
+	Manifest bool `gorm:"not null;default:false" json:"manifest"` // 是否为部署清单，清单中的文件按内容哈希存储在 blobs/ 下 Whether it is a deployment manifest whose files are stored by content hash under blobs/
 }
 
 // TableName 自定义表名 Custom table name
 func (File) TableName() string {
-	return "projects"
+	return "files"
 }
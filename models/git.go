@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GitIntegration 站点绑定的 Git 仓库，推送到指定分支时拉取、构建并发布为新版本
+// Git repository bound to a site, pushes to the branch are fetched, built and published as a new version
+type GitIntegration struct {
+	gorm.Model
+	SiteID       uint     `gorm:"not null;uniqueIndex"`                                           // 站点ID，每个站点最多绑定一个仓库 Site ID, at most one repository per site
+	Site         Site     `gorm:"foreignKey:SiteID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"` // 站点 Site
+	Provider     string   `gorm:"size:16;not null"`                                               // 代码托管平台 Git hosting provider
+	RepoURL      string   `gorm:"not null"`                                                       // 仓库 https 地址 Repository https URL
+	Branch       string   `gorm:"not null;default:main"`                                          // 部署的分支 Deployed branch
+	Secret       string   `gorm:"not null;serializer:encrypted"`                                  // 推送事件的签名密钥或令牌，加密保存 Signing secret or token of push events, stored encrypted
+	Token        string   `gorm:"serializer:encrypted"`                                           // 拉取私有仓库的访问令牌，加密保存 Access token for fetching private repositories, stored encrypted
+	BuildCommand string   // 构建命令，为空时不构建 Build command, no build when empty
+	OutputDir    string   // 发布的输出目录，相对仓库根目录 Published output directory, relative to the repository root
+	BuildPreset  string   `gorm:"size:16"` // 构建预设，例如 hugo、mkdocs 或 vite Build preset such as hugo, mkdocs or vite
+	BuildImage   string   // 构建镜像，替换沙箱前缀中的 {image} Build image, replacing {image} in the sandbox prefix
+	RunnerLabels []string `gorm:"serializer:json;type:text"` // 交给远程执行器时执行器必须具有的标签 Labels a remote runner must have to take the builds
+
+	LastCommit string     `gorm:"size:64"` // 最近一次推送的提交 Commit of the latest push
+	LastStatus string     `gorm:"size:16"` // 最近一次运行的状态 Status of the latest run
+	LastError  string     // 最近一次运行的错误 Error of the latest run
+	LastLog    string     `gorm:"type:text"` // 最近一次构建的输出，已截断 Output of the latest build, truncated
+	LastRunAt  *time.Time // 最近一次运行的时间 Time of the latest run
+}
+
+// TableName 重写表名
+// Rewrite table name
+func (GitIntegration) TableName() string {
+	return "git_integrations"
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Index 热点查询路径的显式索引定义
+// Explicit index definition for a hot query path
+type Index struct {
+	Name    string // 索引名称 Index name
+	Table   string // 表名 Table name
+	Columns string // 列或表达式 Columns or expressions
+	Unique  bool   // 是否唯一 Whether the index is unique
+	Where   string // 部分索引条件，通常用于排除软删除的行 Partial index condition, usually excluding soft-deleted rows
+	Using   string // 索引类型，空为默认 Index method, empty for the default
+	Dialect string // 只在该驱动上创建，空为全部 Only created on this driver, empty for all
+}
+
+// Indexes 热点查询路径的索引注册表，新增热点查询时在此登记，并追加一个调用 EnsureIndexes 的迁移
+// Registry of indexes for hot query paths, register new hot queries here and append a migration calling EnsureIndexes
+var Indexes = append([]Index{
+	// 最新发布查询和按站点列出发布 Latest release lookup and listing releases by site
+	{Name: "idx_site_releases_site_tag", Table: "site_releases", Columns: "site_id, tag", Where: "deleted_at IS NULL"},
+	{Name: "idx_site_releases_site_created", Table: "site_releases", Columns: "site_id, created_at DESC", Where: "deleted_at IS NULL"},
+	// 按主机名查找站点 Site lookup by hostname
+	{Name: "idx_site_domains_domain", Table: "site_domains", Columns: "domain"},
+	{Name: "idx_site_domains_site", Table: "site_domains", Columns: "site_id"},
+	{Name: "idx_sites_sub_domain_lower", Table: "sites", Columns: "LOWER(sub_domain)", Where: "deleted_at IS NULL"},
+	// 按用户撤销令牌 Token revocation by user
+	{Name: "idx_tokens_user", Table: "tokens", Columns: "user_id", Where: "deleted_at IS NULL"},
+	// 按所有者列出项目 Listing projects by owner
+	{Name: "idx_projects_owner", Table: "projects", Columns: "owner_type, owner_id", Where: "deleted_at IS NULL"},
+	// 不区分大小写的唯一名称，名称用作子域名和路径 Case-insensitive unique names as they are used in subdomains and paths
+	{Name: "idx_users_name_lower", Table: "users", Columns: "LOWER(name)", Unique: true, Where: "deleted_at IS NULL"},
+	{Name: "idx_organizations_name_lower", Table: "organizations", Columns: "LOWER(name)", Unique: true, Where: "deleted_at IS NULL"},
+	{Name: "idx_projects_name_lower", Table: "projects", Columns: "LOWER(name)", Unique: true, Where: "deleted_at IS NULL"},
+	// 路径清除限流与列表 Path purge rate limiting and listing
+	{Name: "idx_site_path_purges_project_created", Table: "site_path_purges", Columns: "project_id, created_at", Where: "deleted_at IS NULL"},
+}, searchIndexes()...)
+
+// SQL 生成对应驱动的建索引语句
+// Build the CREATE INDEX statement for the given driver
+func (i Index) SQL(dialect string) string {
+	unique := ""
+	if i.Unique {
+		unique = "UNIQUE "
+	}
+	// Postgres 使用 CONCURRENTLY 避免建索引时锁表，SQLite 不支持该关键字
+	// Postgres uses CONCURRENTLY to avoid locking the table while building, SQLite does not support the keyword
+	concurrently := ""
+	if dialect == "postgres" {
+		concurrently = "CONCURRENTLY "
+	}
+	using := ""
+	if i.Using != "" {
+		using = "USING " + i.Using + " "
+	}
+	stmt := fmt.Sprintf("CREATE %sINDEX %sIF NOT EXISTS %s ON %s %s(%s)", unique, concurrently, i.Name, i.Table, using, i.Columns)
+	if i.Where != "" {
+		stmt += " WHERE " + i.Where
+	}
+	return stmt
+}
+
+// EnsureIndexes 创建注册表中缺失的索引
+// Create the registered indexes that are missing
+func EnsureIndexes(db *gorm.DB) error {
+	dialect := db.Dialector.Name()
+	for _, index := range Indexes {
+		if index.Dialect != "" && index.Dialect != dialect {
+			continue
+		}
+		if err := db.Exec(index.SQL(dialect)).Error; err != nil {
+			return fmt.Errorf("create index %s: %w", index.Name, err)
+		}
+	}
+	return nil
+}
+
+// dropIndexes 删除注册表中的索引
+// Drop the registered indexes
+func dropIndexes(db *gorm.DB) error {
+	for _, index := range Indexes {
+		if err := db.Exec("DROP INDEX IF EXISTS " + index.Name).Error; err != nil {
+			return fmt.Errorf("drop index %s: %w", index.Name, err)
+		}
+	}
+	return nil
+}
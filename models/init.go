@@ -1,28 +1,209 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"fmt"
+	"strings"
+	"time"
 
-// Migrate 迁移模型，通过依赖注入的方式，使用gorm.DB进行数据库操作
-// Migrate models, using gorm.DB for database operations through dependency injection
+	"github.com/LiteyukiStudio/spage/constants"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Migrate 迁移模型到最新版本，通过依赖注入的方式，使用gorm.DB进行数据库操作
+// Migrate models to the latest version, using gorm.DB for database operations through dependency injection
 func Migrate(db *gorm.DB) error {
-	if err := db.AutoMigrate(
-		// entity.go
-		&User{},
-		&Organization{},
-		&Project{},
-		// file.go
-		&File{},
-		// jwt
-		&Token{},
-		// oidc_config.go
-		&OIDCConfig{},
-		// site.go
-		&Site{},
-		&SiteRelease{},
-		// node.go
-		&Node{},
-	); err != nil {
+	return MigrateTo(db, LatestVersion())
+}
+
+// normalizeEnums 修正历史数据中的非法枚举值，避免读取时 Scan 失败
+// Normalize invalid enum values in historic rows so that Scan does not fail on read
+func normalizeEnums(db *gorm.DB) error {
+	if err := db.Model(&User{}).
+		Where("role NOT IN ? OR role IS NULL", constants.Roles).
+		Update("role", constants.RoleUser).Error; err != nil {
 		return err
 	}
+	if err := db.Model(&Project{}).
+		Where("visibility NOT IN ? OR visibility IS NULL", constants.Visibilities).
+		Update("visibility", constants.VisibilityPublic).Error; err != nil {
+		return err
+	}
+	return db.Model(&SiteRelease{}).
+		Where("status NOT IN ? OR status IS NULL", constants.DeploymentStatuses).
+		Update("status", constants.DeploymentStatusReady).Error
+}
+
+// backfillSiteDomains 域名索引表为空时从已有站点回填
+// Backfill the domain lookup table from existing sites when it is empty
+func backfillSiteDomains(db *gorm.DB) error {
+	var count int64
+	if err := db.Model(&SiteDomain{}).Count(&count).Error; err != nil || count > 0 {
+		return err
+	}
+	var sites []Site
+	if err := db.Select("id", "domains").Find(&sites).Error; err != nil {
+		return err
+	}
+	var domains []SiteDomain
+	for _, site := range sites {
+		for _, domain := range site.Domains {
+			domains = append(domains, SiteDomain{SiteID: site.ID, Domain: strings.ToLower(domain)})
+		}
+	}
+	if len(domains) == 0 {
+		return nil
+	}
+	return db.CreateInBatches(domains, 500).Error
+}
+
+// renameDuplicateNames 为只有大小写不同的用户、组织和项目名称追加 ID 后缀，最早创建的保留原名，以便建立不区分大小写的唯一索引
+// Append the ID to user, organization and project names differing only in case, the oldest keeps its name,
+// so that the case-insensitive unique indexes can be built
+func renameDuplicateNames(db *gorm.DB) error {
+	for _, model := range []any{&User{}, &Organization{}, &Project{}} {
+		var rows []struct {
+			ID   uint
+			Name string
+		}
+		if err := db.Model(model).Select("id", "name").Order("id").Find(&rows).Error; err != nil {
+			return err
+		}
+		seen := map[string]bool{}
+		for _, row := range rows {
+			key := strings.ToLower(row.Name)
+			if !seen[key] {
+				seen[key] = true
+				continue
+			}
+			name := fmt.Sprintf("%s-%d", row.Name, row.ID)
+			if err := db.Model(model).Where("id = ?", row.ID).Update("name", name).Error; err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
+
+// rewriteFilePaths 转换文件、预览图和预览图模板记录中保存的路径
+// Convert the paths stored in file, preview image and preview template records
+func rewriteFilePaths(db *gorm.DB, convert func(string) string) error {
+	var files []File
+	if err := db.Unscoped().Select("id", "path").Find(&files).Error; err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := db.Unscoped().Model(&File{}).Where("id = ?", file.ID).Update("path", convert(file.Path)).Error; err != nil {
+			return err
+		}
+	}
+	var releases []SiteRelease
+	if err := db.Unscoped().Select("id", "preview_path").Where("preview_path <> ''").Find(&releases).Error; err != nil {
+		return err
+	}
+	for _, release := range releases {
+		if err := db.Unscoped().Model(&SiteRelease{}).Where("id = ?", release.ID).Update("preview_path", convert(release.PreviewPath)).Error; err != nil {
+			return err
+		}
+	}
+	var orgs []Organization
+	if err := db.Unscoped().Select("id", "preview_template").Where("preview_template IS NOT NULL").Find(&orgs).Error; err != nil {
+		return err
+	}
+	for _, org := range orgs {
+		if err := db.Unscoped().Model(&Organization{}).Where("id = ?", org.ID).Update("preview_template", convert(*org.PreviewTemplate)).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backfillActiveReleases 每个站点只保留最新的一条 latest 记录，并关联到与其文件相同的最新版本
+// Keep only the newest latest record of each site and link it to the newest version sharing its file
+func backfillActiveReleases(db *gorm.DB) error {
+	var latest []SiteRelease
+	if err := db.Select("id", "site_id", "file_id").Where("tag = ?", constants.ReleaseTagLatest).Order("id DESC").Find(&latest).Error; err != nil {
+		return err
+	}
+	kept := map[uint]bool{}
+	for _, pointer := range latest {
+		if kept[pointer.SiteID] {
+			if err := db.Unscoped().Delete(&SiteRelease{}, pointer.ID).Error; err != nil {
+				return err
+			}
+			continue
+		}
+		kept[pointer.SiteID] = true
+		var version SiteRelease
+		err := db.Select("id").Where("site_id = ? AND file_id = ? AND tag <> ?", pointer.SiteID, pointer.FileID, constants.ReleaseTagLatest).
+			Order("id DESC").Limit(1).Find(&version).Error
+		if err != nil {
+			return err
+		}
+		if version.ID == 0 {
+			continue
+		}
+		if err := db.Model(&SiteRelease{}).Where("id = ?", pointer.ID).Update("active_release_id", version.ID).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backfillMemberRoles 将旧的组织所有者和项目所有者关联表转为带角色的成员
+// Turn the legacy organization owner and project owner join tables into members with roles
+func backfillMemberRoles(db *gorm.DB) error {
+	now := time.Now()
+	if db.Migrator().HasTable("organization_owners") {
+		var owners []OrgMember
+		if err := db.Table("organization_owners").Select("organization_id", "user_id").Find(&owners).Error; err != nil {
+			return err
+		}
+		for _, owner := range owners {
+			owner.Role, owner.CreatedAt = constants.OrgRoleOwner, now
+			err := db.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "organization_id"}, {Name: "user_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"role"}),
+			}).Create(&owner).Error
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if db.Migrator().HasTable("project_owners") {
+		var owners []ProjectMember
+		if err := db.Table("project_owners").Select("project_id", "user_id").Find(&owners).Error; err != nil {
+			return err
+		}
+		for _, owner := range owners {
+			owner.Role, owner.CreatedAt = constants.OrgRoleOwner, now
+			if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&owner).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// restoreOwnerTables 回滚时从 owner 角色重建旧的所有者关联表，其他角色的成员不再保留
+// Rebuild the legacy owner join tables from owner roles on rollback, members with other roles are dropped
+func restoreOwnerTables(db *gorm.DB) error {
+	type orgOwner struct {
+		OrganizationID uint `gorm:"primaryKey;autoIncrement:false"`
+		UserID         uint `gorm:"primaryKey;autoIncrement:false"`
+	}
+	type projectOwner struct {
+		ProjectID uint `gorm:"primaryKey;autoIncrement:false"`
+		UserID    uint `gorm:"primaryKey;autoIncrement:false"`
+	}
+	if err := db.Table("organization_owners").AutoMigrate(&orgOwner{}); err != nil {
+		return err
+	}
+	if err := db.Table("project_owners").AutoMigrate(&projectOwner{}); err != nil {
+		return err
+	}
+	if err := db.Exec("INSERT INTO organization_owners (organization_id, user_id) SELECT organization_id, user_id FROM organization_members WHERE role = ?", constants.OrgRoleOwner).Error; err != nil {
+		return err
+	}
+	return db.Exec("INSERT INTO project_owners (project_id, user_id) SELECT project_id, user_id FROM project_members WHERE role = ?", constants.OrgRoleOwner).Error
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"gorm.io/gorm"
+)
+
+// Invitation 注册邀请链接，可限制使用次数和邮箱，指定组织时使用者加入该组织
+// Registration invite link limited in uses and optionally to an email, users of it join the organization when one is set
+type Invitation struct {
+	gorm.Model
+	OrganizationID *uint             `gorm:"index"`                                                 // 加入的组织ID，空为实例邀请 Organization to join, an instance invite when empty
+	Organization   *Organization     `gorm:"foreignKey:OrganizationID;constraint:OnDelete:CASCADE"` // 加入的组织 Organization to join
+	Role           constants.OrgRole `gorm:"size:16;not null;default:member"`                       // 加入组织后的角色，仅指定组织时有效 Role after joining, only meaningful with an organization
+	Email          string            `gorm:"default:''"`                                            // 限定的邮箱，空为不限 Restricted email, anyone when empty
+	Prefix         string            `gorm:"not null"`                                              // 令牌开头部分，用于识别 Leading part of the token for identification
+	Hash           string            `gorm:"not null;uniqueIndex"`                                  // 令牌的 SHA-256 哈希 SHA-256 hash of the token
+	MaxUses        int               `gorm:"not null;default:1"`                                    // 可使用次数 Allowed uses
+	Uses           int               `gorm:"not null;default:0"`                                    // 已使用次数 Uses so far
+	ExpiresAt      time.Time         `gorm:"not null"`                                              // 过期时间 Expiry time
+	CreatedByID    uint              `gorm:"not null;index"`                                        // 创建者ID Creator ID
+	CreatedBy      User              `gorm:"foreignKey:CreatedByID;constraint:OnDelete:CASCADE"`    // 创建者 Creator
+}
+
+// TableName 重写表名
+// Rewrite table name
+func (Invitation) TableName() string {
+	return "invitations"
+}
+
+// Usable 邀请是否仍可使用
+// Whether the invitation can still be used
+func (i *Invitation) Usable() bool {
+	return i.Uses < i.MaxUses && time.Now().Before(i.ExpiresAt)
+}
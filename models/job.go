@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Job 后台任务，由任务队列的工作协程领取执行，失败时按指数退避重试
+// A background job, claimed and run by the workers of the job queue and retried with exponential backoff on failure
+type Job struct {
+	ID          uint       `gorm:"primaryKey"` // 任务ID Job ID
+	CreatedAt   time.Time  `gorm:"not null"`   // 创建时间 Created time
+	UpdatedAt   time.Time  // 更新时间 Updated time
+	Type        string     `gorm:"size:64;not null;index"`                              // 任务类型，决定执行函数 Job type, selecting the handler
+	Payload     string     `gorm:"type:text"`                                           // JSON 格式的参数 Parameters as JSON
+	Status      string     `gorm:"size:16;not null;default:pending;index:idx_jobs_due"` // 任务状态 Job status
+	Attempts    int        `gorm:"not null;default:0"`                                  // 已尝试次数 Attempts made
+	MaxAttempts int        `gorm:"not null;default:1"`                                  // 最大尝试次数 Max attempts
+	RunAt       time.Time  `gorm:"not null;index:idx_jobs_due"`                         // 下次执行时间，运行中时为租约到期时间 Next run time, the lease expiry while running
+	StartedAt   *time.Time // 最近一次开始执行的时间 Start of the latest attempt
+	FinishedAt  *time.Time // 成功、失败或取消的时间 Time it succeeded, failed or was canceled
+	Result      string     `gorm:"type:text"` // JSON 格式的执行结果 Outcome as JSON
+	Error       string     `gorm:"type:text"` // 最近一次的错误 Error of the latest attempt
+	UserID      uint       `gorm:"index"`     // 创建任务的用户ID，系统任务为 0 ID of the user creating the job, 0 for system jobs
+	SiteID      uint       `gorm:"index"`     // 关联的站点ID，站点成员可查看 Related site ID, visible to the site's members
+
+	Stage         string `gorm:"size:32"` // 执行中的阶段 Stage of the running attempt
+	Progress      int64  // 当前阶段已完成的量 Amount of the current stage done
+	ProgressTotal int64  // 当前阶段的总量，未知时为 0 Total amount of the current stage, 0 when unknown
+
+	TraceParent string `gorm:"size:64"` // 创建任务时的链路上下文，任务的 span 沿用该链路 Trace context at creation, the job's spans continue that trace
+}
+
+// TableName 重写表名
+// Rewrite table name
+func (Job) TableName() string {
+	return "jobs"
+}
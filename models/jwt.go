@@ -1,8 +1,24 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
 
+	"github.com/LiteyukiStudio/spage/constants"
+	"gorm.io/gorm"
+)
+
+// Token 登录会话，访问令牌携带会话ID，撤销会话后其访问令牌和刷新令牌立即失效
+// Sign-in session, access tokens carry the session ID and both they and the refresh token stop working once the session is revoked
 type Token struct {
 	gorm.Model
-	UserID uint
+	UserID         uint                   `gorm:"index"`
+	RefreshHash    string                 `gorm:"size:64;index"` // 刷新令牌的 SHA-256 哈希 SHA-256 hash of the refresh token
+	ExpiresAt      time.Time              `gorm:"index"`         // 会话过期时间 Session expiry time
+	LastUsedAt     time.Time              // 最近一次刷新访问令牌的时间 Last time an access token was refreshed
+	Method         string                 `gorm:"size:32"`                   // 登录方式 Login method
+	IP             string                 `gorm:"size:64"`                   // 最近使用的客户端IP Client IP of the last use
+	UserAgent      string                 `gorm:"size:512"`                  // 最近使用的用户代理 User agent of the last use
+	ImpersonatorID uint                   `gorm:"index"`                     // 代为登录的管理员ID，普通会话为 0 ID of the impersonating administrator, 0 for ordinary sessions
+	Scopes         []constants.TokenScope `gorm:"serializer:json;type:json"` // 代为登录会话的权限范围 Scopes of an impersonation session
+	Reason         string                 `gorm:"size:255"`                  // 代为登录的原因 Reason for the impersonation
 }
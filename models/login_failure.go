@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// 登录失败的统计对象 Subjects login failures are counted for
+const (
+	LoginFailureUser = "user" // 账号，Subject 为用户ID Account, Subject is the user ID
+	LoginFailureIP   = "ip"   // 客户端 IP Client IP
+)
+
+// LoginFailure 账号或客户端 IP 的连续登录失败，登录成功或管理员解锁时删除
+// Consecutive failed logins of an account or a client IP, deleted on a successful login or when an administrator unlocks it
+type LoginFailure struct {
+	Kind         string     `gorm:"primaryKey;size:8"`  // 统计对象 Counted subject kind
+	Subject      string     `gorm:"primaryKey;size:64"` // 用户ID或 IP User ID or IP
+	Failures     int        `gorm:"not null;default:0"` // 连续失败次数 Consecutive failures
+	LastFailedAt time.Time  `gorm:"not null;index"`     // 最近一次失败的时间 Time of the latest failure
+	LockedUntil  *time.Time // 账号锁定到的时间，空为未锁定 Time the account is locked until, empty when not locked
+}
+
+// TableName 重写表名
+// Rewrite table name
+func (LoginFailure) TableName() string {
+	return "login_failures"
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"time"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"gorm.io/gorm"
+)
+
+// OrgMember 组织成员及其角色，与 Organization.Members 共用关联表
+// Organization member and their role, sharing the join table of Organization.Members
+type OrgMember struct {
+	OrganizationID uint              `gorm:"primaryKey;autoIncrement:false"` // 组织ID Organization ID
+	UserID         uint              `gorm:"primaryKey;autoIncrement:false"` // 用户ID User ID
+	Role           constants.OrgRole `gorm:"not null;default:member"`        // 组织角色，对组织下所有项目生效 Organization role, applies to every project of the organization
+	CreatedAt      time.Time         // 加入时间 Joined time
+}
+
+// 组织成员表名 Organization member table name
+func (OrgMember) TableName() string {
+	return "organization_members"
+}
+
+// Team 组织内的团队，被授予项目角色后团队成员获得该角色
+// Team within an organization, its members get the role granted to the team on a project
+type Team struct {
+	gorm.Model
+	OrganizationID uint         `gorm:"not null;uniqueIndex:idx_teams_org_name"`               // 所属组织ID Organization ID
+	Organization   Organization `gorm:"foreignKey:OrganizationID;constraint:OnDelete:CASCADE"` // 所属组织 Organization
+	Name           string       `gorm:"not null;uniqueIndex:idx_teams_org_name"`               // 组织内唯一的团队名称 Team name, unique within the organization
+	Description    string       `gorm:"default:''"`                                            // 团队描述 Team description
+}
+
+// 团队表名 Team table name
+func (Team) TableName() string {
+	return "teams"
+}
+
+// TeamMember 团队成员，必须是团队所属组织的成员
+// Team member, who must be a member of the team's organization
+type TeamMember struct {
+	TeamID    uint `gorm:"primaryKey;autoIncrement:false"`                // 团队ID Team ID
+	Team      Team `gorm:"foreignKey:TeamID;constraint:OnDelete:CASCADE"` // 团队 Team
+	UserID    uint `gorm:"primaryKey;autoIncrement:false;index"`          // 用户ID User ID
+	User      User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"` // 用户 User
+	CreatedAt time.Time
+}
+
+// 团队成员表名 Team member table name
+func (TeamMember) TableName() string {
+	return "team_members"
+}
+
+// ProjectMember 直接授予用户的项目角色
+// Project role granted directly to a user
+type ProjectMember struct {
+	ProjectID uint              `gorm:"primaryKey;autoIncrement:false"`                   // 项目ID Project ID
+	Project   Project           `gorm:"foreignKey:ProjectID;constraint:OnDelete:CASCADE"` // 项目 Project
+	UserID    uint              `gorm:"primaryKey;autoIncrement:false;index"`             // 用户ID User ID
+	User      User              `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`    // 用户 User
+	Role      constants.OrgRole `gorm:"not null;default:member"`                          // 项目角色 Project role
+	CreatedAt time.Time
+}
+
+// 项目成员表名 Project member table name
+func (ProjectMember) TableName() string {
+	return "project_members"
+}
+
+// ProjectTeam 授予团队的项目角色，仅用于组织项目
+// Project role granted to a team, only for organization projects
+type ProjectTeam struct {
+	ProjectID uint              `gorm:"primaryKey;autoIncrement:false"`                   // 项目ID Project ID
+	Project   Project           `gorm:"foreignKey:ProjectID;constraint:OnDelete:CASCADE"` // 项目 Project
+	TeamID    uint              `gorm:"primaryKey;autoIncrement:false;index"`             // 团队ID Team ID
+	Team      Team              `gorm:"foreignKey:TeamID;constraint:OnDelete:CASCADE"`    // 团队 Team
+	Role      constants.OrgRole `gorm:"not null;default:member"`                          // 项目角色 Project role
+	CreatedAt time.Time
+}
+
+// 项目团队表名 Project team table name
+func (ProjectTeam) TableName() string {
+	return "project_teams"
+}
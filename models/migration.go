@@ -0,0 +1,1162 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/storage"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// SchemaMigration 已执行的迁移记录
+// Record of an applied migration
+type SchemaMigration struct {
+	Version   int       `gorm:"primaryKey;autoIncrement:false"` // 迁移版本 Migration version
+	Name      string    `gorm:"not null"`                       // 迁移名称 Migration name
+	AppliedAt time.Time `gorm:"not null"`                       // 执行时间 Applied time
+}
+
+// 迁移记录表名 Migration record table name
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Migration 一个版本化的迁移步骤
+// A versioned migration step
+type Migration struct {
+	Version int                     // 版本号，必须严格递增 Version, must be strictly increasing
+	Name    string                  // 名称 Name
+	Up      func(tx *gorm.DB) error // 升级 Upgrade
+	Down    func(tx *gorm.DB) error // 回滚 Rollback
+	NoTx    bool                    // 不在事务中执行，例如 Postgres 并发建索引 Run outside a transaction, e.g. concurrent index builds on Postgres
+}
+
+// Migrations 迁移注册表，已发布的迁移不可修改，结构变更需追加新的迁移
+// Migration registry, released migrations must not be changed, schema changes append a new migration
+var Migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "baseline",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.AutoMigrate(baselineModels...); err != nil {
+				return err
+			}
+			if err := normalizeEnums(tx); err != nil {
+				return err
+			}
+			return backfillSiteDomains(tx)
+		},
+		Down: func(tx *gorm.DB) error {
+			tables := append([]any{"organization_members", "organization_owners", "project_owners"}, baselineModels...)
+			return tx.Migrator().DropTable(tables...)
+		},
+	},
+	{
+		Version: 2,
+		Name:    "hot query indexes",
+		Up:      EnsureIndexes,
+		Down:    dropIndexes,
+		NoTx:    true,
+	},
+	{
+		Version: 3,
+		Name:    "storage object keys",
+		Up: func(tx *gorm.DB) error {
+			return rewriteFilePaths(tx, storage.KeyFromPath)
+		},
+		Down: func(tx *gorm.DB) error {
+			return rewriteFilePaths(tx, func(key string) string {
+				if p, err := storage.PathFromKey(key); err == nil {
+					return p
+				}
+				return key
+			})
+		},
+	},
+	{
+		Version: 4,
+		Name:    "auth providers and user identities",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&OIDCConfig{}, &UserIdentity{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable(&UserIdentity{}); err != nil {
+				return err
+			}
+			for _, column := range []string{"Type", "BaseURL", "Enabled"} {
+				if err := tx.Migrator().DropColumn(&OIDCConfig{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 5,
+		Name:    "personal access tokens",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&APIToken{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&APIToken{})
+		},
+	},
+	{
+		Version: 6,
+		Name:    "two-factor authentication",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&UserTOTP{}, &RecoveryCode{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&RecoveryCode{}, &UserTOTP{})
+		},
+	},
+	{
+		Version: 7,
+		Name:    "custom domains and acme certificates",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&CustomDomain{}, &ACMECertificate{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&ACMECertificate{}, &CustomDomain{})
+		},
+	},
+	{
+		Version: 8,
+		Name:    "release versions",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.AutoMigrate(&SiteRelease{}); err != nil {
+				return err
+			}
+			return backfillActiveReleases(tx)
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&SiteRelease{}, "ActiveReleaseID")
+		},
+	},
+	{
+		Version: 9,
+		Name:    "preview deployments",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&SitePreview{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&SitePreview{})
+		},
+	},
+	{
+		Version: 10,
+		Name:    "site response headers",
+		Up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasColumn(&Site{}, "Headers") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&Site{}, "Headers")
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&Site{}, "Headers")
+		},
+	},
+	{
+		Version: 11,
+		Name:    "organization roles and teams",
+		Up: func(tx *gorm.DB) error {
+			// OrgMember 与 Organization.Members 的关联表同名，需单独迁移以免被关联表结构覆盖
+			// OrgMember shares its table with the join table of Organization.Members and is migrated alone so the join table schema does not shadow it
+			if err := tx.AutoMigrate(&OrgMember{}); err != nil {
+				return err
+			}
+			if err := tx.AutoMigrate(&Team{}, &TeamMember{}, &ProjectMember{}, &ProjectTeam{}); err != nil {
+				return err
+			}
+			if err := backfillMemberRoles(tx); err != nil {
+				return err
+			}
+			return tx.Migrator().DropTable("organization_owners", "project_owners")
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := restoreOwnerTables(tx); err != nil {
+				return err
+			}
+			if err := tx.Migrator().DropTable(&ProjectTeam{}, &ProjectMember{}, &TeamMember{}, &Team{}); err != nil {
+				return err
+			}
+			for _, column := range []string{"Role", "CreatedAt"} {
+				if err := tx.Migrator().DropColumn(&OrgMember{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 12,
+		Name:    "audit logs",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&AuditLog{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&AuditLog{})
+		},
+	},
+	{
+		Version: 13,
+		Name:    "file sizes",
+		Up: func(tx *gorm.DB) error {
+			// 已有文件的大小由启动后的后台任务从存储中补齐 Sizes of existing files are filled from storage by a background task after startup
+			if tx.Migrator().HasColumn(&File{}, "Size") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&File{}, "Size")
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&File{}, "Size")
+		},
+	},
+	{
+		Version: 14,
+		Name:    "webhooks",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&Webhook{}, &WebhookDelivery{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&WebhookDelivery{}, &Webhook{})
+		},
+	},
+	{
+		Version: 15,
+		Name:    "git integrations",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&GitIntegration{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&GitIntegration{})
+		},
+	},
+	{
+		Version: 16,
+		Name:    "chunked uploads",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&Upload{}, &UploadChunk{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&UploadChunk{}, &Upload{})
+		},
+	},
+	{
+		Version: 17,
+		Name:    "content addressed blobs",
+		Up: func(tx *gorm.DB) error {
+			if !tx.Migrator().HasColumn(&File{}, "Manifest") {
+				if err := tx.Migrator().AddColumn(&File{}, "Manifest"); err != nil {
+					return err
+				}
+			}
+			return tx.AutoMigrate(&Blob{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable(&Blob{}); err != nil {
+				return err
+			}
+			return tx.Migrator().DropColumn(&File{}, "Manifest")
+		},
+	},
+	{
+		Version: 18,
+		Name:    "storage quotas",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&FileBlob{}, &Quota{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&Quota{}, &FileBlob{})
+		},
+	},
+	{
+		Version: 19,
+		Name:    "site cache rules",
+		Up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasColumn(&Site{}, "CacheRules") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&Site{}, "CacheRules")
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&Site{}, "CacheRules")
+		},
+	},
+	{
+		Version: 20,
+		Name:    "background jobs",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&Job{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&Job{})
+		},
+	},
+	{
+		Version: 21,
+		Name:    "full text search",
+		Up: func(tx *gorm.DB) error {
+			if err := ensureFullText(tx); err != nil {
+				return err
+			}
+			return EnsureIndexes(tx)
+		},
+		Down: dropFullText,
+		NoTx: true,
+	},
+	{
+		Version: 22,
+		Name:    "site traffic",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&SiteTraffic{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&SiteTraffic{})
+		},
+	},
+	{
+		Version: 23,
+		Name:    "site access protection",
+		Up: func(tx *gorm.DB) error {
+			for _, column := range []string{"AccessMode", "AccessPassword"} {
+				if tx.Migrator().HasColumn(&Site{}, column) {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(&Site{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, column := range []string{"AccessPassword", "AccessMode"} {
+				if err := tx.Migrator().DropColumn(&Site{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 24,
+		Name:    "site ip rules",
+		Up: func(tx *gorm.DB) error {
+			for _, column := range []string{"AllowIPs", "DenyIPs"} {
+				if tx.Migrator().HasColumn(&Site{}, column) {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(&Site{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, column := range []string{"DenyIPs", "AllowIPs"} {
+				if err := tx.Migrator().DropColumn(&Site{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 25,
+		Name:    "email verification and password reset",
+		Up: func(tx *gorm.DB) error {
+			if !tx.Migrator().HasColumn(&User{}, "EmailVerifiedAt") {
+				if err := tx.Migrator().AddColumn(&User{}, "EmailVerifiedAt"); err != nil {
+					return err
+				}
+			}
+			return tx.AutoMigrate(&UserToken{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable(&UserToken{}); err != nil {
+				return err
+			}
+			return tx.Migrator().DropColumn(&User{}, "EmailVerifiedAt")
+		},
+	},
+	{
+		Version: 26,
+		Name:    "invitations",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&Invitation{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&Invitation{})
+		},
+	},
+	{
+		Version: 27,
+		Name:    "ldap auth providers",
+		Up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasColumn(&OIDCConfig{}, "LDAP") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&OIDCConfig{}, "LDAP")
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&OIDCConfig{}, "LDAP")
+		},
+	},
+	{
+		Version: 28,
+		Name:    "site analytics",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&SiteAnalytics{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&SiteAnalytics{})
+		},
+	},
+	{
+		Version: 29,
+		Name:    "dynamic settings",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&Setting{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&Setting{})
+		},
+	},
+	{
+		Version: 30,
+		Name:    "server-side sessions",
+		Up: func(tx *gorm.DB) error {
+			// 旧的刷新令牌是未保存哈希的 JWT，无法迁移，用户需要重新登录 Old refresh tokens are JWTs without a stored hash and cannot be migrated, users sign in again
+			if err := tx.Unscoped().Where("1 = 1").Delete(&Token{}).Error; err != nil {
+				return err
+			}
+			return tx.AutoMigrate(&Token{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if tx.Migrator().HasIndex(&Token{}, "UserID") {
+				if err := tx.Migrator().DropIndex(&Token{}, "UserID"); err != nil {
+					return err
+				}
+			}
+			for _, column := range []string{"RefreshHash", "ExpiresAt", "LastUsedAt", "Method", "IP", "UserAgent"} {
+				if err := tx.Migrator().DropColumn(&Token{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 31,
+		Name:    "project transfers",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&ProjectTransfer{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&ProjectTransfer{})
+		},
+	},
+	{
+		Version: 32,
+		Name:    "project labels",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&ProjectLabel{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&ProjectLabel{})
+		},
+	},
+	{
+		Version: 33,
+		Name:    "project primary site",
+		Up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasColumn(&Project{}, "PrimarySiteID") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&Project{}, "PrimarySiteID")
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&Project{}, "PrimarySiteID")
+		},
+	},
+	{
+		Version: 34,
+		Name:    "scheduled releases",
+		Up: func(tx *gorm.DB) error {
+			for _, table := range scheduleColumns() {
+				for _, column := range table.columns {
+					if tx.Migrator().HasColumn(table.model, column) {
+						continue
+					}
+					if err := tx.Migrator().AddColumn(table.model, column); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, table := range scheduleColumns() {
+				for _, column := range table.columns {
+					if err := tx.Migrator().DropColumn(table.model, column); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 35,
+		Name:    "job progress",
+		Up: func(tx *gorm.DB) error {
+			for _, column := range []string{"Stage", "Progress", "ProgressTotal"} {
+				if tx.Migrator().HasColumn(&Job{}, column) {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(&Job{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, column := range []string{"ProgressTotal", "Progress", "Stage"} {
+				if err := tx.Migrator().DropColumn(&Job{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 36,
+		Name:    "upload limits",
+		Up: func(tx *gorm.DB) error {
+			for _, model := range []any{&Quota{}, &Project{}} {
+				if tx.Migrator().HasColumn(model, "MaxUploadSize") {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(model, "MaxUploadSize"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, model := range []any{&Project{}, &Quota{}} {
+				if err := tx.Migrator().DropColumn(model, "MaxUploadSize"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 37,
+		Name:    "release content scans",
+		Up: func(tx *gorm.DB) error {
+			for _, column := range []string{"ScanFindings", "ScanActivate"} {
+				if tx.Migrator().HasColumn(&SiteRelease{}, column) {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(&SiteRelease{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, column := range []string{"ScanActivate", "ScanFindings"} {
+				if err := tx.Migrator().DropColumn(&SiteRelease{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 38,
+		Name:    "content policies",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&ContentPolicy{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&ContentPolicy{})
+		},
+	},
+	{
+		Version: 39,
+		Name:    "site sitemap and robots",
+		Up: func(tx *gorm.DB) error {
+			for _, column := range []string{"Sitemap", "RobotsPolicy", "RobotsTxt"} {
+				if tx.Migrator().HasColumn(&Site{}, column) {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(&Site{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, column := range []string{"RobotsTxt", "RobotsPolicy", "Sitemap"} {
+				if err := tx.Migrator().DropColumn(&Site{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 40,
+		Name:    "project directory listing",
+		Up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasColumn(&Project{}, "DirectoryListing") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&Project{}, "DirectoryListing")
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&Project{}, "DirectoryListing")
+		},
+	},
+	{
+		Version: 41,
+		Name:    "site transfer limits",
+		Up: func(tx *gorm.DB) error {
+			for _, column := range []string{"TransferSoftLimit", "TransferHardLimit"} {
+				if tx.Migrator().HasColumn(&Site{}, column) {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(&Site{}, column); err != nil {
+					return err
+				}
+			}
+			return tx.AutoMigrate(&SiteTransferAlert{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable(&SiteTransferAlert{}); err != nil {
+				return err
+			}
+			for _, column := range []string{"TransferHardLimit", "TransferSoftLimit"} {
+				if err := tx.Migrator().DropColumn(&Site{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 42,
+		Name:    "impersonation sessions",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&Token{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if tx.Migrator().HasIndex(&Token{}, "ImpersonatorID") {
+				if err := tx.Migrator().DropIndex(&Token{}, "ImpersonatorID"); err != nil {
+					return err
+				}
+			}
+			for _, column := range []string{"Reason", "Scopes", "ImpersonatorID"} {
+				if err := tx.Migrator().DropColumn(&Token{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 43,
+		Name:    "account deletion",
+		Up: func(tx *gorm.DB) error {
+			if !tx.Migrator().HasColumn(&User{}, "DeleteAt") {
+				if err := tx.Migrator().AddColumn(&User{}, "DeleteAt"); err != nil {
+					return err
+				}
+			}
+			if tx.Migrator().HasIndex(&User{}, "DeleteAt") {
+				return nil
+			}
+			return tx.Migrator().CreateIndex(&User{}, "DeleteAt")
+		},
+		Down: func(tx *gorm.DB) error {
+			if tx.Migrator().HasIndex(&User{}, "DeleteAt") {
+				if err := tx.Migrator().DropIndex(&User{}, "DeleteAt"); err != nil {
+					return err
+				}
+			}
+			return tx.Migrator().DropColumn(&User{}, "DeleteAt")
+		},
+	},
+	{
+		Version: 44,
+		Name:    "notification channels",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&NotificationChannel{}, &NotificationDelivery{}, &NotificationAlert{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&NotificationAlert{}, &NotificationDelivery{}, &NotificationChannel{})
+		},
+	},
+	{
+		Version: 45,
+		Name:    "case-insensitive names",
+		Up: func(tx *gorm.DB) error {
+			if err := renameDuplicateNames(tx); err != nil {
+				return err
+			}
+			return EnsureIndexes(tx)
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, name := range []string{"idx_projects_name_lower", "idx_organizations_name_lower", "idx_users_name_lower"} {
+				if err := tx.Exec("DROP INDEX IF EXISTS " + name).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		NoTx: true,
+	},
+	{
+		Version: 46,
+		Name:    "login throttling",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&LoginFailure{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&LoginFailure{})
+		},
+	},
+	{
+		Version: 47,
+		Name:    "uploaded avatars",
+		Up: func(tx *gorm.DB) error {
+			for _, model := range []any{&User{}, &Organization{}} {
+				if tx.Migrator().HasColumn(model, "AvatarHash") {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(model, "AvatarHash"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, model := range []any{&User{}, &Organization{}} {
+				if err := tx.Migrator().DropColumn(model, "AvatarHash"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 48,
+		Name:    "project deploy keys",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&DeployKey{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&DeployKey{})
+		},
+	},
+	{
+		Version: 49,
+		Name:    "tenants",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.AutoMigrate(&Tenant{}); err != nil {
+				return err
+			}
+			for _, model := range []any{&User{}, &Organization{}, &Project{}} {
+				if tx.Migrator().HasColumn(model, "TenantID") {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(model, "TenantID"); err != nil {
+					return err
+				}
+				if err := tx.Migrator().CreateIndex(model, "TenantID"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, model := range []any{&User{}, &Organization{}, &Project{}} {
+				if err := tx.Migrator().DropColumn(model, "TenantID"); err != nil {
+					return err
+				}
+			}
+			return tx.Migrator().DropTable(&Tenant{})
+		},
+	},
+	{
+		Version: 50,
+		Name:    "project immutable assets",
+		Up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasColumn(&Project{}, "ImmutableAssets") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&Project{}, "ImmutableAssets")
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&Project{}, "ImmutableAssets")
+		},
+	},
+	{
+		Version: 51,
+		Name:    "cdn integrations",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&CDNIntegration{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&CDNIntegration{})
+		},
+	},
+	{
+		Version: 52,
+		Name:    "release checksums",
+		Up: func(tx *gorm.DB) error {
+			for _, model := range []any{&SiteRelease{}, &Upload{}} {
+				if tx.Migrator().HasColumn(model, "Checksums") {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(model, "Checksums"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, model := range []any{&SiteRelease{}, &Upload{}} {
+				if err := tx.Migrator().DropColumn(model, "Checksums"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 53,
+		Name:    "git build images",
+		Up: func(tx *gorm.DB) error {
+			for _, column := range []string{"BuildPreset", "BuildImage"} {
+				if tx.Migrator().HasColumn(&GitIntegration{}, column) {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(&GitIntegration{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, column := range []string{"BuildPreset", "BuildImage"} {
+				if err := tx.Migrator().DropColumn(&GitIntegration{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 54,
+		Name:    "build runners",
+		Up: func(tx *gorm.DB) error {
+			if !tx.Migrator().HasColumn(&GitIntegration{}, "RunnerLabels") {
+				if err := tx.Migrator().AddColumn(&GitIntegration{}, "RunnerLabels"); err != nil {
+					return err
+				}
+			}
+			return tx.AutoMigrate(&Runner{}, &RunnerBuild{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable(&RunnerBuild{}, &Runner{}); err != nil {
+				return err
+			}
+			return tx.Migrator().DropColumn(&GitIntegration{}, "RunnerLabels")
+		},
+	},
+	{
+		Version: 55,
+		Name:    "deployment approvals",
+		Up: func(tx *gorm.DB) error {
+			if !tx.Migrator().HasColumn(&Project{}, "Protected") {
+				if err := tx.Migrator().AddColumn(&Project{}, "Protected"); err != nil {
+					return err
+				}
+			}
+			if tx.Migrator().HasColumn(&SiteRelease{}, "ApprovalActivate") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&SiteRelease{}, "ApprovalActivate")
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropColumn(&SiteRelease{}, "ApprovalActivate"); err != nil {
+				return err
+			}
+			return tx.Migrator().DropColumn(&Project{}, "Protected")
+		},
+	},
+	{
+		Version: 56,
+		Name:    "cron tasks",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&CronTask{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&CronTask{})
+		},
+	},
+	{
+		Version: 57,
+		Name:    "link check reports",
+		Up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasColumn(&SiteRelease{}, "LinkReport") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&SiteRelease{}, "LinkReport")
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&SiteRelease{}, "LinkReport")
+		},
+	},
+	{
+		Version: 58,
+		Name:    "site request logs",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&AccessLog{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&AccessLog{})
+		},
+	},
+	{
+		Version: 59,
+		Name:    "job trace context",
+		Up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasColumn(&Job{}, "TraceParent") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&Job{}, "TraceParent")
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&Job{}, "TraceParent")
+		},
+	},
+	{
+		Version: 60,
+		Name:    "disabled users",
+		Up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasColumn(&User{}, "DisabledAt") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&User{}, "DisabledAt")
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&User{}, "DisabledAt")
+		},
+	},
+	{
+		Version: 61,
+		Name:    "release retention",
+		Up: func(tx *gorm.DB) error {
+			for _, column := range []string{"KeepReleases", "KeepReleaseDays"} {
+				if tx.Migrator().HasColumn(&Project{}, column) {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(&Project{}, column); err != nil {
+					return err
+				}
+			}
+			if tx.Migrator().HasColumn(&SiteRelease{}, "Pinned") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&SiteRelease{}, "Pinned")
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropColumn(&SiteRelease{}, "Pinned"); err != nil {
+				return err
+			}
+			for _, column := range []string{"KeepReleaseDays", "KeepReleases"} {
+				if err := tx.Migrator().DropColumn(&Project{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 62,
+		Name:    "deployment changelog",
+		Up: func(tx *gorm.DB) error {
+			if !tx.Migrator().HasColumn(&SiteRelease{}, "Changelog") {
+				if err := tx.Migrator().AddColumn(&SiteRelease{}, "Changelog"); err != nil {
+					return err
+				}
+			}
+			if tx.Migrator().HasColumn(&Upload{}, "Changelog") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&Upload{}, "Changelog")
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropColumn(&Upload{}, "Changelog"); err != nil {
+				return err
+			}
+			return tx.Migrator().DropColumn(&SiteRelease{}, "Changelog")
+		},
+	},
+}
+
+// scheduleColumns 定时上线和到期所需的列
+// Columns needed by scheduled go-lives and expiries
+func scheduleColumns() []struct {
+	model   any
+	columns []string
+} {
+	return []struct {
+		model   any
+		columns []string
+	}{
+		{&SiteRelease{}, []string{"PublishAt", "ExpiresAt", "ExpireAction"}},
+		{&Upload{}, []string{"PublishAt", "ReleaseExpiresAt", "ExpireAction"}},
+	}
+}
+
+// baselineModels 基线迁移创建的模型
+// Models created by the baseline migration
+var baselineModels = []any{
+	// entity.go
+	&User{},
+	&Organization{},
+	&Project{},
+	// file.go
+	&File{},
+	// jwt
+	&Token{},
+	// oidc_config.go
+	&OIDCConfig{},
+	// site.go
+	&Site{},
+	&SiteDomain{},
+	&SiteRelease{},
+	&SitePathPurge{},
+	&SiteBlockedPath{},
+	// node.go
+	&Node{},
+}
+
+// LatestVersion 注册表中的最新版本
+// Latest version in the registry
+func LatestVersion() int {
+	if len(Migrations) == 0 {
+		return 0
+	}
+	return Migrations[len(Migrations)-1].Version
+}
+
+// CurrentVersion 数据库当前的迁移版本
+// Current migration version of the database
+func CurrentVersion(db *gorm.DB) (version int, err error) {
+	err = db.Model(&SchemaMigration{}).Select("COALESCE(MAX(version), 0)").Scan(&version).Error
+	return
+}
+
+// MigrateTo 升级或回滚到指定版本
+// Upgrade or roll back to the given version
+func MigrateTo(db *gorm.DB, target int) error {
+	if db.Dialector.Name() != "sqlite" {
+		return migrateTo(db, target)
+	}
+	// SQLite 修改列时会重建表，开启外键时删除旧表会级联删除引用它的行；
+	// 外键开关属于连接且在事务中无效，因此在同一个连接上关闭后再迁移
+	// SQLite rebuilds tables to alter columns and dropping the old table cascades to the rows referencing it while foreign keys are on;
+	// the switch belongs to the connection and is ignored inside transactions, so it is turned off on one connection for the migration
+	return db.Connection(func(conn *gorm.DB) error {
+		conn = conn.Session(&gorm.Session{NewDB: true})
+		var enabled int
+		if err := conn.Raw("PRAGMA foreign_keys").Scan(&enabled).Error; err != nil {
+			return err
+		}
+		if enabled == 0 {
+			return migrateTo(conn, target)
+		}
+		if err := conn.Exec("PRAGMA foreign_keys = OFF").Error; err != nil {
+			return err
+		}
+		defer conn.Exec("PRAGMA foreign_keys = ON")
+		return migrateTo(conn, target)
+	})
+}
+
+func migrateTo(db *gorm.DB, target int) error {
+	for i := 1; i < len(Migrations); i++ {
+		if Migrations[i].Version <= Migrations[i-1].Version {
+			return fmt.Errorf("migration %d is not in increasing order", Migrations[i].Version)
+		}
+	}
+	if target < 0 || target > LatestVersion() {
+		return fmt.Errorf("target version %d out of range [0, %d]", target, LatestVersion())
+	}
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return err
+	}
+	current, err := CurrentVersion(db)
+	if err != nil {
+		return err
+	}
+	// 升级 Upgrade
+	for _, migration := range Migrations {
+		if migration.Version <= current || migration.Version > target {
+			continue
+		}
+		logrus.Infof("Applying migration %d: %s", migration.Version, migration.Name)
+		err := runMigration(db, migration, migration.Up, func(tx *gorm.DB) error {
+			return tx.Create(&SchemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("migration %d %s failed: %w", migration.Version, migration.Name, err)
+		}
+	}
+	// 回滚 Roll back
+	for i := len(Migrations) - 1; i >= 0; i-- {
+		migration := Migrations[i]
+		if migration.Version > current || migration.Version <= target {
+			continue
+		}
+		if migration.Down == nil {
+			return fmt.Errorf("migration %d %s cannot be rolled back", migration.Version, migration.Name)
+		}
+		logrus.Infof("Rolling back migration %d: %s", migration.Version, migration.Name)
+		err := runMigration(db, migration, migration.Down, func(tx *gorm.DB) error {
+			return tx.Delete(&SchemaMigration{}, migration.Version).Error
+		})
+		if err != nil {
+			return fmt.Errorf("rollback %d %s failed: %w", migration.Version, migration.Name, err)
+		}
+	}
+	return nil
+}
+
+func runMigration(db *gorm.DB, migration Migration, step, record func(tx *gorm.DB) error) error {
+	if step == nil {
+		return errors.New("missing migration step")
+	}
+	if migration.NoTx {
+		if err := step(db); err != nil {
+			return err
+		}
+		return record(db)
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := step(tx); err != nil {
+			return err
+		}
+		return record(tx)
+	})
+}
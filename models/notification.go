@@ -0,0 +1,78 @@
+package models
+
+import (
+	"slices"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"gorm.io/gorm"
+)
+
+// NotificationChannel 用户或组织的通知渠道，订阅的事件发生在其项目上时通过邮件或聊天机器人通知
+// Notification channel of a user or organization, notified by email or a chat bot when subscribed events happen on its projects
+type NotificationChannel struct {
+	gorm.Model
+	OwnerType string   `gorm:"size:16;not null;index:idx_notification_channels_owner"` // 所有者类型 Owner type
+	OwnerID   uint     `gorm:"not null;index:idx_notification_channels_owner"`         // 所有者ID Owner ID
+	Name      string   `gorm:"size:64;not null"`                                       // 名称 Name
+	Kind      string   `gorm:"size:16;not null"`                                       // 渠道类型 Channel kind
+	Target    string   `gorm:"not null"`                                               // 邮箱地址、webhook 地址或聊天ID Email address, webhook URL or chat ID
+	Secret    string   `gorm:"serializer:encrypted"`                                   // 签名密钥或机器人令牌，加密保存 Signing secret or bot token, stored encrypted
+	Events    []string `gorm:"serializer:json;type:json;default:'[]'"`                 // 订阅的事件，为空时订阅失败和警告 Subscribed events, failures and warnings when empty
+	Active    bool     `gorm:"not null;default:true"`                                  // 是否启用 Whether enabled
+}
+
+// TableName 重写表名
+// Rewrite table name
+func (NotificationChannel) TableName() string {
+	return "notification_channels"
+}
+
+// Subscribes 是否订阅了事件
+// Whether the channel subscribes to the event
+func (n *NotificationChannel) Subscribes(event string) bool {
+	if len(n.Events) == 0 {
+		return slices.Contains(constants.NotificationDefaultEvents, event)
+	}
+	return slices.Contains(n.Events, event)
+}
+
+// NotificationDelivery 一次通知投递及其最近一次尝试的结果
+// A notification delivery and the outcome of its latest attempt
+type NotificationDelivery struct {
+	ID             uint                `gorm:"primaryKey"`                                                        // 投递ID Delivery ID
+	CreatedAt      time.Time           `gorm:"not null"`                                                          // 创建时间 Created time
+	ChannelID      uint                `gorm:"not null;index"`                                                    // 通知渠道ID Notification channel ID
+	Channel        NotificationChannel `gorm:"foreignKey:ChannelID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"` // 通知渠道 Notification channel
+	Event          string              `gorm:"size:64;not null"`                                                  // 事件 Event
+	Subject        string              `gorm:"not null"`                                                          // 标题 Subject
+	Text           string              `gorm:"type:text"`                                                         // 正文 Text
+	Status         string              `gorm:"size:16;not null;default:pending;index"`                            // 投递状态 Delivery status
+	Attempts       int                 `gorm:"not null;default:0"`                                                // 已尝试次数 Attempts made
+	NextAttemptAt  time.Time           `gorm:"not null;index"`                                                    // 下次尝试时间 Next attempt time
+	ResponseStatus int                 // 最近一次的响应状态码，邮件为 0 Status code of the latest response, 0 for email
+	ResponseBody   string              `gorm:"type:text"` // 最近一次的响应体，已截断 Body of the latest response, truncated
+	Error          string              // 最近一次的错误 Error of the latest attempt
+	DeliveredAt    *time.Time          // 投递成功时间 Time of successful delivery
+}
+
+// TableName 重写表名
+// Rewrite table name
+func (NotificationDelivery) TableName() string {
+	return "notification_deliveries"
+}
+
+// NotificationAlert 已通知过的持续性警告，警告解除前不重复通知
+// A lasting warning that was notified, not notified again until it clears
+type NotificationAlert struct {
+	OwnerType string    `gorm:"primaryKey;size:16"`             // 所有者类型 Owner type
+	OwnerID   uint      `gorm:"primaryKey;autoIncrement:false"` // 所有者ID Owner ID
+	Key       string    `gorm:"primaryKey;size:255"`            // 警告的键，例如 quota.storage Key of the warning, e.g. quota.storage
+	CreatedAt time.Time // 通知时间 Time notified
+}
+
+// TableName 重写表名
+// Rewrite table name
+func (NotificationAlert) TableName() string {
+	return "notification_alerts"
+}
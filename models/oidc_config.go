@@ -1,17 +1,20 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"github.com/LiteyukiStudio/spage/constants"
+	"gorm.io/gorm"
+)
 
 type OIDCConfig struct {
 	gorm.Model
-	AdminGroups []string `gorm:"type:json;column:admin_groups;default:'[]'"` // 平台管理员组，默认为：[]string{}，*为匹配所有组，储存为逗号分隔的字符串
+	AdminGroups []string `gorm:"serializer:json;type:json;column:admin_groups;default:'[]'"` // 平台管理员组，默认为：[]string{}，*为匹配所有组，储存为逗号分隔的字符串
 	// Admin groups, default is: []string{}, * matches all groups, stored as a comma-separated string
-	AllowedGroups []string `gorm:"type:json;column:allowed_groups;default:'[\"*\"]'"` // 允许登录的组，默认为：[]string{"*"}，*为匹配所有组，储存为逗号分隔的字符串
+	AllowedGroups []string `gorm:"serializer:json;type:json;column:allowed_groups;default:'[\"*\"]'"` // 允许登录的组，默认为：[]string{"*"}，*为匹配所有组，储存为逗号分隔的字符串
 	// Allowed groups for login, default is: []string{"*"}, * matches all groups, stored as a comma-separated string
 	ClientID string `gorm:"column:client_id"` // 客户端ID
 	// Client ID
-	ClientSecret string `gorm:"column:client_secret"` // 客户端密钥
-	// Client Secret
+	ClientSecret string `gorm:"column:client_secret;serializer:encrypted"` // 客户端密钥，加密保存
+	// Client Secret, stored encrypted
 	DisplayName string `gorm:"column:display_name"` // 显示名称，例如：轻雪通行证
 	// Display name, e.g., Light Snow Passport
 	GroupsClaim *string `gorm:"default:groups"` // 组声明，默认为："groups"
@@ -20,6 +23,36 @@ type OIDCConfig struct {
 	// Icon URL, if empty use the built-in default icon
 	OidcDiscoveryURL string `gorm:"column:oidc_discovery_url"` // OpenID自动发现URL，例如 ：https://pass.liteyuki.icu/.well-known/openid-configuration
 	// OpenID auto-discovery URL, e.g., https://pass.liteyuki.icu/.well-known/openid-configuration
+	Type constants.AuthProviderType `gorm:"not null;default:oidc"` // 提供方类型：github、gitlab、oidc 或 ldap
+	// Provider type: github, gitlab, oidc or ldap
+	BaseURL string `gorm:"column:base_url"` // 自托管 GitLab 或 GitHub Enterprise 的地址，留空使用官方服务；LDAP 提供方为服务器地址
+	// Base URL of a self-hosted GitLab or GitHub Enterprise, leave empty for the public service; the server URL for LDAP providers
+	Enabled bool `gorm:"not null;default:true"` // 是否允许通过该提供方登录
+	// Whether signing in through this provider is allowed
+	LDAP LDAPSettings `gorm:"serializer:json;type:json;column:ldap;default:'{}'"` // LDAP 提供方的目录设置，ClientID 和 ClientSecret 为查询用户的绑定 DN 和密码
+	// Directory settings of LDAP providers, ClientID and ClientSecret are the bind DN and password used to look up users
+}
+
+// LDAPSettings LDAP 目录的查询和映射设置，属性为空时使用 OpenLDAP 的常用名称
+// Lookup and mapping settings of an LDAP directory, empty attributes fall back to common OpenLDAP names
+type LDAPSettings struct {
+	UserBaseDN         string             `json:"user_base_dn"`         // 查找用户的起点 Base DN to search users in
+	UserFilter         string             `json:"user_filter"`          // 查找用户的过滤器，{username} 替换为登录名，默认为 (uid={username}) Filter to find the user, {username} is the login name, (uid={username}) by default
+	UsernameAttribute  string             `json:"username_attribute"`   // 用户名属性，默认为 uid Username attribute, uid by default
+	EmailAttribute     string             `json:"email_attribute"`      // 邮箱属性，默认为 mail Email attribute, mail by default
+	NameAttribute      string             `json:"name_attribute"`       // 显示名称属性，默认为 cn Display name attribute, cn by default
+	GroupAttribute     string             `json:"group_attribute"`      // 用户所属组的属性，默认为 memberOf Attribute listing the user's groups, memberOf by default
+	StartTLS           bool               `json:"start_tls"`            // 在 ldap:// 连接上启用 StartTLS Use StartTLS on ldap:// connections
+	InsecureSkipVerify bool               `json:"insecure_skip_verify"` // 不校验服务器证书 Skip verifying the server certificate
+	GroupMappings      []LDAPGroupMapping `json:"group_mappings"`       // 组到组织角色的映射 Mappings from groups to organization roles
+}
+
+// LDAPGroupMapping 目录组到组织角色的映射，登录时加入组织或提升角色，不会自动降级或移除
+// Mapping from a directory group to an organization role, signing in joins the organization or raises the role, never demoting or removing automatically
+type LDAPGroupMapping struct {
+	Group        string            `json:"group"`        // 组的 DN 或 CN Group DN or CN
+	Organization string            `json:"organization"` // 组织名称 Organization name
+	Role         constants.OrgRole `json:"role"`         // 组织角色 Organization role
 }
 
 // TableName 重写表名
@@ -27,3 +60,21 @@ type OIDCConfig struct {
 func (OIDCConfig) TableName() string {
 	return "oidc_configs"
 }
+
+// UserIdentity 用户在第三方登录提供方的身份，一个用户可以关联多个提供方
+// A user's identity at a third-party auth provider, a user may link several providers
+type UserIdentity struct {
+	gorm.Model
+	UserID     uint       `gorm:"not null;index"`                                    // 本地用户ID Local user ID
+	User       User       `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`     // 本地用户 Local user
+	ProviderID uint       `gorm:"not null;uniqueIndex:idx_user_identities_subject"`  // 登录提供方ID Auth provider ID
+	Provider   OIDCConfig `gorm:"foreignKey:ProviderID;constraint:OnDelete:CASCADE"` // 登录提供方 Auth provider
+	Subject    string     `gorm:"not null;uniqueIndex:idx_user_identities_subject"`  // 提供方内的唯一用户标识 Unique user identifier at the provider
+	Email      *string    // 提供方返回的邮箱 Email returned by the provider
+}
+
+// TableName 重写表名
+// Rewrite table name
+func (UserIdentity) TableName() string {
+	return "user_identities"
+}
@@ -0,0 +1,21 @@
+package models
+
+import "gorm.io/gorm"
+
+// Quota 管理员为单个用户或组织设置的配额，字段为空时使用配置文件中的默认值，0 为不限制
+// Quota set by an administrator for one user or organization, empty fields fall back to the configured defaults and 0 means unlimited
+type Quota struct {
+	gorm.Model
+	OwnerType         string `gorm:"not null;uniqueIndex:idx_quotas_owner"` // 所有者类型 user 或 organization Owner type, user or organization
+	OwnerID           uint   `gorm:"not null;uniqueIndex:idx_quotas_owner"` // 所有者ID Owner ID
+	MaxProjects       *int64 // 可创建的项目数量 Number of projects that may be created
+	MaxDeploymentSize *int64 // 单次部署解压后的最大字节数 Largest uncompressed size of one deployment in bytes
+	MaxStorage        *int64 // 可占用的存储字节数 Storage that may be occupied in bytes
+	MaxUploadSize     *int64 // 单次上传的最大字节数 Largest single upload in bytes
+}
+
+// TableName 重写表名
+// Rewrite table name
+func (Quota) TableName() string {
+	return "quotas"
+}
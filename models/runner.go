@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Runner 远程构建执行器，在其他主机上领取并执行 Git 推送部署的构建，只保存令牌的哈希值，明文仅在创建时返回一次；
+// 标签、并发数和版本由执行器注册时上报
+// Remote build runner taking and running the builds of git push deployments on another host, only the hash of the token is stored and the plain token is returned once on creation;
+// the labels, concurrency and version are reported by the runner when it registers
+type Runner struct {
+	gorm.Model
+	Name        string     `gorm:"not null"`                  // 执行器名称 Runner name
+	Prefix      string     `gorm:"not null"`                  // 令牌开头部分，用于识别 Leading part of the token, for identification
+	Hash        string     `gorm:"not null;uniqueIndex"`      // 令牌的 SHA-256 哈希 SHA-256 hash of the token
+	Labels      []string   `gorm:"serializer:json;type:text"` // 执行器的标签 Labels of the runner
+	Concurrency int        `gorm:"not null;default:1"`        // 同时执行的构建数量 Number of builds run at the same time
+	Version     string     `gorm:"size:64"`                   // 执行器的版本 Version of the runner
+	LastSeenAt  *time.Time // 最后一次注册、心跳或领取构建的时间 Time of the latest registration, heartbeat or claim
+}
+
+// TableName 重写表名
+// Rewrite table name
+func (Runner) TableName() string {
+	return "runners"
+}
+
+// RunnerBuild 等待或正在由远程执行器执行的构建，Status 沿用 Git 推送部署的运行状态；
+// 执行器通过心跳延长租约，租约过期的构建视为失败
+// Build waiting for or running on a remote runner, Status reuses the run statuses of git push deployments;
+// runners extend the lease through heartbeats and builds whose lease expired count as failed
+type RunnerBuild struct {
+	gorm.Model
+	SiteID        uint           `gorm:"not null;index"`                                       // 站点ID Site ID
+	IntegrationID uint           `gorm:"not null;index"`                                       // 仓库绑定ID Repository binding ID
+	Integration   GitIntegration `gorm:"foreignKey:IntegrationID;constraint:OnDelete:CASCADE"` // 仓库绑定 Repository binding
+	Commit        string         `gorm:"size:64"`                                              // 请求构建的提交，为空时构建分支的最新提交 Commit asked for, the head of the branch when empty
+	MapCNAME      bool           // 发布后是否绑定 CNAME 文件中的域名 Whether the domain in the CNAME file is bound after publishing
+	Labels        []string       `gorm:"serializer:json;type:text"` // 执行器必须具有的标签 Labels the runner must have
+	Status        string         `gorm:"size:16;not null;index"`    // 运行状态 Run status
+	RunnerID      *uint          `gorm:"index"`                     // 领取构建的执行器ID ID of the runner that took the build
+	LeaseUntil    *time.Time     // 租约到期时间 Lease expiry
+	Error         string         // 失败原因 Failure reason
+	StartedAt     *time.Time     // 开始时间 Start time
+	FinishedAt    *time.Time     // 结束时间 Finish time
+}
+
+// TableName 重写表名
+// Rewrite table name
+func (RunnerBuild) TableName() string {
+	return "runner_builds"
+}
@@ -0,0 +1,118 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// SearchTable 全文检索覆盖的表及其文本列
+// A table covered by full-text search and its text columns
+type SearchTable struct {
+	Table   string   // 表名 Table name
+	Columns []string // 参与检索的列 Columns searched
+}
+
+// SearchTables 全文检索覆盖的表，SQLite 为每张表维护一个 FTS5 trigram 外部内容表，Postgres 使用 tsvector 表达式上的 GIN 索引
+// Tables covered by full-text search, SQLite keeps an FTS5 trigram external content table per table and Postgres uses a GIN index on a tsvector expression
+var SearchTables = []SearchTable{
+	{Table: "projects", Columns: []string{"name", "display_name", "description"}},
+	{Table: "sites", Columns: []string{"name", "description", "sub_domain"}},
+	{Table: "users", Columns: []string{"name", "display_name"}},
+	{Table: "organizations", Columns: []string{"name", "display_name"}},
+}
+
+// FTSTable SQLite 中对应的 FTS5 表名
+// Name of the matching FTS5 table on SQLite
+func (t SearchTable) FTSTable() string {
+	return t.Table + "_fts"
+}
+
+// Vector Postgres 中的 tsvector 表达式，查询必须使用相同的表达式才能命中索引
+// The tsvector expression on Postgres, queries must use the very same expression to hit the index
+func (t SearchTable) Vector() string {
+	parts := make([]string, 0, len(t.Columns))
+	for _, column := range t.Columns {
+		parts = append(parts, fmt.Sprintf("COALESCE(%s, '')", column))
+	}
+	return fmt.Sprintf("to_tsvector('simple', %s)", strings.Join(parts, " || ' ' || "))
+}
+
+// searchIndexes Postgres 全文检索的 GIN 索引
+// GIN indexes of full-text search on Postgres
+func searchIndexes() []Index {
+	indexes := make([]Index, 0, len(SearchTables))
+	for _, table := range SearchTables {
+		indexes = append(indexes, Index{
+			Name:    "idx_" + table.Table + "_search",
+			Table:   table.Table,
+			Columns: table.Vector(),
+			Using:   "GIN",
+			Dialect: "postgres",
+		})
+	}
+	return indexes
+}
+
+// ensureFullText 在 SQLite 中创建 FTS5 表及同步触发器，并从原表重建检索内容
+// Create the FTS5 tables and their sync triggers on SQLite, rebuilding the search content from the source tables
+func ensureFullText(db *gorm.DB) error {
+	if db.Dialector.Name() != "sqlite" {
+		return nil
+	}
+	for _, table := range SearchTables {
+		columns := strings.Join(table.Columns, ", ")
+		values := func(prefix string) string {
+			parts := make([]string, 0, len(table.Columns))
+			for _, column := range table.Columns {
+				parts = append(parts, prefix+column)
+			}
+			return strings.Join(parts, ", ")
+		}
+		fts := table.FTSTable()
+		stmts := []string{
+			fmt.Sprintf("CREATE VIRTUAL TABLE IF NOT EXISTS %s USING fts5(%s, content='%s', content_rowid='id', tokenize='trigram')", fts, columns, table.Table),
+			fmt.Sprintf("CREATE TRIGGER IF NOT EXISTS %[1]s_ai AFTER INSERT ON %[2]s BEGIN INSERT INTO %[1]s(rowid, %[3]s) VALUES (new.id, %[4]s); END",
+				fts, table.Table, columns, values("new.")),
+			fmt.Sprintf("CREATE TRIGGER IF NOT EXISTS %[1]s_ad AFTER DELETE ON %[2]s BEGIN INSERT INTO %[1]s(%[1]s, rowid, %[3]s) VALUES ('delete', old.id, %[4]s); END",
+				fts, table.Table, columns, values("old.")),
+			fmt.Sprintf("CREATE TRIGGER IF NOT EXISTS %[1]s_au AFTER UPDATE ON %[2]s BEGIN INSERT INTO %[1]s(%[1]s, rowid, %[3]s) VALUES ('delete', old.id, %[4]s); INSERT INTO %[1]s(rowid, %[3]s) VALUES (new.id, %[5]s); END",
+				fts, table.Table, columns, values("old."), values("new.")),
+			fmt.Sprintf("INSERT INTO %[1]s(%[1]s) VALUES ('rebuild')", fts),
+		}
+		for _, stmt := range stmts {
+			if err := db.Exec(stmt).Error; err != nil {
+				return fmt.Errorf("create full-text table %s: %w", fts, err)
+			}
+		}
+	}
+	return nil
+}
+
+// dropFullText 删除 SQLite 的 FTS5 表和触发器，以及 Postgres 的 GIN 索引
+// Drop the FTS5 tables and triggers on SQLite and the GIN indexes on Postgres
+func dropFullText(db *gorm.DB) error {
+	if db.Dialector.Name() == "sqlite" {
+		for _, table := range SearchTables {
+			fts := table.FTSTable()
+			for _, stmt := range []string{
+				"DROP TRIGGER IF EXISTS " + fts + "_ai",
+				"DROP TRIGGER IF EXISTS " + fts + "_ad",
+				"DROP TRIGGER IF EXISTS " + fts + "_au",
+				"DROP TABLE IF EXISTS " + fts,
+			} {
+				if err := db.Exec(stmt).Error; err != nil {
+					return fmt.Errorf("drop full-text table %s: %w", fts, err)
+				}
+			}
+		}
+		return nil
+	}
+	for _, index := range searchIndexes() {
+		if err := db.Exec("DROP INDEX IF EXISTS " + index.Name).Error; err != nil {
+			return fmt.Errorf("drop index %s: %w", index.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Setting 管理员在运行时修改的动态配置项，覆盖配置文件中的值
+// Dynamic setting changed by an administrator at runtime, overriding the value of the configuration file
+type Setting struct {
+	Key       string    `gorm:"primaryKey;size:128"` // 配置项名称，如 quota.max-projects Setting key, e.g. quota.max-projects
+	Value     string    `gorm:"not null"`            // JSON 编码的值 JSON encoded value
+	UpdatedAt time.Time // 修改时间 Update time
+	UpdatedBy uint      // 修改者用户ID User ID of the last editor
+}
+
+// TableName 重写表名
+// Rewrite table name
+func (Setting) TableName() string {
+	return "settings"
+}
@@ -1,6 +1,9 @@
 package models
 
 import (
+	"time"
+
+	"github.com/LiteyukiStudio/spage/constants"
 	"gorm.io/gorm"
 )
 
@@ -11,7 +14,23 @@ type Site struct {
 	ProjectID   uint     `gorm:"not null"`                                                          // 项目ID Project ID
 	Project     Project  `gorm:"foreignKey:ProjectID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"` // 项目 Project
 	SubDomain   string   `gorm:"unique;size:255"`                                                   // 子域前缀 Subdomain prefix
-	Domains     []string `gorm:"type:json;default:'[]'"`                                            // 允许的域名，json格式 Allowed domains, json format
+	Domains     []string `gorm:"serializer:json;type:json;default:'[]'"`                            // 允许的域名，json格式 Allowed domains, json format
+	OGPreview   bool     `gorm:"default:false"`                                                     // 是否在发布时生成 open-graph 预览图 Whether to generate an open-graph preview image at publish time
+	Headers     []string `gorm:"serializer:json;type:json;default:'[]'"`                            // 自定义响应头，格式为 "Name: value" Custom response headers, formatted as "Name: value"
+	CacheRules  []string `gorm:"serializer:json;type:json;default:'[]'"`                            // 按路径的 Cache-Control，格式为 "<路径模式> <值>" Cache-Control by path, formatted as "<pattern> <value>"
+
+	AccessMode     constants.SiteAccessMode `gorm:"not null;default:public"` // 访问保护方式 Access protection
+	AccessPassword string                   `gorm:"size:255"`                // 访问密码的 bcrypt 哈希，仅密码模式使用 Bcrypt hash of the access password, only used by the password mode
+
+	AllowIPs []string `gorm:"serializer:json;type:json;default:'[]'"` // 允许访问的地址或 CIDR 网段，为空时不限制 Addresses or CIDR ranges allowed to visit, no restriction when empty
+	DenyIPs  []string `gorm:"serializer:json;type:json;default:'[]'"` // 禁止访问的地址或 CIDR 网段，优先于允许规则 Addresses or CIDR ranges refused, taking precedence over allow rules
+
+	Sitemap      bool   `gorm:"default:false"` // 上传的文件中没有 sitemap.xml 时是否按部署的页面生成 Whether sitemap.xml is generated from the deployed pages when the upload has none
+	RobotsPolicy string `gorm:"size:16"`       // 上传的文件中没有 robots.txt 时使用的策略，为空时不生成 Policy used when the upload has no robots.txt, never generated when empty
+	RobotsTxt    string `gorm:"type:text"`     // 自定义 robots.txt 内容，仅 custom 策略使用 Custom robots.txt content, only used by the custom policy
+
+	TransferSoftLimit *int64 // 管理员为站点设置的每月流量软限制字节数，为空时使用 traffic.soft-limit Soft monthly transfer limit in bytes set by an administrator, traffic.soft-limit applies when empty
+	TransferHardLimit *int64 // 管理员为站点设置的每月流量硬限制字节数，为空时使用 traffic.hard-limit Hard monthly transfer limit in bytes set by an administrator, traffic.hard-limit applies when empty
 }
 
 // 站点表名 Site table name
@@ -19,17 +38,101 @@ func (Site) TableName() string {
 	return "sites"
 }
 
+// 站点域名索引表，与 Site.Domains 同步，用于按主机名查找站点
+// Site domain lookup table, kept in sync with Site.Domains for site lookup by hostname
+type SiteDomain struct {
+	ID     uint   `gorm:"primaryKey"`
+	SiteID uint   `gorm:"not null"` // 站点ID Site ID
+	Domain string `gorm:"not null"` // 小写域名 Lowercase domain
+}
+
+// 站点域名表名 Site domain table name
+func (SiteDomain) TableName() string {
+	return "site_domains"
+}
+
 // 站点发布表 Site release table
 type SiteRelease struct {
 	gorm.Model
-	SiteID uint   `gorm:"not null"` // 站点ID Site ID
-	Site   Site   `gorm:"foreignKey:SiteID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
-	Tag    string `gorm:"not null"`                                                        // 版本标签 Version tag
-	FileID uint   `gorm:"not null"`                                                        // 版本文件ID Version file ID
-	File   File   `gorm:"foreignKey:FileID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"` // 版本文件 Version file
+	SiteID uint                       `gorm:"not null"` // 站点ID Site ID
+	Site   Site                       `gorm:"foreignKey:SiteID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Tag    string                     `gorm:"not null"`                                                        // 版本标签 Version tag
+	FileID uint                       `gorm:"not null"`                                                        // 版本文件ID Version file ID
+	File   File                       `gorm:"foreignKey:FileID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"` // 版本文件 Version file
+	Status constants.DeploymentStatus `gorm:"not null;default:ready"`                                          // 部署状态 Deployment status
+
+	PreviewPath string `gorm:"column:preview_path"` // open-graph 预览图对象键 Open-graph preview image object key
+	PreviewHash string `gorm:"column:preview_hash"` // 预览图输入摘要，输入不变时复用 Digest of preview inputs, reused when unchanged
+
+	ActiveReleaseID *uint `gorm:"column:active_release_id"` // 仅 latest 记录使用，指向当前激活的版本 Only used by the latest record, pointing at the active version
+
+	PublishAt    *time.Time // 定时上线时间 Scheduled go-live time
+	ExpiresAt    *time.Time // 到期时间，到期时仍激活则按 ExpireAction 处理 Expiry time, handled by ExpireAction when the version is still active then
+	ExpireAction string     `gorm:"size:16"` // 到期处理方式 Expiry action
+
+	ScanFindings string `gorm:"type:text"` // 内容扫描发现的恶意文件，JSON 格式 Malicious files found by the content scan, as JSON
+	ScanActivate bool   // 隔离的版本审核通过后是否激活 Whether a quarantined version is activated once approved
+
+	ApprovalActivate bool // 等待批准的版本批准后是否激活 Whether a version awaiting approval is activated once approved
+
+	LinkReport string `gorm:"type:text"` // 上线后链接检查的报告，JSON 格式，未检查时为空 Report of the link check after going live, as JSON, empty when never checked
+
+	Pinned bool `gorm:"default:false"` // 固定的版本不会被保留策略删除 Pinned versions are never removed by the retention policy
+
+	Changelog string `gorm:"type:text"` // 上传时提供的变更说明，显示在部署订阅源中 Changelog given with the upload, shown in the deployment feeds
+
+	Checksums map[string]string `gorm:"serializer:json;type:text"` // 上传时提供并在解压后校验通过的文件 SHA-256，未提供时为空 SHA-256 of the files given with the upload and verified after extraction, empty when none were given
 }
 
 // 站点发布表名 Site release table name
 func (SiteRelease) TableName() string {
 	return "site_releases"
 }
+
+// 站点路径清除记录 Site path purge record
+type SitePathPurge struct {
+	gorm.Model
+	SiteID        uint     `gorm:"not null;index"`  // 站点ID Site ID
+	ProjectID     uint     `gorm:"not null;index"`  // 项目ID，用于按项目限流 Project ID, used for per-project rate limiting
+	UserID        uint     `gorm:"not null"`        // 操作者ID Operator ID
+	Paths         []string `gorm:"serializer:json"` // 清除的路径或前缀 Purged paths or prefixes
+	Removed       int      // 实际移除的文件数 Number of files actually removed
+	FromReleaseID uint     // 清除前的发布ID Release ID before purging
+	ToReleaseID   uint     // 清除后派生的发布ID Derived release ID after purging
+	Takedown      bool     // 是否为下架，下架路径在所有历史发布中屏蔽 Whether it is a takedown, blocked in all historic releases
+}
+
+// 站点路径清除记录表名 Site path purge table name
+func (SitePathPurge) TableName() string {
+	return "site_path_purges"
+}
+
+// 站点屏蔽路径，由下架操作产生 Site blocked path, produced by takedowns
+type SiteBlockedPath struct {
+	gorm.Model
+	SiteID  uint   `gorm:"not null;index"` // 站点ID Site ID
+	Pattern string `gorm:"not null"`       // 路径或以 /* 结尾的前缀 Path or prefix ending with /*
+	PurgeID uint   `gorm:"not null"`       // 来源清除记录ID Source purge record ID
+}
+
+// 站点屏蔽路径表名 Site blocked path table name
+func (SiteBlockedPath) TableName() string {
+	return "site_blocked_paths"
+}
+
+// 站点预览部署，通过 <name>--<sub_domain>.<base-domain> 访问，到期后自动清理
+// Site preview deployment, served at <name>--<sub_domain>.<base-domain> and cleaned up once expired
+type SitePreview struct {
+	gorm.Model
+	SiteID    uint        `gorm:"not null;uniqueIndex:idx_site_previews_site_name"`                  // 站点ID Site ID
+	Site      Site        `gorm:"foreignKey:SiteID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`    // 站点 Site
+	Name      string      `gorm:"not null;uniqueIndex:idx_site_previews_site_name"`                  // 分支或合并请求标识，例如 pr-42 Branch or pull request identifier, e.g. pr-42
+	ReleaseID uint        `gorm:"not null"`                                                          // 预览的版本ID Previewed version ID
+	Release   SiteRelease `gorm:"foreignKey:ReleaseID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"` // 预览的版本 Previewed version
+	ExpiresAt time.Time   `gorm:"not null;index"`                                                    // 过期时间 Expiration time
+}
+
+// 站点预览部署表名 Site preview table name
+func (SitePreview) TableName() string {
+	return "site_previews"
+}
@@ -0,0 +1,30 @@
+package models
+
+import "gorm.io/gorm"
+
+// Tenant 同一实例中相互隔离的租户，例如不同的业务部门；用户和组织属于至多一个租户，项目随所有者属于同一租户，
+// 没有租户的用户、组织和项目属于实例本身
+// Isolated tenant within one instance such as a business unit; users and organizations belong to at most one tenant and projects follow their owner,
+// users, organizations and projects without a tenant belong to the instance itself
+type Tenant struct {
+	gorm.Model
+	Name        string  `gorm:"not null;uniqueIndex"` // 租户的唯一名称 Unique name of the tenant
+	DisplayName string  `gorm:"not null"`             // 显示名称，同时作为控制台中展示的品牌名称 Display name, also shown as the brand name in the dashboard
+	BaseDomain  *string `gorm:"uniqueIndex"`          // 租户站点子域的基础域名，为空时使用 serve.base-domain Base domain of the tenant's site subdomains, serve.base-domain when empty
+
+	LogoURL      *string // 控制台中展示的标志 Logo shown in the dashboard
+	PrimaryColor string  // 控制台的主题色，例如 #3b82f6 Theme color of the dashboard such as #3b82f6
+
+	// 租户内用户和组织的默认配额，字段为空时使用配置文件中的默认值，单独设置的配额优先
+	// Default quotas of the tenant's users and organizations, empty fields use the configured defaults and quotas set per owner come first
+	MaxProjects       *int64
+	MaxDeploymentSize *int64
+	MaxStorage        *int64
+	MaxUploadSize     *int64
+}
+
+// TableName 重写表名
+// Rewrite table name
+func (Tenant) TableName() string {
+	return "tenants"
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// SiteTraffic 站点每天的请求数和响应字节数，由各实例在内存中累计后定期写入
+// Requests and response bytes of a site per day, accumulated in memory by each instance and written periodically
+type SiteTraffic struct {
+	SiteID   uint      `gorm:"primaryKey;autoIncrement:false"`        // 站点ID Site ID
+	Day      time.Time `gorm:"primaryKey;index:idx_site_traffic_day"` // UTC 日期的零点 Midnight of the UTC day
+	Requests int64     `gorm:"not null;default:0"`                    // 请求数 Requests
+	Bytes    int64     `gorm:"not null;default:0"`                    // 响应字节数 Response bytes
+}
+
+// TableName 重写表名
+// Rewrite table name
+func (SiteTraffic) TableName() string {
+	return "site_traffic"
+}
+
+// 站点流量限制的级别 Levels of site transfer limits
+const (
+	TransferLimitSoft = "soft" // 软限制，只通知 Soft limit, only notifying
+	TransferLimitHard = "hard" // 硬限制，限速或暂停服务 Hard limit, throttling or suspending
+)
+
+// SiteTransferAlert 站点某月超出流量限制的记录，每个级别每月只通知一次
+// Record of a site exceeding a transfer limit in a month, each level is notified once a month
+type SiteTransferAlert struct {
+	SiteID    uint      `gorm:"primaryKey;autoIncrement:false"` // 站点ID Site ID
+	Month     string    `gorm:"primaryKey;size:7"`              // UTC 月份，格式为 2006-01 UTC month, formatted as 2006-01
+	Level     string    `gorm:"primaryKey;size:8"`              // 限制级别 Limit level
+	Bytes     int64     `gorm:"not null"`                       // 超出时的月流量 Monthly transfer when exceeded
+	CreatedAt time.Time // 超出时间 Time exceeded
+}
+
+// TableName 重写表名
+// Rewrite table name
+func (SiteTransferAlert) TableName() string {
+	return "site_transfer_alerts"
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+)
+
+// ProjectTransfer 等待接收方确认的项目转移，每个项目最多一个，接受或取消后删除
+// Project transfer awaiting confirmation by the recipient, at most one per project and deleted once accepted or cancelled
+type ProjectTransfer struct {
+	ID            uint      `gorm:"primarykey"`                                           // 转移ID Transfer ID
+	ProjectID     uint      `gorm:"not null;uniqueIndex"`                                 // 转移的项目ID Project being transferred
+	Project       Project   `gorm:"foreignKey:ProjectID;constraint:OnDelete:CASCADE"`     // 转移的项目 Project being transferred
+	OwnerType     string    `gorm:"size:16;not null;index:idx_project_transfers_owner"`   // 接收方类型，用户或组织 Recipient type, user or organization
+	OwnerID       uint      `gorm:"not null;index:idx_project_transfers_owner"`           // 接收方ID Recipient ID
+	RequestedByID uint      `gorm:"not null;index"`                                       // 发起者ID Requester ID
+	RequestedBy   User      `gorm:"foreignKey:RequestedByID;constraint:OnDelete:CASCADE"` // 发起者 Requester
+	ExpiresAt     time.Time `gorm:"not null"`                                             // 过期时间 Expiry time
+	CreatedAt     time.Time // 发起时间 Request time
+}
+
+// TableName 重写表名
+// Rewrite table name
+func (ProjectTransfer) TableName() string {
+	return "project_transfers"
+}
+
+// Pending 转移是否仍等待确认
+// Whether the transfer is still awaiting confirmation
+func (t *ProjectTransfer) Pending() bool {
+	return time.Now().Before(t.ExpiresAt)
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserTOTP 用户的 TOTP 两步验证配置，确认验证码之前不生效
+// User's TOTP two-factor configuration, it takes effect only after a code is confirmed
+type UserTOTP struct {
+	gorm.Model
+	UserID       uint   `gorm:"not null;uniqueIndex"`                          // 用户ID User ID
+	User         User   `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"` // 用户 User
+	Secret       string `gorm:"not null;serializer:encrypted"`                 // Base32 编码的密钥，加密保存 Base32 encoded secret, stored encrypted
+	Enabled      bool   `gorm:"not null;default:false"`                        // 是否已启用 Whether it is enabled
+	LastUsedStep int64  `gorm:"not null;default:0"`                            // 最后使用的时间步，防止验证码重放 Last used time step, against code replay
+}
+
+// TableName 重写表名
+// Rewrite table name
+func (UserTOTP) TableName() string {
+	return "user_totps"
+}
+
+// RecoveryCode 两步验证恢复码，只保存哈希，每个恢复码只能使用一次
+// Two-factor recovery code, only the hash is stored and each code can be used once
+type RecoveryCode struct {
+	gorm.Model
+	UserID uint       `gorm:"not null;index"`                                // 用户ID User ID
+	User   User       `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"` // 用户 User
+	Hash   string     `gorm:"not null"`                                      // 恢复码的 SHA-256 哈希 SHA-256 hash of the code
+	UsedAt *time.Time // 使用时间，空为未使用 Used time, unused when empty
+}
+
+// TableName 重写表名
+// Rewrite table name
+func (RecoveryCode) TableName() string {
+	return "recovery_codes"
+}
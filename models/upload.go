@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+// Upload 分片上传会话，全部分片上传后在服务端拼接、校验并发布
+// Chunked upload session, the chunks are assembled, verified and published on the server once all are uploaded
+type Upload struct {
+	ID        string    `gorm:"primaryKey;size:32"` // 会话ID Session ID
+	CreatedAt time.Time // 创建时间 Created time
+	SiteID    uint      `gorm:"not null;index"` // 站点ID Site ID
+	UserID    uint      `gorm:"not null"`       // 创建会话的用户ID ID of the user creating the session
+	Tag       string    `gorm:"not null"`       // 版本标签 Version tag
+	Preview   string    // 预览部署名称，为空时为正式部署 Preview deployment name, a regular deployment when empty
+	TTL       int       // 预览有效期，单位秒 Preview lifetime in seconds
+	Size      int64     `gorm:"not null"`         // 压缩包总字节数 Total size of the archive in bytes
+	SHA256    string    `gorm:"size:64;not null"` // 压缩包的 SHA-256 SHA-256 of the archive
+	ChunkSize int64     `gorm:"not null"`         // 分片字节数，最后一片可以更小 Chunk size in bytes, the last chunk may be smaller
+	ExpiresAt time.Time `gorm:"not null;index"`   // 过期时间，过期后删除已上传的分片 Expiry time, uploaded chunks are deleted afterwards
+
+	PublishAt        *time.Time // 版本的定时上线时间 Scheduled go-live time of the version
+	ReleaseExpiresAt *time.Time // 版本的到期时间 Expiry time of the version
+	ExpireAction     string     `gorm:"size:16"` // 版本的到期处理方式 Expiry action of the version
+
+	Checksums map[string]string `gorm:"serializer:json;type:text"` // 解压后校验的文件 SHA-256 SHA-256 of the files verified after extraction
+	Changelog string            `gorm:"type:text"`                 // 版本的变更说明 Changelog of the version
+}
+
+// TableName 重写表名
+// Rewrite table name
+func (Upload) TableName() string {
+	return "uploads"
+}
+
+// Chunks 分片数量
+// Number of chunks
+func (u *Upload) Chunks() int {
+	return int((u.Size + u.ChunkSize - 1) / u.ChunkSize)
+}
+
+// ChunkLength 指定分片应有的字节数
+// Expected size of the given chunk
+func (u *Upload) ChunkLength(index int) int64 {
+	if index == u.Chunks()-1 {
+		return u.Size - int64(index)*u.ChunkSize
+	}
+	return u.ChunkSize
+}
+
+// UploadChunk 已上传的分片
+// An uploaded chunk
+type UploadChunk struct {
+	UploadID string `gorm:"primaryKey;size:32"`             // 会话ID Session ID
+	Index    int    `gorm:"primaryKey;autoIncrement:false"` // 分片序号，从 0 开始 Chunk index, starting at 0
+	Size     int64  `gorm:"not null"`                       // 字节数 Size in bytes
+	SHA256   string `gorm:"size:64;not null"`               // 服务端计算的 SHA-256 SHA-256 computed by the server
+}
+
+// TableName 重写表名
+// Rewrite table name
+func (UploadChunk) TableName() string {
+	return "upload_chunks"
+}
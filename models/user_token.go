@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserToken 通过邮件发送的一次性令牌，用于验证邮箱和重置密码，只保存哈希
+// One-time token sent by email to verify an address or reset a password, only the hash is stored
+type UserToken struct {
+	gorm.Model
+	UserID    uint       `gorm:"not null;index"`                                // 用户ID User ID
+	User      User       `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"` // 用户 User
+	Purpose   string     `gorm:"not null;size:32"`                              // 用途，见 constants.UserTokenVerifyEmail 等 Purpose, see constants.UserTokenVerifyEmail etc.
+	Hash      string     `gorm:"not null;uniqueIndex"`                          // 令牌的 SHA-256 哈希 SHA-256 hash of the token
+	Email     string     `gorm:"not null"`                                      // 发送到的邮箱，邮箱修改后令牌失效 Address it was sent to, the token is void once the address changes
+	ExpiresAt time.Time  `gorm:"not null"`                                      // 过期时间 Expiry time
+	UsedAt    *time.Time // 使用时间，空为未使用 Used time, unused when empty
+}
+
+// TableName 重写表名
+// Rewrite table name
+func (UserToken) TableName() string {
+	return "user_tokens"
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Webhook 项目的 webhook 订阅，事件发生时向 URL 投递带签名的载荷
+// Webhook subscription of a project, signed payloads are delivered to the URL when events happen
+type Webhook struct {
+	gorm.Model
+	ProjectID uint     `gorm:"not null;index"`                                                    // 项目ID Project ID
+	Project   Project  `gorm:"foreignKey:ProjectID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"` // 项目 Project
+	URL       string   `gorm:"not null"`                                                          // 投递地址 Delivery URL
+	Secret    string   `gorm:"not null;serializer:encrypted"`                                     // 签名密钥，加密保存 Signing secret, stored encrypted
+	Events    []string `gorm:"serializer:json;type:json;default:'[]'"`                            // 订阅的事件，为空时订阅全部 Subscribed events, all events when empty
+	Format    string   `gorm:"not null;default:json"`                                             // 载荷格式 Payload format
+	Active    bool     `gorm:"not null;default:true"`                                             // 是否启用 Whether enabled
+}
+
+// TableName 重写表名
+// Rewrite table name
+func (Webhook) TableName() string {
+	return "webhooks"
+}
+
+// Subscribes 是否订阅了事件
+// Whether the webhook subscribes to the event
+func (w *Webhook) Subscribes(event string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery 一次 webhook 投递及其最近一次尝试的结果
+// A webhook delivery and the outcome of its latest attempt
+type WebhookDelivery struct {
+	ID             uint       `gorm:"primaryKey"`                                                        // 投递ID Delivery ID
+	CreatedAt      time.Time  `gorm:"not null"`                                                          // 创建时间 Created time
+	WebhookID      uint       `gorm:"not null;index"`                                                    // Webhook ID
+	Webhook        Webhook    `gorm:"foreignKey:WebhookID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"` // Webhook
+	Event          string     `gorm:"size:64;not null"`                                                  // 事件 Event
+	Payload        string     `gorm:"type:text"`                                                         // 请求体 Request body
+	Status         string     `gorm:"size:16;not null;default:pending;index"`                            // 投递状态 Delivery status
+	Attempts       int        `gorm:"not null;default:0"`                                                // 已尝试次数 Attempts made
+	NextAttemptAt  time.Time  `gorm:"not null;index"`                                                    // 下次尝试时间 Next attempt time
+	ResponseStatus int        // 最近一次的响应状态码 Status code of the latest response
+	ResponseBody   string     `gorm:"type:text"` // 最近一次的响应体，已截断 Body of the latest response, truncated
+	Error          string     // 最近一次的错误 Error of the latest attempt
+	DeliveredAt    *time.Time // 投递成功时间 Time of successful delivery
+}
+
+// TableName 重写表名
+// Rewrite table name
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
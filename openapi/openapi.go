@@ -0,0 +1,387 @@
+// Package openapi 根据路由定义生成 OpenAPI 3 文档并提供 Swagger UI
+// Package openapi builds the OpenAPI 3 document from the route definitions and serves a Swagger UI
+package openapi
+
+//go:generate go run ../cmd/openapi-gen
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"html/template"
+	"mime/multipart"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/sirupsen/logrus"
+)
+
+// SpecPath 和 UIPath 文档和 Swagger UI 的路径 Paths of the document and the Swagger UI
+const (
+	SpecPath = "/api/openapi.json"
+	UIPath   = "/api/docs"
+)
+
+// operation 一个接口，由 cmd/openapi-gen 从路由定义生成
+// An endpoint, generated by cmd/openapi-gen from the route definitions
+type operation struct {
+	Method      string
+	Path        string // Hertz 形式的路径，例如 /api/v1/org/:id Hertz style path, e.g. /api/v1/org/:id
+	ID          string // 处理函数名称 Handler name
+	Summary     string // 路由注释 Route comment
+	Description string // 处理函数注释 Handler comment
+	Auth        bool   // 是否需要登录 Whether sign-in is required
+	Admin       bool   // 是否需要管理员权限 Whether admin rights are required
+	Query       []string
+	Request     reflect.Type
+}
+
+//go:embed swagger.html
+var swaggerHTML string
+
+var swaggerTemplate = template.Must(template.New("swagger").Parse(swaggerHTML))
+
+var (
+	specOnce sync.Once
+	specJSON []byte
+)
+
+// pathParam 匹配 :name 和 *name 形式的路径参数 Match path parameters of the forms :name and *name
+var pathParam = regexp.MustCompile(`[:*][A-Za-z_][A-Za-z0-9_]*`)
+
+// Document 构建 OpenAPI 3 文档
+// Build the OpenAPI 3 document
+func Document() map[string]any {
+	schemas := map[string]any{
+		"Response": map[string]any{
+			"type":                 "object",
+			"description":          "所有接口的响应都包含 message，其余字段因接口而异 Every response contains message, the other fields depend on the endpoint",
+			"properties":           map[string]any{"message": map[string]any{"type": "string"}},
+			"required":             []string{"message"},
+			"additionalProperties": true,
+		},
+		"Error": map[string]any{
+			"type":        "object",
+			"description": "4xx 和 5xx 响应的统一格式，客户端应根据 code 判断错误，message 随 Accept-Language 变化 Uniform body of 4xx and 5xx responses, clients should tell errors apart by code as message follows Accept-Language",
+			"properties": map[string]any{
+				"code":       map[string]any{"type": "string", "example": "not_found"},
+				"message":    map[string]any{"type": "string"},
+				"details":    map[string]any{"type": "object", "additionalProperties": true},
+				"request_id": map[string]any{"type": "string"},
+			},
+			"required":             []string{"code", "message"},
+			"additionalProperties": true,
+		},
+	}
+	paths := map[string]map[string]any{}
+	ids := map[string]int{}
+	for _, op := range operations {
+		path, params := convertPath(op.Path)
+		// 同一处理函数注册在多个路径时 operationId 加上序号 Number the operationId when a handler is registered on several paths
+		id := op.ID
+		if ids[op.ID]++; ids[op.ID] > 1 {
+			id += strconv.Itoa(ids[op.ID])
+		}
+		for _, name := range op.Query {
+			params = append(params, map[string]any{"name": name, "in": "query", "schema": map[string]any{"type": queryType(name)}})
+		}
+		item := map[string]any{
+			"operationId": id,
+			"summary":     op.Summary,
+			"tags":        []string{tag(op.Path)},
+			"responses": map[string]any{
+				"200":     response("OK", "Response"),
+				"default": response("Error", "Error"),
+			},
+		}
+		if op.Description != "" {
+			item["description"] = op.Description
+		}
+		if op.Admin {
+			item["description"] = strings.TrimSpace(op.Description + "\n\n需要管理员权限 Requires admin rights")
+		}
+		if op.Auth {
+			item["security"] = []map[string][]string{{"bearer": {}}, {"cookie": {}}}
+		} else {
+			item["security"] = []map[string][]string{}
+		}
+		if op.Request != nil {
+			// 带 query 或 form 标签且没有上传文件的请求结构体从查询参数读取 Request structs with query or form tags and no uploaded file are read from the query
+			if query := queryParams(op.Request, schemas, params); len(query) > 0 && !hasFile(op.Request) {
+				params = append(params, query...)
+			} else if op.Method != "GET" {
+				mediaType := "application/json"
+				if hasFile(op.Request) {
+					mediaType = "multipart/form-data"
+				}
+				item["requestBody"] = map[string]any{
+					"required": true,
+					"content":  map[string]any{mediaType: map[string]any{"schema": schemaOf(op.Request, schemas)}},
+				}
+			}
+		}
+		if len(params) > 0 {
+			item["parameters"] = params
+		}
+		if paths[path] == nil {
+			paths[path] = map[string]any{}
+		}
+		paths[path][strings.ToLower(op.Method)] = item
+	}
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":       "Spage API",
+			"description": "Spage 静态站点托管平台的接口 API of the Spage static site hosting platform",
+			"version":     "v1",
+		},
+		"servers": []map[string]any{{"url": strings.TrimSuffix(config.FrontEndURL, "/")}},
+		"paths":   paths,
+		"components": map[string]any{
+			"schemas": schemas,
+			"securitySchemes": map[string]any{
+				"bearer": map[string]any{"type": "http", "scheme": "bearer", "description": "会话令牌或个人访问令牌 Session token or personal access token"},
+				"cookie": map[string]any{"type": "apiKey", "in": "cookie", "name": "token"},
+			},
+		},
+	}
+}
+
+// convertPath 将 Hertz 路径参数转换为 OpenAPI 形式 Convert Hertz path parameters to the OpenAPI form
+func convertPath(path string) (string, []map[string]any) {
+	var params []map[string]any
+	converted := pathParam.ReplaceAllStringFunc(path, func(param string) string {
+		name := param[1:]
+		schema := map[string]any{"type": "string"}
+		if name == "id" || strings.HasSuffix(name, "_id") {
+			schema["type"] = "integer"
+		}
+		params = append(params, map[string]any{"name": name, "in": "path", "required": true, "schema": schema})
+		return "{" + name + "}"
+	})
+	return converted, params
+}
+
+// tag 使用 /api/v1 之后的第一段路径作为分组 Use the first path segment after /api/v1 as the tag
+func tag(path string) string {
+	rest := strings.TrimPrefix(strings.TrimPrefix(path, "/api/v1"), "/")
+	first, _, _ := strings.Cut(rest, "/")
+	return first
+}
+
+// queryType 常见查询参数的类型 Types of common query parameters
+func queryType(name string) string {
+	switch name {
+	case "page", "limit", "days":
+		return "integer"
+	}
+	return "string"
+}
+
+func response(description, schema string) map[string]any {
+	return map[string]any{
+		"description": description,
+		"content":     map[string]any{"application/json": map[string]any{"schema": map[string]any{"$ref": "#/components/schemas/" + schema}}},
+	}
+}
+
+// queryParams 将 GET 请求结构体中带 query 或 form 标签的字段转换为查询参数，跳过已声明的参数
+// Convert fields with query or form tags of a GET request struct to query parameters, skipping declared ones
+func queryParams(t reflect.Type, schemas map[string]any, declared []map[string]any) (params []map[string]any) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := tagName(field, "query", "form")
+		if name == "" || slices.ContainsFunc(declared, func(p map[string]any) bool { return p["name"] == name }) {
+			continue
+		}
+		param := map[string]any{"name": name, "in": "query", "schema": schemaOf(field.Type, schemas)}
+		if doc := fieldDocs[docKey(t, field)]; doc != "" {
+			param["description"] = doc
+		}
+		params = append(params, param)
+	}
+	return params
+}
+
+// tagName 返回第一个存在的标签的名称 Return the name of the first present tag
+func tagName(field reflect.StructField, tags ...string) string {
+	for _, key := range tags {
+		if value, ok := field.Tag.Lookup(key); ok {
+			name, _, _ := strings.Cut(value, ",")
+			if name == "-" {
+				return ""
+			}
+			return name
+		}
+	}
+	return ""
+}
+
+func docKey(t reflect.Type, field reflect.StructField) string {
+	pkg := t.PkgPath()
+	return pkg[strings.LastIndex(pkg, "/")+1:] + "." + t.Name() + "." + field.Name
+}
+
+var (
+	timeType   = reflect.TypeOf(time.Time{})
+	fileType   = reflect.TypeOf(multipart.FileHeader{})
+	rawType    = reflect.TypeOf(json.RawMessage{})
+	modulePath = "github.com/LiteyukiStudio/spage/"
+)
+
+// hasFile 请求结构体是否包含上传文件 Whether the request struct contains an uploaded file
+func hasFile(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	for i := 0; i < t.NumField(); i++ {
+		ft := t.Field(i).Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if ft == fileType {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaOf 通过反射生成类型的 JSON Schema，本项目的结构体登记为组件并以 $ref 引用
+// Build the JSON schema of a type through reflection, structs of this module are registered as components and referenced with $ref
+func schemaOf(t reflect.Type, schemas map[string]any) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t {
+	case timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case fileType:
+		return map[string]any{"type": "string", "format": "binary"}
+	case rawType:
+		return map[string]any{}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "format": "byte"}
+		}
+		return map[string]any{"type": "array", "items": schemaOf(t.Elem(), schemas)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaOf(t.Elem(), schemas)}
+	case reflect.Struct:
+		if !strings.HasPrefix(t.PkgPath(), modulePath) || t.Name() == "" {
+			return structSchema(t, schemas)
+		}
+		if _, ok := schemas[t.Name()]; !ok {
+			// 先占位，避免自引用的类型无限递归 Reserve the name first so self-referencing types do not recurse forever
+			schemas[t.Name()] = map[string]any{}
+			schemas[t.Name()] = structSchema(t, schemas)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + t.Name()}
+	}
+	return map[string]any{}
+}
+
+// structSchema 按 json 标签生成结构体的属性，内嵌结构体的字段展开到外层
+// Build the properties of a struct by its json tags, fields of embedded structs are inlined
+func structSchema(t reflect.Type, schemas map[string]any) map[string]any {
+	properties := map[string]any{}
+	var required []string
+	var walk func(t reflect.Type)
+	walk = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name := tagName(field, "json")
+			if field.Anonymous && name == "" {
+				ft := field.Type
+				for ft.Kind() == reflect.Pointer {
+					ft = ft.Elem()
+				}
+				if ft.Kind() == reflect.Struct {
+					walk(ft)
+					continue
+				}
+			}
+			if _, tagged := field.Tag.Lookup("json"); tagged && name == "" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			schema := schemaOf(field.Type, schemas)
+			if doc := fieldDocs[docKey(t, field)]; doc != "" {
+				if _, isRef := schema["$ref"]; isRef {
+					// OpenAPI 3.0 中 $ref 的同级字段会被忽略 Siblings of $ref are ignored in OpenAPI 3.0
+					schema = map[string]any{"allOf": []any{schema}, "description": doc}
+				} else {
+					schema["description"] = doc
+				}
+			}
+			properties[name] = schema
+			if strings.Contains(field.Tag.Get("binding"), "required") || strings.Contains(field.Tag.Get("vd"), "required") ||
+				slices.Contains(strings.Split(field.Tag.Get("validate"), ","), "required") {
+				required = append(required, name)
+			}
+		}
+	}
+	walk(t)
+	schema := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// ServeSpec 返回 OpenAPI 文档，文档只在首次请求时生成
+// Respond with the OpenAPI document, which is built on the first request only
+func ServeSpec(ctx context.Context, c *app.RequestContext) {
+	specOnce.Do(func() {
+		data, err := json.Marshal(Document())
+		if err != nil {
+			logrus.Errorf("Failed to encode the OpenAPI document: %v", err)
+			return
+		}
+		specJSON = data
+	})
+	if specJSON == nil {
+		c.AbortWithStatus(500)
+		return
+	}
+	c.Data(200, "application/json; charset=utf-8", specJSON)
+}
+
+// ServeUI 返回加载文档的 Swagger UI 页面
+// Respond with the Swagger UI page loading the document
+func ServeUI(ctx context.Context, c *app.RequestContext) {
+	var buf strings.Builder
+	if err := swaggerTemplate.Execute(&buf, map[string]string{
+		"Assets": strings.TrimSuffix(config.OpenAPIUIAssets, "/"),
+		"Spec":   SpecPath,
+	}); err != nil {
+		c.AbortWithStatus(500)
+		return
+	}
+	c.Data(200, "text/html; charset=utf-8", []byte(buf.String()))
+}
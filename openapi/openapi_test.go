@@ -0,0 +1,48 @@
+package openapi
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+)
+
+// TestDocument 测试文档可以编码，operationId 唯一且路径参数都已声明
+// Test that the document encodes, operationIds are unique and every path parameter is declared
+func TestDocument(t *testing.T) {
+	doc := Document()
+	if _, err := json.Marshal(doc); err != nil {
+		t.Fatal(err)
+	}
+	placeholder := regexp.MustCompile(`\{([^}]+)\}`)
+	ids := map[string]bool{}
+	for path, item := range doc["paths"].(map[string]map[string]any) {
+		for method, raw := range item {
+			op := raw.(map[string]any)
+			id := op["operationId"].(string)
+			if ids[id] {
+				t.Errorf("Duplicate operationId %s on %s %s", id, method, path)
+			}
+			ids[id] = true
+			declared := map[string]bool{}
+			params, _ := op["parameters"].([]map[string]any)
+			for _, param := range params {
+				if param["in"] == "path" {
+					declared[param["name"].(string)] = true
+				}
+			}
+			for _, match := range placeholder.FindAllStringSubmatch(path, -1) {
+				if !declared[match[1]] {
+					t.Errorf("Path parameter %s of %s %s is not declared", match[1], method, path)
+				}
+			}
+		}
+	}
+	schemas := doc["components"].(map[string]any)["schemas"].(map[string]any)
+	login, ok := schemas["LoginReq"].(map[string]any)
+	if !ok {
+		t.Fatal("Expected LoginReq to be registered as a component")
+	}
+	if _, ok := login["properties"].(map[string]any)["username"]; !ok {
+		t.Error("Expected LoginReq to have a username property")
+	}
+}
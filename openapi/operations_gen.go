@@ -0,0 +1,1497 @@
+// Code generated by cmd/openapi-gen from router/router.go and handlers; DO NOT EDIT.
+
+package openapi
+
+import (
+	"reflect"
+
+	"github.com/LiteyukiStudio/spage/handlers"
+)
+
+var operations = []operation{
+	{Method: "GET", Path: "/api/v1/admin/audit-logs", ID: "Audit.List", Summary: "查询审计日志 Query audit logs", Description: "分页查询审计日志\nQuery audit logs with pagination", Auth: true, Admin: true, Query: []string{"page", "limit", "sort"}, Request: reflect.TypeOf(handlers.AuditListReq{})},
+	{Method: "GET", Path: "/api/v1/admin/audit-logs/verify", ID: "Audit.Verify", Summary: "校验审计日志哈希链 Verify the audit log hash chain", Description: "校验审计日志的哈希链\nVerify the hash chain of the audit log", Auth: true, Admin: true},
+	{Method: "GET", Path: "/api/v1/admin/backup", ID: "Admin.Backup", Summary: "下载实例备份 Download instance backup", Description: "以流的形式下载实例备份\nDownload an instance backup as a stream", Auth: true, Admin: true},
+	{Method: "POST", Path: "/api/v1/admin/bulk/projects/delete", ID: "Admin.BulkDeleteProjects", Summary: "批量删除长期未部署的项目 Delete projects not deployed for a long time in bulk", Description: "在后台任务中将创建超过 inactive_days 天且期间没有部署的项目移入回收站，可按所有者筛选\nMove the projects older than inactive_days days without any deployment in that time to the trash in a background job, optionally filtered by owner", Auth: true, Admin: true, Request: reflect.TypeOf(handlers.BulkDeleteProjectsReq{})},
+	{Method: "POST", Path: "/api/v1/admin/bulk/projects/reassign", ID: "Admin.BulkReassignProjects", Summary: "批量更换项目的所有者 Reassign the owner of projects in bulk", Description: "在后台任务中将一个所有者的项目直接交给另一个用户或组织，不需要接收方确认\nHand the projects of one owner directly to another user or organization in a background job, without the recipient confirming", Auth: true, Admin: true, Request: reflect.TypeOf(handlers.BulkReassignProjectsReq{})},
+	{Method: "POST", Path: "/api/v1/admin/bulk/users/disable", ID: "Admin.BulkDisableUsers", Summary: "批量停用用户 Disable users in bulk", Description: "在后台任务中批量停用用户并撤销其登录会话，系统管理员和自己会被跳过\nDisable users in bulk in a background job and revoke their sign-in sessions, the system administrator and yourself are skipped", Auth: true, Admin: true, Request: reflect.TypeOf(handlers.BulkDisableUsersReq{})},
+	{Method: "GET", Path: "/api/v1/admin/content-policy/:org_id", ID: "ContentPolicy.AdminGet", Summary: "获取组织的内容策略 Get an organization content policy", Description: "管理员获取组织生效的内容策略\nAdmin gets the effective content policy of an organization", Auth: true, Admin: true},
+	{Method: "PUT", Path: "/api/v1/admin/content-policy/:org_id", ID: "ContentPolicy.AdminUpdate", Summary: "设置组织的内容策略 Set an organization content policy", Description: "管理员为组织设置内容策略，覆盖默认值，已部署的内容只在开启访问时拒绝后受影响\nAdmin sets the content policy of an organization, overriding the defaults; deployed content is only affected once requests are refused too", Auth: true, Admin: true, Request: reflect.TypeOf(handlers.ContentPolicyReq{})},
+	{Method: "DELETE", Path: "/api/v1/admin/content-policy/:org_id", ID: "ContentPolicy.AdminDelete", Summary: "恢复默认内容策略 Restore the default content policy", Description: "管理员删除组织的内容策略，恢复为默认策略\nAdmin removes the content policy of an organization, restoring the defaults", Auth: true, Admin: true},
+	{Method: "GET", Path: "/api/v1/admin/cron", ID: "Cron.List", Summary: "获取定时任务的运行状态 List scheduled tasks and their runs", Description: "获取所有定时任务的表达式、上次和下次运行时间\nList every scheduled task with its expression and last and next runs", Auth: true, Admin: true},
+	{Method: "POST", Path: "/api/v1/admin/cron/:name/run", ID: "Cron.Run", Summary: "手动运行定时任务 Run a scheduled task now", Description: "立即在后台运行一次定时任务，不影响下次运行时间；任务正在运行时返回冲突\nRun a scheduled task once in the background right away without moving its next run; a conflict while it is running", Auth: true, Admin: true},
+	{Method: "POST", Path: "/api/v1/admin/database/checkpoint", ID: "Admin.Checkpoint", Summary: "执行 SQLite WAL 检查点 Run a SQLite WAL checkpoint", Description: "将 SQLite 的 WAL 写回数据库文件，返回 WAL 和已写回的页数；数据库不是 SQLite 时返回参数错误\nWrite the SQLite WAL back into the database file, responding with the pages in the WAL and written back; a bad request when the database is not SQLite", Auth: true, Admin: true, Request: reflect.TypeOf(handlers.CheckpointReq{})},
+	{Method: "POST", Path: "/api/v1/admin/gc", ID: "Job.CollectGarbage", Summary: "运行存储垃圾回收 Run the storage garbage collection", Description: "管理员立即运行存储垃圾回收，返回任务，回收的字节数见任务结果\nAdmin runs a storage garbage collection right away, responding with the job whose result reports the reclaimed bytes", Auth: true, Admin: true, Request: reflect.TypeOf(handlers.GCReq{})},
+	{Method: "GET", Path: "/api/v1/admin/invitations", ID: "Invitation.AdminList", Summary: "获取所有邀请 List all invitations", Description: "获取实例的所有邀请\nList all invitations of the instance", Auth: true, Admin: true},
+	{Method: "POST", Path: "/api/v1/admin/invitations", ID: "Invitation.AdminCreate", Summary: "创建注册邀请 Create an invitation", Description: "创建注册邀请，可指定加入的组织\nCreate an invitation to sign up, optionally joining an organization", Auth: true, Admin: true, Request: reflect.TypeOf(handlers.CreateInvitationReq{})},
+	{Method: "DELETE", Path: "/api/v1/admin/invitations/:invitation_id", ID: "Invitation.AdminRevoke", Summary: "撤销邀请 Revoke an invitation", Description: "撤销任意邀请\nRevoke any invitation", Auth: true, Admin: true},
+	{Method: "GET", Path: "/api/v1/admin/jobs", ID: "Job.List", Summary: "查询后台任务 Query background jobs", Description: "管理员分页获取后台任务，可按状态和类型过滤\nAdmin lists background jobs with pagination, optionally filtered by status and type", Auth: true, Admin: true, Query: []string{"status", "page", "limit", "type"}},
+	{Method: "GET", Path: "/api/v1/admin/jobs/:job_id", ID: "Job.Get", Summary: "获取后台任务 Get a background job", Description: "管理员获取后台任务及其参数\nAdmin gets a background job with its parameters", Auth: true, Admin: true},
+	{Method: "POST", Path: "/api/v1/admin/jobs/:job_id/cancel", ID: "Job.Cancel", Summary: "取消后台任务 Cancel a background job", Description: "管理员取消尚未开始执行的任务，运行中的任务不能取消\nAdmin cancels a job that has not started running, running jobs cannot be canceled", Auth: true, Admin: true},
+	{Method: "POST", Path: "/api/v1/admin/jobs/:job_id/retry", ID: "Job.Retry", Summary: "重试后台任务 Retry a background job", Description: "管理员重新执行失败或已取消的任务，尝试次数从零开始\nAdmin runs a failed or canceled job again, starting over with zero attempts", Auth: true, Admin: true},
+	{Method: "GET", Path: "/api/v1/admin/oidc", ID: "OIDC.AdminList", Summary: "获取登录提供方 List auth providers", Description: "管理员获取所有登录提供方\nAdmin lists all auth providers", Auth: true, Admin: true},
+	{Method: "POST", Path: "/api/v1/admin/oidc", ID: "OIDC.AdminCreate", Summary: "创建登录提供方 Create auth provider", Description: "管理员创建登录提供方\nAdmin creates an auth provider", Auth: true, Admin: true, Request: reflect.TypeOf(handlers.AuthProviderReq{})},
+	{Method: "PUT", Path: "/api/v1/admin/oidc/:provider_id", ID: "OIDC.AdminUpdate", Summary: "更新登录提供方 Update auth provider", Description: "管理员更新登录提供方\nAdmin updates an auth provider", Auth: true, Admin: true, Request: reflect.TypeOf(handlers.AuthProviderReq{})},
+	{Method: "DELETE", Path: "/api/v1/admin/oidc/:provider_id", ID: "OIDC.AdminDelete", Summary: "删除登录提供方 Delete auth provider", Description: "管理员删除登录提供方，关联的身份一并删除\nAdmin deletes an auth provider together with its linked identities", Auth: true, Admin: true},
+	{Method: "GET", Path: "/api/v1/admin/orgs/:name", ID: "Admin.GetOrgByName", Summary: "按名称获取组织 Get an organization by name", Description: "按名称获取组织，响应带有 ETag\nGet an organization by name, the response carries an ETag", Auth: true, Admin: true},
+	{Method: "PUT", Path: "/api/v1/admin/orgs/:name", ID: "Admin.PutOrg", Summary: "按名称创建或更新组织 Create or update an organization by name", Description: "按名称创建或更新组织，owners 中的用户成为所有者，创建时第一个用户为创建者\nCreate or update an organization by name, users of owners become owners and the first one is the creator on creation", Auth: true, Admin: true, Request: reflect.TypeOf(handlers.AdminOrgReq{})},
+	{Method: "PUT", Path: "/api/v1/admin/project/:id/upload-limit", ID: "Quota.AdminProjectUpload", Summary: "设置项目的上传大小 Set the upload size of a project", Description: "管理员设置项目的单次上传最大字节数，覆盖所有者的配额，为空时恢复为所有者的配额\nAdmin sets the largest single upload of a project, overriding the owner's quota, empty restores the owner's quota", Auth: true, Admin: true, Request: reflect.TypeOf(handlers.ProjectUploadLimitReq{})},
+	{Method: "GET", Path: "/api/v1/admin/projects/:name", ID: "Admin.GetProjectByName", Summary: "按名称获取项目 Get a project by name", Description: "按名称获取项目，响应带有 ETag\nGet a project by name, the response carries an ETag", Auth: true, Admin: true},
+	{Method: "PUT", Path: "/api/v1/admin/projects/:name", ID: "Admin.PutProject", Summary: "按名称创建或更新项目 Create or update a project by name", Description: "按名称创建或更新项目，创建时检查所有者的配额；已有项目的所有者不同时返回 409，须通过项目转移修改\nCreate or update a project by name, checking the owner's quota on creation; a different owner of an existing project returns 409 as it changes through a project transfer", Auth: true, Admin: true, Request: reflect.TypeOf(handlers.AdminProjectReq{})},
+	{Method: "GET", Path: "/api/v1/admin/quarantine", ID: "Scan.List", Summary: "获取等待审核的隔离版本 List quarantined versions waiting for review", Description: "管理员分页获取所有等待审核的隔离版本\nAdmin lists all quarantined versions waiting for review with pagination", Auth: true, Admin: true, Query: []string{"page", "limit"}},
+	{Method: "POST", Path: "/api/v1/admin/quarantine/:release_id/approve", ID: "Scan.Approve", Summary: "放行隔离的版本 Approve a quarantined version", Description: "管理员放行隔离的版本：定时上线时间未到时恢复定时，部署时要求上线或定时上线时间已过时立即激活，其余恢复为普通版本；\n受保护项目中本应上线或定时的版本转为等待维护者批准\nAdmin releases a quarantined version: it is scheduled again while its go-live time is ahead, activated right away when the deployment asked for it or the go-live time has passed, and becomes a plain version otherwise;\nin protected projects a version that would go live or be scheduled waits for a maintainer's approval instead", Auth: true, Admin: true},
+	{Method: "POST", Path: "/api/v1/admin/quarantine/:release_id/reject", ID: "Scan.Reject", Summary: "拒绝隔离的版本 Reject a quarantined version", Description: "管理员拒绝隔离的版本，版本标记为失败且不再提供服务，项目的 webhook 收到部署失败通知\nAdmin rejects a quarantined version, which is marked failed and never served, the project's webhooks are told the deployment failed", Auth: true, Admin: true, Request: reflect.TypeOf(handlers.QuarantineRejectReq{})},
+	{Method: "GET", Path: "/api/v1/admin/quota/:owner_type/:owner_id", ID: "Quota.AdminGet", Summary: "获取用户或组织的配额 Get a user or organization quota", Description: "管理员获取用户或组织的配额和用量\nAdmin gets the quota and usage of a user or organization", Auth: true, Admin: true},
+	{Method: "PUT", Path: "/api/v1/admin/quota/:owner_type/:owner_id", ID: "Quota.AdminUpdate", Summary: "设置用户或组织的配额 Set a user or organization quota", Description: "管理员为用户或组织设置配额，覆盖默认值，已超出新配额的内容不受影响\nAdmin sets the quota of a user or organization, overriding the defaults; content already beyond the new quota is left alone", Auth: true, Admin: true, Request: reflect.TypeOf(handlers.QuotaReq{})},
+	{Method: "DELETE", Path: "/api/v1/admin/quota/:owner_type/:owner_id", ID: "Quota.AdminDelete", Summary: "恢复默认配额 Restore the default quota", Description: "管理员删除用户或组织的配额，恢复为默认配额\nAdmin removes the quota of a user or organization, restoring the defaults", Auth: true, Admin: true},
+	{Method: "GET", Path: "/api/v1/admin/runners", ID: "Runner.AdminList", Summary: "获取远程构建执行器 List remote build runners", Description: "获取所有执行器及其是否在线\nList all runners and whether they are online", Auth: true, Admin: true},
+	{Method: "POST", Path: "/api/v1/admin/runners", ID: "Runner.AdminCreate", Summary: "创建远程构建执行器 Create a remote build runner", Description: "创建执行器，令牌明文只在此时返回\nCreate a runner, the plain token is only returned here", Auth: true, Admin: true, Request: reflect.TypeOf(handlers.CreateRunnerReq{})},
+	{Method: "DELETE", Path: "/api/v1/admin/runners/:runner_id", ID: "Runner.AdminDelete", Summary: "删除远程构建执行器 Delete a remote build runner", Description: "删除执行器，令牌立即失效，其正在执行的构建在租约过期后视为失败\nDelete a runner, its token stops working at once and its running builds fail once their lease expires", Auth: true, Admin: true},
+	{Method: "GET", Path: "/api/v1/admin/search", ID: "Search.Admin", Summary: "检索整个实例 Search the whole instance", Description: "管理员检索整个实例\nAdmin searches the whole instance", Auth: true, Admin: true, Query: []string{"q", "type", "page", "limit"}},
+	{Method: "GET", Path: "/api/v1/admin/settings", ID: "Setting.List", Summary: "获取动态配置项 List dynamic settings", Description: "获取所有动态配置项\nList all dynamic settings", Auth: true, Admin: true},
+	{Method: "PUT", Path: "/api/v1/admin/settings/:key", ID: "Setting.Update", Summary: "修改动态配置项 Change a dynamic setting", Description: "修改动态配置项，无需重启即可生效\nChange a dynamic setting, taking effect without a restart", Auth: true, Admin: true, Request: reflect.TypeOf(handlers.SettingReq{})},
+	{Method: "DELETE", Path: "/api/v1/admin/settings/:key", ID: "Setting.Delete", Summary: "恢复配置文件中的值 Restore the configured value", Description: "删除管理员对动态配置项的覆盖，恢复为配置文件中的值\nRemove the override of a dynamic setting, restoring the value of the configuration file", Auth: true, Admin: true},
+	{Method: "PUT", Path: "/api/v1/admin/site/:id/transfer-limit", ID: "Bandwidth.AdminSiteLimit", Summary: "设置站点的每月流量限制 Set the monthly transfer limits of a site", Description: "管理员设置站点每月流量的软限制和硬限制，为空时恢复为实例配置，0 为不限制\nAdmin sets the soft and hard monthly transfer limits of a site, empty restores the instance configuration and 0 means unlimited", Auth: true, Admin: true, Request: reflect.TypeOf(handlers.SiteTransferLimitReq{})},
+	{Method: "GET", Path: "/api/v1/admin/stats", ID: "Stats.Get", Summary: "获取实例统计 Get instance statistics", Description: "获取最近 days 天的实例统计，默认 30 天；数据库无法连接时只返回数据库的健康状况\nGet the instance statistics of the last days days, 30 by default; only the database health is returned when the database is unreachable", Auth: true, Admin: true, Query: []string{"days"}},
+	{Method: "GET", Path: "/api/v1/admin/tenants", ID: "Tenant.AdminList", Summary: "获取所有租户 List all tenants", Description: "管理员获取所有租户\nAdmin lists all tenants", Auth: true, Admin: true},
+	{Method: "POST", Path: "/api/v1/admin/tenants", ID: "Tenant.AdminCreate", Summary: "创建租户 Create a tenant", Description: "管理员创建租户\nAdmin creates a tenant", Auth: true, Admin: true, Request: reflect.TypeOf(handlers.TenantReq{})},
+	{Method: "PUT", Path: "/api/v1/admin/tenants/:tenant_id", ID: "Tenant.AdminUpdate", Summary: "修改租户 Change a tenant", Description: "管理员修改租户，修改基础域名后租户的站点只在新的基础域名下访问\nAdmin changes a tenant, after a base domain change the tenant's sites are only served under the new base domain", Auth: true, Admin: true, Request: reflect.TypeOf(handlers.TenantReq{})},
+	{Method: "DELETE", Path: "/api/v1/admin/tenants/:tenant_id", ID: "Tenant.AdminDelete", Summary: "删除租户 Delete a tenant", Description: "管理员删除租户，租户中仍有用户或组织时拒绝\nAdmin deletes a tenant, refused while users or organizations remain in it", Auth: true, Admin: true},
+	{Method: "PUT", Path: "/api/v1/admin/tenants/:tenant_id/:owner_type/:owner_id", ID: "Tenant.AdminAssign", Summary: "将用户或组织移入租户 Move a user or organization into a tenant", Description: "管理员将用户或组织连同其拥有的项目移入租户，已有的组织成员和项目成员不受影响\nAdmin moves a user or organization together with the projects it owns into a tenant; existing organization and project members are left alone", Auth: true, Admin: true},
+	{Method: "DELETE", Path: "/api/v1/admin/tenants/:tenant_id/:owner_type/:owner_id", ID: "Tenant.AdminUnassign", Summary: "将用户或组织移出租户 Move a user or organization out of a tenant", Description: "管理员将用户或组织连同其拥有的项目移回实例本身\nAdmin moves a user or organization together with the projects it owns back to the instance itself", Auth: true, Admin: true},
+	{Method: "GET", Path: "/api/v1/admin/user", ID: "Admin.ListUsers", Summary: "查询用户 Query users", Description: "分页查询用户，q 按用户名、显示名称和邮箱筛选，role 按全局角色筛选\nQuery users with pagination, q filters by name, display name and email and role by global role", Auth: true, Admin: true, Query: []string{"page", "limit", "sort", "q", "role"}},
+	{Method: "POST", Path: "/api/v1/admin/user", ID: "Admin.CreateUser", Summary: "创建用户 Create user", Description: "创建用户\nCreate User", Auth: true, Admin: true, Request: reflect.TypeOf(handlers.UserDTO{})},
+	{Method: "POST", Path: "/api/v1/admin/user/:id/impersonate", ID: "Admin.Impersonate", Summary: "代为登录用户 Impersonate a user", Description: "代为登录用户以排查用户反馈的问题，创建有效期短、权限受限的会话并记入审计日志，令牌只在响应中返回，不替换管理员自己的 cookie；\n用户可在会话列表中看到并撤销该会话，以该会话的访问令牌登出即结束代为登录\nImpersonate a user to debug the problems they report, creating a short-lived session with limited scopes that is recorded in the audit log,\nthe tokens are only returned in the response and do not replace the administrator's own cookies;\nthe user sees the session in their session list and can revoke it, logging out with its access token ends the impersonation", Auth: true, Admin: true, Request: reflect.TypeOf(handlers.ImpersonateReq{})},
+	{Method: "DELETE", Path: "/api/v1/admin/user/:id/lockout", ID: "Admin.Unlock", Summary: "解除登录失败锁定 Lift the failed login lockout", Description: "解除账号因连续登录失败而被临时锁定的状态并清零失败次数\nLift the temporary lock of an account after too many failed logins and clear its failures", Auth: true, Admin: true},
+	{Method: "GET", Path: "/api/v1/admin/users/:name", ID: "Admin.GetUserByName", Summary: "按名称获取用户 Get a user by name", Description: "按名称获取用户，响应带有 ETag\nGet a user by name, the response carries an ETag", Auth: true, Admin: true},
+	{Method: "PUT", Path: "/api/v1/admin/users/:name", ID: "Admin.PutUser", Summary: "按名称创建或更新用户 Create or update a user by name", Description: "按名称创建或更新用户，密码只在提交时设置；不能修改系统管理员和自己的角色\nCreate or update a user by name, the password is only set when submitted; the role of the system administrator and of oneself cannot change", Auth: true, Admin: true, Request: reflect.TypeOf(handlers.AdminUserReq{})},
+	{Method: "GET", Path: "/api/v1/avatars/:owner_type/:owner_id/:file", ID: "Avatar.Get", Summary: "获取上传的头像 Get an uploaded avatar", Description: "获取上传的头像，size 参数选择不小于它的最小标准尺寸；地址包含内容哈希，响应可被长期缓存\nGet an uploaded avatar, the size parameter picks the smallest standard size not below it; the URL contains the content hash so responses are cached for long", Auth: false, Admin: false, Query: []string{"size"}},
+	{Method: "GET", Path: "/api/v1/badge/:project/deploy-status.svg", ID: "Badge.DeployStatus", Summary: "获取部署状态徽章 Get the deployment status badge", Description: "获取公开项目最近一次部署状态的 SVG 徽章，可通过 site 参数指定站点，用于嵌入 README\nGet an SVG badge of the latest deployment status of a public project, the site parameter picks a site, meant to be embedded in READMEs", Auth: false, Admin: false, Query: []string{"site"}},
+	{Method: "GET", Path: "/api/v1/feed/org/:name/deployments.atom", ID: "Feed.Org", Summary: "获取组织的部署订阅源 Get the deployment feed of an organization", Description: "获取组织全部公开项目的部署订阅源\nGet the deployment feed of every public project of an organization", Auth: false, Admin: false},
+	{Method: "GET", Path: "/api/v1/feed/org/:name/deployments.rss", ID: "Feed.Org", Summary: "获取组织的部署订阅源 Get the deployment feed of an organization", Description: "获取组织全部公开项目的部署订阅源\nGet the deployment feed of every public project of an organization", Auth: false, Admin: false},
+	{Method: "GET", Path: "/api/v1/feed/project/:name/deployments.atom", ID: "Feed.Project", Summary: "获取项目的部署订阅源 Get the deployment feed of a project", Description: "获取公开项目的部署订阅源\nGet the deployment feed of a public project", Auth: false, Admin: false},
+	{Method: "GET", Path: "/api/v1/feed/project/:name/deployments.rss", ID: "Feed.Project", Summary: "获取项目的部署订阅源 Get the deployment feed of a project", Description: "获取公开项目的部署订阅源\nGet the deployment feed of a public project", Auth: false, Admin: false},
+	{Method: "GET", Path: "/api/v1/graphql", ID: "GraphQL.Schema", Summary: "获取 GraphQL 模式定义 Get the GraphQL schema definition", Description: "以 GraphQL 模式定义语言获取接口的类型\nGet the types of the API in the GraphQL schema definition language", Auth: true, Admin: false},
+	{Method: "POST", Path: "/api/v1/graphql", ID: "GraphQL.Query", Summary: "执行 GraphQL 查询 Run a GraphQL query", Description: "执行 GraphQL 查询，结果按 GraphQL 规范返回 data 和 errors，不使用 REST 的响应结构\nExecute a GraphQL query, answered with data and errors as the GraphQL spec describes instead of the REST response structure", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.GraphQLReq{})},
+	{Method: "POST", Path: "/api/v1/hooks/git/:id", ID: "Git.Receive", Summary: "接收仓库推送事件 Receive repository push events", Description: "接收 GitHub 或 GitLab 的推送事件，校验签名后在后台部署推送的提交\nReceive push events from GitHub or GitLab, deploying the pushed commit in the background once the signature checks out", Auth: false, Admin: false},
+	{Method: "GET", Path: "/api/v1/invitation/:token", ID: "Invitation.Get", Summary: "查看邀请 Look up an invitation", Description: "根据链接中的令牌查看邀请，供注册页面展示\nLook up an invitation by the token of its link, shown by the sign-up page", Auth: false, Admin: false},
+	{Method: "POST", Path: "/api/v1/org", ID: "Org.CreateOrganization", Summary: "创建组织 Create organization", Description: "创建组织\nCreate Organization", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.CreateOrgReq{})},
+	{Method: "GET", Path: "/api/v1/org/:id", ID: "Org.GetOrganization", Summary: "获取组织信息 Get organization info", Description: "获取组织信息\nGet Organization Information", Auth: true, Admin: false},
+	{Method: "PUT", Path: "/api/v1/org/:id", ID: "Org.UpdateOrganization", Summary: "更新组织 Update organization", Description: "更新组织信息\nUpdate Organization Information", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.UpdateOrgReq{})},
+	{Method: "DELETE", Path: "/api/v1/org/:id", ID: "Org.DeleteOrganization", Summary: "删除组织 Delete organization", Description: "删除组织\nDelete Organization", Auth: true, Admin: false},
+	{Method: "PUT", Path: "/api/v1/org/:id/avatar", ID: "Avatar.UploadOrg", Summary: "上传组织标志 Upload organization logo", Description: "上传组织的标志，显示在控制台中\nUpload the logo of the organization shown in the dashboard", Auth: true, Admin: false},
+	{Method: "DELETE", Path: "/api/v1/org/:id/avatar", ID: "Avatar.DeleteOrg", Summary: "删除组织标志 Delete organization logo", Description: "删除组织上传或设置的标志\nDelete the logo the organization uploaded or set", Auth: true, Admin: false},
+	{Method: "GET", Path: "/api/v1/org/:id/content-policy", ID: "ContentPolicy.Org", Summary: "获取组织的内容策略 Get organization content policy", Description: "组织成员查看组织生效的内容策略\nOrganization members view the effective content policy of the organization", Auth: true, Admin: false},
+	{Method: "GET", Path: "/api/v1/org/:id/invitations", ID: "Invitation.OrgList", Summary: "获取组织邀请 List invitations", Description: "获取组织的邀请\nList the invitations of an organization", Auth: true, Admin: false},
+	{Method: "POST", Path: "/api/v1/org/:id/invitations", ID: "Invitation.OrgCreate", Summary: "创建邀请 Create invitation", Description: "创建加入组织的邀请\nCreate an invitation to join the organization", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.CreateInvitationReq{})},
+	{Method: "DELETE", Path: "/api/v1/org/:id/invitations/:invitation_id", ID: "Invitation.OrgRevoke", Summary: "撤销邀请 Revoke invitation", Description: "撤销组织的邀请\nRevoke an invitation of the organization", Auth: true, Admin: false},
+	{Method: "GET", Path: "/api/v1/org/:id/notifications", ID: "Notification.List", Summary: "获取通知渠道 List notification channels", Description: "获取当前用户或组织的通知渠道\nList the notification channels of the current user or the organization", Auth: true, Admin: false},
+	{Method: "POST", Path: "/api/v1/org/:id/notifications", ID: "Notification.Create", Summary: "创建通知渠道 Create notification channel", Description: "为当前用户或组织创建通知渠道\nCreate a notification channel for the current user or the organization", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.NotificationChannelReq{})},
+	{Method: "PUT", Path: "/api/v1/org/:id/notifications/:channel_id", ID: "Notification.Update", Summary: "更新通知渠道 Update notification channel", Description: "更新通知渠道\nUpdate a notification channel", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.NotificationChannelReq{})},
+	{Method: "DELETE", Path: "/api/v1/org/:id/notifications/:channel_id", ID: "Notification.Delete", Summary: "删除通知渠道 Delete notification channel", Description: "删除通知渠道及其投递记录\nDelete a notification channel and its deliveries", Auth: true, Admin: false},
+	{Method: "GET", Path: "/api/v1/org/:id/notifications/:channel_id/deliveries", ID: "Notification.Deliveries", Summary: "获取投递记录 List deliveries", Description: "分页获取通知渠道的投递记录\nList the deliveries of a notification channel with pagination", Auth: true, Admin: false, Query: []string{"page", "limit"}},
+	{Method: "POST", Path: "/api/v1/org/:id/notifications/:channel_id/test", ID: "Notification.Test", Summary: "发送测试通知 Send a test notification", Description: "通过通知渠道发送一条测试通知，结果见投递记录\nSend a test notification through the channel, the outcome shows in its deliveries", Auth: true, Admin: false},
+	{Method: "PUT", Path: "/api/v1/org/:id/preview-template", ID: "Org.UploadPreviewTemplate", Summary: "上传预览图模板 Upload preview template", Description: "上传组织的预览图模板背景\nUpload the organization's preview image template background", Auth: true, Admin: false},
+	{Method: "GET", Path: "/api/v1/org/:id/projects", ID: "Org.GetOrganizationProject", Summary: "获取组织项目 Get organization projects", Description: "获取组织项目\nGet Organization Projects", Auth: true, Admin: false, Query: []string{"page", "limit", "sort", "q"}},
+	{Method: "GET", Path: "/api/v1/org/:id/quota", ID: "Quota.Org", Summary: "获取组织配额和用量 Get organization quota and usage", Description: "获取组织的配额和用量\nGet the quota and usage of an organization", Auth: true, Admin: false},
+	{Method: "GET", Path: "/api/v1/org/:id/teams", ID: "Team.List", Summary: "获取组织团队 List teams", Description: "获取组织的团队\nList the teams of the organization", Auth: true, Admin: false},
+	{Method: "POST", Path: "/api/v1/org/:id/teams", ID: "Team.Create", Summary: "创建团队 Create team", Description: "创建团队\nCreate a team", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.TeamReq{})},
+	{Method: "PUT", Path: "/api/v1/org/:id/teams/:team_id", ID: "Team.Update", Summary: "更新团队 Update team", Description: "更新团队\nUpdate a team", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.TeamReq{})},
+	{Method: "DELETE", Path: "/api/v1/org/:id/teams/:team_id", ID: "Team.Delete", Summary: "删除团队 Delete team", Description: "删除团队，团队的项目授权一并撤销\nDelete a team, revoking its project grants", Auth: true, Admin: false},
+	{Method: "GET", Path: "/api/v1/org/:id/teams/:team_id/members", ID: "Team.Members", Summary: "获取团队成员 List team members", Description: "获取团队成员\nList the members of a team", Auth: true, Admin: false},
+	{Method: "PUT", Path: "/api/v1/org/:id/teams/:team_id/members", ID: "Team.AddMember", Summary: "添加团队成员 Add team member", Description: "添加团队成员，用户必须已是组织成员\nAdd a team member, the user must already be a member of the organization", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.TeamUserReq{})},
+	{Method: "DELETE", Path: "/api/v1/org/:id/teams/:team_id/members", ID: "Team.RemoveMember", Summary: "移除团队成员 Remove team member", Description: "移除团队成员\nRemove a team member", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.TeamUserReq{})},
+	{Method: "GET", Path: "/api/v1/org/:id/trash", ID: "Trash.Org", Summary: "获取回收站中的项目 List projects in the trash", Description: "获取组织回收站中的项目\nList the projects in the trash of an organization", Auth: true, Admin: false, Query: []string{"page", "limit"}},
+	{Method: "GET", Path: "/api/v1/org/:id/users", ID: "Org.GetOrganizationUsers", Summary: "获取组织成员及其角色 Get organization members and roles", Description: "获取组织成员及其角色\nGet Organization members and their roles", Auth: true, Admin: false},
+	{Method: "PUT", Path: "/api/v1/org/:id/users", ID: "Org.AddOrganizationUser", Summary: "添加组织成员或修改角色 Add organization member or change role", Description: "添加组织成员或修改其角色\nAdd an organization member or change their role", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.OrgUserReq{})},
+	{Method: "DELETE", Path: "/api/v1/org/:id/users", ID: "Org.DeleteOrganizationUser", Summary: "移除组织成员 Remove organization member", Description: "移除组织成员\nRemove an organization member", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.OrgUserReq{})},
+	{Method: "POST", Path: "/api/v1/project", ID: "Project.Create", Summary: "创建项目 Create project", Description: "创建项目\nCreate project", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.CreateProjectReq{})},
+	{Method: "GET", Path: "/api/v1/project/:id", ID: "Project.Info", Summary: "获取项目信息 Get project info", Description: "获取项目信息\nGet project information", Auth: true, Admin: false},
+	{Method: "PUT", Path: "/api/v1/project/:id", ID: "Project.Update", Summary: "更新项目 Update project", Description: "更新项目\nUpdate project", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.UpdateProjectReq{})},
+	{Method: "DELETE", Path: "/api/v1/project/:id", ID: "Project.Delete", Summary: "删除项目 Delete project", Description: "删除项目，项目及其站点移入回收站，保留期内可以恢复\nDelete project, moving it and its sites to the trash where they can be restored within the retention window", Auth: true, Admin: false},
+	{Method: "GET", Path: "/api/v1/project/:id/approvals", ID: "Approval.List", Summary: "获取等待批准的部署 List deployments awaiting approval", Description: "分页获取项目中等待批准的部署\nList the deployments of the project awaiting approval with pagination", Auth: true, Admin: false, Query: []string{"page", "limit"}},
+	{Method: "POST", Path: "/api/v1/project/:id/approvals/:release_id/approve", ID: "Approval.Approve", Summary: "批准部署 Approve a deployment", Description: "维护者批准部署：定时上线时间未到时恢复定时，部署时要求上线或定时上线时间已过时立即激活，其余恢复为普通版本\nA maintainer approves a deployment: it is scheduled again while its go-live time is ahead, activated right away when the deployment asked for it or the go-live time has passed, and becomes a plain version otherwise", Auth: true, Admin: false},
+	{Method: "POST", Path: "/api/v1/project/:id/approvals/:release_id/reject", ID: "Approval.Reject", Summary: "驳回部署 Reject a deployment", Description: "维护者驳回部署，版本标记为失败且不再提供服务，项目的 webhook 收到部署失败通知\nA maintainer rejects a deployment, which is marked failed and never served, the project's webhooks are told the deployment failed", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.ApprovalRejectReq{})},
+	{Method: "GET", Path: "/api/v1/project/:id/cdn", ID: "CDN.List", Summary: "获取 CDN 集成 List CDN integrations", Description: "获取项目的 CDN 集成\nList the CDN integrations of the project", Auth: true, Admin: false},
+	{Method: "POST", Path: "/api/v1/project/:id/cdn", ID: "CDN.Create", Summary: "创建 CDN 集成 Create a CDN integration", Description: "为项目创建 CDN 集成\nCreate a CDN integration for the project", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.CDNIntegrationReq{})},
+	{Method: "PUT", Path: "/api/v1/project/:id/cdn/:cdn_id", ID: "CDN.Update", Summary: "更新 CDN 集成 Update a CDN integration", Description: "更新 CDN 集成\nUpdate a CDN integration", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.CDNIntegrationReq{})},
+	{Method: "DELETE", Path: "/api/v1/project/:id/cdn/:cdn_id", ID: "CDN.Delete", Summary: "删除 CDN 集成 Delete a CDN integration", Description: "删除 CDN 集成\nDelete a CDN integration", Auth: true, Admin: false},
+	{Method: "GET", Path: "/api/v1/project/:id/deploy-keys", ID: "DeployKey.List", Summary: "获取部署密钥 List deploy keys", Description: "获取项目的部署密钥\nList the deploy keys of the project", Auth: true, Admin: false},
+	{Method: "POST", Path: "/api/v1/project/:id/deploy-keys", ID: "DeployKey.Create", Summary: "创建部署密钥 Create a deploy key", Description: "创建项目部署密钥，以当前用户的身份执行，明文只在此时返回；适合交给 CI，避免共享个人访问令牌\nCreate a project deploy key acting as the current user, the plain key is only returned here; meant for CI instead of sharing personal access tokens", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.CreateDeployKeyReq{})},
+	{Method: "DELETE", Path: "/api/v1/project/:id/deploy-keys/:key_id", ID: "DeployKey.Delete", Summary: "撤销部署密钥 Revoke a deploy key", Description: "撤销项目部署密钥\nRevoke a project deploy key", Auth: true, Admin: false},
+	{Method: "GET", Path: "/api/v1/project/:id/export", ID: "Export.Project", Summary: "导出项目的设置和内容 Export the project settings and content", Description: "以 tar.gz 流的形式导出项目的设置、自定义域名、重定向规则和各站点当前或指定版本的内容，用于备份或在实例间迁移\nStream a tar.gz of the project's settings, custom domains, redirect rules and the content of the active or chosen version of each site, for backups or migrating between instances", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.ExportProjectReq{})},
+	{Method: "GET", Path: "/api/v1/project/:id/members", ID: "Project.Members", Summary: "获取项目成员 List project members", Description: "获取直接授予角色的项目成员\nList the project members granted a role directly", Auth: true, Admin: false},
+	{Method: "PUT", Path: "/api/v1/project/:id/members", ID: "Project.SetMember", Summary: "添加项目成员或修改角色 Add project member or change role", Description: "添加项目成员或修改其角色\nAdd a project member or change their role", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.ProjectUserReq{})},
+	{Method: "DELETE", Path: "/api/v1/project/:id/members", ID: "Project.RemoveMember", Summary: "移除项目成员 Remove project member", Description: "移除项目成员\nRemove a project member", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.ProjectUserReq{})},
+	{Method: "GET", Path: "/api/v1/project/:id/retention", ID: "Retention.Get", Summary: "获取版本保留策略 Get the release retention policy", Description: "获取项目的版本保留策略\nGet the release retention policy of a project", Auth: true, Admin: false},
+	{Method: "PUT", Path: "/api/v1/project/:id/retention", ID: "Retention.Update", Summary: "修改版本保留策略 Change the release retention policy", Description: "所有者修改项目的版本保留策略，在下一次 retention 定时任务时生效\nOwners change the release retention policy of a project, applied by the next run of the retention scheduled task", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.RetentionReq{})},
+	{Method: "POST", Path: "/api/v1/project/:id/site", ID: "Site.Create", Summary: "创建站点 Create site", Description: "创建站点\nCreate Site", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.CreateSiteReq{})},
+	{Method: "GET", Path: "/api/v1/project/:id/site/:site_id", ID: "Site.Info", Summary: "获取网站信息 Get site info", Description: "", Auth: true, Admin: false},
+	{Method: "PUT", Path: "/api/v1/project/:id/site/:site_id", ID: "Site.Update", Summary: "更新站点 Update site", Description: "", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.UpdateSiteReq{})},
+	{Method: "DELETE", Path: "/api/v1/project/:id/site/:site_id", ID: "Site.Delete", Summary: "删除站点 Delete site", Description: "删除站点，站点移入项目回收站，保留期内可以恢复\nDelete a site, moving it to the project's trash where it can be restored within the retention window", Auth: true, Admin: false},
+	{Method: "GET", Path: "/api/v1/project/:id/site/:site_id/analytics", ID: "Analytics.Site", Summary: "获取站点访问分析 Get site analytics", Description: "获取站点最近 days 天的访问分析，默认 30 天，每个维度返回浏览量最多的 limit 个值，并附带本月的流量及其限制\nGet the analytics of the site over the last days days, 30 by default, with the limit values of each dimension with the most views and the transfer of this month with its limits", Auth: true, Admin: false},
+	{Method: "PUT", Path: "/api/v1/project/:id/site/:site_id/blobs/:hash", ID: "Blob.Put", Summary: "上传文件内容 Upload file content", Description: "上传一个文件的内容，请求体为文件原始内容，服务端校验其 SHA-256 与路径中的哈希一致\nUpload the content of one file as the raw request body, the server checks that its SHA-256 matches the hash in the path", Auth: true, Admin: false},
+	{Method: "POST", Path: "/api/v1/project/:id/site/:site_id/blobs/missing", ID: "Blob.Missing", Summary: "查询缺失的文件 Look up missing files", Description: "返回尚未存储的内容哈希，客户端只需上传这些文件\nReturn the content hashes not stored yet, the only files the client needs to upload", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.MissingBlobsReq{})},
+	{Method: "POST", Path: "/api/v1/project/:id/site/:site_id/cdn/purge", ID: "CDN.Purge", Summary: "刷新站点的 CDN 缓存 Purge the CDN cache of the site", Description: "手动刷新站点在项目所有已启用 CDN 集成中的缓存，返回执行刷新的后台任务\nPurge the site from every enabled CDN integration of the project by hand, returning the background job doing it", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.CDNPurgeReq{})},
+	{Method: "GET", Path: "/api/v1/project/:id/site/:site_id/debug/resolve", ID: "Site.DebugResolve", Summary: "解释站点服务判定 Explain the serving decision", Description: "以解释模式运行生产环境的服务判定流程，返回匹配结果而不输出站点内容\nRun the production serving pipeline in explain mode, returning the decision without serving any bytes", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.DebugResolveReq{})},
+	{Method: "GET", Path: "/api/v1/project/:id/site/:site_id/deployments", ID: "Release.Deployments", Summary: "获取站点部署版本 List deployed versions", Description: "分页获取站点的部署版本，每次上传都会保留为不可变的版本\nList the deployed versions of the site with pagination, every upload is kept as an immutable version", Auth: true, Admin: false, Query: []string{"page", "limit", "sort", "status"}},
+	{Method: "GET", Path: "/api/v1/project/:id/site/:site_id/deployments/:release_id/checksums", ID: "Release.Checksums", Summary: "获取部署校验过的文件哈希 Get the verified file hashes of a deployment", Description: "获取部署上传时提供并在解压后校验通过的文件 SHA-256\nGet the file SHA-256 given with the upload of a deployment and verified after extraction", Auth: true, Admin: false},
+	{Method: "GET", Path: "/api/v1/project/:id/site/:site_id/deployments/:release_id/links", ID: "LinkCheck.Get", Summary: "获取部署的链接检查报告 Get the link check report of a deployment", Description: "获取版本最近一次链接检查的报告，未检查过时报告为空\nGet the report of the latest link check of a version, the report is null when it was never checked", Auth: true, Admin: false},
+	{Method: "POST", Path: "/api/v1/project/:id/site/:site_id/deployments/:release_id/links", ID: "LinkCheck.Run", Summary: "重新检查部署的链接 Check the links of a deployment again", Description: "立即在后台任务中重新检查版本，不受 linkcheck.enable 影响\nCheck the version again in a background job right away, regardless of linkcheck.enable", Auth: true, Admin: false},
+	{Method: "POST", Path: "/api/v1/project/:id/site/:site_id/deployments/:release_id/pin", ID: "Retention.Pin", Summary: "固定部署使其不被保留策略删除 Pin a deployment so the retention policy keeps it", Description: "固定版本\nPin a version", Auth: true, Admin: false},
+	{Method: "DELETE", Path: "/api/v1/project/:id/site/:site_id/deployments/:release_id/pin", ID: "Retention.Unpin", Summary: "取消固定部署 Unpin a deployment", Description: "取消固定版本\nUnpin a version", Auth: true, Admin: false},
+	{Method: "GET", Path: "/api/v1/project/:id/site/:site_id/deployments/diff", ID: "Release.Diff", Summary: "比较两个部署版本 Compare two deployments", Description: "比较站点的两个部署版本，列出新增、删除和变化的文件及其大小和哈希，便于回滚前确认部署改动了什么\nCompare two deployments of the site, listing added, removed and changed files with their sizes and hashes so owners can see what a deploy changed before rolling back", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.DiffDeploymentsReq{})},
+	{Method: "GET", Path: "/api/v1/project/:id/site/:site_id/domains", ID: "Domain.List", Summary: "获取自定义域名 List custom domains", Description: "获取站点的自定义域名\nList the custom domains of the site", Auth: true, Admin: false},
+	{Method: "POST", Path: "/api/v1/project/:id/site/:site_id/domains", ID: "Domain.Create", Summary: "绑定自定义域名 Bind a custom domain", Description: "为站点绑定自定义域名，返回所有权验证方式，验证通过后才会提供服务\nBind a custom domain to the site and return the verification instructions, it is served only after verification", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.CreateCustomDomainReq{})},
+	{Method: "DELETE", Path: "/api/v1/project/:id/site/:site_id/domains/:domain_id", ID: "Domain.Delete", Summary: "解除自定义域名 Unbind a custom domain", Description: "解除自定义域名并删除其证书\nUnbind a custom domain and delete its certificate", Auth: true, Admin: false},
+	{Method: "POST", Path: "/api/v1/project/:id/site/:site_id/domains/:domain_id/verify", ID: "Domain.Verify", Summary: "验证域名所有权 Verify domain ownership", Description: "检查域名所有权，通过后开始提供服务，启用 ACME 时在后台任务中申请证书\nCheck the domain ownership, once verified it is served and with ACME enabled a background job obtains the certificate", Auth: true, Admin: false},
+	{Method: "GET", Path: "/api/v1/project/:id/site/:site_id/draft", ID: "WebDAV.Get", Summary: "获取草稿的 WebDAV 地址 Get the WebDAV URL of the draft", Description: "获取站点草稿的 WebDAV 地址和用量\nGet the WebDAV URL and usage of the site's draft", Auth: true, Admin: false},
+	{Method: "POST", Path: "/api/v1/project/:id/site/:site_id/draft", ID: "WebDAV.Create", Summary: "创建草稿 Create a draft", Description: "创建站点草稿，默认复制当前版本的文件，已有草稿时返回 409\nCreate a draft of the site, copying the files of the active version by default; 409 when there is a draft already", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.CreateDraftReq{})},
+	{Method: "DELETE", Path: "/api/v1/project/:id/site/:site_id/draft", ID: "WebDAV.Delete", Summary: "丢弃草稿 Discard the draft", Description: "丢弃站点草稿\nDiscard the site's draft", Auth: true, Admin: false},
+	{Method: "POST", Path: "/api/v1/project/:id/site/:site_id/draft/publish", ID: "WebDAV.Publish", Summary: "将草稿发布为新版本 Publish the draft as a new version", Description: "将草稿打包并按普通上传发布为新版本，跳过文件管理器的元数据文件\nPack the draft and publish it as a new version like a plain upload, skipping the metadata files of file managers", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.PublishDraftReq{})},
+	{Method: "GET", Path: "/api/v1/project/:id/site/:site_id/git", ID: "Git.Get", Summary: "获取绑定的仓库 Get the bound repository", Description: "获取站点绑定的仓库及最近一次运行\nGet the repository bound to the site and its latest run", Auth: true, Admin: false},
+	{Method: "PUT", Path: "/api/v1/project/:id/site/:site_id/git", ID: "Git.Save", Summary: "绑定或更新仓库 Bind or update the repository", Description: "为站点绑定或更新仓库，首次绑定时未提供密钥则生成一个并只在此时返回\nBind or update the site's repository, generating a secret on first binding when none is given and returning it only then", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.GitIntegrationReq{})},
+	{Method: "DELETE", Path: "/api/v1/project/:id/site/:site_id/git", ID: "Git.Delete", Summary: "解除绑定的仓库 Unbind the repository", Description: "解除站点绑定的仓库，已发布的版本保留\nUnbind the site's repository, published versions are kept", Auth: true, Admin: false},
+	{Method: "POST", Path: "/api/v1/project/:id/site/:site_id/git/deploy", ID: "Git.Deploy", Summary: "部署分支的最新提交 Deploy the head of the branch", Description: "手动部署分支的最新提交\nDeploy the head of the branch manually", Auth: true, Admin: false},
+	{Method: "GET", Path: "/api/v1/project/:id/site/:site_id/git/logs", ID: "Git.Logs", Summary: "实时推送构建输出 Live output of the build", Description: "以 SSE 实时推送站点最近一次构建的输出，先发送已有的输出，构建结束时发送 done 事件；输出从仓库绑定记录读取，构建可以在其他实例上进行\nStream the output of the site's latest build as SSE, sending the output so far first and a done event once the build ends; the output is read from the binding record so the build may run on another instance", Auth: true, Admin: false},
+	{Method: "GET", Path: "/api/v1/project/:id/site/:site_id/jobs", ID: "Job.SiteList", Summary: "获取站点的后台任务 List the background jobs of the site", Description: "分页获取站点的后台任务\nList the background jobs of the site with pagination", Auth: true, Admin: false, Query: []string{"page", "limit"}},
+	{Method: "GET", Path: "/api/v1/project/:id/site/:site_id/jobs/:job_id", ID: "Job.SiteGet", Summary: "获取后台任务 Get a background job", Description: "获取站点的后台任务，用于轮询异步部署的结果\nGet a background job of the site, used to poll the outcome of an async deployment", Auth: true, Admin: false},
+	{Method: "GET", Path: "/api/v1/project/:id/site/:site_id/jobs/:job_id/events", ID: "Job.SiteEvents", Summary: "实时推送后台任务进度 Live progress of a background job", Description: "以 SSE 推送站点后台任务的状态和进度，任务结束时发送 done 事件并关闭连接，用于实时显示异步部署的进度\nStream the status and progress of a site's background job via SSE, sending a done event and closing once the job finished; used to show the progress of async deployments live", Auth: true, Admin: false},
+	{Method: "GET", Path: "/api/v1/project/:id/site/:site_id/logs", ID: "Site.Logs", Summary: "查询最近的请求日志 Query recent request logs", Description: "分页查询站点最近的请求日志，可按状态码或状态码类别、路径或路径前缀和时间范围过滤，默认最新的在前\nQuery the recent request logs of the site with pagination, optionally filtered by status code or class, path or path prefix and time range, newest first by default", Auth: true, Admin: false, Query: []string{"page", "limit", "sort"}, Request: reflect.TypeOf(handlers.SiteLogsReq{})},
+	{Method: "GET", Path: "/api/v1/project/:id/site/:site_id/logs/tail", ID: "Site.TailLogs", Summary: "实时查看访问日志 Live tail of access logs", Description: "以 SSE 实时推送站点访问日志，支持按路径前缀和状态码类别过滤\nStream the site's access logs in real time via SSE, optionally filtered by path prefix and status class", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.TailLogsReq{})},
+	{Method: "GET", Path: "/api/v1/project/:id/site/:site_id/og", ID: "Site.OGMeta", Summary: "获取 open-graph 元信息 Get open-graph meta", Description: "获取站点当前发布的 open-graph 元信息，供页面注入\nGet the open-graph meta values of the site's active release for injection into pages", Auth: true, Admin: false},
+	{Method: "DELETE", Path: "/api/v1/project/:id/site/:site_id/paths", ID: "Release.PurgePaths", Summary: "从当前发布清除路径 Purge paths from the active release", Description: "从当前发布中移除指定路径，生成新的派生发布并切换，保留历史发布以便回滚\nRemove paths from the active release by creating a derived release and switching to it, keeping history for rollback", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.PurgePathsReq{})},
+	{Method: "GET", Path: "/api/v1/project/:id/site/:site_id/previews", ID: "Preview.List", Summary: "获取预览部署 List preview deployments", Description: "获取站点的预览部署\nList the preview deployments of the site", Auth: true, Admin: false},
+	{Method: "DELETE", Path: "/api/v1/project/:id/site/:site_id/previews/:preview_id", ID: "Preview.Delete", Summary: "删除预览部署 Delete a preview deployment", Description: "提前删除预览部署及其版本\nDelete a preview deployment and its version ahead of expiry", Auth: true, Admin: false},
+	{Method: "GET", Path: "/api/v1/project/:id/site/:site_id/purges", ID: "Release.PurgeList", Summary: "获取路径清除记录 Get path purge records", Description: "获取站点的路径清除记录\nGet path purge records of the site", Auth: true, Admin: false, Query: []string{"page", "limit"}},
+	{Method: "POST", Path: "/api/v1/project/:id/site/:site_id/release", ID: "Release.Create", Summary: "创建站点发布 Create site release", Description: "", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.CreateReleaseReq{})},
+	{Method: "DELETE", Path: "/api/v1/project/:id/site/:site_id/release", ID: "Release.Delete", Summary: "删除站点版本 Delete site release", Description: "", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.ReleaseIdReq{})},
+	{Method: "POST", Path: "/api/v1/project/:id/site/:site_id/release/activation", ID: "Release.Activation", Summary: "指定使用该站点版本", Description: "", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.ReleaseIdReq{})},
+	{Method: "POST", Path: "/api/v1/project/:id/site/:site_id/release/manifest", ID: "Blob.Deploy", Summary: "使用部署清单发布 Publish from a deployment manifest", Description: "使用部署清单创建新版本，清单引用的文件需事先上传，版本和预览参数与普通上传相同\nCreate a version from a deployment manifest whose files are uploaded beforehand, the version and preview parameters match plain uploads", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.ManifestDeployReq{})},
+	{Method: "GET", Path: "/api/v1/project/:id/site/:site_id/releases", ID: "Release.ReleaseList", Summary: "获取站点 release 列表", Description: "分页获取站点的 release 记录\nList the release records of the site with pagination", Auth: true, Admin: false, Query: []string{"page", "limit", "sort"}},
+	{Method: "POST", Path: "/api/v1/project/:id/site/:site_id/rollback", ID: "Release.Rollback", Summary: "回滚站点版本 Roll back the site", Description: "回滚到指定版本，未指定时回滚到当前版本之前的版本\nRoll back to the given version, or to the version before the active one when none is given", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.RollbackReq{})},
+	{Method: "POST", Path: "/api/v1/project/:id/site/:site_id/signed-urls", ID: "Site.SignURL", Summary: "签发受保护文件的临时链接 Issue a temporary URL of a protected file", Description: "为受保护站点的一个文件签发临时链接，无需登录即可访问直到过期，用于对外分享单个文件；修改站点的访问设置后所有链接失效\nIssue a temporary URL for one file of a protected site, reachable without signing in until it expires, to share a single file outside; changing the site's access settings voids every URL", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.SignURLReq{})},
+	{Method: "POST", Path: "/api/v1/project/:id/site/:site_id/uploads", ID: "Upload.Create", Summary: "创建分片上传 Create a chunked upload", Description: "创建分片上传会话，返回分片大小和数量\nCreate a chunked upload session, returning the chunk size and count", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.CreateUploadReq{})},
+	{Method: "GET", Path: "/api/v1/project/:id/site/:site_id/uploads/:upload_id", ID: "Upload.Get", Summary: "获取上传进度 Get upload progress", Description: "获取上传会话及已上传的分片，用于续传\nGet an upload session with its uploaded chunks, used to resume", Auth: true, Admin: false},
+	{Method: "DELETE", Path: "/api/v1/project/:id/site/:site_id/uploads/:upload_id", ID: "Upload.Delete", Summary: "放弃上传 Abort the upload", Description: "放弃上传会话并删除已上传的分片\nAbort an upload session and delete the uploaded chunks", Auth: true, Admin: false},
+	{Method: "PUT", Path: "/api/v1/project/:id/site/:site_id/uploads/:upload_id/chunks/:index", ID: "Upload.PutChunk", Summary: "上传分片 Upload a chunk", Description: "上传一个分片，请求体为分片原始内容，可通过 X-Chunk-Sha256 请求头校验；重传会覆盖同一分片\nUpload one chunk as the raw request body, optionally verified by the X-Chunk-Sha256 header; re-uploading overwrites the chunk", Auth: true, Admin: false},
+	{Method: "POST", Path: "/api/v1/project/:id/site/:site_id/uploads/:upload_id/complete", ID: "Upload.Complete", Summary: "拼接并发布 Assemble and publish", Description: "拼接全部分片并校验大小和 SHA-256，通过后按普通上传发布，async 时在后台任务中进行；校验失败时保留会话以便重传分片\nAssemble all chunks and verify the size and SHA-256, then publish like a plain upload, in a background job with async; the session is kept on mismatch so chunks can be re-uploaded", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.CompleteUploadReq{})},
+	{Method: "GET", Path: "/api/v1/project/:id/sites", ID: "Project.GetSites", Summary: "获取项目站点 Get project sites", Description: "获取站点列表\nGet site list", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.GetSiteListReq{})},
+	{Method: "GET", Path: "/api/v1/project/:id/teams", ID: "Project.Teams", Summary: "获取项目团队 List project teams", Description: "获取被授予项目角色的团队\nList the teams granted a role on the project", Auth: true, Admin: false},
+	{Method: "PUT", Path: "/api/v1/project/:id/teams", ID: "Project.SetTeam", Summary: "为团队授予项目角色 Grant a team a project role", Description: "为组织内的团队授予项目角色\nGrant a team of the organization a role on the project", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.ProjectTeamReq{})},
+	{Method: "DELETE", Path: "/api/v1/project/:id/teams", ID: "Project.RemoveTeam", Summary: "撤销团队的项目角色 Revoke a team's project role", Description: "撤销团队的项目角色\nRevoke a team's role on the project", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.ProjectTeamReq{})},
+	{Method: "GET", Path: "/api/v1/project/:id/transfer", ID: "Transfer.Get", Summary: "获取等待确认的转移 Get the pending transfer", Description: "获取项目等待确认的转移\nGet the project's transfer awaiting confirmation", Auth: true, Admin: false},
+	{Method: "POST", Path: "/api/v1/project/:id/transfer", ID: "Transfer.Request", Summary: "发起项目转移 Request a project transfer", Description: "发起项目转移，接收方在 transfer-ttl 内确认后项目连同站点、域名和设置一起转移，重复发起会替换之前的转移\nRequest a project transfer, the project moves with its sites, domains and settings once the recipient confirms within transfer-ttl, requesting again replaces the previous transfer", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.TransferProjectReq{})},
+	{Method: "DELETE", Path: "/api/v1/project/:id/transfer", ID: "Transfer.Cancel", Summary: "取消项目转移 Cancel the project transfer", Description: "取消项目等待确认的转移\nCancel the project's transfer awaiting confirmation", Auth: true, Admin: false},
+	{Method: "GET", Path: "/api/v1/project/:id/trash", ID: "Trash.Sites", Summary: "获取回收站中的站点 List sites in the trash", Description: "获取项目回收站中单独删除的站点，随项目删除的站点随项目一起恢复\nList the sites deleted on their own in the trash of a project, sites deleted along with the project are restored with it", Auth: true, Admin: false, Query: []string{"page", "limit"}},
+	{Method: "DELETE", Path: "/api/v1/project/:id/trash/:site_id", ID: "Trash.PurgeSite", Summary: "彻底删除站点 Purge a site", Description: "彻底删除回收站中的站点，其版本和文件无法再恢复\nPurge a site from the trash, its versions and files can no longer be restored", Auth: true, Admin: false},
+	{Method: "POST", Path: "/api/v1/project/:id/trash/:site_id/restore", ID: "Trash.RestoreSite", Summary: "恢复站点 Restore a site", Description: "从回收站恢复站点，恢复后立即按原有的激活版本提供服务\nRestore a site from the trash, it is served from its previously active version right away", Auth: true, Admin: false},
+	{Method: "GET", Path: "/api/v1/project/:id/webhooks", ID: "Webhook.List", Summary: "获取项目 webhook List project webhooks", Description: "获取项目的 webhook\nList the webhooks of the project", Auth: true, Admin: false},
+	{Method: "POST", Path: "/api/v1/project/:id/webhooks", ID: "Webhook.Create", Summary: "创建 webhook Create webhook", Description: "为项目创建 webhook，未提供密钥时生成一个并只在此时返回\nCreate a webhook for the project, generating a secret when none is given and returning it only now", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.WebhookReq{})},
+	{Method: "PUT", Path: "/api/v1/project/:id/webhooks/:webhook_id", ID: "Webhook.Update", Summary: "更新 webhook Update webhook", Description: "更新 webhook\nUpdate a webhook", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.WebhookReq{})},
+	{Method: "DELETE", Path: "/api/v1/project/:id/webhooks/:webhook_id", ID: "Webhook.Delete", Summary: "删除 webhook Delete webhook", Description: "删除 webhook 及其投递记录\nDelete a webhook and its deliveries", Auth: true, Admin: false},
+	{Method: "GET", Path: "/api/v1/project/:id/webhooks/:webhook_id/deliveries", ID: "Webhook.Deliveries", Summary: "获取投递记录 List deliveries", Description: "分页获取 webhook 的投递记录\nList the deliveries of a webhook with pagination", Auth: true, Admin: false, Query: []string{"page", "limit"}},
+	{Method: "GET", Path: "/api/v1/project/:id/webhooks/:webhook_id/deliveries/:delivery_id", ID: "Webhook.Delivery", Summary: "获取投递详情 Get delivery details", Description: "获取投递记录及其请求体\nGet a delivery with its request body", Auth: true, Admin: false},
+	{Method: "POST", Path: "/api/v1/project/:id/webhooks/:webhook_id/deliveries/:delivery_id/redeliver", ID: "Webhook.Redeliver", Summary: "重新投递 Redeliver", Description: "以相同的请求体重新投递，创建一条新的投递记录\nSend the same body again as a new delivery", Auth: true, Admin: false},
+	{Method: "POST", Path: "/api/v1/project/import", ID: "Import.Create", Summary: "从导出的压缩包或仓库导入项目 Import a project from an exported archive or a repository", Description: "从 GitHub Pages、Netlify 导出的压缩包或仓库创建项目和站点并完成首次部署，根目录的 CNAME 文件绑定为待验证的自定义域名\nCreate a project and a site from an archive exported by GitHub Pages or Netlify or from a repository and deploy it for the first time, the CNAME file at the root is bound as an unverified custom domain", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.ImportProjectReq{})},
+	{Method: "POST", Path: "/api/v1/runner/builds/:build_id/fail", ID: "Runner.Fail", Summary: "上报构建失败 Report a failed build", Description: "结束失败的构建\nFinish a failed build", Auth: false, Admin: false, Request: reflect.TypeOf(handlers.RunnerFailReq{})},
+	{Method: "PUT", Path: "/api/v1/runner/builds/:build_id/log", ID: "Runner.Log", Summary: "上报构建输出 Report build output", Description: "保存构建的输出，站点的实时构建日志随之更新\nSave the output of a build, updating the live build log of the site", Auth: false, Admin: false, Request: reflect.TypeOf(handlers.RunnerLogReq{})},
+	{Method: "POST", Path: "/api/v1/runner/builds/:build_id/result", ID: "Runner.Result", Summary: "上传构建结果 Upload the build result", Description: "上传构建成功后输出目录的 zip 压缩包并发布为站点的新版本，构建期间收到更新的推送时不发布\nUpload the zip of the output directory of a successful build and publish it as a new version of the site, nothing is published when a newer push arrived during the build", Auth: false, Admin: false},
+	{Method: "POST", Path: "/api/v1/runner/builds/claim", ID: "Runner.Claim", Summary: "领取构建 Claim a build", Description: "领取一个标签匹配的排队构建，没有可领取的构建时 build 为空\nClaim a queued build whose labels match, build is null when nothing is claimable", Auth: false, Admin: false},
+	{Method: "POST", Path: "/api/v1/runner/heartbeat", ID: "Runner.Heartbeat", Summary: "执行器心跳 Runner heartbeat", Description: "记录执行器在线并延长其正在执行的构建的租约，返回仍在执行的构建ID，不在其中的构建执行器应当放弃\nRecord that the runner is online and extend the lease of its running builds, returning the IDs of the builds still running; the runner should abandon any other build", Auth: false, Admin: false},
+	{Method: "POST", Path: "/api/v1/runner/register", ID: "Runner.Register", Summary: "执行器注册 Register the runner", Description: "执行器启动时上报标签、并发数和版本，返回心跳间隔和构建超时时间\nA runner reports its labels, concurrency and version when it starts, receiving the heartbeat interval and the build timeout", Auth: false, Admin: false, Request: reflect.TypeOf(handlers.RegisterRunnerReq{})},
+	{Method: "GET", Path: "/api/v1/search", ID: "Search.User", Summary: "检索可访问的项目、站点、用户和组织 Search accessible projects, sites, users and organizations", Description: "在当前用户可访问的范围内检索\nSearch within what the current user can access", Auth: true, Admin: false, Query: []string{"q", "type", "page", "limit"}},
+	{Method: "GET", Path: "/api/v1/site/:site_id/access", ID: "Site.Access", Summary: "以项目成员或私有实例用户的身份访问站点 Visit a site as a project member or a user of the private instance", Description: "为仅限成员访问的站点或私有实例的站点签发跳转凭据并返回站点，未登录时先跳转到面板登录页，return 为要访问的站点地址；\n私有实例下任何已登录的用户都可以访问非成员站点\nIssue a ticket for a members-only site or a site of a private instance and go back to it, signing in on the panel first when needed; return is the site address to visit;\nany signed-in user may visit sites other than members-only ones on a private instance", Auth: false, Admin: false, Query: []string{"return"}},
+	{Method: "GET", Path: "/api/v1/site/:site_id/og.png", ID: "Release.PreviewImage", Summary: "获取站点预览图 Get site preview image", Description: "公开获取站点当前发布的 open-graph 预览图，仅限公开项目，私有实例不提供\nPublicly serve the open-graph preview image of the site's active release, public projects only and never on private instances", Auth: false, Admin: false},
+	{Method: "DELETE", Path: "/api/v1/trash/project/:id", ID: "Trash.PurgeProject", Summary: "彻底删除项目 Purge a project", Description: "彻底删除回收站中的项目，其站点、版本和文件无法再恢复\nPurge a project from the trash, its sites, versions and files can no longer be restored", Auth: true, Admin: false},
+	{Method: "POST", Path: "/api/v1/trash/project/:id/restore", ID: "Trash.RestoreProject", Summary: "恢复项目 Restore a project", Description: "从回收站恢复项目及随其删除的站点，恢复后的项目同样计入配额\nRestore a project and the sites deleted along with it from the trash, the restored project counts against the quota again", Auth: true, Admin: false},
+	{Method: "GET", Path: "/api/v1/user", ID: "User.GetUser", Summary: "获取用户信息 Get user info", Description: "获取用户信息\nGet user information", Auth: true, Admin: false},
+	{Method: "PUT", Path: "/api/v1/user", ID: "User.UpdateUser", Summary: "更新用户信息 Update user info", Description: "更新用户信息\nUpdate user information", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.UserDTO{})},
+	{Method: "GET", Path: "/api/v1/user/2fa", ID: "TwoFactor.Status", Summary: "获取两步验证状态 Get two-factor status", Description: "获取当前用户的两步验证状态\nGet the two-factor status of the current user", Auth: true, Admin: false},
+	{Method: "POST", Path: "/api/v1/user/2fa/recovery-codes", ID: "TwoFactor.RegenerateRecoveryCodes", Summary: "重新生成恢复码 Regenerate recovery codes", Description: "使用验证码重新生成恢复码\nRegenerate recovery codes, confirmed by a code", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.TwoFactorCodeReq{})},
+	{Method: "POST", Path: "/api/v1/user/2fa/totp", ID: "TwoFactor.Enroll", Summary: "开始绑定 TOTP Start TOTP enrollment", Description: "开始绑定 TOTP，生成新的密钥和 otpauth 地址，确认验证码后才会启用\nStart TOTP enrollment, generating a new secret and otpauth URI, it is enabled only after a code is confirmed", Auth: true, Admin: false},
+	{Method: "DELETE", Path: "/api/v1/user/2fa/totp", ID: "TwoFactor.Disable", Summary: "关闭两步验证 Disable two-factor authentication", Description: "使用验证码或恢复码关闭两步验证\nDisable two-factor authentication with a code or a recovery code", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.TwoFactorCodeReq{})},
+	{Method: "POST", Path: "/api/v1/user/2fa/totp/confirm", ID: "TwoFactor.Confirm", Summary: "确认并启用 TOTP Confirm and enable TOTP", Description: "确认验证码并启用 TOTP，返回只显示一次的恢复码\nConfirm a code and enable TOTP, returning recovery codes that are shown only once", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.TwoFactorCodeReq{})},
+	{Method: "GET", Path: "/api/v1/user/:id", ID: "User.GetUser", Summary: "获取用户信息 Get user info", Description: "获取用户信息\nGet user information", Auth: true, Admin: false},
+	{Method: "GET", Path: "/api/v1/user/:id/orgs", ID: "User.GetOrgs", Summary: "获取用户组织 Get user orgs", Description: "GetUserOrgs 获取用户的组织\nGet user organizations", Auth: true, Admin: false, Query: []string{"page", "limit"}},
+	{Method: "GET", Path: "/api/v1/user/:id/projects", ID: "User.GetProjects", Summary: "获取用户项目 Get user projects", Description: "GetUserProjects 获取用户的项目\nGet user projects", Auth: true, Admin: false, Query: []string{"page", "limit", "sort", "q"}},
+	{Method: "PUT", Path: "/api/v1/user/avatar", ID: "Avatar.UploadUser", Summary: "上传头像 Upload avatar", Description: "上传当前用户的头像\nUpload the avatar of the current user", Auth: true, Admin: false},
+	{Method: "DELETE", Path: "/api/v1/user/avatar", ID: "Avatar.DeleteUser", Summary: "删除头像 Delete avatar", Description: "删除当前用户上传或设置的头像，改用 Gravatar\nDelete the avatar the current user uploaded or set, falling back to Gravatar", Auth: true, Admin: false},
+	{Method: "GET", Path: "/api/v1/user/captcha", ID: "User.GetCaptcha", Summary: "获取验证码 Get captcha", Description: "获取验证码配置，使用内置算术题时每次请求返回一道新题\nGet captcha configuration, handing out a new challenge per request with the built-in math captcha", Auth: false, Admin: false},
+	{Method: "GET", Path: "/api/v1/user/deletion", ID: "Account.DeletionStatus", Summary: "获取账号删除状态 Get the account deletion status", Description: "获取账号的删除状态，以及删除前应转移的个人项目和需要其他所有者的组织\nGet the deletion status of the account with the personal projects to transfer and the organizations needing another owner first", Auth: true, Admin: false},
+	{Method: "POST", Path: "/api/v1/user/deletion", ID: "Account.RequestDeletion", Summary: "申请删除账号 Ask for the account's deletion", Description: "申请删除账号，等待 account.deletion-grace 后删除，期间可以取消；\n用户是组织的唯一所有者时拒绝，有个人项目时需要确认项目随账号移入回收站，两种情况都返回 409 和需要处理的项目与组织\nAsk for the account's deletion, done after account.deletion-grace and cancellable meanwhile;\nrefused while the user is the only owner of an organization and personal projects need confirming they go to the trash with the account,\nboth answered with 409 and the projects and organizations to take care of", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.DeleteAccountReq{})},
+	{Method: "DELETE", Path: "/api/v1/user/deletion", ID: "Account.CancelDeletion", Summary: "取消删除账号 Cancel the account's deletion", Description: "在删除时间之前取消删除账号\nCancel the account's deletion before its time comes", Auth: true, Admin: false},
+	{Method: "POST", Path: "/api/v1/user/email/verify", ID: "User.VerifyEmail", Summary: "验证邮箱 Verify the email address", Description: "通过邮件中的令牌验证邮箱\nVerify the email address with the token from the email", Auth: false, Admin: false, Request: reflect.TypeOf(handlers.VerifyEmailReq{})},
+	{Method: "POST", Path: "/api/v1/user/email/verify/resend", ID: "User.ResendVerification", Summary: "重新发送验证邮件 Send the verification email again", Description: "重新发送当前用户的邮箱验证链接\nSend the verification link of the current user again", Auth: true, Admin: false},
+	{Method: "GET", Path: "/api/v1/user/export", ID: "Account.Export", Summary: "导出个人数据 Export personal data", Description: "以 JSON 文件下载个人数据，包括个人资料、关联身份、组织和项目的成员关系、个人项目、会话、令牌、通知渠道和相关的审计日志\nDownload the personal data as a JSON file, with the profile, linked identities, organization and project memberships,\npersonal projects, sessions, tokens, notification channels and the related audit entries", Auth: true, Admin: false},
+	{Method: "GET", Path: "/api/v1/user/identities", ID: "OIDC.Identities", Summary: "获取已关联的身份 Get linked identities", Description: "获取当前用户关联的身份\nGet the identities linked to the current user", Auth: true, Admin: false},
+	{Method: "POST", Path: "/api/v1/user/invitations/accept", ID: "Invitation.Accept", Summary: "使用邀请加入组织 Join an organization with an invitation", Description: "已登录用户使用邀请加入组织\nA signed-in user uses an invitation to join its organization", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.InvitationTokenReq{})},
+	{Method: "POST", Path: "/api/v1/user/ldap/:provider_id/link", ID: "LDAP.Link", Summary: "关联目录账号 Link a directory account", Description: "使用目录登录名和密码将目录账号关联到当前用户\nLink a directory account to the current user with its login name and password", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.LDAPLinkReq{})},
+	{Method: "POST", Path: "/api/v1/user/login", ID: "User.Login", Summary: "登录，需要时校验验证码 Login, checking the captcha when needed", Description: "用户登录，按账号和客户端 IP 统计连续失败，失败过多时退避、要求验证码或临时锁定账号\nUser login, counting consecutive failures by account and client IP to back off, require the captcha or lock the account temporarily after too many", Auth: false, Admin: false, Request: reflect.TypeOf(handlers.LoginReq{})},
+	{Method: "POST", Path: "/api/v1/user/login/2fa", ID: "TwoFactor.Login", Summary: "完成两步验证登录 Complete two-factor login", Description: "使用密码登录返回的临时凭证和第二因素完成登录\nComplete the login with the challenge returned by the password login and the second factor", Auth: false, Admin: false, Request: reflect.TypeOf(handlers.TwoFactorLoginReq{})},
+	{Method: "POST", Path: "/api/v1/user/logout", ID: "User.Logout", Summary: "登出并撤销当前会话 Log out and revoke the current session", Description: "用户登出，撤销当前的登录会话\nUser logout, revoking the current sign-in session", Auth: false, Admin: false},
+	{Method: "GET", Path: "/api/v1/user/notifications", ID: "Notification.List", Summary: "获取通知渠道 List notification channels", Description: "获取当前用户或组织的通知渠道\nList the notification channels of the current user or the organization", Auth: true, Admin: false},
+	{Method: "POST", Path: "/api/v1/user/notifications", ID: "Notification.Create", Summary: "创建通知渠道 Create notification channel", Description: "为当前用户或组织创建通知渠道\nCreate a notification channel for the current user or the organization", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.NotificationChannelReq{})},
+	{Method: "PUT", Path: "/api/v1/user/notifications/:channel_id", ID: "Notification.Update", Summary: "更新通知渠道 Update notification channel", Description: "更新通知渠道\nUpdate a notification channel", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.NotificationChannelReq{})},
+	{Method: "DELETE", Path: "/api/v1/user/notifications/:channel_id", ID: "Notification.Delete", Summary: "删除通知渠道 Delete notification channel", Description: "删除通知渠道及其投递记录\nDelete a notification channel and its deliveries", Auth: true, Admin: false},
+	{Method: "GET", Path: "/api/v1/user/notifications/:channel_id/deliveries", ID: "Notification.Deliveries", Summary: "获取投递记录 List deliveries", Description: "分页获取通知渠道的投递记录\nList the deliveries of a notification channel with pagination", Auth: true, Admin: false, Query: []string{"page", "limit"}},
+	{Method: "POST", Path: "/api/v1/user/notifications/:channel_id/test", ID: "Notification.Test", Summary: "发送测试通知 Send a test notification", Description: "通过通知渠道发送一条测试通知，结果见投递记录\nSend a test notification through the channel, the outcome shows in its deliveries", Auth: true, Admin: false},
+	{Method: "GET", Path: "/api/v1/user/oidc", ID: "OIDC.Providers", Summary: "获取登录提供方 Get auth providers", Description: "获取可用于登录的提供方\nGet the providers available for signing in", Auth: false, Admin: false},
+	{Method: "GET", Path: "/api/v1/user/oidc/:provider_id/callback", ID: "OIDC.Callback", Summary: "提供方回调 Provider callback", Description: "处理提供方回调：已关联的身份直接登录，关联流程绑定到当前用户，否则创建新用户\nHandle the provider callback: linked identities sign in, link flows bind to the current user, otherwise a new user is created", Auth: false, Admin: false, Query: []string{"state", "error", "code"}},
+	{Method: "GET", Path: "/api/v1/user/oidc/:provider_id/link", ID: "OIDC.Link", Summary: "关联提供方身份 Link a provider identity", Description: "为当前用户关联提供方身份\nLink a provider identity to the current user", Auth: true, Admin: false},
+	{Method: "DELETE", Path: "/api/v1/user/oidc/:provider_id/link", ID: "OIDC.Unlink", Summary: "解除关联 Unlink a provider identity", Description: "解除当前用户与提供方的关联，必须保留至少一种登录方式\nUnlink the provider from the current user, at least one sign-in method must remain", Auth: true, Admin: false},
+	{Method: "GET", Path: "/api/v1/user/oidc/:provider_id/login", ID: "OIDC.Login", Summary: "跳转到提供方登录 Redirect to the provider", Description: "跳转到提供方登录\nRedirect to the provider to sign in", Auth: false, Admin: false},
+	{Method: "POST", Path: "/api/v1/user/password/forgot", ID: "User.ForgotPassword", Summary: "申请重置密码 Ask for a password reset", Description: "向账号邮箱发送密码重置链接，响应不透露邮箱是否存在\nSend a password reset link to the account's email, the response does not tell whether the address exists", Auth: false, Admin: false, Request: reflect.TypeOf(handlers.ForgotPasswordReq{})},
+	{Method: "POST", Path: "/api/v1/user/password/reset", ID: "User.ResetPassword", Summary: "重置密码 Reset the password", Description: "通过邮件中的令牌设置新密码，成功后撤销该用户的所有会话\nSet a new password with the token from the email, all sessions of the user are revoked afterwards", Auth: false, Admin: false, Request: reflect.TypeOf(handlers.ResetPasswordReq{})},
+	{Method: "GET", Path: "/api/v1/user/quota", ID: "Quota.User", Summary: "获取配额和用量 Get quota and usage", Description: "获取当前用户的配额和用量\nGet the quota and usage of the current user", Auth: true, Admin: false},
+	{Method: "POST", Path: "/api/v1/user/register", ID: "User.Register", Summary: "注册 Register", Description: "用户注册\nUser registration", Auth: false, Admin: false, Request: reflect.TypeOf(handlers.RegisterReq{})},
+	{Method: "GET", Path: "/api/v1/user/sessions", ID: "Session.List", Summary: "获取登录会话 List sign-in sessions", Description: "获取当前用户未过期的登录会话\nList the unexpired sign-in sessions of the current user", Auth: true, Admin: false},
+	{Method: "DELETE", Path: "/api/v1/user/sessions", ID: "Session.RevokeAll", Summary: "在所有设备上登出 Log out everywhere", Description: "在所有设备上登出，撤销当前用户的全部登录会话，包括当前会话\nLog out everywhere, revoking every sign-in session of the current user including the current one", Auth: true, Admin: false},
+	{Method: "DELETE", Path: "/api/v1/user/sessions/:session_id", ID: "Session.Revoke", Summary: "撤销登录会话 Revoke a sign-in session", Description: "撤销当前用户的一个登录会话，其访问令牌和刷新令牌立即失效\nRevoke one sign-in session of the current user, its access and refresh tokens stop working at once", Auth: true, Admin: false},
+	{Method: "GET", Path: "/api/v1/user/tenant", ID: "Tenant.Current", Summary: "获取所属租户 Get the tenant of the user", Description: "当前用户所属的租户，用于展示租户的品牌，不属于任何租户时为空\nTenant of the current user for showing its branding, null when the user belongs to none", Auth: true, Admin: false},
+	{Method: "POST", Path: "/api/v1/user/token/refresh", ID: "User.Refresh", Summary: "刷新访问令牌 Refresh the access token", Description: "使用刷新令牌获取新的访问令牌，刷新令牌取自请求体或 cookie\nGet a new access token with a refresh token taken from the request body or the cookie", Auth: false, Admin: false, Request: reflect.TypeOf(handlers.RefreshTokenReq{})},
+	{Method: "GET", Path: "/api/v1/user/tokens", ID: "APIToken.List", Summary: "获取个人访问令牌 List personal access tokens", Description: "获取当前用户的个人访问令牌\nList the personal access tokens of the current user", Auth: true, Admin: false},
+	{Method: "POST", Path: "/api/v1/user/tokens", ID: "APIToken.Create", Summary: "创建个人访问令牌 Create personal access token", Description: "创建个人访问令牌，明文只在此时返回\nCreate a personal access token, the plain token is only returned here", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.CreateAPITokenReq{})},
+	{Method: "PUT", Path: "/api/v1/user/tokens/:token_id", ID: "APIToken.Update", Summary: "更新个人访问令牌 Update personal access token", Description: "更新个人访问令牌的名称或权限范围\nUpdate the name or scopes of a personal access token", Auth: true, Admin: false, Request: reflect.TypeOf(handlers.UpdateAPITokenReq{})},
+	{Method: "DELETE", Path: "/api/v1/user/tokens/:token_id", ID: "APIToken.Delete", Summary: "撤销个人访问令牌 Revoke personal access token", Description: "撤销个人访问令牌\nRevoke a personal access token", Auth: true, Admin: false},
+	{Method: "GET", Path: "/api/v1/user/transfers", ID: "Transfer.Incoming", Summary: "获取待接受的项目转移 List incoming project transfers", Description: "获取当前用户可以接受的项目转移\nList the project transfers the current user may accept", Auth: true, Admin: false},
+	{Method: "DELETE", Path: "/api/v1/user/transfers/:transfer_id", ID: "Transfer.Decline", Summary: "拒绝项目转移 Decline a project transfer", Description: "拒绝项目转移\nDecline a project transfer", Auth: true, Admin: false},
+	{Method: "POST", Path: "/api/v1/user/transfers/:transfer_id/accept", ID: "Transfer.Accept", Summary: "接受项目转移 Accept a project transfer", Description: "接受项目转移，接收方的项目配额必须有余量；原组织的团队授权会被移除，直接授予的项目成员保留\nAccept a project transfer, the recipient must have room in its project quota; team grants of the former organization are removed while directly granted project members stay", Auth: true, Admin: false},
+	{Method: "GET", Path: "/api/v1/user/trash", ID: "Trash.User", Summary: "获取回收站中的项目 List projects in the trash", Description: "获取当前用户回收站中的项目\nList the projects in the trash of the current user", Auth: true, Admin: false, Query: []string{"page", "limit"}},
+}
+
+var fieldDocs = map[string]string{
+	"constants.EnumError.Allowed":                     "允许的取值 Allowed values",
+	"constants.EnumError.Field":                       "字段名 Field name",
+	"constants.EnumError.Value":                       "非法取值 Invalid value",
+	"handlers.APITokenDTO.CreatedAt":                  "创建时间 Created time",
+	"handlers.APITokenDTO.ExpiresAt":                  "过期时间 Expiry time",
+	"handlers.APITokenDTO.ID":                         "令牌ID Token ID",
+	"handlers.APITokenDTO.LastUsedAt":                 "最后使用时间 Last used time",
+	"handlers.APITokenDTO.Name":                       "令牌名称 Token name",
+	"handlers.APITokenDTO.Prefix":                     "令牌开头部分 Leading part of the token",
+	"handlers.APITokenDTO.Scopes":                     "权限范围 Scopes",
+	"handlers.AccountDeletionDTO.DeleteAt":            "账号的删除时间，未申请时为空 Time the account is deleted at, empty when not requested",
+	"handlers.AccountDeletionDTO.OwnedProjects":       "个人项目，删除账号时移入回收站，可先转移给他人 Personal projects, moved to the trash with the account unless transferred first",
+	"handlers.AccountDeletionDTO.SoleOwnerOrgs":       "用户是唯一所有者的组织，需要先添加其他所有者或删除 Organizations the user is the only owner of, which need another owner or deleting first",
+	"handlers.AccountExport.AuditLogs":                "用户执行的或以用户为目标的审计日志 Audit entries performed by or targeting the user",
+	"handlers.AccountExport.ExportedAt":               "导出时间 Export time",
+	"handlers.AccountExport.Identities":               "关联的第三方身份 Linked third-party identities",
+	"handlers.AccountExport.Memberships":              "直接授予角色的项目 Projects granting a role directly",
+	"handlers.AccountExport.Notifications":            "通知渠道，不包含密钥 Notification channels, without their secrets",
+	"handlers.AccountExport.Organizations":            "所属组织及角色 Organizations and roles",
+	"handlers.AccountExport.Projects":                 "个人项目 Personal projects",
+	"handlers.AccountExport.Sessions":                 "登录会话 Sign-in sessions",
+	"handlers.AccountExport.Tokens":                   "个人访问令牌，不包含明文 Personal access tokens, without the plain tokens",
+	"handlers.AccountExport.User":                     "个人资料 Profile",
+	"handlers.AccountExport.Version":                  "导出格式版本 Export format version",
+	"handlers.AdminOrgReq.Description":                "描述信息 Description",
+	"handlers.AdminOrgReq.DisplayName":                "显示名称，创建时默认为组织名称 Display name, the organization name by default on creation",
+	"handlers.AdminOrgReq.Email":                      "邮箱地址 Email address",
+	"handlers.AdminOrgReq.Owners":                     "应为所有者的用户名，创建时至少一个，不会移除其他所有者 Names of users that should be owners, at least one on creation; other owners are never removed",
+	"handlers.AdminProjectReq.Description":            "描述 Description",
+	"handlers.AdminProjectReq.DisplayName":            "显示名称 Display name",
+	"handlers.AdminProjectReq.Labels":                 "项目标签，设置时替换全部标签 Project labels, replacing all of them when set",
+	"handlers.AdminProjectReq.Owner":                  "所有者的用户名或组织名称，已有项目的所有者须通过项目转移修改 Name of the owning user or organization, owners of existing projects change through a project transfer",
+	"handlers.AdminProjectReq.OwnerType":              "所有者类型 Owner type",
+	"handlers.AdminProjectReq.Visibility":             "可见性 Visibility",
+	"handlers.AdminUserReq.Description":               "描述 Description",
+	"handlers.AdminUserReq.Disabled":                  "停用或重新启用账号 Disable or enable the account again",
+	"handlers.AdminUserReq.DisplayName":               "显示名称 Display name",
+	"handlers.AdminUserReq.Email":                     "邮箱，为空字符串时清除 Email, cleared by an empty string",
+	"handlers.AdminUserReq.Language":                  "语言 Language",
+	"handlers.AdminUserReq.Password":                  "密码，只写，为空时不修改 Password, write-only and unchanged when empty",
+	"handlers.AdminUserReq.Role":                      "全局角色，创建时默认为 user Global role, user by default on creation",
+	"handlers.AnalyticsValueDTO.Value":                "维度的值，超出记录上限的值合并为 (other) Value of the dimension, values beyond the limit are merged into (other)",
+	"handlers.AnalyticsValueDTO.Views":                "浏览量 Page views",
+	"handlers.ApprovalRejectReq.Reason":               "驳回原因，随 webhook 通知项目 Reason of the rejection, sent to the project's webhooks",
+	"handlers.AuditListReq.Action":                    "操作 Action",
+	"handlers.AuditListReq.ActorID":                   "操作者ID Actor ID",
+	"handlers.AuditListReq.Since":                     "起始时间（RFC 3339） Start time (RFC 3339)",
+	"handlers.AuditListReq.TargetID":                  "目标ID Target ID",
+	"handlers.AuditListReq.TargetType":                "目标类型 Target type",
+	"handlers.AuditListReq.Until":                     "结束时间（RFC 3339） End time (RFC 3339)",
+	"handlers.AuditLogDTO.Action":                     "操作 Action",
+	"handlers.AuditLogDTO.ActorID":                    "操作者ID Actor ID",
+	"handlers.AuditLogDTO.ActorName":                  "操作者名称 Actor name",
+	"handlers.AuditLogDTO.CreatedAt":                  "发生时间 Event time",
+	"handlers.AuditLogDTO.Details":                    "详细信息 Details",
+	"handlers.AuditLogDTO.Hash":                       "记录哈希 Entry hash",
+	"handlers.AuditLogDTO.ID":                         "日志ID Log ID",
+	"handlers.AuditLogDTO.IP":                         "客户端IP Client IP",
+	"handlers.AuditLogDTO.TargetID":                   "目标ID Target ID",
+	"handlers.AuditLogDTO.TargetType":                 "目标类型 Target type",
+	"handlers.AuthProviderAdminDTO.AdminGroups":       "平台管理员组 Admin groups",
+	"handlers.AuthProviderAdminDTO.AllowedGroups":     "允许登录的组 Allowed groups",
+	"handlers.AuthProviderAdminDTO.BaseURL":           "自托管服务地址 Self-hosted base URL",
+	"handlers.AuthProviderAdminDTO.CallbackURL":       "需要在提供方登记的回调地址 Callback URL to register at the provider",
+	"handlers.AuthProviderAdminDTO.ClientID":          "客户端ID Client ID",
+	"handlers.AuthProviderAdminDTO.Enabled":           "是否启用 Enabled",
+	"handlers.AuthProviderAdminDTO.GroupsClaim":       "组声明 Groups claim",
+	"handlers.AuthProviderAdminDTO.LDAP":              "LDAP 目录设置 LDAP directory settings",
+	"handlers.AuthProviderAdminDTO.OidcDiscoveryURL":  "OpenID自动发现URL OpenID discovery URL",
+	"handlers.AuthProviderDTO.DisplayName":            "显示名称 Display name",
+	"handlers.AuthProviderDTO.ID":                     "提供方ID Provider ID",
+	"handlers.AuthProviderDTO.Icon":                   "图标 Icon URL",
+	"handlers.AuthProviderDTO.Type":                   "提供方类型 Provider type",
+	"handlers.AuthProviderReq.AdminGroups":            "平台管理员组 Admin groups",
+	"handlers.AuthProviderReq.AllowedGroups":          "允许登录的组 Allowed groups",
+	"handlers.AuthProviderReq.BaseURL":                "自托管服务地址 Self-hosted base URL",
+	"handlers.AuthProviderReq.ClientID":               "客户端ID Client ID",
+	"handlers.AuthProviderReq.ClientSecret":           "客户端密钥 Client secret",
+	"handlers.AuthProviderReq.DisplayName":            "显示名称 Display name",
+	"handlers.AuthProviderReq.Enabled":                "是否启用 Enabled",
+	"handlers.AuthProviderReq.GroupsClaim":            "组声明 Groups claim",
+	"handlers.AuthProviderReq.Icon":                   "图标 Icon URL",
+	"handlers.AuthProviderReq.LDAP":                   "LDAP 目录设置，整体替换 LDAP directory settings, replaced as a whole",
+	"handlers.AuthProviderReq.OidcDiscoveryURL":       "OpenID自动发现URL OpenID discovery URL",
+	"handlers.AuthProviderReq.Type":                   "提供方类型 Provider type",
+	"handlers.BulkDeleteProjectsReq.DryRun":           "只列出将被删除的项目 Only list the projects that would be deleted",
+	"handlers.BulkDeleteProjectsReq.InactiveDays":     "创建已超过且期间没有部署的天数 Days the projects have existed without any deployment",
+	"handlers.BulkDeleteProjectsReq.OwnerID":          "只删除此所有者的项目，需要同时指定 owner_type Only delete projects of this owner, requires owner_type",
+	"handlers.BulkDeleteProjectsReq.OwnerType":        "只删除此类所有者的项目 Only delete projects of this owner type",
+	"handlers.BulkDisableUsersReq.DryRun":             "只列出将被停用的用户 Only list the users that would be disabled",
+	"handlers.BulkDisableUsersReq.UserIDs":            "停用的用户ID，系统管理员和自己会被跳过 IDs of the users to disable, the system administrator and yourself are skipped",
+	"handlers.BulkReassignProjectsReq.DryRun":         "只列出将被转移的项目 Only list the projects that would be reassigned",
+	"handlers.BulkReassignProjectsReq.FromOwnerID":    "原所有者ID Current owner ID",
+	"handlers.BulkReassignProjectsReq.FromOwnerType":  "原所有者类型 Current owner type",
+	"handlers.BulkReassignProjectsReq.ProjectIDs":     "只转移原所有者的这些项目，为空时转移全部 Only reassign these projects of the current owner, all of them when empty",
+	"handlers.BulkReassignProjectsReq.ToOwnerID":      "新所有者ID New owner ID",
+	"handlers.BulkReassignProjectsReq.ToOwnerType":    "新所有者类型 New owner type",
+	"handlers.CDNIntegrationDTO.Active":               "是否启用 Whether enabled",
+	"handlers.CDNIntegrationDTO.CreatedAt":            "创建时间 Created time",
+	"handlers.CDNIntegrationDTO.ID":                   "集成ID Integration ID",
+	"handlers.CDNIntegrationDTO.Provider":             "服务商 Provider",
+	"handlers.CDNIntegrationDTO.URL":                  "webhook 的地址 URL of the webhook",
+	"handlers.CDNIntegrationDTO.ZoneID":               "Cloudflare 的区域ID Zone ID of Cloudflare",
+	"handlers.CDNIntegrationReq.Active":               "是否启用，默认启用 Whether enabled, enabled by default",
+	"handlers.CDNIntegrationReq.Provider":             "服务商，cloudflare、bunny 或 webhook Provider, cloudflare, bunny or webhook",
+	"handlers.CDNIntegrationReq.Token":                "API 令牌、API 密钥或签名密钥，创建时必填 API token, API key or signing secret, required on creation",
+	"handlers.CDNIntegrationReq.URL":                  "接收刷新请求的地址，webhook 必填 URL receiving purge requests, required by webhook",
+	"handlers.CDNIntegrationReq.ZoneID":               "Cloudflare 的区域ID，cloudflare 必填 Zone ID of Cloudflare, required by cloudflare",
+	"handlers.CDNPurgeReq.Paths":                      "要刷新的路径，/dir/* 形式刷新整个目录 Paths to purge, the form /dir/* purges a whole directory",
+	"handlers.CheckpointReq.Mode":                     "检查点模式，默认 passive，truncate 会清空 WAL 文件 Checkpoint mode, passive by default, truncate empties the WAL file",
+	"handlers.CompleteUploadReq.Async":                "在后台任务中拼接、校验并发布，立即返回任务 Assemble, verify and publish in a background job, returning the job right away",
+	"handlers.ContentPolicyDTO.Custom":                "是否为管理员单独设置的策略 Whether an administrator set this policy individually",
+	"handlers.ContentPolicyDTO.OrganizationID":        "组织ID Organization ID",
+	"handlers.ContentPolicyReq.Action":                "reject 拒绝部署，strip 去掉禁止的文件 Reject refuses the deployment, strip drops the disallowed files",
+	"handlers.ContentPolicyReq.BlockedExtensions":     "禁止的扩展名，例如 .exe Disallowed extensions, e.g. .exe",
+	"handlers.ContentPolicyReq.BlockedMIMETypes":      "禁止的 MIME 类型，支持 type/* 通配 Disallowed MIME types, type/* wildcards are allowed",
+	"handlers.ContentPolicyReq.Serve":                 "访问时是否同样拒绝 Whether requests are refused too",
+	"handlers.CountDTO.Recent":                        "统计期内新增 Added within the period",
+	"handlers.CountDTO.Total":                         "总数 Total",
+	"handlers.CountDTO.Trashed":                       "回收站中的数量 In the trash",
+	"handlers.CreateAPITokenReq.ExpiresAt":            "过期时间，为空则永不过期 Expiry time, never expires when empty",
+	"handlers.CreateAPITokenReq.Name":                 "令牌名称 Token name",
+	"handlers.CreateAPITokenReq.Scopes":               "权限范围 Scopes",
+	"handlers.CreateCustomDomainReq.Domain":           "域名 Domain",
+	"handlers.CreateCustomDomainReq.Method":           "验证方式，默认为 dns Verification method, dns by default",
+	"handlers.CreateDeployKeyReq.ExpiresAt":           "过期时间，为空则永不过期 Expiry time, never expires when empty",
+	"handlers.CreateDeployKeyReq.Name":                "密钥名称 Key name",
+	"handlers.CreateDraftReq.Empty":                   "创建空草稿，默认复制当前版本的文件 Start from an empty draft instead of copying the files of the active version",
+	"handlers.CreateInvitationReq.Email":              "限定的邮箱，设置且启用邮件时发送邀请邮件 Restricted email, an invite email is sent when set and email is enabled",
+	"handlers.CreateInvitationReq.ExpiresIn":          "有效期，单位秒，默认为 registration.invite-ttl Lifetime in seconds, registration.invite-ttl by default",
+	"handlers.CreateInvitationReq.MaxUses":            "可使用次数，默认为 1 Allowed uses, 1 by default",
+	"handlers.CreateInvitationReq.OrganizationID":     "加入的组织，仅管理员接口使用 Organization to join, only used by the admin endpoint",
+	"handlers.CreateInvitationReq.Role":               "组织角色，默认 member Organization role, member by default",
+	"handlers.CreateOrgReq.AvatarURL":                 "头像URL Avatar URL",
+	"handlers.CreateOrgReq.Description":               "描述信息 Description",
+	"handlers.CreateOrgReq.DisplayName":               "显示名称 Display Name",
+	"handlers.CreateOrgReq.Email":                     "邮箱地址 Email Address",
+	"handlers.CreateOrgReq.Name":                      "组织名称，为空时由显示名称生成 Organization Name, derived from the display name when empty",
+	"handlers.CreateProjectReq.Description":           "项目描述 Project Description",
+	"handlers.CreateProjectReq.DisplayName":           "项目显示名称 Project Display Name",
+	"handlers.CreateProjectReq.Labels":                "项目标签 Project labels",
+	"handlers.CreateProjectReq.Name":                  "项目名称，可用作子域名，为空时由显示名称生成 Project Name, usable as a subdomain and derived from the display name when empty",
+	"handlers.CreateProjectReq.OwnerID":               "项目拥有者ID Project Owner ID",
+	"handlers.CreateProjectReq.OwnerType":             "项目拥有者类型 Project Owner Type",
+	"handlers.CreateProjectReq.Visibility":            "项目可见性 Project Visibility",
+	"handlers.CreateReleaseReq.Async":                 "在后台任务中解压发布，立即返回任务 Unpack and publish in a background job, returning the job right away",
+	"handlers.CreateReleaseReq.Changelog":             "版本的变更说明，显示在部署订阅源中 Changelog of the version, shown in the deployment feeds",
+	"handlers.CreateReleaseReq.Checksums":             "文件 SHA-256 清单，路径到哈希的 JSON 对象或 sha256sum 的输出，解压后逐个校验 SHA-256 list of the files, a JSON object mapping paths to hashes or sha256sum output, each verified after extraction",
+	"handlers.CreateReleaseReq.ExpireAction":          "到期处理方式 rollback 或 offline，默认 rollback Expiry action, rollback or offline, rollback by default",
+	"handlers.CreateReleaseReq.ExpiresAt":             "RFC 3339 到期时间 RFC 3339 expiry time",
+	"handlers.CreateReleaseReq.Preview":               "分支或合并请求标识，填写时作为预览部署 Branch or pull request identifier, makes it a preview deployment",
+	"handlers.CreateReleaseReq.PublishAt":             "RFC 3339 定时上线时间，到时才切换站点 RFC 3339 go-live time, the site switches only then",
+	"handlers.CreateReleaseReq.TTL":                   "预览有效期，单位秒，0 为默认值 Preview lifetime in seconds, 0 for the default",
+	"handlers.CreateRunnerReq.Name":                   "执行器名称 Runner name",
+	"handlers.CreateSiteReq.CacheRules":               "按路径的 Cache-Control，格式为 \"<路径模式> <值>\" Cache-Control by path, formatted as \"<pattern> <value>\"",
+	"handlers.CreateSiteReq.Description":              "网站描述 WebSiteDescription",
+	"handlers.CreateSiteReq.Domains":                  "域名 Domains",
+	"handlers.CreateSiteReq.Headers":                  "自定义响应头，格式为 \"Name: value\" Custom response headers, formatted as \"Name: value\"",
+	"handlers.CreateSiteReq.Name":                     "网站名称 WebSiteName",
+	"handlers.CreateSiteReq.OGPreview":                "是否生成预览图 Whether to generate preview images",
+	"handlers.CreateSiteReq.SubDomain":                "子域名 SubDomain",
+	"handlers.CreateUploadReq.Changelog":              "版本的变更说明，显示在部署订阅源中 Changelog of the version, shown in the deployment feeds",
+	"handlers.CreateUploadReq.Checksums":              "路径到文件 SHA-256 的清单，拼接解压后逐个校验 Map of paths to file SHA-256, each verified once assembled and extracted",
+	"handlers.CreateUploadReq.ExpireAction":           "到期处理方式 Expiry action",
+	"handlers.CreateUploadReq.ExpiresAt":              "RFC 3339 到期时间 RFC 3339 expiry time",
+	"handlers.CreateUploadReq.Preview":                "分支或合并请求标识，填写时作为预览部署 Branch or pull request identifier, makes it a preview deployment",
+	"handlers.CreateUploadReq.PublishAt":              "RFC 3339 定时上线时间 RFC 3339 go-live time",
+	"handlers.CreateUploadReq.SHA256":                 "压缩包的 SHA-256 十六进制值 Hex SHA-256 of the archive",
+	"handlers.CreateUploadReq.Size":                   "压缩包总字节数 Total size of the archive in bytes",
+	"handlers.CreateUploadReq.TTL":                    "预览有效期，单位秒，0 为默认值 Preview lifetime in seconds, 0 for the default",
+	"handlers.CreateUploadReq.Tag":                    "版本标签 Version tag",
+	"handlers.CronTaskDTO.Description":                "任务说明 Task description",
+	"handlers.CronTaskDTO.Enabled":                    "是否按表达式运行 Whether it runs by its expression",
+	"handlers.CronTaskDTO.LastError":                  "最近一次的错误 Error of the latest run",
+	"handlers.CronTaskDTO.LastFinishedAt":             "最近一次结束的时间 End of the latest run",
+	"handlers.CronTaskDTO.LastResult":                 "最近一次的结果详情 Details of the latest run",
+	"handlers.CronTaskDTO.LastRunAt":                  "最近一次开始运行的时间 Start of the latest run",
+	"handlers.CronTaskDTO.LastStatus":                 "最近一次的结果 Outcome of the latest run",
+	"handlers.CronTaskDTO.LastTrigger":                "最近一次的触发方式 How the latest run was triggered",
+	"handlers.CronTaskDTO.Name":                       "任务名称 Task name",
+	"handlers.CronTaskDTO.NextRunAt":                  "下次运行时间 Next run time",
+	"handlers.CronTaskDTO.Running":                    "是否正在运行 Whether it is running",
+	"handlers.CronTaskDTO.RunningSince":               "正在运行时的开始时间 Start of the run in progress",
+	"handlers.CronTaskDTO.Schedule":                   "cron 表达式，为空时禁用 Cron expression, empty when disabled",
+	"handlers.CustomDomainDTO.ChallengeURL":           "HTTP 验证时请求的地址 URL requested by HTTP verification",
+	"handlers.CustomDomainDTO.Domain":                 "域名 Domain",
+	"handlers.CustomDomainDTO.ID":                     "域名ID Domain ID",
+	"handlers.CustomDomainDTO.LastCheckedAt":          "最后一次验证时间 Last verification attempt",
+	"handlers.CustomDomainDTO.LastError":              "最后一次验证失败的原因 Reason of the last failed verification",
+	"handlers.CustomDomainDTO.Method":                 "验证方式 Verification method",
+	"handlers.CustomDomainDTO.RecordName":             "DNS 验证需要创建的 TXT 记录名 TXT record name for DNS verification",
+	"handlers.CustomDomainDTO.RecordValue":            "DNS 验证需要创建的 TXT 记录值 TXT record value for DNS verification",
+	"handlers.CustomDomainDTO.Verified":               "是否已验证 Whether it is verified",
+	"handlers.CustomDomainDTO.VerifiedAt":             "验证通过时间 Verified time",
+	"handlers.DailyCountDTO.Count":                    "数量 Count",
+	"handlers.DailyCountDTO.Day":                      "UTC 日期 UTC date",
+	"handlers.DatabaseStatsDTO.Driver":                "数据库驱动 Database driver",
+	"handlers.DatabaseStatsDTO.Error":                 "检查失败的原因 Why the check failed",
+	"handlers.DatabaseStatsDTO.Healthy":               "是否可以连接 Whether it is reachable",
+	"handlers.DatabaseStatsDTO.Idle":                  "空闲连接 Idle connections",
+	"handlers.DatabaseStatsDTO.InUse":                 "使用中的连接 Connections in use",
+	"handlers.DatabaseStatsDTO.LatestVersion":         "程序支持的最新迁移版本 Latest migration version of the binary",
+	"handlers.DatabaseStatsDTO.MaxOpen":               "最大连接数，0 为不限制 Max open connections, 0 for unlimited",
+	"handlers.DatabaseStatsDTO.PingMs":                "往返耗时（毫秒） Round trip in milliseconds",
+	"handlers.DatabaseStatsDTO.SchemaVersion":         "当前迁移版本 Current migration version",
+	"handlers.DatabaseStatsDTO.SizeBytes":             "数据库大小 Database size",
+	"handlers.DatabaseStatsDTO.WaitCount":             "因连接池耗尽而等待的次数 Waits for an exhausted pool",
+	"handlers.DatabaseStatsDTO.WaitDurationMs":        "等待连接的总时长（毫秒） Total wait in milliseconds",
+	"handlers.DebugResolveReq.Host":                   "请求主机名，默认为站点规范主机名 Request host, defaults to the site's canonical host",
+	"handlers.DebugResolveReq.IP":                     "客户端地址，为空时不检查 IP 规则 Client address, IP rules are not checked when empty",
+	"handlers.DebugResolveReq.Path":                   "请求路径 Request path",
+	"handlers.DeleteAccountReq.Confirm":               "用户名，用于确认 Username, for confirmation",
+	"handlers.DeleteAccountReq.DeleteProjects":        "确认个人项目随账号移入回收站 Confirms the personal projects go to the trash with the account",
+	"handlers.DeleteAccountReq.Password":              "账号设置了密码时必填 Required when the account has a password",
+	"handlers.DeployKeyDTO.CreatedAt":                 "创建时间 Created time",
+	"handlers.DeployKeyDTO.CreatedByID":               "创建者ID，密钥以创建者的身份执行 Creator ID, the key acts as its creator",
+	"handlers.DeployKeyDTO.ExpiresAt":                 "过期时间 Expiry time",
+	"handlers.DeployKeyDTO.ID":                        "密钥ID Key ID",
+	"handlers.DeployKeyDTO.LastUsedAt":                "最后使用时间 Last used time",
+	"handlers.DeployKeyDTO.Name":                      "密钥名称 Key name",
+	"handlers.DeployKeyDTO.Prefix":                    "密钥开头部分 Leading part of the key",
+	"handlers.DiffDeploymentsReq.From":                "基准版本ID Base version ID",
+	"handlers.DiffDeploymentsReq.To":                  "比较的版本ID，默认为当前版本 Version compared, the active one by default",
+	"handlers.DomainExport.Domain":                    "域名 Domain",
+	"handlers.DomainExport.Method":                    "验证方式 Verification method",
+	"handlers.DomainExport.Verified":                  "导出时是否已验证 Whether it was verified at export time",
+	"handlers.DraftDTO.Files":                         "文件数 Number of files",
+	"handlers.DraftDTO.MaxSize":                       "最大字节数 Largest size in bytes",
+	"handlers.DraftDTO.Size":                          "总字节数 Total size in bytes",
+	"handlers.DraftDTO.URL":                           "WebDAV 地址，用户名任意，密码为个人访问令牌或部署密钥 WebDAV URL, any user name with a personal access token or deploy key as the password",
+	"handlers.DraftDTO.UpdatedAt":                     "最近修改时间 Last modified time",
+	"handlers.ExportProjectReq.ReleaseID":             "导出的版本，需同时指定站点 Exported version, requires site_id",
+	"handlers.ExportProjectReq.SiteID":                "只导出该站点 Only export this site",
+	"handlers.ForgotPasswordReq.CaptchaToken":         "验证码 Token，配置了验证码时必填 Captcha token, required when a captcha is configured",
+	"handlers.ForgotPasswordReq.Email":                "账号邮箱 Account email",
+	"handlers.GCReq.DryRun":                           "只统计可回收的对象和字节数，不删除 Only count the collectable objects and bytes without deleting them",
+	"handlers.GetSiteListReq.Limit":                   "每页数量 Page Limit",
+	"handlers.GetSiteListReq.Page":                    "页码 Page",
+	"handlers.GetSiteListReq.Project":                 "项目名称 Project Name",
+	"handlers.GetSiteListReq.SiteName":                "站点名称 Site Name",
+	"handlers.GitExport.Branch":                       "部署的分支 Deployed branch",
+	"handlers.GitExport.BuildCommand":                 "构建命令 Build command",
+	"handlers.GitExport.BuildImage":                   "构建镜像 Build image",
+	"handlers.GitExport.BuildPreset":                  "构建预设 Build preset",
+	"handlers.GitExport.OutputDir":                    "输出目录 Output directory",
+	"handlers.GitExport.Provider":                     "代码托管平台 Git hosting provider",
+	"handlers.GitExport.RepoURL":                      "仓库地址 Repository URL",
+	"handlers.GitExport.RunnerLabels":                 "远程执行器必须具有的标签 Labels a remote runner must have",
+	"handlers.GitIntegrationDTO.Branch":               "部署的分支 Deployed branch",
+	"handlers.GitIntegrationDTO.BuildCommand":         "构建命令 Build command",
+	"handlers.GitIntegrationDTO.BuildImage":           "构建镜像 Build image",
+	"handlers.GitIntegrationDTO.BuildPreset":          "构建预设 Build preset",
+	"handlers.GitIntegrationDTO.CreatedAt":            "创建时间 Created time",
+	"handlers.GitIntegrationDTO.HasToken":             "是否设置了访问令牌 Whether an access token is set",
+	"handlers.GitIntegrationDTO.HookURL":              "在托管平台填写的 webhook 地址 Webhook URL to configure on the provider",
+	"handlers.GitIntegrationDTO.ID":                   "绑定ID Binding ID",
+	"handlers.GitIntegrationDTO.LastCommit":           "最近一次推送的提交 Commit of the latest push",
+	"handlers.GitIntegrationDTO.LastError":            "最近一次运行的错误 Error of the latest run",
+	"handlers.GitIntegrationDTO.LastLog":              "最近一次构建的输出 Output of the latest build",
+	"handlers.GitIntegrationDTO.LastRunAt":            "最近一次运行的时间 Time of the latest run",
+	"handlers.GitIntegrationDTO.LastStatus":           "最近一次运行的状态 Status of the latest run",
+	"handlers.GitIntegrationDTO.OutputDir":            "输出目录 Output directory",
+	"handlers.GitIntegrationDTO.Provider":             "代码托管平台 Git hosting provider",
+	"handlers.GitIntegrationDTO.RepoURL":              "仓库地址 Repository URL",
+	"handlers.GitIntegrationDTO.RunnerLabels":         "远程执行器必须具有的标签 Labels a remote runner must have",
+	"handlers.GitIntegrationDTO.Secret":               "推送事件的签名密钥或令牌 Signing secret or token of push events",
+	"handlers.GitIntegrationReq.Branch":               "部署的分支，默认 main Deployed branch, main by default",
+	"handlers.GitIntegrationReq.BuildCommand":         "构建命令，需要实例开启 git.build-enable Build command, requires git.build-enable",
+	"handlers.GitIntegrationReq.BuildImage":           "构建镜像，替换沙箱前缀中的 {image} Build image, replacing {image} in the sandbox prefix",
+	"handlers.GitIntegrationReq.BuildPreset":          "构建预设 hugo、mkdocs 或 vite，填充未指定的命令、输出目录和镜像 Build preset hugo, mkdocs or vite, filling in the command, output directory and image left empty",
+	"handlers.GitIntegrationReq.OutputDir":            "发布的输出目录，默认仓库根目录 Published output directory, the repository root by default",
+	"handlers.GitIntegrationReq.Provider":             "代码托管平台 github 或 gitlab Git hosting provider, github or gitlab",
+	"handlers.GitIntegrationReq.RepoURL":              "仓库 https 地址 Repository https URL",
+	"handlers.GitIntegrationReq.RunnerLabels":         "开启 git.runners 时执行器必须具有的标签 Labels a runner must have when git.runners is enabled",
+	"handlers.GitIntegrationReq.Secret":               "推送密钥，创建时为空则自动生成 Push secret, generated when empty on creation",
+	"handlers.GitIntegrationReq.Token":                "拉取私有仓库的访问令牌 Access token for private repositories",
+	"handlers.GitLogDTO.Text":                         "新增的输出，reset 事件中为全部输出 Output added, the whole output in reset events",
+	"handlers.GitRunDTO.Commit":                       "构建的提交 Built commit",
+	"handlers.GitRunDTO.Error":                        "失败原因 Failure reason",
+	"handlers.GitRunDTO.Status":                       "运行状态 Run status",
+	"handlers.GraphQLReq.OperationName":               "文档包含多个操作时要执行的操作 Operation to run when the document has several",
+	"handlers.GraphQLReq.Query":                       "查询文档 Query document",
+	"handlers.GraphQLReq.Variables":                   "查询变量 Query variables",
+	"handlers.HealthCheckDTO.Error":                   "失败原因 Failure reason",
+	"handlers.HealthCheckDTO.LatencyMs":               "检查耗时（毫秒） Check latency in milliseconds",
+	"handlers.HealthCheckDTO.Latest":                  "最新迁移版本 Latest migration version",
+	"handlers.HealthCheckDTO.Status":                  "ok 或 fail ok or fail",
+	"handlers.HealthCheckDTO.Version":                 "当前迁移版本 Current migration version",
+	"handlers.HealthDTO.Checks":                       "各项依赖的检查结果 Results of the dependency checks",
+	"handlers.HealthDTO.Status":                       "ok 或 fail ok or fail",
+	"handlers.IdentityDTO.CreatedAt":                  "关联时间 Linked time",
+	"handlers.IdentityDTO.Email":                      "提供方邮箱 Email at the provider",
+	"handlers.IdentityDTO.ID":                         "身份ID Identity ID",
+	"handlers.IdentityDTO.Provider":                   "提供方 Provider",
+	"handlers.ImpersonateReq.Duration":                "会话有效期，单位秒，默认且最长为配置的上限 Session lifetime in seconds, the configured maximum by default and at most",
+	"handlers.ImpersonateReq.Reason":                  "代为登录的原因，记入审计日志 Reason for the impersonation, recorded in the audit log",
+	"handlers.ImpersonateReq.Scopes":                  "会话的权限范围，默认只读，不能包含 admin Scopes of the session, read-only by default and never admin",
+	"handlers.ImpersonationDTO.ExpiresAt":             "会话过期时间 Session expiry time",
+	"handlers.ImpersonationDTO.RefreshToken":          "刷新令牌 Refresh token",
+	"handlers.ImpersonationDTO.Scopes":                "权限范围 Scopes",
+	"handlers.ImpersonationDTO.SessionID":             "会话ID Session ID",
+	"handlers.ImpersonationDTO.Token":                 "访问令牌 Access token",
+	"handlers.ImportProjectReq.Branch":                "部署的分支，默认 gh-pages Deployed branch, gh-pages by default",
+	"handlers.ImportProjectReq.BuildCommand":          "构建命令，需要实例开启 git.build-enable Build command, requires git.build-enable",
+	"handlers.ImportProjectReq.BuildImage":            "构建镜像 Build image",
+	"handlers.ImportProjectReq.BuildPreset":           "构建预设 hugo、mkdocs 或 vite Build preset hugo, mkdocs or vite",
+	"handlers.ImportProjectReq.Description":           "项目描述 Project Description",
+	"handlers.ImportProjectReq.DisplayName":           "项目显示名称 Project Display Name",
+	"handlers.ImportProjectReq.File":                  "GitHub Pages 构建产物或 Netlify 导出的 zip、tar、tar.gz GitHub Pages artifact or Netlify export as zip, tar or tar.gz",
+	"handlers.ImportProjectReq.Name":                  "项目名称，为空时由显示名称生成 Project Name, derived from the display name when empty",
+	"handlers.ImportProjectReq.OutputDir":             "发布的输出目录 Published output directory",
+	"handlers.ImportProjectReq.OwnerID":               "项目拥有者ID，拥有者为组织时必填 Project Owner ID, required for organizations",
+	"handlers.ImportProjectReq.OwnerType":             "项目拥有者类型 Project Owner Type",
+	"handlers.ImportProjectReq.Provider":              "代码托管平台，默认按仓库地址判断 Git hosting provider, inferred from the repository URL by default",
+	"handlers.ImportProjectReq.RepoURL":               "仓库 https 地址，导入后绑定该仓库 Repository https URL, bound to the site after importing",
+	"handlers.ImportProjectReq.SiteName":              "站点名称，默认与项目名称相同 Site name, the project name by default",
+	"handlers.ImportProjectReq.SubDomain":             "站点子域名 Site subdomain",
+	"handlers.ImportProjectReq.Token":                 "拉取私有仓库的访问令牌 Access token for private repositories",
+	"handlers.ImportProjectReq.Visibility":            "项目可见性 Project Visibility",
+	"handlers.InvitationDTO.CreatedAt":                "创建时间 Created time",
+	"handlers.InvitationDTO.CreatedBy":                "创建者用户名 Creator username",
+	"handlers.InvitationDTO.Email":                    "限定的邮箱 Restricted email",
+	"handlers.InvitationDTO.ExpiresAt":                "过期时间 Expiry time",
+	"handlers.InvitationDTO.ID":                       "邀请ID Invitation ID",
+	"handlers.InvitationDTO.MaxUses":                  "可使用次数 Allowed uses",
+	"handlers.InvitationDTO.Organization":             "加入的组织 Organization to join",
+	"handlers.InvitationDTO.Prefix":                   "令牌开头部分 Leading part of the token",
+	"handlers.InvitationDTO.Role":                     "加入组织后的角色 Role after joining",
+	"handlers.InvitationDTO.Uses":                     "已使用次数 Uses so far",
+	"handlers.InvitationTokenReq.Token":               "邀请令牌 Invitation token",
+	"handlers.JobDTO.Attempts":                        "已尝试次数 Attempts made",
+	"handlers.JobDTO.CreatedAt":                       "创建时间 Created time",
+	"handlers.JobDTO.Error":                           "最近一次的错误 Error of the latest attempt",
+	"handlers.JobDTO.FinishedAt":                      "结束时间 Finished time",
+	"handlers.JobDTO.ID":                              "任务ID Job ID",
+	"handlers.JobDTO.MaxAttempts":                     "最大尝试次数 Max attempts",
+	"handlers.JobDTO.Payload":                         "任务参数 Job parameters",
+	"handlers.JobDTO.Progress":                        "当前阶段已完成的量 Amount of the current stage done",
+	"handlers.JobDTO.ProgressTotal":                   "当前阶段的总量，未知时为 0 Total amount of the current stage, 0 when unknown",
+	"handlers.JobDTO.Result":                          "执行结果 Outcome",
+	"handlers.JobDTO.RunAt":                           "下次执行时间，等待执行时返回 Next run time, returned while pending",
+	"handlers.JobDTO.SiteID":                          "关联的站点ID Related site ID",
+	"handlers.JobDTO.Stage":                           "执行中的阶段 Stage of the running attempt",
+	"handlers.JobDTO.StartedAt":                       "最近一次开始执行的时间 Start of the latest attempt",
+	"handlers.JobDTO.Status":                          "任务状态 Job status",
+	"handlers.JobDTO.Type":                            "任务类型 Job type",
+	"handlers.JobDTO.UserID":                          "创建任务的用户ID ID of the user creating the job",
+	"handlers.JobProgressDTO.Attempts":                "已尝试次数 Attempts made",
+	"handlers.JobProgressDTO.Progress":                "当前阶段已完成的量 Amount of the current stage done",
+	"handlers.JobProgressDTO.RunAt":                   "等待执行时的下次执行时间 Next run time while pending",
+	"handlers.JobProgressDTO.Stage":                   "执行中的阶段 Stage of the running attempt",
+	"handlers.JobProgressDTO.Status":                  "任务状态 Job status",
+	"handlers.JobProgressDTO.Total":                   "当前阶段的总量，未知时为 0 Total amount of the current stage, 0 when unknown",
+	"handlers.LDAPLinkReq.Password":                   "目录密码 Directory password",
+	"handlers.LDAPLinkReq.Username":                   "目录登录名 Directory login name",
+	"handlers.LoginReq.CaptchaToken":                  "验证码 Token，连续失败过多或 login.captcha-after 为 0 时必填 Captcha token, required after too many failures or when login.captcha-after is 0",
+	"handlers.LoginReq.Password":                      "密码 Password",
+	"handlers.LoginReq.Username":                      "用户名 Username",
+	"handlers.ManifestDeployReq.Changelog":            "版本的变更说明，显示在部署订阅源中 Changelog of the version, shown in the deployment feeds",
+	"handlers.ManifestDeployReq.ExpireAction":         "到期处理方式 Expiry action",
+	"handlers.ManifestDeployReq.ExpiresAt":            "RFC 3339 到期时间 RFC 3339 expiry time",
+	"handlers.ManifestDeployReq.Files":                "站点的全部文件 Every file of the site",
+	"handlers.ManifestDeployReq.Preview":              "分支或合并请求标识，填写时作为预览部署 Branch or pull request identifier, makes it a preview deployment",
+	"handlers.ManifestDeployReq.PublishAt":            "RFC 3339 定时上线时间 RFC 3339 go-live time",
+	"handlers.ManifestDeployReq.TTL":                  "预览有效期，单位秒，0 为默认值 Preview lifetime in seconds, 0 for the default",
+	"handlers.ManifestDeployReq.Tag":                  "版本标签 Version tag",
+	"handlers.MemberDTO.Role":                         "角色 Role",
+	"handlers.MemberDTO.User":                         "用户 User",
+	"handlers.MissingBlobsReq.Hashes":                 "文件内容的 SHA-256 列表 SHA-256 list of file contents",
+	"handlers.NotificationChannelDTO.Active":          "是否启用 Whether enabled",
+	"handlers.NotificationChannelDTO.CreatedAt":       "创建时间 Created time",
+	"handlers.NotificationChannelDTO.Events":          "订阅的事件，为空时订阅失败和警告 Subscribed events, failures and warnings when empty",
+	"handlers.NotificationChannelDTO.HasSecret":       "是否设置了密钥 Whether a secret is set",
+	"handlers.NotificationChannelDTO.ID":              "渠道ID Channel ID",
+	"handlers.NotificationChannelDTO.Kind":            "渠道类型 Channel kind",
+	"handlers.NotificationChannelDTO.Name":            "名称 Name",
+	"handlers.NotificationChannelDTO.Target":          "邮箱地址、webhook 地址或聊天ID Email address, webhook URL or chat ID",
+	"handlers.NotificationChannelReq.Active":          "是否启用，默认启用 Whether enabled, enabled by default",
+	"handlers.NotificationChannelReq.Events":          "订阅的事件 Subscribed events",
+	"handlers.NotificationChannelReq.Kind":            "渠道类型：email、slack、telegram 或 feishu Channel kind: email, slack, telegram or feishu",
+	"handlers.NotificationChannelReq.Name":            "名称 Name",
+	"handlers.NotificationChannelReq.Secret":          "飞书签名密钥或 Telegram 机器人令牌 Feishu signing secret or Telegram bot token",
+	"handlers.NotificationChannelReq.Target":          "邮箱地址、webhook 地址或 Telegram 聊天ID Email address, webhook URL or Telegram chat ID",
+	"handlers.NotificationDeliveryDTO.Attempts":       "已尝试次数 Attempts made",
+	"handlers.NotificationDeliveryDTO.CreatedAt":      "创建时间 Created time",
+	"handlers.NotificationDeliveryDTO.DeliveredAt":    "投递成功时间 Time of successful delivery",
+	"handlers.NotificationDeliveryDTO.Error":          "最近一次的错误 Error of the latest attempt",
+	"handlers.NotificationDeliveryDTO.Event":          "事件 Event",
+	"handlers.NotificationDeliveryDTO.ID":             "投递ID Delivery ID",
+	"handlers.NotificationDeliveryDTO.NextAttemptAt":  "下次尝试时间，已结束时为空 Next attempt time, empty once finished",
+	"handlers.NotificationDeliveryDTO.ResponseBody":   "最近一次的响应体 Body of the latest response",
+	"handlers.NotificationDeliveryDTO.ResponseStatus": "最近一次的响应状态码 Status code of the latest response",
+	"handlers.NotificationDeliveryDTO.Status":         "投递状态 Delivery status",
+	"handlers.NotificationDeliveryDTO.Subject":        "标题 Subject",
+	"handlers.NotificationDeliveryDTO.Text":           "正文 Text",
+	"handlers.OrgUserReq.Role":                        "角色 Role",
+	"handlers.OrgUserReq.UserID":                      "用户ID User ID",
+	"handlers.OrganizationDTO.AvatarURL":              "头像URL Avatar URL",
+	"handlers.OrganizationDTO.Description":            "描述信息 Description",
+	"handlers.OrganizationDTO.DisplayName":            "显示名称 Display Name",
+	"handlers.OrganizationDTO.Email":                  "邮箱地址 Email Address",
+	"handlers.OrganizationDTO.ID":                     "组织ID Organization ID",
+	"handlers.OrganizationDTO.Name":                   "组织名称 Organization Name",
+	"handlers.OrganizationDTO.ProjectLimit":           "项目数量限制 Project Limit",
+	"handlers.OrganizationDTO.Role":                   "当前用户在组织中的角色 Current user's role in the organization",
+	"handlers.PreviewDTO.Host":                        "预览访问地址 Host serving the preview",
+	"handlers.ProjectDTO.Description":                 "项目描述 Project Description",
+	"handlers.ProjectDTO.DirectoryListing":            "没有 index.html 的目录是否显示目录索引 Whether directories without index.html show a directory index",
+	"handlers.ProjectDTO.DisplayName":                 "项目显示名称 Project Display Name",
+	"handlers.ProjectDTO.Host":                        "项目主机名，未配置 serve.project-domain 时为空 Project host, empty without serve.project-domain",
+	"handlers.ProjectDTO.ID":                          "项目ID Project ID",
+	"handlers.ProjectDTO.ImmutableAssets":             "文件名带内容哈希的资源是否长期缓存 Whether assets with a content hash in their file name are cached for long",
+	"handlers.ProjectDTO.Labels":                      "项目标签 Project labels",
+	"handlers.ProjectDTO.MaxUploadSize":               "管理员为项目设置的单次上传最大字节数 Largest single upload in bytes set by an administrator",
+	"handlers.ProjectDTO.Name":                        "项目名称 Project Name",
+	"handlers.ProjectDTO.OwnerID":                     "项目拥有者ID Project Owner ID",
+	"handlers.ProjectDTO.OwnerType":                   "项目拥有者类型 Project Owner Type",
+	"handlers.ProjectDTO.PrimarySiteID":               "通过项目主机名访问的站点，为空时使用最早创建的站点 Site served at the project host, the oldest site when empty",
+	"handlers.ProjectDTO.Protected":                   "新部署是否需要维护者批准后才能上线 Whether new deployments only go live once a maintainer approves them",
+	"handlers.ProjectDTO.Role":                        "当前用户在项目中的角色 Current user's role in the project",
+	"handlers.ProjectDTO.SiteLimit":                   "项目站点数量限制 Project Site Limit",
+	"handlers.ProjectDTO.Visibility":                  "项目可见性 Project Visibility",
+	"handlers.ProjectExport.Description":              "项目描述 Project Description",
+	"handlers.ProjectExport.DisplayName":              "项目显示名称 Project Display Name",
+	"handlers.ProjectExport.ExportedAt":               "导出时间 Export time",
+	"handlers.ProjectExport.Labels":                   "项目标签 Project labels",
+	"handlers.ProjectExport.Name":                     "项目名称 Project Name",
+	"handlers.ProjectExport.Sites":                    "导出的站点 Exported sites",
+	"handlers.ProjectExport.Version":                  "导出格式版本 Export format version",
+	"handlers.ProjectExport.Visibility":               "项目可见性 Project Visibility",
+	"handlers.ProjectTeamDTO.Role":                    "项目角色 Project role",
+	"handlers.ProjectTeamDTO.Team":                    "团队 Team",
+	"handlers.ProjectTeamReq.Role":                    "授予团队成员的项目角色 Project role granted to the team's members",
+	"handlers.ProjectTeamReq.TeamID":                  "团队ID Team ID",
+	"handlers.ProjectUploadLimitReq.MaxUploadSize":    "单次上传的最大字节数 Largest single upload in bytes",
+	"handlers.ProjectUserReq.Role":                    "项目角色 Project role",
+	"handlers.ProjectUserReq.UserID":                  "用户ID User ID",
+	"handlers.PublishDraftReq.Changelog":              "版本的变更说明，显示在部署订阅源中 Changelog of the version, shown in the deployment feeds",
+	"handlers.PublishDraftReq.Discard":                "发布后丢弃草稿，默认保留以便继续编辑 Discard the draft once published, it is kept for further edits by default",
+	"handlers.PublishDraftReq.Tag":                    "版本标签 Version tag",
+	"handlers.PurgePathsReq.Confirm":                  "批量前缀清除时需填写站点名称确认 Site name confirmation required for prefix purges",
+	"handlers.PurgePathsReq.Paths":                    "要清除的路径，可重复，前缀形式为 /dir/* Paths to purge, repeatable, prefix form is /dir/*",
+	"handlers.PurgePathsReq.Takedown":                 "是否下架，下架后历史发布也无法访问 Whether it is a takedown, blocking historic releases too",
+	"handlers.QuarantineRejectReq.Reason":             "拒绝原因，随 webhook 通知项目 Reason of the rejection, sent to the project's webhooks",
+	"handlers.QuotaDTO.Custom":                        "是否为管理员单独设置的配额 Whether an administrator set this quota individually",
+	"handlers.QuotaDTO.MaxDeploymentSize":             "单次部署解压后的最大字节数 Largest uncompressed size of one deployment in bytes",
+	"handlers.QuotaDTO.MaxProjects":                   "可创建的项目数量 Number of projects that may be created",
+	"handlers.QuotaDTO.MaxStorage":                    "可占用的存储字节数 Storage that may be occupied in bytes",
+	"handlers.QuotaDTO.MaxUploadSize":                 "单次上传的最大字节数 Largest single upload in bytes",
+	"handlers.QuotaDTO.OwnerID":                       "所有者ID Owner ID",
+	"handlers.QuotaDTO.OwnerType":                     "所有者类型 Owner type",
+	"handlers.QuotaDTO.Projects":                      "已创建的项目数量 Number of projects created",
+	"handlers.QuotaDTO.StorageUsed":                   "已占用的存储字节数 Storage occupied in bytes",
+	"handlers.QuotaReq.MaxDeploymentSize":             "单次部署解压后的最大字节数 Largest uncompressed size of one deployment in bytes",
+	"handlers.QuotaReq.MaxProjects":                   "可创建的项目数量 Number of projects that may be created",
+	"handlers.QuotaReq.MaxStorage":                    "可占用的存储字节数 Storage that may be occupied in bytes",
+	"handlers.QuotaReq.MaxUploadSize":                 "单次上传的最大字节数 Largest single upload in bytes",
+	"handlers.RefreshTokenReq.RefreshToken":           "刷新令牌 Refresh token",
+	"handlers.RegisterReq.CaptchaToken":               "验证码 Token，配置了验证码时必填 Captcha token, required when a captcha is configured",
+	"handlers.RegisterReq.Email":                      "邮箱 Email",
+	"handlers.RegisterReq.InviteToken":                "邀请令牌，只允许邀请注册时必填 Invitation token, required when sign-up is by invitation only",
+	"handlers.RegisterReq.Password":                   "密码 Password",
+	"handlers.RegisterReq.Username":                   "用户名 Username",
+	"handlers.RegisterRunnerReq.Concurrency":          "同时执行的构建数量 Number of builds run at the same time",
+	"handlers.RegisterRunnerReq.Labels":               "执行器的标签 Labels of the runner",
+	"handlers.RegisterRunnerReq.Version":              "执行器的版本 Version of the runner",
+	"handlers.ReleaseDTO.Active":                      "是否为当前激活的版本 Whether it is the active version",
+	"handlers.ReleaseDTO.Changelog":                   "上传时提供的变更说明 Changelog given with the upload",
+	"handlers.ReleaseDTO.ExpireAction":                "到期处理方式 Expiry action",
+	"handlers.ReleaseDTO.ExpiresAt":                   "到期时间 Expiry time",
+	"handlers.ReleaseDTO.Pinned":                      "是否固定，固定的版本不会被保留策略删除 Whether pinned, pinned versions are never removed by the retention policy",
+	"handlers.ReleaseDTO.PublishAt":                   "定时上线时间 Scheduled go-live time",
+	"handlers.ReleaseDTO.ScanFindings":                "内容扫描发现的恶意文件 Malicious files found by the content scan",
+	"handlers.ReleaseDTO.VerifiedFiles":               "上传时提供哈希并校验通过的文件数 Number of files verified against the hashes given with the upload",
+	"handlers.ReleaseExport.CreatedAt":                "部署时间 Deployed time",
+	"handlers.ReleaseExport.ID":                       "版本ID Version ID",
+	"handlers.ReleaseExport.Path":                     "内容在导出包中的目录 Directory of the content in the export",
+	"handlers.ReleaseExport.Redirects":                "根目录 _redirects 中的规则 Rules of _redirects at the root",
+	"handlers.ReleaseExport.Tag":                      "版本标签 Version tag",
+	"handlers.ResetPasswordReq.Password":              "新密码 New password",
+	"handlers.ResetPasswordReq.Token":                 "邮件中的令牌 Token from the email",
+	"handlers.RetentionDTO.EffectiveDays":             "生效的保留天数，0 不按时间保留 Days kept in effect, 0 keeps none by age",
+	"handlers.RetentionDTO.EffectiveKeep":             "生效的保留版本数，0 不按数量保留 Versions kept in effect, 0 keeps none by count",
+	"handlers.RetentionDTO.KeepReleaseDays":           "项目设置的保留天数 Days kept as set on the project",
+	"handlers.RetentionDTO.KeepReleases":              "项目设置的保留版本数 Versions kept as set on the project",
+	"handlers.RetentionDTO.ProjectID":                 "项目ID Project ID",
+	"handlers.RetentionReq.KeepReleaseDays":           "保留最近天数内的版本 Versions of the last days kept",
+	"handlers.RetentionReq.KeepReleases":              "保留最新的版本数 Newest versions kept",
+	"handlers.RollbackReq.ReleaseID":                  "目标版本ID Target version ID",
+	"handlers.RunnerBuildDTO.Branch":                  "分支 Branch",
+	"handlers.RunnerBuildDTO.BuildCommand":            "构建命令 Build command",
+	"handlers.RunnerBuildDTO.BuildImage":              "构建镜像 Build image",
+	"handlers.RunnerBuildDTO.Commit":                  "提交，为空时构建分支的最新提交 Commit, the head of the branch when empty",
+	"handlers.RunnerBuildDTO.ID":                      "构建ID Build ID",
+	"handlers.RunnerBuildDTO.OutputDir":               "输出目录 Output directory",
+	"handlers.RunnerBuildDTO.Provider":                "代码托管平台 Git hosting provider",
+	"handlers.RunnerBuildDTO.RepoURL":                 "仓库地址 Repository URL",
+	"handlers.RunnerBuildDTO.Token":                   "访问令牌 Access token",
+	"handlers.RunnerDTO.Concurrency":                  "同时执行的构建数量 Number of builds run at the same time",
+	"handlers.RunnerDTO.CreatedAt":                    "创建时间 Created time",
+	"handlers.RunnerDTO.ID":                           "执行器ID Runner ID",
+	"handlers.RunnerDTO.Labels":                       "执行器的标签 Labels of the runner",
+	"handlers.RunnerDTO.LastSeenAt":                   "最后一次注册、心跳或领取构建的时间 Time of the latest registration, heartbeat or claim",
+	"handlers.RunnerDTO.Name":                         "执行器名称 Runner name",
+	"handlers.RunnerDTO.Online":                       "心跳是否未超时 Whether the heartbeat has not timed out",
+	"handlers.RunnerDTO.Prefix":                       "令牌开头部分 Leading part of the token",
+	"handlers.RunnerDTO.Version":                      "执行器的版本 Version of the runner",
+	"handlers.RunnerFailReq.Commit":                   "实际构建的提交，拉取前失败时为空 Commit actually built, empty when failing before the fetch",
+	"handlers.RunnerFailReq.Error":                    "失败原因 Failure reason",
+	"handlers.RunnerFailReq.Log":                      "截断后的输出 Truncated output",
+	"handlers.RunnerLogReq.Log":                       "截断后的输出 Truncated output",
+	"handlers.SeedOptions.Orgs":                       "组织数 Organizations",
+	"handlers.SeedOptions.Password":                   "所有演示用户的密码 Password of every demo user",
+	"handlers.SeedOptions.Posts":                      "每个站点的文章数 Posts per site",
+	"handlers.SeedOptions.Projects":                   "项目数，每个项目一个站点 Projects, each with one site",
+	"handlers.SeedOptions.Releases":                   "每个站点的部署次数 Deployments per site",
+	"handlers.SeedOptions.Seed":                       "随机数种子，相同种子生成相同的名称 Random seed, the same seed generates the same names",
+	"handlers.SeedOptions.Users":                      "用户数 Users",
+	"handlers.SessionDTO.CreatedAt":                   "登录时间 Sign-in time",
+	"handlers.SessionDTO.Current":                     "是否为当前请求的会话 Whether it is the session of the current request",
+	"handlers.SessionDTO.ExpiresAt":                   "过期时间 Expiry time",
+	"handlers.SessionDTO.ID":                          "会话ID Session ID",
+	"handlers.SessionDTO.IP":                          "最近使用的客户端IP Client IP of the last use",
+	"handlers.SessionDTO.Impersonated":                "是否为管理员代为登录的会话 Whether an administrator is impersonating the user in it",
+	"handlers.SessionDTO.LastUsedAt":                  "最近使用时间 Last used time",
+	"handlers.SessionDTO.Method":                      "登录方式 Login method",
+	"handlers.SessionDTO.UserAgent":                   "最近使用的用户代理 User agent of the last use",
+	"handlers.SettingDTO.Default":                     "配置文件中的值 Value of the configuration file",
+	"handlers.SettingDTO.Description":                 "说明 Description",
+	"handlers.SettingDTO.Key":                         "配置项名称 Setting key",
+	"handlers.SettingDTO.Min":                         "整数的最小值 Minimum of integers",
+	"handlers.SettingDTO.Overridden":                  "是否已被管理员覆盖 Whether an administrator overrode it",
+	"handlers.SettingDTO.Type":                        "值类型 int、bool 或 string Value type, int, bool or string",
+	"handlers.SettingDTO.Value":                       "当前值 Current value",
+	"handlers.SettingReq.Value":                       "新值，类型须与配置项一致 New value, must match the type of the setting",
+	"handlers.SignURLReq.ExpiresIn":                   "有效期，单位秒，默认 site-access.signed-url-ttl，最长 site-access.signed-url-max-ttl Lifetime in seconds, site-access.signed-url-ttl by default and at most site-access.signed-url-max-ttl",
+	"handlers.SignURLReq.Path":                        "链接指向的文件路径 File path the URL points to",
+	"handlers.SiteAnalyticsDTO.Countries":             "浏览量最多的国家或地区 Countries with the most views",
+	"handlers.SiteAnalyticsDTO.Daily":                 "每天的浏览量 Page views per day",
+	"handlers.SiteAnalyticsDTO.Days":                  "统计的天数 Days covered",
+	"handlers.SiteAnalyticsDTO.Paths":                 "浏览量最多的路径 Paths with the most views",
+	"handlers.SiteAnalyticsDTO.Referrers":             "浏览量最多的来源主机 Referring hosts with the most views",
+	"handlers.SiteAnalyticsDTO.Statuses":              "各状态码的浏览量 Page views by status code",
+	"handlers.SiteAnalyticsDTO.Transfer":              "本月的流量及其限制 Transfer this month and its limits",
+	"handlers.SiteAnalyticsDTO.Views":                 "总浏览量 Total page views",
+	"handlers.SiteCountDTO.Active":                    "有激活版本的站点 Sites with an active version",
+	"handlers.SiteDTO.AccessMode":                     "访问保护方式 Access protection",
+	"handlers.SiteDTO.AllowIPs":                       "允许访问的地址或 CIDR 网段 Addresses or CIDR ranges allowed to visit",
+	"handlers.SiteDTO.CacheRules":                     "按路径的 Cache-Control Cache-Control by path",
+	"handlers.SiteDTO.DenyIPs":                        "禁止访问的地址或 CIDR 网段 Addresses or CIDR ranges refused",
+	"handlers.SiteDTO.Description":                    "网站描述 WebSiteDescription",
+	"handlers.SiteDTO.Domains":                        "域名 Domains",
+	"handlers.SiteDTO.HasAccessPassword":              "是否设置了访问密码 Whether an access password is set",
+	"handlers.SiteDTO.Headers":                        "自定义响应头 Custom response headers",
+	"handlers.SiteDTO.ID":                             "网站ID WebSiteID",
+	"handlers.SiteDTO.Name":                           "网站名称 WebSiteName",
+	"handlers.SiteDTO.OGPreview":                      "是否生成预览图 Whether to generate preview images",
+	"handlers.SiteDTO.Project":                        "项目详情 ProjectDetail",
+	"handlers.SiteDTO.ProjectID":                      "项目ID ProjectID",
+	"handlers.SiteDTO.RobotsPolicy":                   "robots.txt 策略 Robots policy",
+	"handlers.SiteDTO.RobotsTxt":                      "自定义 robots.txt 内容 Custom robots.txt content",
+	"handlers.SiteDTO.Sitemap":                        "是否生成 sitemap.xml Whether sitemap.xml is generated",
+	"handlers.SiteDTO.SubDomain":                      "子域名 SubDomain",
+	"handlers.SiteExport.AccessMode":                  "访问保护方式，密码不导出 Access protection, the password is not exported",
+	"handlers.SiteExport.AllowIPs":                    "允许访问的地址 Allowed addresses",
+	"handlers.SiteExport.CacheRules":                  "按路径的 Cache-Control Cache-Control by path",
+	"handlers.SiteExport.CustomDomains":               "自定义域名 Custom domains",
+	"handlers.SiteExport.DenyIPs":                     "禁止访问的地址 Refused addresses",
+	"handlers.SiteExport.Description":                 "站点描述 Site description",
+	"handlers.SiteExport.Domains":                     "域名 Domains",
+	"handlers.SiteExport.Git":                         "绑定的仓库 Bound repository",
+	"handlers.SiteExport.Headers":                     "自定义响应头 Custom response headers",
+	"handlers.SiteExport.Name":                        "站点名称 Site name",
+	"handlers.SiteExport.OGPreview":                   "是否生成预览图 Whether to generate preview images",
+	"handlers.SiteExport.Release":                     "导出的版本，站点没有版本时为空 Exported version, empty when the site has none",
+	"handlers.SiteExport.SubDomain":                   "子域名 SubDomain",
+	"handlers.SiteLogsReq.Path":                       "完整路径 Full path",
+	"handlers.SiteLogsReq.PathPrefix":                 "路径前缀 Path prefix",
+	"handlers.SiteLogsReq.Since":                      "起始时间（RFC 3339） Start time (RFC 3339)",
+	"handlers.SiteLogsReq.Status":                     "状态码如 404 或类别如 4xx Status code such as 404 or class such as 4xx",
+	"handlers.SiteLogsReq.Until":                      "结束时间（RFC 3339） End time (RFC 3339)",
+	"handlers.SiteTrafficDTO.Bytes":                   "响应字节数 Response bytes",
+	"handlers.SiteTrafficDTO.Name":                    "站点名称，已彻底删除时为空 Site name, empty once purged",
+	"handlers.SiteTrafficDTO.Requests":                "请求数 Requests",
+	"handlers.SiteTrafficDTO.SiteID":                  "站点ID Site ID",
+	"handlers.SiteTransferDTO.Action":                 "超出硬限制后的处理方式，未超出时为空 Handling beyond the hard limit, empty while within it",
+	"handlers.SiteTransferDTO.Bytes":                  "本月响应字节数 Response bytes this month",
+	"handlers.SiteTransferDTO.Custom":                 "是否由管理员为站点单独设置 Whether an administrator set the limits for the site",
+	"handlers.SiteTransferDTO.HardLimit":              "生效的硬限制字节数，0 为不限制 Effective hard limit in bytes, 0 is unlimited",
+	"handlers.SiteTransferDTO.Month":                  "UTC 月份，格式为 2006-01 UTC month, formatted as 2006-01",
+	"handlers.SiteTransferDTO.Requests":               "本月请求数 Requests this month",
+	"handlers.SiteTransferDTO.SoftLimit":              "生效的软限制字节数，0 为不限制 Effective soft limit in bytes, 0 is unlimited",
+	"handlers.SiteTransferLimitReq.HardLimit":         "硬限制字节数，超出后限速或暂停服务 Hard limit in bytes, throttling or suspending once exceeded",
+	"handlers.SiteTransferLimitReq.SoftLimit":         "软限制字节数，超出时通知 Soft limit in bytes, notifying once exceeded",
+	"handlers.StatsDTO.Database":                      "数据库健康状况 Database health",
+	"handlers.StatsDTO.Days":                          "统计的天数 Days covered",
+	"handlers.StatsDTO.Deployments":                   "每天的部署次数 Deployments per day",
+	"handlers.StatsDTO.Jobs":                          "各状态的后台任务数 Background jobs by status",
+	"handlers.StatsDTO.Orgs":                          "组织数 Organizations",
+	"handlers.StatsDTO.Projects":                      "项目数 Projects",
+	"handlers.StatsDTO.Sites":                         "站点数 Sites",
+	"handlers.StatsDTO.Storage":                       "存储用量 Storage usage",
+	"handlers.StatsDTO.TopSites":                      "请求数最多的站点 Sites with the most requests",
+	"handlers.StatsDTO.Users":                         "用户数 Users",
+	"handlers.StorageStatsDTO.BlobBytes":              "按内容存储的文件字节数 Bytes of files stored by content",
+	"handlers.StorageStatsDTO.Blobs":                  "按内容存储的文件数 Files stored by content",
+	"handlers.StorageStatsDTO.FileBytes":              "版本压缩包和部署清单的字节数 Bytes of release archives and manifests",
+	"handlers.StorageStatsDTO.Files":                  "版本压缩包和部署清单数 Release archives and manifests",
+	"handlers.StorageStatsDTO.TotalBytes":             "总字节数 Total bytes",
+	"handlers.TailLogsReq.PathPrefix":                 "路径前缀 Path prefix",
+	"handlers.TailLogsReq.Status":                     "状态码类别，例如 4xx Status class, e.g. 4xx",
+	"handlers.TeamDTO.Description":                    "团队描述 Team description",
+	"handlers.TeamDTO.ID":                             "团队ID Team ID",
+	"handlers.TeamDTO.Name":                           "团队名称 Team name",
+	"handlers.TeamDTO.OrganizationID":                 "所属组织ID Organization ID",
+	"handlers.TeamReq.Description":                    "团队描述 Team description",
+	"handlers.TeamReq.Name":                           "团队名称 Team name",
+	"handlers.TeamUserReq.UserID":                     "用户ID User ID",
+	"handlers.TenantDTO.BaseDomain":                   "站点子域的基础域名 Base domain of the site subdomains",
+	"handlers.TenantDTO.DisplayName":                  "显示名称 Display name",
+	"handlers.TenantDTO.ID":                           "租户ID Tenant ID",
+	"handlers.TenantDTO.LogoURL":                      "标志 Logo",
+	"handlers.TenantDTO.MaxDeploymentSize":            "默认单次部署解压后的最大字节数 Default largest uncompressed size of one deployment in bytes",
+	"handlers.TenantDTO.MaxProjects":                  "默认可创建的项目数量 Default number of projects that may be created",
+	"handlers.TenantDTO.MaxStorage":                   "默认可占用的存储字节数 Default storage that may be occupied in bytes",
+	"handlers.TenantDTO.MaxUploadSize":                "默认单次上传的最大字节数 Default largest single upload in bytes",
+	"handlers.TenantDTO.Name":                         "租户名称 Tenant name",
+	"handlers.TenantDTO.Organizations":                "租户中的组织数量，仅管理员可见 Number of organizations in the tenant, only for administrators",
+	"handlers.TenantDTO.PrimaryColor":                 "主题色 Theme color",
+	"handlers.TenantDTO.Users":                        "租户中的用户数量，仅管理员可见 Number of users in the tenant, only for administrators",
+	"handlers.TenantReq.BaseDomain":                   "站点子域的基础域名，为空时使用 serve.base-domain Base domain of the site subdomains, serve.base-domain when empty",
+	"handlers.TenantReq.DisplayName":                  "显示名称 Display name",
+	"handlers.TenantReq.LogoURL":                      "标志 Logo",
+	"handlers.TenantReq.MaxDeploymentSize":            "默认单次部署解压后的最大字节数 Default largest uncompressed size of one deployment in bytes",
+	"handlers.TenantReq.MaxProjects":                  "默认可创建的项目数量 Default number of projects that may be created",
+	"handlers.TenantReq.MaxStorage":                   "默认可占用的存储字节数 Default storage that may be occupied in bytes",
+	"handlers.TenantReq.MaxUploadSize":                "默认单次上传的最大字节数 Default largest single upload in bytes",
+	"handlers.TenantReq.Name":                         "租户名称 Tenant name",
+	"handlers.TenantReq.PrimaryColor":                 "主题色，例如 #3b82f6 Theme color such as #3b82f6",
+	"handlers.TransferDTO.CreatedAt":                  "发起时间 Request time",
+	"handlers.TransferDTO.ExpiresAt":                  "过期时间 Expiry time",
+	"handlers.TransferDTO.ID":                         "转移ID Transfer ID",
+	"handlers.TransferDTO.OwnerID":                    "接收方ID Recipient ID",
+	"handlers.TransferDTO.OwnerType":                  "接收方类型 Recipient type",
+	"handlers.TransferDTO.Project":                    "转移的项目 Project being transferred",
+	"handlers.TransferDTO.RequestedBy":                "发起者用户名 Requester username",
+	"handlers.TransferProjectReq.OwnerID":             "接收方ID Recipient ID",
+	"handlers.TransferProjectReq.OwnerType":           "接收方类型 Recipient type",
+	"handlers.TrashProjectDTO.DeletedAt":              "删除时间 Deletion time",
+	"handlers.TrashProjectDTO.PurgeAt":                "将被彻底删除的时间，一直保留时为空 Time it will be purged, empty when kept indefinitely",
+	"handlers.TrashSiteDTO.DeletedAt":                 "删除时间 Deletion time",
+	"handlers.TrashSiteDTO.PurgeAt":                   "将被彻底删除的时间，一直保留时为空 Time it will be purged, empty when kept indefinitely",
+	"handlers.TwoFactorCodeReq.Code":                  "TOTP 验证码或恢复码 TOTP code or recovery code",
+	"handlers.TwoFactorLoginReq.Challenge":            "密码登录返回的临时凭证 Challenge returned by the password login",
+	"handlers.TwoFactorLoginReq.Code":                 "TOTP 验证码或恢复码 TOTP code or recovery code",
+	"handlers.UpdateAPITokenReq.Name":                 "令牌名称 Token name",
+	"handlers.UpdateAPITokenReq.Scopes":               "权限范围 Scopes",
+	"handlers.UpdateOrgReq.AvatarURL":                 "头像URL Avatar URL",
+	"handlers.UpdateOrgReq.Description":               "描述信息 Description",
+	"handlers.UpdateOrgReq.DisplayName":               "显示名称 Display Name",
+	"handlers.UpdateOrgReq.Email":                     "邮箱地址 Email Address",
+	"handlers.UpdateProjectReq.Description":           "项目描述 Project Description",
+	"handlers.UpdateProjectReq.DirectoryListing":      "没有 index.html 的目录是否显示自动生成的目录索引 Whether directories without index.html show a generated directory index",
+	"handlers.UpdateProjectReq.DisplayName":           "项目显示名称 Project Display Name",
+	"handlers.UpdateProjectReq.ImmutableAssets":       "文件名带内容哈希的资源是否以 immutable 缓存一年 Whether assets with a content hash in their file name are cached immutable for a year",
+	"handlers.UpdateProjectReq.Labels":                "项目标签，设置时替换全部标签 Project labels, replacing all of them when set",
+	"handlers.UpdateProjectReq.Name":                  "项目名称 Project Name",
+	"handlers.UpdateProjectReq.PrimarySiteID":         "通过项目主机名访问的站点，为 0 时恢复为最早创建的站点 Site served at the project host, 0 falls back to the oldest site",
+	"handlers.UpdateProjectReq.Protected":             "新部署是否需要维护者批准后才能上线 Whether new deployments only go live once a maintainer approves them",
+	"handlers.UpdateProjectReq.Visibility":            "项目可见性 Project Visibility",
+	"handlers.UpdateSiteReq.AccessMode":               "访问保护方式 Access protection",
+	"handlers.UpdateSiteReq.AccessPassword":           "访问密码，只写，切换到密码模式时必填 Access password, write-only and required when switching to the password mode",
+	"handlers.UpdateSiteReq.AllowIPs":                 "允许访问的地址或 CIDR 网段，为空时不限制 Addresses or CIDR ranges allowed to visit, no restriction when empty",
+	"handlers.UpdateSiteReq.CacheRules":               "按路径的 Cache-Control，格式为 \"<路径模式> <值>\" Cache-Control by path, formatted as \"<pattern> <value>\"",
+	"handlers.UpdateSiteReq.DenyIPs":                  "禁止访问的地址或 CIDR 网段，优先于允许规则 Addresses or CIDR ranges refused, taking precedence over allow rules",
+	"handlers.UpdateSiteReq.Description":              "网站描述 WebSiteDescription",
+	"handlers.UpdateSiteReq.Domains":                  "域名 Domains",
+	"handlers.UpdateSiteReq.Headers":                  "自定义响应头，格式为 \"Name: value\" Custom response headers, formatted as \"Name: value\"",
+	"handlers.UpdateSiteReq.Name":                     "网站名称 WebSiteName",
+	"handlers.UpdateSiteReq.OGPreview":                "是否生成预览图 Whether to generate preview images",
+	"handlers.UpdateSiteReq.RobotsPolicy":             "上传的文件中没有 robots.txt 时使用的策略：allow、disallow、custom 或空 Policy used when the upload has no robots.txt: allow, disallow, custom or empty",
+	"handlers.UpdateSiteReq.RobotsTxt":                "自定义 robots.txt 内容，custom 策略必填 Custom robots.txt content, required by the custom policy",
+	"handlers.UpdateSiteReq.Sitemap":                  "上传的文件中没有 sitemap.xml 时是否按部署的页面生成 Whether sitemap.xml is generated from the deployed pages when the upload has none",
+	"handlers.UpdateSiteReq.SubDomain":                "子域名 SubDomain",
+	"handlers.UploadChunkDTO.Index":                   "分片序号 Chunk index",
+	"handlers.UploadChunkDTO.SHA256":                  "服务端计算的 SHA-256 SHA-256 computed by the server",
+	"handlers.UploadChunkDTO.Size":                    "字节数 Size in bytes",
+	"handlers.UploadDTO.ChunkSize":                    "分片字节数 Chunk size in bytes",
+	"handlers.UploadDTO.Chunks":                       "分片数量 Number of chunks",
+	"handlers.UploadDTO.CreatedAt":                    "创建时间 Created time",
+	"handlers.UploadDTO.ExpiresAt":                    "过期时间 Expiry time",
+	"handlers.UploadDTO.ID":                           "会话ID Session ID",
+	"handlers.UploadDTO.Preview":                      "预览部署名称 Preview deployment name",
+	"handlers.UploadDTO.Received":                     "已上传的分片 Uploaded chunks",
+	"handlers.UploadDTO.SHA256":                       "压缩包的 SHA-256 SHA-256 of the archive",
+	"handlers.UploadDTO.Size":                         "压缩包总字节数 Total size of the archive in bytes",
+	"handlers.UploadDTO.Tag":                          "版本标签 Version tag",
+	"handlers.UserDTO.Avatar":                         "头像 Avatar URL",
+	"handlers.UserDTO.DeleteAt":                       "已申请删除时账号的删除时间 Time the account is deleted at once its deletion was requested",
+	"handlers.UserDTO.Description":                    "描述 Description",
+	"handlers.UserDTO.DisabledAt":                     "管理员停用账号的时间 Time an administrator disabled the account",
+	"handlers.UserDTO.DisplayName":                    "显示名称 DisplayName",
+	"handlers.UserDTO.Email":                          "邮箱 Email",
+	"handlers.UserDTO.EmailVerified":                  "邮箱是否已验证 Whether the email is verified",
+	"handlers.UserDTO.ID":                             "用户ID User ID",
+	"handlers.UserDTO.Language":                       "语言 Language",
+	"handlers.UserDTO.Name":                           "用户名 Username",
+	"handlers.UserDTO.Organizations":                  "组织 Organizations",
+	"handlers.UserDTO.Role":                           "角色 Role",
+	"handlers.VerifyEmailReq.Token":                   "邮件中的令牌 Token from the email",
+	"handlers.WebhookDTO.Active":                      "是否启用 Whether enabled",
+	"handlers.WebhookDTO.CreatedAt":                   "创建时间 Created time",
+	"handlers.WebhookDTO.Events":                      "订阅的事件，为空时订阅全部 Subscribed events, all events when empty",
+	"handlers.WebhookDTO.Format":                      "载荷格式 Payload format",
+	"handlers.WebhookDTO.ID":                          "Webhook ID",
+	"handlers.WebhookDTO.Secret":                      "签名密钥 Signing secret",
+	"handlers.WebhookDTO.URL":                         "投递地址 Delivery URL",
+	"handlers.WebhookDeliveryDTO.Attempts":            "已尝试次数 Attempts made",
+	"handlers.WebhookDeliveryDTO.CreatedAt":           "创建时间 Created time",
+	"handlers.WebhookDeliveryDTO.DeliveredAt":         "投递成功时间 Time of successful delivery",
+	"handlers.WebhookDeliveryDTO.Error":               "最近一次的错误 Error of the latest attempt",
+	"handlers.WebhookDeliveryDTO.Event":               "事件 Event",
+	"handlers.WebhookDeliveryDTO.ID":                  "投递ID Delivery ID",
+	"handlers.WebhookDeliveryDTO.NextAttemptAt":       "下次尝试时间，已结束时为空 Next attempt time, empty once finished",
+	"handlers.WebhookDeliveryDTO.Payload":             "请求体 Request body",
+	"handlers.WebhookDeliveryDTO.ResponseBody":        "最近一次的响应体 Body of the latest response",
+	"handlers.WebhookDeliveryDTO.ResponseStatus":      "最近一次的响应状态码 Status code of the latest response",
+	"handlers.WebhookDeliveryDTO.Status":              "投递状态 Delivery status",
+	"handlers.WebhookReq.Active":                      "是否启用，默认启用 Whether enabled, enabled by default",
+	"handlers.WebhookReq.Events":                      "订阅的事件 Subscribed events",
+	"handlers.WebhookReq.Format":                      "载荷格式，默认 json Payload format, json by default",
+	"handlers.WebhookReq.Secret":                      "签名密钥，创建时为空则自动生成 Signing secret, generated when empty on creation",
+	"handlers.WebhookReq.URL":                         "投递地址 Delivery URL",
+	"handlers.bulkItem.ID":                            "用户或项目ID User or project ID",
+	"handlers.bulkItem.Name":                          "用户或项目名称 User or project name",
+	"handlers.bulkItem.Reason":                        "跳过或失败的原因 Why it was skipped or failed",
+	"handlers.bulkItem.Status":                        "matched、done、skipped 或 failed Matched, done, skipped or failed",
+	"handlers.bulkPayload.Action":                     "批量操作 Bulk operation",
+	"handlers.bulkPayload.Before":                     "删除在此之前创建且此后没有部署的项目 Delete projects created before and not deployed since",
+	"handlers.bulkPayload.DryRun":                     "只列出将被处理的对象 Only list the objects that would be processed",
+	"handlers.bulkPayload.OwnerID":                    "筛选或原所有者ID Owner ID filtered by or the current one",
+	"handlers.bulkPayload.OwnerType":                  "筛选或原所有者类型 Owner type filtered by or the current one",
+	"handlers.bulkPayload.ProjectIDs":                 "只转移这些项目 Only reassign these projects",
+	"handlers.bulkPayload.ToOwnerID":                  "新所有者ID New owner ID",
+	"handlers.bulkPayload.ToOwnerType":                "新所有者类型 New owner type",
+	"handlers.bulkPayload.UserIDs":                    "停用的用户ID IDs of the users to disable",
+	"handlers.bulkResult.Action":                      "批量操作 Bulk operation",
+	"handlers.bulkResult.Done":                        "已处理的数量 Number processed",
+	"handlers.bulkResult.DryRun":                      "是否为试运行 Whether it was a dry run",
+	"handlers.bulkResult.Failed":                      "失败的数量 Number failed",
+	"handlers.bulkResult.Items":                       "每项的结果 Outcome of each item",
+	"handlers.bulkResult.Matched":                     "满足条件的数量 Number of qualifying items",
+	"handlers.bulkResult.Skipped":                     "跳过的数量 Number skipped",
+	"handlers.cdnPurgePayload.Paths":                  "要刷新的路径，为空时刷新全部内容 Paths to purge, everything when empty",
+	"handlers.cdnPurgePayload.ReleaseID":              "切换到的版本ID Release ID switched to",
+	"handlers.deployJobPayload.Archive":               "暂存压缩包的存储键 Storage key of the staged archive",
+	"handlers.deployJobPayload.Changelog":             "版本的变更说明 Changelog of the version",
+	"handlers.deployJobPayload.Checksums":             "解压后校验的文件 SHA-256 SHA-256 of the files verified after extraction",
+	"handlers.deployJobPayload.Preview":               "预览部署名称 Preview deployment name",
+	"handlers.deployJobPayload.TTL":                   "预览有效期 Preview lifetime",
+	"handlers.deployJobPayload.Tag":                   "版本标签 Version tag",
+	"handlers.deployJobPayload.UploadID":              "分片上传会话ID Chunked upload session ID",
+	"handlers.gitPush.After":                          "推送后的提交 Commit after the push",
+	"handlers.gitPush.Deleted":                        "分支是否被删除，仅 GitHub Whether the branch was deleted, GitHub only",
+	"handlers.gitPush.Ref":                            "推送的引用 Pushed ref",
+	"handlers.graphqlScope.flushed":                   "本次请求是否已写入待写入的浏览量 Whether the pending views were written for this request",
+	"handlers.graphqlScope.projects":                  "已解析的项目，供按项目批量计算角色 Projects resolved so far, used to compute roles in batches",
+	"handlers.linkCheckPayload.ReleaseID":             "检查的版本ID Version ID checked",
+	"handlers.releaseArchive.changelog":               "上传时提供的变更说明 Changelog given with the upload",
+	"handlers.releaseArchive.checksums":               "上传时提供的文件 SHA-256，解压后逐个校验，为空时不校验 SHA-256 of the files given with the upload, each verified after extraction, nothing is verified when empty",
+	"handlers.releaseManifest.changelog":              "部署时提供的变更说明 Changelog given with the deployment",
+	"handlers.releaseSchedule.ExpireAction":           "到期处理方式 Expiry action",
+	"handlers.releaseSchedule.ExpiresAt":              "到期时间 Expiry time",
+	"handlers.releaseSchedule.PublishAt":              "定时上线时间 Scheduled go-live time",
+	"handlers.scheduleJobPayload.Action":              "publish 或 expire Publish or expire",
+	"handlers.scheduleJobPayload.At":                  "计划执行时间 Planned time",
+	"handlers.scheduleJobPayload.ReleaseID":           "版本ID Version ID",
+	"handlers.webhookPayload.Text":                    "便于阅读的摘要 Human readable summary",
+	"models.ACMECertificate.Data":                     "PEM 编码的证书和私钥 PEM encoded certificate and private key",
+	"models.ACMECertificate.Name":                     "缓存键，通常为域名 Cache key, usually the domain",
+	"models.ACMECertificate.UpdatedAt":                "更新时间 Updated time",
+	"models.APIToken.ExpiresAt":                       "过期时间，空为永不过期 Expiry time, never expires when empty",
+	"models.APIToken.Hash":                            "令牌的 SHA-256 哈希 SHA-256 hash of the token",
+	"models.APIToken.LastUsedAt":                      "最后使用时间 Last used time",
+	"models.APIToken.Name":                            "令牌名称 Token name",
+	"models.APIToken.Prefix":                          "令牌开头部分，用于识别 Leading part of the token, for identification",
+	"models.APIToken.Scopes":                          "权限范围 Scopes",
+	"models.APIToken.User":                            "所属用户 Owner user",
+	"models.APIToken.UserID":                          "所属用户ID Owner user ID",
+	"models.AccessLog.Bytes":                          "响应字节数 Response bytes",
+	"models.AccessLog.ClientIP":                       "客户端IP Client IP",
+	"models.AccessLog.CreatedAt":                      "请求时间 Request time",
+	"models.AccessLog.Host":                           "请求主机 Request host",
+	"models.AccessLog.ID":                             "日志ID Log ID",
+	"models.AccessLog.LatencyMs":                      "请求耗时（毫秒） Latency in milliseconds",
+	"models.AccessLog.Method":                         "请求方法 Request method",
+	"models.AccessLog.Path":                           "请求路径 Request path",
+	"models.AccessLog.Referer":                        "来源 Referer",
+	"models.AccessLog.SiteID":                         "站点ID Site ID",
+	"models.AccessLog.Status":                         "响应状态码 Response status code",
+	"models.AccessLog.UserAgent":                      "用户代理 User agent",
+	"models.AuditLog.Action":                          "操作，例如 release.create Action, e.g. release.create",
+	"models.AuditLog.ActorID":                         "操作者ID，0 表示匿名 Actor ID, 0 for anonymous",
+	"models.AuditLog.ActorName":                       "操作者名称 Actor name",
+	"models.AuditLog.CreatedAt":                       "发生时间 Event time",
+	"models.AuditLog.Details":                         "JSON 格式的详细信息 Details in JSON",
+	"models.AuditLog.Hash":                            "本条记录的哈希 Hash of this entry",
+	"models.AuditLog.ID":                              "日志ID Log ID",
+	"models.AuditLog.IP":                              "客户端IP Client IP",
+	"models.AuditLog.PrevHash":                        "上一条记录的哈希，唯一以防止链分叉 Hash of the previous entry, unique so the chain cannot fork",
+	"models.AuditLog.TargetID":                        "目标ID Target ID",
+	"models.AuditLog.TargetType":                      "目标类型 Target type",
+	"models.Blob.CreatedAt":                           "首次上传时间 First upload time",
+	"models.Blob.Hash":                                "内容的 SHA-256 SHA-256 of the content",
+	"models.Blob.Size":                                "字节数 Size in bytes",
+	"models.CDNIntegration.Active":                    "是否启用 Whether enabled",
+	"models.CDNIntegration.Project":                   "项目 Project",
+	"models.CDNIntegration.ProjectID":                 "项目ID Project ID",
+	"models.CDNIntegration.Provider":                  "服务商，cloudflare、bunny 或 webhook Provider, cloudflare, bunny or webhook",
+	"models.CDNIntegration.Token":                     "Cloudflare 的 API 令牌、BunnyCDN 的 API 密钥或 webhook 的签名密钥，加密保存 API token of Cloudflare, API key of BunnyCDN or signing secret of the webhook, stored encrypted",
+	"models.CDNIntegration.URL":                       "webhook 的地址 URL of the webhook",
+	"models.CDNIntegration.ZoneID":                    "Cloudflare 的区域ID Zone ID of Cloudflare",
+	"models.ContentPolicy.Action":                     "reject 或 strip Reject or strip",
+	"models.ContentPolicy.BlockedExtensions":          "禁止的扩展名 Disallowed extensions",
+	"models.ContentPolicy.BlockedMIMETypes":           "禁止的 MIME 类型 Disallowed MIME types",
+	"models.ContentPolicy.OrganizationID":             "组织ID Organization ID",
+	"models.ContentPolicy.Serve":                      "访问时是否同样拒绝 Whether requests are refused too",
+	"models.CronTask.LastError":                       "最近一次的错误 Error of the latest run",
+	"models.CronTask.LastFinishedAt":                  "最近一次结束的时间 End of the latest run",
+	"models.CronTask.LastResult":                      "JSON 格式的最近一次结果 Result of the latest run as JSON",
+	"models.CronTask.LastRunAt":                       "最近一次开始运行的时间 Start of the latest run",
+	"models.CronTask.LastStatus":                      "最近一次运行的结果 Outcome of the latest run",
+	"models.CronTask.LastTrigger":                     "最近一次运行的触发方式 How the latest run was triggered",
+	"models.CronTask.Name":                            "任务名称 Task name",
+	"models.CronTask.NextRunAt":                       "下次运行时间，禁用时为空 Next run time, nil when disabled",
+	"models.CronTask.RunningSince":                    "正在运行时的开始时间 Start of the run in progress",
+	"models.CronTask.Schedule":                        "计算下次运行时间所用的表达式 Expression the next run was computed from",
+	"models.CronTask.UpdatedAt":                       "更新时间 Updated time",
+	"models.CustomDomain.Domain":                      "小写域名 Lowercase domain",
+	"models.CustomDomain.LastCheckedAt":               "最后一次验证时间 Last verification attempt",
+	"models.CustomDomain.LastError":                   "最后一次验证失败的原因 Reason of the last failed verification",
+	"models.CustomDomain.Method":                      "验证方式 Verification method",
+	"models.CustomDomain.Site":                        "站点 Site",
+	"models.CustomDomain.SiteID":                      "站点ID Site ID",
+	"models.CustomDomain.Token":                       "验证令牌 Verification token",
+	"models.CustomDomain.VerifiedAt":                  "验证通过时间，空为未验证 Verified time, unverified when empty",
+	"models.DeployKey.CreatedByID":                    "创建者ID Creator ID",
+	"models.DeployKey.ExpiresAt":                      "过期时间，空为永不过期 Expiry time, never expires when empty",
+	"models.DeployKey.Hash":                           "密钥的 SHA-256 哈希 SHA-256 hash of the key",
+	"models.DeployKey.LastUsedAt":                     "最后使用时间 Last used time",
+	"models.DeployKey.Name":                           "密钥名称 Key name",
+	"models.DeployKey.Prefix":                         "密钥开头部分，用于识别 Leading part of the key, for identification",
+	"models.DeployKey.Project":                        "所属项目 Project",
+	"models.DeployKey.ProjectID":                      "所属项目ID Project ID",
+	"models.File.Hash":                                "文件哈希值 File hash",
+	"models.File.ID":                                  "文件ID File ID",
+	"models.File.Manifest":                            "是否为部署清单，清单中的文件按内容哈希存储在 blobs/ 下 Whether it is a deployment manifest whose files are stored by content hash under blobs/",
+	"models.File.Path":                                "存储对象键，例如 releases/<site>/<tag>/<time>.zip Storage object key, e.g. releases/<site>/<tag>/<time>.zip",
+	"models.File.Size":                                "字节数，0 表示尚未统计 Size in bytes, 0 means not yet measured",
+	"models.FileBlob.FileID":                          "清单文件ID Manifest file ID",
+	"models.FileBlob.Hash":                            "内容的 SHA-256 SHA-256 of the content",
+	"models.GitIntegration.Branch":                    "部署的分支 Deployed branch",
+	"models.GitIntegration.BuildCommand":              "构建命令，为空时不构建 Build command, no build when empty",
+	"models.GitIntegration.BuildImage":                "构建镜像，替换沙箱前缀中的 {image} Build image, replacing {image} in the sandbox prefix",
+	"models.GitIntegration.BuildPreset":               "构建预设，例如 hugo、mkdocs 或 vite Build preset such as hugo, mkdocs or vite",
+	"models.GitIntegration.LastCommit":                "最近一次推送的提交 Commit of the latest push",
+	"models.GitIntegration.LastError":                 "最近一次运行的错误 Error of the latest run",
+	"models.GitIntegration.LastLog":                   "最近一次构建的输出，已截断 Output of the latest build, truncated",
+	"models.GitIntegration.LastRunAt":                 "最近一次运行的时间 Time of the latest run",
+	"models.GitIntegration.LastStatus":                "最近一次运行的状态 Status of the latest run",
+	"models.GitIntegration.OutputDir":                 "发布的输出目录，相对仓库根目录 Published output directory, relative to the repository root",
+	"models.GitIntegration.Provider":                  "代码托管平台 Git hosting provider",
+	"models.GitIntegration.RepoURL":                   "仓库 https 地址 Repository https URL",
+	"models.GitIntegration.RunnerLabels":              "交给远程执行器时执行器必须具有的标签 Labels a remote runner must have to take the builds",
+	"models.GitIntegration.Secret":                    "推送事件的签名密钥或令牌，加密保存 Signing secret or token of push events, stored encrypted",
+	"models.GitIntegration.Site":                      "站点 Site",
+	"models.GitIntegration.SiteID":                    "站点ID，每个站点最多绑定一个仓库 Site ID, at most one repository per site",
+	"models.GitIntegration.Token":                     "拉取私有仓库的访问令牌，加密保存 Access token for fetching private repositories, stored encrypted",
+	"models.Index.Columns":                            "列或表达式 Columns or expressions",
+	"models.Index.Dialect":                            "只在该驱动上创建，空为全部 Only created on this driver, empty for all",
+	"models.Index.Name":                               "索引名称 Index name",
+	"models.Index.Table":                              "表名 Table name",
+	"models.Index.Unique":                             "是否唯一 Whether the index is unique",
+	"models.Index.Using":                              "索引类型，空为默认 Index method, empty for the default",
+	"models.Index.Where":                              "部分索引条件，通常用于排除软删除的行 Partial index condition, usually excluding soft-deleted rows",
+	"models.Invitation.CreatedBy":                     "创建者 Creator",
+	"models.Invitation.CreatedByID":                   "创建者ID Creator ID",
+	"models.Invitation.Email":                         "限定的邮箱，空为不限 Restricted email, anyone when empty",
+	"models.Invitation.ExpiresAt":                     "过期时间 Expiry time",
+	"models.Invitation.Hash":                          "令牌的 SHA-256 哈希 SHA-256 hash of the token",
+	"models.Invitation.MaxUses":                       "可使用次数 Allowed uses",
+	"models.Invitation.Organization":                  "加入的组织 Organization to join",
+	"models.Invitation.OrganizationID":                "加入的组织ID，空为实例邀请 Organization to join, an instance invite when empty",
+	"models.Invitation.Prefix":                        "令牌开头部分，用于识别 Leading part of the token for identification",
+	"models.Invitation.Role":                          "加入组织后的角色，仅指定组织时有效 Role after joining, only meaningful with an organization",
+	"models.Invitation.Uses":                          "已使用次数 Uses so far",
+	"models.Job.Attempts":                             "已尝试次数 Attempts made",
+	"models.Job.CreatedAt":                            "创建时间 Created time",
+	"models.Job.Error":                                "最近一次的错误 Error of the latest attempt",
+	"models.Job.FinishedAt":                           "成功、失败或取消的时间 Time it succeeded, failed or was canceled",
+	"models.Job.ID":                                   "任务ID Job ID",
+	"models.Job.MaxAttempts":                          "最大尝试次数 Max attempts",
+	"models.Job.Payload":                              "JSON 格式的参数 Parameters as JSON",
+	"models.Job.Progress":                             "当前阶段已完成的量 Amount of the current stage done",
+	"models.Job.ProgressTotal":                        "当前阶段的总量，未知时为 0 Total amount of the current stage, 0 when unknown",
+	"models.Job.Result":                               "JSON 格式的执行结果 Outcome as JSON",
+	"models.Job.RunAt":                                "下次执行时间，运行中时为租约到期时间 Next run time, the lease expiry while running",
+	"models.Job.SiteID":                               "关联的站点ID，站点成员可查看 Related site ID, visible to the site's members",
+	"models.Job.Stage":                                "执行中的阶段 Stage of the running attempt",
+	"models.Job.StartedAt":                            "最近一次开始执行的时间 Start of the latest attempt",
+	"models.Job.Status":                               "任务状态 Job status",
+	"models.Job.TraceParent":                          "创建任务时的链路上下文，任务的 span 沿用该链路 Trace context at creation, the job's spans continue that trace",
+	"models.Job.Type":                                 "任务类型，决定执行函数 Job type, selecting the handler",
+	"models.Job.UpdatedAt":                            "更新时间 Updated time",
+	"models.Job.UserID":                               "创建任务的用户ID，系统任务为 0 ID of the user creating the job, 0 for system jobs",
+	"models.LDAPGroupMapping.Group":                   "组的 DN 或 CN Group DN or CN",
+	"models.LDAPGroupMapping.Organization":            "组织名称 Organization name",
+	"models.LDAPGroupMapping.Role":                    "组织角色 Organization role",
+	"models.LDAPSettings.EmailAttribute":              "邮箱属性，默认为 mail Email attribute, mail by default",
+	"models.LDAPSettings.GroupAttribute":              "用户所属组的属性，默认为 memberOf Attribute listing the user's groups, memberOf by default",
+	"models.LDAPSettings.GroupMappings":               "组到组织角色的映射 Mappings from groups to organization roles",
+	"models.LDAPSettings.InsecureSkipVerify":          "不校验服务器证书 Skip verifying the server certificate",
+	"models.LDAPSettings.NameAttribute":               "显示名称属性，默认为 cn Display name attribute, cn by default",
+	"models.LDAPSettings.StartTLS":                    "在 ldap:// 连接上启用 StartTLS Use StartTLS on ldap:// connections",
+	"models.LDAPSettings.UserBaseDN":                  "查找用户的起点 Base DN to search users in",
+	"models.LDAPSettings.UserFilter":                  "查找用户的过滤器，{username} 替换为登录名，默认为 (uid={username}) Filter to find the user, {username} is the login name, (uid={username}) by default",
+	"models.LDAPSettings.UsernameAttribute":           "用户名属性，默认为 uid Username attribute, uid by default",
+	"models.LoginFailure.Failures":                    "连续失败次数 Consecutive failures",
+	"models.LoginFailure.Kind":                        "统计对象 Counted subject kind",
+	"models.LoginFailure.LastFailedAt":                "最近一次失败的时间 Time of the latest failure",
+	"models.LoginFailure.LockedUntil":                 "账号锁定到的时间，空为未锁定 Time the account is locked until, empty when not locked",
+	"models.LoginFailure.Subject":                     "用户ID或 IP User ID or IP",
+	"models.Migration.Down":                           "回滚 Rollback",
+	"models.Migration.Name":                           "名称 Name",
+	"models.Migration.NoTx":                           "不在事务中执行，例如 Postgres 并发建索引 Run outside a transaction, e.g. concurrent index builds on Postgres",
+	"models.Migration.Up":                             "升级 Upgrade",
+	"models.Migration.Version":                        "版本号，必须严格递增 Version, must be strictly increasing",
+	"models.Node.Name":                                "节点名称",
+	"models.Node.Token":                               "节点创建Token",
+	"models.NotificationAlert.CreatedAt":              "通知时间 Time notified",
+	"models.NotificationAlert.Key":                    "警告的键，例如 quota.storage Key of the warning, e.g. quota.storage",
+	"models.NotificationAlert.OwnerID":                "所有者ID Owner ID",
+	"models.NotificationAlert.OwnerType":              "所有者类型 Owner type",
+	"models.NotificationChannel.Active":               "是否启用 Whether enabled",
+	"models.NotificationChannel.Events":               "订阅的事件，为空时订阅失败和警告 Subscribed events, failures and warnings when empty",
+	"models.NotificationChannel.Kind":                 "渠道类型 Channel kind",
+	"models.NotificationChannel.Name":                 "名称 Name",
+	"models.NotificationChannel.OwnerID":              "所有者ID Owner ID",
+	"models.NotificationChannel.OwnerType":            "所有者类型 Owner type",
+	"models.NotificationChannel.Secret":               "签名密钥或机器人令牌，加密保存 Signing secret or bot token, stored encrypted",
+	"models.NotificationChannel.Target":               "邮箱地址、webhook 地址或聊天ID Email address, webhook URL or chat ID",
+	"models.NotificationDelivery.Attempts":            "已尝试次数 Attempts made",
+	"models.NotificationDelivery.Channel":             "通知渠道 Notification channel",
+	"models.NotificationDelivery.ChannelID":           "通知渠道ID Notification channel ID",
+	"models.NotificationDelivery.CreatedAt":           "创建时间 Created time",
+	"models.NotificationDelivery.DeliveredAt":         "投递成功时间 Time of successful delivery",
+	"models.NotificationDelivery.Error":               "最近一次的错误 Error of the latest attempt",
+	"models.NotificationDelivery.Event":               "事件 Event",
+	"models.NotificationDelivery.ID":                  "投递ID Delivery ID",
+	"models.NotificationDelivery.NextAttemptAt":       "下次尝试时间 Next attempt time",
+	"models.NotificationDelivery.ResponseBody":        "最近一次的响应体，已截断 Body of the latest response, truncated",
+	"models.NotificationDelivery.ResponseStatus":      "最近一次的响应状态码，邮件为 0 Status code of the latest response, 0 for email",
+	"models.NotificationDelivery.Status":              "投递状态 Delivery status",
+	"models.NotificationDelivery.Subject":             "标题 Subject",
+	"models.NotificationDelivery.Text":                "正文 Text",
+	"models.OIDCConfig.AdminGroups":                   "平台管理员组，默认为：[]string{}，*为匹配所有组，储存为逗号分隔的字符串",
+	"models.OIDCConfig.AllowedGroups":                 "允许登录的组，默认为：[]string{\"*\"}，*为匹配所有组，储存为逗号分隔的字符串",
+	"models.OIDCConfig.BaseURL":                       "自托管 GitLab 或 GitHub Enterprise 的地址，留空使用官方服务；LDAP 提供方为服务器地址",
+	"models.OIDCConfig.ClientID":                      "客户端ID",
+	"models.OIDCConfig.ClientSecret":                  "客户端密钥，加密保存",
+	"models.OIDCConfig.DisplayName":                   "显示名称，例如：轻雪通行证",
+	"models.OIDCConfig.Enabled":                       "是否允许通过该提供方登录",
+	"models.OIDCConfig.GroupsClaim":                   "组声明，默认为：\"groups\"",
+	"models.OIDCConfig.Icon":                          "图标url，为空则使用内置默认图标",
+	"models.OIDCConfig.LDAP":                          "LDAP 提供方的目录设置，ClientID 和 ClientSecret 为查询用户的绑定 DN 和密码",
+	"models.OIDCConfig.OidcDiscoveryURL":              "OpenID自动发现URL，例如 ：https://pass.liteyuki.icu/.well-known/openid-configuration",
+	"models.OIDCConfig.Type":                          "提供方类型：github、gitlab、oidc 或 ldap",
+	"models.OrgMember.CreatedAt":                      "加入时间 Joined time",
+	"models.OrgMember.OrganizationID":                 "组织ID Organization ID",
+	"models.OrgMember.Role":                           "组织角色，对组织下所有项目生效 Organization role, applies to every project of the organization",
+	"models.OrgMember.UserID":                         "用户ID User ID",
+	"models.Organization.AvatarHash":                  "上传的标志的内容哈希，优先于 AvatarURL Content hash of the uploaded logo, preferred over AvatarURL",
+	"models.Organization.AvatarURL":                   "留空以使用 Gravatar Leave blank to use Gravatar",
+	"models.Organization.Description":                 "组织描述 Organization description",
+	"models.Organization.DisplayName":                 "组织的显示名称 Organization's display name",
+	"models.Organization.Email":                       "组织的电子邮件地址 Organization's email address",
+	"models.Organization.Members":                     "组织的成员包含创建者，角色见 OrgMember Members including the creator, see OrgMember for roles",
+	"models.Organization.Name":                        "组织的唯一名称 Organization's unique name",
+	"models.Organization.PreviewTemplate":             "自定义预览图模板对象键 Custom preview image template object key",
+	"models.Organization.ProjectLimit":                "组织的项目限制，0：遵循策略，-1：无限制 Organization's project limit, 0: follow the policy, -1: unlimited",
+	"models.Organization.TenantID":                    "所属租户，空为实例本身 Tenant the organization belongs to, the instance itself when empty",
+	"models.Project.Description":                      "项目描述 Project description",
+	"models.Project.DirectoryListing":                 "没有 index.html 的目录是否显示自动生成的目录索引 Whether directories without index.html show a generated directory index",
+	"models.Project.DisplayName":                      "项目的显示名称 Project's display name",
+	"models.Project.ImmutableAssets":                  "文件名带内容哈希的资源是否作为不可变内容长期缓存 Whether assets with a content hash in their file name are cached for long as immutable",
+	"models.Project.KeepReleaseDays":                  "版本至少保留的天数，为空时使用 release.keep-days，0 为不按时间保留 Days versions are kept at least, release.keep-days applies when empty and 0 keeps none by age",
+	"models.Project.KeepReleases":                     "每个站点至少保留的最新版本数，为空时使用 release.keep-last，0 为不按数量保留 Newest versions kept per site at least, release.keep-last applies when empty and 0 keeps none by count",
+	"models.Project.Labels":                           "项目标签，仅在需要时加载 Project labels, only loaded when needed",
+	"models.Project.MaxUploadSize":                    "管理员为项目设置的单次上传最大字节数，为空时使用所有者的配额 Largest single upload in bytes set by an administrator, the owner's quota applies when empty",
+	"models.Project.Name":                             "项目的唯一名称 Project's unique name",
+	"models.Project.OwnerID":                          "所有者 ID（用户 ID 或组织 ID） Owner ID (user ID or organization ID)",
+	"models.Project.OwnerType":                        "所有者类型，可以是用户或组织 Owner type, can be user or organization",
+	"models.Project.PrimarySiteID":                    "通过项目主机名访问的站点，为空时使用最早创建的站点 Site served at the project host, the oldest site when empty",
+	"models.Project.Protected":                        "新部署是否需要维护者批准后才能上线 Whether new deployments only go live once a maintainer approves them",
+	"models.Project.SiteLimit":                        "项目的站点限制，0：遵循策略，-1：无限制 Project's site limit, 0: follow the policy, -1: unlimited",
+	"models.Project.TenantID":                         "所有者所属的租户，创建时从所有者复制 Tenant of the owner, copied from the owner on creation",
+	"models.Project.Visibility":                       "项目的可见性 Project visibility",
+	"models.ProjectLabel.Key":                         "标签键 Label key",
+	"models.ProjectLabel.ProjectID":                   "项目ID Project ID",
+	"models.ProjectLabel.Value":                       "标签值，可以为空 Label value, may be empty",
+	"models.ProjectMember.Project":                    "项目 Project",
+	"models.ProjectMember.ProjectID":                  "项目ID Project ID",
+	"models.ProjectMember.Role":                       "项目角色 Project role",
+	"models.ProjectMember.User":                       "用户 User",
+	"models.ProjectMember.UserID":                     "用户ID User ID",
+	"models.ProjectTeam.Project":                      "项目 Project",
+	"models.ProjectTeam.ProjectID":                    "项目ID Project ID",
+	"models.ProjectTeam.Role":                         "项目角色 Project role",
+	"models.ProjectTeam.Team":                         "团队 Team",
+	"models.ProjectTeam.TeamID":                       "团队ID Team ID",
+	"models.ProjectTransfer.CreatedAt":                "发起时间 Request time",
+	"models.ProjectTransfer.ExpiresAt":                "过期时间 Expiry time",
+	"models.ProjectTransfer.ID":                       "转移ID Transfer ID",
+	"models.ProjectTransfer.OwnerID":                  "接收方ID Recipient ID",
+	"models.ProjectTransfer.OwnerType":                "接收方类型，用户或组织 Recipient type, user or organization",
+	"models.ProjectTransfer.Project":                  "转移的项目 Project being transferred",
+	"models.ProjectTransfer.ProjectID":                "转移的项目ID Project being transferred",
+	"models.ProjectTransfer.RequestedBy":              "发起者 Requester",
+	"models.ProjectTransfer.RequestedByID":            "发起者ID Requester ID",
+	"models.Quota.MaxDeploymentSize":                  "单次部署解压后的最大字节数 Largest uncompressed size of one deployment in bytes",
+	"models.Quota.MaxProjects":                        "可创建的项目数量 Number of projects that may be created",
+	"models.Quota.MaxStorage":                         "可占用的存储字节数 Storage that may be occupied in bytes",
+	"models.Quota.MaxUploadSize":                      "单次上传的最大字节数 Largest single upload in bytes",
+	"models.Quota.OwnerID":                            "所有者ID Owner ID",
+	"models.Quota.OwnerType":                          "所有者类型 user 或 organization Owner type, user or organization",
+	"models.RecoveryCode.Hash":                        "恢复码的 SHA-256 哈希 SHA-256 hash of the code",
+	"models.RecoveryCode.UsedAt":                      "使用时间，空为未使用 Used time, unused when empty",
+	"models.RecoveryCode.User":                        "用户 User",
+	"models.RecoveryCode.UserID":                      "用户ID User ID",
+	"models.Runner.Concurrency":                       "同时执行的构建数量 Number of builds run at the same time",
+	"models.Runner.Hash":                              "令牌的 SHA-256 哈希 SHA-256 hash of the token",
+	"models.Runner.Labels":                            "执行器的标签 Labels of the runner",
+	"models.Runner.LastSeenAt":                        "最后一次注册、心跳或领取构建的时间 Time of the latest registration, heartbeat or claim",
+	"models.Runner.Name":                              "执行器名称 Runner name",
+	"models.Runner.Prefix":                            "令牌开头部分，用于识别 Leading part of the token, for identification",
+	"models.Runner.Version":                           "执行器的版本 Version of the runner",
+	"models.RunnerBuild.Commit":                       "请求构建的提交，为空时构建分支的最新提交 Commit asked for, the head of the branch when empty",
+	"models.RunnerBuild.Error":                        "失败原因 Failure reason",
+	"models.RunnerBuild.FinishedAt":                   "结束时间 Finish time",
+	"models.RunnerBuild.Integration":                  "仓库绑定 Repository binding",
+	"models.RunnerBuild.IntegrationID":                "仓库绑定ID Repository binding ID",
+	"models.RunnerBuild.Labels":                       "执行器必须具有的标签 Labels the runner must have",
+	"models.RunnerBuild.LeaseUntil":                   "租约到期时间 Lease expiry",
+	"models.RunnerBuild.MapCNAME":                     "发布后是否绑定 CNAME 文件中的域名 Whether the domain in the CNAME file is bound after publishing",
+	"models.RunnerBuild.RunnerID":                     "领取构建的执行器ID ID of the runner that took the build",
+	"models.RunnerBuild.SiteID":                       "站点ID Site ID",
+	"models.RunnerBuild.StartedAt":                    "开始时间 Start time",
+	"models.RunnerBuild.Status":                       "运行状态 Run status",
+	"models.SchemaMigration.AppliedAt":                "执行时间 Applied time",
+	"models.SchemaMigration.Name":                     "迁移名称 Migration name",
+	"models.SchemaMigration.Version":                  "迁移版本 Migration version",
+	"models.SearchTable.Columns":                      "参与检索的列 Columns searched",
+	"models.SearchTable.Table":                        "表名 Table name",
+	"models.Setting.Key":                              "配置项名称，如 quota.max-projects Setting key, e.g. quota.max-projects",
+	"models.Setting.UpdatedAt":                        "修改时间 Update time",
+	"models.Setting.UpdatedBy":                        "修改者用户ID User ID of the last editor",
+	"models.Setting.Value":                            "JSON 编码的值 JSON encoded value",
+	"models.Site.AccessMode":                          "访问保护方式 Access protection",
+	"models.Site.AccessPassword":                      "访问密码的 bcrypt 哈希，仅密码模式使用 Bcrypt hash of the access password, only used by the password mode",
+	"models.Site.AllowIPs":                            "允许访问的地址或 CIDR 网段，为空时不限制 Addresses or CIDR ranges allowed to visit, no restriction when empty",
+	"models.Site.CacheRules":                          "按路径的 Cache-Control，格式为 \"<路径模式> <值>\" Cache-Control by path, formatted as \"<pattern> <value>\"",
+	"models.Site.DenyIPs":                             "禁止访问的地址或 CIDR 网段，优先于允许规则 Addresses or CIDR ranges refused, taking precedence over allow rules",
+	"models.Site.Description":                         "站点描述 Site description",
+	"models.Site.Domains":                             "允许的域名，json格式 Allowed domains, json format",
+	"models.Site.Headers":                             "自定义响应头，格式为 \"Name: value\" Custom response headers, formatted as \"Name: value\"",
+	"models.Site.Name":                                "站点名称 Site name",
+	"models.Site.OGPreview":                           "是否在发布时生成 open-graph 预览图 Whether to generate an open-graph preview image at publish time",
+	"models.Site.Project":                             "项目 Project",
+	"models.Site.ProjectID":                           "项目ID Project ID",
+	"models.Site.RobotsPolicy":                        "上传的文件中没有 robots.txt 时使用的策略，为空时不生成 Policy used when the upload has no robots.txt, never generated when empty",
+	"models.Site.RobotsTxt":                           "自定义 robots.txt 内容，仅 custom 策略使用 Custom robots.txt content, only used by the custom policy",
+	"models.Site.Sitemap":                             "上传的文件中没有 sitemap.xml 时是否按部署的页面生成 Whether sitemap.xml is generated from the deployed pages when the upload has none",
+	"models.Site.SubDomain":                           "子域前缀 Subdomain prefix",
+	"models.Site.TransferHardLimit":                   "管理员为站点设置的每月流量硬限制字节数，为空时使用 traffic.hard-limit Hard monthly transfer limit in bytes set by an administrator, traffic.hard-limit applies when empty",
+	"models.Site.TransferSoftLimit":                   "管理员为站点设置的每月流量软限制字节数，为空时使用 traffic.soft-limit Soft monthly transfer limit in bytes set by an administrator, traffic.soft-limit applies when empty",
+	"models.SiteAnalytics.Day":                        "UTC 日期的零点 Midnight of the UTC day",
+	"models.SiteAnalytics.Dimension":                  "维度 Dimension",
+	"models.SiteAnalytics.SiteID":                     "站点ID Site ID",
+	"models.SiteAnalytics.Value":                      "维度的值 Value of the dimension",
+	"models.SiteAnalytics.Views":                      "浏览量 Page views",
+	"models.SiteBlockedPath.Pattern":                  "路径或以 /* 结尾的前缀 Path or prefix ending with /*",
+	"models.SiteBlockedPath.PurgeID":                  "来源清除记录ID Source purge record ID",
+	"models.SiteBlockedPath.SiteID":                   "站点ID Site ID",
+	"models.SiteDomain.Domain":                        "小写域名 Lowercase domain",
+	"models.SiteDomain.SiteID":                        "站点ID Site ID",
+	"models.SitePathPurge.FromReleaseID":              "清除前的发布ID Release ID before purging",
+	"models.SitePathPurge.Paths":                      "清除的路径或前缀 Purged paths or prefixes",
+	"models.SitePathPurge.ProjectID":                  "项目ID，用于按项目限流 Project ID, used for per-project rate limiting",
+	"models.SitePathPurge.Removed":                    "实际移除的文件数 Number of files actually removed",
+	"models.SitePathPurge.SiteID":                     "站点ID Site ID",
+	"models.SitePathPurge.Takedown":                   "是否为下架，下架路径在所有历史发布中屏蔽 Whether it is a takedown, blocked in all historic releases",
+	"models.SitePathPurge.ToReleaseID":                "清除后派生的发布ID Derived release ID after purging",
+	"models.SitePathPurge.UserID":                     "操作者ID Operator ID",
+	"models.SitePreview.ExpiresAt":                    "过期时间 Expiration time",
+	"models.SitePreview.Name":                         "分支或合并请求标识，例如 pr-42 Branch or pull request identifier, e.g. pr-42",
+	"models.SitePreview.Release":                      "预览的版本 Previewed version",
+	"models.SitePreview.ReleaseID":                    "预览的版本ID Previewed version ID",
+	"models.SitePreview.Site":                         "站点 Site",
+	"models.SitePreview.SiteID":                       "站点ID Site ID",
+	"models.SiteRelease.ActiveReleaseID":              "仅 latest 记录使用，指向当前激活的版本 Only used by the latest record, pointing at the active version",
+	"models.SiteRelease.ApprovalActivate":             "等待批准的版本批准后是否激活 Whether a version awaiting approval is activated once approved",
+	"models.SiteRelease.Changelog":                    "上传时提供的变更说明，显示在部署订阅源中 Changelog given with the upload, shown in the deployment feeds",
+	"models.SiteRelease.Checksums":                    "上传时提供并在解压后校验通过的文件 SHA-256，未提供时为空 SHA-256 of the files given with the upload and verified after extraction, empty when none were given",
+	"models.SiteRelease.ExpireAction":                 "到期处理方式 Expiry action",
+	"models.SiteRelease.ExpiresAt":                    "到期时间，到期时仍激活则按 ExpireAction 处理 Expiry time, handled by ExpireAction when the version is still active then",
+	"models.SiteRelease.File":                         "版本文件 Version file",
+	"models.SiteRelease.FileID":                       "版本文件ID Version file ID",
+	"models.SiteRelease.LinkReport":                   "上线后链接检查的报告，JSON 格式，未检查时为空 Report of the link check after going live, as JSON, empty when never checked",
+	"models.SiteRelease.Pinned":                       "固定的版本不会被保留策略删除 Pinned versions are never removed by the retention policy",
+	"models.SiteRelease.PreviewHash":                  "预览图输入摘要，输入不变时复用 Digest of preview inputs, reused when unchanged",
+	"models.SiteRelease.PreviewPath":                  "open-graph 预览图对象键 Open-graph preview image object key",
+	"models.SiteRelease.PublishAt":                    "定时上线时间 Scheduled go-live time",
+	"models.SiteRelease.ScanActivate":                 "隔离的版本审核通过后是否激活 Whether a quarantined version is activated once approved",
+	"models.SiteRelease.ScanFindings":                 "内容扫描发现的恶意文件，JSON 格式 Malicious files found by the content scan, as JSON",
+	"models.SiteRelease.SiteID":                       "站点ID Site ID",
+	"models.SiteRelease.Status":                       "部署状态 Deployment status",
+	"models.SiteRelease.Tag":                          "版本标签 Version tag",
+	"models.SiteTraffic.Bytes":                        "响应字节数 Response bytes",
+	"models.SiteTraffic.Day":                          "UTC 日期的零点 Midnight of the UTC day",
+	"models.SiteTraffic.Requests":                     "请求数 Requests",
+	"models.SiteTraffic.SiteID":                       "站点ID Site ID",
+	"models.SiteTransferAlert.Bytes":                  "超出时的月流量 Monthly transfer when exceeded",
+	"models.SiteTransferAlert.CreatedAt":              "超出时间 Time exceeded",
+	"models.SiteTransferAlert.Level":                  "限制级别 Limit level",
+	"models.SiteTransferAlert.Month":                  "UTC 月份，格式为 2006-01 UTC month, formatted as 2006-01",
+	"models.SiteTransferAlert.SiteID":                 "站点ID Site ID",
+	"models.Team.Description":                         "团队描述 Team description",
+	"models.Team.Name":                                "组织内唯一的团队名称 Team name, unique within the organization",
+	"models.Team.Organization":                        "所属组织 Organization",
+	"models.Team.OrganizationID":                      "所属组织ID Organization ID",
+	"models.TeamMember.Team":                          "团队 Team",
+	"models.TeamMember.TeamID":                        "团队ID Team ID",
+	"models.TeamMember.User":                          "用户 User",
+	"models.TeamMember.UserID":                        "用户ID User ID",
+	"models.Tenant.BaseDomain":                        "租户站点子域的基础域名，为空时使用 serve.base-domain Base domain of the tenant's site subdomains, serve.base-domain when empty",
+	"models.Tenant.DisplayName":                       "显示名称，同时作为控制台中展示的品牌名称 Display name, also shown as the brand name in the dashboard",
+	"models.Tenant.LogoURL":                           "控制台中展示的标志 Logo shown in the dashboard",
+	"models.Tenant.MaxProjects":                       "租户内用户和组织的默认配额，字段为空时使用配置文件中的默认值，单独设置的配额优先 Default quotas of the tenant's users and organizations, empty fields use the configured defaults and quotas set per owner come first",
+	"models.Tenant.Name":                              "租户的唯一名称 Unique name of the tenant",
+	"models.Tenant.PrimaryColor":                      "控制台的主题色，例如 #3b82f6 Theme color of the dashboard such as #3b82f6",
+	"models.Token.ExpiresAt":                          "会话过期时间 Session expiry time",
+	"models.Token.IP":                                 "最近使用的客户端IP Client IP of the last use",
+	"models.Token.ImpersonatorID":                     "代为登录的管理员ID，普通会话为 0 ID of the impersonating administrator, 0 for ordinary sessions",
+	"models.Token.LastUsedAt":                         "最近一次刷新访问令牌的时间 Last time an access token was refreshed",
+	"models.Token.Method":                             "登录方式 Login method",
+	"models.Token.Reason":                             "代为登录的原因 Reason for the impersonation",
+	"models.Token.RefreshHash":                        "刷新令牌的 SHA-256 哈希 SHA-256 hash of the refresh token",
+	"models.Token.Scopes":                             "代为登录会话的权限范围 Scopes of an impersonation session",
+	"models.Token.UserAgent":                          "最近使用的用户代理 User agent of the last use",
+	"models.Upload.Changelog":                         "版本的变更说明 Changelog of the version",
+	"models.Upload.Checksums":                         "解压后校验的文件 SHA-256 SHA-256 of the files verified after extraction",
+	"models.Upload.ChunkSize":                         "分片字节数，最后一片可以更小 Chunk size in bytes, the last chunk may be smaller",
+	"models.Upload.CreatedAt":                         "创建时间 Created time",
+	"models.Upload.ExpireAction":                      "版本的到期处理方式 Expiry action of the version",
+	"models.Upload.ExpiresAt":                         "过期时间，过期后删除已上传的分片 Expiry time, uploaded chunks are deleted afterwards",
+	"models.Upload.ID":                                "会话ID Session ID",
+	"models.Upload.Preview":                           "预览部署名称，为空时为正式部署 Preview deployment name, a regular deployment when empty",
+	"models.Upload.PublishAt":                         "版本的定时上线时间 Scheduled go-live time of the version",
+	"models.Upload.ReleaseExpiresAt":                  "版本的到期时间 Expiry time of the version",
+	"models.Upload.SHA256":                            "压缩包的 SHA-256 SHA-256 of the archive",
+	"models.Upload.SiteID":                            "站点ID Site ID",
+	"models.Upload.Size":                              "压缩包总字节数 Total size of the archive in bytes",
+	"models.Upload.TTL":                               "预览有效期，单位秒 Preview lifetime in seconds",
+	"models.Upload.Tag":                               "版本标签 Version tag",
+	"models.Upload.UserID":                            "创建会话的用户ID ID of the user creating the session",
+	"models.UploadChunk.Index":                        "分片序号，从 0 开始 Chunk index, starting at 0",
+	"models.UploadChunk.SHA256":                       "服务端计算的 SHA-256 SHA-256 computed by the server",
+	"models.UploadChunk.Size":                         "字节数 Size in bytes",
+	"models.UploadChunk.UploadID":                     "会话ID Session ID",
+	"models.User.AvatarHash":                          "上传的头像的内容哈希，优先于 AvatarURL Content hash of the uploaded avatar, preferred over AvatarURL",
+	"models.User.AvatarURL":                           "留空以使用 Gravatar Leave blank to use Gravatar",
+	"models.User.DeleteAt":                            "用户申请删除账号后的删除时间，空为未申请 Time the account is deleted at after the user asked for it, empty when not requested",
+	"models.User.Description":                         "用户描述 User description",
+	"models.User.DisabledAt":                          "管理员停用账号的时间，停用的账号无法登录或调用 API，空为正常 Time an administrator disabled the account, disabled accounts can neither sign in nor call the API, active when empty",
+	"models.User.DisplayName":                         "用户的显示名称 User's display name",
+	"models.User.Email":                               "用户的电子邮件地址，只有用户的电子邮件地址是唯一的（用于 oidc 身份验证） User's email address, only the user's email address is unique (used for oidc authentication)",
+	"models.User.EmailVerifiedAt":                     "邮箱验证通过的时间，空为未验证，修改邮箱后清空 Time the email was verified, unverified when empty and cleared when the email changes",
+	"models.User.Flag":                                "system_admin 的另一面旗帜 The other side of system_admin flag",
+	"models.User.Language":                            "用户的语言，默认为英语 User's language, default to English",
+	"models.User.Name":                                "用户的唯一名称 User's unique name",
+	"models.User.Organizations":                       "隶属于许多组织 Many organizations the user belongs to",
+	"models.User.Password":                            "用户的密码（经过哈希处理），仅用于本地身份验证 User's password (hashed), only used for local authentication",
+	"models.User.ProjectLimit":                        "用户的项目限制，0 表示无限制 User's project limit, 0 means no limit",
+	"models.User.Role":                                "用户的全局角色 User's global role",
+	"models.User.TenantID":                            "所属租户，空为实例本身 Tenant the user belongs to, the instance itself when empty",
+	"models.UserIdentity.Email":                       "提供方返回的邮箱 Email returned by the provider",
+	"models.UserIdentity.Provider":                    "登录提供方 Auth provider",
+	"models.UserIdentity.ProviderID":                  "登录提供方ID Auth provider ID",
+	"models.UserIdentity.Subject":                     "提供方内的唯一用户标识 Unique user identifier at the provider",
+	"models.UserIdentity.User":                        "本地用户 Local user",
+	"models.UserIdentity.UserID":                      "本地用户ID Local user ID",
+	"models.UserTOTP.Enabled":                         "是否已启用 Whether it is enabled",
+	"models.UserTOTP.LastUsedStep":                    "最后使用的时间步，防止验证码重放 Last used time step, against code replay",
+	"models.UserTOTP.Secret":                          "Base32 编码的密钥，加密保存 Base32 encoded secret, stored encrypted",
+	"models.UserTOTP.User":                            "用户 User",
+	"models.UserTOTP.UserID":                          "用户ID User ID",
+	"models.UserToken.Email":                          "发送到的邮箱，邮箱修改后令牌失效 Address it was sent to, the token is void once the address changes",
+	"models.UserToken.ExpiresAt":                      "过期时间 Expiry time",
+	"models.UserToken.Hash":                           "令牌的 SHA-256 哈希 SHA-256 hash of the token",
+	"models.UserToken.Purpose":                        "用途，见 constants.UserTokenVerifyEmail 等 Purpose, see constants.UserTokenVerifyEmail etc.",
+	"models.UserToken.UsedAt":                         "使用时间，空为未使用 Used time, unused when empty",
+	"models.UserToken.User":                           "用户 User",
+	"models.UserToken.UserID":                         "用户ID User ID",
+	"models.Webhook.Active":                           "是否启用 Whether enabled",
+	"models.Webhook.Events":                           "订阅的事件，为空时订阅全部 Subscribed events, all events when empty",
+	"models.Webhook.Format":                           "载荷格式 Payload format",
+	"models.Webhook.Project":                          "项目 Project",
+	"models.Webhook.ProjectID":                        "项目ID Project ID",
+	"models.Webhook.Secret":                           "签名密钥，加密保存 Signing secret, stored encrypted",
+	"models.Webhook.URL":                              "投递地址 Delivery URL",
+	"models.WebhookDelivery.Attempts":                 "已尝试次数 Attempts made",
+	"models.WebhookDelivery.CreatedAt":                "创建时间 Created time",
+	"models.WebhookDelivery.DeliveredAt":              "投递成功时间 Time of successful delivery",
+	"models.WebhookDelivery.Error":                    "最近一次的错误 Error of the latest attempt",
+	"models.WebhookDelivery.Event":                    "事件 Event",
+	"models.WebhookDelivery.ID":                       "投递ID Delivery ID",
+	"models.WebhookDelivery.NextAttemptAt":            "下次尝试时间 Next attempt time",
+	"models.WebhookDelivery.Payload":                  "请求体 Request body",
+	"models.WebhookDelivery.ResponseBody":             "最近一次的响应体，已截断 Body of the latest response, truncated",
+	"models.WebhookDelivery.ResponseStatus":           "最近一次的响应状态码 Status code of the latest response",
+	"models.WebhookDelivery.Status":                   "投递状态 Delivery status",
+	"models.WebhookDelivery.Webhook":                  "Webhook",
+	"models.WebhookDelivery.WebhookID":                "Webhook ID",
+}
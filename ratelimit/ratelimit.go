@@ -0,0 +1,214 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/cache"
+	"github.com/LiteyukiStudio/spage/config"
+)
+
+// 限流分组 Rate limit groups
+const (
+	GroupAuth   = "auth"   // 登录、注册和第三方登录回调，按 IP 计数 Login, registration and provider callbacks, counted per IP
+	GroupUpload = "upload" // 发布上传和模板上传，按用户计数 Release and template uploads, counted per user
+	GroupServe  = "serve"  // 托管站点访问，按 IP 计数 Hosted site requests, counted per IP
+)
+
+// Groups 所有限流分组
+// All rate limit groups
+var Groups = []string{GroupAuth, GroupUpload, GroupServe}
+
+// Rule 令牌桶规则，每 Period 补充 Limit 个令牌，最多积攒 Burst 个
+// Token bucket rule, refilling Limit tokens every Period and holding at most Burst
+type Rule struct {
+	Limit  int           // 每个周期的请求数，0 表示不限流 Requests per period, 0 disables limiting
+	Period time.Duration // 周期 Period
+	Burst  int           // 桶容量 Bucket capacity
+}
+
+// Enabled 规则是否生效
+// Whether the rule limits anything
+func (r Rule) Enabled() bool {
+	return r.Limit > 0 && r.Period > 0
+}
+
+// rate 每秒补充的令牌数
+// Tokens refilled per second
+func (r Rule) rate() float64 {
+	return float64(r.Limit) / r.Period.Seconds()
+}
+
+// burst 桶容量，未配置时等于 Limit
+// Bucket capacity, equal to Limit when not configured
+func (r Rule) burst() float64 {
+	if r.Burst > 0 {
+		return float64(r.Burst)
+	}
+	return float64(r.Limit)
+}
+
+// idle 桶从空到满所需的时间，超过该时间未访问的桶可以丢弃
+// Time for an empty bucket to refill, buckets idle for longer can be dropped
+func (r Rule) idle() time.Duration {
+	return time.Duration(r.burst() / r.rate() * float64(time.Second))
+}
+
+// Result 一次请求的限流结果
+// Outcome of a rate limited request
+type Result struct {
+	Allowed    bool          // 是否放行 Whether the request may proceed
+	Remaining  int           // 剩余的整令牌数 Whole tokens left
+	RetryAfter time.Duration // 被拒绝时距离下一个令牌的时间 Time until the next token when refused
+}
+
+// Limiter 令牌桶存储，key 区分不同的桶
+// Token bucket store, key tells buckets apart
+type Limiter interface {
+	Allow(ctx context.Context, key string, rule Rule) (Result, error)
+}
+
+// Config 限流配置
+// Rate limit configuration
+type Config struct {
+	Enable  bool            // 是否启用限流 Whether rate limiting is enabled
+	Backend string          // 令牌桶存储，memory 或 redis Token bucket store, memory or redis
+	Rules   map[string]Rule // 各分组的规则 Rules of each group
+
+	RedisPrefix string // 键前缀，多个实例共用 Redis 时区分 Key prefix, telling instances sharing a Redis apart
+}
+
+var (
+	// Default 当前实例使用的令牌桶存储，未启用时为 nil
+	// Token bucket store used by this instance, nil when disabled
+	Default Limiter
+	// Rules 各分组生效的规则
+	// Rules in effect for each group
+	Rules = map[string]Rule{}
+)
+
+// defaultRules 未配置时的规则，站点访问默认不限流以免误伤共享出口的访客
+// Rules used when not configured, site serving is unlimited by default so visitors behind shared egress are not caught
+var defaultRules = map[string]Rule{
+	GroupAuth:   {Limit: 10, Period: time.Minute, Burst: 10},
+	GroupUpload: {Limit: 30, Period: time.Minute, Burst: 10},
+	GroupServe:  {Limit: 0, Period: time.Second},
+}
+
+// loadConfig 从配置文件加载限流配置
+// Load rate limit configuration from config file
+func loadConfig() Config {
+	cfg := Config{
+		Enable:  config.GetBool("ratelimit.enable", true),
+		Backend: config.GetString("ratelimit.backend", "memory"),
+		Rules:   map[string]Rule{},
+
+		RedisPrefix: config.GetString("redis.prefix", "spage:"),
+	}
+	for _, group := range Groups {
+		rule := defaultRules[group]
+		prefix := "ratelimit." + group + "."
+		cfg.Rules[group] = Rule{
+			Limit:  config.GetInt(prefix+"limit", rule.Limit),
+			Period: time.Duration(config.GetInt(prefix+"period", int(rule.Period.Seconds()))) * time.Second,
+			Burst:  config.GetInt(prefix+"burst", rule.Burst),
+		}
+	}
+	return cfg
+}
+
+// Init 根据配置初始化限流
+// Initialize rate limiting from configuration
+func Init() error {
+	cfg := loadConfig()
+	Rules = cfg.Rules
+	if !cfg.Enable {
+		Default = nil
+		return nil
+	}
+	switch cfg.Backend {
+	case "memory":
+		Default = NewMemory()
+	case "redis":
+		client, err := cache.Client(context.Background())
+		if err != nil {
+			return fmt.Errorf("init redis rate limiter: %w", err)
+		}
+		Default = NewRedis(client, cfg.RedisPrefix)
+	default:
+		return errors.New("unsupported rate limit backend: " + cfg.Backend)
+	}
+	return nil
+}
+
+// Memory 进程内的令牌桶存储，只对单个实例生效
+// In-process token bucket store, only effective for a single instance
+type Memory struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	idle   time.Duration
+}
+
+// sweepInterval 清理空闲令牌桶的间隔
+// Interval between sweeps of idle buckets
+const sweepInterval = time.Minute
+
+// NewMemory 创建进程内令牌桶存储
+// Create an in-process token bucket store
+func NewMemory() *Memory {
+	return &Memory{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+func (m *Memory) Allow(_ context.Context, key string, rule Rule) (Result, error) {
+	if !rule.Enabled() {
+		return Result{Allowed: true}, nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	if now.Sub(m.lastSweep) > sweepInterval {
+		m.sweep(now)
+	}
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: rule.burst(), last: now}
+		m.buckets[key] = b
+	}
+	b.idle = rule.idle()
+	b.tokens = math.Min(rule.burst(), b.tokens+now.Sub(b.last).Seconds()*rule.rate())
+	b.last = now
+	return take(&b.tokens, rule), nil
+}
+
+// sweep 丢弃已经补满的令牌桶，它们与新建的桶没有区别
+// Drop buckets that have refilled, they are no different from new ones
+func (m *Memory) sweep(now time.Time) {
+	for key, b := range m.buckets {
+		if now.Sub(b.last) > b.idle {
+			delete(m.buckets, key)
+		}
+	}
+	m.lastSweep = now
+}
+
+// take 尝试取出一个令牌
+// Try to take one token
+func take(tokens *float64, rule Rule) Result {
+	if *tokens >= 1 {
+		*tokens--
+		return Result{Allowed: true, Remaining: int(*tokens)}
+	}
+	wait := (1 - *tokens) / rule.rate()
+	return Result{RetryAfter: time.Duration(math.Ceil(wait * float64(time.Second)))}
+}
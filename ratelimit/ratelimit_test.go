@@ -0,0 +1,65 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryTokenBucket(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	m := NewMemory()
+	m.now = func() time.Time { return now }
+	rule := Rule{Limit: 2, Period: time.Second, Burst: 3}
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if res, _ := m.Allow(ctx, "ip:1", rule); !res.Allowed || res.Remaining != 2-i {
+			t.Fatalf("request %d: got %+v, want allowed with %d remaining", i, res, 2-i)
+		}
+	}
+	res, _ := m.Allow(ctx, "ip:1", rule)
+	if res.Allowed || res.RetryAfter != 500*time.Millisecond {
+		t.Fatalf("burst exhausted: got %+v, want refused with 500ms retry", res)
+	}
+	if res, _ := m.Allow(ctx, "ip:2", rule); !res.Allowed {
+		t.Fatal("other keys should have their own bucket")
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if res, _ := m.Allow(ctx, "ip:1", rule); !res.Allowed {
+		t.Fatal("a token should be refilled after 500ms")
+	}
+	now = now.Add(time.Hour)
+	if res, _ := m.Allow(ctx, "ip:1", rule); !res.Allowed || res.Remaining != 2 {
+		t.Fatalf("refill should stop at the burst, got %+v", res)
+	}
+}
+
+func TestMemorySweep(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	m := NewMemory()
+	m.now = func() time.Time { return now }
+	rule := Rule{Limit: 1, Period: time.Second}
+	m.Allow(context.Background(), "a", rule)
+	now = now.Add(2 * sweepInterval)
+	m.Allow(context.Background(), "b", rule)
+	if _, ok := m.buckets["a"]; ok {
+		t.Error("idle buckets should be swept")
+	}
+	if _, ok := m.buckets["b"]; !ok {
+		t.Error("the current bucket should be kept")
+	}
+}
+
+func TestDisabledRule(t *testing.T) {
+	m := NewMemory()
+	for i := 0; i < 100; i++ {
+		if res, _ := m.Allow(context.Background(), "x", Rule{}); !res.Allowed {
+			t.Fatal("a zero rule should not limit")
+		}
+	}
+	if len(m.buckets) != 0 {
+		t.Error("a zero rule should not create buckets")
+	}
+}
@@ -0,0 +1,158 @@
+package resps
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/LiteyukiStudio/spage/i18n"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+	"gorm.io/gorm"
+)
+
+// 错误码，客户端应根据错误码而不是消息判断错误，消息会随语言变化
+// Error codes, clients should tell errors apart by code rather than message, which changes with the language
+const (
+	CodeBadRequest         = "bad_request"
+	CodeInvalidParameter   = "invalid_parameter"
+	CodeMissingParameter   = "missing_parameter"
+	CodeUnauthorized       = "unauthorized"
+	CodeInvalidToken       = "invalid_token"
+	CodeInvalidCredentials = "invalid_credentials"
+	CodeTwoFactorRequired  = "two_factor_required"
+	CodeEmailNotVerified   = "email_not_verified"
+	CodeAccountLocked      = "account_locked"
+	CodeAccountDisabled    = "account_disabled"
+	CodeCaptchaFailed      = "captcha_failed"
+	CodeCSRFFailed         = "csrf_failed"
+	CodeForbidden          = "forbidden"
+	CodeInsufficientScope  = "insufficient_scope"
+	CodeInvitationRequired = "invitation_required"
+	CodeInvitationInvalid  = "invitation_invalid"
+	CodeNotFound           = "not_found"
+	CodeConflict           = "conflict"
+	CodePrecondition       = "precondition_failed"
+	CodeWeakPassword       = "weak_password"
+	CodePayloadTooLarge    = "payload_too_large"
+	CodeQuotaExceeded      = "quota_exceeded"
+	CodeRateLimited        = "rate_limited"
+	CodeInternal           = "internal_error"
+	CodeUnavailable        = "service_unavailable"
+	CodeReadOnly           = "read_only"
+	CodeValidationFailed   = "validation_failed"
+	CodeUnsafeArchive      = "unsafe_archive"
+)
+
+// ErrorBody 统一的错误响应体
+// Uniform body of error responses
+type ErrorBody struct {
+	Code      string `json:"code"`                 // 错误码 Error code
+	Message   string `json:"message"`              // 按请求语言翻译的提示 Message translated into the language of the request
+	Details   any    `json:"details,omitempty"`    // 附加信息 Additional information
+	RequestID string `json:"request_id,omitempty"` // 请求ID，与响应头 X-Request-ID 一致 Request ID, the same as the X-Request-ID response header
+}
+
+// statusCodes 各状态码的默认错误码 Default error code of each status
+var statusCodes = map[int]string{
+	http.StatusBadRequest:            CodeBadRequest,
+	http.StatusUnauthorized:          CodeUnauthorized,
+	http.StatusForbidden:             CodeForbidden,
+	http.StatusNotFound:              CodeNotFound,
+	http.StatusConflict:              CodeConflict,
+	http.StatusPreconditionFailed:    CodePrecondition,
+	http.StatusRequestEntityTooLarge: CodePayloadTooLarge,
+	http.StatusTooManyRequests:       CodeRateLimited,
+	http.StatusServiceUnavailable:    CodeUnavailable,
+}
+
+// messageCodes 客户端需要区分的消息对应的错误码，其余消息使用状态码的默认错误码
+// Error codes of messages clients need to tell apart, other messages use the default code of their status
+var messageCodes = map[string]string{
+	ParameterError:                                               CodeInvalidParameter,
+	MissingParameter:                                             CodeMissingParameter,
+	TooManyRequestsMessage:                                       CodeRateLimited,
+	"Invalid token":                                              CodeInvalidToken,
+	"Refresh token not found":                                    CodeInvalidToken,
+	"Refresh token expired or invalid":                           CodeInvalidToken,
+	"Token scope does not allow this request":                    CodeInsufficientScope,
+	"Deploy key does not allow this request":                     CodeInsufficientScope,
+	"Incorrect password":                                         CodeInvalidCredentials,
+	"User does not exist":                                        CodeInvalidCredentials,
+	"Incorrect code":                                             CodeInvalidCredentials,
+	"Two-factor authentication required":                         CodeTwoFactorRequired,
+	"Email is not verified, please check your inbox":             CodeEmailNotVerified,
+	"Captcha verification failed":                                CodeCaptchaFailed,
+	"Account is temporarily locked after too many failed logins": CodeAccountLocked,
+	"Account is disabled":                                        CodeAccountDisabled,
+	"CSRF token missing or invalid":                              CodeCSRFFailed,
+	"Registration requires an invitation":                        CodeInvitationRequired,
+	"Invalid or expired invitation":                              CodeInvitationInvalid,
+	"Password complexity is too low":                             CodeWeakPassword,
+	"Username already exists":                                    CodeConflict,
+	"organization name already exists":                           CodeConflict,
+	"sub_domain is already used":                                 CodeConflict,
+	"quota exceeded":                                             CodeQuotaExceeded,
+	"The instance is read-only for maintenance, retry later":     CodeReadOnly,
+}
+
+// errorCode 消息对应的错误码，"消息: 详情" 按冒号前的部分查找
+// Error code of a message, "message: detail" is looked up by the part before the colon
+func errorCode(status int, message string) string {
+	if code, ok := messageCodes[message]; ok {
+		return code
+	}
+	if head, _, ok := strings.Cut(message, ": "); ok {
+		if code, ok := messageCodes[head]; ok {
+			return code
+		}
+	}
+	if code, ok := statusCodes[status]; ok {
+		return code
+	}
+	if status >= 500 {
+		return CodeInternal
+	}
+	return CodeBadRequest
+}
+
+// Fail 以指定错误码返回错误响应，details 为空时省略
+// Respond with an error of the given code, details are omitted when nil
+func Fail(c *app.RequestContext, status int, code, message string, details any) {
+	c.JSON(status, ErrorBody{
+		Code:      code,
+		Message:   translate(c, message),
+		Details:   details,
+		RequestID: string(c.Response.Header.Peek(utils.RequestIDHeader)),
+	})
+}
+
+// fail 以消息推断的错误码返回错误响应
+// Respond with an error whose code is inferred from the message
+func fail(c *app.RequestContext, status int, message string) {
+	Fail(c, status, errorCode(status, message), message, nil)
+}
+
+// DBError 按数据库错误返回响应：记录不存在为 404，违反唯一约束为 409，其余为 500 并使用 message
+// Respond according to a database error: 404 for missing records, 409 for unique violations, 500 with message otherwise
+func DBError(c *app.RequestContext, err error, message string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		Fail(c, http.StatusNotFound, CodeNotFound, TargetNotFound, nil)
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		Fail(c, http.StatusConflict, CodeConflict, AlreadyExists, nil)
+	default:
+		Fail(c, http.StatusInternalServerError, CodeInternal, message, nil)
+	}
+}
+
+// Invalid 字段校验失败的 400 响应，details.fields 按顺序列出每个字段未通过的规则和提示
+// 400 response of failed field validation, details.fields lists the failed rule and message of every field in order
+func Invalid(c *app.RequestContext, errs utils.ValidationErrors) {
+	lang := i18n.Language(c)
+	fields := make([]map[string]string, 0, len(errs))
+	for _, e := range errs {
+		fields = append(fields, map[string]string{"field": e.Field, "rule": e.Rule, "message": e.Message.In(lang)})
+	}
+	Fail(c, http.StatusBadRequest, CodeValidationFailed, ValidationFailed, map[string]any{"fields": fields})
+}
@@ -0,0 +1,72 @@
+package resps
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+	"gorm.io/gorm"
+)
+
+func TestErrorCode(t *testing.T) {
+	cases := []struct {
+		status  int
+		message string
+		want    string
+	}{
+		{400, ParameterError, CodeInvalidParameter},
+		{400, RespMessageWithError(ParameterError, errors.New("bad json")), CodeInvalidParameter},
+		{401, TargetNotFound, CodeUnauthorized},
+		{404, TargetNotFound, CodeNotFound},
+		{412, "The resource does not match the precondition", CodePrecondition},
+		{403, "Two-factor authentication required", CodeTwoFactorRequired},
+		{400, "something else", CodeBadRequest},
+		{418, "teapot", CodeBadRequest},
+		{502, "upstream failed", CodeInternal},
+	}
+	for _, c := range cases {
+		if got := errorCode(c.status, c.message); got != c.want {
+			t.Errorf("errorCode(%d, %q) = %q, want %q", c.status, c.message, got, c.want)
+		}
+	}
+}
+
+func TestDBError(t *testing.T) {
+	cases := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{gorm.ErrRecordNotFound, 404, CodeNotFound},
+		{fmt.Errorf("create site: %w", gorm.ErrDuplicatedKey), 409, CodeConflict},
+		{errors.New("disk I/O error"), 500, CodeInternal},
+	}
+	for _, tc := range cases {
+		c := app.NewContext(0)
+		c.Response.Header.Set(utils.RequestIDHeader, "req-1")
+		DBError(c, tc.err, "save site error")
+		var body ErrorBody
+		if err := json.Unmarshal(c.Response.Body(), &body); err != nil {
+			t.Fatal(err)
+		}
+		if c.Response.StatusCode() != tc.status || body.Code != tc.code || body.RequestID != "req-1" || body.Message == "" {
+			t.Errorf("DBError(%v) = %d %+v", tc.err, c.Response.StatusCode(), body)
+		}
+	}
+}
+
+func TestCustomError(t *testing.T) {
+	c := app.NewContext(0)
+	Custom(c, 413, "quota exceeded", map[string]any{"limit": 10})
+	var body map[string]any
+	if err := json.Unmarshal(c.Response.Body(), &body); err != nil {
+		t.Fatal(err)
+	}
+	details, _ := body["details"].(map[string]any)
+	if body["code"] != CodeQuotaExceeded || body["limit"] != float64(10) || details["limit"] != float64(10) {
+		t.Errorf("body = %v", body)
+	}
+}
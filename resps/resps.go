@@ -1,14 +1,32 @@
 package resps
 
-import "github.com/cloudwego/hertz/pkg/app"
+import (
+	"github.com/LiteyukiStudio/spage/i18n"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
+)
 
-// 自定义响应
-// Custom response
+// 自定义响应，4xx 和 5xx 使用统一的错误响应体，data 放入 details 并为兼容旧客户端同时保留在顶层
+// Custom response, 4xx and 5xx use the uniform error body with data in details, also kept at the top level for older clients
 func Custom(c *app.RequestContext, code int, message string, data ...map[string]any) {
 	if len(data) == 0 {
 		data = append(data, map[string]any{})
 	}
-	data[0]["message"] = message
+	if code >= 400 {
+		body := map[string]any{}
+		for key, value := range data[0] {
+			body[key] = value
+		}
+		if len(data[0]) > 0 {
+			body["details"] = data[0]
+		}
+		body["code"] = errorCode(code, message)
+		body["message"] = translate(c, message)
+		body["request_id"] = string(c.Response.Header.Peek(utils.RequestIDHeader))
+		c.JSON(code, body)
+		return
+	}
+	data[0]["message"] = translate(c, message)
 	c.JSON(code, data[0])
 }
 
@@ -18,38 +36,66 @@ func Ok(c *app.RequestContext, message string, data ...map[string]any) {
 	if len(data) == 0 {
 		data = append(data, map[string]any{})
 	}
-	data[0]["message"] = message
+	data[0]["message"] = translate(c, message)
 	c.JSON(200, data[0])
 }
 
 // 4xx
 
+// BadRequestf 翻译格式字符串后格式化的 400 响应
+// 400 response formatted after its format string is translated
+func BadRequestf(c *app.RequestContext, format string, args ...any) {
+	lang := i18n.Language(c)
+	c.Header("Content-Language", lang)
+	c.JSON(400, ErrorBody{
+		Code:      errorCode(400, format),
+		Message:   i18n.Sprintf(lang, format, args...),
+		RequestID: string(c.Response.Header.Peek(utils.RequestIDHeader)),
+	})
+}
+
 func BadRequest(c *app.RequestContext, message string) {
-	c.JSON(400, map[string]string{"message": message})
+	fail(c, 400, message)
 }
 
 func Unauthorized(c *app.RequestContext, message string) {
-	c.JSON(401, map[string]string{"message": message})
+	fail(c, 401, message)
 }
 
 func Forbidden(c *app.RequestContext, message string) {
-	c.JSON(403, map[string]string{"message": message})
+	fail(c, 403, message)
 }
 
 func NotFound(c *app.RequestContext, message string) {
-	c.JSON(404, map[string]string{"message": message})
+	fail(c, 404, message)
+}
+
+func PreconditionFailed(c *app.RequestContext, message string) {
+	fail(c, 412, message)
+}
+
+func TooManyRequests(c *app.RequestContext, message string) {
+	fail(c, 429, message)
 }
 
 // 5xx
 
 func InternalServerError(c *app.RequestContext, message string) {
-	c.JSON(500, map[string]string{"message": message})
+	fail(c, 500, message)
 }
 
 func ServiceUnavailable(c *app.RequestContext, message string) {
-	c.JSON(503, map[string]string{"message": message})
+	fail(c, 503, message)
 }
 
 func RespMessageWithError(message string, err error) string {
 	return message + ": " + err.Error()
 }
+
+// translate 以请求协商的语言表示消息，并在响应头中说明语言
+// Express the message in the language negotiated for the request and state the language in the response header
+func translate(c *app.RequestContext, message string) string {
+	lang := i18n.Language(c)
+	c.Header("Content-Language", lang)
+	return i18n.T(lang, message)
+}
@@ -1,9 +1,13 @@
 package resps
 
 const (
-	ParameterError   = "Parameter error"   // 参数错误
-	MissingParameter = "Missing parameter" // 缺少参数
-	TargetNotFound   = "Target not found"  // 目标不存在
-	OK               = "OK"                // 操作成功
-	PermissionDenied = "PermissionDenied"  // 权限不足
+	ParameterError   = "Parameter error"       // 参数错误
+	MissingParameter = "Missing parameter"     // 缺少参数
+	TargetNotFound   = "Target not found"      // 目标不存在
+	AlreadyExists    = "Target already exists" // 目标已存在
+	ValidationFailed = "Validation failed"     // 参数校验失败
+	OK               = "OK"                    // 操作成功
+	PermissionDenied = "PermissionDenied"      // 权限不足
+
+	TooManyRequestsMessage = "Too many requests, retry later" // 请求过于频繁
 )
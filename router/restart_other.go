@@ -0,0 +1,26 @@
+//go:build windows || plan9
+
+package router
+
+import (
+	"context"
+	"errors"
+	"syscall"
+
+	"github.com/sirupsen/logrus"
+)
+
+// reusePort 当前平台不支持 SO_REUSEPORT
+// SO_REUSEPORT is not supported on this platform
+func reusePort(network, address string, conn syscall.RawConn) error {
+	return errors.New("server.reuse-port is not supported on this platform")
+}
+
+// WatchRestart 当前平台不支持平滑重启
+// Graceful restart is not supported on this platform
+func WatchRestart(ctx context.Context) context.Context {
+	logrus.Warn("server.graceful-restart is not supported on this platform, ignored")
+	return ctx
+}
+
+func notifyReady() {}
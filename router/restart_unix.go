@@ -0,0 +1,125 @@
+//go:build !windows && !plan9
+
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
+)
+
+// readyFDEnv 平滑重启时传给新进程的就绪管道的文件描述符
+// File descriptor of the readiness pipe passed to the new process of a graceful restart
+const readyFDEnv = "SPAGE_READY_FD"
+
+// restartReadyTimeout 等待新进程就绪的最长时间，新进程启动时可能需要执行迁移
+// Longest wait for the new process to become ready, it may run migrations while starting
+const restartReadyTimeout = 2 * time.Minute
+
+// reusePort 为监听的套接字设置 SO_REUSEPORT
+// Set SO_REUSEPORT on the listening socket
+func reusePort(network, address string, conn syscall.RawConn) error {
+	var err error
+	if cerr := conn.Control(func(fd uintptr) {
+		err = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_REUSEPORT, 1)
+	}); cerr != nil {
+		return cerr
+	}
+	return err
+}
+
+// WatchRestart 收到 SIGUSR2 时启动新进程，新进程开始监听后结束返回的上下文以平滑退出；新进程启动失败时继续服务
+// Start a new process on SIGUSR2 and end the returned context once it listens so this one exits gracefully; serving continues when the new process fails to start
+func WatchRestart(ctx context.Context) context.Context {
+	ctx, cancel := context.WithCancel(ctx)
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR2)
+	go func() {
+		defer signal.Stop(signals)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-signals:
+			}
+			logrus.Info("Graceful restart requested, starting a new process...")
+			pid, err := spawn()
+			if err != nil {
+				logrus.Errorf("Graceful restart failed, keep serving: %v", err)
+				continue
+			}
+			logrus.Infof("New process %d is ready, handing over", pid)
+			cancel()
+			return
+		}
+	}()
+	return ctx
+}
+
+// spawn 以相同的参数启动当前可执行文件并等待其就绪
+// Start the current executable with the same arguments and wait until it is ready
+func spawn() (int, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return 0, err
+	}
+	r, w, err := os.Pipe()
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+	cmd := exec.Command(executable, os.Args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	// ExtraFiles 中的第一个文件在新进程中为 3 The first of ExtraFiles is 3 in the new process
+	cmd.ExtraFiles = []*os.File{w}
+	cmd.Env = append(os.Environ(), readyFDEnv+"=3")
+	err = cmd.Start()
+	_ = w.Close()
+	if err != nil {
+		return 0, err
+	}
+	// 新进程退出前一直等待，避免僵尸进程 Reap the new process whenever it exits so no zombie is left
+	go func() {
+		_ = cmd.Wait()
+	}()
+	_ = r.SetReadDeadline(time.Now().Add(restartReadyTimeout))
+	if _, err := r.Read(make([]byte, 1)); err != nil {
+		_ = cmd.Process.Kill()
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			return 0, fmt.Errorf("process %d was not ready within %s", cmd.Process.Pid, restartReadyTimeout)
+		}
+		return 0, fmt.Errorf("process %d exited before it was ready", cmd.Process.Pid)
+	}
+	return cmd.Process.Pid, nil
+}
+
+// notifyReady 由平滑重启启动时通知旧进程新进程已开始监听
+// Tell the old process that this one listens when started by a graceful restart
+func notifyReady() {
+	value := os.Getenv(readyFDEnv)
+	if value == "" {
+		return
+	}
+	_ = os.Unsetenv(readyFDEnv)
+	fd, err := strconv.Atoi(value)
+	if err != nil {
+		return
+	}
+	f := os.NewFile(uintptr(fd), "ready")
+	if f == nil {
+		return
+	}
+	defer f.Close()
+	if _, err := f.Write([]byte{1}); err != nil {
+		logrus.Warnf("Failed to notify the previous process: %v", err)
+	}
+}
@@ -1,26 +1,200 @@
 package router
 
 import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/certs"
 	"github.com/LiteyukiStudio/spage/config"
 	"github.com/LiteyukiStudio/spage/handlers"
 	"github.com/LiteyukiStudio/spage/middle"
+	"github.com/LiteyukiStudio/spage/openapi"
+	"github.com/LiteyukiStudio/spage/ratelimit"
+	"github.com/LiteyukiStudio/spage/serve"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/app/server"
+	hconfig "github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/sirupsen/logrus"
 )
 
-// Run 运行路由服务
-// Run router service
-func Run() error {
+// readyPollInterval 等待服务开始监听时的检查间隔
+// Interval between checks while waiting for the servers to listen
+const readyPollInterval = 10 * time.Millisecond
+
+// bodyPrefetchSize 读取请求头后预先读入内存的请求体字节数，其余部分在处理请求时以流的方式读取并受请求体限制约束
+// Body bytes read into memory along with the headers, the rest is streamed while handling the request and bound by the body limits
+const bodyPrefetchSize = 1 << 20
+
+// Run 运行路由服务直到 ctx 结束，随后停止接受新连接并等待进行中的请求完成，最多等待 server.shutdown-timeout
+// Run router service until ctx is done, then stop accepting connections and wait for in-flight requests for at most server.shutdown-timeout
+func Run(ctx context.Context) error {
+	timeout := time.Duration(config.ServerShutdownTimeout) * time.Second
+	options := []hconfig.Option{
+		server.WithExitWaitTime(timeout),
+		server.WithStreamBody(true),
+		server.WithMaxRequestBodySize(bodyPrefetchSize),
+		server.WithDisablePreParseMultipartForm(true),
+		// 客户端断开时取消请求上下文，进行中的查询随之中止 Cancel the request context when the client disconnects so running queries stop
+		server.WithSenseClientDisconnection(true),
+	}
+	if config.ServerReusePort {
+		options = append(options, server.WithListenConfig(&net.ListenConfig{Control: reusePort}))
+	}
+
 	// 运行路由 Run router
-	H := server.New(server.WithHostPorts(":" + config.ServerPort))
-	H.Use(middle.Cors.UseCors(), middle.Trace.UseTrace())
+	H := server.New(append(options, server.WithHostPorts(":"+config.ServerPort))...)
+	register(H)
+	servers := []*server.Hertz{H}
+	errs := make(chan error, len(servers)+1)
+
+	// 启用 TLS 终止时在 HTTPS 端口解密后转发给 HTTP 服务 Decrypt on the HTTPS port and forward to the HTTP server when TLS termination is enabled
+	var proxy *tlsProxy
+	if certs.Serving() {
+		var err error
+		if proxy, err = newTLSProxy(ctx); err != nil {
+			return err
+		}
+		go func() {
+			errs <- proxy.Run()
+		}()
+	}
+
+	// 运行服务 Run service
+	for _, h := range servers {
+		go func() {
+			errs <- h.Run()
+		}()
+	}
+	if err := waitListening(ctx, servers, errs); err != nil {
+		return err
+	}
+	// 作为平滑重启的新进程时通知旧进程可以退出 Tell the old process it may exit when started by a graceful restart
+	notifyReady()
+
+	var err error
+	select {
+	case <-ctx.Done():
+	case err = <-errs:
+	}
+	logrus.Info("Shutting down, waiting for in-flight requests...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	// 先等待转发中的 HTTPS 请求完成，它们依赖 HTTP 服务 Wait for forwarded HTTPS requests first, they depend on the HTTP server
+	if proxy != nil {
+		if err := proxy.Shutdown(shutdownCtx); err != nil && !errors.Is(err, context.DeadlineExceeded) {
+			logrus.Warnf("Shutting down HTTPS server failed: %v", err)
+		}
+	}
+	var wg sync.WaitGroup
+	for _, h := range servers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := h.Shutdown(shutdownCtx); err != nil && !errors.Is(err, context.DeadlineExceeded) {
+				logrus.Warnf("Shutting down server failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	return err
+}
+
+// waitListening 等待所有服务开始监听，任一服务启动失败时返回其错误
+// Wait until all servers listen, returning the error of a server that fails to start
+func waitListening(ctx context.Context, servers []*server.Hertz, errs <-chan error) error {
+	ticker := time.NewTicker(readyPollInterval)
+	defer ticker.Stop()
+	for {
+		ready := true
+		for _, h := range servers {
+			ready = ready && h.IsRunning()
+		}
+		if ready {
+			return nil
+		}
+		select {
+		case err := <-errs:
+			return err
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// clientIPOptions 只信任来自 server.trusted-proxies 的 X-Forwarded-For 和 X-Real-IP，防止客户端伪造地址绕过限流和 IP 规则
+// Only trust X-Forwarded-For and X-Real-IP from server.trusted-proxies, so clients cannot forge their address past rate limits and IP rules
+func clientIPOptions() app.ClientIPOptions {
+	trusted := make([]*net.IPNet, 0, len(config.ServerTrustedProxies))
+	for _, rule := range config.ServerTrustedProxies {
+		prefix, err := serve.ParseIPRule(rule)
+		if err != nil {
+			logrus.Warnf("Ignoring trusted proxy: %v", err)
+			continue
+		}
+		trusted = append(trusted, &net.IPNet{IP: prefix.Addr().AsSlice(), Mask: net.CIDRMask(prefix.Bits(), prefix.Addr().BitLen())})
+	}
+	return app.ClientIPOptions{
+		RemoteIPHeaders: []string{"X-Forwarded-For", "X-Real-IP"},
+		TrustedCIDRs:    trusted,
+	}
+}
+
+// register 注册中间件和路由
+// Register middlewares and routes
+func register(H *server.Hertz) {
+	H.SetClientIPFunc(app.ClientIPWithOption(clientIPOptions()))
+	H.Use(middle.RequestID.UseRequestID(), middle.Tracing.UseTracing(), middle.BodyLimit.Use(), middle.TLS.UseTLS(), middle.Trace.UseTrace(), middle.Serve.UseServe(), middle.Cors.UseCors(), middle.Maintenance.UseReadOnly())
 	apiV1 := H.Group("/api/v1")
 	apiV1.Use(middle.Auth.UseAuth())
 	apiV1WithoutAuth := H.Group("/api/v1")
 	{
-		apiV1WithoutAuth.POST("/user/register", handlers.User.Register).Use(middle.Captcha.UseCaptcha()) // 注册 Register
-		apiV1WithoutAuth.POST("/user/login", handlers.User.Login).Use(middle.Captcha.UseCaptcha())
-		apiV1WithoutAuth.GET("/user/captcha", handlers.User.GetCaptcha) // 获取验证码 Get captcha
-		apiV1WithoutAuth.POST("/user/logout", handlers.User.Logout)
+		authLimit := middle.RateLimit.UseRateLimit(ratelimit.GroupAuth) // 认证接口限流 Rate limit of auth endpoints
+
+		apiV1WithoutAuth.POST("/user/register", authLimit, middle.Captcha.UseCaptcha(), handlers.User.Register) // 注册 Register
+		apiV1WithoutAuth.POST("/user/login", authLimit, handlers.User.Login)                                    // 登录，需要时校验验证码 Login, checking the captcha when needed
+
+		apiV1WithoutAuth.GET("/user/captcha", handlers.User.GetCaptcha)                                                      // 获取验证码 Get captcha
+		apiV1WithoutAuth.POST("/user/logout", handlers.User.Logout)                                                          // 登出并撤销当前会话 Log out and revoke the current session
+		apiV1WithoutAuth.POST("/user/token/refresh", authLimit, handlers.User.Refresh)                                       // 刷新访问令牌 Refresh the access token
+		apiV1WithoutAuth.POST("/user/login/2fa", authLimit, handlers.TwoFactor.Login)                                        // 完成两步验证登录 Complete two-factor login
+		apiV1WithoutAuth.POST("/user/email/verify", authLimit, handlers.User.VerifyEmail)                                    // 验证邮箱 Verify the email address
+		apiV1WithoutAuth.POST("/user/password/forgot", authLimit, middle.Captcha.UseCaptcha(), handlers.User.ForgotPassword) // 申请重置密码 Ask for a password reset
+		apiV1WithoutAuth.POST("/user/password/reset", authLimit, handlers.User.ResetPassword)                                // 重置密码 Reset the password
+		apiV1WithoutAuth.GET("/invitation/:token", authLimit, handlers.Invitation.Get)                                       // 查看邀请 Look up an invitation
+		apiV1WithoutAuth.GET("/site/:site_id/og.png", handlers.Release.PreviewImage)                                         // 获取站点预览图 Get site preview image
+		apiV1WithoutAuth.GET("/avatars/:owner_type/:owner_id/:file", handlers.Avatar.Get)                                    // 获取上传的头像 Get an uploaded avatar
+		apiV1WithoutAuth.GET("/site/:site_id/access", handlers.Site.Access)                                                  // 以项目成员或私有实例用户的身份访问站点 Visit a site as a project member or a user of the private instance
+		apiV1WithoutAuth.GET("/badge/:project/deploy-status.svg", handlers.Badge.DeployStatus)                               // 获取部署状态徽章 Get the deployment status badge
+		apiV1WithoutAuth.GET("/feed/project/:name/deployments.atom", handlers.Feed.Project)                                  // 获取项目的部署订阅源 Get the deployment feed of a project
+		apiV1WithoutAuth.GET("/feed/project/:name/deployments.rss", handlers.Feed.Project)                                   // 获取项目的部署订阅源 Get the deployment feed of a project
+		apiV1WithoutAuth.GET("/feed/org/:name/deployments.atom", handlers.Feed.Org)                                          // 获取组织的部署订阅源 Get the deployment feed of an organization
+		apiV1WithoutAuth.GET("/feed/org/:name/deployments.rss", handlers.Feed.Org)                                           // 获取组织的部署订阅源 Get the deployment feed of an organization
+		apiV1WithoutAuth.POST("/hooks/git/:id", handlers.Git.Receive)                                                        // 接收仓库推送事件 Receive repository push events
+
+		runnerGroup := apiV1WithoutAuth.Group("/runner", middle.Auth.UseRunner()) // 远程构建执行器使用执行器令牌认证 Remote build runners authenticate with runner tokens
+		{
+			runnerGroup.POST("/register", handlers.Runner.Register)                                                                   // 执行器注册 Register the runner
+			runnerGroup.POST("/heartbeat", handlers.Runner.Heartbeat)                                                                 // 执行器心跳 Runner heartbeat
+			runnerGroup.POST("/builds/claim", handlers.Runner.Claim)                                                                  // 领取构建 Claim a build
+			runnerGroup.PUT("/builds/:build_id/log", handlers.Runner.Log)                                                             // 上报构建输出 Report build output
+			runnerGroup.POST("/builds/:build_id/fail", handlers.Runner.Fail)                                                          // 上报构建失败 Report a failed build
+			runnerGroup.POST("/builds/:build_id/result", middle.BodyLimit.Limit(handlers.Runner.UploadLimit), handlers.Runner.Result) // 上传构建结果 Upload the build result
+		}
+
+		apiV1WithoutAuth.GET("/user/oidc", handlers.OIDC.Providers)                                 // 获取登录提供方 Get auth providers
+		apiV1WithoutAuth.GET("/user/oidc/:provider_id/login", authLimit, handlers.OIDC.Login)       // 跳转到提供方登录 Redirect to the provider
+		apiV1WithoutAuth.GET("/user/oidc/:provider_id/callback", authLimit, handlers.OIDC.Callback) // 提供方回调 Provider callback
+		apiV1.GET("/search", handlers.Search.User)                                                  // 检索可访问的项目、站点、用户和组织 Search accessible projects, sites, users and organizations
+		apiV1.POST("/graphql", handlers.GraphQL.Query)                                              // 执行 GraphQL 查询 Run a GraphQL query
+		apiV1.GET("/graphql", handlers.GraphQL.Schema)                                              // 获取 GraphQL 模式定义 Get the GraphQL schema definition
+
+		uploadLimit := middle.RateLimit.UseRateLimit(ratelimit.GroupUpload) // 上传接口限流 Rate limit of upload endpoints
+		uploadBody := middle.BodyLimit.Limit(handlers.Quota.UploadLimit)    // 上传接口的请求体限制 Body limit of upload endpoints
 		userGroup := apiV1.Group("/user")
 		{
 			userGroup.PUT("", handlers.User.UpdateUser)               // 更新用户信息 Update user info
@@ -28,6 +202,51 @@ func Run() error {
 			userGroup.GET("/:id", handlers.User.GetUser)              // 获取用户信息 Get user info
 			userGroup.GET("/:id/projects", handlers.User.GetProjects) // 获取用户项目 Get user projects
 			userGroup.GET("/:id/orgs", handlers.User.GetOrgs)         // 获取用户组织 Get user orgs
+			userGroup.GET("/quota", handlers.Quota.User)              // 获取配额和用量 Get quota and usage
+			userGroup.GET("/tenant", handlers.Tenant.Current)         // 获取所属租户 Get the tenant of the user
+			userGroup.GET("/trash", handlers.Trash.User)              // 获取回收站中的项目 List projects in the trash
+
+			userGroup.PUT("/avatar", uploadLimit, uploadBody, handlers.Avatar.UploadUser) // 上传头像 Upload avatar
+			userGroup.DELETE("/avatar", handlers.Avatar.DeleteUser)                       // 删除头像 Delete avatar
+
+			userGroup.POST("/email/verify/resend", authLimit, handlers.User.ResendVerification) // 重新发送验证邮件 Send the verification email again
+			userGroup.POST("/invitations/accept", handlers.Invitation.Accept)                   // 使用邀请加入组织 Join an organization with an invitation
+
+			userGroup.GET("/transfers", handlers.Transfer.Incoming)                    // 获取待接受的项目转移 List incoming project transfers
+			userGroup.POST("/transfers/:transfer_id/accept", handlers.Transfer.Accept) // 接受项目转移 Accept a project transfer
+			userGroup.DELETE("/transfers/:transfer_id", handlers.Transfer.Decline)     // 拒绝项目转移 Decline a project transfer
+
+			userGroup.GET("/oidc/:provider_id/link", handlers.OIDC.Link)             // 关联提供方身份 Link a provider identity
+			userGroup.DELETE("/oidc/:provider_id/link", handlers.OIDC.Unlink)        // 解除关联 Unlink a provider identity
+			userGroup.POST("/ldap/:provider_id/link", authLimit, handlers.LDAP.Link) // 关联目录账号 Link a directory account
+			userGroup.GET("/identities", handlers.OIDC.Identities)                   // 获取已关联的身份 Get linked identities
+
+			userGroup.GET("/deletion", handlers.Account.DeletionStatus)    // 获取账号删除状态 Get the account deletion status
+			userGroup.POST("/deletion", handlers.Account.RequestDeletion)  // 申请删除账号 Ask for the account's deletion
+			userGroup.DELETE("/deletion", handlers.Account.CancelDeletion) // 取消删除账号 Cancel the account's deletion
+			userGroup.GET("/export", handlers.Account.Export)              // 导出个人数据 Export personal data
+
+			userGroup.GET("/notifications", handlers.Notification.List)                              // 获取通知渠道 List notification channels
+			userGroup.POST("/notifications", handlers.Notification.Create)                           // 创建通知渠道 Create notification channel
+			userGroup.PUT("/notifications/:channel_id", handlers.Notification.Update)                // 更新通知渠道 Update notification channel
+			userGroup.DELETE("/notifications/:channel_id", handlers.Notification.Delete)             // 删除通知渠道 Delete notification channel
+			userGroup.GET("/notifications/:channel_id/deliveries", handlers.Notification.Deliveries) // 获取投递记录 List deliveries
+			userGroup.POST("/notifications/:channel_id/test", handlers.Notification.Test)            // 发送测试通知 Send a test notification
+
+			userGroup.GET("/sessions", handlers.Session.List)                  // 获取登录会话 List sign-in sessions
+			userGroup.DELETE("/sessions", handlers.Session.RevokeAll)          // 在所有设备上登出 Log out everywhere
+			userGroup.DELETE("/sessions/:session_id", handlers.Session.Revoke) // 撤销登录会话 Revoke a sign-in session
+
+			userGroup.GET("/tokens", handlers.APIToken.List)                // 获取个人访问令牌 List personal access tokens
+			userGroup.POST("/tokens", handlers.APIToken.Create)             // 创建个人访问令牌 Create personal access token
+			userGroup.PUT("/tokens/:token_id", handlers.APIToken.Update)    // 更新个人访问令牌 Update personal access token
+			userGroup.DELETE("/tokens/:token_id", handlers.APIToken.Delete) // 撤销个人访问令牌 Revoke personal access token
+
+			userGroup.GET("/2fa", handlers.TwoFactor.Status)                                  // 获取两步验证状态 Get two-factor status
+			userGroup.POST("/2fa/totp", handlers.TwoFactor.Enroll)                            // 开始绑定 TOTP Start TOTP enrollment
+			userGroup.POST("/2fa/totp/confirm", handlers.TwoFactor.Confirm)                   // 确认并启用 TOTP Confirm and enable TOTP
+			userGroup.DELETE("/2fa/totp", handlers.TwoFactor.Disable)                         // 关闭两步验证 Disable two-factor authentication
+			userGroup.POST("/2fa/recovery-codes", handlers.TwoFactor.RegenerateRecoveryCodes) // 重新生成恢复码 Regenerate recovery codes
 		}
 		orgGroup := apiV1.Group("/org", handlers.Org.UserOrgAuth)
 		{
@@ -36,42 +255,238 @@ func Run() error {
 			orgGroup.DELETE("/:id", handlers.Org.DeleteOrganization)           // 删除组织 Delete organization
 			orgGroup.GET("/:id", handlers.Org.GetOrganization)                 // 获取组织信息 Get organization info
 			orgGroup.GET("/:id/projects", handlers.Org.GetOrganizationProject) // 获取组织项目 Get organization projects
-			orgGroup.GET("/:id/users", handlers.Org.GetOrganizationUsers)      // 获取组织所有成员和所有者 Get organization users
-			orgGroup.PUT("/:id/users", handlers.Org.AddOrganizationUser)       // 添加组织成员或所有者 Add organization user
-			orgGroup.DELETE("/:id/users", handlers.Org.DeleteOrganizationUser) // 删除组织成员或所有者 Delete organization user
+			orgGroup.GET("/:id/users", handlers.Org.GetOrganizationUsers)      // 获取组织成员及其角色 Get organization members and roles
+			orgGroup.GET("/:id/quota", handlers.Quota.Org)                     // 获取组织配额和用量 Get organization quota and usage
+			orgGroup.GET("/:id/content-policy", handlers.ContentPolicy.Org)    // 获取组织的内容策略 Get organization content policy
+			orgGroup.GET("/:id/trash", handlers.Trash.Org)                     // 获取回收站中的项目 List projects in the trash
+			orgGroup.PUT("/:id/users", handlers.Org.AddOrganizationUser)       // 添加组织成员或修改角色 Add organization member or change role
+			orgGroup.DELETE("/:id/users", handlers.Org.DeleteOrganizationUser) // 移除组织成员 Remove organization member
+
+			orgGroup.GET("/:id/teams", handlers.Team.List)                             // 获取组织团队 List teams
+			orgGroup.POST("/:id/teams", handlers.Team.Create)                          // 创建团队 Create team
+			orgGroup.PUT("/:id/teams/:team_id", handlers.Team.Update)                  // 更新团队 Update team
+			orgGroup.DELETE("/:id/teams/:team_id", handlers.Team.Delete)               // 删除团队 Delete team
+			orgGroup.GET("/:id/teams/:team_id/members", handlers.Team.Members)         // 获取团队成员 List team members
+			orgGroup.PUT("/:id/teams/:team_id/members", handlers.Team.AddMember)       // 添加团队成员 Add team member
+			orgGroup.DELETE("/:id/teams/:team_id/members", handlers.Team.RemoveMember) // 移除团队成员 Remove team member
+
+			orgGroup.GET("/:id/invitations", handlers.Invitation.OrgList)                     // 获取组织邀请 List invitations
+			orgGroup.POST("/:id/invitations", handlers.Invitation.OrgCreate)                  // 创建邀请 Create invitation
+			orgGroup.DELETE("/:id/invitations/:invitation_id", handlers.Invitation.OrgRevoke) // 撤销邀请 Revoke invitation
+
+			orgGroup.GET("/:id/notifications", handlers.Notification.List)                              // 获取通知渠道 List notification channels
+			orgGroup.POST("/:id/notifications", handlers.Notification.Create)                           // 创建通知渠道 Create notification channel
+			orgGroup.PUT("/:id/notifications/:channel_id", handlers.Notification.Update)                // 更新通知渠道 Update notification channel
+			orgGroup.DELETE("/:id/notifications/:channel_id", handlers.Notification.Delete)             // 删除通知渠道 Delete notification channel
+			orgGroup.GET("/:id/notifications/:channel_id/deliveries", handlers.Notification.Deliveries) // 获取投递记录 List deliveries
+			orgGroup.POST("/:id/notifications/:channel_id/test", handlers.Notification.Test)            // 发送测试通知 Send a test notification
+
+			orgGroup.PUT("/:id/preview-template", uploadLimit, uploadBody, handlers.Org.UploadPreviewTemplate) // 上传预览图模板 Upload preview template
+			orgGroup.PUT("/:id/avatar", uploadLimit, uploadBody, handlers.Avatar.UploadOrg)                    // 上传组织标志 Upload organization logo
+			orgGroup.DELETE("/:id/avatar", handlers.Avatar.DeleteOrg)                                          // 删除组织标志 Delete organization logo
 		}
 		projectGroup := apiV1.Group("/project", handlers.Project.UserProjectAuth)
 		{
-			projectGroup.POST("", handlers.Project.Create)                  // 创建项目 Create project
-			projectGroup.PUT("/:id", handlers.Project.Update)               // 更新项目 Update project
-			projectGroup.DELETE("/:id", handlers.Project.Delete)            // 删除项目 Delete project
-			projectGroup.GET("/:id", handlers.Project.Info)                 // 获取项目信息 Get project info
-			projectGroup.GET("/:id/owners", handlers.Project.GetOwners)     // 获取项目所有者 Get project owners
-			projectGroup.PUT("/:id/owner", handlers.Project.AddOwner)       // 更新项目所有者 Add project owner
-			projectGroup.DELETE("/:id/owner", handlers.Project.DeleteOwner) // 删除项目所有者 Delete project owner
-			projectGroup.GET("/:id/sites", handlers.Project.GetSites)       // 获取项目站点 Get project sites
+			projectGroup.POST("", handlers.Project.Create)                                // 创建项目 Create project
+			projectGroup.POST("/import", uploadLimit, uploadBody, handlers.Import.Create) // 从导出的压缩包或仓库导入项目 Import a project from an exported archive or a repository
+			projectGroup.PUT("/:id", handlers.Project.Update)                             // 更新项目 Update project
+			projectGroup.DELETE("/:id", handlers.Project.Delete)                          // 删除项目 Delete project
+			projectGroup.GET("/:id", handlers.Project.Info)                               // 获取项目信息 Get project info
+			projectGroup.GET("/:id/sites", handlers.Project.GetSites)                     // 获取项目站点 Get project sites
+			projectGroup.GET("/:id/export", handlers.Export.Project)                      // 导出项目的设置和内容 Export the project settings and content
+
+			projectGroup.GET("/:id/trash", handlers.Trash.Sites)                         // 获取回收站中的站点 List sites in the trash
+			projectGroup.POST("/:id/trash/:site_id/restore", handlers.Trash.RestoreSite) // 恢复站点 Restore a site
+			projectGroup.DELETE("/:id/trash/:site_id", handlers.Trash.PurgeSite)         // 彻底删除站点 Purge a site
+
+			projectGroup.GET("/:id/members", handlers.Project.Members)         // 获取项目成员 List project members
+			projectGroup.PUT("/:id/members", handlers.Project.SetMember)       // 添加项目成员或修改角色 Add project member or change role
+			projectGroup.DELETE("/:id/members", handlers.Project.RemoveMember) // 移除项目成员 Remove project member
+			projectGroup.GET("/:id/teams", handlers.Project.Teams)             // 获取项目团队 List project teams
+			projectGroup.PUT("/:id/teams", handlers.Project.SetTeam)           // 为团队授予项目角色 Grant a team a project role
+			projectGroup.DELETE("/:id/teams", handlers.Project.RemoveTeam)     // 撤销团队的项目角色 Revoke a team's project role
+
+			projectGroup.GET("/:id/transfer", handlers.Transfer.Get)       // 获取等待确认的转移 Get the pending transfer
+			projectGroup.POST("/:id/transfer", handlers.Transfer.Request)  // 发起项目转移 Request a project transfer
+			projectGroup.DELETE("/:id/transfer", handlers.Transfer.Cancel) // 取消项目转移 Cancel the project transfer
+
+			projectGroup.GET("/:id/retention", handlers.Retention.Get)    // 获取版本保留策略 Get the release retention policy
+			projectGroup.PUT("/:id/retention", handlers.Retention.Update) // 修改版本保留策略 Change the release retention policy
+
+			projectGroup.GET("/:id/deploy-keys", handlers.DeployKey.List)              // 获取部署密钥 List deploy keys
+			projectGroup.POST("/:id/deploy-keys", handlers.DeployKey.Create)           // 创建部署密钥 Create a deploy key
+			projectGroup.DELETE("/:id/deploy-keys/:key_id", handlers.DeployKey.Delete) // 撤销部署密钥 Revoke a deploy key
+
+			projectGroup.GET("/:id/webhooks", handlers.Webhook.List)                                                     // 获取项目 webhook List project webhooks
+			projectGroup.POST("/:id/webhooks", handlers.Webhook.Create)                                                  // 创建 webhook Create webhook
+			projectGroup.PUT("/:id/webhooks/:webhook_id", handlers.Webhook.Update)                                       // 更新 webhook Update webhook
+			projectGroup.DELETE("/:id/webhooks/:webhook_id", handlers.Webhook.Delete)                                    // 删除 webhook Delete webhook
+			projectGroup.GET("/:id/webhooks/:webhook_id/deliveries", handlers.Webhook.Deliveries)                        // 获取投递记录 List deliveries
+			projectGroup.GET("/:id/webhooks/:webhook_id/deliveries/:delivery_id", handlers.Webhook.Delivery)             // 获取投递详情 Get delivery details
+			projectGroup.POST("/:id/webhooks/:webhook_id/deliveries/:delivery_id/redeliver", handlers.Webhook.Redeliver) // 重新投递 Redeliver
+
+			projectGroup.GET("/:id/cdn", handlers.CDN.List)              // 获取 CDN 集成 List CDN integrations
+			projectGroup.POST("/:id/cdn", handlers.CDN.Create)           // 创建 CDN 集成 Create a CDN integration
+			projectGroup.PUT("/:id/cdn/:cdn_id", handlers.CDN.Update)    // 更新 CDN 集成 Update a CDN integration
+			projectGroup.DELETE("/:id/cdn/:cdn_id", handlers.CDN.Delete) // 删除 CDN 集成 Delete a CDN integration
+
+			projectGroup.GET("/:id/approvals", handlers.Approval.List)                         // 获取等待批准的部署 List deployments awaiting approval
+			projectGroup.POST("/:id/approvals/:release_id/approve", handlers.Approval.Approve) // 批准部署 Approve a deployment
+			projectGroup.POST("/:id/approvals/:release_id/reject", handlers.Approval.Reject)   // 驳回部署 Reject a deployment
 			siteGroup := projectGroup.Group("/:id/site", handlers.Site.SiteAuth)
 			{
-				siteGroup.POST("", handlers.Site.Create)            // 创建站点 Create site
-				siteGroup.PUT("/:site_id", handlers.Site.Update)    // 更新站点 Update site
-				siteGroup.DELETE("/:site_id", handlers.Site.Delete) // 删除站点 Delete site
-				siteGroup.GET("/:site_id", handlers.Site.Info)      // 获取网站信息 Get site info
+				siteGroup.POST("", handlers.Site.Create)                       // 创建站点 Create site
+				siteGroup.PUT("/:site_id", handlers.Site.Update)               // 更新站点 Update site
+				siteGroup.DELETE("/:site_id", handlers.Site.Delete)            // 删除站点 Delete site
+				siteGroup.GET("/:site_id", handlers.Site.Info)                 // 获取网站信息 Get site info
+				siteGroup.GET("/:site_id/logs", handlers.Site.Logs)            // 查询最近的请求日志 Query recent request logs
+				siteGroup.GET("/:site_id/logs/tail", handlers.Site.TailLogs)   // 实时查看访问日志 Live tail of access logs
+				siteGroup.GET("/:site_id/og", handlers.Site.OGMeta)            // 获取 open-graph 元信息 Get open-graph meta
+				siteGroup.POST("/:site_id/signed-urls", handlers.Site.SignURL) // 签发受保护文件的临时链接 Issue a temporary URL of a protected file
+
+				siteGroup.DELETE("/:site_id/paths", handlers.Release.PurgePaths) // 从当前发布清除路径 Purge paths from the active release
+				siteGroup.GET("/:site_id/purges", handlers.Release.PurgeList)    // 获取路径清除记录 Get path purge records
+				siteGroup.POST("/:site_id/cdn/purge", handlers.CDN.Purge)        // 刷新站点的 CDN 缓存 Purge the CDN cache of the site
+
+				siteGroup.GET("/:site_id/debug/resolve", handlers.Site.DebugResolve) // 解释站点服务判定 Explain the serving decision
+
+				siteGroup.GET("/:site_id/analytics", handlers.Analytics.Site) // 获取站点访问分析 Get site analytics
+
+				siteGroup.GET("/:site_id/domains", handlers.Domain.List)                      // 获取自定义域名 List custom domains
+				siteGroup.POST("/:site_id/domains", handlers.Domain.Create)                   // 绑定自定义域名 Bind a custom domain
+				siteGroup.POST("/:site_id/domains/:domain_id/verify", handlers.Domain.Verify) // 验证域名所有权 Verify domain ownership
+				siteGroup.DELETE("/:site_id/domains/:domain_id", handlers.Domain.Delete)      // 解除自定义域名 Unbind a custom domain
+
+				siteGroup.GET("/:site_id/git", handlers.Git.Get)            // 获取绑定的仓库 Get the bound repository
+				siteGroup.PUT("/:site_id/git", handlers.Git.Save)           // 绑定或更新仓库 Bind or update the repository
+				siteGroup.DELETE("/:site_id/git", handlers.Git.Delete)      // 解除绑定的仓库 Unbind the repository
+				siteGroup.POST("/:site_id/git/deploy", handlers.Git.Deploy) // 部署分支的最新提交 Deploy the head of the branch
+				siteGroup.GET("/:site_id/git/logs", handlers.Git.Logs)      // 实时推送构建输出 Live output of the build
+
+				siteGroup.GET("/:site_id/previews", handlers.Preview.List)                  // 获取预览部署 List preview deployments
+				siteGroup.DELETE("/:site_id/previews/:preview_id", handlers.Preview.Delete) // 删除预览部署 Delete a preview deployment
+
+				siteGroup.GET("/:site_id/releases", handlers.Release.ReleaseList)                                              // 获取站点 release 列表
+				siteGroup.GET("/:site_id/deployments", handlers.Release.Deployments)                                           // 获取站点部署版本 List deployed versions
+				siteGroup.GET("/:site_id/deployments/diff", handlers.Release.Diff)                                             // 比较两个部署版本 Compare two deployments
+				siteGroup.GET("/:site_id/deployments/:release_id/checksums", handlers.Release.Checksums)                       // 获取部署校验过的文件哈希 Get the verified file hashes of a deployment
+				siteGroup.GET("/:site_id/deployments/:release_id/links", handlers.LinkCheck.Get)                               // 获取部署的链接检查报告 Get the link check report of a deployment
+				siteGroup.POST("/:site_id/deployments/:release_id/links", handlers.LinkCheck.Run)                              // 重新检查部署的链接 Check the links of a deployment again
+				siteGroup.POST("/:site_id/deployments/:release_id/pin", handlers.Retention.Pin)                                // 固定部署使其不被保留策略删除 Pin a deployment so the retention policy keeps it
+				siteGroup.DELETE("/:site_id/deployments/:release_id/pin", handlers.Retention.Unpin)                            // 取消固定部署 Unpin a deployment
+				siteGroup.POST("/:site_id/rollback", handlers.Release.Rollback)                                                // 回滚站点版本 Roll back the site
+				siteGroup.POST("/:site_id/uploads", handlers.Upload.Create)                                                    // 创建分片上传 Create a chunked upload
+				siteGroup.GET("/:site_id/uploads/:upload_id", handlers.Upload.Get)                                             // 获取上传进度 Get upload progress
+				siteGroup.PUT("/:site_id/uploads/:upload_id/chunks/:index", uploadLimit, uploadBody, handlers.Upload.PutChunk) // 上传分片 Upload a chunk
+				siteGroup.POST("/:site_id/uploads/:upload_id/complete", handlers.Upload.Complete)                              // 拼接并发布 Assemble and publish
+				siteGroup.DELETE("/:site_id/uploads/:upload_id", handlers.Upload.Delete)                                       // 放弃上传 Abort the upload
+
+				siteGroup.GET("/:site_id/jobs", handlers.Job.SiteList)                  // 获取站点的后台任务 List the background jobs of the site
+				siteGroup.GET("/:site_id/jobs/:job_id", handlers.Job.SiteGet)           // 获取后台任务 Get a background job
+				siteGroup.GET("/:site_id/jobs/:job_id/events", handlers.Job.SiteEvents) // 实时推送后台任务进度 Live progress of a background job
+
+				siteGroup.POST("/:site_id/blobs/missing", handlers.Blob.Missing)                   // 查询缺失的文件 Look up missing files
+				siteGroup.PUT("/:site_id/blobs/:hash", uploadLimit, uploadBody, handlers.Blob.Put) // 上传文件内容 Upload file content
+
+				siteGroup.GET("/:site_id/draft", handlers.WebDAV.Get)              // 获取草稿的 WebDAV 地址 Get the WebDAV URL of the draft
+				siteGroup.POST("/:site_id/draft", handlers.WebDAV.Create)          // 创建草稿 Create a draft
+				siteGroup.DELETE("/:site_id/draft", handlers.WebDAV.Delete)        // 丢弃草稿 Discard the draft
+				siteGroup.POST("/:site_id/draft/publish", handlers.WebDAV.Publish) // 将草稿发布为新版本 Publish the draft as a new version
+				// 草稿的 WebDAV 接口 WebDAV interface of the draft
+				for _, method := range []string{"GET", "HEAD", "OPTIONS", "PUT", "DELETE", "MKCOL", "COPY", "MOVE", "PROPFIND", "PROPPATCH", "LOCK", "UNLOCK"} {
+					siteGroup.Handle(method, "/:site_id/dav/*path", handlers.WebDAV.Serve)
+				}
 
-				siteGroup.GET("/:site_id/releases", handlers.Release.ReleaseList) // 获取站点 release 列表
 				siteRelease := siteGroup.Group("/:site_id/release")
 				{
-					siteRelease.POST("", handlers.Release.Create)                // 创建站点发布 Create site release
-					siteRelease.DELETE("", handlers.Release.Delete)              // 删除站点版本 Delete site release
-					siteRelease.POST("/activation", handlers.Release.Activation) // 指定使用该站点版本
+					siteRelease.POST("", uploadLimit, uploadBody, handlers.Release.Create) // 创建站点发布 Create site release
+					siteRelease.POST("/manifest", handlers.Blob.Deploy)                    // 使用部署清单发布 Publish from a deployment manifest
+					siteRelease.DELETE("", handlers.Release.Delete)                        // 删除站点版本 Delete site release
+					siteRelease.POST("/activation", handlers.Release.Activation)           // 指定使用该站点版本
 				}
 			}
 		}
+		trashGroup := apiV1.Group("/trash") // 回收站中的项目不经过项目权限检查，由处理函数校验所有者角色 Projects in the trash skip the project auth, the handlers check the owner role
+		{
+			trashGroup.POST("/project/:id/restore", handlers.Trash.RestoreProject) // 恢复项目 Restore a project
+			trashGroup.DELETE("/project/:id", handlers.Trash.PurgeProject)         // 彻底删除项目 Purge a project
+		}
 		adminGroup := apiV1.Group("/admin") // 管理员路由
 		adminGroup.Use(middle.Auth.IsAdmin())
 		{
 			adminUser := adminGroup.Group("/user")
 			{
-				adminUser.POST("", handlers.Admin.CreateUser) // 创建用户 Create user
+				adminUser.GET("", handlers.Admin.ListUsers)                    // 查询用户 Query users
+				adminUser.POST("", handlers.Admin.CreateUser)                  // 创建用户 Create user
+				adminUser.POST("/:id/impersonate", handlers.Admin.Impersonate) // 代为登录用户 Impersonate a user
+				adminUser.DELETE("/:id/lockout", handlers.Admin.Unlock)        // 解除登录失败锁定 Lift the failed login lockout
+			}
+			adminGroup.GET("/users/:name", handlers.Admin.GetUserByName)       // 按名称获取用户 Get a user by name
+			adminGroup.PUT("/users/:name", handlers.Admin.PutUser)             // 按名称创建或更新用户 Create or update a user by name
+			adminGroup.GET("/orgs/:name", handlers.Admin.GetOrgByName)         // 按名称获取组织 Get an organization by name
+			adminGroup.PUT("/orgs/:name", handlers.Admin.PutOrg)               // 按名称创建或更新组织 Create or update an organization by name
+			adminGroup.GET("/projects/:name", handlers.Admin.GetProjectByName) // 按名称获取项目 Get a project by name
+			adminGroup.PUT("/projects/:name", handlers.Admin.PutProject)       // 按名称创建或更新项目 Create or update a project by name
+
+			adminGroup.GET("/backup", handlers.Admin.Backup)                   // 下载实例备份 Download instance backup
+			adminGroup.POST("/database/checkpoint", handlers.Admin.Checkpoint) // 执行 SQLite WAL 检查点 Run a SQLite WAL checkpoint
+			adminGroup.GET("/search", handlers.Search.Admin)                   // 检索整个实例 Search the whole instance
+			adminGroup.GET("/stats", handlers.Stats.Get)                       // 获取实例统计 Get instance statistics
+
+			adminGroup.GET("/audit-logs", handlers.Audit.List)          // 查询审计日志 Query audit logs
+			adminGroup.GET("/audit-logs/verify", handlers.Audit.Verify) // 校验审计日志哈希链 Verify the audit log hash chain
+
+			adminGroup.GET("/invitations", handlers.Invitation.AdminList)                     // 获取所有邀请 List all invitations
+			adminGroup.POST("/invitations", handlers.Invitation.AdminCreate)                  // 创建注册邀请 Create an invitation
+			adminGroup.DELETE("/invitations/:invitation_id", handlers.Invitation.AdminRevoke) // 撤销邀请 Revoke an invitation
+
+			adminGroup.GET("/quota/:owner_type/:owner_id", handlers.Quota.AdminGet)        // 获取用户或组织的配额 Get a user or organization quota
+			adminGroup.PUT("/quota/:owner_type/:owner_id", handlers.Quota.AdminUpdate)     // 设置用户或组织的配额 Set a user or organization quota
+			adminGroup.DELETE("/quota/:owner_type/:owner_id", handlers.Quota.AdminDelete)  // 恢复默认配额 Restore the default quota
+			adminGroup.PUT("/project/:id/upload-limit", handlers.Quota.AdminProjectUpload) // 设置项目的上传大小 Set the upload size of a project
+			adminGroup.PUT("/site/:id/transfer-limit", handlers.Bandwidth.AdminSiteLimit)  // 设置站点的每月流量限制 Set the monthly transfer limits of a site
+
+			adminGroup.GET("/content-policy/:org_id", handlers.ContentPolicy.AdminGet)       // 获取组织的内容策略 Get an organization content policy
+			adminGroup.PUT("/content-policy/:org_id", handlers.ContentPolicy.AdminUpdate)    // 设置组织的内容策略 Set an organization content policy
+			adminGroup.DELETE("/content-policy/:org_id", handlers.ContentPolicy.AdminDelete) // 恢复默认内容策略 Restore the default content policy
+
+			adminGroup.GET("/tenants", handlers.Tenant.AdminList)                                         // 获取所有租户 List all tenants
+			adminGroup.POST("/tenants", handlers.Tenant.AdminCreate)                                      // 创建租户 Create a tenant
+			adminGroup.PUT("/tenants/:tenant_id", handlers.Tenant.AdminUpdate)                            // 修改租户 Change a tenant
+			adminGroup.DELETE("/tenants/:tenant_id", handlers.Tenant.AdminDelete)                         // 删除租户 Delete a tenant
+			adminGroup.PUT("/tenants/:tenant_id/:owner_type/:owner_id", handlers.Tenant.AdminAssign)      // 将用户或组织移入租户 Move a user or organization into a tenant
+			adminGroup.DELETE("/tenants/:tenant_id/:owner_type/:owner_id", handlers.Tenant.AdminUnassign) // 将用户或组织移出租户 Move a user or organization out of a tenant
+
+			adminGroup.GET("/quarantine", handlers.Scan.List)                         // 获取等待审核的隔离版本 List quarantined versions waiting for review
+			adminGroup.POST("/quarantine/:release_id/approve", handlers.Scan.Approve) // 放行隔离的版本 Approve a quarantined version
+			adminGroup.POST("/quarantine/:release_id/reject", handlers.Scan.Reject)   // 拒绝隔离的版本 Reject a quarantined version
+
+			adminGroup.GET("/settings", handlers.Setting.List)           // 获取动态配置项 List dynamic settings
+			adminGroup.PUT("/settings/:key", handlers.Setting.Update)    // 修改动态配置项 Change a dynamic setting
+			adminGroup.DELETE("/settings/:key", handlers.Setting.Delete) // 恢复配置文件中的值 Restore the configured value
+
+			adminGroup.GET("/jobs", handlers.Job.List)                   // 查询后台任务 Query background jobs
+			adminGroup.GET("/jobs/:job_id", handlers.Job.Get)            // 获取后台任务 Get a background job
+			adminGroup.POST("/jobs/:job_id/retry", handlers.Job.Retry)   // 重试后台任务 Retry a background job
+			adminGroup.POST("/jobs/:job_id/cancel", handlers.Job.Cancel) // 取消后台任务 Cancel a background job
+			adminGroup.POST("/gc", handlers.Job.CollectGarbage)          // 运行存储垃圾回收 Run the storage garbage collection
+
+			adminGroup.POST("/bulk/users/disable", handlers.Admin.BulkDisableUsers)         // 批量停用用户 Disable users in bulk
+			adminGroup.POST("/bulk/projects/delete", handlers.Admin.BulkDeleteProjects)     // 批量删除长期未部署的项目 Delete projects not deployed for a long time in bulk
+			adminGroup.POST("/bulk/projects/reassign", handlers.Admin.BulkReassignProjects) // 批量更换项目的所有者 Reassign the owner of projects in bulk
+
+			adminGroup.GET("/cron", handlers.Cron.List)           // 获取定时任务的运行状态 List scheduled tasks and their runs
+			adminGroup.POST("/cron/:name/run", handlers.Cron.Run) // 手动运行定时任务 Run a scheduled task now
+
+			adminGroup.GET("/runners", handlers.Runner.AdminList)                 // 获取远程构建执行器 List remote build runners
+			adminGroup.POST("/runners", handlers.Runner.AdminCreate)              // 创建远程构建执行器 Create a remote build runner
+			adminGroup.DELETE("/runners/:runner_id", handlers.Runner.AdminDelete) // 删除远程构建执行器 Delete a remote build runner
+			adminOIDC := adminGroup.Group("/oidc")
+			{
+				adminOIDC.GET("", handlers.OIDC.AdminList)                   // 获取登录提供方 List auth providers
+				adminOIDC.POST("", handlers.OIDC.AdminCreate)                // 创建登录提供方 Create auth provider
+				adminOIDC.PUT("/:provider_id", handlers.OIDC.AdminUpdate)    // 更新登录提供方 Update auth provider
+				adminOIDC.DELETE("/:provider_id", handlers.OIDC.AdminDelete) // 删除登录提供方 Delete auth provider
 			}
 			adminNode := adminGroup.Group("/node")
 			{
@@ -87,22 +502,30 @@ func Run() error {
 		}
 	}
 
+	// 接口文档 API documentation
+	if config.OpenAPIEnable {
+		H.GET(openapi.SpecPath, openapi.ServeSpec)
+		H.GET(openapi.UIPath, openapi.ServeUI)
+	}
+
+	// 存活和就绪探针 Liveness and readiness probes
+	H.GET("/healthz", handlers.Health.Live)
+	H.GET("/readyz", handlers.Health.Ready)
+
+	// Prometheus 监控指标 Prometheus metrics
+	if config.MetricsEnable {
+		H.GET("/metrics", handlers.Metrics.Serve)
+	}
+
+	// 域名所有权和 ACME 验证 Domain ownership and ACME challenges
+	H.GET(utils.DomainChallengePath+":token", handlers.Domain.Challenge)
+	if certs.Enabled() {
+		H.GET("/.well-known/acme-challenge/*any", certs.HTTPChallenge())
+	}
+
 	// 设置静态文件目录 Set static file directory
 	web := H.Group("")
 	{
 		web.GET("/*any", handlers.WebHandler)
 	}
-
-	// 运行服务 Run service
-	if config.Mode == "dev" {
-		// 开发模式 Development mode
-		err := H.Run()
-		if err != nil {
-			return err
-		}
-	} else {
-		// 生产模式 Production mode
-		H.Spin()
-	}
-	return nil
 }
@@ -0,0 +1,86 @@
+package router
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/certs"
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/sirupsen/logrus"
+)
+
+// tlsProxy 在 HTTPS 端口终止 TLS 并把请求转发给本机的 HTTP 服务，由 net/http 协商 HTTP/2
+// Terminate TLS on the HTTPS port and forward requests to the local HTTP server, net/http negotiates HTTP/2
+type tlsProxy struct {
+	server   *http.Server
+	listener net.Listener
+}
+
+// newTLSProxy 监听 HTTPS 端口，转发的请求携带客户端地址和 certs.ProxyHeader 凭证
+// Listen on the HTTPS port, forwarded requests carry the client address and the certs.ProxyHeader credential
+func newTLSProxy(ctx context.Context) (*tlsProxy, error) {
+	listenConfig := net.ListenConfig{}
+	if config.ServerReusePort {
+		listenConfig.Control = reusePort
+	}
+	listener, err := listenConfig.Listen(ctx, "tcp", ":"+certs.HTTPSPort)
+	if err != nil {
+		return nil, err
+	}
+	backend := &url.URL{Scheme: "http", Host: net.JoinHostPort("127.0.0.1", config.ServerPort)}
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetURL(backend)
+			r.Out.Host = r.In.Host
+			r.Out.Header.Del("X-Real-IP")
+			if ip, _, err := net.SplitHostPort(r.In.RemoteAddr); err == nil {
+				r.Out.Header.Set("X-Forwarded-For", ip)
+			}
+			r.Out.Header.Set("X-Forwarded-Proto", "https")
+			r.Out.Header.Set(certs.ProxyHeader, certs.ProxyToken())
+		},
+		// 立即转发流式响应，如实时日志和构建输出 Forward streamed responses such as live logs and build output immediately
+		FlushInterval: -1,
+		Transport: &http.Transport{
+			DialContext:         (&net.Dialer{Timeout: 5 * time.Second}).DialContext,
+			MaxIdleConnsPerHost: 256,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			if !errors.Is(err, context.Canceled) {
+				logrus.Warnf("Forwarding HTTPS request failed: %v", err)
+			}
+			w.WriteHeader(http.StatusBadGateway)
+		},
+	}
+	server := &http.Server{
+		Handler:           proxy,
+		TLSConfig:         certs.TLSConfig(),
+		ReadHeaderTimeout: 30 * time.Second,
+	}
+	if !certs.Termination.HTTP2 {
+		server.TLSNextProto = map[string]func(*http.Server, *tls.Conn, http.Handler){}
+	}
+	return &tlsProxy{server: server, listener: tls.NewListener(listener, server.TLSConfig)}, nil
+}
+
+// Run 提供服务直到关闭
+// Serve until shut down
+func (p *tlsProxy) Run() error {
+	if err := p.server.Serve(p.listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown 停止接受新连接并等待进行中的请求完成
+// Stop accepting connections and wait for in-flight requests
+func (p *tlsProxy) Shutdown(ctx context.Context) error {
+	return p.server.Shutdown(ctx)
+}
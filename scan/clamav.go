@@ -0,0 +1,115 @@
+package scan
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// clamChunkSize INSTREAM 每个数据块的大小
+// Size of every INSTREAM chunk
+const clamChunkSize = 64 << 10
+
+// ClamAV 通过 clamd 的 INSTREAM 命令扫描
+// Scan through the INSTREAM command of clamd
+type ClamAV struct {
+	network string
+	address string
+	timeout time.Duration
+}
+
+// NewClamAV 创建 clamd 扫描器，address 为 tcp://host:port、unix:///path 或 host:port
+// Create a clamd scanner, address is tcp://host:port, unix:///path or host:port
+func NewClamAV(address string, timeout time.Duration) *ClamAV {
+	network := "tcp"
+	switch {
+	case strings.HasPrefix(address, "unix://"):
+		network, address = "unix", strings.TrimPrefix(address, "unix://")
+	case strings.HasPrefix(address, "unix:"):
+		network, address = "unix", strings.TrimPrefix(address, "unix:")
+	case strings.HasPrefix(address, "tcp://"):
+		address = strings.TrimPrefix(address, "tcp://")
+	}
+	return &ClamAV{network: network, address: address, timeout: timeout}
+}
+
+// Scan 将文件以长度前缀的数据块发送给 clamd 并解析结果
+// Send the file to clamd as length-prefixed chunks and parse the reply
+func (s *ClamAV) Scan(ctx context.Context, name string, body io.Reader) (string, error) {
+	dialer := net.Dialer{Timeout: s.timeout}
+	conn, err := dialer.DialContext(ctx, s.network, s.address)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	deadline, ok := ctx.Deadline()
+	if s.timeout > 0 && (!ok || time.Now().Add(s.timeout).Before(deadline)) {
+		deadline, ok = time.Now().Add(s.timeout), true
+	}
+	if ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return "", err
+	}
+	buf := make([]byte, 4+clamChunkSize)
+	for {
+		n, readErr := io.ReadFull(body, buf[4:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(buf, uint32(n))
+			if _, err := conn.Write(buf[:4+n]); err != nil {
+				// clamd 超出 StreamMaxLength 时会提前回复并关闭连接 clamd replies early and closes the connection once StreamMaxLength is exceeded
+				if reply, replyErr := readClamReply(conn); replyErr == nil {
+					return parseClamReply(reply)
+				}
+				return "", err
+			}
+		}
+		if errors.Is(readErr, io.EOF) || errors.Is(readErr, io.ErrUnexpectedEOF) {
+			break
+		}
+		if readErr != nil {
+			return "", readErr
+		}
+	}
+	if _, err := conn.Write([]byte{0, 0, 0, 0}); err != nil {
+		return "", err
+	}
+	reply, err := readClamReply(conn)
+	if err != nil {
+		return "", err
+	}
+	return parseClamReply(reply)
+}
+
+// readClamReply 读取以 \0 结尾的 clamd 回复
+// Read a clamd reply ending with \0
+func readClamReply(conn net.Conn) (string, error) {
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && (reply == "" || !errors.Is(err, io.EOF)) {
+		return "", err
+	}
+	return strings.TrimRight(reply, "\x00\n"), nil
+}
+
+// parseClamReply 解析 "stream: OK"、"stream: <特征> FOUND" 或 "<错误> ERROR" 形式的回复
+// Parse a reply of the form "stream: OK", "stream: <signature> FOUND" or "<error> ERROR"
+func parseClamReply(reply string) (string, error) {
+	result := reply
+	if i := strings.Index(reply, ": "); i >= 0 {
+		result = reply[i+2:]
+	}
+	switch {
+	case result == "OK":
+		return "", nil
+	case strings.HasSuffix(result, " FOUND"):
+		return strings.TrimSuffix(result, " FOUND"), nil
+	}
+	return "", fmt.Errorf("clamd: %s", strings.TrimSuffix(result, " ERROR"))
+}
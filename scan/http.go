@@ -0,0 +1,64 @@
+package scan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// HTTP 将文件提交给外部扫描服务
+// Submit files to an external scanning service
+type HTTP struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// httpResult 外部扫描服务的响应
+// Response of the external scanning service
+type httpResult struct {
+	Infected  bool   `json:"infected"`  // 是否发现恶意内容 Whether malicious content was found
+	Signature string `json:"signature"` // 命中的特征名称 Matched signature
+}
+
+// NewHTTP 创建外部扫描服务的扫描器，token 不为空时以 Bearer 令牌携带
+// Create a scanner for an external scanning service, sending token as a bearer token when set
+func NewHTTP(url, token string, timeout time.Duration) *HTTP {
+	return &HTTP{url: url, token: token, client: &http.Client{Timeout: timeout}}
+}
+
+// Scan 以请求体 POST 文件，文件名放在 X-File-Name 请求头，服务以 {"infected": bool, "signature": string} 响应
+// POST the file as the request body with its name in the X-File-Name header, the service answers {"infected": bool, "signature": string}
+func (s *HTTP) Scan(ctx context.Context, name string, body io.Reader) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, body)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("X-File-Name", name)
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("scanner responded with status %d", resp.StatusCode)
+	}
+	result := httpResult{}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&result); err != nil {
+		return "", fmt.Errorf("invalid scanner response: %w", err)
+	}
+	if !result.Infected {
+		return "", nil
+	}
+	if result.Signature == "" {
+		return "unknown", nil
+	}
+	return result.Signature, nil
+}
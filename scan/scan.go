@@ -0,0 +1,69 @@
+package scan
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+)
+
+// Finding 扫描发现的恶意文件
+// A malicious file found by the scan
+type Finding struct {
+	Path      string `json:"path"`      // 文件在版本中的路径 Path of the file in the version
+	Signature string `json:"signature"` // 扫描器报告的特征名称 Signature reported by the scanner
+}
+
+// Scanner 内容扫描器，文件干净时返回空字符串，否则返回命中的特征名称
+// Content scanner, returning an empty string for a clean file and the matched signature otherwise
+type Scanner interface {
+	Scan(ctx context.Context, name string, body io.Reader) (signature string, err error)
+}
+
+// Visitor 遍历版本文件的函数，与 serve.WalkRelease 相同
+// Function walking the files of a version, the same as serve.WalkRelease
+type Visitor func(visit func(name string, size int64, body io.Reader) error) error
+
+// New 按配置创建扫描器，未开启扫描时返回 nil
+// Create the scanner from the configuration, nil when scanning is disabled
+func New() (Scanner, error) {
+	if !config.ScanEnable {
+		return nil, nil
+	}
+	timeout := time.Duration(config.ScanTimeout) * time.Second
+	switch config.ScanBackend {
+	case config.ScanBackendClamAV:
+		return NewClamAV(config.ScanClamAVAddress, timeout), nil
+	case config.ScanBackendHTTP:
+		if config.ScanHTTPURL == "" {
+			return nil, fmt.Errorf("scan.http-url is required by the http backend")
+		}
+		return NewHTTP(config.ScanHTTPURL, config.ScanHTTPToken, timeout), nil
+	}
+	return nil, fmt.Errorf("unknown scan backend %q", config.ScanBackend)
+}
+
+// Walk 用 scanner 扫描 walk 遍历的每个文件，跳过大于 maxSize 字节的文件，maxSize 为 0 时全部扫描；扫描出错时立即返回
+// Scan every file walked by walk with scanner, skipping files larger than maxSize bytes and scanning all of them when maxSize is 0; returns as soon as scanning fails
+func Walk(ctx context.Context, scanner Scanner, walk Visitor, maxSize int64) ([]Finding, error) {
+	var findings []Finding
+	err := walk(func(name string, size int64, body io.Reader) error {
+		if maxSize > 0 && size > maxSize {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		signature, err := scanner.Scan(ctx, name, body)
+		if err != nil {
+			return fmt.Errorf("scan %s: %w", name, err)
+		}
+		if signature != "" {
+			findings = append(findings, Finding{Path: name, Signature: signature})
+		}
+		return nil
+	})
+	return findings, err
+}
@@ -0,0 +1,161 @@
+package scan
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeClamd 接收 INSTREAM 数据，内容包含 EICAR 时回复 FOUND
+// Accept INSTREAM data, replying FOUND when the content contains EICAR
+func fakeClamd(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				command, err := reader.ReadString(0)
+				if err != nil || command != "zINSTREAM\x00" {
+					_, _ = conn.Write([]byte("UNKNOWN COMMAND\x00"))
+					return
+				}
+				var data bytes.Buffer
+				for {
+					var size uint32
+					if err := binary.Read(reader, binary.BigEndian, &size); err != nil {
+						return
+					}
+					if size == 0 {
+						break
+					}
+					if _, err := io.CopyN(&data, reader, int64(size)); err != nil {
+						return
+					}
+				}
+				reply := "stream: OK\x00"
+				if strings.Contains(data.String(), "EICAR") {
+					reply = "stream: Eicar-Test-Signature FOUND\x00"
+				}
+				_, _ = conn.Write([]byte(reply))
+			}(conn)
+		}
+	}()
+	return "tcp://" + listener.Addr().String()
+}
+
+func TestClamAV(t *testing.T) {
+	scanner := NewClamAV(fakeClamd(t), 5*time.Second)
+	ctx := context.Background()
+	if signature, err := scanner.Scan(ctx, "index.html", strings.NewReader("<h1>hello</h1>")); err != nil || signature != "" {
+		t.Fatalf("clean file: got %q, %v", signature, err)
+	}
+	// 跨越多个数据块的文件 A file spanning several chunks
+	body := strings.Repeat("a", 3*clamChunkSize) + "EICAR"
+	if signature, err := scanner.Scan(ctx, "bad.bin", strings.NewReader(body)); err != nil || signature != "Eicar-Test-Signature" {
+		t.Fatalf("infected file: got %q, %v", signature, err)
+	}
+}
+
+func TestNewClamAVAddress(t *testing.T) {
+	tests := map[string][2]string{
+		"tcp://127.0.0.1:3310":      {"tcp", "127.0.0.1:3310"},
+		"clamd:3310":                {"tcp", "clamd:3310"},
+		"unix:///run/clamd.ctl":     {"unix", "/run/clamd.ctl"},
+		"unix:/var/run/clamd/clamd": {"unix", "/var/run/clamd/clamd"},
+	}
+	for address, want := range tests {
+		s := NewClamAV(address, 0)
+		if s.network != want[0] || s.address != want[1] {
+			t.Errorf("%s: got %s %s, want %s %s", address, s.network, s.address, want[0], want[1])
+		}
+	}
+}
+
+func TestParseClamReply(t *testing.T) {
+	if signature, err := parseClamReply("stream: OK"); err != nil || signature != "" {
+		t.Fatalf("OK: got %q, %v", signature, err)
+	}
+	if signature, err := parseClamReply("stream: Win.Test.EICAR_HDB-1 FOUND"); err != nil || signature != "Win.Test.EICAR_HDB-1" {
+		t.Fatalf("FOUND: got %q, %v", signature, err)
+	}
+	if _, err := parseClamReply("INSTREAM size limit exceeded. ERROR"); err == nil || !strings.Contains(err.Error(), "size limit exceeded") {
+		t.Fatalf("ERROR: got %v", err)
+	}
+}
+
+func TestHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		result := httpResult{}
+		if bytes.Contains(body, []byte("phish")) {
+			result = httpResult{Infected: true, Signature: "Phishing." + r.Header.Get("X-File-Name")}
+		}
+		_ = json.NewEncoder(w).Encode(result)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	scanner := NewHTTP(server.URL, "secret", 5*time.Second)
+	if signature, err := scanner.Scan(ctx, "index.html", strings.NewReader("hello")); err != nil || signature != "" {
+		t.Fatalf("clean file: got %q, %v", signature, err)
+	}
+	if signature, err := scanner.Scan(ctx, "login.html", strings.NewReader("phish")); err != nil || signature != "Phishing.login.html" {
+		t.Fatalf("infected file: got %q, %v", signature, err)
+	}
+	if _, err := NewHTTP(server.URL, "", time.Second).Scan(ctx, "index.html", strings.NewReader("hello")); err == nil {
+		t.Fatal("a non-2xx response should be an error")
+	}
+}
+
+type fakeScanner map[string]string
+
+func (s fakeScanner) Scan(_ context.Context, name string, body io.Reader) (string, error) {
+	_, _ = io.Copy(io.Discard, body)
+	return s[name], nil
+}
+
+func TestWalk(t *testing.T) {
+	files := map[string]string{"index.html": "ok", "login.html": "phish", "big.bin": strings.Repeat("x", 100)}
+	walk := func(visit func(name string, size int64, body io.Reader) error) error {
+		for _, name := range []string{"index.html", "login.html", "big.bin"} {
+			if err := visit(name, int64(len(files[name])), strings.NewReader(files[name])); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	scanner := fakeScanner{"login.html": "Phishing.Generic", "big.bin": "Too.Big"}
+	findings, err := Walk(context.Background(), scanner, walk, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 1 || findings[0] != (Finding{Path: "login.html", Signature: "Phishing.Generic"}) {
+		t.Fatalf("got %+v, want only login.html, big.bin is over the size limit", findings)
+	}
+	if findings, _ := Walk(context.Background(), scanner, walk, 0); len(findings) != 2 {
+		t.Fatalf("got %+v, want every file scanned without a size limit", findings)
+	}
+}
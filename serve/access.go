@@ -0,0 +1,389 @@
+package serve
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"html/template"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/utils"
+)
+
+const (
+	// AccessCookie 站点域名下保存访问登录状态的 Cookie
+	// Cookie of the site's host keeping the visitor's session
+	AccessCookie = "spage_access"
+	// InstanceCookie 私有实例下站点域名保存实例登录状态的 Cookie
+	// Cookie of the site's host keeping the instance session on private instances
+	InstanceCookie = "spage_instance"
+	// ChallengeInstance 私有实例要求登录实例账号时的登录方式
+	// Sign-in method asked for when a private instance requires an account of the instance
+	ChallengeInstance = "instance"
+	// SignedURLParam 签名链接中携带凭据的查询参数
+	// Query parameter carrying the credential of a signed URL
+	SignedURLParam = "spage_signature"
+	// AccessPathPrefix 站点域名下保留给访问保护的路径，不会提供同名的站点文件
+	// Paths of the site's host reserved for access protection, site files of the same names are not served
+	AccessPathPrefix = "/.spage/"
+	// accessTicketTTL 面板签发的跳转凭据的有效期
+	// Lifetime of the tickets issued by the panel
+	accessTicketTTL = time.Minute
+	// accessPasswordTTL 已验证的 Basic 认证密码的缓存时间，避免每个请求都计算 bcrypt
+	// How long verified Basic auth passwords are remembered, saving a bcrypt run per request
+	accessPasswordTTL = 5 * time.Minute
+	// maxAccessPasswords 缓存的已验证密码数量上限，超过后清空
+	// Max number of remembered passwords, the cache is cleared beyond it
+	maxAccessPasswords = 4096
+	// accessTokenTTL 已验证的个人访问令牌的缓存时间，令牌撤销后最多在这段时间内仍可访问
+	// How long verified personal access tokens are remembered, a revoked token keeps access for at most this long
+	accessTokenTTL = time.Minute
+	// instanceFingerprint 实例跳转凭据的摘要，与站点访问设置的摘要不会相同
+	// Fingerprint of instance tickets, never equal to the digest of a site's access settings
+	instanceFingerprint = "instance"
+)
+
+// ErrAccessDenied 密码或访问凭据无效
+// The password or the access credential is invalid
+var ErrAccessDenied = errors.New("access denied")
+
+type protectionType struct {
+	mu        sync.Mutex
+	passwords map[string]time.Time  // 已验证的密码和成员令牌摘要及其过期时间 Digests of verified passwords and member tokens and their expiry
+	tokens    map[string]tokenGrant // 已验证的个人访问令牌哈希 Hashes of verified personal access tokens
+}
+
+// tokenGrant 已验证的个人访问令牌所属的用户
+// User of a verified personal access token
+type tokenGrant struct {
+	userID  uint
+	expires time.Time
+}
+
+// Protection 站点的密码和成员访问保护，以及私有实例的实例访问保护
+// Password and member access protection of sites, and the instance access protection of private instances
+var Protection = &protectionType{
+	passwords: make(map[string]time.Time),
+	tokens:    make(map[string]tokenGrant),
+}
+
+// fingerprint 站点当前访问设置的摘要
+// Digest of the site's current access settings
+func fingerprint(site *models.Site) string {
+	return utils.SiteAccess.Fingerprint(string(site.AccessMode), site.AccessPassword)
+}
+
+// Protected 站点是否启用了访问保护
+// Whether the site has access protection enabled
+func Protected(site *models.Site) bool {
+	return site.AccessMode != "" && site.AccessMode != constants.SiteAccessPublic
+}
+
+// Private 是否为私有实例，所有托管站点都要求登录实例账号
+// Whether the instance is private, every hosted site requiring an account of the instance
+func Private() bool {
+	return config.ServePrivate
+}
+
+// allows 判断请求是否可以访问受保护的站点；access 为 AccessCookie 的值，authorization 为 Authorization 请求头，
+// 成员站点也接受项目成员的个人访问令牌
+// Check whether the request may visit the protected site; access is the value of AccessCookie and authorization the Authorization header,
+// member sites also take personal access tokens of project members
+func (a *protectionType) allows(site *models.Site, access, authorization string) (bool, string) {
+	if access != "" {
+		if claims, err := utils.SiteAccess.Parse(access, utils.SiteAccessSession); err == nil && claims.SiteID == site.ID && claims.Fingerprint == fingerprint(site) {
+			return true, "signed in to the protected site"
+		}
+	}
+	if site.AccessMode == constants.SiteAccessPassword {
+		if password, ok := basicPassword(authorization); ok && a.verify(site, password) {
+			return true, "access password"
+		}
+		return false, "site requires a password"
+	}
+	if hash, userID, ok := a.tokenUser(authorization); ok && a.member(site, hash, userID) {
+		return true, "personal access token of a project member"
+	}
+	return false, "site is restricted to project members"
+}
+
+// allowsSigned 判断签名链接的凭据是否允许访问站点的 path，只校验签名，不查询数据库
+// Check whether the credential of a signed URL allows reaching path of the site, verifying the signature only without querying the database
+func (a *protectionType) allowsSigned(site *models.Site, path, signature string) bool {
+	if signature == "" {
+		return false
+	}
+	claims, err := utils.SiteAccess.Parse(signature, utils.SiteAccessFile)
+	return err == nil && claims.SiteID == site.ID && claims.Fingerprint == fingerprint(site) && claims.Path == path
+}
+
+// allowsInstance 判断请求是否已登录私有实例：instance 为 InstanceCookie 的值，成员站点的登录状态和个人访问令牌同样可以证明访问者是实例用户
+// Check whether the request signed in to the private instance: instance is the value of InstanceCookie, a member session of the site and
+// personal access tokens prove an instance user as well
+func (a *protectionType) allowsInstance(site *models.Site, instance, access, authorization string) (bool, string) {
+	if instance != "" {
+		if claims, err := utils.SiteAccess.Parse(instance, utils.SiteAccessInstance); err == nil && claims.SiteID == site.ID && claims.Viewer != 0 {
+			return true, "signed in to the instance"
+		}
+	}
+	if access != "" {
+		if claims, err := utils.SiteAccess.Parse(access, utils.SiteAccessSession); err == nil && claims.SiteID == site.ID && claims.Viewer != 0 && claims.Fingerprint == fingerprint(site) {
+			return true, "signed in to the protected site"
+		}
+	}
+	if _, _, ok := a.tokenUser(authorization); ok {
+		return true, "personal access token"
+	}
+	return false, "instance requires signing in"
+}
+
+// tokenUser 校验 Basic 认证密码或 Bearer 中可读取的个人访问令牌，返回令牌哈希和所属用户
+// Verify a personal access token allowing reads given as the Basic auth password or a Bearer token, returning its hash and user
+func (a *protectionType) tokenUser(authorization string) (string, uint, bool) {
+	plain, ok := basicPassword(authorization)
+	if !ok {
+		plain, ok = bearerToken(authorization)
+	}
+	if !ok || !strings.HasPrefix(plain, constants.APITokenPrefix) {
+		return "", 0, false
+	}
+	hash := utils.Token.HashAPIToken(plain)
+	now := time.Now()
+	a.mu.Lock()
+	grant, ok := a.tokens[hash]
+	a.mu.Unlock()
+	if ok && now.Before(grant.expires) {
+		return hash, grant.userID, true
+	}
+	token, err := store.APIToken.GetByHash(hash)
+	if err != nil || (token.ExpiresAt != nil && token.ExpiresAt.Before(now)) || !readScope(token.Scopes) {
+		return "", 0, false
+	}
+	// 停用账号的令牌不再有效 Tokens of disabled accounts no longer work
+	if user, err := store.User.GetByID(token.UserID); err != nil || user.DisabledAt != nil {
+		return "", 0, false
+	}
+	_ = store.APIToken.Touch(token)
+	a.mu.Lock()
+	if len(a.tokens) >= maxAccessPasswords {
+		clear(a.tokens)
+	}
+	a.tokens[hash] = tokenGrant{userID: token.UserID, expires: now.Add(accessTokenTTL)}
+	a.mu.Unlock()
+	return hash, token.UserID, true
+}
+
+// member 令牌的用户是否为站点所属项目的成员
+// Whether the user of the token is a member of the site's project
+func (a *protectionType) member(site *models.Site, hash string, userID uint) bool {
+	key := "member\n" + strconv.FormatUint(uint64(site.ID), 10) + "\n" + hash
+	now := time.Now()
+	a.mu.Lock()
+	expires, ok := a.passwords[key]
+	a.mu.Unlock()
+	if ok && now.Before(expires) {
+		return true
+	}
+	if !store.Project.UserRole(&site.Project, userID).Valid() {
+		return false
+	}
+	a.mu.Lock()
+	if len(a.passwords) >= maxAccessPasswords {
+		clear(a.passwords)
+	}
+	a.passwords[key] = now.Add(accessTokenTTL)
+	a.mu.Unlock()
+	return true
+}
+
+// readScope 令牌的权限范围是否包含只读访问，admin 只用于管理员接口
+// Whether the scopes of a token include read access, admin only covers admin endpoints
+func readScope(scopes []constants.TokenScope) bool {
+	for _, scope := range scopes {
+		if scope == constants.TokenScopeRead || scope == constants.TokenScopeDeploy || scope == constants.TokenScopeWrite {
+			return true
+		}
+	}
+	return false
+}
+
+// verify 校验站点的访问密码
+// Verify the site's access password
+func (a *protectionType) verify(site *models.Site, password string) bool {
+	if site.AccessPassword == "" || password == "" {
+		return false
+	}
+	sum := sha256.Sum256([]byte(strconv.FormatUint(uint64(site.ID), 10) + "\n" + site.AccessPassword + "\n" + password))
+	key := hex.EncodeToString(sum[:])
+	now := time.Now()
+	a.mu.Lock()
+	expires, ok := a.passwords[key]
+	a.mu.Unlock()
+	if ok && now.Before(expires) {
+		return true
+	}
+	if ok, _ := utils.Password.VerifyPassword(password, site.AccessPassword); !ok {
+		return false
+	}
+	a.mu.Lock()
+	if len(a.passwords) >= maxAccessPasswords {
+		clear(a.passwords)
+	}
+	a.passwords[key] = now.Add(accessPasswordTTL)
+	a.mu.Unlock()
+	return true
+}
+
+// SignIn 使用访问密码登录主机名所属的受保护站点，返回要写入 AccessCookie 的登录状态
+// Sign in to the protected site of the host with its access password, returning the session to store in AccessCookie
+func (a *protectionType) SignIn(ctx context.Context, host, password string) (string, error) {
+	entry, _, err := lookupSite(ctx, normalizeHost(host))
+	if err != nil {
+		return "", err
+	}
+	site := entry.Site
+	if site.AccessMode != constants.SiteAccessPassword || !a.verify(site, password) {
+		return "", ErrAccessDenied
+	}
+	return a.session(site, 0)
+}
+
+// Ticket 为项目成员签发访问站点的跳转凭据，由 Redeem 在站点域名下换取登录状态
+// Issue a ticket for a project member to visit the site, exchanged for a session on the site's host by Redeem
+func (a *protectionType) Ticket(site *models.Site, userID uint) (string, error) {
+	return utils.SiteAccess.Sign(utils.SiteAccessTicket, site.ID, userID, fingerprint(site), accessTicketTTL)
+}
+
+// SignURL 为受保护站点的一个路径签发临时链接，无需登录即可访问该路径直到过期；修改站点的访问设置后所有链接失效
+// Issue a temporary URL for one path of a protected site, reachable without signing in until it expires; changing the site's access settings voids every URL
+func (a *protectionType) SignURL(site *models.Site, userID uint, filePath string, ttl time.Duration) (string, error) {
+	host := CanonicalHost(site)
+	if host == "" {
+		return "", errors.New("site has no host")
+	}
+	filePath = cleanPath(filePath)
+	signature, err := utils.SiteAccess.SignFile(site.ID, userID, fingerprint(site), filePath, ttl)
+	if err != nil {
+		return "", err
+	}
+	target := url.URL{Scheme: "https", Host: host, Path: filePath, RawQuery: SignedURLParam + "=" + url.QueryEscape(signature)}
+	return target.String(), nil
+}
+
+// InstanceTicket 私有实例下为已登录的用户签发访问站点的跳转凭据，由 Redeem 在站点域名下换取实例登录状态
+// Issue a ticket for a signed-in user to visit the site on a private instance, exchanged for an instance session on the site's host by Redeem
+func (a *protectionType) InstanceTicket(site *models.Site, userID uint) (string, error) {
+	return utils.SiteAccess.Sign(utils.SiteAccessTicket, site.ID, userID, instanceFingerprint, accessTicketTTL)
+}
+
+// Redeem 在站点域名下使用跳转凭据换取登录状态，返回要写入的 Cookie 名称，成员凭据写入 AccessCookie，实例凭据写入 InstanceCookie
+// Exchange a ticket on the site's host for a session, returning the cookie to store it in, AccessCookie for member tickets and InstanceCookie for instance tickets
+func (a *protectionType) Redeem(ctx context.Context, host, ticket string) (string, string, error) {
+	entry, _, err := lookupSite(ctx, normalizeHost(host))
+	if err != nil {
+		return "", "", err
+	}
+	site := entry.Site
+	claims, err := utils.SiteAccess.Parse(ticket, utils.SiteAccessTicket)
+	if err != nil || claims.SiteID != site.ID {
+		return "", "", ErrAccessDenied
+	}
+	if claims.Fingerprint == instanceFingerprint && Private() && claims.Viewer != 0 {
+		session, err := utils.SiteAccess.Sign(utils.SiteAccessInstance, site.ID, claims.Viewer, instanceFingerprint, time.Duration(config.SiteAccessSessionTTL)*time.Second)
+		return InstanceCookie, session, err
+	}
+	if claims.Fingerprint != fingerprint(site) || site.AccessMode != constants.SiteAccessMembers {
+		return "", "", ErrAccessDenied
+	}
+	session, err := a.session(site, claims.Viewer)
+	return AccessCookie, session, err
+}
+
+func (a *protectionType) session(site *models.Site, viewer uint) (string, error) {
+	return utils.SiteAccess.Sign(utils.SiteAccessSession, site.ID, viewer, fingerprint(site), time.Duration(config.SiteAccessSessionTTL)*time.Second)
+}
+
+// basicPassword 从 Basic 认证请求头中读取密码，用户名被忽略
+// Read the password from a Basic Authorization header, the username is ignored
+func basicPassword(authorization string) (string, bool) {
+	scheme, credentials, ok := strings.Cut(authorization, " ")
+	if !ok || !strings.EqualFold(scheme, "Basic") {
+		return "", false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(credentials))
+	if err != nil {
+		return "", false
+	}
+	_, password, ok := strings.Cut(string(decoded), ":")
+	return password, ok
+}
+
+// bearerToken 从 Bearer 认证请求头中读取令牌
+// Read the token from a Bearer Authorization header
+func bearerToken(authorization string) (string, bool) {
+	scheme, token, ok := strings.Cut(authorization, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// SafeReturn 登录后返回的站点内路径，拒绝跳转到其他主机
+// Path within the site to return to after signing in, refusing to go to other hosts
+func SafeReturn(target string) string {
+	if !strings.HasPrefix(target, "/") || strings.HasPrefix(target, "//") || strings.HasPrefix(target, "/\\") || strings.HasPrefix(target, AccessPathPrefix) {
+		return "/"
+	}
+	return target
+}
+
+var loginPage = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>{{.Site}}</title>
+<style>
+body{font-family:system-ui,sans-serif;display:flex;align-items:center;justify-content:center;min-height:100vh;margin:0;background:#f5f5f5}
+form{background:#fff;padding:2rem;border-radius:8px;box-shadow:0 1px 4px rgba(0,0,0,.1);width:18rem}
+h1{font-size:1.2rem;margin:0 0 1rem}input,button{width:100%;box-sizing:border-box;padding:.5rem;margin-top:.5rem}
+p{color:#c00;font-size:.9rem}
+</style>
+</head>
+<body>
+<form method="post" action="{{.Action}}">
+<h1>{{.Site}}</h1>
+<label for="password">This site is protected. Enter the password to continue.</label>
+<input id="password" name="password" type="password" autocomplete="current-password" autofocus required>
+<input name="return" type="hidden" value="{{.Return}}">
+{{if .Failed}}<p>Wrong password.</p>{{end}}
+<button type="submit">Continue</button>
+</form>
+</body>
+</html>
+`))
+
+// LoginPage 密码保护站点的登录页，提交到站点域名下的 AccessPathPrefix + "login"
+// Login page of password protected sites, submitting to AccessPathPrefix + "login" on the site's host
+func LoginPage(siteName, returnPath string, failed bool) []byte {
+	var b strings.Builder
+	_ = loginPage.Execute(&b, map[string]any{
+		"Site":   siteName,
+		"Action": AccessPathPrefix + "login",
+		"Return": SafeReturn(returnPath),
+		"Failed": failed,
+	})
+	return []byte(b.String())
+}
@@ -0,0 +1,92 @@
+package serve
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/utils"
+)
+
+func TestAllowsInstance(t *testing.T) {
+	site := &models.Site{Name: "docs", AccessMode: constants.SiteAccessMembers}
+	site.ID = 1
+	sign := func(kind string, siteID, viewer uint, fp string) string {
+		t.Helper()
+		signed, err := utils.SiteAccess.Sign(kind, siteID, viewer, fp, time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+	cases := []struct {
+		name             string
+		instance, access string
+		want             bool
+	}{
+		{"nothing", "", "", false},
+		{"instance session", sign(utils.SiteAccessInstance, 1, 5, instanceFingerprint), "", true},
+		{"instance session of another site", sign(utils.SiteAccessInstance, 2, 5, instanceFingerprint), "", false},
+		{"ticket as instance session", sign(utils.SiteAccessTicket, 1, 5, instanceFingerprint), "", false},
+		{"member session", "", sign(utils.SiteAccessSession, 1, 5, fingerprint(site)), true},
+		// 密码登录没有访问者，不能证明是实例用户 A password session has no visitor and proves no instance user
+		{"password session", "", sign(utils.SiteAccessSession, 1, 0, fingerprint(site)), false},
+	}
+	for _, c := range cases {
+		if got, _ := Protection.allowsInstance(site, c.instance, c.access, ""); got != c.want {
+			t.Errorf("%s: allowed = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestAllowsSigned(t *testing.T) {
+	site := &models.Site{Name: "docs", SubDomain: "docs", Domains: []string{"docs.example.com"}, AccessMode: constants.SiteAccessMembers}
+	site.ID = 1
+	signed, err := Protection.SignURL(site, 5, "/reports/../reports/q3.pdf", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	target, err := url.Parse(signed)
+	if err != nil || target.Host != "docs.example.com" || target.Path != "/reports/q3.pdf" {
+		t.Fatalf("SignURL = %q, %v", signed, err)
+	}
+	signature := target.Query().Get(SignedURLParam)
+	if !Protection.allowsSigned(site, "/reports/q3.pdf", signature) {
+		t.Error("signed URL refused for its own path")
+	}
+	if Protection.allowsSigned(site, "/reports/q4.pdf", signature) {
+		t.Error("signed URL allowed another path")
+	}
+	other := *site
+	other.ID = 2
+	if Protection.allowsSigned(&other, "/reports/q3.pdf", signature) {
+		t.Error("signed URL allowed another site")
+	}
+	// 修改访问设置后链接失效 Changing the access settings voids the URL
+	changed := *site
+	changed.AccessMode = constants.SiteAccessPassword
+	if Protection.allowsSigned(&changed, "/reports/q3.pdf", signature) {
+		t.Error("signed URL survived an access settings change")
+	}
+	session, _ := utils.SiteAccess.Sign(utils.SiteAccessSession, 1, 5, fingerprint(site), time.Minute)
+	if Protection.allowsSigned(site, "/reports/q3.pdf", session) {
+		t.Error("session accepted as a signed URL")
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	cases := map[string]string{
+		"Bearer spat_x":  "spat_x",
+		"bearer  spat_x": "spat_x",
+		"Basic spat_x":   "",
+		"Bearer ":        "",
+		"":               "",
+	}
+	for header, want := range cases {
+		if got, _ := bearerToken(header); got != want {
+			t.Errorf("bearerToken(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
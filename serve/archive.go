@@ -0,0 +1,220 @@
+package serve
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/storage"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// evictGrace 淘汰的压缩包延迟关闭，给正在进行的读取留出时间
+// Evicted archives are closed after a delay, leaving time for in-flight reads
+const evictGrace = time.Minute
+
+// archiveFile 发布中的一个文件，来自压缩包条目或按内容哈希存储的文件
+// A file of a release, backed by an archive entry or a file stored by content hash
+type archiveFile struct {
+	name string
+	size uint64
+	hash string
+	etag string // 响应的 ETag，部署清单中的文件为强 ETag Response ETag, strong for files of manifests
+	open func() (io.ReadCloser, error)
+
+	variantKey func(ext string) string // 压缩版本的对象键，为空时不压缩 Object key of a compressed version, nil when never compressed
+
+	mu       sync.Mutex
+	compress int             // 压缩状态 Compression state
+	skipped  map[string]bool // 压缩收益不足、未保存的编码 Encodings not stored because they saved too little
+}
+
+// archive 已打开的发布压缩包或部署清单及其文件索引
+// An opened release archive or deployment manifest with its file index
+type archive struct {
+	object    storage.Object // 压缩包对象，部署清单读取后即关闭因此为空 Archive object, nil for manifests which are closed once read
+	files     map[string]*archiveFile
+	redirects []RedirectRule // 站点根目录 _redirects 中的规则 Rules of _redirects at the site root
+	headers   []HeaderRule   // 站点根目录 _headers 中的规则 Rules of _headers at the site root
+}
+
+// archiveCache 发布文件不可变（清除会生成新文件），因此可以按对象键缓存
+// Release files are immutable (purges produce new files), so they can be cached by object key
+type archiveCache struct {
+	mu       sync.Mutex
+	archives map[string]*archive
+}
+
+var archives = &archiveCache{
+	archives: make(map[string]*archive),
+}
+
+// open 打开或复用发布文件，对象存储上的压缩包只按需读取目录和条目，部署清单中的文件按需从存储读取
+// Open or reuse a release file, archives on object storage only fetch the directory and entries on demand
+// and files of manifests are fetched from storage on demand
+func (a *archiveCache) open(file *models.File) (*archive, error) {
+	key := file.Path
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if cached, ok := a.archives[key]; ok {
+		return cached, nil
+	}
+	var opened *archive
+	var err error
+	if file.Manifest {
+		opened, err = openManifest(key)
+	} else {
+		opened, err = openZip(key, file.ID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if f, ok := opened.files[RedirectsFile]; ok {
+		opened.redirects = loadRules(key, f, ParseRedirects)
+	}
+	if f, ok := opened.files[HeadersFile]; ok {
+		opened.headers = loadRules(key, f, ParseHeaders)
+	}
+	// 超出容量时淘汰任意一个，延迟关闭以免影响正在进行的读取
+	// Evict an arbitrary entry when full, closing it later so in-flight reads are not broken
+	if len(a.archives) >= config.ServeArchiveCache {
+		for evictKey, evicted := range a.archives {
+			delete(a.archives, evictKey)
+			if evicted.object != nil {
+				time.AfterFunc(evictGrace, func() { _ = evicted.object.Close() })
+			}
+			break
+		}
+	}
+	a.archives[key] = opened
+	return opened, nil
+}
+
+// openZip 打开发布压缩包并索引其中的文件
+// Open a release archive and index its files
+func openZip(key string, fileID uint) (*archive, error) {
+	// 缓存的对象比请求存活更久，不能使用请求的 context Cached objects outlive the request and must not use its context
+	object, err := storage.Default.Get(context.Background(), key)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := zip.NewReader(object, object.Info().Size)
+	if err != nil {
+		_ = object.Close()
+		return nil, err
+	}
+	opened := &archive{
+		object: object,
+		files:  make(map[string]*archiveFile, len(reader.File)),
+	}
+	for _, f := range reader.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		name := strings.TrimPrefix(f.Name, "./")
+		entry := &archiveFile{
+			name: name,
+			size: f.UncompressedSize64,
+			hash: fmt.Sprintf("crc32:%08x", f.CRC32),
+			// crc32 不足以保证字节相同，只能作为弱 ETag crc32 cannot guarantee identical bytes, so it is only a weak ETag
+			etag: fmt.Sprintf(`W/"%08x"`, f.CRC32),
+			open: func() (io.ReadCloser, error) { return f.Open() },
+		}
+		// 不规范的条目名不能作为对象键 Irregular entry names cannot be used in object keys
+		if utils.ValidManifestPath(name) {
+			entry.variantKey = func(ext string) string { return storage.CompressedFilePrefix(fileID) + name + "." + ext }
+		}
+		opened.files[name] = entry
+	}
+	return opened, nil
+}
+
+// openManifest 读取部署清单，文件内容在访问时按哈希从存储读取
+// Read a deployment manifest, file contents are fetched from storage by hash when served
+func openManifest(key string) (*archive, error) {
+	object, err := storage.Default.Get(context.Background(), key)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := utils.DecodeManifest(object)
+	_ = object.Close()
+	if err != nil {
+		return nil, err
+	}
+	opened := &archive{
+		files: make(map[string]*archiveFile, len(manifest.Files)),
+	}
+	for _, f := range manifest.Files {
+		blobKey := storage.BlobKey(f.SHA256)
+		opened.files[f.Path] = &archiveFile{
+			name:       f.Path,
+			size:       uint64(f.Size),
+			hash:       "sha256:" + f.SHA256,
+			etag:       `"` + f.SHA256 + `"`,
+			open:       func() (io.ReadCloser, error) { return storage.Default.Get(context.Background(), blobKey) },
+			variantKey: func(ext string) string { return storage.CompressedBlobKey(f.SHA256, ext) },
+		}
+	}
+	return opened, nil
+}
+
+// loadRules 读取站点根目录下的规则文件，无效行记录警告后忽略
+// Read a rule file at the site root, invalid lines are logged and ignored
+func loadRules[T any](key string, f *archiveFile, parse func(io.Reader) ([]T, []error)) []T {
+	reader, err := f.open()
+	if err != nil {
+		logrus.Warnf("failed to open %s of %s: %v", f.name, key, err)
+		return nil
+	}
+	defer reader.Close()
+	rules, errs := parse(reader)
+	for _, err := range errs {
+		logrus.Warnf("invalid rule in %s of %s: %v", f.name, key, err)
+	}
+	return rules
+}
+
+// WalkRelease 按路径顺序读取发布文件中的全部文件，压缩包和部署清单均可
+// Read every file of a release file in path order, for archives and deployment manifests alike
+func WalkRelease(file *models.File, visit func(name string, size int64, body io.Reader) error) error {
+	opened, err := archives.open(file)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(opened.files))
+	for name := range opened.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		f := opened.files[name]
+		body, err := f.open()
+		if err != nil {
+			return fmt.Errorf("open %s: %w", name, err)
+		}
+		err = visit(name, int64(f.size), body)
+		_ = body.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReleaseRedirects 发布文件根目录 _redirects 中的有效规则
+// Valid rules of _redirects at the root of a release file
+func ReleaseRedirects(file *models.File) ([]RedirectRule, error) {
+	opened, err := archives.open(file)
+	if err != nil {
+		return nil, err
+	}
+	return opened.redirects, nil
+}
@@ -0,0 +1,65 @@
+package serve
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+)
+
+type bandwidthType struct {
+	mu      sync.RWMutex
+	actions map[uint]string
+}
+
+// Bandwidth 超出每月流量硬限制的站点及其处理方式，由定期的流量检查更新
+// Sites beyond their hard monthly transfer limit and how they are handled, updated by the periodic transfer check
+var Bandwidth = &bandwidthType{actions: make(map[uint]string)}
+
+// Set 替换超出硬限制的站点，值为 config.TransferActionThrottle 或 config.TransferActionSuspend
+// Replace the sites beyond their hard limit, valued config.TransferActionThrottle or config.TransferActionSuspend
+func (t *bandwidthType) Set(actions map[uint]string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.actions = actions
+}
+
+// Action 站点当前的处理方式，未超出硬限制时为空
+// Current handling of the site, empty while within its hard limit
+func (t *bandwidthType) Action(siteID uint) string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.actions[siteID]
+}
+
+// Throttle 按 traffic.throttle-rate 限制 body 的读取速度
+// Limit the read speed of body to traffic.throttle-rate
+func Throttle(body io.ReadCloser) io.ReadCloser {
+	return &throttledBody{ReadCloser: body, rate: int64(max(config.TrafficThrottleRate, 1)) << 10}
+}
+
+// throttledBody 每次读取后等待到平均速度不超过 rate 字节每秒
+// Wait after each read until the average speed is within rate bytes per second
+type throttledBody struct {
+	io.ReadCloser
+	rate  int64
+	start time.Time
+	read  int64
+}
+
+func (b *throttledBody) Read(p []byte) (int, error) {
+	if b.start.IsZero() {
+		b.start = time.Now()
+	}
+	if int64(len(p)) > b.rate {
+		p = p[:b.rate]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	due := time.Duration(float64(b.read) / float64(b.rate) * float64(time.Second))
+	if wait := due - time.Since(b.start); wait > 0 {
+		time.Sleep(wait)
+	}
+	return n, err
+}
@@ -0,0 +1,42 @@
+package serve
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+)
+
+// TestThrottle 限速后的读取速度不超过 traffic.throttle-rate，内容保持不变
+// Throttled reads stay within traffic.throttle-rate and keep the content intact
+func TestThrottle(t *testing.T) {
+	defer func(rate int) { config.TrafficThrottleRate = rate }(config.TrafficThrottleRate)
+	config.TrafficThrottleRate = 64
+	content := bytes.Repeat([]byte("x"), 32<<10)
+	start := time.Now()
+	read, err := io.ReadAll(Throttle(io.NopCloser(bytes.NewReader(content))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(read, content) {
+		t.Fatal("throttled content differs")
+	}
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond {
+		t.Errorf("read 32 KiB at 64 KiB/s in %v", elapsed)
+	}
+}
+
+// TestBandwidthAction 只有超出硬限制的站点有处理方式
+// Only sites beyond their hard limit have a handling
+func TestBandwidthAction(t *testing.T) {
+	defer Bandwidth.Set(nil)
+	Bandwidth.Set(map[uint]string{1: config.TransferActionSuspend})
+	if got := Bandwidth.Action(1); got != config.TransferActionSuspend {
+		t.Errorf("got %q, want suspend", got)
+	}
+	if got := Bandwidth.Action(2); got != "" {
+		t.Errorf("got %q for a site within its limit", got)
+	}
+}
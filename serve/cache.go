@@ -0,0 +1,215 @@
+package serve
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/cache"
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/utils"
+)
+
+// hostEntry 缓存的主机名归属，SiteID 为 0 表示不属于任何站点
+// Cached owner of a host, SiteID 0 means the host belongs to no site
+type hostEntry struct {
+	SiteID  uint   `json:"site_id"`
+	Preview string `json:"preview,omitempty"` // 预览部署名称 Preview deployment name
+}
+
+// siteEntry 缓存的站点判定数据，站点、当前版本和下架路径一并缓存，命中时无需查询数据库
+// Cached decision data of a site, the site, its active release and takedown paths are cached together so hits need no database query
+type siteEntry struct {
+	Site    *models.Site          `json:"site"`
+	Release *models.SiteRelease   `json:"release,omitempty"` // 当前版本，没有时为空 Active release, nil when there is none
+	Blocked []string              `json:"blocked,omitempty"` // 下架路径 Takedown paths
+	Policy  *models.ContentPolicy `json:"policy,omitempty"`  // 所属组织的内容策略，没有时使用默认策略 Content policy of the owning organization, the defaults apply when nil
+}
+
+// blocks 判断路径是否已被下架
+// Check whether a path has been taken down
+func (e *siteEntry) blocks(p string) bool {
+	for _, pattern := range e.Blocked {
+		if utils.MatchSitePath(pattern, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func hostKey(host string) string {
+	return "serve:host:" + host
+}
+
+func siteKey(siteID uint) string {
+	return "serve:site:" + strconv.FormatUint(uint64(siteID), 10)
+}
+
+func previewKey(siteID uint, name string) string {
+	return "serve:preview:" + strconv.FormatUint(uint64(siteID), 10) + ":" + name
+}
+
+// lookupSite 按主机名查找站点，依次使用缓存的主机名归属和站点数据，缓存不可用时回退到数据库
+// Look up the site by host, using the cached host owner and site data in turn and falling back to the database when the cache is unavailable
+func lookupSite(ctx context.Context, host string) (*siteEntry, *models.SitePreview, error) {
+	if host == "" {
+		return nil, nil, ErrNoSite
+	}
+	var owner hostEntry
+	if err := cache.GetJSON(ctx, hostKey(host), &owner); err == nil {
+		if owner.SiteID == 0 {
+			return nil, nil, ErrNoSite
+		}
+		// 站点改名或解绑域名后旧的归属不再成立，重新查找 Owners are stale once the site renames or unbinds the host, look it up again
+		if entry, err := loadSite(ctx, owner.SiteID); err == nil && HostMatches(entry.Site, host) {
+			if owner.Preview == "" {
+				return entry, nil, nil
+			}
+			if preview, err := loadPreview(ctx, owner.SiteID, owner.Preview); err == nil {
+				return entry, preview, nil
+			}
+		}
+	}
+
+	site, preview, err := findSite(host)
+	if err != nil {
+		remember(ctx, hostKey(host), hostEntry{}, 0)
+		return nil, nil, err
+	}
+	entry, err := buildSite(ctx, site)
+	if err != nil {
+		return nil, nil, err
+	}
+	owner = hostEntry{SiteID: site.ID}
+	if preview != nil {
+		owner.Preview = preview.Name
+		remember(ctx, previewKey(site.ID, preview.Name), preview, previewTTL(preview))
+	}
+	remember(ctx, hostKey(host), owner, 0)
+	return entry, preview, nil
+}
+
+// loadSite 读取缓存的站点数据，未命中时从数据库加载
+// Read the cached site data, loading it from the database on a miss
+func loadSite(ctx context.Context, siteID uint) (*siteEntry, error) {
+	entry := &siteEntry{}
+	if err := cache.GetJSON(ctx, siteKey(siteID), entry); err == nil && entry.Site != nil {
+		return entry, nil
+	}
+	site, err := store.Site.GetByID(siteID)
+	if err != nil {
+		return nil, err
+	}
+	return buildSite(ctx, site)
+}
+
+// buildSite 从数据库加载站点的当前版本、下架路径和内容策略并写入缓存
+// Load the site's active release, takedown paths and content policy from the database and cache them
+func buildSite(ctx context.Context, site *models.Site) (*siteEntry, error) {
+	blocked, err := store.Site.ListBlockedPatterns(site.ID)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := store.ContentPolicy.Override(&site.Project)
+	if err != nil {
+		return nil, err
+	}
+	entry := &siteEntry{Site: site, Blocked: blocked, Policy: policy}
+	if release, err := store.Site.GetLatestRelease(site); err == nil {
+		entry.Release = release
+	}
+	remember(ctx, siteKey(site.ID), entry, 0)
+	return entry, nil
+}
+
+// loadPreview 读取缓存的预览部署，未命中或已过期时从数据库加载
+// Read the cached preview deployment, loading it from the database when missing or expired
+func loadPreview(ctx context.Context, siteID uint, name string) (*models.SitePreview, error) {
+	preview := &models.SitePreview{}
+	if err := cache.GetJSON(ctx, previewKey(siteID, name), preview); err == nil && preview.ExpiresAt.After(time.Now()) {
+		return preview, nil
+	}
+	preview, err := store.Preview.GetActive(siteID, name)
+	if err != nil {
+		return nil, err
+	}
+	remember(ctx, previewKey(siteID, name), preview, previewTTL(preview))
+	return preview, nil
+}
+
+// previewTTL 预览部署的缓存有效期，不超过其剩余时间
+// Cache lifetime of a preview deployment, never beyond its remaining time
+func previewTTL(preview *models.SitePreview) time.Duration {
+	return min(cache.TTL, time.Until(preview.ExpiresAt))
+}
+
+// remember 写入缓存，失败时只记录日志，缓存不可用不影响服务
+// Write to the cache, only logging failures since serving must not depend on the cache
+func remember(ctx context.Context, key string, v any, ttl time.Duration) {
+	if ttl < 0 {
+		return
+	}
+	if err := cache.SetJSON(ctx, key, v, ttl); err != nil {
+		utils.Log.Ctx(ctx).Warnf("failed to cache %s: %v", key, err)
+	}
+}
+
+type siteCacheType struct{}
+
+// SiteCache 站点查找缓存，修改站点、域名、版本或预览后调用以立即生效
+// Site lookup cache, called after changing sites, domains, releases or previews so the change takes effect at once
+var SiteCache = siteCacheType{}
+
+// Forget 失效站点数据和给定主机名的归属，新增的主机名需要传入以清除之前缓存的未命中
+// Invalidate the site data and the owners of the given hosts, newly added hosts must be passed to clear previously cached misses
+func (siteCacheType) Forget(ctx context.Context, siteID uint, hosts ...string) {
+	keys := []string{siteKey(siteID)}
+	for _, host := range hosts {
+		keys = append(keys, hostKey(normalizeHost(host)))
+	}
+	forget(ctx, keys...)
+}
+
+// ForgetSite 失效站点数据及其当前所有主机名的归属
+// Invalidate the site data and the owners of all its current hosts
+func (s siteCacheType) ForgetSite(ctx context.Context, site *models.Site) {
+	s.Forget(ctx, site.ID, SiteHosts(site)...)
+}
+
+// ForgetProject 失效项目下所有站点的数据及其主机名的归属，用于可见性、删除和恢复等项目级变更，回收站中的站点同样失效
+// Invalidate the data and host owners of all sites of a project, used for project level changes such as visibility, deletion and restoring; sites in the trash are invalidated too
+func (s siteCacheType) ForgetProject(ctx context.Context, projectID uint) {
+	sites, err := store.Project.ListAllSites(projectID)
+	if err != nil {
+		utils.Log.Ctx(ctx).Warnf("failed to list sites of project %d for cache invalidation: %v", projectID, err)
+		return
+	}
+	for _, site := range sites {
+		s.ForgetSite(ctx, &site)
+	}
+	// 项目主机名在项目改名或更换主站点后指向其他站点 The project host points at another site once the project is renamed or changes its primary site
+	if project, err := store.Project.GetByID(projectID); err == nil && ProjectHost(project) != "" {
+		forget(ctx, hostKey(ProjectHost(project)))
+	}
+}
+
+// ForgetPreview 失效预览部署及其主机名的归属
+// Invalidate a preview deployment and the owner of its host
+func (siteCacheType) ForgetPreview(ctx context.Context, site *models.Site, name string) {
+	keys := []string{previewKey(site.ID, name)}
+	if base := BaseDomain(site); site.SubDomain != "" && base != "" {
+		keys = append(keys, hostKey(normalizeHost(utils.Domain.PreviewHost(name, site.SubDomain, base))))
+	}
+	if config.ServeProjectDomain != "" && site.Project.ID == site.ProjectID {
+		keys = append(keys, hostKey(normalizeHost(utils.Domain.PreviewHost(name, site.Project.Name, config.ServeProjectDomain))))
+	}
+	forget(ctx, keys...)
+}
+
+func forget(ctx context.Context, keys ...string) {
+	if err := cache.Delete(ctx, keys...); err != nil {
+		utils.Log.Ctx(ctx).Warnf("failed to invalidate cached sites: %v", err)
+	}
+}
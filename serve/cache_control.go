@@ -0,0 +1,45 @@
+package serve
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CacheRule 按路径设置 Cache-Control 的规则，格式为 "<路径模式> <Cache-Control 值>"
+// A rule setting Cache-Control by path, formatted as "<path pattern> <Cache-Control value>"
+type CacheRule struct {
+	Path  string `json:"path"`  // 路径模式，语法与 _redirects 的来源相同 Path pattern, same syntax as the from of _redirects
+	Value string `json:"value"` // Cache-Control 的值 Value of Cache-Control
+}
+
+// ParseCacheRule 解析并校验一条缓存规则，实例配置与站点设置共用
+// Parse and validate a cache rule, shared by the instance configuration and site settings
+func ParseCacheRule(line string) (CacheRule, error) {
+	pattern, value, ok := strings.Cut(strings.TrimSpace(line), " ")
+	if !ok {
+		return CacheRule{}, fmt.Errorf("expected \"<path> <Cache-Control value>\", got %q", line)
+	}
+	if err := validPattern(pattern); err != nil {
+		return CacheRule{}, err
+	}
+	_, value, err := ParseHeaderLine("Cache-Control: " + value)
+	if err != nil {
+		return CacheRule{}, err
+	}
+	if value == "" {
+		return CacheRule{}, fmt.Errorf("empty Cache-Control value for %s", pattern)
+	}
+	return CacheRule{Path: pattern, Value: value}, nil
+}
+
+// String 规范格式 Normalized format
+func (r CacheRule) String() string {
+	return r.Path + " " + r.Value
+}
+
+// Match 判断路径是否匹配规则
+// Check whether the path matches the rule
+func (r CacheRule) Match(p string) bool {
+	_, ok := matchPattern(r.Path, p)
+	return ok
+}
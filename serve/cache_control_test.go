@@ -0,0 +1,56 @@
+package serve
+
+import "testing"
+
+// TestParseCacheRule 解析路径模式和 Cache-Control 值，拒绝缺少值或路径不以 / 开头的规则
+// Parse the path pattern and Cache-Control value, refusing rules without a value or with a path not starting with /
+func TestParseCacheRule(t *testing.T) {
+	rule, err := ParseCacheRule("  /assets/*   public, max-age=31536000, immutable ")
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if rule.String() != "/assets/* public, max-age=31536000, immutable" || !rule.Match("/assets/app.js") || rule.Match("/index.html") {
+		t.Errorf("Unexpected rule %+v", rule)
+	}
+	for _, line := range []string{"/assets/*", "assets/* no-cache", "/* no-cache\r\nX-Injected: 1"} {
+		if _, err := ParseCacheRule(line); err == nil {
+			t.Errorf("Expected %q to be refused", line)
+		}
+	}
+}
+
+// TestNotModified If-None-Match 弱比较并匹配压缩版本，同时出现时忽略 If-Modified-Since
+// If-None-Match compares weakly and matches compressed versions, If-Modified-Since is ignored when both are present
+func TestNotModified(t *testing.T) {
+	d := &Decision{
+		Status: 200,
+		Access: Access{Allowed: true},
+		Entry:  &Entry{Name: "app.js"},
+		Headers: []Header{
+			{Name: "ETag", Value: `"abc"`},
+			{Name: "Last-Modified", Value: "Wed, 14 Oct 2026 15:00:00 GMT"},
+		},
+	}
+	cases := []struct {
+		ifNoneMatch, ifModifiedSince string
+		want                         bool
+	}{
+		{`"abc"`, "", true},
+		{`W/"abc"`, "", true},
+		{`"old", "abc-br"`, "", true},
+		{"*", "", true},
+		{`"old"`, "Wed, 14 Oct 2026 16:00:00 GMT", false},
+		{"", "Wed, 14 Oct 2026 15:00:00 GMT", true},
+		{"", "Wed, 14 Oct 2026 14:59:59 GMT", false},
+		{"", "not a date", false},
+	}
+	for _, c := range cases {
+		if _, ok := d.NotModified(c.ifNoneMatch, c.ifModifiedSince); ok != c.want {
+			t.Errorf("NotModified(%q, %q) = %v, want %v", c.ifNoneMatch, c.ifModifiedSince, ok, c.want)
+		}
+	}
+	d.Status = 404
+	if _, ok := d.NotModified(`"abc"`, ""); ok {
+		t.Errorf("Expected fallback responses to never be not modified")
+	}
+}
@@ -0,0 +1,233 @@
+package serve
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/storage"
+	"github.com/andybalholm/brotli"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// compressWorkers 同时进行压缩的文件数量 Number of files compressed at the same time
+	compressWorkers = 2
+	// compressQueueSize 等待压缩的文件数量上限，访问触发的压缩在队列已满时放弃 Max files waiting for compression, compressions triggered by requests are dropped when full
+	compressQueueSize = 1024
+	// brotliLevel 预压缩只进行一次，使用较高的压缩级别 Precompression happens once, so a high level is used
+	brotliLevel = 9
+)
+
+// compressibleExts 文本类文件的扩展名，图片和视频等已压缩的格式不再压缩
+// Extensions of text-like files, already compressed formats such as images and videos are skipped
+var compressibleExts = []string{
+	".html", ".htm", ".xhtml", ".css", ".js", ".mjs", ".cjs", ".json", ".map", ".webmanifest", ".svg", ".xml",
+	".rss", ".atom", ".txt", ".md", ".csv", ".wasm", ".ico", ".ttf", ".otf", ".eot",
+}
+
+// encoding 站点文件的压缩编码
+// A compression encoding of site files
+type encoding struct {
+	name   string // Content-Encoding 的值 Value of Content-Encoding
+	ext    string // 压缩版本对象键的扩展名 Extension of the object key of the compressed version
+	writer func(w io.Writer) io.WriteCloser
+}
+
+// encodings 支持的编码，按服务端偏好排列
+// Supported encodings in server preference order
+var encodings = []encoding{
+	{name: "br", ext: "br", writer: func(w io.Writer) io.WriteCloser { return brotli.NewWriterLevel(w, brotliLevel) }},
+	{name: "gzip", ext: "gz", writer: func(w io.Writer) io.WriteCloser {
+		writer, _ := gzip.NewWriterLevel(w, gzip.BestCompression)
+		return writer
+	}},
+}
+
+// 文件的压缩状态 Compression state of a file
+const (
+	compressUnknown = iota // 尚未检查 Not checked yet
+	compressQueued         // 等待或正在压缩 Waiting for or being compressed
+	compressDone           // 已压缩 Compressed
+)
+
+// compressible 文件是否值得压缩
+// Whether a file is worth compressing
+func compressible(name string, size uint64) bool {
+	return config.ServeCompress && size >= uint64(config.ServeCompressMinSize) && size <= uint64(config.ServeCompressMaxSize)<<20 &&
+		slices.Contains(compressibleExts, strings.ToLower(path.Ext(name)))
+}
+
+// negotiate 按 Accept-Encoding 返回客户端接受的编码，按服务端偏好排列，q=0 表示拒绝
+// Return the encodings accepted by Accept-Encoding in server preference order, q=0 means refused
+func negotiate(acceptEncoding string) []encoding {
+	accepted := make(map[string]bool)
+	wildcard := false
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		ok := true
+		if value, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			ok = err == nil && q > 0
+		}
+		if name == "*" {
+			wildcard = ok
+			continue
+		}
+		if name != "" {
+			accepted[name] = ok
+		}
+	}
+	var result []encoding
+	for _, enc := range encodings {
+		if ok, listed := accepted[enc.name]; ok || !listed && wildcard {
+			result = append(result, enc)
+		}
+	}
+	return result
+}
+
+// openVariant 打开文件的压缩版本，尚未压缩时加入压缩队列并返回空，本次请求使用原文件
+// Open the compressed version of a file, queueing the file and returning nil when not compressed yet so this request uses the original
+func (f *archiveFile) openVariant(enc encoding) storage.Object {
+	f.mu.Lock()
+	state, skipped := f.compress, f.skipped[enc.name]
+	f.mu.Unlock()
+	if state == compressQueued || skipped {
+		return nil
+	}
+	object, err := storage.Default.Get(context.Background(), f.variantKey(enc.ext))
+	if err == nil {
+		return object
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		logrus.Warnf("failed to open compressed %s: %v", f.name, err)
+		return nil
+	}
+	if state == compressUnknown {
+		compressor.enqueue(f, false)
+	}
+	return nil
+}
+
+// compressorType 后台压缩队列，压缩版本写入存储后由所有节点共用
+// Background compression queue, the compressed versions are written to storage and shared by all nodes
+type compressorType struct {
+	once  sync.Once
+	queue chan *archiveFile
+}
+
+var compressor = &compressorType{
+	queue: make(chan *archiveFile, compressQueueSize),
+}
+
+// enqueue 将文件加入压缩队列，wait 为假时队列已满则放弃，之后的访问会再次尝试
+// Queue a file for compression, giving up when the queue is full unless wait is set; later requests try again
+func (p *compressorType) enqueue(f *archiveFile, wait bool) {
+	f.mu.Lock()
+	if f.compress != compressUnknown {
+		f.mu.Unlock()
+		return
+	}
+	f.compress = compressQueued
+	f.mu.Unlock()
+	p.once.Do(func() {
+		for range compressWorkers {
+			go p.work()
+		}
+	})
+	if wait {
+		p.queue <- f
+		return
+	}
+	select {
+	case p.queue <- f:
+	default:
+		f.mu.Lock()
+		f.compress = compressUnknown
+		f.mu.Unlock()
+	}
+}
+
+func (p *compressorType) work() {
+	for f := range p.queue {
+		skipped, err := compressFile(f)
+		if err != nil {
+			logrus.Warnf("failed to compress %s: %v", f.name, err)
+		}
+		f.mu.Lock()
+		f.compress = compressDone
+		f.skipped = skipped
+		f.mu.Unlock()
+	}
+}
+
+// compressFile 为文件生成各编码的压缩版本，压缩后体积减少不足 10% 的编码不保存，返回这些编码
+// Produce the compressed versions of a file, encodings saving less than 10% are not stored and are returned
+func compressFile(f *archiveFile) (map[string]bool, error) {
+	ctx := context.Background()
+	reader, err := f.open()
+	if err != nil {
+		return nil, err
+	}
+	content, err := io.ReadAll(io.LimitReader(reader, int64(config.ServeCompressMaxSize)<<20+1))
+	_ = reader.Close()
+	if err != nil {
+		return nil, err
+	}
+	skipped := make(map[string]bool)
+	for _, enc := range encodings {
+		key := f.variantKey(enc.ext)
+		// 相同内容的文件共用压缩版本 Files with the same content share the compressed version
+		if object, err := storage.Default.Get(ctx, key); err == nil {
+			_ = object.Close()
+			continue
+		}
+		var buf bytes.Buffer
+		writer := enc.writer(&buf)
+		if _, err := writer.Write(content); err != nil {
+			return skipped, err
+		}
+		if err := writer.Close(); err != nil {
+			return skipped, err
+		}
+		if buf.Len() > len(content)*9/10 {
+			skipped[enc.name] = true
+			continue
+		}
+		if err := storage.Default.Put(ctx, key, &buf, int64(buf.Len())); err != nil {
+			return skipped, err
+		}
+	}
+	return skipped, nil
+}
+
+// Precompress 在后台为发布中的文本类文件生成压缩版本，部署后调用，首次访问时即可使用压缩版本
+// Produce the compressed versions of the text-like files of a release in the background, called after deploying so the first visits already get them
+func Precompress(file *models.File) {
+	if !config.ServeCompress {
+		return
+	}
+	go func() {
+		opened, err := archives.open(file)
+		if err != nil {
+			logrus.Warnf("failed to open %s for compression: %v", file.Path, err)
+			return
+		}
+		for _, f := range opened.files {
+			if f.variantKey != nil && compressible(f.name, f.size) {
+				compressor.enqueue(f, true)
+			}
+		}
+	}()
+}
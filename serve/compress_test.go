@@ -0,0 +1,32 @@
+package serve
+
+import "testing"
+
+// TestNegotiate 按服务端偏好返回客户端接受的编码，q=0 的编码被拒绝，* 匹配未列出的编码
+// Accepted encodings come back in server preference order, q=0 refuses an encoding and * matches unlisted ones
+func TestNegotiate(t *testing.T) {
+	cases := map[string][]string{
+		"":                      nil,
+		"gzip, deflate, br":     {"br", "gzip"},
+		"gzip;q=1.0, br;q=0":    {"gzip"},
+		"*":                     {"br", "gzip"},
+		"*;q=0.5, gzip;q=0":     {"br"},
+		"identity, BR ; q=0.8":  {"br"},
+		"br;q=bad, gzip;q=0.01": {"gzip"},
+	}
+	for header, want := range cases {
+		got := negotiate(header)
+		if len(got) != len(want) {
+			t.Errorf("negotiate(%q) = %v, want %v", header, got, want)
+			continue
+		}
+		for i := range got {
+			if got[i].name != want[i] {
+				t.Errorf("negotiate(%q)[%d] = %s, want %s", header, i, got[i].name, want[i])
+			}
+		}
+	}
+	if etag := EncodedETag(`"sha256:abc"`, "br"); etag != `"sha256:abc-br"` {
+		t.Errorf("Unexpected encoded ETag %s", etag)
+	}
+}
@@ -0,0 +1,78 @@
+package serve
+
+import (
+	"mime"
+	"path"
+	"strings"
+
+	"github.com/LiteyukiStudio/spage/config"
+)
+
+// builtinContentTypes 不依赖系统 mime.types 的常见静态资源类型，系统表缺失或过时时同样正确
+// Common static asset types independent of the system mime.types, correct even when that table is missing or outdated
+var builtinContentTypes = map[string]string{
+	".html":        "text/html; charset=utf-8",
+	".htm":         "text/html; charset=utf-8",
+	".css":         "text/css; charset=utf-8",
+	".js":          "text/javascript; charset=utf-8",
+	".mjs":         "text/javascript; charset=utf-8",
+	".cjs":         "text/javascript; charset=utf-8",
+	".json":        "application/json",
+	".map":         "application/json",
+	".webmanifest": "application/manifest+json",
+	".xml":         "application/xml",
+	".txt":         "text/plain; charset=utf-8",
+	".md":          "text/markdown; charset=utf-8",
+	".csv":         "text/csv; charset=utf-8",
+	".wasm":        "application/wasm",
+	".svg":         "image/svg+xml",
+	".png":         "image/png",
+	".jpg":         "image/jpeg",
+	".jpeg":        "image/jpeg",
+	".gif":         "image/gif",
+	".webp":        "image/webp",
+	".avif":        "image/avif",
+	".ico":         "image/x-icon",
+	".woff":        "font/woff",
+	".woff2":       "font/woff2",
+	".ttf":         "font/ttf",
+	".otf":         "font/otf",
+	".mp4":         "video/mp4",
+	".webm":        "video/webm",
+	".m3u8":        "application/vnd.apple.mpegurl",
+	".mp3":         "audio/mpeg",
+	".ogg":         "audio/ogg",
+	".wav":         "audio/wav",
+	".flac":        "audio/flac",
+	".pdf":         "application/pdf",
+	".zip":         "application/zip",
+	".gz":          "application/gzip",
+	".tgz":         "application/gzip",
+	".tar":         "application/x-tar",
+	".7z":          "application/x-7z-compressed",
+	".apk":         "application/vnd.android.package-archive",
+	".dmg":         "application/x-apple-diskimage",
+	".iso":         "application/x-iso9660-image",
+}
+
+// ContentType 按扩展名判断文件的 Content-Type，依次使用实例配置 serve.mime-types、内置类型和系统类型表，均未知时为 application/octet-stream
+// Content-Type of a file by its extension, from the instance setting serve.mime-types, the built-in types and the system table in order, application/octet-stream when unknown
+func ContentType(name string) string {
+	ext := strings.ToLower(path.Ext(name))
+	if ext == "" {
+		return "application/octet-stream"
+	}
+	for _, line := range config.ServeMIMETypes {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && strings.EqualFold(fields[0], ext) {
+			return strings.Join(fields[1:], " ")
+		}
+	}
+	if contentType, ok := builtinContentTypes[ext]; ok {
+		return contentType
+	}
+	if contentType := mime.TypeByExtension(ext); contentType != "" {
+		return contentType
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,49 @@
+package serve
+
+import (
+	"testing"
+
+	"github.com/LiteyukiStudio/spage/config"
+)
+
+// TestContentType 内置类型覆盖 wasm、模块脚本和 source map，实例配置优先，未知扩展名为二进制流
+// Built-in types cover wasm, module scripts and source maps, the instance setting wins and unknown extensions are binary streams
+func TestContentType(t *testing.T) {
+	defer func(types []string) { config.ServeMIMETypes = types }(config.ServeMIMETypes)
+	config.ServeMIMETypes = []string{".glb model/gltf-binary", ".MAP application/octet-stream"}
+	cases := map[string]string{
+		"app.wasm":       "application/wasm",
+		"main.mjs":       "text/javascript; charset=utf-8",
+		"Index.HTML":     "text/html; charset=utf-8",
+		"scene.glb":      "model/gltf-binary",
+		"main.js.map":    "application/octet-stream",
+		"release.tar.gz": "application/gzip",
+		"data.unknownxx": "application/octet-stream",
+		"LICENSE":        "application/octet-stream",
+	}
+	for name, want := range cases {
+		if got := ContentType(name); got != want {
+			t.Errorf("%s: got %q, want %q", name, got, want)
+		}
+	}
+}
+
+// TestDownloadName download 参数为空时使用文件名，参数中的路径被去掉
+// An empty download parameter uses the file name, paths in the parameter are dropped
+func TestDownloadName(t *testing.T) {
+	cases := []struct {
+		query, want string
+	}{
+		{"", ""},
+		{"v=1", ""},
+		{"download", "app.tar.gz"},
+		{"download=", "app.tar.gz"},
+		{"download=custom.tgz", "custom.tgz"},
+		{"download=..%2F..%2Fetc%2Fpasswd", "passwd"},
+	}
+	for _, tc := range cases {
+		if got := downloadName(tc.query, "releases/app.tar.gz"); got != tc.want {
+			t.Errorf("%q: got %q, want %q", tc.query, got, tc.want)
+		}
+	}
+}
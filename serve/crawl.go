@@ -0,0 +1,123 @@
+package serve
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/constants"
+)
+
+// 上传的文件中没有时按站点设置生成的文件 Files generated from the site settings when the upload has none
+const (
+	RobotsFile  = "robots.txt"
+	SitemapFile = "sitemap.xml"
+)
+
+// maxSitemapURLs 单个 sitemap.xml 最多列出的地址数 Most URLs listed by a single sitemap.xml
+const maxSitemapURLs = 50000
+
+// generatedEntry 按站点设置生成 robots.txt 或 sitemap.xml，站点未启用或路径不是这两个文件时返回 nil；host 为地址使用的主机名
+// Generate robots.txt or sitemap.xml from the site settings, nil when the site has it disabled or the path is neither file; host is used in the URLs
+func generatedEntry(entry *siteEntry, opened *archive, p, host string, modified time.Time) *archiveFile {
+	name := strings.TrimPrefix(p, "/")
+	var content []byte
+	switch name {
+	case RobotsFile:
+		content = robotsTxt(entry, host)
+	case SitemapFile:
+		if entry.Site.Sitemap && host != "" {
+			content = sitemapXML(entry, opened, host, modified)
+		}
+	}
+	if content == nil {
+		return nil
+	}
+	return memoryFile(name, content)
+}
+
+// memoryFile 内容在内存中生成的文件，ETag 取自内容的 sha256
+// A file generated in memory, its ETag taken from the sha256 of the content
+func memoryFile(name string, content []byte) *archiveFile {
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])
+	return &archiveFile{
+		name: name,
+		size: uint64(len(content)),
+		hash: hash,
+		etag: `"` + hash + `"`,
+		open: func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(content)), nil
+		},
+	}
+}
+
+// robotsTxt 按站点的 robots.txt 策略生成内容，启用 sitemap.xml 时附带其地址
+// Content of the site's robots policy, pointing at sitemap.xml when it is enabled
+func robotsTxt(entry *siteEntry, host string) []byte {
+	site := entry.Site
+	var b strings.Builder
+	switch site.RobotsPolicy {
+	case constants.RobotsPolicyAllow:
+		b.WriteString("User-agent: *\nAllow: /\n")
+	case constants.RobotsPolicyDisallow:
+		b.WriteString("User-agent: *\nDisallow: /\n")
+	case constants.RobotsPolicyCustom:
+		b.WriteString(site.RobotsTxt)
+		if !strings.HasSuffix(site.RobotsTxt, "\n") {
+			b.WriteString("\n")
+		}
+	default:
+		return nil
+	}
+	if site.Sitemap && host != "" && site.RobotsPolicy != constants.RobotsPolicyCustom {
+		b.WriteString("\nSitemap: https://" + host + "/" + SitemapFile + "\n")
+	}
+	return []byte(b.String())
+}
+
+// sitemapXML 列出发布中的 HTML 页面，index.html 使用所在目录的地址，跳过 404.html 和已下架的路径
+// List the HTML pages of the release, index.html uses the URL of its directory while 404.html and taken down paths are skipped
+func sitemapXML(entry *siteEntry, opened *archive, host string, modified time.Time) []byte {
+	var paths []string
+	for name := range opened.files {
+		if !strings.HasSuffix(name, ".html") || name == "404.html" {
+			continue
+		}
+		p := "/" + name
+		if name == "index.html" || strings.HasSuffix(name, "/index.html") {
+			p = strings.TrimSuffix(p, "index.html")
+		}
+		if entry.blocks(p) {
+			continue
+		}
+		paths = append(paths, p)
+	}
+	slices.Sort(paths)
+	if len(paths) > maxSitemapURLs {
+		paths = paths[:maxSitemapURLs]
+	}
+	lastmod := ""
+	if !modified.IsZero() {
+		lastmod = modified.UTC().Format(time.RFC3339)
+	}
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	b.WriteString(`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">` + "\n")
+	for _, p := range paths {
+		b.WriteString("  <url><loc>")
+		_ = xml.EscapeText(&b, []byte("https://"+host+p))
+		b.WriteString("</loc>")
+		if lastmod != "" {
+			b.WriteString("<lastmod>" + lastmod + "</lastmod>")
+		}
+		b.WriteString("</url>\n")
+	}
+	b.WriteString("</urlset>\n")
+	return b.Bytes()
+}
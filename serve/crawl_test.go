@@ -0,0 +1,88 @@
+package serve
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/models"
+)
+
+// TestGeneratedRobots 按策略生成 robots.txt，启用 sitemap.xml 时附带其地址，未设置策略时不生成
+// robots.txt follows the policy and points at sitemap.xml when enabled, nothing is generated without a policy
+func TestGeneratedRobots(t *testing.T) {
+	opened := &archive{files: map[string]*archiveFile{}}
+	cases := []struct {
+		site *models.Site
+		want string
+	}{
+		{&models.Site{}, ""},
+		{&models.Site{RobotsPolicy: constants.RobotsPolicyAllow}, "User-agent: *\nAllow: /\n"},
+		{&models.Site{RobotsPolicy: constants.RobotsPolicyDisallow, Sitemap: true}, "User-agent: *\nDisallow: /\n\nSitemap: https://example.com/sitemap.xml\n"},
+		{&models.Site{RobotsPolicy: constants.RobotsPolicyCustom, RobotsTxt: "User-agent: bot\nDisallow: /private", Sitemap: true}, "User-agent: bot\nDisallow: /private\n"},
+	}
+	for _, tc := range cases {
+		file := generatedEntry(&siteEntry{Site: tc.site}, opened, "/robots.txt", "example.com", time.Time{})
+		if tc.want == "" {
+			if file != nil {
+				t.Errorf("policy %q: expected no robots.txt", tc.site.RobotsPolicy)
+			}
+			continue
+		}
+		if got := readEntry(t, file); got != tc.want {
+			t.Errorf("policy %q: got %q, want %q", tc.site.RobotsPolicy, got, tc.want)
+		}
+	}
+}
+
+// TestGeneratedSitemap sitemap.xml 列出 HTML 页面，跳过 404.html 和下架的路径
+// sitemap.xml lists the HTML pages, skipping 404.html and taken down paths
+func TestGeneratedSitemap(t *testing.T) {
+	opened := &archive{files: map[string]*archiveFile{
+		"index.html":      {name: "index.html"},
+		"about.html":      {name: "about.html"},
+		"docs/index.html": {name: "docs/index.html"},
+		"secret.html":     {name: "secret.html"},
+		"404.html":        {name: "404.html"},
+		"style.css":       {name: "style.css"},
+	}}
+	entry := &siteEntry{Site: &models.Site{Sitemap: true}, Blocked: []string{"/secret.html"}}
+	if generatedEntry(&siteEntry{Site: &models.Site{}}, opened, "/sitemap.xml", "example.com", time.Time{}) != nil {
+		t.Fatal("expected no sitemap.xml when disabled")
+	}
+	modified := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	got := readEntry(t, generatedEntry(entry, opened, "/sitemap.xml", "example.com", modified))
+	var locs []string
+	for _, line := range strings.Split(got, "\n") {
+		if loc, ok := strings.CutPrefix(line, "  <url><loc>"); ok {
+			loc, _, _ = strings.Cut(loc, "</loc>")
+			locs = append(locs, loc)
+		}
+	}
+	want := []string{"https://example.com/", "https://example.com/about.html", "https://example.com/docs/"}
+	if strings.Join(locs, " ") != strings.Join(want, " ") {
+		t.Errorf("got %v, want %v", locs, want)
+	}
+	if !strings.Contains(got, "<lastmod>2024-05-01T08:00:00Z</lastmod>") {
+		t.Errorf("expected lastmod in %q", got)
+	}
+}
+
+func readEntry(t *testing.T, file *archiveFile) string {
+	t.Helper()
+	if file == nil {
+		t.Fatal("expected a generated file")
+	}
+	body, err := file.open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer body.Close()
+	content, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
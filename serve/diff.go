@@ -0,0 +1,81 @@
+package serve
+
+import (
+	"sort"
+
+	"github.com/LiteyukiStudio/spage/models"
+)
+
+// ReleaseFile 发布中的一个文件及其大小和内容哈希
+// A file of a release with its size and content hash
+type ReleaseFile struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+	Hash string `json:"hash"` // 带算法前缀的哈希，部署清单为 sha256:，压缩包为 crc32: Hash prefixed with its algorithm, sha256: for manifests and crc32: for archives
+}
+
+// FileChange 两个版本之间内容变化的文件
+// A file whose content differs between two releases
+type FileChange struct {
+	Path     string `json:"path"`
+	FromSize int64  `json:"from_size"`
+	FromHash string `json:"from_hash"`
+	ToSize   int64  `json:"to_size"`
+	ToHash   string `json:"to_hash"`
+}
+
+// ReleaseDiff 两个版本之间新增、删除和变化的文件，均按路径排序
+// Files added, removed and changed between two releases, each sorted by path
+type ReleaseDiff struct {
+	Added     []ReleaseFile `json:"added"`
+	Removed   []ReleaseFile `json:"removed"`
+	Changed   []FileChange  `json:"changed"`
+	Unchanged int           `json:"unchanged"` // 未变化的文件数量 Number of unchanged files
+}
+
+// ReleaseFiles 按路径顺序列出发布文件中的全部文件，压缩包和部署清单均可
+// List every file of a release file in path order, for archives and deployment manifests alike
+func ReleaseFiles(file *models.File) ([]ReleaseFile, error) {
+	opened, err := archives.open(file)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]ReleaseFile, 0, len(opened.files))
+	for name, f := range opened.files {
+		files = append(files, ReleaseFile{Path: name, Size: int64(f.size), Hash: f.hash})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+// DiffReleases 比较两个版本的文件；压缩包和部署清单的哈希算法不同，无法证明内容相同，此时同一路径的文件均视为变化
+// Compare the files of two releases; archives and manifests use different hash algorithms which cannot prove identical content, so such files always count as changed
+func DiffReleases(from, to []ReleaseFile) ReleaseDiff {
+	diff := ReleaseDiff{Added: []ReleaseFile{}, Removed: []ReleaseFile{}, Changed: []FileChange{}}
+	previous := make(map[string]ReleaseFile, len(from))
+	for _, f := range from {
+		previous[f.Path] = f
+	}
+	for _, f := range to {
+		old, ok := previous[f.Path]
+		if !ok {
+			diff.Added = append(diff.Added, f)
+			continue
+		}
+		delete(previous, f.Path)
+		if old.Size == f.Size && old.Hash == f.Hash {
+			diff.Unchanged++
+			continue
+		}
+		diff.Changed = append(diff.Changed, FileChange{Path: f.Path, FromSize: old.Size, FromHash: old.Hash, ToSize: f.Size, ToHash: f.Hash})
+	}
+	for _, f := range from {
+		if _, ok := previous[f.Path]; ok {
+			diff.Removed = append(diff.Removed, f)
+		}
+	}
+	sort.Slice(diff.Added, func(i, j int) bool { return diff.Added[i].Path < diff.Added[j].Path })
+	sort.Slice(diff.Removed, func(i, j int) bool { return diff.Removed[i].Path < diff.Removed[j].Path })
+	sort.Slice(diff.Changed, func(i, j int) bool { return diff.Changed[i].Path < diff.Changed[j].Path })
+	return diff
+}
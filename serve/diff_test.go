@@ -0,0 +1,50 @@
+package serve
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestDiffReleases 新增、删除和变化的文件按路径排序，哈希算法不同的同名文件视为变化
+// Added, removed and changed files are sorted by path, files of the same path with different hash algorithms count as changed
+func TestDiffReleases(t *testing.T) {
+	from := []ReleaseFile{
+		{Path: "index.html", Size: 10, Hash: "sha256:aa"},
+		{Path: "old.js", Size: 5, Hash: "sha256:bb"},
+		{Path: "style.css", Size: 7, Hash: "sha256:cc"},
+		{Path: "zip.txt", Size: 3, Hash: "crc32:0000000d"},
+	}
+	to := []ReleaseFile{
+		{Path: "style.css", Size: 7, Hash: "sha256:cc"},
+		{Path: "index.html", Size: 12, Hash: "sha256:dd"},
+		{Path: "new.js", Size: 4, Hash: "sha256:ee"},
+		{Path: "a.js", Size: 1, Hash: "sha256:ff"},
+		{Path: "zip.txt", Size: 3, Hash: "sha256:11"},
+	}
+	diff := DiffReleases(from, to)
+	if got := []string{diff.Added[0].Path, diff.Added[1].Path}; len(diff.Added) != 2 || !reflect.DeepEqual(got, []string{"a.js", "new.js"}) {
+		t.Fatalf("added = %+v", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].Path != "old.js" {
+		t.Fatalf("removed = %+v", diff.Removed)
+	}
+	want := []FileChange{
+		{Path: "index.html", FromSize: 10, FromHash: "sha256:aa", ToSize: 12, ToHash: "sha256:dd"},
+		{Path: "zip.txt", FromSize: 3, FromHash: "crc32:0000000d", ToSize: 3, ToHash: "sha256:11"},
+	}
+	if !reflect.DeepEqual(diff.Changed, want) {
+		t.Fatalf("changed = %+v", diff.Changed)
+	}
+	if diff.Unchanged != 1 {
+		t.Fatalf("unchanged = %d", diff.Unchanged)
+	}
+}
+
+// TestDiffReleasesEmpty 空版本之间的差异返回空列表而不是 nil
+// A diff between empty releases returns empty lists instead of nil
+func TestDiffReleasesEmpty(t *testing.T) {
+	diff := DiffReleases(nil, nil)
+	if diff.Added == nil || diff.Removed == nil || diff.Changed == nil || diff.Unchanged != 0 {
+		t.Fatalf("diff = %+v", diff)
+	}
+}
@@ -0,0 +1,64 @@
+package serve
+
+import (
+	"path"
+	"strings"
+)
+
+// ImmutableCacheControl 文件名带内容哈希的资源使用的 Cache-Control，内容变化时文件名随之变化
+// Cache-Control of assets with a content hash in their file name, whose name changes with their content
+const ImmutableCacheControl = "public, max-age=31536000, immutable"
+
+// Fingerprinted 文件名是否带有构建工具生成的内容哈希，例如 app.3f9ab2.js、index-BxY3kQ9a.js 和 main.4c2d8e1f.chunk.css；HTML 页面的地址固定，不视为带哈希
+// Whether the file name carries a content hash generated by a build tool such as app.3f9ab2.js, index-BxY3kQ9a.js and main.4c2d8e1f.chunk.css; HTML pages have fixed addresses and never count as hashed
+func Fingerprinted(name string) bool {
+	base := path.Base(name)
+	ext := strings.ToLower(path.Ext(base))
+	if ext == ".html" || ext == ".htm" {
+		return false
+	}
+	// 第一段是资源名称，哈希位于之后以 . 分隔的段中或某段中 - 之后的部分
+	// The first segment is the asset name, the hash is a later dot separated segment or the part of a segment after a hyphen
+	segments := strings.Split(strings.TrimSuffix(base, path.Ext(base)), ".")
+	for i, segment := range segments {
+		if i > 0 && hashToken(segment) {
+			return true
+		}
+		for j := 0; j < len(segment); j++ {
+			if segment[j] == '-' && hashToken(segment[j+1:]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hashToken 是否像内容哈希：6 到 64 位同时含有字母和数字的小写十六进制，或 Rollup 和 Vite 生成的 8 位同时含有大小写字母和数字的 base64url
+// Whether the token looks like a content hash: 6 to 64 lowercase hex characters with both letters and digits, or the 8 base64url characters with upper and lower case letters and digits generated by Rollup and Vite
+func hashToken(token string) bool {
+	var lower, upper, digit, other bool
+	hex := true
+	for _, r := range token {
+		switch {
+		case r >= '0' && r <= '9':
+			digit = true
+		case r >= 'a' && r <= 'z':
+			lower = true
+			hex = hex && r <= 'f'
+		case r >= 'A' && r <= 'Z':
+			upper = true
+			hex = false
+		case r == '-' || r == '_':
+			hex = false
+		default:
+			other = true
+		}
+	}
+	if other || !digit {
+		return false
+	}
+	if hex && lower && len(token) >= 6 && len(token) <= 64 {
+		return true
+	}
+	return len(token) == 8 && lower && upper
+}
@@ -0,0 +1,28 @@
+package serve
+
+import "testing"
+
+// TestFingerprinted 常见构建工具的哈希文件名被识别，HTML 页面、版本号和普通名称不被识别
+// Hashed file names of common build tools are detected while HTML pages, version numbers and plain names are not
+func TestFingerprinted(t *testing.T) {
+	cases := map[string]bool{
+		"app.3f9ab2.js":                            true,
+		"/assets/index-BxY3kQ9a.js":                true,
+		"static/css/main.4c2d8e1f.chunk.css":       true,
+		"_next/static/chunks/_app-1a2b3c4d5e6f.js": true,
+		"fonts/inter.8d6f2a1b9c0e4d7f3a2b.woff2":   true,
+		"index.3f9ab2.html":                        false,
+		"app.js":                                   false,
+		"jquery-3.7.1.min.js":                      false,
+		"logo192.png":                              false,
+		"font-roboto-v30-latin.woff2":              false,
+		"vendor.bundle.js":                         false,
+		"photo-20240101.jpg":                       false,
+		"Report-Q1Final.pdf":                       false,
+	}
+	for name, want := range cases {
+		if got := Fingerprinted(name); got != want {
+			t.Errorf("%s: got %v, want %v", name, got, want)
+		}
+	}
+}
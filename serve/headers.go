@@ -0,0 +1,103 @@
+package serve
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// HeadersFile 站点根目录下的响应头规则文件，格式与 Netlify 的 _headers 相同
+// Response header rule file at the site root, in the same format as Netlify's _headers
+const HeadersFile = "_headers"
+
+// maxHeaderValueLength 单个响应头值的最大长度，足够容纳较长的 CSP
+// Max length of a single header value, enough for long CSPs
+const maxHeaderValueLength = 8 << 10
+
+// reservedHeaders 由服务端控制、站点不能设置的响应头
+// Response headers controlled by the server that sites cannot set
+var reservedHeaders = []string{"connection", "content-encoding", "content-length", "content-range", "keep-alive", "te", "trailer", "transfer-encoding", "upgrade"}
+
+// HeaderRule _headers 中的一条规则，路径下缩进的行为要添加的响应头
+// A rule of _headers, the indented lines below a path are the headers to add
+type HeaderRule struct {
+	Path    string   `json:"path"`    // 路径模式，语法与 _redirects 的来源相同 Path pattern, same syntax as the from of _redirects
+	Headers []Header `json:"headers"` // 响应头 Headers
+	Line    int      `json:"line"`    // 所在行号 Line number
+}
+
+// ParseHeaderLine 解析并校验 "Name: value" 格式的响应头，站点设置与 _headers 共用
+// Parse and validate a "Name: value" header, shared by site settings and _headers
+func ParseHeaderLine(line string) (name, value string, err error) {
+	name, value, ok := strings.Cut(line, ":")
+	if !ok {
+		return "", "", fmt.Errorf("expected \"Name: value\", got %q", line)
+	}
+	name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+	if name == "" || strings.IndexFunc(name, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("!#$%&'*+-.^_`|~", r))
+	}) >= 0 {
+		return "", "", fmt.Errorf("invalid header name %q", name)
+	}
+	if slices.Contains(reservedHeaders, strings.ToLower(name)) {
+		return "", "", fmt.Errorf("header %s is controlled by the server", name)
+	}
+	if len(value) > maxHeaderValueLength || strings.IndexFunc(value, func(r rune) bool { return r < 0x20 && r != '\t' || r == 0x7f }) >= 0 {
+		return "", "", fmt.Errorf("invalid value of header %s", name)
+	}
+	return name, value, nil
+}
+
+// ParseHeaders 解析 _headers 内容，返回有效规则和无效行的错误
+// Parse _headers content, returning the valid rules and errors of invalid lines
+func ParseHeaders(r io.Reader) (rules []HeaderRule, errs []error) {
+	scanner := bufio.NewScanner(io.LimitReader(r, maxRuleFileSize))
+	line := 0
+	var current *HeaderRule
+	for scanner.Scan() {
+		line++
+		raw := scanner.Text()
+		text := strings.TrimSpace(raw)
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		// 不缩进的行开始一条新规则 An unindented line starts a new rule
+		if raw[0] != ' ' && raw[0] != '\t' {
+			current = nil
+			if len(rules) >= maxRules {
+				errs = append(errs, fmt.Errorf("line %d: more than %d rules", line, maxRules))
+				break
+			}
+			if err := validPattern(text); err != nil {
+				errs = append(errs, fmt.Errorf("line %d: %w", line, err))
+				continue
+			}
+			rules = append(rules, HeaderRule{Path: text, Line: line})
+			current = &rules[len(rules)-1]
+			continue
+		}
+		if current == nil {
+			errs = append(errs, fmt.Errorf("line %d: header without a path", line))
+			continue
+		}
+		name, value, err := ParseHeaderLine(text)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %w", line, err))
+			continue
+		}
+		current.Headers = append(current.Headers, Header{Name: name, Value: value, Source: HeaderSourceFile})
+	}
+	if err := scanner.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	return rules, errs
+}
+
+// Match 判断路径是否匹配规则
+// Check whether the path matches the rule
+func (r *HeaderRule) Match(p string) bool {
+	_, ok := matchPattern(r.Path, p)
+	return ok
+}
@@ -0,0 +1,25 @@
+package serve
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestParseHeaders 解析路径与缩进的响应头，拒绝服务端控制的头和无路径的行
+// Parse paths with their indented headers, refusing server controlled headers and lines without a path
+func TestParseHeaders(t *testing.T) {
+	content := "  X-Orphan: 1\n/*\n  X-Frame-Options: DENY\n  Content-Length: 1\n/assets/*\n\tCache-Control: public, max-age=31536000, immutable\n"
+	rules, errs := ParseHeaders(strings.NewReader(content))
+	if len(errs) != 2 {
+		t.Errorf("Expected 2 errors, got %v", errs)
+	}
+	if len(rules) != 2 || len(rules[0].Headers) != 1 || rules[1].Headers[0].Value != "public, max-age=31536000, immutable" {
+		t.Fatalf("Unexpected rules %+v", rules)
+	}
+	if !rules[1].Match("/assets/app.js") || rules[1].Match("/index.html") {
+		t.Errorf("Expected /assets/* to match only assets")
+	}
+	if _, _, err := ParseHeaderLine("X-Bad: a\r\nInjected: b"); err == nil {
+		t.Errorf("Expected header values with line breaks to be refused")
+	}
+}
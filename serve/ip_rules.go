@@ -0,0 +1,67 @@
+package serve
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/LiteyukiStudio/spage/config"
+)
+
+// ParseIPRule 解析一条 IP 规则，接受单个地址或 CIDR 网段，实例配置与站点设置共用
+// Parse an IP rule, accepting a single address or a CIDR range, shared by the instance configuration and site settings
+func ParseIPRule(rule string) (netip.Prefix, error) {
+	rule = strings.TrimSpace(rule)
+	if !strings.Contains(rule, "/") {
+		addr, err := netip.ParseAddr(rule)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("invalid IP address or CIDR range %q", rule)
+		}
+		addr = addr.Unmap()
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+	prefix, err := netip.ParsePrefix(rule)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid IP address or CIDR range %q", rule)
+	}
+	// IPv4 映射网段按 IPv4 网段处理 IPv4-mapped ranges are treated as IPv4 ranges
+	if addr := prefix.Addr(); addr.Is4In6() && prefix.Bits() >= 96 {
+		return netip.PrefixFrom(addr.Unmap(), prefix.Bits()-96).Masked(), nil
+	}
+	return prefix.Masked(), nil
+}
+
+// matchIP 判断地址是否属于任一规则，无效的规则被忽略
+// Check whether the address belongs to any of the rules, invalid rules are ignored
+func matchIP(addr netip.Addr, rules []string) bool {
+	for _, rule := range rules {
+		if prefix, err := ParseIPRule(rule); err == nil && prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ipAllowed 按实例和站点的 IP 规则判断客户端地址能否访问，拒绝规则优先；设置了允许规则时只有匹配的地址可以访问
+// Check with the instance and site IP rules whether the client address may visit, deny rules win; once allow rules are set only matching addresses may visit
+func ipAllowed(clientIP string, allow, deny []string) (bool, string) {
+	if len(config.ServeAllowIPs) == 0 && len(config.ServeDenyIPs) == 0 && len(allow) == 0 && len(deny) == 0 {
+		return true, ""
+	}
+	addr, err := netip.ParseAddr(strings.TrimSpace(clientIP))
+	if err != nil {
+		return false, "client address is unknown"
+	}
+	addr = addr.Unmap()
+	switch {
+	case matchIP(addr, config.ServeDenyIPs):
+		return false, "client address is denied by the instance"
+	case matchIP(addr, deny):
+		return false, "client address is denied by the site"
+	case len(config.ServeAllowIPs) > 0 && !matchIP(addr, config.ServeAllowIPs):
+		return false, "client address is not allowed by the instance"
+	case len(allow) > 0 && !matchIP(addr, allow):
+		return false, "client address is not allowed by the site"
+	}
+	return true, ""
+}
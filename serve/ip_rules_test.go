@@ -0,0 +1,61 @@
+package serve
+
+import (
+	"testing"
+
+	"github.com/LiteyukiStudio/spage/config"
+)
+
+// TestParseIPRule 单个地址视为完整网段，网段按掩码规范化，IPv4 映射地址按 IPv4 处理
+// A single address counts as a full range, ranges are masked and IPv4-mapped addresses are treated as IPv4
+func TestParseIPRule(t *testing.T) {
+	cases := map[string]string{
+		" 10.1.2.3 ":          "10.1.2.3/32",
+		"10.1.2.3/16":         "10.1.0.0/16",
+		"::ffff:10.0.0.1":     "10.0.0.1/32",
+		"2001:db8::1/32":      "2001:db8::/32",
+		"192.168.0.0/24":      "192.168.0.0/24",
+		"fd00::/8":            "fd00::/8",
+		"::ffff:10.0.0.0/104": "10.0.0.0/8",
+	}
+	for rule, want := range cases {
+		prefix, err := ParseIPRule(rule)
+		if err != nil || prefix.String() != want {
+			t.Errorf("ParseIPRule(%q) = %v, %v, want %s", rule, prefix, err, want)
+		}
+	}
+	for _, rule := range []string{"", "10.0.0", "10.0.0.0/33", "example.com"} {
+		if _, err := ParseIPRule(rule); err == nil {
+			t.Errorf("Expected %q to be refused", rule)
+		}
+	}
+}
+
+// TestIPAllowed 拒绝规则优先于允许规则，实例与站点的允许规则都需满足
+// Deny rules win over allow rules, both the instance and the site allow rules have to match
+func TestIPAllowed(t *testing.T) {
+	defer func(allow, deny []string) { config.ServeAllowIPs, config.ServeDenyIPs = allow, deny }(config.ServeAllowIPs, config.ServeDenyIPs)
+	config.ServeAllowIPs, config.ServeDenyIPs = nil, []string{"203.0.113.7"}
+	cases := []struct {
+		ip          string
+		allow, deny []string
+		want        bool
+	}{
+		{"198.51.100.1", nil, nil, true},
+		{"203.0.113.7", nil, nil, false},
+		{"10.8.1.1", []string{"10.8.0.0/16"}, nil, true},
+		{"10.9.1.1", []string{"10.8.0.0/16"}, nil, false},
+		{"10.8.1.1", []string{"10.8.0.0/16"}, []string{"10.8.1.0/24"}, false},
+		{"::ffff:10.8.1.1", []string{"10.8.0.0/16"}, nil, true},
+		{"", nil, nil, false},
+	}
+	for _, c := range cases {
+		if got, reason := ipAllowed(c.ip, c.allow, c.deny); got != c.want {
+			t.Errorf("ipAllowed(%q, %v, %v) = %v (%s), want %v", c.ip, c.allow, c.deny, got, reason, c.want)
+		}
+	}
+	config.ServeAllowIPs = []string{"10.0.0.0/8"}
+	if ok, _ := ipAllowed("192.168.1.1", []string{"192.168.0.0/16"}, nil); ok {
+		t.Errorf("Expected the instance allow rules to apply as well")
+	}
+}
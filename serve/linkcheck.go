@@ -0,0 +1,319 @@
+package serve
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/LiteyukiStudio/spage/models"
+	"golang.org/x/net/html"
+)
+
+// 链接检查发现的问题类型 Kinds of problems found by the link check
+const (
+	LinkIssueBroken    = "broken_link"    // 站内链接指向的页面不存在 A site link points at a page that does not exist
+	LinkIssueMissing   = "missing_asset"  // 页面引用的站内资源不存在 A site asset referenced by a page does not exist
+	LinkIssueOversized = "oversized_page" // 页面及其引用的资源超出大小上限 A page with the assets it references exceeds the size limit
+)
+
+const (
+	// maxLinkIssues 报告中最多保留的问题数 Most issues kept in a report
+	maxLinkIssues = 500
+	// maxLinkPageRead 解析一个页面时最多读取的字节数 Most bytes read when parsing one page
+	maxLinkPageRead = 8 << 20
+	// maxLinkRedirects 跟随站内重定向的最大次数，超过后交给浏览器处理 Most site redirects followed, anything longer is left to the browser
+	maxLinkRedirects = 5
+)
+
+// LinkIssue 链接检查发现的一个问题
+// One problem found by the link check
+type LinkIssue struct {
+	Kind   string `json:"kind"`             // 问题类型 Kind of problem
+	Page   string `json:"page"`             // 出现问题的页面路径 Path of the page with the problem
+	Target string `json:"target,omitempty"` // 失效的链接或资源 The broken link or asset
+	Size   int64  `json:"size,omitempty"`   // 过大页面及其资源的总字节数 Total bytes of an oversized page and its assets
+}
+
+// LinkReport 一次链接检查的结果
+// Outcome of one link check
+type LinkReport struct {
+	Pages     int         `json:"pages"`     // 抓取的页面数 Pages crawled
+	Links     int         `json:"links"`     // 检查的站内链接和资源引用数 Site links and asset references checked
+	Truncated bool        `json:"truncated"` // 是否因页面数或问题数达到上限而提前结束 Whether the check stopped early at the page or issue limit
+	Issues    []LinkIssue `json:"issues"`    // 发现的问题 Problems found
+}
+
+// pageLink 页面中的一个链接或资源引用
+// One link or asset reference of a page
+type pageLink struct {
+	ref   string
+	asset bool // 页面加载时一并请求的资源，而不是跳转的页面 Fetched along with the page rather than navigated to
+}
+
+// linkPage 等待抓取的页面及访问它的路径
+// A page waiting to be crawled and the path it is reached at
+type linkPage struct {
+	path string
+	file *archiveFile
+}
+
+// linkTarget 站内路径的查找结果
+// Lookup result of a site path
+type linkTarget struct {
+	path string       // 跟随重定向后的路径 Path after following redirects
+	file *archiveFile // 提供内容的文件，重定向到站外或代理时为空 File serving the content, nil for redirects off the site and proxies
+	ok   bool
+}
+
+type linkChecker struct {
+	opened      *archive
+	hosts       []string
+	maxPages    int
+	maxPageSize int64
+
+	report   *LinkReport
+	queue    []linkPage
+	queued   map[string]bool // 已加入队列的页面文件 Page files already queued
+	targets  map[string]linkTarget
+	reported map[LinkIssue]bool
+}
+
+// CheckLinks 从站点根路径开始按服务时的文件查找和 _redirects 规则抓取版本中的页面，报告失效的站内链接、缺失的资源和过大的页面；
+// hosts 为站点的主机名，指向这些主机的绝对地址同样视为站内链接，maxPageSize 为页面及其资源的字节数上限，0 为不检查
+// Crawl the pages of a release from the site root, looking files up and applying _redirects as when serving, and report broken site links, missing assets and oversized pages;
+// hosts are the site's hostnames whose absolute URLs count as site links too, maxPageSize limits the bytes of a page with its assets and 0 disables that check
+func CheckLinks(ctx context.Context, file *models.File, hosts []string, maxPages int, maxPageSize int64) (*LinkReport, error) {
+	opened, err := archives.open(file)
+	if err != nil {
+		return nil, err
+	}
+	return checkLinks(ctx, opened, hosts, maxPages, maxPageSize)
+}
+
+func checkLinks(ctx context.Context, opened *archive, hosts []string, maxPages int, maxPageSize int64) (*LinkReport, error) {
+	c := &linkChecker{
+		opened:      opened,
+		hosts:       make([]string, 0, len(hosts)),
+		maxPages:    max(maxPages, 1),
+		maxPageSize: maxPageSize,
+		report:      &LinkReport{Issues: []LinkIssue{}},
+		queued:      make(map[string]bool),
+		targets:     make(map[string]linkTarget),
+		reported:    make(map[LinkIssue]bool),
+	}
+	for _, host := range hosts {
+		c.hosts = append(c.hosts, normalizeHost(host))
+	}
+	root := c.lookup("/")
+	if !root.ok {
+		c.issue(LinkIssue{Kind: LinkIssueBroken, Page: "/", Target: "/"})
+	} else {
+		c.enqueue(root)
+	}
+	for len(c.queue) > 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if c.report.Pages >= c.maxPages {
+			c.report.Truncated = true
+			break
+		}
+		page := c.queue[0]
+		c.queue = c.queue[1:]
+		c.report.Pages++
+		if err := c.check(page); err != nil {
+			return nil, err
+		}
+	}
+	return c.report, nil
+}
+
+// check 检查一个页面中的链接和资源，并将链接到的页面加入队列
+// Check the links and assets of one page and queue the pages it links to
+func (c *linkChecker) check(page linkPage) error {
+	body, err := page.file.open()
+	if err != nil {
+		return fmt.Errorf("open %s: %w", page.file.name, err)
+	}
+	links, baseRef := parseLinks(io.LimitReader(body, maxLinkPageRead))
+	_ = body.Close()
+	base := &url.URL{Path: page.path}
+	if baseRef != "" {
+		if ref, err := url.Parse(baseRef); err == nil {
+			base = base.ResolveReference(ref)
+		}
+	}
+	size := int64(page.file.size)
+	assets := make(map[string]bool)
+	for _, link := range links {
+		p, internal := c.resolve(base, link.ref)
+		if !internal {
+			continue
+		}
+		c.report.Links++
+		target := c.lookup(p)
+		switch {
+		case !target.ok && link.asset:
+			c.issue(LinkIssue{Kind: LinkIssueMissing, Page: page.path, Target: p})
+		case !target.ok:
+			c.issue(LinkIssue{Kind: LinkIssueBroken, Page: page.path, Target: p})
+		case target.file == nil:
+		case link.asset:
+			if !assets[target.file.name] {
+				assets[target.file.name] = true
+				size += int64(target.file.size)
+			}
+		default:
+			c.enqueue(target)
+		}
+	}
+	if c.maxPageSize > 0 && size > c.maxPageSize {
+		c.issue(LinkIssue{Kind: LinkIssueOversized, Page: page.path, Size: size})
+	}
+	return nil
+}
+
+// enqueue 将 HTML 页面加入抓取队列，同一文件只抓取一次
+// Queue an HTML page for crawling, each file is crawled once
+func (c *linkChecker) enqueue(target linkTarget) {
+	if target.file == nil || c.queued[target.file.name] || !strings.HasPrefix(ContentType(target.file.name), "text/html") {
+		return
+	}
+	c.queued[target.file.name] = true
+	c.queue = append(c.queue, linkPage{path: target.path, file: target.file})
+}
+
+// issue 记录问题，相同的问题只记录一次，达到上限后不再记录
+// Record a problem, the same problem only once and nothing beyond the limit
+func (c *linkChecker) issue(issue LinkIssue) {
+	if c.reported[issue] {
+		return
+	}
+	if len(c.report.Issues) >= maxLinkIssues {
+		c.report.Truncated = true
+		return
+	}
+	c.reported[issue] = true
+	c.report.Issues = append(c.report.Issues, issue)
+}
+
+// resolve 将页面中的引用解析为站内路径，站外地址、片段和 mailto: 等其他协议不是站内链接
+// Resolve a reference of a page to a site path, URLs of other hosts, fragments and other schemes such as mailto: are not site links
+func (c *linkChecker) resolve(base *url.URL, ref string) (string, bool) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || strings.HasPrefix(ref, "#") {
+		return "", false
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return "", false
+	}
+	u = base.ResolveReference(u)
+	if u.Scheme != "" && u.Scheme != "http" && u.Scheme != "https" {
+		return "", false
+	}
+	if u.Host != "" && !slices.Contains(c.hosts, normalizeHost(u.Host)) {
+		return "", false
+	}
+	return cleanPath(u.Path), true
+}
+
+// lookup 按服务时的文件查找和 _redirects 规则查找站内路径，跟随站内重定向，重定向到站外和代理的路径视为可访问
+// Look up a site path with the file lookup and _redirects rules used when serving, following site redirects; redirects off the site and proxied paths count as reachable
+func (c *linkChecker) lookup(p string) linkTarget {
+	if target, ok := c.targets[p]; ok {
+		return target
+	}
+	target := linkTarget{path: p, ok: true}
+	for range maxLinkRedirects {
+		file := pickEntry(c.opened, target.path)
+		rule, to := matchRedirect(c.opened.redirects, target.path, file != nil)
+		if rule == nil {
+			target.file = file
+			// 上传的文件中没有时按站点设置生成 Generated from the site settings when the upload has none
+			if name := strings.TrimPrefix(target.path, "/"); file == nil {
+				target.ok = name == RobotsFile || name == SitemapFile
+			}
+			break
+		}
+		to, _, _ = strings.Cut(to, "?")
+		switch {
+		case rule.Proxy():
+		case rule.Status == 404:
+			target.ok = false
+		case rule.Status == 200:
+			target.file = pickEntry(c.opened, cleanPath(to))
+			target.ok = target.file != nil
+		case strings.HasPrefix(to, "/") && !strings.HasPrefix(to, "//"):
+			target.path = cleanPath(to)
+			continue
+		}
+		break
+	}
+	c.targets[p] = target
+	return target
+}
+
+// parseLinks 解析 HTML 中的链接和资源引用，同时返回 <base> 的 href
+// Parse the links and asset references of HTML, along with the href of <base>
+func parseLinks(body io.Reader) (links []pageLink, base string) {
+	z := html.NewTokenizer(body)
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return links, base
+		case html.StartTagToken, html.SelfClosingTagToken:
+		default:
+			continue
+		}
+		name, hasAttr := z.TagName()
+		if !hasAttr {
+			continue
+		}
+		attrs := make(map[string]string)
+		for more := true; more; {
+			var key, value []byte
+			key, value, more = z.TagAttr()
+			attrs[string(key)] = string(value)
+		}
+		add := func(attr string, asset bool) {
+			if ref, ok := attrs[attr]; ok {
+				links = append(links, pageLink{ref: ref, asset: asset})
+			}
+		}
+		switch string(name) {
+		case "a", "area":
+			add("href", false)
+		case "iframe", "frame":
+			add("src", false)
+		case "base":
+			if base == "" {
+				base = attrs["href"]
+			}
+		case "link":
+			for _, rel := range strings.Fields(strings.ToLower(attrs["rel"])) {
+				if slices.Contains([]string{"stylesheet", "icon", "apple-touch-icon", "manifest", "preload", "modulepreload"}, rel) {
+					add("href", true)
+					break
+				}
+			}
+		case "img", "source":
+			add("src", true)
+			for _, candidate := range strings.Split(attrs["srcset"], ",") {
+				if fields := strings.Fields(candidate); len(fields) > 0 {
+					links = append(links, pageLink{ref: fields[0], asset: true})
+				}
+			}
+		case "script", "audio", "embed", "track":
+			add("src", true)
+		case "video":
+			add("src", true)
+			add("poster", true)
+		case "object":
+			add("data", true)
+		}
+	}
+}
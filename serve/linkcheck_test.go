@@ -0,0 +1,76 @@
+package serve
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestCheckLinks 从根路径抓取页面，报告失效链接、缺失资源和过大的页面，按 _redirects 解析路径并忽略站外链接
+// Pages are crawled from the root, broken links, missing assets and oversized pages are reported, paths follow _redirects and off-site links are ignored
+func TestCheckLinks(t *testing.T) {
+	files := map[string]string{
+		"index.html": `<html><head><base href="/"><link rel="stylesheet" href="style.css"><link rel="canonical" href="/gone"></head><body>
+<a href="docs/">Docs</a> <a href="about">About</a> <a href="/old">Old</a> <a href="/missing">Missing</a>
+<a href="https://example.com/about">Self</a> <a href="https://other.example/x">Other</a> <a href="mailto:a@example.com">Mail</a> <a href="#top">Top</a>
+<img src="/logo.png" srcset="/logo.png 1x, /logo@2x.png 2x"></body></html>`,
+		"about.html":      `<a href="/">Home</a><script src="/app.js"></script>`,
+		"docs/index.html": `<a href="intro.html">Intro</a><a href="../about.html">About</a><img src="big.png">`,
+		"docs/intro.html": `<a href="/docs/">Back</a>`,
+		"docs/big.png":    strings.Repeat("x", 4096),
+		"style.css":       "body{}",
+		"logo.png":        "png",
+	}
+	opened := &archive{files: map[string]*archiveFile{}}
+	for name, content := range files {
+		opened.files[name] = memoryFile(name, []byte(content))
+	}
+	opened.redirects, _ = ParseRedirects(strings.NewReader("/old /about 301\n"))
+
+	report, err := checkLinks(context.Background(), opened, []string{"Example.com"}, 10, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Pages != 4 || report.Truncated {
+		t.Errorf("pages %d truncated %v, want 4 pages", report.Pages, report.Truncated)
+	}
+	want := []LinkIssue{
+		{Kind: LinkIssueBroken, Page: "/", Target: "/missing"},
+		{Kind: LinkIssueMissing, Page: "/", Target: "/logo@2x.png"},
+		{Kind: LinkIssueOversized, Page: "/docs/", Size: int64(len(files["docs/index.html"]) + 4096)},
+		{Kind: LinkIssueMissing, Page: "/about", Target: "/app.js"},
+	}
+	if !slices.Equal(report.Issues, want) {
+		t.Errorf("issues %+v, want %+v", report.Issues, want)
+	}
+
+	report, err = checkLinks(context.Background(), opened, nil, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Pages != 1 || !report.Truncated {
+		t.Errorf("pages %d truncated %v, want 1 page truncated", report.Pages, report.Truncated)
+	}
+}
+
+// TestCheckLinksMissingRoot 站点根路径没有页面时报告为失效链接，单页应用的回退规则视为存在
+// A site root without a page is reported as a broken link, the fallback rule of a single page app counts as present
+func TestCheckLinksMissingRoot(t *testing.T) {
+	opened := &archive{files: map[string]*archiveFile{"app.html": memoryFile("app.html", []byte(`<a href="/any/route">x</a>`))}}
+	report, err := checkLinks(context.Background(), opened, nil, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []LinkIssue{{Kind: LinkIssueBroken, Page: "/", Target: "/"}}; !slices.Equal(report.Issues, want) {
+		t.Errorf("issues %+v, want %+v", report.Issues, want)
+	}
+	opened.redirects, _ = ParseRedirects(strings.NewReader("/* /app.html 200\n"))
+	report, err = checkLinks(context.Background(), opened, nil, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Pages != 1 || len(report.Issues) != 0 {
+		t.Errorf("pages %d issues %+v, want 1 page without issues", report.Pages, report.Issues)
+	}
+}
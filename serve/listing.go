@@ -0,0 +1,84 @@
+package serve
+
+import (
+	"html/template"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// listingEntry 目录索引中的一项
+// An item of a directory index
+type listingEntry struct {
+	Name string
+	Href string // 相对地址，以 ./ 开头避免名称被当作协议 Relative URL, starting with ./ so the name is never taken for a scheme
+	Dir  bool
+	Size uint64
+}
+
+// directoryListing 为没有 index.html 的目录生成索引页，dir 为以 / 结尾的目录路径；目录下没有可提供的文件时返回 nil
+// Generate the index page of a directory without index.html, dir is the directory path ending with /; nil when the directory holds no servable file
+func directoryListing(entry *siteEntry, opened *archive, dir string) *archiveFile {
+	prefix := strings.TrimPrefix(dir, "/")
+	items := map[string]*listingEntry{}
+	for name, file := range opened.files {
+		rest, ok := strings.CutPrefix(name, prefix)
+		if !ok || rest == "" || name == RedirectsFile || name == HeadersFile || entry.blocks("/"+name) {
+			continue
+		}
+		child, _, nested := strings.Cut(rest, "/")
+		if _, ok := items[child]; ok {
+			continue
+		}
+		href := "./" + url.PathEscape(child)
+		if nested {
+			href += "/"
+		}
+		items[child] = &listingEntry{Name: child, Href: href, Dir: nested, Size: file.size}
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	list := make([]listingEntry, 0, len(items))
+	for _, item := range items {
+		list = append(list, *item)
+	}
+	// 目录在前，按名称排序 Directories first, sorted by name
+	slices.SortFunc(list, func(a, b listingEntry) int {
+		if a.Dir != b.Dir {
+			if a.Dir {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	var b strings.Builder
+	_ = listingPage.Execute(&b, map[string]any{
+		"Dir":     dir,
+		"Parent":  dir != "/",
+		"Entries": list,
+	})
+	return memoryFile(prefix+"index.html", []byte(b.String()))
+}
+
+var listingPage = template.Must(template.New("listing").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Index of {{.Dir}}</title>
+<style>
+body{font-family:system-ui,sans-serif;margin:2rem}
+table{border-collapse:collapse}td{padding:.2rem 1.5rem .2rem 0}td.size{text-align:right;color:#666}
+</style>
+</head>
+<body>
+<h1>Index of {{.Dir}}</h1>
+<table>
+{{if .Parent}}<tr><td><a href="../">../</a></td><td></td></tr>
+{{end}}{{range .Entries}}<tr>{{if .Dir}}<td><a href="{{.Href}}">{{.Name}}/</a></td><td class="size">-</td>{{else}}<td><a href="{{.Href}}">{{.Name}}</a></td><td class="size">{{.Size}}</td>{{end}}</tr>
+{{end}}</table>
+</body>
+</html>
+`))
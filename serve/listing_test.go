@@ -0,0 +1,45 @@
+package serve
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/LiteyukiStudio/spage/models"
+)
+
+// TestDirectoryListing 目录索引列出直接子项，目录在前，跳过规则文件和下架的路径
+// Directory indexes list the direct children with directories first, skipping rule files and taken down paths
+func TestDirectoryListing(t *testing.T) {
+	opened := &archive{files: map[string]*archiveFile{
+		"_redirects":             {name: "_redirects"},
+		"readme.txt":             {name: "readme.txt", size: 12},
+		"secret.zip":             {name: "secret.zip"},
+		"releases/v1/app.tar.gz": {name: "releases/v1/app.tar.gz"},
+		"releases/notes.txt":     {name: "releases/notes.txt"},
+		"a:b.txt":                {name: "a:b.txt"},
+	}}
+	entry := &siteEntry{Site: &models.Site{}, Blocked: []string{"/secret.zip"}}
+	if directoryListing(entry, opened, "/missing/") != nil {
+		t.Fatal("expected no listing for a missing directory")
+	}
+	root := readEntry(t, directoryListing(entry, opened, "/"))
+	for _, want := range []string{`href="./releases/"`, `href="./readme.txt"`, `href="./a:b.txt"`, ">12<"} {
+		if !strings.Contains(root, want) {
+			t.Errorf("root listing lacks %s:\n%s", want, root)
+		}
+	}
+	for _, unwanted := range []string{"_redirects", "secret.zip", `href="../"`} {
+		if strings.Contains(root, unwanted) {
+			t.Errorf("root listing contains %s", unwanted)
+		}
+	}
+	if strings.Index(root, "releases/") > strings.Index(root, "readme.txt") {
+		t.Error("expected directories before files")
+	}
+	sub := readEntry(t, directoryListing(entry, opened, "/releases/"))
+	for _, want := range []string{`href="../"`, `href="./v1/"`, `href="./notes.txt"`, "Index of /releases/"} {
+		if !strings.Contains(sub, want) {
+			t.Errorf("releases listing lacks %s:\n%s", want, sub)
+		}
+	}
+}
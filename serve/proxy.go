@@ -0,0 +1,88 @@
+package serve
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+)
+
+// ErrProxyTarget 代理目标解析到了内网地址
+// The proxy target resolved to an internal address
+var ErrProxyTarget = errors.New("proxy target address is not allowed")
+
+// proxyForwardHeaders 转发给代理目标的请求头，认证信息不会转发
+// Request headers forwarded to proxy targets, credentials are never forwarded
+var proxyForwardHeaders = []string{"Accept", "Accept-Language", "Content-Type", "If-Modified-Since", "If-None-Match", "Range", "User-Agent"}
+
+// hopHeaders 不应回传的逐跳响应头，Content-Length 由服务端重新计算
+// Hop-by-hop response headers that must not be passed back, Content-Length is recomputed by the server
+var hopHeaders = []string{"Connection", "Content-Length", "Keep-Alive", "Proxy-Authenticate", "Proxy-Connection", "Te", "Trailer", "Transfer-Encoding", "Upgrade"}
+
+var proxyClient = &http.Client{
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: 10 * time.Second,
+			Control: func(network, address string, _ syscall.RawConn) error {
+				host, _, err := net.SplitHostPort(address)
+				if err != nil {
+					return err
+				}
+				ip := net.ParseIP(host)
+				if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+					return ErrProxyTarget
+				}
+				return nil
+			},
+		}).DialContext,
+		MaxIdleConnsPerHost: 8,
+		IdleConnTimeout:     time.Minute,
+	},
+	// 目标的重定向原样返回给访问者 Redirects of the target are passed back to the visitor as is
+	CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// Proxy 将请求转发到代理目标，调用方负责关闭响应体
+// Forward the request to the proxy target, the caller closes the response body
+func Proxy(ctx context.Context, target, method string, header func(string) string, body io.Reader) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(config.ServeProxyTimeout)*time.Second)
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	for _, name := range proxyForwardHeaders {
+		if value := header(name); value != "" {
+			req.Header.Set(name, value)
+		}
+	}
+	resp, err := proxyClient.Do(req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	for _, name := range hopHeaders {
+		resp.Header.Del(name)
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelBody 关闭响应体时释放请求的 context
+// Release the request's context when the body is closed
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
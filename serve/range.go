@@ -0,0 +1,114 @@
+package serve
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ErrRangeNotSatisfiable Range 请求的范围超出文件大小
+// The range of a Range request lies beyond the file size
+var ErrRangeNotSatisfiable = errors.New("range not satisfiable")
+
+// errRangeIgnored 格式无效或包含多个范围的 Range 请求，按规范忽略并返回完整内容
+// Invalid Range requests or ones with several ranges, ignored as the spec allows by serving the whole content
+var errRangeIgnored = errors.New("range ignored")
+
+// ParseRange 解析只包含一个范围的 bytes Range 请求头，返回起始位置和长度；范围超出 size 时返回 ErrRangeNotSatisfiable，其余无法使用的请求头返回其他错误
+// Parse a bytes Range header with a single range into its start and length; ErrRangeNotSatisfiable when the range lies beyond size, other errors for headers that cannot be used
+func ParseRange(header string, size int64) (start, length int64, err error) {
+	spec, ok := strings.CutPrefix(strings.TrimSpace(header), "bytes=")
+	if !ok || strings.Contains(spec, ",") {
+		return 0, 0, errRangeIgnored
+	}
+	first, last, ok := strings.Cut(strings.TrimSpace(spec), "-")
+	if !ok {
+		return 0, 0, errRangeIgnored
+	}
+	if first == "" {
+		// 最后 n 个字节 The last n bytes
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || n < 0 {
+			return 0, 0, errRangeIgnored
+		}
+		if n == 0 || size == 0 {
+			return 0, 0, ErrRangeNotSatisfiable
+		}
+		n = min(n, size)
+		return size - n, n, nil
+	}
+	start, err = strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return 0, 0, errRangeIgnored
+	}
+	end := size - 1
+	if last != "" {
+		end, err = strconv.ParseInt(last, 10, 64)
+		if err != nil || end < start {
+			return 0, 0, errRangeIgnored
+		}
+		end = min(end, size-1)
+	}
+	if start >= size {
+		return 0, 0, ErrRangeNotSatisfiable
+	}
+	return start, end - start + 1, nil
+}
+
+// RangeApplies 按 If-Range 判断 Range 请求是否仍然适用，ETag 使用强比较，日期需与 Last-Modified 相同；只有 200 的文件支持 Range
+// Whether a Range request still applies according to If-Range, ETags are compared strongly and dates must equal Last-Modified; only files answered with 200 support ranges
+func (d *Decision) RangeApplies(ifRange string) bool {
+	if !d.Access.Allowed || d.file == nil || d.Status != 200 {
+		return false
+	}
+	ifRange = strings.TrimSpace(ifRange)
+	if ifRange == "" {
+		return true
+	}
+	if strings.HasPrefix(ifRange, `"`) {
+		return ifRange == d.header("ETag")
+	}
+	since, err := http.ParseTime(ifRange)
+	if err != nil {
+		return false
+	}
+	modified, err := http.ParseTime(d.header("Last-Modified"))
+	return err == nil && modified.Equal(since)
+}
+
+// Size 选中文件解压后的大小
+// Uncompressed size of the chosen file
+func (d *Decision) Size() int64 {
+	if d.file == nil {
+		return 0
+	}
+	return int64(d.file.size)
+}
+
+// OpenRange 打开选中文件从 start 开始的 length 个字节，始终使用原文件；压缩包内压缩过的条目需要从头读到 start
+// Open length bytes of the chosen file from start, always from the original file; compressed archive entries are read from the beginning up to start
+func (d *Decision) OpenRange(start, length int64) (io.ReadCloser, error) {
+	body, err := d.Open()
+	if err != nil {
+		return nil, err
+	}
+	if seeker, ok := body.(io.Seeker); ok {
+		if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+			_ = body.Close()
+			return nil, err
+		}
+	} else if _, err := io.CopyN(io.Discard, body, start); err != nil {
+		_ = body.Close()
+		return nil, err
+	}
+	return rangeBody{Reader: io.LimitReader(body, length), Closer: body}, nil
+}
+
+// rangeBody 只读取范围内的字节，关闭时关闭原文件
+// Read only the bytes of the range, closing the original file when closed
+type rangeBody struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,91 @@
+package serve
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// TestParseRange 单个范围按起止、开放结尾和末尾字节解析，超出大小返回 416，多个范围和无效格式被忽略
+// Single ranges parse as start-end, open ended and suffix forms, ranges beyond the size give 416 while multiple ranges and invalid forms are ignored
+func TestParseRange(t *testing.T) {
+	cases := []struct {
+		header        string
+		start, length int64
+		err           error
+	}{
+		{"bytes=0-99", 0, 100, nil},
+		{"bytes=900-", 900, 100, nil},
+		{"bytes=-10", 990, 10, nil},
+		{"bytes=-5000", 0, 1000, nil},
+		{"bytes=990-5000", 990, 10, nil},
+		{"bytes=1000-", 0, 0, ErrRangeNotSatisfiable},
+		{"bytes=-0", 0, 0, ErrRangeNotSatisfiable},
+		{"bytes=0-1,5-6", 0, 0, errRangeIgnored},
+		{"bytes=5-1", 0, 0, errRangeIgnored},
+		{"items=0-1", 0, 0, errRangeIgnored},
+		{"bytes=abc", 0, 0, errRangeIgnored},
+	}
+	for _, tc := range cases {
+		start, length, err := ParseRange(tc.header, 1000)
+		if !errors.Is(err, tc.err) || start != tc.start || length != tc.length {
+			t.Errorf("%s: got %d+%d (%v), want %d+%d (%v)", tc.header, start, length, err, tc.start, tc.length, tc.err)
+		}
+	}
+}
+
+// TestRangeApplies If-Range 的 ETag 使用强比较，日期需与 Last-Modified 相同
+// If-Range compares ETags strongly and dates must equal Last-Modified
+func TestRangeApplies(t *testing.T) {
+	d := &Decision{
+		Access: Access{Allowed: true},
+		Status: 200,
+		file:   &archiveFile{name: "app.bin"},
+		Headers: []Header{
+			{Name: "ETag", Value: `"abc"`},
+			{Name: "Last-Modified", Value: "Wed, 01 May 2024 08:00:00 GMT"},
+		},
+	}
+	cases := map[string]bool{
+		"":                              true,
+		`"abc"`:                         true,
+		`"other"`:                       false,
+		`W/"abc"`:                       false,
+		"Wed, 01 May 2024 08:00:00 GMT": true,
+		"Thu, 02 May 2024 08:00:00 GMT": false,
+	}
+	for ifRange, want := range cases {
+		if got := d.RangeApplies(ifRange); got != want {
+			t.Errorf("If-Range %q: got %v, want %v", ifRange, got, want)
+		}
+	}
+	d.Status = 404
+	if d.RangeApplies("") {
+		t.Error("expected no ranges for a 404")
+	}
+}
+
+// TestOpenRange 范围只读取所需的字节
+// A range reads only the bytes asked for
+func TestOpenRange(t *testing.T) {
+	d := &Decision{file: &archiveFile{
+		name: "app.bin",
+		size: 10,
+		open: func() (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader("0123456789")), nil
+		},
+	}}
+	body, err := d.OpenRange(3, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer body.Close()
+	content, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "3456" {
+		t.Errorf("got %q, want 3456", content)
+	}
+}
@@ -0,0 +1,199 @@
+package serve
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/LiteyukiStudio/spage/config"
+)
+
+const (
+	// RedirectsFile 站点根目录下的重定向规则文件，格式与 Netlify 的 _redirects 相同
+	// Redirect rule file at the site root, in the same format as Netlify's _redirects
+	RedirectsFile = "_redirects"
+
+	maxRules        = 1000     // 每个规则文件的最大规则数 Max number of rules per rule file
+	maxRuleFileSize = 64 << 10 // 规则文件读取的最大字节数 Max bytes read from a rule file
+)
+
+// RedirectRule _redirects 中的一条规则，例如 "/blog/* /posts/:splat 301" 或 "/* /index.html 200"
+// A rule of _redirects, e.g. "/blog/* /posts/:splat 301" or "/* /index.html 200"
+type RedirectRule struct {
+	From   string `json:"from"`   // 匹配路径，:name 匹配一段，结尾的 * 匹配剩余部分 Path to match, :name matches a segment and a trailing * matches the rest
+	To     string `json:"to"`     // 目标路径或地址，可引用 :name 和 :splat Target path or URL, may reference :name and :splat
+	Status int    `json:"status"` // 3xx 重定向，200 重写或代理，404 自定义未找到页面 3xx redirects, 200 rewrites or proxies, 404 custom not found pages
+	Force  bool   `json:"force"`  // 带 ! 时即使路径存在文件也应用 With ! it applies even when a file exists at the path
+	Line   int    `json:"line"`   // 所在行号 Line number
+}
+
+// Proxy 目标为外部地址的 200 规则作为代理
+// 200 rules targeting an external URL act as proxies
+func (r *RedirectRule) Proxy() bool {
+	return r.Status == 200 && (strings.HasPrefix(r.To, "http://") || strings.HasPrefix(r.To, "https://"))
+}
+
+// ParseRedirects 解析 _redirects 内容，返回有效规则和无效行的错误
+// Parse _redirects content, returning the valid rules and errors of invalid lines
+func ParseRedirects(r io.Reader) (rules []RedirectRule, errs []error) {
+	scanner := bufio.NewScanner(io.LimitReader(r, maxRuleFileSize))
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		if len(rules) >= maxRules {
+			errs = append(errs, fmt.Errorf("line %d: more than %d rules", line, maxRules))
+			break
+		}
+		rule, err := parseRedirectLine(text)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %w", line, err))
+			continue
+		}
+		rule.Line = line
+		rules = append(rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	return rules, errs
+}
+
+func parseRedirectLine(text string) (RedirectRule, error) {
+	fields := strings.Fields(text)
+	if len(fields) < 2 || len(fields) > 3 {
+		return RedirectRule{}, fmt.Errorf("expected \"from to [status]\", got %q", text)
+	}
+	rule := RedirectRule{From: fields[0], To: fields[1], Status: 301}
+	if err := validPattern(rule.From); err != nil {
+		return RedirectRule{}, err
+	}
+	if !strings.HasPrefix(rule.To, "/") {
+		target, err := url.Parse(rule.To)
+		if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+			return RedirectRule{}, fmt.Errorf("to must be a path or an http(s) URL: %q", rule.To)
+		}
+	}
+	if len(fields) == 3 {
+		status := fields[2]
+		if strings.HasSuffix(status, "!") {
+			rule.Force = true
+			status = strings.TrimSuffix(status, "!")
+		}
+		code, err := strconv.Atoi(status)
+		if err != nil {
+			return RedirectRule{}, fmt.Errorf("invalid status %q", fields[2])
+		}
+		rule.Status = code
+	}
+	switch rule.Status {
+	case 301, 302, 303, 307, 308:
+	case 200, 404:
+		if rule.Status == 404 && !strings.HasPrefix(rule.To, "/") {
+			return RedirectRule{}, fmt.Errorf("404 rules must target a path: %q", rule.To)
+		}
+	default:
+		return RedirectRule{}, fmt.Errorf("unsupported status %d", rule.Status)
+	}
+	return rule, nil
+}
+
+// Match 判断路径是否匹配规则，返回替换占位符后的目标
+// Check whether the path matches the rule, returning the target with placeholders replaced
+func (r *RedirectRule) Match(p string) (string, bool) {
+	values, ok := matchPattern(r.From, p)
+	if !ok {
+		return "", false
+	}
+	target := r.To
+	// 先替换较长的名称，避免 :id 覆盖 :ids Replace longer names first so :id never clobbers :ids
+	for len(values) > 0 {
+		longest := ""
+		for name := range values {
+			if len(name) > len(longest) {
+				longest = name
+			}
+		}
+		target = strings.ReplaceAll(target, ":"+longest, values[longest])
+		delete(values, longest)
+	}
+	return target, true
+}
+
+// matchRedirect 按顺序查找第一条生效的规则，路径存在文件时只应用强制规则
+// Find the first applicable rule in order, only forced rules apply when a file exists at the path
+func matchRedirect(rules []RedirectRule, p string, exists bool) (*RedirectRule, string) {
+	for i := range rules {
+		rule := &rules[i]
+		if exists && !rule.Force {
+			continue
+		}
+		if rule.Proxy() && !config.ServeProxy {
+			continue
+		}
+		if target, ok := rule.Match(p); ok {
+			return rule, target
+		}
+	}
+	return nil, ""
+}
+
+// withQuery 目标没有查询参数时保留请求的查询参数
+// Keep the request's query string when the target has none
+func withQuery(target, query string) string {
+	if query == "" || strings.Contains(target, "?") {
+		return target
+	}
+	return target + "?" + query
+}
+
+// validPattern 路径模式必须以 / 开头，* 只能作为结尾的 /*
+// Path patterns start with / and * may only appear as a trailing /*
+func validPattern(pattern string) error {
+	if !strings.HasPrefix(pattern, "/") {
+		return fmt.Errorf("path must start with /: %q", pattern)
+	}
+	if strings.Contains(strings.TrimSuffix(pattern, "*"), "*") || (strings.HasSuffix(pattern, "*") && !strings.HasSuffix(pattern, "/*")) {
+		return fmt.Errorf("* is only allowed as a trailing /*: %q", pattern)
+	}
+	return nil
+}
+
+// matchPattern 按段匹配路径，:name 匹配一段，结尾的 /* 匹配剩余部分并记为 splat
+// Match the path segment by segment, :name matches one segment and a trailing /* matches the rest as splat
+func matchPattern(pattern, p string) (map[string]string, bool) {
+	values := map[string]string{}
+	patternSegments := strings.Split(strings.TrimSuffix(pattern, "*"), "/")
+	pathSegments := strings.Split(p, "/")
+	if strings.HasSuffix(pattern, "*") {
+		// 最后一段为通配部分，/blog/* 同时匹配 /blog The last segment is the splat, /blog/* also matches /blog
+		fixed := len(patternSegments) - 1
+		if len(pathSegments) < fixed {
+			return nil, false
+		}
+		values["splat"] = strings.Join(pathSegments[fixed:], "/")
+		patternSegments, pathSegments = patternSegments[:fixed], pathSegments[:fixed]
+	}
+	if len(patternSegments) != len(pathSegments) {
+		return nil, false
+	}
+	for i, segment := range patternSegments {
+		if name, ok := strings.CutPrefix(segment, ":"); ok && name != "" {
+			if pathSegments[i] == "" {
+				return nil, false
+			}
+			values[name] = pathSegments[i]
+			continue
+		}
+		if segment != pathSegments[i] {
+			return nil, false
+		}
+	}
+	return values, true
+}
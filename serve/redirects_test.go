@@ -0,0 +1,64 @@
+package serve
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestParseRedirects 解析有效规则并报告无效行
+// Parse valid rules and report invalid lines
+func TestParseRedirects(t *testing.T) {
+	content := `# comment
+/old /new
+/blog/* /posts/:splat 302
+/app/* /index.html 200
+/forced /other 301!
+/api/* https://api.example.com/:splat 200
+/missing/* /404.html 404
+relative /x
+/a* /b
+/x /y 418
+`
+	rules, errs := ParseRedirects(strings.NewReader(content))
+	if len(rules) != 6 {
+		t.Fatalf("Expected 6 rules, got %d", len(rules))
+	}
+	if len(errs) != 3 {
+		t.Errorf("Expected 3 errors, got %v", errs)
+	}
+	if rules[0].Status != 301 || rules[0].Line != 2 {
+		t.Errorf("Expected default status 301 on line 2, got %+v", rules[0])
+	}
+	if !rules[3].Force || rules[3].Status != 301 {
+		t.Errorf("Expected a forced 301, got %+v", rules[3])
+	}
+	if !rules[4].Proxy() || rules[2].Proxy() {
+		t.Errorf("Expected only the absolute 200 rule to proxy")
+	}
+}
+
+// TestRedirectMatch 占位符、通配和精确匹配
+// Placeholders, splats and exact matches
+func TestRedirectMatch(t *testing.T) {
+	cases := []struct {
+		from, to, path, target string
+		ok                     bool
+	}{
+		{"/old", "/new", "/old", "/new", true},
+		{"/old", "/new", "/old/", "", false},
+		{"/blog/*", "/posts/:splat", "/blog/2024/hello", "/posts/2024/hello", true},
+		{"/blog/*", "/posts/:splat", "/blog", "/posts/", true},
+		{"/blog/*", "/posts/:splat", "/blogs", "", false},
+		{"/*", "/index.html", "/deep/link", "/index.html", true},
+		{"/u/:id/*", "/users/:id?tab=:splat", "/u/42/repos", "/users/42?tab=repos", true},
+		{"/u/:id", "/users/:id", "/u/", "", false},
+		{"/:ids/:id", "/:id/:ids", "/a/b", "/b/a", true},
+	}
+	for _, tc := range cases {
+		rule := RedirectRule{From: tc.from, To: tc.to, Status: 301}
+		target, ok := rule.Match(tc.path)
+		if ok != tc.ok || target != tc.target {
+			t.Errorf("%s -> %s on %s: expected (%q, %v), got (%q, %v)", tc.from, tc.to, tc.path, tc.target, tc.ok, target, ok)
+		}
+	}
+}
@@ -0,0 +1,698 @@
+package serve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/utils"
+)
+
+// ErrNoSite 主机名不属于任何托管站点
+// The host does not belong to any hosted site
+var ErrNoSite = errors.New("host does not belong to any site")
+
+// 响应头来源 Sources of response headers
+const (
+	HeaderSourceInstance = "instance" // 实例配置 serve.headers Instance configuration serve.headers
+	HeaderSourcePolicy   = "policy"   // 自动策略 Automatic policy
+	HeaderSourceRedirect = "redirect" // _redirects 规则 _redirects rules
+	HeaderSourceSite     = "site"     // 站点设置 Site settings
+	HeaderSourceFile     = "file"     // _headers 规则 _headers rules
+)
+
+// Request 需要判定的请求
+// A request to decide on
+type Request struct {
+	Host  string
+	Path  string
+	Query string // 不含 ? 的查询参数 Query string without the leading ?
+
+	ClientIP       string // 客户端地址 Client address
+	AccessCookie   string // AccessCookie 的值 Value of AccessCookie
+	InstanceCookie string // InstanceCookie 的值 Value of InstanceCookie
+	Authorization  string // Authorization 请求头 Authorization header
+	Signature      string // 签名链接的 SignedURLParam 查询参数 SignedURLParam query parameter of a signed URL
+	Trusted        bool   // 调用方已确认访问者有权访问，跳过站点访问保护，ClientIP 为空时同时跳过 IP 规则 The caller already checked the visitor may see the site, skipping access protection and also the IP rules when ClientIP is empty
+}
+
+// Entry 选中的发布文件条目
+// The chosen release file entry
+type Entry struct {
+	Name string `json:"name"` // 发布中的文件名 File name inside the release
+	Size uint64 `json:"size"` // 解压后大小 Uncompressed size
+	Hash string `json:"hash"` // 条目校验值，压缩包条目为 crc32，部署清单中的文件为 sha256 Entry checksum, crc32 for archive entries and sha256 for files of manifests
+}
+
+// Header 生效的响应头及其来源
+// An effective response header and its source
+type Header struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+// Access 可见性与访问判定
+// Visibility and access decision
+type Access struct {
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason"`
+}
+
+// Decision 服务判定结果，生产服务与调试接口共用同一结果
+// The serving decision, shared by production serving and the debug endpoint
+type Decision struct {
+	Host          string        `json:"host"`
+	Path          string        `json:"path"`
+	SiteID        uint          `json:"site_id"`
+	CanonicalHost string        `json:"canonical_host"`
+	Access        Access        `json:"access"`
+	Challenge     string        `json:"challenge,omitempty"` // 被访问保护拒绝时需要的登录方式 Sign-in method required when access protection refused the request
+	Preview       string        `json:"preview,omitempty"`   // 预览部署名称 Preview deployment name
+	ReleaseID     uint          `json:"release_id,omitempty"`
+	Entry         *Entry        `json:"entry,omitempty"`
+	Fallback      bool          `json:"fallback"`           // 是否使用了 404.html 兜底 Whether 404.html was used as fallback
+	Rule          *RedirectRule `json:"rule,omitempty"`     // 生效的 _redirects 规则 Applied _redirects rule
+	Redirect      string        `json:"redirect,omitempty"` // 重定向目标 Redirect target
+	Proxy         string        `json:"proxy,omitempty"`    // 代理目标 Proxy target
+	Status        int           `json:"status"`
+	Throttled     bool          `json:"throttled,omitempty"` // 站点超出每月流量硬限制，响应被限速 The site exceeded its hard monthly transfer limit and responses are throttled
+	Headers       []Header      `json:"headers"`
+
+	file        *archiveFile
+	siteName    string
+	protected   bool      // 站点启用了访问保护或实例为私有实例 The site has access protection enabled or the instance is private
+	immutable   bool      // 项目允许长期缓存带内容哈希的资源 The project allows long caching of assets with a content hash
+	modified    time.Time // 版本的发布时间 Publish time of the version
+	siteHeaders []string
+	cacheRules  []string
+	headerRules []HeaderRule
+	download    string // 查询参数 download 要求的附件文件名 Attachment file name asked for by the download query parameter
+}
+
+// Open 打开选中的文件条目
+// Open the chosen file entry
+func (d *Decision) Open() (io.ReadCloser, error) {
+	if d.file == nil {
+		return nil, errors.New("decision has no entry")
+	}
+	return d.file.open()
+}
+
+// OpenEncoded 按 Accept-Encoding 打开选中文件的压缩版本，没有可用的压缩版本时打开原文件，此时 encoding 为空
+// Open the compressed version of the chosen file according to Accept-Encoding, opening the original with an empty encoding when none is available
+func (d *Decision) OpenEncoded(acceptEncoding string) (body io.ReadCloser, size int64, encoding string, err error) {
+	if d.file == nil {
+		return nil, 0, "", errors.New("decision has no entry")
+	}
+	if d.file.variantKey != nil && compressible(d.file.name, d.file.size) {
+		for _, enc := range negotiate(acceptEncoding) {
+			if object := d.file.openVariant(enc); object != nil {
+				return object, object.Info().Size, enc.name, nil
+			}
+		}
+	}
+	body, err = d.file.open()
+	return body, int64(d.file.size), "", err
+}
+
+// EncodedETag 压缩版本的 ETag，不同编码的字节不同因此 ETag 也不同
+// ETag of a compressed version, the bytes differ between encodings and so do the ETags
+func EncodedETag(etag, encoding string) string {
+	if encoding == "" || !strings.HasSuffix(etag, `"`) {
+		return etag
+	}
+	return strings.TrimSuffix(etag, `"`) + "-" + encoding + `"`
+}
+
+type resolverType struct{}
+
+// Resolver 托管站点的服务判定流程
+// Serving decision pipeline of hosted sites
+var Resolver = resolverType{}
+
+// Resolve 依次判定站点、可见性、下架、发布、文件条目和响应头，不输出任何内容
+// Decide site, visibility, takedown, release, file entry and headers in order, without serving any bytes
+func (resolverType) Resolve(ctx context.Context, req Request) (*Decision, error) {
+	host := normalizeHost(req.Host)
+	entry, preview, err := lookupSite(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	site := entry.Site
+	decision := &Decision{
+		Host:          host,
+		Path:          cleanPath(req.Path),
+		SiteID:        site.ID,
+		CanonicalHost: CanonicalHost(site),
+		siteHeaders:   site.Headers,
+		cacheRules:    site.CacheRules,
+		siteName:      site.Name,
+		protected:     Protected(site) || Private(),
+		immutable:     site.Project.ImmutableAssets,
+	}
+
+	// 可见性 Visibility
+	if site.Project.Visibility != constants.VisibilityPublic {
+		decision.deny(404, "project is not public")
+		return decision, nil
+	}
+	// IP 规则 IP rules
+	if !req.Trusted || req.ClientIP != "" {
+		if allowed, reason := ipAllowed(req.ClientIP, site.AllowIPs, site.DenyIPs); !allowed {
+			decision.deny(403, reason)
+			return decision, nil
+		}
+	}
+	// 私有实例的访问保护 Access protection of private instances
+	accessReason := "project is public"
+	if Private() {
+		accessReason = "trusted caller"
+		if !req.Trusted {
+			allowed, reason := Protection.allowsInstance(site, req.InstanceCookie, req.AccessCookie, req.Authorization)
+			if !allowed {
+				decision.Challenge = ChallengeInstance
+				decision.deny(401, reason)
+				return decision, nil
+			}
+			accessReason = reason
+		}
+	}
+	// 站点的访问保护 Access protection of the site
+	if Protected(site) {
+		accessReason = "trusted caller"
+		if !req.Trusted {
+			allowed, reason := true, "signed URL"
+			if !Protection.allowsSigned(site, decision.Path, req.Signature) {
+				allowed, reason = Protection.allows(site, req.AccessCookie, req.Authorization)
+			}
+			if !allowed {
+				decision.Challenge = string(site.AccessMode)
+				decision.deny(401, reason)
+				return decision, nil
+			}
+			accessReason = reason
+		}
+	}
+	// 每月流量硬限制 Hard monthly transfer limit
+	switch Bandwidth.Action(site.ID) {
+	case config.TransferActionSuspend:
+		decision.deny(503, "site exceeded its monthly transfer limit")
+		return decision, nil
+	case config.TransferActionThrottle:
+		decision.Throttled = true
+	}
+	// 下架路径，选出文件后再按文件名检查一次 Takedown paths, checked again by file name once the file is picked
+	if entry.blocks(decision.Path) {
+		decision.deny(410, "path has been taken down")
+		return decision, nil
+	}
+	// 当前发布，预览主机名使用预览的版本 Active release, preview hosts use the previewed version
+	release := entry.Release
+	if preview != nil {
+		decision.Preview = preview.Name
+		release = &preview.Release
+	} else if release == nil {
+		decision.deny(404, "site has no active release")
+		return decision, nil
+	}
+	decision.ReleaseID = release.ID
+	decision.modified = release.CreatedAt
+	if !release.Status.Servable() {
+		decision.deny(503, fmt.Sprintf("release is %s", release.Status))
+		return decision, nil
+	}
+	decision.Access = Access{Allowed: true, Reason: accessReason}
+
+	// 文件条目 File entry
+	opened, err := archives.open(&release.File)
+	if err != nil {
+		return nil, err
+	}
+	decision.Status = 200
+	decision.headerRules = opened.headers
+	file := pickEntry(opened, decision.Path)
+	// 重定向、重写与代理规则 Redirect, rewrite and proxy rules
+	if rule, target := matchRedirect(opened.redirects, decision.Path, file != nil); rule != nil {
+		decision.Rule = rule
+		switch {
+		case rule.Proxy():
+			decision.Proxy = withQuery(target, req.Query)
+			decision.Headers = effectiveHeaders(decision)
+			return decision, ctx.Err()
+		case rule.Status == 200 || rule.Status == 404:
+			targetPath, _, _ := strings.Cut(target, "?")
+			decision.Status = rule.Status
+			file = pickEntry(opened, cleanPath(targetPath))
+		default:
+			decision.Status = rule.Status
+			decision.Redirect = withQuery(target, req.Query)
+			decision.Headers = effectiveHeaders(decision)
+			return decision, ctx.Err()
+		}
+	}
+	// 通过 .html 后缀、目录的 index.html 或 200 重写访问到的下架文件 Taken down files reached through the .html suffix, a directory's index.html or a 200 rewrite
+	if file != nil && entry.blocks("/"+file.name) {
+		decision.deny(410, "path has been taken down")
+		return decision, nil
+	}
+	// 内容策略收紧前部署的禁止文件 Disallowed files deployed before the content policy was tightened
+	if file != nil {
+		if policy := utils.EffectiveContentPolicy(entry.Policy); policy.Serve {
+			if rule, blocked := policy.Blocks(file.name); blocked {
+				decision.deny(403, fmt.Sprintf("file type %s is not allowed", rule))
+				return decision, nil
+			}
+		}
+	}
+	// 上传的文件中没有的 robots.txt 和 sitemap.xml robots.txt and sitemap.xml missing from the upload
+	if file == nil && decision.Rule == nil {
+		canonical := decision.CanonicalHost
+		if canonical == "" {
+			canonical = host
+		}
+		file = generatedEntry(entry, opened, decision.Path, canonical, decision.modified)
+	}
+	// 项目开启目录索引时没有 index.html 的目录，不以 / 结尾的目录路径先重定向 Directories without index.html when the project enables listings, directory paths without a trailing / are redirected first
+	if file == nil && decision.Rule == nil && site.Project.DirectoryListing {
+		if strings.HasSuffix(decision.Path, "/") {
+			file = directoryListing(entry, opened, decision.Path)
+		} else if directoryListing(entry, opened, decision.Path+"/") != nil {
+			decision.Status = 301
+			decision.Redirect = withQuery(decision.Path+"/", req.Query)
+			decision.Headers = effectiveHeaders(decision)
+			return decision, ctx.Err()
+		}
+	}
+	if file == nil {
+		decision.Status = 404
+		decision.Fallback = true
+		file = opened.files["404.html"]
+		// 下架的 404 页面不再作为回退 A taken down 404 page no longer serves as the fallback
+		if file != nil && entry.blocks("/"+file.name) {
+			file = nil
+		}
+	}
+	if file != nil {
+		decision.file = file
+		decision.Entry = &Entry{
+			Name: file.name,
+			Size: file.size,
+			Hash: file.hash,
+		}
+		decision.download = downloadName(req.Query, file.name)
+	}
+	decision.Headers = effectiveHeaders(decision)
+	return decision, ctx.Err()
+}
+
+// NotModified 按 If-None-Match 和 If-Modified-Since 判断客户端缓存的版本是否仍然有效，返回客户端持有的 ETag；
+// 同时出现时只使用 If-None-Match
+// Check with If-None-Match and If-Modified-Since whether the client's cached copy is still valid, returning the ETag the client holds;
+// only If-None-Match is used when both are present
+func (d *Decision) NotModified(ifNoneMatch, ifModifiedSince string) (string, bool) {
+	if !d.Access.Allowed || d.Entry == nil || d.Status != 200 {
+		return "", false
+	}
+	etag := d.header("ETag")
+	if ifNoneMatch != "" {
+		if etag == "" {
+			return "", false
+		}
+		// 弱比较，压缩版本的 ETag 同样匹配 Weak comparison, ETags of compressed versions match as well
+		base := strings.TrimPrefix(etag, "W/")
+		for _, candidate := range strings.Split(ifNoneMatch, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" {
+				return etag, true
+			}
+			tag := strings.TrimPrefix(candidate, "W/")
+			if tag == base {
+				return candidate, true
+			}
+			for _, enc := range encodings {
+				if tag == EncodedETag(base, enc.name) {
+					return candidate, true
+				}
+			}
+		}
+		return "", false
+	}
+	if ifModifiedSince == "" {
+		return "", false
+	}
+	since, err := http.ParseTime(ifModifiedSince)
+	if err != nil {
+		return "", false
+	}
+	modified, err := http.ParseTime(d.header("Last-Modified"))
+	if err != nil || modified.After(since) {
+		return "", false
+	}
+	return etag, true
+}
+
+// header 生效的响应头的值
+// Value of an effective response header
+func (d *Decision) header(name string) string {
+	for _, header := range d.Headers {
+		if strings.EqualFold(header.Name, name) {
+			return header.Value
+		}
+	}
+	return ""
+}
+
+// setCacheControl 按第一条匹配路径的规则设置 Cache-Control，无效的规则被忽略
+// Set Cache-Control from the first rule matching the path, invalid rules are ignored
+func setCacheControl(lines []string, p, source string, set func(name, value, source string)) {
+	for _, line := range lines {
+		rule, err := ParseCacheRule(line)
+		if err == nil && rule.Match(p) {
+			set("Cache-Control", rule.Value, source)
+			return
+		}
+	}
+}
+
+// SiteName 站点名称，用于登录页和认证域
+// Name of the site, used by the login page and the auth realm
+func (d *Decision) SiteName() string {
+	return d.siteName
+}
+
+func (d *Decision) deny(status int, reason string) {
+	d.Status = status
+	d.Access = Access{Allowed: false, Reason: reason}
+	d.Headers = effectiveHeaders(d)
+}
+
+// HostMatches 判断主机名是否属于站点（自定义域名或站点基础域名下的子域）
+// Check whether the host belongs to the site (custom domain or subdomain of the site's base domain)
+func HostMatches(site *models.Site, host string) bool {
+	host = normalizeHost(host)
+	for _, domain := range site.Domains {
+		if strings.EqualFold(domain, host) {
+			return true
+		}
+	}
+	if label, ok := domainLabel(host, BaseDomain(site)); ok && site.SubDomain != "" {
+		subDomain := strings.ToLower(site.SubDomain)
+		if label == subDomain || strings.HasSuffix(label, utils.PreviewSeparator+subDomain) {
+			return true
+		}
+	}
+	// 项目主机名只属于项目的主站点，未设置主站点时属于最早创建的站点，由查找时保证 Project hosts belong to the project's primary site only, or to its oldest site which the lookup guarantees
+	label, ok := projectLabel(host)
+	if !ok || site.Project.ID != site.ProjectID || (site.Project.PrimarySiteID != nil && *site.Project.PrimarySiteID != site.ID) {
+		return false
+	}
+	name := strings.ToLower(site.Project.Name)
+	return label == name || strings.HasSuffix(label, utils.PreviewSeparator+name)
+}
+
+// ProjectHost 项目的主机名，未配置 serve.project-domain 时为空
+// Host of the project, empty when serve.project-domain is not configured
+func ProjectHost(project *models.Project) string {
+	if config.ServeProjectDomain == "" {
+		return ""
+	}
+	return strings.ToLower(project.Name) + "." + config.ServeProjectDomain
+}
+
+// Dedicated 主机名是否为只用于托管站点的项目域名或其子域，这些主机名不会进入面板和接口
+// Whether the host is the project domain used only for hosted sites or one of its subdomains, such hosts never reach the panel and API
+func Dedicated(host string) bool {
+	host = normalizeHost(host)
+	return config.ServeProjectDomain != "" && (host == config.ServeProjectDomain || strings.HasSuffix(host, "."+config.ServeProjectDomain))
+}
+
+// CanonicalHost 站点的规范主机名，优先使用第一个自定义域名
+// Canonical host of the site, preferring the first custom domain
+func CanonicalHost(site *models.Site) string {
+	if len(site.Domains) > 0 {
+		return strings.ToLower(site.Domains[0])
+	}
+	if base := BaseDomain(site); site.SubDomain != "" && base != "" {
+		return strings.ToLower(site.SubDomain) + "." + base
+	}
+	return ""
+}
+
+// SiteHosts 站点当前的所有主机名：自定义域名、基础域名下的子域，以及作为项目主站点时的项目主机名
+// Every current host of the site: custom domains, the subdomain of the base domain and the project host when it may be the project's primary site
+func SiteHosts(site *models.Site) []string {
+	hosts := append([]string{}, site.Domains...)
+	if base := BaseDomain(site); site.SubDomain != "" && base != "" {
+		hosts = append(hosts, site.SubDomain+"."+base)
+	}
+	if host := ProjectHost(&site.Project); host != "" && site.Project.ID == site.ProjectID {
+		hosts = append(hosts, host)
+	}
+	return hosts
+}
+
+// findSite 从数据库按主机名查找站点，依次匹配自定义域名、基础域名下的子域和项目域名下的项目名，
+// <name>--<sub_domain> 和 <name>--<project> 形式的主机名对应站点的预览部署
+// Find the site by host in the database, matching custom domains, subdomains of the base domain and project names under the project domain in turn,
+// hosts of the form <name>--<sub_domain> and <name>--<project> map to the site's preview deployments
+func findSite(host string) (*models.Site, *models.SitePreview, error) {
+	if site, err := store.Site.GetByDomain(host); err == nil {
+		return site, nil, nil
+	}
+	// 租户的站点只通过租户的基础域名访问 Sites of a tenant are only served under the tenant's base domain
+	if label, base, ok := subDomainLabel(host); ok {
+		if site, preview, err := findByLabel(label, store.Site.GetBySubDomain); err == nil && BaseDomain(site) == base {
+			return site, preview, nil
+		}
+	}
+	if label, ok := projectLabel(host); ok {
+		return findByLabel(label, store.Site.GetProjectPrimary)
+	}
+	return nil, nil, ErrNoSite
+}
+
+// findByLabel 按单级子域查找站点，完整匹配失败时按 <name>--<label> 查找预览部署
+// Find the site by a single-level label, looking up a preview deployment as <name>--<label> when the whole label does not match
+func findByLabel(label string, get func(string) (*models.Site, error)) (*models.Site, *models.SitePreview, error) {
+	if site, err := get(label); err == nil {
+		return site, nil, nil
+	}
+	if name, rest, ok := strings.Cut(label, utils.PreviewSeparator); ok {
+		if site, err := get(rest); err == nil {
+			if preview, err := store.Preview.GetActive(site.ID, name); err == nil {
+				return site, preview, nil
+			}
+		}
+	}
+	return nil, nil, ErrNoSite
+}
+
+// subDomainLabel 取出 serve.base-domain 或租户的基础域名下的单级子域及该基础域名，未配置基础域名时不匹配
+// Extract the single-level subdomain under serve.base-domain or a tenant base domain together with that base domain, never matching when no base domain is configured
+func subDomainLabel(host string) (label, base string, ok bool) {
+	if label, ok := domainLabel(host, config.ServeBaseDomain); ok {
+		return label, config.ServeBaseDomain, true
+	}
+	_, parent, found := strings.Cut(host, ".")
+	if !found {
+		return "", "", false
+	}
+	for _, domain := range tenantDomains() {
+		if domain == parent {
+			label, ok = domainLabel(host, domain)
+			return label, domain, ok
+		}
+	}
+	return "", "", false
+}
+
+// projectLabel 取出项目域名下的单级子域，即小写的项目名，未配置项目域名时不匹配
+// Extract the single-level subdomain under the project domain, which is the lowercase project name, never matching when no project domain is configured
+func projectLabel(host string) (string, bool) {
+	return domainLabel(host, config.ServeProjectDomain)
+}
+
+// domainLabel 取出 base 下的单级子域，base 为空时不匹配
+// Extract the single-level subdomain under base, never matching when base is empty
+func domainLabel(host, base string) (string, bool) {
+	base = strings.ToLower(base)
+	if base == "" || !strings.HasSuffix(host, "."+base) {
+		return "", false
+	}
+	label := strings.TrimSuffix(host, "."+base)
+	if label == "" || strings.Contains(label, ".") {
+		return "", false
+	}
+	return label, true
+}
+
+func normalizeHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
+
+func cleanPath(p string) string {
+	cleaned := path.Clean("/" + p)
+	if strings.HasSuffix(p, "/") && cleaned != "/" {
+		cleaned += "/"
+	}
+	return cleaned
+}
+
+// downloadName 查询参数 download 要求的附件文件名，参数为空时使用文件名，没有该参数时为空
+// Attachment file name asked for by the download query parameter, the file's own name when the parameter is empty and empty without it
+func downloadName(query, name string) string {
+	values, err := url.ParseQuery(query)
+	if err != nil || !values.Has("download") {
+		return ""
+	}
+	if download := path.Base(strings.TrimSpace(values.Get("download"))); download != "" && download != "." && download != "/" {
+		return download
+	}
+	return path.Base(name)
+}
+
+// pickEntry 按 路径、路径.html、路径/index.html 的顺序查找文件
+// Look up the file as path, path.html, then path/index.html
+func pickEntry(opened *archive, p string) *archiveFile {
+	name := strings.TrimPrefix(p, "/")
+	var candidates []string
+	if name == "" || strings.HasSuffix(name, "/") {
+		candidates = []string{name + "index.html"}
+	} else {
+		candidates = []string{name, name + ".html", name + "/index.html"}
+	}
+	for _, candidate := range candidates {
+		// 规则文件不对外提供 Rule files are never served
+		if candidate == RedirectsFile || candidate == HeadersFile {
+			continue
+		}
+		if f, ok := opened.files[candidate]; ok {
+			return f
+		}
+	}
+	return nil
+}
+
+// effectiveHeaders 计算响应头，依次为自动策略、实例配置、站点设置和 _headers，后者覆盖前者的同名头
+// Compute response headers from the automatic policy, instance configuration, site settings and _headers in order, later sources override headers of the same name
+func effectiveHeaders(d *Decision) []Header {
+	var headers []Header
+	set := func(name, value, source string) {
+		for i := range headers {
+			if strings.EqualFold(headers[i].Name, name) {
+				headers[i] = Header{Name: name, Value: value, Source: source}
+				return
+			}
+		}
+		headers = append(headers, Header{Name: name, Value: value, Source: source})
+	}
+
+	set("X-Content-Type-Options", "nosniff", HeaderSourcePolicy)
+	if d.Redirect != "" {
+		set("Location", d.Redirect, HeaderSourceRedirect)
+	}
+	if d.Entry != nil {
+		set("Content-Type", ContentType(d.Entry.Name), HeaderSourcePolicy)
+		if d.file != nil {
+			set("ETag", d.file.etag, HeaderSourcePolicy)
+		}
+		if d.file != nil && d.Status == 200 {
+			set("Accept-Ranges", "bytes", HeaderSourcePolicy)
+		}
+		if !d.modified.IsZero() {
+			set("Last-Modified", d.modified.UTC().Format(http.TimeFormat), HeaderSourcePolicy)
+		}
+		if d.file != nil && d.file.variantKey != nil && compressible(d.file.name, d.file.size) {
+			set("Vary", "Accept-Encoding", HeaderSourcePolicy)
+		}
+		if strings.HasSuffix(d.Entry.Name, ".html") || d.Status != 200 {
+			set("Cache-Control", "no-cache", HeaderSourcePolicy)
+		} else if d.immutable && Fingerprinted(d.Entry.Name) {
+			set("Cache-Control", ImmutableCacheControl, HeaderSourcePolicy)
+		} else {
+			set("Cache-Control", "public, max-age=3600", HeaderSourcePolicy)
+		}
+	}
+	// 预览部署不应被搜索引擎收录 Preview deployments should not be indexed by search engines
+	if d.Preview != "" {
+		set("X-Robots-Tag", "noindex", HeaderSourcePolicy)
+	}
+	if d.CanonicalHost != "" && d.CanonicalHost != d.Host {
+		set("Link", "<https://"+d.CanonicalHost+d.Path+">; rel=\"canonical\"", HeaderSourcePolicy)
+	}
+	for _, line := range config.ServeHeaders {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		set(strings.TrimSpace(name), strings.TrimSpace(value), HeaderSourceInstance)
+	}
+	cached := d.Entry != nil && d.Status == 200
+	if cached {
+		setCacheControl(config.ServeCacheRules, d.Path, HeaderSourceInstance, set)
+	}
+	for _, line := range d.siteHeaders {
+		if name, value, err := ParseHeaderLine(line); err == nil {
+			set(name, value, HeaderSourceSite)
+		}
+	}
+	if cached {
+		setCacheControl(d.cacheRules, d.Path, HeaderSourceSite, set)
+	}
+	for _, rule := range d.headerRules {
+		if !rule.Match(d.Path) {
+			continue
+		}
+		for _, header := range rule.Headers {
+			set(header.Name, header.Value, HeaderSourceFile)
+		}
+	}
+	// 查询参数 download 要求作为附件下载 The download query parameter asks for an attachment
+	if d.Entry != nil && d.download != "" {
+		if disposition := mime.FormatMediaType("attachment", map[string]string{"filename": d.download}); disposition != "" {
+			set("Content-Disposition", disposition, HeaderSourcePolicy)
+		}
+	}
+	// 受保护站点的内容不能由共享缓存保存 Shared caches must not store the content of protected sites
+	if d.protected {
+		value := ""
+		for _, header := range headers {
+			if strings.EqualFold(header.Name, "Cache-Control") {
+				value = header.Value
+			}
+		}
+		set("Cache-Control", privateCacheControl(value), HeaderSourcePolicy)
+	}
+	return headers
+}
+
+// privateCacheControl 去掉允许共享缓存的指令并加上 private
+// Drop the directives allowing shared caches and add private
+func privateCacheControl(value string) string {
+	directives := []string{"private"}
+	for _, directive := range strings.Split(value, ",") {
+		directive = strings.TrimSpace(directive)
+		name, _, _ := strings.Cut(strings.ToLower(directive), "=")
+		if directive == "" || name == "public" || name == "private" || name == "s-maxage" {
+			continue
+		}
+		directives = append(directives, directive)
+	}
+	return strings.Join(directives, ", ")
+}
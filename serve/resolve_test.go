@@ -0,0 +1,143 @@
+package serve
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/cache"
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/utils"
+)
+
+// TestHostMatchesProject 项目主机名只属于项目的主站点，预览主机名同样匹配
+// Project hosts belong to the project's primary site only, preview hosts match as well
+func TestHostMatchesProject(t *testing.T) {
+	defer func(base, project string) { config.ServeBaseDomain, config.ServeProjectDomain = base, project }(config.ServeBaseDomain, config.ServeProjectDomain)
+	config.ServeBaseDomain, config.ServeProjectDomain = "", "pages.example.com"
+
+	site := &models.Site{ProjectID: 3, Project: models.Project{Name: "Docs"}}
+	site.ID, site.Project.ID = 7, 3
+	for _, host := range []string{"docs.pages.example.com", "DOCS.pages.example.com.", "feature-x--docs.pages.example.com:443"} {
+		if !HostMatches(site, host) {
+			t.Errorf("Expected %s to match the project site", host)
+		}
+	}
+	for _, host := range []string{"blog.pages.example.com", "a.docs.pages.example.com", "docs.example.com"} {
+		if HostMatches(site, host) {
+			t.Errorf("Expected %s not to match the project site", host)
+		}
+	}
+	other := uint(8)
+	site.Project.PrimarySiteID = &other
+	if HostMatches(site, "docs.pages.example.com") {
+		t.Errorf("Expected the project host to belong to the primary site only")
+	}
+	if host := ProjectHost(&site.Project); host != "docs.pages.example.com" {
+		t.Errorf("Unexpected project host %q", host)
+	}
+}
+
+// TestDedicated 项目域名及其子域只用于托管站点
+// The project domain and its subdomains only host sites
+func TestDedicated(t *testing.T) {
+	defer func(project string) { config.ServeProjectDomain = project }(config.ServeProjectDomain)
+	config.ServeProjectDomain = "pages.example.com"
+	for host, want := range map[string]bool{
+		"pages.example.com":         true,
+		"docs.pages.example.com:80": true,
+		"a.b.pages.example.com":     true,
+		"panel.example.com":         false,
+		"evilpages.example.com":     false,
+	} {
+		if got := Dedicated(host); got != want {
+			t.Errorf("Dedicated(%q) = %v, want %v", host, got, want)
+		}
+	}
+	config.ServeProjectDomain = ""
+	if Dedicated("docs.pages.example.com") {
+		t.Errorf("Expected no dedicated hosts without a project domain")
+	}
+}
+
+// TestResolveTakedown 下架的文件无论通过 .html 后缀、目录的 index.html、200 重写还是旧版本的预览主机名访问都返回 410
+// Taken down files answer 410 whether reached through the .html suffix, a directory's index.html, a 200 rewrite or a preview host of an older version
+func TestResolveTakedown(t *testing.T) {
+	defer func(base string) { config.ServeBaseDomain = base }(config.ServeBaseDomain)
+	config.ServeBaseDomain = "pages.test"
+	ctx := context.Background()
+
+	files := func(names ...string) map[string]*archiveFile {
+		m := map[string]*archiveFile{}
+		for _, name := range names {
+			m[name] = &archiveFile{name: name}
+		}
+		return m
+	}
+	rules, _ := ParseRedirects(strings.NewReader("/leak /docs/secret.html 200\n/private-leak /private/ 200\n"))
+	archives.mu.Lock()
+	archives.archives["test/takedown-active"] = &archive{files: files("index.html", "404.html")}
+	archives.archives["test/takedown-old"] = &archive{
+		files:     files("index.html", "404.html", "docs/secret.html", "private/index.html", "docs/public.html"),
+		redirects: rules,
+	}
+	archives.mu.Unlock()
+	defer func() {
+		archives.mu.Lock()
+		delete(archives.archives, "test/takedown-active")
+		delete(archives.archives, "test/takedown-old")
+		archives.mu.Unlock()
+	}()
+
+	site := &models.Site{SubDomain: "docs", AccessMode: constants.SiteAccessPublic, Project: models.Project{Visibility: constants.VisibilityPublic}}
+	site.ID = 9101
+	entry := siteEntry{
+		Site:    site,
+		Release: &models.SiteRelease{Status: constants.DeploymentStatusReady, Site: *site, File: models.File{Path: "test/takedown-active"}},
+		Blocked: []string{"/docs/secret.html", "/private/index.html", "/404.html"},
+	}
+	old := models.SitePreview{
+		Site:      *site,
+		Name:      "old",
+		ExpiresAt: time.Now().Add(time.Hour),
+		Release:   models.SiteRelease{Status: constants.DeploymentStatusReady, Site: *site, File: models.File{Path: "test/takedown-old"}},
+	}
+	host := "old" + utils.PreviewSeparator + "docs.pages.test"
+	for key, value := range map[string]any{
+		siteKey(site.ID):           entry,
+		previewKey(site.ID, "old"): old,
+		hostKey(host):              hostEntry{SiteID: site.ID, Preview: "old"},
+	} {
+		if err := cache.SetJSON(ctx, key, value, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for path, want := range map[string]int{
+		"/docs/secret.html": 410,
+		"/docs/secret":      410,
+		"/private/":         410,
+		"/leak":             410,
+		"/private-leak":     410,
+		"/docs/public":      200,
+		"/missing":          404,
+	} {
+		decision, err := Resolver.Resolve(ctx, Request{Host: host, Path: path})
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		if decision.Status != want {
+			t.Errorf("%s: status %d, want %d", path, decision.Status, want)
+		}
+		if want == 410 && decision.Entry != nil {
+			t.Errorf("%s: taken down file %s was picked", path, decision.Entry.Name)
+		}
+	}
+	// 下架的 404 页面不作为回退 A taken down 404 page is not the fallback
+	if decision, _ := Resolver.Resolve(ctx, Request{Host: host, Path: "/missing"}); decision.Entry != nil {
+		t.Errorf("expected no fallback entry, got %s", decision.Entry.Name)
+	}
+}
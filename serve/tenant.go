@@ -0,0 +1,66 @@
+package serve
+
+import (
+	"context"
+	"strings"
+
+	"github.com/LiteyukiStudio/spage/cache"
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/utils"
+)
+
+// tenantsKey 缓存的租户基础域名的键
+// Key of the cached tenant base domains
+const tenantsKey = "serve:tenants"
+
+// tenantDomains 租户ID到基础域名的映射，未命中缓存时从数据库加载
+// Base domains by tenant ID, loaded from the database on a cache miss
+func tenantDomains() map[uint]string {
+	ctx := context.Background()
+	var domains map[uint]string
+	if err := cache.GetJSON(ctx, tenantsKey, &domains); err == nil {
+		return domains
+	}
+	domains, err := store.Tenant.BaseDomains()
+	if err != nil {
+		utils.Log.Ctx(ctx).Warnf("failed to load tenant base domains: %v", err)
+		return nil
+	}
+	remember(ctx, tenantsKey, domains, 0)
+	return domains
+}
+
+// BaseDomain 站点子域所在的基础域名，所属租户设置了基础域名时使用租户的基础域名，否则使用 serve.base-domain
+// Base domain of the site's subdomain, the base domain of its tenant when the tenant set one and serve.base-domain otherwise
+func BaseDomain(site *models.Site) string {
+	if id := site.Project.TenantID; id != nil && site.Project.ID == site.ProjectID {
+		if domain, ok := tenantDomains()[*id]; ok {
+			return domain
+		}
+	}
+	return config.ServeBaseDomain
+}
+
+// UnderBaseDomain 域名是否为 serve.base-domain 或租户的基础域名，或是它们的子域，这些域名不能绑定为自定义域名
+// Whether the name is serve.base-domain or a tenant base domain or one of their subdomains, such names cannot be bound as custom domains
+func UnderBaseDomain(name string) bool {
+	name = normalizeHost(name)
+	bases := []string{config.ServeBaseDomain}
+	for _, domain := range tenantDomains() {
+		bases = append(bases, domain)
+	}
+	for _, base := range bases {
+		if base != "" && (name == base || strings.HasSuffix(name, "."+base)) {
+			return true
+		}
+	}
+	return false
+}
+
+// ForgetTenants 失效缓存的租户基础域名，创建、修改或删除租户后调用
+// Invalidate the cached tenant base domains, called after creating, changing or deleting a tenant
+func (siteCacheType) ForgetTenants(ctx context.Context) {
+	forget(ctx, tenantsKey)
+}
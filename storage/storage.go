@@ -0,0 +1,208 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+)
+
+// 对象键前缀 Object key prefixes
+const (
+	ReleasePrefix    = "releases/"   // 发布压缩包与预览图 Release archives and preview images
+	TemplatePrefix   = "templates/"  // 组织预览图模板 Organization preview templates
+	UploadPrefix     = "uploads/"    // 分片上传的分片 Chunks of chunked uploads
+	BlobPrefix       = "blobs/"      // 按内容哈希存储的站点文件 Site files stored by content hash
+	CompressedPrefix = "compressed/" // 预压缩的站点文件，可随时重新生成 Precompressed site files, can be regenerated any time
+	AvatarPrefix     = "avatars/"    // 上传的用户头像和组织标志 Uploaded user avatars and organization logos
+
+	StagedPrefix = UploadPrefix + "staged-" // 等待后台任务发布的压缩包 Archives waiting to be published by a background job
+)
+
+// ErrNotFound 对象不存在
+// The object does not exist
+var ErrNotFound = errors.New("object not found")
+
+// ObjectInfo 对象元信息
+// Object metadata
+type ObjectInfo struct {
+	Key     string    // 对象键 Object key
+	Size    int64     // 字节数 Size in bytes
+	ModTime time.Time // 最后修改时间 Last modified time
+}
+
+// Object 已打开的对象，支持随机读取以便直接读取 zip 目录
+// An opened object, supporting random access so zip directories can be read in place
+type Object interface {
+	io.ReadCloser
+	io.ReaderAt
+	Info() ObjectInfo
+}
+
+// Storage 对象存储抽象，键为 / 分隔的相对路径，例如 releases/<site>/<tag>/<time>.zip
+// Object storage abstraction, keys are slash separated relative paths such as releases/<site>/<tag>/<time>.zip
+type Storage interface {
+	// Put 写入对象，size 为 -1 表示未知长度 Write an object, a size of -1 means unknown length
+	Put(ctx context.Context, key string, r io.Reader, size int64) error
+	// Get 打开对象，不存在时返回 ErrNotFound Open an object, returning ErrNotFound when missing
+	Get(ctx context.Context, key string) (Object, error)
+	// Delete 删除对象，不存在时不报错 Delete an object, missing objects are not an error
+	Delete(ctx context.Context, key string) error
+	// List 列出前缀下的所有对象 List all objects under the prefix
+	List(ctx context.Context, prefix string) ([]ObjectInfo, error)
+}
+
+// Default 当前实例使用的存储，由 Init 根据 storage.driver 设置
+// Storage used by this instance, set by Init according to storage.driver
+var Default Storage
+
+// Config 存储配置
+// Storage configuration
+type Config struct {
+	Driver string // 存储驱动，local 或 s3 Storage driver, local or s3
+
+	S3Endpoint  string // S3 服务地址，例如 s3.amazonaws.com 或 minio:9000 S3 endpoint, e.g. s3.amazonaws.com or minio:9000
+	S3Region    string // S3 区域 S3 region
+	S3Bucket    string // 存储桶 Bucket
+	S3AccessKey string // 访问密钥 Access key
+	S3SecretKey string // 私有密钥 Secret key
+	S3UseSSL    bool   // 是否使用 HTTPS Whether to use HTTPS
+	S3Prefix    string // 存储桶内的键前缀，便于多个实例共用一个存储桶 Key prefix inside the bucket, so several instances can share a bucket
+}
+
+// loadConfig 从配置文件加载存储配置
+// Load storage configuration from config file
+func loadConfig() Config {
+	return Config{
+		Driver: config.GetString("storage.driver", "local"),
+
+		S3Endpoint:  config.GetString("storage.s3.endpoint", ""),
+		S3Region:    config.GetString("storage.s3.region", ""),
+		S3Bucket:    config.GetString("storage.s3.bucket", "spage"),
+		S3AccessKey: config.GetString("storage.s3.access-key", ""),
+		S3SecretKey: config.GetString("storage.s3.secret-key", ""),
+		S3UseSSL:    config.GetBool("storage.s3.use-ssl", true),
+		S3Prefix:    config.GetString("storage.s3.prefix", ""),
+	}
+}
+
+// Init 根据配置初始化存储驱动
+// Initialize the storage driver from configuration
+func Init() error {
+	cfg := loadConfig()
+	switch cfg.Driver {
+	case "local":
+		Default = Local{}
+	case "s3":
+		s3, err := NewS3(context.Background(), cfg)
+		if err != nil {
+			return fmt.Errorf("init s3 storage: %w", err)
+		}
+		Default = s3
+	default:
+		return errors.New("unsupported storage driver: " + cfg.Driver)
+	}
+	Default = traced{Storage: Default, driver: cfg.Driver}
+	return nil
+}
+
+// healthKey 检查存储可用时读取的键，不需要存在，不是合法的哈希所以不会与文件内容冲突
+// Key read to check that the storage is reachable, it does not have to exist and is no valid hash so it never clashes with file content
+const healthKey = BlobPrefix + "healthz"
+
+// Ping 检查存储是否可以访问，对象不存在视为可以访问
+// Check that the storage is reachable, a missing object counts as reachable
+func Ping(ctx context.Context) error {
+	object, err := Default.Get(ctx, healthKey)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return object.Close()
+}
+
+// cleanKey 规范化对象键并拒绝越界路径
+// Normalize an object key and reject paths escaping the storage root
+func cleanKey(key string) (string, error) {
+	if key == "" || strings.Contains(key, "..") || strings.ContainsRune(key, '\\') {
+		return "", fmt.Errorf("invalid object key %q", key)
+	}
+	cleaned := strings.TrimPrefix(path.Clean("/"+key), "/")
+	if cleaned == "" {
+		return "", fmt.Errorf("invalid object key %q", key)
+	}
+	return cleaned, nil
+}
+
+// KeyFromPath 将旧版记录中的本地文件路径转换为对象键，无法转换时原样返回
+// Convert a local file path stored by older records into an object key, returning it unchanged when it cannot be converted
+func KeyFromPath(p string) string {
+	p = filepath.ToSlash(filepath.Clean(p))
+	for prefix, root := range localRoots() {
+		root = filepath.ToSlash(filepath.Clean(root))
+		if rel, ok := strings.CutPrefix(p, root+"/"); ok {
+			return prefix + rel
+		}
+	}
+	return p
+}
+
+// PathFromKey 将对象键转换为本地驱动下的文件路径
+// Convert an object key into its file path under the local driver
+func PathFromKey(key string) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	for prefix, root := range localRoots() {
+		if rel, ok := strings.CutPrefix(key, prefix); ok {
+			return filepath.Join(root, filepath.FromSlash(rel)), nil
+		}
+	}
+	return "", fmt.Errorf("object key %q has no known prefix", key)
+}
+
+// localRoots 本地驱动下各键前缀对应的目录
+// Directories backing each key prefix under the local driver
+func localRoots() map[string]string {
+	return map[string]string{
+		ReleasePrefix:    config.ReleaseSavePath,
+		TemplatePrefix:   config.TemplateSavePath,
+		UploadPrefix:     config.UploadSavePath,
+		BlobPrefix:       config.BlobSavePath,
+		CompressedPrefix: config.CompressedSavePath,
+		AvatarPrefix:     config.AvatarSavePath,
+	}
+}
+
+// BlobKey 内容哈希对应的对象键，按前两位分目录以免单个目录过大
+// Object key of a content hash, sharded by its first two characters so no single directory grows too large
+func BlobKey(hash string) string {
+	return BlobPrefix + hash[:2] + "/" + hash
+}
+
+// CompressedBlobKey 按内容哈希存储的文件的压缩版本，ext 为编码的扩展名，例如 br
+// Object key of the compressed version of a file stored by content hash, ext is the extension of the encoding such as br
+func CompressedBlobKey(hash, ext string) string {
+	return CompressedPrefix + "sha256/" + hash[:2] + "/" + hash + "." + ext
+}
+
+// CompressedFilePrefix 发布压缩包中文件的压缩版本所在的前缀，删除发布文件时一并删除
+// Prefix of the compressed versions of the files of a release archive, deleted together with the release file
+func CompressedFilePrefix(fileID uint) string {
+	return fmt.Sprintf("%sfiles/%d/", CompressedPrefix, fileID)
+}
+
+// AvatarKey 上传的头像缩放到 size 像素的对象键，hash 为原图的内容哈希，更换头像后键随之变化
+// Object key of an uploaded avatar scaled to size pixels, hash is the content hash of the original so the key changes with the avatar
+func AvatarKey(ownerType string, ownerID uint, hash string, size int) string {
+	return fmt.Sprintf("%s%s/%d/%s-%d.png", AvatarPrefix, ownerType, ownerID, hash, size)
+}
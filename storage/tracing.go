@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"context"
+	"io"
+
+	"github.com/LiteyukiStudio/spage/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// traced 为存储操作创建 span 的包装，只在 ctx 中已有 span 时创建，以免后台扫描产生大量孤立的链路
+// Wrapper creating spans for storage operations, only when ctx already carries a span so background scans do not produce a flood of orphan traces
+type traced struct {
+	Storage
+	driver string
+}
+
+// start 创建存储操作的 span
+// Start the span of a storage operation
+func (t traced) start(ctx context.Context, operation, key string) (context.Context, trace.Span) {
+	return tracing.Start(ctx, "storage."+operation, trace.SpanKindClient,
+		attribute.String("storage.driver", t.driver),
+		attribute.String("storage.key", key),
+	)
+}
+
+func (t traced) Put(ctx context.Context, key string, r io.Reader, size int64) (err error) {
+	if !tracing.Active(ctx) {
+		return t.Storage.Put(ctx, key, r, size)
+	}
+	ctx, span := t.start(ctx, "put", key)
+	defer func() { tracing.End(span, err) }()
+	span.SetAttributes(attribute.Int64("storage.size", size))
+	return t.Storage.Put(ctx, key, r, size)
+}
+
+func (t traced) Get(ctx context.Context, key string) (object Object, err error) {
+	if !tracing.Active(ctx) {
+		return t.Storage.Get(ctx, key)
+	}
+	ctx, span := t.start(ctx, "get", key)
+	defer func() { tracing.End(span, err, ErrNotFound) }()
+	object, err = t.Storage.Get(ctx, key)
+	if err == nil {
+		span.SetAttributes(attribute.Int64("storage.size", object.Info().Size))
+	}
+	return object, err
+}
+
+func (t traced) Delete(ctx context.Context, key string) (err error) {
+	if !tracing.Active(ctx) {
+		return t.Storage.Delete(ctx, key)
+	}
+	ctx, span := t.start(ctx, "delete", key)
+	defer func() { tracing.End(span, err) }()
+	return t.Storage.Delete(ctx, key)
+}
+
+func (t traced) List(ctx context.Context, prefix string) (infos []ObjectInfo, err error) {
+	if !tracing.Active(ctx) {
+		return t.Storage.List(ctx, prefix)
+	}
+	ctx, span := t.start(ctx, "list", prefix)
+	defer func() { tracing.End(span, err) }()
+	infos, err = t.Storage.List(ctx, prefix)
+	span.SetAttributes(attribute.Int("storage.objects", len(infos)))
+	return infos, err
+}
@@ -0,0 +1,102 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/utils"
+	"gorm.io/gorm"
+)
+
+// accessLogBatch 每次插入的请求日志数 Request logs inserted per statement
+const accessLogBatch = 500
+
+type accessLogType struct {
+	db      *gorm.DB
+	mu      sync.Mutex
+	pending []models.AccessLog
+}
+
+// AccessLog 站点请求日志，请求先在内存中排队，由 Flush 批量写入数据库
+// Site request logs, requests are queued in memory and written to the database in batches by Flush
+var AccessLog = accessLogType{db: DB}
+
+// AccessLogFilter 请求日志的查询条件，零值不过滤
+// Query conditions of request logs, zero values do not filter
+type AccessLogFilter struct {
+	Status      int       // 状态码 Status code
+	StatusClass int       // 状态码类别，例如 4 表示 4xx Status class, e.g. 4 means 4xx
+	Path        string    // 完整路径 Full path
+	PathPrefix  string    // 路径前缀 Path prefix
+	Since       time.Time // 不早于该时间 Not before this time
+	Until       time.Time // 早于该时间 Before this time
+}
+
+// Add 排队一条请求日志，排队的日志达到 log.site.buffer-size 时丢弃
+// Queue a request log, dropped once log.site.buffer-size entries are queued
+func (a *accessLogType) Add(entry models.AccessLog) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.pending) >= config.SiteLogBufferSize {
+		return
+	}
+	a.pending = append(a.pending, entry)
+}
+
+// Flush 批量写入排队的请求日志，写入失败的日志在队列有空位时留到下一次
+// Write the queued request logs in batches, logs that fail to be written are kept for the next time while the queue has room
+func (a *accessLogType) Flush(ctx context.Context) error {
+	a.mu.Lock()
+	rows := a.pending
+	a.pending = nil
+	a.mu.Unlock()
+	for start := 0; start < len(rows); start += accessLogBatch {
+		batch := rows[start:min(start+accessLogBatch, len(rows))]
+		if err := a.db.WithContext(ctx).Create(&batch).Error; err != nil {
+			a.mu.Lock()
+			rest := rows[start:]
+			if room := max(config.SiteLogBufferSize-len(a.pending), 0); len(rest) > room {
+				rest = rest[:room]
+			}
+			a.pending = append(rest, a.pending...)
+			a.mu.Unlock()
+			return err
+		}
+	}
+	return nil
+}
+
+// List 按条件分页查询站点的请求日志
+// Query the request logs of a site by conditions with pagination
+func (a *accessLogType) List(ctx context.Context, siteID uint, filter AccessLogFilter, list utils.ListQuery) (logs []models.AccessLog, total int64, err error) {
+	query := a.db.WithContext(ctx).Where("site_id = ?", siteID)
+	if filter.Status != 0 {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.StatusClass != 0 {
+		query = query.Where("status >= ? AND status < ?", filter.StatusClass*100, filter.StatusClass*100+100)
+	}
+	if filter.Path != "" {
+		query = query.Where("path = ?", filter.Path)
+	}
+	if filter.PathPrefix != "" {
+		query = query.Where("path LIKE ? ESCAPE '\\'", likePrefix(filter.PathPrefix))
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("created_at >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		query = query.Where("created_at < ?", filter.Until)
+	}
+	return PaginateList[models.AccessLog](query, list)
+}
+
+// DeleteBefore 删除早于指定时间的请求日志
+// Delete request logs older than the given time
+func (a *accessLogType) DeleteBefore(before time.Time) (int64, error) {
+	result := a.db.Where("created_at < ?", before).Delete(&models.AccessLog{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,191 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// analyticsKey 浏览量按站点、UTC 日期、维度和值累计
+// Page views are accumulated per site, UTC day, dimension and value
+type analyticsKey struct {
+	analyticsGroup
+	value string
+}
+
+// analyticsGroup 站点某天的一个维度，用于限制不同值的数量
+// One dimension of a site on a day, used to limit the number of distinct values
+type analyticsGroup struct {
+	siteID    uint
+	day       time.Time
+	dimension string
+}
+
+type analyticsType struct {
+	db      *gorm.DB
+	mu      sync.Mutex
+	pending map[analyticsKey]int64
+	seen    map[analyticsGroup]map[string]struct{}
+}
+
+// Analytics 站点访问分析，浏览量先在内存中累计，由 Flush 合并写入每日汇总
+// Site analytics, page views are accumulated in memory and merged into the daily rollups by Flush
+var Analytics = analyticsType{
+	db:      DB,
+	pending: make(map[analyticsKey]int64),
+	seen:    make(map[analyticsGroup]map[string]struct{}),
+}
+
+// PageView 一次页面浏览，空的维度不记录
+// A page view, empty dimensions are not recorded
+type PageView struct {
+	SiteID   uint
+	Path     string
+	Referrer string
+	Country  string
+	Status   string
+}
+
+// AnalyticsValue 一段时间内维度值的浏览量合计
+// Page views of a dimension value over a period
+type AnalyticsValue struct {
+	Value string
+	Views int64
+}
+
+// DailyViews 一天的浏览量
+// Page views of a day
+type DailyViews struct {
+	Day   time.Time
+	Views int64
+}
+
+// Record 累计一次页面浏览，每个站点每天每个维度最多记录 analytics.max-values 个不同的值，其余合并为 (other)
+// Count a page view, at most analytics.max-values distinct values are kept per site, day and dimension and the rest are merged into (other)
+func (a *analyticsType) Record(view PageView, now time.Time) {
+	day := now.UTC().Truncate(24 * time.Hour)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, dim := range [...]struct{ name, value string }{
+		{models.AnalyticsPath, view.Path},
+		{models.AnalyticsReferrer, view.Referrer},
+		{models.AnalyticsCountry, view.Country},
+		{models.AnalyticsStatus, view.Status},
+	} {
+		if dim.value == "" {
+			continue
+		}
+		group := analyticsGroup{siteID: view.SiteID, day: day, dimension: dim.name}
+		a.pending[analyticsKey{group, a.limit(group, dim.value)}]++
+	}
+}
+
+// limit 返回记录使用的值，值的数量达到上限时返回 (other)，调用方需持有锁
+// Return the value to record, (other) once the limit of values is reached, the caller holds the lock
+func (a *analyticsType) limit(group analyticsGroup, value string) string {
+	values := a.seen[group]
+	if values == nil {
+		// 新的一天开始时丢弃之前的记录 Drop the values of earlier days when a new day starts
+		for seen := range a.seen {
+			if seen.day.Before(group.day) {
+				delete(a.seen, seen)
+			}
+		}
+		values = make(map[string]struct{})
+		a.seen[group] = values
+	}
+	if _, ok := values[value]; ok {
+		return value
+	}
+	if config.AnalyticsMaxValues > 0 && len(values) >= config.AnalyticsMaxValues {
+		return models.AnalyticsOther
+	}
+	values[value] = struct{}{}
+	return value
+}
+
+// Flush 将内存中的累计值加到数据库，写入失败的值留到下一次
+// Add the accumulated counts to the database, counts that fail to be written are kept for the next time
+func (a *analyticsType) Flush(ctx context.Context) error {
+	a.mu.Lock()
+	rows := make([]models.SiteAnalytics, 0, len(a.pending))
+	for key, views := range a.pending {
+		rows = append(rows, models.SiteAnalytics{SiteID: key.siteID, Day: key.day, Dimension: key.dimension, Value: key.value, Views: views})
+	}
+	a.pending = make(map[analyticsKey]int64)
+	a.mu.Unlock()
+	for i, row := range rows {
+		err := a.db.WithContext(ctx).Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "site_id"}, {Name: "day"}, {Name: "dimension"}, {Name: "value"}},
+			DoUpdates: clause.Assignments(map[string]any{
+				"views": gorm.Expr("site_analytics.views + ?", row.Views),
+			}),
+		}).Create(&row).Error
+		if err != nil {
+			a.mu.Lock()
+			for _, rest := range rows[i:] {
+				a.pending[analyticsKey{analyticsGroup{rest.SiteID, rest.Day, rest.Dimension}, rest.Value}] += rest.Views
+			}
+			a.mu.Unlock()
+			return err
+		}
+	}
+	return nil
+}
+
+// Daily 获取站点 since 之后每天的浏览量
+// Get the page views of a site per day since the given time
+func (a *analyticsType) Daily(ctx context.Context, siteID uint, since time.Time) (days []DailyViews, err error) {
+	// 每次浏览都有状态码，状态码维度的合计即为浏览量 Every view has a status code, so the status dimension sums up to the views
+	err = a.db.WithContext(ctx).Model(&models.SiteAnalytics{}).
+		Select("day, SUM(views) AS views").
+		Where("site_id = ? AND dimension = ? AND day >= ?", siteID, models.AnalyticsStatus, since.UTC().Truncate(24*time.Hour)).
+		Group("day").Order("day").Scan(&days).Error
+	return
+}
+
+// DailyBySites 获取多个站点 since 之后每天的浏览量
+// Get the page views of several sites per day since the given time
+func (a *analyticsType) DailyBySites(ctx context.Context, siteIDs []uint, since time.Time) (map[uint][]DailyViews, error) {
+	grouped := map[uint][]DailyViews{}
+	if len(siteIDs) == 0 {
+		return grouped, nil
+	}
+	var rows []struct {
+		SiteID uint
+		DailyViews
+	}
+	err := a.db.WithContext(ctx).Model(&models.SiteAnalytics{}).
+		Select("site_id, day, SUM(views) AS views").
+		Where("site_id IN ? AND dimension = ? AND day >= ?", siteIDs, models.AnalyticsStatus, since.UTC().Truncate(24*time.Hour)).
+		Group("site_id, day").Order("day").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		grouped[row.SiteID] = append(grouped[row.SiteID], row.DailyViews)
+	}
+	return grouped, nil
+}
+
+// Top 获取站点 since 之后某个维度浏览量最多的值
+// Get the values of a dimension with the most page views of a site since the given time
+func (a *analyticsType) Top(ctx context.Context, siteID uint, dimension string, since time.Time, limit int) (values []AnalyticsValue, err error) {
+	err = a.db.WithContext(ctx).Model(&models.SiteAnalytics{}).
+		Select("value, SUM(views) AS views").
+		Where("site_id = ? AND dimension = ? AND day >= ?", siteID, dimension, since.UTC().Truncate(24*time.Hour)).
+		Group("value").Order("views DESC, value").Limit(limit).Scan(&values).Error
+	return
+}
+
+// DeleteBefore 删除早于 before 的每日汇总
+// Delete the daily rollups before the given time
+func (a *analyticsType) DeleteBefore(before time.Time) (int64, error) {
+	result := a.db.Where("day < ?", before.UTC().Truncate(24*time.Hour)).Delete(&models.SiteAnalytics{})
+	return result.RowsAffected, result.Error
+}
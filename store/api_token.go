@@ -0,0 +1,79 @@
+package store
+
+import (
+	"time"
+
+	"github.com/LiteyukiStudio/spage/models"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+type apiTokenType struct {
+	db *gorm.DB
+}
+
+// APIToken 个人访问令牌
+// Personal access tokens
+var APIToken = apiTokenType{
+	db: DB,
+}
+
+// lastUsedInterval 最后使用时间的更新间隔，避免每个请求都写库
+// Update interval of the last used time, avoiding a write on every request
+const lastUsedInterval = time.Minute
+
+// Create 创建令牌
+func (a *apiTokenType) Create(token *models.APIToken) error {
+	return a.db.Create(token).Error
+}
+
+// ListByUser 获取用户的所有令牌
+// List all tokens of the user
+func (a *apiTokenType) ListByUser(userID uint) (tokens []models.APIToken, err error) {
+	err = a.db.Where("user_id = ?", userID).Order("id").Find(&tokens).Error
+	return
+}
+
+// GetByUser 获取用户的指定令牌
+// Get the given token of the user
+func (a *apiTokenType) GetByUser(userID, id uint) (token *models.APIToken, err error) {
+	token = &models.APIToken{}
+	err = a.db.Where("id = ? AND user_id = ?", id, userID).First(token).Error
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// GetByHash 根据哈希获取令牌，撤销必须立即生效，因此从主库读取
+// Get a token by hash, revocation must take effect immediately so read from the primary
+func (a *apiTokenType) GetByHash(hash string) (token *models.APIToken, err error) {
+	token = &models.APIToken{}
+	err = a.db.Clauses(dbresolver.Write).Where("hash = ?", hash).First(token).Error
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// Update 更新令牌
+func (a *apiTokenType) Update(token *models.APIToken) error {
+	return a.db.Save(token).Error
+}
+
+// Delete 撤销令牌，直接删除使哈希无法再被查到
+// Revoke a token, deleted permanently so the hash can no longer be found
+func (a *apiTokenType) Delete(token *models.APIToken) error {
+	return a.db.Unscoped().Delete(token).Error
+}
+
+// Touch 记录令牌的使用时间
+// Record the time the token was used
+func (a *apiTokenType) Touch(token *models.APIToken) error {
+	now := time.Now()
+	if token.LastUsedAt != nil && now.Sub(*token.LastUsedAt) < lastUsedInterval {
+		return nil
+	}
+	token.LastUsedAt = &now
+	return a.db.Model(token).UpdateColumn("last_used_at", now).Error
+}
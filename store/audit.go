@@ -0,0 +1,130 @@
+package store
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/utils"
+	"gorm.io/gorm"
+)
+
+// auditAppendAttempts 追加审计日志的尝试次数，其他实例同时追加时 prev_hash 唯一约束会拒绝分叉的记录
+// Attempts to append an audit entry, the unique prev_hash rejects the forked entry when another instance appends concurrently
+const auditAppendAttempts = 3
+
+type auditType struct {
+	db *gorm.DB
+	mu sync.Mutex // 串行化本实例的追加 Serializes appends of this instance
+}
+
+// Audit 只追加的审计日志，记录按哈希链接
+// Append-only audit log with hash-chained entries
+var Audit = auditType{
+	db: DB,
+}
+
+// AuditFilter 审计日志查询条件，零值表示不过滤
+// Audit log query filter, zero values do not filter
+type AuditFilter struct {
+	ActorID    uint
+	Action     string
+	TargetType string
+	TargetID   string
+	Since      time.Time
+	Until      time.Time
+}
+
+// AuditVerification 审计日志链的校验结果
+// Verification result of the audit log chain
+type AuditVerification struct {
+	Entries  int64  `json:"entries"`             // 已校验的记录数 Number of verified entries
+	Head     string `json:"head"`                // 最新记录的哈希，可在外部留存以发现末尾被截断 Hash of the newest entry, keep it externally to detect truncation
+	Valid    bool   `json:"valid"`               // 链是否完整 Whether the chain is intact
+	BrokenID uint   `json:"broken_id,omitempty"` // 第一条校验失败的记录 First entry failing verification
+}
+
+// Append 追加审计日志，计算其哈希并链接到上一条记录
+// Append an audit entry, computing its hash and linking it to the previous entry
+func (a *auditType) Append(ctx context.Context, entry *models.AuditLog) (err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	entry.CreatedAt = entry.CreatedAt.UTC().Truncate(time.Microsecond)
+	for attempt := 0; attempt < auditAppendAttempts; attempt++ {
+		err = a.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			last := models.AuditLog{}
+			if err := tx.Select("hash").Order("id DESC").Limit(1).Find(&last).Error; err != nil {
+				return err
+			}
+			entry.ID = 0
+			entry.PrevHash = last.Hash
+			entry.Hash = entry.ComputeHash()
+			return tx.Create(entry).Error
+		})
+		if err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// List 按条件分页查询审计日志，默认从新到旧排序
+// Query audit entries by filter with pagination, newest first by default
+func (a *auditType) List(ctx context.Context, filter AuditFilter, list utils.ListQuery) (logs []models.AuditLog, total int64, err error) {
+	query := a.db.WithContext(ctx)
+	if filter.ActorID != 0 {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("created_at >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		query = query.Where("created_at < ?", filter.Until)
+	}
+	return PaginateList[models.AuditLog](query, list)
+}
+
+// ListByUser 获取用户执行的或以该用户为目标的全部审计日志，从旧到新排序
+// List every audit entry performed by the user or targeting them, oldest first
+func (a *auditType) ListByUser(userID uint) (logs []models.AuditLog, err error) {
+	err = a.db.Where("actor_id = ? OR (target_type = ? AND target_id = ?)", userID, constants.AuditTargetUser, strconv.FormatUint(uint64(userID), 10)).
+		Order("id").Find(&logs).Error
+	return
+}
+
+// Verify 从第一条记录开始重新计算哈希并检查链接
+// Recompute hashes from the first entry and check the links
+func (a *auditType) Verify() (result AuditVerification, err error) {
+	result.Valid = true
+	var batch []models.AuditLog
+	err = a.db.FindInBatches(&batch, 500, func(tx *gorm.DB, _ int) error {
+		for _, entry := range batch {
+			if entry.PrevHash != result.Head || entry.Hash != entry.ComputeHash() {
+				result.Valid, result.BrokenID = false, entry.ID
+				return gorm.ErrInvalidData
+			}
+			result.Head = entry.Hash
+			result.Entries++
+		}
+		return nil
+	}).Error
+	if !result.Valid {
+		err = nil
+	}
+	return
+}
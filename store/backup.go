@@ -0,0 +1,415 @@
+package store
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/storage"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// BackupFormat 备份格式版本 Backup format version
+const BackupFormat = 1
+
+// BackupManifest 备份归档的清单
+// Manifest of a backup archive
+type BackupManifest struct {
+	Format        int       `json:"format"`         // 备份格式版本 Backup format version
+	SchemaVersion int       `json:"schema_version"` // 数据库迁移版本 Database migration version
+	Driver        string    `json:"driver"`         // 来源数据库驱动 Source database driver
+	CreatedAt     time.Time `json:"created_at"`     // 备份时间 Backup time
+	ReleaseRoot   string    `json:"release_root"`   // 来源发布文件目录 Source release directory
+	TemplateRoot  string    `json:"template_root"`  // 来源模板目录 Source template directory
+	Tables        []string  `json:"tables"`         // 包含的表 Included tables
+}
+
+// backupTable 参与备份的表，按外键依赖顺序排列
+// A table included in backups, in foreign key dependency order
+type backupTable struct {
+	name  string
+	model any // 为空表示纯关联表，按 map 读写 Nil means a plain join table read and written as maps
+}
+
+var backupTables = []backupTable{
+	{"tenants", &models.Tenant{}},
+	{"users", &models.User{}},
+	{"organizations", &models.Organization{}},
+	{"projects", &models.Project{}},
+	{"project_labels", &models.ProjectLabel{}},
+	{"organization_members", &models.OrgMember{}},
+	{"organization_owners", nil}, // 迁移 11 之前的组织所有者 Organization owners before migration 11
+	{"project_owners", nil},      // 迁移 11 之前的项目所有者 Project owners before migration 11
+	{"teams", &models.Team{}},
+	{"team_members", &models.TeamMember{}},
+	{"project_members", &models.ProjectMember{}},
+	{"project_teams", &models.ProjectTeam{}},
+	{"files", &models.File{}},
+	{"blobs", &models.Blob{}},
+	{"file_blobs", &models.FileBlob{}},
+	{"tokens", &models.Token{}},
+	{"oidc_configs", &models.OIDCConfig{}},
+	{"user_identities", &models.UserIdentity{}},
+	{"api_tokens", &models.APIToken{}},
+	{"deploy_keys", &models.DeployKey{}},
+	{"user_totps", &models.UserTOTP{}},
+	{"recovery_codes", &models.RecoveryCode{}},
+	{"sites", &models.Site{}},
+	{"site_domains", &models.SiteDomain{}},
+	{"site_releases", &models.SiteRelease{}},
+	{"site_path_purges", &models.SitePathPurge{}},
+	{"site_blocked_paths", &models.SiteBlockedPath{}},
+	{"site_previews", &models.SitePreview{}},
+	{"custom_domains", &models.CustomDomain{}},
+	{"acme_certificates", &models.ACMECertificate{}},
+	{"nodes", &models.Node{}},
+	{"audit_logs", &models.AuditLog{}},
+	{"webhooks", &models.Webhook{}},
+	{"webhook_deliveries", &models.WebhookDelivery{}},
+	{"cdn_integrations", &models.CDNIntegration{}},
+	{"notification_channels", &models.NotificationChannel{}},
+	{"notification_deliveries", &models.NotificationDelivery{}},
+	{"git_integrations", &models.GitIntegration{}},
+	{"runners", &models.Runner{}},
+	{"runner_builds", &models.RunnerBuild{}},
+	{"quotas", &models.Quota{}},
+	{"content_policies", &models.ContentPolicy{}},
+	{"invitations", &models.Invitation{}},
+	{"project_transfers", &models.ProjectTransfer{}},
+	{"settings", &models.Setting{}},
+}
+
+// backupPrefixes 参与备份的存储前缀，分片上传的分片是临时数据、预压缩文件可重新生成，均不备份
+// Storage prefixes included in backups, chunks of chunked uploads are transient and precompressed files can be regenerated, so both are skipped
+var backupPrefixes = []string{storage.ReleasePrefix, storage.TemplatePrefix, storage.BlobPrefix, storage.AvatarPrefix}
+
+type backupType struct {
+	db *gorm.DB
+}
+
+// Backup 实例备份与恢复，数据按表逻辑导出，可在 SQLite 与 Postgres 之间迁移
+// Instance backup and restore, tables are dumped logically so archives move between SQLite and Postgres
+var Backup = backupType{
+	db: DB,
+}
+
+// Dump 将数据库和上传的站点文件写入 zip 归档
+// Write the database and uploaded site files into a zip archive
+func (b *backupType) Dump(w io.Writer) error {
+	// 大表的导出不受语句超时限制 Dumping large tables is exempt from the statement timeout
+	db := b.db.WithContext(WithoutTimeout(context.Background()))
+	version, err := models.CurrentVersion(db)
+	if err != nil {
+		return err
+	}
+	manifest := BackupManifest{
+		Format:        BackupFormat,
+		SchemaVersion: version,
+		Driver:        db.Dialector.Name(),
+		CreatedAt:     time.Now(),
+		ReleaseRoot:   filepath.ToSlash(filepath.Clean(config.ReleaseSavePath)),
+		TemplateRoot:  filepath.ToSlash(filepath.Clean(config.TemplateSavePath)),
+	}
+	archive := zip.NewWriter(w)
+
+	// 在只读事务中导出，保证各表一致 Dump inside a read-only transaction so tables are consistent
+	var opts *sql.TxOptions
+	if manifest.Driver == "postgres" {
+		opts = &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for _, table := range backupTables {
+			if !tx.Migrator().HasTable(table.name) {
+				continue
+			}
+			entry, err := archive.Create("tables/" + table.name + ".jsonl")
+			if err != nil {
+				return err
+			}
+			if err := dumpTable(tx, table, entry); err != nil {
+				return fmt.Errorf("dump table %s: %w", table.name, err)
+			}
+			manifest.Tables = append(manifest.Tables, table.name)
+		}
+		return nil
+	}, opts)
+	if err != nil {
+		return err
+	}
+
+	for _, prefix := range backupPrefixes {
+		if err := dumpObjects(archive, prefix); err != nil {
+			return err
+		}
+	}
+	entry, err := archive.Create("manifest.json")
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(entry).Encode(manifest); err != nil {
+		return err
+	}
+	return archive.Close()
+}
+
+// Restore 从 zip 归档恢复，目标数据库会先迁移到归档的版本；非空数据库需要 force，并会清空现有数据
+// Restore from a zip archive, the target database is migrated to the archive's version first;
+// a non-empty database requires force and its rows are replaced
+func (b *backupType) Restore(r io.ReaderAt, size int64, force bool) (*BackupManifest, error) {
+	db := b.db.WithContext(WithoutTimeout(context.Background()))
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+	manifest := &BackupManifest{}
+	if err := readJSON(files["manifest.json"], manifest); err != nil {
+		return nil, fmt.Errorf("read manifest: %w", err)
+	}
+	if manifest.Format != BackupFormat {
+		return nil, fmt.Errorf("unsupported backup format %d", manifest.Format)
+	}
+	if err := models.MigrateTo(db, manifest.SchemaVersion); err != nil {
+		return nil, err
+	}
+	var users int64
+	if err := db.Model(&models.User{}).Unscoped().Count(&users).Error; err != nil {
+		return nil, err
+	}
+	if users > 0 && !force {
+		return nil, errors.New("target database is not empty, use force to replace its data")
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// 逆序清空以满足外键 Clear in reverse order to satisfy foreign keys
+		for i := len(backupTables) - 1; i >= 0; i-- {
+			// 回滚到较早版本后，之后迁移创建的表不存在 Tables created by later migrations do not exist after rolling back to an older version
+			if !tx.Migrator().HasTable(backupTables[i].name) {
+				continue
+			}
+			if err := tx.Exec("DELETE FROM " + backupTables[i].name).Error; err != nil {
+				return err
+			}
+		}
+		for _, table := range backupTables {
+			f, ok := files["tables/"+table.name+".jsonl"]
+			if !ok {
+				continue
+			}
+			if err := restoreTable(tx, table, f, manifest); err != nil {
+				return fmt.Errorf("restore table %s: %w", table.name, err)
+			}
+		}
+		return resetSequences(tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range archive.File {
+		key, ok := strings.CutPrefix(f.Name, "files/")
+		if !ok || !slices.ContainsFunc(backupPrefixes, func(prefix string) bool { return strings.HasPrefix(key, prefix) }) {
+			continue
+		}
+		if err := restoreObject(f, key); err != nil {
+			return nil, err
+		}
+	}
+	return manifest, nil
+}
+
+func dumpTable(tx *gorm.DB, table backupTable, w io.Writer) error {
+	query := tx.Table(table.name)
+	if table.model != nil {
+		query = tx.Model(table.model).Unscoped()
+	}
+	rows, err := query.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	// 关联字段不属于本表，导出时去掉 Associations do not belong to this table and are stripped
+	var relations map[string]*schema.Relationship
+	if table.model != nil {
+		stmt := &gorm.Statement{DB: tx}
+		if err := stmt.Parse(table.model); err != nil {
+			return err
+		}
+		relations = stmt.Schema.Relationships.Relations
+	}
+	encoder := json.NewEncoder(w)
+	for rows.Next() {
+		var item any
+		if table.model != nil {
+			item = reflect.New(reflect.TypeOf(table.model).Elem()).Interface()
+		} else {
+			item = &map[string]any{}
+		}
+		if err := tx.ScanRows(rows, item); err != nil {
+			return err
+		}
+		if len(relations) > 0 {
+			data, err := json.Marshal(item)
+			if err != nil {
+				return err
+			}
+			fields := map[string]json.RawMessage{}
+			if err := json.Unmarshal(data, &fields); err != nil {
+				return err
+			}
+			for name := range relations {
+				delete(fields, name)
+			}
+			item = fields
+		}
+		if err := encoder.Encode(item); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func restoreTable(tx *gorm.DB, table backupTable, f *zip.File, manifest *BackupManifest) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	scanner := bufio.NewScanner(rc)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if table.model == nil {
+			row := map[string]any{}
+			if err := json.Unmarshal(scanner.Bytes(), &row); err != nil {
+				return err
+			}
+			if err := tx.Table(table.name).Create(row).Error; err != nil {
+				return err
+			}
+			continue
+		}
+		item := reflect.New(reflect.TypeOf(table.model).Elem()).Interface()
+		if err := json.Unmarshal(scanner.Bytes(), item); err != nil {
+			return err
+		}
+		rebaseFilePaths(item, manifest)
+		if err := tx.Omit(clause.Associations).Create(item).Error; err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// rebaseFilePaths 将来源实例的文件目录替换为当前实例的目录，迁移 3 之后记录保存的是与目录无关的对象键
+// Replace the source instance's file directories with the current ones, since migration 3 records hold directory independent object keys
+func rebaseFilePaths(item any, manifest *BackupManifest) {
+	if manifest.SchemaVersion >= 3 {
+		return
+	}
+	rebase := func(p, from, to string) string {
+		p = filepath.ToSlash(filepath.Clean(p))
+		if p == from || strings.HasPrefix(p, from+"/") {
+			return filepath.Join(to, strings.TrimPrefix(p, from))
+		}
+		return p
+	}
+	switch v := item.(type) {
+	case *models.File:
+		v.Path = rebase(v.Path, manifest.ReleaseRoot, config.ReleaseSavePath)
+	case *models.SiteRelease:
+		if v.PreviewPath != "" {
+			v.PreviewPath = rebase(v.PreviewPath, manifest.ReleaseRoot, config.ReleaseSavePath)
+		}
+	case *models.Organization:
+		if v.PreviewTemplate != nil {
+			rebased := rebase(*v.PreviewTemplate, manifest.TemplateRoot, config.TemplateSavePath)
+			v.PreviewTemplate = &rebased
+		}
+	}
+}
+
+// resetSequences 显式写入主键后，Postgres 的自增序列需要重置
+// Postgres sequences must be reset after inserting explicit primary keys
+func resetSequences(tx *gorm.DB) error {
+	if tx.Dialector.Name() != "postgres" {
+		return nil
+	}
+	for _, table := range backupTables {
+		// 没有自增主键的表无需处理 Tables without a serial primary key are skipped
+		if table.model == nil || !tx.Migrator().HasColumn(table.model, "id") {
+			continue
+		}
+		stmt := fmt.Sprintf("SELECT setval(pg_get_serial_sequence('%[1]s', 'id'), COALESCE((SELECT MAX(id) FROM %[1]s), 0) + 1, false)", table.name)
+		if err := tx.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func dumpObjects(archive *zip.Writer, prefix string) error {
+	ctx := context.Background()
+	objects, err := storage.Default.List(ctx, prefix)
+	if err != nil {
+		return err
+	}
+	for _, info := range objects {
+		entry, err := archive.CreateHeader(&zip.FileHeader{Name: "files/" + info.Key, Method: zip.Store, Modified: info.ModTime})
+		if err != nil {
+			return err
+		}
+		object, err := storage.Default.Get(ctx, info.Key)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(entry, object)
+		_ = object.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func restoreObject(f *zip.File, key string) error {
+	if strings.Contains(key, "..") || strings.HasSuffix(key, "/") {
+		logrus.Warnf("Skipping unsafe backup entry %s", f.Name)
+		return nil
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return storage.Default.Put(context.Background(), key, rc, int64(f.UncompressedSize64))
+}
+
+func readJSON(f *zip.File, v any) error {
+	if f == nil {
+		return errors.New("entry not found")
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return json.NewDecoder(rc).Decode(v)
+}
@@ -0,0 +1,102 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// blobQueryBatch 每次查询的哈希数量，避免超出数据库的参数数量限制
+// Number of hashes per query, staying below the parameter limits of databases
+const blobQueryBatch = 500
+
+type blobType struct {
+	db *gorm.DB
+}
+
+// Blob 按内容哈希存储的站点文件
+// Site files stored by content hash
+var Blob = blobType{
+	db: DB,
+}
+
+// Create 记录已存储的文件内容，已存在时忽略
+// Record stored file contents, ignoring ones that already exist
+func (b *blobType) Create(ctx context.Context, blobs []models.Blob) error {
+	if len(blobs) == 0 {
+		return nil
+	}
+	return b.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(blobs, blobQueryBatch).Error
+}
+
+// Link 记录部署清单引用的文件内容，已存在时忽略
+// Record the file contents referenced by a deployment manifest, ignoring rows that already exist
+func (b *blobType) Link(ctx context.Context, fileID uint, hashes []string) error {
+	links := make([]models.FileBlob, 0, len(hashes))
+	for _, hash := range hashes {
+		links = append(links, models.FileBlob{FileID: fileID, Hash: hash})
+	}
+	if len(links) == 0 {
+		return nil
+	}
+	return b.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(links, blobQueryBatch).Error
+}
+
+// ListUnlinkedManifests 获取尚未记录引用内容的部署清单，afterID 用于分批遍历
+// List deployment manifests whose referenced contents are not recorded yet, afterID pages through them in batches
+func (b *blobType) ListUnlinkedManifests(afterID uint, limit int) (files []models.File, err error) {
+	err = b.db.Where("manifest = ? AND id > ?", true, afterID).
+		Where("NOT EXISTS (?)", b.db.Model(&models.FileBlob{}).Select("1").Where("file_blobs.file_id = files.id")).
+		Order("id").Limit(limit).Find(&files).Error
+	return
+}
+
+// Sizes 查询已存储的哈希及其字节数，未存储的哈希不在结果中
+// Look up stored hashes and their sizes, hashes not stored are absent from the result
+func (b *blobType) Sizes(ctx context.Context, hashes []string) (map[string]int64, error) {
+	sizes := make(map[string]int64, len(hashes))
+	for start := 0; start < len(hashes); start += blobQueryBatch {
+		end := min(start+blobQueryBatch, len(hashes))
+		var found []models.Blob
+		err := b.db.WithContext(ctx).Where("hash IN ?", hashes[start:end]).Find(&found).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, blob := range found {
+			sizes[blob.Hash] = blob.Size
+		}
+	}
+	return sizes, nil
+}
+
+// TotalSize 统计所有文件内容的数量和字节数
+// Count all stored file contents and their total size in bytes
+func (b *blobType) TotalSize() (count, bytes int64, err error) {
+	row := struct {
+		Count int64
+		Bytes int64
+	}{}
+	err = b.db.Model(&models.Blob{}).Select("COUNT(*) AS count, COALESCE(SUM(size), 0) AS bytes").Scan(&row).Error
+	return row.Count, row.Bytes, err
+}
+
+// ListOrphaned 获取创建时间早于 before 且没有任何部署清单引用的内容，afterHash 用于分批遍历
+// List contents created before the given time that no deployment manifest references, afterHash pages through them in batches
+func (b *blobType) ListOrphaned(before time.Time, afterHash string, limit int) (blobs []models.Blob, err error) {
+	err = b.db.Where("created_at <= ? AND hash > ?", before, afterHash).
+		Where("NOT EXISTS (?)", b.db.Model(&models.FileBlob{}).Select("1").Where("file_blobs.hash = blobs.hash")).
+		Order("hash").Limit(limit).Find(&blobs).Error
+	return
+}
+
+// DeleteOrphaned 在内容仍未被引用时删除其记录，返回是否已删除；之后提交的清单会发现内容缺失并要求重新上传
+// Delete the record of a content while nothing references it, reporting whether it was deleted; manifests submitted afterwards find it missing and ask for it again
+func (b *blobType) DeleteOrphaned(ctx context.Context, hash string) (bool, error) {
+	result := b.db.WithContext(ctx).Where("hash = ?", hash).
+		Where("NOT EXISTS (?)", b.db.Model(&models.FileBlob{}).Select("1").Where("file_blobs.hash = blobs.hash")).
+		Delete(&models.Blob{})
+	return result.RowsAffected > 0, result.Error
+}
@@ -0,0 +1,65 @@
+package store
+
+import (
+	"github.com/LiteyukiStudio/spage/models"
+	"gorm.io/gorm"
+)
+
+type cdnType struct {
+	db *gorm.DB
+}
+
+// CDN 项目的 CDN 集成
+// CDN integrations of projects
+var CDN = cdnType{
+	db: DB,
+}
+
+// ListByProject 获取项目的 CDN 集成
+// List the CDN integrations of a project
+func (c *cdnType) ListByProject(projectID uint) (integrations []models.CDNIntegration, err error) {
+	err = c.db.Where("project_id = ?", projectID).Order("id").Find(&integrations).Error
+	return
+}
+
+// ListActive 获取项目已启用的 CDN 集成
+// List the enabled CDN integrations of a project
+func (c *cdnType) ListActive(projectID uint) (integrations []models.CDNIntegration, err error) {
+	err = c.db.Where("project_id = ? AND active = ?", projectID, true).Order("id").Find(&integrations).Error
+	return
+}
+
+// CountActive 项目已启用的 CDN 集成数量
+// Number of enabled CDN integrations of a project
+func (c *cdnType) CountActive(projectID uint) (count int64, err error) {
+	err = c.db.Model(&models.CDNIntegration{}).Where("project_id = ? AND active = ?", projectID, true).Count(&count).Error
+	return
+}
+
+// GetByProject 获取项目的指定 CDN 集成
+// Get the given CDN integration of a project
+func (c *cdnType) GetByProject(projectID, id uint) (integration *models.CDNIntegration, err error) {
+	integration = &models.CDNIntegration{}
+	if err = c.db.Where("id = ? AND project_id = ?", id, projectID).First(integration).Error; err != nil {
+		return nil, err
+	}
+	return integration, nil
+}
+
+// Create 创建 CDN 集成
+// Create a CDN integration
+func (c *cdnType) Create(integration *models.CDNIntegration) error {
+	return c.db.Create(integration).Error
+}
+
+// Update 更新 CDN 集成，单独写入 active 以支持停用
+// Update a CDN integration, writing active separately so it can be disabled
+func (c *cdnType) Update(integration *models.CDNIntegration) error {
+	return c.db.Model(integration).Select("provider", "zone_id", "url", "token", "active").Updates(integration).Error
+}
+
+// Delete 删除 CDN 集成
+// Delete a CDN integration
+func (c *cdnType) Delete(integration *models.CDNIntegration) error {
+	return c.db.Unscoped().Delete(integration).Error
+}
@@ -0,0 +1,66 @@
+package store
+
+import (
+	"errors"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/models"
+	"gorm.io/gorm"
+)
+
+type contentPolicyType struct {
+	db *gorm.DB
+}
+
+// ContentPolicy 组织的内容策略
+// Content policies of organizations
+var ContentPolicy = contentPolicyType{
+	db: DB,
+}
+
+// Get 获取管理员为组织设置的内容策略
+// Get the content policy an administrator set for an organization
+func (p *contentPolicyType) Get(orgID uint) (policy *models.ContentPolicy, err error) {
+	policy = &models.ContentPolicy{}
+	err = p.db.Where("organization_id = ?", orgID).First(policy).Error
+	if err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// Save 创建或覆盖组织的内容策略
+// Create or overwrite the content policy of an organization
+func (p *contentPolicyType) Save(policy *models.ContentPolicy) error {
+	if existing, err := p.Get(policy.OrganizationID); err == nil {
+		policy.ID = existing.ID
+		policy.CreatedAt = existing.CreatedAt
+	}
+	return p.db.Save(policy).Error
+}
+
+// Delete 删除组织的内容策略，恢复为默认策略
+// Delete the content policy of an organization, restoring the defaults
+func (p *contentPolicyType) Delete(orgID uint) error {
+	return p.db.Unscoped().Where("organization_id = ?", orgID).Delete(&models.ContentPolicy{}).Error
+}
+
+// Override 项目所属组织的内容策略，个人项目或组织没有单独设置时为空
+// Content policy of the project's organization, nil for personal projects or organizations without one
+func (p *contentPolicyType) Override(project *models.Project) (*models.ContentPolicy, error) {
+	if project.OwnerType != constants.OwnerTypeOrg {
+		return nil, nil
+	}
+	policy, err := p.Get(project.OwnerID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return policy, err
+}
+
+// ProjectIDs 组织的全部项目ID，包括回收站中的项目
+// IDs of all projects of an organization, including those in the trash
+func (p *contentPolicyType) ProjectIDs(orgID uint) (ids []uint, err error) {
+	err = p.db.Unscoped().Model(&models.Project{}).Where("owner_type = ? AND owner_id = ?", constants.OwnerTypeOrg, orgID).Pluck("id", &ids).Error
+	return
+}
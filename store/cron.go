@@ -0,0 +1,84 @@
+package store
+
+import (
+	"time"
+
+	"github.com/LiteyukiStudio/spage/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type cronType struct {
+	db *gorm.DB
+}
+
+// Cron 定时任务的运行状态
+// Run state of scheduled tasks
+var Cron = cronType{
+	db: DB,
+}
+
+// Ensure 为尚无记录的任务创建记录，多个实例同时创建时只保留一条
+// Create the records of tasks that have none, keeping one when instances create them at once
+func (c *cronType) Ensure(names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	tasks := make([]models.CronTask, 0, len(names))
+	for _, name := range names {
+		tasks = append(tasks, models.CronTask{Name: name})
+	}
+	return c.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&tasks).Error
+}
+
+// List 获取所有任务的状态
+// List the state of all tasks
+func (c *cronType) List() (tasks []models.CronTask, err error) {
+	err = c.db.Order("name").Find(&tasks).Error
+	return
+}
+
+// Get 获取任务的状态
+// Get the state of a task
+func (c *cronType) Get(name string) (task *models.CronTask, err error) {
+	task = &models.CronTask{}
+	err = c.db.Where("name = ?", name).First(task).Error
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// SetSchedule 保存任务的表达式和按其计算的下次运行时间，禁用时 next 为空
+// Save the expression of a task and the next run computed from it, next is nil when disabled
+func (c *cronType) SetSchedule(name, schedule string, next *time.Time) error {
+	return c.db.Model(&models.CronTask{}).Where("name = ?", name).
+		Updates(map[string]any{"schedule": schedule, "next_run_at": next}).Error
+}
+
+// Claim 领取一次运行，任务正在运行且开始时间不早于 stale 时失败；next 不为空时为到期运行，要求下次运行时间已到并将其推进到 next，
+// 为空时为手动触发，不影响下次运行时间；多个实例同时领取时只有一个成功
+// Claim a run, failing while the task runs since stale or later; a non-nil next makes it a due run that needs the next run time passed and moves it to next,
+// nil makes it a manual one leaving the next run alone; only one instance succeeds when several claim at once
+func (c *cronType) Claim(name, trigger string, now, stale time.Time, next *time.Time) (bool, error) {
+	query := c.db.Model(&models.CronTask{}).Where("name = ? AND (running_since IS NULL OR running_since < ?)", name, stale)
+	updates := map[string]any{"running_since": now, "last_run_at": now, "last_trigger": trigger}
+	if next != nil {
+		query = query.Where("next_run_at <= ?", now)
+		updates["next_run_at"] = *next
+	}
+	result := query.Updates(updates)
+	return result.RowsAffected > 0, result.Error
+}
+
+// Finish 记录一次运行的结果并解除运行状态
+// Record the outcome of a run and clear its running state
+func (c *cronType) Finish(name string, now time.Time, status, runErr, result string) error {
+	return c.db.Model(&models.CronTask{}).Where("name = ?", name).Updates(map[string]any{
+		"running_since":    nil,
+		"last_finished_at": now,
+		"last_status":      status,
+		"last_error":       runErr,
+		"last_result":      result,
+	}).Error
+}
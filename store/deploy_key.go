@@ -0,0 +1,71 @@
+package store
+
+import (
+	"time"
+
+	"github.com/LiteyukiStudio/spage/models"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+type deployKeyType struct {
+	db *gorm.DB
+}
+
+// DeployKey 项目部署密钥
+// Project deploy keys
+var DeployKey = deployKeyType{
+	db: DB,
+}
+
+// Create 创建部署密钥
+// Create a deploy key
+func (d *deployKeyType) Create(key *models.DeployKey) error {
+	return d.db.Create(key).Error
+}
+
+// ListByProject 获取项目的所有部署密钥
+// List all deploy keys of the project
+func (d *deployKeyType) ListByProject(projectID uint) (keys []models.DeployKey, err error) {
+	err = d.db.Where("project_id = ?", projectID).Order("id").Find(&keys).Error
+	return
+}
+
+// GetByProject 获取项目的指定部署密钥
+// Get the given deploy key of the project
+func (d *deployKeyType) GetByProject(projectID, id uint) (key *models.DeployKey, err error) {
+	key = &models.DeployKey{}
+	err = d.db.Where("id = ? AND project_id = ?", id, projectID).First(key).Error
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// GetByHash 根据哈希获取部署密钥，撤销必须立即生效，因此从主库读取
+// Get a deploy key by hash, revocation must take effect immediately so read from the primary
+func (d *deployKeyType) GetByHash(hash string) (key *models.DeployKey, err error) {
+	key = &models.DeployKey{}
+	err = d.db.Clauses(dbresolver.Write).Where("hash = ?", hash).First(key).Error
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Delete 撤销部署密钥，直接删除使哈希无法再被查到
+// Revoke a deploy key, deleted permanently so the hash can no longer be found
+func (d *deployKeyType) Delete(key *models.DeployKey) error {
+	return d.db.Unscoped().Delete(key).Error
+}
+
+// Touch 记录部署密钥的使用时间
+// Record the time the deploy key was used
+func (d *deployKeyType) Touch(key *models.DeployKey) error {
+	now := time.Now()
+	if key.LastUsedAt != nil && now.Sub(*key.LastUsedAt) < lastUsedInterval {
+		return nil
+	}
+	key.LastUsedAt = &now
+	return d.db.Model(key).UpdateColumn("last_used_at", now).Error
+}
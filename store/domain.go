@@ -0,0 +1,156 @@
+package store
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type domainType struct {
+	db *gorm.DB
+}
+
+// Domain 站点自定义域名与 ACME 证书缓存
+// Site custom domains and the ACME certificate cache
+var Domain = domainType{
+	db: DB,
+}
+
+// ListBySite 获取站点的自定义域名
+// List the custom domains of a site
+func (d *domainType) ListBySite(siteID uint) (domains []models.CustomDomain, err error) {
+	err = d.db.Where("site_id = ?", siteID).Order("id").Find(&domains).Error
+	return
+}
+
+// GetBySite 获取站点的指定自定义域名
+// Get the given custom domain of a site
+func (d *domainType) GetBySite(siteID, id uint) (domain *models.CustomDomain, err error) {
+	domain = &models.CustomDomain{}
+	err = d.db.Where("id = ? AND site_id = ?", id, siteID).First(domain).Error
+	if err != nil {
+		return nil, err
+	}
+	return domain, nil
+}
+
+// GetByDomain 根据域名获取自定义域名
+// Get a custom domain by name
+func (d *domainType) GetByDomain(name string) (domain *models.CustomDomain, err error) {
+	domain = &models.CustomDomain{}
+	err = d.db.Where("domain = ?", strings.ToLower(name)).First(domain).Error
+	if err != nil {
+		return nil, err
+	}
+	return domain, nil
+}
+
+// IsVerified 判断域名是否已验证所有权
+// Check whether the ownership of the domain is verified
+func (d *domainType) IsVerified(name string) bool {
+	domain, err := d.GetByDomain(name)
+	return err == nil && domain.VerifiedAt != nil
+}
+
+// IsClaimedByOther 判断域名是否已被其他站点绑定为自定义域名
+// Check whether the domain is bound as a custom domain by another site
+func (d *domainType) IsClaimedByOther(name string, siteID uint) bool {
+	var count int64
+	err := d.db.Model(&models.CustomDomain{}).Where("domain = ? AND site_id <> ?", strings.ToLower(name), siteID).Count(&count).Error
+	return err != nil || count > 0
+}
+
+// Create 创建自定义域名
+func (d *domainType) Create(domain *models.CustomDomain) error {
+	return d.db.Create(domain).Error
+}
+
+// SaveCheck 保存验证结果，验证通过时将域名加入站点的域名列表以开始提供服务
+// Save the verification result, a verified domain is added to the site's domains so it starts being served
+func (d *domainType) SaveCheck(domain *models.CustomDomain) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(domain).Error; err != nil {
+			return err
+		}
+		if domain.VerifiedAt == nil {
+			return nil
+		}
+		return updateSiteDomains(tx, domain.SiteID, func(domains []string) []string {
+			if slices.Contains(domains, domain.Domain) {
+				return domains
+			}
+			return append(domains, domain.Domain)
+		})
+	})
+}
+
+// Delete 解除自定义域名，同时从站点的域名列表中移除
+// Unbind a custom domain and remove it from the site's domains
+func (d *domainType) Delete(domain *models.CustomDomain) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Delete(domain).Error; err != nil {
+			return err
+		}
+		// autocert 以域名为键保存 ECDSA 证书，RSA 证书带 +rsa 后缀 autocert keys ECDSA certificates by domain and RSA ones with a +rsa suffix
+		if err := tx.Where("name IN ?", []string{domain.Domain, domain.Domain + "+rsa"}).Delete(&models.ACMECertificate{}).Error; err != nil {
+			return err
+		}
+		return updateSiteDomains(tx, domain.SiteID, func(domains []string) []string {
+			return slices.DeleteFunc(domains, func(name string) bool {
+				return strings.EqualFold(name, domain.Domain)
+			})
+		})
+	})
+}
+
+func updateSiteDomains(tx *gorm.DB, siteID uint, update func([]string) []string) error {
+	site := &models.Site{}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", siteID).First(site).Error; err != nil {
+		return err
+	}
+	site.Domains = update(site.Domains)
+	if site.Domains == nil {
+		site.Domains = []string{}
+	}
+	if err := tx.Model(site).Select("domains").Updates(site).Error; err != nil {
+		return err
+	}
+	return syncDomains(tx, site)
+}
+
+// GetCertificate 读取 ACME 缓存
+// Read from the ACME cache
+func (d *domainType) GetCertificate(key string) (cert *models.ACMECertificate, err error) {
+	cert = &models.ACMECertificate{}
+	err = d.db.Where("name = ?", key).First(cert).Error
+	if err != nil {
+		return nil, err
+	}
+	return cert, nil
+}
+
+// PutCertificate 写入 ACME 缓存
+// Write to the ACME cache
+func (d *domainType) PutCertificate(key string, data []byte) error {
+	return d.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"data", "updated_at"}),
+	}).Create(&models.ACMECertificate{Name: key, Data: data, UpdatedAt: time.Now()}).Error
+}
+
+// DeleteCertificate 删除 ACME 缓存
+// Delete from the ACME cache
+func (d *domainType) DeleteCertificate(key string) error {
+	return d.db.Where("name = ?", key).Delete(&models.ACMECertificate{}).Error
+}
+
+// ListCertificates 获取 ACME 缓存中的全部条目
+// List every entry of the ACME cache
+func (d *domainType) ListCertificates() (certs []models.ACMECertificate, err error) {
+	err = d.db.Order("name").Find(&certs).Error
+	return
+}
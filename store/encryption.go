@@ -0,0 +1,227 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/LiteyukiStudio/spage/keyring"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	// maxStaleSecrets 等待重新加密的值的上限，超出的值留给 rekey 定时任务
+	// Most values waiting to be re-encrypted, the rest is left to the rekey scheduled task
+	maxStaleSecrets = 10000
+	// rekeyBatch rekey 每次读取的行数 Rows read per batch by rekey
+	rekeyBatch = 200
+)
+
+func init() {
+	schema.RegisterSerializer("encrypted", encryptedSerializer{})
+}
+
+// encryptedSerializer 以 gorm:"serializer:encrypted" 标记的字符串字段写入时用 keyring 加密，读取时解密，附加数据为表名和列名；
+// 读到未加密或由旧密钥加密的值时排队重新加密
+// Strings tagged gorm:"serializer:encrypted" are encrypted with the keyring as they are written and decrypted as they are read, bound to their table and column;
+// plain values and values of an old key are queued to be encrypted again when read
+type encryptedSerializer struct{}
+
+func (encryptedSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue any) error {
+	var raw string
+	switch value := dbValue.(type) {
+	case nil:
+	case string:
+		raw = value
+	case []byte:
+		raw = string(value)
+	default:
+		return fmt.Errorf("unsupported encrypted value %T of %s", dbValue, field.Name)
+	}
+	plain := raw
+	if raw != "" {
+		var stale bool
+		var err error
+		if plain, stale, err = keyring.Decrypt(raw, secretAAD(field)); err != nil {
+			return fmt.Errorf("decrypt %s.%s: %w", field.Schema.Table, field.DBName, err)
+		}
+		if stale {
+			Encryption.queue(ctx, field, dst, raw)
+		}
+	}
+	field.ReflectValueOf(ctx, dst).SetString(plain)
+	return nil
+}
+
+func (encryptedSerializer) Value(_ context.Context, field *schema.Field, _ reflect.Value, fieldValue any) (any, error) {
+	plain, ok := fieldValue.(string)
+	if !ok {
+		return nil, fmt.Errorf("unsupported encrypted field %T of %s", fieldValue, field.Name)
+	}
+	// 空值保持为空，以便区分未设置 Empty values stay empty so unset ones remain recognizable
+	if plain == "" {
+		return "", nil
+	}
+	return keyring.Encrypt(plain, secretAAD(field))
+}
+
+// secretAAD 加密时绑定的表名和列名，防止密文被复制到其他列后解密
+// Table and column bound when encrypting, so a ciphertext copied into another column does not decrypt
+func secretAAD(field *schema.Field) string {
+	return field.Schema.Table + "." + field.DBName
+}
+
+// staleSecret 等待重新加密的值
+// A value waiting to be re-encrypted
+type staleSecret struct {
+	table  string
+	column string
+	pk     string
+	id     any
+}
+
+type encryptionType struct {
+	db      *gorm.DB
+	mu      sync.Mutex
+	pending map[staleSecret]string // 读取时数据库中的值 Value in the database when read
+}
+
+// Encryption 敏感字段的重新加密，读取时发现的旧值在内存中排队，由 Flush 写回，Rekey 扫描全部加密字段
+// Re-encryption of sensitive columns, stale values found while reading are queued in memory and written back by Flush, Rekey scans every encrypted column
+var Encryption = encryptionType{
+	db:      DB,
+	pending: make(map[staleSecret]string),
+}
+
+// queue 排队重新加密读到的旧值，需要已读取到主键，否则留给 rekey 定时任务
+// Queue a stale value that was read for re-encryption, the primary key has to be loaded already or it is left to the rekey scheduled task
+func (e *encryptionType) queue(ctx context.Context, field *schema.Field, dst reflect.Value, raw string) {
+	pk := field.Schema.PrioritizedPrimaryField
+	if pk == nil || !dst.IsValid() {
+		return
+	}
+	id, zero := pk.ValueOf(ctx, dst)
+	if zero {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if len(e.pending) >= maxStaleSecrets {
+		return
+	}
+	e.pending[staleSecret{table: field.Schema.Table, column: field.DBName, pk: pk.DBName, id: id}] = raw
+}
+
+// Flush 用当前密钥重新加密排队的值，只在数据库中的值未被修改时写回
+// Encrypt the queued values again with the active key, writing back only where the value in the database is unchanged
+func (e *encryptionType) Flush(ctx context.Context) error {
+	e.mu.Lock()
+	pending := e.pending
+	e.pending = make(map[staleSecret]string)
+	e.mu.Unlock()
+	var errs []error
+	for secret, raw := range pending {
+		if _, err := e.rewrite(ctx, secret, raw); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// rewrite 重新加密一个值并在数据库中的值仍为 raw 时写回，返回是否写入
+// Encrypt one value again and write it back while the database still holds raw, reporting whether it was written
+func (e *encryptionType) rewrite(ctx context.Context, secret staleSecret, raw string) (bool, error) {
+	aad := secret.table + "." + secret.column
+	plain, stale, err := keyring.Decrypt(raw, aad)
+	if err != nil || !stale {
+		return false, err
+	}
+	sealed, err := keyring.Encrypt(plain, aad)
+	if err != nil {
+		return false, err
+	}
+	result := e.db.WithContext(ctx).Table(secret.table).
+		Where(clause.Eq{Column: clause.Column{Name: secret.pk}, Value: secret.id}).
+		Where(clause.Eq{Column: clause.Column{Name: secret.column}, Value: raw}).
+		UpdateColumn(secret.column, sealed)
+	return result.RowsAffected > 0, result.Error
+}
+
+// encryptedColumn 一个加密字段所在的表和列
+// Table and column of one encrypted field
+type encryptedColumn struct {
+	table  string
+	column string
+	pk     string
+}
+
+// encryptedColumns 备份表中以 serializer:encrypted 标记的字段
+// Fields tagged serializer:encrypted in the backed up tables
+func (e *encryptionType) encryptedColumns() ([]encryptedColumn, error) {
+	var columns []encryptedColumn
+	for _, table := range backupTables {
+		if table.model == nil {
+			continue
+		}
+		stmt := &gorm.Statement{DB: e.db}
+		if err := stmt.Parse(table.model); err != nil {
+			return nil, err
+		}
+		for _, field := range stmt.Schema.Fields {
+			if field.TagSettings["SERIALIZER"] == "encrypted" && field.DBName != "" {
+				columns = append(columns, encryptedColumn{table: stmt.Schema.Table, column: field.DBName, pk: stmt.Schema.PrioritizedPrimaryField.DBName})
+			}
+		}
+	}
+	return columns, nil
+}
+
+// Rekey 用当前密钥重新加密所有未加密或由旧密钥加密的值，包括已软删除的记录，返回每列重新加密的数量；未开启加密时不做任何事
+// Encrypt every plain value and every value of an old key again with the active key, soft deleted records included, returning the count per column; does nothing while encryption is off
+func (e *encryptionType) Rekey(ctx context.Context) (map[string]int, error) {
+	result := map[string]int{}
+	if !keyring.Enabled() {
+		return result, nil
+	}
+	columns, err := e.encryptedColumns()
+	if err != nil {
+		return nil, err
+	}
+	for _, column := range columns {
+		var lastID uint
+		for {
+			var rows []struct {
+				ID    uint
+				Value string
+			}
+			err := e.db.WithContext(ctx).Table(column.table).
+				Select(column.pk+" AS id", column.column+" AS value").
+				Where(clause.Gt{Column: clause.Column{Name: column.pk}, Value: lastID}).
+				Where(clause.Neq{Column: clause.Column{Name: column.column}, Value: ""}).
+				Where(clause.Expr{SQL: "? NOT LIKE ? ESCAPE '\\'", Vars: []any{clause.Column{Name: column.column}, likePrefix(keyring.ActivePrefix())}}).
+				Order(clause.OrderByColumn{Column: clause.Column{Name: column.pk}}).
+				Limit(rekeyBatch).Find(&rows).Error
+			if err != nil {
+				return nil, err
+			}
+			for _, row := range rows {
+				written, err := e.rewrite(ctx, staleSecret{table: column.table, column: column.column, pk: column.pk, id: row.ID}, row.Value)
+				if err != nil {
+					return nil, fmt.Errorf("re-encrypt %s.%s of %d: %w", column.table, column.column, row.ID, err)
+				}
+				if written {
+					result[column.table+"."+column.column]++
+				}
+			}
+			if len(rows) < rekeyBatch {
+				break
+			}
+			lastID = rows[len(rows)-1].ID
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,95 @@
+package store
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/keyring"
+	"github.com/LiteyukiStudio/spage/models"
+)
+
+// useKeys 在测试期间使用给定的加密配置 Use the given encryption configuration for the duration of the test
+func useKeys(t *testing.T, active string, ids ...string) {
+	t.Helper()
+	config.EncryptionActiveKey = active
+	config.EncryptionKeys = map[string]string{}
+	for i, id := range ids {
+		config.EncryptionKeys[id] = base64.StdEncoding.EncodeToString([]byte(strings.Repeat(string(rune('a'+i)), 32)))
+	}
+	if err := keyring.Init(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		config.EncryptionActiveKey, config.EncryptionKeys = "", nil
+		_ = keyring.Init()
+	})
+}
+
+// TestEncryptedColumns 开启加密后旧的明文值在读取时重新加密，新值加密写入，轮换密钥后 Rekey 重新加密全部值
+// Once encryption is on, older plain values are encrypted again when read and new values are written encrypted; after a rotation Rekey encrypts every value again
+func TestEncryptedColumns(t *testing.T) {
+	db := openTestHandle(t, "encryption").DB()
+	previous := Encryption.db
+	Encryption.db = db
+	t.Cleanup(func() { Encryption.db = previous })
+	ctx := context.Background()
+	raw := func(id uint) string {
+		var value string
+		if err := db.Table("webhooks").Where("id = ?", id).Pluck("secret", &value).Error; err != nil {
+			t.Fatal(err)
+		}
+		return value
+	}
+
+	project := &models.Project{Name: "encryption"}
+	if err := db.Create(project).Error; err != nil {
+		t.Fatal(err)
+	}
+	plain := &models.Webhook{ProjectID: project.ID, URL: "https://example.com/a", Secret: "first secret"}
+	if err := db.Create(plain).Error; err != nil {
+		t.Fatal(err)
+	}
+	if got := raw(plain.ID); got != "first secret" {
+		t.Fatalf("stored %q without keys, want plain", got)
+	}
+
+	useKeys(t, "k1", "k1")
+	loaded := &models.Webhook{}
+	if err := db.First(loaded, plain.ID).Error; err != nil || loaded.Secret != "first secret" {
+		t.Fatalf("read plain value %q %v", loaded.Secret, err)
+	}
+	if err := Encryption.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := raw(plain.ID); !strings.HasPrefix(got, "enc:k1:") {
+		t.Errorf("after reading %q, want it re-encrypted with k1", got)
+	}
+	sealed := &models.Webhook{ProjectID: project.ID, URL: "https://example.com/b", Secret: "second secret"}
+	if err := db.Create(sealed).Error; err != nil {
+		t.Fatal(err)
+	}
+	if got := raw(sealed.ID); !strings.HasPrefix(got, "enc:k1:") {
+		t.Errorf("created %q, want it encrypted with k1", got)
+	}
+
+	useKeys(t, "k2", "k1", "k2")
+	counts, err := Encryption.Rekey(ctx)
+	if err != nil || counts["webhooks.secret"] != 2 {
+		t.Fatalf("Rekey = %v %v, want 2 webhook secrets", counts, err)
+	}
+	var hooks []models.Webhook
+	if err := db.Order("id").Find(&hooks).Error; err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []string{"first secret", "second secret"} {
+		if hooks[i].Secret != want || !strings.HasPrefix(raw(hooks[i].ID), "enc:k2:") {
+			t.Errorf("webhook %d: %q stored as %q, want %q encrypted with k2", i, hooks[i].Secret, raw(hooks[i].ID), want)
+		}
+	}
+	if counts, err := Encryption.Rekey(ctx); err != nil || len(counts) != 0 {
+		t.Errorf("second Rekey = %v %v, want nothing left", counts, err)
+	}
+}
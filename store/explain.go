@@ -0,0 +1,93 @@
+package store
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// canonicalQuery 热点查询，语句由仓库实际使用的查询构造生成
+// A hot query, built from the same query builder the repositories use
+type canonicalQuery struct {
+	name  string
+	build func(tx *gorm.DB) *gorm.DB
+}
+
+var canonicalQueries = []canonicalQuery{
+	{"latest release", func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("site_id = ? AND tag = ?", 1, "latest").First(&models.SiteRelease{})
+	}},
+	{"releases by site", func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("site_id = ?", 1).Order("created_at DESC").Find(&[]models.SiteRelease{})
+	}},
+	{"site by domain", func(tx *gorm.DB) *gorm.DB {
+		return tx.Joins("JOIN site_domains ON site_domains.site_id = sites.id").
+			Where("site_domains.domain = ?", "example.com").First(&models.Site{})
+	}},
+	{"site by subdomain", func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("LOWER(sub_domain) = ?", "example").First(&models.Site{})
+	}},
+	{"tokens by user", func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("user_id = ?", 1).Find(&[]models.Token{})
+	}},
+	{"purges since", func(tx *gorm.DB) *gorm.DB {
+		var count int64
+		return tx.Model(&models.SitePathPurge{}).Where("project_id = ? AND created_at >= ?", 1, time.Now()).Count(&count)
+	}},
+}
+
+// explainPlan Postgres EXPLAIN (FORMAT JSON) 的计划节点
+// A plan node of Postgres EXPLAIN (FORMAT JSON)
+type explainPlan struct {
+	NodeType     string        `json:"Node Type"`
+	RelationName string        `json:"Relation Name"`
+	Plans        []explainPlan `json:"Plans"`
+}
+
+// checkQueryPlans 对热点查询执行 EXPLAIN，对超过阈值的大表顺序扫描发出警告，仅支持 Postgres
+// EXPLAIN the hot queries and warn on sequential scans over tables above the threshold, Postgres only
+func checkQueryPlans(db *gorm.DB, threshold int) {
+	if db.Dialector.Name() != "postgres" {
+		logrus.Info("Query plan check is only supported on postgres, skipped")
+		return
+	}
+	for _, query := range canonicalQueries {
+		sql := db.ToSQL(query.build)
+		var raw string
+		if err := db.Raw("EXPLAIN (FORMAT JSON) " + sql).Row().Scan(&raw); err != nil {
+			logrus.Warnf("Failed to explain query %q: %v", query.name, err)
+			continue
+		}
+		var plans []struct {
+			Plan explainPlan `json:"Plan"`
+		}
+		if err := json.Unmarshal([]byte(raw), &plans); err != nil || len(plans) == 0 {
+			logrus.Warnf("Failed to parse plan of query %q: %v", query.name, err)
+			continue
+		}
+		for _, relation := range seqScans(plans[0].Plan) {
+			var rows float64
+			if err := db.Raw("SELECT reltuples FROM pg_class WHERE oid = to_regclass(?)", relation).Row().Scan(&rows); err != nil {
+				continue
+			}
+			if rows > float64(threshold) {
+				logrus.Warnf("Query %q runs a sequential scan on %s (~%.0f rows): %s", query.name, relation, rows, sql)
+			}
+		}
+	}
+}
+
+// seqScans 收集计划树中顺序扫描的表
+// Collect the relations scanned sequentially in the plan tree
+func seqScans(plan explainPlan) (relations []string) {
+	if plan.NodeType == "Seq Scan" {
+		relations = append(relations, plan.RelationName)
+	}
+	for _, child := range plan.Plans {
+		relations = append(relations, seqScans(child)...)
+	}
+	return
+}
@@ -1,6 +1,8 @@
 package store
 
 import (
+	"context"
+
 	"github.com/LiteyukiStudio/spage/models"
 	"gorm.io/gorm"
 )
@@ -13,6 +15,79 @@ var File = FileType{
 	db: DB,
 }
 
-func (f *FileType) Create(file *models.File) (err error) {
-	return f.db.Create(file).Error
+func (f *FileType) Create(ctx context.Context, file *models.File) (err error) {
+	return f.db.WithContext(ctx).Create(file).Error
+}
+
+// GetByPath 根据对象键获取文件记录
+// Get the file record by its object key
+func (f *FileType) GetByPath(ctx context.Context, path string) (file *models.File, err error) {
+	file = &models.File{}
+	err = f.db.WithContext(ctx).Where("path = ?", path).First(file).Error
+	return
+}
+
+// SetSize 记录文件的字节数
+// Record the size of a file in bytes
+func (f *FileType) SetSize(file *models.File, size int64) error {
+	file.Size = size
+	return f.db.Model(file).Update("size", size).Error
+}
+
+// ListUnsized 获取尚未统计大小的文件，afterID 用于分批遍历
+// List files whose size is not yet measured, afterID pages through them in batches
+func (f *FileType) ListUnsized(afterID uint, limit int) (files []models.File, err error) {
+	err = f.db.Where("size = 0 AND id > ?", afterID).Order("id").Limit(limit).Find(&files).Error
+	return
+}
+
+// TotalSize 统计所有文件的数量和字节数
+// Count all files and their total size in bytes
+func (f *FileType) TotalSize() (count, bytes int64, err error) {
+	row := struct {
+		Count int64
+		Bytes int64
+	}{}
+	err = f.db.Model(&models.File{}).Select("COUNT(*) AS count, COALESCE(SUM(size), 0) AS bytes").Scan(&row).Error
+	return row.Count, row.Bytes, err
+}
+
+// ExistingIDs 查询仍存在的文件ID
+// Look up which of the file IDs still exist
+func (f *FileType) ExistingIDs(ctx context.Context, ids []uint) (map[uint]bool, error) {
+	existing := make(map[uint]bool, len(ids))
+	for start := 0; start < len(ids); start += blobQueryBatch {
+		end := min(start+blobQueryBatch, len(ids))
+		var found []uint
+		err := f.db.WithContext(ctx).Model(&models.File{}).Where("id IN ?", ids[start:end]).Pluck("id", &found).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range found {
+			existing[id] = true
+		}
+	}
+	return existing, nil
+}
+
+// KnownPaths 查询被文件记录或版本预览图引用的对象键
+// Look up which of the object keys are referenced by a file record or a version preview image
+func (f *FileType) KnownPaths(ctx context.Context, paths []string) (map[string]bool, error) {
+	known := make(map[string]bool, len(paths))
+	for start := 0; start < len(paths); start += blobQueryBatch {
+		end := min(start+blobQueryBatch, len(paths))
+		var found, previews []string
+		err := f.db.WithContext(ctx).Model(&models.File{}).Where("path IN ?", paths[start:end]).Pluck("path", &found).Error
+		if err != nil {
+			return nil, err
+		}
+		err = f.db.WithContext(ctx).Unscoped().Model(&models.SiteRelease{}).Where("preview_path IN ?", paths[start:end]).Pluck("preview_path", &previews).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range append(found, previews...) {
+			known[path] = true
+		}
+	}
+	return known, nil
 }
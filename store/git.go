@@ -0,0 +1,80 @@
+package store
+
+import (
+	"time"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/models"
+	"gorm.io/gorm"
+)
+
+type gitType struct {
+	db *gorm.DB
+}
+
+// Git 站点绑定的 Git 仓库
+// Git repositories bound to sites
+var Git = gitType{
+	db: DB,
+}
+
+// GetBySite 获取站点绑定的仓库
+// Get the repository bound to a site
+func (g *gitType) GetBySite(siteID uint) (integration *models.GitIntegration, err error) {
+	integration = &models.GitIntegration{}
+	err = g.db.Where("site_id = ?", siteID).First(integration).Error
+	if err != nil {
+		return nil, err
+	}
+	return integration, nil
+}
+
+// GetByID 获取仓库绑定及其站点和项目，用于处理推送事件
+// Get a repository binding with its site and project, used to handle push events
+func (g *gitType) GetByID(id uint) (integration *models.GitIntegration, err error) {
+	integration = &models.GitIntegration{}
+	err = g.db.Where("id = ?", id).Preload("Site").Preload("Site.Project").First(integration).Error
+	if err != nil {
+		return nil, err
+	}
+	return integration, nil
+}
+
+// Create 创建仓库绑定
+// Create a repository binding
+func (g *gitType) Create(integration *models.GitIntegration) error {
+	return g.db.Omit("Site").Create(integration).Error
+}
+
+// Update 更新仓库绑定的设置，单独写入可清空的字段
+// Update the settings of a repository binding, writing clearable fields explicitly
+func (g *gitType) Update(integration *models.GitIntegration) error {
+	return g.db.Model(integration).Omit("Site").
+		Select("provider", "repo_url", "branch", "secret", "token", "build_command", "build_preset", "build_image", "runner_labels", "output_dir").
+		Updates(integration).Error
+}
+
+// Delete 删除仓库绑定
+// Delete a repository binding
+func (g *gitType) Delete(integration *models.GitIntegration) error {
+	return g.db.Unscoped().Delete(integration).Error
+}
+
+// SaveRun 记录一次运行的状态
+// Record the status of a run
+func (g *gitType) SaveRun(id uint, commit, status, runErr, log string) error {
+	now := time.Now()
+	return g.db.Model(&models.GitIntegration{}).Where("id = ?", id).Updates(map[string]any{
+		"last_commit": commit,
+		"last_status": status,
+		"last_error":  runErr,
+		"last_log":    log,
+		"last_run_at": &now,
+	}).Error
+}
+
+// SaveLog 更新正在进行的构建的输出
+// Update the output of the build in progress
+func (g *gitType) SaveLog(id uint, log string) error {
+	return g.db.Model(&models.GitIntegration{}).Where("id = ? AND last_status = ?", id, constants.GitRunBuilding).Update("last_log", log).Error
+}
@@ -0,0 +1,115 @@
+package store
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// Handle 绑定到同一数据库连接、上下文或事务的一组仓库，使跨表操作可以原子完成，测试也可以各自使用隔离的数据库。
+// 审计、锁、流量、访问统计和请求日志仓库持有本实例的状态，只通过包级变量访问；仓库方法内部调用的其他仓库同样使用包级变量。
+// A set of repositories bound to one database connection, context or transaction, so multi-table operations can be atomic and tests can each use an isolated database.
+// The audit, lock, traffic, analytics and request log repositories hold per-instance state and are only reached through the package-level variables; repositories called inside repository methods use the package-level ones too.
+type Handle struct {
+	db *gorm.DB
+
+	User          userType
+	Org           orgType
+	Project       projectType
+	Site          SiteType
+	File          FileType
+	OIDC          oidcType
+	APIToken      apiTokenType
+	TwoFactor     twoFactorType
+	Domain        domainType
+	Preview       previewType
+	Team          teamType
+	Webhook       webhookType
+	CDN           cdnType
+	Notification  notificationType
+	Git           gitType
+	Upload        uploadType
+	Blob          blobType
+	Quota         quotaType
+	ContentPolicy contentPolicyType
+	Trash         trashType
+	Job           jobType
+	Search        searchType
+	Stats         statsType
+	UserToken     userTokenType
+	Invitation    invitationType
+	Transfer      transferType
+	Setting       settingType
+	LoginFailure  loginFailureType
+	DeployKey     deployKeyType
+	Tenant        tenantType
+	Runner        runnerType
+	Cron          cronType
+	JWT           jwtType
+	Backup        backupType
+}
+
+// Default 绑定到默认数据库连接的仓库，连接建立前为空
+// Repositories bound to the default database connection, nil until it is open
+var Default *Handle
+
+// New 创建绑定到数据库连接或事务的仓库
+// Create repositories bound to a database connection or transaction
+func New(db *gorm.DB) *Handle {
+	h := &Handle{db: db}
+	h.User.db = db
+	h.Org.db = db
+	h.Project.db = db
+	h.Site.db = db
+	h.File.db = db
+	h.OIDC.db = db
+	h.APIToken.db = db
+	h.TwoFactor.db = db
+	h.Domain.db = db
+	h.Preview.db = db
+	h.Team.db = db
+	h.Webhook.db = db
+	h.CDN.db = db
+	h.Notification.db = db
+	h.Git.db = db
+	h.Upload.db = db
+	h.Blob.db = db
+	h.Quota.db = db
+	h.ContentPolicy.db = db
+	h.Trash.db = db
+	h.Job.db = db
+	h.Search.db = db
+	h.Stats.db = db
+	h.UserToken.db = db
+	h.Invitation.db = db
+	h.Transfer.db = db
+	h.Setting.db = db
+	h.LoginFailure.db = db
+	h.DeployKey.db = db
+	h.Tenant.db = db
+	h.Runner.db = db
+	h.Cron.db = db
+	h.JWT.db = db
+	h.Backup.db = db
+	return h
+}
+
+// DB 获取仓库绑定的数据库连接或事务
+// Get the database connection or transaction the repositories are bound to
+func (h *Handle) DB() *gorm.DB {
+	return h.db
+}
+
+// WithContext 获取在 ctx 下执行查询的仓库，ctx 结束时查询随之取消
+// Get repositories running their queries under ctx, which are cancelled when ctx is done
+func (h *Handle) WithContext(ctx context.Context) *Handle {
+	return New(h.db.WithContext(ctx))
+}
+
+// WithTx 在事务中执行 fn，fn 返回错误或 panic 时回滚；已在事务中时使用保存点
+// Run fn within a transaction, rolling back when fn returns an error or panics; a savepoint is used when already inside a transaction
+func (h *Handle) WithTx(ctx context.Context, fn func(tx *Handle) error) error {
+	return h.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(New(tx))
+	})
+}
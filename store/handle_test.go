@@ -0,0 +1,589 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTestHandle 创建以 name 命名的隔离内存数据库并返回绑定到它的仓库
+// Create an isolated in-memory database called name and return repositories bound to it
+func openTestHandle(t *testing.T, name string) *Handle {
+	db, err := gorm.Open(sqlite.Open(MemoryDSN(name)), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := models.Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+	return New(db)
+}
+
+// seedSite 创建项目、与项目同名的站点和站点的版本，未设置所有者的项目归属 ID 为 1 的用户
+// Create a project, a site named after it and versions of the site, projects without an owner belong to user 1
+func seedSite(t *testing.T, h *Handle, project *models.Project, releases ...*models.SiteRelease) *models.Site {
+	t.Helper()
+	if project.OwnerType == "" {
+		project.OwnerType, project.OwnerID = constants.OwnerTypeUser, 1
+	}
+	if err := h.Project.Create(project); err != nil {
+		t.Fatal(err)
+	}
+	site := &models.Site{Name: project.Name, ProjectID: project.ID, SubDomain: project.Name}
+	if err := h.Site.Create(site); err != nil {
+		t.Fatal(err)
+	}
+	for _, release := range releases {
+		release.SiteID = site.ID
+		if err := h.Site.CreateRelease(context.Background(), release); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return site
+}
+
+func TestHandleWithTx(t *testing.T) {
+	for _, name := range []string{"tx_a", "tx_b"} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			h := openTestHandle(t, name)
+			ctx := context.Background()
+			create := func(fail error) error {
+				return h.WithTx(ctx, func(tx *Handle) error {
+					project := &models.Project{Name: name, OwnerType: constants.OwnerTypeUser, OwnerID: 1}
+					if err := tx.Project.Create(project); err != nil {
+						return err
+					}
+					if err := tx.Site.Create(&models.Site{Name: name, ProjectID: project.ID, SubDomain: name}); err != nil {
+						return err
+					}
+					return fail
+				})
+			}
+
+			failed := errors.New("initial deployment failed")
+			if err := create(failed); !errors.Is(err, failed) {
+				t.Fatalf("WithTx = %v, want %v", err, failed)
+			}
+			if _, err := h.Project.GetByName(name); !errors.Is(err, gorm.ErrRecordNotFound) {
+				t.Fatalf("project should be rolled back, got %v", err)
+			}
+			if _, err := h.Site.GetBySubDomain(name); !errors.Is(err, gorm.ErrRecordNotFound) {
+				t.Fatalf("site should be rolled back, got %v", err)
+			}
+
+			if err := create(nil); err != nil {
+				t.Fatal(err)
+			}
+			project, err := h.Project.GetByName(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			site, err := h.Site.GetBySubDomain(name)
+			if err != nil || site.ProjectID != project.ID {
+				t.Fatalf("site = %+v, %v", site, err)
+			}
+			// 每个数据库只包含自己的项目 Each database only holds its own project
+			var count int64
+			if err := h.DB().Model(&models.Project{}).Count(&count).Error; err != nil || count != 1 {
+				t.Fatalf("projects = %d, %v", count, err)
+			}
+		})
+	}
+}
+
+func TestSessionsWithTx(t *testing.T) {
+	h := openTestHandle(t, "sessions_tx")
+	ctx := context.Background()
+	kept := &models.Token{UserID: 1, ExpiresAt: time.Now().Add(time.Hour)}
+	if err := h.JWT.CreateSession(kept); err != nil {
+		t.Fatal(err)
+	}
+	// 事务回滚时新建的会话和撤销一并撤回 Rolling back undoes both the new session and the revocation
+	failed := errors.New("password change failed")
+	err := h.WithTx(ctx, func(tx *Handle) error {
+		if err := tx.JWT.CreateSession(&models.Token{UserID: 1, ExpiresAt: time.Now().Add(time.Hour)}); err != nil {
+			return err
+		}
+		if err := tx.JWT.RevokeTokenByUserID(1); err != nil {
+			return err
+		}
+		return failed
+	})
+	if !errors.Is(err, failed) {
+		t.Fatalf("WithTx = %v, want %v", err, failed)
+	}
+	if sessions, err := h.JWT.ListSessions(1); err != nil || len(sessions) != 1 || sessions[0].ID != kept.ID {
+		t.Fatalf("sessions after rollback = %+v, %v", sessions, err)
+	}
+	if err := h.WithTx(ctx, func(tx *Handle) error { return tx.JWT.RevokeTokenByUserID(1) }); err != nil {
+		t.Fatal(err)
+	}
+	if sessions, err := h.JWT.ListSessions(1); err != nil || len(sessions) != 0 {
+		t.Fatalf("sessions after revocation = %+v, %v", sessions, err)
+	}
+}
+
+func TestCaseInsensitiveNames(t *testing.T) {
+	h := openTestHandle(t, "case_insensitive_names")
+	if err := h.User.Create(&models.User{Name: "Alice"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.User.Create(&models.User{Name: "alice"}); err == nil {
+		t.Fatal("user names differing only in case should conflict")
+	}
+	if user, err := h.User.GetByName("ALICE"); err != nil || user.Name != "Alice" {
+		t.Fatalf("GetByName(ALICE) = %v, %v", user, err)
+	}
+	if err := h.Project.Create(&models.Project{Name: "blog", OwnerType: constants.OwnerTypeUser, OwnerID: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Project.Create(&models.Project{Name: "Blog", OwnerType: constants.OwnerTypeUser, OwnerID: 1}); err == nil {
+		t.Fatal("project names differing only in case should conflict")
+	}
+}
+
+func TestLoginFailure(t *testing.T) {
+	h := openTestHandle(t, "login_failure")
+	now := time.Now()
+	for i := 1; i <= 3; i++ {
+		failure, err := h.LoginFailure.Fail(models.LoginFailureUser, "1", now, now.Add(-time.Hour))
+		if err != nil || failure.Failures != i {
+			t.Fatalf("Fail #%d = %d, %v", i, failure.Failures, err)
+		}
+	}
+	// 窗口外的失败重新计数 Failures outside the window count afresh
+	later := now.Add(2 * time.Hour)
+	if failure, err := h.LoginFailure.Fail(models.LoginFailureUser, "1", later, later.Add(-time.Hour)); err != nil || failure.Failures != 1 {
+		t.Fatalf("Fail after the window = %d, %v", failure.Failures, err)
+	}
+	if err := h.LoginFailure.Lock(models.LoginFailureUser, "1", later.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if failure, err := h.LoginFailure.Get(models.LoginFailureUser, "1"); err != nil || failure.LockedUntil == nil || failure.Failures != 0 {
+		t.Fatalf("Get after Lock = %+v, %v", failure, err)
+	}
+	if removed, err := h.LoginFailure.DeleteBefore(later.Add(time.Hour), later); err != nil || removed != 0 {
+		t.Fatalf("DeleteBefore removed a locked record: %d, %v", removed, err)
+	}
+	if err := h.LoginFailure.Reset(models.LoginFailureUser, "1"); err != nil {
+		t.Fatal(err)
+	}
+	if failure, err := h.LoginFailure.Get(models.LoginFailureUser, "1"); err != nil || failure.Failures != 0 || failure.LockedUntil != nil {
+		t.Fatalf("Get after Reset = %+v, %v", failure, err)
+	}
+}
+
+func TestTenant(t *testing.T) {
+	h := openTestHandle(t, "tenant")
+	domain := "pages.example.org"
+	tenant := &models.Tenant{Name: "sales", DisplayName: "Sales", BaseDomain: &domain}
+	if err := h.Tenant.Create(tenant); err != nil {
+		t.Fatal(err)
+	}
+	user := &models.User{Name: "bob"}
+	if err := h.User.Create(user); err != nil {
+		t.Fatal(err)
+	}
+	before := &models.Project{Name: "before", OwnerType: constants.OwnerTypeUser, OwnerID: user.ID}
+	if err := h.Project.Create(before); err != nil || before.TenantID != nil {
+		t.Fatalf("Create before Assign = %v, %v", before.TenantID, err)
+	}
+	ids, err := h.Tenant.Assign(constants.OwnerTypeUser, user.ID, &tenant.ID)
+	if err != nil || len(ids) != 1 || ids[0] != before.ID {
+		t.Fatalf("Assign = %v, %v", ids, err)
+	}
+	// 新项目随所有者属于租户 New projects follow their owner into the tenant
+	after := &models.Project{Name: "after", OwnerType: constants.OwnerTypeUser, OwnerID: user.ID}
+	if err := h.Project.Create(after); err != nil || after.TenantID == nil || *after.TenantID != tenant.ID {
+		t.Fatalf("Create after Assign = %v, %v", after.TenantID, err)
+	}
+	if got, err := h.Tenant.OfOwner(constants.OwnerTypeUser, user.ID); err != nil || got == nil || got.ID != tenant.ID {
+		t.Fatalf("OfOwner = %+v, %v", got, err)
+	}
+	if domains, err := h.Tenant.BaseDomains(); err != nil || domains[tenant.ID] != domain {
+		t.Fatalf("BaseDomains = %v, %v", domains, err)
+	}
+	if _, err := h.Tenant.Assign(constants.OwnerTypeUser, user.ID+1, &tenant.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("Assign of a missing user = %v", err)
+	}
+	if ids, err := h.Tenant.Assign(constants.OwnerTypeUser, user.ID, nil); err != nil || len(ids) != 2 {
+		t.Fatalf("Assign back = %v, %v", ids, err)
+	}
+	if got, err := h.Tenant.OfOwner(constants.OwnerTypeUser, user.ID); err != nil || got != nil {
+		t.Fatalf("OfOwner after leaving = %+v, %v", got, err)
+	}
+	if users, orgs, err := h.Tenant.CountMembers(tenant.ID); err != nil || users != 0 || orgs != 0 {
+		t.Fatalf("CountMembers = %d, %d, %v", users, orgs, err)
+	}
+}
+
+func TestRunnerClaimBuild(t *testing.T) {
+	h := openTestHandle(t, "runner")
+	site := seedSite(t, h, &models.Project{Name: "docs"})
+	integration := &models.GitIntegration{SiteID: site.ID, Provider: constants.GitProviderGitHub, RepoURL: "https://example.com/docs.git", Secret: "s"}
+	if err := h.Git.Create(integration); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	queue := func(commit string, labels ...string) *models.RunnerBuild {
+		build := &models.RunnerBuild{SiteID: site.ID, IntegrationID: integration.ID, Commit: commit, Labels: labels}
+		if err := h.Runner.QueueBuild(build, now); err != nil {
+			t.Fatal(err)
+		}
+		return build
+	}
+	runner := &models.Runner{Name: "linux", Hash: "h", Labels: []string{"linux"}, Concurrency: 1}
+	if err := h.Runner.Create(runner); err != nil {
+		t.Fatal(err)
+	}
+
+	// 新的推送使尚未领取的旧构建失败 A newer push fails the older build not taken yet
+	old := queue("a")
+	gpu := queue("b", "linux", "gpu")
+	var status string
+	if err := h.DB().Model(&models.RunnerBuild{}).Where("id = ?", old.ID).Pluck("status", &status).Error; err != nil || status != constants.GitRunFailed {
+		t.Fatalf("superseded build status = %q, %v", status, err)
+	}
+	// 缺少标签时不能领取 Missing labels keep the build unclaimed
+	if build, err := h.Runner.ClaimBuild(runner, now, now.Add(time.Minute)); err != nil || build != nil {
+		t.Fatalf("ClaimBuild without gpu = %+v, %v", build, err)
+	}
+	runner.Labels = append(runner.Labels, "gpu")
+	build, err := h.Runner.ClaimBuild(runner, now, now.Add(time.Minute))
+	if err != nil || build == nil || build.ID != gpu.ID || build.Integration.RepoURL != integration.RepoURL {
+		t.Fatalf("ClaimBuild = %+v, %v", build, err)
+	}
+	// 达到并发数后不再领取 Nothing more is claimed at the concurrency limit
+	queue("c")
+	if next, err := h.Runner.ClaimBuild(runner, now, now.Add(time.Minute)); err != nil || next != nil {
+		t.Fatalf("ClaimBuild at the limit = %+v, %v", next, err)
+	}
+	if running, err := h.Runner.Heartbeat(runner, now, now.Add(time.Hour)); err != nil || len(running) != 1 || running[0] != build.ID {
+		t.Fatalf("Heartbeat = %v, %v", running, err)
+	}
+	if expired, err := h.Runner.ListExpired(now.Add(2*time.Minute), 10); err != nil || len(expired) != 0 {
+		t.Fatalf("ListExpired after heartbeat = %d, %v", len(expired), err)
+	}
+	if expired, err := h.Runner.ListExpired(now.Add(2*time.Hour), 10); err != nil || len(expired) != 1 || expired[0].Integration.Site.ID != site.ID {
+		t.Fatalf("ListExpired = %+v, %v", expired, err)
+	}
+	if ok, err := h.Runner.FinishBuild(build, constants.GitRunSucceeded, "", now); err != nil || !ok {
+		t.Fatalf("FinishBuild = %v, %v", ok, err)
+	}
+	if ok, err := h.Runner.FinishBuild(build, constants.GitRunFailed, "late", now); err != nil || ok {
+		t.Fatalf("second FinishBuild = %v, %v", ok, err)
+	}
+	if next, err := h.Runner.ClaimBuild(runner, now, now.Add(time.Minute)); err != nil || next == nil || next.Commit != "c" {
+		t.Fatalf("ClaimBuild after finishing = %+v, %v", next, err)
+	}
+}
+
+func TestAwaitingApproval(t *testing.T) {
+	h := openTestHandle(t, "approval")
+	ctx := context.Background()
+	projects := make([]*models.Project, 2)
+	releases := make([]*models.SiteRelease, 2)
+	for i, name := range []string{"docs", "blog"} {
+		projects[i] = &models.Project{Name: name, Protected: true}
+		releases[i] = &models.SiteRelease{Tag: "v1", File: models.File{Path: name, Hash: name}, Status: constants.DeploymentStatusAwaitingApproval, ApprovalActivate: true}
+		seedSite(t, h, projects[i], releases[i])
+	}
+
+	// 只列出本项目的版本 Only versions of the project are listed
+	listed, total, err := h.Site.ListAwaitingApproval(ctx, projects[0].ID, 1, 10)
+	if err != nil || total != 1 || len(listed) != 1 || listed[0].ID != releases[0].ID || listed[0].Site.Name != "docs" {
+		t.Fatalf("ListAwaitingApproval = %+v, %d, %v", listed, total, err)
+	}
+	release := releases[0]
+	release.Status = constants.DeploymentStatusReady
+	release.ApprovalActivate = false
+	if saved, err := h.Site.SetApproval(release); err != nil || !saved {
+		t.Fatalf("SetApproval = %v, %v", saved, err)
+	}
+	// 已处理的版本不能再次处理 A decided version cannot be decided again
+	release.Status = constants.DeploymentStatusFailed
+	if saved, err := h.Site.SetApproval(release); err != nil || saved {
+		t.Fatalf("second SetApproval = %v, %v", saved, err)
+	}
+	stored, err := h.Site.GetReleaseById(release.ID)
+	if err != nil || stored.Status != constants.DeploymentStatusReady || stored.ApprovalActivate {
+		t.Fatalf("stored release = %+v, %v", stored, err)
+	}
+	if _, total, err := h.Site.ListAwaitingApproval(ctx, projects[0].ID, 1, 10); err != nil || total != 0 {
+		t.Fatalf("ListAwaitingApproval after approval = %d, %v", total, err)
+	}
+}
+
+func TestCronClaim(t *testing.T) {
+	h := openTestHandle(t, "cron")
+	if err := h.Cron.Ensure([]string{"gc", "backup"}); err != nil {
+		t.Fatal(err)
+	}
+	// 重复创建不报错 Creating again is harmless
+	if err := h.Cron.Ensure([]string{"gc"}); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	stale := now.Add(-time.Hour)
+	next := now.Add(time.Hour)
+	// 禁用的任务不会到期 A disabled task is never due
+	if ok, err := h.Cron.Claim("gc", constants.CronTriggerSchedule, now, stale, &next); err != nil || ok {
+		t.Fatalf("Claim disabled = %v, %v", ok, err)
+	}
+	due := now.Add(-time.Minute)
+	if err := h.Cron.SetSchedule("gc", "@hourly", &due); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := h.Cron.Claim("gc", constants.CronTriggerSchedule, now, stale, &next); err != nil || !ok {
+		t.Fatalf("Claim due = %v, %v", ok, err)
+	}
+	// 同一次运行只能领取一次，运行中也不能手动触发 A run is claimed once and a running task cannot be triggered
+	if ok, err := h.Cron.Claim("gc", constants.CronTriggerSchedule, now, stale, &next); err != nil || ok {
+		t.Fatalf("second Claim = %v, %v", ok, err)
+	}
+	if ok, err := h.Cron.Claim("gc", constants.CronTriggerManual, now, stale, nil); err != nil || ok {
+		t.Fatalf("manual Claim while running = %v, %v", ok, err)
+	}
+	if err := h.Cron.Finish("gc", now, constants.JobSucceeded, "", `{"job_id":1}`); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := h.Cron.Claim("gc", constants.CronTriggerManual, now, stale, nil); err != nil || !ok {
+		t.Fatalf("manual Claim = %v, %v", ok, err)
+	}
+	task, err := h.Cron.Get("gc")
+	if err != nil || task.RunningSince == nil || task.LastTrigger != constants.CronTriggerManual || task.LastStatus != constants.JobSucceeded ||
+		task.NextRunAt == nil || !task.NextRunAt.Equal(next) {
+		t.Fatalf("Get = %+v, %v", task, err)
+	}
+	// 运行过久的任务视为已中断 A run lasting too long counts as abandoned
+	if ok, err := h.Cron.Claim("gc", constants.CronTriggerManual, now.Add(2*time.Hour), now.Add(time.Minute), nil); err != nil || !ok {
+		t.Fatalf("Claim after stale = %v, %v", ok, err)
+	}
+	if tasks, err := h.Cron.List(); err != nil || len(tasks) != 2 || tasks[0].Name != "backup" {
+		t.Fatalf("List = %+v, %v", tasks, err)
+	}
+}
+
+func TestStaleProjectsAndReassign(t *testing.T) {
+	h := openTestHandle(t, "stale")
+	ctx := context.Background()
+	alice, bob := &models.User{Name: "alice"}, &models.User{Name: "bob"}
+	for _, user := range []*models.User{alice, bob} {
+		if err := h.User.Create(user); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := time.Now().AddDate(0, 0, -100)
+	cutoff := time.Now().AddDate(0, 0, -30)
+	projects := map[string]*models.Project{}
+	for _, name := range []string{"idle", "active", "fresh"} {
+		project := &models.Project{Name: name, OwnerType: constants.OwnerTypeUser, OwnerID: alice.ID}
+		if name != "fresh" {
+			project.CreatedAt = old
+		}
+		var releases []*models.SiteRelease
+		if name == "active" {
+			releases = append(releases, &models.SiteRelease{Tag: "v1", File: models.File{Path: name, Hash: name}})
+		}
+		seedSite(t, h, project, releases...)
+		projects[name] = project
+	}
+
+	// 只有创建已久且此后没有部署的项目 Only projects created long ago and not deployed since
+	stale, err := h.Project.ListStale(ctx, StaleProjectFilter{Before: cutoff, OwnerType: constants.OwnerTypeUser, OwnerID: alice.ID})
+	if err != nil || len(stale) != 1 || stale[0].ID != projects["idle"].ID {
+		t.Fatalf("ListStale = %+v, %v", stale, err)
+	}
+	if stale, err := h.Project.ListStale(ctx, StaleProjectFilter{Before: cutoff, OwnerID: bob.ID}); err != nil || len(stale) != 0 {
+		t.Fatalf("ListStale of another owner = %+v, %v", stale, err)
+	}
+
+	project := projects["idle"]
+	transfer := &models.ProjectTransfer{ProjectID: project.ID, OwnerType: constants.OwnerTypeUser, OwnerID: bob.ID, RequestedByID: alice.ID, ExpiresAt: time.Now().Add(time.Hour)}
+	if err := h.Transfer.Request(transfer); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Project.Reassign(project, constants.OwnerTypeUser, bob.ID); err != nil || project.OwnerID != bob.ID {
+		t.Fatalf("Reassign = %+v, %v", project, err)
+	}
+	stored, err := h.Project.GetByID(project.ID)
+	if err != nil || stored.OwnerID != bob.ID {
+		t.Fatalf("reassigned project = %+v, %v", stored, err)
+	}
+	if owned, err := h.Project.ListOwned(ctx, constants.OwnerTypeUser, bob.ID, nil); err != nil || len(owned) != 1 || owned[0].ID != project.ID {
+		t.Fatalf("ListOwned = %+v, %v", owned, err)
+	}
+	if owned, err := h.Project.ListOwned(ctx, constants.OwnerTypeUser, alice.ID, []uint{projects["active"].ID}); err != nil || len(owned) != 1 || owned[0].Name != "active" {
+		t.Fatalf("ListOwned of some projects = %+v, %v", owned, err)
+	}
+	if _, err := h.Transfer.GetByProject(project.ID); !errors.Is(err, ErrTransferInvalid) {
+		t.Fatalf("pending transfer after Reassign = %v", err)
+	}
+	if err := h.Project.Reassign(project, constants.OwnerTypeUser, bob.ID+10); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("Reassign to a missing user = %v", err)
+	}
+}
+
+func TestExpiredVersions(t *testing.T) {
+	h := openTestHandle(t, "retention")
+	ctx := context.Background()
+	old := time.Now().AddDate(0, 0, -60)
+	cutoff := time.Now().AddDate(0, 0, -30)
+	releases := make([]*models.SiteRelease, 5)
+	for i, tag := range []string{"pinned", "active", "waiting", "stale", "new"} {
+		releases[i] = &models.SiteRelease{Tag: tag, File: models.File{Path: tag, Hash: tag}, Status: constants.DeploymentStatusReady, Pinned: tag == "pinned"}
+		releases[i].CreatedAt = old
+		switch tag {
+		case "waiting":
+			releases[i].Status = constants.DeploymentStatusAwaitingApproval
+		case "new":
+			releases[i].CreatedAt = time.Now()
+		}
+	}
+	project := &models.Project{Name: "docs"}
+	site := seedSite(t, h, project, releases...)
+	latest := &models.SiteRelease{SiteID: site.ID, Tag: constants.ReleaseTagLatest, FileID: releases[1].FileID, ActiveReleaseID: &releases[1].ID}
+	if err := h.Site.CreateRelease(ctx, latest); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		keepLast int
+		before   time.Time
+		want     []string
+	}{
+		{1, cutoff, []string{"stale"}},
+		{0, cutoff, []string{"stale"}},
+		{1, time.Time{}, []string{"stale"}},
+		{2, time.Time{}, nil},
+		{0, time.Time{}, []string{"stale", "new"}},
+	} {
+		expired, err := h.Site.ExpiredVersions(ctx, site.ID, c.keepLast, c.before)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var tags []string
+		for _, release := range expired {
+			tags = append(tags, release.Tag)
+		}
+		if !slices.Equal(tags, c.want) {
+			t.Errorf("ExpiredVersions(%d, %v) = %v, want %v", c.keepLast, c.before, tags, c.want)
+		}
+	}
+
+	sites, err := h.Site.ListRetention(0, 10)
+	if err != nil || len(sites) != 1 || sites[0].SiteID != site.ID || sites[0].KeepReleases != nil {
+		t.Fatalf("ListRetention = %+v, %v", sites, err)
+	}
+	keep := 3
+	if err := h.Project.SetRetention(project, &keep, nil); err != nil {
+		t.Fatal(err)
+	}
+	if sites, err := h.Site.ListRetention(0, 10); err != nil || sites[0].KeepReleases == nil || *sites[0].KeepReleases != 3 || sites[0].KeepReleaseDays != nil {
+		t.Fatalf("ListRetention after SetRetention = %+v, %v", sites, err)
+	}
+}
+
+func TestFeedReleases(t *testing.T) {
+	h := openTestHandle(t, "feed")
+	ctx := context.Background()
+	var projectIDs []uint
+	for _, name := range []string{"blog", "wiki"} {
+		var releases []*models.SiteRelease
+		for i, status := range []constants.DeploymentStatus{constants.DeploymentStatusReady, constants.DeploymentStatusFailed, constants.DeploymentStatusReady} {
+			tag := name + "-" + string(rune('a'+i))
+			releases = append(releases, &models.SiteRelease{Tag: tag, File: models.File{Path: tag, Hash: tag}, Status: status, Changelog: "notes of " + tag})
+		}
+		project := &models.Project{Name: name}
+		seedSite(t, h, project, releases...)
+		projectIDs = append(projectIDs, project.ID)
+	}
+
+	releases, err := h.Site.FeedReleases(ctx, projectIDs[:1], 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(releases) != 2 || releases[0].ID < releases[1].ID {
+		t.Fatalf("FeedReleases of one project = %+v", releases)
+	}
+	if releases[0].Site.Project.Name != "blog" || releases[0].Changelog == "" {
+		t.Errorf("site and project are not preloaded: %+v", releases[0])
+	}
+	if releases, err := h.Site.FeedReleases(ctx, projectIDs, 3); err != nil || len(releases) != 3 || releases[0].Site.Project.Name != "wiki" {
+		t.Fatalf("FeedReleases of both projects = %+v, %v", releases, err)
+	}
+	if releases, err := h.Site.FeedReleases(ctx, nil, 3); err != nil || len(releases) != 0 {
+		t.Fatalf("FeedReleases without projects = %+v, %v", releases, err)
+	}
+}
+
+func TestPurgeLimit(t *testing.T) {
+	h := openTestHandle(t, "purge_limit")
+	previous := Lock.db
+	Lock.db = h.DB()
+	t.Cleanup(func() { Lock.db = previous })
+	project := &models.Project{Name: "docs"}
+	site := seedSite(t, h, project)
+	ctx := context.Background()
+	since := time.Now().Add(-time.Hour)
+
+	// 并发的清除不会超出次数限制 Concurrent purges never exceed the limit
+	results := make(chan error, 6)
+	for range 6 {
+		go func() {
+			results <- h.Site.CreatePurge(ctx, &models.SitePathPurge{SiteID: site.ID, ProjectID: project.ID, UserID: 1, Paths: []string{"/a.html"}, Takedown: true}, since, 3)
+		}()
+	}
+	created := 0
+	for range 6 {
+		switch err := <-results; {
+		case err == nil:
+			created++
+		case !errors.Is(err, ErrPurgeLimit):
+			t.Fatal(err)
+		}
+	}
+	if created != 3 {
+		t.Fatalf("created %d purges, want 3", created)
+	}
+
+	// 删除未完成的清除后次数和屏蔽路径一并释放 Deleting an unfinished purge frees its slot and blocked paths
+	purges, _, err := h.Site.ListPurges(ctx, site.ID, 1, 10)
+	if err != nil || len(purges) != 3 {
+		t.Fatalf("ListPurges = %d, %v", len(purges), err)
+	}
+	if err := h.Site.DeletePurge(&purges[0]); err != nil {
+		t.Fatal(err)
+	}
+	if patterns, err := h.Site.ListBlockedPatterns(site.ID); err != nil || len(patterns) != 2 {
+		t.Fatalf("ListBlockedPatterns = %v, %v", patterns, err)
+	}
+	purge := &models.SitePathPurge{SiteID: site.ID, ProjectID: project.ID, UserID: 1, Paths: []string{"/b.html"}}
+	if err := h.Site.CreatePurge(ctx, purge, since, 3); err != nil {
+		t.Fatalf("CreatePurge after DeletePurge = %v", err)
+	}
+	purge.Removed, purge.FromReleaseID, purge.ToReleaseID = 1, 2, 3
+	if err := h.Site.FinishPurge(purge); err != nil {
+		t.Fatal(err)
+	}
+	purges, _, err = h.Site.ListPurges(ctx, site.ID, 1, 10)
+	if err != nil || len(purges) != 3 || purges[0].ID != purge.ID || purges[0].ToReleaseID != 3 {
+		t.Fatalf("ListPurges after FinishPurge = %+v, %v", purges, err)
+	}
+}
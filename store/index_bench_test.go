@@ -0,0 +1,100 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// benchRows 基准测试数据集的行数 Row count of the benchmark dataset
+const benchRows = 1_000_000
+
+// seedBenchDB 创建内存数据库并用递归 CTE 快速写入发布和清除记录
+// Create an in-memory database and seed releases and purges quickly with a recursive CTE
+func seedBenchDB(b *testing.B) *gorm.DB {
+	rows := benchRows
+	if testing.Short() {
+		rows = 10_000
+	}
+	db, err := gorm.Open(sqlite.Open("file:bench?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+	if err := models.Migrate(db); err != nil {
+		b.Fatal(err)
+	}
+	dropIndexes(b, db)
+	seed := []string{
+		`INSERT INTO site_releases (created_at, updated_at, site_id, tag, file_id, status)
+		WITH RECURSIVE seq(n) AS (SELECT 1 UNION ALL SELECT n + 1 FROM seq WHERE n < ?)
+		SELECT datetime('now', '-' || n || ' seconds'), datetime('now'), n % 1000 + 1,
+			CASE WHEN n <= 1000 THEN 'latest' ELSE 'v' || n END, 1, 'ready' FROM seq`,
+		`INSERT INTO site_path_purges (created_at, updated_at, site_id, project_id, user_id, paths)
+		WITH RECURSIVE seq(n) AS (SELECT 1 UNION ALL SELECT n + 1 FROM seq WHERE n < ?)
+		SELECT datetime('now', '-' || n || ' seconds'), datetime('now'), n % 1000 + 1, n % 1000 + 1, 1, '[]' FROM seq`,
+	}
+	for _, stmt := range seed {
+		if err := db.Exec(stmt, rows).Error; err != nil {
+			b.Fatal(err)
+		}
+	}
+	bindRepositories(db)
+	return db
+}
+
+func dropIndexes(b *testing.B, db *gorm.DB) {
+	for _, index := range models.Indexes {
+		if err := db.Exec("DROP INDEX IF EXISTS " + index.Name).Error; err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkHotQueries 对比热点查询在建立注册索引前后的耗时
+// Compare hot queries before and after creating the registered indexes
+func BenchmarkHotQueries(b *testing.B) {
+	db := seedBenchDB(b)
+	site := &models.Site{Model: gorm.Model{ID: 42}}
+	since := time.Now().Add(-time.Hour)
+	queries := []struct {
+		name string
+		run  func() error
+	}{
+		{"LatestRelease", func() error {
+			_, err := Site.GetLatestRelease(site)
+			return err
+		}},
+		{"ReleaseList", func() error {
+			_, err := Site.GetReleaseList(site.ID)
+			return err
+		}},
+		{"CountPurgesSince", func() error {
+			_, err := Site.CountPurgesSince(site.ID, since)
+			return err
+		}},
+	}
+	for _, indexed := range []bool{false, true} {
+		name := "WithoutIndexes"
+		if indexed {
+			name = "WithIndexes"
+			if err := models.EnsureIndexes(db); err != nil {
+				b.Fatal(err)
+			}
+		}
+		for _, query := range queries {
+			b.Run(name+"/"+query.name, func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					if err := query.run(); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package store
+
+import (
+	"errors"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvitationInvalid 邀请不存在、已过期或次数已用完
+// The invitation does not exist, has expired or has no uses left
+var ErrInvitationInvalid = errors.New("invalid or expired invitation")
+
+type invitationType struct {
+	db *gorm.DB
+}
+
+// Invitation 注册邀请
+// Registration invitations
+var Invitation = invitationType{
+	db: DB,
+}
+
+// Create 创建邀请
+// Create an invitation
+func (i *invitationType) Create(invitation *models.Invitation) error {
+	return i.db.Omit(clause.Associations).Create(invitation).Error
+}
+
+// GetByID 根据 ID 获取邀请
+// Get an invitation by ID
+func (i *invitationType) GetByID(id uint) (*models.Invitation, error) {
+	invitation := &models.Invitation{}
+	if err := i.db.Preload("Organization").First(invitation, id).Error; err != nil {
+		return nil, err
+	}
+	return invitation, nil
+}
+
+// GetByToken 根据令牌明文获取仍可使用的邀请
+// Get a still usable invitation by its plain token
+func (i *invitationType) GetByToken(plain string) (*models.Invitation, error) {
+	invitation := &models.Invitation{}
+	if err := i.db.Preload("Organization").Preload("CreatedBy").Where("hash = ?", utils.Token.HashAPIToken(plain)).First(invitation).Error; err != nil {
+		return nil, ErrInvitationInvalid
+	}
+	if !invitation.Usable() {
+		return nil, ErrInvitationInvalid
+	}
+	return invitation, nil
+}
+
+// List 获取邀请，orgID 为空时返回所有邀请
+// List invitations, all of them when orgID is nil
+func (i *invitationType) List(orgID *uint) (invitations []models.Invitation, err error) {
+	query := i.db.Preload("Organization").Preload("CreatedBy").Order("id DESC")
+	if orgID != nil {
+		query = query.Where("organization_id = ?", *orgID)
+	}
+	err = query.Find(&invitations).Error
+	return
+}
+
+// Revoke 撤销邀请
+// Revoke an invitation
+func (i *invitationType) Revoke(invitation *models.Invitation) error {
+	return i.db.Delete(invitation).Error
+}
+
+// Register 使用邀请创建用户，用户创建和邀请计数在同一事务中完成
+// Create a user with an invitation, creating the user and counting the use happen in one transaction
+func (i *invitationType) Register(invitation *models.Invitation, user *models.User) error {
+	return i.db.Transaction(func(tx *gorm.DB) error {
+		// 受邀加入组织的新用户属于组织所在的租户 New users invited into an organization belong to its tenant
+		if invitation.OrganizationID != nil && user.TenantID == nil {
+			tenantID, err := ownerTenantID(tx, constants.OwnerTypeOrg, *invitation.OrganizationID)
+			if err != nil {
+				return err
+			}
+			user.TenantID = tenantID
+		}
+		if err := tx.Omit(clause.Associations).Create(user).Error; err != nil {
+			return err
+		}
+		return useInvitation(tx, invitation, user.ID)
+	})
+}
+
+// Accept 已有用户使用邀请加入组织
+// An existing user uses an invitation to join its organization
+func (i *invitationType) Accept(invitation *models.Invitation, userID uint) error {
+	return i.db.Transaction(func(tx *gorm.DB) error {
+		return useInvitation(tx, invitation, userID)
+	})
+}
+
+// useInvitation 条件累加使用次数，保证并发使用不超过上限，并按邀请加入组织
+// Count a use with a conditional update so concurrent uses stay within the limit, and join the invitation's organization
+func useInvitation(tx *gorm.DB, invitation *models.Invitation, userID uint) error {
+	result := tx.Model(&models.Invitation{}).
+		Where("id = ? AND uses < max_uses AND expires_at > ?", invitation.ID, time.Now()).
+		Update("uses", gorm.Expr("uses + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvitationInvalid
+	}
+	invitation.Uses++
+	if invitation.OrganizationID == nil {
+		return nil
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "organization_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role"}),
+	}).Create(&models.OrgMember{OrganizationID: *invitation.OrganizationID, UserID: userID, Role: invitation.Role, CreatedAt: time.Now()}).Error
+}
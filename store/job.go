@@ -0,0 +1,153 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/models"
+	"gorm.io/gorm"
+)
+
+type jobType struct {
+	db *gorm.DB
+}
+
+// Job 后台任务队列
+// Background job queue
+var Job = jobType{
+	db: DB,
+}
+
+// Create 创建后台任务
+// Create a background job
+func (j *jobType) Create(job *models.Job) error {
+	return j.db.Create(job).Error
+}
+
+// GetByID 获取后台任务
+// Get a background job
+func (j *jobType) GetByID(id uint) (job *models.Job, err error) {
+	job = &models.Job{}
+	err = j.db.First(job, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// GetBySite 获取站点的后台任务
+// Get a background job of a site
+func (j *jobType) GetBySite(siteID, id uint) (job *models.Job, err error) {
+	job = &models.Job{}
+	err = j.db.Where("id = ? AND site_id = ?", id, siteID).First(job).Error
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// List 分页获取后台任务，status 和 kind 为空时不过滤
+// List background jobs with pagination, empty status and kind do not filter
+func (j *jobType) List(ctx context.Context, status, kind string, siteID uint, page, limit int) (jobs []models.Job, total int64, err error) {
+	query := j.db.WithContext(ctx)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if kind != "" {
+		query = query.Where("type = ?", kind)
+	}
+	if siteID != 0 {
+		query = query.Where("site_id = ?", siteID)
+	}
+	return Paginate[models.Job](query, page, limit)
+}
+
+// ListDue 获取已到执行时间的任务，包括租约已过期的运行中任务，即执行它的实例已经退出
+// List jobs due to run, including running jobs whose lease expired because the instance running them went away
+func (j *jobType) ListDue(now time.Time, limit int) (jobs []models.Job, err error) {
+	err = j.db.Where("status IN ? AND run_at <= ?", []string{constants.JobPending, constants.JobRunning}, now).
+		Order("run_at").Limit(limit).Find(&jobs).Error
+	return
+}
+
+// Claim 领取一次执行并设置租约，多个实例同时领取时只有一个成功
+// Claim an attempt and set its lease, only one instance succeeds when several claim at once
+func (j *jobType) Claim(job *models.Job, now, until time.Time) (bool, error) {
+	result := j.db.Model(&models.Job{}).
+		Where("id = ? AND status = ? AND attempts = ?", job.ID, job.Status, job.Attempts).
+		Updates(map[string]any{"status": constants.JobRunning, "attempts": job.Attempts + 1, "run_at": until, "started_at": now})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	job.Status = constants.JobRunning
+	job.Attempts++
+	job.RunAt = until
+	job.StartedAt = &now
+	return true, nil
+}
+
+// SaveAttempt 保存一次执行的结果
+// Save the outcome of an attempt
+func (j *jobType) SaveAttempt(job *models.Job) error {
+	return j.db.Model(job).Select("status", "run_at", "finished_at", "result", "error").Updates(job).Error
+}
+
+// SetProgress 保存执行中任务的阶段和进度
+// Save the stage and progress of a running job
+func (j *jobType) SetProgress(job *models.Job) error {
+	return j.db.Model(job).Select("stage", "progress", "progress_total").Updates(job).Error
+}
+
+// Retry 将失败或已取消的任务重新加入队列，尝试次数从零开始
+// Queue a failed or canceled job again, starting over with zero attempts
+func (j *jobType) Retry(job *models.Job, now time.Time) (bool, error) {
+	result := j.db.Model(&models.Job{}).
+		Where("id = ? AND status IN ?", job.ID, []string{constants.JobFailed, constants.JobCanceled}).
+		Updates(map[string]any{"status": constants.JobPending, "attempts": 0, "run_at": now, "finished_at": nil, "error": ""})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+	return true, nil
+}
+
+// Cancel 取消尚未开始执行的任务
+// Cancel a job that has not started running
+func (j *jobType) Cancel(job *models.Job, now time.Time) (bool, error) {
+	result := j.db.Model(&models.Job{}).
+		Where("id = ? AND status = ?", job.ID, constants.JobPending).
+		Updates(map[string]any{"status": constants.JobCanceled, "finished_at": now})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+	return true, nil
+}
+
+// DeleteFinished 删除结束时间早于 before 的任务记录
+// Delete the records of jobs finished before the given time
+func (j *jobType) DeleteFinished(before time.Time) (int64, error) {
+	result := j.db.Where("status IN ? AND finished_at <= ?", []string{constants.JobSucceeded, constants.JobFailed, constants.JobCanceled}, before).
+		Delete(&models.Job{})
+	return result.RowsAffected, result.Error
+}
+
+// ListUnfinished 获取指定类型中等待执行或运行中的任务
+// List the pending or running jobs of a type
+func (j *jobType) ListUnfinished(kind string) (jobs []models.Job, err error) {
+	err = j.db.Where("type = ? AND status IN ?", kind, []string{constants.JobPending, constants.JobRunning}).Find(&jobs).Error
+	return
+}
+
+// Latest 获取指定类型最近创建的任务
+// Get the most recently created job of a type
+func (j *jobType) Latest(kind string) (job *models.Job, err error) {
+	job = &models.Job{}
+	err = j.db.Where("type = ?", kind).Order("id DESC").First(job).Error
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
@@ -1,52 +1,127 @@
 package store
 
-import "github.com/LiteyukiStudio/spage/models"
+import (
+	"context"
+	"strconv"
+	"time"
 
-type JWTType struct{}
+	"github.com/LiteyukiStudio/spage/cache"
+	"github.com/LiteyukiStudio/spage/models"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
 
-var JWT = JWTType{}
+type jwtType struct {
+	db *gorm.DB
+}
 
-// CreateToken 创建令牌
-// Create Token
-func (JWTType) CreateToken(userID uint) (*models.Token, error) {
-	token := &models.Token{
-		UserID: userID,
-	}
-	if err := DB.Create(token).Error; err != nil {
+var JWT = jwtType{
+	db: DB,
+}
+
+// CreateSession 创建登录会话
+// Create a sign-in session
+func (j *jwtType) CreateSession(session *models.Token) error {
+	return j.db.Create(session).Error
+}
+
+// GetByRefreshHash 根据刷新令牌的哈希获取未过期的会话
+// Get an unexpired session by the hash of its refresh token
+func (j *jwtType) GetByRefreshHash(hash string) (*models.Token, error) {
+	session := &models.Token{}
+	// 撤销必须立即生效，因此从主库读取 Revocation must take effect immediately, so read from the primary
+	err := j.db.Clauses(dbresolver.Write).Where("refresh_hash = ? AND expires_at > ?", hash, time.Now()).First(session).Error
+	if err != nil {
 		return nil, err
 	}
-	return token, nil
+	return session, nil
+}
+
+// Touch 记录会话的最近使用时间和客户端
+// Record the last use and client of a session
+func (j *jwtType) Touch(session *models.Token, ip, userAgent string) error {
+	session.LastUsedAt, session.IP, session.UserAgent = time.Now(), ip, userAgent
+	return j.db.Model(session).Select("last_used_at", "ip", "user_agent").Updates(session).Error
+}
+
+// ListSessions 获取用户未过期的会话，最近使用的在前
+// List the unexpired sessions of a user, most recently used first
+func (j *jwtType) ListSessions(userID uint) (sessions []models.Token, err error) {
+	err = j.db.Where("user_id = ? AND expires_at > ?", userID, time.Now()).Order("last_used_at DESC, id DESC").Find(&sessions).Error
+	return
+}
+
+// DeleteExpired 彻底删除已过期或已撤销的会话
+// Permanently delete expired or revoked sessions
+func (j *jwtType) DeleteExpired(now time.Time) (int64, error) {
+	result := j.db.Unscoped().Where("expires_at < ? OR deleted_at IS NOT NULL", now).Delete(&models.Token{})
+	return result.RowsAffected, result.Error
 }
 
-// IsTokenRevoked 检查令牌是否被撤销
-// Check if a token has been revoked
-func (JWTType) IsTokenRevoked(tokenID uint) bool {
-	var count int64
-	// 查询是否存在该令牌（未被删除的）
-	// Check if the token exists (not deleted)
-	err := DB.Model(&models.Token{}).Where("id = ?", tokenID).Count(&count).Error
-	// 如果查询出错或找不到令牌，默认视为已撤销（安全优先）
-	// If the query fails or no token is found, assume it's revoked (safety first)
-	if err != nil || count == 0 {
+// sessionKey 有效会话令牌的缓存键
+// Cache key of a valid session token
+func sessionKey(tokenID uint) string {
+	return "session:" + strconv.FormatUint(uint64(tokenID), 10)
+}
+
+// IsTokenRevoked 检查会话是否被撤销或已过期，有效的会话会被缓存到过期为止，撤销时同步删除缓存
+// Check if a session has been revoked or expired, valid sessions are cached until they expire and the entries are deleted on revocation
+func (j *jwtType) IsTokenRevoked(tokenID uint) bool {
+	ctx := context.Background()
+	var valid bool
+	if cache.GetJSON(ctx, sessionKey(tokenID), &valid) == nil && valid {
+		return false
+	}
+	session := models.Token{}
+	// 查询是否存在该会话（未被删除的）
+	// Check if the session exists (not deleted)
+	// 撤销必须立即生效，因此从主库读取 Revocation must take effect immediately, so read from the primary
+	err := j.db.Clauses(dbresolver.Write).Select("id", "expires_at").Where("id = ?", tokenID).Limit(1).Find(&session).Error
+	// 如果查询出错或找不到会话，默认视为已撤销（安全优先）
+	// If the query fails or no session is found, assume it's revoked (safety first)
+	if err != nil || session.ID == 0 {
 		return true
 	}
+	ttl := time.Until(session.ExpiresAt)
+	if ttl <= 0 {
+		return true
+	}
+	_ = cache.SetJSON(ctx, sessionKey(tokenID), true, ttl)
 	return false
 }
 
-// RevokeTokenByID 撤销令牌
-// Revoke Token
-func (JWTType) RevokeTokenByID(id uint) error {
-	if err := DB.Where("id = ?", id).Delete(&models.Token{}).Error; err != nil {
+// RevokeTokenByID 撤销会话
+// Revoke a session
+func (j *jwtType) RevokeTokenByID(id uint) error {
+	if err := j.db.Where("id = ?", id).Delete(&models.Token{}).Error; err != nil {
 		return err
 	}
-	return nil
+	return cache.Delete(context.Background(), sessionKey(id))
 }
 
-// RevokeTokenByUserID 撤销用户的所有令牌
-// Revoke all tokens for a user
-func (JWTType) RevokeTokenByUserID(userID uint) error {
-	if err := DB.Where("user_id = ?", userID).Delete(&models.Token{}).Error; err != nil {
+// RevokeSession 撤销用户自己的会话，会话不属于该用户时返回 false
+// Revoke a session of the user, returning false when the session belongs to someone else
+func (j *jwtType) RevokeSession(userID, id uint) (bool, error) {
+	result := j.db.Where("id = ? AND user_id = ?", id, userID).Delete(&models.Token{})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+	return true, cache.Delete(context.Background(), sessionKey(id))
+}
+
+// RevokeTokenByUserID 撤销用户的所有会话
+// Revoke all sessions for a user
+func (j *jwtType) RevokeTokenByUserID(userID uint) error {
+	var ids []uint
+	if err := j.db.Model(&models.Token{}).Where("user_id = ?", userID).Pluck("id", &ids).Error; err != nil {
 		return err
 	}
-	return nil
+	if err := j.db.Where("user_id = ?", userID).Delete(&models.Token{}).Error; err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, sessionKey(id))
+	}
+	return cache.Delete(context.Background(), keys...)
 }
@@ -0,0 +1,113 @@
+package store
+
+import (
+	"context"
+	"hash/fnv"
+	"strconv"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// 锁名称 Lock names
+const (
+	LockBootstrap  = "bootstrap"   // 启动时的迁移和管理员初始化 Migrations and the admin bootstrap at startup
+	LockGCSchedule = "gc-schedule" // 安排存储垃圾回收任务 Scheduling storage garbage collection jobs
+	LockStorageGC  = "storage-gc"  // 执行存储垃圾回收 Running storage garbage collection
+)
+
+type lockType struct {
+	db        *gorm.DB
+	namespace string // 锁所属的 schema，为空时为默认 schema Schema the locks belong to, empty for the default one
+	mu        sync.Mutex
+	local     map[string]*sync.Mutex
+}
+
+// Lock 跨实例的互斥锁，Postgres 上使用 advisory lock，让共用数据库的多个实例串行执行；SQLite 只能由一个实例使用，使用进程内的锁
+// Mutual exclusion across instances, using advisory locks on Postgres so instances sharing the database take turns; SQLite serves one instance only and uses in-process locks
+var Lock = lockType{
+	db:    DB,
+	local: make(map[string]*sync.Mutex),
+}
+
+// LockKey 由名称和可选的 ID 组成锁名称，例如站点的部署激活
+// Build a lock name from a name and an optional ID, such as the deployment activation of a site
+func LockKey(name string, id uint) string {
+	return name + ":" + strconv.FormatUint(uint64(id), 10)
+}
+
+// advisoryKey 将锁名称映射为 Postgres advisory lock 使用的 64 位整数，advisory lock 对整个数据库生效，因此配置了 schema 时将其计入
+// Map a lock name to the 64-bit integer used by Postgres advisory locks, which span the whole database so a configured schema is part of it
+func (l *lockType) advisoryKey(name string) int64 {
+	h := fnv.New64a()
+	if l.namespace != "" {
+		name = l.namespace + ":" + name
+	}
+	_, _ = h.Write([]byte("spage:" + name))
+	return int64(h.Sum64())
+}
+
+func (l *lockType) postgres() bool {
+	return l.db.Dialector.Name() == "postgres"
+}
+
+// mutex 返回名称对应的进程内锁
+// Return the in-process lock of the name
+func (l *lockType) mutex(name string) *sync.Mutex {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	m := l.local[name]
+	if m == nil {
+		m = &sync.Mutex{}
+		l.local[name] = m
+	}
+	return m
+}
+
+// With 持有锁执行 fn，其他实例等待直到锁释放；Postgres 上 fn 收到的 db 使用持有锁的连接，连接池只有一个连接时也不会死锁
+// Run fn holding the lock, other instances wait until it is released; on Postgres the db passed to fn uses the connection holding the lock, so a pool of one connection cannot deadlock
+func (l *lockType) With(ctx context.Context, name string, fn func(db *gorm.DB) error) error {
+	if !l.postgres() {
+		m := l.mutex(name)
+		m.Lock()
+		defer m.Unlock()
+		return fn(l.db.WithContext(ctx))
+	}
+	sqlDB, err := l.db.DB()
+	if err != nil {
+		return err
+	}
+	// 会话级的锁属于连接，加锁和解锁必须使用同一个连接 Session locks belong to a connection, locking and unlocking must use the same one
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	key := l.advisoryKey(name)
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", key); err != nil {
+		return err
+	}
+	defer func() {
+		_, _ = conn.ExecContext(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", key)
+	}()
+	tx := l.db.Session(&gorm.Session{NewDB: true, Context: ctx})
+	tx.Statement.ConnPool = conn
+	return fn(tx)
+}
+
+// Tx 在事务中持有锁执行 fn，事务结束时锁自动释放
+// Run fn in a transaction holding the lock, which is released when the transaction ends
+func (l *lockType) Tx(ctx context.Context, name string, fn func(tx *gorm.DB) error) error {
+	if !l.postgres() {
+		m := l.mutex(name)
+		m.Lock()
+		defer m.Unlock()
+		return l.db.WithContext(ctx).Transaction(fn)
+	}
+	return l.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", l.advisoryKey(name)).Error; err != nil {
+			return err
+		}
+		return fn(tx)
+	})
+}
@@ -0,0 +1,63 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	gormutils "gorm.io/gorm/utils"
+)
+
+// gormLogger 通过 logrus 输出 GORM 日志，上下文中有请求ID时附带 request_id 字段
+// Route GORM logs through logrus, adding the request_id field when the context carries a request ID
+type gormLogger struct {
+	slow time.Duration // 慢语句的阈值，0 表示不记录 Threshold of slow statements, 0 disables them
+}
+
+func (l gormLogger) LogMode(logger.LogLevel) logger.Interface {
+	// 级别由 log.level 统一控制 The level is controlled by log.level
+	return l
+}
+
+func (gormLogger) Info(ctx context.Context, msg string, args ...any) {
+	utils.Log.Ctx(ctx).WithField("source", gormutils.FileWithLineNum()).Infof(msg, args...)
+}
+
+func (gormLogger) Warn(ctx context.Context, msg string, args ...any) {
+	utils.Log.Ctx(ctx).WithField("source", gormutils.FileWithLineNum()).Warnf(msg, args...)
+}
+
+func (gormLogger) Error(ctx context.Context, msg string, args ...any) {
+	utils.Log.Ctx(ctx).WithField("source", gormutils.FileWithLineNum()).Errorf(msg, args...)
+}
+
+// Trace 记录语句，失败的语句为错误，慢语句为警告，其余仅在 debug 级别输出；caller 为发起语句的处理函数或后台任务
+// Log a statement, failures as errors, slow statements as warnings and the rest only at debug level;
+// caller is the handler or background task that issued the statement
+func (l gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	elapsed := time.Since(begin)
+	failed := err != nil && !errors.Is(err, gorm.ErrRecordNotFound)
+	slow := l.slow > 0 && elapsed > l.slow
+	if !failed && !slow && !logrus.IsLevelEnabled(logrus.DebugLevel) {
+		return
+	}
+	sql, rows := fc()
+	entry := utils.Log.Ctx(ctx).WithFields(logrus.Fields{
+		"source":     gormutils.FileWithLineNum(),
+		"caller":     queryCaller(),
+		"elapsed_ms": float64(elapsed.Microseconds()) / 1000,
+		"rows":       rows,
+	})
+	switch {
+	case failed:
+		entry.WithError(err).Error(sql)
+	case slow:
+		entry.Warn(sql)
+	default:
+		entry.Debug(sql)
+	}
+}
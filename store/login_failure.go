@@ -0,0 +1,63 @@
+package store
+
+import (
+	"time"
+
+	"github.com/LiteyukiStudio/spage/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type loginFailureType struct {
+	db *gorm.DB
+}
+
+// LoginFailure 按账号和客户端 IP 统计的连续登录失败
+// Consecutive failed logins counted by account and client IP
+var LoginFailure = loginFailureType{
+	db: DB,
+}
+
+// Get 获取账号或 IP 的连续失败，没有记录时返回零值
+// Get the consecutive failures of an account or IP, the zero value when there are none
+func (l *loginFailureType) Get(kind, subject string) (failure models.LoginFailure, err error) {
+	err = l.db.Where("kind = ? AND subject = ?", kind, subject).Limit(1).Find(&failure).Error
+	return
+}
+
+// Fail 记录一次失败并返回累计后的记录，最近一次失败早于 since 时重新计数
+// Record a failure and return the updated record, counting afresh when the latest failure is before since
+func (l *loginFailureType) Fail(kind, subject string, now, since time.Time) (failure models.LoginFailure, err error) {
+	err = l.db.Transaction(func(tx *gorm.DB) error {
+		failures := gorm.Expr("CASE WHEN login_failures.last_failed_at < ? THEN 1 ELSE login_failures.failures + 1 END", since)
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "kind"}, {Name: "subject"}},
+			DoUpdates: clause.Assignments(map[string]any{"failures": failures, "last_failed_at": now}),
+		}).Create(&models.LoginFailure{Kind: kind, Subject: subject, Failures: 1, LastFailedAt: now}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("kind = ? AND subject = ?", kind, subject).First(&failure).Error
+	})
+	return
+}
+
+// Lock 锁定账号或 IP 到 until 并清零失败次数，解锁后重新计数
+// Lock an account or IP until the given time and clear its failures, which are counted afresh once unlocked
+func (l *loginFailureType) Lock(kind, subject string, until time.Time) error {
+	return l.db.Model(&models.LoginFailure{}).Where("kind = ? AND subject = ?", kind, subject).
+		Updates(map[string]any{"failures": 0, "locked_until": until}).Error
+}
+
+// Reset 删除账号或 IP 的失败记录，登录成功或管理员解锁时调用
+// Delete the failures of an account or IP, called on a successful login or when an administrator unlocks it
+func (l *loginFailureType) Reset(kind, subject string) error {
+	return l.db.Where("kind = ? AND subject = ?", kind, subject).Delete(&models.LoginFailure{}).Error
+}
+
+// DeleteBefore 删除最近一次失败早于 before 且未锁定的记录
+// Delete records whose latest failure is before the given time and that are not locked
+func (l *loginFailureType) DeleteBefore(before, now time.Time) (int64, error) {
+	result := l.db.Where("last_failed_at < ? AND (locked_until IS NULL OR locked_until < ?)", before, now).Delete(&models.LoginFailure{})
+	return result.RowsAffected, result.Error
+}
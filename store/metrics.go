@@ -0,0 +1,234 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/metrics"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const queryStartKey = "metrics:query_start"
+
+var (
+	queryDuration = metrics.NewHistogramVec("spage_db_query_duration_seconds",
+		"Duration of GORM statements by operation and table.", nil, "operation", "table")
+	queryErrors = metrics.NewCounterVec("spage_db_query_errors_total",
+		"GORM statements that failed, not counting record not found.", "operation", "table")
+	callerDuration = metrics.NewHistogramVec("spage_db_caller_duration_seconds",
+		"Duration of GORM statements by the handler or background task issuing them.", nil, "caller")
+)
+
+// modulePath 本项目的模块路径，用于从调用栈中找出发起语句的函数
+// Module path of the project, used to find the function issuing a statement in the call stack
+const modulePath = "github.com/LiteyukiStudio/spage/"
+
+// callerNames 调用栈中函数地址到发起者名称的缓存，空字符串表示不是发起者
+// Cache of call stack addresses to caller names, empty for functions that are not callers
+var callerNames sync.Map
+
+func init() {
+	metrics.NewGaugeFunc("spage_db_connections", "Connections of the primary database pool by state.", func(emit func(float64, ...string)) {
+		if stats, ok := poolStats(); ok {
+			emit(float64(stats.InUse), "in_use")
+			emit(float64(stats.Idle), "idle")
+			emit(float64(stats.MaxOpenConnections), "max_open")
+		}
+	}, "state")
+	metrics.NewCounterFunc("spage_db_wait_count_total", "Connections waited for because the pool was exhausted.", func(emit func(float64, ...string)) {
+		if stats, ok := poolStats(); ok {
+			emit(float64(stats.WaitCount))
+		}
+	})
+	metrics.NewCounterFunc("spage_db_wait_duration_seconds_total", "Time spent waiting for pool connections.", func(emit func(float64, ...string)) {
+		if stats, ok := poolStats(); ok {
+			emit(stats.WaitDuration.Seconds())
+		}
+	})
+	metrics.NewCounterFunc("spage_db_closed_connections_total", "Connections closed by the pool by reason.", func(emit func(float64, ...string)) {
+		if stats, ok := poolStats(); ok {
+			emit(float64(stats.MaxIdleClosed), "max_idle")
+			emit(float64(stats.MaxIdleTimeClosed), "max_idle_time")
+			emit(float64(stats.MaxLifetimeClosed), "max_lifetime")
+		}
+	}, "reason")
+
+	metrics.NewGaugeFunc("spage_sites_active", "Sites currently serving an active version.", func(emit func(float64, ...string)) {
+		gaugeCount(emit, "active sites", func(h *Handle) (int64, error) { return h.Site.CountActive() })
+	})
+	metrics.NewGaugeFunc("spage_previews_active", "Preview deployments that have not expired.", func(emit func(float64, ...string)) {
+		gaugeCount(emit, "active previews", func(h *Handle) (int64, error) { return h.Preview.CountActive(time.Now()) })
+	})
+	metrics.NewGaugeFunc("spage_storage_files", "Stored release archives and manifests.", func(emit func(float64, ...string)) {
+		gaugeCount(emit, "stored files", func(h *Handle) (int64, error) {
+			count, _, err := h.File.TotalSize()
+			return count, err
+		})
+	})
+	metrics.NewGaugeFunc("spage_storage_blobs", "Site files stored by content hash.", func(emit func(float64, ...string)) {
+		gaugeCount(emit, "stored blobs", func(h *Handle) (int64, error) {
+			count, _, err := h.Blob.TotalSize()
+			return count, err
+		})
+	})
+	metrics.NewGaugeFunc("spage_storage_bytes", "Bytes used by stored release archives, manifests and site files.", func(emit func(float64, ...string)) {
+		gaugeCount(emit, "storage bytes", func(h *Handle) (int64, error) {
+			_, fileBytes, err := h.File.TotalSize()
+			if err != nil {
+				return 0, err
+			}
+			_, blobBytes, err := h.Blob.TotalSize()
+			return fileBytes + blobBytes, err
+		})
+	})
+}
+
+// poolStats 主库连接池状态，数据库尚未连接时返回 false
+// Stats of the primary pool, false before the database is connected
+func poolStats() (stats sql.DBStats, ok bool) {
+	h := Default
+	if h == nil || h.DB() == nil {
+		return stats, false
+	}
+	sqlDB, err := h.DB().DB()
+	if err != nil {
+		return stats, false
+	}
+	return sqlDB.Stats(), true
+}
+
+// gaugeCount 抓取时执行统计查询，数据库未连接或查询失败时不输出样本
+// Run a counting query at scrape time, emitting no sample before the database is connected or when the query fails
+func gaugeCount(emit func(float64, ...string), what string, count func(h *Handle) (int64, error)) {
+	h := Default
+	if h == nil || h.DB() == nil {
+		return
+	}
+	value, err := count(h)
+	if err != nil {
+		logrus.Warnf("failed to count %s for metrics: %v", what, err)
+		return
+	}
+	emit(float64(value))
+}
+
+// queryMetrics 记录每条 GORM 语句耗时的插件
+// Plugin recording the duration of every GORM statement
+type queryMetrics struct{}
+
+func (queryMetrics) Name() string {
+	return "spage:metrics"
+}
+
+func (queryMetrics) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("metrics:before_create", startQuery),
+		cb.Create().After("gorm:create").Register("metrics:after_create", finishQuery("create")),
+		cb.Query().Before("gorm:query").Register("metrics:before_query", startQuery),
+		cb.Query().After("gorm:query").Register("metrics:after_query", finishQuery("query")),
+		cb.Update().Before("gorm:update").Register("metrics:before_update", startQuery),
+		cb.Update().After("gorm:update").Register("metrics:after_update", finishQuery("update")),
+		cb.Delete().Before("gorm:delete").Register("metrics:before_delete", startQuery),
+		cb.Delete().After("gorm:delete").Register("metrics:after_delete", finishQuery("delete")),
+		cb.Row().Before("gorm:row").Register("metrics:before_row", startQuery),
+		cb.Row().After("gorm:row").Register("metrics:after_row", finishQuery("row")),
+		cb.Raw().Before("gorm:raw").Register("metrics:before_raw", startQuery),
+		cb.Raw().After("gorm:raw").Register("metrics:after_raw", finishQuery("raw")),
+	)
+}
+
+func startQuery(db *gorm.DB) {
+	db.InstanceSet(queryStartKey, time.Now())
+}
+
+func finishQuery(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(queryStartKey)
+		if !ok {
+			return
+		}
+		start, ok := value.(time.Time)
+		if !ok {
+			return
+		}
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+		elapsed := time.Since(start).Seconds()
+		queryDuration.Observe(elapsed, operation, table)
+		callerDuration.Observe(elapsed, queryCaller())
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			queryErrors.Inc(operation, table)
+		}
+	}
+}
+
+// queryCaller 发起当前语句的处理函数或后台任务，即调用栈中第一个不属于 store 包的本项目函数，找不到时为 unknown
+// Handler or background task issuing the current statement, the first function of the project outside the store package in the call stack, unknown when there is none
+func queryCaller() string {
+	var pcs [64]uintptr
+	n := runtime.Callers(3, pcs[:])
+	for _, pc := range pcs[:n] {
+		if name, ok := callerNames.Load(pc); ok {
+			if name != "" {
+				return name.(string)
+			}
+			continue
+		}
+		name := ""
+		if fn := runtime.FuncForPC(pc - 1); fn != nil {
+			name = callerName(fn.Name())
+		}
+		callerNames.Store(pc, name)
+		if name != "" {
+			return name
+		}
+	}
+	return "unknown"
+}
+
+// callerName 将运行时函数名转换为发起者名称，例如 handlers.SiteApi.Get，不属于本项目或属于 store 包的函数返回空字符串；
+// 闭包归入定义它的函数，指针接收者去掉括号，以限制标签数量
+// Turn a runtime function name into a caller name such as handlers.SiteApi.Get, empty for functions outside the project or in the store package;
+// closures count as the function defining them and pointer receivers lose their parentheses to bound label cardinality
+func callerName(fn string) string {
+	name, ok := strings.CutPrefix(fn, modulePath)
+	if !ok {
+		return ""
+	}
+	slash := strings.LastIndex(name, "/") + 1
+	dot := strings.Index(name[slash:], ".")
+	if dot < 0 || name[:slash+dot] == "store" {
+		return ""
+	}
+	name = strings.NewReplacer("(*", "", ")", "", "[...]", "").Replace(name)
+	parts := strings.Split(name, ".")
+	for len(parts) > 2 && isClosurePart(parts[len(parts)-1]) {
+		parts = parts[:len(parts)-1]
+	}
+	return strings.Join(parts, ".")
+}
+
+// isClosurePart 函数名的一段是否为编译器为闭包生成的后缀，例如 func1、gowrap2 或 3
+// Whether a part of a function name is a suffix the compiler generates for closures, such as func1, gowrap2 or 3
+func isClosurePart(part string) bool {
+	for _, prefix := range []string{"func", "gowrap", "deferwrap"} {
+		if rest, ok := strings.CutPrefix(part, prefix); ok && rest != "" {
+			part = rest
+			break
+		}
+	}
+	for _, r := range part {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return part != ""
+}
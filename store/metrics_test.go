@@ -0,0 +1,26 @@
+package store
+
+import "testing"
+
+func TestCallerName(t *testing.T) {
+	cases := []struct {
+		fn, want string
+	}{
+		{"github.com/LiteyukiStudio/spage/handlers.SiteApi.Get", "handlers.SiteApi.Get"},
+		{"github.com/LiteyukiStudio/spage/handlers.NotificationApi.Deliveries.func1", "handlers.NotificationApi.Deliveries"},
+		{"github.com/LiteyukiStudio/spage/handlers.newGraphQLScope.func3.1", "handlers.newGraphQLScope"},
+		{"github.com/LiteyukiStudio/spage/task.(*worker).run", "task.worker.run"},
+		{"github.com/LiteyukiStudio/spage/task.deliverWebhooks", "task.deliverWebhooks"},
+		{"github.com/LiteyukiStudio/spage/cmd/server.main.gowrap2", "cmd/server.main"},
+		{"github.com/LiteyukiStudio/spage/handlers.graphqlBatch[...]", "handlers.graphqlBatch"},
+		{"github.com/LiteyukiStudio/spage/store.(*notificationType).ListByOwner", ""},
+		{"github.com/LiteyukiStudio/spage/store.Paginate[...]", ""},
+		{"gorm.io/gorm.(*DB).Find", ""},
+		{"runtime.goexit", ""},
+	}
+	for _, tc := range cases {
+		if got := callerName(tc.fn); got != tc.want {
+			t.Errorf("callerName(%q) = %q, want %q", tc.fn, got, tc.want)
+		}
+	}
+}
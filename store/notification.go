@@ -0,0 +1,156 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type notificationType struct {
+	db *gorm.DB
+}
+
+// Notification 用户和组织的通知渠道、投递记录以及已通知的警告
+// Notification channels of users and organizations, their deliveries and the warnings notified
+var Notification = notificationType{
+	db: DB,
+}
+
+// ListByOwner 获取用户或组织的通知渠道
+// List the notification channels of a user or organization
+func (n *notificationType) ListByOwner(ownerType string, ownerID uint) (channels []models.NotificationChannel, err error) {
+	err = n.db.Where("owner_type = ? AND owner_id = ?", ownerType, ownerID).Order("id").Find(&channels).Error
+	return
+}
+
+// GetByOwner 获取用户或组织的指定通知渠道
+// Get the given notification channel of a user or organization
+func (n *notificationType) GetByOwner(ownerType string, ownerID, id uint) (channel *models.NotificationChannel, err error) {
+	channel = &models.NotificationChannel{}
+	err = n.db.Where("id = ? AND owner_type = ? AND owner_id = ?", id, ownerType, ownerID).First(channel).Error
+	if err != nil {
+		return nil, err
+	}
+	return channel, nil
+}
+
+// Create 创建通知渠道
+// Create a notification channel
+func (n *notificationType) Create(channel *models.NotificationChannel) error {
+	return n.db.Create(channel).Error
+}
+
+// Update 更新通知渠道，单独写入 active 以支持停用
+// Update a notification channel, writing active separately so it can be disabled
+func (n *notificationType) Update(channel *models.NotificationChannel) error {
+	return n.db.Model(channel).Select("name", "target", "secret", "events", "active").Updates(channel).Error
+}
+
+// Delete 删除通知渠道及其投递记录
+// Delete a notification channel and its deliveries
+func (n *notificationType) Delete(channel *models.NotificationChannel) error {
+	return n.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("channel_id = ?", channel.ID).Delete(&models.NotificationDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(channel).Error
+	})
+}
+
+// deleteByOwner 在事务中删除用户或组织的通知渠道、投递记录和警告
+// Delete the notification channels, deliveries and warnings of a user or organization within a transaction
+func (n *notificationType) deleteByOwner(tx *gorm.DB, ownerType string, ownerID uint) error {
+	channels := tx.Session(&gorm.Session{NewDB: true}).Model(&models.NotificationChannel{}).Unscoped().Select("id").
+		Where("owner_type = ? AND owner_id = ?", ownerType, ownerID)
+	if err := tx.Where("channel_id IN (?)", channels).Delete(&models.NotificationDelivery{}).Error; err != nil {
+		return err
+	}
+	for _, model := range []any{&models.NotificationChannel{}, &models.NotificationAlert{}} {
+		if err := tx.Unscoped().Where("owner_type = ? AND owner_id = ?", ownerType, ownerID).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListSubscribed 获取用户或组织订阅了事件的已启用通知渠道
+// List the enabled notification channels of a user or organization subscribed to the event
+func (n *notificationType) ListSubscribed(ownerType string, ownerID uint, event string) (channels []models.NotificationChannel, err error) {
+	var all []models.NotificationChannel
+	if err = n.db.Where("owner_type = ? AND owner_id = ? AND active = ?", ownerType, ownerID, true).Find(&all).Error; err != nil {
+		return nil, err
+	}
+	for _, channel := range all {
+		if channel.Subscribes(event) {
+			channels = append(channels, channel)
+		}
+	}
+	return channels, nil
+}
+
+// CreateDeliveries 创建待投递的记录
+// Create deliveries waiting to be sent
+func (n *notificationType) CreateDeliveries(deliveries []models.NotificationDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return n.db.Omit("Channel").Create(&deliveries).Error
+}
+
+// ListDue 获取已到重试时间的待投递记录
+// List pending deliveries whose attempt is due
+func (n *notificationType) ListDue(now time.Time, limit int) (deliveries []models.NotificationDelivery, err error) {
+	// 停用的渠道暂停投递 Deliveries of disabled channels are paused
+	err = n.db.Joins("JOIN notification_channels ON notification_channels.id = notification_deliveries.channel_id AND notification_channels.active = ?", true).
+		Where("notification_deliveries.status = ? AND notification_deliveries.next_attempt_at <= ?", constants.WebhookDeliveryPending, now).
+		Order("notification_deliveries.next_attempt_at").Limit(limit).Preload("Channel").Find(&deliveries).Error
+	return
+}
+
+// Claim 领取一次投递尝试并推迟其下次尝试时间，多个实例同时领取时只有一个成功
+// Claim a delivery attempt and push back its next attempt, only one instance succeeds when several claim at once
+func (n *notificationType) Claim(delivery *models.NotificationDelivery, until time.Time) (bool, error) {
+	result := n.db.Model(&models.NotificationDelivery{}).
+		Where("id = ? AND status = ? AND attempts = ?", delivery.ID, constants.WebhookDeliveryPending, delivery.Attempts).
+		Updates(map[string]any{"attempts": delivery.Attempts + 1, "next_attempt_at": until})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	delivery.Attempts++
+	delivery.NextAttemptAt = until
+	return true, nil
+}
+
+// SaveAttempt 保存一次投递尝试的结果
+// Save the outcome of a delivery attempt
+func (n *notificationType) SaveAttempt(delivery *models.NotificationDelivery) error {
+	return n.db.Model(delivery).Select("status", "attempts", "next_attempt_at", "response_status", "response_body", "error", "delivered_at").
+		Updates(delivery).Error
+}
+
+// ListDeliveries 分页获取通知渠道的投递记录
+// List the deliveries of a notification channel with pagination
+func (n *notificationType) ListDeliveries(ctx context.Context, channelID uint, page, limit int) (deliveries []models.NotificationDelivery, total int64, err error) {
+	return Paginate[models.NotificationDelivery](n.db.WithContext(ctx), page, limit, "channel_id = ?", channelID)
+}
+
+// RecordAlert 记录用户或组织的警告，已记录过时返回 false
+// Record a warning of a user or organization, false when it was already recorded
+func (n *notificationType) RecordAlert(ownerType string, ownerID uint, key string, now time.Time) (bool, error) {
+	alert := models.NotificationAlert{OwnerType: ownerType, OwnerID: ownerID, Key: key, CreatedAt: now}
+	result := n.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&alert)
+	return result.RowsAffected > 0, result.Error
+}
+
+// ClearAlert 警告解除后删除记录，再次出现时重新通知
+// Delete a warning once it clears, so it is notified again when it comes back
+func (n *notificationType) ClearAlert(ownerType string, ownerID uint, key string) error {
+	return n.db.Where("owner_type = ? AND owner_id = ? AND key = ?", ownerType, ownerID, key).Delete(&models.NotificationAlert{}).Error
+}
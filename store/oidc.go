@@ -0,0 +1,101 @@
+package store
+
+import (
+	"github.com/LiteyukiStudio/spage/models"
+	"gorm.io/gorm"
+)
+
+type oidcType struct {
+	db *gorm.DB
+}
+
+// OIDC 第三方登录提供方与用户身份
+// Third-party auth providers and user identities
+var OIDC = oidcType{
+	db: DB,
+}
+
+// ListProviders 获取登录提供方列表，enabledOnly 为 true 时只返回启用的提供方
+// List auth providers, only enabled ones when enabledOnly is true
+func (o *oidcType) ListProviders(enabledOnly bool) (providers []models.OIDCConfig, err error) {
+	query := o.db.Order("id")
+	if enabledOnly {
+		query = query.Where("enabled = ?", true)
+	}
+	err = query.Find(&providers).Error
+	return
+}
+
+// GetProviderByID 根据ID获取登录提供方
+func (o *oidcType) GetProviderByID(id uint) (provider *models.OIDCConfig, err error) {
+	provider = &models.OIDCConfig{}
+	err = o.db.Where("id = ?", id).First(provider).Error
+	if err != nil {
+		return nil, err
+	}
+	return provider, nil
+}
+
+// CreateProvider 创建登录提供方
+func (o *oidcType) CreateProvider(provider *models.OIDCConfig) error {
+	return o.db.Create(provider).Error
+}
+
+// UpdateProvider 更新登录提供方
+func (o *oidcType) UpdateProvider(provider *models.OIDCConfig) error {
+	return o.db.Save(provider).Error
+}
+
+// DeleteProvider 删除登录提供方及其关联的身份
+// Delete an auth provider together with its linked identities
+func (o *oidcType) DeleteProvider(provider *models.OIDCConfig) error {
+	return o.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("provider_id = ?", provider.ID).Delete(&models.UserIdentity{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(provider).Error
+	})
+}
+
+// GetIdentity 根据提供方和提供方内的用户标识获取身份
+// Get an identity by provider and the user identifier at the provider
+func (o *oidcType) GetIdentity(providerID uint, subject string) (identity *models.UserIdentity, err error) {
+	identity = &models.UserIdentity{}
+	err = o.db.Where("provider_id = ? AND subject = ?", providerID, subject).Preload("User").First(identity).Error
+	if err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
+
+// ListIdentities 获取用户关联的所有身份
+// List all identities linked to the user
+func (o *oidcType) ListIdentities(userID uint) (identities []models.UserIdentity, err error) {
+	err = o.db.Where("user_id = ?", userID).Preload("Provider").Order("id").Find(&identities).Error
+	return
+}
+
+// CreateIdentity 关联身份
+// Link an identity
+func (o *oidcType) CreateIdentity(identity *models.UserIdentity) error {
+	return o.db.Create(identity).Error
+}
+
+// CreateUserWithIdentity 在同一事务中创建用户和关联的身份
+// Create a user and the linked identity in one transaction
+func (o *oidcType) CreateUserWithIdentity(user *models.User, identity *models.UserIdentity) error {
+	return o.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		identity.UserID = user.ID
+		return tx.Create(identity).Error
+	})
+}
+
+// DeleteIdentity 解除关联，直接删除以便之后重新关联同一身份
+// Unlink an identity, deleted permanently so the same identity can be linked again later
+func (o *oidcType) DeleteIdentity(userID, providerID uint) (affected int64, err error) {
+	result := o.db.Unscoped().Where("user_id = ? AND provider_id = ?", userID, providerID).Delete(&models.UserIdentity{})
+	return result.RowsAffected, result.Error
+}
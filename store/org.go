@@ -1,8 +1,14 @@
 package store
 
 import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/constants"
 	"github.com/LiteyukiStudio/spage/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type orgType struct {
@@ -13,16 +19,23 @@ var Org = orgType{
 	db: DB,
 }
 
+// Member 带有组织或项目角色的用户
+// User with an organization or project role
+type Member struct {
+	models.User
+	MemberRole constants.OrgRole // 组织或项目角色，区别于用户的全局角色 Organization or project role, distinct from the user's global role
+}
+
 // ListByUserID 通过UserID获取用户组织，支持分页和预加载关系
 // Get Organizations by UserID, support pagination and preload relationships
-func (o *orgType) ListByUserID(userID string, page, limit int) (orgs []models.Organization, err error) {
+func (o *orgType) ListByUserID(ctx context.Context, userID string, page, limit int) (orgs []models.Organization, total int64, err error) {
 	// 使用连接查询
-	query := o.db.Joins("JOIN organization_members ON organizations.id = organization_members.organization_id").
+	query := o.db.WithContext(ctx).Joins("JOIN organization_members ON organizations.id = organization_members.organization_id").
 		Where("organization_members.user_id = ?", userID)
 	// 预加载关系
-	query = WithPreloads(query, "Members", "Owners")
+	query = WithPreloads(query, "Members")
 	// 使用通用分页方法
-	orgs, _, err = Paginate[models.Organization](
+	orgs, total, err = Paginate[models.Organization](
 		query,
 		page,
 		limit,
@@ -34,7 +47,14 @@ func (o *orgType) ListByUserID(userID string, page, limit int) (orgs []models.Or
 // GetOrgById 通过ID获取组织
 // Get Organization by ID
 func (o *orgType) GetOrgById(id uint) (org *models.Organization, err error) {
-	err = o.db.Model(&models.Organization{}).Where("id = ?", id).Preload("Members").Preload("Owners").First(&org).Error
+	err = o.db.Model(&models.Organization{}).Where("id = ?", id).Preload("Members").First(&org).Error
+	return
+}
+
+// GetOrgByName 通过名称获取组织
+// Get Organization by name
+func (o *orgType) GetOrgByName(name string) (org *models.Organization, err error) {
+	err = o.db.Model(&models.Organization{}).Where("LOWER(name) = ?", strings.ToLower(name)).First(&org).Error
 	return
 }
 
@@ -42,29 +62,89 @@ func (o *orgType) GetOrgById(id uint) (org *models.Organization, err error) {
 // Check if the organization name exists
 func (o *orgType) OrgNameIsExist(name string) bool {
 	var count int64
-	o.db.Model(&models.Organization{}).Where("name = ?", name).Count(&count)
+	o.db.Model(&models.Organization{}).Where("LOWER(name) = ?", strings.ToLower(name)).Count(&count)
 	return count > 0
 }
 
 // GetUserAuth 获取用户在组织中的权限
 // Get User's Authority in Organization
-func (o *orgType) GetUserAuth(org *models.Organization, userID uint) (auth string) {
-	for _, owner := range org.Owners {
-		if owner.ID == userID {
-			return "owner"
-		}
+func (o *orgType) GetUserAuth(org *models.Organization, userID uint) (auth constants.OrgRole) {
+	var roles []constants.OrgRole
+	if err := o.db.Model(&models.OrgMember{}).Where("organization_id = ? AND user_id = ?", org.ID, userID).Pluck("role", &roles).Error; err != nil {
+		return ""
 	}
-	for _, member := range org.Members {
-		if member.ID == userID {
-			return "member"
-		}
+	return constants.HighestOrgRole(roles...)
+}
+
+// UserRoles 批量获取用户在多个组织中的角色，不是成员的组织不在结果中
+// Get the user's roles in several organizations at once, organizations the user is not a member of are left out
+func (o *orgType) UserRoles(orgIDs []uint, userID uint) (map[uint]constants.OrgRole, error) {
+	roles := map[uint]constants.OrgRole{}
+	if len(orgIDs) == 0 {
+		return roles, nil
+	}
+	var members []models.OrgMember
+	if err := o.db.Where("organization_id IN ? AND user_id = ?", orgIDs, userID).Find(&members).Error; err != nil {
+		return nil, err
 	}
-	return ""
+	for _, member := range members {
+		roles[member.OrganizationID] = constants.HighestOrgRole(roles[member.OrganizationID], member.Role)
+	}
+	return roles, nil
 }
 
-// CreateOrg 创建组织
-func (o *orgType) CreateOrg(org *models.Organization) error {
-	return o.db.Create(org).Error
+// ListMembers 获取组织成员及其角色
+// List the organization's members and their roles
+func (o *orgType) ListMembers(orgID uint) (members []Member, err error) {
+	err = o.db.Model(&models.User{}).
+		Select("users.*, organization_members.role AS member_role").
+		Joins("JOIN organization_members ON organization_members.user_id = users.id").
+		Where("organization_members.organization_id = ?", orgID).
+		Order("users.id").
+		Find(&members).Error
+	return
+}
+
+// CountOwners 统计组织的所有者数量
+// Count the owners of the organization
+func (o *orgType) CountOwners(orgID uint) (count int64, err error) {
+	err = o.db.Model(&models.OrgMember{}).Where("organization_id = ? AND role = ?", orgID, constants.OrgRoleOwner).Count(&count).Error
+	return
+}
+
+// SetMember 添加组织成员或修改其角色
+// Add an organization member or change their role
+func (o *orgType) SetMember(orgID, userID uint, role constants.OrgRole) error {
+	return o.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "organization_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role"}),
+	}).Create(&models.OrgMember{OrganizationID: orgID, UserID: userID, Role: role, CreatedAt: time.Now()}).Error
+}
+
+// RemoveMember 移除组织成员，同时移出该组织的所有团队
+// Remove an organization member, also removing them from every team of the organization
+func (o *orgType) RemoveMember(orgID, userID uint) error {
+	return o.db.Transaction(func(tx *gorm.DB) error {
+		teams := tx.Model(&models.Team{}).Select("id").Where("organization_id = ?", orgID)
+		if err := tx.Where("user_id = ? AND team_id IN (?)", userID, teams).Delete(&models.TeamMember{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("organization_id = ? AND user_id = ?", orgID, userID).Delete(&models.OrgMember{}).Error
+	})
+}
+
+// CreateOrg 创建组织，创建者成为所有者，组织属于创建者所在的租户
+// Create an organization with the creator as its owner, belonging to the tenant of the creator
+func (o *orgType) CreateOrg(org *models.Organization, creator *models.User) error {
+	if org.TenantID == nil {
+		org.TenantID = creator.TenantID
+	}
+	return o.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(org).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.OrgMember{OrganizationID: org.ID, UserID: creator.ID, Role: constants.OrgRoleOwner, CreatedAt: time.Now()}).Error
+	})
 }
 
 // UpdateOrg 更新组织
@@ -72,6 +152,12 @@ func (o *orgType) UpdateOrg(org *models.Organization) error {
 	return o.db.Updates(org).Error
 }
 
+// SetAvatar 设置上传的标志的内容哈希和头像地址，两者都可清空
+// Set the content hash of the uploaded logo and the avatar URL, both may be cleared
+func (o *orgType) SetAvatar(id uint, hash string, url *string) error {
+	return o.db.Model(&models.Organization{}).Where("id = ?", id).Updates(map[string]any{"avatar_hash": hash, "avatar_url": url}).Error
+}
+
 // DeleteOrg 删除组织
 func (o *orgType) DeleteOrg(org *models.Organization) error {
 	return o.db.Model(org).Delete(org).Error
@@ -0,0 +1,91 @@
+package store
+
+import (
+	"time"
+
+	"github.com/LiteyukiStudio/spage/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type previewType struct {
+	db *gorm.DB
+}
+
+// Preview 分支和合并请求的预览部署
+// Preview deployments of branches and pull requests
+var Preview = previewType{
+	db: DB,
+}
+
+// ListBySite 获取站点的预览部署
+// List the preview deployments of a site
+func (p *previewType) ListBySite(siteID uint) (previews []models.SitePreview, err error) {
+	err = p.db.Where("site_id = ?", siteID).Order("id DESC").Preload("Release").Find(&previews).Error
+	return
+}
+
+// GetBySite 获取站点的指定预览部署
+// Get the given preview deployment of a site
+func (p *previewType) GetBySite(siteID, id uint) (preview *models.SitePreview, err error) {
+	preview = &models.SitePreview{}
+	err = p.db.Where("id = ? AND site_id = ?", id, siteID).Preload("Release.File").First(preview).Error
+	if err != nil {
+		return nil, err
+	}
+	return preview, nil
+}
+
+// GetActive 根据名称获取站点未过期的预览部署
+// Get an unexpired preview deployment of a site by name
+func (p *previewType) GetActive(siteID uint, name string) (preview *models.SitePreview, err error) {
+	preview = &models.SitePreview{}
+	err = p.db.Where("site_id = ? AND name = ? AND expires_at > ?", siteID, name, time.Now()).Preload("Release.File").First(preview).Error
+	if err != nil {
+		return nil, err
+	}
+	return preview, nil
+}
+
+// Save 创建或更新同名的预览部署，返回被替换的版本ID
+// Create or update the preview deployment of the same name, returning the replaced version ID
+func (p *previewType) Save(preview *models.SitePreview) (replacedID uint, err error) {
+	err = p.db.Transaction(func(tx *gorm.DB) error {
+		existing := &models.SitePreview{}
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("site_id = ? AND name = ?", preview.SiteID, preview.Name).Limit(1).Find(existing).Error
+		if err != nil {
+			return err
+		}
+		if existing.ID == 0 {
+			return tx.Create(preview).Error
+		}
+		replacedID = existing.ReleaseID
+		preview.Model = existing.Model
+		return tx.Model(existing).Updates(map[string]any{
+			"release_id": preview.ReleaseID,
+			"expires_at": preview.ExpiresAt,
+		}).Error
+	})
+	return
+}
+
+// ListExpired 获取已过期的预览部署
+// List expired preview deployments
+func (p *previewType) ListExpired(now time.Time, limit int) (previews []models.SitePreview, err error) {
+	err = p.db.Where("expires_at <= ?", now).Order("expires_at").Limit(limit).Preload("Release.File").Find(&previews).Error
+	return
+}
+
+// Delete 删除预览部署记录，版本由调用方处理
+// Delete a preview deployment record, the version is handled by the caller
+func (p *previewType) Delete(preview *models.SitePreview) error {
+	return p.db.Unscoped().Delete(preview).Error
+}
+
+// CountActive 统计尚未过期的预览部署数量
+// Count the preview deployments that have not expired
+func (p *previewType) CountActive(now time.Time) (count int64, err error) {
+	err = p.db.Model(&models.SitePreview{}).Where("expires_at > ?", now).Count(&count).Error
+	return
+}
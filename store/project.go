@@ -1,11 +1,17 @@
 package store
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/LiteyukiStudio/spage/constants"
 	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/utils"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type projectType struct {
@@ -16,36 +22,123 @@ var Project = projectType{
 	db: DB,
 }
 
-// Create 创建项目
-// Create a project
+// Create 创建项目，项目属于所有者所在的租户
+// Create a project, which belongs to the tenant of its owner
 func (p *projectType) Create(project *models.Project) (err error) {
+	if project.TenantID == nil {
+		if project.TenantID, err = ownerTenantID(p.db, project.OwnerType, project.OwnerID); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+	}
 	return p.db.Create(project).Error
 }
 
 // GetByID 通过项目ID获取项目
 // Get Project by ID
 func (p *projectType) GetByID(id uint) (project *models.Project, err error) {
-	err = p.db.First(&project, id).Preload("Owners").Error
+	err = p.db.First(&project, id).Error
 	return
 }
 
-// UserIsOwner 判断用户是否是项目的所有者
-// Check if a user is the owner of a project
-func (p *projectType) UserIsOwner(project *models.Project, userID uint) bool {
+// GetByName 通过项目名称获取项目
+// Get Project by name
+func (p *projectType) GetByName(name string) (project *models.Project, err error) {
+	err = p.db.Where("LOWER(name) = ?", strings.ToLower(name)).First(&project).Error
+	return
+}
+
+// UserRole 获取用户在项目中的角色，取个人项目所有者、项目成员、组织成员和团队授权中最高的一个
+// Get the user's role in the project, the highest of personal ownership, project membership, organization membership and team grants
+func (p *projectType) UserRole(project *models.Project, userID uint) constants.OrgRole {
 	if project.OwnerType == constants.OwnerTypeUser && project.OwnerID == userID {
-		return true
+		return constants.OrgRoleOwner
+	}
+	var roles, orgRoles, teamRoles []constants.OrgRole
+	if err := p.db.Model(&models.ProjectMember{}).Where("project_id = ? AND user_id = ?", project.ID, userID).Pluck("role", &roles).Error; err != nil {
+		return ""
 	}
-	for _, owner := range project.Owners {
-		if owner.ID == userID {
-			return true
+	if project.OwnerType == constants.OwnerTypeOrg {
+		if err := p.db.Model(&models.OrgMember{}).Where("organization_id = ? AND user_id = ?", project.OwnerID, userID).Pluck("role", &orgRoles).Error; err != nil {
+			return ""
+		}
+		err := p.db.Model(&models.ProjectTeam{}).
+			Joins("JOIN team_members ON team_members.team_id = project_teams.team_id").
+			Where("project_teams.project_id = ? AND team_members.user_id = ?", project.ID, userID).
+			Pluck("project_teams.role", &teamRoles).Error
+		if err != nil {
+			return ""
 		}
 	}
-	return false
+	return constants.HighestOrgRole(append(append(roles, orgRoles...), teamRoles...)...)
 }
 
-// ListByOwner 通过用户ID获取项目列表，支持分页和从新到旧排序
-// Get Project List by UserID, support pagination and new to old sorting
-func (p *projectType) ListByOwner(ownerType, ownerID string, page, limit int) (projects []models.Project, total int64, err error) {
+// UserRoles 批量获取用户在多个项目中的角色，规则与 UserRole 相同，没有任何角色的项目不在结果中
+// Get the user's roles in several projects at once by the same rules as UserRole, projects without any role are left out
+func (p *projectType) UserRoles(projects []*models.Project, userID uint) (map[uint]constants.OrgRole, error) {
+	grants := map[uint][]constants.OrgRole{}
+	projectIDs := make([]uint, 0, len(projects))
+	var orgProjectIDs, orgIDs []uint
+	for _, project := range projects {
+		projectIDs = append(projectIDs, project.ID)
+		switch {
+		case project.OwnerType == constants.OwnerTypeUser && project.OwnerID == userID:
+			grants[project.ID] = append(grants[project.ID], constants.OrgRoleOwner)
+		case project.OwnerType == constants.OwnerTypeOrg:
+			orgProjectIDs = append(orgProjectIDs, project.ID)
+			orgIDs = append(orgIDs, project.OwnerID)
+		}
+	}
+	roles := map[uint]constants.OrgRole{}
+	if len(projectIDs) == 0 {
+		return roles, nil
+	}
+	var members []models.ProjectMember
+	if err := p.db.Where("project_id IN ? AND user_id = ?", projectIDs, userID).Find(&members).Error; err != nil {
+		return nil, err
+	}
+	for _, member := range members {
+		grants[member.ProjectID] = append(grants[member.ProjectID], member.Role)
+	}
+	if len(orgIDs) > 0 {
+		orgRoles, err := Org.UserRoles(orgIDs, userID)
+		if err != nil {
+			return nil, err
+		}
+		for _, project := range projects {
+			if role, ok := orgRoles[project.OwnerID]; ok && project.OwnerType == constants.OwnerTypeOrg {
+				grants[project.ID] = append(grants[project.ID], role)
+			}
+		}
+		var teams []models.ProjectTeam
+		err = p.db.Model(&models.ProjectTeam{}).Select("project_teams.project_id, project_teams.role").
+			Joins("JOIN team_members ON team_members.team_id = project_teams.team_id").
+			Where("project_teams.project_id IN ? AND team_members.user_id = ?", orgProjectIDs, userID).
+			Scan(&teams).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, team := range teams {
+			grants[team.ProjectID] = append(grants[team.ProjectID], team.Role)
+		}
+	}
+	for id, projectGrants := range grants {
+		if role := constants.HighestOrgRole(projectGrants...); role.Valid() {
+			roles[id] = role
+		}
+	}
+	return roles, nil
+}
+
+// LabelSelector 按标签筛选项目，Value 为空时只要求项目带有该键
+// Filter projects by a label, only requiring the key when Value is nil
+type LabelSelector struct {
+	Key   string
+	Value *string
+}
+
+// ListByOwner 通过用户ID获取项目列表及其标签，支持分页、排序、按名称和标签筛选，多个标签条件需要同时满足
+// Get Project List by UserID with their labels, support pagination, sorting and filtering by name and labels, all label selectors must match
+func (p *projectType) ListByOwner(ctx context.Context, ownerType, ownerID, keyword string, labels []LabelSelector, list utils.ListQuery) (projects []models.Project, total int64, err error) {
 	tableName := ""
 	switch ownerType {
 	case constants.OwnerTypeUser:
@@ -57,37 +150,296 @@ func (p *projectType) ListByOwner(ownerType, ownerID string, page, limit int) (p
 		return
 	}
 
-	projects, total, err = Paginate[models.Project](
-		p.db,
-		page,
-		limit,
+	query := p.db.WithContext(ctx)
+	if keyword != "" {
+		pattern := likePattern(strings.ToLower(keyword))
+		query = query.Where("(LOWER(name) LIKE ? ESCAPE '\\' OR LOWER(display_name) LIKE ? ESCAPE '\\')", pattern, pattern)
+	}
+	for _, label := range labels {
+		matched := p.db.Session(&gorm.Session{NewDB: true}).Model(&models.ProjectLabel{}).Select("project_id").Where("key = ?", label.Key)
+		if label.Value != nil {
+			matched = matched.Where("value = ?", *label.Value)
+		}
+		query = query.Where("id IN (?)", matched)
+	}
+	projects, total, err = PaginateList[models.Project](
+		query,
+		list,
 		"owner_type = ? AND owner_id = ?",
 		tableName,
 		ownerID,
 	)
+	if err != nil {
+		return
+	}
+	err = p.LoadLabels(projects...)
+	return
+}
+
+// ListByOwners 获取多个所有者的项目及其标签，每个所有者最多 limit 个，按ID倒序
+// Get the projects of several owners with their labels, at most limit per owner, newest first
+func (p *projectType) ListByOwners(ctx context.Context, ownerType string, ownerIDs []uint, limit int) (map[uint][]models.Project, error) {
+	grouped := map[uint][]models.Project{}
+	if len(ownerIDs) == 0 {
+		return grouped, nil
+	}
+	projects, err := FirstPerGroup[models.Project](p.db.WithContext(ctx), "owner_id", "id DESC", limit, "owner_type = ? AND owner_id IN ?", ownerType, ownerIDs)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.LoadLabels(projects...); err != nil {
+		return nil, err
+	}
+	for _, project := range projects {
+		grouped[project.OwnerID] = append(grouped[project.OwnerID], project)
+	}
+	return grouped, nil
+}
+
+// LoadLabels 为项目加载标签
+// Load the labels of projects
+func (p *projectType) LoadLabels(projects ...models.Project) error {
+	if len(projects) == 0 {
+		return nil
+	}
+	ids := make([]uint, 0, len(projects))
+	for _, project := range projects {
+		ids = append(ids, project.ID)
+	}
+	var labels []models.ProjectLabel
+	if err := p.db.Where("project_id IN ?", ids).Order("key").Find(&labels).Error; err != nil {
+		return err
+	}
+	for i := range projects {
+		projects[i].Labels = nil
+		for _, label := range labels {
+			if label.ProjectID == projects[i].ID {
+				projects[i].Labels = append(projects[i].Labels, label)
+			}
+		}
+	}
+	return nil
+}
+
+// SetLabels 替换项目的全部标签
+// Replace all labels of a project
+func (p *projectType) SetLabels(project *models.Project, labels []models.ProjectLabel) error {
+	err := p.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("project_id = ?", project.ID).Delete(&models.ProjectLabel{}).Error; err != nil {
+			return err
+		}
+		if len(labels) == 0 {
+			return nil
+		}
+		for i := range labels {
+			labels[i].ProjectID = project.ID
+		}
+		return tx.Create(&labels).Error
+	})
+	if err == nil {
+		project.Labels = labels
+	}
+	return err
+}
+
+// LatestRelease 获取项目最近一次部署的版本，siteName 不为空时只查找该站点，不包括 latest 记录和预览部署
+// Get the newest deployed version of the project, only of the named site when siteName is set, excluding the latest record and preview deployments
+func (p *projectType) LatestRelease(projectID uint, siteName string) (release *models.SiteRelease, err error) {
+	sites := p.db.Session(&gorm.Session{NewDB: true}).Model(&models.Site{}).Select("id").Where("project_id = ?", projectID)
+	if siteName != "" {
+		sites = sites.Where("name = ?", siteName)
+	}
+	release = &models.SiteRelease{}
+	err = p.db.Where("site_id IN (?) AND tag <> ? AND id NOT IN (?)", sites, constants.ReleaseTagLatest, previewReleaseIDs(p.db)).
+		Order("id DESC").First(release).Error
 	return
 }
 
-// Update 更新项目
+// SetPrimarySite 设置通过项目主机名访问的站点，siteID 为空时恢复为最早创建的站点
+// Set the site served at the project host, falling back to the oldest site when siteID is nil
+func (p *projectType) SetPrimarySite(project *models.Project, siteID *uint) error {
+	if err := p.db.Model(project).Update("primary_site_id", siteID).Error; err != nil {
+		return err
+	}
+	project.PrimarySiteID = siteID
+	return nil
+}
+
+// SetMaxUploadSize 设置项目的单次上传最大字节数，size 为空时恢复为所有者的配额
+// Set the largest single upload of the project, falling back to the owner's quota when size is nil
+func (p *projectType) SetMaxUploadSize(project *models.Project, size *int64) error {
+	if err := p.db.Model(project).Update("max_upload_size", size).Error; err != nil {
+		return err
+	}
+	project.MaxUploadSize = size
+	return nil
+}
+
+// SetDirectoryListing 设置项目是否为没有 index.html 的目录显示目录索引，单独更新以支持写入 false
+// Set whether the project shows a directory index for directories without index.html, updated separately to allow writing false
+func (p *projectType) SetDirectoryListing(project *models.Project, enabled bool) error {
+	if err := p.db.Model(project).Update("directory_listing", enabled).Error; err != nil {
+		return err
+	}
+	project.DirectoryListing = enabled
+	return nil
+}
+
+// SetImmutableAssets 设置项目是否长期缓存文件名带内容哈希的资源，单独更新以支持写入 false
+// Set whether the project caches assets with a content hash in their file name for long, updated separately to allow writing false
+func (p *projectType) SetImmutableAssets(project *models.Project, enabled bool) error {
+	if err := p.db.Model(project).Update("immutable_assets", enabled).Error; err != nil {
+		return err
+	}
+	project.ImmutableAssets = enabled
+	return nil
+}
+
+// SetProtected 设置项目的新部署是否需要维护者批准，单独更新以支持写入 false；已在等待批准的版本不受影响
+// Set whether new deployments of the project need a maintainer's approval, updated separately to allow writing false; versions already awaiting approval are left alone
+func (p *projectType) SetProtected(project *models.Project, protected bool) error {
+	if err := p.db.Model(project).Update("protected", protected).Error; err != nil {
+		return err
+	}
+	project.Protected = protected
+	return nil
+}
+
+// SetRetention 设置项目的版本保留策略，为空的条件使用实例配置
+// Set the release retention policy of the project, conditions left empty use the instance configuration
+func (p *projectType) SetRetention(project *models.Project, keepReleases, keepReleaseDays *int) error {
+	if err := p.db.Model(project).Updates(map[string]any{"keep_releases": keepReleases, "keep_release_days": keepReleaseDays}).Error; err != nil {
+		return err
+	}
+	project.KeepReleases, project.KeepReleaseDays = keepReleases, keepReleaseDays
+	return nil
+}
+
+// Update 更新项目，标签通过 SetLabels 修改
+// Update a project, labels are changed through SetLabels
 func (p *projectType) Update(project *models.Project) (err error) {
-	return p.db.Updates(project).Error
+	return p.db.Omit(clause.Associations).Updates(project).Error
 }
 
-// Delete 删除项目
+// Delete 将项目及其站点移入回收站，站点记录与项目相同的删除时间，恢复项目时一并恢复
+// Move the project and its sites to the trash, the sites record the deletion time of the project and are restored with it
 func (p *projectType) Delete(project *models.Project) (err error) {
-	return p.db.Delete(project).Error
+	now := time.Now()
+	return p.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Site{}).Where("project_id = ?", project.ID).Update("deleted_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Model(project).Update("deleted_at", now).Error
+	})
+}
+
+// StaleProjectFilter 长期未部署的项目的筛选条件
+// Filter of projects that have not been deployed for a long time
+type StaleProjectFilter struct {
+	Before    time.Time // 在此之前创建且此后没有部署 Created before and not deployed since
+	OwnerType string    // 所有者类型，为空时不限 Owner type, any when empty
+	OwnerID   uint      // 所有者ID，为 0 时不限 Owner ID, any when 0
+}
+
+// ListStale 获取在 Before 之前创建且此后没有任何站点部署过版本的项目，已删除的版本和站点也计入部署
+// List the projects created before Before whose sites have not deployed any version since, deleted versions and sites count as deployments too
+func (p *projectType) ListStale(ctx context.Context, filter StaleProjectFilter) (projects []models.Project, err error) {
+	deployed := p.db.Unscoped().Model(&models.SiteRelease{}).Select("1").
+		Joins("JOIN sites ON sites.id = site_releases.site_id").
+		Where("sites.project_id = projects.id AND site_releases.created_at >= ?", filter.Before)
+	query := p.db.WithContext(ctx).Where("projects.created_at < ? AND NOT EXISTS (?)", filter.Before, deployed)
+	if filter.OwnerType != "" {
+		query = query.Where("owner_type = ?", filter.OwnerType)
+	}
+	if filter.OwnerID != 0 {
+		query = query.Where("owner_id = ?", filter.OwnerID)
+	}
+	err = query.Order("id").Find(&projects).Error
+	return
+}
+
+// ListOwned 获取所有者的全部项目，ids 不为空时只取其中的项目
+// List every project of an owner, only those among ids when ids is not empty
+func (p *projectType) ListOwned(ctx context.Context, ownerType string, ownerID uint, ids []uint) (projects []models.Project, err error) {
+	query := p.db.WithContext(ctx).Where("owner_type = ? AND owner_id = ?", ownerType, ownerID)
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+	err = query.Order("id").Find(&projects).Error
+	return
+}
+
+// Reassign 管理员直接更换项目的所有者，与接受转移一样移除原组织的团队授权，并丢弃等待确认的转移；项目随新所有者进入其租户
+// An administrator changes the owner of a project directly, removing the team grants of the former organization like an accepted transfer and dropping the transfer awaiting confirmation; the project follows the new owner into its tenant
+func (p *projectType) Reassign(project *models.Project, ownerType string, ownerID uint) error {
+	tenantID, err := ownerTenantID(p.db, ownerType, ownerID)
+	if err != nil {
+		return err
+	}
+	err = p.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("project_id = ?", project.ID).Delete(&models.ProjectTransfer{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("project_id = ?", project.ID).Delete(&models.ProjectTeam{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Project{}).Where("id = ?", project.ID).
+			Updates(map[string]any{"owner_type": ownerType, "owner_id": ownerID, "tenant_id": tenantID}).Error
+	})
+	if err != nil {
+		return err
+	}
+	project.OwnerType, project.OwnerID, project.TenantID = ownerType, ownerID, tenantID
+	return nil
 }
 
-// AddOwner 为项目添加所有者
-// Add Owner to a project
-func (p *projectType) AddOwner(project *models.Project, user *models.User) (err error) {
-	return p.db.Model(project).Association("Owners").Append(user)
+// ListMembers 获取直接授予角色的项目成员
+// List the project members granted a role directly
+func (p *projectType) ListMembers(projectID uint) (members []Member, err error) {
+	err = p.db.Model(&models.User{}).
+		Select("users.*, project_members.role AS member_role").
+		Joins("JOIN project_members ON project_members.user_id = users.id").
+		Where("project_members.project_id = ?", projectID).
+		Order("users.id").
+		Find(&members).Error
+	return
 }
 
-// DeleteOwner 从项目删除所有者
-// Delete Owner from a project
-func (p *projectType) DeleteOwner(project *models.Project, user *models.User) (err error) {
-	return p.db.Model(project).Association("Owners").Delete(user)
+// SetMember 添加项目成员或修改其角色
+// Add a project member or change their role
+func (p *projectType) SetMember(projectID, userID uint, role constants.OrgRole) error {
+	return p.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "project_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role"}),
+	}).Create(&models.ProjectMember{ProjectID: projectID, UserID: userID, Role: role, CreatedAt: time.Now()}).Error
+}
+
+// RemoveMember 移除项目成员
+// Remove a project member
+func (p *projectType) RemoveMember(projectID, userID uint) error {
+	return p.db.Where("project_id = ? AND user_id = ?", projectID, userID).Delete(&models.ProjectMember{}).Error
+}
+
+// ListTeams 获取被授予项目角色的团队
+// List the teams granted a role on the project
+func (p *projectType) ListTeams(projectID uint) (grants []models.ProjectTeam, err error) {
+	err = p.db.Preload("Team").Where("project_id = ?", projectID).Order("team_id").Find(&grants).Error
+	return
+}
+
+// SetTeam 为团队授予项目角色
+// Grant a team a role on the project
+func (p *projectType) SetTeam(projectID, teamID uint, role constants.OrgRole) error {
+	return p.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "project_id"}, {Name: "team_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role"}),
+	}).Create(&models.ProjectTeam{ProjectID: projectID, TeamID: teamID, Role: role, CreatedAt: time.Now()}).Error
+}
+
+// RemoveTeam 撤销团队的项目角色
+// Revoke a team's role on the project
+func (p *projectType) RemoveTeam(projectID, teamID uint) error {
+	return p.db.Where("project_id = ? AND team_id = ?", projectID, teamID).Delete(&models.ProjectTeam{}).Error
 }
 
 // GetSiteList 获取项目下的站点列表
@@ -102,3 +454,27 @@ func (p *projectType) GetSiteList(project *models.Project, page, limit int) (sit
 	)
 	return
 }
+
+// ListSitesByProjects 获取多个项目的站点，每个项目最多 limit 个，按ID倒序
+// Get the sites of several projects, at most limit per project, newest first
+func (p *projectType) ListSitesByProjects(projectIDs []uint, limit int) (map[uint][]models.Site, error) {
+	grouped := map[uint][]models.Site{}
+	if len(projectIDs) == 0 {
+		return grouped, nil
+	}
+	sites, err := FirstPerGroup[models.Site](p.db, "project_id", "id DESC", limit, "project_id IN ?", projectIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, site := range sites {
+		grouped[site.ProjectID] = append(grouped[site.ProjectID], site)
+	}
+	return grouped, nil
+}
+
+// ListAllSites 获取项目下的所有站点，包括回收站中的站点
+// List all sites of a project, including those in the trash
+func (p *projectType) ListAllSites(projectID uint) (sites []models.Site, err error) {
+	err = p.db.Unscoped().Where("project_id = ?", projectID).Find(&sites).Error
+	return
+}
@@ -0,0 +1,100 @@
+package store
+
+import (
+	"context"
+
+	"github.com/LiteyukiStudio/spage/models"
+	"gorm.io/gorm"
+)
+
+type quotaType struct {
+	db *gorm.DB
+}
+
+// Quota 用户和组织的配额及用量
+// Quotas and usage of users and organizations
+var Quota = quotaType{
+	db: DB,
+}
+
+// Get 获取管理员为所有者设置的配额
+// Get the quota an administrator set for an owner
+func (q *quotaType) Get(ownerType string, ownerID uint) (quota *models.Quota, err error) {
+	quota = &models.Quota{}
+	err = q.db.Where("owner_type = ? AND owner_id = ?", ownerType, ownerID).First(quota).Error
+	if err != nil {
+		return nil, err
+	}
+	return quota, nil
+}
+
+// Save 创建或覆盖所有者的配额
+// Create or overwrite the quota of an owner
+func (q *quotaType) Save(quota *models.Quota) error {
+	if existing, err := q.Get(quota.OwnerType, quota.OwnerID); err == nil {
+		quota.ID = existing.ID
+		quota.CreatedAt = existing.CreatedAt
+	}
+	return q.db.Save(quota).Error
+}
+
+// Delete 删除所有者的配额，恢复为默认配额
+// Delete the quota of an owner, restoring the defaults
+func (q *quotaType) Delete(ownerType string, ownerID uint) error {
+	return q.db.Unscoped().Where("owner_type = ? AND owner_id = ?", ownerType, ownerID).Delete(&models.Quota{}).Error
+}
+
+// CountProjects 统计所有者的项目数量
+// Count the projects of an owner
+func (q *quotaType) CountProjects(ownerType string, ownerID uint) (count int64, err error) {
+	err = q.db.Model(&models.Project{}).Where("owner_type = ? AND owner_id = ?", ownerType, ownerID).Count(&count).Error
+	return
+}
+
+// ownedFiles 所有者的站点版本引用的文件ID子查询
+// Subquery of the file IDs referenced by the site versions of an owner
+func (q *quotaType) ownedFiles(ctx context.Context, ownerType string, ownerID uint) *gorm.DB {
+	return q.db.WithContext(ctx).Table("site_releases").Select("site_releases.file_id").
+		Joins("JOIN sites ON sites.id = site_releases.site_id AND sites.deleted_at IS NULL").
+		Joins("JOIN projects ON projects.id = sites.project_id AND projects.deleted_at IS NULL").
+		Where("site_releases.deleted_at IS NULL AND projects.owner_type = ? AND projects.owner_id = ?", ownerType, ownerID)
+}
+
+// StorageUsed 统计所有者的站点版本占用的字节数，部署清单引用的相同内容只计算一次
+// Count the bytes occupied by the site versions of an owner, identical contents referenced by manifests count once
+func (q *quotaType) StorageUsed(ctx context.Context, ownerType string, ownerID uint) (int64, error) {
+	var blobBytes, fileBytes int64
+	err := q.db.WithContext(ctx).Model(&models.Blob{}).Select("COALESCE(SUM(size), 0)").
+		Where("hash IN (?)", q.db.Model(&models.FileBlob{}).Select("hash").Where("file_id IN (?)", q.ownedFiles(ctx, ownerType, ownerID))).
+		Scan(&blobBytes).Error
+	if err != nil {
+		return 0, err
+	}
+	err = q.db.WithContext(ctx).Model(&models.File{}).Select("COALESCE(SUM(size), 0)").
+		Where("manifest = ? AND id IN (?)", false, q.ownedFiles(ctx, ownerType, ownerID)).
+		Scan(&fileBytes).Error
+	if err != nil {
+		return 0, err
+	}
+	return blobBytes + fileBytes, nil
+}
+
+// OwnedHashes 查询所有者的站点版本已引用的内容哈希，这些内容不再占用额外的配额
+// Look up the content hashes already referenced by the site versions of an owner, which take no further quota
+func (q *quotaType) OwnedHashes(ctx context.Context, ownerType string, ownerID uint, hashes []string) (map[string]bool, error) {
+	owned := make(map[string]bool)
+	for start := 0; start < len(hashes); start += blobQueryBatch {
+		end := min(start+blobQueryBatch, len(hashes))
+		var found []string
+		err := q.db.WithContext(ctx).Model(&models.FileBlob{}).Distinct("hash").
+			Where("hash IN ? AND file_id IN (?)", hashes[start:end], q.ownedFiles(ctx, ownerType, ownerID)).
+			Pluck("hash", &found).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, hash := range found {
+			owned[hash] = true
+		}
+	}
+	return owned, nil
+}
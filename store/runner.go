@@ -0,0 +1,188 @@
+package store
+
+import (
+	"slices"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/models"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// runnerClaimBatch 领取构建时每次检查的排队构建数量上限
+// Max number of queued builds checked per claim
+const runnerClaimBatch = 50
+
+type runnerType struct {
+	db *gorm.DB
+}
+
+// Runner 远程构建执行器及其构建
+// Remote build runners and their builds
+var Runner = runnerType{
+	db: DB,
+}
+
+// Create 创建执行器
+// Create a runner
+func (r *runnerType) Create(runner *models.Runner) error {
+	return r.db.Create(runner).Error
+}
+
+// List 获取所有执行器
+// List all runners
+func (r *runnerType) List() (runners []models.Runner, err error) {
+	err = r.db.Order("id").Find(&runners).Error
+	return
+}
+
+// GetByID 获取执行器
+// Get a runner
+func (r *runnerType) GetByID(id uint) (runner *models.Runner, err error) {
+	runner = &models.Runner{}
+	err = r.db.Where("id = ?", id).First(runner).Error
+	if err != nil {
+		return nil, err
+	}
+	return runner, nil
+}
+
+// GetByHash 根据令牌哈希获取执行器，删除必须立即生效，因此从主库读取
+// Get a runner by token hash, deletion must take effect immediately so read from the primary
+func (r *runnerType) GetByHash(hash string) (runner *models.Runner, err error) {
+	runner = &models.Runner{}
+	err = r.db.Clauses(dbresolver.Write).Where("hash = ?", hash).First(runner).Error
+	if err != nil {
+		return nil, err
+	}
+	return runner, nil
+}
+
+// Delete 删除执行器，直接删除使令牌哈希无法再被查到；正在执行的构建在租约过期后视为失败
+// Delete a runner permanently so the token hash can no longer be found; its running builds fail once their lease expires
+func (r *runnerType) Delete(runner *models.Runner) error {
+	return r.db.Unscoped().Delete(runner).Error
+}
+
+// Register 保存执行器注册时上报的标签、并发数和版本
+// Save the labels, concurrency and version reported when the runner registers
+func (r *runnerType) Register(runner *models.Runner, now time.Time) error {
+	runner.LastSeenAt = &now
+	return r.db.Model(runner).Select("labels", "concurrency", "version", "last_seen_at").Updates(runner).Error
+}
+
+// Heartbeat 记录执行器的心跳并将其正在执行的构建的租约延长到 until，返回仍在执行的构建ID
+// Record a heartbeat of the runner and extend the lease of its running builds to until, returning the IDs of the builds still running
+func (r *runnerType) Heartbeat(runner *models.Runner, now, until time.Time) (running []uint, err error) {
+	runner.LastSeenAt = &now
+	if err := r.db.Model(runner).UpdateColumn("last_seen_at", now).Error; err != nil {
+		return nil, err
+	}
+	builds := r.db.Model(&models.RunnerBuild{}).Where("runner_id = ? AND status = ?", runner.ID, constants.GitRunBuilding)
+	if err := builds.Session(&gorm.Session{}).Update("lease_until", until).Error; err != nil {
+		return nil, err
+	}
+	err = builds.Session(&gorm.Session{}).Pluck("id", &running).Error
+	return running, err
+}
+
+// QueueBuild 创建排队的构建，同一仓库绑定尚未被领取的旧构建标记为失败，避免旧提交覆盖新提交
+// Create a queued build, failing older builds of the same binding that were not taken yet so an older commit never overrides a newer one
+func (r *runnerType) QueueBuild(build *models.RunnerBuild, now time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.RunnerBuild{}).
+			Where("integration_id = ? AND status = ?", build.IntegrationID, constants.GitRunQueued).
+			Updates(map[string]any{"status": constants.GitRunFailed, "error": "superseded by a newer push", "finished_at": now}).Error
+		if err != nil {
+			return err
+		}
+		build.Status = constants.GitRunQueued
+		return tx.Omit("Integration").Create(build).Error
+	})
+}
+
+// ClaimBuild 为执行器领取最早排队且标签匹配的构建并设置租约，执行器已达到并发数或没有可领取的构建时返回空；
+// 多个执行器同时领取同一构建时只有一个成功
+// Claim the oldest queued build whose labels the runner has and set its lease, nil when the runner is at its concurrency or nothing is claimable;
+// only one runner succeeds when several claim the same build at once
+func (r *runnerType) ClaimBuild(runner *models.Runner, now, until time.Time) (*models.RunnerBuild, error) {
+	var running int64
+	err := r.db.Model(&models.RunnerBuild{}).Where("runner_id = ? AND status = ?", runner.ID, constants.GitRunBuilding).Count(&running).Error
+	if err != nil || running >= int64(max(runner.Concurrency, 1)) {
+		return nil, err
+	}
+	var builds []models.RunnerBuild
+	err = r.db.Where("status = ?", constants.GitRunQueued).Preload("Integration").Order("id").Limit(runnerClaimBatch).Find(&builds).Error
+	if err != nil {
+		return nil, err
+	}
+	for i := range builds {
+		build := &builds[i]
+		if !hasLabels(runner.Labels, build.Labels) {
+			continue
+		}
+		result := r.db.Model(&models.RunnerBuild{}).
+			Where("id = ? AND status = ?", build.ID, constants.GitRunQueued).
+			Updates(map[string]any{"status": constants.GitRunBuilding, "runner_id": runner.ID, "lease_until": until, "started_at": now})
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+		build.Status = constants.GitRunBuilding
+		build.RunnerID = &runner.ID
+		build.LeaseUntil = &until
+		build.StartedAt = &now
+		return build, nil
+	}
+	return nil, nil
+}
+
+// GetBuild 获取执行器正在执行的构建及其仓库绑定、站点和项目
+// Get a build the runner is running with its binding, site and project
+func (r *runnerType) GetBuild(runnerID, id uint) (build *models.RunnerBuild, err error) {
+	build = &models.RunnerBuild{}
+	err = r.db.Where("id = ? AND runner_id = ? AND status = ?", id, runnerID, constants.GitRunBuilding).
+		Preload("Integration").Preload("Integration.Site").Preload("Integration.Site.Project").First(build).Error
+	if err != nil {
+		return nil, err
+	}
+	return build, nil
+}
+
+// FinishBuild 结束正在执行的构建，构建已被结束时返回假
+// Finish a running build, reporting false when it was already finished
+func (r *runnerType) FinishBuild(build *models.RunnerBuild, status, buildErr string, now time.Time) (bool, error) {
+	result := r.db.Model(&models.RunnerBuild{}).
+		Where("id = ? AND status = ?", build.ID, constants.GitRunBuilding).
+		Updates(map[string]any{"status": status, "error": buildErr, "finished_at": now})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+	build.Status = status
+	build.Error = buildErr
+	build.FinishedAt = &now
+	return true, nil
+}
+
+// ListExpired 获取租约已过期的构建及其仓库绑定、站点和项目，即执行器已停止心跳
+// List builds whose lease expired because their runner stopped sending heartbeats, with their binding, site and project
+func (r *runnerType) ListExpired(now time.Time, limit int) (builds []models.RunnerBuild, err error) {
+	err = r.db.Where("status = ? AND lease_until < ?", constants.GitRunBuilding, now).
+		Preload("Integration").Preload("Integration.Site").Preload("Integration.Site.Project").
+		Order("id").Limit(limit).Find(&builds).Error
+	return
+}
+
+// hasLabels 执行器的标签是否包含构建要求的全部标签
+// Whether the runner has every label the build asks for
+func hasLabels(have, want []string) bool {
+	for _, label := range want {
+		if !slices.Contains(have, label) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,170 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxSearchTerms 查询中参与匹配的词数上限
+// Max number of terms of a query that are matched
+const maxSearchTerms = 8
+
+// minTrigramTerm FTS5 trigram 分词能匹配的最短词长，更短的词回退到 LIKE
+// Shortest term the FTS5 trigram tokenizer can match, shorter terms fall back to LIKE
+const minTrigramTerm = 3
+
+type searchType struct {
+	db *gorm.DB
+}
+
+// Search 全文检索项目、站点、用户和组织，所有词都需匹配；userID 为 0 时不按成员关系过滤，供管理员使用
+// Full-text search of projects, sites, users and organizations, every term has to match; a userID of 0 skips the membership filter and is meant for administrators
+var Search = searchType{
+	db: DB,
+}
+
+// SearchTerms 将查询按空白拆分为小写的词，最多保留 maxSearchTerms 个
+// Split a query into lowercase terms on whitespace, keeping at most maxSearchTerms
+func SearchTerms(query string) []string {
+	terms := strings.Fields(strings.ToLower(query))
+	return terms[:min(len(terms), maxSearchTerms)]
+}
+
+// Projects 按名称、显示名称、描述、所有者名称或站点域名检索项目
+// Search projects by name, display name, description, owner name or site domain
+func (s *searchType) Projects(ctx context.Context, terms []string, userID uint, page, limit int) (projects []models.Project, total int64, err error) {
+	query := s.db.WithContext(ctx).Where(s.db.Where(s.match("projects", terms)).
+		Or("owner_type = ? AND owner_id IN (?)", constants.OwnerTypeUser, s.db.Model(&models.User{}).Select("id").Where(s.match("users", terms))).
+		Or("owner_type = ? AND owner_id IN (?)", constants.OwnerTypeOrg, s.db.Model(&models.Organization{}).Select("id").Where(s.match("organizations", terms))).
+		Or("id IN (?)", s.db.Model(&models.Site{}).Select("project_id").Where(s.domainSites(terms))))
+	if userID != 0 {
+		query = query.Where("id IN (?)", s.accessibleProjects(userID))
+	}
+	return Paginate[models.Project](query.Order(s.rank("name", terms)), page, limit)
+}
+
+// Sites 按名称、描述、子域或域名检索站点
+// Search sites by name, description, subdomain or domain
+func (s *searchType) Sites(ctx context.Context, terms []string, userID uint, page, limit int) (sites []models.Site, total int64, err error) {
+	query := s.db.WithContext(ctx).Where(s.db.Where(s.match("sites", terms)).Or(s.domainSites(terms)))
+	projects := s.db.Model(&models.Project{}).Select("id")
+	if userID != 0 {
+		projects = projects.Where("id IN (?)", s.accessibleProjects(userID))
+	}
+	query = query.Where("project_id IN (?)", projects)
+	return Paginate[models.Site](query.Preload("Project").Order(s.rank("name", terms)), page, limit)
+}
+
+// Users 按用户名或显示名称检索用户
+// Search users by name or display name
+func (s *searchType) Users(ctx context.Context, terms []string, page, limit int) (users []models.User, total int64, err error) {
+	return Paginate[models.User](s.db.WithContext(ctx).Where(s.match("users", terms)).Order(s.rank("name", terms)), page, limit)
+}
+
+// Orgs 按名称或显示名称检索组织，userID 不为 0 时只返回其所在的组织
+// Search organizations by name or display name, only returning the user's organizations when userID is not 0
+func (s *searchType) Orgs(ctx context.Context, terms []string, userID uint, page, limit int) (orgs []models.Organization, total int64, err error) {
+	query := s.db.WithContext(ctx).Where(s.match("organizations", terms))
+	if userID != 0 {
+		query = query.Where("id IN (?)", s.db.Model(&models.OrgMember{}).Select("organization_id").Where("user_id = ?", userID))
+	}
+	return Paginate[models.Organization](query.Order(s.rank("name", terms)), page, limit)
+}
+
+// accessibleProjects 用户在其中拥有角色的项目ID，与 Project.UserRole 的判断一致
+// IDs of the projects the user holds a role in, matching Project.UserRole
+func (s *searchType) accessibleProjects(userID uint) *gorm.DB {
+	orgs := s.db.Model(&models.OrgMember{}).Select("organization_id").Where("user_id = ?", userID)
+	members := s.db.Model(&models.ProjectMember{}).Select("project_id").Where("user_id = ?", userID)
+	teams := s.db.Model(&models.ProjectTeam{}).Select("project_teams.project_id").
+		Joins("JOIN team_members ON team_members.team_id = project_teams.team_id").
+		Where("team_members.user_id = ?", userID)
+	return s.db.Model(&models.Project{}).Select("id").
+		Where("owner_type = ? AND owner_id = ?", constants.OwnerTypeUser, userID).
+		Or("owner_type = ? AND owner_id IN (?)", constants.OwnerTypeOrg, orgs).
+		Or("id IN (?)", members).
+		Or("owner_type = ? AND id IN (?)", constants.OwnerTypeOrg, teams)
+}
+
+// domainSites 站点的条件：域名或自定义域名包含整个查询，或查询为 <子域>.<serve.base-domain>
+// Condition on sites: a domain or custom domain contains the whole query, or the query is <subdomain>.<serve.base-domain>
+func (s *searchType) domainSites(terms []string) *gorm.DB {
+	query := strings.Join(terms, " ")
+	pattern := likePattern(query)
+	cond := s.db.Where("id IN (?)", s.db.Model(&models.SiteDomain{}).Select("site_id").Where("domain LIKE ? ESCAPE '\\'", pattern)).
+		Or("id IN (?)", s.db.Model(&models.CustomDomain{}).Select("site_id").Where("domain LIKE ? ESCAPE '\\'", pattern))
+	if sub, ok := strings.CutSuffix(query, "."+config.ServeBaseDomain); ok && config.ServeBaseDomain != "" {
+		cond = cond.Or("LOWER(sub_domain) = ?", sub)
+	}
+	return cond
+}
+
+// match 表中所有词都匹配的条件，Postgres 使用 tsvector 前缀匹配，SQLite 使用 FTS5 trigram 子串匹配
+// Condition that every term matches the table, Postgres uses tsvector prefix matching and SQLite FTS5 trigram substring matching
+func (s *searchType) match(table string, terms []string) clause.Expression {
+	var search models.SearchTable
+	for _, t := range models.SearchTables {
+		if t.Table == table {
+			search = t
+		}
+	}
+	if s.db.Dialector.Name() == "postgres" {
+		lexemes := make([]string, 0, len(terms))
+		for _, term := range terms {
+			lexemes = append(lexemes, "'"+strings.NewReplacer(`\`, `\\`, `'`, `''`).Replace(term)+"':*")
+		}
+		return clause.Expr{SQL: search.Vector() + " @@ to_tsquery('simple', ?)", Vars: []any{strings.Join(lexemes, " & ")}}
+	}
+	exprs := make([]clause.Expression, 0, len(terms))
+	for _, term := range terms {
+		if utf8.RuneCountInString(term) >= minTrigramTerm {
+			exprs = append(exprs, clause.Expr{
+				SQL:  fmt.Sprintf("%s.id IN (SELECT rowid FROM %[2]s WHERE %[2]s MATCH ?)", table, search.FTSTable()),
+				Vars: []any{`"` + strings.ReplaceAll(term, `"`, `""`) + `"`},
+			})
+			continue
+		}
+		likes := make([]string, 0, len(search.Columns))
+		vars := make([]any, 0, len(search.Columns))
+		for _, column := range search.Columns {
+			likes = append(likes, fmt.Sprintf("LOWER(%s.%s) LIKE ? ESCAPE '\\'", table, column))
+			vars = append(vars, likePattern(term))
+		}
+		exprs = append(exprs, clause.Expr{SQL: "(" + strings.Join(likes, " OR ") + ")", Vars: vars})
+	}
+	return clause.And(exprs...)
+}
+
+// rank 排序表达式：名称与查询相同的排在最前，其次是以查询开头的名称
+// Ordering expression: names equal to the query come first, followed by names starting with it
+func (s *searchType) rank(column string, terms []string) clause.OrderBy {
+	query := strings.Join(terms, " ")
+	return clause.OrderBy{Expression: clause.Expr{
+		SQL:                fmt.Sprintf("CASE WHEN LOWER(%[1]s) = ? THEN 0 WHEN LOWER(%[1]s) LIKE ? ESCAPE '\\' THEN 1 ELSE 2 END", column),
+		Vars:               []any{query, strings.TrimPrefix(likePattern(query), "%")},
+		WithoutParentheses: true,
+	}}
+}
+
+// likeEscaper 转义 LIKE 通配符 Escapes LIKE wildcards
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// likePattern 转义 LIKE 通配符后匹配包含该词的值
+// Pattern matching values containing the term, with LIKE wildcards escaped
+func likePattern(term string) string {
+	return "%" + likeEscaper.Replace(term) + "%"
+}
+
+// likePrefix 转义 LIKE 通配符后匹配以 prefix 开头的值
+// Pattern matching values starting with prefix, with LIKE wildcards escaped
+func likePrefix(prefix string) string {
+	return likeEscaper.Replace(prefix) + "%"
+}
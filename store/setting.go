@@ -0,0 +1,48 @@
+package store
+
+import (
+	"time"
+
+	"github.com/LiteyukiStudio/spage/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type settingType struct {
+	db *gorm.DB
+}
+
+// Setting 管理员在运行时修改的动态配置项
+// Dynamic settings changed by administrators at runtime
+var Setting = settingType{
+	db: DB,
+}
+
+// Overrides 获取数据库中保存的所有动态配置项，键为配置项名称，值为 JSON 编码的值
+// Get all dynamic settings stored in the database, keyed by setting key with JSON encoded values
+func (s *settingType) Overrides() (map[string]string, error) {
+	var settings []models.Setting
+	if err := s.db.Find(&settings).Error; err != nil {
+		return nil, err
+	}
+	overrides := make(map[string]string, len(settings))
+	for _, setting := range settings {
+		overrides[setting.Key] = setting.Value
+	}
+	return overrides, nil
+}
+
+// Save 创建或覆盖动态配置项
+// Create or overwrite a dynamic setting
+func (s *settingType) Save(key, value string, userID uint) error {
+	return s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "updated_at", "updated_by"}),
+	}).Create(&models.Setting{Key: key, Value: value, UpdatedAt: time.Now(), UpdatedBy: userID}).Error
+}
+
+// Delete 删除动态配置项，恢复为配置文件中的值
+// Delete a dynamic setting, restoring the value of the configuration file
+func (s *settingType) Delete(key string) error {
+	return s.db.Where("key = ?", key).Delete(&models.Setting{}).Error
+}
@@ -1,10 +1,28 @@
 package store
 
 import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/constants"
 	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/storage"
+	"github.com/LiteyukiStudio/spage/utils"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrReleaseActive 版本正在使用中，不能删除
+// The version is in use and cannot be deleted
+var ErrReleaseActive = errors.New("release is active")
+
+// ErrPurgeLimit 项目一段时间内的路径清除次数已达上限
+// The project has reached its path purge limit for the period
+var ErrPurgeLimit = errors.New("purge limit reached")
+
 type SiteType struct {
 	db *gorm.DB
 }
@@ -16,7 +34,12 @@ var Site = SiteType{
 // Create 创建站点
 // Create Site
 func (s *SiteType) Create(site *models.Site) (err error) {
-	return s.db.Create(site).Error
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(site).Error; err != nil {
+			return err
+		}
+		return syncDomains(tx, site)
+	})
 }
 
 // GetByID 根据id获取站点信息
@@ -27,16 +50,136 @@ func (s *SiteType) GetByID(id uint) (site *models.Site, err error) {
 	return
 }
 
+// GetByDomain 根据自定义域名获取站点
+// Get Site by custom domain
+func (s *SiteType) GetByDomain(domain string) (site *models.Site, err error) {
+	site = &models.Site{}
+	err = s.db.Joins("JOIN site_domains ON site_domains.site_id = sites.id").
+		Where("site_domains.domain = ?", strings.ToLower(domain)).
+		Preload("Project").First(site).Error
+	return
+}
+
+// GetBySubDomain 根据子域前缀获取站点
+// Get Site by subdomain prefix
+func (s *SiteType) GetBySubDomain(subDomain string) (site *models.Site, err error) {
+	site = &models.Site{}
+	err = s.db.Where("LOWER(sub_domain) = ?", subDomain).Preload("Project").First(site).Error
+	return
+}
+
+// GetProjectPrimary 根据小写的项目名获取项目主机名对应的站点，项目未设置主站点时使用最早创建的站点
+// Get the site served at a project host by the lowercase project name, the oldest site when the project has no primary site
+func (s *SiteType) GetProjectPrimary(projectName string) (site *models.Site, err error) {
+	project := &models.Project{}
+	if err = s.db.Where("LOWER(name) = ?", projectName).First(project).Error; err != nil {
+		return nil, err
+	}
+	query := s.db.Where("project_id = ?", project.ID)
+	if project.PrimarySiteID != nil {
+		query = query.Where("id = ?", *project.PrimarySiteID)
+	}
+	site = &models.Site{}
+	err = query.Order("id").Preload("Project").First(site).Error
+	return
+}
+
 func (s *SiteType) Update(site *models.Site) (err error) {
-	return s.db.Updates(site).Error
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Updates(site).Error; err != nil {
+			return err
+		}
+		return syncDomains(tx, site)
+	})
+}
+
+// syncDomains 用站点当前的域名重建域名索引
+// Rebuild the domain lookup rows from the site's current domains
+func syncDomains(tx *gorm.DB, site *models.Site) error {
+	if err := tx.Where("site_id = ?", site.ID).Delete(&models.SiteDomain{}).Error; err != nil {
+		return err
+	}
+	var domains []models.SiteDomain
+	for _, domain := range site.Domains {
+		domains = append(domains, models.SiteDomain{SiteID: site.ID, Domain: strings.ToLower(domain)})
+	}
+	if len(domains) == 0 {
+		return nil
+	}
+	return tx.Create(&domains).Error
+}
+
+// SetOGPreview 设置站点是否生成预览图，单独更新以支持写入 false
+// Set whether the site generates preview images, updated separately to allow writing false
+func (s *SiteType) SetOGPreview(site *models.Site, enabled bool) (err error) {
+	return s.db.Model(site).Update("og_preview", enabled).Error
+}
+
+// SetHeaders 设置站点自定义响应头，单独更新以支持清空
+// Set the site's custom response headers, updated separately to allow clearing them
+func (s *SiteType) SetHeaders(site *models.Site, headers []string) (err error) {
+	site.Headers = headers
+	return s.db.Model(site).Select("headers").Updates(site).Error
+}
+
+// SetCacheRules 更新站点按路径的 Cache-Control 规则
+// Update the Cache-Control rules of a site
+func (s *SiteType) SetCacheRules(site *models.Site, rules []string) (err error) {
+	site.CacheRules = rules
+	return s.db.Model(site).Select("cache_rules").Updates(site).Error
+}
+
+// SetAccess 设置站点的访问保护方式和访问密码哈希，单独更新以支持清空密码
+// Set the site's access protection and access password hash, updated separately to allow clearing the password
+func (s *SiteType) SetAccess(site *models.Site, mode constants.SiteAccessMode, passwordHash string) (err error) {
+	site.AccessMode = mode
+	site.AccessPassword = passwordHash
+	return s.db.Model(site).Select("access_mode", "access_password").Updates(site).Error
+}
+
+// SetIPRules 设置站点的 IP 允许和拒绝规则，单独更新以支持清空
+// Set the site's IP allow and deny rules, updated separately to allow clearing them
+func (s *SiteType) SetIPRules(site *models.Site, allow, deny []string) (err error) {
+	site.AllowIPs = allow
+	site.DenyIPs = deny
+	return s.db.Model(site).Select("allow_ips", "deny_ips").Updates(site).Error
 }
 
+// SetCrawling 设置站点是否生成 sitemap.xml 以及 robots.txt 的策略和自定义内容，单独更新以支持写入 false 和清空
+// Set whether the site generates sitemap.xml and its robots policy and custom content, updated separately to allow writing false and clearing them
+func (s *SiteType) SetCrawling(site *models.Site, sitemap bool, robotsPolicy, robotsTxt string) (err error) {
+	site.Sitemap = sitemap
+	site.RobotsPolicy = robotsPolicy
+	site.RobotsTxt = robotsTxt
+	return s.db.Model(site).Select("sitemap", "robots_policy", "robots_txt").Updates(site).Error
+}
+
+// SetTransferLimits 设置站点每月流量的软限制和硬限制字节数，为空时恢复为实例配置
+// Set the soft and hard monthly transfer limits of the site in bytes, nil falls back to the instance configuration
+func (s *SiteType) SetTransferLimits(site *models.Site, soft, hard *int64) (err error) {
+	site.TransferSoftLimit = soft
+	site.TransferHardLimit = hard
+	return s.db.Model(site).Select("transfer_soft_limit", "transfer_hard_limit").Updates(site).Error
+}
+
+// GetByIDs 获取多个站点及其项目，不存在的站点被忽略
+// Get several sites with their projects, missing sites are skipped
+func (s *SiteType) GetByIDs(ids []uint) (sites []models.Site, err error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	err = s.db.Where("id IN ?", ids).Preload("Project").Find(&sites).Error
+	return
+}
+
+// Delete 将站点移入回收站，版本和文件保留到站点被彻底删除
+// Move the site to the trash, its versions and files are kept until the site is purged
 func (s *SiteType) Delete(site *models.Site) (err error) {
 	return s.db.Delete(site).Error
 }
 
 func (s *SiteType) GetReleaseList(siteID uint) (releases []*models.SiteRelease, err error) {
-	err = s.db.Where("site_id = ?", siteID).Preload("File").Find(&releases).Error
+	err = s.db.Where("site_id = ?", siteID).Order("created_at DESC").Preload("File").Find(&releases).Error
 	return
 }
 
@@ -48,12 +191,344 @@ func (s *SiteType) GetReleaseById(id uint) (release *models.SiteRelease, err err
 
 func (s *SiteType) GetLatestRelease(site *models.Site) (release *models.SiteRelease, err error) {
 	release = &models.SiteRelease{}
-	err = s.db.Where("site_id = ? AND tag = ?", site.ID, "latest").Preload("File").First(release).Error
+	err = s.db.Where("site_id = ? AND tag = ?", site.ID, constants.ReleaseTagLatest).Order("id DESC").Preload("File").First(release).Error
+	return
+}
+
+// GetSiteRelease 获取站点的指定版本，不包括 latest 记录
+// Get the given version of a site, excluding the latest record
+func (s *SiteType) GetSiteRelease(siteID, id uint) (release *models.SiteRelease, err error) {
+	release = &models.SiteRelease{}
+	err = s.db.Where("id = ? AND site_id = ? AND tag <> ?", id, siteID, constants.ReleaseTagLatest).Preload("File").First(release).Error
+	return
+}
+
+// ListVersions 分页获取站点的部署版本，不包括 latest 记录，status 不为空时只返回该状态的版本
+// List the deployed versions of a site with pagination, excluding the latest record, only versions in status when it is set
+func (s *SiteType) ListVersions(ctx context.Context, siteID uint, status constants.DeploymentStatus, list utils.ListQuery) (releases []models.SiteRelease, total int64, err error) {
+	query := s.db.WithContext(ctx).Preload("File")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	return PaginateList[models.SiteRelease](query, list,
+		"site_id = ? AND tag <> ? AND id NOT IN (?)", siteID, constants.ReleaseTagLatest, previewReleaseIDs(s.db))
+}
+
+// ListVersionsBySites 获取多个站点最近的部署版本，每个站点最多 limit 个，不包括 latest 记录和预览部署
+// Get the latest deployed versions of several sites, at most limit per site, excluding the latest records and preview deployments
+func (s *SiteType) ListVersionsBySites(siteIDs []uint, limit int) (map[uint][]models.SiteRelease, error) {
+	grouped := map[uint][]models.SiteRelease{}
+	if len(siteIDs) == 0 {
+		return grouped, nil
+	}
+	releases, err := FirstPerGroup[models.SiteRelease](s.db.Preload("File"), "site_id", "id DESC", limit,
+		"site_id IN ? AND tag <> ? AND id NOT IN (?)", siteIDs, constants.ReleaseTagLatest, previewReleaseIDs(s.db))
+	if err != nil {
+		return nil, err
+	}
+	for _, release := range releases {
+		grouped[release.SiteID] = append(grouped[release.SiteID], release)
+	}
+	return grouped, nil
+}
+
+// ActiveReleaseIDs 获取多个站点当前激活的版本ID，没有激活版本的站点不在结果中
+// Get the active version IDs of several sites, sites without an active version are left out
+func (s *SiteType) ActiveReleaseIDs(siteIDs []uint) (map[uint]uint, error) {
+	active := map[uint]uint{}
+	if len(siteIDs) == 0 {
+		return active, nil
+	}
+	var latest []models.SiteRelease
+	if err := s.db.Where("site_id IN ? AND tag = ?", siteIDs, constants.ReleaseTagLatest).Order("id DESC").Find(&latest).Error; err != nil {
+		return nil, err
+	}
+	// 与 GetLatestRelease 一样只看每个站点最新的 latest 记录 Only the newest latest record of each site counts, as in GetLatestRelease
+	seen := map[uint]bool{}
+	for _, release := range latest {
+		if !seen[release.SiteID] && release.ActiveReleaseID != nil {
+			active[release.SiteID] = *release.ActiveReleaseID
+		}
+		seen[release.SiteID] = true
+	}
+	return active, nil
+}
+
+// GetReleasesByIDs 批量获取版本及其文件
+// Get several versions with their files at once
+func (s *SiteType) GetReleasesByIDs(ids []uint) (map[uint]models.SiteRelease, error) {
+	releases := map[uint]models.SiteRelease{}
+	if len(ids) == 0 {
+		return releases, nil
+	}
+	var list []models.SiteRelease
+	if err := s.db.Where("id IN ?", ids).Preload("File").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	for _, release := range list {
+		releases[release.ID] = release
+	}
+	return releases, nil
+}
+
+// ListReleases 分页获取站点的所有 release 记录
+// List all release records of a site with pagination
+func (s *SiteType) ListReleases(ctx context.Context, siteID uint, list utils.ListQuery) (releases []models.SiteRelease, total int64, err error) {
+	return PaginateList[models.SiteRelease](s.db.WithContext(ctx).Preload("File"), list, "site_id = ?", siteID)
+}
+
+// previewReleaseIDs 预览部署使用的版本，不参与生产版本列表和回滚
+// Versions used by preview deployments, left out of production version lists and rollbacks
+func previewReleaseIDs(db *gorm.DB) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true}).Model(&models.SitePreview{}).Unscoped().Select("release_id")
+}
+
+// GetPreviousRelease 获取指定版本之前最近的一个可用版本，用于回滚
+// Get the newest servable version before the given one, used for rollbacks
+func (s *SiteType) GetPreviousRelease(siteID, beforeID uint) (release *models.SiteRelease, err error) {
+	release = &models.SiteRelease{}
+	err = s.db.Where("site_id = ? AND tag <> ? AND id < ? AND status = ?", siteID, constants.ReleaseTagLatest, beforeID, constants.DeploymentStatusReady).
+		Where("id NOT IN (?)", previewReleaseIDs(s.db)).
+		Order("id DESC").Preload("File").First(release).Error
 	return
 }
 
-func (s *SiteType) CreateRelease(release *models.SiteRelease) (err error) {
-	return s.db.Create(release).Error
+// ActivateRelease 在事务中将站点的 latest 记录切换到指定版本，返回切换前激活的版本ID
+// Switch the site's latest record to the given version in a transaction, returning the previously active version ID
+func (s *SiteType) ActivateRelease(ctx context.Context, release *models.SiteRelease) (previousID uint, err error) {
+	// 站点还没有 latest 记录时行锁锁不住任何行，由站点的锁串行化 Row locks lock nothing while the site has no latest record yet, so the site's lock serializes activations
+	err = Lock.Tx(ctx, LockKey("site-activation", release.SiteID), func(tx *gorm.DB) error {
+		latest := &models.SiteRelease{}
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("site_id = ? AND tag = ?", release.SiteID, constants.ReleaseTagLatest).
+			Order("id DESC").Limit(1).Find(latest).Error
+		if err != nil {
+			return err
+		}
+		activeID := release.ID
+		if latest.ID == 0 {
+			return tx.Create(&models.SiteRelease{
+				SiteID:          release.SiteID,
+				Tag:             constants.ReleaseTagLatest,
+				FileID:          release.FileID,
+				Status:          release.Status,
+				PreviewPath:     release.PreviewPath,
+				PreviewHash:     release.PreviewHash,
+				ActiveReleaseID: &activeID,
+			}).Error
+		}
+		if latest.ActiveReleaseID != nil {
+			previousID = *latest.ActiveReleaseID
+		}
+		return tx.Model(latest).Updates(map[string]any{
+			"file_id":           release.FileID,
+			"status":            release.Status,
+			"preview_path":      release.PreviewPath,
+			"preview_hash":      release.PreviewHash,
+			"active_release_id": activeID,
+		}).Error
+	})
+	return
+}
+
+// DeactivateRelease 删除站点的 latest 记录使站点下线，返回下线前激活的版本ID
+// Delete the site's latest record to take the site offline, returning the version ID active before
+func (s *SiteType) DeactivateRelease(ctx context.Context, siteID uint) (previousID uint, err error) {
+	err = Lock.Tx(ctx, LockKey("site-activation", siteID), func(tx *gorm.DB) error {
+		latest := &models.SiteRelease{}
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("site_id = ? AND tag = ?", siteID, constants.ReleaseTagLatest).
+			Order("id DESC").Limit(1).Find(latest).Error
+		if err != nil || latest.ID == 0 {
+			return err
+		}
+		if latest.ActiveReleaseID != nil {
+			previousID = *latest.ActiveReleaseID
+		}
+		return tx.Unscoped().Where("site_id = ? AND tag = ?", siteID, constants.ReleaseTagLatest).Delete(&models.SiteRelease{}).Error
+	})
+	return
+}
+
+// SetSchedule 保存版本的状态、定时上线时间和到期设置
+// Save the status, scheduled go-live time and expiry settings of a version
+func (s *SiteType) SetSchedule(release *models.SiteRelease) (err error) {
+	return s.db.Model(release).Select("status", "publish_at", "expires_at", "expire_action").Updates(release).Error
+}
+
+// SetScan 保存版本的状态和内容扫描结果，审核通过后转为等待批准时一并保存批准后是否激活
+// Save the status and content scan result of a version, along with whether it is activated once approved when the review hands it on for approval
+func (s *SiteType) SetScan(release *models.SiteRelease) (err error) {
+	return s.db.Model(release).Select("status", "scan_findings", "scan_activate", "approval_activate").Updates(release).Error
+}
+
+// SetLinkReport 保存版本的链接检查报告
+// Save the link check report of a version
+func (s *SiteType) SetLinkReport(releaseID uint, report string) error {
+	return s.db.Model(&models.SiteRelease{}).Where("id = ?", releaseID).Update("link_report", report).Error
+}
+
+// SetApproval 保存维护者对等待批准的版本的决定，版本已被他人处理时返回假
+// Save a maintainer's decision on a version awaiting approval, reporting false when someone else already decided
+func (s *SiteType) SetApproval(release *models.SiteRelease) (bool, error) {
+	result := s.db.Model(release).Where("status = ?", constants.DeploymentStatusAwaitingApproval).
+		Select("status", "approval_activate").Updates(release)
+	return result.RowsAffected > 0, result.Error
+}
+
+// ListAwaitingApproval 分页获取项目各站点中等待批准的版本，最早部署的在前
+// List the versions awaiting approval across the sites of a project with pagination, the oldest first
+func (s *SiteType) ListAwaitingApproval(ctx context.Context, projectID uint, page, limit int) (releases []models.SiteRelease, total int64, err error) {
+	return PaginateOrdered[models.SiteRelease](s.db.WithContext(ctx).Preload("File").Preload("Site"), page, limit, "id",
+		"status = ? AND site_id IN (?)", constants.DeploymentStatusAwaitingApproval, s.db.Model(&models.Site{}).Select("id").Where("project_id = ?", projectID))
+}
+
+// ListQuarantined 分页获取所有站点中被内容扫描隔离的版本，最早隔离的在前
+// List the versions of all sites quarantined by the content scan with pagination, the oldest first
+func (s *SiteType) ListQuarantined(ctx context.Context, page, limit int) (releases []models.SiteRelease, total int64, err error) {
+	return PaginateOrdered[models.SiteRelease](s.db.WithContext(ctx).Preload("File").Preload("Site.Project"), page, limit, "id",
+		"status = ?", constants.DeploymentStatusQuarantined)
+}
+
+// DeleteVersion 删除不再激活的版本，没有其他版本引用其文件时一并删除文件和存储对象
+// Delete a version that is no longer active, also deleting its file and stored object when no other version references it
+func (s *SiteType) DeleteVersion(ctx context.Context, release *models.SiteRelease) error {
+	orphaned := false
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var active int64
+		err := tx.Model(&models.SiteRelease{}).Where("site_id = ? AND tag = ? AND active_release_id = ?", release.SiteID, constants.ReleaseTagLatest, release.ID).Count(&active).Error
+		if err != nil {
+			return err
+		}
+		if active > 0 {
+			return ErrReleaseActive
+		}
+		if err := tx.Delete(release).Error; err != nil {
+			return err
+		}
+		var count int64
+		if err := tx.Model(&models.SiteRelease{}).Where("file_id = ?", release.FileID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+		orphaned = true
+		if err := tx.Where("file_id = ?", release.FileID).Delete(&models.FileBlob{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.File{}, release.FileID).Error
+	})
+	if err != nil || !orphaned {
+		return err
+	}
+	return deleteFileObjects(ctx, &release.File)
+}
+
+// SetPinned 固定或取消固定版本 Pin or unpin a version
+func (s *SiteType) SetPinned(release *models.SiteRelease, pinned bool) error {
+	if err := s.db.Model(release).Update("pinned", pinned).Error; err != nil {
+		return err
+	}
+	release.Pinned = pinned
+	return nil
+}
+
+// SiteRetention 站点所属项目的版本保留设置，为空时使用实例配置
+// Release retention settings of the project of a site, the instance configuration applies when empty
+type SiteRetention struct {
+	SiteID          uint
+	KeepReleases    *int
+	KeepReleaseDays *int
+}
+
+// EffectiveRetention 生效的保留条件，项目未设置的条件使用 release.keep-last 和 release.keep-days
+// Retention conditions in effect, those the project leaves unset come from release.keep-last and release.keep-days
+func EffectiveRetention(keepReleases, keepReleaseDays *int) (keepLast, keepDays int) {
+	keepLast, keepDays = config.ReleaseKeepLast, config.ReleaseKeepDays
+	if keepReleases != nil {
+		keepLast = *keepReleases
+	}
+	if keepReleaseDays != nil {
+		keepDays = *keepReleaseDays
+	}
+	return keepLast, keepDays
+}
+
+// ListRetention 按站点ID顺序分批获取站点及其项目的保留设置，不包括回收站中的站点
+// List sites with the retention settings of their projects in batches by site ID, sites in the trash excluded
+func (s *SiteType) ListRetention(afterID uint, limit int) (sites []SiteRetention, err error) {
+	err = s.db.Model(&models.Site{}).
+		Select("sites.id AS site_id, projects.keep_releases, projects.keep_release_days").
+		Joins("JOIN projects ON projects.id = sites.project_id AND projects.deleted_at IS NULL").
+		Where("sites.id > ?", afterID).Order("sites.id").Limit(limit).Scan(&sites).Error
+	return
+}
+
+// ExpiredVersions 获取超出保留策略的版本：不在最新的 keepLast 个版本之中且创建于 before 之前，keepLast 为 0 或 before 为零值时不受对应条件保护；
+// 激活和固定的版本、预览部署以及等待上线、审核或批准的版本总是保留
+// List the versions past the retention policy: not among the newest keepLast versions and created before before, a keepLast of 0 or a zero before protects nothing by that condition;
+// the active and pinned versions, preview deployments and versions waiting to go live, for review or for approval are always kept
+func (s *SiteType) ExpiredVersions(ctx context.Context, siteID uint, keepLast int, before time.Time) (releases []models.SiteRelease, err error) {
+	db := s.db.Session(&gorm.Session{NewDB: true})
+	active := db.Model(&models.SiteRelease{}).Select("active_release_id").
+		Where("site_id = ? AND tag = ? AND active_release_id IS NOT NULL", siteID, constants.ReleaseTagLatest)
+	query := s.db.WithContext(ctx).Preload("File").
+		Where("site_id = ? AND tag <> ? AND id NOT IN (?)", siteID, constants.ReleaseTagLatest, previewReleaseIDs(s.db)).
+		Where("pinned = ? AND status IN ? AND id NOT IN (?)", false, []constants.DeploymentStatus{constants.DeploymentStatusReady, constants.DeploymentStatusFailed}, active)
+	if keepLast > 0 {
+		newest := db.Model(&models.SiteRelease{}).Select("id").
+			Where("site_id = ? AND tag <> ? AND id NOT IN (?)", siteID, constants.ReleaseTagLatest, previewReleaseIDs(s.db)).
+			Order("id DESC").Limit(keepLast)
+		query = query.Where("id NOT IN (?)", newest)
+	}
+	if !before.IsZero() {
+		query = query.Where("created_at < ?", before)
+	}
+	err = query.Order("id").Find(&releases).Error
+	return
+}
+
+// FeedReleases 项目最近上线的版本，按ID倒序并预加载站点和项目，用于部署订阅源；不包括预览部署以及定时、隔离、等待批准和失败的版本
+// Versions that went live most recently in the projects, newest first with their site and project preloaded, used by the deployment feeds; previews and scheduled, quarantined, awaiting or failed versions are left out
+func (s *SiteType) FeedReleases(ctx context.Context, projectIDs []uint, limit int) (releases []models.SiteRelease, err error) {
+	if len(projectIDs) == 0 {
+		return nil, nil
+	}
+	sites := s.db.Session(&gorm.Session{NewDB: true}).Model(&models.Site{}).Select("id").Where("project_id IN ?", projectIDs)
+	err = s.db.WithContext(ctx).
+		Where("site_id IN (?) AND tag <> ? AND status = ? AND id NOT IN (?)", sites, constants.ReleaseTagLatest, constants.DeploymentStatusReady, previewReleaseIDs(s.db)).
+		Preload("Site.Project").Order("id DESC").Limit(limit).Find(&releases).Error
+	return
+}
+
+// deleteFileObjects 删除不再被任何版本引用的文件的存储对象及其压缩版本
+// Delete the stored object and compressed versions of a file no version references anymore
+func deleteFileObjects(ctx context.Context, file *models.File) error {
+	if file.Path == "" {
+		return nil
+	}
+	if err := storage.Default.Delete(ctx, file.Path); err != nil {
+		return err
+	}
+	// 部署清单中的文件按内容共用压缩版本，不随版本删除 Files of manifests share compressed versions by content and are kept
+	if file.Manifest {
+		return nil
+	}
+	variants, err := storage.Default.List(ctx, storage.CompressedFilePrefix(file.ID))
+	if err != nil {
+		return err
+	}
+	for _, variant := range variants {
+		if err := storage.Default.Delete(ctx, variant.Key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SiteType) CreateRelease(ctx context.Context, release *models.SiteRelease) (err error) {
+	return s.db.WithContext(ctx).Create(release).Error
 }
 
 func (s *SiteType) DeleteRelease(release *models.SiteRelease) (err error) {
@@ -63,3 +538,79 @@ func (s *SiteType) DeleteRelease(release *models.SiteRelease) (err error) {
 func (s *SiteType) UpdateRelease(release *models.SiteRelease) (err error) {
 	return s.db.Updates(release).Error
 }
+
+// CountPurgesSince 统计项目自某时间以来的路径清除次数
+// Count path purges of a project since the given time
+func (s *SiteType) CountPurgesSince(projectID uint, since time.Time) (count int64, err error) {
+	err = s.db.Model(&models.SitePathPurge{}).Where("project_id = ? AND created_at >= ?", projectID, since).Count(&count).Error
+	return
+}
+
+// CreatePurge 在项目的锁内统计自 since 以来的路径清除次数并创建清除记录，达到 limit 时返回 ErrPurgeLimit，下架时同时写入屏蔽路径；
+// 记录在派生版本生成之前创建以占用次数，完成后由 FinishPurge 写入结果，失败时由 DeletePurge 删除
+// Count the path purges of the project since the given time and create the purge record under the project's lock, returning ErrPurgeLimit at the limit
+// and also writing blocked paths for takedowns; the record is created before the derived version to take up its slot, FinishPurge writes the
+// outcome and DeletePurge removes it on failure
+func (s *SiteType) CreatePurge(ctx context.Context, purge *models.SitePathPurge, since time.Time, limit int) (err error) {
+	return Lock.Tx(ctx, LockKey("site-purge", purge.ProjectID), func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.SitePathPurge{}).Where("project_id = ? AND created_at >= ?", purge.ProjectID, since).Count(&count).Error; err != nil {
+			return err
+		}
+		if count >= int64(limit) {
+			return ErrPurgeLimit
+		}
+		if err := tx.Create(purge).Error; err != nil {
+			return err
+		}
+		if !purge.Takedown {
+			return nil
+		}
+		for _, pattern := range purge.Paths {
+			if err := tx.Create(&models.SiteBlockedPath{SiteID: purge.SiteID, Pattern: pattern, PurgeID: purge.ID}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// FinishPurge 写入路径清除的结果
+// Write the outcome of a path purge
+func (s *SiteType) FinishPurge(purge *models.SitePathPurge) (err error) {
+	return s.db.Model(purge).Select("removed", "from_release_id", "to_release_id").Updates(purge).Error
+}
+
+// DeletePurge 删除未能完成的路径清除记录及其屏蔽路径，不再计入次数
+// Delete a path purge that could not complete together with its blocked paths, so it no longer counts
+func (s *SiteType) DeletePurge(purge *models.SitePathPurge) (err error) {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("purge_id = ?", purge.ID).Delete(&models.SiteBlockedPath{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(purge).Error
+	})
+}
+
+// ListPurges 分页获取站点的路径清除记录
+// List path purge records of a site with pagination
+func (s *SiteType) ListPurges(ctx context.Context, siteID uint, page, limit int) (purges []models.SitePathPurge, total int64, err error) {
+	return Paginate[models.SitePathPurge](s.db.WithContext(ctx), page, limit, "site_id = ?", siteID)
+}
+
+// ListBlockedPatterns 获取站点已下架的路径模式，所有历史发布和预览地址都应遵循
+// List the taken down path patterns of a site, honored by all historic releases and preview URLs
+func (s *SiteType) ListBlockedPatterns(siteID uint) (patterns []string, err error) {
+	err = s.db.Model(&models.SiteBlockedPath{}).Where("site_id = ?", siteID).Pluck("pattern", &patterns).Error
+	return
+}
+
+// CountActive 统计当前有激活版本的站点数量
+// Count the sites that currently have an active version
+func (s *SiteType) CountActive() (count int64, err error) {
+	err = s.db.Model(&models.SiteRelease{}).
+		Joins("JOIN sites ON sites.id = site_releases.site_id AND sites.deleted_at IS NULL").
+		Where("site_releases.tag = ? AND site_releases.active_release_id IS NOT NULL", constants.ReleaseTagLatest).
+		Count(&count).Error
+	return
+}
@@ -0,0 +1,78 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// CheckpointModes SQLite 支持的 WAL 检查点模式
+// WAL checkpoint modes supported by SQLite
+var CheckpointModes = []string{"passive", "full", "restart", "truncate"}
+
+// ErrCheckpointUnsupported 当前数据库不是 SQLite 时无法执行检查点
+// Checkpoints cannot run when the database is not SQLite
+var ErrCheckpointUnsupported = errors.New("checkpoints are only supported on sqlite")
+
+// CheckpointResult 一次 WAL 检查点的结果
+// Result of one WAL checkpoint
+type CheckpointResult struct {
+	Mode         string `json:"mode"`         // 检查点模式 Checkpoint mode
+	Busy         bool   `json:"busy"`         // 是否因其他连接未能完成 Whether other connections kept it from completing
+	Log          int    `json:"log"`          // WAL 中的页数 Pages in the WAL
+	Checkpointed int    `json:"checkpointed"` // 已写回数据库文件的页数 Pages written back to the database file
+}
+
+// sqliteDSN 生成 SQLite 连接串，每个新连接都会执行其中的 PRAGMA；写事务以 IMMEDIATE 开始，等待锁时遵循 busy_timeout 而不是立即失败
+// Build the SQLite DSN whose PRAGMAs run on every new connection; write transactions begin IMMEDIATE so waiting for the lock honours busy_timeout instead of failing right away
+func sqliteDSN(config DBConfig) string {
+	query := url.Values{}
+	if config.JournalMode != "" {
+		query.Add("_pragma", "journal_mode("+config.JournalMode+")")
+	}
+	query.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", config.BusyTimeout))
+	if config.Synchronous != "" {
+		query.Add("_pragma", "synchronous("+config.Synchronous+")")
+	}
+	if config.ForeignKeys {
+		query.Add("_pragma", "foreign_keys(1)")
+	}
+	if config.WALAutoCheckpoint >= 0 {
+		query.Add("_pragma", fmt.Sprintf("wal_autocheckpoint(%d)", config.WALAutoCheckpoint))
+	}
+	if config.TxLock != "" {
+		query.Set("_txlock", config.TxLock)
+	}
+	return config.Path + "?" + query.Encode()
+}
+
+// Checkpoint 将 SQLite 的 WAL 写回数据库文件，mode 为空时使用 passive；
+// 关闭自动检查点交给 Litestream 等复制工具时，可在复制完成后调用以控制 WAL 的大小
+// Write the SQLite WAL back into the database file, passive when mode is empty;
+// with automatic checkpoints left to a replication tool such as Litestream, call it after replicating to bound the size of the WAL
+func Checkpoint(ctx context.Context, mode string) (*CheckpointResult, error) {
+	if DB == nil || DB.Dialector.Name() != "sqlite" {
+		return nil, ErrCheckpointUnsupported
+	}
+	if mode == "" {
+		mode = "passive"
+	}
+	mode = strings.ToLower(mode)
+	if !slices.Contains(CheckpointModes, mode) {
+		return nil, fmt.Errorf("unknown checkpoint mode %q", mode)
+	}
+	// PRAGMA 不接受参数绑定，模式已在上面校验 PRAGMA takes no bound parameters, the mode is checked above
+	var busy int
+	result := &CheckpointResult{Mode: mode}
+	err := DB.WithContext(WithoutTimeout(ctx)).
+		Raw("PRAGMA wal_checkpoint("+strings.ToUpper(mode)+")").
+		Row().Scan(&busy, &result.Log, &result.Checkpointed)
+	if err != nil {
+		return nil, err
+	}
+	result.Busy = busy != 0
+	return result, nil
+}
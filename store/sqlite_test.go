@@ -0,0 +1,72 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestSQLitePragmas(t *testing.T) {
+	config := DBConfig{
+		Path:              filepath.Join(t.TempDir(), "data.db"),
+		JournalMode:       "WAL",
+		BusyTimeout:       3000,
+		Synchronous:       "NORMAL",
+		ForeignKeys:       true,
+		WALAutoCheckpoint: 0,
+		TxLock:            "immediate",
+	}
+	db, err := gorm.Open(sqlite.Open(sqliteDSN(config)), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, _ := db.DB()
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	previous := DB
+	DB = db
+	t.Cleanup(func() { DB = previous })
+
+	// 迁移在关闭外键的连接上执行，结束后外键重新开启 Migrations run on a connection with foreign keys off, which are back on afterwards
+	if err := models.Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	var journal string
+	var busyTimeout, synchronous, foreignKeys, autoCheckpoint int
+	db.Raw("PRAGMA journal_mode").Scan(&journal)
+	db.Raw("PRAGMA busy_timeout").Scan(&busyTimeout)
+	db.Raw("PRAGMA synchronous").Scan(&synchronous)
+	db.Raw("PRAGMA foreign_keys").Scan(&foreignKeys)
+	db.Raw("PRAGMA wal_autocheckpoint").Scan(&autoCheckpoint)
+	if journal != "wal" || busyTimeout != 3000 || synchronous != 1 || foreignKeys != 1 || autoCheckpoint != 0 {
+		t.Errorf("pragmas = %s, %d, %d, %d, %d", journal, busyTimeout, synchronous, foreignKeys, autoCheckpoint)
+	}
+
+	if err := db.Create(&models.Project{Name: "wal"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	result, err := Checkpoint(context.Background(), "truncate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Mode != "truncate" || result.Busy || result.Log != 0 {
+		t.Errorf("Checkpoint = %+v", result)
+	}
+	if _, err := Checkpoint(context.Background(), "sideways"); err == nil {
+		t.Error("Checkpoint accepted an unknown mode")
+	}
+}
+
+func TestCheckpointUnsupported(t *testing.T) {
+	previous := DB
+	DB = nil
+	t.Cleanup(func() { DB = previous })
+	if _, err := Checkpoint(context.Background(), ""); !errors.Is(err, ErrCheckpointUnsupported) {
+		t.Errorf("Checkpoint without a database = %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/models"
+	"gorm.io/gorm"
+)
+
+type statsType struct {
+	db *gorm.DB
+}
+
+// Stats 管理员查看的实例统计
+// Instance statistics for administrators
+var Stats = statsType{
+	db: DB,
+}
+
+// DailyCount 一天内的数量
+// Count within a day
+type DailyCount struct {
+	Day   string // UTC 日期，格式为 2006-01-02 UTC date formatted as 2006-01-02
+	Count int64
+}
+
+// DatabaseStats 数据库的健康状况
+// Health of the database
+type DatabaseStats struct {
+	Driver        string        // 数据库驱动 Database driver
+	Ping          time.Duration // 一次往返的耗时 Round trip time
+	SchemaVersion int           // 当前迁移版本 Current migration version
+	SizeBytes     int64         // 数据库文件或库的大小 Size of the database file or database
+	InUse         int           // 使用中的连接 Connections in use
+	Idle          int           // 空闲连接 Idle connections
+	MaxOpen       int           // 最大连接数 Max open connections
+	WaitCount     int64         // 因连接池耗尽而等待的次数 Waits for an exhausted pool
+	WaitDuration  time.Duration // 等待连接的总时长 Total time waited for connections
+}
+
+// Count 统计模型的全部记录数和 since 之后创建的记录数
+// Count all records of a model and those created since the given time
+func (s *statsType) Count(ctx context.Context, model any, since time.Time) (total, recent int64, err error) {
+	if err = s.db.WithContext(ctx).Model(model).Count(&total).Error; err != nil {
+		return
+	}
+	err = s.db.WithContext(ctx).Model(model).Where("created_at >= ?", since).Count(&recent).Error
+	return
+}
+
+// CountTrashed 统计回收站中的记录数
+// Count the records in the trash
+func (s *statsType) CountTrashed(ctx context.Context, model any) (count int64, err error) {
+	err = s.db.WithContext(ctx).Unscoped().Model(model).Where("deleted_at IS NOT NULL").Count(&count).Error
+	return
+}
+
+// DailyReleases 按 UTC 日期统计 since 之后的部署次数，包括预览部署和之后被删除的版本，不包括指向激活版本的记录
+// Count deployments per UTC day since the given time, including previews and versions deleted later but not the pointer to the active version
+func (s *statsType) DailyReleases(ctx context.Context, since time.Time) (days []DailyCount, err error) {
+	day := "strftime('%Y-%m-%d', created_at)"
+	if s.db.Dialector.Name() == "postgres" {
+		day = "to_char(created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD')"
+	}
+	err = s.db.WithContext(ctx).Unscoped().Model(&models.SiteRelease{}).
+		Select(day+" AS day, COUNT(*) AS count").
+		Where("created_at >= ? AND tag <> ?", since, constants.ReleaseTagLatest).
+		Group("day").Order("day").Scan(&days).Error
+	return
+}
+
+// JobCounts 按状态统计后台任务
+// Count background jobs by status
+func (s *statsType) JobCounts(ctx context.Context) (counts map[string]int64, err error) {
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	if err = s.db.WithContext(ctx).Model(&models.Job{}).Select("status, COUNT(*) AS count").Group("status").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	counts = make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+// Ping 检查主库的连通性并获取迁移版本，供就绪探针使用
+// Check that the primary database is reachable and get its migration version, used by the readiness probe
+func (s *statsType) Ping(ctx context.Context) (ping time.Duration, version int, err error) {
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return 0, 0, err
+	}
+	start := time.Now()
+	if err = sqlDB.PingContext(ctx); err != nil {
+		return 0, 0, err
+	}
+	ping = time.Since(start)
+	version, err = models.CurrentVersion(s.db.WithContext(ctx))
+	return ping, version, err
+}
+
+// Database 检查主库的连通性并收集连接池、迁移版本和大小
+// Check that the primary database is reachable and collect its pool, migration version and size
+func (s *statsType) Database(ctx context.Context) (stats DatabaseStats, err error) {
+	stats.Driver = s.db.Dialector.Name()
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return stats, err
+	}
+	start := time.Now()
+	if err = sqlDB.PingContext(ctx); err != nil {
+		return stats, err
+	}
+	stats.Ping = time.Since(start)
+	pool := sqlDB.Stats()
+	stats.InUse, stats.Idle, stats.MaxOpen = pool.InUse, pool.Idle, pool.MaxOpenConnections
+	stats.WaitCount, stats.WaitDuration = pool.WaitCount, pool.WaitDuration
+	if stats.SchemaVersion, err = models.CurrentVersion(s.db.WithContext(ctx)); err != nil {
+		return stats, err
+	}
+	size := "SELECT page_count * page_size FROM pragma_page_count(), pragma_page_size()"
+	if stats.Driver == "postgres" {
+		// 使用独立 schema 时只统计其中的表和索引 Only the tables and indexes of it count when using a dedicated schema
+		size = `SELECT CASE WHEN current_schema() = 'public' THEN pg_database_size(current_database())
+			ELSE (SELECT COALESCE(SUM(pg_total_relation_size(oid)), 0)::bigint FROM pg_class WHERE relnamespace = current_schema()::regnamespace AND relkind IN ('r', 'm')) END`
+	}
+	err = s.db.WithContext(ctx).Raw(size).Scan(&stats.SizeBytes).Error
+	return stats, err
+}
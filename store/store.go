@@ -1,10 +1,17 @@
 package store
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
 
 	"github.com/LiteyukiStudio/spage/config"
 	"github.com/LiteyukiStudio/spage/constants"
@@ -14,11 +21,19 @@ import (
 	"github.com/sirupsen/logrus"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
+	"gorm.io/plugin/dbresolver"
 )
 
 var DB *gorm.DB
 
+// memoryConn 内存数据库保持打开的连接，最后一个连接关闭时内存数据库即被销毁
+// Connection kept open for the in-memory database, which is destroyed once its last connection closes
+var memoryConn *sql.Conn
+
+// resolver 只读副本的连接，未配置副本时为空
+// Connections of the read replicas, nil when no replica is configured
+var resolver *dbresolver.DBResolver
+
 // DBConfig 数据库配置结构体
 type DBConfig struct {
 	Driver   string // 数据库驱动类型，例如 "sqlite" 或 "postgres" Database driver type, e.g., "sqlite" or "postgres"
@@ -29,6 +44,26 @@ type DBConfig struct {
 	Password string // PostgreSQL 密码 PostgreSQL password
 	DBName   string // PostgreSQL 数据库名 PostgreSQL database name
 	SSLMode  string // PostgreSQL SSL 模式 PostgreSQL SSL mode
+	Schema   string // PostgreSQL schema，为空时使用服务器的默认 search_path PostgreSQL schema, the server default search_path when empty
+
+	JournalMode       string // SQLite 日志模式，默认 WAL SQLite journal mode, WAL by default
+	BusyTimeout       int    // SQLite 等待其他连接释放锁的时间，单位毫秒 Time SQLite waits for other connections to release a lock in milliseconds
+	Synchronous       string // SQLite 同步级别 SQLite synchronous level
+	ForeignKeys       bool   // SQLite 是否检查外键约束 Whether SQLite enforces foreign key constraints
+	WALAutoCheckpoint int    // SQLite WAL 自动检查点的页数，0 表示关闭，负数使用 SQLite 默认值 Pages of SQLite automatic WAL checkpoints, 0 disables them and negative keeps the SQLite default
+	TxLock            string // SQLite 事务开始时的锁模式 Lock mode SQLite transactions begin with
+
+	Replicas []string // PostgreSQL 只读副本，格式为 host 或 host:port，其余连接参数与主库相同 PostgreSQL read replicas as host or host:port, other parameters follow the primary
+
+	MaxOpenConns    int // 最大打开连接数，0 表示不限制 Max open connections, 0 means unlimited
+	MaxIdleConns    int // 最大空闲连接数 Max idle connections
+	ConnMaxLifetime int // 连接最长复用时间，单位秒，0 表示不限制 Max connection lifetime in seconds, 0 means unlimited
+
+	ExplainCheck     bool // 启动时检查热点查询的执行计划 Check query plans of hot queries at startup
+	ExplainThreshold int  // 超过该行数的顺序扫描会发出警告 Sequential scans over tables above this row count are warned
+
+	SlowQuery        int // 超过该耗时的语句以警告级别记录，单位毫秒，0 表示不记录 Statements slower than this are logged as warnings, in milliseconds, 0 disables it
+	StatementTimeout int // 单条语句的超时时间，单位秒，0 表示不限制 Timeout of a single statement in seconds, 0 means unlimited
 }
 
 // loadDBConfig 从配置文件加载数据库配置
@@ -43,38 +78,60 @@ func loadDBConfig() DBConfig {
 		Password: config.GetString("database.password", "spage"),
 		DBName:   config.GetString("database.dbname", "spage"),
 		SSLMode:  config.GetString("database.sslmode", "disable"),
+		Schema:   config.GetString("database.schema", ""),
+
+		JournalMode:       config.GetString("database.journal-mode", "WAL"),
+		BusyTimeout:       config.GetInt("database.busy-timeout", 5000),
+		Synchronous:       config.GetString("database.synchronous", "NORMAL"),
+		ForeignKeys:       config.GetBool("database.foreign-keys", true),
+		WALAutoCheckpoint: config.GetInt("database.wal-autocheckpoint", -1),
+		TxLock:            config.GetString("database.txlock", "immediate"),
+
+		Replicas: config.GetStringSlice("database.replicas", []string{}),
+
+		MaxOpenConns:    config.GetInt("database.max_open_conns", 0),
+		MaxIdleConns:    config.GetInt("database.max_idle_conns", 2),
+		ConnMaxLifetime: config.GetInt("database.conn_max_lifetime", 0),
+
+		ExplainCheck:     config.GetBool("database.explain-check", false),
+		ExplainThreshold: config.GetInt("database.explain-threshold", 10000),
+
+		SlowQuery:        config.GetInt("database.slow-query", 200),
+		StatementTimeout: config.GetInt("database.statement-timeout", 30),
 	}
 }
 
-// Init 手动初始化数据库连接
-// Manually initialize database connection
+// Init 手动初始化数据库连接，迁移到最新版本并初始化数据
+// Manually initialize database connection, migrate to the latest version and initialize data
 func Init() error {
-	dbConfig := loadDBConfig()
-
-	// 创建通用的 GORM 配置
-	// Create a common GORM configuration
-	gormConfig := &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
+	dbConfig, err := Connect()
+	if err != nil {
+		return err
 	}
 
-	var err error
-
-	switch dbConfig.Driver {
-	case "postgres":
-		if err = initPostgres(dbConfig, gormConfig); err != nil {
-			return fmt.Errorf("postgres initialization failed: %w", err)
-		}
-	case "sqlite":
-		if err = initSQLite(dbConfig, gormConfig); err != nil {
-			return fmt.Errorf("sqlite initialization failed: %w", err)
-		}
-	default:
-		return errors.New("unsupported database driver, only sqlite and postgres are supported")
+	// 共用数据库的多个实例同时启动时依次迁移和初始化数据
+	// Instances sharing the database and starting together migrate and initialize data one at a time
+	err = Lock.With(WithoutTimeout(context.Background()), LockBootstrap, bootstrap)
+	if err != nil {
+		return err
+	}
+	if dbConfig.ExplainCheck {
+		checkQueryPlans(DB, dbConfig.ExplainThreshold)
 	}
+	// 迁移只在主库上执行，完成后再启用只读副本
+	// Migrations run on the primary only, replicas are enabled afterwards
+	if err = useReplicas(DB, dbConfig); err != nil {
+		return fmt.Errorf("replica initialization failed: %w", err)
+	}
+	return nil
+}
 
+// bootstrap 迁移模型并创建或更新管理员账户，调用方持有 LockBootstrap
+// Migrate models and create or update the admin account, the caller holds LockBootstrap
+func bootstrap(db *gorm.DB) error {
 	// 迁移模型
 	// Migrate models
-	if err = models.Migrate(DB); err != nil {
+	if err := models.Migrate(db); err != nil {
 		logrus.Error("Failed to migrate models:", err)
 		return err
 	}
@@ -82,7 +139,7 @@ func Init() error {
 	// Initialize data
 	// 创建管理员账户
 	// Create admin account
-	hashedPassword, err := utils.Password.HashPassword(config.AdminPassword, config.JwtSecret)
+	hashedPassword, err := utils.Password.HashPassword(config.AdminPassword)
 	if err != nil {
 		logrus.Error("Failed to hash password:", err)
 		return err
@@ -92,13 +149,76 @@ func Init() error {
 		Password: &hashedPassword,
 		Role:     constants.RoleAdmin,
 	}
-	if err = User.UpdateSystemAdmin(user); err != nil {
+	if err = (&userType{db: db}).UpdateSystemAdmin(user); err != nil {
 		logrus.Error("Failed to update admin user:", err)
 		return err
 	}
 	return nil
 }
 
+// Connect 建立数据库连接并注入各仓库，不执行迁移
+// Open the database connection and inject it into repositories, without migrating
+func Connect() (DBConfig, error) {
+	dbConfig := loadDBConfig()
+
+	// 创建通用的 GORM 配置
+	// Create a common GORM configuration
+	// 将驱动的唯一约束等错误转换为 gorm.ErrDuplicatedKey 等，便于映射为 HTTP 状态码
+	// Translate driver errors such as unique violations into gorm.ErrDuplicatedKey and friends so they map to HTTP statuses
+	gormConfig := &gorm.Config{
+		Logger:         gormLogger{slow: time.Duration(dbConfig.SlowQuery) * time.Millisecond},
+		TranslateError: true,
+	}
+
+	var err error
+
+	switch dbConfig.Driver {
+	case "postgres":
+		if err = initPostgres(dbConfig, gormConfig); err != nil {
+			return dbConfig, fmt.Errorf("postgres initialization failed: %w", err)
+		}
+	case "sqlite":
+		if err = initSQLite(dbConfig, gormConfig); err != nil {
+			return dbConfig, fmt.Errorf("sqlite initialization failed: %w", err)
+		}
+	default:
+		return dbConfig, errors.New("unsupported database driver, only sqlite and postgres are supported")
+	}
+
+	// 记录语句耗时
+	// Record statement durations
+	if err = DB.Use(queryMetrics{}); err != nil {
+		return dbConfig, fmt.Errorf("register query metrics failed: %w", err)
+	}
+
+	// 为带有链路上下文的语句创建 span
+	// Create spans for statements carrying a trace context
+	if err = DB.Use(queryTracing{}); err != nil {
+		return dbConfig, fmt.Errorf("register query tracing failed: %w", err)
+	}
+
+	// 限制语句执行时间
+	// Bound statement execution time
+	if dbConfig.StatementTimeout > 0 {
+		if err = DB.Use(statementTimeout{timeout: time.Duration(dbConfig.StatementTimeout) * time.Second}); err != nil {
+			return dbConfig, fmt.Errorf("register statement timeout failed: %w", err)
+		}
+	}
+
+	// 设置连接池
+	// Configure the connection pool
+	if err = applyPool(DB, dbConfig); err != nil {
+		return dbConfig, fmt.Errorf("configure connection pool failed: %w", err)
+	}
+
+	// 为各仓库注入数据库连接
+	// Inject the database connection into repositories
+	bindRepositories(DB)
+	// 同一数据库中不同 schema 的安装互不阻塞 Installations in different schemas of one database do not block each other
+	Lock.namespace = dbConfig.Schema
+	return dbConfig, nil
+}
+
 // initPostgres 初始化PostgreSQL连接
 // Initialize PostgreSQL connection
 func initPostgres(config DBConfig, gormConfig *gorm.Config) error {
@@ -106,12 +226,108 @@ func initPostgres(config DBConfig, gormConfig *gorm.Config) error {
 		return errors.New("PostgreSQL configuration is incomplete")
 	}
 
-	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-		config.Host, config.Port, config.User, config.Password, config.DBName, config.SSLMode)
+	if config.Schema != "" && !schemaPattern.MatchString(config.Schema) {
+		return fmt.Errorf("invalid schema %q, use lowercase letters, digits and underscores", config.Schema)
+	}
 
 	var err error
-	DB, err = gorm.Open(postgres.Open(dsn), gormConfig)
-	return err
+	if DB, err = gorm.Open(postgres.Open(postgresDSN(config, config.Host, config.Port)), gormConfig); err != nil {
+		return err
+	}
+	return ensureSchema(DB, config.Schema)
+}
+
+// schemaPattern 允许的 schema 名称，无需加引号即可用于 search_path 和 DDL
+// Allowed schema names, usable in search_path and DDL without quoting
+var schemaPattern = regexp.MustCompile(`^[a-z_][a-z0-9_]{0,62}$`)
+
+// postgresDSN 生成 PostgreSQL 连接串，配置了 schema 时每个连接的 search_path 只包含它，迁移创建的表和查询都落在其中
+// Build the PostgreSQL DSN, with a schema configured the search_path of every connection holds only it so tables created by migrations and queries land there
+func postgresDSN(config DBConfig, host string, port int) string {
+	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		host, port, config.User, config.Password, config.DBName, config.SSLMode)
+	if config.Schema != "" {
+		dsn += " search_path=" + config.Schema
+	}
+	return dsn
+}
+
+// ensureSchema schema 不存在时创建它；已存在时不需要数据库的 CREATE 权限
+// Create the schema when it does not exist; no CREATE privilege on the database is needed once it does
+func ensureSchema(db *gorm.DB, schema string) error {
+	if schema == "" {
+		return nil
+	}
+	var exists bool
+	if err := db.Raw("SELECT EXISTS (SELECT 1 FROM pg_namespace WHERE nspname = ?)", schema).Scan(&exists).Error; err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+	logrus.Infof("Creating schema %s", schema)
+	return db.Exec("CREATE SCHEMA IF NOT EXISTS " + schema).Error
+}
+
+// useReplicas 注册只读副本，读查询路由到副本，写入和事务仍在主库
+// Register read replicas, routing reads to replicas while writes and transactions stay on the primary
+func useReplicas(db *gorm.DB, config DBConfig) error {
+	if len(config.Replicas) == 0 {
+		return nil
+	}
+	if config.Driver != "postgres" {
+		logrus.Warn("Read replicas are only supported on postgres, ignored")
+		return nil
+	}
+	replicas := make([]gorm.Dialector, 0, len(config.Replicas))
+	for _, replica := range config.Replicas {
+		host, port := replica, config.Port
+		if h, p, err := net.SplitHostPort(replica); err == nil {
+			host = h
+			if port, err = strconv.Atoi(p); err != nil {
+				return fmt.Errorf("invalid replica port %q", replica)
+			}
+		}
+		replicas = append(replicas, postgres.Open(postgresDSN(config, host, port)))
+	}
+	resolver = dbresolver.Register(dbresolver.Config{
+		Replicas: replicas,
+		Policy:   dbresolver.RandomPolicy{},
+	}).
+		SetMaxOpenConns(config.MaxOpenConns).
+		SetMaxIdleConns(config.MaxIdleConns).
+		SetConnMaxLifetime(time.Duration(config.ConnMaxLifetime) * time.Second)
+	if err := db.Use(resolver); err != nil {
+		return err
+	}
+	logrus.Infof("Routing reads to %d replica(s)", len(replicas))
+	return nil
+}
+
+// Close 关闭主库和只读副本的连接，SQLite 会在关闭时写回 WAL
+// Close the connections of the primary and the read replicas, SQLite checkpoints its WAL on close
+func Close() error {
+	if DB == nil {
+		return nil
+	}
+	var errs []error
+	if resolver != nil {
+		errs = append(errs, resolver.Call(func(pool gorm.ConnPool) error {
+			if closer, ok := pool.(interface{ Close() error }); ok {
+				return closer.Close()
+			}
+			return nil
+		}))
+	}
+	if memoryConn != nil {
+		errs = append(errs, memoryConn.Close())
+		memoryConn = nil
+	}
+	sqlDB, err := DB.DB()
+	if err == nil {
+		err = sqlDB.Close()
+	}
+	return errors.Join(append(errs, err)...)
 }
 
 // initSQLite 初始化SQLite连接
@@ -120,6 +336,9 @@ func initSQLite(config DBConfig, gormConfig *gorm.Config) error {
 	if config.Path == "" {
 		config.Path = "./data/data.db"
 	}
+	if config.Path == MemoryPath {
+		return openMemory(gormConfig)
+	}
 	// 创建 SQLite 数据库文件的目录
 	// Create the directory for SQLite database file if it doesn't exist
 	if err := os.MkdirAll(filepath.Dir(config.Path), os.ModePerm); err != nil {
@@ -127,6 +346,98 @@ func initSQLite(config DBConfig, gormConfig *gorm.Config) error {
 	}
 
 	var err error
-	DB, err = gorm.Open(sqlite.Open(config.Path), gormConfig)
+	DB, err = gorm.Open(sqlite.Open(sqliteDSN(config)), gormConfig)
 	return err
 }
+
+// MemoryPath 使用共享缓存内存数据库的 SQLite 路径，数据不写入文件系统，进程退出后丢弃
+// SQLite path using a shared-cache in-memory database, nothing touches the filesystem and the data is gone when the process exits
+const MemoryPath = ":memory:"
+
+// MemoryDSN 以 name 命名的共享缓存内存数据库，同一进程中同名的连接访问同一个数据库
+// Shared-cache in-memory database called name, connections with the same name in a process reach the same database
+func MemoryDSN(name string) string {
+	return "file:" + url.PathEscape(name) + "?mode=memory&cache=shared"
+}
+
+// openMemory 打开内存数据库并保持一个连接，使连接池回收空闲连接时数据不会丢失
+// Open the in-memory database and hold a connection, so the data survives the pool closing idle connections
+func openMemory(gormConfig *gorm.Config) error {
+	var err error
+	if DB, err = gorm.Open(sqlite.Open(MemoryDSN("spage")), gormConfig); err != nil {
+		return err
+	}
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	memoryConn, err = sqlDB.Conn(context.Background())
+	return err
+}
+
+// applyPool 通过 sql.DB 应用连接池配置
+// Apply the connection pool configuration via sql.DB
+func applyPool(db *gorm.DB, config DBConfig) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	sqlDB.SetMaxOpenConns(config.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(config.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(time.Duration(config.ConnMaxLifetime) * time.Second)
+	return nil
+}
+
+// Bind 将包级数据库连接和各仓库替换为 db，供测试使用隔离的数据库，db 为空时恢复未连接的状态
+// Replace the package-level connection and repositories with db so tests can use an isolated database, a nil db restores the unconnected state
+func Bind(db *gorm.DB) {
+	DB = db
+	bindRepositories(db)
+}
+
+// bindRepositories 为各仓库注入数据库连接，仓库在包初始化时 DB 尚未建立
+// Inject the database connection into repositories, DB is not yet open when the package is initialized
+func bindRepositories(db *gorm.DB) {
+	Default = New(db)
+	User = Default.User
+	Org = Default.Org
+	Project = Default.Project
+	Site = Default.Site
+	File = Default.File
+	OIDC = Default.OIDC
+	APIToken = Default.APIToken
+	TwoFactor = Default.TwoFactor
+	Domain = Default.Domain
+	Preview = Default.Preview
+	Team = Default.Team
+	Webhook = Default.Webhook
+	CDN = Default.CDN
+	Notification = Default.Notification
+	Git = Default.Git
+	Upload = Default.Upload
+	Blob = Default.Blob
+	Quota = Default.Quota
+	ContentPolicy = Default.ContentPolicy
+	Trash = Default.Trash
+	Job = Default.Job
+	Search = Default.Search
+	Stats = Default.Stats
+	UserToken = Default.UserToken
+	Invitation = Default.Invitation
+	Transfer = Default.Transfer
+	Setting = Default.Setting
+	LoginFailure = Default.LoginFailure
+	DeployKey = Default.DeployKey
+	Tenant = Default.Tenant
+	Runner = Default.Runner
+	Cron = Default.Cron
+	JWT = Default.JWT
+	Backup = Default.Backup
+	// 持有本实例状态的仓库不属于 Handle Repositories holding per-instance state are not part of Handle
+	Audit.db = db
+	Traffic.db = db
+	Analytics.db = db
+	AccessLog.db = db
+	Encryption.db = db
+	Lock.db = db
+}
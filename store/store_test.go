@@ -0,0 +1,33 @@
+package store
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPostgresDSN(t *testing.T) {
+	config := DBConfig{User: "spage", Password: "secret", DBName: "shared", SSLMode: "disable"}
+	dsn := postgresDSN(config, "db", 5432)
+	if strings.Contains(dsn, "search_path") {
+		t.Errorf("DSN without schema = %q", dsn)
+	}
+	config.Schema = "spage"
+	if dsn := postgresDSN(config, "replica", 5433); !strings.HasSuffix(dsn, " search_path=spage") || !strings.Contains(dsn, "host=replica port=5433") {
+		t.Errorf("DSN with schema = %q", dsn)
+	}
+	for schema, valid := range map[string]bool{"spage": true, "_app_2": true, "Spage": false, "a-b": false, "x;drop": false, "1st": false} {
+		if schemaPattern.MatchString(schema) != valid {
+			t.Errorf("schema %q valid = %v", schema, !valid)
+		}
+	}
+}
+
+func TestAdvisoryKeyNamespace(t *testing.T) {
+	public, dedicated, other := lockType{}, lockType{namespace: "spage"}, lockType{namespace: "other"}
+	if public.advisoryKey(LockBootstrap) == dedicated.advisoryKey(LockBootstrap) || dedicated.advisoryKey(LockBootstrap) == other.advisoryKey(LockBootstrap) {
+		t.Error("locks of different schemas share a key")
+	}
+	if dedicated.advisoryKey(LockBootstrap) != (&lockType{namespace: "spage"}).advisoryKey(LockBootstrap) {
+		t.Error("locks of one schema differ")
+	}
+}
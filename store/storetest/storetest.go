@@ -0,0 +1,97 @@
+// Package storetest 为依赖 store 的测试提供迁移到最新版本的一次性内存数据库和示例数据
+// Package storetest provides throwaway in-memory databases migrated to the latest version and fixture data for tests depending on store
+package storetest
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// sequence 使同一测试多次创建的数据库互不相同
+// Keeps databases created several times by one test apart
+var sequence atomic.Int64
+
+// Fixtures 示例数据：管理员、普通用户、用户所有的组织，以及个人和组织项目各一个，个人项目带一个站点
+// Fixture data: an administrator, a regular user, an organization owned by the user, and one personal and one organization project, the personal one with a site
+type Fixtures struct {
+	Admin      *models.User
+	User       *models.User
+	Org        *models.Organization
+	Project    *models.Project
+	OrgProject *models.Project
+	Site       *models.Site
+}
+
+// New 创建迁移到最新版本的一次性内存数据库，测试结束时关闭；不同测试的数据库相互隔离，可以并行
+// Create a throwaway in-memory database migrated to the latest version and closed when the test ends; databases of different tests are isolated and can run in parallel
+func New(t testing.TB) *store.Handle {
+	t.Helper()
+	name := fmt.Sprintf("%s-%d", strings.NewReplacer("/", "_", " ", "_").Replace(t.Name()), sequence.Add(1))
+	db, err := gorm.Open(sqlite.Open(store.MemoryDSN(name)), &gorm.Config{
+		Logger:         logger.Default.LogMode(logger.Silent),
+		TranslateError: true,
+	})
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	// 保持一个连接，连接池回收空闲连接时数据库不会被销毁 Hold one connection so the database survives the pool closing idle ones
+	sqlDB.SetMaxIdleConns(1)
+	if err := models.Migrate(db); err != nil {
+		t.Fatalf("migrate test database: %v", err)
+	}
+	return store.New(db)
+}
+
+// Install 将包级仓库绑定到 h 的数据库，测试结束时恢复，供通过包级变量访问 store 的代码使用；使用它的测试不能并行
+// Bind the package-level repositories to the database of h and restore them when the test ends, for code reaching store through the package-level variables; tests using it cannot run in parallel
+func Install(t testing.TB, h *store.Handle) {
+	t.Helper()
+	previous := store.DB
+	store.Bind(h.DB())
+	t.Cleanup(func() { store.Bind(previous) })
+}
+
+// Seed 写入示例数据
+// Write the fixture data
+func Seed(t testing.TB, h *store.Handle) *Fixtures {
+	t.Helper()
+	f := &Fixtures{
+		Admin: &models.User{Name: "admin", Role: constants.RoleAdmin},
+		User:  &models.User{Name: "alice", Role: constants.RoleUser},
+		Org:   &models.Organization{Name: "acme"},
+	}
+	for _, user := range []*models.User{f.Admin, f.User} {
+		if err := h.User.Create(user); err != nil {
+			t.Fatalf("seed user %s: %v", user.Name, err)
+		}
+	}
+	if err := h.Org.CreateOrg(f.Org, f.User); err != nil {
+		t.Fatalf("seed organization: %v", err)
+	}
+	f.Project = &models.Project{Name: "alice-blog", OwnerType: constants.OwnerTypeUser, OwnerID: f.User.ID}
+	f.OrgProject = &models.Project{Name: "acme-docs", OwnerType: constants.OwnerTypeOrg, OwnerID: f.Org.ID}
+	for _, project := range []*models.Project{f.Project, f.OrgProject} {
+		if err := h.Project.Create(project); err != nil {
+			t.Fatalf("seed project %s: %v", project.Name, err)
+		}
+	}
+	f.Site = &models.Site{Name: "blog", ProjectID: f.Project.ID, SubDomain: "alice-blog"}
+	if err := h.Site.Create(f.Site); err != nil {
+		t.Fatalf("seed site: %v", err)
+	}
+	return f
+}
@@ -0,0 +1,44 @@
+package storetest
+
+import (
+	"testing"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/store"
+)
+
+func TestSeed(t *testing.T) {
+	for _, name := range []string{"first", "second"} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			h := New(t)
+			f := Seed(t, h)
+			if role := h.Project.UserRole(f.OrgProject, f.User.ID); role != constants.OrgRoleOwner {
+				t.Errorf("role of the organization owner = %q", role)
+			}
+			site, err := h.Site.GetBySubDomain(f.Site.SubDomain)
+			if err != nil || site.Project.ID != f.Project.ID {
+				t.Fatalf("site = %+v, %v", site, err)
+			}
+			var users int64
+			if err := h.DB().Table("users").Count(&users).Error; err != nil || users != 2 {
+				t.Errorf("users = %d, %v", users, err)
+			}
+		})
+	}
+}
+
+func TestInstall(t *testing.T) {
+	h := New(t)
+	t.Run("installed", func(t *testing.T) {
+		Install(t, h)
+		f := Seed(t, h)
+		user, err := store.User.GetByName(f.User.Name)
+		if err != nil || user.ID != f.User.ID {
+			t.Fatalf("user = %+v, %v", user, err)
+		}
+	})
+	if store.DB != nil {
+		t.Error("the package-level connection should be restored")
+	}
+}
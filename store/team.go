@@ -0,0 +1,93 @@
+package store
+
+import (
+	"time"
+
+	"github.com/LiteyukiStudio/spage/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type teamType struct {
+	db *gorm.DB
+}
+
+// Team 组织内的团队及其成员
+// Teams within organizations and their members
+var Team = teamType{
+	db: DB,
+}
+
+// ListByOrg 获取组织的团队
+// List the teams of an organization
+func (t *teamType) ListByOrg(orgID uint) (teams []models.Team, err error) {
+	err = t.db.Where("organization_id = ?", orgID).Order("id").Find(&teams).Error
+	return
+}
+
+// GetByOrg 获取组织的指定团队
+// Get the given team of an organization
+func (t *teamType) GetByOrg(orgID, id uint) (team *models.Team, err error) {
+	team = &models.Team{}
+	err = t.db.Where("id = ? AND organization_id = ?", id, orgID).First(team).Error
+	if err != nil {
+		return nil, err
+	}
+	return team, nil
+}
+
+// NameIsExist 判断组织内团队名称是否已被使用
+// Check whether the team name is taken within the organization
+func (t *teamType) NameIsExist(orgID uint, name string, excludeID uint) bool {
+	var count int64
+	t.db.Model(&models.Team{}).Where("organization_id = ? AND name = ? AND id <> ?", orgID, name, excludeID).Count(&count)
+	return count > 0
+}
+
+// Create 创建团队
+func (t *teamType) Create(team *models.Team) error {
+	return t.db.Create(team).Error
+}
+
+// Update 更新团队
+func (t *teamType) Update(team *models.Team) error {
+	return t.db.Model(team).Select("name", "description").Updates(team).Error
+}
+
+// Delete 删除团队及其成员和项目授权
+// Delete a team with its members and project grants
+func (t *teamType) Delete(team *models.Team) error {
+	return t.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("team_id = ?", team.ID).Delete(&models.ProjectTeam{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("team_id = ?", team.ID).Delete(&models.TeamMember{}).Error; err != nil {
+			return err
+		}
+		// 硬删除以便重新使用团队名称 Hard delete so the team name can be reused
+		return tx.Unscoped().Delete(team).Error
+	})
+}
+
+// ListMembers 获取团队成员
+// List the members of a team
+func (t *teamType) ListMembers(teamID uint) (users []models.User, err error) {
+	err = t.db.Joins("JOIN team_members ON team_members.user_id = users.id").
+		Where("team_members.team_id = ?", teamID).
+		Order("users.id").
+		Find(&users).Error
+	return
+}
+
+// AddMember 添加团队成员，已是成员时忽略
+// Add a team member, ignored when they already are one
+func (t *teamType) AddMember(teamID, userID uint) error {
+	return t.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.TeamMember{TeamID: teamID, UserID: userID, CreatedAt: time.Now()}).Error
+}
+
+// RemoveMember 移除团队成员
+// Remove a team member
+func (t *teamType) RemoveMember(teamID, userID uint) error {
+	return t.db.Where("team_id = ? AND user_id = ?", teamID, userID).Delete(&models.TeamMember{}).Error
+}
@@ -0,0 +1,148 @@
+package store
+
+import (
+	"errors"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/models"
+	"gorm.io/gorm"
+)
+
+type tenantType struct {
+	db *gorm.DB
+}
+
+// Tenant 租户及其用户、组织和项目的归属
+// Tenants and the membership of their users, organizations and projects
+var Tenant = tenantType{
+	db: DB,
+}
+
+// Create 创建租户
+// Create a tenant
+func (t *tenantType) Create(tenant *models.Tenant) error {
+	return t.db.Create(tenant).Error
+}
+
+// Update 保存租户的全部字段
+// Save every field of the tenant
+func (t *tenantType) Update(tenant *models.Tenant) error {
+	return t.db.Save(tenant).Error
+}
+
+// Delete 删除租户，直接删除使名称和基础域名可以再次使用
+// Delete a tenant, deleted permanently so its name and base domain can be used again
+func (t *tenantType) Delete(tenant *models.Tenant) error {
+	return t.db.Unscoped().Delete(tenant).Error
+}
+
+// GetByID 获取租户
+// Get a tenant
+func (t *tenantType) GetByID(id uint) (tenant *models.Tenant, err error) {
+	tenant = &models.Tenant{}
+	if err = t.db.First(tenant, id).Error; err != nil {
+		return nil, err
+	}
+	return tenant, nil
+}
+
+// GetByName 按名称获取租户
+// Get a tenant by name
+func (t *tenantType) GetByName(name string) (tenant *models.Tenant, err error) {
+	tenant = &models.Tenant{}
+	if err = t.db.Where("name = ?", name).First(tenant).Error; err != nil {
+		return nil, err
+	}
+	return tenant, nil
+}
+
+// List 获取所有租户
+// List all tenants
+func (t *tenantType) List() (tenants []models.Tenant, err error) {
+	err = t.db.Order("id").Find(&tenants).Error
+	return
+}
+
+// BaseDomains 设置了基础域名的租户的基础域名
+// Base domains of the tenants that set one
+func (t *tenantType) BaseDomains() (map[uint]string, error) {
+	var tenants []models.Tenant
+	if err := t.db.Select("id", "base_domain").Where("base_domain IS NOT NULL AND base_domain <> ''").Find(&tenants).Error; err != nil {
+		return nil, err
+	}
+	domains := make(map[uint]string, len(tenants))
+	for _, tenant := range tenants {
+		domains[tenant.ID] = *tenant.BaseDomain
+	}
+	return domains, nil
+}
+
+// CountMembers 租户中的用户和组织数量
+// Number of users and organizations in the tenant
+func (t *tenantType) CountMembers(id uint) (users, orgs int64, err error) {
+	if err = t.db.Model(&models.User{}).Where("tenant_id = ?", id).Count(&users).Error; err != nil {
+		return
+	}
+	err = t.db.Model(&models.Organization{}).Where("tenant_id = ?", id).Count(&orgs).Error
+	return
+}
+
+// OfOwner 用户或组织所属的租户，不属于任何租户时为 nil
+// Tenant of a user or organization, nil when it belongs to none
+func (t *tenantType) OfOwner(ownerType string, ownerID uint) (*models.Tenant, error) {
+	id, err := ownerTenantID(t.db, ownerType, ownerID)
+	if err != nil || id == nil {
+		return nil, err
+	}
+	return t.GetByID(*id)
+}
+
+// Assign 将用户或组织连同其拥有的项目移入租户，tenantID 为空时移回实例本身，返回受影响的项目ID
+// Move a user or organization together with the projects it owns into a tenant, back to the instance itself when tenantID is nil, returning the affected project IDs
+func (t *tenantType) Assign(ownerType string, ownerID uint, tenantID *uint) (projectIDs []uint, err error) {
+	var model any = &models.User{}
+	if ownerType == constants.OwnerTypeOrg {
+		model = &models.Organization{}
+	}
+	err = t.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(model).Where("id = ?", ownerID).Update("tenant_id", tenantID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		projects := tx.Model(&models.Project{}).Where("owner_type = ? AND owner_id = ?", ownerType, ownerID)
+		if err := projects.Pluck("id", &projectIDs).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Project{}).Where("owner_type = ? AND owner_id = ?", ownerType, ownerID).Update("tenant_id", tenantID).Error
+	})
+	return
+}
+
+// ownerTenantID 用户或组织所属的租户ID，不属于任何租户时为 nil
+// Tenant ID of a user or organization, nil when it belongs to none
+func ownerTenantID(db *gorm.DB, ownerType string, ownerID uint) (*uint, error) {
+	var model any
+	switch ownerType {
+	case constants.OwnerTypeUser:
+		model = &models.User{}
+	case constants.OwnerTypeOrg:
+		model = &models.Organization{}
+	default:
+		return nil, errors.New("unknown owner type " + ownerType)
+	}
+	var owner struct{ TenantID *uint }
+	if err := db.Model(model).Select("tenant_id").Where("id = ?", ownerID).Take(&owner).Error; err != nil {
+		return nil, err
+	}
+	return owner.TenantID, nil
+}
+
+// ProjectIDs 租户中所有项目的ID
+// IDs of every project of the tenant
+func (t *tenantType) ProjectIDs(id uint) (ids []uint, err error) {
+	err = t.db.Model(&models.Project{}).Where("tenant_id = ?", id).Pluck("id", &ids).Error
+	return
+}
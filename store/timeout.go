@@ -0,0 +1,82 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	statementCancelKey  = "timeout:cancel"
+	statementContextKey = "timeout:context"
+)
+
+// noTimeoutKey 标记不受语句超时限制的上下文，用于迁移和备份等长时间运行的语句
+// Marks contexts exempt from the statement timeout, used by long-running statements such as migrations and backups
+type noTimeoutKey struct{}
+
+// WithoutTimeout 返回不受语句超时限制的上下文
+// Return a context exempt from the statement timeout
+func WithoutTimeout(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noTimeoutKey{}, true)
+}
+
+// statementTimeout 为每条 GORM 语句设置超时的插件，超时或请求取消时数据库驱动中止语句。
+// Row 和 Rows 在回调结束后才读取结果，只受调用方上下文限制
+// Plugin giving every GORM statement a timeout, the driver aborts the statement when it expires or the request is cancelled.
+// Row and Rows read their results after the callbacks finish and are only bounded by the caller's context
+type statementTimeout struct {
+	timeout time.Duration
+}
+
+func (statementTimeout) Name() string {
+	return "spage:timeout"
+}
+
+func (s statementTimeout) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("timeout:before_create", s.start),
+		cb.Create().After("gorm:create").Register("timeout:after_create", finishTimeout),
+		cb.Query().Before("gorm:query").Register("timeout:before_query", s.start),
+		cb.Query().After("gorm:query").Register("timeout:after_query", finishTimeout),
+		cb.Update().Before("gorm:update").Register("timeout:before_update", s.start),
+		cb.Update().After("gorm:update").Register("timeout:after_update", finishTimeout),
+		cb.Delete().Before("gorm:delete").Register("timeout:before_delete", s.start),
+		cb.Delete().After("gorm:delete").Register("timeout:after_delete", finishTimeout),
+		cb.Raw().Before("gorm:raw").Register("timeout:before_raw", s.start),
+		cb.Raw().After("gorm:raw").Register("timeout:after_raw", finishTimeout),
+	)
+}
+
+func (s statementTimeout) start(db *gorm.DB) {
+	ctx := db.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if ctx.Value(noTimeoutKey{}) != nil {
+		return
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	// 同一语句对象可能再次执行，例如分页时先计数再查询，结束后恢复原上下文
+	// The same statement may run again, such as counting before listing a page, so the original context is restored afterwards
+	db.InstanceSet(statementContextKey, db.Statement.Context)
+	db.InstanceSet(statementCancelKey, cancel)
+	db.Statement.Context = timeoutCtx
+}
+
+func finishTimeout(db *gorm.DB) {
+	value, ok := db.InstanceGet(statementCancelKey)
+	if !ok {
+		return
+	}
+	if cancel, ok := value.(context.CancelFunc); ok {
+		cancel()
+	}
+	if ctx, ok := db.InstanceGet(statementContextKey); ok {
+		db.Statement.Context, _ = ctx.(context.Context)
+	}
+	db.InstanceSet(statementCancelKey, nil)
+}
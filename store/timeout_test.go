@@ -0,0 +1,44 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/models"
+)
+
+func TestStatementTimeout(t *testing.T) {
+	db := openTestHandle(t, "timeout").DB()
+	if err := db.Use(statementTimeout{timeout: time.Minute}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&models.Project{Name: "timeout"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	// 分页时同一语句先计数再查询，第二次执行不能沿用已取消的上下文
+	// Pagination counts and then lists with the same statement, the second run must not reuse the cancelled context
+	projects, total, err := Paginate[models.Project](db, 1, 10)
+	if err != nil || total != 1 || len(projects) != 1 {
+		t.Fatalf("Paginate = %d, %d, %v", len(projects), total, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := db.WithContext(ctx).First(&models.Project{}).Error; !errors.Is(err, context.Canceled) {
+		t.Errorf("query with a cancelled context = %v", err)
+	}
+
+	short := openTestHandle(t, "timeout_short").DB()
+	if err := short.Use(statementTimeout{timeout: time.Nanosecond}); err != nil {
+		t.Fatal(err)
+	}
+	if err := short.First(&models.Project{}).Error; !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("query past the timeout = %v", err)
+	}
+	var count int64
+	if err := short.WithContext(WithoutTimeout(context.Background())).Model(&models.Project{}).Count(&count).Error; err != nil {
+		t.Errorf("query without timeout = %v", err)
+	}
+}
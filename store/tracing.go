@@ -0,0 +1,80 @@
+package store
+
+import (
+	"errors"
+
+	"github.com/LiteyukiStudio/spage/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const querySpanKey = "tracing:span"
+
+// maxTracedStatement span 中保留的 SQL 语句的最大长度，语句只含占位符不含参数
+// Longest SQL statement kept on a span, statements carry placeholders and never the arguments
+const maxTracedStatement = 2048
+
+// queryTracing 为每条 GORM 语句创建 span 的插件，只在语句的上下文中已有 span 时创建，未传入上下文的语句不出现在链路中
+// Plugin creating a span for every GORM statement, only when the statement's context already carries a span; statements without a context stay out of traces
+type queryTracing struct{}
+
+func (queryTracing) Name() string {
+	return "spage:tracing"
+}
+
+func (queryTracing) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("tracing:before_create", startSpan("create")),
+		cb.Create().After("gorm:create").Register("tracing:after_create", finishSpan),
+		cb.Query().Before("gorm:query").Register("tracing:before_query", startSpan("query")),
+		cb.Query().After("gorm:query").Register("tracing:after_query", finishSpan),
+		cb.Update().Before("gorm:update").Register("tracing:before_update", startSpan("update")),
+		cb.Update().After("gorm:update").Register("tracing:after_update", finishSpan),
+		cb.Delete().Before("gorm:delete").Register("tracing:before_delete", startSpan("delete")),
+		cb.Delete().After("gorm:delete").Register("tracing:after_delete", finishSpan),
+		cb.Row().Before("gorm:row").Register("tracing:before_row", startSpan("row")),
+		cb.Row().After("gorm:row").Register("tracing:after_row", finishSpan),
+		cb.Raw().Before("gorm:raw").Register("tracing:before_raw", startSpan("raw")),
+		cb.Raw().After("gorm:raw").Register("tracing:after_raw", finishSpan),
+	)
+}
+
+func startSpan(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		if !tracing.Active(db.Statement.Context) {
+			return
+		}
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+		ctx, span := tracing.Start(db.Statement.Context, "db."+operation+" "+table, trace.SpanKindClient,
+			semconv.DBSystemKey.String(db.Dialector.Name()),
+			semconv.DBOperationName(operation),
+			semconv.DBCollectionName(table),
+		)
+		db.Statement.Context = ctx
+		db.InstanceSet(querySpanKey, span)
+	}
+}
+
+func finishSpan(db *gorm.DB) {
+	value, ok := db.InstanceGet(querySpanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+	statement := db.Statement.SQL.String()
+	if len(statement) > maxTracedStatement {
+		statement = statement[:maxTracedStatement]
+	}
+	span.SetAttributes(semconv.DBQueryText(statement))
+	span.SetAttributes(attribute.Int64("db.rows_affected", db.Statement.RowsAffected))
+	tracing.End(span, db.Error, gorm.ErrRecordNotFound)
+}
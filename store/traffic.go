@@ -0,0 +1,184 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// trafficKey 流量按站点和 UTC 日期累计
+// Traffic is accumulated per site and UTC day
+type trafficKey struct {
+	siteID uint
+	day    time.Time
+}
+
+type trafficType struct {
+	db      *gorm.DB
+	mu      sync.Mutex
+	pending map[trafficKey]*models.SiteTraffic
+}
+
+// Traffic 站点流量统计，请求先在内存中累计，由 Flush 合并写入数据库
+// Site traffic statistics, requests are accumulated in memory and merged into the database by Flush
+var Traffic = trafficType{
+	db:      DB,
+	pending: make(map[trafficKey]*models.SiteTraffic),
+}
+
+// SiteTrafficSum 一段时间内站点的流量合计
+// Traffic totals of a site over a period
+type SiteTrafficSum struct {
+	SiteID   uint
+	Name     string // 站点名称，站点已彻底删除时为空 Site name, empty once the site was purged
+	Requests int64
+	Bytes    int64
+}
+
+// Add 累计一次站点请求
+// Count a request to a site
+func (t *trafficType) Add(siteID uint, bytes int64, now time.Time) {
+	t.merge(models.SiteTraffic{SiteID: siteID, Day: now.UTC().Truncate(24 * time.Hour), Requests: 1, Bytes: max(bytes, 0)})
+}
+
+func (t *trafficType) merge(counts models.SiteTraffic) {
+	key := trafficKey{siteID: counts.SiteID, day: counts.Day}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	row := t.pending[key]
+	if row == nil {
+		row = &models.SiteTraffic{SiteID: counts.SiteID, Day: counts.Day}
+		t.pending[key] = row
+	}
+	row.Requests += counts.Requests
+	row.Bytes += counts.Bytes
+}
+
+// Flush 将内存中的累计值加到数据库，写入失败的值留到下一次
+// Add the accumulated counts to the database, counts that fail to be written are kept for the next time
+func (t *trafficType) Flush(ctx context.Context) error {
+	t.mu.Lock()
+	rows := make([]models.SiteTraffic, 0, len(t.pending))
+	for _, row := range t.pending {
+		rows = append(rows, *row)
+	}
+	t.pending = make(map[trafficKey]*models.SiteTraffic)
+	t.mu.Unlock()
+	for i, row := range rows {
+		err := t.db.WithContext(ctx).Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "site_id"}, {Name: "day"}},
+			DoUpdates: clause.Assignments(map[string]any{
+				"requests": gorm.Expr("site_traffic.requests + ?", row.Requests),
+				"bytes":    gorm.Expr("site_traffic.bytes + ?", row.Bytes),
+			}),
+		}).Create(&row).Error
+		if err != nil {
+			for _, rest := range rows[i:] {
+				t.merge(rest)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// monthStart UTC 月份的第一天零点 Midnight of the first day of the UTC month
+func monthStart(now time.Time) time.Time {
+	now = now.UTC()
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// Month 站点本月的请求数和响应字节数，包括本实例尚未写入的累计值
+// Requests and response bytes of the site this month, including the counts this instance has not written yet
+func (t *trafficType) Month(ctx context.Context, siteID uint, now time.Time) (SiteTrafficSum, error) {
+	sums, err := t.Months(ctx, []uint{siteID}, now)
+	return sums[siteID], err
+}
+
+// Months 多个站点本月的请求数和响应字节数，包括本实例尚未写入的累计值，每个站点都在结果中
+// Requests and response bytes of several sites this month, including the counts this instance has not written yet, with every site in the result
+func (t *trafficType) Months(ctx context.Context, siteIDs []uint, now time.Time) (map[uint]SiteTrafficSum, error) {
+	start := monthStart(now)
+	sums := make(map[uint]SiteTrafficSum, len(siteIDs))
+	for _, id := range siteIDs {
+		sums[id] = SiteTrafficSum{SiteID: id}
+	}
+	if len(siteIDs) == 0 {
+		return sums, nil
+	}
+	var rows []SiteTrafficSum
+	err := t.db.WithContext(ctx).Model(&models.SiteTraffic{}).
+		Select("site_id, COALESCE(SUM(requests), 0) AS requests, COALESCE(SUM(bytes), 0) AS bytes").
+		Where("site_id IN ? AND day >= ?", siteIDs, start).Group("site_id").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		sums[row.SiteID] = row
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for key, row := range t.pending {
+		if sum, ok := sums[key.siteID]; ok && !key.day.Before(start) {
+			sum.Requests += row.Requests
+			sum.Bytes += row.Bytes
+			sums[key.siteID] = sum
+		}
+	}
+	return sums, nil
+}
+
+// MonthlyBytes 本月有流量的站点及其响应字节数，包括本实例尚未写入的累计值
+// Sites with traffic this month and their response bytes, including the counts this instance has not written yet
+func (t *trafficType) MonthlyBytes(ctx context.Context, now time.Time) (map[uint]int64, error) {
+	start := monthStart(now)
+	var sums []SiteTrafficSum
+	err := t.db.WithContext(ctx).Model(&models.SiteTraffic{}).
+		Select("site_id, SUM(bytes) AS bytes").
+		Where("day >= ?", start).Group("site_id").Scan(&sums).Error
+	if err != nil {
+		return nil, err
+	}
+	usage := make(map[uint]int64, len(sums))
+	for _, sum := range sums {
+		usage[sum.SiteID] = sum.Bytes
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for key, row := range t.pending {
+		if !key.day.Before(start) {
+			usage[key.siteID] += row.Bytes
+		}
+	}
+	return usage, nil
+}
+
+// RecordAlert 记录站点本月超出流量限制，返回 false 表示本月已经记录过该级别
+// Record that the site exceeded a transfer limit this month, false when the level was already recorded this month
+func (t *trafficType) RecordAlert(siteID uint, level string, bytes int64, now time.Time) (bool, error) {
+	alert := models.SiteTransferAlert{SiteID: siteID, Month: now.UTC().Format("2006-01"), Level: level, Bytes: bytes, CreatedAt: now}
+	result := t.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&alert)
+	return result.RowsAffected > 0, result.Error
+}
+
+// Top 获取一段时间内请求数最多的站点
+// Get the sites with the most requests over a period
+func (t *trafficType) Top(ctx context.Context, since time.Time, limit int) (sums []SiteTrafficSum, err error) {
+	err = t.db.WithContext(ctx).Model(&models.SiteTraffic{}).
+		Select("site_traffic.site_id, COALESCE(sites.name, '') AS name, SUM(site_traffic.requests) AS requests, SUM(site_traffic.bytes) AS bytes").
+		Joins("LEFT JOIN sites ON sites.id = site_traffic.site_id").
+		Where("site_traffic.day >= ?", since.UTC().Truncate(24*time.Hour)).
+		Group("site_traffic.site_id, sites.name").Order("requests DESC").Limit(limit).Scan(&sums).Error
+	return
+}
+
+// DeleteBefore 删除早于 before 的每日统计
+// Delete the daily statistics before the given time
+func (t *trafficType) DeleteBefore(before time.Time) (int64, error) {
+	result := t.db.Where("day < ?", before.UTC().Truncate(24*time.Hour)).Delete(&models.SiteTraffic{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,101 @@
+package store
+
+import (
+	"errors"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrTransferInvalid 转移不存在或已过期
+// The transfer does not exist or has expired
+var ErrTransferInvalid = errors.New("invalid or expired transfer")
+
+type transferType struct {
+	db *gorm.DB
+}
+
+// Transfer 项目转移
+// Project transfers
+var Transfer = transferType{
+	db: DB,
+}
+
+// Request 发起项目转移，替换该项目尚未完成的转移
+// Request a project transfer, replacing the pending transfer of the project
+func (t *transferType) Request(transfer *models.ProjectTransfer) error {
+	return t.db.Omit(clause.Associations).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "project_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"owner_type", "owner_id", "requested_by_id", "expires_at", "created_at"}),
+	}).Create(transfer).Error
+}
+
+// pending 仍等待确认的转移，回收站中的项目暂停转移
+// Transfers still awaiting confirmation, transfers of projects in the trash are suspended
+func (t *transferType) pending() *gorm.DB {
+	return t.db.Preload("Project").Preload("RequestedBy").
+		Where("expires_at > ? AND project_id IN (?)", time.Now(), t.db.Model(&models.Project{}).Select("id"))
+}
+
+// GetByID 根据 ID 获取仍等待确认的转移
+// Get a transfer still awaiting confirmation by ID
+func (t *transferType) GetByID(id uint) (*models.ProjectTransfer, error) {
+	transfer := &models.ProjectTransfer{}
+	if err := t.pending().First(transfer, id).Error; err != nil {
+		return nil, ErrTransferInvalid
+	}
+	return transfer, nil
+}
+
+// GetByProject 获取项目仍等待确认的转移
+// Get the transfer of a project still awaiting confirmation
+func (t *transferType) GetByProject(projectID uint) (*models.ProjectTransfer, error) {
+	transfer := &models.ProjectTransfer{}
+	if err := t.pending().Where("project_id = ?", projectID).First(transfer).Error; err != nil {
+		return nil, ErrTransferInvalid
+	}
+	return transfer, nil
+}
+
+// ListIncoming 获取用户可以接受的转移：转给用户本人，或转给其担任所有者或维护者的组织
+// List the transfers the user may accept: those to the user, or to organizations where the user is an owner or maintainer
+func (t *transferType) ListIncoming(userID uint) (transfers []models.ProjectTransfer, err error) {
+	orgs := t.db.Model(&models.OrgMember{}).Select("organization_id").
+		Where("user_id = ? AND role IN ?", userID, []constants.OrgRole{constants.OrgRoleOwner, constants.OrgRoleMaintainer})
+	err = t.pending().
+		Where(t.db.Where("owner_type = ? AND owner_id = ?", constants.OwnerTypeUser, userID).
+			Or("owner_type = ? AND owner_id IN (?)", constants.OwnerTypeOrg, orgs)).
+		Order("id DESC").
+		Find(&transfers).Error
+	return
+}
+
+// Cancel 取消或拒绝转移
+// Cancel or decline a transfer
+func (t *transferType) Cancel(transfer *models.ProjectTransfer) error {
+	return t.db.Delete(&models.ProjectTransfer{}, transfer.ID).Error
+}
+
+// Accept 完成转移，项目的站点、域名和设置随项目一起转移；
+// 原组织的团队授权不再适用而被移除，删除转移记录的条件保证同一转移只完成一次
+// Complete a transfer, the project's sites, domains and settings move with it;
+// team grants of the former organization no longer apply and are removed, deleting the transfer conditionally makes it complete only once
+func (t *transferType) Accept(transfer *models.ProjectTransfer) error {
+	return t.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND expires_at > ?", transfer.ID, time.Now()).Delete(&models.ProjectTransfer{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrTransferInvalid
+		}
+		if err := tx.Where("project_id = ?", transfer.ProjectID).Delete(&models.ProjectTeam{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Project{}).Where("id = ?", transfer.ProjectID).
+			Updates(map[string]any{"owner_type": transfer.OwnerType, "owner_id": transfer.OwnerID}).Error
+	})
+}
@@ -0,0 +1,185 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/storage"
+	"gorm.io/gorm"
+)
+
+type trashType struct {
+	db *gorm.DB
+}
+
+// Trash 回收站，已删除的项目和站点在保留期内可以恢复，名称、子域和域名保持占用直到彻底删除
+// The trash, deleted projects and sites can be restored within the retention window and keep their names, subdomains and domains until purged
+var Trash = trashType{
+	db: DB,
+}
+
+// ListProjects 分页获取所有者回收站中的项目
+// List the projects in the trash of an owner with pagination
+func (t *trashType) ListProjects(ctx context.Context, ownerType string, ownerID uint, page, limit int) (projects []models.Project, total int64, err error) {
+	return Paginate[models.Project](t.db.WithContext(ctx).Unscoped(), page, limit, "owner_type = ? AND owner_id = ? AND deleted_at IS NOT NULL", ownerType, ownerID)
+}
+
+// GetProject 获取回收站中的项目
+// Get a project in the trash
+func (t *trashType) GetProject(id uint) (project *models.Project, err error) {
+	project = &models.Project{}
+	err = t.db.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(project).Error
+	if err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// RestoreProject 恢复项目及随其删除的站点，在项目之前单独删除的站点仍留在回收站
+// Restore a project with the sites deleted along with it, sites deleted on their own before stay in the trash
+func (t *trashType) RestoreProject(project *models.Project) error {
+	return t.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Model(&models.Site{}).
+			Where("project_id = ? AND deleted_at >= ?", project.ID, project.DeletedAt.Time).
+			Update("deleted_at", nil).Error
+		if err != nil {
+			return err
+		}
+		return tx.Unscoped().Model(project).Update("deleted_at", nil).Error
+	})
+}
+
+// ListSites 分页获取项目回收站中的站点
+// List the sites in the trash of a project with pagination
+func (t *trashType) ListSites(ctx context.Context, projectID uint, page, limit int) (sites []models.Site, total int64, err error) {
+	return Paginate[models.Site](t.db.WithContext(ctx).Unscoped(), page, limit, "project_id = ? AND deleted_at IS NOT NULL", projectID)
+}
+
+// GetSite 获取项目回收站中的站点
+// Get a site in the trash of a project
+func (t *trashType) GetSite(projectID, id uint) (site *models.Site, err error) {
+	site = &models.Site{}
+	err = t.db.Unscoped().Where("id = ? AND project_id = ? AND deleted_at IS NOT NULL", id, projectID).Preload("Project").First(site).Error
+	if err != nil {
+		return nil, err
+	}
+	return site, nil
+}
+
+// RestoreSite 恢复回收站中的站点
+// Restore a site from the trash
+func (t *trashType) RestoreSite(site *models.Site) error {
+	return t.db.Unscoped().Model(site).Update("deleted_at", nil).Error
+}
+
+// ListExpiredProjects 获取删除时间早于 before 的项目
+// List the projects deleted before the given time
+func (t *trashType) ListExpiredProjects(before time.Time, limit int) (projects []models.Project, err error) {
+	err = t.db.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at <= ?", before).Order("deleted_at").Limit(limit).Find(&projects).Error
+	return
+}
+
+// ListExpiredSites 获取删除时间早于 before 的站点
+// List the sites deleted before the given time
+func (t *trashType) ListExpiredSites(before time.Time, limit int) (sites []models.Site, err error) {
+	err = t.db.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at <= ?", before).Order("deleted_at").Limit(limit).Find(&sites).Error
+	return
+}
+
+// PurgeProject 彻底删除项目及其全部站点、成员、团队授权和 webhook
+// Purge a project with all of its sites, members, team grants and webhooks
+func (t *trashType) PurgeProject(ctx context.Context, project *models.Project) error {
+	var sites []models.Site
+	if err := t.db.Unscoped().Where("project_id = ?", project.ID).Find(&sites).Error; err != nil {
+		return err
+	}
+	for _, site := range sites {
+		if err := t.PurgeSite(ctx, &site); err != nil {
+			return err
+		}
+	}
+	return t.db.Transaction(func(tx *gorm.DB) error {
+		hooks := tx.Session(&gorm.Session{NewDB: true}).Model(&models.Webhook{}).Unscoped().Select("id").Where("project_id = ?", project.ID)
+		if err := tx.Where("webhook_id IN (?)", hooks).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		for _, model := range []any{&models.Webhook{}, &models.CDNIntegration{}, &models.ProjectMember{}, &models.ProjectTeam{}, &models.ProjectTransfer{}, &models.ProjectLabel{}} {
+			if err := tx.Unscoped().Where("project_id = ?", project.ID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Unscoped().Delete(project).Error
+	})
+}
+
+// PurgeSite 彻底删除站点及其版本、预览、域名、仓库绑定和上传会话，不再被引用的版本文件和存储对象一并删除
+// Purge a site with its versions, previews, domains, repository binding and upload sessions, deleting version files and stored objects nothing references anymore
+func (t *trashType) PurgeSite(ctx context.Context, site *models.Site) error {
+	var uploads []models.Upload
+	if err := t.db.Where("site_id = ?", site.ID).Find(&uploads).Error; err != nil {
+		return err
+	}
+	for _, upload := range uploads {
+		if err := Upload.Delete(ctx, &upload); err != nil {
+			return err
+		}
+	}
+	var releases []models.SiteRelease
+	if err := t.db.Unscoped().Where("site_id = ?", site.ID).Preload("File").Find(&releases).Error; err != nil {
+		return err
+	}
+	var orphaned []models.File
+	err := t.db.Transaction(func(tx *gorm.DB) error {
+		for _, model := range []any{&models.SitePreview{}, &models.SiteBlockedPath{}, &models.SitePathPurge{}, &models.SiteDomain{},
+			&models.CustomDomain{}, &models.RunnerBuild{}, &models.GitIntegration{}, &models.SiteRelease{}, &models.SiteAnalytics{}, &models.AccessLog{}} {
+			if err := tx.Unscoped().Where("site_id = ?", site.ID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Unscoped().Delete(site).Error; err != nil {
+			return err
+		}
+		seen := make(map[uint]bool, len(releases))
+		for _, release := range releases {
+			if seen[release.FileID] {
+				continue
+			}
+			seen[release.FileID] = true
+			var count int64
+			if err := tx.Model(&models.SiteRelease{}).Where("file_id = ?", release.FileID).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				continue
+			}
+			if err := tx.Where("file_id = ?", release.FileID).Delete(&models.FileBlob{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Delete(&models.File{}, release.FileID).Error; err != nil {
+				return err
+			}
+			orphaned = append(orphaned, release.File)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, file := range orphaned {
+		if err := deleteFileObjects(ctx, &file); err != nil {
+			return err
+		}
+	}
+	// 预览图只属于本站点的版本 Preview images only belong to versions of this site
+	previews := make(map[string]bool)
+	for _, release := range releases {
+		if release.PreviewPath != "" && !previews[release.PreviewPath] {
+			previews[release.PreviewPath] = true
+			if err := storage.Default.Delete(ctx, release.PreviewPath); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
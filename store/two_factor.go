@@ -0,0 +1,117 @@
+package store
+
+import (
+	"errors"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/models"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+type twoFactorType struct {
+	db *gorm.DB
+}
+
+// TwoFactor 两步验证配置和恢复码
+// Two-factor configuration and recovery codes
+var TwoFactor = twoFactorType{
+	db: DB,
+}
+
+// GetTOTP 获取用户的 TOTP 配置，从主库读取以免刚启用时读到旧数据
+// Get the user's TOTP configuration, read from the primary so a freshly enabled one is never missed
+func (t *twoFactorType) GetTOTP(userID uint) (totp *models.UserTOTP, err error) {
+	totp = &models.UserTOTP{}
+	err = t.db.Clauses(dbresolver.Write).Where("user_id = ?", userID).First(totp).Error
+	if err != nil {
+		return nil, err
+	}
+	return totp, nil
+}
+
+// IsEnabled 判断用户是否启用了两步验证
+// Check whether the user enabled two-factor authentication
+func (t *twoFactorType) IsEnabled(userID uint) (bool, error) {
+	totp, err := t.GetTOTP(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return totp.Enabled, nil
+}
+
+// SaveTOTP 保存 TOTP 配置
+func (t *twoFactorType) SaveTOTP(totp *models.UserTOTP) error {
+	return t.db.Save(totp).Error
+}
+
+// EnableTOTP 启用 TOTP 并替换恢复码
+// Enable TOTP and replace the recovery codes
+func (t *twoFactorType) EnableTOTP(totp *models.UserTOTP, step int64, hashes []string) error {
+	return t.db.Transaction(func(tx *gorm.DB) error {
+		totp.Enabled = true
+		totp.LastUsedStep = step
+		if err := tx.Save(totp).Error; err != nil {
+			return err
+		}
+		return replaceRecoveryCodes(tx, totp.UserID, hashes)
+	})
+}
+
+// DisableTOTP 关闭两步验证，删除密钥和恢复码
+// Disable two-factor authentication, deleting the secret and the recovery codes
+func (t *twoFactorType) DisableTOTP(userID uint) error {
+	return t.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("user_id = ?", userID).Delete(&models.UserTOTP{}).Error
+	})
+}
+
+// ReplaceRecoveryCodes 重新生成恢复码，旧的恢复码全部失效
+// Regenerate recovery codes, all old codes become invalid
+func (t *twoFactorType) ReplaceRecoveryCodes(userID uint, hashes []string) error {
+	return t.db.Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, userID, hashes)
+	})
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID uint, hashes []string) error {
+	if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return err
+	}
+	codes := make([]models.RecoveryCode, 0, len(hashes))
+	for _, hash := range hashes {
+		codes = append(codes, models.RecoveryCode{UserID: userID, Hash: hash})
+	}
+	return tx.Create(&codes).Error
+}
+
+// UseTOTPStep 记录已使用的时间步，同一时间步或更早的验证码不能再次使用
+// Record the used time step, codes of the same or an earlier step cannot be used again
+func (t *twoFactorType) UseTOTPStep(totp *models.UserTOTP, step int64) (bool, error) {
+	result := t.db.Model(&models.UserTOTP{}).
+		Where("id = ? AND last_used_step < ?", totp.ID, step).
+		UpdateColumn("last_used_step", step)
+	return result.RowsAffected == 1, result.Error
+}
+
+// UseRecoveryCode 使用一个未使用过的恢复码
+// Consume an unused recovery code
+func (t *twoFactorType) UseRecoveryCode(userID uint, hash string) (bool, error) {
+	result := t.db.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND hash = ? AND used_at IS NULL", userID, hash).
+		UpdateColumn("used_at", time.Now())
+	return result.RowsAffected == 1, result.Error
+}
+
+// CountRecoveryCodes 统计剩余可用的恢复码
+// Count the remaining unused recovery codes
+func (t *twoFactorType) CountRecoveryCodes(userID uint) (count int64, err error) {
+	err = t.db.Model(&models.RecoveryCode{}).Where("user_id = ? AND used_at IS NULL", userID).Count(&count).Error
+	return
+}
@@ -0,0 +1,107 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/storage"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type uploadType struct {
+	db *gorm.DB
+}
+
+// Upload 分片上传会话
+// Chunked upload sessions
+var Upload = uploadType{
+	db: DB,
+}
+
+// Create 创建上传会话
+// Create an upload session
+func (u *uploadType) Create(upload *models.Upload) error {
+	return u.db.Create(upload).Error
+}
+
+// Get 获取站点的上传会话
+// Get an upload session of a site
+func (u *uploadType) Get(siteID uint, id string) (upload *models.Upload, err error) {
+	upload = &models.Upload{}
+	err = u.db.Where("id = ? AND site_id = ?", id, siteID).First(upload).Error
+	if err != nil {
+		return nil, err
+	}
+	return upload, nil
+}
+
+// SaveChunk 记录已上传的分片，重传时覆盖原记录
+// Record an uploaded chunk, overwriting the previous record on re-upload
+func (u *uploadType) SaveChunk(chunk *models.UploadChunk) error {
+	return u.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "upload_id"}, {Name: "index"}},
+		DoUpdates: clause.AssignmentColumns([]string{"size", "sha256"}),
+	}).Create(chunk).Error
+}
+
+// ListChunks 按序号获取已上传的分片
+// List the uploaded chunks ordered by index
+func (u *uploadType) ListChunks(id string) (chunks []models.UploadChunk, err error) {
+	err = u.db.Where("upload_id = ?", id).Order(clause.OrderByColumn{Column: clause.Column{Name: "index"}}).Find(&chunks).Error
+	return
+}
+
+// ChunkKey 分片在对象存储中的键，不使用子目录以免本地驱动残留空目录
+// Object key of a chunk in storage, flat so the local driver leaves no empty directories behind
+func (u *uploadType) ChunkKey(id string, index int) string {
+	return fmt.Sprintf("%s%s.%06d", storage.UploadPrefix, id, index)
+}
+
+// Delete 删除上传会话及其分片记录和分片对象
+// Delete an upload session with its chunk records and chunk objects
+func (u *uploadType) Delete(ctx context.Context, upload *models.Upload) error {
+	err := u.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("upload_id = ?", upload.ID).Delete(&models.UploadChunk{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(upload).Error
+	})
+	if err != nil {
+		return err
+	}
+	// 未上传的分片不存在，删除时不报错 Chunks never uploaded do not exist, which is not an error
+	for index := 0; index < upload.Chunks(); index++ {
+		if err := storage.Default.Delete(ctx, u.ChunkKey(upload.ID, index)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListExpired 获取已过期的上传会话
+// List expired upload sessions
+func (u *uploadType) ListExpired(now time.Time, limit int) (uploads []models.Upload, err error) {
+	err = u.db.Where("expires_at <= ?", now).Order("expires_at").Limit(limit).Find(&uploads).Error
+	return
+}
+
+// ExistingIDs 查询仍存在的上传会话ID
+// Look up which of the upload session IDs still exist
+func (u *uploadType) ExistingIDs(ctx context.Context, ids []string) (map[string]bool, error) {
+	existing := make(map[string]bool, len(ids))
+	for start := 0; start < len(ids); start += blobQueryBatch {
+		end := min(start+blobQueryBatch, len(ids))
+		var found []string
+		err := u.db.WithContext(ctx).Model(&models.Upload{}).Where("id IN ?", ids[start:end]).Pluck("id", &found).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range found {
+			existing[id] = true
+		}
+	}
+	return existing, nil
+}
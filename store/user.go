@@ -1,13 +1,25 @@
 package store
 
 import (
+	"context"
 	"errors"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/LiteyukiStudio/spage/constants"
 	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/utils"
 	"gorm.io/gorm"
 )
 
+// UserFilter 用户列表的筛选条件，零值表示不限
+// Filters of the user list, zero values match everything
+type UserFilter struct {
+	Keyword string         // 用户名、显示名称或邮箱包含的文本 Text contained in the name, display name or email
+	Role    constants.Role // 全局角色 Global role
+}
+
 type userType struct {
 	db *gorm.DB
 }
@@ -24,7 +36,7 @@ func (u *userType) Create(user *models.User) (err error) {
 // GetByName 根据名称获取用户
 func (u *userType) GetByName(name string) (user *models.User, err error) {
 	user = &models.User{} // 初始化指针 // Initialize pointer
-	err = u.db.Where("name = ?", name).First(user).Error
+	err = u.db.Where("LOWER(name) = ?", strings.ToLower(name)).First(user).Error
 	if err != nil {
 		return nil, err // 出错时返回nil When an error occurs, return nil
 	}
@@ -34,7 +46,7 @@ func (u *userType) GetByName(name string) (user *models.User, err error) {
 // IsNameExist 判断用户名是否存在
 func (u *userType) IsNameExist(name string) bool {
 	var count int64
-	err := u.db.Model(&models.User{}).Where("name = ?", name).Count(&count).Error
+	err := u.db.Model(&models.User{}).Where("LOWER(name) = ?", strings.ToLower(name)).Count(&count).Error
 	if err != nil {
 		return false
 	}
@@ -70,6 +82,18 @@ func (u *userType) Update(user *models.User) (err error) {
 	return nil
 }
 
+// SetPassword 只更新用户的密码哈希，用于登录时重新计算哈希
+// Update only the password hash of a user, used when the hash is recomputed on login
+func (u *userType) SetPassword(id uint, hashedPassword string) error {
+	return u.db.Model(&models.User{}).Where("id = ?", id).Update("password", hashedPassword).Error
+}
+
+// SetAvatar 设置上传的头像的内容哈希和头像地址，两者都可清空
+// Set the content hash of the uploaded avatar and the avatar URL, both may be cleared
+func (u *userType) SetAvatar(id uint, hash string, url *string) error {
+	return u.db.Model(&models.User{}).Where("id = ?", id).Updates(map[string]any{"avatar_hash": hash, "avatar_url": url}).Error
+}
+
 // DeleteByID 根据ID删除用户
 func (u *userType) DeleteByID(id uint) (err error) {
 	err = u.db.Delete(&models.User{}, id).Error
@@ -104,3 +128,168 @@ func (u *userType) UpdateSystemAdmin(user *models.User) (err error) {
 	user.ID = existingAdmin.ID
 	return u.db.Model(&existingAdmin).Updates(user).Error
 }
+
+// List 按条件分页查询用户
+// Query users by filter with pagination
+func (u *userType) List(ctx context.Context, filter UserFilter, list utils.ListQuery) (users []models.User, total int64, err error) {
+	query := u.db.WithContext(ctx)
+	if filter.Keyword != "" {
+		pattern := likePattern(strings.ToLower(filter.Keyword))
+		query = query.Where("(LOWER(name) LIKE ? ESCAPE '\\' OR LOWER(display_name) LIKE ? ESCAPE '\\' OR LOWER(email) LIKE ? ESCAPE '\\')", pattern, pattern, pattern)
+	}
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+	return PaginateList[models.User](query, list)
+}
+
+// Membership 用户所属的组织或项目及其角色
+// Organization or project a user belongs to and their role in it
+type Membership struct {
+	ID       uint              `json:"id"`        // 组织或项目ID Organization or project ID
+	Name     string            `json:"name"`      // 组织或项目名称 Organization or project name
+	Role     constants.OrgRole `json:"role"`      // 角色 Role
+	JoinedAt time.Time         `json:"joined_at"` // 加入时间 Joined time
+}
+
+// OrgMemberships 获取用户所属的组织及角色
+// List the organizations of a user with their roles
+func (u *userType) OrgMemberships(userID uint) (memberships []Membership, err error) {
+	err = u.db.Model(&models.OrgMember{}).
+		Select("organizations.id, organizations.name, organization_members.role, organization_members.created_at AS joined_at").
+		Joins("JOIN organizations ON organizations.id = organization_members.organization_id AND organizations.deleted_at IS NULL").
+		Where("organization_members.user_id = ?", userID).
+		Order("organizations.id").
+		Scan(&memberships).Error
+	return
+}
+
+// ProjectMemberships 获取直接授予用户角色的项目
+// List the projects granting the user a role directly
+func (u *userType) ProjectMemberships(userID uint) (memberships []Membership, err error) {
+	err = u.db.Model(&models.ProjectMember{}).
+		Select("projects.id, projects.name, project_members.role, project_members.created_at AS joined_at").
+		Joins("JOIN projects ON projects.id = project_members.project_id AND projects.deleted_at IS NULL").
+		Where("project_members.user_id = ?", userID).
+		Order("projects.id").
+		Scan(&memberships).Error
+	return
+}
+
+// OwnedProjects 获取用户个人所有的项目，不包括回收站中的项目
+// List the projects the user owns personally, without those in the trash
+func (u *userType) OwnedProjects(userID uint) (projects []models.Project, err error) {
+	err = u.db.Where("owner_type = ? AND owner_id = ?", constants.OwnerTypeUser, userID).Order("id").Find(&projects).Error
+	return
+}
+
+// SoleOwnerOrgs 获取用户是唯一所有者的组织，删除账号前需要转让或删除这些组织
+// List the organizations the user is the only owner of, which have to be handed over or deleted before the account is
+func (u *userType) SoleOwnerOrgs(userID uint) (orgs []models.Organization, err error) {
+	owned := u.db.Model(&models.OrgMember{}).Select("organization_id").Where("user_id = ? AND role = ?", userID, constants.OrgRoleOwner)
+	others := u.db.Model(&models.OrgMember{}).Select("organization_id").Where("user_id <> ? AND role = ?", userID, constants.OrgRoleOwner)
+	err = u.db.Where("id IN (?) AND id NOT IN (?)", owned, others).Order("id").Find(&orgs).Error
+	return
+}
+
+// OwnerLanguage 用户或组织接收通知使用的语言，组织使用最早加入的所有者的语言，没有时返回空串
+// Language notifications of a user or organization are written in, organizations use the language of their earliest owner, empty when there is none
+func (u *userType) OwnerLanguage(ownerType string, ownerID uint) string {
+	query := u.db.Model(&models.User{}).Where("id = ?", ownerID)
+	if ownerType == constants.OwnerTypeOrg {
+		owner := u.db.Model(&models.OrgMember{}).Select("user_id").Where("organization_id = ? AND role = ?", ownerID, constants.OrgRoleOwner).Order("id").Limit(1)
+		query = u.db.Model(&models.User{}).Where("id = (?)", owner)
+	}
+	var languages []string
+	if err := query.Limit(1).Pluck("language", &languages).Error; err != nil || len(languages) == 0 {
+		return ""
+	}
+	return languages[0]
+}
+
+// ScheduleDeletion 设置账号的删除时间，为空时取消删除
+// Set the time the account is deleted at, cancelling the deletion when nil
+func (u *userType) ScheduleDeletion(userID uint, at *time.Time) error {
+	return u.db.Model(&models.User{}).Where("id = ?", userID).Update("delete_at", at).Error
+}
+
+// SetDisabled 停用或重新启用账号，已签发的会话由调用方撤销
+// Disable an account or enable it again, the caller revokes the sessions already issued
+func (u *userType) SetDisabled(userID uint, disabled bool) error {
+	var at *time.Time
+	if disabled {
+		now := time.Now()
+		at = &now
+	}
+	return u.db.Model(&models.User{}).Where("id = ?", userID).Update("disabled_at", at).Error
+}
+
+// ListDueDeletions 获取删除时间已到的账号
+// List the accounts whose deletion time has come
+func (u *userType) ListDueDeletions(now time.Time, limit int) (users []models.User, err error) {
+	err = u.db.Where("delete_at IS NOT NULL AND delete_at <= ?", now).Order("delete_at").Limit(limit).Find(&users).Error
+	return
+}
+
+// Purge 删除账号：个人项目移入回收站，移除成员关系、登录方式、令牌、会话、配额和通知渠道，清除个人资料后软删除用户以保留审计日志和任务的引用；
+// 返回移入回收站的项目ID
+// Delete an account: personal projects go to the trash, memberships, sign-in methods, tokens, sessions, quotas and notification channels are removed,
+// and the profile is cleared before the user is soft-deleted so audit entries and jobs keep their reference; returns the IDs of the trashed projects
+func (u *userType) Purge(ctx context.Context, user *models.User) (projectIDs []uint, err error) {
+	if err := JWT.RevokeTokenByUserID(user.ID); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	err = u.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Project{}).Where("owner_type = ? AND owner_id = ?", constants.OwnerTypeUser, user.ID).Pluck("id", &projectIDs).Error; err != nil {
+			return err
+		}
+		if len(projectIDs) > 0 {
+			if err := tx.Model(&models.Site{}).Where("project_id IN ?", projectIDs).Update("deleted_at", now).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.Project{}).Where("id IN ?", projectIDs).Update("deleted_at", now).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("requested_by_id = ? OR (owner_type = ? AND owner_id = ?)", user.ID, constants.OwnerTypeUser, user.ID).Delete(&models.ProjectTransfer{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("owner_type = ? AND owner_id = ?", constants.OwnerTypeUser, user.ID).Delete(&models.Quota{}).Error; err != nil {
+			return err
+		}
+		if err := Notification.deleteByOwner(tx, constants.OwnerTypeUser, user.ID); err != nil {
+			return err
+		}
+		for _, model := range []any{
+			&models.OrgMember{}, &models.TeamMember{}, &models.ProjectMember{}, &models.UserIdentity{}, &models.UserTOTP{},
+			&models.RecoveryCode{}, &models.APIToken{}, &models.UserToken{}, &models.Token{},
+		} {
+			if err := tx.Unscoped().Where("user_id = ?", user.ID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Unscoped().Where("created_by_id = ?", user.ID).Delete(&models.Invitation{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("created_by_id = ?", user.ID).Delete(&models.DeployKey{}).Error; err != nil {
+			return err
+		}
+		// 名称和邮箱保持唯一，清除后可被重新注册 Names and emails stay unique, clearing them lets others sign up with them
+		err := tx.Model(user).Select("name", "display_name", "email", "description", "avatar_url", "avatar_hash", "password", "email_verified_at", "delete_at", "deleted_at").
+			Updates(map[string]any{
+				"name":              "deleted-user-" + strconv.FormatUint(uint64(user.ID), 10),
+				"display_name":      nil,
+				"email":             nil,
+				"description":       "",
+				"avatar_url":        nil,
+				"avatar_hash":       "",
+				"password":          nil,
+				"email_verified_at": nil,
+				"delete_at":         nil,
+				"deleted_at":        now,
+			}).Error
+		return err
+	})
+	return projectIDs, err
+}
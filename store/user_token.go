@@ -0,0 +1,80 @@
+package store
+
+import (
+	"errors"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/utils"
+	"gorm.io/gorm"
+)
+
+// ErrUserTokenInvalid 令牌不存在、已过期或已使用
+// The token does not exist, has expired or was already used
+var ErrUserTokenInvalid = errors.New("invalid or expired token")
+
+type userTokenType struct {
+	db *gorm.DB
+}
+
+// UserToken 邮箱验证和密码重置的一次性令牌
+// One-time tokens of email verification and password reset
+var UserToken = userTokenType{
+	db: DB,
+}
+
+// Issue 为用户签发一次性令牌并返回明文，同一用途此前未使用的令牌随之作废，顺带清理过期的令牌
+// Issue a one-time token for the user and return the plain token, earlier unused tokens of the same purpose are voided and expired tokens are cleaned up along the way
+func (u *userTokenType) Issue(userID uint, purpose, email string, ttl time.Duration) (string, error) {
+	plain, hash, err := utils.Token.NewOneTimeToken()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	err = u.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("expires_at < ?", now).Delete(&models.UserToken{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).Delete(&models.UserToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.UserToken{
+			UserID:    userID,
+			Purpose:   purpose,
+			Hash:      hash,
+			Email:     email,
+			ExpiresAt: now.Add(ttl),
+		}).Error
+	})
+	if err != nil {
+		return "", err
+	}
+	return plain, nil
+}
+
+// Consume 使用一次性令牌，条件更新保证并发请求中只有一个成功
+// Use a one-time token, the conditional update makes sure only one of concurrent requests succeeds
+func (u *userTokenType) Consume(plain, purpose string) (*models.UserToken, error) {
+	token := &models.UserToken{}
+	if err := u.db.Where("hash = ? AND purpose = ?", utils.Token.HashAPIToken(plain), purpose).First(token).Error; err != nil {
+		return nil, ErrUserTokenInvalid
+	}
+	now := time.Now()
+	result := u.db.Model(&models.UserToken{}).
+		Where("id = ? AND used_at IS NULL AND expires_at > ?", token.ID, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrUserTokenInvalid
+	}
+	token.UsedAt = &now
+	return token, nil
+}
+
+// Void 作废用户某种用途的所有未使用令牌，例如修改密码后作废重置链接
+// Void all unused tokens of a purpose for the user, such as reset links after the password changes
+func (u *userTokenType) Void(userID uint, purpose string) error {
+	return u.db.Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).Delete(&models.UserToken{}).Error
+}
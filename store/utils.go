@@ -2,6 +2,7 @@ package store
 
 import (
 	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/utils"
 	"gorm.io/gorm"
 )
 
@@ -10,6 +11,22 @@ import (
 // Paginate generic pagination query logic
 // T is any data model type
 func Paginate[T any](db *gorm.DB, page, limit int, conditions ...any) (items []T, total int64, err error) {
+	return PaginateOrdered[T](db, page, limit, "id DESC", conditions...)
+}
+
+// PaginateList 按列表接口的分页和排序参数查询
+// Query with the pagination and sorting parameters of a list endpoint
+func PaginateList[T any](db *gorm.DB, list utils.ListQuery, conditions ...any) (items []T, total int64, err error) {
+	order := list.Order
+	if order == "" {
+		order = "id DESC"
+	}
+	return PaginateOrdered[T](db, list.Page, list.Limit, order, conditions...)
+}
+
+// PaginateOrdered 按指定的排序子句分页查询，order 必须来自受信任的字段名
+// Paginated query with the given order clause, order must come from trusted field names
+func PaginateOrdered[T any](db *gorm.DB, page, limit int, order string, conditions ...any) (items []T, total int64, err error) {
 	// 查询总记录数
 	countDB := db
 	if len(conditions) > 0 {
@@ -38,7 +55,20 @@ func Paginate[T any](db *gorm.DB, page, limit int, conditions ...any) (items []T
 		queryDB = queryDB.Offset(offset)
 	}
 
-	err = queryDB.Limit(limit).Order("id DESC").Find(&items).Error
+	err = queryDB.Limit(limit).Order(order).Find(&items).Error
+	return
+}
+
+// FirstPerGroup 按 group 分组，每组按 order 取前 limit 条记录，结果整体按 order 排序；db 上的预加载作用于结果，group 和 order 必须来自受信任的字段名
+// Take the first limit records of each group by order, the whole result sorted by order; preloads on db apply to the result, group and order must come from trusted field names
+func FirstPerGroup[T any](db *gorm.DB, group, order string, limit int, conditions ...any) (items []T, err error) {
+	ranked := db.Session(&gorm.Session{NewDB: true}).Model(new(T)).
+		Select("*, ROW_NUMBER() OVER (PARTITION BY " + group + " ORDER BY " + order + ") AS group_row")
+	if len(conditions) > 0 {
+		ranked = ranked.Where(conditions[0], conditions[1:]...)
+	}
+	// 软删除的记录已在子查询中排除 Soft-deleted records are already excluded by the subquery
+	err = db.Unscoped().Table("(?) AS ranked", ranked).Where("group_row <= ?", limit).Order(order).Find(&items).Error
 	return
 }
 
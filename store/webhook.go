@@ -0,0 +1,136 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/models"
+	"gorm.io/gorm"
+)
+
+type webhookType struct {
+	db *gorm.DB
+}
+
+// Webhook 项目的 webhook 订阅及其投递记录
+// Webhook subscriptions of projects and their deliveries
+var Webhook = webhookType{
+	db: DB,
+}
+
+// ListByProject 获取项目的 webhook
+// List the webhooks of a project
+func (w *webhookType) ListByProject(projectID uint) (hooks []models.Webhook, err error) {
+	err = w.db.Where("project_id = ?", projectID).Order("id").Find(&hooks).Error
+	return
+}
+
+// GetByProject 获取项目的指定 webhook
+// Get the given webhook of a project
+func (w *webhookType) GetByProject(projectID, id uint) (hook *models.Webhook, err error) {
+	hook = &models.Webhook{}
+	err = w.db.Where("id = ? AND project_id = ?", id, projectID).First(hook).Error
+	if err != nil {
+		return nil, err
+	}
+	return hook, nil
+}
+
+// Create 创建 webhook
+// Create a webhook
+func (w *webhookType) Create(hook *models.Webhook) error {
+	return w.db.Create(hook).Error
+}
+
+// Update 更新 webhook，单独写入 active 以支持停用
+// Update a webhook, writing active separately so it can be disabled
+func (w *webhookType) Update(hook *models.Webhook) error {
+	return w.db.Model(hook).Select("url", "secret", "events", "format", "active").Updates(hook).Error
+}
+
+// Delete 删除 webhook 及其投递记录
+// Delete a webhook and its deliveries
+func (w *webhookType) Delete(hook *models.Webhook) error {
+	return w.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", hook.ID).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(hook).Error
+	})
+}
+
+// ListSubscribed 获取项目中订阅了事件的已启用 webhook
+// List the enabled webhooks of a project subscribed to the event
+func (w *webhookType) ListSubscribed(projectID uint, event string) (hooks []models.Webhook, err error) {
+	var all []models.Webhook
+	if err = w.db.Where("project_id = ? AND active = ?", projectID, true).Find(&all).Error; err != nil {
+		return nil, err
+	}
+	for _, hook := range all {
+		if hook.Subscribes(event) {
+			hooks = append(hooks, hook)
+		}
+	}
+	return hooks, nil
+}
+
+// CreateDeliveries 创建待投递的记录
+// Create deliveries waiting to be sent
+func (w *webhookType) CreateDeliveries(deliveries []models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return w.db.Omit("Webhook").Create(&deliveries).Error
+}
+
+// ListDue 获取已到重试时间的待投递记录
+// List pending deliveries whose attempt is due
+func (w *webhookType) ListDue(now time.Time, limit int) (deliveries []models.WebhookDelivery, err error) {
+	// 停用的 webhook 暂停投递 Deliveries of disabled webhooks are paused
+	err = w.db.Joins("JOIN webhooks ON webhooks.id = webhook_deliveries.webhook_id AND webhooks.active = ?", true).
+		Where("webhook_deliveries.status = ? AND webhook_deliveries.next_attempt_at <= ?", constants.WebhookDeliveryPending, now).
+		Order("webhook_deliveries.next_attempt_at").Limit(limit).Preload("Webhook").Find(&deliveries).Error
+	return
+}
+
+// Claim 领取一次投递尝试并推迟其下次尝试时间，多个实例同时领取时只有一个成功
+// Claim a delivery attempt and push back its next attempt, only one instance succeeds when several claim at once
+func (w *webhookType) Claim(delivery *models.WebhookDelivery, until time.Time) (bool, error) {
+	result := w.db.Model(&models.WebhookDelivery{}).
+		Where("id = ? AND status = ? AND attempts = ?", delivery.ID, constants.WebhookDeliveryPending, delivery.Attempts).
+		Updates(map[string]any{"attempts": delivery.Attempts + 1, "next_attempt_at": until})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	delivery.Attempts++
+	delivery.NextAttemptAt = until
+	return true, nil
+}
+
+// SaveAttempt 保存一次投递尝试的结果
+// Save the outcome of a delivery attempt
+func (w *webhookType) SaveAttempt(delivery *models.WebhookDelivery) error {
+	return w.db.Model(delivery).Select("status", "attempts", "next_attempt_at", "response_status", "response_body", "error", "delivered_at").
+		Updates(delivery).Error
+}
+
+// ListDeliveries 分页获取 webhook 的投递记录
+// List the deliveries of a webhook with pagination
+func (w *webhookType) ListDeliveries(ctx context.Context, webhookID uint, page, limit int) (deliveries []models.WebhookDelivery, total int64, err error) {
+	return Paginate[models.WebhookDelivery](w.db.WithContext(ctx), page, limit, "webhook_id = ?", webhookID)
+}
+
+// GetDelivery 获取 webhook 的指定投递记录
+// Get the given delivery of a webhook
+func (w *webhookType) GetDelivery(webhookID, id uint) (delivery *models.WebhookDelivery, err error) {
+	delivery = &models.WebhookDelivery{}
+	err = w.db.Where("id = ? AND webhook_id = ?", id, webhookID).First(delivery).Error
+	if err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
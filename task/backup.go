@@ -0,0 +1,79 @@
+package task
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/store"
+)
+
+// backupPrefix 定时备份文件名的前缀，与手动备份相同
+// Prefix of scheduled backup file names, the same as manual backups
+const backupPrefix = "spage-backup-"
+
+// backupInstance 将数据库和站点文件备份到 cron.backup-dir，写完后再重命名以免留下不完整的归档，并只保留最新的 cron.backup-keep 个
+// Back up the database and site files into cron.backup-dir, renaming once written so no partial archive is left, and keep only the latest cron.backup-keep
+func backupInstance(context.Context) (any, error) {
+	dir := config.CronBackupDir
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+	tmp, err := os.CreateTemp(dir, "."+backupPrefix+"*.tmp")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	if err := store.Backup.Dump(tmp); err != nil {
+		_ = tmp.Close()
+		return nil, err
+	}
+	info, err := tmp.Stat()
+	if err != nil {
+		_ = tmp.Close()
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, backupPrefix+time.Now().Format("20060102150405")+".zip")
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, err
+	}
+	removed, err := rotateBackups(dir, config.CronBackupKeep)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"file": path, "size": info.Size(), "removed": removed}, nil
+}
+
+// rotateBackups 删除目录中超出 keep 个的较早备份，keep 为 0 时全部保留
+// Delete the older backups of the directory beyond keep, keeping all of them when keep is 0
+func rotateBackups(dir string, keep int) (removed []string, err error) {
+	if keep <= 0 {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	// 文件名中的时间按字典序即为时间顺序 Timestamps in the names sort chronologically
+	var backups []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.Type().IsRegular() && strings.HasPrefix(name, backupPrefix) && strings.HasSuffix(name, ".zip") {
+			backups = append(backups, name)
+		}
+	}
+	for len(backups) > keep {
+		if err := os.Remove(filepath.Join(dir, backups[0])); err != nil {
+			return removed, err
+		}
+		removed = append(removed, backups[0])
+		backups = backups[1:]
+	}
+	return removed, nil
+}
@@ -0,0 +1,191 @@
+package task
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/utils"
+)
+
+const (
+	// cloudflarePurgeBatch Cloudflare 每次刷新请求最多包含的地址或前缀数量
+	// Max number of URLs or prefixes in one Cloudflare purge request
+	cloudflarePurgeBatch = 30
+	// cdnEvent 发送给 webhook 的刷新事件名称
+	// Event name of purges sent to webhooks
+	cdnEvent = "cdn.purge"
+)
+
+// CDN 服务商接口的地址 Addresses of the provider APIs
+const (
+	cloudflareAPI = "https://api.cloudflare.com/client/v4"
+	bunnyAPI      = "https://api.bunny.net"
+)
+
+// CDNPurge 一次 CDN 刷新的范围，Paths 为空时刷新主机名下的全部内容，/dir/* 形式的路径刷新整个目录
+// Scope of one CDN purge, everything under the hosts when Paths is empty, paths of the form /dir/* purge the whole directory
+type CDNPurge struct {
+	SiteID    uint     `json:"site_id"`              // 站点ID Site ID
+	Site      string   `json:"site"`                 // 站点名称 Site name
+	ReleaseID uint     `json:"release_id,omitempty"` // 切换到的版本ID Release ID switched to
+	Hosts     []string `json:"hosts"`                // 站点的主机名 Hosts of the site
+	Paths     []string `json:"paths,omitempty"`      // 要刷新的路径 Paths to purge
+}
+
+// PurgeCDN 调用 CDN 集成对应服务商的接口刷新缓存
+// Purge the cache through the API of the integration's provider
+func PurgeCDN(ctx context.Context, integration *models.CDNIntegration, purge CDNPurge) error {
+	if len(purge.Hosts) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(config.WebhookTimeout)*time.Second)
+	defer cancel()
+	switch integration.Provider {
+	case constants.CDNProviderCloudflare:
+		return purgeCloudflare(ctx, integration, purge)
+	case constants.CDNProviderBunny:
+		return purgeBunny(ctx, integration, purge)
+	case constants.CDNProviderWebhook:
+		return purgeWebhook(ctx, integration, purge)
+	}
+	return Permanent(fmt.Errorf("unknown CDN provider %q", integration.Provider))
+}
+
+// purgeURL 主机名下路径的完整地址，/dir/* 形式的路径保留结尾的通配符
+// Full URL of a path under the host, keeping the trailing wildcard of paths of the form /dir/*
+func purgeURL(host, p string) string {
+	dir, prefix := strings.CutSuffix(p, "*")
+	if !prefix {
+		return (&url.URL{Scheme: "https", Host: host, Path: p}).String()
+	}
+	return (&url.URL{Scheme: "https", Host: host, Path: dir}).String() + "*"
+}
+
+// purgeCloudflare 按主机名、地址或前缀刷新 Cloudflare 区域的缓存
+// Purge the cache of a Cloudflare zone by host, URL or prefix
+func purgeCloudflare(ctx context.Context, integration *models.CDNIntegration, purge CDNPurge) error {
+	lists := map[string][]string{}
+	if len(purge.Paths) == 0 {
+		lists["hosts"] = purge.Hosts
+	}
+	for _, host := range purge.Hosts {
+		for _, p := range purge.Paths {
+			if strings.HasSuffix(p, "/*") {
+				// 前缀不带协议和通配符 Prefixes carry neither the scheme nor the wildcard
+				lists["prefixes"] = append(lists["prefixes"], strings.TrimSuffix(strings.TrimPrefix(purgeURL(host, p), "https://"), "*"))
+			} else {
+				lists["files"] = append(lists["files"], purgeURL(host, p))
+			}
+		}
+	}
+	endpoint := cloudflareAPI + "/zones/" + url.PathEscape(integration.ZoneID) + "/purge_cache"
+	for key, values := range lists {
+		for start := 0; start < len(values); start += cloudflarePurgeBatch {
+			body, err := json.Marshal(map[string][]string{key: values[start:min(start+cloudflarePurgeBatch, len(values))]})
+			if err != nil {
+				return err
+			}
+			status, respBody, err := sendPurge(ctx, http.MethodPost, endpoint, body, map[string]string{
+				"Authorization": "Bearer " + integration.Token,
+			})
+			if err != nil {
+				return err
+			}
+			result := struct {
+				Success bool `json:"success"`
+				Errors  []struct {
+					Message string `json:"message"`
+				} `json:"errors"`
+			}{}
+			if err := json.Unmarshal(respBody, &result); err != nil || status < 200 || status >= 300 || !result.Success {
+				message := fmt.Sprintf("unexpected status %d", status)
+				if len(result.Errors) > 0 {
+					message = result.Errors[0].Message
+				}
+				return fmt.Errorf("cloudflare purge failed: %s", message)
+			}
+		}
+	}
+	return nil
+}
+
+// purgeBunny 逐个地址刷新 BunnyCDN 的缓存，整个主机名使用通配符地址
+// Purge the BunnyCDN cache URL by URL, a wildcard URL covers a whole host
+func purgeBunny(ctx context.Context, integration *models.CDNIntegration, purge CDNPurge) error {
+	paths := purge.Paths
+	if len(paths) == 0 {
+		paths = []string{"/*"}
+	}
+	for _, host := range purge.Hosts {
+		for _, p := range paths {
+			endpoint := bunnyAPI + "/purge?url=" + url.QueryEscape(purgeURL(host, p))
+			status, _, err := sendPurge(ctx, http.MethodPost, endpoint, nil, map[string]string{
+				"AccessKey": integration.Token,
+			})
+			if err != nil {
+				return err
+			}
+			if status < 200 || status >= 300 {
+				return fmt.Errorf("bunny purge of %s failed: unexpected status %d", purgeURL(host, p), status)
+			}
+		}
+	}
+	return nil
+}
+
+// purgeWebhook 向自定义地址发送签名的刷新请求，签名方式与项目 webhook 相同
+// Send a signed purge request to a custom URL, signed the same way as project webhooks
+func purgeWebhook(ctx context.Context, integration *models.CDNIntegration, purge CDNPurge) error {
+	body, err := json.Marshal(purge)
+	if err != nil {
+		return err
+	}
+	status, _, err := sendPurge(ctx, http.MethodPost, integration.URL, body, map[string]string{
+		utils.WebhookEventHeader:     cdnEvent,
+		utils.WebhookSignatureHeader: utils.Webhook.Sign(integration.Token, body),
+	})
+	if err != nil {
+		return err
+	}
+	if status < 200 || status >= 300 {
+		return fmt.Errorf("purge webhook failed: unexpected status %d", status)
+	}
+	return nil
+}
+
+// sendPurge 发送刷新请求，与 webhook 投递共用不允许访问内网地址的客户端
+// Send a purge request through the client shared with webhook deliveries, which refuses internal addresses
+func sendPurge(ctx context.Context, method, endpoint string, body []byte, headers map[string]string) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, Permanent(err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("User-Agent", "Spage-CDN")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := webhookClient.Do(req)
+	if errors.Is(err, ErrWebhookTarget) {
+		return 0, nil, Permanent(err)
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, webhookResponseLimit))
+	return resp.StatusCode, respBody, nil
+}
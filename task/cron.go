@@ -0,0 +1,317 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/cron"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// cronCheckInterval 检查定时任务是否到期的间隔
+	// Interval between checks whether scheduled tasks are due
+	cronCheckInterval = 30 * time.Second
+	// cronTimeout 单次运行的超时时间，运行更久的任务视为已中断，可以再次领取
+	// Timeout of one run, a task running longer counts as interrupted and may be claimed again
+	cronTimeout = 6 * time.Hour
+)
+
+var (
+	// ErrCronUnknown 没有注册该名称的定时任务 No scheduled task is registered under the name
+	ErrCronUnknown = errors.New("unknown scheduled task")
+	// ErrCronRunning 定时任务正在运行 The scheduled task is running
+	ErrCronRunning = errors.New("the scheduled task is already running")
+)
+
+// CronHandler 定时任务的说明、表达式和执行函数
+// Description, expression and handler of a scheduled task
+type CronHandler struct {
+	// Description 任务的说明 Description of the task
+	Description string
+	// Schedule 返回当前的 cron 表达式，为空时禁用，每次检查时调用以便动态配置生效
+	// Return the current cron expression, empty disables the task; called on every check so dynamic settings take effect
+	Schedule func() string
+	// Run 执行一次任务，返回的结果以 JSON 保存在任务状态中
+	// Run the task once, the returned result is saved as JSON in the task state
+	Run func(ctx context.Context) (any, error)
+}
+
+// CronInfo 定时任务的当前表达式和运行状态
+// Current expression and run state of a scheduled task
+type CronInfo struct {
+	Name        string
+	Description string
+	Schedule    string
+	Running     bool
+	State       models.CronTask
+}
+
+var (
+	cronHandlers   = make(map[string]CronHandler)
+	cronHandlersMu sync.RWMutex
+	// cronRuns 正在运行的定时任务，退出时等待其完成 Scheduled tasks running, waited for when exiting
+	cronRuns sync.WaitGroup
+)
+
+// RegisterCron 注册定时任务，应在 RunCron 启动之前调用
+// Register a scheduled task, meant to be called before RunCron starts
+func RegisterCron(name string, handler CronHandler) {
+	cronHandlersMu.Lock()
+	defer cronHandlersMu.Unlock()
+	cronHandlers[name] = handler
+}
+
+func cronHandler(name string) (CronHandler, bool) {
+	cronHandlersMu.RLock()
+	defer cronHandlersMu.RUnlock()
+	handler, ok := cronHandlers[name]
+	return handler, ok
+}
+
+// cronNames 按名称排序的已注册任务 Registered tasks sorted by name
+func cronNames() []string {
+	cronHandlersMu.RLock()
+	defer cronHandlersMu.RUnlock()
+	names := make([]string, 0, len(cronHandlers))
+	for name := range cronHandlers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// RunCron 注册内置的定时任务，按各自的 cron 表达式运行到期的任务，多个实例中只有一个执行同一次运行，直到 ctx 结束；结束时等待运行中的任务完成
+// Register the built-in scheduled tasks and run the due ones by their cron expressions, one instance running each run, until ctx is done; then running tasks are waited for
+func RunCron(ctx context.Context) {
+	RegisterCron(constants.CronTaskGC, CronHandler{
+		Description: "Storage garbage collection, run by gc.interval when no expression is set",
+		Schedule:    func() string { return config.CronGC },
+		Run:         cronGC,
+	})
+	RegisterCron(constants.CronTaskAnalytics, CronHandler{
+		Description: "Roll up traffic, analytics and request logs and prune records past their retention",
+		Schedule:    func() string { return config.CronAnalytics },
+		Run:         rollupTraffic,
+	})
+	RegisterCron(constants.CronTaskBackup, CronHandler{
+		Description: "Back up the database and site files to cron.backup-dir",
+		Schedule:    func() string { return config.CronBackup },
+		Run:         backupInstance,
+	})
+	RegisterCron(constants.CronTaskRekey, CronHandler{
+		Description: "Re-encrypt sensitive columns that are plain or encrypted with an old key using the active key",
+		Schedule:    func() string { return config.CronRekey },
+		Run:         rekeySecrets,
+	})
+	RegisterCron(constants.CronTaskRetention, CronHandler{
+		Description: "Remove old versions past the release retention policy of their project or the instance",
+		Schedule:    func() string { return config.CronRetention },
+		Run:         pruneReleases,
+	})
+	ticker := time.NewTicker(cronCheckInterval)
+	defer ticker.Stop()
+	for {
+		// 只读期间不运行，恢复后补上到期的运行 Nothing runs while read-only, due runs catch up once writes are back
+		if !config.ReadOnly() {
+			if err := scheduleCron(ctx, time.Now()); err != nil {
+				logrus.Warnf("failed to run scheduled tasks: %v", err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			cronRuns.Wait()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// scheduleCron 表达式变化时重新计算下次运行时间，并领取和启动到期的任务
+// Recompute the next run of tasks whose expression changed, then claim and start the due ones
+func scheduleCron(ctx context.Context, now time.Time) error {
+	names := cronNames()
+	tasks, err := store.Cron.List()
+	if err != nil {
+		return err
+	}
+	states := make(map[string]models.CronTask, len(tasks))
+	for _, t := range tasks {
+		states[t.Name] = t
+	}
+	var missing []string
+	for _, name := range names {
+		if _, ok := states[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if err := store.Cron.Ensure(missing); err != nil {
+		return err
+	}
+	for _, name := range names {
+		handler, _ := cronHandler(name)
+		state := states[name]
+		expr := handler.Schedule()
+		var schedule *cron.Schedule
+		if expr != "" {
+			if schedule, err = cron.Parse(expr); err != nil {
+				logrus.Warnf("scheduled task %s has an invalid expression %q: %v", name, expr, err)
+				continue
+			}
+		}
+		if expr != state.Schedule || (schedule != nil && state.NextRunAt == nil) {
+			var next *time.Time
+			if schedule != nil {
+				at := schedule.Next(now)
+				next = &at
+			}
+			if err := store.Cron.SetSchedule(name, expr, next); err != nil {
+				return err
+			}
+			continue
+		}
+		if schedule == nil || state.NextRunAt == nil || state.NextRunAt.After(now) {
+			continue
+		}
+		next := schedule.Next(now)
+		claimed, err := store.Cron.Claim(name, constants.CronTriggerSchedule, now, now.Add(-cronTimeout), &next)
+		if err != nil {
+			return err
+		}
+		if claimed {
+			startCron(ctx, name, handler)
+		}
+	}
+	return nil
+}
+
+// TriggerCron 立即在后台运行一次定时任务，不影响下次运行时间；任务未注册或正在运行时返回错误
+// Run a scheduled task once in the background right away without moving its next run; an error when the task is unknown or running
+func TriggerCron(ctx context.Context, name string) error {
+	handler, ok := cronHandler(name)
+	if !ok {
+		return ErrCronUnknown
+	}
+	if err := store.Cron.Ensure([]string{name}); err != nil {
+		return err
+	}
+	now := time.Now()
+	claimed, err := store.Cron.Claim(name, constants.CronTriggerManual, now, now.Add(-cronTimeout), nil)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return ErrCronRunning
+	}
+	startCron(ctx, name, handler)
+	return nil
+}
+
+// startCron 在后台运行已领取的任务并记录结果，运行不随 ctx 取消，只受超时限制
+// Run a claimed task in the background and record the outcome, the run outlives ctx and is only bound by the timeout
+func startCron(ctx context.Context, name string, handler CronHandler) {
+	cronRuns.Add(1)
+	go func() {
+		defer cronRuns.Done()
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cronTimeout)
+		defer cancel()
+		started := time.Now()
+		result, err := callCron(ctx, handler)
+		status, runErr, data := constants.JobSucceeded, "", ""
+		if err != nil {
+			status, runErr = constants.JobFailed, err.Error()
+			logrus.Warnf("scheduled task %s failed: %v", name, err)
+		} else {
+			logrus.Infof("Scheduled task %s finished in %s", name, time.Since(started).Round(time.Millisecond))
+		}
+		if result != nil {
+			if raw, merr := json.Marshal(result); merr == nil {
+				data = string(raw)
+			}
+		}
+		if err := store.Cron.Finish(name, time.Now(), status, runErr, data); err != nil {
+			logrus.Warnf("failed to record the run of scheduled task %s: %v", name, err)
+		}
+	}()
+}
+
+// callCron 调用任务的执行函数，执行函数的 panic 视为失败
+// Call the handler of a task, a panicking handler counts as a failure
+func callCron(ctx context.Context, handler CronHandler) (result any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("scheduled task panicked: %v", r)
+		}
+	}()
+	return handler.Run(ctx)
+}
+
+// ListCron 获取所有已注册定时任务的当前表达式和运行状态，表达式刚修改、尚未重新调度时按新表达式给出下次运行时间
+// Get the current expression and run state of every registered scheduled task, the next run follows a just changed expression before it is rescheduled
+func ListCron() ([]CronInfo, error) {
+	tasks, err := store.Cron.List()
+	if err != nil {
+		return nil, err
+	}
+	states := make(map[string]models.CronTask, len(tasks))
+	for _, t := range tasks {
+		states[t.Name] = t
+	}
+	now := time.Now()
+	names := cronNames()
+	infos := make([]CronInfo, 0, len(names))
+	for _, name := range names {
+		handler, _ := cronHandler(name)
+		info := CronInfo{
+			Name:        name,
+			Description: handler.Description,
+			Schedule:    handler.Schedule(),
+			State:       states[name],
+		}
+		info.State.Name = name
+		if info.Schedule != info.State.Schedule {
+			info.State.NextRunAt = nil
+			if schedule, err := cron.Parse(info.Schedule); err == nil {
+				next := schedule.Next(now)
+				info.State.NextRunAt = &next
+			}
+		}
+		info.Running = info.State.RunningSince != nil && now.Sub(*info.State.RunningSince) < cronTimeout
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// GetCron 获取一个已注册定时任务的当前表达式和运行状态
+// Get the current expression and run state of one registered scheduled task
+func GetCron(name string) (*CronInfo, error) {
+	infos, err := ListCron()
+	if err != nil {
+		return nil, err
+	}
+	for i := range infos {
+		if infos[i].Name == name {
+			return &infos[i], nil
+		}
+	}
+	return nil, ErrCronUnknown
+}
+
+// cronGC 创建一次存储垃圾回收任务
+// Enqueue a storage garbage collection job
+func cronGC(ctx context.Context) (any, error) {
+	job, err := EnqueueJob(ctx, constants.JobTypeStorageGC, GCJob{}, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"job_id": job.ID}, nil
+}
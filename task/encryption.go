@@ -0,0 +1,36 @@
+package task
+
+import (
+	"context"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/sirupsen/logrus"
+)
+
+// reencryptInterval 写回读取时发现的旧密文的间隔
+// Interval between write-backs of stale ciphertexts found while reading
+const reencryptInterval = time.Minute
+
+// Reencrypt 定期用当前密钥重新加密读取时发现的未加密或由旧密钥加密的敏感字段，直到 ctx 结束；其余的值由 rekey 定时任务处理
+// Periodically encrypt sensitive columns found plain or encrypted with an old key while reading again with the active key, until ctx is done; the other values are left to the rekey scheduled task
+func Reencrypt(ctx context.Context) {
+	ticker := time.NewTicker(reencryptInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := store.Encryption.Flush(ctx); err != nil {
+			logrus.Warnf("failed to re-encrypt sensitive columns: %v", err)
+		}
+	}
+}
+
+// rekeySecrets 用当前密钥重新加密所有未加密或由旧密钥加密的敏感字段，完成后旧密钥即可从配置中删除
+// Encrypt every sensitive column that is plain or encrypted with an old key again with the active key, after which old keys can be removed from the configuration
+func rekeySecrets(ctx context.Context) (any, error) {
+	return store.Encryption.Rekey(ctx)
+}
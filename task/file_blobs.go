@@ -0,0 +1,67 @@
+package task
+
+import (
+	"context"
+	"errors"
+
+	"github.com/LiteyukiStudio/spage/storage"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// BackfillFileBlobs 读取尚未记录引用内容的部署清单，用于配额的存储用量统计，记录之前创建的清单只需补齐一次
+// Read deployment manifests whose referenced contents are not recorded for quota storage accounting, manifests created before this was recorded only need this once
+func BackfillFileBlobs(ctx context.Context) {
+	filled, failed, err := linkManifests(ctx)
+	if err != nil {
+		logrus.Warnf("failed to list unlinked manifests: %v", err)
+	}
+	if failed > 0 {
+		logrus.Warnf("Could not record the contents of %d manifest(s)", failed)
+	}
+	if filled > 0 {
+		logrus.Infof("Recorded the contents of %d existing manifest(s)", filled)
+	}
+}
+
+// linkManifests 记录尚未记录引用内容的部署清单，返回已记录和无法读取的清单数量，存储中已不存在的清单不计入
+// Record the contents of manifests not recorded yet, returning how many were recorded and how many could not be read; manifests missing from storage count as neither
+func linkManifests(ctx context.Context) (filled, failed int, err error) {
+	var afterID uint
+	for ctx.Err() == nil {
+		files, err := store.Blob.ListUnlinkedManifests(afterID, fileSizeBatch)
+		if err != nil {
+			return filled, failed, err
+		}
+		if len(files) == 0 {
+			break
+		}
+		for _, file := range files {
+			afterID = file.ID
+			object, err := storage.Default.Get(ctx, file.Path)
+			if errors.Is(err, storage.ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				logrus.Warnf("failed to open manifest %s: %v", file.Path, err)
+				failed++
+				continue
+			}
+			manifest, err := utils.DecodeManifest(object)
+			_ = object.Close()
+			if err != nil {
+				logrus.Warnf("failed to read manifest %s: %v", file.Path, err)
+				failed++
+				continue
+			}
+			if err := store.Blob.Link(ctx, file.ID, manifest.Hashes()); err != nil {
+				logrus.Warnf("failed to record contents of manifest %d: %v", file.ID, err)
+				failed++
+				continue
+			}
+			filled++
+		}
+	}
+	return filled, failed, ctx.Err()
+}
@@ -0,0 +1,55 @@
+package task
+
+import (
+	"context"
+	"errors"
+
+	"github.com/LiteyukiStudio/spage/storage"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/sirupsen/logrus"
+)
+
+// fileSizeBatch 每批补齐大小的文件数量
+// Number of files measured per batch
+const fileSizeBatch = 100
+
+// BackfillFileSizes 从存储中读取尚未记录大小的文件，用于存储用量统计，记录大小之前上传的文件只需补齐一次
+// Read the size of files without one from storage for usage statistics, files uploaded before sizes were recorded only need this once
+func BackfillFileSizes(ctx context.Context) {
+	var afterID uint
+	filled := 0
+	for ctx.Err() == nil {
+		files, err := store.File.ListUnsized(afterID, fileSizeBatch)
+		if err != nil {
+			logrus.Warnf("failed to list files without size: %v", err)
+			return
+		}
+		if len(files) == 0 {
+			break
+		}
+		for _, file := range files {
+			afterID = file.ID
+			object, err := storage.Default.Get(ctx, file.Path)
+			if errors.Is(err, storage.ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				logrus.Warnf("failed to open file %s: %v", file.Path, err)
+				continue
+			}
+			size := object.Info().Size
+			_ = object.Close()
+			if size <= 0 {
+				continue
+			}
+			if err := store.File.SetSize(&file, size); err != nil {
+				logrus.Warnf("failed to record size of file %d: %v", file.ID, err)
+				continue
+			}
+			filled++
+		}
+	}
+	if filled > 0 {
+		logrus.Infof("Recorded the size of %d existing file(s)", filled)
+	}
+}
@@ -0,0 +1,364 @@
+package task
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/utils"
+)
+
+const (
+	// GitLogLimit 保存的构建输出长度，只保留末尾
+	// Length of the kept build output, only the tail is kept
+	GitLogLimit = 16 * 1024
+	// gitLogInterval 构建期间报告输出的间隔
+	// Interval of output reports during a build
+	gitLogInterval = time.Second
+)
+
+var (
+	// ErrGitRepoURL 仓库地址不是允许的 https 地址
+	// The repository URL is not an allowed https URL
+	ErrGitRepoURL = errors.New("repository URL must be a public https URL")
+	// ErrGitBuildDisabled 实例未开启构建
+	// Builds are disabled on this instance
+	ErrGitBuildDisabled = errors.New("build commands are disabled on this instance")
+	// ErrGitOutputDir 输出目录不存在或位于仓库之外
+	// The output directory does not exist or lies outside the repository
+	ErrGitOutputDir = errors.New("output directory must be an existing directory inside the repository")
+	// ErrGitBuildImage 构建沙箱需要镜像但未指定
+	// The build sandbox needs an image but none is given
+	ErrGitBuildImage = errors.New("the build sandbox needs a build image")
+)
+
+// BuildPreset 常见静态站点生成器的默认构建镜像、命令和输出目录
+// Default build image, command and output directory of a common static site generator
+type BuildPreset struct {
+	Image     string `json:"image"`      // 构建镜像 Build image
+	Command   string `json:"command"`    // 构建命令 Build command
+	OutputDir string `json:"output_dir"` // 输出目录 Output directory
+}
+
+// BuildPresets 按名称索引的构建预设 Build presets by name
+var BuildPresets = map[string]BuildPreset{
+	constants.BuildPresetHugo:   {Image: "hugomods/hugo:exts", Command: "hugo --minify", OutputDir: "public"},
+	constants.BuildPresetMkDocs: {Image: "squidfunk/mkdocs-material", Command: "mkdocs build", OutputDir: "site"},
+	constants.BuildPresetVite:   {Image: "node:22", Command: "npm ci && npm run build", OutputDir: "dist"},
+}
+
+var (
+	gitSlots     chan struct{}
+	gitSlotsOnce sync.Once
+)
+
+// GitBuild 一次推送部署的拉取和构建参数
+// Fetch and build parameters of a push deployment
+type GitBuild struct {
+	Provider     string // 代码托管平台 Git hosting provider
+	RepoURL      string // 仓库地址 Repository URL
+	Token        string // 访问令牌 Access token
+	Branch       string // 分支 Branch
+	Commit       string // 提交，为空时使用分支的最新提交 Commit, the head of the branch when empty
+	BuildCommand string // 构建命令 Build command
+	BuildImage   string // 构建镜像，替换沙箱前缀中的 {image} Build image, replacing {image} in the sandbox prefix
+	OutputDir    string // 输出目录 Output directory
+
+	OnLog func(log string) // 构建期间定期收到截断后的输出，可为空 Receives the truncated output periodically during the build, may be nil
+}
+
+// GitResult 拉取和构建的结果，Cleanup 删除工作目录
+// Outcome of a fetch and build, Cleanup removes the work directory
+type GitResult struct {
+	Archive string // 输出目录的 zip 压缩包 Zip archive of the output directory
+	Commit  string // 实际构建的提交 Commit actually built
+	Log     string // 拉取和构建的输出，已截断 Fetch and build output, truncated
+	Cleanup func()
+}
+
+// CheckGitRepo 检查仓库地址，未开启 git.allow-private 时只允许解析到公网地址的 https 地址
+// Check a repository URL, only https URLs resolving to public addresses are allowed unless git.allow-private is set
+func CheckGitRepo(raw string) error {
+	target, err := url.Parse(raw)
+	if err != nil || target.Host == "" || target.User != nil {
+		return ErrGitRepoURL
+	}
+	if config.GitAllowPrivate {
+		if target.Scheme != "https" && target.Scheme != "http" {
+			return ErrGitRepoURL
+		}
+		return nil
+	}
+	if target.Scheme != "https" {
+		return ErrGitRepoURL
+	}
+	ips, err := net.LookupIP(target.Hostname())
+	if err != nil || len(ips) == 0 {
+		return fmt.Errorf("resolve repository host: %w", err)
+	}
+	for _, ip := range ips {
+		if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+			return ErrGitRepoURL
+		}
+	}
+	return nil
+}
+
+// RunGitBuild 拉取提交，按需执行构建命令，并将输出目录打包为 zip；同时进行的数量受 git.concurrency 限制
+// Fetch the commit, run the build command when set and pack the output directory into a zip; concurrency is limited by git.concurrency
+func RunGitBuild(ctx context.Context, build *GitBuild) (*GitResult, error) {
+	if build.BuildCommand != "" && !config.GitBuildEnable {
+		return nil, ErrGitBuildDisabled
+	}
+	if err := CheckGitRepo(build.RepoURL); err != nil {
+		return nil, err
+	}
+	gitSlotsOnce.Do(func() {
+		gitSlots = make(chan struct{}, max(config.GitConcurrency, 1))
+	})
+	select {
+	case gitSlots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-gitSlots }()
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(config.GitBuildTimeout)*time.Second)
+	defer cancel()
+	workDir, err := os.MkdirTemp("", "spage-git-")
+	if err != nil {
+		return nil, err
+	}
+	result := &GitResult{Cleanup: func() { _ = os.RemoveAll(workDir) }}
+	output := &tailWriter{limit: GitLogLimit}
+	if build.OnLog != nil {
+		stop := reportGitLog(output, build.OnLog)
+		defer stop()
+	}
+	if err := runGitBuild(ctx, build, workDir, output, result); err != nil {
+		result.Cleanup()
+		if ctx.Err() != nil {
+			err = fmt.Errorf("timed out after %ds: %w", config.GitBuildTimeout, err)
+		}
+		return &GitResult{Commit: result.Commit, Log: output.String(), Cleanup: func() {}}, err
+	}
+	result.Log = output.String()
+	return result, nil
+}
+
+// reportGitLog 输出变化时按 gitLogInterval 报告，返回的函数停止报告
+// Report the output every gitLogInterval when it changed, the returned function stops reporting
+func reportGitLog(output *tailWriter, onLog func(string)) func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(gitLogInterval)
+		defer ticker.Stop()
+		last := ""
+		for {
+			select {
+			case <-ticker.C:
+			case <-done:
+				return
+			}
+			if current := output.String(); current != last {
+				last = current
+				onLog(current)
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+func runGitBuild(ctx context.Context, build *GitBuild, workDir string, output io.Writer, result *GitResult) error {
+	srcDir := filepath.Join(workDir, "src")
+	homeDir := filepath.Join(workDir, "home")
+	if err := os.MkdirAll(homeDir, 0o700); err != nil {
+		return err
+	}
+	git := func(args ...string) error {
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Env = gitEnv(build, homeDir)
+		cmd.Stdout, cmd.Stderr = output, output
+		cmd.WaitDelay = 10 * time.Second
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("git %s: %w", args[0], err)
+		}
+		return nil
+	}
+	if err := git("init", "-q", srcDir); err != nil {
+		return err
+	}
+	// 优先按提交拉取，托管平台不支持时回退到分支的最新提交
+	// Fetch by commit first, falling back to the head of the branch when the host does not allow it
+	fetched := false
+	if build.Commit != "" {
+		fetched = git("-C", srcDir, "fetch", "-q", "--depth", "1", "--no-tags", build.RepoURL, build.Commit) == nil
+	}
+	if !fetched {
+		if err := git("-C", srcDir, "fetch", "-q", "--depth", "1", "--no-tags", build.RepoURL, "refs/heads/"+build.Branch); err != nil {
+			return err
+		}
+	}
+	if err := git("-C", srcDir, "checkout", "-q", "--detach", "FETCH_HEAD"); err != nil {
+		return err
+	}
+	head, err := exec.CommandContext(ctx, "git", "-C", srcDir, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return fmt.Errorf("git rev-parse: %w", err)
+	}
+	result.Commit = strings.TrimSpace(string(head))
+
+	if build.BuildCommand != "" {
+		if err := runBuildCommand(ctx, build, srcDir, homeDir, result.Commit, output); err != nil {
+			return err
+		}
+	}
+	outDir, err := gitOutputDir(srcDir, build.OutputDir)
+	if err != nil {
+		return err
+	}
+	result.Archive = filepath.Join(workDir, "site.zip")
+	count, err := utils.ZipDir(outDir, result.Archive, func(_ string, d fs.DirEntry) bool {
+		return d.IsDir() && d.Name() == ".git"
+	})
+	if err == nil && count == 0 {
+		err = errors.New("output directory is empty")
+	}
+	return err
+}
+
+// gitEnv git 命令的环境变量，访问令牌通过环境中的配置以请求头传入，不会写入仓库配置或出现在命令行中
+// Environment of git commands, the access token is passed as a header through config in the environment and never lands in the repository config or the command line
+func gitEnv(build *GitBuild, homeDir string) []string {
+	env := []string{
+		"PATH=" + os.Getenv("PATH"),
+		"HOME=" + homeDir,
+		"GIT_TERMINAL_PROMPT=0",
+		"GIT_CONFIG_NOSYSTEM=1",
+		"GIT_ALLOW_PROTOCOL=https:http",
+	}
+	configs := [][2]string{{"advice.detachedHead", "false"}}
+	if !config.GitAllowPrivate {
+		// 重定向可能绕过地址检查 Redirects could bypass the address check
+		configs = append(configs, [2]string{"http.followRedirects", "false"})
+	}
+	if build.Token != "" {
+		user := "x-access-token"
+		if build.Provider == constants.GitProviderGitLab {
+			user = "oauth2"
+		}
+		credentials := base64.StdEncoding.EncodeToString([]byte(user + ":" + build.Token))
+		configs = append(configs, [2]string{"http.extraHeader", "Authorization: Basic " + credentials})
+	}
+	env = append(env, fmt.Sprintf("GIT_CONFIG_COUNT=%d", len(configs)))
+	for i, kv := range configs {
+		env = append(env, fmt.Sprintf("GIT_CONFIG_KEY_%d=%s", i, kv[0]), fmt.Sprintf("GIT_CONFIG_VALUE_%d=%s", i, kv[1]))
+	}
+	return env
+}
+
+// runBuildCommand 在工作目录中执行构建命令，配置了 git.build-sandbox 时在沙箱中执行；环境中不包含服务端的任何配置或凭据
+// Run the build command in the work directory, inside git.build-sandbox when configured; the environment carries none of the server's config or credentials
+func runBuildCommand(ctx context.Context, build *GitBuild, srcDir, homeDir, commit string, output io.Writer) error {
+	args := []string{"sh", "-c", build.BuildCommand}
+	if config.GitBuildSandbox != "" {
+		sandbox, err := sandboxArgs(config.GitBuildSandbox, srcDir, build.BuildImage)
+		if err != nil {
+			return err
+		}
+		args = append(sandbox, args...)
+	}
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Dir = srcDir
+	cmd.Env = []string{
+		"PATH=" + os.Getenv("PATH"),
+		"HOME=" + homeDir,
+		"CI=true",
+		"SPAGE_COMMIT=" + commit,
+		"SPAGE_BRANCH=" + build.Branch,
+	}
+	cmd.Stdout, cmd.Stderr = output, output
+	cmd.WaitDelay = 10 * time.Second
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("build command: %w", err)
+	}
+	return nil
+}
+
+// sandboxArgs 展开沙箱前缀，{dir} 替换为工作目录，{image} 替换为构建镜像，未指定镜像时使用 git.build-image
+// Expand the sandbox prefix, {dir} becomes the work directory and {image} the build image, git.build-image when none is given
+func sandboxArgs(sandbox, dir, image string) ([]string, error) {
+	if image == "" {
+		image = config.GitBuildImage
+	}
+	fields := strings.Fields(sandbox)
+	for i, field := range fields {
+		if strings.Contains(field, "{image}") && image == "" {
+			return nil, ErrGitBuildImage
+		}
+		fields[i] = strings.NewReplacer("{dir}", dir, "{image}", image).Replace(field)
+	}
+	return fields, nil
+}
+
+// gitOutputDir 解析输出目录，符号链接解析后仍须位于仓库之内
+// Resolve the output directory, it must stay inside the repository after resolving symlinks
+func gitOutputDir(srcDir, outputDir string) (string, error) {
+	root, err := filepath.EvalSymlinks(srcDir)
+	if err != nil {
+		return "", err
+	}
+	dir, err := filepath.EvalSymlinks(filepath.Join(root, filepath.Clean("/"+outputDir)))
+	if err != nil {
+		return "", ErrGitOutputDir
+	}
+	if dir != root && !strings.HasPrefix(dir, root+string(filepath.Separator)) {
+		return "", ErrGitOutputDir
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return "", ErrGitOutputDir
+	}
+	return dir, nil
+}
+
+// tailWriter 只保留最后 limit 字节的输出
+// Keep only the last limit bytes of output
+type tailWriter struct {
+	mu    sync.Mutex
+	buf   []byte
+	limit int
+}
+
+func (w *tailWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = append(w.buf, p...)
+	if over := len(w.buf) - w.limit; over > 0 {
+		w.buf = append(w.buf[:0], w.buf[over:]...)
+	}
+	return len(p), nil
+}
+
+func (w *tailWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return string(w.buf)
+}
@@ -0,0 +1,284 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/tracing"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// jobBatch 每轮领取的任务数量上限
+	// Max number of jobs claimed per round
+	jobBatch = 20
+	// jobPollInterval 检查到期任务的间隔
+	// Interval between checks for due jobs
+	jobPollInterval = 2 * time.Second
+	// jobRetryBase 第一次重试的等待时间，之后每次乘以 4
+	// Wait before the first retry, multiplied by 4 for each further retry
+	jobRetryBase = 30 * time.Second
+	// jobLeaseMargin 租约在超时之外多保留的时间，避免仍在收尾的任务被其他实例重复领取
+	// Extra time the lease lasts beyond the timeout so a job still wrapping up is not claimed again elsewhere
+	jobLeaseMargin = time.Minute
+	// jobCleanupInterval 清理已结束任务记录的间隔
+	// Interval between deletions of finished job records
+	jobCleanupInterval = time.Hour
+)
+
+// ErrJobPermanent 任务因为无法通过重试解决的原因失败，例如参数无效
+// The job failed for a reason retrying cannot fix, such as invalid parameters
+var ErrJobPermanent = errors.New("permanent job failure")
+
+// permanentError 不可重试的错误，错误信息与原错误相同
+// A non-retryable error, keeping the message of the original error
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e permanentError) Unwrap() []error {
+	return []error{ErrJobPermanent, e.err}
+}
+
+// Permanent 标记错误不可重试，任务立即进入失败状态
+// Mark an error as not retryable, the job fails right away
+func Permanent(err error) error {
+	return permanentError{err: err}
+}
+
+// JobHandler 一种任务类型的执行函数
+// Functions running one job type
+type JobHandler struct {
+	// Run 执行一次任务，返回的结果以 JSON 保存在任务记录中
+	// Run one attempt, the returned result is saved as JSON in the job record
+	Run func(ctx context.Context, job *models.Job) (any, error)
+	// Discard 任务最终失败或被取消后清理其占用的资源，可为空
+	// Release the resources held by a job once it finally failed or was canceled, may be nil
+	Discard func(ctx context.Context, job *models.Job)
+}
+
+var (
+	jobHandlers   = make(map[string]JobHandler)
+	jobHandlersMu sync.RWMutex
+	jobWake       = make(chan struct{}, 1)
+)
+
+// RegisterJob 注册任务类型的执行函数，应在 RunJobs 启动之前调用
+// Register the handler of a job type, meant to be called before RunJobs starts
+func RegisterJob(kind string, handler JobHandler) {
+	jobHandlersMu.Lock()
+	defer jobHandlersMu.Unlock()
+	jobHandlers[kind] = handler
+}
+
+func jobHandler(kind string) (JobHandler, bool) {
+	jobHandlersMu.RLock()
+	defer jobHandlersMu.RUnlock()
+	handler, ok := jobHandlers[kind]
+	return handler, ok
+}
+
+// EnqueueJob 创建任务并通知工作协程，payload 以 JSON 保存，ctx 中的链路上下文随任务保存，任务的 span 沿用该链路
+// Create a job and tell the workers about it, payload is saved as JSON and the trace context of ctx is kept with the job so its spans continue that trace
+func EnqueueJob(ctx context.Context, kind string, payload any, siteID, userID uint) (*models.Job, error) {
+	return EnqueueJobAt(ctx, kind, payload, siteID, userID, time.Now())
+}
+
+// EnqueueJobAt 创建在 runAt 之后执行的任务，用于定时上线等延迟执行的任务
+// Create a job running no earlier than runAt, used by delayed work such as scheduled go-lives
+func EnqueueJobAt(ctx context.Context, kind string, payload any, siteID, userID uint, runAt time.Time) (*models.Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	job := &models.Job{
+		Type:        kind,
+		Payload:     string(data),
+		Status:      constants.JobPending,
+		MaxAttempts: max(config.JobMaxAttempts, 1),
+		RunAt:       runAt,
+		UserID:      userID,
+		SiteID:      siteID,
+		TraceParent: tracing.Inject(ctx),
+	}
+	if err := store.Job.Create(job); err != nil {
+		return nil, err
+	}
+	WakeJobs()
+	return job, nil
+}
+
+// WakeJobs 通知工作协程有新的待执行任务
+// Tell the workers that new jobs are waiting
+func WakeJobs() {
+	select {
+	case jobWake <- struct{}{}:
+	default:
+	}
+}
+
+// RunJobs 注册内置的任务类型，领取并执行到期的后台任务，失败的任务按指数退避重试，直到 ctx 结束；结束时不再领取新任务并等待执行中的任务完成
+// Register the built-in job types, then claim and run due background jobs, retrying failed ones with exponential backoff until ctx is done; then no new jobs are claimed and running ones are waited for
+func RunJobs(ctx context.Context) {
+	RegisterJob(constants.JobTypeStorageGC, JobHandler{Run: collectGarbage})
+	workers := make(chan struct{}, max(config.JobWorkers, 1))
+	ticker := time.NewTicker(jobPollInterval)
+	defer ticker.Stop()
+	var cleaned time.Time
+	for {
+		now := time.Now()
+		// 只读期间任务留在队列中，恢复后继续执行 Jobs stay queued while read-only and run once writes are back
+		if !config.ReadOnly() {
+			if err := runJobs(ctx, now, workers); err != nil {
+				logrus.Warnf("failed to run jobs: %v", err)
+			}
+		}
+		if config.JobRetention > 0 && now.Sub(cleaned) >= jobCleanupInterval {
+			cleaned = now
+			if removed, err := store.Job.DeleteFinished(now.Add(-time.Duration(config.JobRetention) * time.Second)); err != nil {
+				logrus.Warnf("failed to delete finished jobs: %v", err)
+			} else if removed > 0 {
+				logrus.Infof("Deleted %d finished job record(s)", removed)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			// 占满所有工作位即所有任务都已完成 Holding every worker slot means all jobs have finished
+			for range cap(workers) {
+				workers <- struct{}{}
+			}
+			return
+		case <-ticker.C:
+		case <-jobWake:
+		}
+	}
+}
+
+func runJobs(ctx context.Context, now time.Time, workers chan struct{}) error {
+	free := cap(workers) - len(workers)
+	if free <= 0 {
+		return nil
+	}
+	jobs, err := store.Job.ListDue(now, min(free, jobBatch))
+	if err != nil {
+		return err
+	}
+	timeout := time.Duration(config.JobTimeout) * time.Second
+	// 退出时执行中的任务继续运行到完成或超时 Running jobs go on until they finish or time out when exiting
+	jobCtx := context.WithoutCancel(ctx)
+	for i := range jobs {
+		job := &jobs[i]
+		// 领取期间其他实例不会重复执行 Other instances skip the job while it is claimed
+		claimed, err := store.Job.Claim(job, now, now.Add(timeout+jobLeaseMargin))
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			continue
+		}
+		if job.Attempts > job.MaxAttempts {
+			// 最后一次执行中途实例退出，不再重试 The instance went away during the last attempt, no retry is left
+			finished := time.Now()
+			job.Status = constants.JobFailed
+			job.Error = "the job was interrupted and no attempts are left"
+			job.FinishedAt = &finished
+			DiscardJob(ctx, job)
+			if err := store.Job.SaveAttempt(job); err != nil {
+				return err
+			}
+			continue
+		}
+		workers <- struct{}{}
+		go func() {
+			defer func() { <-workers }()
+			runJob(jobCtx, job, timeout)
+			if err := store.Job.SaveAttempt(job); err != nil {
+				logrus.Warnf("failed to save job %d: %v", job.ID, err)
+			}
+			// 空出的位置可以立即领取下一个任务 The freed slot may pick up the next job right away
+			WakeJobs()
+		}()
+	}
+	return nil
+}
+
+// runJob 执行一次任务并记录结果
+// Run a job once and record the outcome
+func runJob(ctx context.Context, job *models.Job, timeout time.Duration) {
+	ctx, span := tracing.Start(tracing.Extract(ctx, job.TraceParent), "job "+job.Type, trace.SpanKindConsumer,
+		attribute.Int64("job.id", int64(job.ID)),
+		attribute.String("job.type", job.Type),
+		attribute.Int("job.attempt", job.Attempts),
+		attribute.Int64("site.id", int64(job.SiteID)),
+	)
+	result, err := callJob(ctx, job, timeout)
+	tracing.End(span, err)
+	now := time.Now()
+	job.Error = ""
+	if err == nil {
+		data, merr := json.Marshal(result)
+		if merr == nil {
+			job.Status = constants.JobSucceeded
+			job.Result = string(data)
+			job.FinishedAt = &now
+			return
+		}
+		err = Permanent(merr)
+	}
+	job.Error = err.Error()
+	if errors.Is(err, ErrJobPermanent) || job.Attempts >= job.MaxAttempts {
+		job.Status = constants.JobFailed
+		job.FinishedAt = &now
+		logrus.Warnf("job %d (%s) failed after %d attempt(s): %s", job.ID, job.Type, job.Attempts, job.Error)
+		DiscardJob(ctx, job)
+		return
+	}
+	job.Status = constants.JobPending
+	job.RunAt = now.Add(jobRetryBase << (2 * (job.Attempts - 1)))
+}
+
+// callJob 在超时时间内调用任务的执行函数，执行函数的 panic 视为失败
+// Call the handler of a job within the timeout, a panicking handler counts as a failure
+func callJob(ctx context.Context, job *models.Job, timeout time.Duration) (result any, err error) {
+	handler, ok := jobHandler(job.Type)
+	if !ok {
+		return nil, Permanent(fmt.Errorf("unknown job type %q", job.Type))
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return handler.Run(withJobProgress(ctx, job), job)
+}
+
+// DiscardJob 调用任务类型的清理函数，用于最终失败和被取消的任务
+// Call the cleanup function of the job type, used for jobs that finally failed or were canceled
+func DiscardJob(ctx context.Context, job *models.Job) {
+	handler, ok := jobHandler(job.Type)
+	if !ok || handler.Discard == nil {
+		return
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			logrus.Warnf("discarding job %d panicked: %v", job.ID, r)
+		}
+	}()
+	handler.Discard(ctx, job)
+}
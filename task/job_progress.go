@@ -0,0 +1,86 @@
+package task
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/sirupsen/logrus"
+)
+
+// jobProgressInterval 同一阶段内两次写入进度的最小间隔
+// Minimum interval between two progress writes within a stage
+const jobProgressInterval = 500 * time.Millisecond
+
+type jobProgressKey struct{}
+
+// jobProgress 执行中任务的进度，按间隔写入任务记录
+// Progress of a running job, written to the job record at an interval
+type jobProgress struct {
+	mu      sync.Mutex
+	job     *models.Job
+	written time.Time
+}
+
+// withJobProgress 让执行函数可以通过 ctx 报告进度，新的一次执行从空阶段开始
+// Let the handler report progress through ctx, a new attempt starts with an empty stage
+func withJobProgress(ctx context.Context, job *models.Job) context.Context {
+	if job.Stage != "" {
+		job.Stage, job.Progress, job.ProgressTotal = "", 0, 0
+		if err := store.Job.SetProgress(job); err != nil {
+			logrus.Warnf("failed to reset progress of job %d: %v", job.ID, err)
+		}
+	}
+	return context.WithValue(ctx, jobProgressKey{}, &jobProgress{job: job})
+}
+
+// ReportProgress 记录执行中任务的阶段和进度，阶段变化或完成时立即写入，其余按间隔写入；total 未知时为 0，ctx 不属于任务时什么也不做
+// Record the stage and progress of the running job, written right away when the stage changes or completes and at an interval otherwise; total is 0 when unknown, nothing happens when ctx belongs to no job
+func ReportProgress(ctx context.Context, stage string, progress, total int64) {
+	p, ok := ctx.Value(jobProgressKey{}).(*jobProgress)
+	if !ok {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	changed := stage != p.job.Stage || progress == total
+	p.job.Stage, p.job.Progress, p.job.ProgressTotal = stage, progress, total
+	if !changed && now.Sub(p.written) < jobProgressInterval {
+		return
+	}
+	p.written = now
+	if err := store.Job.SetProgress(p.job); err != nil {
+		logrus.Warnf("failed to save progress of job %d: %v", p.job.ID, err)
+	}
+}
+
+// ProgressReader 包装 reader，按读取的字节数报告 stage 的进度
+// Wrap reader to report the progress of stage by the bytes read
+func ProgressReader(ctx context.Context, stage string, reader io.Reader, total int64) io.Reader {
+	if _, ok := ctx.Value(jobProgressKey{}).(*jobProgress); !ok {
+		return reader
+	}
+	ReportProgress(ctx, stage, 0, total)
+	return &progressReader{ctx: ctx, stage: stage, reader: reader, total: total}
+}
+
+type progressReader struct {
+	ctx    context.Context
+	stage  string
+	reader io.Reader
+	read   int64
+	total  int64
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.read += int64(n)
+		ReportProgress(r.ctx, r.stage, r.read, r.total)
+	}
+	return n, err
+}
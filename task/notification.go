@@ -0,0 +1,176 @@
+package task
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/mailer"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// telegramAPI Telegram 机器人接口地址
+// Telegram bot API address
+const telegramAPI = "https://api.telegram.org"
+
+var notificationWake = make(chan struct{}, 1)
+
+// notificationDriver 通过一种渠道发送一次通知，返回响应状态码和响应体，邮件没有响应
+// Send a notification once through one kind of channel, returning the response status and body, emails have no response
+type notificationDriver func(ctx context.Context, channel *models.NotificationChannel, subject, text string) (int, string, error)
+
+// notificationDrivers 按渠道类型索引的发送方式
+// Senders indexed by channel kind
+var notificationDrivers = map[string]notificationDriver{
+	constants.NotificationChannelEmail: func(ctx context.Context, channel *models.NotificationChannel, subject, text string) (int, string, error) {
+		language := store.User.OwnerLanguage(channel.OwnerType, channel.OwnerID)
+		return 0, "", mailer.SendNotification(channel.Target, language, channel.Name, subject, text)
+	},
+	constants.NotificationChannelSlack: func(ctx context.Context, channel *models.NotificationChannel, subject, text string) (int, string, error) {
+		return postNotification(ctx, channel.Target, map[string]any{"text": "*" + subject + "*\n" + text})
+	},
+	constants.NotificationChannelTelegram: func(ctx context.Context, channel *models.NotificationChannel, subject, text string) (int, string, error) {
+		token := url.PathEscape(channel.Secret)
+		status, body, err := postNotification(ctx, telegramAPI+"/bot"+token+"/sendMessage", map[string]any{"chat_id": channel.Target, "text": subject + "\n\n" + text})
+		// 请求错误包含地址，记录前去掉其中的机器人令牌 Request errors include the URL, the bot token is removed before they are recorded
+		if err != nil && token != "" {
+			err = errors.New(strings.ReplaceAll(err.Error(), token, "<token>"))
+		}
+		return status, body, err
+	},
+	constants.NotificationChannelFeishu: func(ctx context.Context, channel *models.NotificationChannel, subject, text string) (int, string, error) {
+		message := map[string]any{"msg_type": "text", "content": map[string]any{"text": subject + "\n" + text}}
+		if channel.Secret != "" {
+			timestamp := time.Now().Unix()
+			message["timestamp"] = strconv.FormatInt(timestamp, 10)
+			message["sign"] = utils.Webhook.FeishuSign(channel.Secret, timestamp)
+		}
+		status, body, err := postNotification(ctx, channel.Target, message)
+		if err != nil {
+			return status, body, err
+		}
+		// 飞书以 200 和非零 code 表示签名错误等失败 Feishu answers failures such as a bad signature with 200 and a non-zero code
+		result := struct {
+			Code int    `json:"code"`
+			Msg  string `json:"msg"`
+		}{}
+		if json.Unmarshal([]byte(body), &result) == nil && result.Code != 0 {
+			return status, body, fmt.Errorf("feishu error %d: %s", result.Code, result.Msg)
+		}
+		return status, body, nil
+	},
+}
+
+// WakeNotifications 通知投递任务有新的待投递通知
+// Tell the delivery task that new notifications are waiting
+func WakeNotifications() {
+	select {
+	case notificationWake <- struct{}{}:
+	default:
+	}
+}
+
+// DeliverNotifications 投递到期的通知并与 webhook 相同地按指数退避重试，直到 ctx 结束
+// Send due notifications and retry failed ones with the same exponential backoff as webhooks until ctx is done
+func DeliverNotifications(ctx context.Context) {
+	ticker := time.NewTicker(webhookPollInterval)
+	defer ticker.Stop()
+	for {
+		if err := deliverNotifications(ctx, time.Now()); err != nil {
+			logrus.Warnf("failed to deliver notifications: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-notificationWake:
+		}
+	}
+}
+
+func deliverNotifications(ctx context.Context, now time.Time) error {
+	deliveries, err := store.Notification.ListDue(now, webhookBatch)
+	if err != nil {
+		return err
+	}
+	timeout := time.Duration(config.WebhookTimeout) * time.Second
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		// 领取期间其他实例不会重复投递 Other instances skip the delivery while it is claimed
+		claimed, err := store.Notification.Claim(delivery, now.Add(2*timeout))
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			continue
+		}
+		deliverNotification(ctx, delivery, timeout)
+		if err := store.Notification.SaveAttempt(delivery); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deliverNotification 发送一次通知并记录结果
+// Send a notification once and record the outcome
+func deliverNotification(ctx context.Context, delivery *models.NotificationDelivery, timeout time.Duration) {
+	delivery.ResponseStatus, delivery.ResponseBody, delivery.Error = 0, "", ""
+	err := fmt.Errorf("unknown channel kind %q", delivery.Channel.Kind)
+	if driver, ok := notificationDrivers[delivery.Channel.Kind]; ok {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		delivery.ResponseStatus, delivery.ResponseBody, err = driver(ctx, &delivery.Channel, delivery.Subject, delivery.Text)
+		cancel()
+	}
+	now := time.Now()
+	if err == nil {
+		delivery.Status = constants.WebhookDeliverySucceeded
+		delivery.DeliveredAt = &now
+		return
+	}
+	delivery.Error = err.Error()
+	if delivery.Attempts >= config.WebhookMaxAttempts {
+		delivery.Status = constants.WebhookDeliveryFailed
+		logrus.Warnf("notification delivery %d through %s channel %d failed after %d attempts: %s", delivery.ID, delivery.Channel.Kind, delivery.ChannelID, delivery.Attempts, delivery.Error)
+		return
+	}
+	delivery.NextAttemptAt = retryAt(now, delivery.Attempts)
+}
+
+// postNotification 以 JSON 请求体调用聊天机器人接口，非 2xx 响应视为失败
+// Call a chat bot API with a JSON body, responses other than 2xx count as failures
+func postNotification(ctx context.Context, endpoint string, message any) (int, string, error) {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return 0, "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Spage-Notification")
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, webhookResponseLimit))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, string(respBody), fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, string(respBody), nil
+}
@@ -0,0 +1,59 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/sirupsen/logrus"
+)
+
+// previewCleanupBatch 每轮清理的预览部署数量上限
+// Max number of preview deployments cleaned up per round
+const previewCleanupBatch = 100
+
+// CleanupPreviews 周期性删除过期的预览部署及其版本，直到 ctx 结束
+// Periodically delete expired preview deployments and their versions until ctx is done
+func CleanupPreviews(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(config.PreviewCleanupInterval) * time.Second)
+	defer ticker.Stop()
+	for {
+		if removed, err := cleanupPreviews(ctx, time.Now()); err != nil {
+			logrus.Warnf("failed to clean up previews: %v", err)
+		} else if removed > 0 {
+			logrus.Infof("Cleaned up %d expired preview(s)", removed)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func cleanupPreviews(ctx context.Context, now time.Time) (removed int, err error) {
+	// 只读期间过期的预览继续保留 Expired previews stay while read-only
+	if config.ReadOnly() {
+		return 0, nil
+	}
+	previews, err := store.Preview.ListExpired(now, previewCleanupBatch)
+	if err != nil {
+		return 0, err
+	}
+	for _, preview := range previews {
+		if err := store.Preview.Delete(&preview); err != nil {
+			return removed, err
+		}
+		removed++
+		// 已被激活为站点当前版本的预览版本保留 Preview versions promoted to the active version are kept
+		if preview.Release.ID == 0 {
+			continue
+		}
+		if err := store.Site.DeleteVersion(ctx, &preview.Release); err != nil && !errors.Is(err, store.ErrReleaseActive) {
+			logrus.Warnf("failed to delete preview release %d: %v", preview.ReleaseID, err)
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,66 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/sirupsen/logrus"
+)
+
+// retentionBatch 每批读取的站点数量
+// Sites read per batch
+const retentionBatch = 200
+
+// pruneReleases 按项目或实例的保留策略删除各站点的旧版本，不再被引用的文件和存储对象一并删除；激活和固定的版本总是保留
+// Remove the old versions of every site by the retention policy of its project or the instance, along with files and stored objects nothing references anymore; the active and pinned versions are always kept
+func pruneReleases(ctx context.Context) (any, error) {
+	now := time.Now()
+	sites, removed := 0, 0
+	var lastID uint
+	for {
+		batch, err := store.Site.ListRetention(lastID, retentionBatch)
+		if err != nil {
+			return nil, err
+		}
+		for _, site := range batch {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			keepLast, keepDays := store.EffectiveRetention(site.KeepReleases, site.KeepReleaseDays)
+			if keepLast <= 0 && keepDays <= 0 {
+				continue
+			}
+			var before time.Time
+			if keepDays > 0 {
+				before = now.AddDate(0, 0, -keepDays)
+			}
+			expired, err := store.Site.ExpiredVersions(ctx, site.SiteID, max(keepLast, 0), before)
+			if err != nil {
+				return nil, err
+			}
+			pruned := 0
+			for i := range expired {
+				// 查询后被激活的版本保留 Versions activated after the query are kept
+				if err := store.Site.DeleteVersion(ctx, &expired[i]); err != nil {
+					if !errors.Is(err, store.ErrReleaseActive) {
+						logrus.Warnf("failed to remove release %d of site %d: %v", expired[i].ID, site.SiteID, err)
+					}
+					continue
+				}
+				pruned++
+			}
+			if pruned > 0 {
+				sites++
+				removed += pruned
+				logrus.Infof("Removed %d old release(s) of site %d by the retention policy", pruned, site.SiteID)
+			}
+		}
+		if len(batch) < retentionBatch {
+			break
+		}
+		lastID = batch[len(batch)-1].SiteID
+	}
+	return map[string]any{"sites": sites, "removed": removed}, nil
+}
@@ -0,0 +1,33 @@
+package task
+
+import (
+	"context"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/sirupsen/logrus"
+)
+
+// CleanupSessions 按预览清理间隔周期性彻底删除已过期或已撤销的登录会话和过期的登录失败记录，直到 ctx 结束
+// Periodically delete expired or revoked sign-in sessions and stale login failures at the preview cleanup interval until ctx is done
+func CleanupSessions(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(config.PreviewCleanupInterval) * time.Second)
+	defer ticker.Stop()
+	for {
+		if removed, err := store.JWT.DeleteExpired(time.Now()); err != nil {
+			logrus.Warnf("failed to clean up sessions: %v", err)
+		} else if removed > 0 {
+			logrus.Infof("Cleaned up %d expired session(s)", removed)
+		}
+		now := time.Now()
+		if _, err := store.LoginFailure.DeleteBefore(now.Add(-time.Duration(config.LoginFailureWindow)*time.Second), now); err != nil {
+			logrus.Warnf("failed to clean up login failures: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
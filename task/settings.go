@@ -0,0 +1,43 @@
+package task
+
+import (
+	"context"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/sirupsen/logrus"
+)
+
+// LoadSettings 以数据库中保存的值覆盖动态配置项
+// Override the dynamic settings with the values stored in the database
+func LoadSettings() error {
+	overrides, err := store.Setting.Overrides()
+	if err != nil {
+		return err
+	}
+	for _, key := range config.ApplySettings(overrides) {
+		logrus.Infof("Setting %s reloaded", key)
+	}
+	return nil
+}
+
+// ReloadSettings 按 settings.reload-interval 从数据库重新加载动态配置项，使其他实例的修改生效，直到 ctx 结束；间隔为 0 时不运行
+// Reload the dynamic settings from the database every settings.reload-interval so changes of other instances take effect, until ctx is done; not run when the interval is 0
+func ReloadSettings(ctx context.Context) {
+	if config.SettingsReloadInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(time.Duration(config.SettingsReloadInterval) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := LoadSettings(); err != nil {
+			logrus.Warnf("failed to reload settings: %v", err)
+		}
+	}
+}
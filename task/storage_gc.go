@@ -0,0 +1,321 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/storage"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	// gcBatch 每次查询的对象或内容数量
+	// Number of objects or contents looked up per query
+	gcBatch = 500
+	// gcCheckInterval 检查是否需要安排垃圾回收的间隔
+	// Interval between checks whether a garbage collection is due
+	gcCheckInterval = time.Hour
+)
+
+// GCJob 存储垃圾回收任务的参数
+// Parameters of a storage garbage collection job
+type GCJob struct {
+	DryRun bool `json:"dry_run"` // 只统计可回收的对象，不删除 Only count the collectable objects without deleting them
+}
+
+// GCResult 存储垃圾回收的结果
+// Outcome of a storage garbage collection
+type GCResult struct {
+	DryRun  bool  `json:"dry_run"` // 是否只统计 Whether it only counted
+	Blobs   int   `json:"blobs"`   // 删除的文件内容记录数 Content records removed
+	Objects int   `json:"objects"` // 删除的存储对象数 Storage objects removed
+	Bytes   int64 `json:"bytes"`   // 回收的字节数 Bytes reclaimed
+}
+
+// CollectGarbage 按 gc.interval 安排存储垃圾回收任务，多个实例共用最近一次任务的时间，直到 ctx 结束；间隔为 0 时不运行，设置了 cron.gc 时由定时任务代替
+// Schedule storage garbage collection jobs every gc.interval, instances share the time of the latest job, until ctx is done; not run when the interval is 0 and left to the scheduled task while cron.gc is set
+func CollectGarbage(ctx context.Context) {
+	if config.GCInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(min(time.Duration(config.GCInterval)*time.Second, gcCheckInterval))
+	defer ticker.Stop()
+	for {
+		if config.CronGC == "" {
+			// 检查最近一次任务和创建任务之间不能有其他实例插入 No other instance may slip in between checking the latest job and creating one
+			err := store.Lock.With(ctx, store.LockGCSchedule, func(*gorm.DB) error {
+				return scheduleGC(ctx, time.Now())
+			})
+			if err != nil {
+				logrus.Warnf("failed to schedule storage garbage collection: %v", err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func scheduleGC(ctx context.Context, now time.Time) error {
+	latest, err := store.Job.Latest(constants.JobTypeStorageGC)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if latest != nil && now.Sub(latest.CreatedAt) < time.Duration(config.GCInterval)*time.Second {
+		return nil
+	}
+	_, err = EnqueueJob(ctx, constants.JobTypeStorageGC, GCJob{}, 0, 0)
+	return err
+}
+
+// garbageCollector 一次垃圾回收的状态
+// State of one garbage collection
+type garbageCollector struct {
+	dryRun  bool
+	before  time.Time
+	removed map[string]bool // 已删除或将删除的内容哈希 Content hashes removed or to be removed
+	result  GCResult
+}
+
+// collectGarbage 删除没有任何部署清单引用的文件内容，以及存储中不属于任何记录的对象，只处理超过 gc.grace 的对象
+// Delete file contents no deployment manifest references and stored objects that belong to no record, only touching objects older than gc.grace
+func collectGarbage(ctx context.Context, job *models.Job) (any, error) {
+	payload := GCJob{}
+	if err := json.Unmarshal([]byte(job.Payload), &payload); err != nil {
+		return nil, Permanent(err)
+	}
+	// 同时运行的两次回收会互相删除对方刚记录的引用 Two collections running at once would delete references the other just recorded
+	var result GCResult
+	err := store.Lock.With(ctx, store.LockStorageGC, func(*gorm.DB) error {
+		var err error
+		result, err = runGC(ctx, payload)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// runGC 执行一次垃圾回收，调用方持有 LockStorageGC
+// Run one garbage collection, the caller holds LockStorageGC
+func runGC(ctx context.Context, payload GCJob) (GCResult, error) {
+	// 引用记录不完整时会误删仍在使用的内容 Incomplete references would remove contents still in use
+	if _, failed, err := linkManifests(ctx); err != nil {
+		return GCResult{}, err
+	} else if failed > 0 {
+		return GCResult{}, fmt.Errorf("the contents of %d manifest(s) could not be recorded", failed)
+	}
+	gc := &garbageCollector{
+		dryRun:  payload.DryRun,
+		before:  time.Now().Add(-time.Duration(config.GCGrace) * time.Second),
+		removed: make(map[string]bool),
+	}
+	gc.result.DryRun = payload.DryRun
+	if err := gc.blobs(ctx); err != nil {
+		return GCResult{}, err
+	}
+	sweeps := []struct {
+		prefix string
+		ref    func(key string) string
+		known  func(ctx context.Context, refs []string) (map[string]bool, error)
+	}{
+		{storage.BlobPrefix, blobRef, gc.knownBlobs(true)},
+		{storage.CompressedPrefix + "sha256/", compressedBlobRef, gc.knownBlobs(false)},
+		{storage.CompressedPrefix + "files/", compressedFileRef, knownFiles},
+		{storage.ReleasePrefix, func(key string) string { return key }, store.File.KnownPaths},
+		{storage.UploadPrefix, uploadRef, knownUploads},
+	}
+	for _, sweep := range sweeps {
+		if err := gc.sweep(ctx, sweep.prefix, sweep.ref, sweep.known); err != nil {
+			return GCResult{}, err
+		}
+	}
+	if gc.result.Objects > 0 && !gc.dryRun {
+		logrus.Infof("Storage garbage collection removed %d object(s), reclaiming %d bytes", gc.result.Objects, gc.result.Bytes)
+	}
+	return gc.result, nil
+}
+
+// blobs 删除没有任何部署清单引用的文件内容记录及其对象
+// Delete content records no deployment manifest references along with their objects
+func (gc *garbageCollector) blobs(ctx context.Context) error {
+	afterHash := ""
+	for ctx.Err() == nil {
+		blobs, err := store.Blob.ListOrphaned(gc.before, afterHash, gcBatch)
+		if err != nil {
+			return err
+		}
+		if len(blobs) == 0 {
+			return nil
+		}
+		for _, blob := range blobs {
+			afterHash = blob.Hash
+			if !gc.dryRun {
+				deleted, err := store.Blob.DeleteOrphaned(ctx, blob.Hash)
+				if err != nil {
+					return err
+				}
+				// 期间被新的清单引用 A new manifest referenced it meanwhile
+				if !deleted {
+					continue
+				}
+				if err := storage.Default.Delete(ctx, storage.BlobKey(blob.Hash)); err != nil {
+					return err
+				}
+			}
+			gc.removed[blob.Hash] = true
+			gc.result.Blobs++
+			gc.result.Objects++
+			gc.result.Bytes += blob.Size
+		}
+	}
+	return ctx.Err()
+}
+
+// sweep 删除前缀下不属于任何记录的对象，ref 从对象键得到其所属记录的标识，返回空时保留对象，known 查询仍存在的记录
+// Delete the objects under the prefix that belong to no record, ref maps a key to the record it belongs to with empty keeping the object, known looks up the records that still exist
+func (gc *garbageCollector) sweep(ctx context.Context, prefix string, ref func(key string) string, known func(ctx context.Context, refs []string) (map[string]bool, error)) error {
+	objects, err := storage.Default.List(ctx, prefix)
+	if err != nil {
+		return err
+	}
+	var candidates []storage.ObjectInfo
+	for _, object := range objects {
+		if object.ModTime.Before(gc.before) && ref(object.Key) != "" {
+			candidates = append(candidates, object)
+		}
+	}
+	for start := 0; start < len(candidates); start += gcBatch {
+		batch := candidates[start:min(start+gcBatch, len(candidates))]
+		refs := make([]string, 0, len(batch))
+		for _, object := range batch {
+			refs = append(refs, ref(object.Key))
+		}
+		existing, err := known(ctx, refs)
+		if err != nil {
+			return err
+		}
+		for _, object := range batch {
+			if existing[ref(object.Key)] {
+				continue
+			}
+			if !gc.dryRun {
+				if err := storage.Default.Delete(ctx, object.Key); err != nil {
+					return err
+				}
+			}
+			gc.result.Objects++
+			gc.result.Bytes += object.Size
+		}
+	}
+	return nil
+}
+
+// knownBlobs 查询仍有记录的内容哈希；本次已回收的内容在 blobs/ 下已计入结果，其压缩版本则随之回收
+// Look up the content hashes that still have records; contents collected in this run are already counted under blobs/ while their compressed versions go with them
+func (gc *garbageCollector) knownBlobs(keepRemoved bool) func(ctx context.Context, hashes []string) (map[string]bool, error) {
+	return func(ctx context.Context, hashes []string) (map[string]bool, error) {
+		sizes, err := store.Blob.Sizes(ctx, hashes)
+		if err != nil {
+			return nil, err
+		}
+		known := make(map[string]bool, len(sizes))
+		for _, hash := range hashes {
+			_, ok := sizes[hash]
+			known[hash] = (ok && !gc.removed[hash]) || (keepRemoved && gc.removed[hash])
+		}
+		return known, nil
+	}
+}
+
+func knownFiles(ctx context.Context, refs []string) (map[string]bool, error) {
+	ids := make([]uint, 0, len(refs))
+	for _, ref := range refs {
+		id, _ := strconv.ParseUint(ref, 10, 64)
+		ids = append(ids, uint(id))
+	}
+	existing, err := store.File.ExistingIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(existing))
+	for id := range existing {
+		known[strconv.FormatUint(uint64(id), 10)] = true
+	}
+	return known, nil
+}
+
+// knownUploads 查询仍存在的上传会话，暂存的压缩包在被未结束的发布任务引用时保留
+// Look up the upload sessions that still exist, staged archives are kept while an unfinished deploy job references them
+func knownUploads(ctx context.Context, refs []string) (map[string]bool, error) {
+	known, err := store.Upload.ExistingIDs(ctx, refs)
+	if err != nil {
+		return nil, err
+	}
+	jobs, err := store.Job.ListUnfinished(constants.JobTypeDeploy)
+	if err != nil {
+		return nil, err
+	}
+	for _, ref := range refs {
+		for _, job := range jobs {
+			if strings.Contains(job.Payload, ref) {
+				known[ref] = true
+			}
+		}
+	}
+	return known, nil
+}
+
+// blobRef blobs/<xx>/<hash> 对应的内容哈希
+// Content hash of blobs/<xx>/<hash>
+func blobRef(key string) string {
+	if hash := path.Base(key); utils.ValidSHA256(hash) {
+		return hash
+	}
+	return ""
+}
+
+// compressedBlobRef compressed/sha256/<xx>/<hash>.<ext> 对应的内容哈希
+// Content hash of compressed/sha256/<xx>/<hash>.<ext>
+func compressedBlobRef(key string) string {
+	hash, _, _ := strings.Cut(path.Base(key), ".")
+	if utils.ValidSHA256(hash) {
+		return hash
+	}
+	return ""
+}
+
+// compressedFileRef compressed/files/<id>/... 对应的文件ID
+// File ID of compressed/files/<id>/...
+func compressedFileRef(key string) string {
+	id, _, _ := strings.Cut(strings.TrimPrefix(key, storage.CompressedPrefix+"files/"), "/")
+	if _, err := strconv.ParseUint(id, 10, 64); err != nil {
+		return ""
+	}
+	return id
+}
+
+// uploadRef uploads/<id>.<index> 对应的上传会话ID，暂存的压缩包使用完整的键
+// Upload session ID of uploads/<id>.<index>, staged archives use the full key
+func uploadRef(key string) string {
+	if strings.HasPrefix(key, storage.StagedPrefix) {
+		return key
+	}
+	id, _, _ := strings.Cut(strings.TrimPrefix(key, storage.UploadPrefix), ".")
+	return id
+}
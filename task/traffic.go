@@ -0,0 +1,72 @@
+package task
+
+import (
+	"context"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/sirupsen/logrus"
+)
+
+// trafficFlushInterval 将内存中的流量累计写入数据库的间隔
+// Interval between writes of the traffic accumulated in memory to the database
+const trafficFlushInterval = time.Minute
+
+// FlushTraffic 定期写入站点流量统计、访问分析和请求日志，ctx 结束时写入剩余的累计值；过期记录由 analytics 定时任务删除
+// Periodically write the site traffic statistics, analytics and request logs, writing what is left when ctx is done; expired records are deleted by the analytics scheduled task
+func FlushTraffic(ctx context.Context) {
+	ticker := time.NewTicker(trafficFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			flushTraffic(context.Background())
+			return
+		case <-ticker.C:
+		}
+		flushTraffic(ctx)
+	}
+}
+
+// rollupTraffic 写入本实例累计的流量、访问分析和请求日志，并删除超过 traffic.retention、analytics.retention 和 log.site.retention 的记录
+// Write the traffic, analytics and request logs accumulated by this instance and delete records older than traffic.retention, analytics.retention and log.site.retention
+func rollupTraffic(ctx context.Context) (any, error) {
+	flushTraffic(ctx)
+	now := time.Now()
+	result := map[string]int64{}
+	if config.TrafficRetention > 0 {
+		removed, err := store.Traffic.DeleteBefore(now.Add(-time.Duration(config.TrafficRetention) * time.Second))
+		if err != nil {
+			return nil, err
+		}
+		result["traffic_deleted"] = removed
+	}
+	if config.AnalyticsRetention > 0 {
+		removed, err := store.Analytics.DeleteBefore(now.Add(-time.Duration(config.AnalyticsRetention) * time.Second))
+		if err != nil {
+			return nil, err
+		}
+		result["analytics_deleted"] = removed
+	}
+	if config.SiteLogRetention > 0 {
+		removed, err := store.AccessLog.DeleteBefore(now.Add(-time.Duration(config.SiteLogRetention) * time.Second))
+		if err != nil {
+			return nil, err
+		}
+		result["site_logs_deleted"] = removed
+	}
+	return result, nil
+}
+
+func flushTraffic(ctx context.Context) {
+	if err := store.Traffic.Flush(ctx); err != nil {
+		logrus.Warnf("failed to write site traffic: %v", err)
+	}
+	if err := store.Analytics.Flush(ctx); err != nil {
+		logrus.Warnf("failed to write site analytics: %v", err)
+	}
+	if err := store.AccessLog.Flush(ctx); err != nil {
+		logrus.Warnf("failed to write site request logs: %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package task
+
+import (
+	"context"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/sirupsen/logrus"
+)
+
+// trashCleanupBatch 每轮彻底删除的项目或站点数量上限
+// Max number of projects or sites purged per round
+const trashCleanupBatch = 20
+
+// PurgeTrash 按预览清理间隔周期性彻底删除超出保留时间的项目和站点及其文件，直到 ctx 结束；保留时间为 0 时不运行
+// Periodically purge projects and sites past the trash retention along with their files at the preview cleanup interval until ctx is done; not run when the retention is 0
+func PurgeTrash(ctx context.Context) {
+	if config.TrashRetention <= 0 {
+		return
+	}
+	ticker := time.NewTicker(time.Duration(config.PreviewCleanupInterval) * time.Second)
+	defer ticker.Stop()
+	for {
+		before := time.Now().Add(-time.Duration(config.TrashRetention) * time.Second)
+		if removed, err := purgeTrash(ctx, before); err != nil {
+			logrus.Warnf("failed to purge trash: %v", err)
+		} else if removed > 0 {
+			logrus.Infof("Purged %d expired project(s) and site(s) from the trash", removed)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func purgeTrash(ctx context.Context, before time.Time) (removed int, err error) {
+	// 只读期间不删除文件 No files are deleted while read-only
+	if config.ReadOnly() {
+		return 0, nil
+	}
+	// 先删除项目，随项目删除的站点一并清除 Projects go first, purging the sites deleted along with them
+	projects, err := store.Trash.ListExpiredProjects(before, trashCleanupBatch)
+	if err != nil {
+		return 0, err
+	}
+	for _, project := range projects {
+		if err := store.Trash.PurgeProject(ctx, &project); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	sites, err := store.Trash.ListExpiredSites(before, trashCleanupBatch)
+	if err != nil {
+		return removed, err
+	}
+	for _, site := range sites {
+		if err := store.Trash.PurgeSite(ctx, &site); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
@@ -0,0 +1,51 @@
+package task
+
+import (
+	"context"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/sirupsen/logrus"
+)
+
+// uploadCleanupBatch 每轮清理的上传会话数量上限
+// Max number of upload sessions cleaned up per round
+const uploadCleanupBatch = 100
+
+// CleanupUploads 按预览清理间隔周期性删除过期的分片上传会话及其分片，直到 ctx 结束
+// Periodically delete expired chunked upload sessions and their chunks at the preview cleanup interval until ctx is done
+func CleanupUploads(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(config.PreviewCleanupInterval) * time.Second)
+	defer ticker.Stop()
+	for {
+		if removed, err := cleanupUploads(ctx, time.Now()); err != nil {
+			logrus.Warnf("failed to clean up uploads: %v", err)
+		} else if removed > 0 {
+			logrus.Infof("Cleaned up %d expired upload(s)", removed)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func cleanupUploads(ctx context.Context, now time.Time) (removed int, err error) {
+	// 只读期间保留分片，存储迁移完成后再清理 Chunks are kept while read-only and cleaned up after the storage migration
+	if config.ReadOnly() {
+		return 0, nil
+	}
+	uploads, err := store.Upload.ListExpired(now, uploadCleanupBatch)
+	if err != nil {
+		return 0, err
+	}
+	for _, upload := range uploads {
+		if err := store.Upload.Delete(ctx, &upload); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
@@ -0,0 +1,193 @@
+package task
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/store"
+	"github.com/LiteyukiStudio/spage/utils"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// webhookBatch 每轮投递的记录数量上限
+	// Max number of deliveries sent per round
+	webhookBatch = 50
+	// webhookPollInterval 检查到期重试的间隔
+	// Interval between checks for due retries
+	webhookPollInterval = 10 * time.Second
+	// webhookRetryBase 第一次重试的等待时间，之后每次乘以 4
+	// Wait before the first retry, multiplied by 4 for each further retry
+	webhookRetryBase = 30 * time.Second
+	// webhookResponseLimit 投递记录中保存的响应体长度
+	// Length of the response body kept in the delivery log
+	webhookResponseLimit = 4096
+)
+
+// ErrWebhookTarget webhook 地址解析到了内网地址
+// The webhook URL resolved to an internal address
+var ErrWebhookTarget = errors.New("webhook target address is not allowed")
+
+var webhookWake = make(chan struct{}, 1)
+
+var webhookClient = &http.Client{
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: 10 * time.Second,
+			Control: func(network, address string, _ syscall.RawConn) error {
+				if config.WebhookAllowPrivate {
+					return nil
+				}
+				host, _, err := net.SplitHostPort(address)
+				if err != nil {
+					return err
+				}
+				ip := net.ParseIP(host)
+				if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+					return ErrWebhookTarget
+				}
+				return nil
+			},
+		}).DialContext,
+		MaxIdleConnsPerHost: 4,
+		IdleConnTimeout:     time.Minute,
+	},
+	// 重定向视为失败，避免绕过地址检查 Redirects count as failures so they cannot bypass the address check
+	CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// WakeWebhooks 通知投递任务有新的待投递记录
+// Tell the delivery task that new deliveries are waiting
+func WakeWebhooks() {
+	select {
+	case webhookWake <- struct{}{}:
+	default:
+	}
+}
+
+// DeliverWebhooks 投递到期的 webhook 并按指数退避重试失败的投递，直到 ctx 结束
+// Send due webhooks and retry failed deliveries with exponential backoff until ctx is done
+func DeliverWebhooks(ctx context.Context) {
+	ticker := time.NewTicker(webhookPollInterval)
+	defer ticker.Stop()
+	for {
+		if err := deliverWebhooks(ctx, time.Now()); err != nil {
+			logrus.Warnf("failed to deliver webhooks: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-webhookWake:
+		}
+	}
+}
+
+func deliverWebhooks(ctx context.Context, now time.Time) error {
+	deliveries, err := store.Webhook.ListDue(now, webhookBatch)
+	if err != nil {
+		return err
+	}
+	timeout := time.Duration(config.WebhookTimeout) * time.Second
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		// 领取期间其他实例不会重复投递 Other instances skip the delivery while it is claimed
+		claimed, err := store.Webhook.Claim(delivery, now.Add(2*timeout))
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			continue
+		}
+		deliverWebhook(ctx, delivery, timeout)
+		if err := store.Webhook.SaveAttempt(delivery); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deliverWebhook 发送一次投递并记录结果
+// Send a delivery once and record the outcome
+func deliverWebhook(ctx context.Context, delivery *models.WebhookDelivery, timeout time.Duration) {
+	delivery.ResponseStatus, delivery.ResponseBody, delivery.Error = 0, "", ""
+	status, body, err := sendWebhook(ctx, delivery, timeout)
+	now := time.Now()
+	delivery.ResponseStatus = status
+	delivery.ResponseBody = body
+	switch {
+	case err == nil && status >= 200 && status < 300:
+		delivery.Status = constants.WebhookDeliverySucceeded
+		delivery.DeliveredAt = &now
+		return
+	case err != nil:
+		delivery.Error = err.Error()
+	default:
+		delivery.Error = fmt.Sprintf("unexpected status %d", status)
+	}
+	if delivery.Attempts >= config.WebhookMaxAttempts {
+		delivery.Status = constants.WebhookDeliveryFailed
+		logrus.Warnf("webhook delivery %d to %s failed after %d attempts: %s", delivery.ID, delivery.Webhook.URL, delivery.Attempts, delivery.Error)
+		return
+	}
+	delivery.NextAttemptAt = retryAt(now, delivery.Attempts)
+}
+
+// retryAt 第 attempts 次尝试失败后下次重试的时间，webhook 和通知共用
+// Time of the next retry after the given number of failed attempts, shared by webhooks and notifications
+func retryAt(now time.Time, attempts int) time.Time {
+	return now.Add(webhookRetryBase << (2 * (attempts - 1)))
+}
+
+func sendWebhook(ctx context.Context, delivery *models.WebhookDelivery, timeout time.Duration) (int, string, error) {
+	hook := &delivery.Webhook
+	body := []byte(delivery.Payload)
+	// 飞书机器人要求签名和时间戳位于请求体中且时间戳在一小时内，因此在发送时加入
+	// Feishu bots require the signature and a timestamp within the hour in the body, so they are added at send time
+	if hook.Format == constants.WebhookFormatFeishu {
+		var message map[string]any
+		if err := json.Unmarshal(body, &message); err != nil {
+			return 0, "", err
+		}
+		timestamp := time.Now().Unix()
+		message["timestamp"] = strconv.FormatInt(timestamp, 10)
+		message["sign"] = utils.Webhook.FeishuSign(hook.Secret, timestamp)
+		signed, err := json.Marshal(message)
+		if err != nil {
+			return 0, "", err
+		}
+		body = signed
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Spage-Webhook")
+	req.Header.Set(utils.WebhookEventHeader, delivery.Event)
+	req.Header.Set(utils.WebhookDeliveryHeader, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(utils.WebhookSignatureHeader, utils.Webhook.Sign(hook.Secret, body))
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, webhookResponseLimit))
+	return resp.StatusCode, string(respBody), nil
+}
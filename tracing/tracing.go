@@ -0,0 +1,116 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName 本项目创建的 span 所属的库名
+// Instrumentation library name of the spans created by the project
+const instrumentationName = "github.com/LiteyukiStudio/spage"
+
+// provider 开启追踪时的 TracerProvider，未开启时为空，span 由 OpenTelemetry 默认的空实现丢弃
+// TracerProvider while tracing is enabled, nil otherwise and spans are dropped by the OpenTelemetry no-op default
+var provider *sdktrace.TracerProvider
+
+// Propagator 在请求头和任务记录中传递链路上下文的格式，使用 W3C Trace Context
+// Format carrying the trace context in request headers and job records, W3C Trace Context
+var Propagator propagation.TextMapPropagator = propagation.TraceContext{}
+
+func init() {
+	otel.SetTextMapPropagator(Propagator)
+}
+
+// Init 根据配置创建 OTLP/HTTP 导出器并注册为全局 TracerProvider，未开启时不做任何事
+// Create the OTLP/HTTP exporter from configuration and register it as the global TracerProvider, doing nothing when disabled
+func Init() error {
+	if !config.TracingEnable {
+		return nil
+	}
+	exporter, err := otlptracehttp.New(context.Background(), otlptracehttp.WithEndpointURL(config.TracingEndpoint))
+	if err != nil {
+		return fmt.Errorf("create otlp exporter: %w", err)
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName(config.TracingServiceName)))
+	if err != nil {
+		return fmt.Errorf("create tracing resource: %w", err)
+	}
+	provider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.TracingSampleRate))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		logrus.Warnf("tracing: %v", err)
+	}))
+	logrus.Infof("Exporting traces to %s", config.TracingEndpoint)
+	return nil
+}
+
+// Shutdown 导出剩余的 span 并关闭导出器，未开启时不做任何事
+// Export the remaining spans and close the exporter, doing nothing when disabled
+func Shutdown(ctx context.Context) error {
+	if provider == nil {
+		return nil
+	}
+	return provider.Shutdown(ctx)
+}
+
+// Start 创建一个 span，ctx 中已有的 span 作为父级
+// Start a span, the span already in ctx becomes its parent
+func Start(ctx context.Context, name string, kind trace.SpanKind, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithSpanKind(kind), trace.WithAttributes(attrs...))
+}
+
+// Active ctx 中是否有正在记录的 span，没有时子操作不必创建 span
+// Whether ctx carries a recording span, child operations need no span otherwise
+func Active(ctx context.Context) bool {
+	return ctx != nil && trace.SpanFromContext(ctx).IsRecording()
+}
+
+// End 结束 span，err 不为空且不是 ignore 中的错误时标记为失败
+// End the span, marking it failed when err is set and not one of ignore
+func End(span trace.Span, err error, ignore ...error) {
+	if err != nil {
+		expected := false
+		for _, target := range ignore {
+			expected = expected || errors.Is(err, target)
+		}
+		if !expected {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+	span.End()
+}
+
+// Inject 将 ctx 中的链路上下文编码为 traceparent，没有 span 时为空
+// Encode the trace context of ctx as a traceparent, empty when there is no span
+func Inject(ctx context.Context) string {
+	carrier := propagation.MapCarrier{}
+	Propagator.Inject(ctx, carrier)
+	return carrier.Get("traceparent")
+}
+
+// Extract 将 Inject 编码的 traceparent 作为远程父级写入 ctx，为空或无效时原样返回 ctx
+// Put a traceparent encoded by Inject into ctx as the remote parent, returning ctx unchanged when it is empty or invalid
+func Extract(ctx context.Context, traceParent string) context.Context {
+	if traceParent == "" {
+		return ctx
+	}
+	return Propagator.Extract(ctx, propagation.MapCarrier{"traceparent": traceParent})
+}
@@ -0,0 +1,35 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TestInjectExtract 任务记录中的 traceparent 还原为同一链路的远程父级，空值和无效值不改变上下文
+// A traceparent kept in a job record is restored as a remote parent of the same trace, empty and invalid values leave the context unchanged
+func TestInjectExtract(t *testing.T) {
+	if got := Inject(context.Background()); got != "" {
+		t.Errorf("inject without span %q, want empty", got)
+	}
+	provider := sdktrace.NewTracerProvider()
+	defer func() { _ = provider.Shutdown(context.Background()) }()
+	ctx, span := provider.Tracer("test").Start(context.Background(), "deploy")
+	defer span.End()
+
+	parent := Inject(ctx)
+	if parent == "" {
+		t.Fatal("inject with span is empty")
+	}
+	restored := trace.SpanContextFromContext(Extract(context.Background(), parent))
+	if restored.TraceID() != span.SpanContext().TraceID() || restored.SpanID() != span.SpanContext().SpanID() || !restored.IsRemote() {
+		t.Errorf("extracted %v, want remote parent %v", restored, span.SpanContext())
+	}
+	for _, value := range []string{"", "not-a-traceparent"} {
+		if trace.SpanContextFromContext(Extract(context.Background(), value)).IsValid() {
+			t.Errorf("extract %q gave a valid parent", value)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package utils
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// maxSymlinkTarget 符号链接目标的最大字节数 Longest symlink target in bytes
+const maxSymlinkTarget = 4096
+
+// 压缩包未通过安全检查的原因 Reasons an archive fails the safety checks
+const (
+	ArchiveInvalidPath   = "invalid_path"   // 条目名包含控制字符 The entry name contains control characters
+	ArchiveAbsolutePath  = "absolute_path"  // 条目使用绝对路径 The entry uses an absolute path
+	ArchivePathTraversal = "path_traversal" // 条目路径包含 .. 段 The entry path contains .. segments
+	ArchiveSymlinkEscape = "symlink_escape" // 符号链接指向站点根目录之外 A symlink points outside the site root
+	ArchiveTooManyFiles  = "too_many_files" // 文件数量超出限制 Too many files
+	ArchiveFileTooLarge  = "file_too_large" // 单个文件解压后超出限制 A file is too large once uncompressed
+	ArchiveTooLarge      = "too_large"      // 全部文件解压后超出限制 All files together are too large once uncompressed
+)
+
+// ArchiveLimits 压缩包的文件数量和解压大小限制，0 为不限制
+// Limits on the file count and uncompressed sizes of an archive, 0 is unlimited
+type ArchiveLimits struct {
+	MaxFiles     int   // 最多文件数 Most files
+	MaxFileSize  int64 // 单个文件解压后的最大字节数 Largest uncompressed file in bytes
+	MaxTotalSize int64 // 全部文件解压后的最大字节数 Largest uncompressed total in bytes
+}
+
+// ArchiveError 压缩包未通过安全检查，Reason 为上面的原因之一
+// The archive failed the safety checks, Reason is one of the reasons above
+type ArchiveError struct {
+	Reason string `json:"reason"`          // 原因 Reason
+	Entry  string `json:"entry,omitempty"` // 出错的条目 Offending entry
+	Limit  int64  `json:"limit,omitempty"` // 超出的限制 Exceeded limit
+}
+
+// Message 不含条目名的固定提示，可以翻译
+// Fixed message without the entry name, suitable for translation
+func (e *ArchiveError) Message() string {
+	switch e.Reason {
+	case ArchiveInvalidPath:
+		return "archive entry names must not contain control characters"
+	case ArchiveAbsolutePath:
+		return "archive entries must not use absolute paths"
+	case ArchivePathTraversal:
+		return "archive entries must not contain .. segments"
+	case ArchiveSymlinkEscape:
+		return "archive symlinks must not point outside the site root"
+	case ArchiveTooManyFiles:
+		return "the archive has too many files"
+	case ArchiveFileTooLarge:
+		return "an archive file is too large once uncompressed"
+	case ArchiveTooLarge:
+		return "the archive is too large once uncompressed"
+	}
+	return "the archive is not safe to deploy"
+}
+
+func (e *ArchiveError) Error() string {
+	switch {
+	case e.Entry != "" && e.Limit > 0:
+		return fmt.Sprintf("%s: %s (limit %d)", e.Message(), e.Entry, e.Limit)
+	case e.Entry != "":
+		return e.Message() + ": " + e.Entry
+	case e.Limit > 0:
+		return fmt.Sprintf("%s (limit %d)", e.Message(), e.Limit)
+	}
+	return e.Message()
+}
+
+// CheckZip 检查压缩包的条目路径、符号链接、文件数量和声明的解压大小；读取条目时实际字节数不会超过声明的大小，因此声明的大小可以信任
+// Check the entry paths, symlinks, file count and declared uncompressed sizes of an archive; reading an entry never yields more bytes than declared, so declared sizes can be trusted
+func CheckZip(reader *zip.Reader, limits ArchiveLimits) error {
+	var files int
+	var total uint64
+	for _, entry := range reader.File {
+		name := strings.ReplaceAll(entry.Name, "\\", "/")
+		if err := checkArchivePath(entry.Name, name); err != nil {
+			return err
+		}
+		if entry.Mode()&fs.ModeSymlink != 0 {
+			if err := checkSymlink(entry, name); err != nil {
+				return err
+			}
+		}
+		if entry.FileInfo().IsDir() {
+			continue
+		}
+		files++
+		if limits.MaxFiles > 0 && files > limits.MaxFiles {
+			return &ArchiveError{Reason: ArchiveTooManyFiles, Limit: int64(limits.MaxFiles)}
+		}
+		if limits.MaxFileSize > 0 && entry.UncompressedSize64 > uint64(limits.MaxFileSize) {
+			return &ArchiveError{Reason: ArchiveFileTooLarge, Entry: entry.Name, Limit: limits.MaxFileSize}
+		}
+		total += entry.UncompressedSize64
+		if limits.MaxTotalSize > 0 && total > uint64(limits.MaxTotalSize) {
+			return &ArchiveError{Reason: ArchiveTooLarge, Limit: limits.MaxTotalSize}
+		}
+	}
+	return nil
+}
+
+// checkArchivePath 条目路径必须是站点内的相对路径，name 为反斜杠替换为斜杠后的路径
+// The entry path must be relative to the site, name is the path with backslashes replaced by slashes
+func checkArchivePath(raw, name string) error {
+	for _, r := range name {
+		if r < 0x20 || r == 0x7f {
+			return &ArchiveError{Reason: ArchiveInvalidPath, Entry: raw}
+		}
+	}
+	if absolutePath(name) {
+		return &ArchiveError{Reason: ArchiveAbsolutePath, Entry: raw}
+	}
+	for _, segment := range strings.Split(name, "/") {
+		if segment == ".." {
+			return &ArchiveError{Reason: ArchivePathTraversal, Entry: raw}
+		}
+	}
+	return nil
+}
+
+// absolutePath 是否为 / 开头或带 Windows 盘符的路径
+// Whether the path starts with / or a Windows drive letter
+func absolutePath(name string) bool {
+	if strings.HasPrefix(name, "/") {
+		return true
+	}
+	return len(name) >= 2 && name[1] == ':' && (name[0] >= 'a' && name[0] <= 'z' || name[0] >= 'A' && name[0] <= 'Z')
+}
+
+// checkSymlink 符号链接的目标必须留在站点根目录内
+// The target of a symlink must stay inside the site root
+func checkSymlink(entry *zip.File, name string) error {
+	body, err := entry.Open()
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	target, err := io.ReadAll(io.LimitReader(body, maxSymlinkTarget+1))
+	if err != nil {
+		return err
+	}
+	if len(target) > maxSymlinkTarget {
+		return &ArchiveError{Reason: ArchiveSymlinkEscape, Entry: entry.Name}
+	}
+	link := strings.ReplaceAll(string(target), "\\", "/")
+	if link == "" || absolutePath(link) {
+		return &ArchiveError{Reason: ArchiveSymlinkEscape, Entry: entry.Name}
+	}
+	resolved := path.Join(path.Dir(name), link)
+	if resolved == ".." || strings.HasPrefix(resolved, "../") {
+		return &ArchiveError{Reason: ArchiveSymlinkEscape, Entry: entry.Name}
+	}
+	return nil
+}
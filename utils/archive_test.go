@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+type testEntry struct {
+	name    string
+	body    string
+	symlink bool
+}
+
+func buildZip(t *testing.T, entries ...testEntry) *zip.Reader {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	writer := zip.NewWriter(buf)
+	for _, entry := range entries {
+		header := &zip.FileHeader{Name: entry.name, Method: zip.Deflate}
+		if entry.symlink {
+			header.SetMode(fs.ModeSymlink | 0o777)
+		}
+		w, err := writer.CreateHeader(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(entry.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return reader
+}
+
+// TestCheckZipPaths 绝对路径、.. 段、控制字符和逃出根目录的符号链接被拒绝，根目录内的符号链接允许
+// Absolute paths, .. segments, control characters and symlinks escaping the root are rejected while symlinks inside the root are allowed
+func TestCheckZipPaths(t *testing.T) {
+	tests := []struct {
+		entry  testEntry
+		reason string
+	}{
+		{testEntry{name: "index.html"}, ""},
+		{testEntry{name: "docs/a..b.html"}, ""},
+		{testEntry{name: "docs/latest", body: "../docs/v2", symlink: true}, ""},
+		{testEntry{name: "../evil.html"}, ArchivePathTraversal},
+		{testEntry{name: "docs/../../evil.html"}, ArchivePathTraversal},
+		{testEntry{name: "docs\\..\\..\\evil.html"}, ArchivePathTraversal},
+		{testEntry{name: "/etc/passwd"}, ArchiveAbsolutePath},
+		{testEntry{name: "C:\\Windows\\evil.dll"}, ArchiveAbsolutePath},
+		{testEntry{name: "bad\x00name"}, ArchiveInvalidPath},
+		{testEntry{name: "link", body: "/etc/passwd", symlink: true}, ArchiveSymlinkEscape},
+		{testEntry{name: "docs/link", body: "../../secret", symlink: true}, ArchiveSymlinkEscape},
+		{testEntry{name: "link", body: strings.Repeat("a/", maxSymlinkTarget), symlink: true}, ArchiveSymlinkEscape},
+	}
+	for _, tt := range tests {
+		err := CheckZip(buildZip(t, tt.entry), ArchiveLimits{})
+		var archiveErr *ArchiveError
+		switch {
+		case tt.reason == "" && err != nil:
+			t.Errorf("CheckZip(%q) = %v, want nil", tt.entry.name, err)
+		case tt.reason != "" && (!errors.As(err, &archiveErr) || archiveErr.Reason != tt.reason):
+			t.Errorf("CheckZip(%q) = %v, want %s", tt.entry.name, err, tt.reason)
+		}
+	}
+}
+
+// TestCheckZipLimits 文件数量、单个文件大小和总大小的限制，0 为不限制
+// Limits on the file count, single file size and total size, 0 is unlimited
+func TestCheckZipLimits(t *testing.T) {
+	reader := buildZip(t,
+		testEntry{name: "docs/"},
+		testEntry{name: "a.txt", body: strings.Repeat("a", 100)},
+		testEntry{name: "b.txt", body: strings.Repeat("b", 100)},
+	)
+	tests := []struct {
+		limits ArchiveLimits
+		reason string
+	}{
+		{ArchiveLimits{}, ""},
+		{ArchiveLimits{MaxFiles: 2, MaxFileSize: 100, MaxTotalSize: 200}, ""},
+		{ArchiveLimits{MaxFiles: 1}, ArchiveTooManyFiles},
+		{ArchiveLimits{MaxFileSize: 99}, ArchiveFileTooLarge},
+		{ArchiveLimits{MaxTotalSize: 199}, ArchiveTooLarge},
+	}
+	for _, tt := range tests {
+		err := CheckZip(reader, tt.limits)
+		var archiveErr *ArchiveError
+		switch {
+		case tt.reason == "" && err != nil:
+			t.Errorf("CheckZip(%+v) = %v, want nil", tt.limits, err)
+		case tt.reason != "" && (!errors.As(err, &archiveErr) || archiveErr.Reason != tt.reason):
+			t.Errorf("CheckZip(%+v) = %v, want %s", tt.limits, err, tt.reason)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+
+	xdraw "golang.org/x/image/draw"
+)
+
+// AvatarSizes 上传的头像缩放到的边长，从小到大
+// Side lengths uploaded avatars are scaled to, from small to large
+var AvatarSizes = []int{32, 64, 128, 256}
+
+type avatarType struct{}
+
+var Avatar = avatarType{}
+
+// Process 解码上传的图片，居中裁剪为正方形并缩放为 AvatarSizes 中的每种尺寸的 PNG，返回原图的内容哈希
+// Decode an uploaded image, crop it to a centered square and scale it into a PNG of every size of AvatarSizes, returning the content hash of the original
+func (avatarType) Process(r io.Reader, maxBytes int64) (hash string, images map[int][]byte, err error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxBytes+1))
+	if err != nil {
+		return "", nil, err
+	}
+	img, err := decodeBoundedImage(bytes.NewReader(data), maxBytes)
+	if err != nil {
+		return "", nil, err
+	}
+	sum := sha256.Sum256(data)
+	square := Avatar.crop(img)
+	images = make(map[int][]byte, len(AvatarSizes))
+	for _, size := range AvatarSizes {
+		scaled := image.NewRGBA(image.Rect(0, 0, size, size))
+		xdraw.CatmullRom.Scale(scaled, scaled.Bounds(), img, square, draw.Src, nil)
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, scaled); err != nil {
+			return "", nil, err
+		}
+		images[size] = buf.Bytes()
+	}
+	return hex.EncodeToString(sum[:8]), images, nil
+}
+
+// crop 图片中居中的最大正方形
+// The largest centered square of the image
+func (avatarType) crop(img image.Image) image.Rectangle {
+	bounds := img.Bounds()
+	side := min(bounds.Dx(), bounds.Dy())
+	x := bounds.Min.X + (bounds.Dx()-side)/2
+	y := bounds.Min.Y + (bounds.Dy()-side)/2
+	return image.Rect(x, y, x+side, y+side)
+}
+
+// Size 不小于请求边长的最小标准尺寸，请求为 0 或超过最大尺寸时使用最大尺寸
+// The smallest standard size not below the requested side, the largest size when the request is 0 or beyond it
+func (avatarType) Size(requested int) int {
+	for _, size := range AvatarSizes {
+		if requested > 0 && size >= requested {
+			return size
+		}
+	}
+	return AvatarSizes[len(AvatarSizes)-1]
+}
+
+// Gravatar 邮箱对应的 Gravatar 地址，baseURL 为服务地址，没有头像时显示 identicon
+// Gravatar URL of an email, baseURL is the service URL and an identicon is shown when there is no avatar
+func (avatarType) Gravatar(baseURL, email string, size int) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(email))))
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	return baseURL + hex.EncodeToString(sum[:]) + "?d=identicon&s=" + strconv.Itoa(size)
+}
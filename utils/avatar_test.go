@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestAvatarProcess(t *testing.T) {
+	// 左侧红色、右侧蓝色的宽图，居中裁剪后左右各半 A wide image red on the left and blue on the right, half of each after the centered crop
+	src := image.NewRGBA(image.Rect(0, 0, 300, 100))
+	for x := 0; x < 300; x++ {
+		for y := 0; y < 100; y++ {
+			c := color.RGBA{R: 0xff, A: 0xff}
+			if x >= 150 {
+				c = color.RGBA{B: 0xff, A: 0xff}
+			}
+			src.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, src); err != nil {
+		t.Fatal(err)
+	}
+	hash, images, err := Avatar.Process(bytes.NewReader(buf.Bytes()), 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hash) != 16 {
+		t.Errorf("hash = %q", hash)
+	}
+	for _, size := range AvatarSizes {
+		img, err := png.Decode(bytes.NewReader(images[size]))
+		if err != nil {
+			t.Fatalf("size %d: %v", size, err)
+		}
+		if img.Bounds().Dx() != size || img.Bounds().Dy() != size {
+			t.Fatalf("size %d has bounds %v", size, img.Bounds())
+		}
+		if r, _, b, _ := img.At(1, size/2).RGBA(); r < 0xf000 || b > 0x1000 {
+			t.Errorf("size %d: left edge should be red", size)
+		}
+		if r, _, b, _ := img.At(size-2, size/2).RGBA(); b < 0xf000 || r > 0x1000 {
+			t.Errorf("size %d: right edge should be blue", size)
+		}
+	}
+	if _, _, err := Avatar.Process(bytes.NewReader(buf.Bytes()), 16); err == nil {
+		t.Error("images beyond the size limit should be rejected")
+	}
+	if _, _, err := Avatar.Process(strings.NewReader("not an image"), 1<<20); err == nil {
+		t.Error("undecodable data should be rejected")
+	}
+}
+
+func TestAvatarSize(t *testing.T) {
+	for requested, want := range map[int]int{0: 256, 1: 32, 32: 32, 33: 64, 200: 256, 1000: 256} {
+		if got := Avatar.Size(requested); got != want {
+			t.Errorf("Size(%d) = %d, want %d", requested, got, want)
+		}
+	}
+}
+
+func TestAvatarGravatar(t *testing.T) {
+	got := Avatar.Gravatar("https://www.gravatar.com/avatar", " MyEmailAddress@example.com ", 64)
+	want := "https://www.gravatar.com/avatar/84059b07d4be67b806386c0aad8070a23f18836bbaae342275dc0a83414c32ee?d=identicon&s=64"
+	if got != want {
+		t.Fatalf("Gravatar = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"fmt"
+	"html"
+	"unicode/utf8"
+)
+
+// 徽章颜色 Badge colors
+const (
+	BadgeGreen  = "#4c1"
+	BadgeYellow = "#dfb317"
+	BadgeRed    = "#e05d44"
+	BadgeGrey   = "#9f9f9f"
+)
+
+type badgeType struct{}
+
+var Badge = badgeType{}
+
+// badgeTextWidth 估算 11px Verdana 下文本的宽度，全角字符按两倍计算
+// Estimate the width of text in 11px Verdana, counting wide characters twice
+func badgeTextWidth(text string) int {
+	width := 0
+	for _, r := range text {
+		if utf8.RuneLen(r) > 2 {
+			width += 12
+		} else {
+			width += 7
+		}
+	}
+	return width
+}
+
+// Render 生成扁平样式的 SVG 徽章，左侧为标签，右侧为带颜色的消息
+// Render a flat SVG badge with the label on the left and the colored message on the right
+func (badgeType) Render(label, message, color string) []byte {
+	labelWidth := badgeTextWidth(label) + 10
+	messageWidth := badgeTextWidth(message) + 10
+	width := labelWidth + messageWidth
+	label, message = html.EscapeString(label), html.EscapeString(message)
+	return fmt.Appendf(nil, `<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="%[4]s: %[5]s">`+
+		`<title>%[4]s: %[5]s</title>`+
+		`<linearGradient id="s" x2="0" y2="100%%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>`+
+		`<clipPath id="r"><rect width="%[1]d" height="20" rx="3" fill="#fff"/></clipPath>`+
+		`<g clip-path="url(#r)"><rect width="%[2]d" height="20" fill="#555"/><rect x="%[2]d" width="%[3]d" height="20" fill="%[6]s"/><rect width="%[1]d" height="20" fill="url(#s)"/></g>`+
+		`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">`+
+		`<text x="%[7]d" y="14">%[4]s</text><text x="%[8]d" y="14">%[5]s</text></g></svg>`,
+		width, labelWidth, messageWidth, label, message, html.EscapeString(color), labelWidth/2, labelWidth+messageWidth/2)
+}
@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBadgeRender(t *testing.T) {
+	svg := string(Badge.Render("deploy", "ready", BadgeGreen))
+	if !strings.HasPrefix(svg, "<svg ") || !strings.HasSuffix(svg, "</svg>") {
+		t.Fatalf("not an svg document: %s", svg)
+	}
+	for _, want := range []string{`width="97"`, `fill="#4c1"`, `>deploy</text>`, `>ready</text>`} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("badge is missing %s: %s", want, svg)
+		}
+	}
+}
+
+func TestBadgeRenderEscapes(t *testing.T) {
+	svg := string(Badge.Render("<a>", `"b"&`, BadgeGrey))
+	if strings.Contains(svg, "<a>") || strings.Contains(svg, `"b"`) {
+		t.Fatalf("badge text is not escaped: %s", svg)
+	}
+}
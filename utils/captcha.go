@@ -1,15 +1,41 @@
 package utils
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	mrand "math/rand/v2"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/LiteyukiStudio/spage/config"
 	"github.com/LiteyukiStudio/spage/constants"
 	"github.com/go-resty/resty/v2"
+	"github.com/golang-jwt/jwt/v5"
 )
 
-type captchaType struct{}
+// mathCaptchaTTL 内置算术题的有效期 Lifetime of a built-in math challenge
+const mathCaptchaTTL = 5 * time.Minute
 
-var Captcha = captchaType{}
+type captchaType struct {
+	mu        sync.Mutex
+	providers map[string]CaptchaProvider
+	used      map[string]time.Time // 已使用的算术题及其过期时间，防止重放 Math challenges used with their expiry, preventing replays
+}
+
+var Captcha = &captchaType{
+	providers: map[string]CaptchaProvider{
+		constants.CaptchaTypeTurnstile: siteVerify{url: "https://challenges.cloudflare.com/turnstile/v0/siteverify"},
+		constants.CaptchaTypeHCaptcha:  siteVerify{url: "https://api.hcaptcha.com/siteverify"},
+		constants.CaptchaTypeReCaptcha: siteVerify{url: "https://www.google.com/recaptcha/api/siteverify"},
+		constants.CaptchaTypeMath:      mathCaptcha{},
+	},
+	used: make(map[string]time.Time),
+}
 
 type CaptchaConfig struct {
 	Type        string
@@ -17,31 +43,165 @@ type CaptchaConfig struct {
 	SecretKey   string
 }
 
+// CaptchaProvider 验证码服务，按 captcha.type 的名称选择
+// A captcha service, picked by the name in captcha.type
+type CaptchaProvider interface {
+	// Verify 校验客户端提交的令牌 Verify the token submitted by the client
+	Verify(restyClient *resty.Client, captchaConfig *CaptchaConfig, captchaToken string) (bool, error)
+}
+
+// Register 注册或替换验证码服务，需在启动时调用
+// Register or replace a captcha service, meant to be called at startup
+func (c *captchaType) Register(name string, provider CaptchaProvider) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.providers[name] = provider
+}
+
 // VerifyCaptcha 根据提供的配置和令牌验证验证码
 // Verify captcha based on provided configuration and token
-func (captchaType) VerifyCaptcha(restyClient *resty.Client, captchaConfig *CaptchaConfig, captchaToken string) (bool, error) {
-	switch captchaConfig.Type {
-	case constants.CaptchaTypeDisable:
+func (c *captchaType) VerifyCaptcha(restyClient *resty.Client, captchaConfig *CaptchaConfig, captchaToken string) (bool, error) {
+	if captchaConfig.Type == constants.CaptchaTypeDisable {
 		return true, nil
-	case constants.CaptchaTypeTurnstile:
-		result := make(map[string]any)
-		resp, err := restyClient.R().
-			SetFormData(map[string]string{
-				"secret":   captchaConfig.SecretKey,
-				"response": captchaToken,
-			}).SetResult(&result).Post("https://challenges.cloudflare.com/turnstile/v0/siteverify")
-		if err != nil {
-			return false, err
-		}
-		if resp.IsError() {
-			return false, nil
-		}
-		if success, ok := result["success"].(bool); ok && success {
-			return true, nil
-		} else {
-			return false, nil
-		}
-	default:
+	}
+	c.mu.Lock()
+	provider, ok := c.providers[captchaConfig.Type]
+	c.mu.Unlock()
+	if !ok {
 		return false, fmt.Errorf("invalid captcha type: %s", captchaConfig.Type)
 	}
+	if captchaToken == "" {
+		return false, nil
+	}
+	return provider.Verify(restyClient, captchaConfig, captchaToken)
+}
+
+// siteVerify 使用 siteverify 接口校验令牌的服务，Turnstile、hCaptcha 和 reCAPTCHA 的接口相同
+// A service verifying tokens through a siteverify endpoint, shared by Turnstile, hCaptcha and reCAPTCHA
+type siteVerify struct {
+	url string
+}
+
+func (s siteVerify) Verify(restyClient *resty.Client, captchaConfig *CaptchaConfig, captchaToken string) (bool, error) {
+	result := struct {
+		Success bool `json:"success"`
+	}{}
+	resp, err := restyClient.R().
+		SetFormData(map[string]string{
+			"secret":   captchaConfig.SecretKey,
+			"response": captchaToken,
+		}).SetResult(&result).Post(s.url)
+	if err != nil {
+		return false, err
+	}
+	if resp.IsError() {
+		return false, nil
+	}
+	return result.Success, nil
+}
+
+// MathChallenge 内置算术验证码的一道题
+// One challenge of the built-in math captcha
+type MathChallenge struct {
+	Question  string    `json:"question"`   // 题目，例如 3 + 4 = ? Question, e.g. 3 + 4 = ?
+	Challenge string    `json:"challenge"`  // 签名的题目凭据，以 <challenge>:<答案> 作为验证码令牌提交 Signed challenge, submitted as <challenge>:<answer> for the captcha token
+	ExpiresAt time.Time `json:"expires_at"` // 过期时间 Expiry time
+}
+
+// mathClaims 算术题凭据，只包含答案的摘要
+// Credential of a math challenge, holding only a digest of the answer
+type mathClaims struct {
+	jwt.RegisteredClaims
+	Digest string `json:"digest"`
+}
+
+// mathKey 签名密钥，与登录令牌的密钥不同
+// Signing key, different from the one of login tokens
+func mathKey() []byte {
+	return []byte("captcha\n" + config.JwtSecret)
+}
+
+// mathDigest 题目编号和答案的摘要
+// Digest of the challenge ID and the answer
+func mathDigest(id, answer string) string {
+	mac := hmac.New(sha256.New, mathKey())
+	mac.Write([]byte(id + "\n" + answer))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewMath 生成一道内置算术题，答案不会出现在凭据中
+// Generate a built-in math challenge, the answer never appears in the credential
+func (c *captchaType) NewMath() (*MathChallenge, error) {
+	a, b := mrand.IntN(20)+1, mrand.IntN(20)+1
+	var question string
+	var answer int
+	switch mrand.IntN(3) {
+	case 0:
+		question, answer = fmt.Sprintf("%d + %d = ?", a, b), a+b
+	case 1:
+		a, b = max(a, b), min(a, b)
+		question, answer = fmt.Sprintf("%d - %d = ?", a, b), a-b
+	default:
+		a, b = a%9+1, b%9+1
+		question, answer = fmt.Sprintf("%d × %d = ?", a, b), a*b
+	}
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	id := hex.EncodeToString(buf)
+	expiresAt := time.Now().Add(mathCaptchaTTL)
+	claims := mathClaims{
+		RegisteredClaims: jwt.RegisteredClaims{ID: id, ExpiresAt: jwt.NewNumericDate(expiresAt)},
+		Digest:           mathDigest(id, strconv.Itoa(answer)),
+	}
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(mathKey())
+	if err != nil {
+		return nil, err
+	}
+	return &MathChallenge{Question: question, Challenge: signed, ExpiresAt: expiresAt}, nil
+}
+
+// consume 将算术题标记为已使用，已使用过时返回假；同时清理已过期的记录
+// Mark a math challenge as used, false when it was used already; expired records are cleaned up on the way
+func (c *captchaType) consume(id string, expiresAt time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for usedID, expiry := range c.used {
+		if now.After(expiry) {
+			delete(c.used, usedID)
+		}
+	}
+	if _, ok := c.used[id]; ok {
+		return false
+	}
+	c.used[id] = expiresAt
+	return true
+}
+
+// mathCaptcha 内置算术验证码，无需外部服务；每道题只能使用一次，多实例部署时各实例分别记录
+// Built-in math captcha needing no external service; each challenge is accepted once, tracked per instance when several run
+type mathCaptcha struct{}
+
+func (mathCaptcha) Verify(_ *resty.Client, _ *CaptchaConfig, captchaToken string) (bool, error) {
+	i := strings.LastIndexByte(captchaToken, ':')
+	if i < 0 {
+		return false, nil
+	}
+	claims := &mathClaims{}
+	_, err := jwt.ParseWithClaims(captchaToken[:i], claims, func(token *jwt.Token) (any, error) {
+		return mathKey(), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil || claims.ID == "" {
+		return false, nil
+	}
+	answer, err := strconv.Atoi(strings.TrimSpace(captchaToken[i+1:]))
+	if err != nil {
+		return false, nil
+	}
+	if !hmac.Equal([]byte(mathDigest(claims.ID, strconv.Itoa(answer))), []byte(claims.Digest)) {
+		return false, nil
+	}
+	return Captcha.consume(claims.ID, claims.ExpiresAt.Time), nil
 }
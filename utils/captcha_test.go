@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/go-resty/resty/v2"
+)
+
+// solveMath 按题目计算答案 Compute the answer of a question
+func solveMath(t *testing.T, question string) int {
+	m := regexp.MustCompile(`^(\d+) (.) (\d+) = \?$`).FindStringSubmatch(question)
+	if m == nil {
+		t.Fatalf("unexpected question %q", question)
+	}
+	a, _ := strconv.Atoi(m[1])
+	b, _ := strconv.Atoi(m[3])
+	switch m[2] {
+	case "+":
+		return a + b
+	case "-":
+		return a - b
+	}
+	return a * b
+}
+
+func TestMathCaptcha(t *testing.T) {
+	mathConfig := &CaptchaConfig{Type: constants.CaptchaTypeMath}
+	challenge, err := Captcha.NewMath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	answer := solveMath(t, challenge.Question)
+	if strings.Contains(challenge.Challenge, ":") {
+		t.Fatalf("challenge must not contain the separator: %s", challenge.Challenge)
+	}
+	for _, token := range []string{"", challenge.Challenge, challenge.Challenge + ":" + strconv.Itoa(answer+1), challenge.Challenge + "x:" + strconv.Itoa(answer)} {
+		if ok, err := Captcha.VerifyCaptcha(nil, mathConfig, token); ok || err != nil {
+			t.Errorf("token %q passed: %v, %v", token, ok, err)
+		}
+	}
+	token := challenge.Challenge + ": " + strconv.Itoa(answer)
+	if ok, err := Captcha.VerifyCaptcha(nil, mathConfig, token); !ok || err != nil {
+		t.Fatalf("correct answer rejected: %v, %v", ok, err)
+	}
+	if ok, _ := Captcha.VerifyCaptcha(nil, mathConfig, token); ok {
+		t.Fatal("a challenge must only be accepted once")
+	}
+}
+
+func TestSiteVerifyCaptcha(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		w.Header().Set("Content-Type", "application/json")
+		if r.Form.Get("secret") == "secret" && r.Form.Get("response") == "good" {
+			_, _ = w.Write([]byte(`{"success": true}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"success": false}`))
+	}))
+	defer server.Close()
+	Captcha.Register("test", siteVerify{url: server.URL})
+	testConfig := &CaptchaConfig{Type: "test", SecretKey: "secret"}
+	client := resty.New()
+	if ok, err := Captcha.VerifyCaptcha(client, testConfig, "good"); !ok || err != nil {
+		t.Errorf("valid token rejected: %v, %v", ok, err)
+	}
+	if ok, err := Captcha.VerifyCaptcha(client, testConfig, "bad"); ok || err != nil {
+		t.Errorf("invalid token passed: %v, %v", ok, err)
+	}
+	if ok, err := Captcha.VerifyCaptcha(client, &CaptchaConfig{Type: constants.CaptchaTypeDisable}, ""); !ok || err != nil {
+		t.Errorf("disabled captcha must pass: %v, %v", ok, err)
+	}
+	if _, err := Captcha.VerifyCaptcha(client, &CaptchaConfig{Type: "unknown"}, "good"); err == nil {
+		t.Error("unknown captcha type must fail")
+	}
+}
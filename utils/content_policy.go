@@ -0,0 +1,145 @@
+package utils
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"strings"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/models"
+)
+
+// ContentPolicy 托管内容策略，按扩展名和 MIME 类型禁止文件
+// Hosted content policy disallowing files by extension and MIME type
+type ContentPolicy struct {
+	Extensions []string `json:"blocked_extensions"` // 禁止的扩展名 Disallowed extensions
+	MIMETypes  []string `json:"blocked_mime_types"` // 禁止的 MIME 类型 Disallowed MIME types
+	Action     string   `json:"action"`             // reject 或 strip Reject or strip
+	Serve      bool     `json:"serve"`              // 访问时是否同样拒绝 Whether requests are refused too
+}
+
+// EffectiveContentPolicy 生效的内容策略，override 中设置的字段覆盖配置文件中的实例默认值，override 为空时即为默认策略
+// Effective content policy, fields set in override come before the instance defaults of the configuration, which apply alone when override is nil
+func EffectiveContentPolicy(override *models.ContentPolicy) ContentPolicy {
+	policy := ContentPolicy{
+		Extensions: config.PolicyBlockedExtensions,
+		MIMETypes:  config.PolicyBlockedMIMETypes,
+		Action:     config.PolicyAction,
+		Serve:      config.PolicyServe,
+	}
+	if override == nil {
+		return policy
+	}
+	if override.BlockedExtensions != nil {
+		policy.Extensions = override.BlockedExtensions
+	}
+	if override.BlockedMIMETypes != nil {
+		policy.MIMETypes = override.BlockedMIMETypes
+	}
+	if override.Action != "" {
+		policy.Action = override.Action
+	}
+	if override.Serve != nil {
+		policy.Serve = *override.Serve
+	}
+	return policy
+}
+
+// Empty 策略是否不禁止任何文件
+// Whether the policy disallows nothing
+func (p ContentPolicy) Empty() bool {
+	return len(p.Extensions) == 0 && len(p.MIMETypes) == 0
+}
+
+// Blocks 判断文件是否被禁止，返回命中的规则
+// Check whether a file is disallowed, returning the matched rule
+func (p ContentPolicy) Blocks(name string) (string, bool) {
+	lower := strings.ToLower(name)
+	for _, ext := range p.Extensions {
+		if ext = normalizeExtension(ext); ext != "." && strings.HasSuffix(lower, ext) {
+			return ext, true
+		}
+	}
+	if len(p.MIMETypes) == 0 {
+		return "", false
+	}
+	contentType, _, _ := strings.Cut(mime.TypeByExtension(path.Ext(lower)), ";")
+	if contentType == "" {
+		return "", false
+	}
+	for _, pattern := range p.MIMETypes {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern == contentType || (strings.HasSuffix(pattern, "/*") && strings.HasPrefix(contentType, strings.TrimSuffix(pattern, "*"))) {
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+// Blocked 列出被禁止的文件及命中的规则
+// List the disallowed files with their matched rules
+func (p ContentPolicy) Blocked(names []string) map[string]string {
+	blocked := make(map[string]string)
+	for _, name := range names {
+		if rule, ok := p.Blocks(name); ok {
+			blocked[name] = rule
+		}
+	}
+	return blocked
+}
+
+// Validate 校验扩展名和 MIME 类型的格式及处理方式
+// Validate the format of extensions and MIME types and the action
+func (p ContentPolicy) Validate() error {
+	for _, ext := range p.Extensions {
+		if ext = normalizeExtension(ext); ext == "." || strings.ContainsAny(ext, "/\\ ") {
+			return fmt.Errorf("invalid extension %q", ext)
+		}
+	}
+	for _, pattern := range p.MIMETypes {
+		kind, sub, ok := strings.Cut(strings.TrimSpace(pattern), "/")
+		if !ok || kind == "" || kind == "*" || sub == "" || strings.ContainsAny(pattern, "; ") {
+			return fmt.Errorf("invalid mime type %q, use type/subtype or type/*", pattern)
+		}
+	}
+	if p.Action != config.PolicyActionReject && p.Action != config.PolicyActionStrip {
+		return fmt.Errorf("action must be %q or %q", config.PolicyActionReject, config.PolicyActionStrip)
+	}
+	return nil
+}
+
+// normalizeExtension 转为小写并补全开头的点
+// Lowercase the extension and add the leading dot
+func normalizeExtension(ext string) string {
+	ext = strings.ToLower(strings.TrimSpace(ext))
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
+}
+
+// StripZip 将压缩包中未被禁止的条目原样复制到 dst，返回去掉的文件及命中的规则
+// Copy the entries of the archive the policy allows to dst unchanged, returning the stripped files with their matched rules
+func (p ContentPolicy) StripZip(src io.ReaderAt, size int64, dst io.Writer) (map[string]string, error) {
+	reader, err := zip.NewReader(src, size)
+	if err != nil {
+		return nil, err
+	}
+	stripped := make(map[string]string)
+	w := zip.NewWriter(dst)
+	for _, entry := range reader.File {
+		if !entry.FileInfo().IsDir() {
+			if rule, ok := p.Blocks(entry.Name); ok {
+				stripped[strings.TrimPrefix(entry.Name, "./")] = rule
+				continue
+			}
+		}
+		if err := w.Copy(entry); err != nil {
+			return nil, err
+		}
+	}
+	return stripped, w.Close()
+}
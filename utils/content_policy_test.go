@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/models"
+)
+
+func TestContentPolicyBlocks(t *testing.T) {
+	policy := ContentPolicy{Extensions: []string{".exe", "PHP", ".tar.gz"}, MIMETypes: []string{"application/wasm", "image/*"}}
+	tests := map[string]string{
+		"index.html":         "",
+		"setup.EXE":          ".exe",
+		"api/login.php":      ".php",
+		"backup.tar.gz":      ".tar.gz",
+		"app.wasm":           "application/wasm",
+		"media/logo.PNG":     "image/*",
+		"php/readme.md":      "",
+		"archive.gz":         "",
+		"installer.exe.html": "",
+	}
+	for name, want := range tests {
+		rule, blocked := policy.Blocks(name)
+		if rule != want || blocked != (want != "") {
+			t.Errorf("%s: got %q %v, want %q", name, rule, blocked, want)
+		}
+	}
+	if _, blocked := (ContentPolicy{}).Blocks("setup.exe"); blocked {
+		t.Fatal("an empty policy should allow everything")
+	}
+}
+
+func TestContentPolicyValidate(t *testing.T) {
+	valid := ContentPolicy{Extensions: []string{".exe", "php"}, MIMETypes: []string{"application/*", "text/x-php"}, Action: config.PolicyActionStrip}
+	if err := valid.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	for _, policy := range []ContentPolicy{
+		{Extensions: []string{"."}, Action: config.PolicyActionReject},
+		{Extensions: []string{"a/b"}, Action: config.PolicyActionReject},
+		{MIMETypes: []string{"application"}, Action: config.PolicyActionReject},
+		{MIMETypes: []string{"*/*"}, Action: config.PolicyActionReject},
+		{MIMETypes: []string{"text/html; charset=utf-8"}, Action: config.PolicyActionReject},
+		{Action: "delete"},
+	} {
+		if err := policy.Validate(); err == nil {
+			t.Errorf("%+v should be invalid", policy)
+		}
+	}
+}
+
+func TestContentPolicyStripZip(t *testing.T) {
+	archive := importZip(t, map[string][]byte{
+		"index.html":      []byte("<h1>hi</h1>"),
+		"download/a.exe":  []byte("MZ"),
+		"scripts/app.js":  []byte("console.log(1)"),
+		"scripts/cmd.PHP": []byte("<?php"),
+	})
+	policy := ContentPolicy{Extensions: []string{".exe", ".php"}}
+	var out bytes.Buffer
+	stripped, err := policy.StripZip(bytes.NewReader(archive), int64(len(archive)), &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stripped) != 2 || stripped["download/a.exe"] != ".exe" || stripped["scripts/cmd.PHP"] != ".php" {
+		t.Fatalf("got %v, want the exe and php files stripped", stripped)
+	}
+	reader, err := zip.NewReader(bytes.NewReader(out.Bytes()), int64(out.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	kept := map[string]string{}
+	for _, entry := range reader.File {
+		body, _ := entry.Open()
+		data, _ := io.ReadAll(body)
+		kept[entry.Name] = string(data)
+	}
+	if len(kept) != 2 || kept["index.html"] != "<h1>hi</h1>" || kept["scripts/app.js"] != "console.log(1)" {
+		t.Fatalf("got %v, want the allowed files copied unchanged", kept)
+	}
+}
+
+func TestEffectiveContentPolicy(t *testing.T) {
+	extensions, action := config.PolicyBlockedExtensions, config.PolicyAction
+	defer func() { config.PolicyBlockedExtensions, config.PolicyAction = extensions, action }()
+	config.PolicyBlockedExtensions, config.PolicyAction = []string{".exe"}, config.PolicyActionReject
+
+	if policy := EffectiveContentPolicy(nil); len(policy.Extensions) != 1 || policy.Action != config.PolicyActionReject {
+		t.Fatalf("got %+v, want the defaults", policy)
+	}
+	serve := true
+	policy := EffectiveContentPolicy(&models.ContentPolicy{BlockedExtensions: []string{}, Action: config.PolicyActionStrip, Serve: &serve})
+	if !policy.Empty() || policy.Action != config.PolicyActionStrip || !policy.Serve {
+		t.Fatalf("got %+v, want the override to allow everything and strip", policy)
+	}
+}
@@ -0,0 +1,151 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/models"
+)
+
+type domainType struct{}
+
+// Domain 自定义域名的校验与所有权验证
+// Validation and ownership verification of custom domains
+var Domain = domainType{}
+
+const (
+	DomainChallengeRecord = "_spage-challenge"              // DNS 验证的 TXT 记录前缀 TXT record label of DNS verification
+	DomainChallengePath   = "/.well-known/spage-challenge/" // HTTP 验证的路径前缀 Path prefix of HTTP verification
+
+	PreviewSeparator = "--" // 预览主机名中名称与子域的分隔符 Separator between the name and the subdomain of preview hosts
+
+	domainCheckTimeout   = 10 * time.Second
+	previewNameMaxLength = 40
+)
+
+// PreviewName 将分支或合并请求标识转换为预览子域中使用的名称，例如 feature/Login 转为 feature-login
+// Turn a branch or pull request identifier into the name used in preview hosts, e.g. feature/Login becomes feature-login
+func (domainType) PreviewName(raw string) (string, error) {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(raw)) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		} else if s := b.String(); s != "" && !strings.HasSuffix(s, "-") {
+			b.WriteByte('-')
+		}
+	}
+	name := strings.TrimSuffix(b.String(), "-")
+	if name == "" || len(name) > previewNameMaxLength {
+		return "", fmt.Errorf("invalid preview name %q", raw)
+	}
+	return name, nil
+}
+
+// PreviewHost 预览部署的主机名，名称与子域之间用 -- 分隔
+// Host of a preview deployment, the name and the subdomain are separated by --
+func (domainType) PreviewHost(name, subDomain, baseDomain string) string {
+	return name + PreviewSeparator + strings.ToLower(subDomain) + "." + baseDomain
+}
+
+// Normalize 转为小写并校验域名格式，拒绝 IP 地址和单级名称
+// Lowercase and validate the domain, rejecting IP addresses and single-label names
+func (domainType) Normalize(name string) (string, error) {
+	name = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+	if len(name) == 0 || len(name) > 253 || net.ParseIP(name) != nil {
+		return "", fmt.Errorf("invalid domain %q", name)
+	}
+	labels := strings.Split(name, ".")
+	if len(labels) < 2 {
+		return "", fmt.Errorf("invalid domain %q", name)
+	}
+	for _, label := range labels {
+		if len(label) == 0 || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return "", fmt.Errorf("invalid domain %q", name)
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-') {
+				return "", fmt.Errorf("invalid domain %q", name)
+			}
+		}
+	}
+	return name, nil
+}
+
+// Host 去掉请求主机名中的端口并转为小写
+// Strip the port from a request host and lowercase it
+func (domainType) Host(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
+
+// NewToken 生成验证令牌
+// Generate a verification token
+func (domainType) NewToken() (string, error) {
+	return randomToken()
+}
+
+// RecordName DNS 验证需要创建的 TXT 记录名
+// Name of the TXT record to create for DNS verification
+func (domainType) RecordName(domain string) string {
+	return DomainChallengeRecord + "." + domain
+}
+
+// RecordValue DNS 验证需要创建的 TXT 记录值
+// Value of the TXT record to create for DNS verification
+func (domainType) RecordValue(token string) string {
+	return "spage-verify=" + token
+}
+
+// ChallengeURL HTTP 验证时请求的地址，域名解析到本实例后由实例自身应答
+// URL requested by HTTP verification, answered by this instance once the domain resolves to it
+func (domainType) ChallengeURL(domain, token string) string {
+	return "http://" + domain + DomainChallengePath + token
+}
+
+// Verify 按域名的验证方式检查所有权
+// Check the ownership with the domain's verification method
+func (domainType) Verify(ctx context.Context, domain *models.CustomDomain) error {
+	ctx, cancel := context.WithTimeout(ctx, domainCheckTimeout)
+	defer cancel()
+	switch domain.Method {
+	case constants.DomainVerifyDNS:
+		records, err := net.DefaultResolver.LookupTXT(ctx, Domain.RecordName(domain.Domain))
+		if err != nil {
+			return fmt.Errorf("lookup TXT record: %w", err)
+		}
+		if !slices.Contains(records, Domain.RecordValue(domain.Token)) {
+			return errors.New("TXT record not found or does not match")
+		}
+		return nil
+	case constants.DomainVerifyHTTP:
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, Domain.ChallengeURL(domain.Domain, domain.Token), nil)
+		if err != nil {
+			return err
+		}
+		// 不连接内网地址也不跟随重定向，应答必须来自域名本身 Internal addresses are never dialed and redirects are not followed, the answer must come from the domain itself
+		resp, err := publicClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("request challenge: %w", err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode != http.StatusOK || strings.TrimSpace(string(body)) != domain.Token {
+			return fmt.Errorf("challenge returned status %d with unexpected content", resp.StatusCode)
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported verification method %q", domain.Method)
+}
@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/models"
+)
+
+// TestVerifyHTTPRefusesInternal HTTP 验证不会请求本机或内网地址
+// HTTP verification never requests the local host or internal addresses
+func TestVerifyHTTPRefusesInternal(t *testing.T) {
+	requested := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = true
+		_, _ = w.Write([]byte("token"))
+	}))
+	defer server.Close()
+
+	domain := &models.CustomDomain{Domain: strings.TrimPrefix(server.URL, "http://"), Method: constants.DomainVerifyHTTP, Token: "token"}
+	if err := Domain.Verify(context.Background(), domain); !errors.Is(err, ErrPrivateAddress) {
+		t.Errorf("Verify = %v, want ErrPrivateAddress", err)
+	}
+	if requested {
+		t.Error("the loopback server was requested")
+	}
+}
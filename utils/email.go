@@ -2,11 +2,17 @@ package utils
 
 import (
 	"crypto/tls"
+	"encoding/base64"
 	"fmt"
 	"io"
+	"mime"
 	"net"
+	"net/mail"
 	"net/smtp"
 	"strings"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
 )
 
 type EmailType struct{}
@@ -21,15 +27,16 @@ type EmailConfig struct {
 	Port     string // 邮箱服务器端口 Email server port
 	Password string // 邮箱密码 Email password
 	SSL      bool   // 是否使用SSL Email use SSL
+	FromName string // 发件人名称 Sender display name
 }
 
 // SendTemplate 发送HTML模板，从配置文件中读取邮箱配置
 // Send HTML template, read email configuration from the configuration file
-func SendTemplate(emailConfig *EmailConfig, target, htmlTemplate string, placeholders map[string]string) error {
+func SendTemplate(emailConfig *EmailConfig, target, subject, htmlTemplate string, placeholders map[string]string) error {
 	for placeholder, value := range placeholders {
 		htmlTemplate = strings.ReplaceAll(htmlTemplate, placeholder, value)
 	}
-	err := SendEmail(emailConfig, target, htmlTemplate, true)
+	err := SendEmail(emailConfig, target, subject, htmlTemplate, true)
 	if err != nil {
 		return err
 	}
@@ -38,7 +45,7 @@ func SendTemplate(emailConfig *EmailConfig, target, htmlTemplate string, placeho
 
 // SendEmail 发送邮件
 // Send Email
-func SendEmail(emailConfig *EmailConfig, target, content string, isHTML bool) error {
+func SendEmail(emailConfig *EmailConfig, target, subject, content string, isHTML bool) error {
 	// 如果配置未启用，则直接返回nil
 	// If the configuration is not enabled, return nil directly
 	if !emailConfig.Enable {
@@ -85,6 +92,13 @@ func SendEmail(emailConfig *EmailConfig, target, content string, isHTML bool) er
 		}
 	}(client)
 
+	// 非 SSL 连接在服务器支持时升级为 TLS，避免明文发送密码 Plain connections are upgraded to TLS when the server supports it, so the password is not sent in clear text
+	if ok, _ := client.Extension("STARTTLS"); ok && !emailConfig.SSL {
+		if err = client.StartTLS(&tls.Config{ServerName: emailConfig.Host}); err != nil {
+			return err
+		}
+	}
+
 	if err = client.Auth(auth); err != nil {
 		return err
 		// todo: 处理身份验证时的错误
@@ -118,13 +132,46 @@ func SendEmail(emailConfig *EmailConfig, target, content string, isHTML bool) er
 		}
 	}(writer)
 
-	var message string
+	contentType := "text/plain; charset=UTF-8"
 	if isHTML {
-		message = fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: Notification\r\nContent-Type: text/html; charset=UTF-8\r\n\r\n%s", emailConfig.Address, target, content)
-	} else {
-		message = fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: Notification\r\n\r\n%s", emailConfig.Address, target, content)
+		contentType = "text/html; charset=UTF-8"
+	}
+	from := (&mail.Address{Name: emailConfig.FromName, Address: emailConfig.Address}).String()
+	messageID, err := randomToken()
+	if err != nil {
+		return err
 	}
+	message := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nDate: %s\r\nMessage-ID: <%s@%s>\r\nMIME-Version: 1.0\r\nContent-Type: %s\r\nContent-Transfer-Encoding: base64\r\n\r\n%s",
+		from, target, mime.QEncoding.Encode("utf-8", subject), time.Now().Format(time.RFC1123Z), messageID, emailConfig.Host, contentType, wrapBase64(content))
 
 	_, err = writer.Write([]byte(message))
 	return err
 }
+
+// wrapBase64 以 base64 编码正文并按 76 个字符换行，避免非 ASCII 内容和超长行被服务器拒绝
+// Encode the body as base64 wrapped at 76 characters, so servers do not refuse non-ASCII content or overlong lines
+func wrapBase64(content string) string {
+	encoded := base64.StdEncoding.EncodeToString([]byte(content))
+	var b strings.Builder
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	b.WriteString(encoded)
+	return b.String()
+}
+
+// EmailConfigFromConfig 由配置文件的 email 配置项构建邮箱配置
+// Build the email configuration from the email section of the configuration file
+func EmailConfigFromConfig() *EmailConfig {
+	return &EmailConfig{
+		Enable:   config.EmailEnable,
+		Username: config.EmailUsername,
+		Address:  config.EmailAddress,
+		Host:     config.EmailHost,
+		Port:     config.EmailPort,
+		Password: config.EmailPassword,
+		SSL:      config.EmailSSL,
+		FromName: config.EmailFromName,
+	}
+}
@@ -0,0 +1,180 @@
+package utils
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// Feed 订阅源，可输出为 Atom 或 RSS 2.0
+// A syndication feed, rendered as Atom or RSS 2.0
+type Feed struct {
+	ID          string      // 订阅源的唯一标识 Unique identifier of the feed
+	Title       string      // 标题 Title
+	Description string      // 描述 Description
+	Link        string      // 对应的网页 Web page of the feed
+	Self        string      // 订阅源自身的地址 URL of the feed itself
+	Author      string      // 条目未设置作者时使用的作者 Author of the entries that set none
+	Updated     time.Time   // 最后更新时间，为零时取最新条目的时间 Last update, the newest entry's time when zero
+	Entries     []FeedEntry // 条目，最新的在前 Entries, newest first
+}
+
+// FeedEntry 订阅源的一个条目
+// One entry of a feed
+type FeedEntry struct {
+	ID        string    // 条目的唯一标识 Unique identifier of the entry
+	Title     string    // 标题 Title
+	Link      string    // 对应的网页 Web page of the entry
+	Author    string    // 作者，可以为空 Author, may be empty
+	Content   string    // 纯文本内容，可以为空 Plain text content, may be empty
+	Published time.Time // 发布时间 Published time
+}
+
+// 输出的 XML 结构 XML structures written out
+type (
+	atomFeed struct {
+		XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+		ID       string      `xml:"id"`
+		Title    string      `xml:"title"`
+		Subtitle string      `xml:"subtitle,omitempty"`
+		Author   *atomAuthor `xml:"author,omitempty"`
+		Updated  string      `xml:"updated"`
+		Links    []atomLink  `xml:"link"`
+		Entries  []atomEntry `xml:"entry"`
+	}
+	atomLink struct {
+		Rel  string `xml:"rel,attr,omitempty"`
+		Type string `xml:"type,attr,omitempty"`
+		Href string `xml:"href,attr"`
+	}
+	atomAuthor struct {
+		Name string `xml:"name"`
+	}
+	atomText struct {
+		Type string `xml:"type,attr"`
+		Body string `xml:",chardata"`
+	}
+	atomEntry struct {
+		ID        string      `xml:"id"`
+		Title     string      `xml:"title"`
+		Updated   string      `xml:"updated"`
+		Published string      `xml:"published"`
+		Link      *atomLink   `xml:"link,omitempty"`
+		Author    *atomAuthor `xml:"author,omitempty"`
+		Content   *atomText   `xml:"content,omitempty"`
+	}
+	rssFeed struct {
+		XMLName xml.Name   `xml:"rss"`
+		Version string     `xml:"version,attr"`
+		Atom    string     `xml:"xmlns:atom,attr"`
+		DC      string     `xml:"xmlns:dc,attr"`
+		Channel rssChannel `xml:"channel"`
+	}
+	rssChannel struct {
+		Title         string    `xml:"title"`
+		Link          string    `xml:"link"`
+		Description   string    `xml:"description"`
+		LastBuildDate string    `xml:"lastBuildDate"`
+		Self          *atomLink `xml:"atom:link,omitempty"`
+		Items         []rssItem `xml:"item"`
+	}
+	rssGUID struct {
+		IsPermaLink string `xml:"isPermaLink,attr"`
+		Value       string `xml:",chardata"`
+	}
+	rssItem struct {
+		Title       string  `xml:"title"`
+		Link        string  `xml:"link,omitempty"`
+		Description string  `xml:"description,omitempty"`
+		Author      string  `xml:"dc:creator,omitempty"`
+		GUID        rssGUID `xml:"guid"`
+		PubDate     string  `xml:"pubDate"`
+	}
+)
+
+// updated 订阅源的最后更新时间，没有条目且未设置时为 Unix 纪元
+// Last update of the feed, the Unix epoch when unset without entries
+func (f *Feed) updated() time.Time {
+	if !f.Updated.IsZero() {
+		return f.Updated
+	}
+	var updated time.Time
+	for _, entry := range f.Entries {
+		if entry.Published.After(updated) {
+			updated = entry.Published
+		}
+	}
+	if updated.IsZero() {
+		return time.Unix(0, 0)
+	}
+	return updated
+}
+
+// Atom 按 RFC 4287 输出 Atom 订阅源
+// Render the feed as Atom per RFC 4287
+func (f *Feed) Atom() ([]byte, error) {
+	doc := atomFeed{
+		ID:       f.ID,
+		Title:    f.Title,
+		Subtitle: f.Description,
+		Updated:  f.updated().UTC().Format(time.RFC3339),
+	}
+	if f.Author != "" {
+		doc.Author = &atomAuthor{Name: f.Author}
+	}
+	if f.Link != "" {
+		doc.Links = append(doc.Links, atomLink{Rel: "alternate", Type: "text/html", Href: f.Link})
+	}
+	if f.Self != "" {
+		doc.Links = append(doc.Links, atomLink{Rel: "self", Type: "application/atom+xml", Href: f.Self})
+	}
+	for _, entry := range f.Entries {
+		published := entry.Published.UTC().Format(time.RFC3339)
+		item := atomEntry{ID: entry.ID, Title: entry.Title, Updated: published, Published: published}
+		if entry.Link != "" {
+			item.Link = &atomLink{Rel: "alternate", Type: "text/html", Href: entry.Link}
+		}
+		if entry.Author != "" {
+			item.Author = &atomAuthor{Name: entry.Author}
+		}
+		if entry.Content != "" {
+			item.Content = &atomText{Type: "text", Body: entry.Content}
+		}
+		doc.Entries = append(doc.Entries, item)
+	}
+	return feedXML(doc)
+}
+
+// RSS 输出 RSS 2.0 订阅源，条目作者使用 Dublin Core 的 creator
+// Render the feed as RSS 2.0, entry authors go into the Dublin Core creator
+func (f *Feed) RSS() ([]byte, error) {
+	channel := rssChannel{
+		Title:         f.Title,
+		Link:          f.Link,
+		Description:   f.Description,
+		LastBuildDate: f.updated().UTC().Format(time.RFC1123Z),
+	}
+	if f.Self != "" {
+		channel.Self = &atomLink{Rel: "self", Type: "application/rss+xml", Href: f.Self}
+	}
+	for _, entry := range f.Entries {
+		channel.Items = append(channel.Items, rssItem{
+			Title:       entry.Title,
+			Link:        entry.Link,
+			Description: entry.Content,
+			Author:      entry.Author,
+			GUID:        rssGUID{IsPermaLink: "false", Value: entry.ID},
+			PubDate:     entry.Published.UTC().Format(time.RFC1123Z),
+		})
+	}
+	return feedXML(rssFeed{Version: "2.0", Atom: "http://www.w3.org/2005/Atom", DC: "http://purl.org/dc/elements/1.1/", Channel: channel})
+}
+
+// feedXML 带 XML 声明输出文档
+// Write the document out with the XML declaration
+func feedXML(doc any) ([]byte, error) {
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testFeed() *Feed {
+	return &Feed{
+		ID:     "tag:example.com,2024-01-01:project/1",
+		Title:  "demo deployments",
+		Link:   "https://panel.example.com/project/demo",
+		Author: "demo-org",
+		Self:   "https://panel.example.com/api/v1/feed/project/demo/deployments.atom",
+		Entries: []FeedEntry{
+			{ID: "tag:example.com,2024-01-03:release/2", Title: "v2 <beta>", Link: "https://demo.example.com/", Author: "alice", Content: "Fix & improve", Published: time.Date(2024, 1, 3, 8, 0, 0, 0, time.UTC)},
+			{ID: "tag:example.com,2024-01-02:release/1", Title: "v1", Published: time.Date(2024, 1, 2, 8, 0, 0, 0, time.UTC)},
+		},
+	}
+}
+
+func TestFeedAtom(t *testing.T) {
+	data, err := testFeed().Atom()
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := string(data)
+	for _, want := range []string{
+		`<feed xmlns="http://www.w3.org/2005/Atom">`,
+		`<updated>2024-01-03T08:00:00Z</updated>`,
+		`rel="self" type="application/atom+xml"`,
+		`<title>v2 &lt;beta&gt;</title>`,
+		`<content type="text">Fix &amp; improve</content>`,
+		`<name>alice</name>`,
+		`<name>demo-org</name>`,
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("atom feed is missing %s:\n%s", want, doc)
+		}
+	}
+	if strings.Count(doc, "<entry>") != 2 {
+		t.Errorf("expected 2 entries:\n%s", doc)
+	}
+	if err := xml.Unmarshal(data, new(struct{})); err != nil {
+		t.Errorf("atom feed is not well-formed: %v", err)
+	}
+}
+
+func TestFeedRSS(t *testing.T) {
+	data, err := testFeed().RSS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := string(data)
+	for _, want := range []string{
+		`<rss version="2.0"`,
+		`<lastBuildDate>Wed, 03 Jan 2024 08:00:00 +0000</lastBuildDate>`,
+		`<guid isPermaLink="false">tag:example.com,2024-01-02:release/1</guid>`,
+		`<dc:creator>alice</dc:creator>`,
+		`<description>Fix &amp; improve</description>`,
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("rss feed is missing %s:\n%s", want, doc)
+		}
+	}
+	if err := xml.Unmarshal(data, new(struct{})); err != nil {
+		t.Errorf("rss feed is not well-formed: %v", err)
+	}
+}
+
+func TestFeedEmptyUpdated(t *testing.T) {
+	data, err := (&Feed{ID: "x", Title: "empty"}).Atom()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "<updated>1970-01-01T00:00:00Z</updated>") {
+		t.Errorf("empty feed should fall back to the epoch:\n%s", data)
+	}
+}
@@ -2,12 +2,14 @@ package utils
 
 import (
 	"archive/zip"
-	"bytes"
 	"crypto/sha256"
 	"encoding/hex"
 	"io"
+	"io/fs"
 	"mime/multipart"
 	"os"
+	"path/filepath"
+	"strings"
 )
 
 // IsValidZipFile 检查 multipart.FileHeader 是否为合法的 ZIP 文件
@@ -18,33 +20,27 @@ func IsValidZipFile(fileHeader *multipart.FileHeader) (bool, error) {
 		return false, err
 	}
 	defer file.Close()
+	return IsValidZip(file, fileHeader.Size)
+}
 
+// IsValidZip 检查可随机读取的内容是否为合法的 ZIP 文件，上传的文件和磁盘文件都可以使用
+// Check whether random access content is a valid ZIP file, works for uploads and files on disk alike
+func IsValidZip(file io.ReaderAt, size int64) (bool, error) {
 	// 读取文件的前 512 字节用于检测（ZIP 文件头通常在文件开头）
 	buffer := make([]byte, 512)
-	_, err = file.Read(buffer)
+	n, err := file.ReadAt(buffer, 0)
 	if err != nil && err != io.EOF {
 		return false, err
 	}
 
 	// 检查文件签名是否是 ZIP 文件
 	// ZIP 文件签名通常是 "PK\x03\x04" 或其他变体
-	if !isZipFileSignature(buffer) {
+	if !isZipFileSignature(buffer[:n]) {
 		return false, nil
 	}
 
-	// 重置文件读取位置，因为上面已经读取了一部分
-	if _, err = file.Seek(0, io.SeekStart); err != nil {
-		return false, err
-	}
-
 	// 使用 archive/zip 包尝试读取 ZIP 文件
-	fileBytes, err := io.ReadAll(file)
-	if err != nil {
-		return false, err
-	}
-
-	reader := bytes.NewReader(fileBytes)
-	zipReader, err := zip.NewReader(reader, fileHeader.Size)
+	zipReader, err := zip.NewReader(file, size)
 	if err != nil {
 		return false, nil // 不是有效的 ZIP 文件
 	}
@@ -81,18 +77,117 @@ func FileHash(filePath string) (string, error) {
 		return "", err
 	}
 	defer file.Close()
+	return ReaderHash(file)
+}
 
+// ReaderHash 计算读取内容的 sha256 哈希值并返回十六进制字符串
+// Compute the sha256 hash of the read content as a hex string
+func ReaderHash(r io.Reader) (string, error) {
 	// 创建哈希计算器
 	hash := sha256.New()
 
-	// 将文件内容拷贝到哈希计算器
-	if _, err := io.Copy(hash, file); err != nil {
+	// 将内容拷贝到哈希计算器
+	if _, err := io.Copy(hash, r); err != nil {
 		return "", err
 	}
 
 	// 计算哈希值并转换为十六进制字符串
-	hashInBytes := hash.Sum(nil)
-	hashString := hex.EncodeToString(hashInBytes)
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
 
-	return hashString, nil
+// RewriteZipWithout 复制 ZIP 内容并去除匹配的条目，返回去除的条目数
+// Copy ZIP content while dropping matching entries, returning the number of dropped entries
+func RewriteZipWithout(src io.ReaderAt, size int64, dst io.Writer, drop func(name string) bool) (removed int, err error) {
+	reader, err := zip.NewReader(src, size)
+	if err != nil {
+		return 0, err
+	}
+
+	writer := zip.NewWriter(dst)
+	for _, entry := range reader.File {
+		if drop(entry.Name) {
+			removed++
+			continue
+		}
+		// 直接复制原始压缩数据，无需重新压缩
+		// Copy the raw compressed data without recompressing
+		if err = writer.Copy(entry); err != nil {
+			return removed, err
+		}
+	}
+	err = writer.Close()
+	return removed, err
+}
+
+// ZipDir 将目录中的普通文件打包为 zip 并返回文件数，跳过符号链接和 skip 返回真的条目，跳过的目录不再进入
+// Pack the regular files of a directory into a zip and return the file count, skipping symlinks and entries skip reports true for, skipped directories are not entered
+func ZipDir(dir, target string, skip func(rel string, d fs.DirEntry) bool) (int, error) {
+	out, err := os.Create(target)
+	if err != nil {
+		return 0, err
+	}
+	defer out.Close()
+	writer := zip.NewWriter(out)
+	count := 0
+	err = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		if rel != "." && skip != nil && skip(filepath.ToSlash(rel), d) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !d.Type().IsRegular() {
+			return nil
+		}
+		entry, err := writer.Create(filepath.ToSlash(rel))
+		if err != nil {
+			return err
+		}
+		file, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(entry, file)
+		_ = file.Close()
+		count++
+		return err
+	})
+	if err != nil {
+		return count, err
+	}
+	if err := writer.Close(); err != nil {
+		return count, err
+	}
+	return count, out.Close()
+}
+
+// MatchSitePath 判断站点内的相对路径是否匹配模式，模式仅支持以 /* 结尾的前缀形式
+// Check whether a relative site path matches the pattern, only the trailing /* prefix form is supported
+func MatchSitePath(pattern, name string) bool {
+	pattern = strings.TrimPrefix(pattern, "/")
+	name = strings.TrimPrefix(name, "/")
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(name, prefix)
+	}
+	return name == pattern
+}
+
+// ValidSitePathPattern 校验路径模式：必须以 / 开头，通配符只能出现在末尾的 /* 中
+// Validate a path pattern: it must start with / and a wildcard may only appear as a trailing /*
+func ValidSitePathPattern(pattern string) bool {
+	if !strings.HasPrefix(pattern, "/") || strings.Contains(pattern, "..") {
+		return false
+	}
+	wildcards := strings.Count(pattern, "*")
+	if wildcards == 0 {
+		return pattern != "/"
+	}
+	return wildcards == 1 && strings.HasSuffix(pattern, "/*")
 }
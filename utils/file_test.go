@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"archive/zip"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestZipDir(t *testing.T) {
+	dir := t.TempDir()
+	for name, body := range map[string]string{
+		"index.html":       "<h1>hi</h1>",
+		"assets/app.css":   "body{}",
+		".git/HEAD":        "ref: refs/heads/main",
+		"assets/.DS_Store": "junk",
+	} {
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(target, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(filepath.Join(dir, "index.html"), filepath.Join(dir, "link.html")); err != nil {
+		t.Fatal(err)
+	}
+
+	target := filepath.Join(t.TempDir(), "site.zip")
+	count, err := ZipDir(dir, target, func(rel string, d fs.DirEntry) bool {
+		return rel == ".git" || d.Name() == ".DS_Store"
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("count = %d, want 2", count)
+	}
+	reader, err := zip.OpenReader(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	var names []string
+	for _, f := range reader.File {
+		names = append(names, f.Name)
+	}
+	slices.Sort(names)
+	if want := []string{"assets/app.css", "index.html"}; !slices.Equal(names, want) {
+		t.Errorf("entries = %v, want %v", names, want)
+	}
+
+	count, err = ZipDir(t.TempDir(), filepath.Join(t.TempDir(), "empty.zip"), nil)
+	if err != nil || count != 0 {
+		t.Errorf("empty dir: count = %d, err = %v", count, err)
+	}
+}
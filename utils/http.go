@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// ErrPrivateAddress 用户提供的地址解析到了内网地址
+// The user supplied address resolved to an internal address
+var ErrPrivateAddress = errors.New("address is not allowed")
+
+// publicClient 请求用户提供的地址，拒绝连接回环、内网、链路本地和未指定地址，重定向原样返回而不跟随
+// Fetch user supplied addresses, refusing loopback, private, link-local and unspecified addresses and returning redirects instead of following them
+var publicClient = &http.Client{
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: 10 * time.Second,
+			Control: func(network, address string, _ syscall.RawConn) error {
+				host, _, err := net.SplitHostPort(address)
+				if err != nil {
+					return err
+				}
+				ip := net.ParseIP(host)
+				if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+					return ErrPrivateAddress
+				}
+				return nil
+			},
+		}).DialContext,
+		MaxIdleConnsPerHost: 4,
+		IdleConnTimeout:     time.Minute,
+	},
+	// 重定向不跟随，避免绕过地址检查 Redirects are not followed so they cannot bypass the address check
+	CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
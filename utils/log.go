@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader 携带请求ID的请求头和响应头
+// Request and response header carrying the request ID
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength 接受的上游请求ID最大长度
+// Max length of a request ID accepted from upstream
+const maxRequestIDLength = 64
+
+type logType struct{}
+
+var Log = logType{}
+
+type requestIDKey struct{}
+
+// NewRequestID 生成随机的请求ID
+// Generate a random request ID
+func (logType) NewRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// ValidRequestID 判断上游传入的请求ID是否可以沿用，只允许字母、数字和 -_.:
+// Check whether a request ID passed from upstream can be kept, only letters, digits and -_.: are allowed
+func (logType) ValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// WithRequestID 将请求ID写入上下文
+// Store the request ID in the context
+func (logType) WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID 获取上下文中的请求ID，没有时为空
+// Get the request ID from the context, empty when there is none
+func (logType) RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// Ctx 返回带有请求ID字段的日志记录器，上下文中有被采样的 span 时同时带有 trace_id 字段
+// Return a logger carrying the request ID field, along with a trace_id field when the context carries a sampled span
+func (l logType) Ctx(ctx context.Context) *logrus.Entry {
+	entry := logrus.NewEntry(logrus.StandardLogger())
+	if id := l.RequestID(ctx); id != "" {
+		entry = entry.WithField("request_id", id)
+	}
+	if ctx != nil {
+		if span := trace.SpanContextFromContext(ctx); span.IsSampled() {
+			entry = entry.WithField("trace_id", span.TraceID().String())
+		}
+	}
+	return entry
+}
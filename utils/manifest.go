@@ -0,0 +1,156 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+)
+
+// SiteManifest 部署清单，列出发布中每个文件的路径和内容哈希，文件内容按哈希单独存储，未变化的文件在多次部署之间共用
+// Deployment manifest listing the path and content hash of every file of a release, contents are stored once per hash and shared by deployments
+type SiteManifest struct {
+	Files []ManifestFile `json:"files"`
+}
+
+// ManifestFile 清单中的一个文件
+// A file of a manifest
+type ManifestFile struct {
+	Path   string `json:"path"`   // 站点内的相对路径，例如 docs/index.html Relative path inside the site, e.g. docs/index.html
+	SHA256 string `json:"sha256"` // 内容的 SHA-256 十六进制值 Hex SHA-256 of the content
+	Size   int64  `json:"size"`   // 字节数 Size in bytes
+}
+
+// ValidSHA256 是否为小写十六进制 SHA-256
+// Whether the value is a lowercase hex SHA-256
+func ValidSHA256(value string) bool {
+	if len(value) != sha256.Size*2 || strings.ToLower(value) != value {
+		return false
+	}
+	_, err := hex.DecodeString(value)
+	return err == nil
+}
+
+// ValidManifestPath 路径必须是规范的相对路径，不能包含 . 或 .. 段
+// A path must be a clean relative path without . or .. segments
+func ValidManifestPath(p string) bool {
+	return p != "" && !strings.HasPrefix(p, "/") && !strings.HasSuffix(p, "/") && path.Clean(p) == p && p != "." &&
+		p != ".." && !strings.HasPrefix(p, "../")
+}
+
+// Validate 校验路径和哈希，同一路径只能出现一次
+// Validate paths and hashes, each path may appear only once
+func (m *SiteManifest) Validate() error {
+	if len(m.Files) == 0 {
+		return fmt.Errorf("manifest has no files")
+	}
+	seen := make(map[string]bool, len(m.Files))
+	for _, f := range m.Files {
+		if !ValidManifestPath(f.Path) {
+			return fmt.Errorf("invalid path %q", f.Path)
+		}
+		if !ValidSHA256(f.SHA256) {
+			return fmt.Errorf("invalid sha256 of %s", f.Path)
+		}
+		if f.Size < 0 {
+			return fmt.Errorf("invalid size of %s", f.Path)
+		}
+		if seen[f.Path] {
+			return fmt.Errorf("duplicate path %s", f.Path)
+		}
+		seen[f.Path] = true
+	}
+	return nil
+}
+
+// Hashes 清单引用的不重复的内容哈希
+// Distinct content hashes referenced by the manifest
+func (m *SiteManifest) Hashes() []string {
+	seen := make(map[string]bool, len(m.Files))
+	var hashes []string
+	for _, f := range m.Files {
+		if !seen[f.SHA256] {
+			seen[f.SHA256] = true
+			hashes = append(hashes, f.SHA256)
+		}
+	}
+	return hashes
+}
+
+// Encode 按路径排序后编码，相同的文件集合总是得到相同的字节，清单本身也可以按哈希去重
+// Encode sorted by path, so the same set of files always yields the same bytes and manifests dedupe by hash too
+func (m *SiteManifest) Encode() ([]byte, error) {
+	sort.Slice(m.Files, func(i, j int) bool { return m.Files[i].Path < m.Files[j].Path })
+	return json.Marshal(m)
+}
+
+// DecodeManifest 读取部署清单
+// Read a deployment manifest
+func DecodeManifest(r io.Reader) (*SiteManifest, error) {
+	manifest := &SiteManifest{}
+	if err := json.NewDecoder(r).Decode(manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// ParseChecksums 读取上传附带的文件哈希清单，可以是路径到 SHA-256 的 JSON 对象，也可以是 sha256sum 的输出
+// Read the file hash list given with an upload, either a JSON object mapping paths to SHA-256 or the output of sha256sum
+func ParseChecksums(text string) (map[string]string, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, nil
+	}
+	checksums := map[string]string{}
+	if strings.HasPrefix(text, "{") {
+		if err := json.Unmarshal([]byte(text), &checksums); err != nil {
+			return nil, fmt.Errorf("invalid checksums: %w", err)
+		}
+		return NormalizeChecksums(checksums)
+	}
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		// sha256sum 的每行为 "哈希  路径"，二进制模式下路径前带 * Each sha256sum line is "hash  path", binary mode prefixes the path with *
+		hash, name, ok := strings.Cut(line, " ")
+		if !ok {
+			return nil, fmt.Errorf("invalid checksums line %d", i+1)
+		}
+		name = strings.TrimPrefix(strings.TrimLeft(name, " "), "*")
+		if _, ok := checksums[name]; ok {
+			return nil, fmt.Errorf("duplicate path %s", name)
+		}
+		checksums[name] = hash
+	}
+	return NormalizeChecksums(checksums)
+}
+
+// NormalizeChecksums 去掉路径开头的 ./ 并将哈希转为小写，校验路径和哈希
+// Strip a leading ./ from the paths and lowercase the hashes, validating both
+func NormalizeChecksums(checksums map[string]string) (map[string]string, error) {
+	if len(checksums) == 0 {
+		return nil, nil
+	}
+	normalized := make(map[string]string, len(checksums))
+	for name, hash := range checksums {
+		name = strings.TrimPrefix(name, "./")
+		hash = strings.ToLower(hash)
+		if !ValidManifestPath(name) {
+			return nil, fmt.Errorf("invalid path %q", name)
+		}
+		if !ValidSHA256(hash) {
+			return nil, fmt.Errorf("invalid sha256 of %s", name)
+		}
+		if _, ok := normalized[name]; ok {
+			return nil, fmt.Errorf("duplicate path %s", name)
+		}
+		normalized[name] = hash
+	}
+	return normalized, nil
+}
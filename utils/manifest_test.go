@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestManifestValidate(t *testing.T) {
+	hash := strings.Repeat("ab", 32)
+	tests := []struct {
+		path string
+		ok   bool
+	}{
+		{"index.html", true},
+		{"docs/a.html", true},
+		{"/index.html", false},
+		{"docs/", false},
+		{"docs/../a.html", false},
+		{"./a.html", false},
+		{"..", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		m := SiteManifest{Files: []ManifestFile{{Path: tt.path, SHA256: hash, Size: 1}}}
+		if err := m.Validate(); (err == nil) != tt.ok {
+			t.Errorf("Validate(%q) = %v, want ok %v", tt.path, err, tt.ok)
+		}
+	}
+	dup := SiteManifest{Files: []ManifestFile{{Path: "a", SHA256: hash}, {Path: "a", SHA256: hash}}}
+	if dup.Validate() == nil {
+		t.Error("Expected duplicate paths to be rejected")
+	}
+	upper := SiteManifest{Files: []ManifestFile{{Path: "a", SHA256: strings.ToUpper(hash)}}}
+	if upper.Validate() == nil {
+		t.Error("Expected uppercase hashes to be rejected")
+	}
+}
+
+func TestManifestEncodeStable(t *testing.T) {
+	a := SiteManifest{Files: []ManifestFile{{Path: "b", SHA256: "2"}, {Path: "a", SHA256: "1"}}}
+	b := SiteManifest{Files: []ManifestFile{{Path: "a", SHA256: "1"}, {Path: "b", SHA256: "2"}}}
+	encodedA, _ := a.Encode()
+	encodedB, _ := b.Encode()
+	if !bytes.Equal(encodedA, encodedB) {
+		t.Errorf("Expected the same bytes, got %s and %s", encodedA, encodedB)
+	}
+	decoded, err := DecodeManifest(bytes.NewReader(encodedA))
+	if err != nil || len(decoded.Files) != 2 || decoded.Files[0].Path != "a" {
+		t.Errorf("Unexpected decoded manifest %+v, %v", decoded, err)
+	}
+	if hashes := a.Hashes(); len(hashes) != 2 {
+		t.Errorf("Expected 2 hashes, got %v", hashes)
+	}
+}
+
+func TestParseChecksums(t *testing.T) {
+	a, b := strings.Repeat("ab", 32), strings.Repeat("cd", 32)
+	want := map[string]string{"index.html": a, "assets/app.js": b}
+	inputs := []string{
+		`{"./index.html": "` + strings.ToUpper(a) + `", "assets/app.js": "` + b + `"}`,
+		a + "  ./index.html\n" + b + " *assets/app.js\n\n",
+	}
+	for _, input := range inputs {
+		got, err := ParseChecksums(input)
+		if err != nil {
+			t.Fatalf("ParseChecksums(%q): %v", input, err)
+		}
+		if len(got) != len(want) || got["index.html"] != want["index.html"] || got["assets/app.js"] != want["assets/app.js"] {
+			t.Errorf("ParseChecksums(%q) = %v, want %v", input, got, want)
+		}
+	}
+	if got, err := ParseChecksums("  "); got != nil || err != nil {
+		t.Errorf("Expected no checksums for empty input, got %v, %v", got, err)
+	}
+	for _, input := range []string{
+		a,
+		"xyz  index.html",
+		a + "  ../index.html",
+		a + "  index.html\n" + b + "  index.html",
+		`{"index.html": "` + a + `", "./index.html": "` + b + `"}`,
+		`{"index.html": 1}`,
+	} {
+		if _, err := ParseChecksums(input); err == nil {
+			t.Errorf("Expected ParseChecksums(%q) to fail", input)
+		}
+	}
+}
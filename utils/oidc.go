@@ -0,0 +1,330 @@
+package utils
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/models"
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+)
+
+type oidcType struct{}
+
+// OIDC 第三方登录提供方适配，支持 GitHub OAuth2、GitLab 和通用 OIDC
+// Third-party auth provider adapters, supporting GitHub OAuth2, GitLab and generic OIDC
+var OIDC = oidcType{}
+
+// discovered 已完成自动发现的 OIDC 提供方，按 issuer 缓存
+// OIDC providers that finished discovery, cached by issuer
+var discovered = struct {
+	sync.Mutex
+	providers map[string]*oidc.Provider
+}{providers: make(map[string]*oidc.Provider)}
+
+// ExternalIdentity 提供方返回的用户信息
+// User information returned by the provider
+type ExternalIdentity struct {
+	Subject       string   // 提供方内的唯一用户标识 Unique user identifier at the provider
+	Email         string   // 邮箱 Email
+	EmailVerified bool     // 邮箱是否已被提供方验证 Whether the provider verified the email
+	Name          string   // 显示名称 Display name
+	Username      string   // 用户名 Username
+	AvatarURL     string   // 头像 Avatar URL
+	Groups        []string // 所属组 Groups
+}
+
+// OIDCState 登录流程中保存在 cookie 里的签名状态
+// Signed state kept in a cookie during the login flow
+type OIDCState struct {
+	jwt.RegisteredClaims
+	State      string `json:"state"`        // 防 CSRF 的随机值 Random value against CSRF
+	Nonce      string `json:"nonce"`        // 防重放的随机值 Random value against replay
+	ProviderID uint   `json:"provider_id"`  // 登录提供方ID Auth provider ID
+	LinkUserID uint   `json:"link_user_id"` // 非 0 时为已登录用户关联身份 Link the identity to this signed-in user when not 0
+}
+
+// NewState 生成随机的 state 和 nonce
+// Generate random state and nonce values
+func (oidcType) NewState(providerID, linkUserID uint, ttl time.Duration) (*OIDCState, error) {
+	state, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	return &OIDCState{
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl))},
+		State:            state,
+		Nonce:            nonce,
+		ProviderID:       providerID,
+		LinkUserID:       linkUserID,
+	}, nil
+}
+
+// SignState 签名登录状态
+// Sign the login state
+func (oidcType) SignState(state *OIDCState) (string, error) {
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, state).SignedString([]byte(config.JwtSecret))
+}
+
+// ParseState 校验并解析登录状态
+// Verify and parse the login state
+func (oidcType) ParseState(signed string) (*OIDCState, error) {
+	state := &OIDCState{}
+	_, err := jwt.ParseWithClaims(signed, state, func(token *jwt.Token) (any, error) {
+		return []byte(config.JwtSecret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// AuthCodeURL 生成跳转到提供方的授权地址
+// Build the authorization URL that redirects to the provider
+func (oidcType) AuthCodeURL(ctx context.Context, provider *models.OIDCConfig, redirectURL string, state *OIDCState) (string, error) {
+	cfg, _, err := oauthConfig(ctx, provider, redirectURL)
+	if err != nil {
+		return "", err
+	}
+	var opts []oauth2.AuthCodeOption
+	if provider.Type != constants.AuthProviderGitHub {
+		opts = append(opts, oidc.Nonce(state.Nonce))
+	}
+	return cfg.AuthCodeURL(state.State, opts...), nil
+}
+
+// Exchange 使用授权码换取令牌并读取用户信息，OIDC 提供方会校验 ID Token 的签名和 nonce
+// Exchange the authorization code and read the user information, ID token signature and nonce are verified for OIDC providers
+func (oidcType) Exchange(ctx context.Context, provider *models.OIDCConfig, redirectURL, code string, state *OIDCState) (*ExternalIdentity, error) {
+	cfg, discoveredProvider, err := oauthConfig(ctx, provider, redirectURL)
+	if err != nil {
+		return nil, err
+	}
+	token, err := cfg.Exchange(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if provider.Type == constants.AuthProviderGitHub {
+		return githubIdentity(ctx, cfg.Client(ctx, token), githubAPI(provider))
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, errors.New("provider did not return an id_token")
+	}
+	idToken, err := discoveredProvider.Verifier(&oidc.Config{ClientID: provider.ClientID}).Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, err
+	}
+	if idToken.Nonce != state.Nonce {
+		return nil, errors.New("id_token nonce mismatch")
+	}
+	claims := map[string]any{}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, err
+	}
+	// 部分提供方只在 userinfo 中返回组和头像 Some providers only return groups and avatars from userinfo
+	if userInfo, err := discoveredProvider.UserInfo(ctx, oauth2.StaticTokenSource(token)); err == nil && userInfo.Subject == idToken.Subject {
+		extra := map[string]any{}
+		if err := userInfo.Claims(&extra); err == nil {
+			for key, value := range extra {
+				if _, exists := claims[key]; !exists {
+					claims[key] = value
+				}
+			}
+		}
+	}
+	groupsClaim := "groups"
+	if provider.GroupsClaim != nil && *provider.GroupsClaim != "" {
+		groupsClaim = *provider.GroupsClaim
+	}
+	identity := &ExternalIdentity{
+		Subject:       idToken.Subject,
+		Email:         stringClaim(claims, "email"),
+		EmailVerified: boolClaim(claims, "email_verified"),
+		Name:          stringClaim(claims, "name"),
+		Username:      stringClaim(claims, "preferred_username"),
+		AvatarURL:     stringClaim(claims, "picture"),
+		Groups:        stringsClaim(claims, groupsClaim),
+	}
+	if identity.Username == "" {
+		identity.Username = stringClaim(claims, "nickname")
+	}
+	return identity, nil
+}
+
+// MatchGroups 判断用户组是否匹配配置的组列表，* 匹配所有组
+// Check whether the user's groups match the configured list, * matches every group
+func (oidcType) MatchGroups(patterns, groups []string) bool {
+	for _, pattern := range patterns {
+		if pattern == "*" || slices.Contains(groups, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// oauthConfig 构建 OAuth2 配置，OIDC 提供方通过自动发现获取端点
+// Build the OAuth2 configuration, OIDC providers get their endpoints through discovery
+func oauthConfig(ctx context.Context, provider *models.OIDCConfig, redirectURL string) (*oauth2.Config, *oidc.Provider, error) {
+	cfg := &oauth2.Config{
+		ClientID:     provider.ClientID,
+		ClientSecret: provider.ClientSecret,
+		RedirectURL:  redirectURL,
+	}
+	if provider.Type == constants.AuthProviderGitHub {
+		base := strings.TrimSuffix(provider.BaseURL, "/")
+		if base == "" {
+			base = "https://github.com"
+		}
+		cfg.Endpoint = oauth2.Endpoint{AuthURL: base + "/login/oauth/authorize", TokenURL: base + "/login/oauth/access_token"}
+		cfg.Scopes = []string{"read:user", "user:email"}
+		return cfg, nil, nil
+	}
+	discoveredProvider, err := discover(ctx, issuer(provider))
+	if err != nil {
+		return nil, nil, err
+	}
+	cfg.Endpoint = discoveredProvider.Endpoint()
+	cfg.Scopes = []string{oidc.ScopeOpenID, "profile", "email"}
+	return cfg, discoveredProvider, nil
+}
+
+// issuer 提供方的 issuer 地址
+// Issuer URL of the provider
+func issuer(provider *models.OIDCConfig) string {
+	if provider.Type == constants.AuthProviderGitLab {
+		if provider.BaseURL != "" {
+			return strings.TrimSuffix(provider.BaseURL, "/")
+		}
+		return "https://gitlab.com"
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(provider.OidcDiscoveryURL, "/"), "/.well-known/openid-configuration")
+}
+
+func discover(ctx context.Context, issuer string) (*oidc.Provider, error) {
+	discovered.Lock()
+	defer discovered.Unlock()
+	if provider, ok := discovered.providers[issuer]; ok {
+		return provider, nil
+	}
+	// 缓存的提供方会在之后的请求中复用，不能绑定当前请求的 context
+	// The cached provider is reused by later requests and must not be bound to this request's context
+	discoverCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+	provider, err := oidc.NewProvider(discoverCtx, issuer)
+	if err != nil {
+		return nil, err
+	}
+	discovered.providers[issuer] = provider
+	return provider, nil
+}
+
+func githubAPI(provider *models.OIDCConfig) string {
+	if provider.BaseURL == "" {
+		return "https://api.github.com"
+	}
+	return strings.TrimSuffix(provider.BaseURL, "/") + "/api/v3"
+}
+
+// githubIdentity 通过 GitHub API 读取用户和已验证的主邮箱
+// Read the user and the verified primary email through the GitHub API
+func githubIdentity(ctx context.Context, client *http.Client, api string) (*ExternalIdentity, error) {
+	var user struct {
+		ID        int64  `json:"id"`
+		Login     string `json:"login"`
+		Name      string `json:"name"`
+		AvatarURL string `json:"avatar_url"`
+	}
+	if err := getJSON(ctx, client, api+"/user", &user); err != nil {
+		return nil, err
+	}
+	identity := &ExternalIdentity{
+		Subject:   strconv.FormatInt(user.ID, 10),
+		Name:      user.Name,
+		Username:  user.Login,
+		AvatarURL: user.AvatarURL,
+	}
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := getJSON(ctx, client, api+"/user/emails", &emails); err == nil {
+		for _, email := range emails {
+			if email.Primary {
+				identity.Email = email.Email
+				identity.EmailVerified = email.Verified
+			}
+		}
+	}
+	return identity, nil
+}
+
+func getJSON(ctx context.Context, client *http.Client, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s", resp.StatusCode, url)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func stringClaim(claims map[string]any, key string) string {
+	value, _ := claims[key].(string)
+	return value
+}
+
+func boolClaim(claims map[string]any, key string) bool {
+	switch value := claims[key].(type) {
+	case bool:
+		return value
+	case string:
+		return value == "true"
+	}
+	return false
+}
+
+func stringsClaim(claims map[string]any, key string) []string {
+	values, _ := claims[key].([]any)
+	groups := make([]string, 0, len(values))
+	for _, value := range values {
+		if group, ok := value.(string); ok {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+func randomToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
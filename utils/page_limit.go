@@ -1,16 +1,31 @@
 package utils
 
 import (
+	"fmt"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/LiteyukiStudio/spage/config"
 	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol"
 )
 
 type ctxType struct{}
 
 var Ctx = ctxType{}
 
+// TotalCountHeader 列表接口返回总数的响应头 Response header carrying the total count of list endpoints
+const TotalCountHeader = "X-Total-Count"
+
+// ListQuery 列表接口的分页和排序参数
+// Pagination and sorting parameters of list endpoints
+type ListQuery struct {
+	Page  int    // 页码，从 1 开始 Page number, starting from 1
+	Limit int    // 每页数量 Items per page
+	Order string // 排序子句，例如 "created_at DESC, id DESC" Order clause, e.g. "created_at DESC, id DESC"
+}
+
 // GetPageLimit 封装从上下文中的query获取查询参数并转换为整数的函数，出错返回默认值
 // packing from the query in the context and converting it to an integer, returning the default value on error
 func (ctxType) GetPageLimit(c *app.RequestContext) (page, limit int) {
@@ -27,3 +42,62 @@ func (ctxType) GetPageLimit(c *app.RequestContext) (page, limit int) {
 	}
 	return
 }
+
+// GetListQuery 获取分页参数和 sort 参数，sort 为 sortable 中的字段名，前缀 - 表示降序，为空时使用 defaultSort
+// Get the pagination parameters and the sort parameter, sort is a field name in sortable with a leading - for descending order, defaultSort is used when empty
+func (x ctxType) GetListQuery(c *app.RequestContext, defaultSort string, sortable ...string) (ListQuery, error) {
+	query := ListQuery{}
+	query.Page, query.Limit = x.GetPageLimit(c)
+	order, err := SortOrder(c.Query("sort"), defaultSort, sortable...)
+	if err != nil {
+		return query, err
+	}
+	query.Order = order
+	return query, nil
+}
+
+// SortOrder 将 sort 参数转换为排序子句，字段只能取自 sortable，并按 id 排序保证分页稳定
+// Convert a sort parameter to an order clause, fields can only come from sortable and id breaks ties to keep pages stable
+func SortOrder(sort, defaultSort string, sortable ...string) (string, error) {
+	if sort == "" {
+		sort = defaultSort
+	}
+	field, desc := strings.CutPrefix(sort, "-")
+	if !slices.Contains(sortable, field) {
+		return "", fmt.Errorf("invalid sort field %q, allowed values: %s", field, strings.Join(sortable, ", "))
+	}
+	direction := "ASC"
+	if desc {
+		direction = "DESC"
+	}
+	if field == "id" {
+		return "id " + direction, nil
+	}
+	return field + " " + direction + ", id " + direction, nil
+}
+
+// SetPageHeaders 设置总数响应头和指向第一页、上一页、下一页、最后一页的 Link 响应头
+// Set the total count header and the Link header pointing to the first, previous, next and last pages
+func (ctxType) SetPageHeaders(c *app.RequestContext, page, limit int, total int64) {
+	c.Response.Header.Set(TotalCountHeader, strconv.FormatInt(total, 10))
+	if limit <= 0 {
+		return
+	}
+	last := max(int((total+int64(limit)-1)/int64(limit)), 1)
+	link := func(p int, rel string) string {
+		args := &protocol.Args{}
+		c.QueryArgs().CopyTo(args)
+		args.Set("page", strconv.Itoa(p))
+		args.Set("limit", strconv.Itoa(limit))
+		return fmt.Sprintf("<%s?%s>; rel=\"%s\"", c.URI().Path(), args.QueryString(), rel)
+	}
+	links := []string{link(1, "first")}
+	if page > 1 {
+		links = append(links, link(min(page-1, last), "prev"))
+	}
+	if page < last {
+		links = append(links, link(page+1, "next"))
+	}
+	links = append(links, link(last, "last"))
+	c.Response.Header.Set("Link", strings.Join(links, ", "))
+}
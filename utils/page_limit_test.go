@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// TestSortOrder 测试 sort 参数的解析和白名单
+// Test parsing and whitelisting of the sort parameter
+func TestSortOrder(t *testing.T) {
+	cases := map[string]string{
+		"":            "id DESC",
+		"id":          "id ASC",
+		"-created_at": "created_at DESC, id DESC",
+		"name":        "name ASC, id ASC",
+	}
+	for sort, expected := range cases {
+		order, err := SortOrder(sort, "-id", "id", "created_at", "name")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if order != expected {
+			t.Errorf("Expected %q for %q, got %q", expected, sort, order)
+		}
+	}
+	for _, sort := range []string{"password", "name; DROP TABLE users", "--id"} {
+		if _, err := SortOrder(sort, "-id", "id", "name"); err == nil {
+			t.Errorf("Expected %q to be rejected", sort)
+		}
+	}
+}
+
+// TestSetPageHeaders 测试总数和 Link 响应头
+// Test the total count and Link headers
+func TestSetPageHeaders(t *testing.T) {
+	c := app.NewContext(0)
+	c.Request.SetRequestURI("/api/v1/admin/audit-logs?action=login&page=2&limit=10")
+	Ctx.SetPageHeaders(c, 2, 10, 35)
+	if total := string(c.Response.Header.Peek(TotalCountHeader)); total != "35" {
+		t.Errorf("Expected total 35, got %s", total)
+	}
+	expected := `</api/v1/admin/audit-logs?action=login&page=1&limit=10>; rel="first", ` +
+		`</api/v1/admin/audit-logs?action=login&page=1&limit=10>; rel="prev", ` +
+		`</api/v1/admin/audit-logs?action=login&page=3&limit=10>; rel="next", ` +
+		`</api/v1/admin/audit-logs?action=login&page=4&limit=10>; rel="last"`
+	if link := string(c.Response.Header.Peek("Link")); link != expected {
+		t.Errorf("Unexpected Link header %s", link)
+	}
+
+	c = app.NewContext(0)
+	c.Request.SetRequestURI("/api/v1/user/1/projects")
+	Ctx.SetPageHeaders(c, 1, 10, 0)
+	if link := string(c.Response.Header.Peek("Link")); link != `</api/v1/user/1/projects?page=1&limit=10>; rel="first", </api/v1/user/1/projects?page=1&limit=10>; rel="last"` {
+		t.Errorf("Unexpected Link header for an empty list %s", link)
+	}
+}
@@ -1,46 +1,84 @@
 package utils
 
 import (
+	"crypto/rand"
 	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
 	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
 	"unicode"
 
+	"github.com/LiteyukiStudio/spage/config"
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// PasswordHasher 一种密码哈希算法，编码后的哈希以 $<名称>$ 开头
+// A password hashing algorithm, encoded hashes start with $<name>$
+type PasswordHasher interface {
+	// Name 算法名称，也是哈希的前缀 Name of the algorithm, also the prefix of its hashes
+	Name() string
+	// Hash 使用当前参数计算哈希 Compute a hash with the current parameters
+	Hash(password string) (string, error)
+	// Verify 校验密码是否与哈希一致 Check whether the password matches the hash
+	Verify(password, encoded string) bool
+	// Outdated 哈希的参数是否与当前参数不同 Whether the parameters of the hash differ from the current ones
+	Outdated(encoded string) bool
+}
+
 type PasswordType struct {
+	hashers map[string]PasswordHasher
 }
 
-var Password = PasswordType{}
+var Password = PasswordType{hashers: map[string]PasswordHasher{}}
 
-// HashPassword 密码哈希函数
-// hash password function
-func (u *PasswordType) HashPassword(password string, salt string) (string, error) {
-	saltedPassword := Password.addSalt(password, salt)
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(saltedPassword), bcrypt.DefaultCost)
-	if err != nil {
-		return "", err
+func init() {
+	Password.Register(argon2idHasher{})
+	Password.Register(bcryptHasher{})
+}
+
+// Register 注册密码哈希算法，password.algorithm 可选择已注册的名称
+// Register a password hashing algorithm, password.algorithm may select any registered name
+func (u *PasswordType) Register(hasher PasswordHasher) {
+	u.hashers[hasher.Name()] = hasher
+}
+
+// current 当前配置的哈希算法，未知的名称使用 argon2id
+// The configured hashing algorithm, unknown names use argon2id
+func (u *PasswordType) current() PasswordHasher {
+	if hasher, ok := u.hashers[config.PasswordAlgorithm]; ok {
+		return hasher
 	}
-	return string(hashedPassword), nil
+	return u.hashers["argon2id"]
 }
 
-// VerifyPassword 验证密码
-// verify password
+// HashPassword 使用配置的算法计算密码哈希
+// Hash a password with the configured algorithm
+func (u *PasswordType) HashPassword(password string) (string, error) {
+	return u.current().Hash(password)
+}
 
-func (u *PasswordType) VerifyPassword(password, hashedPassword string, salt string) bool {
-	saltedPassword := Password.addSalt(password, salt)
-	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(saltedPassword))
-	return err == nil
+// VerifyPassword 验证密码，rehash 表示哈希的算法或参数已过时，应在验证通过后以 HashPassword 重新计算并保存
+// Verify a password, rehash reports that the algorithm or parameters of the hash are outdated and it should be recomputed with HashPassword and saved after a successful verification
+func (u *PasswordType) VerifyPassword(password, hashedPassword string) (ok, rehash bool) {
+	name, _, found := strings.Cut(strings.TrimPrefix(hashedPassword, "$"), "$")
+	hasher, registered := u.hashers[name]
+	if !found || !registered {
+		// 没有算法前缀的是旧版本加盐的 bcrypt 哈希 Hashes without an algorithm prefix are salted bcrypt hashes of older versions
+		err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(u.addSalt(password, config.PasswordLegacySecret)))
+		return err == nil, err == nil
+	}
+	if !hasher.Verify(password, hashedPassword) {
+		return false, false
+	}
+	return true, hasher != u.current() || hasher.Outdated(hashedPassword)
 }
 
-// addSalt 加盐函数
-// password: 原始密码
-// salt: 盐值
-// 返回值: 加盐后的密码
-// Add salt function
-// password: original password
-// salt: salt value
-// return value: salted password
+// addSalt 旧版本的加盐函数，将密码和 token.secret 拼接后取 SHA-256，只用于验证旧哈希
+// Salting of older versions, the SHA-256 of the password joined with token.secret, only used to verify old hashes
 func (u *PasswordType) addSalt(password string, salt string) string {
 	combined := password + salt
 	hash := sha256.New()
@@ -48,6 +86,98 @@ func (u *PasswordType) addSalt(password string, salt string) string {
 	return hex.EncodeToString(hash.Sum(nil))
 }
 
+// argon2idHasher 格式为 $argon2id$v=19$m=65536,t=3,p=2$<盐>$<哈希>，与 PHC 字符串格式一致
+// Formatted as $argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>, following the PHC string format
+type argon2idHasher struct{}
+
+const (
+	argon2SaltLen = 16
+	argon2KeyLen  = 32
+)
+
+func (argon2idHasher) Name() string {
+	return "argon2id"
+}
+
+func (argon2idHasher) params() (memory, time uint32, threads uint8) {
+	return uint32(max(config.PasswordArgon2Memory, 8)), uint32(max(config.PasswordArgon2Time, 1)), uint8(min(max(config.PasswordArgon2Threads, 1), 255))
+}
+
+func (h argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, argon2SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	memory, time, threads := h.params()
+	key := argon2.IDKey([]byte(password), salt, time, memory, threads, argon2KeyLen)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, memory, time, threads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// decode 解析哈希中的参数、盐和密钥
+// Parse the parameters, salt and key of a hash
+func (argon2idHasher) decode(encoded string) (memory, time uint32, threads uint8, salt, key []byte, err error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[2] != fmt.Sprintf("v=%d", argon2.Version) {
+		return 0, 0, 0, nil, nil, errors.New("invalid argon2id hash")
+	}
+	if _, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads); err != nil {
+		return
+	}
+	if salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return
+	}
+	key, err = base64.RawStdEncoding.DecodeString(parts[5])
+	return
+}
+
+func (h argon2idHasher) Verify(password, encoded string) bool {
+	memory, time, threads, salt, key, err := h.decode(encoded)
+	if err != nil || len(key) == 0 {
+		return false
+	}
+	computed := argon2.IDKey([]byte(password), salt, time, memory, threads, uint32(len(key)))
+	return subtle.ConstantTimeCompare(computed, key) == 1
+}
+
+func (h argon2idHasher) Outdated(encoded string) bool {
+	memory, time, threads, _, key, err := h.decode(encoded)
+	wantMemory, wantTime, wantThreads := h.params()
+	return err != nil || memory != wantMemory || time != wantTime || threads != wantThreads || len(key) != argon2KeyLen
+}
+
+// bcryptHasher 格式为 $bcrypt$<bcrypt 哈希>，密码先取 SHA-256 以避开 bcrypt 72 字节的长度限制
+// Formatted as $bcrypt$<bcrypt hash>, the password is reduced by SHA-256 first to avoid the 72 byte limit of bcrypt
+type bcryptHasher struct{}
+
+const bcryptPrefix = "$bcrypt"
+
+func (bcryptHasher) Name() string {
+	return "bcrypt"
+}
+
+func (bcryptHasher) prehash(password string) []byte {
+	sum := sha256.Sum256([]byte(password))
+	return []byte(hex.EncodeToString(sum[:]))
+}
+
+func (h bcryptHasher) Hash(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword(h.prehash(password), min(max(config.PasswordBcryptCost, bcrypt.MinCost), bcrypt.MaxCost))
+	if err != nil {
+		return "", err
+	}
+	return bcryptPrefix + string(hashed), nil
+}
+
+func (h bcryptHasher) Verify(password, encoded string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(strings.TrimPrefix(encoded, bcryptPrefix)), h.prehash(password)) == nil
+}
+
+func (bcryptHasher) Outdated(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(strings.TrimPrefix(encoded, bcryptPrefix)))
+	return err != nil || cost != min(max(config.PasswordBcryptCost, bcrypt.MinCost), bcrypt.MaxCost)
+}
+
 // CheckPasswordComplexity 根据指定级别检查密码复杂度
 // password: 待检查的密码
 // level: 复杂度级别(1-4)
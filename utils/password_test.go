@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// TestPasswordHashers 测试各算法的哈希前缀、验证以及参数或算法改变后要求重新计算
+// Test the hash prefix and verification of each algorithm and that changed parameters or algorithms ask for a rehash
+func TestPasswordHashers(t *testing.T) {
+	defer func(algorithm string, memory, cost int) {
+		config.PasswordAlgorithm, config.PasswordArgon2Memory, config.PasswordBcryptCost = algorithm, memory, cost
+	}(config.PasswordAlgorithm, config.PasswordArgon2Memory, config.PasswordBcryptCost)
+	config.PasswordArgon2Memory, config.PasswordBcryptCost = 1024, bcrypt.MinCost
+
+	for _, algorithm := range []string{"argon2id", "bcrypt"} {
+		config.PasswordAlgorithm = algorithm
+		hashed, err := Password.HashPassword("correct horse")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(hashed, "$"+algorithm+"$") {
+			t.Errorf("Expected the %s hash to start with its name, got %s", algorithm, hashed)
+		}
+		if ok, rehash := Password.VerifyPassword("correct horse", hashed); !ok || rehash {
+			t.Errorf("Expected the %s hash to verify without a rehash, got ok %v rehash %v", algorithm, ok, rehash)
+		}
+		if ok, _ := Password.VerifyPassword("wrong horse", hashed); ok {
+			t.Errorf("Expected a wrong password to fail against the %s hash", algorithm)
+		}
+	}
+
+	config.PasswordAlgorithm = "argon2id"
+	hashed, _ := Password.HashPassword("correct horse")
+	config.PasswordArgon2Memory = 2048
+	if ok, rehash := Password.VerifyPassword("correct horse", hashed); !ok || !rehash {
+		t.Error("Expected changed argon2id parameters to ask for a rehash")
+	}
+	config.PasswordAlgorithm = "bcrypt"
+	if ok, rehash := Password.VerifyPassword("correct horse", hashed); !ok || !rehash {
+		t.Error("Expected a changed algorithm to ask for a rehash")
+	}
+}
+
+// TestLegacyPassword 测试旧版本以 token.secret 加盐的 bcrypt 哈希仍可验证并要求重新计算
+// Test that old bcrypt hashes salted with token.secret still verify and ask for a rehash
+func TestLegacyPassword(t *testing.T) {
+	defer func(secret string) { config.PasswordLegacySecret = secret }(config.PasswordLegacySecret)
+	config.PasswordLegacySecret = "old-secret"
+	legacy, err := bcrypt.GenerateFromPassword([]byte(Password.addSalt("correct horse", "old-secret")), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, rehash := Password.VerifyPassword("correct horse", string(legacy)); !ok || !rehash {
+		t.Errorf("Expected the legacy hash to verify and ask for a rehash, got ok %v rehash %v", ok, rehash)
+	}
+	config.PasswordLegacySecret = "rotated"
+	if ok, _ := Password.VerifyPassword("correct horse", string(legacy)); ok {
+		t.Error("Expected the legacy hash to need its original secret")
+	}
+}
@@ -0,0 +1,233 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/jpeg" // 支持 JPEG 头像和模板 Support JPEG avatars and templates
+	"image/png"
+	"io"
+	"net/http"
+	"strings"
+
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	PreviewWidth  = 1200 // 预览图宽度 Preview image width
+	PreviewHeight = 630  // 预览图高度 Preview image height
+
+	previewMaxImageSide = 4096 // 头像和模板允许的最大边长 Max side length of avatars and templates
+	previewPadding      = 72   // 预览图内边距 Preview image padding
+	previewAvatarSize   = 160  // 头像尺寸 Avatar size
+)
+
+type previewType struct{}
+
+var Preview = previewType{}
+
+// PreviewCard 预览卡片的输入
+// Inputs of a preview card
+type PreviewCard struct {
+	Title       string      // 标题，通常为站点名称 Title, usually the site name
+	Subtitle    string      // 副标题，通常为项目名称 Subtitle, usually the project name
+	Description string      // 描述 Description
+	Brand       string      // 实例品牌 Instance branding
+	Avatar      image.Image // 所有者头像，可为空 Owner avatar, optional
+	Background  image.Image // 自定义模板背景，可为空 Custom template background, optional
+}
+
+// Hash 根据卡片输入计算摘要，用于判断是否需要重新生成
+// Compute a digest of the card inputs to decide whether regeneration is needed
+func (previewType) Hash(parts ...string) string {
+	hash := sha256.New()
+	for _, part := range parts {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Render 渲染 PNG 预览图，画布尺寸固定以保证时间和大小有界
+// Render the PNG preview image, the canvas size is fixed to keep time and size bounded
+func (previewType) Render(ctx context.Context, card *PreviewCard) ([]byte, error) {
+	titleFace, err := newFace(gobold.TTF, 64)
+	if err != nil {
+		return nil, err
+	}
+	textFace, err := newFace(goregular.TTF, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	canvas := image.NewRGBA(image.Rect(0, 0, PreviewWidth, PreviewHeight))
+	if card.Background != nil {
+		xdraw.CatmullRom.Scale(canvas, canvas.Bounds(), card.Background, card.Background.Bounds(), draw.Src, nil)
+	} else {
+		draw.Draw(canvas, canvas.Bounds(), image.NewUniform(color.RGBA{R: 0x1f, G: 0x29, B: 0x37, A: 0xff}), image.Point{}, draw.Src)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	textLeft := previewPadding
+	if card.Avatar != nil {
+		avatarRect := image.Rect(previewPadding, previewPadding, previewPadding+previewAvatarSize, previewPadding+previewAvatarSize)
+		xdraw.CatmullRom.Scale(canvas, avatarRect, card.Avatar, card.Avatar.Bounds(), draw.Over, nil)
+		textLeft += previewAvatarSize + 40
+	}
+
+	white := image.NewUniform(color.White)
+	grey := image.NewUniform(color.RGBA{R: 0xcb, G: 0xd5, B: 0xe1, A: 0xff})
+	maxWidth := PreviewWidth - textLeft - previewPadding
+
+	y := previewPadding + 64
+	for _, line := range wrapText(titleFace, card.Title, maxWidth, 2) {
+		drawText(canvas, titleFace, white, textLeft, y, line)
+		y += 76
+	}
+	if card.Subtitle != "" {
+		drawText(canvas, textFace, grey, textLeft, y, truncateText(textFace, card.Subtitle, maxWidth))
+		y += 48
+	}
+	y += 24
+	for _, line := range wrapText(textFace, card.Description, PreviewWidth-2*previewPadding, 4) {
+		drawText(canvas, textFace, white, previewPadding, y, line)
+		y += 44
+	}
+	if card.Brand != "" {
+		drawText(canvas, textFace, grey, previewPadding, PreviewHeight-previewPadding, truncateText(textFace, card.Brand, maxWidth))
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	if err := png.Encode(buf, canvas); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// FetchImage 在超时和大小限制内下载并解码图片，只接受 http 和 https 地址，不连接内网地址也不跟随重定向
+// Download and decode an image within a timeout and size limit, only http and https URLs are accepted,
+// internal addresses are never dialed and redirects are not followed
+func (previewType) FetchImage(ctx context.Context, url string, maxBytes int64) (image.Image, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported scheme %q", req.URL.Scheme)
+	}
+	resp, err := publicClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return decodeBoundedImage(io.LimitReader(resp.Body, maxBytes+1), maxBytes)
+}
+
+// LoadImage 读取已保存的图片，拒绝过大的文件和尺寸
+// Load a stored image, rejecting oversized files and dimensions
+func (previewType) LoadImage(r io.Reader, maxBytes int64) (image.Image, error) {
+	return decodeBoundedImage(io.LimitReader(r, maxBytes+1), maxBytes)
+}
+
+// ValidateImage 校验上传的图片是否可解码且尺寸合法
+// Validate that an uploaded image is decodable and within the allowed dimensions
+func (previewType) ValidateImage(r io.Reader, maxBytes int64) error {
+	_, err := decodeBoundedImage(io.LimitReader(r, maxBytes+1), maxBytes)
+	return err
+}
+
+func decodeBoundedImage(r io.Reader, maxBytes int64) (image.Image, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, errors.New("image is too large")
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Width > previewMaxImageSide || cfg.Height > previewMaxImageSide {
+		return nil, errors.New("image dimensions are too large")
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, err
+}
+
+func newFace(ttf []byte, size float64) (font.Face, error) {
+	parsed, err := opentype.Parse(ttf)
+	if err != nil {
+		return nil, err
+	}
+	return opentype.NewFace(parsed, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+}
+
+func drawText(dst draw.Image, face font.Face, src image.Image, x, y int, text string) {
+	drawer := &font.Drawer{Dst: dst, Src: src, Face: face, Dot: fixed.P(x, y)}
+	drawer.DrawString(text)
+}
+
+// wrapText 按宽度换行，超出最大行数时截断并追加省略号
+// Wrap text by width, truncating with an ellipsis when exceeding the max line count
+func wrapText(face font.Face, text string, maxWidth, maxLines int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		candidate := strings.TrimSpace(line + " " + word)
+		if font.MeasureString(face, candidate).Ceil() <= maxWidth || line == "" {
+			line = candidate
+			continue
+		}
+		lines = append(lines, line)
+		line = word
+		if len(lines) == maxLines {
+			break
+		}
+	}
+	if line != "" && len(lines) < maxLines {
+		lines = append(lines, line)
+	} else if len(lines) == maxLines {
+		lines[maxLines-1] = truncateText(face, lines[maxLines-1]+" …", maxWidth)
+	}
+	for i := range lines {
+		lines[i] = truncateText(face, lines[i], maxWidth)
+	}
+	return lines
+}
+
+// truncateText 截断超宽文本并追加省略号
+// Truncate text that is too wide and append an ellipsis
+func truncateText(face font.Face, text string, maxWidth int) string {
+	if font.MeasureString(face, text).Ceil() <= maxWidth {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		candidate := string(runes) + "…"
+		if font.MeasureString(face, candidate).Ceil() <= maxWidth {
+			return candidate
+		}
+	}
+	return ""
+}
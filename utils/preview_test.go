@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestFetchImageRefusesInternal 头像地址不能指向本机或内网，也不能使用 http 和 https 之外的协议
+// Avatar URLs may not point at the local host or internal network, nor use schemes other than http and https
+func TestFetchImageRefusesInternal(t *testing.T) {
+	requested := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = true
+	}))
+	defer server.Close()
+
+	if _, err := Preview.FetchImage(context.Background(), server.URL+"/avatar.png", 1<<20); !errors.Is(err, ErrPrivateAddress) {
+		t.Errorf("loopback FetchImage = %v, want ErrPrivateAddress", err)
+	}
+	if requested {
+		t.Error("the loopback server was requested")
+	}
+	for _, url := range []string{"file:///etc/passwd", "ftp://example.com/avatar.png"} {
+		if _, err := Preview.FetchImage(context.Background(), url, 1<<20); err == nil {
+			t.Errorf("FetchImage(%s) succeeded", url)
+		}
+	}
+}
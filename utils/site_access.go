@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// 受保护站点的访问凭据类型 Kinds of access credentials of protected sites
+const (
+	SiteAccessTicket   = "ticket"   // 面板签发的一次性跳转凭据，在站点域名下换取登录状态 Short-lived credential issued by the panel, exchanged for a session on the site's host
+	SiteAccessSession  = "session"  // 保存在站点域名 Cookie 中的登录状态 Session kept in a cookie of the site's host
+	SiteAccessInstance = "instance" // 私有实例下保存在站点域名 Cookie 中的实例登录状态 Instance session kept in a cookie of the site's host on private instances
+	SiteAccessFile     = "file"     // 签名链接中只能访问一个路径的临时凭据 Temporary credential of a signed URL reaching a single path
+)
+
+// SiteAccessClaims 受保护站点的访问凭据
+// Access credential of a protected site
+type SiteAccessClaims struct {
+	jwt.RegisteredClaims
+	Kind        string `json:"kind"`             // 凭据类型 Credential kind
+	SiteID      uint   `json:"site_id"`          // 站点ID Site ID
+	Viewer      uint   `json:"viewer,omitempty"` // 成员模式下的访问者用户ID User ID of the visitor under the members mode
+	Fingerprint string `json:"fp"`               // 站点访问设置的摘要，设置变化后凭据失效 Digest of the site's access settings, credentials expire once they change
+	Path        string `json:"path,omitempty"`   // 签名链接可以访问的路径 Path a signed URL may reach
+}
+
+type siteAccessType struct{}
+
+var SiteAccess = siteAccessType{}
+
+// key 签名密钥，与登录令牌的密钥不同，访问凭据不能被当作登录令牌使用
+// Signing key, different from the one of login tokens so access credentials cannot pass as login tokens
+func (siteAccessType) key() []byte {
+	return []byte("site-access\n" + config.JwtSecret)
+}
+
+// Fingerprint 站点访问设置的摘要，密码哈希变化或切换模式后不同
+// Digest of the site's access settings, differing once the password hash or the mode changes
+func (siteAccessType) Fingerprint(mode, passwordHash string) string {
+	sum := sha256.Sum256([]byte(mode + "\n" + passwordHash))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Sign 签发访问凭据
+// Issue an access credential
+func (s siteAccessType) Sign(kind string, siteID, viewer uint, fingerprint string, ttl time.Duration) (string, error) {
+	claims := SiteAccessClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+		},
+		Kind:        kind,
+		SiteID:      siteID,
+		Viewer:      viewer,
+		Fingerprint: fingerprint,
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.key())
+}
+
+// SignFile 签发签名链接的凭据，只能访问站点的 path
+// Issue the credential of a signed URL, reaching only path of the site
+func (s siteAccessType) SignFile(siteID, viewer uint, fingerprint, path string, ttl time.Duration) (string, error) {
+	claims := SiteAccessClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+		},
+		Kind:        SiteAccessFile,
+		SiteID:      siteID,
+		Viewer:      viewer,
+		Fingerprint: fingerprint,
+		Path:        path,
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.key())
+}
+
+// Parse 校验并解析指定类型的访问凭据
+// Verify and parse an access credential of the given kind
+func (s siteAccessType) Parse(signed, kind string) (*SiteAccessClaims, error) {
+	claims := &SiteAccessClaims{}
+	_, err := jwt.ParseWithClaims(signed, claims, func(token *jwt.Token) (any, error) {
+		return s.key(), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, err
+	}
+	if claims.Kind != kind {
+		return nil, errors.New("unexpected access credential kind")
+	}
+	return claims, nil
+}
@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"archive/zip"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+type demoType struct{}
+
+// Demo 生成演示和压测用的静态站点
+// Generate static sites for demos and load testing
+var Demo = demoType{}
+
+// DemoSite 演示站点的内容
+// Content of a demo site
+type DemoSite struct {
+	Title       string   // 站点标题 Site title
+	Description string   // 站点简介 Site description
+	Author      string   // 作者 Author
+	Version     string   // 版本，显示在页脚，使各版本内容不同 Version shown in the footer so every version differs
+	Posts       []string // 文章标题 Post titles
+}
+
+// demoStyle 演示站点共用的样式表 Stylesheet shared by demo sites
+const demoStyle = `body{font-family:system-ui,sans-serif;max-width:46rem;margin:2rem auto;padding:0 1rem;line-height:1.6;color:#222}
+header,footer{color:#666;font-size:.9rem}
+a{color:#0b63ce}
+article{margin:1.5rem 0}
+`
+
+// Write 将演示站点写入 zip：首页、关于页、404 页、每篇文章一个页面以及样式表
+// Write a demo site as a zip: the index, an about page, a 404 page, one page per post and a stylesheet
+func (demoType) Write(dst io.Writer, site DemoSite) error {
+	archive := zip.NewWriter(dst)
+	var index strings.Builder
+	index.WriteString("<p>" + html.EscapeString(site.Description) + "</p>\n<ul>\n")
+	for i, title := range site.Posts {
+		fmt.Fprintf(&index, "<li><a href=\"/posts/%d.html\">%s</a></li>\n", i+1, html.EscapeString(title))
+	}
+	index.WriteString("</ul>\n")
+	pages := map[string]string{
+		"index.html": demoPage(site, site.Title, index.String()),
+		"about.html": demoPage(site, "About", "<p>"+html.EscapeString(site.Title)+" is written by "+html.EscapeString(site.Author)+".</p>\n"),
+		"404.html":   demoPage(site, "Not found", "<p>The page you are looking for does not exist. <a href=\"/\">Back home</a></p>\n"),
+	}
+	for i, title := range site.Posts {
+		body := fmt.Sprintf("<article>\n<h2>%s</h2>\n<p>%s</p>\n<p>%s</p>\n</article>\n",
+			html.EscapeString(title), demoParagraph(i), demoParagraph(i+1))
+		pages[fmt.Sprintf("posts/%d.html", i+1)] = demoPage(site, title, body)
+	}
+	pages["assets/style.css"] = demoStyle
+	// 按固定顺序写入，相同内容生成相同的压缩包 Written in a fixed order so identical content yields identical archives
+	names := []string{"index.html", "about.html", "404.html", "assets/style.css"}
+	for i := range site.Posts {
+		names = append(names, fmt.Sprintf("posts/%d.html", i+1))
+	}
+	for _, name := range names {
+		w, err := archive.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, pages[name]); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+// demoPage 以统一的页头和页脚包装页面内容
+// Wrap page content with the shared header and footer
+func demoPage(site DemoSite, title, body string) string {
+	return "<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<meta charset=\"utf-8\">\n" +
+		"<meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">\n" +
+		"<title>" + html.EscapeString(title) + "</title>\n" +
+		"<meta name=\"description\" content=\"" + html.EscapeString(site.Description) + "\">\n" +
+		"<link rel=\"stylesheet\" href=\"/assets/style.css\">\n</head>\n<body>\n" +
+		"<header><a href=\"/\">" + html.EscapeString(site.Title) + "</a> · <a href=\"/about.html\">About</a></header>\n" +
+		"<h1>" + html.EscapeString(title) + "</h1>\n" + body +
+		"<footer>" + html.EscapeString(site.Author) + " · " + html.EscapeString(site.Version) + "</footer>\n</body>\n</html>\n"
+}
+
+// demoSentences 组成文章段落的句子 Sentences making up post paragraphs
+var demoSentences = []string{
+	"Static sites load quickly because every page is prepared ahead of time.",
+	"Each deployment is kept as a version, so rolling back takes a single click.",
+	"Custom domains are verified and served with automatically issued certificates.",
+	"Previews let reviewers see a change before it reaches visitors.",
+	"Build once, cache everywhere, and keep the origin quiet.",
+	"Small pages with few requests remain fast on slow networks.",
+	"Good defaults matter more than clever configuration.",
+}
+
+// demoParagraph 生成第 n 个段落，不同段落的句子组合不同
+// Build the nth paragraph, each paragraph combining different sentences
+func demoParagraph(n int) string {
+	sentences := make([]string, 0, 3)
+	for i := range 3 {
+		sentences = append(sentences, demoSentences[(n*3+i)%len(demoSentences)])
+	}
+	return strings.Join(sentences, " ")
+}
@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDemoWrite(t *testing.T) {
+	site := DemoSite{
+		Title:       "Notes <&>",
+		Description: "A demo site",
+		Author:      "alice",
+		Version:     "v2",
+		Posts:       []string{"Hello", "World"},
+	}
+	var buf bytes.Buffer
+	if err := Demo.Write(&buf, site); err != nil {
+		t.Fatal(err)
+	}
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{}
+	for _, f := range reader.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(rc)
+		_ = rc.Close()
+		files[f.Name] = string(body)
+	}
+	for _, name := range []string{"index.html", "about.html", "404.html", "assets/style.css", "posts/1.html", "posts/2.html"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("missing %s", name)
+		}
+	}
+	index := files["index.html"]
+	if !strings.Contains(index, "Notes &lt;&amp;&gt;") || strings.Contains(index, "Notes <&>") {
+		t.Error("the title should be escaped")
+	}
+	if !strings.Contains(index, `href="/posts/2.html"`) || !strings.Contains(index, "v2") {
+		t.Errorf("index = %s", index)
+	}
+
+	var again bytes.Buffer
+	if err := Demo.Write(&again, site); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), again.Bytes()) {
+		t.Error("identical content should produce identical archives")
+	}
+}
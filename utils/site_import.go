@@ -0,0 +1,290 @@
+package utils
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+type importType struct{}
+
+// Import 将 GitHub Pages、Netlify 等平台导出的站点压缩包转换为可发布的 zip
+// Convert site archives exported from GitHub Pages, Netlify and similar platforms into publishable zips
+var Import = importType{}
+
+var (
+	// ErrImportFormat 压缩包格式无法识别或没有文件
+	// The archive format is not recognized or it holds no files
+	ErrImportFormat = errors.New("archive must be a zip, tar or tar.gz file containing at least one file")
+	// ErrImportTooLarge 解压后的内容超过上限
+	// The unpacked content exceeds the limit
+	ErrImportTooLarge = errors.New("archive content exceeds the size limit")
+)
+
+// importCNAMEFile GitHub Pages 记录自定义域名的文件
+// File GitHub Pages records the custom domain in
+const importCNAMEFile = "CNAME"
+
+// importSkipped 导入时跳过的目录 Directories skipped when importing
+var importSkipped = []string{".git", "__MACOSX"}
+
+// archiveWalker 依次访问压缩包中的普通文件，可以多次遍历
+// Visit the regular files of an archive in order, may be walked more than once
+type archiveWalker func(visit func(name string, body io.Reader) error) error
+
+// Normalize 将 zip、tar 或 tar.gz 转换为 zip 写入 dst：GitHub Pages 构建产物 zip 中的 artifact.tar 会被展开，
+// 所有文件位于同一顶层目录时去掉该目录，跳过 .git、__MACOSX 和非普通文件；limit 为解压后的字节上限，0 表示不限制
+// Convert a zip, tar or tar.gz into a zip written to dst: the artifact.tar inside a GitHub Pages artifact zip is unpacked,
+// a top-level directory holding every file is stripped, and .git, __MACOSX and non-regular files are skipped; limit bounds the unpacked bytes, 0 is unlimited
+func (importType) Normalize(src io.ReaderAt, size int64, dst io.Writer, limit int64) error {
+	walk, cleanup, err := importWalker(src, size, limit, true)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	var names []string
+	seen := map[string]bool{}
+	if err := walk(func(name string, _ io.Reader) error {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+		return nil
+	}); err != nil {
+		return ErrImportFormat
+	}
+	if len(names) == 0 {
+		return ErrImportFormat
+	}
+	prefix := importCommonDir(names)
+
+	writer := zip.NewWriter(dst)
+	written := map[string]bool{}
+	remaining := limit
+	err = walk(func(name string, body io.Reader) error {
+		if written[name] {
+			return nil
+		}
+		written[name] = true
+		entry, err := writer.Create(strings.TrimPrefix(name, prefix))
+		if err != nil {
+			return err
+		}
+		if limit <= 0 {
+			_, err = io.Copy(entry, body)
+			return err
+		}
+		n, err := io.Copy(entry, io.LimitReader(body, remaining+1))
+		if err != nil {
+			return err
+		}
+		if remaining -= n; remaining < 0 {
+			return ErrImportTooLarge
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+// CNAME 读取 zip 根目录下 CNAME 文件中的域名，没有时返回空字符串
+// Read the domain from the CNAME file at the root of a zip, empty when there is none
+func (importType) CNAME(src io.ReaderAt, size int64) string {
+	reader, err := zip.NewReader(src, size)
+	if err != nil {
+		return ""
+	}
+	for _, file := range reader.File {
+		if file.Name != importCNAMEFile {
+			continue
+		}
+		body, err := file.Open()
+		if err != nil {
+			return ""
+		}
+		defer body.Close()
+		scanner := bufio.NewScanner(io.LimitReader(body, 1024))
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); line != "" {
+				return line
+			}
+		}
+		return ""
+	}
+	return ""
+}
+
+// importWalker 按文件头识别压缩包格式，unwrap 为真时展开只包含一个 tar 的 zip
+// Recognize the archive format by its header, unpacking a zip that holds a single tar when unwrap is set
+func importWalker(src io.ReaderAt, size, limit int64, unwrap bool) (archiveWalker, func(), error) {
+	head := make([]byte, 512)
+	n, err := src.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return nil, nil, err
+	}
+	head = head[:n]
+	switch {
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")):
+		reader, err := zip.NewReader(src, size)
+		if err != nil {
+			return nil, nil, ErrImportFormat
+		}
+		if inner := importSingleTar(reader); unwrap && inner != nil {
+			return importUnwrap(inner, limit)
+		}
+		return func(visit func(name string, body io.Reader) error) error {
+			for _, file := range reader.File {
+				name, ok := importPath(file.Name)
+				if !ok || !file.Mode().IsRegular() {
+					continue
+				}
+				body, err := file.Open()
+				if err != nil {
+					return err
+				}
+				err = visit(name, body)
+				_ = body.Close()
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}, func() {}, nil
+	case bytes.HasPrefix(head, []byte{0x1f, 0x8b}):
+		return importTarWalker(func() (io.Reader, error) {
+			return gzip.NewReader(io.NewSectionReader(src, 0, size))
+		}), func() {}, nil
+	case len(head) >= 262 && string(head[257:262]) == "ustar":
+		return importTarWalker(func() (io.Reader, error) {
+			return io.NewSectionReader(src, 0, size), nil
+		}), func() {}, nil
+	}
+	return nil, nil, ErrImportFormat
+}
+
+// importSingleTar zip 中唯一的普通文件是 tar 或 tar.gz 时返回它
+// Return the only regular file of a zip when it is a tar or tar.gz
+func importSingleTar(reader *zip.Reader) *zip.File {
+	var single *zip.File
+	for _, file := range reader.File {
+		if !file.Mode().IsRegular() {
+			continue
+		}
+		if single != nil {
+			return nil
+		}
+		single = file
+	}
+	if single == nil {
+		return nil
+	}
+	if name := strings.ToLower(single.Name); strings.HasSuffix(name, ".tar") || strings.HasSuffix(name, ".tar.gz") || strings.HasSuffix(name, ".tgz") {
+		return single
+	}
+	return nil
+}
+
+// importUnwrap 将 zip 中的 tar 解压到临时文件并遍历它，返回的清理函数删除临时文件
+// Unpack the tar inside a zip into a temporary file and walk it, the returned cleanup removes the file
+func importUnwrap(file *zip.File, limit int64) (archiveWalker, func(), error) {
+	body, err := file.Open()
+	if err != nil {
+		return nil, nil, ErrImportFormat
+	}
+	defer body.Close()
+	tmp, err := os.CreateTemp("", "spage-import-*.tar")
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+	}
+	reader := io.Reader(body)
+	if limit > 0 {
+		reader = io.LimitReader(body, limit+1)
+	}
+	size, err := io.Copy(tmp, reader)
+	if err == nil && limit > 0 && size > limit {
+		err = ErrImportTooLarge
+	}
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	walk, innerCleanup, err := importWalker(tmp, size, limit, false)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	return walk, func() { innerCleanup(); cleanup() }, nil
+}
+
+// importTarWalker 遍历 tar，每次遍历重新打开
+// Walk a tar, reopening it for every walk
+func importTarWalker(open func() (io.Reader, error)) archiveWalker {
+	return func(visit func(name string, body io.Reader) error) error {
+		stream, err := open()
+		if err != nil {
+			return ErrImportFormat
+		}
+		reader := tar.NewReader(stream)
+		for {
+			header, err := reader.Next()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return ErrImportFormat
+			}
+			name, ok := importPath(header.Name)
+			if !ok || header.Typeflag != tar.TypeReg {
+				continue
+			}
+			if err := visit(name, reader); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// importPath 规范压缩包中的路径，空路径和跳过的目录返回 false
+// Normalize a path inside an archive, reporting false for empty paths and skipped directories
+func importPath(name string) (string, bool) {
+	clean := path.Clean("/" + strings.ReplaceAll(name, "\\", "/"))[1:]
+	if clean == "" {
+		return "", false
+	}
+	for _, part := range strings.Split(clean, "/") {
+		for _, skipped := range importSkipped {
+			if part == skipped {
+				return "", false
+			}
+		}
+	}
+	return clean, true
+}
+
+// importCommonDir 所有文件都位于同一顶层目录时返回该目录加斜杠，否则返回空字符串
+// Return the top-level directory plus a slash when it holds every file, otherwise an empty string
+func importCommonDir(names []string) string {
+	prefix := ""
+	for _, name := range names {
+		dir, _, ok := strings.Cut(name, "/")
+		if !ok || (prefix != "" && dir != prefix) {
+			return ""
+		}
+		prefix = dir
+	}
+	return prefix + "/"
+}
@@ -0,0 +1,142 @@
+package utils
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"sort"
+	"testing"
+)
+
+func importTar(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := tar.NewWriter(&buf)
+	for name, body := range files {
+		if err := w.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(body)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		_, _ = w.Write([]byte(body))
+	}
+	_ = w.Close()
+	return buf.Bytes()
+}
+
+func importZip(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, body := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = f.Write(body)
+	}
+	_ = w.Close()
+	return buf.Bytes()
+}
+
+func importNormalize(t *testing.T, data []byte, limit int64) ([]byte, map[string]string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	if err := Import.Normalize(bytes.NewReader(data), int64(len(data)), &out, limit); err != nil {
+		return nil, nil, err
+	}
+	reader, err := zip.NewReader(bytes.NewReader(out.Bytes()), int64(out.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{}
+	for _, f := range reader.File {
+		rc, _ := f.Open()
+		body, _ := io.ReadAll(rc)
+		_ = rc.Close()
+		files[f.Name] = string(body)
+	}
+	return out.Bytes(), files, nil
+}
+
+func importNames(files map[string]string) []string {
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TestImportNormalizeTarGz 测试去掉公共顶层目录并跳过 .git
+// Test stripping the common top-level directory and skipping .git
+func TestImportNormalizeTarGz(t *testing.T) {
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	_, _ = w.Write(importTar(t, map[string]string{
+		"site/index.html":  "<h1>hi</h1>",
+		"site/css/a.css":   "body{}",
+		"site/CNAME":       "\n  www.example.com \n",
+		"site/.git/HEAD":   "ref",
+		"site/x/../escape": "x",
+	}))
+	_ = w.Close()
+	out, files, err := importNormalize(t, gz.Bytes(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := importNames(files); len(got) != 4 || got[0] != "CNAME" || got[1] != "css/a.css" || got[2] != "escape" || got[3] != "index.html" {
+		t.Fatalf("unexpected files %v", got)
+	}
+	if files["index.html"] != "<h1>hi</h1>" {
+		t.Errorf("unexpected content %q", files["index.html"])
+	}
+	if cname := Import.CNAME(bytes.NewReader(out), int64(len(out))); cname != "www.example.com" {
+		t.Errorf("CNAME = %q", cname)
+	}
+}
+
+// TestImportNormalizeArtifact 测试展开 GitHub Pages 构建产物中的 artifact.tar
+// Test unpacking the artifact.tar of a GitHub Pages artifact
+func TestImportNormalizeArtifact(t *testing.T) {
+	data := importZip(t, map[string][]byte{"artifact.tar": importTar(t, map[string]string{"./index.html": "a", "./docs/b.html": "b"})})
+	out, files, err := importNormalize(t, data, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := importNames(files); len(got) != 2 || got[0] != "docs/b.html" || got[1] != "index.html" {
+		t.Fatalf("unexpected files %v", got)
+	}
+	if cname := Import.CNAME(bytes.NewReader(out), int64(len(out))); cname != "" {
+		t.Errorf("CNAME = %q, want none", cname)
+	}
+}
+
+// TestImportNormalizeZip 测试文件位于根目录时保留目录结构
+// Test keeping the layout when files sit at the root
+func TestImportNormalizeZip(t *testing.T) {
+	data := importZip(t, map[string][]byte{"index.html": []byte("a"), "assets/app.js": []byte("b"), "__MACOSX/._index.html": []byte("c")})
+	_, files, err := importNormalize(t, data, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := importNames(files); len(got) != 2 || got[0] != "assets/app.js" || got[1] != "index.html" {
+		t.Fatalf("unexpected files %v", got)
+	}
+}
+
+// TestImportNormalizeRejects 测试超出上限和无法识别的压缩包
+// Test archives over the limit and unrecognized archives
+func TestImportNormalizeRejects(t *testing.T) {
+	data := importZip(t, map[string][]byte{"index.html": bytes.Repeat([]byte("a"), 100)})
+	if _, _, err := importNormalize(t, data, 10); !errors.Is(err, ErrImportTooLarge) {
+		t.Errorf("expected ErrImportTooLarge, got %v", err)
+	}
+	if _, _, err := importNormalize(t, []byte("not an archive"), 0); !errors.Is(err, ErrImportFormat) {
+		t.Errorf("expected ErrImportFormat, got %v", err)
+	}
+	if _, _, err := importNormalize(t, importZip(t, map[string][]byte{".git/HEAD": []byte("x")}), 0); !errors.Is(err, ErrImportFormat) {
+		t.Errorf("expected ErrImportFormat for an empty site, got %v", err)
+	}
+}
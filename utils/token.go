@@ -1,12 +1,13 @@
 package utils
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"time"
 
 	"github.com/LiteyukiStudio/spage/config"
-	"github.com/LiteyukiStudio/spage/models"
+	"github.com/LiteyukiStudio/spage/constants"
 	"github.com/golang-jwt/jwt/v5"
-	"gorm.io/gorm"
 )
 
 type TokenType struct{}
@@ -18,32 +19,28 @@ type Claims struct {
 	UserID   uint `json:"user_id"`  // 用户ID，用于身份验证 Verify user identity using the User ID
 	TokenID  uint `json:"token_id"` // 令牌ID，用于服务端会话维持 Keep the token ID for server-side session maintenance
 	Stateful bool `json:"stateful"` // 是否为有状态Token Whether it is a stateful Token
+	// 代为登录的管理员ID和会话的权限范围，普通会话为空 Impersonating administrator and scopes of the session, empty for ordinary sessions
+	Impersonator uint                   `json:"impersonator,omitempty"`
+	Scopes       []constants.TokenScope `json:"scopes,omitempty"`
+	// 两步验证等临时凭证的用途，登录令牌没有 Purpose of temporary credentials such as two-factor challenges, login tokens have none
+	Purpose string `json:"purpose,omitempty"`
 }
 
-// CreateToken 生成用户会话令牌（默认24小时有效）
-// stateful=false的无状态Token不会做持久化，在实例重启后失效
-// Create a user session token (default 24 hours valid)
-// stateful=false tokens are not persistent, and they will expire after the instance restarts
-func (TokenType) CreateToken(userID uint, duration time.Duration, stateful bool, persistentHandler func(uint) (*models.Token, error)) (string, error) {
-	var tokenModel *models.Token
-	var err error
-	if stateful {
-		tokenModel, err = persistentHandler(userID)
-		if err != nil {
-			return "", err
-		}
-	} else {
-		tokenModel = &models.Token{
-			Model: gorm.Model{
-				ID: 0,
-			},
-		}
-	}
+// CreateToken 为登录会话生成短期访问令牌，会话被撤销后令牌立即失效
+// Create a short-lived access token of a sign-in session, the token stops working as soon as the session is revoked
+func (t TokenType) CreateToken(userID, sessionID uint, duration time.Duration) (string, error) {
+	return t.CreateImpersonationToken(userID, sessionID, 0, nil, duration)
+}
 
+// CreateImpersonationToken 为管理员代为登录的会话生成访问令牌，令牌携带管理员ID和会话的权限范围
+// Create an access token of a session where an administrator impersonates the user, carrying the administrator ID and the session's scopes
+func (TokenType) CreateImpersonationToken(userID, sessionID, impersonatorID uint, scopes []constants.TokenScope, duration time.Duration) (string, error) {
 	claims := Claims{
-		UserID:   userID,
-		TokenID:  tokenModel.ID,
-		Stateful: stateful,
+		UserID:       userID,
+		TokenID:      sessionID,
+		Stateful:     true,
+		Impersonator: impersonatorID,
+		Scopes:       scopes,
 		RegisteredClaims: jwt.RegisteredClaims{
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(duration)),
@@ -69,12 +66,75 @@ func (TokenType) ParseToken(tokenString string, revokeChecker func(uint) bool) (
 		return nil, jwt.ErrSignatureInvalid
 	}
 
-	// 有状态token被吊销也视为过期
-	// Revoked stateful tokens are considered expired
-	if claims.Stateful {
-		if revokeChecker(claims.UserID) {
-			return nil, jwt.ErrTokenExpired
-		}
+	// 带用途的临时凭证不能用于调用接口 Temporary credentials carrying a purpose never authenticate API calls
+	if claims.Purpose != "" {
+		return nil, jwt.ErrTokenInvalidClaims
+	}
+	// 旧版本签发的无状态 token 无法撤销，不再接受；会话被撤销也视为过期
+	// Stateless tokens issued by older versions cannot be revoked and are no longer accepted; revoked sessions are considered expired
+	if !claims.Stateful || revokeChecker(claims.TokenID) {
+		return nil, jwt.ErrTokenExpired
 	}
 	return claims, nil
 }
+
+// NewAPIToken 生成个人访问令牌，返回明文和用于存储的哈希
+// Generate a personal access token, returning the plain token and the hash to store
+func (TokenType) NewAPIToken() (plain, hash string, err error) {
+	random, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+	plain = constants.APITokenPrefix + random
+	return plain, Token.HashAPIToken(plain), nil
+}
+
+// NewDeployKey 生成项目部署密钥，返回明文和用于存储的哈希
+// Generate a project deploy key, returning the plain key and the hash to store
+func (TokenType) NewDeployKey() (plain, hash string, err error) {
+	random, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+	plain = constants.DeployKeyPrefix + random
+	return plain, Token.HashAPIToken(plain), nil
+}
+
+// NewRunnerToken 生成远程构建执行器的令牌，返回明文和用于存储的哈希
+// Generate the token of a remote build runner, returning the plain token and the hash to store
+func (TokenType) NewRunnerToken() (plain, hash string, err error) {
+	random, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+	plain = constants.RunnerTokenPrefix + random
+	return plain, Token.HashAPIToken(plain), nil
+}
+
+// NewRefreshToken 生成登录会话的刷新令牌，返回明文和用于存储的哈希
+// Generate the refresh token of a sign-in session, returning the plain token and the hash to store
+func (TokenType) NewRefreshToken() (plain, hash string, err error) {
+	random, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+	plain = constants.RefreshTokenPrefix + random
+	return plain, Token.HashAPIToken(plain), nil
+}
+
+// NewOneTimeToken 生成邮件链接中的一次性令牌，返回明文和用于存储的哈希
+// Generate a one-time token for email links, returning the plain token and the hash to store
+func (TokenType) NewOneTimeToken() (plain, hash string, err error) {
+	plain, err = randomToken()
+	if err != nil {
+		return "", "", err
+	}
+	return plain, Token.HashAPIToken(plain), nil
+}
+
+// HashAPIToken 计算个人访问令牌的哈希，令牌本身是高熵随机值，无需慢哈希
+// Hash a personal access token, the token is high-entropy random data so no slow hash is needed
+func (TokenType) HashAPIToken(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
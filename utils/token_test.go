@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/constants"
+)
+
+// TestImpersonationToken 代为登录的访问令牌携带管理员和权限范围，普通访问令牌不携带
+// Impersonation access tokens carry the administrator and scopes, ordinary access tokens do not
+func TestImpersonationToken(t *testing.T) {
+	notRevoked := func(uint) bool { return false }
+	token, err := Token.CreateImpersonationToken(2, 7, 1, []constants.TokenScope{constants.TokenScopeRead}, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := Token.ParseToken(token, notRevoked)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.UserID != 2 || claims.TokenID != 7 || claims.Impersonator != 1 || !slices.Equal(claims.Scopes, []constants.TokenScope{constants.TokenScopeRead}) {
+		t.Errorf("unexpected claims %+v", claims)
+	}
+	token, err = Token.CreateToken(2, 8, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims, err = Token.ParseToken(token, notRevoked); err != nil || claims.Impersonator != 0 || claims.Scopes != nil {
+		t.Errorf("ordinary token: %+v %v", claims, err)
+	}
+	if _, err = Token.ParseToken(token, func(uint) bool { return true }); err == nil {
+		t.Error("expected a revoked session to be rejected")
+	}
+}
@@ -0,0 +1,165 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type totpType struct{}
+
+// TOTP 基于时间的一次性密码（RFC 6238，SHA-1、30 秒、6 位），兼容常见的身份验证器应用
+// Time-based one-time passwords (RFC 6238, SHA-1, 30 seconds, 6 digits), compatible with common authenticator apps
+var TOTP = totpType{}
+
+const (
+	totpPeriod = 30 // 时间步长，单位秒 Time step in seconds
+	totpDigits = 6  // 验证码位数 Number of code digits
+	totpSkew   = 1  // 允许前后偏移的步数 Steps of clock skew allowed in each direction
+
+	recoveryCodeCount = 10 // 每次生成的恢复码数量 Number of recovery codes generated at once
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TwoFactorChallenge 密码验证通过后、完成两步验证前签发的临时凭证
+// Temporary credential issued after the password is verified and before the second factor is completed
+type TwoFactorChallenge struct {
+	jwt.RegisteredClaims
+	UserID  uint   `json:"user_id"` // 用户ID User ID
+	Purpose string `json:"purpose"` // 固定为 2fa，防止与其他令牌混用 Always 2fa, so it cannot be mixed up with other tokens
+}
+
+// NewSecret 生成 160 位的随机密钥
+// Generate a random 160-bit secret
+func (totpType) NewSecret() (string, error) {
+	buf := make([]byte, 20)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return totpEncoding.EncodeToString(buf), nil
+}
+
+// ProvisioningURI 身份验证器应用扫描的 otpauth 地址，可由前端渲染为二维码
+// otpauth URI scanned by authenticator apps, the frontend may render it as a QR code
+func (totpType) ProvisioningURI(secret, account string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", config.TOTPIssuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(totpDigits))
+	query.Set("period", fmt.Sprint(totpPeriod))
+	label := url.PathEscape(config.TOTPIssuer + ":" + account)
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+// Code 计算指定时间步的验证码
+// Compute the code of the given time step
+func (totpType) Code(secret string, step int64) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil {
+		return "", err
+	}
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	mod := uint32(1)
+	for range totpDigits {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", totpDigits, value%mod), nil
+}
+
+// Verify 校验验证码，返回匹配的时间步；调用方需保证时间步大于上次使用的时间步以防重放
+// Verify a code and return the matching time step; callers must require it to be greater than the last used step against replay
+func (totpType) Verify(secret, code string, now time.Time) (step int64, ok bool) {
+	code = strings.TrimSpace(code)
+	if len(code) != totpDigits {
+		return 0, false
+	}
+	current := now.Unix() / totpPeriod
+	for delta := int64(-totpSkew); delta <= totpSkew; delta++ {
+		expected, err := TOTP.Code(secret, current+delta)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return current + delta, true
+		}
+	}
+	return 0, false
+}
+
+// NewRecoveryCodes 生成一组恢复码，返回明文和对应的哈希
+// Generate a set of recovery codes, returning the plain codes and their hashes
+func (totpType) NewRecoveryCodes() (codes, hashes []string, err error) {
+	for range recoveryCodeCount {
+		buf := make([]byte, 5)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		raw := strings.ToLower(totpEncoding.EncodeToString(buf))
+		code := raw[:4] + "-" + raw[4:]
+		codes = append(codes, code)
+		hashes = append(hashes, TOTP.HashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// HashRecoveryCode 计算恢复码的哈希，忽略大小写和分隔符
+// Hash a recovery code, ignoring case and separators
+func (totpType) HashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// challengeKey 签名密钥，与登录令牌的密钥不同，临时凭证不能被当作登录令牌使用
+// Signing key, different from the one of login tokens so challenges cannot pass as login tokens
+func (totpType) challengeKey() []byte {
+	return []byte("two-factor\n" + config.JwtSecret)
+}
+
+// SignChallenge 签发两步验证临时凭证
+// Sign a two-factor challenge
+func (totpType) SignChallenge(userID uint, ttl time.Duration) (string, error) {
+	claims := TwoFactorChallenge{
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl))},
+		UserID:           userID,
+		Purpose:          "2fa",
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(TOTP.challengeKey())
+}
+
+// ParseChallenge 校验并解析两步验证临时凭证
+// Verify and parse a two-factor challenge
+func (totpType) ParseChallenge(signed string) (*TwoFactorChallenge, error) {
+	claims := &TwoFactorChallenge{}
+	_, err := jwt.ParseWithClaims(signed, claims, func(token *jwt.Token) (any, error) {
+		return TOTP.challengeKey(), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != "2fa" || claims.UserID == 0 {
+		return nil, errors.New("not a two-factor challenge")
+	}
+	return claims, nil
+}
@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+
+	"github.com/LiteyukiStudio/spage/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// TestTOTPCode 使用 RFC 6238 附录 B 的 SHA-1 测试向量（取末 6 位）
+// Use the SHA-1 test vectors of RFC 6238 appendix B (last 6 digits)
+func TestTOTPCode(t *testing.T) {
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1234567890:  "005924",
+		20000000000: "353130",
+	}
+	for unix, expected := range vectors {
+		code, err := TOTP.Code(secret, unix/totpPeriod)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code != expected {
+			t.Errorf("Expected %s at %d, got %s", expected, unix, code)
+		}
+		step, ok := TOTP.Verify(secret, expected, time.Unix(unix+totpPeriod, 0))
+		if !ok || step != unix/totpPeriod {
+			t.Errorf("Expected %s to verify within the skew window at %d", expected, unix)
+		}
+	}
+	if _, ok := TOTP.Verify(secret, "287082", time.Unix(59+3*totpPeriod, 0)); ok {
+		t.Error("Expected a code outside the skew window to be rejected")
+	}
+}
+
+// TestRecoveryCodeHash 测试恢复码哈希忽略大小写和分隔符
+// Test that recovery code hashes ignore case and separators
+func TestRecoveryCodeHash(t *testing.T) {
+	codes, hashes, err := TOTP.NewRecoveryCodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != recoveryCodeCount || len(hashes) != recoveryCodeCount {
+		t.Fatalf("Expected %d codes, got %d", recoveryCodeCount, len(codes))
+	}
+	if TOTP.HashRecoveryCode("ABCD-EFGH") != TOTP.HashRecoveryCode("abcdefgh") {
+		t.Error("Expected recovery code hashes to ignore case and separators")
+	}
+}
+
+// TestChallengeIsNotAToken 两步验证临时凭证不能被当作登录令牌，带用途的令牌也会被拒绝
+// Two-factor challenges never pass as login tokens, and tokens carrying a purpose are refused
+func TestChallengeIsNotAToken(t *testing.T) {
+	notRevoked := func(uint) bool { return false }
+	challenge, err := TOTP.SignChallenge(2, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims, err := TOTP.ParseChallenge(challenge); err != nil || claims.UserID != 2 {
+		t.Fatalf("ParseChallenge = %+v, %v", claims, err)
+	}
+	if _, err := Token.ParseToken(challenge, notRevoked); err == nil {
+		t.Error("a challenge authenticated as a login token")
+	}
+	// 即使以登录令牌的密钥签名并声明有状态 Even when signed with the login key and claiming to be stateful
+	forged := Claims{UserID: 2, TokenID: 1, Stateful: true, Purpose: "2fa"}
+	forged.ExpiresAt = jwt.NewNumericDate(time.Now().Add(time.Minute))
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, forged).SignedString([]byte(config.JwtSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Token.ParseToken(signed, notRevoked); err == nil {
+		t.Error("a token carrying a purpose authenticated")
+	}
+	if _, err := TOTP.ParseChallenge(signed); err == nil {
+		t.Error("a token signed with the login key passed as a challenge")
+	}
+}
@@ -0,0 +1,372 @@
+package utils
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/LiteyukiStudio/spage/constants"
+	"github.com/LiteyukiStudio/spage/i18n"
+	"golang.org/x/text/unicode/norm"
+)
+
+type validatorType struct{}
+
+// Validator 按 validate 标签校验请求结构体
+// Validate request structs by their validate tags
+//
+// 规则以逗号分隔，指针为 nil 时只检查 required，字符串去掉首尾空白后检查 required：
+// Rules are separated by commas, nil pointers are only checked by required and strings are trimmed before required is checked:
+//
+//	required     非零值 Non-zero value
+//	min=N max=N  字符串的字符数、切片和 map 的长度或数字的大小 Characters of strings, length of slices and maps or value of numbers
+//	slug         小写字母、数字和单个连字符，以字母或数字开头和结尾，可用作子域名 Lowercase letters, digits and single hyphens starting and ending with a letter or digit, usable as a subdomain
+//	reserved     不是 constants.ReservedNames 中的名称 Not one of constants.ReservedNames
+//	domain       合法的域名 Valid domain name
+//	email        邮箱地址 Email address
+//	url          完整的 http 或 https 地址 Absolute http or https URL
+//	oneof=a b    取值之一 One of the values
+var Validator = validatorType{}
+
+// FieldError 一个字段未通过的规则
+// Rule a field failed
+type FieldError struct {
+	Field   string       `json:"field"` // 请求中的字段名，嵌套字段以点分隔 Field name in the request, nested fields are separated by dots
+	Rule    string       `json:"rule"`  // 未通过的规则 Failed rule
+	Message i18n.Message `json:"-"`     // 提示 Message
+}
+
+// ValidationErrors 所有未通过校验的字段
+// Every field failing validation
+type ValidationErrors []FieldError
+
+func (errs ValidationErrors) Error() string {
+	parts := make([]string, 0, len(errs))
+	for _, e := range errs {
+		parts = append(parts, e.Field+" "+e.Message.String())
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Struct 校验结构体或结构体指针，全部通过时返回 nil；标签有误时 panic，应由 Check 在测试中提前发现
+// Validate a struct or a pointer to one, nil when everything passes; panics on bad tags, which Check is meant to catch in tests
+func (validatorType) Struct(v any) ValidationErrors {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+	var errs ValidationErrors
+	validateStruct(value, "", &errs)
+	return errs
+}
+
+// Check 解析结构体及其嵌套结构体的全部 validate 标签，返回第一个有误的标签，不检查字段的值
+// Parse every validate tag of a struct and its nested structs, returning the first bad tag without looking at field values
+func (validatorType) Check(v any) error {
+	return checkType(reflect.TypeOf(v), map[reflect.Type]bool{})
+}
+
+func checkType(t reflect.Type, seen map[reflect.Type]bool) error {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct || t.PkgPath() == "time" || seen[t] {
+		return nil
+	}
+	seen[t] = true
+	if _, err := compiledFields(t); err != nil {
+		return err
+	}
+	for i := range t.NumField() {
+		if err := checkType(t.Field(i).Type, seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rule 解析后的一条规则
+// Parsed rule
+type rule struct {
+	name  string
+	param string
+	limit int64 // min 和 max 的参数 Parameter of min and max
+}
+
+// compiledField 解析后的字段及其规则
+// Parsed field and its rules
+type compiledField struct {
+	index    int
+	name     string // 请求中的字段名 Field name in the request
+	rules    []rule
+	required bool
+	embedded bool // 字段属于外层的嵌入结构体 Embedded struct whose fields belong to the outer struct
+	nested   bool // 需要递归校验的结构体或结构体指针 Struct or pointer to one validated recursively
+}
+
+// compiled 每个结构体类型解析后的字段，标签只解析一次 Parsed fields of each struct type, tags are only parsed once
+var compiled sync.Map
+
+// fieldsOf 结构体类型解析后的字段，标签有误时 panic
+// Parsed fields of a struct type, panics on bad tags
+func fieldsOf(t reflect.Type) []compiledField {
+	fields, err := compiledFields(t)
+	if err != nil {
+		panic(err.Error())
+	}
+	return fields
+}
+
+func compiledFields(t reflect.Type) ([]compiledField, error) {
+	if cached, ok := compiled.Load(t); ok {
+		return cached.([]compiledField), nil
+	}
+	fields := make([]compiledField, 0, t.NumField())
+	for i := range t.NumField() {
+		field := t.Field(i)
+		// 嵌入的结构体的字段属于外层，类型未导出时字段仍可导出 Fields of embedded structs belong to the outer struct, and stay exported when the type is not
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			fields = append(fields, compiledField{index: i, embedded: true})
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		f := compiledField{index: i, name: fieldName(field)}
+		if tag := field.Tag.Get("validate"); tag != "" {
+			kind := field.Type.Kind()
+			if kind == reflect.Pointer {
+				kind = field.Type.Elem().Kind()
+			}
+			for _, text := range strings.Split(tag, ",") {
+				r, err := parseRule(strings.TrimSpace(text), kind)
+				if err != nil {
+					return nil, fmt.Errorf("%s.%s: %w", t, field.Name, err)
+				}
+				f.required = f.required || r.name == "required"
+				f.rules = append(f.rules, r)
+			}
+		}
+		inner := field.Type
+		for inner.Kind() == reflect.Pointer {
+			inner = inner.Elem()
+		}
+		f.nested = inner.Kind() == reflect.Struct && inner.PkgPath() != "time"
+		fields = append(fields, f)
+	}
+	compiled.Store(t, fields)
+	return fields, nil
+}
+
+// parseRule 解析一条规则并检查它是否适用于字段的类型
+// Parse a rule and check that it applies to the kind of the field
+func parseRule(text string, kind reflect.Kind) (rule, error) {
+	name, param, _ := strings.Cut(text, "=")
+	r := rule{name: name, param: param}
+	switch name {
+	case "required":
+	case "min", "max":
+		limit, err := strconv.ParseInt(param, 10, 64)
+		if err != nil {
+			return r, fmt.Errorf("invalid validate rule %q", text)
+		}
+		r.limit = limit
+		switch kind {
+		case reflect.String, reflect.Slice, reflect.Map, reflect.Array,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		default:
+			return r, fmt.Errorf("validate rule %s does not apply to %s", name, kind)
+		}
+	case "slug", "reserved", "domain", "email", "url", "oneof":
+		if kind != reflect.String {
+			return r, fmt.Errorf("validate rule %s does not apply to %s", name, kind)
+		}
+		if name == "oneof" && strings.TrimSpace(param) == "" {
+			return r, fmt.Errorf("invalid validate rule %q", text)
+		}
+	default:
+		return r, fmt.Errorf("unknown validate rule %q", text)
+	}
+	return r, nil
+}
+
+func validateStruct(value reflect.Value, prefix string, errs *ValidationErrors) {
+	for _, f := range fieldsOf(value.Type()) {
+		fv := value.Field(f.index)
+		if f.embedded {
+			validateStruct(fv, prefix, errs)
+			continue
+		}
+		name := prefix + f.name
+		if len(f.rules) > 0 {
+			validateField(fv, name, f, errs)
+		}
+		if !f.nested {
+			continue
+		}
+		for fv.Kind() == reflect.Pointer && !fv.IsNil() {
+			fv = fv.Elem()
+		}
+		if fv.Kind() == reflect.Struct {
+			validateStruct(fv, name+".", errs)
+		}
+	}
+}
+
+// fieldName 字段在请求中的名称，依次取 json、form、query 标签 Name of the field in the request, from the json, form or query tag
+func fieldName(field reflect.StructField) string {
+	for _, key := range []string{"json", "form", "query"} {
+		if name, _, _ := strings.Cut(field.Tag.Get(key), ","); name != "" && name != "-" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+func validateField(value reflect.Value, name string, f compiledField, errs *ValidationErrors) {
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			if f.required {
+				*errs = append(*errs, FieldError{Field: name, Rule: "required", Message: i18n.Msg("is required")})
+			}
+			return
+		}
+		value = value.Elem()
+	}
+	for _, r := range f.rules {
+		if message, ok := checkRule(value, r); !ok {
+			*errs = append(*errs, FieldError{Field: name, Rule: r.name, Message: message})
+			// 每个字段只报告第一条未通过的规则 Only the first failed rule of a field is reported
+			return
+		}
+	}
+}
+
+// checkRule 检查一条已解析的规则，空值只由 required 检查
+// Check one parsed rule, empty values are only checked by required
+func checkRule(value reflect.Value, r rule) (i18n.Message, bool) {
+	if r.name == "required" {
+		if value.Kind() == reflect.String {
+			return i18n.Msg("is required"), strings.TrimSpace(value.String()) != ""
+		}
+		return i18n.Msg("is required"), !value.IsZero()
+	}
+	if value.IsZero() {
+		return i18n.Message{}, true
+	}
+	text := value.String()
+	switch r.name {
+	case "min", "max":
+		return checkLength(value, r.name, r.limit)
+	case "slug":
+		return i18n.Msg("may only contain lowercase letters, digits and single hyphens, and must start and end with a letter or digit"), Validator.Slug(text)
+	case "reserved":
+		return i18n.Msg("is reserved"), !slices.Contains(constants.ReservedNames, strings.ToLower(text))
+	case "domain":
+		_, err := Domain.Normalize(text)
+		return i18n.Msg("must be a valid domain name"), err == nil
+	case "email":
+		address, err := mail.ParseAddress(text)
+		return i18n.Msg("must be a valid email address"), err == nil && address.Address == text
+	case "url":
+		u, err := url.Parse(text)
+		return i18n.Msg("must be an absolute http or https URL"), err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+	case "oneof":
+		return i18n.Msg("must be one of %s", r.param), slices.Contains(strings.Fields(r.param), text)
+	}
+	return i18n.Message{}, true
+}
+
+// checkLength 检查 min 和 max，适用的类型已在解析标签时检查
+// Check min and max, the kinds they apply to are checked when the tag is parsed
+func checkLength(value reflect.Value, rule string, limit int64) (i18n.Message, bool) {
+	var size int64
+	var message i18n.Message
+	switch value.Kind() {
+	case reflect.String:
+		size = int64(utf8.RuneCountInString(value.String()))
+		message = i18n.Msg("must be at least %d characters", limit)
+		if rule == "max" {
+			message = i18n.Msg("must be at most %d characters", limit)
+		}
+	case reflect.Slice, reflect.Map, reflect.Array:
+		size = int64(value.Len())
+		message = i18n.Msg("must have at least %d items", limit)
+		if rule == "max" {
+			message = i18n.Msg("must have at most %d items", limit)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		size = value.Int()
+		message = i18n.Msg("must be at least %d", limit)
+		if rule == "max" {
+			message = i18n.Msg("must be at most %d", limit)
+		}
+	default:
+		size = int64(value.Uint())
+		message = i18n.Msg("must be at least %d", limit)
+		if rule == "max" {
+			message = i18n.Msg("must be at most %d", limit)
+		}
+	}
+	if rule == "max" {
+		return message, size <= limit
+	}
+	return message, size >= limit
+}
+
+// Slug 名称是否只包含小写字母、数字和单个连字符且以字母或数字开头和结尾，这样的名称可以直接用作子域名
+// Whether the name only has lowercase letters, digits and single hyphens and starts and ends with a letter or digit, such names can be used as subdomains directly
+func (validatorType) Slug(name string) bool {
+	if name == "" || len(name) > 63 || name[0] == '-' || name[len(name)-1] == '-' || strings.Contains(name, "--") {
+		return false
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// NormalizeSlug 将任意名称转换为 slug：按 NFKD 分解后去掉附加符号并转为小写，其余非字母数字的字符合并为单个连字符，
+// 例如 "Café Über Blog" 转为 "cafe-uber-blog"；无法转写的文字会被丢弃，结果为空时调用方需要另行提供名称
+// Turn any name into a slug: diacritics are stripped after NFKD decomposition and letters are lowercased, other characters
+// collapse into single hyphens, e.g. "Café Über Blog" becomes "cafe-uber-blog"; scripts that cannot be transliterated are dropped,
+// and callers need another name when the result is empty
+func (validatorType) NormalizeSlug(name string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range norm.NFKD.String(strings.ReplaceAll(name, "ß", "ss")) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		r = unicode.ToLower(r)
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9') {
+			hyphen = true
+			continue
+		}
+		if hyphen && b.Len() > 0 {
+			b.WriteByte('-')
+		}
+		hyphen = false
+		b.WriteRune(r)
+		if b.Len() >= 63 {
+			break
+		}
+	}
+	return strings.TrimRight(b.String(), "-")
+}
@@ -0,0 +1,174 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type validateInner struct {
+	URL string `json:"url" validate:"url"`
+}
+
+type validateEmbedded struct {
+	Note string `json:"note" validate:"max=3"`
+}
+
+type validateReq struct {
+	validateEmbedded
+	Name   string         `json:"name" validate:"required,max=63,slug,reserved"`
+	Title  *string        `json:"title" validate:"max=5"`
+	Email  *string        `json:"email" validate:"required,email"`
+	Domain string         `form:"domain" validate:"domain"`
+	Tags   []string       `json:"tags" validate:"max=2"`
+	Kind   string         `json:"kind" validate:"oneof=dns http"`
+	Inner  *validateInner `json:"inner"`
+}
+
+func failedRules(errs ValidationErrors) map[string]string {
+	rules := map[string]string{}
+	for _, e := range errs {
+		rules[e.Field] = e.Rule
+	}
+	return rules
+}
+
+func TestValidatorStruct(t *testing.T) {
+	email, title := "a@example.com", "hello"
+	valid := validateReq{Name: "my-blog", Title: &title, Email: &email, Domain: "example.com", Kind: "dns"}
+	if errs := Validator.Struct(&valid); errs != nil {
+		t.Fatalf("valid request failed: %v", errs)
+	}
+
+	longTitle, badEmail := "héllo!", "not an email"
+	req := validateReq{
+		validateEmbedded: validateEmbedded{Note: "four"},
+		Name:             "My_Blog",
+		Title:            &longTitle,
+		Email:            &badEmail,
+		Domain:           "-bad-.example",
+		Tags:             []string{"a", "b", "c"},
+		Kind:             "txt",
+		Inner:            &validateInner{URL: "ftp://example.com"},
+	}
+	want := map[string]string{
+		"note":      "max",
+		"name":      "slug",
+		"title":     "max",
+		"email":     "email",
+		"domain":    "domain",
+		"tags":      "max",
+		"kind":      "oneof",
+		"inner.url": "url",
+	}
+	if got := failedRules(Validator.Struct(&req)); !reflect.DeepEqual(got, want) {
+		t.Errorf("failed rules = %v, want %v", got, want)
+	}
+
+	empty := validateReq{Name: "  "}
+	if got := failedRules(Validator.Struct(empty)); !reflect.DeepEqual(got, map[string]string{"name": "required", "email": "required"}) {
+		t.Errorf("empty request failed rules = %v", got)
+	}
+
+	reserved := validateReq{Name: "admin", Email: &email}
+	if got := failedRules(Validator.Struct(&reserved)); got["name"] != "reserved" {
+		t.Errorf("reserved name failed rules = %v", got)
+	}
+}
+
+func TestValidatorCheck(t *testing.T) {
+	if err := Validator.Check(&validateReq{}); err != nil {
+		t.Fatalf("valid tags failed: %v", err)
+	}
+	type unknownRule struct {
+		Name string `json:"name" validate:"required,lowercase"`
+	}
+	type badLimit struct {
+		Name string `json:"name" validate:"max=ten"`
+	}
+	type boolLimit struct {
+		On bool `json:"on" validate:"min=1"`
+	}
+	type floatLimit struct {
+		Ratio *float64 `json:"ratio" validate:"max=1"`
+	}
+	type structLimit struct {
+		Inner validateInner `json:"inner" validate:"min=1"`
+	}
+	type intSlug struct {
+		ID int `json:"id" validate:"slug"`
+	}
+	type emptyOneof struct {
+		Kind string `json:"kind" validate:"oneof="`
+	}
+	type nestedBad struct {
+		Inner *struct {
+			On bool `json:"on" validate:"max=1"`
+		} `json:"inner"`
+	}
+	for _, v := range []any{unknownRule{}, badLimit{}, boolLimit{}, floatLimit{}, structLimit{}, intSlug{}, emptyOneof{}, &nestedBad{}} {
+		if err := Validator.Check(v); err == nil {
+			t.Errorf("%T: bad tag passed the check", v)
+		}
+	}
+
+	// 有误的标签在第一次校验该类型时 panic，与字段的值无关 Bad tags panic the first time the type is validated, whatever the field values
+	defer func() {
+		if recover() == nil {
+			t.Error("Struct with a bad tag did not panic")
+		}
+	}()
+	Validator.Struct(boolLimit{})
+}
+
+func TestValidatorSlug(t *testing.T) {
+	cases := map[string]bool{
+		"blog":     true,
+		"my-blog2": true,
+		"a":        true,
+		"":         false,
+		"-blog":    false,
+		"blog-":    false,
+		"my--blog": false,
+		"My-Blog":  false,
+		"my_blog":  false,
+		"my.blog":  false,
+		"博客":       false,
+	}
+	for name, want := range cases {
+		if got := Validator.Slug(name); got != want {
+			t.Errorf("Slug(%q) = %v, want %v", name, got, want)
+		}
+	}
+	long := make([]byte, 64)
+	for i := range long {
+		long[i] = 'a'
+	}
+	if Validator.Slug(string(long)) || !Validator.Slug(string(long[:63])) {
+		t.Error("slugs are limited to 63 characters")
+	}
+}
+
+func TestValidatorNormalizeSlug(t *testing.T) {
+	cases := map[string]string{
+		"My Blog":           "my-blog",
+		"Café Über Blog":    "cafe-uber-blog",
+		"  --Straße__2--  ": "strasse-2",
+		"ｆｕｌｌｗｉｄｔｈ":         "fullwidth",
+		"博客":                "",
+		"docs 文档 v2":        "docs-v2",
+	}
+	for name, want := range cases {
+		got := Validator.NormalizeSlug(name)
+		if got != want {
+			t.Errorf("NormalizeSlug(%q) = %q, want %q", name, got, want)
+		}
+		if got != "" && !Validator.Slug(got) {
+			t.Errorf("NormalizeSlug(%q) = %q is not a slug", name, got)
+		}
+	}
+	long := Validator.NormalizeSlug("a " + strings.Repeat("b", 100))
+	if len(long) != 63 || !Validator.Slug(long) {
+		t.Errorf("long name normalized to %q", long)
+	}
+}
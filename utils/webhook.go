@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strconv"
+)
+
+// Webhook 请求头 Webhook request headers
+const (
+	WebhookSignatureHeader = "X-Spage-Signature-256" // 请求体的 HMAC-SHA256 签名 HMAC-SHA256 signature of the body
+	WebhookEventHeader     = "X-Spage-Event"         // 事件名称 Event name
+	WebhookDeliveryHeader  = "X-Spage-Delivery"      // 投递ID，重试时不变 Delivery ID, unchanged across retries
+)
+
+type webhookType struct{}
+
+var Webhook = webhookType{}
+
+// NewSecret 生成 webhook 签名密钥
+// Generate a webhook signing secret
+func (webhookType) NewSecret() (string, error) {
+	return randomToken()
+}
+
+// Sign 计算请求体的签名，格式为 sha256=<hex>，接收方用相同的密钥重新计算并比较
+// Compute the signature of the body as sha256=<hex>, receivers recompute it with the same secret and compare
+func (webhookType) Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// FeishuSign 飞书自定义机器人的签名，以时间戳和密钥拼接为键对空消息计算 HMAC-SHA256
+// Signature of Feishu custom bots, an HMAC-SHA256 of an empty message keyed by the timestamp and secret
+func (webhookType) FeishuSign(secret string, timestamp int64) string {
+	mac := hmac.New(sha256.New, []byte(strconv.FormatInt(timestamp, 10)+"\n"+secret))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}